@@ -2,23 +2,16 @@ package main
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 
-	"github.com/alnah/go-transcript/internal/apierr"
-	"github.com/alnah/go-transcript/internal/audio"
 	"github.com/alnah/go-transcript/internal/cli"
-	"github.com/alnah/go-transcript/internal/ffmpeg"
-	"github.com/alnah/go-transcript/internal/lang"
-	"github.com/alnah/go-transcript/internal/restructure"
-	"github.com/alnah/go-transcript/internal/template"
+	"github.com/alnah/go-transcript/internal/config"
 )
 
 // Injected at build time via ldflags.
@@ -27,18 +20,6 @@ var (
 	commit  = "unknown"
 )
 
-// Exit codes per specification.
-const (
-	ExitOK            = 0
-	ExitGeneral       = 1
-	ExitUsage         = 2
-	ExitSetup         = 3
-	ExitValidation    = 4
-	ExitTranscription = 5
-	ExitRestructure   = 6
-	ExitInterrupt     = 130
-)
-
 func main() {
 	// Load .env file if present (ignore error if missing).
 	_ = godotenv.Load()
@@ -50,105 +31,75 @@ func main() {
 
 	// Create the CLI environment with production defaults.
 	env := cli.DefaultEnv()
+	env.Version = fmt.Sprintf("%s (commit: %s)", version, commit)
 
 	// Root command.
+	var (
+		configFile   string
+		ffmpegPath   string
+		suppressWarn []string
+		warnAsError  bool
+	)
 	rootCmd := &cobra.Command{
 		Use:     "transcript",
 		Short:   "Record, transcribe, and restructure audio sessions",
-		Version: fmt.Sprintf("%s (commit: %s)", version, commit),
+		Version: env.Version,
 		// Silence Cobra's default error/usage printing; we handle it ourselves.
 		SilenceErrors: true,
 		SilenceUsage:  true,
+		// Use the config file of --config and the warning flags, then apply
+		// custom CA bundles and client certificates before any request, and
+		// the FFmpeg binary of --ffmpeg-path or ffmpeg-path.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			cli.UseConfigFile(configFile)
+			if err := cli.ConfigureWarnings(suppressWarn, warnAsError); err != nil {
+				return err
+			}
+			cli.ConfigureTLS(env)
+			cli.ConfigureFFmpeg(env, ffmpegPath)
+			return nil
+		},
+		// Fail a successful command that printed a warning with --warn-as-error.
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return cli.WarningsError()
+		},
 	}
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "",
+		"Config file to use instead of the default one (env: "+config.EnvConfigFile+")")
+	rootCmd.PersistentFlags().StringVar(&ffmpegPath, "ffmpeg-path", "",
+		"FFmpeg binary to use instead of FFMPEG_PATH, PATH or the download (config: ffmpeg-path)")
+	rootCmd.PersistentFlags().StringSliceVar(&suppressWarn, "suppress-warn", nil,
+		"Do not print warnings with these codes, like TR-W001 (repeatable, comma-separated)")
+	rootCmd.PersistentFlags().BoolVar(&warnAsError, "warn-as-error", false,
+		"Fail once the command finished if it printed a warning")
 
 	// Subcommands.
 	rootCmd.AddCommand(cli.RecordCmd(env))
 	rootCmd.AddCommand(cli.TranscribeCmd(env))
 	rootCmd.AddCommand(cli.LiveCmd(env))
+	rootCmd.AddCommand(cli.BotCmd(env))
+	rootCmd.AddCommand(cli.WatchCmd(env))
+	rootCmd.AddCommand(cli.DigestCmd(env))
+	rootCmd.AddCommand(cli.ServeCmd(env))
 	rootCmd.AddCommand(cli.StructureCmd(env))
 	rootCmd.AddCommand(cli.ConfigCmd(env))
 	rootCmd.AddCommand(cli.DevicesCmd(env))
+	rootCmd.AddCommand(cli.SchemaCmd(env))
+	rootCmd.AddCommand(cli.TemplatesCmd(env))
+	rootCmd.AddCommand(cli.UndoCmd(env))
+	rootCmd.AddCommand(cli.RecoverCmd(env))
+	rootCmd.AddCommand(cli.ExplainCmd(env))
+	rootCmd.AddCommand(cli.ExplainExitCmd(env))
+	rootCmd.AddCommand(cli.FFmpegCmd(env))
 
-	if err := rootCmd.ExecuteContext(ctx); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(exitCode(err))
-	}
-}
-
-// exitCode maps errors to spec-defined exit codes.
-func exitCode(err error) int {
-	if err == nil {
-		return ExitOK
-	}
-
-	// Check for context cancellation (interrupt).
-	if errors.Is(err, context.Canceled) {
-		return ExitInterrupt
-	}
-
-	// Usage errors (ExitUsage = 2): Cobra flag/arg parsing errors.
-	// Cobra doesn't expose typed errors, so we check for known error message patterns.
-	// These patterns are stable across Cobra versions (tested with v1.8+).
-	if isCobraUsageError(err) {
-		return ExitUsage
-	}
-
-	// Setup errors (ExitSetup = 3).
-	if errors.Is(err, ffmpeg.ErrNotFound) || errors.Is(err, cli.ErrAPIKeyMissing) ||
-		errors.Is(err, cli.ErrDeepSeekKeyMissing) || errors.Is(err, cli.ErrUnsupportedProvider) ||
-		errors.Is(err, audio.ErrNoAudioDevice) || errors.Is(err, audio.ErrLoopbackNotFound) ||
-		errors.Is(err, ffmpeg.ErrUnsupportedPlatform) || errors.Is(err, ffmpeg.ErrChecksumMismatch) ||
-		errors.Is(err, ffmpeg.ErrDownloadFailed) {
-		return ExitSetup
-	}
-
-	// Validation errors (ExitValidation = 4).
-	if errors.Is(err, cli.ErrInvalidDuration) || errors.Is(err, cli.ErrUnsupportedFormat) ||
-		errors.Is(err, cli.ErrFileNotFound) || errors.Is(err, template.ErrUnknown) ||
-		errors.Is(err, cli.ErrOutputExists) || errors.Is(err, audio.ErrChunkingFailed) ||
-		errors.Is(err, audio.ErrChunkTooLarge) || errors.Is(err, lang.ErrInvalid) {
-		return ExitValidation
-	}
-
-	// Transcription errors (ExitTranscription = 5).
-	if errors.Is(err, apierr.ErrRateLimit) || errors.Is(err, apierr.ErrQuotaExceeded) ||
-		errors.Is(err, apierr.ErrTimeout) || errors.Is(err, apierr.ErrAuthFailed) {
-		return ExitTranscription
-	}
-
-	// Restructure errors (ExitRestructure = 6).
-	if errors.Is(err, restructure.ErrTranscriptTooLong) {
-		return ExitRestructure
-	}
-
-	return ExitGeneral
-}
+	// Flag and argument errors of every command match cli.ErrUsage.
+	cli.WrapUsageErrors(rootCmd)
 
-// cobraUsageErrorPatterns contains error message substrings that indicate Cobra usage errors.
-// These patterns are stable across Cobra versions (tested with v1.8+).
-// Cobra doesn't expose typed errors, so string matching is the only reliable approach.
-var cobraUsageErrorPatterns = []string{
-	"required flag",             // Missing required flag
-	"unknown flag",              // Flag doesn't exist
-	"unknown shorthand",         // Short flag doesn't exist
-	"flag needs an argument",    // Flag provided without value
-	"invalid argument",          // Invalid flag value type
-	"if any flags in the group", // Mutually exclusive flag violation
-	"accepts ",                  // Wrong number of arguments (e.g., "accepts 1 arg(s)")
-	"requires at least",         // Too few arguments
-	"requires at most",          // Too many arguments
-}
+	// "transcript file.ogg" runs the default command (config default-command).
+	rootCmd.SetArgs(cli.DefaultCommandArgs(rootCmd, env, os.Args[1:]))
 
-// isCobraUsageError checks if an error is a Cobra usage/parsing error.
-func isCobraUsageError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errMsg := err.Error()
-	for _, pattern := range cobraUsageErrorPatterns {
-		if strings.Contains(errMsg, pattern) {
-			return true
-		}
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, cli.FormatError(err))
+		os.Exit(cli.ExitCode(err))
 	}
-	return false
 }
@@ -4,6 +4,10 @@
 //
 // Providers map HTTP status codes to these errors using fmt.Errorf("%s: %w", msg, sentinel).
 // Callers check with errors.Is(err, apierr.ErrRateLimit) etc.
+//
+// Providers attach the wait requested by an error response with
+// WithRetryAfter(err, ParseRetryAfter(resp.Header, time.Now())), which
+// RetryWithBackoff honors instead of its backoff delay.
 package apierr
 
 import "errors"
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/alnah/go-transcript/internal/progress"
 )
 
 // RetryConfig holds retry parameters for exponential backoff.
@@ -33,6 +35,9 @@ func (c *RetryConfig) normalize() {
 
 // RetryWithBackoff executes fn with exponential backoff retry.
 // It retries only if shouldRetry returns true for the error.
+// When the error carries the wait requested by the API (see WithRetryAfter),
+// it waits that long instead, up to maxRetryAfter.
+// Each retry is reported to the progress hooks of ctx (see progress.Hooks).
 // Returns the result of the last attempt.
 //
 // Invalid RetryConfig values are normalized (see RetryConfig documentation).
@@ -50,7 +55,12 @@ func RetryWithBackoff[T any](
 
 	for attempt := 0; attempt <= cfg.MaxRetries; attempt++ {
 		if attempt > 0 {
-			timer := time.NewTimer(delay)
+			wait := delay
+			if requested, ok := RetryAfter(lastErr); ok {
+				wait = min(requested, maxRetryAfter)
+			}
+			progress.Retry(ctx, attempt, wait, lastErr)
+			timer := time.NewTimer(wait)
 			select {
 			case <-ctx.Done():
 				if !timer.Stop() {
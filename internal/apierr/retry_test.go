@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/progress"
 )
 
 // ---------------------------------------------------------------------------
@@ -46,6 +47,77 @@ func TestRetryWithBackoff(t *testing.T) {
 		}
 	})
 
+	t.Run("waits requested by the API replace the backoff delay", func(t *testing.T) {
+		t.Parallel()
+
+		var delays []time.Duration
+		ctx := progress.WithHooks(context.Background(), progress.Hooks{
+			OnRetry: func(attempt int, delay time.Duration, err error) {
+				delays = append(delays, delay)
+			},
+		})
+
+		// The backoff delay would make the test time out.
+		callCount := 0
+		result, err := apierr.RetryWithBackoff(
+			ctx,
+			apierr.RetryConfig{MaxRetries: 2, BaseDelay: time.Hour, MaxDelay: time.Hour},
+			func() (string, error) {
+				callCount++
+				if callCount == 1 {
+					return "", apierr.WithRetryAfter(apierr.ErrRateLimit, time.Millisecond)
+				}
+				return "ok", nil
+			},
+			func(error) bool { return true },
+		)
+
+		if err != nil || result != "ok" {
+			t.Fatalf("RetryWithBackoff() = %q, %v; want ok", result, err)
+		}
+		if len(delays) != 1 || delays[0] != time.Millisecond {
+			t.Errorf("retry delays = %v, want [1ms]", delays)
+		}
+	})
+
+	t.Run("retries are reported to progress hooks", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts []int
+		var lastErr error
+		ctx := progress.WithHooks(context.Background(), progress.Hooks{
+			OnRetry: func(attempt int, delay time.Duration, err error) {
+				attempts = append(attempts, attempt)
+				lastErr = err
+			},
+		})
+
+		testErr := errors.New("transient")
+		callCount := 0
+		_, err := apierr.RetryWithBackoff(
+			ctx,
+			apierr.RetryConfig{MaxRetries: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+			func() (string, error) {
+				callCount++
+				if callCount < 3 {
+					return "", testErr
+				}
+				return "ok", nil
+			},
+			func(error) bool { return true },
+		)
+
+		if err != nil {
+			t.Fatalf("RetryWithBackoff() unexpected error: %v", err)
+		}
+		if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+			t.Errorf("reported attempts = %v, want [1 2]", attempts)
+		}
+		if !errors.Is(lastErr, testErr) {
+			t.Errorf("reported error = %v, want %v", lastErr, testErr)
+		}
+	})
+
 	t.Run("shouldRetry false stops immediately", func(t *testing.T) {
 		t.Parallel()
 
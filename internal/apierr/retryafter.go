@@ -0,0 +1,101 @@
+package apierr
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRetryAfter caps the waits requested by APIs, so that a misbehaving
+// server cannot stall a run for hours.
+const maxRetryAfter = 5 * time.Minute
+
+// retryAfterError wraps an error with the wait requested by the API before
+// retrying, see WithRetryAfter.
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string { return e.err.Error() }
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+// WithRetryAfter attaches to err the wait requested by the API before
+// retrying, for RetryWithBackoff to honor instead of its backoff delay.
+// Returns err unchanged if err is nil or delay is not positive.
+func WithRetryAfter(err error, delay time.Duration) error {
+	if err == nil || delay <= 0 {
+		return err
+	}
+	if d, ok := RetryAfter(err); ok && d == delay {
+		return err
+	}
+	return &retryAfterError{err: err, delay: delay}
+}
+
+// RetryAfter returns the wait requested by the API before retrying, if err
+// carries one (see WithRetryAfter).
+func RetryAfter(err error) (time.Duration, bool) {
+	var ra *retryAfterError
+	if errors.As(err, &ra) {
+		return ra.delay, true
+	}
+	return 0, false
+}
+
+// rateLimitHeaders pairs the headers giving the requests or tokens left in
+// a rate limit window with the header giving when the window resets.
+// OpenAI (and OpenAI-compatible APIs) give a duration, e.g. "6m0s";
+// Anthropic gives an RFC 3339 timestamp.
+var rateLimitHeaders = []struct{ remaining, reset string }{
+	{"x-ratelimit-remaining-requests", "x-ratelimit-reset-requests"},
+	{"x-ratelimit-remaining-tokens", "x-ratelimit-reset-tokens"},
+	{"anthropic-ratelimit-requests-remaining", "anthropic-ratelimit-requests-reset"},
+	{"anthropic-ratelimit-tokens-remaining", "anthropic-ratelimit-tokens-reset"},
+	{"anthropic-ratelimit-input-tokens-remaining", "anthropic-ratelimit-input-tokens-reset"},
+	{"anthropic-ratelimit-output-tokens-remaining", "anthropic-ratelimit-output-tokens-reset"},
+}
+
+// ParseRetryAfter returns the wait before retrying requested by the headers
+// of an error response, at time now: the Retry-After header (seconds or HTTP
+// date), retry-after-ms, or else the latest reset of the rate limits
+// exhausted. Returns 0 if the headers request no wait.
+func ParseRetryAfter(h http.Header, now time.Time) time.Duration {
+	if v := strings.TrimSpace(h.Get("retry-after-ms")); v != "" {
+		if ms, err := strconv.ParseFloat(v, 64); err == nil && ms > 0 {
+			return time.Duration(ms * float64(time.Millisecond))
+		}
+	}
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		if s, err := strconv.ParseFloat(v, 64); err == nil {
+			return max(time.Duration(s*float64(time.Second)), 0)
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(t.Sub(now), 0)
+		}
+	}
+
+	var wait time.Duration
+	for _, limit := range rateLimitHeaders {
+		if strings.TrimSpace(h.Get(limit.remaining)) != "0" {
+			continue
+		}
+		wait = max(wait, parseReset(h.Get(limit.reset), now))
+	}
+	return wait
+}
+
+// parseReset parses the reset of a rate limit window: a duration or an
+// RFC 3339 timestamp. Returns 0 if v is neither, or is in the past.
+func parseReset(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if d, err := time.ParseDuration(v); err == nil {
+		return max(d, 0)
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
@@ -0,0 +1,106 @@
+package apierr_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+)
+
+func TestWithRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("delay is found through wrapping", func(t *testing.T) {
+		t.Parallel()
+
+		err := fmt.Errorf("slow down: %w", apierr.WithRetryAfter(apierr.ErrRateLimit, 20*time.Second))
+		if d, ok := apierr.RetryAfter(err); !ok || d != 20*time.Second {
+			t.Errorf("RetryAfter() = %v, %v; want 20s", d, ok)
+		}
+		if !errors.Is(err, apierr.ErrRateLimit) {
+			t.Errorf("errors.Is(err, ErrRateLimit) = false, want true")
+		}
+		if err.Error() != "slow down: "+apierr.ErrRateLimit.Error() {
+			t.Errorf("Error() = %q, want the message of the wrapped error", err.Error())
+		}
+	})
+
+	t.Run("no delay", func(t *testing.T) {
+		t.Parallel()
+
+		if err := apierr.WithRetryAfter(apierr.ErrRateLimit, 0); err != apierr.ErrRateLimit {
+			t.Errorf("WithRetryAfter(err, 0) = %v, want err unchanged", err)
+		}
+		if err := apierr.WithRetryAfter(nil, time.Second); err != nil {
+			t.Errorf("WithRetryAfter(nil, 1s) = %v, want nil", err)
+		}
+		if _, ok := apierr.RetryAfter(apierr.ErrRateLimit); ok {
+			t.Error("RetryAfter() ok for an error without delay")
+		}
+	})
+
+	t.Run("same delay is not wrapped twice", func(t *testing.T) {
+		t.Parallel()
+
+		err := apierr.WithRetryAfter(apierr.ErrRateLimit, time.Second)
+		if again := apierr.WithRetryAfter(err, time.Second); again != err {
+			t.Errorf("WithRetryAfter() wrapped the same delay twice")
+		}
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   time.Duration
+	}{
+		{"no headers", nil, 0},
+		{"retry-after seconds", map[string]string{"Retry-After": "20"}, 20 * time.Second},
+		{"retry-after fractional seconds", map[string]string{"Retry-After": "1.5"}, 1500 * time.Millisecond},
+		{"retry-after date", map[string]string{"Retry-After": now.Add(time.Minute).Format(http.TimeFormat)}, time.Minute},
+		{"retry-after date in the past", map[string]string{"Retry-After": now.Add(-time.Minute).Format(http.TimeFormat)}, 0},
+		{"retry-after malformed", map[string]string{"Retry-After": "soon"}, 0},
+		{"retry-after-ms first", map[string]string{"retry-after-ms": "250", "Retry-After": "1"}, 250 * time.Millisecond},
+		{"openai exhausted requests", map[string]string{
+			"x-ratelimit-remaining-requests": "0", "x-ratelimit-reset-requests": "6m0s",
+			"x-ratelimit-remaining-tokens": "1500", "x-ratelimit-reset-tokens": "10s",
+		}, 6 * time.Minute},
+		{"openai exhausted requests and tokens", map[string]string{
+			"x-ratelimit-remaining-requests": "0", "x-ratelimit-reset-requests": "1s",
+			"x-ratelimit-remaining-tokens": "0", "x-ratelimit-reset-tokens": "7.5s",
+		}, 7500 * time.Millisecond},
+		{"openai limits left", map[string]string{
+			"x-ratelimit-remaining-requests": "10", "x-ratelimit-reset-requests": "1s",
+		}, 0},
+		{"anthropic exhausted tokens", map[string]string{
+			"anthropic-ratelimit-tokens-remaining": "0",
+			"anthropic-ratelimit-tokens-reset":     now.Add(30 * time.Second).Format(time.RFC3339),
+		}, 30 * time.Second},
+		{"retry-after wins over resets", map[string]string{
+			"Retry-After":                    "3",
+			"x-ratelimit-remaining-requests": "0", "x-ratelimit-reset-requests": "1m",
+		}, 3 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := http.Header{}
+			for k, v := range tt.header {
+				h.Set(k, v)
+			}
+			if got := apierr.ParseRetryAfter(h, now); got != tt.want {
+				t.Errorf("ParseRetryAfter(%v) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
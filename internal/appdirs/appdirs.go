@@ -0,0 +1,90 @@
+// Package appdirs locates the directories where transcript keeps its files:
+// the XDG base directories on Linux and BSDs, and their equivalents on macOS
+// and Windows. XDG variables set to absolute paths apply on every platform.
+//
+// Versions before XDG support kept everything in ~/.config/go-transcript and
+// FFmpeg in ~/.go-transcript/bin. Legacy returns the former, so that
+// existing files keep being used where they are.
+package appdirs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Name is the directory name of the application in each base directory.
+const Name = "go-transcript"
+
+// Dirs are the directories of the application.
+type Dirs struct {
+	Config string // Settings written by the user: config file, templates
+	State  string // History built across runs: tag vocabulary, intro library
+	Cache  string // Files that can be downloaded again: FFmpeg
+}
+
+// Default returns the directories of the application on this platform.
+func Default() (Dirs, error) {
+	return Resolve(runtime.GOOS, os.Getenv, os.UserHomeDir)
+}
+
+// Resolve returns the directories of the application on goos, reading the
+// environment with getenv and the home directory with home.
+//
+// On macOS and Windows, the legacy directory is used as the config directory
+// while the platform one does not exist, so that the config of earlier
+// versions is still found.
+func Resolve(goos string, getenv func(string) string, home func() (string, error)) (Dirs, error) {
+	h, err := home()
+	if err != nil {
+		return Dirs{}, fmt.Errorf("cannot determine home directory: %w", err)
+	}
+
+	var config, state, cache string
+	switch goos {
+	case "darwin":
+		support := filepath.Join(h, "Library", "Application Support")
+		config, state, cache = support, support, filepath.Join(h, "Library", "Caches")
+	case "windows":
+		local := envOr(getenv, "LOCALAPPDATA", filepath.Join(h, "AppData", "Local"))
+		config, state, cache = envOr(getenv, "APPDATA", filepath.Join(h, "AppData", "Roaming")), local, local
+	default:
+		config = filepath.Join(h, ".config")
+		state = filepath.Join(h, ".local", "state")
+		cache = filepath.Join(h, ".cache")
+	}
+
+	dirs := Dirs{
+		Config: filepath.Join(envOr(getenv, "XDG_CONFIG_HOME", config), Name),
+		State:  filepath.Join(envOr(getenv, "XDG_STATE_HOME", state), Name),
+		Cache:  filepath.Join(envOr(getenv, "XDG_CACHE_HOME", cache), Name),
+	}
+
+	if legacy := Legacy(h); (goos == "darwin" || goos == "windows") && !filepath.IsAbs(getenv("XDG_CONFIG_HOME")) &&
+		!exists(dirs.Config) && exists(legacy) {
+		dirs.Config = legacy
+	}
+	return dirs, nil
+}
+
+// Legacy returns the directory where versions before XDG support kept the
+// config, templates, tag vocabulary and intro library.
+func Legacy(home string) string {
+	return filepath.Join(home, ".config", Name)
+}
+
+// envOr returns the environment variable key if it is an absolute path,
+// or fallback. The XDG specification requires relative paths to be ignored.
+func envOr(getenv func(string) string, key, fallback string) string {
+	if v := getenv(key); filepath.IsAbs(v) {
+		return v
+	}
+	return fallback
+}
+
+// exists reports whether path exists.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
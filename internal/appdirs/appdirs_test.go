@@ -0,0 +1,165 @@
+package appdirs_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/appdirs"
+)
+
+// envMap returns a getenv function reading vars.
+func envMap(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	home := "/home/ada"
+	tests := []struct {
+		name string
+		goos string
+		env  map[string]string
+		want appdirs.Dirs
+	}{
+		{
+			name: "linux defaults",
+			goos: "linux",
+			want: appdirs.Dirs{
+				Config: "/home/ada/.config/go-transcript",
+				State:  "/home/ada/.local/state/go-transcript",
+				Cache:  "/home/ada/.cache/go-transcript",
+			},
+		},
+		{
+			name: "linux XDG variables",
+			goos: "linux",
+			env:  map[string]string{"XDG_CONFIG_HOME": "/xdg/config", "XDG_STATE_HOME": "/xdg/state", "XDG_CACHE_HOME": "/xdg/cache"},
+			want: appdirs.Dirs{
+				Config: "/xdg/config/go-transcript",
+				State:  "/xdg/state/go-transcript",
+				Cache:  "/xdg/cache/go-transcript",
+			},
+		},
+		{
+			name: "relative XDG variables ignored",
+			goos: "freebsd",
+			env:  map[string]string{"XDG_CONFIG_HOME": "config", "XDG_CACHE_HOME": "./cache"},
+			want: appdirs.Dirs{
+				Config: "/home/ada/.config/go-transcript",
+				State:  "/home/ada/.local/state/go-transcript",
+				Cache:  "/home/ada/.cache/go-transcript",
+			},
+		},
+		{
+			name: "macOS defaults",
+			goos: "darwin",
+			want: appdirs.Dirs{
+				Config: "/home/ada/Library/Application Support/go-transcript",
+				State:  "/home/ada/Library/Application Support/go-transcript",
+				Cache:  "/home/ada/Library/Caches/go-transcript",
+			},
+		},
+		{
+			name: "macOS XDG variables",
+			goos: "darwin",
+			env:  map[string]string{"XDG_CONFIG_HOME": "/xdg/config"},
+			want: appdirs.Dirs{
+				Config: "/xdg/config/go-transcript",
+				State:  "/home/ada/Library/Application Support/go-transcript",
+				Cache:  "/home/ada/Library/Caches/go-transcript",
+			},
+		},
+		{
+			name: "windows",
+			goos: "windows",
+			env:  map[string]string{"APPDATA": "/users/ada/roaming", "LOCALAPPDATA": "/users/ada/local"},
+			want: appdirs.Dirs{
+				Config: "/users/ada/roaming/go-transcript",
+				State:  "/users/ada/local/go-transcript",
+				Cache:  "/users/ada/local/go-transcript",
+			},
+		},
+		{
+			name: "windows without APPDATA",
+			goos: "windows",
+			want: appdirs.Dirs{
+				Config: filepath.Join(home, "AppData", "Roaming", "go-transcript"),
+				State:  filepath.Join(home, "AppData", "Local", "go-transcript"),
+				Cache:  filepath.Join(home, "AppData", "Local", "go-transcript"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := appdirs.Resolve(tt.goos, envMap(tt.env), func() (string, error) { return home, nil })
+			if err != nil {
+				t.Fatalf("Resolve() unexpected error: %v", err)
+			}
+			want := appdirs.Dirs{
+				Config: filepath.FromSlash(tt.want.Config),
+				State:  filepath.FromSlash(tt.want.State),
+				Cache:  filepath.FromSlash(tt.want.Cache),
+			}
+			if got != want {
+				t.Errorf("Resolve() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestResolve_Legacy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("legacy config directory kept on macOS", func(t *testing.T) {
+		t.Parallel()
+
+		home := t.TempDir()
+		legacy := appdirs.Legacy(home)
+		if err := os.MkdirAll(legacy, 0o750); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := appdirs.Resolve("darwin", envMap(nil), func() (string, error) { return home, nil })
+		if err != nil {
+			t.Fatalf("Resolve() unexpected error: %v", err)
+		}
+		if got.Config != legacy {
+			t.Errorf("Config = %q, want the legacy %q", got.Config, legacy)
+		}
+	})
+
+	t.Run("platform config directory preferred once created", func(t *testing.T) {
+		t.Parallel()
+
+		home := t.TempDir()
+		platform := filepath.Join(home, "Library", "Application Support", "go-transcript")
+		for _, dir := range []string{appdirs.Legacy(home), platform} {
+			if err := os.MkdirAll(dir, 0o750); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		got, err := appdirs.Resolve("darwin", envMap(nil), func() (string, error) { return home, nil })
+		if err != nil {
+			t.Fatalf("Resolve() unexpected error: %v", err)
+		}
+		if got.Config != platform {
+			t.Errorf("Config = %q, want %q", got.Config, platform)
+		}
+	})
+}
+
+func TestResolve_NoHome(t *testing.T) {
+	t.Parallel()
+
+	_, err := appdirs.Resolve("linux", envMap(nil), func() (string, error) { return "", errors.New("no home") })
+	if err == nil {
+		t.Error("Resolve() expected error without home directory")
+	}
+}
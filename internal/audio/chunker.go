@@ -1,15 +1,24 @@
 package audio
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"golang.org/x/sync/errgroup"
+
 	"github.com/alnah/go-transcript/internal/ffmpeg"
 	"github.com/alnah/go-transcript/internal/format"
 )
@@ -18,6 +27,12 @@ import (
 var (
 	_ Chunker = (*TimeChunker)(nil)
 	_ Chunker = (*SilenceChunker)(nil)
+	_ Planner = (*TimeChunker)(nil)
+	_ Planner = (*SilenceChunker)(nil)
+	_ Chunker = (*SizeChunker)(nil)
+	_ Planner = (*SizeChunker)(nil)
+	_ Chunker = (*EnergyChunker)(nil)
+	_ Planner = (*EnergyChunker)(nil)
 )
 
 // Chunk represents a segment of audio extracted from a larger file.
@@ -27,6 +42,9 @@ type Chunk struct {
 	Index     int           // Zero-based index for ordering.
 	StartTime time.Duration // Start timestamp in the source audio.
 	EndTime   time.Duration // End timestamp in the source audio.
+
+	Extraction time.Duration // Time taken to extract the chunk file (zero if only planned).
+	RunID      string        // Run whose temp directory holds the chunk file (empty if only planned).
 }
 
 // Duration returns the length of this chunk.
@@ -50,6 +68,13 @@ type Chunker interface {
 	Chunk(ctx context.Context, audioPath string) ([]Chunk, error)
 }
 
+// Planner computes the chunks a Chunker would create, without extracting them,
+// e.g. to estimate the cost of a transcription (transcribe --dry-run).
+type Planner interface {
+	// Plan returns the chunks of audioPath, with an empty Path.
+	Plan(ctx context.Context, audioPath string) ([]Chunk, error)
+}
+
 // Default chunking parameters.
 const (
 	// defaultNoiseDB is the silence detection threshold in dB.
@@ -94,11 +119,13 @@ func defaultWarnFunc(msg string) {
 }
 
 // TimeChunker splits audio into fixed-duration chunks with overlap.
-// This is the fallback strategy when silence detection fails or finds no silences.
+// This is the fallback strategy when the loudness of the audio cannot be
+// measured to cut at its quietest moments (see EnergyChunker).
 type TimeChunker struct {
 	ffmpegPath     string
 	targetDuration time.Duration
 	overlap        time.Duration
+	runID          string
 
 	// Injectable dependencies (defaults to OS implementations).
 	cmd     commandRunner
@@ -130,6 +157,14 @@ func WithTimeChunkerFileRemover(f fileRemover) TimeChunkerOption {
 	}
 }
 
+// WithTimeChunkerRunID sets the run ID naming the temp directories of TimeChunker.
+// Default: a new ID (see NewRunID).
+func WithTimeChunkerRunID(id string) TimeChunkerOption {
+	return func(tc *TimeChunker) {
+		tc.runID = id
+	}
+}
+
 // NewTimeChunker creates a TimeChunker with the specified parameters.
 func NewTimeChunker(ffmpegPath string, targetDuration, overlap time.Duration, opts ...TimeChunkerOption) (*TimeChunker, error) {
 	if ffmpegPath == "" {
@@ -149,6 +184,7 @@ func NewTimeChunker(ffmpegPath string, targetDuration, overlap time.Duration, op
 		ffmpegPath:     ffmpegPath,
 		targetDuration: targetDuration,
 		overlap:        overlap,
+		runID:          NewRunID(),
 		cmd:            osCommandRunner{},
 		tempDir:        osTempDirCreator{},
 		files:          osFileRemover{},
@@ -163,18 +199,40 @@ func NewTimeChunker(ffmpegPath string, targetDuration, overlap time.Duration, op
 
 // Chunk splits the audio file into fixed-duration segments with overlap.
 func (tc *TimeChunker) Chunk(ctx context.Context, audioPath string) ([]Chunk, error) {
-	// Get total duration of the audio file.
-	totalDuration, err := tc.probeDuration(ctx, audioPath)
+	chunks, err := tc.Plan(ctx, audioPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to probe audio duration: %w", err)
+		return nil, err
 	}
 
 	// Create temp directory for chunks.
-	tempDir, err := tc.tempDir.MkdirTemp("", "go-transcript-*")
+	tempDir, err := tc.tempDir.MkdirTemp("", chunkDirPattern(tc.runID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temp directory: %w", err)
 	}
 
+	streamCopy := probeStreamCopy(ctx, tc.cmd, tc.ffmpegPath, audioPath)
+	for i := range chunks {
+		chunks[i].Path = filepath.Join(tempDir, fmt.Sprintf("chunk_%03d.ogg", i))
+		chunks[i].RunID = tc.runID
+		start := time.Now()
+		if err := tc.extractChunk(ctx, audioPath, chunks[i].Path, chunks[i].StartTime, chunks[i].EndTime, streamCopy); err != nil {
+			_ = tc.files.RemoveAll(tempDir) // best-effort cleanup; original error takes precedence
+			return nil, err
+		}
+		chunks[i].Extraction = time.Since(start)
+	}
+
+	return chunks, nil
+}
+
+// Plan returns the fixed-duration segments of the audio file, without extracting them.
+func (tc *TimeChunker) Plan(ctx context.Context, audioPath string) ([]Chunk, error) {
+	// Get total duration of the audio file.
+	totalDuration, err := tc.probeDuration(ctx, audioPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe audio duration: %w", err)
+	}
+
 	// Calculate chunk boundaries.
 	var chunks []Chunk
 	step := tc.targetDuration - tc.overlap
@@ -185,14 +243,7 @@ func (tc *TimeChunker) Chunk(ctx context.Context, audioPath string) ([]Chunk, er
 		}
 		end := min(start+tc.targetDuration, totalDuration)
 
-		chunkPath := filepath.Join(tempDir, fmt.Sprintf("chunk_%03d.ogg", i))
-		if err := tc.extractChunk(ctx, audioPath, chunkPath, start, end); err != nil {
-			_ = tc.files.RemoveAll(tempDir) // best-effort cleanup; original error takes precedence
-			return nil, err
-		}
-
 		chunks = append(chunks, Chunk{
-			Path:      chunkPath,
 			Index:     i,
 			StartTime: start,
 			EndTime:   end,
@@ -209,22 +260,24 @@ func (tc *TimeChunker) Chunk(ctx context.Context, audioPath string) ([]Chunk, er
 
 // probeDuration returns the duration of an audio file using ffprobe/ffmpeg.
 func (tc *TimeChunker) probeDuration(ctx context.Context, audioPath string) (time.Duration, error) {
-	// Use ffmpeg to get duration (ffprobe may not be available).
-	// The -i flag with no output shows file info including duration.
-	args := []string{
-		"-i", audioPath,
-		"-f", "null", "-",
-	}
-	output, err := tc.cmd.CombinedOutput(ctx, tc.ffmpegPath, args)
+	return probeDuration(ctx, tc.cmd, tc.ffmpegPath, audioPath)
+}
+
+// probeDuration returns the duration of an audio file (see probeMetadata).
+func probeDuration(ctx context.Context, cmd commandRunner, ffmpegPath, audioPath string) (time.Duration, error) {
+	m, err := probeMetadata(ctx, cmd, ffmpegPath, audioPath)
 	if err != nil {
-		// FFmpeg returns non-zero even when it successfully reads file info,
-		// so we try to parse the output anyway.
-		if len(output) == 0 {
-			return 0, err
-		}
+		return 0, err
 	}
+	return m.Duration, nil
+}
 
-	return parseDurationFromFFmpegOutput(string(output))
+// probeMetadata returns the metadata of an audio file, read by the ffprobe
+// installed along with ffmpegPath, or by FFmpeg if there is none.
+// Both run through cmd.
+func probeMetadata(ctx context.Context, cmd commandRunner, ffmpegPath, audioPath string) (ffmpeg.Metadata, error) {
+	prober := ffmpeg.NewMetadataProber(ffmpegPath, ffmpeg.WithProbeRun(cmd.CombinedOutput))
+	return prober.Probe(ctx, audioPath)
 }
 
 // parseDurationFromFFmpegOutput extracts duration from FFmpeg stderr.
@@ -237,15 +290,25 @@ func parseDurationFromFFmpegOutput(output string) (time.Duration, error) {
 	}
 
 	// Fallback pattern: time=00:05:23.45 (from progress output)
+	if d, ok := parseFinalTime(output); ok {
+		return d, nil
+	}
+
+	return 0, fmt.Errorf("could not parse duration from ffmpeg output")
+}
+
+// parseFinalTime extracts the last progress time of FFmpeg stderr
+// ("time=HH:MM:SS.ms"): the duration of the output written.
+func parseFinalTime(output string) (time.Duration, bool) {
 	timeRe := regexp.MustCompile(`time=(\d+):(\d+):(\d+)\.(\d+)`)
 	// Find all matches and use the last one (final time).
 	allMatches := timeRe.FindAllStringSubmatch(output, -1)
-	if len(allMatches) > 0 {
-		matches := allMatches[len(allMatches)-1]
-		return parseTimeComponents(matches[1], matches[2], matches[3], matches[4])
+	if len(allMatches) == 0 {
+		return 0, false
 	}
-
-	return 0, fmt.Errorf("could not parse duration from ffmpeg output")
+	matches := allMatches[len(allMatches)-1]
+	d, err := parseTimeComponents(matches[1], matches[2], matches[3], matches[4])
+	return d, err == nil
 }
 
 // parseTimeComponents converts HH:MM:SS.ms strings to Duration.
@@ -290,15 +353,78 @@ func chunkEncodingArgs() []string {
 	}
 }
 
+// probeStreamCopy reports whether the chunks of audioPath can be copied
+// without re-encoding (see canStreamCopy). Any probe failure means re-encoding.
+func probeStreamCopy(ctx context.Context, cmd commandRunner, ffmpegPath, audioPath string) bool {
+	m, err := probeMetadata(ctx, cmd, ffmpegPath, audioPath)
+	return err == nil && canStreamCopy(m, opusInputRate(audioPath))
+}
+
+// Limits of the chunk encoding (see chunkEncodingArgs) an input must be within
+// to be copied. A richer one would make chunks larger than planned.
+const (
+	chunkSampleRate = 16000
+	chunkBitrate    = 50_000
+)
+
+// canStreamCopy reports whether an input is already in the chunk encoding: a
+// single mono Opus stream in an OGG container, like recordings, encoded from
+// 16kHz at 50kbps at most. Opus always decodes at 48kHz, so the rate reported
+// by FFmpeg is not the encoding one: inputRate is the rate read from the Opus
+// header (see opusInputRate).
+func canStreamCopy(m ffmpeg.Metadata, inputRate int) bool {
+	return m.Format == "ogg" && m.AudioStreams == 1 && m.Codec == "opus" && m.Channels == 1 &&
+		inputRate == chunkSampleRate && m.Bitrate > 0 && m.Bitrate <= chunkBitrate
+}
+
+// opusHeadSize is how much of a file opusInputRate reads: the OpusHead packet
+// is the first one of an OGG Opus stream, right after the first page header.
+const opusHeadSize = 512
+
+// opusInputRate returns the sample rate an OGG Opus file was encoded from, as
+// stored in its OpusHead packet, or 0 if the file has none or cannot be read.
+func opusInputRate(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	head := make([]byte, opusHeadSize)
+	n, _ := io.ReadFull(f, head)
+	head = head[:n]
+	// OpusHead layout: magic (8 bytes), version, channels, pre-skip (2 bytes),
+	// input sample rate (4 bytes, little-endian).
+	i := bytes.Index(head, []byte("OpusHead"))
+	if i < 0 || i+16 > len(head) {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint32(head[i+12 : i+16]))
+}
+
 // runExtractChunk extracts a segment from audioPath to chunkPath using FFmpeg.
 // Re-encodes to OGG Opus to ensure valid output even from corrupted/truncated sources.
-func runExtractChunk(ctx context.Context, cmd commandRunner, ffmpegPath, audioPath, chunkPath string, start, end time.Duration) error {
+// With streamCopy, the segment is copied instead, which is much cheaper: every
+// Opus packet is a keyframe, so the cuts stay within a packet (20ms at most)
+// of start and end. If copying fails, the segment is re-encoded.
+func runExtractChunk(ctx context.Context, cmd commandRunner, ffmpegPath, audioPath, chunkPath string, start, end time.Duration, streamCopy bool) error {
 	args := []string{
 		"-y",
 		"-i", audioPath,
 		"-ss", formatFFmpegTime(start),
 		"-to", formatFFmpegTime(end),
 	}
+
+	if streamCopy {
+		copyArgs := append(slices.Clone(args), "-c:a", "copy", chunkPath)
+		if _, err := cmd.CombinedOutput(ctx, ffmpegPath, copyArgs); err == nil {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
 	args = append(args, chunkEncodingArgs()...)
 	args = append(args, chunkPath)
 
@@ -311,8 +437,8 @@ func runExtractChunk(ctx context.Context, cmd commandRunner, ffmpegPath, audioPa
 }
 
 // extractChunk extracts a segment from audioPath to chunkPath.
-func (tc *TimeChunker) extractChunk(ctx context.Context, audioPath, chunkPath string, start, end time.Duration) error {
-	return runExtractChunk(ctx, tc.cmd, tc.ffmpegPath, audioPath, chunkPath, start, end)
+func (tc *TimeChunker) extractChunk(ctx context.Context, audioPath, chunkPath string, start, end time.Duration, streamCopy bool) error {
+	return runExtractChunk(ctx, tc.cmd, tc.ffmpegPath, audioPath, chunkPath, start, end, streamCopy)
 }
 
 // formatFFmpegTime formats a duration for FFmpeg -ss/-to arguments.
@@ -324,7 +450,7 @@ func formatFFmpegTime(d time.Duration) string {
 }
 
 // SilenceChunker splits audio at detected silence points.
-// Falls back to TimeChunker if no silences are found.
+// Falls back to EnergyChunker if no silences are found.
 type SilenceChunker struct {
 	ffmpegPath   string
 	noiseDB      float64
@@ -332,6 +458,8 @@ type SilenceChunker struct {
 	maxChunkSize int64
 	fallback     Chunker
 	warn         WarnFunc
+	runID        string
+	workers      int // Chunks extracted at once; 0 means one per CPU
 
 	// Injectable dependencies (defaults to OS implementations).
 	cmd     commandRunner
@@ -369,13 +497,22 @@ func WithMaxChunkSize(size int64) SilenceChunkerOption {
 }
 
 // WithFallback sets a custom fallback Chunker.
-// Default: TimeChunker with 10min target, 30s overlap.
+// Default: EnergyChunker with the same options.
 func WithFallback(c Chunker) SilenceChunkerOption {
 	return func(sc *SilenceChunker) {
 		sc.fallback = c
 	}
 }
 
+// WithExtractWorkers sets the number of chunks extracted at once, each by
+// an FFmpeg process, by SilenceChunker and its default fallback.
+// Default: one per CPU (0).
+func WithExtractWorkers(n int) SilenceChunkerOption {
+	return func(sc *SilenceChunker) {
+		sc.workers = n
+	}
+}
+
 // WithCommandRunner sets the command runner for SilenceChunker.
 func WithCommandRunner(r commandRunner) SilenceChunkerOption {
 	return func(sc *SilenceChunker) {
@@ -412,8 +549,17 @@ func WithWarnFunc(fn WarnFunc) SilenceChunkerOption {
 	}
 }
 
+// WithRunID sets the run ID naming the temp directories of SilenceChunker
+// and of its default fallbacks.
+// Default: a new ID (see NewRunID).
+func WithRunID(id string) SilenceChunkerOption {
+	return func(sc *SilenceChunker) {
+		sc.runID = id
+	}
+}
+
 // NewSilenceChunker creates a SilenceChunker with functional options.
-// If no fallback is provided, a default TimeChunker is created.
+// If no fallback is provided, a default EnergyChunker is created.
 func NewSilenceChunker(ffmpegPath string, opts ...SilenceChunkerOption) (*SilenceChunker, error) {
 	if ffmpegPath == "" {
 		return nil, fmt.Errorf("ffmpegPath cannot be empty: %w", ffmpeg.ErrNotFound)
@@ -425,6 +571,7 @@ func NewSilenceChunker(ffmpegPath string, opts ...SilenceChunkerOption) (*Silenc
 		minSilence:   defaultMinSilence,
 		maxChunkSize: defaultMaxChunkSize,
 		warn:         defaultWarnFunc,
+		runID:        NewRunID(),
 		cmd:          osCommandRunner{},
 		tempDir:      osTempDirCreator{},
 		files:        osFileRemover{},
@@ -437,7 +584,15 @@ func NewSilenceChunker(ffmpegPath string, opts ...SilenceChunkerOption) (*Silenc
 
 	// Create default fallback if not provided.
 	if sc.fallback == nil {
-		fallback, err := NewTimeChunker(ffmpegPath, defaultTargetDuration, defaultOverlap)
+		fallback, err := NewEnergyChunker(ffmpegPath,
+			WithEnergyChunkerMaxChunkSize(sc.maxChunkSize),
+			WithEnergyChunkerWarnFunc(sc.warn),
+			WithEnergyChunkerRunID(sc.runID),
+			WithEnergyChunkerExtractWorkers(sc.workers),
+			WithEnergyChunkerCommandRunner(sc.cmd),
+			WithEnergyChunkerTempDir(sc.tempDir),
+			WithEnergyChunkerFileRemover(sc.files),
+			WithEnergyChunkerFileStatter(sc.statter))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create fallback chunker: %w", err)
 		}
@@ -448,8 +603,47 @@ func NewSilenceChunker(ffmpegPath string, opts ...SilenceChunkerOption) (*Silenc
 }
 
 // Chunk splits the audio file at silence points.
-// If no silences are found, falls back to time-based chunking.
+// If no silences are found, falls back to cutting at the quietest moments.
 func (sc *SilenceChunker) Chunk(ctx context.Context, audioPath string) ([]Chunk, error) {
+	chunks, err := sc.plan(ctx, audioPath)
+	if err != nil {
+		return nil, err
+	}
+	if chunks == nil {
+		return sc.fallback.Chunk(ctx, audioPath)
+	}
+
+	// Create temp directory for chunks.
+	tempDir, err := sc.tempDir.MkdirTemp("", chunkDirPattern(sc.runID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	if err := extractChunks(ctx, sc.cmd, sc.files, sc.ffmpegPath, audioPath, tempDir, sc.runID, sc.workers, chunks); err != nil {
+		_ = sc.files.RemoveAll(tempDir) // best-effort cleanup; original error takes precedence
+		return nil, err
+	}
+
+	return chunks, nil
+}
+
+// Plan returns the chunks the audio file would be split into, without
+// extracting them. If no silences are found, plans with the fallback chunker.
+func (sc *SilenceChunker) Plan(ctx context.Context, audioPath string) ([]Chunk, error) {
+	chunks, err := sc.plan(ctx, audioPath)
+	if err != nil || chunks != nil {
+		return chunks, err
+	}
+	planner, ok := sc.fallback.(Planner)
+	if !ok {
+		return nil, fmt.Errorf("fallback chunker cannot plan chunks")
+	}
+	return planner.Plan(ctx, audioPath)
+}
+
+// plan computes the chunk boundaries at silence points.
+// Returns nil chunks if the fallback chunker must be used instead.
+func (sc *SilenceChunker) plan(ctx context.Context, audioPath string) ([]Chunk, error) {
 	// Get file info for bitrate estimation.
 	fileInfo, err := sc.statter.Stat(audioPath)
 	if err != nil {
@@ -460,19 +654,19 @@ func (sc *SilenceChunker) Chunk(ctx context.Context, audioPath string) ([]Chunk,
 	// Detect silences.
 	silences, totalDuration, err := sc.detectSilences(ctx, audioPath)
 	if err != nil {
-		// Warn and fall back to time-based chunking.
+		// Warn and fall back to the quietest moments.
 		if sc.warn != nil {
-			sc.warn(fmt.Sprintf("Warning: silence detection failed (%v), using time-based chunking", err))
+			sc.warn(fmt.Sprintf("Warning: silence detection failed (%v), cutting at the quietest moments instead", err))
 		}
-		return sc.fallback.Chunk(ctx, audioPath)
+		return nil, nil
 	}
 
-	// No silences found - fall back to time-based chunking.
+	// No silences found (e.g. continuous background noise) - fall back to the quietest moments.
 	if len(silences) == 0 {
 		if sc.warn != nil {
-			sc.warn("Warning: no silences detected, using time-based chunking (may cut mid-sentence)")
+			sc.warn("Warning: no silences detected, cutting at the quietest moments instead")
 		}
-		return sc.fallback.Chunk(ctx, audioPath)
+		return nil, nil
 	}
 
 	// Trim trailing silence: if last silence extends to end of file, use its start as effective end.
@@ -488,20 +682,8 @@ func (sc *SilenceChunker) Chunk(ctx context.Context, audioPath string) ([]Chunk,
 	// Select cut points that keep chunks under maxChunkSize.
 	cutPoints := sc.selectCutPoints(silences, avgBitrate)
 
-	// Create temp directory for chunks.
-	tempDir, err := sc.tempDir.MkdirTemp("", "go-transcript-*")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp directory: %w", err)
-	}
-
-	// Extract chunks using effective duration (excluding trailing silence).
-	chunks, err := sc.extractChunks(ctx, audioPath, tempDir, cutPoints, effectiveDuration)
-	if err != nil {
-		_ = sc.files.RemoveAll(tempDir) // best-effort cleanup; original error takes precedence
-		return nil, err
-	}
-
-	return chunks, nil
+	// Chunk boundaries use the effective duration (excluding trailing silence).
+	return chunkBoundaries(cutPoints, effectiveDuration), nil
 }
 
 // trimTrailingSilence returns an effective end duration excluding trailing silence.
@@ -571,6 +753,13 @@ func (sc *SilenceChunker) detectSilences(ctx context.Context, audioPath string)
 	return silences, duration, nil
 }
 
+// Silencedetect log patterns - tolerant of format variations.
+// They are also followed while recording, to stop on silence.
+var (
+	silenceStartPattern = regexp.MustCompile(`silence_start:\s*([\d.]+)`)
+	silenceEndPattern   = regexp.MustCompile(`silence_end:\s*([\d.]+)`)
+)
+
 // parseSilenceOutput extracts silence points from FFmpeg silencedetect output.
 // FFmpeg outputs lines like:
 //
@@ -581,19 +770,15 @@ func parseSilenceOutput(output string) []silencePoint {
 	var currentStart time.Duration
 	hasStart := false
 
-	// Regex patterns - tolerant of format variations.
-	startRe := regexp.MustCompile(`silence_start:\s*([\d.]+)`)
-	endRe := regexp.MustCompile(`silence_end:\s*([\d.]+)`)
-
 	for line := range strings.SplitSeq(output, "\n") {
-		if matches := startRe.FindStringSubmatch(line); matches != nil {
+		if matches := silenceStartPattern.FindStringSubmatch(line); matches != nil {
 			seconds, err := strconv.ParseFloat(matches[1], 64)
 			if err == nil {
 				currentStart = time.Duration(seconds * float64(time.Second))
 				hasStart = true
 			}
 		}
-		if matches := endRe.FindStringSubmatch(line); matches != nil && hasStart {
+		if matches := silenceEndPattern.FindStringSubmatch(line); matches != nil && hasStart {
 			seconds, err := strconv.ParseFloat(matches[1], 64)
 			if err == nil {
 				silences = append(silences, silencePoint{
@@ -652,11 +837,9 @@ func (sc *SilenceChunker) selectCutPoints(silences []silencePoint, bytesPerSecon
 	return cutPoints
 }
 
-// extractChunks creates chunk files at the specified cut points.
-// If extraction fails partway through, already-created chunk files are cleaned up.
+// chunkBoundaries returns the chunks between the cut points.
 // Segments exceeding defaultMaxChunkDuration are automatically subdivided.
-// Each chunk (except the first) starts with a small overlap to capture words at boundaries.
-func (sc *SilenceChunker) extractChunks(ctx context.Context, audioPath, tempDir string, cutPoints []time.Duration, totalDuration time.Duration) ([]Chunk, error) {
+func chunkBoundaries(cutPoints []time.Duration, totalDuration time.Duration) []Chunk {
 	// Build segment boundaries: [0, cut1, cut2, ..., totalDuration].
 	boundaries := make([]time.Duration, 0, len(cutPoints)+2)
 	boundaries = append(boundaries, 0)
@@ -669,33 +852,65 @@ func (sc *SilenceChunker) extractChunks(ctx context.Context, audioPath, tempDir
 
 	chunks := make([]Chunk, 0, len(boundaries)-1)
 	for i := range len(boundaries) - 1 {
-		start := boundaries[i]
-		end := boundaries[i+1]
+		chunks = append(chunks, Chunk{
+			Index:     i,
+			StartTime: boundaries[i], // Logical start (for ordering), not extract start
+			EndTime:   boundaries[i+1],
+		})
+	}
+	return chunks
+}
 
-		// Apply overlap: start each chunk (except first) slightly earlier.
-		// This ensures words at boundaries are captured in at least one chunk.
-		extractStart := start
-		if i > 0 && start >= defaultSilenceChunkerOverlap {
-			extractStart = start - defaultSilenceChunkerOverlap
-		}
+// extractChunks creates the chunk files of a run in tempDir and sets their paths,
+// running up to workers FFmpeg processes at once (0 means one per CPU).
+// Chunks keep their order whatever order they are extracted in. If extraction
+// fails or ctx is canceled, the extractions left are not started, those running
+// are stopped, and already-created chunk files are cleaned up.
+// Each chunk (except the first) starts with a small overlap to capture words at boundaries.
+// Chunks of audio already in the chunk encoding are copied rather than re-encoded.
+func extractChunks(ctx context.Context, cmd commandRunner, files fileRemover, ffmpegPath, audioPath, tempDir, runID string, workers int, chunks []Chunk) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	streamCopy := probeStreamCopy(ctx, cmd, ffmpegPath, audioPath)
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(workers)
+
+	for i := range chunks {
+		g.Go(func() error {
+			if err := gctx.Err(); err != nil {
+				return err
+			}
 
-		chunkPath := filepath.Join(tempDir, fmt.Sprintf("chunk_%03d.ogg", i))
-		if err := sc.extractChunk(ctx, audioPath, chunkPath, extractStart, end); err != nil {
-			for _, c := range chunks {
-				_ = sc.files.Remove(c.Path) // best-effort cleanup; original error takes precedence
+			// Apply overlap: start each chunk (except first) slightly earlier.
+			// This ensures words at boundaries are captured in at least one chunk.
+			extractStart := chunks[i].StartTime
+			if i > 0 && extractStart >= defaultSilenceChunkerOverlap {
+				extractStart -= defaultSilenceChunkerOverlap
 			}
-			return nil, err
-		}
 
-		chunks = append(chunks, Chunk{
-			Path:      chunkPath,
-			Index:     i,
-			StartTime: start, // Logical start (for ordering), not extract start
-			EndTime:   end,
+			chunkPath := filepath.Join(tempDir, fmt.Sprintf("chunk_%03d.ogg", i))
+			start := time.Now()
+			if err := runExtractChunk(gctx, cmd, ffmpegPath, audioPath, chunkPath, extractStart, chunks[i].EndTime, streamCopy); err != nil {
+				return err
+			}
+			// Each goroutine writes its own chunk only.
+			chunks[i].Path = chunkPath
+			chunks[i].RunID = runID
+			chunks[i].Extraction = time.Since(start)
+			return nil
 		})
 	}
 
-	return chunks, nil
+	if err := g.Wait(); err != nil {
+		for _, c := range chunks {
+			if c.Path != "" {
+				_ = files.Remove(c.Path) // best-effort cleanup; original error takes precedence
+			}
+		}
+		return err
+	}
+	return nil
 }
 
 // expandBoundariesForDuration subdivides segments that exceed maxDuration.
@@ -730,31 +945,55 @@ func expandBoundariesForDuration(boundaries []time.Duration, maxDuration time.Du
 	return expanded
 }
 
-// extractChunk extracts a segment from audioPath to chunkPath.
-// Re-encodes to OGG Opus to ensure valid output even from corrupted/truncated sources.
-func (sc *SilenceChunker) extractChunk(ctx context.Context, audioPath, chunkPath string, start, end time.Duration) error {
-	return runExtractChunk(ctx, sc.cmd, sc.ffmpegPath, audioPath, chunkPath, start, end)
+// chunkDirPrefix is the name prefix of the temp directories holding chunks.
+const chunkDirPrefix = "go-transcript-"
+
+// NewRunID returns a random ID naming the temp directories of a run, so that
+// runs executing concurrently on the same machine never share a directory and
+// CleanupChunks of one run never removes the chunks of another.
+func NewRunID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b) // never returns an error
+	return hex.EncodeToString(b)
 }
 
-// CleanupChunks removes all chunk files and their parent directory.
-// Call this after transcription is complete.
-func CleanupChunks(chunks []Chunk) error {
-	if len(chunks) == 0 {
-		return nil
-	}
+// chunkDirPattern returns the os.MkdirTemp pattern of the chunk directories of a run.
+func chunkDirPattern(runID string) string {
+	return chunkDirPrefix + runID + "-*"
+}
 
-	// All chunks should be in the same temp directory.
-	tempDir := filepath.Dir(chunks[0].Path)
+// isChunkDir reports whether dir is a chunk directory created by the run runID.
+func isChunkDir(dir, runID string) bool {
+	return runID != "" && strings.HasPrefix(filepath.Base(dir), chunkDirPrefix+runID+"-")
+}
 
-	// Verify it's a temp directory before removing.
-	if !strings.Contains(tempDir, "go-transcript-") {
-		// Safety check: don't delete arbitrary directories.
-		// Fall back to removing individual files.
-		for _, chunk := range chunks {
+// CleanupChunks removes all chunk files and their parent directories.
+// Chunks may come from several chunkers (e.g. one per piece of a file cut
+// around a repeated intro), each with its own temp directory.
+// Only directories created for the run of each chunk are removed, so the
+// chunks of other runs are left alone.
+// Call this after transcription is complete.
+func CleanupChunks(chunks []Chunk) error {
+	var firstErr error
+	removed := make(map[string]bool)
+	for _, chunk := range chunks {
+		tempDir := filepath.Dir(chunk.Path)
+
+		// Verify it's a temp directory of the chunk's run before removing.
+		if !isChunkDir(tempDir, chunk.RunID) {
+			// Safety check: don't delete arbitrary directories.
+			// Fall back to removing the individual file.
 			_ = os.Remove(chunk.Path) // best-effort cleanup; files may already be gone
+			continue
 		}
-		return nil
-	}
 
-	return os.RemoveAll(tempDir)
+		if removed[tempDir] {
+			continue
+		}
+		removed[tempDir] = true
+		if err := os.RemoveAll(tempDir); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
 }
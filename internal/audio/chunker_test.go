@@ -9,13 +9,18 @@ package audio_test
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/ffmpeg"
 )
 
 // ---------------------------------------------------------------------------
@@ -226,6 +231,34 @@ func TestCleanupChunks(t *testing.T) {
 			t.Errorf("CleanupChunks([]) = %v, want nil", err)
 		}
 	})
+
+	t.Run("removes every temp directory", func(t *testing.T) {
+		t.Parallel()
+
+		var chunks []audio.Chunk
+		var dirs []string
+		for range 2 {
+			dir, err := os.MkdirTemp(t.TempDir(), "go-transcript-chunks-*")
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, "chunk_000.ogg")
+			if err := os.WriteFile(path, []byte("audio"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			dirs = append(dirs, dir)
+			chunks = append(chunks, audio.Chunk{Path: path, RunID: "chunks"})
+		}
+
+		if err := audio.CleanupChunks(chunks); err != nil {
+			t.Fatalf("CleanupChunks() error: %v", err)
+		}
+		for _, dir := range dirs {
+			if _, err := os.Stat(dir); !os.IsNotExist(err) {
+				t.Errorf("directory %s still exists", dir)
+			}
+		}
+	})
 }
 
 // ---------------------------------------------------------------------------
@@ -734,6 +767,218 @@ func TestChunkEncodingArgs(t *testing.T) {
 	}
 }
 
+func TestCanStreamCopy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		output    string
+		inputRate int
+		want      bool
+	}{
+		{
+			name:      "ogg opus mono",
+			output:    "Input #0, ogg, from 'rec.ogg':\n  Duration: 00:10:00.00, start: 0.000000, bitrate: 49 kb/s\n  Stream #0:0: Audio: opus, 48000 Hz, mono, fltp\n",
+			inputRate: 16000,
+			want:      true,
+		},
+		{
+			name:      "stream with language and codec tag",
+			output:    "Input #0, ogg, from 'rec.ogg':\n  Duration: 00:10:00.00, start: 0.000000, bitrate: 50 kb/s\n  Stream #0:0(eng): Audio: opus (Opus), 48000 Hz, mono, fltp\n",
+			inputRate: 16000,
+			want:      true,
+		},
+		{
+			name:      "high bitrate",
+			output:    "Input #0, ogg, from 'voice.ogg':\n  Duration: 00:10:00.00, start: 0.000000, bitrate: 128 kb/s\n  Stream #0:0: Audio: opus, 48000 Hz, mono, fltp\n",
+			inputRate: 16000,
+			want:      false,
+		},
+		{
+			name:      "unknown bitrate",
+			output:    "Input #0, ogg, from 'rec.ogg':\n  Duration: 00:10:00.00\n  Stream #0:0: Audio: opus, 48000 Hz, mono, fltp\n",
+			inputRate: 16000,
+			want:      false,
+		},
+		{
+			name:      "encoded from 48kHz",
+			output:    "Input #0, ogg, from 'voice.ogg':\n  Duration: 00:10:00.00, start: 0.000000, bitrate: 48 kb/s\n  Stream #0:0: Audio: opus, 48000 Hz, mono, fltp\n",
+			inputRate: 48000,
+			want:      false,
+		},
+		{
+			name:      "no opus header",
+			output:    "Input #0, ogg, from 'rec.ogg':\n  Duration: 00:10:00.00, start: 0.000000, bitrate: 49 kb/s\n  Stream #0:0: Audio: opus, 48000 Hz, mono, fltp\n",
+			inputRate: 0,
+			want:      false,
+		},
+		{
+			name:      "stereo",
+			output:    "Input #0, ogg, from 'music.ogg':\n  Duration: 00:10:00.00, start: 0.000000, bitrate: 49 kb/s\n  Stream #0:0: Audio: opus, 48000 Hz, stereo, fltp\n",
+			inputRate: 16000,
+			want:      false,
+		},
+		{
+			name:      "vorbis",
+			output:    "Input #0, ogg, from 'old.ogg':\n  Duration: 00:10:00.00, start: 0.000000, bitrate: 49 kb/s\n  Stream #0:0: Audio: vorbis, 16000 Hz, mono, fltp, 48 kb/s\n",
+			inputRate: 16000,
+			want:      false,
+		},
+		{
+			name:      "opus in another container",
+			output:    "Input #0, matroska,webm, from 'call.webm':\n  Duration: 00:10:00.00, start: 0.000000, bitrate: 49 kb/s\n  Stream #0:0: Audio: opus, 48000 Hz, mono, fltp\n",
+			inputRate: 16000,
+			want:      false,
+		},
+		{
+			name:      "several audio streams",
+			output:    "Input #0, ogg, from 'two.ogg':\n  Duration: 00:10:00.00, start: 0.000000, bitrate: 49 kb/s\n  Stream #0:0: Audio: opus, 48000 Hz, mono, fltp\n  Stream #0:1: Audio: opus, 48000 Hz, mono, fltp\n",
+			inputRate: 16000,
+			want:      false,
+		},
+		{
+			name:      "no output",
+			output:    "",
+			inputRate: 16000,
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := audio.CanStreamCopy(ffmpeg.ParseInputDescription(tt.output), tt.inputRate); got != tt.want {
+			t.Errorf("%s: CanStreamCopy() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOpusInputRate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if got := audio.OpusInputRate(writeOpusFile(t, dir, "rec.ogg", 16000)); got != 16000 {
+		t.Errorf("OpusInputRate(16kHz) = %d, want 16000", got)
+	}
+	if got := audio.OpusInputRate(writeOpusFile(t, dir, "voice.ogg", 48000)); got != 48000 {
+		t.Errorf("OpusInputRate(48kHz) = %d, want 48000", got)
+	}
+
+	vorbis := filepath.Join(dir, "old.ogg")
+	if err := os.WriteFile(vorbis, []byte("OggS\x00\x02\x01vorbis"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := audio.OpusInputRate(vorbis); got != 0 {
+		t.Errorf("OpusInputRate(vorbis) = %d, want 0", got)
+	}
+	if got := audio.OpusInputRate(filepath.Join(dir, "missing.ogg")); got != 0 {
+		t.Errorf("OpusInputRate(missing) = %d, want 0", got)
+	}
+}
+
+// writeOpusFile writes the start of an OGG Opus file encoded from rate: the
+// first page header and the OpusHead packet.
+func writeOpusFile(t *testing.T, dir, name string, rate uint32) string {
+	t.Helper()
+	data := []byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x13")
+	data = append(data, "OpusHead\x01\x01\x38\x01"...)
+	data = binary.LittleEndian.AppendUint32(data, rate)
+	data = append(data, 0, 0, 0)
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTimeChunker_StreamCopy(t *testing.T) {
+	t.Parallel()
+
+	// probe answers the duration and header probes of an OGG Opus mono recording.
+	probe := []byte("Input #0, ogg, from 'rec.ogg':\n  Duration: 00:01:00.00, start: 0.000000, bitrate: 49 kb/s\n" +
+		"  Stream #0:0: Audio: opus, 48000 Hz, mono, fltp\ntime=00:01:00.00")
+
+	t.Run("copies chunks of compatible input", func(t *testing.T) {
+		t.Parallel()
+
+		mockCmd := &mockCommandRunner{
+			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+				return probe, nil
+			},
+		}
+		tc, _ := audio.NewTimeChunker("/usr/bin/ffmpeg", 30*time.Second, 5*time.Second,
+			audio.WithTimeChunkerCommandRunner(mockCmd),
+			audio.WithTimeChunkerTempDir(&mockTempDirCreator{dir: t.TempDir()}))
+
+		if _, err := tc.Chunk(context.Background(), writeOpusFile(t, t.TempDir(), "rec.ogg", 16000)); err != nil {
+			t.Fatalf("Chunk() error = %v", err)
+		}
+		for _, c := range mockCmd.calls {
+			if contains(c.args, "-ss") && (!contains(c.args, "copy") || contains(c.args, "libopus")) {
+				t.Errorf("extraction args = %v, want a stream copy", c.args)
+			}
+		}
+	})
+
+	t.Run("re-encodes high-bitrate input", func(t *testing.T) {
+		t.Parallel()
+
+		mockCmd := &mockCommandRunner{
+			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+				return []byte(strings.Replace(string(probe), "49 kb/s", "128 kb/s", 1)), nil
+			},
+		}
+		tc, _ := audio.NewTimeChunker("/usr/bin/ffmpeg", 30*time.Second, 5*time.Second,
+			audio.WithTimeChunkerCommandRunner(mockCmd),
+			audio.WithTimeChunkerTempDir(&mockTempDirCreator{dir: t.TempDir()}))
+
+		chunks, err := tc.Chunk(context.Background(), writeOpusFile(t, t.TempDir(), "voice.ogg", 16000))
+		if err != nil {
+			t.Fatalf("Chunk() error = %v", err)
+		}
+		var encoded int
+		for _, c := range mockCmd.calls {
+			if contains(c.args, "copy") {
+				t.Errorf("extraction args = %v, want a re-encoding", c.args)
+			}
+			if contains(c.args, "libopus") {
+				encoded++
+			}
+		}
+		if encoded != len(chunks) {
+			t.Errorf("re-encoded %d chunks, want %d", encoded, len(chunks))
+		}
+	})
+
+	t.Run("re-encodes when copying fails", func(t *testing.T) {
+		t.Parallel()
+
+		mockCmd := &mockCommandRunner{
+			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+				if contains(args, "copy") {
+					return []byte("Invalid data found when processing input"), errors.New("exit status 1")
+				}
+				return probe, nil
+			},
+		}
+		tc, _ := audio.NewTimeChunker("/usr/bin/ffmpeg", 30*time.Second, 5*time.Second,
+			audio.WithTimeChunkerCommandRunner(mockCmd),
+			audio.WithTimeChunkerTempDir(&mockTempDirCreator{dir: t.TempDir()}))
+
+		chunks, err := tc.Chunk(context.Background(), writeOpusFile(t, t.TempDir(), "truncated.ogg", 16000))
+		if err != nil {
+			t.Fatalf("Chunk() error = %v", err)
+		}
+		var encoded int
+		for _, c := range mockCmd.calls {
+			if contains(c.args, "libopus") {
+				encoded++
+			}
+		}
+		if encoded != len(chunks) {
+			t.Errorf("re-encoded %d chunks, want %d", encoded, len(chunks))
+		}
+	})
+}
+
 // ---------------------------------------------------------------------------
 // TimeChunker.Chunk - Integration with mocks
 // ---------------------------------------------------------------------------
@@ -856,12 +1101,10 @@ func TestSilenceChunker_Chunk(t *testing.T) {
 	t.Run("successful chunking with silences", func(t *testing.T) {
 		t.Parallel()
 
-		callCount := 0
 		mockCmd := &mockCommandRunner{
 			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
-				callCount++
-				// First call: detectSilences
-				if callCount == 1 {
+				// detectSilences (chunks may then be extracted concurrently)
+				if !contains(args, "-ss") {
 					return []byte(`Duration: 00:05:00.00
 [silencedetect @ 0x7f8] silence_start: 60.0
 [silencedetect @ 0x7f8] silence_end: 62.0 | silence_duration: 2.0
@@ -980,6 +1223,207 @@ time=00:05:00.00`), nil
 	})
 }
 
+func TestSilenceChunker_ParallelExtraction(t *testing.T) {
+	t.Parallel()
+
+	// silencesEvery50s is the silencedetect output of 6 minutes of audio with a
+	// pause every 50 seconds: 1MB chunks of a 6MB file are cut at each one.
+	var out strings.Builder
+	out.WriteString("Duration: 00:06:00.00\n")
+	for sec := 50; sec < 360; sec += 50 {
+		fmt.Fprintf(&out, "[silencedetect @ 0x1] silence_start: %d.0\n", sec)
+		fmt.Fprintf(&out, "[silencedetect @ 0x1] silence_end: %d.5 | silence_duration: 0.5\n", sec)
+	}
+	silencesEvery50s := []byte(out.String())
+
+	newChunker := func(t *testing.T, workers int, extract func(ctx context.Context, chunkPath string) error) *audio.SilenceChunker {
+		t.Helper()
+		mockCmd := &mockCommandRunner{
+			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+				if !contains(args, "-ss") {
+					return silencesEvery50s, nil
+				}
+				return nil, extract(ctx, args[len(args)-1])
+			},
+		}
+		sc, err := audio.NewSilenceChunker("/usr/bin/ffmpeg",
+			audio.WithCommandRunner(mockCmd),
+			audio.WithTempDirCreator(&mockTempDirCreator{dir: t.TempDir()}),
+			audio.WithFileRemover(&mockFileRemover{}),
+			audio.WithFileStatter(&mockFileStatter{size: 6 * 1024 * 1024}),
+			audio.WithMaxChunkSize(1024*1024),
+			audio.WithExtractWorkers(workers),
+		)
+		if err != nil {
+			t.Fatalf("NewSilenceChunker() error = %v", err)
+		}
+		return sc
+	}
+
+	t.Run("bounded workers keep chunk order", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			mu               sync.Mutex
+			inFlight, maxRun int
+		)
+		sc := newChunker(t, 3, func(ctx context.Context, chunkPath string) error {
+			mu.Lock()
+			inFlight++
+			maxRun = max(maxRun, inFlight)
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return os.WriteFile(chunkPath, nil, 0600)
+		})
+
+		chunks, err := sc.Chunk(context.Background(), "/fake/audio.ogg")
+		if err != nil {
+			t.Fatalf("Chunk() error = %v", err)
+		}
+		if len(chunks) < 4 {
+			t.Fatalf("Chunk() = %v, want at least 4 chunks", chunks)
+		}
+		for i, c := range chunks {
+			if c.Index != i || filepath.Base(c.Path) != fmt.Sprintf("chunk_%03d.ogg", i) {
+				t.Errorf("chunk %d = %+v, want index %d and its own file", i, c, i)
+			}
+			if i > 0 && c.StartTime != chunks[i-1].EndTime {
+				t.Errorf("chunk %d starts at %v, want %v", i, c.StartTime, chunks[i-1].EndTime)
+			}
+		}
+		if maxRun > 3 || maxRun < 2 {
+			t.Errorf("extractions running at once = %d, want 2 to 3", maxRun)
+		}
+	})
+
+	t.Run("canceled context stops the extractions left", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var extractions int
+		sc := newChunker(t, 1, func(ctx context.Context, chunkPath string) error {
+			extractions++
+			cancel()
+			return os.WriteFile(chunkPath, nil, 0600)
+		})
+
+		if _, err := sc.Chunk(ctx, "/fake/audio.ogg"); !errors.Is(err, context.Canceled) {
+			t.Errorf("Chunk() error = %v, want context.Canceled", err)
+		}
+		if extractions != 1 {
+			t.Errorf("extractions = %d, want 1 (none after cancellation)", extractions)
+		}
+	})
+
+	t.Run("failed extraction", func(t *testing.T) {
+		t.Parallel()
+
+		sc := newChunker(t, 2, func(ctx context.Context, chunkPath string) error {
+			if strings.HasSuffix(chunkPath, "chunk_002.ogg") {
+				return errors.New("exit status 1")
+			}
+			return os.WriteFile(chunkPath, nil, 0600)
+		})
+
+		if _, err := sc.Chunk(context.Background(), "/fake/audio.ogg"); !errors.Is(err, audio.ErrChunkingFailed) {
+			t.Errorf("Chunk() error = %v, want ErrChunkingFailed", err)
+		}
+	})
+}
+
+func TestSilenceChunker_Plan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("plans chunks at silences without extracting", func(t *testing.T) {
+		t.Parallel()
+
+		mockCmd := &mockCommandRunner{
+			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+				return []byte(`Duration: 00:08:00.00
+[silencedetect @ 0x7f8] silence_start: 239.0
+[silencedetect @ 0x7f8] silence_end: 241.0 | silence_duration: 2.0
+time=00:08:00.00`), nil
+			},
+		}
+		// Planning must not create chunk files.
+		mockTempDir := &mockTempDirCreator{err: errors.New("unexpected temp directory")}
+
+		sc, err := audio.NewSilenceChunker(
+			"/usr/bin/ffmpeg",
+			audio.WithCommandRunner(mockCmd),
+			audio.WithTempDirCreator(mockTempDir),
+			audio.WithFileStatter(&mockFileStatter{size: 10 * 1024 * 1024}),
+			audio.WithMaxChunkSize(6*1024*1024), // ~4.8 minutes at this bitrate
+		)
+		if err != nil {
+			t.Fatalf("NewSilenceChunker() error = %v", err)
+		}
+
+		chunks, err := sc.Plan(context.Background(), "/fake/audio.ogg")
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+
+		want := []audio.Chunk{
+			{Index: 0, StartTime: 0, EndTime: 240 * time.Second},
+			{Index: 1, StartTime: 240 * time.Second, EndTime: 8 * time.Minute},
+		}
+		if len(chunks) != len(want) {
+			t.Fatalf("Plan() = %v, want %v", chunks, want)
+		}
+		for i := range want {
+			if chunks[i] != want[i] {
+				t.Errorf("Plan()[%d] = %+v, want %+v", i, chunks[i], want[i])
+			}
+		}
+		if len(mockCmd.calls) != 1 {
+			t.Errorf("Plan() ran ffmpeg %d times, want 1 (no extraction)", len(mockCmd.calls))
+		}
+	})
+
+	t.Run("no silences plans with the fallback", func(t *testing.T) {
+		t.Parallel()
+
+		mockCmd := &mockCommandRunner{
+			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+				return []byte("Duration: 00:15:00.00\ntime=00:15:00.00"), nil
+			},
+		}
+		fallback, err := audio.NewTimeChunker("/usr/bin/ffmpeg", 10*time.Minute, 30*time.Second,
+			audio.WithTimeChunkerCommandRunner(mockCmd))
+		if err != nil {
+			t.Fatalf("NewTimeChunker() error = %v", err)
+		}
+		sc, err := audio.NewSilenceChunker(
+			"/usr/bin/ffmpeg",
+			audio.WithCommandRunner(mockCmd),
+			audio.WithFileStatter(&mockFileStatter{size: 5 * 1024 * 1024}),
+			audio.WithFallback(fallback),
+			audio.WithWarnFunc(nil),
+		)
+		if err != nil {
+			t.Fatalf("NewSilenceChunker() error = %v", err)
+		}
+
+		chunks, err := sc.Plan(context.Background(), "/fake/audio.ogg")
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+		if len(chunks) != 2 || chunks[1].StartTime != 570*time.Second || chunks[1].EndTime != 15*time.Minute {
+			t.Errorf("Plan() = %+v, want 10m chunks with 30s overlap", chunks)
+		}
+		for _, c := range chunks {
+			if c.Path != "" {
+				t.Errorf("Plan() chunk %d has path %q, want none", c.Index, c.Path)
+			}
+		}
+	})
+}
+
 // ---------------------------------------------------------------------------
 // SilenceChunker options
 // ---------------------------------------------------------------------------
@@ -1011,6 +1455,14 @@ func TestSilenceChunkerOptions(t *testing.T) {
 		}
 	})
 
+	t.Run("WithExtractWorkers", func(t *testing.T) {
+		t.Parallel()
+		_, err := audio.NewSilenceChunker("/usr/bin/ffmpeg", audio.WithExtractWorkers(4))
+		if err != nil {
+			t.Errorf("WithExtractWorkers() caused error = %v", err)
+		}
+	})
+
 	t.Run("WithFallback", func(t *testing.T) {
 		t.Parallel()
 		fallback, _ := audio.NewTimeChunker("/usr/bin/ffmpeg", 5*time.Minute, 30*time.Second)
@@ -1042,6 +1494,119 @@ func TestCleanupChunks_SafetyCheck(t *testing.T) {
 		// Error is expected since files don't exist, but no panic
 		_ = err
 	})
+
+	t.Run("directory of another run is kept", func(t *testing.T) {
+		t.Parallel()
+
+		dirs := []string{
+			filepath.Join(t.TempDir(), "go-transcript-other-123"),  // Another run
+			filepath.Join(t.TempDir(), "go-transcript-pieces-456"), // Not a chunk directory
+			filepath.Join(t.TempDir(), "go-transcript-run-1"),      // Chunk without run
+		}
+		runIDs := []string{"run", "pieces-x", ""}
+		for i, dir := range dirs {
+			if err := os.Mkdir(dir, 0750); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, "chunk_000.ogg")
+			if err := os.WriteFile(path, []byte("audio"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := audio.CleanupChunks([]audio.Chunk{{Path: path, RunID: runIDs[i]}}); err != nil {
+				t.Fatalf("CleanupChunks() error: %v", err)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("chunk file %s still exists", path)
+			}
+			if _, err := os.Stat(dir); err != nil {
+				t.Errorf("directory %s removed: %v", dir, err)
+			}
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// Concurrent runs - Temp directory collisions
+// ---------------------------------------------------------------------------
+
+func TestTimeChunker_ConcurrentRuns(t *testing.T) {
+	t.Parallel()
+
+	const runs = 8
+	root := t.TempDir()
+	runIDs := make([]string, runs)
+	results := make([][]audio.Chunk, runs)
+	errs := make([]error, runs)
+
+	var wg sync.WaitGroup
+	for i := range runs {
+		runIDs[i] = audio.NewRunID()
+		wg.Go(func() {
+			tc, err := audio.NewTimeChunker("/usr/bin/ffmpeg", 30*time.Second, 5*time.Second,
+				audio.WithTimeChunkerCommandRunner(&mockCommandRunner{outputFunc: fakeFFmpeg(2 * time.Minute)}),
+				audio.WithTimeChunkerTempDir(rootTempDirCreator(root)),
+				audio.WithTimeChunkerRunID(runIDs[i]),
+			)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			results[i], errs[i] = tc.Chunk(context.Background(), "/fake/audio.ogg")
+		})
+	}
+	wg.Wait()
+
+	// Every run writes its chunks to its own directory, named by its run ID.
+	owners := make(map[string]int)
+	for i, chunks := range results {
+		if errs[i] != nil {
+			t.Fatalf("run %d: Chunk() error = %v", i, errs[i])
+		}
+		if len(chunks) != 5 {
+			t.Fatalf("run %d: Chunk() returned %d chunks, want 5", i, len(chunks))
+		}
+		for _, c := range chunks {
+			dir := filepath.Dir(c.Path)
+			if c.RunID != runIDs[i] || !strings.HasPrefix(filepath.Base(dir), "go-transcript-"+runIDs[i]+"-") {
+				t.Errorf("run %d: chunk %s (run %q), want in a directory of run %q", i, c.Path, c.RunID, runIDs[i])
+			}
+			if owner, ok := owners[dir]; ok && owner != i {
+				t.Errorf("runs %d and %d share directory %s", owner, i, dir)
+			}
+			owners[dir] = i
+		}
+	}
+
+	// Cleaning up a run leaves the chunks of the runs still in progress.
+	for i := range runs {
+		if err := audio.CleanupChunks(results[i]); err != nil {
+			t.Fatalf("run %d: CleanupChunks() error = %v", i, err)
+		}
+		for j, chunks := range results {
+			for _, c := range chunks {
+				_, err := os.Stat(c.Path)
+				if cleaned := j <= i; cleaned != os.IsNotExist(err) {
+					t.Errorf("after cleanup of run %d: chunk %s of run %d exists = %v", i, c.Path, j, !cleaned)
+				}
+			}
+		}
+	}
+}
+
+func TestNewRunID(t *testing.T) {
+	t.Parallel()
+
+	seen := make(map[string]bool)
+	for range 100 {
+		id := audio.NewRunID()
+		if id == "" || strings.ContainsAny(id, `-*/\`) {
+			t.Fatalf("NewRunID() = %q, want a plain ID", id)
+		}
+		if seen[id] {
+			t.Fatalf("NewRunID() returned %q twice", id)
+		}
+		seen[id] = true
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -1050,7 +1615,9 @@ func TestCleanupChunks_SafetyCheck(t *testing.T) {
 
 type mockCommandRunner struct {
 	outputFunc func(ctx context.Context, name string, args []string) ([]byte, error)
-	calls      []mockCall
+
+	mu    sync.Mutex // Chunks may be extracted concurrently
+	calls []mockCall
 }
 
 type mockCall struct {
@@ -1059,7 +1626,9 @@ type mockCall struct {
 }
 
 func (m *mockCommandRunner) CombinedOutput(ctx context.Context, name string, args []string) ([]byte, error) {
+	m.mu.Lock()
 	m.calls = append(m.calls, mockCall{name: name, args: args})
+	m.mu.Unlock()
 	if m.outputFunc != nil {
 		return m.outputFunc(ctx, name, args)
 	}
@@ -1078,6 +1647,29 @@ func (m *mockTempDirCreator) MkdirTemp(dir, pattern string) (string, error) {
 	return m.dir, nil
 }
 
+// rootTempDirCreator creates temp directories in the directory it names.
+type rootTempDirCreator string
+
+func (r rootTempDirCreator) MkdirTemp(_, pattern string) (string, error) {
+	return os.MkdirTemp(string(r), pattern)
+}
+
+// fakeFFmpeg returns the output of FFmpeg for an audio file of duration:
+// the file info when probed, and an empty chunk file when extracting.
+func fakeFFmpeg(duration time.Duration) func(ctx context.Context, name string, args []string) ([]byte, error) {
+	return func(ctx context.Context, name string, args []string) ([]byte, error) {
+		if !contains(args, "-ss") {
+			return []byte(fmt.Sprintf("Duration: %s.00, start: 0.000000", formatClock(duration))), nil
+		}
+		return nil, os.WriteFile(args[len(args)-1], nil, 0600)
+	}
+}
+
+// formatClock formats d as HH:MM:SS.
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
 type mockFileRemover struct {
 	removeErr    error
 	removeAllErr error
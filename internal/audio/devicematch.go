@@ -0,0 +1,49 @@
+package audio
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MatchDevice returns the identifier of the device designated by query among
+// devices, as listed by a DeviceLister. query is the identifier or full name
+// of a device, or part of its name, case-insensitive: "yeti" matches
+// "Yeti Stereo Microphone". name is the name of the matched device.
+//
+// A query matching no device is returned unchanged with an empty name:
+// FFmpeg accepts devices it does not list (hw:1,0 on Linux), and reports the
+// others. A query matching several devices returns ErrAmbiguousDevice.
+func MatchDevice(devices []string, query string) (id, name string, err error) {
+	var matches []string
+	for _, entry := range devices {
+		entryID, entryName := splitDeviceEntry(entry)
+		if strings.EqualFold(entryID, query) || strings.EqualFold(entryName, query) {
+			return entryID, entryName, nil
+		}
+		if strings.Contains(strings.ToLower(entryName), strings.ToLower(query)) {
+			matches = append(matches, entry)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return query, "", nil
+	case 1:
+		id, name = splitDeviceEntry(matches[0])
+		return id, name, nil
+	}
+	names := make([]string, len(matches))
+	for i, entry := range matches {
+		_, names[i] = splitDeviceEntry(entry)
+	}
+	return "", "", fmt.Errorf("%w: %q matches %s, use a longer part of the name", ErrAmbiguousDevice, query, strings.Join(names, ", "))
+}
+
+// splitDeviceEntry splits a listed device into its identifier and name.
+// macOS lists ":index<TAB>name"; elsewhere the name is the identifier.
+func splitDeviceEntry(entry string) (id, name string) {
+	if id, name, ok := strings.Cut(entry, "\t"); ok {
+		return id, name
+	}
+	return entry, entry
+}
@@ -0,0 +1,57 @@
+package audio_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+func TestMatchDevice(t *testing.T) {
+	t.Parallel()
+
+	macOS := []string{":1\tYeti Stereo Microphone", ":0\tMacBook Pro Microphone", ":2\tBlackHole 2ch"}
+	windows := []string{"Microphone (Yeti Stereo Microphone)", "Microphone (Realtek High Definition Audio)"}
+
+	tests := []struct {
+		name     string
+		devices  []string
+		query    string
+		wantID   string
+		wantName string
+		wantErr  error
+	}{
+		{"part of a name", macOS, "yeti", ":1", "Yeti Stereo Microphone", nil},
+		{"full name", macOS, "macbook pro microphone", ":0", "MacBook Pro Microphone", nil},
+		{"identifier", macOS, ":2", ":2", "BlackHole 2ch", nil},
+		{"name is the identifier", windows, "realtek", "Microphone (Realtek High Definition Audio)", "Microphone (Realtek High Definition Audio)", nil},
+		{"exact name among partial matches", []string{"hw:0", "hw:0,1"}, "hw:0", "hw:0", "hw:0", nil},
+		{"no match is passed as is", macOS, "hw:1,0", "hw:1,0", "", nil},
+		{"no devices listed", nil, "yeti", "yeti", "", nil},
+		{"several matches", windows, "microphone", "", "", audio.ErrAmbiguousDevice},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			id, name, err := audio.MatchDevice(tt.devices, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MatchDevice(%q) error = %v, want %v", tt.query, err, tt.wantErr)
+			}
+			if id != tt.wantID || name != tt.wantName {
+				t.Errorf("MatchDevice(%q) = (%q, %q), want (%q, %q)", tt.query, id, name, tt.wantID, tt.wantName)
+			}
+		})
+	}
+
+	t.Run("lists the matching devices", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := audio.MatchDevice(windows, "microphone")
+		if err == nil || !strings.Contains(err.Error(), "Microphone (Yeti Stereo Microphone), Microphone (Realtek High Definition Audio)") {
+			t.Errorf("MatchDevice() error = %v, want the matching devices listed", err)
+		}
+	})
+}
@@ -0,0 +1,336 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/ffmpeg"
+)
+
+// Energy-based chunking parameters.
+const (
+	// energyFrame is the length of the frames whose loudness is measured.
+	energyFrame = 100 * time.Millisecond
+
+	// energyFrameSamples is the number of samples of a frame, at the 16kHz
+	// the audio is resampled to before measuring.
+	energyFrameSamples = 1600
+
+	// quietWindow is the length of the quietest window cuts are made in:
+	// as long as the shortest silence SilenceChunker detects, about the
+	// length of a pause between words.
+	quietWindow = defaultMinSilence
+
+	// quietSearchShare is the share of the chunk duration searched before
+	// each chunk boundary: 1/5 of 5 minutes is the last minute.
+	quietSearchShare = 5
+
+	// energyFloor is the loudness given to digital silence (-inf dB),
+	// so that levels can be averaged.
+	energyFloor = -120.0
+)
+
+// EnergyChunker splits audio at its quietest moments, a light voice activity
+// detection: near each chunk boundary, it cuts in the middle of the window of
+// lowest loudness, measured with FFmpeg. It is the fallback of SilenceChunker
+// when no silence is detected, for instance over continuous background noise,
+// where cuts at fixed times would fall mid-word.
+// Falls back to TimeChunker if the loudness cannot be measured.
+type EnergyChunker struct {
+	ffmpegPath   string
+	maxChunkSize int64
+	fallback     Chunker
+	warn         WarnFunc
+	runID        string
+	workers      int // Chunks extracted at once; 0 means one per CPU
+
+	// Injectable dependencies (defaults to OS implementations).
+	cmd     commandRunner
+	tempDir tempDirCreator
+	files   fileRemover
+	statter fileStatter
+}
+
+// EnergyChunkerOption configures an EnergyChunker.
+type EnergyChunkerOption func(*EnergyChunker)
+
+// WithEnergyChunkerMaxChunkSize sets the target maximum chunk size in bytes.
+// Default: 20MB, like SilenceChunker.
+func WithEnergyChunkerMaxChunkSize(size int64) EnergyChunkerOption {
+	return func(ec *EnergyChunker) {
+		ec.maxChunkSize = size
+	}
+}
+
+// WithEnergyChunkerFallback sets a custom fallback Chunker.
+// Default: TimeChunker with 10min target, 30s overlap.
+func WithEnergyChunkerFallback(c Chunker) EnergyChunkerOption {
+	return func(ec *EnergyChunker) {
+		ec.fallback = c
+	}
+}
+
+// WithEnergyChunkerWarnFunc sets a callback for warning messages.
+// By default, warnings are written to stderr. Set to nil to suppress.
+func WithEnergyChunkerWarnFunc(fn WarnFunc) EnergyChunkerOption {
+	return func(ec *EnergyChunker) {
+		ec.warn = fn
+	}
+}
+
+// WithEnergyChunkerRunID sets the run ID naming the temp directories of
+// EnergyChunker and of its default fallback.
+// Default: a new ID (see NewRunID).
+func WithEnergyChunkerRunID(id string) EnergyChunkerOption {
+	return func(ec *EnergyChunker) {
+		ec.runID = id
+	}
+}
+
+// WithEnergyChunkerExtractWorkers sets the number of chunks extracted at
+// once, each by an FFmpeg process.
+// Default: one per CPU (0).
+func WithEnergyChunkerExtractWorkers(n int) EnergyChunkerOption {
+	return func(ec *EnergyChunker) {
+		ec.workers = n
+	}
+}
+
+// WithEnergyChunkerCommandRunner sets the command runner for EnergyChunker.
+func WithEnergyChunkerCommandRunner(r commandRunner) EnergyChunkerOption {
+	return func(ec *EnergyChunker) {
+		ec.cmd = r
+	}
+}
+
+// WithEnergyChunkerTempDir sets the temp directory creator for EnergyChunker.
+func WithEnergyChunkerTempDir(t tempDirCreator) EnergyChunkerOption {
+	return func(ec *EnergyChunker) {
+		ec.tempDir = t
+	}
+}
+
+// WithEnergyChunkerFileRemover sets the file remover for EnergyChunker.
+func WithEnergyChunkerFileRemover(f fileRemover) EnergyChunkerOption {
+	return func(ec *EnergyChunker) {
+		ec.files = f
+	}
+}
+
+// WithEnergyChunkerFileStatter sets the file statter for EnergyChunker.
+func WithEnergyChunkerFileStatter(s fileStatter) EnergyChunkerOption {
+	return func(ec *EnergyChunker) {
+		ec.statter = s
+	}
+}
+
+// NewEnergyChunker creates an EnergyChunker with functional options.
+// If no fallback is provided, a default TimeChunker is created.
+func NewEnergyChunker(ffmpegPath string, opts ...EnergyChunkerOption) (*EnergyChunker, error) {
+	if ffmpegPath == "" {
+		return nil, fmt.Errorf("ffmpegPath cannot be empty: %w", ffmpeg.ErrNotFound)
+	}
+
+	ec := &EnergyChunker{
+		ffmpegPath:   ffmpegPath,
+		maxChunkSize: defaultMaxChunkSize,
+		warn:         defaultWarnFunc,
+		runID:        NewRunID(),
+		cmd:          osCommandRunner{},
+		tempDir:      osTempDirCreator{},
+		files:        osFileRemover{},
+		statter:      osFileStatter{},
+	}
+
+	for _, opt := range opts {
+		opt(ec)
+	}
+
+	// Create default fallback if not provided.
+	if ec.fallback == nil {
+		fallback, err := NewTimeChunker(ffmpegPath, defaultTargetDuration, defaultOverlap,
+			WithTimeChunkerRunID(ec.runID),
+			WithTimeChunkerCommandRunner(ec.cmd),
+			WithTimeChunkerTempDir(ec.tempDir),
+			WithTimeChunkerFileRemover(ec.files))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create fallback chunker: %w", err)
+		}
+		ec.fallback = fallback
+	}
+
+	return ec, nil
+}
+
+// Chunk splits the audio file at its quietest moments.
+// If the loudness cannot be measured, falls back to time-based chunking.
+func (ec *EnergyChunker) Chunk(ctx context.Context, audioPath string) ([]Chunk, error) {
+	chunks, err := ec.plan(ctx, audioPath)
+	if err != nil {
+		return nil, err
+	}
+	if chunks == nil {
+		return ec.fallback.Chunk(ctx, audioPath)
+	}
+
+	// Create temp directory for chunks.
+	tempDir, err := ec.tempDir.MkdirTemp("", chunkDirPattern(ec.runID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	if err := extractChunks(ctx, ec.cmd, ec.files, ec.ffmpegPath, audioPath, tempDir, ec.runID, ec.workers, chunks); err != nil {
+		_ = ec.files.RemoveAll(tempDir) // best-effort cleanup; original error takes precedence
+		return nil, err
+	}
+
+	return chunks, nil
+}
+
+// Plan returns the chunks the audio file would be split into, without
+// extracting them. If the loudness cannot be measured, plans with the
+// fallback chunker.
+func (ec *EnergyChunker) Plan(ctx context.Context, audioPath string) ([]Chunk, error) {
+	chunks, err := ec.plan(ctx, audioPath)
+	if err != nil || chunks != nil {
+		return chunks, err
+	}
+	planner, ok := ec.fallback.(Planner)
+	if !ok {
+		return nil, fmt.Errorf("fallback chunker cannot plan chunks")
+	}
+	return planner.Plan(ctx, audioPath)
+}
+
+// plan computes the chunk boundaries at the quietest moments.
+// Returns nil chunks if the fallback chunker must be used instead.
+func (ec *EnergyChunker) plan(ctx context.Context, audioPath string) ([]Chunk, error) {
+	// Get file info for bitrate estimation.
+	fileInfo, err := ec.statter.Stat(audioPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFileNotFound, err)
+	}
+
+	levels, totalDuration, err := ec.measureEnergy(ctx, audioPath)
+	if err != nil {
+		if ec.warn != nil {
+			ec.warn(fmt.Sprintf("Warning: loudness measurement failed (%v), using time-based chunking (may cut mid-sentence)", err))
+		}
+		return nil, nil
+	}
+
+	// Chunks stay under maxChunkSize, and under the duration limit of all chunks.
+	bytesPerSecond := float64(fileInfo.Size()) / totalDuration.Seconds()
+	maxDuration := time.Duration(float64(ec.maxChunkSize) / bytesPerSecond * float64(time.Second))
+	maxDuration = min(maxDuration, defaultMaxChunkDuration)
+
+	return chunkBoundaries(quietestCuts(levels, totalDuration, maxDuration), totalDuration), nil
+}
+
+// measureEnergy runs FFmpeg astats over frames of energyFrame and returns the
+// RMS level of each frame, in dB, and the total audio duration.
+func (ec *EnergyChunker) measureEnergy(ctx context.Context, audioPath string) ([]float64, time.Duration, error) {
+	args := []string{
+		"-hide_banner", "-nostats",
+		"-i", audioPath,
+		"-af", fmt.Sprintf("aresample=16000,asetnsamples=n=%d:p=0,astats=metadata=1:reset=1,ametadata=mode=print:key=%s",
+			energyFrameSamples, rmsLevelKey),
+		"-f", "null",
+		"-",
+	}
+
+	output, err := ec.cmd.CombinedOutput(ctx, ec.ffmpegPath, args)
+	if err != nil {
+		// FFmpeg may return non-zero even on success, try parsing output
+		if len(output) == 0 {
+			return nil, 0, err
+		}
+	}
+
+	outputStr := string(output)
+	levels := parseEnergyOutput(outputStr)
+	if len(levels) == 0 {
+		return nil, 0, fmt.Errorf("no loudness measurement in ffmpeg output")
+	}
+	duration, err := parseDurationFromFFmpegOutput(outputStr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not determine audio duration: %w", err)
+	}
+
+	return levels, duration, nil
+}
+
+// rmsLevelKey is the astats metadata key of the RMS level of all channels.
+const rmsLevelKey = "lavfi.astats.Overall.RMS_level"
+
+// rmsLevelPattern matches the levels printed by ametadata, one per frame:
+//
+//	[Parsed_ametadata_3 @ 0x...] frame:12   pts:19200   pts_time:1.2
+//	[Parsed_ametadata_3 @ 0x...] lavfi.astats.Overall.RMS_level=-43.210
+//
+// Digital silence is reported as "-inf".
+var rmsLevelPattern = regexp.MustCompile(regexp.QuoteMeta(rmsLevelKey) + `=(-?inf|-?[\d.]+)`)
+
+// parseEnergyOutput extracts the RMS level of each frame from FFmpeg
+// ametadata output, in order. Levels below energyFloor are raised to it.
+func parseEnergyOutput(output string) []float64 {
+	var levels []float64
+	for line := range strings.SplitSeq(output, "\n") {
+		matches := rmsLevelPattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		level, err := strconv.ParseFloat(matches[1], 64) // ParseFloat accepts "-inf"
+		if err != nil || math.IsNaN(level) {
+			continue
+		}
+		levels = append(levels, max(level, energyFloor))
+	}
+	return levels
+}
+
+// quietestCuts returns cut points at most maxDuration apart. Each is the
+// middle of the quietest window of quietWindow in the last 1/quietSearchShare
+// of the chunk before its boundary, so that chunks stay close to maxDuration.
+// levels holds the RMS level of each frame of energyFrame from the start.
+func quietestCuts(levels []float64, totalDuration, maxDuration time.Duration) []time.Duration {
+	if maxDuration <= 0 {
+		return nil
+	}
+	window := int(quietWindow / energyFrame)
+
+	// sums[i] is the sum of the levels of the frames before frame i,
+	// so that the mean level of any window takes two lookups.
+	sums := make([]float64, len(levels)+1)
+	for i, level := range levels {
+		sums[i+1] = sums[i] + level
+	}
+
+	var cuts []time.Duration
+	for lastCut := time.Duration(0); totalDuration-lastCut > maxDuration; {
+		boundary := lastCut + maxDuration
+		cut := boundary
+
+		// Windows fully inside the search span: [boundary-span, boundary].
+		first := int((boundary - maxDuration/quietSearchShare) / energyFrame)
+		last := min(int(boundary/energyFrame), len(levels)) - window
+		// On ties, the window closest to the boundary wins, so that audio of
+		// even loudness is cut in chunks as long as allowed.
+		quietest := math.Inf(1)
+		for i := max(first, 0); i <= last; i++ {
+			if mean := (sums[i+window] - sums[i]) / float64(window); mean <= quietest {
+				quietest = mean
+				cut = time.Duration(i)*energyFrame + quietWindow/2
+			}
+		}
+
+		cuts = append(cuts, cut)
+		lastCut = cut
+	}
+	return cuts
+}
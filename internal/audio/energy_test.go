@@ -0,0 +1,233 @@
+package audio_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// fakeEnergyOutput returns the ametadata output of FFmpeg for audio of
+// duration at -20 dB, with quiet frames at the given levels (by 100ms frame).
+func fakeEnergyOutput(duration time.Duration, quiet map[int]string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  Duration: %s.00, start: 0.000000, bitrate: 64 kb/s\n", formatClock(duration))
+	for i := range int(duration / (100 * time.Millisecond)) {
+		level, ok := quiet[i]
+		if !ok {
+			level = "-20.000"
+		}
+		fmt.Fprintf(&b, "[Parsed_ametadata_3 @ 0x1] frame:%d pts:%d pts_time:%.1f\n", i, i*1600, float64(i)/10)
+		fmt.Fprintf(&b, "[Parsed_ametadata_3 @ 0x1] lavfi.astats.Overall.RMS_level=%s\n", level)
+	}
+	return []byte(b.String())
+}
+
+// quietFrames sets 5 frames (500ms) to level from frame first.
+func quietFrames(levels map[int]string, first int, level string) {
+	for i := first; i < first+5; i++ {
+		levels[i] = level
+	}
+}
+
+func TestEnergyChunker_Plan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cuts at the quietest moment before each boundary", func(t *testing.T) {
+		t.Parallel()
+
+		// Quiet at 4:30 and 9:10, within the last minute of each 5-minute chunk,
+		// and at 1:00, too far from the first boundary.
+		quiet := map[int]string{}
+		quietFrames(quiet, 600, "-80.000")
+		quietFrames(quiet, 2700, "-45.500")
+		quietFrames(quiet, 5500, "-inf")
+		mockCmd := &mockCommandRunner{
+			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+				return fakeEnergyOutput(12*time.Minute, quiet), nil
+			},
+		}
+		ec, err := audio.NewEnergyChunker("/usr/bin/ffmpeg",
+			audio.WithEnergyChunkerCommandRunner(mockCmd),
+			audio.WithEnergyChunkerFileStatter(&mockFileStatter{size: 5 * 1024 * 1024}))
+		if err != nil {
+			t.Fatalf("NewEnergyChunker() error = %v", err)
+		}
+
+		chunks, err := ec.Plan(context.Background(), "/fake/noisy.ogg")
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+
+		want := []audio.Chunk{
+			{Index: 0, StartTime: 0, EndTime: 270250 * time.Millisecond},
+			{Index: 1, StartTime: 270250 * time.Millisecond, EndTime: 550250 * time.Millisecond},
+			{Index: 2, StartTime: 550250 * time.Millisecond, EndTime: 12 * time.Minute},
+		}
+		if len(chunks) != len(want) {
+			t.Fatalf("Plan() = %+v, want %+v", chunks, want)
+		}
+		for i := range want {
+			if chunks[i] != want[i] {
+				t.Errorf("Plan()[%d] = %+v, want %+v", i, chunks[i], want[i])
+			}
+		}
+		if args := strings.Join(mockCmd.calls[0].args, " "); !strings.Contains(args, "astats=metadata=1:reset=1") {
+			t.Errorf("ffmpeg args = %q, want the astats filter", args)
+		}
+	})
+
+	t.Run("chunks under the size limit", func(t *testing.T) {
+		t.Parallel()
+
+		mockCmd := &mockCommandRunner{
+			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+				return fakeEnergyOutput(4*time.Minute, nil), nil
+			},
+		}
+		// 4MB over 4 minutes: 1MB chunks hold 1 minute.
+		ec, _ := audio.NewEnergyChunker("/usr/bin/ffmpeg",
+			audio.WithEnergyChunkerCommandRunner(mockCmd),
+			audio.WithEnergyChunkerFileStatter(&mockFileStatter{size: 4 * 1024 * 1024}),
+			audio.WithEnergyChunkerMaxChunkSize(1024*1024))
+
+		chunks, err := ec.Plan(context.Background(), "/fake/noisy.ogg")
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+		if len(chunks) < 4 || chunks[0].EndTime < 59*time.Second {
+			t.Fatalf("Plan() = %+v, want chunks of about 1m", chunks)
+		}
+		for _, c := range chunks {
+			if c.Duration() > time.Minute {
+				t.Errorf("chunk %d lasts %v, want at most 1m", c.Index, c.Duration())
+			}
+		}
+	})
+
+	t.Run("no measurement plans with the fallback", func(t *testing.T) {
+		t.Parallel()
+
+		mockCmd := &mockCommandRunner{
+			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+				return []byte("Duration: 00:15:00.00\ntime=00:15:00.00"), nil
+			},
+		}
+		var warnings []string
+		ec, err := audio.NewEnergyChunker("/usr/bin/ffmpeg",
+			audio.WithEnergyChunkerCommandRunner(mockCmd),
+			audio.WithEnergyChunkerFileStatter(&mockFileStatter{size: 5 * 1024 * 1024}),
+			audio.WithEnergyChunkerWarnFunc(func(msg string) { warnings = append(warnings, msg) }))
+		if err != nil {
+			t.Fatalf("NewEnergyChunker() error = %v", err)
+		}
+
+		chunks, err := ec.Plan(context.Background(), "/fake/audio.ogg")
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+		if len(chunks) != 2 || chunks[1].StartTime != 570*time.Second {
+			t.Errorf("Plan() = %+v, want 10m time chunks with 30s overlap", chunks)
+		}
+		if len(warnings) != 1 || !strings.Contains(warnings[0], "time-based chunking") {
+			t.Errorf("warnings = %q, want time-based chunking announced", warnings)
+		}
+	})
+
+	t.Run("file stat error", func(t *testing.T) {
+		t.Parallel()
+
+		ec, _ := audio.NewEnergyChunker("/usr/bin/ffmpeg",
+			audio.WithEnergyChunkerFileStatter(&mockFileStatter{err: errors.New("no such file")}))
+
+		if _, err := ec.Plan(context.Background(), "/fake/missing.ogg"); !errors.Is(err, audio.ErrFileNotFound) {
+			t.Errorf("Plan() error = %v, want ErrFileNotFound", err)
+		}
+	})
+}
+
+func TestEnergyChunker_Chunk(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu        sync.Mutex
+		extracted = map[string]string{} // Start of each chunk file
+	)
+	mockCmd := &mockCommandRunner{
+		outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+			if !contains(args, "-ss") {
+				return fakeEnergyOutput(8*time.Minute, nil), nil
+			}
+			mu.Lock()
+			extracted[filepath.Base(args[len(args)-1])] = args[4]
+			mu.Unlock()
+			return nil, os.WriteFile(args[len(args)-1], nil, 0600)
+		},
+	}
+	ec, err := audio.NewEnergyChunker("/usr/bin/ffmpeg",
+		audio.WithEnergyChunkerCommandRunner(mockCmd),
+		audio.WithEnergyChunkerFileStatter(&mockFileStatter{size: 5 * 1024 * 1024}),
+		audio.WithEnergyChunkerTempDir(&mockTempDirCreator{dir: t.TempDir()}),
+		audio.WithEnergyChunkerRunID("run1"))
+	if err != nil {
+		t.Fatalf("NewEnergyChunker() error = %v", err)
+	}
+
+	chunks, err := ec.Chunk(context.Background(), "/fake/noisy.ogg")
+	if err != nil {
+		t.Fatalf("Chunk() error = %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("Chunk() = %+v, want 2 chunks", chunks)
+	}
+	for _, c := range chunks {
+		if c.Path == "" || filepath.Base(c.Path) != fmt.Sprintf("chunk_%03d.ogg", c.Index) || c.RunID != "run1" {
+			t.Errorf("chunk %d = %+v, want an extracted file of run1", c.Index, c)
+		}
+	}
+	// The second chunk starts 2 seconds early, to capture words at the cut.
+	if extracted["chunk_000.ogg"] != "00:00:00.000" || extracted["chunk_001.ogg"] != audio.FormatFFmpegTime(chunks[1].StartTime-2*time.Second) {
+		t.Errorf("extracted starts = %v, want overlapping ranges", extracted)
+	}
+}
+
+func TestSilenceChunker_NoSilencesCutsAtQuietestMoments(t *testing.T) {
+	t.Parallel()
+
+	quiet := map[int]string{}
+	quietFrames(quiet, 2650, "-50.000")
+	mockCmd := &mockCommandRunner{
+		outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+			if strings.Contains(strings.Join(args, " "), "silencedetect") {
+				return []byte("Duration: 00:08:00.00\ntime=00:08:00.00"), nil
+			}
+			return fakeEnergyOutput(8*time.Minute, quiet), nil
+		},
+	}
+	var warnings []string
+	sc, err := audio.NewSilenceChunker("/usr/bin/ffmpeg",
+		audio.WithCommandRunner(mockCmd),
+		audio.WithFileStatter(&mockFileStatter{size: 5 * 1024 * 1024}),
+		audio.WithWarnFunc(func(msg string) { warnings = append(warnings, msg) }))
+	if err != nil {
+		t.Fatalf("NewSilenceChunker() error = %v", err)
+	}
+
+	chunks, err := sc.Plan(context.Background(), "/fake/noisy.ogg")
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(chunks) != 2 || chunks[0].EndTime != 265250*time.Millisecond {
+		t.Errorf("Plan() = %+v, want a cut at 4:25.25", chunks)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "quietest moments") {
+		t.Errorf("warnings = %q, want the quietest moments announced", warnings)
+	}
+}
@@ -11,11 +11,36 @@ var ErrLoopbackNotFound = errors.New("loopback device not found")
 // ErrChunkingFailed indicates FFmpeg failed during audio chunking.
 var ErrChunkingFailed = errors.New("audio chunking failed")
 
-// ErrChunkTooLarge indicates a chunk exceeds the OpenAI API limit (25MB).
-var ErrChunkTooLarge = errors.New("chunk exceeds 25MB limit")
+// ErrChunkTooLarge indicates a chunk exceeds the file size limit of the
+// transcription API (25MB for OpenAI).
+var ErrChunkTooLarge = errors.New("chunk exceeds the API file size limit")
 
 // ErrFileNotFound indicates the specified input file does not exist.
 var ErrFileNotFound = errors.New("file not found")
 
 // ErrInvalidOverlap indicates overlap duration is invalid (>= target duration).
 var ErrInvalidOverlap = errors.New("overlap must be less than target duration")
+
+// ErrInvalidSegmentDuration indicates a streaming segment duration is too short.
+var ErrInvalidSegmentDuration = errors.New("invalid segment duration")
+
+// ErrInvalidChunkSize indicates a chunk size too small to make chunks of.
+var ErrInvalidChunkSize = errors.New("invalid chunk size")
+
+// ErrUnknownChunker indicates no chunker is registered under the given name.
+var ErrUnknownChunker = errors.New("unknown chunker")
+
+// ErrNoAudioTrack indicates an input file has no audio track to transcribe.
+var ErrNoAudioTrack = errors.New("no audio track")
+
+// ErrExtractionFailed indicates FFmpeg failed to extract the audio of a video file.
+var ErrExtractionFailed = errors.New("audio extraction failed")
+
+// ErrRepairFailed indicates FFmpeg failed to repair a recording cut short by a crash.
+var ErrRepairFailed = errors.New("recording repair failed")
+
+// ErrAmbiguousDevice indicates a device name matches several audio input devices.
+var ErrAmbiguousDevice = errors.New("ambiguous audio device")
+
+// ErrPreprocessingFailed indicates FFmpeg failed to denoise or normalize the audio.
+var ErrPreprocessingFailed = errors.New("audio preprocessing failed")
@@ -17,6 +17,20 @@ var ParseTimeComponents = parseTimeComponents
 // FormatFFmpegTime exports formatFFmpegTime for testing.
 var FormatFFmpegTime = formatFFmpegTime
 
+// UnregisterChunker removes the chunker registered under name, so that tests
+// registering one leave the global registry as they found it.
+func UnregisterChunker(name string) {
+	chunkersMu.Lock()
+	defer chunkersMu.Unlock()
+	delete(chunkers, name)
+}
+
+// CanStreamCopy exports canStreamCopy for testing.
+var CanStreamCopy = canStreamCopy
+
+// OpusInputRate exports opusInputRate for testing.
+var OpusInputRate = opusInputRate
+
 // ParseSilenceOutput exports parseSilenceOutput for testing.
 // Returns test-visible SilencePointTest instead of internal silencePoint.
 func ParseSilenceOutput(output string) []SilencePointTest {
@@ -94,6 +108,31 @@ func BuildRecordArgs(inputFormat, inputArg string, durationSec int, output strin
 	return buildRecordArgs(inputFormat, inputArg, time.Duration(durationSec)*time.Second, output)
 }
 
+// BuildStreamRecordArgs exports buildOutputRecordArgs with streamed segments for testing.
+func BuildStreamRecordArgs(inputFormat, inputArg string, durationSec int, output, segmentDir string, segmentSec int) []string {
+	return buildOutputRecordArgs(inputFormat, inputArg, time.Duration(durationSec)*time.Second, recordOutput{
+		path:            output,
+		segmentDir:      segmentDir,
+		segmentDuration: time.Duration(segmentSec) * time.Second,
+	}, "")
+}
+
+// ParseLoudness exports the loudness parsing for testing: it writes output
+// to a lineWriter and returns the loudness values of its lines.
+func ParseLoudness(output string) []float64 {
+	var got []float64
+	w := &lineWriter{fn: func(line []byte) {
+		if l, ok := parseLoudness(line); ok {
+			got = append(got, l)
+		}
+	}}
+	_, _ = w.Write([]byte(output))
+	return got
+}
+
+// EscapeTeePath exports escapeTeePath for testing.
+var EscapeTeePath = escapeTeePath
+
 // EncodingArgs exports encodingArgs for testing.
 var EncodingArgs = encodingArgs
 
@@ -135,6 +174,19 @@ var ExportedWithFFmpegRunner = WithFFmpegRunner
 // WithPactlRunner exports WithPactlRunner for testing.
 var ExportedWithPactlRunner = WithPactlRunner
 
+// NewMixRecorderForTest creates a mix recorder with a fixed loopback device,
+// skipping loopback detection.
+func NewMixRecorderForTest(ffmpegPath, micDevice string, runner ffmpegRunner) *FFmpegRecorder {
+	return &FFmpegRecorder{
+		ffmpegPath:   ffmpegPath,
+		device:       micDevice,
+		captureMode:  CaptureMix,
+		loopback:     &loopbackDevice{name: "BlackHole 2ch", format: "avfoundation"},
+		ffmpegRunner: runner,
+		pactlRunner:  defaultPactlRunner{},
+	}
+}
+
 // --- Loopback exports ---
 
 // ExtractDShowDeviceName exports extractDShowDeviceName for testing.
@@ -180,3 +232,17 @@ type ExportedWarnFunc = WarnFunc
 
 // ExportedWithWarnFunc exports WithWarnFunc for testing.
 var ExportedWithWarnFunc = WithWarnFunc
+
+// --- Fingerprint exports ---
+
+// ComputeFingerprint exports computeFingerprint for testing.
+var ComputeFingerprint = computeFingerprint
+
+// FingerprintSampleRate exports fingerprintSampleRate for testing.
+const FingerprintSampleRate = fingerprintSampleRate
+
+// WithFingerprinterCommandRunner exports WithFingerprinterCommandRunner for testing.
+var ExportedWithFingerprinterCommandRunner = WithFingerprinterCommandRunner
+
+// MaxIntroLibraryEntries exports maxIntroLibraryEntries for testing.
+const MaxIntroLibraryEntries = maxIntroLibraryEntries
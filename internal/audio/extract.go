@@ -0,0 +1,137 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Compile-time interface implementation check.
+var _ AudioExtractor = (*FFmpegAudioExtractor)(nil)
+
+// videoFormats are the video containers whose audio track is extracted
+// before chunking, rather than chunked directly.
+var videoFormats = map[string]bool{
+	".mp4":  true,
+	".mkv":  true,
+	".mov":  true,
+	".webm": true,
+}
+
+// IsVideo reports whether path is a video container, by extension.
+// Audio-only files in these containers (e.g. an .mp4 voice memo) are
+// extracted too, which costs a re-encode but no failure.
+func IsVideo(path string) bool {
+	return videoFormats[strings.ToLower(filepath.Ext(path))]
+}
+
+// AudioExtractor extracts the audio track of video files.
+type AudioExtractor interface {
+	// ExtractAudio writes the first audio track of videoPath to outputPath, in
+	// the encoding of chunks (OGG Opus, 16kHz mono). videoPath may also be the
+	// list of a segmented recording (see IsSegmentList): its files are joined. onProgress, if not nil,
+	// is called as extraction advances, with the duration of audio extracted
+	// so far and the duration of the video (0 if unknown).
+	ExtractAudio(ctx context.Context, videoPath, outputPath string, onProgress func(done, total time.Duration)) error
+}
+
+// FFmpegAudioExtractor implements AudioExtractor with FFmpeg.
+type FFmpegAudioExtractor struct {
+	ffmpegPath string
+	cmd        commandRunner // Probes the duration
+	runner     ffmpegRunner  // Runs the extraction, streaming its progress
+}
+
+// AudioExtractorOption configures an FFmpegAudioExtractor.
+type AudioExtractorOption func(*FFmpegAudioExtractor)
+
+// WithAudioExtractorCommandRunner sets a custom command runner (for testing).
+func WithAudioExtractorCommandRunner(r commandRunner) AudioExtractorOption {
+	return func(e *FFmpegAudioExtractor) {
+		e.cmd = r
+	}
+}
+
+// WithAudioExtractorFFmpegRunner sets a custom FFmpeg runner (for testing).
+func WithAudioExtractorFFmpegRunner(r ffmpegRunner) AudioExtractorOption {
+	return func(e *FFmpegAudioExtractor) {
+		e.runner = r
+	}
+}
+
+// NewAudioExtractor creates an audio extractor using the FFmpeg binary at ffmpegPath.
+func NewAudioExtractor(ffmpegPath string, opts ...AudioExtractorOption) (*FFmpegAudioExtractor, error) {
+	if ffmpegPath == "" {
+		return nil, fmt.Errorf("ffmpeg path cannot be empty")
+	}
+	e := &FFmpegAudioExtractor{
+		ffmpegPath: ffmpegPath,
+		cmd:        osCommandRunner{},
+		runner:     defaultFFmpegRunner{},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
+}
+
+// ExtractAudio writes the first audio track of videoPath to outputPath.
+// Returns ErrNoAudioTrack if the video has none.
+func (e *FFmpegAudioExtractor) ExtractAudio(ctx context.Context, videoPath, outputPath string, onProgress func(done, total time.Duration)) error {
+	return convertAudio(ctx, e.cmd, e.runner, e.ffmpegPath, videoPath, extractAudioArgs(videoPath, outputPath, ""), ErrExtractionFailed, onProgress)
+}
+
+// convertAudio runs FFmpeg with args, writing the first audio track of
+// inputPath to a new file, and reports its progress to onProgress (if not nil).
+// Returns ErrNoAudioTrack if the input has none, or failed wrapping its error.
+func convertAudio(ctx context.Context, cmd commandRunner, runner ffmpegRunner, ffmpegPath, inputPath string, args []string, failed error, onProgress func(done, total time.Duration)) error {
+	// The duration only scales progress: conversion goes on without it
+	total, _ := probeDuration(ctx, cmd, ffmpegPath, inputPath)
+
+	var noAudio bool
+	w := &lineWriter{fn: func(line []byte) {
+		if bytes.Contains(line, []byte("matches no streams")) || bytes.Contains(line, []byte("does not contain any stream")) {
+			noAudio = true
+		}
+		if onProgress != nil {
+			if done, ok := parseFinalTime(string(line)); ok {
+				onProgress(done, total)
+			}
+		}
+	}}
+	err := runner.RunGracefulWithStderr(ctx, ffmpegPath, args, gracefulShutdownTimeout, w)
+	switch {
+	case noAudio:
+		return fmt.Errorf("%w in %s", ErrNoAudioTrack, inputPath)
+	case ctx.Err() != nil:
+		// Stopped early, FFmpeg exits cleanly: the audio is incomplete
+		return ctx.Err()
+	case err != nil:
+		return fmt.Errorf("%w: %s: %v", failed, inputPath, err)
+	}
+	return nil
+}
+
+// extractAudioArgs returns the FFmpeg arguments extracting the first audio
+// track of videoPath to outputPath, dropping video, subtitles and data, and
+// applying the audio filter chain filter (if not empty).
+// Segment lists are read with the concat demuxer, as one input.
+func extractAudioArgs(videoPath, outputPath, filter string) []string {
+	args := []string{"-y"}
+	if IsSegmentList(videoPath) {
+		args = append(args, "-f", "concat")
+	}
+	args = append(args,
+		"-i", videoPath,
+		"-map", "0:a:0",
+		"-vn", "-sn", "-dn",
+	)
+	if filter != "" {
+		args = append(args, "-af", filter)
+	}
+	args = append(args, chunkEncodingArgs()...)
+	return append(args, outputPath)
+}
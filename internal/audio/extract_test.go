@@ -0,0 +1,166 @@
+package audio_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+func TestIsVideo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"talk.mp4", true},
+		{"talk.MKV", true},
+		{"/videos/talk.mov", true},
+		{"talk.webm", true},
+		{"talk.ogg", false},
+		{"talk.m4a", false},
+		{"talk", false},
+	}
+
+	for _, tt := range tests {
+		if got := audio.IsVideo(tt.path); got != tt.want {
+			t.Errorf("IsVideo(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFFmpegAudioExtractor_ExtractAudio(t *testing.T) {
+	t.Parallel()
+
+	probe := &mockCommandRunner{
+		outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+			return []byte("  Duration: 00:10:00.00, start: 0.000000, bitrate: 1200 kb/s\n"), nil
+		},
+	}
+
+	t.Run("reports progress", func(t *testing.T) {
+		t.Parallel()
+
+		var gotArgs []string
+		runner := &mockFFmpegRunner{
+			runGracefulWithStderrFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error {
+				gotArgs = args
+				_, _ = io.WriteString(w, "size=     512kB time=00:05:00.00 bitrate=  13.9kbits/s speed=80x\r")
+				_, _ = io.WriteString(w, "size=    1024kB time=00:10:00.00 bitrate=  13.9kbits/s speed=80x\n")
+				return nil
+			},
+		}
+		e, err := audio.NewAudioExtractor("/usr/bin/ffmpeg",
+			audio.WithAudioExtractorCommandRunner(probe), audio.WithAudioExtractorFFmpegRunner(runner))
+		if err != nil {
+			t.Fatalf("NewAudioExtractor() unexpected error: %v", err)
+		}
+
+		var done []time.Duration
+		err = e.ExtractAudio(context.Background(), "talk.mp4", "audio.ogg", func(d, total time.Duration) {
+			if total != 10*time.Minute {
+				t.Errorf("onProgress total = %v, want 10m0s", total)
+			}
+			done = append(done, d)
+		})
+		if err != nil {
+			t.Fatalf("ExtractAudio() unexpected error: %v", err)
+		}
+		if want := []time.Duration{5 * time.Minute, 10 * time.Minute}; !slices.Equal(done, want) {
+			t.Errorf("onProgress done = %v, want %v", done, want)
+		}
+		for _, want := range []string{"talk.mp4", "0:a:0", "-vn", "libopus", "audio.ogg"} {
+			if !slices.Contains(gotArgs, want) {
+				t.Errorf("ffmpeg args = %v, want containing %q", gotArgs, want)
+			}
+		}
+	})
+
+	t.Run("joins the files of a segment list", func(t *testing.T) {
+		t.Parallel()
+
+		var gotArgs []string
+		runner := &mockFFmpegRunner{
+			runGracefulWithStderrFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error {
+				gotArgs = args
+				return nil
+			},
+		}
+		e, _ := audio.NewAudioExtractor("/usr/bin/ffmpeg",
+			audio.WithAudioExtractorCommandRunner(probe), audio.WithAudioExtractorFFmpegRunner(runner))
+
+		if err := e.ExtractAudio(context.Background(), "meeting.ffconcat", "audio.ogg", nil); err != nil {
+			t.Fatalf("ExtractAudio() unexpected error: %v", err)
+		}
+		want := []string{"-y", "-f", "concat", "-i", "meeting.ffconcat"}
+		if !slices.Equal(gotArgs[:len(want)], want) {
+			t.Errorf("ffmpeg args = %v, want starting with %v", gotArgs, want)
+		}
+	})
+
+	t.Run("no audio track", func(t *testing.T) {
+		t.Parallel()
+
+		runner := &mockFFmpegRunner{
+			runGracefulWithStderrFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error {
+				_, _ = io.WriteString(w, "Stream map '0:a:0' matches no streams.\n")
+				return errors.New("exit status 1")
+			},
+		}
+		e, _ := audio.NewAudioExtractor("/usr/bin/ffmpeg",
+			audio.WithAudioExtractorCommandRunner(probe), audio.WithAudioExtractorFFmpegRunner(runner))
+
+		err := e.ExtractAudio(context.Background(), "screencast.mov", "audio.ogg", nil)
+		if !errors.Is(err, audio.ErrNoAudioTrack) {
+			t.Errorf("ExtractAudio() error = %v, want ErrNoAudioTrack", err)
+		}
+	})
+
+	t.Run("ffmpeg failure", func(t *testing.T) {
+		t.Parallel()
+
+		runner := &mockFFmpegRunner{
+			runGracefulWithStderrFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error {
+				return errors.New("exit status 1")
+			},
+		}
+		e, _ := audio.NewAudioExtractor("/usr/bin/ffmpeg",
+			audio.WithAudioExtractorCommandRunner(probe), audio.WithAudioExtractorFFmpegRunner(runner))
+
+		err := e.ExtractAudio(context.Background(), "broken.mkv", "audio.ogg", nil)
+		if !errors.Is(err, audio.ErrExtractionFailed) {
+			t.Errorf("ExtractAudio() error = %v, want ErrExtractionFailed", err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		runner := &mockFFmpegRunner{
+			runGracefulWithStderrFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error {
+				cancel()
+				return nil // FFmpeg stops cleanly on cancellation
+			},
+		}
+		e, _ := audio.NewAudioExtractor("/usr/bin/ffmpeg",
+			audio.WithAudioExtractorCommandRunner(probe), audio.WithAudioExtractorFFmpegRunner(runner))
+
+		if err := e.ExtractAudio(ctx, "talk.mp4", "audio.ogg", nil); !errors.Is(err, context.Canceled) {
+			t.Errorf("ExtractAudio() error = %v, want context.Canceled", err)
+		}
+	})
+}
+
+func TestNewAudioExtractor_EmptyPath(t *testing.T) {
+	t.Parallel()
+
+	if _, err := audio.NewAudioExtractor(""); err == nil {
+		t.Error("NewAudioExtractor(\"\") expected error")
+	}
+}
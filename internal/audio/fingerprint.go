@@ -0,0 +1,307 @@
+package audio
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/cmplx"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Compile-time interface implementation check.
+var _ Fingerprinter = (*FFmpegFingerprinter)(nil)
+
+// Fingerprinting parameters.
+//
+// Audio is decoded to 8kHz mono and split into overlapping frames. Each frame
+// gets a 32-bit sub-fingerprint: bit m is set if the energy difference between
+// bands m and m+1 increased since the previous frame (Haitsma and Kalker).
+// The bits only depend on the shape of the spectrum over time, so they survive
+// re-encoding and volume changes, which is what makes the intro of one episode
+// match the intro of the next.
+const (
+	fingerprintSampleRate = 8000
+	fingerprintFrameSize  = 2048 // Samples per frame (256ms), a power of two for the FFT
+	fingerprintHopSize    = 512  // Samples between frames (64ms)
+	fingerprintBands      = 33   // 32 band differences
+	fingerprintMinFreq    = 300.0
+	fingerprintMaxFreq    = 2000.0
+
+	// fingerprintSilence is the mean squared amplitude (16-bit samples) below
+	// which a frame is considered silent. Silent frames never match: their
+	// bits are noise, or all equal in digital silence.
+	fingerprintSilence = 100.0 * 100.0
+)
+
+// FingerprintFrameDuration is the time between two frames of a Fingerprint.
+const FingerprintFrameDuration = time.Second * fingerprintHopSize / fingerprintSampleRate
+
+// Fingerprint is the acoustic fingerprint of an audio segment: one 32-bit
+// sub-fingerprint per frame, FingerprintFrameDuration apart.
+// Silent frames are zero.
+type Fingerprint []uint32
+
+// Duration returns the length of audio covered by f.
+func (f Fingerprint) Duration() time.Duration {
+	return time.Duration(len(f)) * FingerprintFrameDuration
+}
+
+// Fingerprinter fingerprints audio files and cuts them, so that segments
+// already heard in earlier recordings can be detected and left out.
+type Fingerprinter interface {
+	// Duration returns the duration of audioPath.
+	Duration(ctx context.Context, audioPath string) (time.Duration, error)
+	// Fingerprint computes the fingerprint of audioPath from start, over at most length.
+	Fingerprint(ctx context.Context, audioPath string, start, length time.Duration) (Fingerprint, error)
+	// Extract writes the audio of audioPath between start and end to outputPath,
+	// encoded like chunks (OGG Opus).
+	Extract(ctx context.Context, audioPath, outputPath string, start, end time.Duration) error
+}
+
+// FFmpegFingerprinter implements Fingerprinter with FFmpeg.
+type FFmpegFingerprinter struct {
+	ffmpegPath string
+	cmd        commandRunner
+	tempDir    tempDirCreator
+}
+
+// FingerprinterOption configures an FFmpegFingerprinter.
+type FingerprinterOption func(*FFmpegFingerprinter)
+
+// WithFingerprinterCommandRunner sets a custom command runner (for testing).
+func WithFingerprinterCommandRunner(r commandRunner) FingerprinterOption {
+	return func(f *FFmpegFingerprinter) {
+		f.cmd = r
+	}
+}
+
+// WithFingerprinterTempDir sets a custom temp directory creator (for testing).
+func WithFingerprinterTempDir(t tempDirCreator) FingerprinterOption {
+	return func(f *FFmpegFingerprinter) {
+		f.tempDir = t
+	}
+}
+
+// NewFingerprinter creates a fingerprinter using the FFmpeg binary at ffmpegPath.
+func NewFingerprinter(ffmpegPath string, opts ...FingerprinterOption) (*FFmpegFingerprinter, error) {
+	if ffmpegPath == "" {
+		return nil, fmt.Errorf("ffmpeg path cannot be empty")
+	}
+	f := &FFmpegFingerprinter{
+		ffmpegPath: ffmpegPath,
+		cmd:        osCommandRunner{},
+		tempDir:    osTempDirCreator{},
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f, nil
+}
+
+// Duration returns the duration of audioPath.
+func (f *FFmpegFingerprinter) Duration(ctx context.Context, audioPath string) (time.Duration, error) {
+	return probeDuration(ctx, f.cmd, f.ffmpegPath, audioPath)
+}
+
+// Fingerprint decodes audioPath between start and start+length to raw PCM
+// and computes its fingerprint.
+func (f *FFmpegFingerprinter) Fingerprint(ctx context.Context, audioPath string, start, length time.Duration) (Fingerprint, error) {
+	dir, err := f.tempDir.MkdirTemp("", "go-transcript-fingerprint-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	rawPath := filepath.Join(dir, "audio.raw")
+	args := []string{
+		"-y",
+		"-ss", formatFFmpegTime(start),
+		"-t", formatFFmpegTime(length),
+		"-i", audioPath,
+		"-ac", "1",
+		"-ar", fmt.Sprint(fingerprintSampleRate),
+		"-f", "s16le",
+		rawPath,
+	}
+	if output, err := f.cmd.CombinedOutput(ctx, f.ffmpegPath, args); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w\nOutput: %s", audioPath, err, string(output))
+	}
+
+	data, err := os.ReadFile(rawPath) // #nosec G304 -- path in our own temp directory
+	if err != nil {
+		return nil, fmt.Errorf("failed to read decoded audio: %w", err)
+	}
+	samples := make([]int16, len(data)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[2*i:])) // #nosec G115 -- reinterpreting PCM bits
+	}
+	return computeFingerprint(samples), nil
+}
+
+// Extract writes the audio of audioPath between start and end to outputPath.
+func (f *FFmpegFingerprinter) Extract(ctx context.Context, audioPath, outputPath string, start, end time.Duration) error {
+	return runExtractChunk(ctx, f.cmd, f.ffmpegPath, audioPath, outputPath, start, end, false)
+}
+
+// computeFingerprint computes the fingerprint of 8kHz mono samples.
+func computeFingerprint(samples []int16) Fingerprint {
+	if len(samples) < fingerprintFrameSize {
+		return nil
+	}
+
+	window := make([]float64, fingerprintFrameSize)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(fingerprintFrameSize-1)) // Hann
+	}
+	edges := bandEdges()
+
+	frameCount := (len(samples)-fingerprintFrameSize)/fingerprintHopSize + 1
+	fp := make(Fingerprint, frameCount)
+	buf := make([]complex128, fingerprintFrameSize)
+	var previous []float64
+
+	for n := range frameCount {
+		frame := samples[n*fingerprintHopSize : n*fingerprintHopSize+fingerprintFrameSize]
+
+		var power float64
+		for i, s := range frame {
+			v := float64(s)
+			power += v * v
+			buf[i] = complex(v*window[i], 0)
+		}
+		fft(buf)
+
+		energies := make([]float64, fingerprintBands)
+		for b := range fingerprintBands {
+			for k := edges[b]; k < edges[b+1]; k++ {
+				energies[b] += real(buf[k])*real(buf[k]) + imag(buf[k])*imag(buf[k])
+			}
+		}
+
+		if previous != nil && power/float64(fingerprintFrameSize) >= fingerprintSilence {
+			var sub uint32
+			for m := range fingerprintBands - 1 {
+				if energies[m]-energies[m+1]-(previous[m]-previous[m+1]) > 0 {
+					sub |= 1 << m
+				}
+			}
+			if sub == 0 {
+				sub = 1 // Zero is reserved for silent frames
+			}
+			fp[n] = sub
+		}
+		previous = energies
+	}
+	return fp
+}
+
+// bandEdges returns the FFT bin boundaries of the logarithmically spaced
+// bands between fingerprintMinFreq and fingerprintMaxFreq.
+func bandEdges() []int {
+	edges := make([]int, fingerprintBands+1)
+	ratio := fingerprintMaxFreq / fingerprintMinFreq
+	binWidth := float64(fingerprintSampleRate) / fingerprintFrameSize
+	for b := range edges {
+		freq := fingerprintMinFreq * math.Pow(ratio, float64(b)/fingerprintBands)
+		edges[b] = int(math.Round(freq / binWidth))
+	}
+	return edges
+}
+
+// fft computes the discrete Fourier transform of x in place.
+// len(x) must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+	// Bit-reversal permutation
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := range size / 2 {
+				a, b := x[start+k], w*x[start+k+size/2]
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
+
+// Matching parameters.
+const (
+	// maxBitErrors is the number of differing bits (out of 32) below which two
+	// frames are considered the same audio.
+	maxBitErrors = 8
+	// maxMatchGap is the number of consecutive mismatching frames tolerated
+	// inside a match (noise, a word spoken over the music).
+	maxMatchGap = 4
+)
+
+// Repeat is a segment of audio found in two fingerprints.
+type Repeat struct {
+	Start time.Duration // Start in the searched fingerprint
+	End   time.Duration // End in the searched fingerprint
+}
+
+// Duration returns the length of the repeated segment.
+func (r Repeat) Duration() time.Duration {
+	return r.End - r.Start
+}
+
+// FindRepeat returns the longest segment of fp that also appears in known,
+// at any offset, if it lasts at least minDuration.
+// Times are relative to the start of fp.
+func FindRepeat(fp, known Fingerprint, minDuration time.Duration) (Repeat, bool) {
+	bestStart, bestLen := 0, 0
+
+	// Align known[i] with fp[i+offset] for every overlapping offset
+	for offset := -(len(known) - 1); offset < len(fp); offset++ {
+		runStart, runEnd, gap := -1, -1, 0
+		for i := max(0, -offset); i < len(known) && i+offset < len(fp); i++ {
+			j := i + offset
+			if framesMatch(fp[j], known[i]) {
+				if runStart < 0 {
+					runStart = j
+				}
+				runEnd, gap = j+1, 0
+				if runEnd-runStart > bestLen {
+					bestStart, bestLen = runStart, runEnd-runStart
+				}
+				continue
+			}
+			if runStart >= 0 {
+				gap++
+				if gap > maxMatchGap {
+					runStart, gap = -1, 0
+				}
+			}
+		}
+	}
+
+	r := Repeat{
+		Start: time.Duration(bestStart) * FingerprintFrameDuration,
+		End:   time.Duration(bestStart+bestLen) * FingerprintFrameDuration,
+	}
+	if bestLen == 0 || r.Duration() < minDuration {
+		return Repeat{}, false
+	}
+	return r, true
+}
+
+// framesMatch reports whether two sub-fingerprints are the same audio.
+func framesMatch(a, b uint32) bool {
+	return a != 0 && b != 0 && bits.OnesCount32(a^b) <= maxBitErrors
+}
@@ -0,0 +1,251 @@
+package audio_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// ---------------------------------------------------------------------------
+// Synthetic audio helpers
+// ---------------------------------------------------------------------------
+
+// melody returns d of 8kHz samples: a sequence of random chords, 200ms each,
+// like a jingle. The same seed gives the same melody.
+func melody(seed uint64, d time.Duration, gain float64) []int16 {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	n := int(d.Seconds() * audio.FingerprintSampleRate)
+	noteLen := audio.FingerprintSampleRate / 5
+	samples := make([]int16, n)
+	freqs := make([]float64, 8)
+	for i := range samples {
+		if i%noteLen == 0 {
+			for k := range freqs {
+				freqs[k] = 300 + rng.Float64()*1700
+			}
+		}
+		t := float64(i) / audio.FingerprintSampleRate
+		var v float64
+		for _, f := range freqs {
+			v += 1500 * math.Sin(2*math.Pi*f*t)
+		}
+		samples[i] = int16(v * gain)
+	}
+	return samples
+}
+
+// addNoise adds uniform noise of the given amplitude to samples (in place).
+func addNoise(samples []int16, seed uint64, amplitude float64) []int16 {
+	rng := rand.New(rand.NewPCG(seed, 0))
+	for i, s := range samples {
+		samples[i] = int16(float64(s) + (rng.Float64()*2-1)*amplitude)
+	}
+	return samples
+}
+
+// concat joins sample slices.
+func concat(parts ...[]int16) []int16 {
+	var out []int16
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// near reports whether got is within tolerance of want.
+func near(got, want, tolerance time.Duration) bool {
+	return got >= want-tolerance && got <= want+tolerance
+}
+
+// ---------------------------------------------------------------------------
+// computeFingerprint / FindRepeat
+// ---------------------------------------------------------------------------
+
+func TestComputeFingerprint(t *testing.T) {
+	t.Parallel()
+
+	t.Run("too short", func(t *testing.T) {
+		t.Parallel()
+		if fp := audio.ComputeFingerprint(make([]int16, 100)); len(fp) != 0 {
+			t.Errorf("ComputeFingerprint(short) = %d frames, want 0", len(fp))
+		}
+	})
+
+	t.Run("frame count matches duration", func(t *testing.T) {
+		t.Parallel()
+		fp := audio.ComputeFingerprint(melody(1, 10*time.Second, 1))
+		if !near(fp.Duration(), 10*time.Second, 300*time.Millisecond) {
+			t.Errorf("Duration() = %v, want ~10s", fp.Duration())
+		}
+	})
+
+	t.Run("silence is zero", func(t *testing.T) {
+		t.Parallel()
+		fp := audio.ComputeFingerprint(make([]int16, 5*audio.FingerprintSampleRate))
+		for i, v := range fp {
+			if v != 0 {
+				t.Fatalf("frame %d of silence = %#x, want 0", i, v)
+			}
+		}
+	})
+}
+
+func TestFindRepeat(t *testing.T) {
+	t.Parallel()
+
+	jingle := func() []int16 { return melody(42, 15*time.Second, 1) }
+	tolerance := 3 * audio.FingerprintFrameDuration
+
+	t.Run("same intro at the start", func(t *testing.T) {
+		t.Parallel()
+
+		episode1 := audio.ComputeFingerprint(concat(jingle(), melody(1, 30*time.Second, 1)))
+		episode2 := audio.ComputeFingerprint(concat(jingle(), melody(2, 30*time.Second, 1)))
+
+		r, ok := audio.FindRepeat(episode2, episode1, audio.MinRepeatDuration)
+		if !ok {
+			t.Fatal("FindRepeat() found nothing, want the intro")
+		}
+		if !near(r.Start, 0, tolerance) || !near(r.End, 15*time.Second, tolerance) {
+			t.Errorf("FindRepeat() = %v-%v, want ~0s-15s", r.Start, r.End)
+		}
+	})
+
+	t.Run("intro after a cold open, re-encoded", func(t *testing.T) {
+		t.Parallel()
+
+		episode1 := audio.ComputeFingerprint(concat(jingle(), melody(1, 30*time.Second, 1)))
+		// Quieter, noisy copy of the intro, 20s into the episode
+		intro := addNoise(melody(42, 15*time.Second, 0.5), 7, 500)
+		episode2 := audio.ComputeFingerprint(concat(melody(3, 20*time.Second, 1), intro, melody(2, 30*time.Second, 1)))
+
+		r, ok := audio.FindRepeat(episode2, episode1, audio.MinRepeatDuration)
+		if !ok {
+			t.Fatal("FindRepeat() found nothing, want the intro")
+		}
+		if !near(r.Start, 20*time.Second, tolerance) || !near(r.End, 35*time.Second, tolerance) {
+			t.Errorf("FindRepeat() = %v-%v, want ~20s-35s", r.Start, r.End)
+		}
+	})
+
+	t.Run("different audio", func(t *testing.T) {
+		t.Parallel()
+
+		a := audio.ComputeFingerprint(melody(1, 30*time.Second, 1))
+		b := audio.ComputeFingerprint(melody(2, 30*time.Second, 1))
+		if r, ok := audio.FindRepeat(a, b, audio.MinRepeatDuration); ok {
+			t.Errorf("FindRepeat(different) = %v-%v, want no match", r.Start, r.End)
+		}
+	})
+
+	t.Run("silence never matches", func(t *testing.T) {
+		t.Parallel()
+
+		silence := audio.ComputeFingerprint(make([]int16, 30*audio.FingerprintSampleRate))
+		if _, ok := audio.FindRepeat(silence, silence, audio.MinRepeatDuration); ok {
+			t.Error("FindRepeat(silence) matched, want no match")
+		}
+	})
+
+	t.Run("shorter than minimum", func(t *testing.T) {
+		t.Parallel()
+
+		short := melody(42, 3*time.Second, 1)
+		a := audio.ComputeFingerprint(concat(short, melody(1, 20*time.Second, 1)))
+		b := audio.ComputeFingerprint(concat(short, melody(2, 20*time.Second, 1)))
+		if r, ok := audio.FindRepeat(a, b, audio.MinRepeatDuration); ok {
+			t.Errorf("FindRepeat(3s repeat) = %v-%v, want no match", r.Start, r.End)
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// FFmpegFingerprinter - FFmpeg invocation via mock runner
+// ---------------------------------------------------------------------------
+
+func TestFFmpegFingerprinter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty ffmpeg path", func(t *testing.T) {
+		t.Parallel()
+		if _, err := audio.NewFingerprinter(""); err == nil {
+			t.Error("NewFingerprinter(\"\") expected error")
+		}
+	})
+
+	t.Run("fingerprint decodes raw PCM", func(t *testing.T) {
+		t.Parallel()
+
+		samples := melody(5, 5*time.Second, 1)
+		var gotArgs []string
+		runner := &mockCommandRunner{
+			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+				gotArgs = args
+				data := make([]byte, 2*len(samples))
+				for i, s := range samples {
+					binary.LittleEndian.PutUint16(data[2*i:], uint16(s))
+				}
+				return nil, os.WriteFile(args[len(args)-1], data, 0600)
+			},
+		}
+		f, err := audio.NewFingerprinter("/usr/bin/ffmpeg", audio.ExportedWithFingerprinterCommandRunner(runner))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fp, err := f.Fingerprint(context.Background(), "episode.mp3", 10*time.Second, audio.IntroWindow)
+		if err != nil {
+			t.Fatalf("Fingerprint() unexpected error: %v", err)
+		}
+		want := audio.ComputeFingerprint(samples)
+		if len(fp) != len(want) || fp[10] != want[10] {
+			t.Errorf("Fingerprint() = %d frames, want %d identical frames", len(fp), len(want))
+		}
+		joined := strings.Join(gotArgs, " ")
+		for _, arg := range []string{"-ss 00:00:10.000", "-t 00:01:30.000", "-i episode.mp3", "-ar 8000", "-f s16le"} {
+			if !strings.Contains(joined, arg) {
+				t.Errorf("ffmpeg args %q missing %q", joined, arg)
+			}
+		}
+	})
+
+	t.Run("decode failure", func(t *testing.T) {
+		t.Parallel()
+
+		runner := &mockCommandRunner{
+			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+				return []byte("Invalid data"), errors.New("exit status 1")
+			},
+		}
+		f, _ := audio.NewFingerprinter("/usr/bin/ffmpeg", audio.ExportedWithFingerprinterCommandRunner(runner))
+		if _, err := f.Fingerprint(context.Background(), "broken.mp3", 0, audio.IntroWindow); err == nil {
+			t.Error("Fingerprint() expected error")
+		}
+	})
+
+	t.Run("duration parsed from ffmpeg output", func(t *testing.T) {
+		t.Parallel()
+
+		runner := &mockCommandRunner{
+			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+				return []byte("  Duration: 00:42:10.50, start: 0.000000, bitrate: 128 kb/s"), errors.New("exit status 1")
+			},
+		}
+		f, _ := audio.NewFingerprinter("/usr/bin/ffmpeg", audio.ExportedWithFingerprinterCommandRunner(runner))
+		d, err := f.Duration(context.Background(), "episode.mp3")
+		if err != nil {
+			t.Fatalf("Duration() unexpected error: %v", err)
+		}
+		if want := 42*time.Minute + 10500*time.Millisecond; d != want {
+			t.Errorf("Duration() = %v, want %v", d, want)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Compile-time interface implementation check.
+var _ LevelMeter = (*FFmpegLevelMeter)(nil)
+
+// Level thresholds, in dBFS.
+const (
+	// silentPeak is the peak below which a recording holds no usable signal:
+	// a muted or disconnected device records digital silence or faint noise.
+	silentPeak = -50.0
+	// quietPeak is the peak below which speech is too faint to transcribe well.
+	quietPeak = -30.0
+	// clippingPeak is the peak at or above which the signal is likely clipped.
+	clippingPeak = -0.5
+)
+
+// Levels are the loudness measurements of an audio file, in dBFS
+// (0 is the loudest representable sample).
+type Levels struct {
+	Peak float64 // Loudest sample
+	Mean float64 // Mean (RMS) volume
+}
+
+// Silent reports whether the audio holds no usable signal.
+func (l Levels) Silent() bool {
+	return l.Peak < silentPeak
+}
+
+// Quiet reports whether the audio has a signal too faint to transcribe well.
+func (l Levels) Quiet() bool {
+	return !l.Silent() && l.Peak < quietPeak
+}
+
+// Clipping reports whether the audio reaches full scale and is likely distorted.
+func (l Levels) Clipping() bool {
+	return l.Peak >= clippingPeak
+}
+
+// LevelMeter measures the loudness of audio files.
+type LevelMeter interface {
+	MeasureLevels(ctx context.Context, audioPath string) (Levels, error)
+}
+
+// FFmpegLevelMeter implements LevelMeter with the FFmpeg volumedetect filter.
+type FFmpegLevelMeter struct {
+	ffmpegPath string
+	cmd        commandRunner
+}
+
+// LevelMeterOption configures an FFmpegLevelMeter.
+type LevelMeterOption func(*FFmpegLevelMeter)
+
+// WithLevelMeterCommandRunner sets a custom command runner (for testing).
+func WithLevelMeterCommandRunner(r commandRunner) LevelMeterOption {
+	return func(m *FFmpegLevelMeter) {
+		m.cmd = r
+	}
+}
+
+// NewLevelMeter creates a level meter using the FFmpeg binary at ffmpegPath.
+func NewLevelMeter(ffmpegPath string, opts ...LevelMeterOption) (*FFmpegLevelMeter, error) {
+	if ffmpegPath == "" {
+		return nil, fmt.Errorf("ffmpeg path cannot be empty")
+	}
+	m := &FFmpegLevelMeter{
+		ffmpegPath: ffmpegPath,
+		cmd:        osCommandRunner{},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
+}
+
+// MeasureLevels decodes audioPath and returns its peak and mean volume.
+func (m *FFmpegLevelMeter) MeasureLevels(ctx context.Context, audioPath string) (Levels, error) {
+	args := []string{"-hide_banner", "-nostats", "-i", audioPath, "-af", "volumedetect", "-f", "null", "-"}
+	output, err := m.cmd.CombinedOutput(ctx, m.ffmpegPath, args)
+	if err != nil {
+		return Levels{}, fmt.Errorf("failed to measure levels of %s: %w", audioPath, err)
+	}
+	return parseVolumeDetect(string(output))
+}
+
+// volumeDetectPattern matches the volumedetect summary lines:
+//
+//	[Parsed_volumedetect_0 @ 0x...] mean_volume: -24.3 dB
+//	[Parsed_volumedetect_0 @ 0x...] max_volume: -3.1 dB
+//
+// Digital silence is reported as "-inf dB".
+var volumeDetectPattern = regexp.MustCompile(`(mean|max)_volume:\s*(-?inf|-?[\d.]+) dB`)
+
+// parseVolumeDetect extracts the levels from FFmpeg volumedetect output.
+func parseVolumeDetect(output string) (Levels, error) {
+	var (
+		levels          Levels
+		hasMean, hasMax bool
+	)
+	for _, m := range volumeDetectPattern.FindAllStringSubmatch(output, -1) {
+		v, err := strconv.ParseFloat(m[2], 64) // ParseFloat accepts "-inf"
+		if err != nil {
+			return Levels{}, fmt.Errorf("invalid volume %q: %w", m[2], err)
+		}
+		if m[1] == "mean" {
+			levels.Mean, hasMean = v, true
+		} else {
+			levels.Peak, hasMax = v, true
+		}
+	}
+	if !hasMean || !hasMax {
+		return Levels{}, fmt.Errorf("no volume measurement in ffmpeg output (empty recording?)")
+	}
+	return levels, nil
+}
+
+// IsLoopbackDevice reports whether a device name is a known loopback or
+// virtual device (BlackHole, Stereo Mix, PulseAudio monitors...), which
+// captures system audio rather than a microphone.
+func IsLoopbackDevice(name string) bool {
+	return isVirtualAudioDevice(name)
+}
+
+// loudnessFilter is the FFmpeg filter logging the loudness while recording.
+const loudnessFilter = "ebur128"
+
+// loudnessPattern matches the momentary loudness in ebur128 frame log lines:
+//
+//	[Parsed_ebur128_0 @ 0x...] t: 1.2      TARGET:-23 LUFS    M: -25.3 S: -27.1 ...
+var loudnessPattern = regexp.MustCompile(`TARGET:.*\sM:\s*(-?inf|-?[\d.]+)`)
+
+// parseLoudness returns the momentary loudness of an ebur128 frame log line.
+// Returns false for other lines.
+func parseLoudness(line []byte) (float64, bool) {
+	m := loudnessPattern.FindSubmatch(line)
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(string(m[1]), 64)
+	return v, err == nil
+}
@@ -0,0 +1,201 @@
+package audio_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+func TestFFmpegLevelMeter_MeasureLevels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		output   string
+		wantPeak float64
+		wantMean float64
+		wantErr  bool
+	}{
+		{
+			name: "speech",
+			output: "[Parsed_volumedetect_0 @ 0x1] n_samples: 48000\n" +
+				"[Parsed_volumedetect_0 @ 0x1] mean_volume: -24.3 dB\n" +
+				"[Parsed_volumedetect_0 @ 0x1] max_volume: -3.1 dB\n",
+			wantPeak: -3.1,
+			wantMean: -24.3,
+		},
+		{
+			name: "full scale",
+			output: "[Parsed_volumedetect_0 @ 0x1] mean_volume: -12.0 dB\n" +
+				"[Parsed_volumedetect_0 @ 0x1] max_volume: 0.0 dB\n",
+			wantPeak: 0,
+			wantMean: -12,
+		},
+		{
+			name: "digital silence",
+			output: "[Parsed_volumedetect_0 @ 0x1] mean_volume: -inf dB\n" +
+				"[Parsed_volumedetect_0 @ 0x1] max_volume: -inf dB\n",
+			wantPeak: math.Inf(-1),
+			wantMean: math.Inf(-1),
+		},
+		{
+			name:    "no measurement",
+			output:  "Output file is empty, nothing was encoded\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &mockCommandRunner{
+				outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+					return []byte(tt.output), nil
+				},
+			}
+			meter, err := audio.NewLevelMeter("/usr/bin/ffmpeg", audio.WithLevelMeterCommandRunner(runner))
+			if err != nil {
+				t.Fatalf("NewLevelMeter() unexpected error: %v", err)
+			}
+
+			got, err := meter.MeasureLevels(context.Background(), "test.ogg")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("MeasureLevels() = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MeasureLevels() unexpected error: %v", err)
+			}
+			if got.Peak != tt.wantPeak || got.Mean != tt.wantMean {
+				t.Errorf("MeasureLevels() = %+v, want peak %v, mean %v", got, tt.wantPeak, tt.wantMean)
+			}
+			if args := runner.calls[0].args; !slices.Contains(args, "volumedetect") || !slices.Contains(args, "test.ogg") {
+				t.Errorf("ffmpeg args = %v, want volumedetect on test.ogg", args)
+			}
+		})
+	}
+}
+
+func TestFFmpegLevelMeter_CommandError(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{
+		outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+			return nil, errors.New("exit status 1")
+		},
+	}
+	meter, _ := audio.NewLevelMeter("/usr/bin/ffmpeg", audio.WithLevelMeterCommandRunner(runner))
+
+	if _, err := meter.MeasureLevels(context.Background(), "test.ogg"); err == nil {
+		t.Error("MeasureLevels() expected error, got nil")
+	}
+}
+
+func TestNewLevelMeter_EmptyPath(t *testing.T) {
+	t.Parallel()
+
+	if _, err := audio.NewLevelMeter(""); err == nil {
+		t.Error("NewLevelMeter(\"\") expected error, got nil")
+	}
+}
+
+func TestLevels_Classification(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		peak         float64
+		wantSilent   bool
+		wantQuiet    bool
+		wantClipping bool
+	}{
+		{"digital silence", math.Inf(-1), true, false, false},
+		{"noise floor", -60, true, false, false},
+		{"faint", -40, false, true, false},
+		{"good", -6, false, false, false},
+		{"clipping", 0, false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			l := audio.Levels{Peak: tt.peak}
+			if l.Silent() != tt.wantSilent || l.Quiet() != tt.wantQuiet || l.Clipping() != tt.wantClipping {
+				t.Errorf("Levels{Peak: %v}: Silent=%v Quiet=%v Clipping=%v, want %v %v %v", tt.peak,
+					l.Silent(), l.Quiet(), l.Clipping(), tt.wantSilent, tt.wantQuiet, tt.wantClipping)
+			}
+		})
+	}
+}
+
+func TestIsLoopbackDevice(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{":1\tMacBook Pro Microphone", false},
+		{":2\tBlackHole 2ch", true},
+		{"alsa_output.pci-0000_00_1f.3.analog-stereo.monitor", true},
+		{"Stereo Mix (Realtek High Definition Audio)", true},
+		{"alsa_input.pci-0000_00_1f.3.analog-stereo", false},
+	}
+
+	for _, tt := range tests {
+		if got := audio.IsLoopbackDevice(tt.name); got != tt.want {
+			t.Errorf("IsLoopbackDevice(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseLoudness(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		output string
+		want   []float64
+	}{
+		{
+			name: "frame log lines",
+			output: "[Parsed_ebur128_0 @ 0x1] t: 0.1      TARGET:-23 LUFS    M: -25.3 S: -27.0     I: -24.1 LUFS       LRA:   0.0 LU\n" +
+				"[Parsed_ebur128_0 @ 0x1] t: 0.2      TARGET:-23 LUFS    M:-120.7 S:-120.7     I: -70.0 LUFS       LRA:   0.0 LU\n",
+			want: []float64{-25.3, -120.7},
+		},
+		{
+			name:   "interleaved progress with carriage returns",
+			output: "size=       1kB time=00:00:01.00 bitrate=   8.0kbits/s\r[Parsed_ebur128_0 @ 0x1] t: 1.1      TARGET:-23 LUFS    M: -18.0 S: -20.1\r",
+			want:   []float64{-18.0},
+		},
+		{
+			name:   "summary is ignored",
+			output: "[Parsed_ebur128_0 @ 0x1] Summary:\n\n  Integrated loudness:\n    I:         -24.1 LUFS\n",
+			want:   nil,
+		},
+		{
+			name:   "incomplete line is not reported",
+			output: "[Parsed_ebur128_0 @ 0x1] t: 0.1      TARGET:-23 LUFS    M: -25.3",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := audio.ParseLoudness(tt.output)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseLoudness() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -43,6 +43,11 @@ type loopbackDevice struct {
 	format string // FFmpeg input format (avfoundation, pulse, dshow)
 }
 
+// Name returns the name of the device, as FFmpeg lists it.
+func (d *loopbackDevice) Name() string {
+	return d.name
+}
+
 // DetectLoopbackDevice attempts to find a loopback device for the current OS.
 // Returns ErrLoopbackNotFound with installation instructions if not found.
 func DetectLoopbackDevice(ctx context.Context, ffmpegPath string) (*loopbackDevice, error) {
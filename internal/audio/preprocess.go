@@ -0,0 +1,104 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Compile-time interface implementation check.
+var _ Preprocessor = (*FFmpegPreprocessor)(nil)
+
+// FFmpeg filters of the preprocessing stages.
+const (
+	// denoiseFilter cuts the rumble below the voice (traffic, air conditioning,
+	// desk knocks), then reduces the broadband noise left, like hiss and fans.
+	denoiseFilter = "highpass=f=80,afftdn=nr=12:nf=-40"
+	// normalizeFilter brings the loudness to the level of speech in broadcast
+	// (EBU R128 for voice), raising faint speakers and taming loud ones.
+	normalizeFilter = "loudnorm=I=-16:LRA=11:TP=-1.5"
+)
+
+// Preprocessing selects the stages cleaning up audio before transcription.
+// The zero value leaves the audio unchanged.
+type Preprocessing struct {
+	Denoise   bool // Reduce background noise (highpass, afftdn)
+	Normalize bool // Normalize loudness (loudnorm)
+}
+
+// IsZero reports whether p has no stage.
+func (p Preprocessing) IsZero() bool {
+	return p == Preprocessing{}
+}
+
+// Filter returns the FFmpeg audio filter chain of p: noise is reduced
+// before the loudness is measured, so that it is not raised along with speech.
+func (p Preprocessing) Filter() string {
+	var filters []string
+	if p.Denoise {
+		filters = append(filters, denoiseFilter)
+	}
+	if p.Normalize {
+		filters = append(filters, normalizeFilter)
+	}
+	return strings.Join(filters, ",")
+}
+
+// Preprocessor cleans up audio before it is chunked and transcribed.
+type Preprocessor interface {
+	// Preprocess writes the first audio track of inputPath to outputPath, in
+	// the encoding of chunks (OGG Opus, 16kHz mono), through the stages of p.
+	// inputPath may be a video or the list of a segmented recording, like for
+	// AudioExtractor. onProgress, if not nil, is called as preprocessing
+	// advances, with the duration of audio done so far and the duration of the
+	// input (0 if unknown).
+	Preprocess(ctx context.Context, inputPath, outputPath string, p Preprocessing, onProgress func(done, total time.Duration)) error
+}
+
+// FFmpegPreprocessor implements Preprocessor with FFmpeg filters.
+type FFmpegPreprocessor struct {
+	ffmpegPath string
+	cmd        commandRunner // Probes the duration
+	runner     ffmpegRunner  // Runs the filters, streaming their progress
+}
+
+// PreprocessorOption configures an FFmpegPreprocessor.
+type PreprocessorOption func(*FFmpegPreprocessor)
+
+// WithPreprocessorCommandRunner sets a custom command runner (for testing).
+func WithPreprocessorCommandRunner(r commandRunner) PreprocessorOption {
+	return func(pp *FFmpegPreprocessor) {
+		pp.cmd = r
+	}
+}
+
+// WithPreprocessorFFmpegRunner sets a custom FFmpeg runner (for testing).
+func WithPreprocessorFFmpegRunner(r ffmpegRunner) PreprocessorOption {
+	return func(pp *FFmpegPreprocessor) {
+		pp.runner = r
+	}
+}
+
+// NewPreprocessor creates a preprocessor using the FFmpeg binary at ffmpegPath.
+func NewPreprocessor(ffmpegPath string, opts ...PreprocessorOption) (*FFmpegPreprocessor, error) {
+	if ffmpegPath == "" {
+		return nil, fmt.Errorf("ffmpeg path cannot be empty")
+	}
+	pp := &FFmpegPreprocessor{
+		ffmpegPath: ffmpegPath,
+		cmd:        osCommandRunner{},
+		runner:     defaultFFmpegRunner{},
+	}
+	for _, opt := range opts {
+		opt(pp)
+	}
+	return pp, nil
+}
+
+// Preprocess filters the audio of inputPath to outputPath.
+// Returns ErrNoAudioTrack if the input has none.
+func (pp *FFmpegPreprocessor) Preprocess(ctx context.Context, inputPath, outputPath string, p Preprocessing, onProgress func(done, total time.Duration)) error {
+	args := extractAudioArgs(inputPath, outputPath, p.Filter())
+	return convertAudio(ctx, pp.cmd, pp.runner, pp.ffmpegPath, inputPath, args, ErrPreprocessingFailed, onProgress)
+}
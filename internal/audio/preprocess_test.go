@@ -0,0 +1,132 @@
+package audio_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+func TestPreprocessing_Filter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		p    audio.Preprocessing
+		want string
+	}{
+		{"none", audio.Preprocessing{}, ""},
+		{"denoise", audio.Preprocessing{Denoise: true}, "highpass=f=80,afftdn=nr=12:nf=-40"},
+		{"normalize", audio.Preprocessing{Normalize: true}, "loudnorm=I=-16:LRA=11:TP=-1.5"},
+		{"denoise then normalize", audio.Preprocessing{Denoise: true, Normalize: true}, "highpass=f=80,afftdn=nr=12:nf=-40,loudnorm=I=-16:LRA=11:TP=-1.5"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.p.Filter(); got != tt.want {
+			t.Errorf("%s: Filter() = %q, want %q", tt.name, got, tt.want)
+		}
+		if got := tt.p.IsZero(); got != (tt.want == "") {
+			t.Errorf("%s: IsZero() = %v, want %v", tt.name, got, tt.want == "")
+		}
+	}
+}
+
+func TestFFmpegPreprocessor_Preprocess(t *testing.T) {
+	t.Parallel()
+
+	probe := &mockCommandRunner{
+		outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+			return []byte("  Duration: 00:10:00.00, start: 0.000000, bitrate: 64 kb/s\n"), nil
+		},
+	}
+
+	t.Run("filters and reports progress", func(t *testing.T) {
+		t.Parallel()
+
+		var gotArgs []string
+		runner := &mockFFmpegRunner{
+			runGracefulWithStderrFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error {
+				gotArgs = args
+				_, _ = io.WriteString(w, "size=    1024kB time=00:10:00.00 bitrate=  13.9kbits/s speed=40x\n")
+				return nil
+			},
+		}
+		pp, err := audio.NewPreprocessor("/usr/bin/ffmpeg",
+			audio.WithPreprocessorCommandRunner(probe), audio.WithPreprocessorFFmpegRunner(runner))
+		if err != nil {
+			t.Fatalf("NewPreprocessor() unexpected error: %v", err)
+		}
+
+		var done []time.Duration
+		p := audio.Preprocessing{Denoise: true, Normalize: true}
+		err = pp.Preprocess(context.Background(), "noisy.ogg", "clean.ogg", p, func(d, total time.Duration) {
+			done = append(done, d)
+		})
+		if err != nil {
+			t.Fatalf("Preprocess() unexpected error: %v", err)
+		}
+		if want := []time.Duration{10 * time.Minute}; !slices.Equal(done, want) {
+			t.Errorf("onProgress done = %v, want %v", done, want)
+		}
+		i := slices.Index(gotArgs, "-af")
+		if i < 0 || i+1 >= len(gotArgs) || gotArgs[i+1] != p.Filter() {
+			t.Errorf("ffmpeg args = %v, want -af %q", gotArgs, p.Filter())
+		}
+		for _, want := range []string{"noisy.ogg", "0:a:0", "libopus", "clean.ogg"} {
+			if !slices.Contains(gotArgs, want) {
+				t.Errorf("ffmpeg args = %v, want containing %q", gotArgs, want)
+			}
+		}
+	})
+
+	t.Run("joins the files of a segment list", func(t *testing.T) {
+		t.Parallel()
+
+		var gotArgs []string
+		runner := &mockFFmpegRunner{
+			runGracefulWithStderrFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error {
+				gotArgs = args
+				return nil
+			},
+		}
+		pp, _ := audio.NewPreprocessor("/usr/bin/ffmpeg",
+			audio.WithPreprocessorCommandRunner(probe), audio.WithPreprocessorFFmpegRunner(runner))
+
+		if err := pp.Preprocess(context.Background(), "day.ffconcat", "clean.ogg", audio.Preprocessing{Normalize: true}, nil); err != nil {
+			t.Fatalf("Preprocess() unexpected error: %v", err)
+		}
+		want := []string{"-y", "-f", "concat", "-i", "day.ffconcat"}
+		if !slices.Equal(gotArgs[:len(want)], want) {
+			t.Errorf("ffmpeg args = %v, want starting with %v", gotArgs, want)
+		}
+	})
+
+	t.Run("ffmpeg failure", func(t *testing.T) {
+		t.Parallel()
+
+		runner := &mockFFmpegRunner{
+			runGracefulWithStderrFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error {
+				return errors.New("exit status 1")
+			},
+		}
+		pp, _ := audio.NewPreprocessor("/usr/bin/ffmpeg",
+			audio.WithPreprocessorCommandRunner(probe), audio.WithPreprocessorFFmpegRunner(runner))
+
+		err := pp.Preprocess(context.Background(), "broken.ogg", "clean.ogg", audio.Preprocessing{Denoise: true}, nil)
+		if !errors.Is(err, audio.ErrPreprocessingFailed) {
+			t.Errorf("Preprocess() error = %v, want ErrPreprocessingFailed", err)
+		}
+	})
+}
+
+func TestNewPreprocessor_EmptyPath(t *testing.T) {
+	t.Parallel()
+
+	if _, err := audio.NewPreprocessor(""); err == nil {
+		t.Error("NewPreprocessor(\"\") expected error")
+	}
+}
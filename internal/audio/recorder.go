@@ -3,7 +3,9 @@ package audio
 import (
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strconv"
@@ -15,8 +17,14 @@ import (
 
 // Compile-time interface implementation checks.
 var (
-	_ Recorder     = (*FFmpegRecorder)(nil)
-	_ DeviceLister = (*FFmpegRecorder)(nil)
+	_ Recorder          = (*FFmpegRecorder)(nil)
+	_ StreamRecorder    = (*FFmpegRecorder)(nil)
+	_ SegmentedRecorder = (*FFmpegRecorder)(nil)
+	_ LevelMonitor      = (*FFmpegRecorder)(nil)
+	_ SilenceStopper    = (*FFmpegRecorder)(nil)
+	_ MixBalancer       = (*FFmpegRecorder)(nil)
+	_ TrackSeparator    = (*FFmpegRecorder)(nil)
+	_ DeviceLister      = (*FFmpegRecorder)(nil)
 )
 
 // Recorder records audio from an input device to a file.
@@ -24,6 +32,61 @@ type Recorder interface {
 	Record(ctx context.Context, duration time.Duration, output string) error
 }
 
+// StreamRecorder records audio while exposing it as fixed-length segments,
+// so that transcription can start before the recording is finished.
+type StreamRecorder interface {
+	RecordStream(ctx context.Context, duration time.Duration, output, segmentDir string, segmentDuration time.Duration) error
+}
+
+// SegmentedRecorder records long sessions to rotating files instead of one,
+// so that a crash loses at most the file being written.
+type SegmentedRecorder interface {
+	RecordSegmented(ctx context.Context, duration time.Duration, output string, segmentDuration time.Duration) error
+}
+
+// LevelMonitor reports the loudness of the input while recording.
+type LevelMonitor interface {
+	// MonitorLevels calls fn with the momentary loudness of the input, in LUFS,
+	// about ten times per second during the following recordings.
+	// fn is called from another goroutine. A nil fn disables monitoring.
+	MonitorLevels(fn func(loudness float64))
+}
+
+// SilenceStopper ends recordings once the input has been silent for too long.
+type SilenceStopper interface {
+	// StopOnSilence ends the following recordings, as if interrupted, once the
+	// input stays silent for d, and then calls onStop (if not nil).
+	// Zero disables it.
+	StopOnSilence(d time.Duration, onStop func())
+}
+
+// MixBalance sets the level of each input of a mix recording.
+// The zero value mixes both inputs at their own level.
+type MixBalance struct {
+	MicGain    float64 // Gain of the microphone, in dB
+	SystemGain float64 // Gain of the system audio, in dB
+	Auto       bool    // Normalize the loudness of both inputs before applying the gains
+}
+
+// IsZero reports whether b leaves both inputs at their own level.
+func (b MixBalance) IsZero() bool {
+	return b == MixBalance{}
+}
+
+// MixBalancer sets the levels of the inputs of mix recordings.
+type MixBalancer interface {
+	// BalanceMix applies b to the following recordings.
+	BalanceMix(b MixBalance)
+}
+
+// TrackSeparator writes the inputs of mix recordings to separate files.
+type TrackSeparator interface {
+	// SeparateTracks also writes the microphone to mic and the system audio
+	// to system during the following mix recordings, synchronized with the
+	// mix. Empty paths disable it.
+	SeparateTracks(mic, system string)
+}
+
 // DeviceLister lists available audio input devices.
 type DeviceLister interface {
 	ListDevices(ctx context.Context) ([]string, error)
@@ -51,6 +114,12 @@ type FFmpegRecorder struct {
 	device      string          // Empty string means auto-detect default device.
 	captureMode CaptureMode     // Microphone, loopback, or mix.
 	loopback    *loopbackDevice // Cached loopback device (for loopback/mix modes).
+	onLevel     func(float64)   // Loudness callback (nil: no monitoring).
+	stopSilence time.Duration   // Silence ending the recording (0: never).
+	onSilence   func()          // Called when a silence ends the recording.
+	balance     MixBalance      // Levels of the inputs (mix mode).
+	micTrack    string          // Separate file of the microphone (mix mode, empty: none).
+	systemTrack string          // Separate file of the system audio (mix mode, empty: none).
 
 	// Injectable dependencies (defaults to real implementations).
 	ffmpegRunner ffmpegRunner
@@ -61,6 +130,7 @@ type FFmpegRecorder struct {
 type ffmpegRunner interface {
 	RunOutput(ctx context.Context, ffmpegPath string, args []string) (string, error)
 	RunGraceful(ctx context.Context, ffmpegPath string, args []string, gracefulTimeout time.Duration) error
+	RunGracefulWithStderr(ctx context.Context, ffmpegPath string, args []string, gracefulTimeout time.Duration, w io.Writer) error
 }
 
 // pactlRunner runs pactl for PulseAudio device discovery.
@@ -96,6 +166,10 @@ func (defaultFFmpegRunner) RunGraceful(ctx context.Context, ffmpegPath string, a
 	return ffmpeg.RunGraceful(ctx, ffmpegPath, args, gracefulTimeout)
 }
 
+func (defaultFFmpegRunner) RunGracefulWithStderr(ctx context.Context, ffmpegPath string, args []string, gracefulTimeout time.Duration, w io.Writer) error {
+	return ffmpeg.RunGracefulWithStderr(ctx, ffmpegPath, args, gracefulTimeout, w)
+}
+
 // defaultPactlRunner implements pactlRunner using exec.Command.
 type defaultPactlRunner struct{}
 
@@ -191,18 +265,98 @@ func NewFFmpegMixRecorder(ctx context.Context, ffmpegPath, micDevice string, opt
 // If device is empty, it auto-detects the default audio input device.
 // Recording can be interrupted via context cancellation (Ctrl+C).
 func (r *FFmpegRecorder) Record(ctx context.Context, duration time.Duration, output string) error {
+	return r.record(ctx, duration, recordOutput{path: output})
+}
+
+// RecordStream records like Record, and additionally writes the recording as
+// consecutive segments of segmentDuration into segmentDir while it is in progress.
+// Segments are named by SegmentPath and can be consumed with a SegmentWatcher.
+// The audio is encoded once and written to both destinations via the tee muxer.
+func (r *FFmpegRecorder) RecordStream(ctx context.Context, duration time.Duration, output, segmentDir string, segmentDuration time.Duration) error {
+	if segmentDuration < time.Second {
+		return fmt.Errorf("segment duration %v is below 1s: %w", segmentDuration, ErrInvalidSegmentDuration)
+	}
+	return r.record(ctx, duration, recordOutput{
+		path:            output,
+		segmentDir:      segmentDir,
+		segmentDuration: segmentDuration,
+	})
+}
+
+// RecordSegmented records like Record, but to consecutive files of
+// segmentDuration named by RecordingSegmentPath(output, i), listed in order in
+// SegmentListPath(output) as each one is completed. The list is a single
+// input for FFmpeg (and transcription), joining the files.
+func (r *FFmpegRecorder) RecordSegmented(ctx context.Context, duration time.Duration, output string, segmentDuration time.Duration) error {
+	if segmentDuration < time.Second {
+		return fmt.Errorf("segment duration %v is below 1s: %w", segmentDuration, ErrInvalidSegmentDuration)
+	}
+	return r.record(ctx, duration, recordOutput{
+		path:            output,
+		segmentDuration: segmentDuration,
+		rotate:          true,
+	})
+}
+
+// MonitorLevels sets the callback receiving the input loudness while recording.
+// The loudness is measured by the FFmpeg ebur128 filter, which logs the
+// momentary loudness (400ms window) every 100ms.
+func (r *FFmpegRecorder) MonitorLevels(fn func(loudness float64)) {
+	r.onLevel = fn
+}
+
+// StopOnSilence ends the following recordings once the input stays below
+// silentPeak for d, detected by the FFmpeg silencedetect filter, and then
+// calls onStop. The recording is finalized as if interrupted.
+func (r *FFmpegRecorder) StopOnSilence(d time.Duration, onStop func()) {
+	r.stopSilence = d
+	r.onSilence = onStop
+}
+
+// BalanceMix sets the levels of the microphone and system audio of the
+// following mix recordings, with FFmpeg volume filters applied to each input
+// before amix. b.Auto first normalizes both inputs to the same loudness with
+// loudnorm, so that neither drowns the other. Other capture modes ignore it.
+func (r *FFmpegRecorder) BalanceMix(b MixBalance) {
+	r.balance = b
+}
+
+// SeparateTracks also writes the microphone and the system audio of the
+// following mix recordings to their own file, before their levels are
+// balanced. The same FFmpeg process records the mix and both tracks, so they
+// start together. Other capture modes ignore it.
+func (r *FFmpegRecorder) SeparateTracks(mic, system string) {
+	r.micTrack, r.systemTrack = mic, system
+}
+
+// filter returns the audio filter chain monitoring the recording, or an empty
+// string if nothing is monitored. The filters pass the audio through unchanged
+// and log their measurements, which run parses.
+func (r *FFmpegRecorder) filter() string {
+	var filters []string
+	if r.onLevel != nil {
+		filters = append(filters, loudnessFilter)
+	}
+	if r.stopSilence > 0 {
+		filters = append(filters, fmt.Sprintf("silencedetect=noise=%ddB:d=%.2f", int(silentPeak), r.stopSilence.Seconds()))
+	}
+	return strings.Join(filters, ",")
+}
+
+// record dispatches to the capture-mode specific recording method.
+func (r *FFmpegRecorder) record(ctx context.Context, duration time.Duration, out recordOutput) error {
 	switch r.captureMode {
 	case CaptureLoopback:
-		return r.recordLoopback(ctx, duration, output)
+		return r.recordLoopback(ctx, duration, out)
 	case CaptureMix:
-		return r.recordMix(ctx, duration, output)
+		return r.recordMix(ctx, duration, out)
 	default:
-		return r.recordMicrophone(ctx, duration, output)
+		return r.recordMicrophone(ctx, duration, out)
 	}
 }
 
 // recordMicrophone records from the microphone input device.
-func (r *FFmpegRecorder) recordMicrophone(ctx context.Context, duration time.Duration, output string) error {
+func (r *FFmpegRecorder) recordMicrophone(ctx context.Context, duration time.Duration, out recordOutput) error {
 	device := r.device
 	if device == "" {
 		detected, err := r.detectDefaultDevice(ctx)
@@ -215,16 +369,71 @@ func (r *FFmpegRecorder) recordMicrophone(ctx context.Context, duration time.Dur
 	format := inputFormat()
 	inputArg := formatInputArg(format, device)
 
-	return r.recordFromInput(ctx, format, inputArg, duration, output)
+	return r.recordFromInput(ctx, format, inputArg, duration, out)
 }
 
 // recordFromInput records from a specified input source.
 // This is the core recording function used by all capture modes.
 // inputFormat is the FFmpeg input format (e.g., "avfoundation", "lavfi").
 // inputArg is the FFmpeg -i argument (e.g., ":0", "anullsrc=r=16000:cl=mono").
-func (r *FFmpegRecorder) recordFromInput(ctx context.Context, inputFormat, inputArg string, duration time.Duration, output string) error {
-	args := buildRecordArgs(inputFormat, inputArg, duration, output)
-	return r.ffmpegRunner.RunGraceful(ctx, r.ffmpegPath, args, gracefulShutdownTimeout)
+func (r *FFmpegRecorder) recordFromInput(ctx context.Context, inputFormat, inputArg string, duration time.Duration, out recordOutput) error {
+	args := buildOutputRecordArgs(inputFormat, inputArg, duration, out, r.filter())
+	return r.run(ctx, args)
+}
+
+// run executes a recording FFmpeg command, following the measurements logged
+// by the monitoring filters: loudness, and silence ending the recording.
+func (r *FFmpegRecorder) run(ctx context.Context, args []string) error {
+	if r.onLevel == nil && r.stopSilence <= 0 {
+		return r.ffmpegRunner.RunGraceful(ctx, r.ffmpegPath, args, gracefulShutdownTimeout)
+	}
+
+	// Stopping on silence cancels the recording: FFmpeg finalizes the file.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var stopped bool
+
+	w := &lineWriter{fn: func(line []byte) {
+		if r.onLevel != nil {
+			if loudness, ok := parseLoudness(line); ok {
+				r.onLevel(loudness)
+			}
+		}
+		if r.stopSilence > 0 && !stopped && silenceStartPattern.Match(line) {
+			stopped = true
+			cancel()
+			if r.onSilence != nil {
+				r.onSilence()
+			}
+		}
+	}}
+	return r.ffmpegRunner.RunGracefulWithStderr(ctx, r.ffmpegPath, args, gracefulShutdownTimeout, w)
+}
+
+// maxLogLine bounds the length of a buffered FFmpeg log line.
+const maxLogLine = 4096
+
+// lineWriter splits the FFmpeg stderr written to it into lines, and passes
+// each complete line to fn.
+type lineWriter struct {
+	fn   func(line []byte)
+	line []byte
+}
+
+// Write buffers p and passes on each complete line. FFmpeg ends progress
+// lines with a carriage return, so both \r and \n end a line.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	for _, c := range p {
+		if c == '\n' || c == '\r' {
+			w.fn(w.line)
+			w.line = w.line[:0]
+			continue
+		}
+		if len(w.line) < maxLogLine {
+			w.line = append(w.line, c)
+		}
+	}
+	return len(p), nil
 }
 
 // gracefulShutdownTimeout is the time to wait for FFmpeg to finalize the file.
@@ -233,25 +442,127 @@ const gracefulShutdownTimeout = 5 * time.Second
 // buildRecordArgs constructs FFmpeg arguments for recording.
 // Uses encodingArgs() for consistent output encoding across all record methods.
 func buildRecordArgs(inputFormat, inputArg string, duration time.Duration, output string) []string {
+	return buildOutputRecordArgs(inputFormat, inputArg, duration, recordOutput{path: output}, "")
+}
+
+// buildOutputRecordArgs constructs FFmpeg arguments for recording a single input
+// to the given output (a plain file, or a file plus streamed segments).
+// filter is the audio filter chain applied before encoding (empty: none).
+func buildOutputRecordArgs(inputFormat, inputArg string, duration time.Duration, out recordOutput, filter string) []string {
 	args := []string{
 		"-y",              // Overwrite output without asking.
 		"-f", inputFormat, // Input format.
 		"-i", inputArg, // Input source.
 		"-t", strconv.Itoa(int(duration.Seconds())), // Duration in seconds.
 	}
-	args = append(args, encodingArgs()...)
-	args = append(args, output)
+	if filter != "" {
+		args = append(args, "-af", filter)
+	}
+	args = append(args, out.args("0:a")...)
 	return args
 }
 
+// recordOutput describes where a recording is written.
+type recordOutput struct {
+	path            string        // Full recording file, or name of the rotated files.
+	segmentDir      string        // Directory for streamed segments (empty: no streaming).
+	segmentDuration time.Duration // Length of each streamed or rotated segment.
+	rotate          bool          // Rotated files instead of a full recording.
+}
+
+// args returns the encoding and output arguments for this destination.
+// mapSpec selects the stream to encode when streaming: unlike regular outputs,
+// the tee muxer does not select an audio stream automatically.
+func (o recordOutput) args(mapSpec string) []string {
+	args := encodingArgs()
+	if o.rotate {
+		return append(args, rotationArgs(o.path, o.segmentDuration)...)
+	}
+	if o.segmentDir == "" {
+		return append(args, o.path)
+	}
+	return append(args,
+		"-map", mapSpec,
+		"-f", "tee",
+		teeTargets(o.path, o.segmentDir, o.segmentDuration),
+	)
+}
+
+// mixLoudness is the loudnorm filter bringing both inputs of a balanced mix
+// to the same loudness (EBU R128 target).
+const mixLoudness = "loudnorm=I=-23:LRA=11:TP=-2"
+
+// mixInputsFilter returns the filters applied to the microphone (input 0) and
+// system audio (input 1) before amix, ending with the labels amix reads, or an
+// empty string if the inputs are mixed as they are.
+func mixInputsFilter(b MixBalance) string {
+	if b.IsZero() {
+		return ""
+	}
+	chain := func(gain float64) string {
+		var filters []string
+		if b.Auto {
+			filters = append(filters, mixLoudness)
+		}
+		if gain != 0 {
+			filters = append(filters, "volume="+strconv.FormatFloat(gain, 'f', -1, 64)+"dB")
+		}
+		if len(filters) == 0 {
+			return "anull"
+		}
+		return strings.Join(filters, ",")
+	}
+	return "[0:a]" + chain(b.MicGain) + "[mic];[1:a]" + chain(b.SystemGain) + "[sys];[mic][sys]"
+}
+
+// trackInputsFilter splits the microphone (input 0) and system audio (input 1)
+// in two: one copy of each is labeled for its track, as recorded, and the
+// other goes to amix through the filters of b, ending with the labels amix reads.
+func trackInputsFilter(b MixBalance) string {
+	split := "[0:a]asplit[mic0][mictrack];[1:a]asplit[sys0][systrack];"
+	if b.IsZero() {
+		return split + "[mic0][sys0]"
+	}
+	return split + strings.NewReplacer("[0:a]", "[mic0]", "[1:a]", "[sys0]").Replace(mixInputsFilter(b))
+}
+
+// teeTargets builds the tee muxer output list: the full recording, plus a
+// segment muxer writing fixed-length files that are complete as soon as the
+// next one starts.
+func teeTargets(output, segmentDir string, segmentDuration time.Duration) string {
+	segmentOpts := fmt.Sprintf("[f=segment:segment_time=%d:reset_timestamps=1]", int(segmentDuration.Seconds()))
+	return escapeTeePath(output) + "|" + segmentOpts + escapeTeePath(SegmentPath(segmentDir, -1))
+}
+
+// rotationArgs returns the segment muxer arguments writing the recording to
+// rotating files named after output, and maintaining their list. Timestamps
+// restart in each file, so that each one also plays on its own.
+func rotationArgs(output string, segmentDuration time.Duration) []string {
+	return []string{
+		"-f", "segment",
+		"-segment_time", strconv.Itoa(int(segmentDuration.Seconds())),
+		"-reset_timestamps", "1",
+		"-segment_list", SegmentListPath(output),
+		"-segment_list_type", "ffconcat",
+		RecordingSegmentPath(output, -1),
+	}
+}
+
+// escapeTeePath escapes characters with special meaning in tee output lists.
+// Paths use forward slashes, which FFmpeg accepts on every platform.
+func escapeTeePath(path string) string {
+	path = filepath.ToSlash(path)
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "[", `\[`).Replace(path)
+}
+
 // recordLoopback records from the loopback device (system audio).
-func (r *FFmpegRecorder) recordLoopback(ctx context.Context, duration time.Duration, output string) error {
+func (r *FFmpegRecorder) recordLoopback(ctx context.Context, duration time.Duration, out recordOutput) error {
 	// Loopback device was detected and cached in NewFFmpegLoopbackRecorder.
-	return r.recordFromInput(ctx, r.loopback.format, r.loopback.name, duration, output)
+	return r.recordFromInput(ctx, r.loopback.format, r.loopback.name, duration, out)
 }
 
 // recordMix records both microphone and loopback mixed together.
-func (r *FFmpegRecorder) recordMix(ctx context.Context, duration time.Duration, output string) error {
+func (r *FFmpegRecorder) recordMix(ctx context.Context, duration time.Duration, out recordOutput) error {
 	// Get microphone device
 	micDevice := r.device
 	if micDevice == "" {
@@ -268,6 +579,21 @@ func (r *FFmpegRecorder) recordMix(ctx context.Context, duration time.Duration,
 
 	// Build FFmpeg command with two inputs and amix filter.
 	// Uses same encoding settings as buildRecordArgs for consistency.
+	// The filter output is only labeled when streaming or writing the tracks,
+	// where it must be mapped explicitly.
+	separate := r.micTrack != "" && r.systemTrack != ""
+	inputs := mixInputsFilter(r.balance)
+	if separate {
+		inputs = trackInputsFilter(r.balance)
+	}
+	filter := inputs + "amix=inputs=2:duration=first:dropout_transition=2"
+	if monitor := r.filter(); monitor != "" {
+		filter += "," + monitor
+	}
+	if out.segmentDir != "" || separate {
+		filter += "[mix]"
+	}
+	seconds := strconv.Itoa(int(duration.Seconds()))
 	args := []string{
 		"-y", // Overwrite output without asking.
 		// Input 1: Microphone
@@ -277,13 +603,23 @@ func (r *FFmpegRecorder) recordMix(ctx context.Context, duration time.Duration,
 		"-f", r.loopback.format,
 		"-i", r.loopback.name,
 		// Mix both inputs
-		"-filter_complex", "amix=inputs=2:duration=first:dropout_transition=2",
-		"-t", strconv.Itoa(int(duration.Seconds())), // Duration in seconds.
+		"-filter_complex", filter,
+		"-t", seconds, // Duration in seconds.
+	}
+	if separate && out.segmentDir == "" {
+		args = append(args, "-map", "[mix]")
+	}
+	args = append(args, out.args("[mix]")...)
+	if separate {
+		// Output options apply to the next output: each track repeats them
+		for _, track := range []struct{ label, path string }{{"[mictrack]", r.micTrack}, {"[systrack]", r.systemTrack}} {
+			args = append(args, "-map", track.label, "-t", seconds)
+			args = append(args, encodingArgs()...)
+			args = append(args, track.path)
+		}
 	}
-	args = append(args, encodingArgs()...)
-	args = append(args, output)
 
-	return r.ffmpegRunner.RunGraceful(ctx, r.ffmpegPath, args, gracefulShutdownTimeout)
+	return r.run(ctx, args)
 }
 
 // encodingArgs returns the standard encoding arguments for OGG Opus output.
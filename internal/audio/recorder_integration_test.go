@@ -9,6 +9,7 @@ package audio_test
 
 import (
 	"context"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -211,6 +212,8 @@ func TestNewFFmpegMixRecorder_AcceptsOptions(t *testing.T) {
 type testFFmpegRunner struct {
 	runOutputFunc   func(ctx context.Context, ffmpegPath string, args []string) (string, error)
 	runGracefulFunc func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration) error
+
+	runGracefulWithStderrFunc func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error
 }
 
 func (r *testFFmpegRunner) RunOutput(ctx context.Context, ffmpegPath string, args []string) (string, error) {
@@ -226,3 +229,10 @@ func (r *testFFmpegRunner) RunGraceful(ctx context.Context, ffmpegPath string, a
 	}
 	return nil
 }
+
+func (r *testFFmpegRunner) RunGracefulWithStderr(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error {
+	if r.runGracefulWithStderrFunc != nil {
+		return r.runGracefulWithStderrFunc(ctx, ffmpegPath, args, timeout, w)
+	}
+	return r.RunGraceful(ctx, ffmpegPath, args, timeout)
+}
@@ -9,7 +9,9 @@ package audio_test
 import (
 	"context"
 	"errors"
+	"io"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -238,6 +240,50 @@ func TestBuildRecordArgs(t *testing.T) {
 	}
 }
 
+func TestBuildStreamRecordArgs(t *testing.T) {
+	t.Parallel()
+
+	args := audio.BuildStreamRecordArgs("alsa", "default", 60, "/tmp/rec.ogg", "/tmp/segments", 30)
+	argsStr := strings.Join(args, " ")
+
+	required := []string{
+		"-f alsa",
+		"-i default",
+		"-t 60",
+		"-c:a libopus",
+		"-map 0:a",
+		"-f tee",
+	}
+	for _, r := range required {
+		if !strings.Contains(argsStr, r) {
+			t.Errorf("BuildStreamRecordArgs() missing %q in %v", r, args)
+		}
+	}
+
+	want := "/tmp/rec.ogg|[f=segment:segment_time=30:reset_timestamps=1]/tmp/segments/segment_%04d.ogg"
+	if got := args[len(args)-1]; got != want {
+		t.Errorf("tee targets = %q, want %q", got, want)
+	}
+}
+
+func TestEscapeTeePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"/tmp/rec.ogg", "/tmp/rec.ogg"},
+		{"/tmp/a|b.ogg", `/tmp/a\|b.ogg`},
+		{"/tmp/[x].ogg", `/tmp/\[x].ogg`},
+	}
+	for _, tt := range tests {
+		if got := audio.EscapeTeePath(tt.in); got != tt.want {
+			t.Errorf("EscapeTeePath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // EncodingArgs - Encoding arguments
 // ---------------------------------------------------------------------------
@@ -602,6 +648,320 @@ func TestFFmpegRecorder_Record(t *testing.T) {
 	})
 }
 
+// ---------------------------------------------------------------------------
+// FFmpegRecorder.RecordStream - Streaming recording with mocks
+// ---------------------------------------------------------------------------
+
+func TestFFmpegRecorder_RecordStream(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes recording and segments through tee", func(t *testing.T) {
+		t.Parallel()
+
+		var gotArgs []string
+		mockRunner := &mockFFmpegRunner{
+			runGracefulFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration) error {
+				gotArgs = args
+				return nil
+			},
+		}
+
+		rec, _ := audio.NewFFmpegRecorder("/usr/bin/ffmpeg", ":0", audio.ExportedWithFFmpegRunner(mockRunner))
+		err := rec.RecordStream(context.Background(), time.Minute, "/tmp/rec.ogg", "/tmp/seg", 30*time.Second)
+		if err != nil {
+			t.Fatalf("RecordStream() unexpected error: %v", err)
+		}
+
+		argsStr := strings.Join(gotArgs, " ")
+		if !strings.Contains(argsStr, "-f tee") || !strings.Contains(argsStr, "segment_time=30") {
+			t.Errorf("RecordStream() args = %v, want tee muxer with 30s segments", gotArgs)
+		}
+	})
+
+	t.Run("rejects segment duration below one second", func(t *testing.T) {
+		t.Parallel()
+
+		rec, _ := audio.NewFFmpegRecorder("/usr/bin/ffmpeg", ":0", audio.ExportedWithFFmpegRunner(&mockFFmpegRunner{}))
+		err := rec.RecordStream(context.Background(), time.Minute, "/tmp/rec.ogg", "/tmp/seg", 500*time.Millisecond)
+		if !errors.Is(err, audio.ErrInvalidSegmentDuration) {
+			t.Errorf("RecordStream() error = %v, want ErrInvalidSegmentDuration", err)
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// FFmpegRecorder.RecordSegmented - Rotating files with mocks
+// ---------------------------------------------------------------------------
+
+func TestFFmpegRecorder_RecordSegmented(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes rotating files and their list", func(t *testing.T) {
+		t.Parallel()
+
+		var gotArgs []string
+		mockRunner := &mockFFmpegRunner{
+			runGracefulFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration) error {
+				gotArgs = args
+				return nil
+			},
+		}
+
+		rec, _ := audio.NewFFmpegRecorder("/usr/bin/ffmpeg", ":0", audio.ExportedWithFFmpegRunner(mockRunner))
+		err := rec.RecordSegmented(context.Background(), 2*time.Hour, "/tmp/meeting.ogg", 30*time.Minute)
+		if err != nil {
+			t.Fatalf("RecordSegmented() unexpected error: %v", err)
+		}
+
+		argsStr := strings.Join(gotArgs, " ")
+		want := "-f segment -segment_time 1800 -reset_timestamps 1 -segment_list /tmp/meeting.ffconcat -segment_list_type ffconcat /tmp/meeting_%04d.ogg"
+		if !strings.HasSuffix(argsStr, want) {
+			t.Errorf("RecordSegmented() args = %q, want ending with %q", argsStr, want)
+		}
+	})
+
+	t.Run("rejects segment duration below one second", func(t *testing.T) {
+		t.Parallel()
+
+		rec, _ := audio.NewFFmpegRecorder("/usr/bin/ffmpeg", ":0", audio.ExportedWithFFmpegRunner(&mockFFmpegRunner{}))
+		err := rec.RecordSegmented(context.Background(), time.Minute, "/tmp/meeting.ogg", 0)
+		if !errors.Is(err, audio.ErrInvalidSegmentDuration) {
+			t.Errorf("RecordSegmented() error = %v, want ErrInvalidSegmentDuration", err)
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// FFmpegRecorder.MonitorLevels - Loudness reported while recording
+// ---------------------------------------------------------------------------
+
+func TestFFmpegRecorder_MonitorLevels(t *testing.T) {
+	t.Parallel()
+
+	const logLine = "[Parsed_ebur128_0 @ 0x1] t: 0.5      TARGET:-23 LUFS    M: -31.2 S:-120.7     I: -70.0 LUFS\n"
+
+	var gotArgs []string
+	mockRunner := &mockFFmpegRunner{
+		runGracefulWithStderrFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error {
+			gotArgs = args
+			_, err := io.WriteString(w, logLine)
+			return err
+		},
+	}
+
+	rec, _ := audio.NewFFmpegRecorder("/usr/bin/ffmpeg", ":0", audio.ExportedWithFFmpegRunner(mockRunner))
+	var got []float64
+	rec.MonitorLevels(func(l float64) { got = append(got, l) })
+
+	if err := rec.Record(context.Background(), time.Minute, "/tmp/rec.ogg"); err != nil {
+		t.Fatalf("Record() unexpected error: %v", err)
+	}
+	if !strings.Contains(strings.Join(gotArgs, " "), "-af ebur128") {
+		t.Errorf("Record() args = %v, want ebur128 filter", gotArgs)
+	}
+	if len(got) != 1 || got[0] != -31.2 {
+		t.Errorf("MonitorLevels() callback got %v, want [-31.2]", got)
+	}
+}
+
+func TestFFmpegRecorder_StopOnSilence(t *testing.T) {
+	t.Parallel()
+
+	var (
+		gotArgs     []string
+		canceled    bool
+		stopCalls   int
+		beforeStart error
+	)
+	mockRunner := &mockFFmpegRunner{
+		runGracefulWithStderrFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error {
+			gotArgs = args
+			_, _ = io.WriteString(w, "[silencedetect @ 0x1] silence_end: 12.5 | silence_duration: 2.0\n")
+			beforeStart = ctx.Err()
+			_, _ = io.WriteString(w, "[silencedetect @ 0x1] silence_start: 20.1\n")
+			canceled = ctx.Err() != nil
+			return nil
+		},
+	}
+
+	rec, _ := audio.NewFFmpegRecorder("/usr/bin/ffmpeg", ":0", audio.ExportedWithFFmpegRunner(mockRunner))
+	rec.StopOnSilence(5*time.Minute, func() { stopCalls++ })
+
+	if err := rec.Record(context.Background(), time.Hour, "/tmp/rec.ogg"); err != nil {
+		t.Fatalf("Record() unexpected error: %v", err)
+	}
+	if !strings.Contains(strings.Join(gotArgs, " "), "-af silencedetect=noise=-50dB:d=300.00") {
+		t.Errorf("Record() args = %v, want silencedetect filter with d=300", gotArgs)
+	}
+	if beforeStart != nil {
+		t.Errorf("recording stopped before silence was detected: %v", beforeStart)
+	}
+	if !canceled || stopCalls != 1 {
+		t.Errorf("after silence_start: canceled = %v, onStop calls = %d, want true, 1", canceled, stopCalls)
+	}
+}
+
+func TestFFmpegRecorder_MixMonitorFilters(t *testing.T) {
+	t.Parallel()
+
+	var gotArgs []string
+	mockRunner := &mockFFmpegRunner{
+		runGracefulWithStderrFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error {
+			gotArgs = args
+			return nil
+		},
+	}
+
+	rec := audio.NewMixRecorderForTest("/usr/bin/ffmpeg", ":0", mockRunner)
+	rec.MonitorLevels(func(float64) {})
+	rec.StopOnSilence(time.Minute, nil)
+
+	if err := rec.Record(context.Background(), time.Hour, "/tmp/rec.ogg"); err != nil {
+		t.Fatalf("Record() unexpected error: %v", err)
+	}
+	want := "amix=inputs=2:duration=first:dropout_transition=2,ebur128,silencedetect=noise=-50dB:d=60.00"
+	if !slices.Contains(gotArgs, want) {
+		t.Errorf("Record() args = %v, want filter %q", gotArgs, want)
+	}
+}
+
+func TestFFmpegRecorder_BalanceMix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		balance audio.MixBalance
+		want    string
+	}{
+		{
+			name: "equal levels",
+			want: "amix=inputs=2:duration=first:dropout_transition=2",
+		},
+		{
+			name:    "gains",
+			balance: audio.MixBalance{MicGain: 6, SystemGain: -3.5},
+			want:    "[0:a]volume=6dB[mic];[1:a]volume=-3.5dB[sys];[mic][sys]amix=inputs=2:duration=first:dropout_transition=2",
+		},
+		{
+			name:    "microphone gain only",
+			balance: audio.MixBalance{MicGain: 4},
+			want:    "[0:a]volume=4dB[mic];[1:a]anull[sys];[mic][sys]amix=inputs=2:duration=first:dropout_transition=2",
+		},
+		{
+			name:    "auto balance then gain",
+			balance: audio.MixBalance{Auto: true, SystemGain: -6},
+			want: "[0:a]loudnorm=I=-23:LRA=11:TP=-2[mic];[1:a]loudnorm=I=-23:LRA=11:TP=-2,volume=-6dB[sys];" +
+				"[mic][sys]amix=inputs=2:duration=first:dropout_transition=2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotArgs []string
+			mockRunner := &mockFFmpegRunner{
+				runGracefulFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration) error {
+					gotArgs = args
+					return nil
+				},
+			}
+
+			rec := audio.NewMixRecorderForTest("/usr/bin/ffmpeg", ":0", mockRunner)
+			rec.BalanceMix(tt.balance)
+			if err := rec.Record(context.Background(), time.Hour, "/tmp/rec.ogg"); err != nil {
+				t.Fatalf("Record() unexpected error: %v", err)
+			}
+			if !slices.Contains(gotArgs, tt.want) {
+				t.Errorf("Record() args = %v, want filter %q", gotArgs, tt.want)
+			}
+		})
+	}
+}
+
+func TestFFmpegRecorder_SeparateTracks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		balance audio.MixBalance
+		filter  string
+	}{
+		{
+			name: "equal levels",
+			filter: "[0:a]asplit[mic0][mictrack];[1:a]asplit[sys0][systrack];" +
+				"[mic0][sys0]amix=inputs=2:duration=first:dropout_transition=2[mix]",
+		},
+		{
+			name:    "balanced mix, tracks as recorded",
+			balance: audio.MixBalance{MicGain: 6},
+			filter: "[0:a]asplit[mic0][mictrack];[1:a]asplit[sys0][systrack];" +
+				"[mic0]volume=6dB[mic];[sys0]anull[sys];[mic][sys]amix=inputs=2:duration=first:dropout_transition=2[mix]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotArgs []string
+			mockRunner := &mockFFmpegRunner{
+				runGracefulFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration) error {
+					gotArgs = args
+					return nil
+				},
+			}
+
+			rec := audio.NewMixRecorderForTest("/usr/bin/ffmpeg", ":0", mockRunner)
+			rec.BalanceMix(tt.balance)
+			rec.SeparateTracks("/tmp/rec.mic.ogg", "/tmp/rec.system.ogg")
+			if err := rec.Record(context.Background(), time.Hour, "/tmp/rec.ogg"); err != nil {
+				t.Fatalf("Record() unexpected error: %v", err)
+			}
+
+			joined := strings.Join(gotArgs, " ")
+			if !slices.Contains(gotArgs, tt.filter) {
+				t.Errorf("Record() args = %v, want filter %q", gotArgs, tt.filter)
+			}
+			for _, want := range []string{
+				"-map [mix] ",
+				"-map [mictrack] -t 3600 ",
+				"-map [systrack] -t 3600 ",
+			} {
+				if !strings.Contains(joined, want) {
+					t.Errorf("Record() args = %q, want %q", joined, want)
+				}
+			}
+			mix := slices.Index(gotArgs, "/tmp/rec.ogg")
+			mic := slices.Index(gotArgs, "/tmp/rec.mic.ogg")
+			system := slices.Index(gotArgs, "/tmp/rec.system.ogg")
+			if mix < 0 || mic <= mix || system <= mic || system != len(gotArgs)-1 {
+				t.Errorf("Record() args = %v, want the mix, mic and system outputs in order", gotArgs)
+			}
+		})
+	}
+}
+
+func TestFFmpegRecorder_NoMonitorLevels(t *testing.T) {
+	t.Parallel()
+
+	var gotArgs []string
+	mockRunner := &mockFFmpegRunner{
+		runGracefulFunc: func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration) error {
+			gotArgs = args
+			return nil
+		},
+	}
+
+	rec, _ := audio.NewFFmpegRecorder("/usr/bin/ffmpeg", ":0", audio.ExportedWithFFmpegRunner(mockRunner))
+	if err := rec.Record(context.Background(), time.Minute, "/tmp/rec.ogg"); err != nil {
+		t.Fatalf("Record() unexpected error: %v", err)
+	}
+	if strings.Contains(strings.Join(gotArgs, " "), "ebur128") {
+		t.Errorf("Record() args = %v, want no ebur128 filter without monitoring", gotArgs)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // FFmpegRecorder.ListDevices - Device listing with mocks
 // ---------------------------------------------------------------------------
@@ -830,6 +1190,8 @@ func TestLoopbackRecorder_InitializesFFmpegRunner(t *testing.T) {
 type mockFFmpegRunner struct {
 	runOutputFunc   func(ctx context.Context, ffmpegPath string, args []string) (string, error)
 	runGracefulFunc func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration) error
+
+	runGracefulWithStderrFunc func(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error
 }
 
 func (m *mockFFmpegRunner) RunOutput(ctx context.Context, ffmpegPath string, args []string) (string, error) {
@@ -845,3 +1207,10 @@ func (m *mockFFmpegRunner) RunGraceful(ctx context.Context, ffmpegPath string, a
 	}
 	return nil
 }
+
+func (m *mockFFmpegRunner) RunGracefulWithStderr(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error {
+	if m.runGracefulWithStderrFunc != nil {
+		return m.runGracefulWithStderrFunc(ctx, ffmpegPath, args, timeout, w)
+	}
+	return m.RunGraceful(ctx, ffmpegPath, args, timeout)
+}
@@ -0,0 +1,140 @@
+package audio
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Built-in chunking strategies (see NewChunker).
+const (
+	// StrategySilence cuts at silences, under a size and duration limit (SilenceChunker).
+	StrategySilence = "silence"
+	// StrategyTime cuts fixed-duration chunks with overlap (TimeChunker).
+	StrategyTime = "time"
+	// StrategySize cuts fixed-size chunks, whatever their duration (SizeChunker).
+	StrategySize = "size"
+	// StrategyEnergy cuts at the quietest moments, under a size and duration limit (EnergyChunker).
+	StrategyEnergy = "energy"
+)
+
+// ChunkerConfig holds the settings given to chunker constructors.
+// Strategies ignore the settings they do not use.
+type ChunkerConfig struct {
+	FFmpegPath string
+	// ChunkSize is the chunk size in bytes: the maximum size of silence and
+	// energy chunks, the exact size of size chunks. 0 means the strategy default.
+	ChunkSize int64
+	// RunID names the temp directories of the chunks (see NewRunID).
+	// Empty means a new ID for each chunker.
+	RunID string
+	// ExtractWorkers is the number of chunks of silence and energy chunkers
+	// extracted at once. 0 means one per CPU.
+	ExtractWorkers int
+}
+
+// ChunkerConstructor creates a chunker from cfg.
+type ChunkerConstructor func(cfg ChunkerConfig) (Chunker, error)
+
+var (
+	chunkersMu sync.RWMutex
+	chunkers   = map[string]ChunkerConstructor{
+		StrategySilence: newSilenceChunker,
+		StrategyTime:    newTimeChunker,
+		StrategySize:    newSizeChunker,
+		StrategyEnergy:  newEnergyChunker,
+	}
+)
+
+// RegisterChunker makes a chunking strategy available under name, e.g. for
+// a voice activity detector. Like database/sql.Register, it is meant to be
+// called from init functions, and panics if name is empty or already
+// registered, or if newChunker is nil.
+func RegisterChunker(name string, newChunker ChunkerConstructor) {
+	chunkersMu.Lock()
+	defer chunkersMu.Unlock()
+	if name == "" || newChunker == nil {
+		panic("audio: RegisterChunker needs a name and a constructor")
+	}
+	if _, dup := chunkers[name]; dup {
+		panic("audio: RegisterChunker called twice for chunker " + name)
+	}
+	chunkers[name] = newChunker
+}
+
+// ChunkerNames returns the names of the registered chunking strategies, sorted.
+func ChunkerNames() []string {
+	chunkersMu.RLock()
+	defer chunkersMu.RUnlock()
+	names := make([]string, 0, len(chunkers))
+	for name := range chunkers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// NewChunker creates the chunker registered under name.
+// An empty name means StrategySilence.
+// Returns ErrUnknownChunker if no chunker is registered under name.
+func NewChunker(name string, cfg ChunkerConfig) (Chunker, error) {
+	if name == "" {
+		name = StrategySilence
+	}
+	chunkersMu.RLock()
+	newChunker, ok := chunkers[name]
+	chunkersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (available: %s)", ErrUnknownChunker, name, strings.Join(ChunkerNames(), ", "))
+	}
+	return newChunker(cfg)
+}
+
+// newSilenceChunker creates a SilenceChunker from cfg.
+func newSilenceChunker(cfg ChunkerConfig) (Chunker, error) {
+	var opts []SilenceChunkerOption
+	if cfg.ChunkSize > 0 {
+		opts = append(opts, WithMaxChunkSize(cfg.ChunkSize))
+	}
+	if cfg.RunID != "" {
+		opts = append(opts, WithRunID(cfg.RunID))
+	}
+	if cfg.ExtractWorkers > 0 {
+		opts = append(opts, WithExtractWorkers(cfg.ExtractWorkers))
+	}
+	return NewSilenceChunker(cfg.FFmpegPath, opts...)
+}
+
+// newTimeChunker creates a TimeChunker with the default duration and overlap.
+func newTimeChunker(cfg ChunkerConfig) (Chunker, error) {
+	var opts []TimeChunkerOption
+	if cfg.RunID != "" {
+		opts = append(opts, WithTimeChunkerRunID(cfg.RunID))
+	}
+	return NewTimeChunker(cfg.FFmpegPath, defaultTargetDuration, defaultOverlap, opts...)
+}
+
+// newSizeChunker creates a SizeChunker from cfg.
+func newSizeChunker(cfg ChunkerConfig) (Chunker, error) {
+	var opts []SizeChunkerOption
+	if cfg.RunID != "" {
+		opts = append(opts, WithSizeChunkerRunID(cfg.RunID))
+	}
+	return NewSizeChunker(cfg.FFmpegPath, cfg.ChunkSize, opts...)
+}
+
+// newEnergyChunker creates an EnergyChunker from cfg.
+func newEnergyChunker(cfg ChunkerConfig) (Chunker, error) {
+	var opts []EnergyChunkerOption
+	if cfg.ChunkSize > 0 {
+		opts = append(opts, WithEnergyChunkerMaxChunkSize(cfg.ChunkSize))
+	}
+	if cfg.RunID != "" {
+		opts = append(opts, WithEnergyChunkerRunID(cfg.RunID))
+	}
+	if cfg.ExtractWorkers > 0 {
+		opts = append(opts, WithEnergyChunkerExtractWorkers(cfg.ExtractWorkers))
+	}
+	return NewEnergyChunker(cfg.FFmpegPath, opts...)
+}
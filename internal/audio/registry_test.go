@@ -0,0 +1,110 @@
+package audio_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+func TestNewChunker(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		strategy string
+		cfg      audio.ChunkerConfig
+		wantType string
+		wantErr  error
+	}{
+		{"default is silence", "", audio.ChunkerConfig{FFmpegPath: "/usr/bin/ffmpeg"}, "silence", nil},
+		{"silence", audio.StrategySilence, audio.ChunkerConfig{FFmpegPath: "/usr/bin/ffmpeg", ChunkSize: 1 << 20}, "silence", nil},
+		{"time", audio.StrategyTime, audio.ChunkerConfig{FFmpegPath: "/usr/bin/ffmpeg"}, "time", nil},
+		{"size", audio.StrategySize, audio.ChunkerConfig{FFmpegPath: "/usr/bin/ffmpeg", ChunkSize: 1 << 20}, "size", nil},
+		{"energy", audio.StrategyEnergy, audio.ChunkerConfig{FFmpegPath: "/usr/bin/ffmpeg", ChunkSize: 1 << 20}, "energy", nil},
+		{"size too small", audio.StrategySize, audio.ChunkerConfig{FFmpegPath: "/usr/bin/ffmpeg", ChunkSize: 1024}, "", audio.ErrInvalidChunkSize},
+		{"unknown", "vad", audio.ChunkerConfig{FFmpegPath: "/usr/bin/ffmpeg"}, "", audio.ErrUnknownChunker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := audio.NewChunker(tt.strategy, tt.cfg)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("NewChunker(%q) error = %v, want %v", tt.strategy, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewChunker(%q) error = %v", tt.strategy, err)
+			}
+
+			var got string
+			switch c.(type) {
+			case *audio.SilenceChunker:
+				got = "silence"
+			case *audio.TimeChunker:
+				got = "time"
+			case *audio.SizeChunker:
+				got = "size"
+			case *audio.EnergyChunker:
+				got = "energy"
+			}
+			if got != tt.wantType {
+				t.Errorf("NewChunker(%q) = %T, want %s chunker", tt.strategy, c, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestRegisterChunker(t *testing.T) {
+	// Not parallel: the registry is global, and parallel tests list its names.
+	t.Cleanup(func() { audio.UnregisterChunker("test-register") })
+
+	want, err := audio.NewTimeChunker("/usr/bin/ffmpeg", 0, 0)
+	if err != nil {
+		t.Fatalf("NewTimeChunker() error = %v", err)
+	}
+	var gotCfg audio.ChunkerConfig
+	audio.RegisterChunker("test-register", func(cfg audio.ChunkerConfig) (audio.Chunker, error) {
+		gotCfg = cfg
+		return want, nil
+	})
+
+	if names := audio.ChunkerNames(); !slices.Contains(names, "test-register") || !slices.IsSorted(names) {
+		t.Errorf("ChunkerNames() = %v, want sorted names with test-register", names)
+	}
+	for _, name := range []string{audio.StrategySilence, audio.StrategySize, audio.StrategyTime} {
+		if !slices.Contains(audio.ChunkerNames(), name) {
+			t.Errorf("ChunkerNames() is missing %q", name)
+		}
+	}
+
+	cfg := audio.ChunkerConfig{FFmpegPath: "/usr/bin/ffmpeg", ChunkSize: 42}
+	c, err := audio.NewChunker("test-register", cfg)
+	if err != nil || c != audio.Chunker(want) || gotCfg != cfg {
+		t.Errorf("NewChunker() = %v, %v with %+v; want registered chunker with %+v", c, err, gotCfg, cfg)
+	}
+
+	for _, tt := range []struct {
+		name       string
+		strategy   string
+		newChunker audio.ChunkerConstructor
+	}{
+		{"duplicate", audio.StrategySilence, func(audio.ChunkerConfig) (audio.Chunker, error) { return nil, nil }},
+		{"empty name", "", func(audio.ChunkerConfig) (audio.Chunker, error) { return nil, nil }},
+		{"nil constructor", "test-nil", nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterChunker(%q) did not panic", tt.strategy)
+				}
+			}()
+			audio.RegisterChunker(tt.strategy, tt.newChunker)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+)
+
+// Compile-time interface implementation check.
+var _ Repairer = (*FFmpegRepairer)(nil)
+
+// Repairer repairs recordings cut short by a crash.
+type Repairer interface {
+	// Repair writes the audio of inputPath to outputPath without re-encoding
+	// it, with the container structures a crash left out (last page, end of
+	// stream). Audio past the point where inputPath is truncated is dropped.
+	Repair(ctx context.Context, inputPath, outputPath string) error
+}
+
+// FFmpegRepairer implements Repairer by remuxing with FFmpeg.
+type FFmpegRepairer struct {
+	ffmpegPath string
+	runner     ffmpegRunner
+}
+
+// RepairerOption configures an FFmpegRepairer.
+type RepairerOption func(*FFmpegRepairer)
+
+// WithRepairerFFmpegRunner sets a custom FFmpeg runner (for testing).
+func WithRepairerFFmpegRunner(r ffmpegRunner) RepairerOption {
+	return func(rp *FFmpegRepairer) {
+		rp.runner = r
+	}
+}
+
+// NewRepairer creates a repairer using the FFmpeg binary at ffmpegPath.
+func NewRepairer(ffmpegPath string, opts ...RepairerOption) (*FFmpegRepairer, error) {
+	if ffmpegPath == "" {
+		return nil, fmt.Errorf("ffmpeg path cannot be empty")
+	}
+	rp := &FFmpegRepairer{
+		ffmpegPath: ffmpegPath,
+		runner:     defaultFFmpegRunner{},
+	}
+	for _, opt := range opts {
+		opt(rp)
+	}
+	return rp, nil
+}
+
+// Repair remuxes inputPath to outputPath. FFmpeg stops reading at the
+// truncation and finalizes outputPath as a complete file.
+func (rp *FFmpegRepairer) Repair(ctx context.Context, inputPath, outputPath string) error {
+	if _, err := rp.runner.RunOutput(ctx, rp.ffmpegPath, repairArgs(inputPath, outputPath)); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: %s: %v", ErrRepairFailed, inputPath, err)
+	}
+	return nil
+}
+
+// repairArgs returns the FFmpeg arguments remuxing the audio of inputPath to
+// outputPath. Decoding errors are ignored: a crash leaves a torn last page.
+func repairArgs(inputPath, outputPath string) []string {
+	return []string{
+		"-y",
+		"-err_detect", "ignore_err",
+		"-i", inputPath,
+		"-map", "0:a",
+		"-c", "copy",
+		outputPath,
+	}
+}
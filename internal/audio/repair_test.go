@@ -0,0 +1,62 @@
+package audio_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+func TestFFmpegRepairer_Repair(t *testing.T) {
+	t.Parallel()
+
+	t.Run("remuxes without re-encoding", func(t *testing.T) {
+		t.Parallel()
+
+		var gotArgs []string
+		runner := &mockFFmpegRunner{
+			runOutputFunc: func(ctx context.Context, ffmpegPath string, args []string) (string, error) {
+				gotArgs = args
+				return "", nil
+			},
+		}
+		rp, err := audio.NewRepairer("/usr/bin/ffmpeg", audio.WithRepairerFFmpegRunner(runner))
+		if err != nil {
+			t.Fatalf("NewRepairer() unexpected error: %v", err)
+		}
+
+		if err := rp.Repair(context.Background(), "partial.ogg", "repaired.ogg"); err != nil {
+			t.Fatalf("Repair() unexpected error: %v", err)
+		}
+		want := []string{"-y", "-err_detect", "ignore_err", "-i", "partial.ogg", "-map", "0:a", "-c", "copy", "repaired.ogg"}
+		if !slices.Equal(gotArgs, want) {
+			t.Errorf("ffmpeg args = %v, want %v", gotArgs, want)
+		}
+	})
+
+	t.Run("ffmpeg failure", func(t *testing.T) {
+		t.Parallel()
+
+		runner := &mockFFmpegRunner{
+			runOutputFunc: func(ctx context.Context, ffmpegPath string, args []string) (string, error) {
+				return "partial.ogg: Invalid data found when processing input", errors.New("exit status 1")
+			},
+		}
+		rp, _ := audio.NewRepairer("/usr/bin/ffmpeg", audio.WithRepairerFFmpegRunner(runner))
+
+		err := rp.Repair(context.Background(), "partial.ogg", "repaired.ogg")
+		if !errors.Is(err, audio.ErrRepairFailed) {
+			t.Errorf("Repair() error = %v, want ErrRepairFailed", err)
+		}
+	})
+}
+
+func TestNewRepairer_EmptyPath(t *testing.T) {
+	t.Parallel()
+
+	if _, err := audio.NewRepairer(""); err == nil {
+		t.Error("NewRepairer(\"\") error = nil, want error")
+	}
+}
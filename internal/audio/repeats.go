@@ -0,0 +1,163 @@
+package audio
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Intro and outro detection parameters.
+const (
+	// IntroWindow is how much of the beginning and end of a recording is
+	// fingerprinted and compared with earlier recordings.
+	IntroWindow = 90 * time.Second
+
+	// MinRepeatDuration is the shortest segment considered a repeated intro or
+	// outro. Shorter matches are likely a shared sound effect or a coincidence.
+	MinRepeatDuration = 5 * time.Second
+
+	// maxIntroLibraryEntries bounds the library: the intro of a series is found
+	// in its last episodes, there is no need to keep them all.
+	maxIntroLibraryEntries = 50
+)
+
+// introLibraryVersion is bumped when the library format changes incompatibly.
+const introLibraryVersion = 1
+
+// IntroLibrary remembers the beginning and end of earlier recordings, so that
+// intros and outros repeated in later recordings can be recognized.
+type IntroLibrary struct {
+	Version int          `json:"version"`
+	Entries []IntroEntry `json:"entries"`
+}
+
+// IntroEntry is the fingerprint of the beginning and end of a recording.
+type IntroEntry struct {
+	Source string      `json:"source"` // Absolute path of the recording
+	Added  time.Time   `json:"added"`
+	Head   Fingerprint `json:"head"` // First IntroWindow
+	Tail   Fingerprint `json:"tail"` // Last IntroWindow
+}
+
+// LoadIntroLibrary reads the library at path.
+// Returns an empty library if the file doesn't exist.
+func LoadIntroLibrary(path string) (*IntroLibrary, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- library path from config
+	if os.IsNotExist(err) {
+		return &IntroLibrary{Version: introLibraryVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read intro library: %w", err)
+	}
+
+	var lib IntroLibrary
+	if err := json.Unmarshal(data, &lib); err != nil {
+		return nil, fmt.Errorf("invalid intro library %s: %w", path, err)
+	}
+	if lib.Version != introLibraryVersion {
+		// Fingerprints of another version cannot be compared: start over.
+		return &IntroLibrary{Version: introLibraryVersion}, nil
+	}
+	return &lib, nil
+}
+
+// Save writes the library to path, creating its directory if needed.
+func (l *IntroLibrary) Save(path string) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("cannot encode intro library: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("cannot create intro library directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("cannot write intro library: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("cannot write intro library: %w", err)
+	}
+	return nil
+}
+
+// Add records entry, replacing an earlier entry of the same source.
+// The oldest entries are dropped beyond the library capacity.
+func (l *IntroLibrary) Add(entry IntroEntry) {
+	entries := l.Entries[:0]
+	for _, e := range l.Entries {
+		if e.Source != entry.Source {
+			entries = append(entries, e)
+		}
+	}
+	entries = append(entries, entry)
+	if len(entries) > maxIntroLibraryEntries {
+		entries = entries[len(entries)-maxIntroLibraryEntries:]
+	}
+	l.Entries = entries
+}
+
+// FindIntro returns the longest segment of head (the beginning of source)
+// that also begins an earlier recording. Times are relative to head.
+func (l *IntroLibrary) FindIntro(source string, head Fingerprint) (Repeat, bool) {
+	return l.find(source, head, func(e IntroEntry) Fingerprint { return e.Head })
+}
+
+// FindOutro returns the longest segment of tail (the end of source) that
+// also ends an earlier recording. Times are relative to tail.
+func (l *IntroLibrary) FindOutro(source string, tail Fingerprint) (Repeat, bool) {
+	return l.find(source, tail, func(e IntroEntry) Fingerprint { return e.Tail })
+}
+
+// find returns the longest repeat of fp in the fingerprints selected by part.
+// Entries of source itself are skipped: a recording matches itself entirely.
+func (l *IntroLibrary) find(source string, fp Fingerprint, part func(IntroEntry) Fingerprint) (Repeat, bool) {
+	var (
+		best  Repeat
+		found bool
+	)
+	for _, e := range l.Entries {
+		if e.Source == source {
+			continue
+		}
+		if r, ok := FindRepeat(fp, part(e), MinRepeatDuration); ok && r.Duration() > best.Duration() {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+// MarshalJSON encodes f compactly, as base64 of little-endian uint32 values.
+func (f Fingerprint) MarshalJSON() ([]byte, error) {
+	data := make([]byte, 4*len(f))
+	for i, v := range f {
+		binary.LittleEndian.PutUint32(data[4*i:], v)
+	}
+	return json.Marshal(base64.StdEncoding.EncodeToString(data))
+}
+
+// UnmarshalJSON decodes a fingerprint encoded by MarshalJSON.
+func (f *Fingerprint) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid fingerprint: %w", err)
+	}
+	if len(data)%4 != 0 {
+		return fmt.Errorf("invalid fingerprint length %d", len(data))
+	}
+	fp := make(Fingerprint, len(data)/4)
+	for i := range fp {
+		fp[i] = binary.LittleEndian.Uint32(data[4*i:])
+	}
+	*f = fp
+	return nil
+}
@@ -0,0 +1,146 @@
+package audio_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+func TestFingerprint_JSON(t *testing.T) {
+	t.Parallel()
+
+	fp := audio.Fingerprint{0, 1, 0xdeadbeef, 42}
+	data, err := json.Marshal(fp)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	var got audio.Fingerprint
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if !reflect.DeepEqual(got, fp) {
+		t.Errorf("round trip = %v, want %v", got, fp)
+	}
+
+	for _, invalid := range []string{`"not base64!"`, `"AAA="`, `42`} {
+		if err := json.Unmarshal([]byte(invalid), &got); err == nil {
+			t.Errorf("Unmarshal(%s) expected error", invalid)
+		}
+	}
+}
+
+func TestIntroLibrary_SaveLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nested", "intros.json")
+
+	lib, err := audio.LoadIntroLibrary(path)
+	if err != nil {
+		t.Fatalf("LoadIntroLibrary(missing) error: %v", err)
+	}
+	if len(lib.Entries) != 0 {
+		t.Errorf("LoadIntroLibrary(missing) = %d entries, want 0", len(lib.Entries))
+	}
+
+	added := time.Date(2026, 1, 25, 14, 30, 0, 0, time.UTC)
+	lib.Add(audio.IntroEntry{Source: "/podcasts/ep1.mp3", Added: added, Head: audio.Fingerprint{1, 2}, Tail: audio.Fingerprint{3}})
+	if err := lib.Save(path); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	loaded, err := audio.LoadIntroLibrary(path)
+	if err != nil {
+		t.Fatalf("LoadIntroLibrary() error: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Entries, lib.Entries) {
+		t.Errorf("loaded entries = %+v, want %+v", loaded.Entries, lib.Entries)
+	}
+}
+
+func TestLoadIntroLibrary_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := audio.LoadIntroLibrary(corrupt); err == nil {
+		t.Error("LoadIntroLibrary(corrupt) expected error")
+	}
+
+	// Fingerprints of another version are dropped, not compared
+	future := filepath.Join(dir, "future.json")
+	if err := os.WriteFile(future, []byte(`{"version":99,"entries":[{"source":"x"}]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	lib, err := audio.LoadIntroLibrary(future)
+	if err != nil || len(lib.Entries) != 0 {
+		t.Errorf("LoadIntroLibrary(other version) = %+v, %v; want empty library", lib, err)
+	}
+}
+
+func TestIntroLibrary_Add(t *testing.T) {
+	t.Parallel()
+
+	lib := &audio.IntroLibrary{}
+	lib.Add(audio.IntroEntry{Source: "a", Head: audio.Fingerprint{1}})
+	lib.Add(audio.IntroEntry{Source: "b"})
+	lib.Add(audio.IntroEntry{Source: "a", Head: audio.Fingerprint{2}})
+
+	if len(lib.Entries) != 2 {
+		t.Fatalf("entries = %d, want 2 (same source replaced)", len(lib.Entries))
+	}
+	if last := lib.Entries[1]; last.Source != "a" || last.Head[0] != 2 {
+		t.Errorf("last entry = %+v, want the new entry of source a", last)
+	}
+
+	for i := range audio.MaxIntroLibraryEntries + 5 {
+		lib.Add(audio.IntroEntry{Source: fmt.Sprint(i)})
+	}
+	if len(lib.Entries) != audio.MaxIntroLibraryEntries {
+		t.Errorf("entries = %d, want capacity %d", len(lib.Entries), audio.MaxIntroLibraryEntries)
+	}
+	if first := lib.Entries[0].Source; first != "5" {
+		t.Errorf("oldest kept entry = %q, want %q", first, "5")
+	}
+}
+
+func TestIntroLibrary_FindIntroOutro(t *testing.T) {
+	t.Parallel()
+
+	intro := melody(42, 10*time.Second, 1)
+	outro := melody(43, 8*time.Second, 1)
+
+	lib := &audio.IntroLibrary{}
+	lib.Add(audio.IntroEntry{
+		Source: "/podcasts/ep1.mp3",
+		Head:   audio.ComputeFingerprint(concat(intro, melody(1, 20*time.Second, 1))),
+		Tail:   audio.ComputeFingerprint(concat(melody(2, 20*time.Second, 1), outro)),
+	})
+
+	head := audio.ComputeFingerprint(concat(intro, melody(3, 20*time.Second, 1)))
+	tail := audio.ComputeFingerprint(concat(melody(4, 20*time.Second, 1), outro))
+	tolerance := 3 * audio.FingerprintFrameDuration
+
+	r, ok := lib.FindIntro("/podcasts/ep2.mp3", head)
+	if !ok || !near(r.End, 10*time.Second, tolerance) {
+		t.Errorf("FindIntro() = %v-%v, %v; want intro ending ~10s", r.Start, r.End, ok)
+	}
+	r, ok = lib.FindOutro("/podcasts/ep2.mp3", tail)
+	if !ok || !near(r.Start, 20*time.Second, tolerance) {
+		t.Errorf("FindOutro() = %v-%v, %v; want outro starting ~20s", r.Start, r.End, ok)
+	}
+
+	// A recording is not compared with itself
+	if _, ok := lib.FindIntro("/podcasts/ep1.mp3", head); ok {
+		t.Error("FindIntro() matched the entry of the same source")
+	}
+}
@@ -0,0 +1,57 @@
+package audio
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SegmentListExt is the extension of the list of files of a segmented
+// recording, in the FFmpeg concat format.
+const SegmentListExt = ".ffconcat"
+
+// recordingSegmentSuffix is appended to the name of a segmented recording to
+// name its files (meeting.ogg: meeting_0000.ogg, meeting_0001.ogg...).
+const recordingSegmentSuffix = "_%04d"
+
+// recordingSegmentPattern matches the file name of a segment of a segmented
+// recording, capturing the name of the recording without extension.
+var recordingSegmentPattern = regexp.MustCompile(`^(.+)_\d{4}\.ogg$`)
+
+// RecordingSegmentPath returns the path of the file with the given index of
+// the segmented recording output (meeting.ogg: meeting_0003.ogg).
+// A negative index returns the FFmpeg pattern itself (meeting_%04d.ogg).
+func RecordingSegmentPath(output string, index int) string {
+	ext := filepath.Ext(output)
+	stem := strings.TrimSuffix(output, ext)
+	if index < 0 {
+		// The whole path is an FFmpeg pattern: escape its own % signs
+		return strings.ReplaceAll(stem, "%", "%%") + recordingSegmentSuffix + ext
+	}
+	return stem + fmt.Sprintf(recordingSegmentSuffix, index) + ext
+}
+
+// SegmentListPath returns the path of the list of files of the segmented
+// recording output (meeting.ogg: meeting.ffconcat). FFmpeg reads the list as
+// a single input joining the files in order.
+func SegmentListPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + SegmentListExt
+}
+
+// IsSegmentList reports whether path is the list of files of a segmented
+// recording, by extension.
+func IsSegmentList(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), SegmentListExt)
+}
+
+// SegmentListOf returns the path of the list a segmented recording file
+// would be listed in (meeting_0003.ogg: meeting.ffconcat), by name.
+// ok is false if path is not named like a segment.
+func SegmentListOf(path string) (list string, ok bool) {
+	m := recordingSegmentPattern.FindStringSubmatch(filepath.Base(path))
+	if m == nil {
+		return "", false
+	}
+	return filepath.Join(filepath.Dir(path), m[1]+SegmentListExt), true
+}
@@ -0,0 +1,86 @@
+package audio_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// ---------------------------------------------------------------------------
+// Segmented recordings - File and list naming
+// ---------------------------------------------------------------------------
+
+func TestRecordingSegmentPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		output string
+		index  int
+		want   string
+	}{
+		{filepath.Join("dir", "meeting.ogg"), 0, filepath.Join("dir", "meeting_0000.ogg")},
+		{filepath.Join("dir", "meeting.ogg"), 12, filepath.Join("dir", "meeting_0012.ogg")},
+		{filepath.Join("dir", "meeting.ogg"), -1, filepath.Join("dir", "meeting_%04d.ogg")},
+		{"100%.ogg", 1, "100%_0001.ogg"},
+		{"100%.ogg", -1, "100%%_%04d.ogg"},
+	}
+
+	for _, tt := range tests {
+		if got := audio.RecordingSegmentPath(tt.output, tt.index); got != tt.want {
+			t.Errorf("RecordingSegmentPath(%q, %d) = %q, want %q", tt.output, tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestSegmentListPath(t *testing.T) {
+	t.Parallel()
+
+	got := audio.SegmentListPath(filepath.Join("dir", "meeting.ogg"))
+	if want := filepath.Join("dir", "meeting.ffconcat"); got != want {
+		t.Errorf("SegmentListPath() = %q, want %q", got, want)
+	}
+}
+
+func TestIsSegmentList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"meeting.ffconcat", true},
+		{"MEETING.FFCONCAT", true},
+		{"meeting.ogg", false},
+		{"ffconcat", false},
+	}
+
+	for _, tt := range tests {
+		if got := audio.IsSegmentList(tt.path); got != tt.want {
+			t.Errorf("IsSegmentList(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSegmentListOf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path     string
+		wantList string
+		wantOK   bool
+	}{
+		{filepath.Join("dir", "meeting_0003.ogg"), filepath.Join("dir", "meeting.ffconcat"), true},
+		{"call_2_0000.ogg", "call_2.ffconcat", true},
+		{"meeting.ogg", "", false},
+		{"meeting_12.ogg", "", false},
+		{"meeting_0003.mp3", "", false},
+	}
+
+	for _, tt := range tests {
+		list, ok := audio.SegmentListOf(tt.path)
+		if list != tt.wantList || ok != tt.wantOK {
+			t.Errorf("SegmentListOf(%q) = (%q, %v), want (%q, %v)", tt.path, list, ok, tt.wantList, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/ffmpeg"
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// Size-based chunking parameters.
+const (
+	// DefaultChunkSize is the default chunk size of SizeChunker: about 5 minutes
+	// of speech once re-encoded, like the longest chunks of SilenceChunker.
+	DefaultChunkSize = 2 * 1024 * 1024
+
+	// MinChunkSize is the smallest chunk size of SizeChunker (about 10 seconds).
+	MinChunkSize = 64 * 1024
+
+	// chunkBytesPerSecond is the average size of re-encoded chunks per second
+	// of audio (see chunkEncodingArgs: 50kbps Opus), used to plan chunks.
+	chunkBytesPerSecond = chunkBitrate / 8
+
+	// sizeLimitMargin is the share of the chunk size kept free: FFmpeg stops
+	// writing once the limit is exceeded, which may take one more Ogg page.
+	sizeLimitMargin = 50 // 1/50 = 2%
+
+	// sizeChunkEndTolerance merges a remainder this short into the last chunk:
+	// FFmpeg times are rounded, so a chunk reaching the end may seem a bit short.
+	sizeChunkEndTolerance = time.Second
+)
+
+// SizeChunker splits audio into chunks of a fixed file size, whatever their
+// duration, for providers with strict payload limits. Chunks are re-encoded
+// like other chunks and cut when the file reaches the size, so cuts may fall
+// mid-word: each chunk (except the first) starts slightly before the previous
+// one ended to capture words at boundaries.
+type SizeChunker struct {
+	ffmpegPath string
+	chunkSize  int64
+	overlap    time.Duration
+	runID      string
+
+	// Injectable dependencies (defaults to OS implementations).
+	cmd     commandRunner
+	tempDir tempDirCreator
+	files   fileRemover
+}
+
+// SizeChunkerOption configures a SizeChunker.
+type SizeChunkerOption func(*SizeChunker)
+
+// WithSizeChunkerCommandRunner sets the command runner for SizeChunker.
+func WithSizeChunkerCommandRunner(r commandRunner) SizeChunkerOption {
+	return func(sc *SizeChunker) {
+		sc.cmd = r
+	}
+}
+
+// WithSizeChunkerTempDir sets the temp directory creator for SizeChunker.
+func WithSizeChunkerTempDir(t tempDirCreator) SizeChunkerOption {
+	return func(sc *SizeChunker) {
+		sc.tempDir = t
+	}
+}
+
+// WithSizeChunkerFileRemover sets the file remover for SizeChunker.
+func WithSizeChunkerFileRemover(f fileRemover) SizeChunkerOption {
+	return func(sc *SizeChunker) {
+		sc.files = f
+	}
+}
+
+// WithSizeChunkerRunID sets the run ID naming the temp directories of SizeChunker.
+// Default: a new ID (see NewRunID).
+func WithSizeChunkerRunID(id string) SizeChunkerOption {
+	return func(sc *SizeChunker) {
+		sc.runID = id
+	}
+}
+
+// NewSizeChunker creates a SizeChunker writing chunks of at most chunkSize bytes.
+// A chunkSize of 0 means DefaultChunkSize.
+// Returns ErrInvalidChunkSize if chunkSize is below MinChunkSize.
+func NewSizeChunker(ffmpegPath string, chunkSize int64, opts ...SizeChunkerOption) (*SizeChunker, error) {
+	if ffmpegPath == "" {
+		return nil, fmt.Errorf("ffmpegPath cannot be empty: %w", ffmpeg.ErrNotFound)
+	}
+	if chunkSize == 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if chunkSize < MinChunkSize {
+		return nil, fmt.Errorf("%w: %s is below the minimum of %s",
+			ErrInvalidChunkSize, format.Size(chunkSize), format.Size(MinChunkSize))
+	}
+
+	sc := &SizeChunker{
+		ffmpegPath: ffmpegPath,
+		chunkSize:  chunkSize,
+		overlap:    defaultSilenceChunkerOverlap,
+		runID:      NewRunID(),
+		cmd:        osCommandRunner{},
+		tempDir:    osTempDirCreator{},
+		files:      osFileRemover{},
+	}
+
+	for _, opt := range opts {
+		opt(sc)
+	}
+
+	return sc, nil
+}
+
+// sizeLimit returns the FFmpeg output size limit of a chunk, in bytes.
+func (sc *SizeChunker) sizeLimit() int64 {
+	return sc.chunkSize - sc.chunkSize/sizeLimitMargin
+}
+
+// Chunk splits the audio file into chunks of the chunk size.
+// Chunks are extracted one after the other: each starts where the audio
+// written to the previous one ended.
+func (sc *SizeChunker) Chunk(ctx context.Context, audioPath string) ([]Chunk, error) {
+	totalDuration, err := probeDuration(ctx, sc.cmd, sc.ffmpegPath, audioPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe audio duration: %w", err)
+	}
+
+	// Create temp directory for chunks.
+	tempDir, err := sc.tempDir.MkdirTemp("", chunkDirPattern(sc.runID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	var chunks []Chunk
+	for start := time.Duration(0); start < totalDuration; {
+		i := len(chunks)
+		extractStart := start
+		if i > 0 {
+			extractStart = max(start-sc.overlap, 0)
+		}
+
+		chunkPath := filepath.Join(tempDir, fmt.Sprintf("chunk_%03d.ogg", i))
+		extractionStart := time.Now()
+		written, err := sc.extractChunk(ctx, audioPath, chunkPath, extractStart)
+		if err != nil {
+			_ = sc.files.RemoveAll(tempDir) // best-effort cleanup; original error takes precedence
+			return nil, err
+		}
+
+		end := min(extractStart+written, totalDuration)
+		if totalDuration-end < sizeChunkEndTolerance {
+			end = totalDuration
+		}
+		if end <= start {
+			_ = sc.files.RemoveAll(tempDir) // best-effort cleanup; original error takes precedence
+			return nil, fmt.Errorf("%w: chunk %d holds no new audio (chunk size %s too small)",
+				ErrChunkingFailed, i, format.Size(sc.chunkSize))
+		}
+
+		chunks = append(chunks, Chunk{Path: chunkPath, Index: i, StartTime: start, EndTime: end,
+			Extraction: time.Since(extractionStart), RunID: sc.runID})
+		start = end
+	}
+
+	return chunks, nil
+}
+
+// Plan estimates the chunks of the audio file from the average size of
+// re-encoded audio, without extracting them. The actual chunks differ
+// slightly, as the encoding bitrate varies with the audio.
+func (sc *SizeChunker) Plan(ctx context.Context, audioPath string) ([]Chunk, error) {
+	totalDuration, err := probeDuration(ctx, sc.cmd, sc.ffmpegPath, audioPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe audio duration: %w", err)
+	}
+
+	perChunk := time.Duration(float64(sc.sizeLimit()) / chunkBytesPerSecond * float64(time.Second))
+	var chunks []Chunk
+	for start := time.Duration(0); start < totalDuration; {
+		i := len(chunks)
+		extractStart := start
+		if i > 0 {
+			extractStart = max(start-sc.overlap, 0)
+		}
+		end := min(extractStart+perChunk, totalDuration)
+		if totalDuration-end < sizeChunkEndTolerance {
+			end = totalDuration
+		}
+		chunks = append(chunks, Chunk{Index: i, StartTime: start, EndTime: end})
+		start = end
+	}
+
+	return chunks, nil
+}
+
+// extractChunk re-encodes audioPath from start to chunkPath, until the chunk
+// size is reached or the audio ends. Returns the duration of audio written.
+func (sc *SizeChunker) extractChunk(ctx context.Context, audioPath, chunkPath string, start time.Duration) (time.Duration, error) {
+	args := []string{
+		"-y",
+		"-i", audioPath,
+		"-ss", formatFFmpegTime(start),
+	}
+	args = append(args, chunkEncodingArgs()...)
+	args = append(args, "-fs", strconv.FormatInt(sc.sizeLimit(), 10), chunkPath)
+
+	output, err := sc.cmd.CombinedOutput(ctx, sc.ffmpegPath, args)
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to extract chunk %s: %v\nOutput: %s",
+			ErrChunkingFailed, chunkPath, err, string(output))
+	}
+
+	written, ok := parseFinalTime(string(output))
+	if !ok {
+		return 0, fmt.Errorf("%w: could not read the duration of chunk %s", ErrChunkingFailed, chunkPath)
+	}
+	return written, nil
+}
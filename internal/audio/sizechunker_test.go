@@ -0,0 +1,214 @@
+package audio_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// sizeChunkerRunner returns a command runner answering the duration probe with
+// duration, and chunk extractions (-fs) with the given progress times in turn.
+func sizeChunkerRunner(duration string, written ...string) *mockCommandRunner {
+	return &mockCommandRunner{
+		outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+			if !contains(args, "-fs") {
+				return []byte("Duration: " + duration + ", start: 0.000000"), nil
+			}
+			if len(written) == 0 {
+				return nil, errors.New("unexpected extraction")
+			}
+			out := "size=  1024kB time=" + written[0] + " bitrate=50.0kbits/s"
+			written = written[1:]
+			return []byte(out), nil
+		},
+	}
+}
+
+func TestNewSizeChunker(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		ffmpegPath string
+		chunkSize  int64
+		wantErr    error
+	}{
+		{"default size", "/usr/bin/ffmpeg", 0, nil},
+		{"custom size", "/usr/bin/ffmpeg", 10 * 1024 * 1024, nil},
+		{"minimum size", "/usr/bin/ffmpeg", audio.MinChunkSize, nil},
+		{"below minimum", "/usr/bin/ffmpeg", audio.MinChunkSize - 1, audio.ErrInvalidChunkSize},
+		{"negative size", "/usr/bin/ffmpeg", -1, audio.ErrInvalidChunkSize},
+		{"empty ffmpeg path", "", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sc, err := audio.NewSizeChunker(tt.ffmpegPath, tt.chunkSize)
+			if tt.ffmpegPath == "" {
+				if err == nil {
+					t.Error("NewSizeChunker() expected error for empty ffmpeg path")
+				}
+				return
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("NewSizeChunker() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || sc == nil {
+				t.Errorf("NewSizeChunker() = %v, %v; want chunker", sc, err)
+			}
+		})
+	}
+}
+
+func TestSizeChunker_Chunk(t *testing.T) {
+	t.Parallel()
+
+	t.Run("chunks follow the audio written", func(t *testing.T) {
+		t.Parallel()
+
+		runner := sizeChunkerRunner("00:05:00.00", "00:02:00.00", "00:02:00.00", "00:01:03.50")
+		sc, err := audio.NewSizeChunker("/usr/bin/ffmpeg", 0,
+			audio.WithSizeChunkerCommandRunner(runner),
+			audio.WithSizeChunkerTempDir(&mockTempDirCreator{dir: t.TempDir()}),
+			audio.WithSizeChunkerFileRemover(&mockFileRemover{}),
+		)
+		if err != nil {
+			t.Fatalf("NewSizeChunker() error = %v", err)
+		}
+
+		chunks, err := sc.Chunk(context.Background(), "/fake/audio.ogg")
+		if err != nil {
+			t.Fatalf("Chunk() error = %v", err)
+		}
+
+		// Each chunk after the first starts 2s early: 0-120, 118-238, 236-300
+		// (the last one ends within a second of the end, so at the end).
+		want := []struct{ start, end time.Duration }{
+			{0, 2 * time.Minute},
+			{2 * time.Minute, 238 * time.Second},
+			{238 * time.Second, 5 * time.Minute},
+		}
+		if len(chunks) != len(want) {
+			t.Fatalf("Chunk() = %v, want %d chunks", chunks, len(want))
+		}
+		for i, c := range chunks {
+			if c.Index != i || c.StartTime != want[i].start || c.EndTime != want[i].end || c.Path == "" {
+				t.Errorf("chunk %d = %+v, want %s-%s", i, c, want[i].start, want[i].end)
+			}
+		}
+
+		// Extractions start with the overlap and stop at the size limit (2% margin).
+		wantLimit := strconv.Itoa(audio.DefaultChunkSize - audio.DefaultChunkSize/50)
+		var starts []string
+		for _, call := range runner.calls {
+			for j, arg := range call.args {
+				switch arg {
+				case "-ss":
+					starts = append(starts, call.args[j+1])
+				case "-fs":
+					if call.args[j+1] != wantLimit {
+						t.Errorf("-fs %s, want %s", call.args[j+1], wantLimit)
+					}
+				}
+			}
+		}
+		wantStarts := []string{"00:00:00.000", "00:01:58.000", "00:03:56.000"}
+		if len(starts) != len(wantStarts) {
+			t.Fatalf("extraction starts = %v, want %v", starts, wantStarts)
+		}
+		for i := range starts {
+			if starts[i] != wantStarts[i] {
+				t.Errorf("extraction starts = %v, want %v", starts, wantStarts)
+				break
+			}
+		}
+	})
+
+	t.Run("no progress", func(t *testing.T) {
+		t.Parallel()
+
+		// The second chunk only holds the 2s overlap: the chunk size is too small.
+		runner := sizeChunkerRunner("00:05:00.00", "00:02:00.00", "00:00:02.00")
+		sc, _ := audio.NewSizeChunker("/usr/bin/ffmpeg", 0,
+			audio.WithSizeChunkerCommandRunner(runner),
+			audio.WithSizeChunkerTempDir(&mockTempDirCreator{dir: t.TempDir()}),
+			audio.WithSizeChunkerFileRemover(&mockFileRemover{}),
+		)
+
+		if _, err := sc.Chunk(context.Background(), "/fake/audio.ogg"); !errors.Is(err, audio.ErrChunkingFailed) {
+			t.Errorf("Chunk() error = %v, want ErrChunkingFailed", err)
+		}
+	})
+
+	t.Run("extraction error", func(t *testing.T) {
+		t.Parallel()
+
+		runner := &mockCommandRunner{
+			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+				if !contains(args, "-fs") {
+					return []byte("Duration: 00:05:00.00"), nil
+				}
+				return []byte("Invalid data"), errors.New("exit status 1")
+			},
+		}
+		sc, _ := audio.NewSizeChunker("/usr/bin/ffmpeg", 0,
+			audio.WithSizeChunkerCommandRunner(runner),
+			audio.WithSizeChunkerTempDir(&mockTempDirCreator{dir: t.TempDir()}),
+			audio.WithSizeChunkerFileRemover(&mockFileRemover{}),
+		)
+
+		if _, err := sc.Chunk(context.Background(), "/fake/audio.ogg"); !errors.Is(err, audio.ErrChunkingFailed) {
+			t.Errorf("Chunk() error = %v, want ErrChunkingFailed", err)
+		}
+	})
+
+	t.Run("probe duration error", func(t *testing.T) {
+		t.Parallel()
+
+		runner := &mockCommandRunner{
+			outputFunc: func(ctx context.Context, name string, args []string) ([]byte, error) {
+				return nil, errors.New("ffmpeg failed")
+			},
+		}
+		sc, _ := audio.NewSizeChunker("/usr/bin/ffmpeg", 0, audio.WithSizeChunkerCommandRunner(runner))
+
+		if _, err := sc.Chunk(context.Background(), "/fake/audio.ogg"); err == nil {
+			t.Error("Chunk() expected error, got nil")
+		}
+	})
+}
+
+func TestSizeChunker_Plan(t *testing.T) {
+	t.Parallel()
+
+	runner := sizeChunkerRunner("00:10:00.00")
+	sc, _ := audio.NewSizeChunker("/usr/bin/ffmpeg", 0, audio.WithSizeChunkerCommandRunner(runner))
+
+	chunks, err := sc.Plan(context.Background(), "/fake/audio.ogg")
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	// 2MB less 2% at 50kbps is about 329s of audio per chunk.
+	if len(chunks) != 2 {
+		t.Fatalf("Plan() = %v, want 2 chunks", chunks)
+	}
+	if d := chunks[0].Duration(); d < 328*time.Second || d > 330*time.Second {
+		t.Errorf("first chunk duration = %s, want about 329s", d)
+	}
+	if chunks[1].StartTime != chunks[0].EndTime || chunks[1].EndTime != 10*time.Minute || chunks[1].Path != "" {
+		t.Errorf("second chunk = %+v, want from %s to the end, without path", chunks[1], chunks[0].EndTime)
+	}
+	if len(runner.calls) != 1 {
+		t.Errorf("Plan() ran %d commands, want only the duration probe", len(runner.calls))
+	}
+}
@@ -0,0 +1,130 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// segmentPattern is the file name pattern of streamed recording segments.
+// It is passed verbatim to the FFmpeg segment muxer.
+const segmentPattern = "segment_%04d.ogg"
+
+// SegmentPath returns the path of the streamed segment with the given index.
+// A negative index returns the FFmpeg pattern itself (segment_%04d.ogg).
+func SegmentPath(dir string, index int) string {
+	if index < 0 {
+		return filepath.Join(dir, segmentPattern)
+	}
+	return filepath.Join(dir, fmt.Sprintf(segmentPattern, index))
+}
+
+// SegmentWatcher detects segments written by StreamRecorder.RecordStream.
+// The segment muxer only opens segment N+1 after closing segment N, so a
+// segment is considered complete as soon as its successor exists.
+// The last segment is only complete once recording stops (see Flush).
+// A SegmentWatcher is not safe for concurrent use.
+type SegmentWatcher struct {
+	dir             string
+	segmentDuration time.Duration
+	next            int // Index of the next segment to emit.
+
+	statter fileStatter
+}
+
+// NewSegmentWatcher creates a watcher for segments of segmentDuration in dir.
+func NewSegmentWatcher(dir string, segmentDuration time.Duration) *SegmentWatcher {
+	return &SegmentWatcher{
+		dir:             dir,
+		segmentDuration: segmentDuration,
+		statter:         osFileStatter{},
+	}
+}
+
+// Poll returns the segments completed since the last call, in order.
+func (w *SegmentWatcher) Poll() []Chunk {
+	var chunks []Chunk
+	for w.exists(w.next + 1) {
+		chunks = append(chunks, w.emit())
+	}
+	return chunks
+}
+
+// Flush returns all remaining segments, including the last one.
+// Call it once recording has stopped. Empty segments are skipped:
+// FFmpeg may open a final segment without writing audio to it.
+func (w *SegmentWatcher) Flush() []Chunk {
+	var chunks []Chunk
+	for w.exists(w.next) {
+		info, err := w.statter.Stat(SegmentPath(w.dir, w.next))
+		if err != nil || info.Size() == 0 {
+			w.next++
+			continue
+		}
+		chunks = append(chunks, w.emit())
+	}
+	return chunks
+}
+
+// Watch polls for completed segments every interval and sends them on the
+// returned channel. When done is closed (recording stopped), the remaining
+// segments are flushed and the channel is closed.
+// Canceling ctx stops the watcher and closes the channel without flushing.
+func (w *SegmentWatcher) Watch(ctx context.Context, interval time.Duration, done <-chan struct{}) <-chan Chunk {
+	out := make(chan Chunk)
+
+	go func() {
+		defer close(out)
+
+		send := func(chunks []Chunk) bool {
+			for _, c := range chunks {
+				select {
+				case out <- c:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				send(w.Poll())
+				send(w.Flush())
+				return
+			case <-ticker.C:
+				if !send(w.Poll()) {
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// emit builds the chunk for the next segment and advances the watcher.
+// Timestamps are nominal: the segment muxer cuts at the requested duration.
+func (w *SegmentWatcher) emit() Chunk {
+	c := Chunk{
+		Path:      SegmentPath(w.dir, w.next),
+		Index:     w.next,
+		StartTime: time.Duration(w.next) * w.segmentDuration,
+		EndTime:   time.Duration(w.next+1) * w.segmentDuration,
+	}
+	w.next++
+	return c
+}
+
+// exists reports whether the segment with the given index has been created.
+func (w *SegmentWatcher) exists(index int) bool {
+	_, err := w.statter.Stat(SegmentPath(w.dir, index))
+	return err == nil
+}
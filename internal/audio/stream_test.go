@@ -0,0 +1,205 @@
+package audio_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// Notes:
+// - SegmentWatcher is exercised against a real temp directory: it only stats files.
+// - FFmpeg's segment muxer is simulated by creating segment files in order.
+
+// writeSegment creates the segment file with the given index and content.
+func writeSegment(t *testing.T, dir string, index int, content string) {
+	t.Helper()
+	if err := os.WriteFile(audio.SegmentPath(dir, index), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write segment %d: %v", index, err)
+	}
+}
+
+// chunkPaths returns the base names of chunk paths.
+func chunkPaths(chunks []audio.Chunk) []string {
+	names := make([]string, len(chunks))
+	for i, c := range chunks {
+		names[i] = filepath.Base(c.Path)
+	}
+	return names
+}
+
+// ---------------------------------------------------------------------------
+// SegmentPath - Segment naming
+// ---------------------------------------------------------------------------
+
+func TestSegmentPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, filepath.Join("dir", "segment_0000.ogg")},
+		{12, filepath.Join("dir", "segment_0012.ogg")},
+		{-1, filepath.Join("dir", "segment_%04d.ogg")},
+	}
+
+	for _, tt := range tests {
+		if got := audio.SegmentPath("dir", tt.index); got != tt.want {
+			t.Errorf("SegmentPath(%q, %d) = %q, want %q", "dir", tt.index, got, tt.want)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// SegmentWatcher - Poll and Flush
+// ---------------------------------------------------------------------------
+
+func TestSegmentWatcher_Poll(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no segments", func(t *testing.T) {
+		t.Parallel()
+
+		w := audio.NewSegmentWatcher(t.TempDir(), 30*time.Second)
+		if got := w.Poll(); len(got) != 0 {
+			t.Errorf("Poll() = %v, want empty", got)
+		}
+	})
+
+	t.Run("segment in progress is not emitted", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeSegment(t, dir, 0, "audio")
+
+		w := audio.NewSegmentWatcher(dir, 30*time.Second)
+		if got := w.Poll(); len(got) != 0 {
+			t.Errorf("Poll() = %v, want empty while segment 0 is the last one", got)
+		}
+	})
+
+	t.Run("segment is emitted once its successor exists", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeSegment(t, dir, 0, "audio")
+		writeSegment(t, dir, 1, "audio")
+		writeSegment(t, dir, 2, "")
+
+		w := audio.NewSegmentWatcher(dir, 30*time.Second)
+		got := w.Poll()
+		if len(got) != 2 {
+			t.Fatalf("Poll() returned %d chunks, want 2: %v", len(got), chunkPaths(got))
+		}
+		if got[1].Index != 1 || got[1].StartTime != 30*time.Second || got[1].EndTime != time.Minute {
+			t.Errorf("Poll()[1] = %+v, want index 1 spanning 30s-1m", got[1])
+		}
+
+		// Subsequent polls only return new segments.
+		if again := w.Poll(); len(again) != 0 {
+			t.Errorf("second Poll() = %v, want empty", chunkPaths(again))
+		}
+		writeSegment(t, dir, 3, "")
+		if next := w.Poll(); len(next) != 1 || next[0].Index != 2 {
+			t.Errorf("third Poll() = %v, want segment 2", chunkPaths(next))
+		}
+	})
+}
+
+func TestSegmentWatcher_Flush(t *testing.T) {
+	t.Parallel()
+
+	t.Run("emits the last segment", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeSegment(t, dir, 0, "audio")
+		writeSegment(t, dir, 1, "audio")
+
+		w := audio.NewSegmentWatcher(dir, 30*time.Second)
+		first := w.Poll()
+		rest := w.Flush()
+
+		if len(first) != 1 || first[0].Index != 0 {
+			t.Errorf("Poll() = %v, want segment 0", chunkPaths(first))
+		}
+		if len(rest) != 1 || rest[0].Index != 1 {
+			t.Errorf("Flush() = %v, want segment 1", chunkPaths(rest))
+		}
+		if again := w.Flush(); len(again) != 0 {
+			t.Errorf("second Flush() = %v, want empty", chunkPaths(again))
+		}
+	})
+
+	t.Run("skips empty segments", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeSegment(t, dir, 0, "audio")
+		writeSegment(t, dir, 1, "") // Opened but never written by FFmpeg.
+
+		w := audio.NewSegmentWatcher(dir, 30*time.Second)
+		got := w.Flush()
+		if len(got) != 1 || got[0].Index != 0 {
+			t.Errorf("Flush() = %v, want segment 0 only", chunkPaths(got))
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// SegmentWatcher.Watch - Channel delivery
+// ---------------------------------------------------------------------------
+
+func TestSegmentWatcher_Watch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("delivers all segments and closes after done", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		done := make(chan struct{})
+		w := audio.NewSegmentWatcher(dir, 30*time.Second)
+		ch := w.Watch(context.Background(), time.Millisecond, done)
+
+		writeSegment(t, dir, 0, "audio")
+		writeSegment(t, dir, 1, "audio")
+
+		first := <-ch
+		if first.Index != 0 {
+			t.Errorf("first chunk index = %d, want 0", first.Index)
+		}
+
+		writeSegment(t, dir, 2, "audio")
+		close(done)
+
+		var rest []audio.Chunk
+		for c := range ch {
+			rest = append(rest, c)
+		}
+		if len(rest) != 2 || rest[0].Index != 1 || rest[1].Index != 2 {
+			t.Errorf("remaining chunks = %v, want segments 1 and 2", chunkPaths(rest))
+		}
+	})
+
+	t.Run("context cancellation closes channel", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		w := audio.NewSegmentWatcher(t.TempDir(), 30*time.Second)
+		ch := w.Watch(ctx, time.Millisecond, make(chan struct{}))
+		cancel()
+
+		select {
+		case _, ok := <-ch:
+			if ok {
+				t.Error("received chunk after cancellation, want closed channel")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("channel not closed after context cancellation")
+		}
+	})
+}
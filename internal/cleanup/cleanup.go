@@ -0,0 +1,248 @@
+// Package cleanup removes disfluencies from transcripts: hesitation sounds,
+// filler words, repeated words, and irregular spacing and punctuation.
+//
+// Cleaning runs locally, after transcription, so transcripts read like
+// written text before they are written or restructured. Rules depend on the
+// language (see Rules): a filler in one language is a word in another.
+package cleanup
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/alnah/go-transcript/internal/lang"
+)
+
+// linePrefix matches what leads a line and is kept as is: the "[Speaker]"
+// label of diarized transcripts, or a whole-line marker like "[intro]".
+var linePrefix = regexp.MustCompile(`^\[[^\]\n]*\]\s*`)
+
+// Punctuation normalization, applied in order.
+var (
+	multipleSpaces   = regexp.MustCompile(`[ \t]+`)
+	spaceBeforePunct = regexp.MustCompile(` +([,.;:!?…)])`)
+	repeatedCommas   = regexp.MustCompile(`,(?: *,)+`)
+	commaBeforeEnd   = regexp.MustCompile(`, *([.!?…])`)
+	missingSpace     = regexp.MustCompile(`([,;])(\pL)`)
+	frenchMarks      = regexp.MustCompile(`([^\s;:!?]) *([;:!?]+)(\s|$)`)
+)
+
+// sentenceEnds are the characters ending a sentence.
+const sentenceEnds = ".!?…"
+
+// Cleaner cleans transcripts of one language. Create it with New.
+type Cleaner struct {
+	hesitation       *regexp.Regexp
+	fillers          [][]string // Words of each filler, lowercase
+	repeats          map[string]bool
+	spaceBeforeMarks bool
+}
+
+// New returns a Cleaner with the rules of language, or the rules of
+// unknown languages if it is zero or has none (see Rules).
+// extra are fillers added to those of the rules, like the fillers of the
+// config (see config.Config.CleanFillers).
+func New(language lang.Language, extra []string) *Cleaner {
+	rules, ok := languageRules[language.BaseCode()]
+	if !ok {
+		rules = anyLanguageRules
+	}
+
+	c := &Cleaner{
+		hesitation:       regexp.MustCompile(`^(?:` + strings.Join(slices.Concat(commonHesitations, rules.Hesitations), "|") + `)$`),
+		repeats:          map[string]bool{},
+		spaceBeforeMarks: rules.SpaceBeforeMarks,
+	}
+	for _, filler := range slices.Concat(rules.Fillers, extra) {
+		if words := strings.Fields(strings.ToLower(filler)); len(words) > 0 {
+			c.fillers = append(c.fillers, words)
+		}
+	}
+	// Longest fillers first: "you know" goes before "you"
+	slices.SortStableFunc(c.fillers, func(a, b []string) int { return len(b) - len(a) })
+	for _, word := range rules.Repeats {
+		c.repeats[word] = true
+	}
+	return c
+}
+
+// Clean returns text without hesitations, fillers and repeated words, with
+// normalized spacing and punctuation. Lines are cleaned one by one, so
+// paragraphs and speaker labels are kept.
+func (c *Cleaner) Clean(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		prefix := linePrefix.FindString(line)
+		lines[i] = prefix + c.cleanLine(line[len(prefix):])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cleanLine cleans a line without its prefix.
+func (c *Cleaner) cleanLine(line string) string {
+	tokens := strings.Fields(line)
+	if len(tokens) == 0 {
+		return ""
+	}
+	tokens = c.removeHesitations(tokens)
+	tokens = c.removeFillers(tokens)
+	tokens = c.collapseRepeats(tokens)
+	return c.normalize(strings.Join(tokens, " "))
+}
+
+// removeHesitations removes the tokens that are hesitation sounds.
+func (c *Cleaner) removeHesitations(tokens []string) []string {
+	for i := 0; i < len(tokens); {
+		if c.hesitation.MatchString(strings.ToLower(core(tokens[i]))) {
+			tokens = removeSpan(tokens, i, i+1)
+			continue
+		}
+		i++
+	}
+	return tokens
+}
+
+// removeFillers removes the fillers set off by commas or sentence
+// boundaries, unless they are the whole sentence.
+func (c *Cleaner) removeFillers(tokens []string) []string {
+	for i := 0; i < len(tokens); {
+		if n := c.fillerAt(tokens, i); n > 0 {
+			tokens = removeSpan(tokens, i, i+n)
+			continue
+		}
+		i++
+	}
+	return tokens
+}
+
+// fillerAt returns the number of tokens of the filler set off at tokens[i],
+// or 0 if there is none.
+func (c *Cleaner) fillerAt(tokens []string, i int) int {
+	startsSentence := i == 0 || strings.ContainsAny(trailing(tokens[i-1]), sentenceEnds)
+	setOffBefore := startsSentence || strings.ContainsAny(trailing(tokens[i-1]), ",;:")
+	if !setOffBefore {
+		return 0
+	}
+	for _, filler := range c.fillers {
+		end := i + len(filler)
+		if end > len(tokens) || !matchWords(tokens[i:end], filler) {
+			continue
+		}
+		last := trailing(tokens[end-1])
+		endsSentence := end == len(tokens) || strings.ContainsAny(last, sentenceEnds)
+		if !endsSentence && !strings.Contains(last, ",") {
+			continue
+		}
+		if startsSentence && endsSentence {
+			continue // The whole sentence: an answer, not a filler
+		}
+		return len(filler)
+	}
+	return 0
+}
+
+// matchWords reports whether tokens are words, only the last one followed
+// by punctuation.
+func matchWords(tokens, words []string) bool {
+	for j, token := range tokens {
+		if strings.ToLower(core(token)) != words[j] || leading(token) != "" {
+			return false
+		}
+		if j < len(tokens)-1 && trailing(token) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// collapseRepeats removes the words immediately repeated ("the the"),
+// except the repeats of the rules.
+func (c *Cleaner) collapseRepeats(tokens []string) []string {
+	for i := 1; i < len(tokens); {
+		prev, word := core(tokens[i-1]), core(tokens[i])
+		if trailing(tokens[i-1]) == "" && leading(tokens[i]) == "" && strings.EqualFold(prev, word) &&
+			strings.IndexFunc(word, unicode.IsLetter) >= 0 && !c.repeats[strings.ToLower(word)] {
+			tokens = removeSpan(tokens, i-1, i)
+			continue
+		}
+		i++
+	}
+	return tokens
+}
+
+// normalize fixes the spacing and punctuation of a line.
+func (c *Cleaner) normalize(line string) string {
+	line = multipleSpaces.ReplaceAllString(line, " ")
+	line = spaceBeforePunct.ReplaceAllString(line, "$1")
+	line = repeatedCommas.ReplaceAllString(line, ",")
+	line = commaBeforeEnd.ReplaceAllString(line, "$1")
+	line = missingSpace.ReplaceAllString(line, "$1 $2")
+	if c.spaceBeforeMarks {
+		line = frenchMarks.ReplaceAllString(line, "$1 $2$3")
+	}
+	return strings.TrimSpace(line)
+}
+
+// removeSpan removes tokens[i:j], keeping the sentence they were part of
+// well formed: a sentence end after them moves to the previous token, a
+// comma before them goes if they ended the line, and the next token is
+// capitalized if they started a capitalized sentence.
+func removeSpan(tokens []string, i, j int) []string {
+	startsSentence := i == 0 || strings.ContainsAny(trailing(tokens[i-1]), sentenceEnds)
+	capitalized := isUpper(core(tokens[i]))
+
+	if !startsSentence {
+		end := trailing(tokens[j-1])
+		switch {
+		case strings.ContainsAny(end, sentenceEnds):
+			tokens[i-1] = strings.TrimRight(tokens[i-1], ",;:") + strings.TrimLeft(end, ",;: ")
+		case j == len(tokens):
+			tokens[i-1] = strings.TrimRight(tokens[i-1], ",;:") // Nothing left to set off
+		}
+	}
+	tokens = slices.Delete(tokens, i, j)
+
+	if startsSentence && capitalized && i < len(tokens) {
+		tokens[i] = capitalize(tokens[i])
+	}
+	return tokens
+}
+
+// leading returns the punctuation before the word of token.
+func leading(token string) string {
+	return token[:len(token)-len(strings.TrimLeftFunc(token, isPunct))]
+}
+
+// trailing returns the punctuation after the word of token.
+func trailing(token string) string {
+	return token[len(strings.TrimRightFunc(token, isPunct)):]
+}
+
+// core returns the word of token, without the punctuation around it.
+func core(token string) string {
+	return strings.TrimFunc(token, isPunct)
+}
+
+// isPunct reports whether r is not part of a word.
+func isPunct(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// isUpper reports whether word starts with an uppercase letter.
+func isUpper(word string) bool {
+	r, _ := utf8.DecodeRuneInString(word)
+	return unicode.IsUpper(r)
+}
+
+// capitalize uppercases the first letter of token.
+func capitalize(token string) string {
+	i := strings.IndexFunc(token, unicode.IsLetter)
+	if i < 0 {
+		return token
+	}
+	r, size := utf8.DecodeRuneInString(token[i:])
+	return token[:i] + string(unicode.ToUpper(r)) + token[i+size:]
+}
@@ -0,0 +1,76 @@
+package cleanup_test
+
+// Notes:
+// - Black-box testing: all tests use the public API only (cleanup_test package)
+// - Rules are tested through Clean, one language at a time, with the cases
+//   where a filler is a word and must be kept
+
+import (
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/cleanup"
+	"github.com/alnah/go-transcript/internal/lang"
+)
+
+func TestCleaner_Clean(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		language string
+		extra    []string
+		input    string
+		want     string
+	}{
+		// Hesitations
+		{"hesitation mid-sentence", "en", nil, "Well, um, I think so.", "Well, I think so."},
+		{"hesitation starting a sentence", "en", nil, "Um, so we start.", "So we start."},
+		{"hesitation ending a sentence", "en", nil, "I think, uh. We can go.", "I think. We can go."},
+		{"elongated hesitation", "en", nil, "It is ummm ready.", "It is ready."},
+		{"french hesitation", "fr", nil, "Euh, on commence.", "On commence."},
+
+		// Fillers
+		{"filler set off by commas", "en", nil, "It was, you know, hard.", "It was, hard."},
+		{"filler ending a sentence", "en", nil, "It works, you know.", "It works."},
+		{"filler starting a sentence", "en", nil, "You know, it works.", "It works."},
+		{"filler ending the line", "en", nil, "I think so, you know", "I think so"},
+		{"filler used as a word", "en", nil, "Do you know the way?", "Do you know the way?"},
+		{"filler as the whole sentence", "en", nil, "Like. I said so.", "Like. I said so."},
+		{"french filler", "fr", nil, "On a, tu vois, un problème.", "On a, un problème."},
+		{"french filler used as a word", "fr", nil, "Est-ce que tu vois le problème ?", "Est-ce que tu vois le problème ?"},
+		{"extra filler", "fr", []string{"du coup"}, "Du coup, on y va.", "On y va."},
+
+		// Repeated words
+		{"repeated word", "en", nil, "The the plan is is ready.", "The plan is ready."},
+		{"repeated word in the rules", "en", nil, "We had had enough.", "We had had enough."},
+		{"french reflexive", "fr", nil, "Nous nous sommes vus.", "Nous nous sommes vus."},
+		{"repetition with a comma kept", "fr", nil, "C'est très, très bien.", "C'est très, très bien."},
+		{"repeated numbers kept", "en", nil, "Room 2 2 is free.", "Room 2 2 is free."},
+
+		// Spacing and punctuation
+		{"spaces", "en", nil, "Hello  there ,  friends .", "Hello there, friends."},
+		{"repeated commas", "en", nil, "Yes,, no", "Yes, no"},
+		{"missing space after comma", "en", nil, "Yes,no, 3,5", "Yes, no, 3,5"},
+		{"french spacing", "fr", nil, "Vraiment? Oui: à 10:30 sur https://example.com!", "Vraiment ? Oui : à 10:30 sur https://example.com !"},
+
+		// Unknown language
+		{"auto-detect removes unambiguous hesitations", "", nil, "Euh, the the test, you know.", "The test, you know."},
+		{"auto-detect keeps words of other languages", "", nil, "Er ist da.", "Er ist da."},
+
+		// Layout
+		{"speaker labels kept", "en", nil, "[Speaker A] Um, hi.\n[Speaker B] Uh hello.", "[Speaker A] Hi.\n[Speaker B] Hello."},
+		{"paragraphs kept", "en", nil, "First, um, part.\n\nSecond part.", "First, part.\n\nSecond part."},
+		{"marker kept", "en", nil, "[intro]", "[intro]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			language := lang.MustParse(tt.language)
+			if got := cleanup.New(language, tt.extra).Clean(tt.input); got != tt.want {
+				t.Errorf("Clean(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package cleanup
+
+// Rules are the cleanup rules of a language.
+type Rules struct {
+	// Hesitations are regular expressions matching whole words that are only
+	// ever hesitation sounds ("um", "euh"), removed wherever they are.
+	// Letters may be repeated ("ummm"), so patterns use +.
+	Hesitations []string
+	// Fillers are words and phrases that carry meaning elsewhere ("like",
+	// "tu vois"): they are only removed when set off by commas or sentence
+	// boundaries, and never when they are the whole sentence.
+	Fillers []string
+	// Repeats are words that may legitimately follow themselves ("nous nous
+	// sommes", "had had"), never collapsed.
+	Repeats []string
+	// SpaceBeforeMarks puts a space before ; : ! and ? (French typography)
+	// instead of removing it.
+	SpaceBeforeMarks bool
+}
+
+// commonHesitations are hesitation sounds of every language.
+var commonHesitations = []string{`u+m+`, `u+h+`, `h+m+`, `m+h*m+`, `e+r+m+`}
+
+// languageRules are the rules of each supported language, by ISO 639-1 code.
+// Hesitations add to commonHesitations.
+var languageRules = map[string]Rules{
+	"en": {
+		Hesitations: []string{`e+r+`, `a+h+m+`},
+		Fillers:     []string{"you know", "i mean", "like", "you see"},
+		Repeats:     []string{"had", "that"},
+	},
+	"fr": {
+		Hesitations:      []string{`e+u+h+`, `h+e+u+h*`},
+		Fillers:          []string{"tu vois", "tu sais", "vous voyez", "bah", "ben", "genre", "quoi"},
+		Repeats:          []string{"nous", "vous"},
+		SpaceBeforeMarks: true,
+	},
+	"es": {
+		Hesitations: []string{`e+h+m*`, `e+m+`},
+		Fillers:     []string{"o sea", "pues", "bueno", "sabes"},
+	},
+	"de": {
+		Hesitations: []string{`ä+h+m*`, `ö+h+m*`},
+		Fillers:     []string{"weißt du", "sozusagen", "gell"},
+		Repeats:     []string{"die", "das", "der"},
+	},
+	"pt": {
+		Hesitations: []string{`é+h+`, `h+u+m+`},
+		Fillers:     []string{"tipo", "né", "sabe", "quer dizer"},
+	},
+	"it": {
+		Hesitations: []string{`e+h+m+`},
+		Fillers:     []string{"cioè", "tipo", "diciamo", "insomma"},
+	},
+}
+
+// anyLanguageRules are the rules when the language is unknown (auto-detect):
+// the hesitations that are not words in any supported language, and the
+// repeats of every language. Fillers are all words somewhere, so none is removed.
+var anyLanguageRules = Rules{
+	Hesitations: []string{`e+u+h+`, `ä+h+m*`},
+	Repeats:     []string{"had", "that", "nous", "vous", "die", "das", "der"},
+}
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alnah/go-transcript/internal/format"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/schemas"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// --actions values: the format of the action items written next to the output.
+const (
+	// ActionsJSON writes the action items to <output>.tasks.json, following
+	// the tasks schema.
+	ActionsJSON = "json"
+
+	// ActionsCSV writes the action items to <output>.tasks.csv, one row per
+	// item, for spreadsheets and the import of task managers.
+	ActionsCSV = "csv"
+)
+
+// actionsFlagHelp describes the --actions flag of the transcribe, live and
+// structure commands.
+const actionsFlagHelp = "With --template, also extract the action items (description, owner, due date, timestamp) to import into a task manager: json (<output>.tasks.json) or csv (<output>.tasks.csv)"
+
+// parseActions parses the --actions value. Empty means no action items.
+// Returns ErrInvalidActionsFormat if the value is not recognized.
+func parseActions(value string) (string, error) {
+	switch value {
+	case "", ActionsJSON, ActionsCSV:
+		return value, nil
+	default:
+		return "", fmt.Errorf("unknown --actions value %q (use %s or %s): %w", value, ActionsJSON, ActionsCSV, ErrInvalidActionsFormat)
+	}
+}
+
+// validateActions checks that --actions has a provider to extract the items
+// (a template is set), and somewhere to write them: a file next to the
+// output, which stdout has not.
+func validateActions(actions string, tmpl template.Name, stdout bool) error {
+	switch {
+	case actions == "":
+		return nil
+	case tmpl.IsZero():
+		return fmt.Errorf("--actions requires --template (the items are extracted by the restructuring provider)")
+	case stdout:
+		return fmt.Errorf("--actions cannot be combined with --stdout (the items are written next to the output)")
+	}
+	return nil
+}
+
+// actionsPath returns the action items file of output:
+// "notes.md" -> "notes.tasks.json" with --actions json.
+func actionsPath(output, actions string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".tasks." + actions
+}
+
+// actionsRun asks restructureWith to extract the action items of the
+// transcript once its templates succeeded (--actions), with the same
+// provider, and receives them.
+type actionsRun struct {
+	input string                   // Timed transcript to extract from; empty means the restructured one
+	items []restructure.ActionItem // Items extracted
+	err   error                    // Extraction failure, only a warning: the output does not depend on it
+}
+
+// newActionsRun returns the extraction of --actions, or nil without it.
+func newActionsRun(actions string) *actionsRun {
+	if actions == "" {
+		return nil
+	}
+	return &actionsRun{}
+}
+
+// extract extracts the action items of content (or the timed transcript set
+// as input) with mr. Does nothing on a nil run.
+func (a *actionsRun) extract(ctx context.Context, env *Env, mr restructure.MapReducer, content string, outputLang lang.Language) {
+	if a == nil {
+		return
+	}
+	extractor, ok := mr.(restructure.ActionsExtractor)
+	if !ok {
+		a.err = fmt.Errorf("the restructurer cannot extract action items")
+		return
+	}
+	input := a.input
+	if input == "" {
+		input = content
+	}
+	fmt.Fprintln(env.Stderr, "Extracting action items...")
+	a.items, a.err = extractor.ExtractActions(ctx, input, outputLang)
+}
+
+// tasksDocument is the JSON of --actions json, following the tasks schema
+// (see schemas.Tasks). Timestamps are in seconds.
+type tasksDocument struct {
+	SchemaVersion int         `json:"schema_version"`
+	Source        string      `json:"source,omitempty"`
+	Tasks         []taskEntry `json:"tasks"`
+}
+
+// taskEntry is a task of tasksDocument.
+type taskEntry struct {
+	Description string   `json:"description"`
+	Owner       string   `json:"owner,omitempty"`
+	Due         string   `json:"due,omitempty"`
+	Timestamp   *float64 `json:"timestamp,omitempty"`
+}
+
+// newTasksDocument returns items, extracted from source, as a tasksDocument.
+func newTasksDocument(source string, items []restructure.ActionItem) tasksDocument {
+	doc := tasksDocument{
+		SchemaVersion: schemas.TasksVersion,
+		Source:        source,
+		Tasks:         make([]taskEntry, len(items)),
+	}
+	for i, item := range items {
+		doc.Tasks[i] = taskEntry{Description: item.Description, Owner: item.Owner, Due: item.Due}
+		if item.Timestamp != nil {
+			seconds := item.Timestamp.Seconds()
+			doc.Tasks[i].Timestamp = &seconds
+		}
+	}
+	return doc
+}
+
+// tasksCSV returns items as CSV: a header, then a row per item, with
+// timestamps as HH:MM:SS (MM:SS under an hour). The text of the items comes
+// from the model: cells a spreadsheet would read as a formula are escaped
+// (see csvCell).
+func tasksCSV(items []restructure.ActionItem) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"description", "owner", "due", "timestamp"})
+	for _, item := range items {
+		var timestamp string
+		if item.Timestamp != nil {
+			timestamp = format.Duration(*item.Timestamp)
+		}
+		_ = w.Write([]string{csvCell(item.Description), csvCell(item.Owner), csvCell(item.Due), timestamp})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// csvCell returns s prefixed with a quote if it starts like a formula ("=",
+// "+", "-", "@", tab or carriage return), so that spreadsheets show it as
+// text instead of evaluating it.
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// saveActions writes the action items of output to actionsPath (--actions),
+// replacing an earlier run's. source is the input they were extracted from.
+// A failure, to extract or to write, is only a warning: the output is already
+// written.
+func saveActions(env *Env, actions, output, source string, run *actionsRun) {
+	if actions == "" || run == nil {
+		return
+	}
+	if run.err != nil {
+		warnf(env.Stderr, warnActionsFailed, "failed to extract action items: %v", run.err)
+		return
+	}
+	path := actionsPath(output, actions)
+	var (
+		data []byte
+		err  error
+	)
+	if actions == ActionsCSV {
+		data, err = tasksCSV(run.items)
+	} else if data, err = json.MarshalIndent(newTasksDocument(source, run.items), "", "  "); err == nil {
+		data = append(data, '\n')
+	}
+	if err == nil {
+		err = os.WriteFile(path, data, 0644) // #nosec G306 -- task list next to the user's output
+	}
+	if err != nil {
+		warnf(env.Stderr, warnFileNotSaved, "failed to save action items: %v", err)
+		return
+	}
+	fmt.Fprintf(env.Stderr, "Action items saved: %s (%d)\n", path, len(run.items))
+}
@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestParseActions(t *testing.T) {
+	t.Parallel()
+
+	for _, value := range []string{"", ActionsJSON, ActionsCSV} {
+		if got, err := parseActions(value); err != nil || got != value {
+			t.Errorf("parseActions(%q) = %q, %v, want it unchanged", value, got, err)
+		}
+	}
+	if _, err := parseActions("xml"); !errors.Is(err, ErrInvalidActionsFormat) {
+		t.Errorf("parseActions(xml) error = %v, want ErrInvalidActionsFormat", err)
+	}
+}
+
+func TestValidateActions(t *testing.T) {
+	t.Parallel()
+
+	meeting := template.MustParseName("meeting")
+	tests := []struct {
+		name    string
+		actions string
+		tmpl    template.Name
+		stdout  bool
+		wantErr string
+	}{
+		{name: "none", stdout: true},
+		{name: "json", actions: ActionsJSON, tmpl: meeting},
+		{name: "without template", actions: ActionsCSV, wantErr: "--template"},
+		{name: "to stdout", actions: ActionsJSON, tmpl: meeting, stdout: true, wantErr: "--stdout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateActions(tt.actions, tt.tmpl, tt.stdout)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validateActions() unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validateActions() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTasksCSV(t *testing.T) {
+	t.Parallel()
+
+	at := 90 * time.Second
+	got, err := tasksCSV([]restructure.ActionItem{
+		{Description: "Send the budget, revised", Owner: "Alice", Due: "Friday", Timestamp: &at},
+		{Description: "Book a room"},
+	})
+	if err != nil {
+		t.Fatalf("tasksCSV() unexpected error: %v", err)
+	}
+	want := "description,owner,due,timestamp\n\"Send the budget, revised\",Alice,Friday,01:30\nBook a room,,,\n"
+	if string(got) != want {
+		t.Errorf("tasksCSV() = %q, want %q", got, want)
+	}
+}
+
+func TestTasksCSV_EscapesFormulas(t *testing.T) {
+	t.Parallel()
+
+	got, err := tasksCSV([]restructure.ActionItem{
+		{Description: `=HYPERLINK("http://evil.example","Click")`, Owner: "@Bob", Due: "-1"},
+		{Description: "+cmd|' /C calc'!A0", Owner: "\tAlice", Due: "\rMonday"},
+		{Description: "Send the budget - revised", Owner: "Alice"},
+	})
+	if err != nil {
+		t.Fatalf("tasksCSV() unexpected error: %v", err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(got)).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV %q: %v", got, err)
+	}
+	want := [][]string{
+		{"description", "owner", "due", "timestamp"},
+		{`'=HYPERLINK("http://evil.example","Click")`, "'@Bob", "'-1", ""},
+		{"'+cmd|' /C calc'!A0", "'\tAlice", "'\rMonday", ""},
+		{"Send the budget - revised", "Alice", "", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("tasksCSV() rows = %q, want %q", rows, want)
+	}
+
+	// JSON keeps the text as extracted.
+	doc := newTasksDocument("", []restructure.ActionItem{{Description: "=1+1"}})
+	if doc.Tasks[0].Description != "=1+1" {
+		t.Errorf("JSON description = %q, want it unescaped", doc.Tasks[0].Description)
+	}
+}
+
+func TestRunTranscribe_Actions(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	output := filepath.Join(t.TempDir(), "out.md")
+	stderr := &syncBuffer{}
+	env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		if !opts.Timestamps {
+			return "", errors.New("timestamps not requested")
+		}
+		return `[{"start":12,"end":20,"text":"Alice will send the budget by Friday."}]`, nil
+	})
+	var extracted string
+	env.RestructurerFactory = &mockRestructurerFactory{mockMapReducer: &mockMapReduceRestructurer{
+		ExtractActionsFunc: func(ctx context.Context, transcript string, outputLang lang.Language) ([]restructure.ActionItem, error) {
+			extracted = transcript
+			at := 12 * time.Second
+			return []restructure.ActionItem{{Description: "Send the budget", Owner: "Alice", Due: "Friday", Timestamp: &at}}, nil
+		},
+	}}
+
+	opts := mustParseTranscribeOptions(t, inputPath, output, "meeting", false, 5, "", "", "deepseek")
+	opts.actions = ActionsJSON
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+
+	if !strings.Contains(extracted, "[00:00:12]") {
+		t.Errorf("action items extracted from %q, want the timed transcript", extracted)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if string(content) != "restructured text" {
+		t.Errorf("output = %q, want the notes only", content)
+	}
+
+	path := filepath.Join(filepath.Dir(output), "out.tasks.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read action items: %v", err)
+	}
+	var doc tasksDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("action items are not JSON: %v", err)
+	}
+	if doc.SchemaVersion != 1 || doc.Source != inputPath || len(doc.Tasks) != 1 {
+		t.Fatalf("action items = %+v, want version 1, the audio and 1 task", doc)
+	}
+	if task := doc.Tasks[0]; task.Owner != "Alice" || task.Due != "Friday" || task.Timestamp == nil || *task.Timestamp != 12 {
+		t.Errorf("task = %+v, want Alice's, due Friday, at 12s", task)
+	}
+	if !strings.Contains(stderr.String(), "Action items saved: "+path) {
+		t.Errorf("stderr = %q, want the action items file", stderr.String())
+	}
+}
+
+func TestStructureCmd_Actions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("csv", func(t *testing.T) {
+		t.Parallel()
+
+		input := createTestTranscriptFile(t, "[00:01:30] Bob: I'll book a room.")
+		output := filepath.Join(t.TempDir(), "notes.md")
+		env := echoStructureEnv(&syncBuffer{})
+		env.RestructurerFactory.(*mockRestructurerFactory).mockMapReducer.ExtractActionsFunc = func(ctx context.Context, transcript string, outputLang lang.Language) ([]restructure.ActionItem, error) {
+			at := 90 * time.Second
+			return []restructure.ActionItem{{Description: "Book a room", Owner: "Bob", Timestamp: &at}}, nil
+		}
+
+		cmd := StructureCmd(env)
+		cmd.SetArgs([]string{input, "-t", "meeting", "-o", output, "--actions=csv"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("StructureCmd.Execute() unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(output), "notes.tasks.csv"))
+		if err != nil {
+			t.Fatalf("failed to read action items: %v", err)
+		}
+		if want := "description,owner,due,timestamp\nBook a room,Bob,,01:30\n"; string(data) != want {
+			t.Errorf("action items = %q, want %q", data, want)
+		}
+	})
+
+	t.Run("extraction failed", func(t *testing.T) {
+		t.Parallel()
+
+		input := createTestTranscriptFile(t, "content")
+		output := filepath.Join(t.TempDir(), "notes.md")
+		stderr := &syncBuffer{}
+		env := echoStructureEnv(stderr)
+		env.RestructurerFactory.(*mockRestructurerFactory).mockMapReducer.ExtractActionsFunc = func(ctx context.Context, transcript string, outputLang lang.Language) ([]restructure.ActionItem, error) {
+			return nil, restructure.ErrInvalidActions
+		}
+
+		cmd := StructureCmd(env)
+		cmd.SetArgs([]string{input, "-t", "meeting", "-o", output, "--actions"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("StructureCmd.Execute() unexpected error: %v", err)
+		}
+		if _, err := os.Stat(output); err != nil {
+			t.Errorf("output error = %v, want the notes written", err)
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(output), "notes.tasks.json")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("action items file error = %v, want none written", err)
+		}
+		if !strings.Contains(stderr.String(), warnActionsFailed) {
+			t.Errorf("stderr = %q, want the %s warning", stderr.String(), warnActionsFailed)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		input := createTestTranscriptFile(t, "content")
+		cmd := StructureCmd(echoStructureEnv(&syncBuffer{}))
+		cmd.SetArgs([]string{input, "-t", "meeting", "--actions=xml"})
+		if err := cmd.Execute(); !errors.Is(err, ErrInvalidActionsFormat) {
+			t.Errorf("StructureCmd.Execute() error = %v, want ErrInvalidActionsFormat", err)
+		}
+	})
+}
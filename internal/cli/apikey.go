@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/keyring"
+)
+
+// apiKeyEnvVars maps the providers with an API key to its environment variable.
+var apiKeyEnvVars = map[Provider]string{
+	OpenAIProvider:    EnvOpenAIAPIKey,
+	DeepSeekProvider:  EnvDeepSeekAPIKey,
+	AnthropicProvider: EnvAnthropicAPIKey,
+}
+
+// apiKey returns the API key of the environment variable name, or the key
+// stored in the keyring with config set-key if the variable is unset.
+// Returns an empty key if neither has one: keyring errors are ignored, so
+// that systems without a keyring fall back to the environment.
+func apiKey(env *Env, name string) string {
+	key, _ := lookupAPIKey(env, name)
+	return key
+}
+
+// lookupAPIKey is apiKey, also reporting whether the key comes from the keyring.
+// The environment takes precedence, so that a key can be overridden for one run.
+func lookupAPIKey(env *Env, name string) (key string, fromKeyring bool) {
+	if key := env.Getenv(name); key != "" {
+		return key, false
+	}
+	if env.Keyring == nil {
+		return "", false
+	}
+	key, err := env.Keyring.Get(name)
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// setKeyHint returns the ways to set the key of envVar for provider, for
+// errors of missing keys.
+func setKeyHint(envVar, example string, provider Provider) string {
+	return fmt.Sprintf("set it with: export %s=%s, or: transcript config set-key --provider %s", envVar, example, provider)
+}
+
+// configSetKeyCmd creates the "config set-key" subcommand.
+func configSetKeyCmd(env *Env) *cobra.Command {
+	var provider string
+
+	cmd := &cobra.Command{
+		Use:   "set-key --provider <provider>",
+		Short: "Store an API key in the OS keyring",
+		Long: `Store the API key of a provider in the keyring of the OS: the macOS
+Keychain, the Windows Credential Manager, or the Secret Service on Linux
+(GNOME Keyring, KWallet, through secret-tool).
+
+The key is read from standard input, so that it does not end up in the shell
+history. Keys stored this way are used when the environment variable
+(OPENAI_API_KEY, DEEPSEEK_API_KEY or ANTHROPIC_API_KEY) is not set: the
+environment and .env files take precedence.
+
+Providers: openai (transcription and --provider openai), deepseek, anthropic.`,
+		Example: `  transcript config set-key --provider openai
+  pbpaste | transcript config set-key --provider deepseek`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := ParseProvider(provider)
+			if err != nil {
+				return err
+			}
+			prompt := false
+			if f, ok := cmd.InOrStdin().(*os.File); ok {
+				prompt = isTerminal(f)
+			}
+			return runConfigSetKey(env, cmd.InOrStdin(), cmd.OutOrStdout(), p, prompt)
+		},
+	}
+
+	cmd.Flags().StringVar(&provider, "provider", "", "Provider of the key: openai, deepseek, anthropic (required)")
+	_ = cmd.MarkFlagRequired("provider")
+
+	return cmd
+}
+
+// runConfigSetKey reads the API key of provider from r and stores it in the
+// keyring. With prompt, asks for the key on env.Stderr first.
+func runConfigSetKey(env *Env, r io.Reader, w io.Writer, provider Provider, prompt bool) error {
+	envVar, ok := apiKeyEnvVars[provider]
+	if !ok {
+		return fmt.Errorf("%w: %s uses no API key", ErrInvalidProvider, provider)
+	}
+	if env.Keyring == nil {
+		return fmt.Errorf("%w: export %s or put it in a .env file instead", keyring.ErrUnavailable, envVar)
+	}
+
+	if prompt {
+		fmt.Fprintf(env.Stderr, "Paste the %s API key and press Enter: ", provider)
+	}
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("cannot read the API key: %w", err)
+	}
+	key := strings.TrimSpace(line)
+	if key == "" {
+		return fmt.Errorf("no API key read from standard input")
+	}
+
+	if err := env.Keyring.Set(envVar, key); err != nil {
+		if errors.Is(err, keyring.ErrUnavailable) {
+			return fmt.Errorf("%w (export %s or put it in a .env file instead)", err, envVar)
+		}
+		return err
+	}
+	fmt.Fprintf(w, "Stored %s in the keyring\n", envVar)
+	if env.Getenv(envVar) != "" {
+		warnf(env.Stderr, warnKeyringOverridden, "%s is set in the environment and takes precedence over the keyring", envVar)
+	}
+	return nil
+}
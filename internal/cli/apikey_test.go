@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/keyring"
+)
+
+func TestLookupAPIKey(t *testing.T) {
+	t.Parallel()
+
+	stored := &mockKeyring{secrets: map[string]string{EnvOpenAIAPIKey: "sk-stored"}}
+	tests := []struct {
+		name            string
+		env             map[string]string
+		keyring         keyring.Keyring
+		wantKey         string
+		wantFromKeyring bool
+	}{
+		{name: "environment", env: map[string]string{EnvOpenAIAPIKey: "sk-env"}, keyring: stored, wantKey: "sk-env"},
+		{name: "keyring when unset", keyring: stored, wantKey: "sk-stored", wantFromKeyring: true},
+		{name: "no keyring", wantKey: ""},
+		{name: "keyring unavailable", keyring: &mockKeyring{Err: keyring.ErrUnavailable}, wantKey: ""},
+		{name: "not in keyring", keyring: &mockKeyring{}, wantKey: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env, _ := testEnv(func(o *testEnvOptions) { o.getenv = staticEnv(tt.env) })
+			env.Keyring = tt.keyring
+			key, fromKeyring := lookupAPIKey(env, EnvOpenAIAPIKey)
+			if key != tt.wantKey || fromKeyring != tt.wantFromKeyring {
+				t.Errorf("lookupAPIKey() = %q, %v, want %q, %v", key, fromKeyring, tt.wantKey, tt.wantFromKeyring)
+			}
+		})
+	}
+}
+
+func TestRestructureAPIKey_Keyring(t *testing.T) {
+	t.Parallel()
+
+	env, _ := testEnv(func(o *testEnvOptions) { o.getenv = staticEnv(nil) })
+	env.Keyring = &mockKeyring{secrets: map[string]string{EnvDeepSeekAPIKey: "sk-stored"}}
+
+	key, err := restructureAPIKey(env, DeepSeekProvider)
+	if err != nil || key != "sk-stored" {
+		t.Errorf("restructureAPIKey(deepseek) = %q, %v, want the key of the keyring", key, err)
+	}
+
+	_, err = restructureAPIKey(env, AnthropicProvider)
+	if !errors.Is(err, ErrAnthropicKeyMissing) || !strings.Contains(err.Error(), "transcript config set-key --provider anthropic") {
+		t.Errorf("restructureAPIKey(anthropic) error = %v, want ErrAnthropicKeyMissing suggesting set-key", err)
+	}
+}
+
+func TestRunConfigSetKey(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stores the key read", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		env, _ := testEnv(func(o *testEnvOptions) {
+			o.stderr = stderr
+			o.getenv = staticEnv(nil)
+		})
+		kr := &mockKeyring{}
+		env.Keyring = kr
+
+		var out bytes.Buffer
+		if err := runConfigSetKey(env, strings.NewReader("  sk-new \n"), &out, DeepSeekProvider, true); err != nil {
+			t.Fatalf("runConfigSetKey() unexpected error: %v", err)
+		}
+		if got, _ := kr.Get(EnvDeepSeekAPIKey); got != "sk-new" {
+			t.Errorf("stored key = %q, want %q", got, "sk-new")
+		}
+		if out.String() != "Stored DEEPSEEK_API_KEY in the keyring\n" {
+			t.Errorf("output = %q, want the key stored", out.String())
+		}
+		if !strings.Contains(stderr.String(), "Paste the deepseek API key") {
+			t.Errorf("stderr = %q, want the prompt", stderr.String())
+		}
+	})
+
+	t.Run("warns when the environment overrides it", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		env, _ := testEnv(func(o *testEnvOptions) { o.stderr = stderr })
+		env.Keyring = &mockKeyring{}
+
+		if err := runConfigSetKey(env, strings.NewReader("sk-new"), &bytes.Buffer{}, OpenAIProvider, false); err != nil {
+			t.Fatalf("runConfigSetKey() unexpected error: %v", err)
+		}
+		if !strings.Contains(stderr.String(), "OPENAI_API_KEY is set in the environment and takes precedence") ||
+			!strings.Contains(stderr.String(), "(code "+warnKeyringOverridden+")") {
+			t.Errorf("stderr = %q, want a warning about the environment", stderr.String())
+		}
+		if strings.Contains(stderr.String(), "Paste") {
+			t.Errorf("stderr = %q, want no prompt", stderr.String())
+		}
+	})
+
+	tests := []struct {
+		name     string
+		provider Provider
+		input    string
+		keyring  keyring.Keyring
+		wantErr  error
+		wantMsg  string
+	}{
+		{name: "provider without key", provider: OllamaProvider, input: "sk", keyring: &mockKeyring{}, wantErr: ErrInvalidProvider},
+		{name: "empty input", provider: OpenAIProvider, input: "\n", keyring: &mockKeyring{}, wantMsg: "no API key"},
+		{name: "no keyring", provider: OpenAIProvider, input: "sk", wantErr: keyring.ErrUnavailable, wantMsg: "export OPENAI_API_KEY"},
+		{
+			name:     "keyring unavailable",
+			provider: AnthropicProvider,
+			input:    "sk-ant",
+			keyring:  &mockKeyring{Err: keyring.ErrUnavailable},
+			wantErr:  keyring.ErrUnavailable,
+			wantMsg:  "export ANTHROPIC_API_KEY or put it in a .env file instead",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env, _ := testEnv()
+			env.Keyring = tt.keyring
+			err := runConfigSetKey(env, strings.NewReader(tt.input), &bytes.Buffer{}, tt.provider, false)
+			if err == nil {
+				t.Fatal("runConfigSetKey() expected error, got nil")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("runConfigSetKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("runConfigSetKey() error = %q, want containing %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestRunConfigSetKey_SuppressWarn(t *testing.T) {
+	resetWarnings(t)
+	if err := ConfigureWarnings([]string{warnKeyringOverridden}, false); err != nil {
+		t.Fatalf("ConfigureWarnings() unexpected error: %v", err)
+	}
+
+	stderr := &syncBuffer{}
+	env, _ := testEnv(func(o *testEnvOptions) { o.stderr = stderr })
+	env.Keyring = &mockKeyring{}
+
+	if err := runConfigSetKey(env, strings.NewReader("sk-new"), &bytes.Buffer{}, OpenAIProvider, false); err != nil {
+		t.Fatalf("runConfigSetKey() unexpected error: %v", err)
+	}
+	if strings.Contains(stderr.String(), "takes precedence") {
+		t.Errorf("stderr = %q, want the warning suppressed", stderr.String())
+	}
+}
+
+func TestConfigSetKeyCmd(t *testing.T) {
+	t.Parallel()
+
+	t.Run("requires a provider", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		cmd := configSetKeyCmd(env)
+		cmd.SetArgs(nil)
+		cmd.SetContext(context.Background())
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "provider") {
+			t.Errorf("Execute() error = %v, want the provider flag required", err)
+		}
+	})
+
+	t.Run("stores the key of stdin", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		kr := &mockKeyring{}
+		env.Keyring = kr
+		cmd := configSetKeyCmd(env)
+		cmd.SetArgs([]string{"--provider", "openai"})
+		cmd.SetIn(strings.NewReader("sk-piped\n"))
+		cmd.SetOut(&bytes.Buffer{})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute() unexpected error: %v", err)
+		}
+		if got, _ := kr.Get(EnvOpenAIAPIKey); got != "sk-piped" {
+			t.Errorf("stored key = %q, want %q", got, "sk-piped")
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// azureConfig returns the Azure OpenAI settings of cfg. The endpoint is empty
+// if OpenAI is called directly.
+func azureConfig(cfg config.Config) AzureConfig {
+	return AzureConfig{
+		Endpoint:             cfg.AzureEndpoint,
+		TranscribeDeployment: cfg.AzureTranscribeDeployment,
+		ChatDeployment:       cfg.AzureChatDeployment,
+		APIVersion:           cfg.AzureAPIVersion,
+	}
+}
+
+// validateAzureTranscription checks that azure has a transcription deployment
+// when it has an endpoint.
+func validateAzureTranscription(azure AzureConfig) error {
+	if azure.Endpoint == "" || azure.TranscribeDeployment != "" {
+		return nil
+	}
+	return fmt.Errorf("%w: azure-endpoint is set (set it with: transcript config set %s <name>)",
+		ErrAzureDeploymentMissing, config.KeyAzureTranscribe)
+}
+
+// validateAzureChat checks that azure has a chat deployment when it has an
+// endpoint and provider is OpenAI. Other providers do not use Azure.
+func validateAzureChat(provider Provider, azure AzureConfig) error {
+	if !provider.IsOpenAI() || azure.Endpoint == "" || azure.ChatDeployment != "" {
+		return nil
+	}
+	return fmt.Errorf("%w: azure-endpoint is set (set it with: transcript config set %s <name>)",
+		ErrAzureDeploymentMissing, config.KeyAzureChat)
+}
+
+// newOpenAITranscriber creates the transcriber of the openai backend: the
+// transcription deployment of azure if it has an endpoint, OpenAI otherwise.
+func newOpenAITranscriber(env *Env, azure AzureConfig) transcribe.Transcriber {
+	key := apiKey(env, EnvOpenAIAPIKey)
+	if azure.Endpoint != "" {
+		return env.TranscriberFactory.NewAzureTranscriber(key, azure)
+	}
+	return env.TranscriberFactory.NewTranscriber(key)
+}
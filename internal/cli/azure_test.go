@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+func TestAzureConfig(t *testing.T) {
+	t.Parallel()
+
+	got := azureConfig(config.Config{
+		AzureEndpoint:             "https://contoso.openai.azure.com",
+		AzureTranscribeDeployment: "whisper",
+		AzureChatDeployment:       "gpt-4o",
+		AzureAPIVersion:           "2025-03-01-preview",
+	})
+	want := AzureConfig{
+		Endpoint:             "https://contoso.openai.azure.com",
+		TranscribeDeployment: "whisper",
+		ChatDeployment:       "gpt-4o",
+		APIVersion:           "2025-03-01-preview",
+	}
+	if got != want {
+		t.Errorf("azureConfig() = %+v, want %+v", got, want)
+	}
+}
+
+func TestValidateAzureChat(t *testing.T) {
+	t.Parallel()
+
+	endpoint := "https://contoso.openai.azure.com"
+	tests := []struct {
+		name     string
+		provider Provider
+		azure    AzureConfig
+		wantErr  bool
+	}{
+		{name: "no endpoint", provider: OpenAIProvider},
+		{name: "chat deployment", provider: OpenAIProvider, azure: AzureConfig{Endpoint: endpoint, ChatDeployment: "gpt-4o"}},
+		{name: "missing chat deployment", provider: OpenAIProvider, azure: AzureConfig{Endpoint: endpoint}, wantErr: true},
+		{name: "other provider ignores azure", provider: DeepSeekProvider, azure: AzureConfig{Endpoint: endpoint}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateAzureChat(tt.provider, tt.azure)
+			if tt.wantErr != errors.Is(err, ErrAzureDeploymentMissing) {
+				t.Errorf("validateAzureChat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewOpenAITranscriber_Azure(t *testing.T) {
+	t.Parallel()
+
+	env, mocks := testEnv()
+	azure := AzureConfig{Endpoint: "https://contoso.openai.azure.com", TranscribeDeployment: "whisper"}
+
+	if tr := newOpenAITranscriber(env, azure); tr == nil {
+		t.Fatal("newOpenAITranscriber() = nil")
+	}
+	if got := mocks.transcriber.AzureConfigs(); len(got) != 1 || got[0] != azure {
+		t.Errorf("NewAzureTranscriber() configs = %+v, want [%+v]", got, azure)
+	}
+	if got := mocks.transcriber.NewTranscriberCalls(); len(got) != 0 {
+		t.Errorf("NewTranscriber() calls = %v, want none", got)
+	}
+
+	newOpenAITranscriber(env, AzureConfig{})
+	if got := mocks.transcriber.NewTranscriberCalls(); len(got) != 1 {
+		t.Errorf("NewTranscriber() calls = %v, want one without azure-endpoint", got)
+	}
+}
+
+func TestRestructureContent_Azure(t *testing.T) {
+	t.Parallel()
+
+	endpoint := "https://contoso.openai.azure.com"
+	tests := []struct {
+		name      string
+		provider  Provider
+		azure     AzureConfig
+		wantAzure bool
+		wantErr   error
+	}{
+		{name: "openai on azure", provider: OpenAIProvider, azure: AzureConfig{Endpoint: endpoint, ChatDeployment: "gpt-4o"}, wantAzure: true},
+		{name: "openai without azure", provider: OpenAIProvider},
+		{name: "deepseek ignores azure", provider: DeepSeekProvider, azure: AzureConfig{Endpoint: endpoint}},
+		{name: "missing chat deployment", provider: OpenAIProvider, azure: AzureConfig{Endpoint: endpoint}, wantErr: ErrAzureDeploymentMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			factory := &mockRestructurerFactory{}
+			env := &Env{Stderr: &syncBuffer{}, Getenv: defaultTestEnv, RestructurerFactory: factory}
+
+			_, err := RestructureContent(context.Background(), env, "content", RestructureOptions{
+				Template: template.MustParseName("notes"),
+				Provider: tt.provider,
+				Model:    "gpt-4o",
+				Azure:    tt.azure,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("RestructureContent() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RestructureContent() unexpected error: %v", err)
+			}
+
+			calls := factory.NewMapReducerCalls()
+			if len(calls) != 1 {
+				t.Fatalf("factory calls = %d, want 1", len(calls))
+			}
+			if gotAzure := calls[0].Azure != (AzureConfig{}); gotAzure != tt.wantAzure {
+				t.Errorf("factory call = %+v, want Azure %v", calls[0], tt.wantAzure)
+			}
+			if tt.wantAzure && (calls[0].Model != "gpt-4o" || calls[0].APIKey == "") {
+				t.Errorf("factory call = %+v, want the model and the OpenAI key", calls[0])
+			}
+		})
+	}
+}
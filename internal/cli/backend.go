@@ -0,0 +1,284 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// Transcription backend names. The other backends are the speech-to-text APIs
+// registered in the transcribe package (see transcribe.ProviderNames).
+const (
+	// BackendOpenAI transcribes with the OpenAI API.
+	BackendOpenAI = "openai"
+	// BackendLocal transcribes offline with whisper.cpp (or a local whisper server).
+	BackendLocal = "local"
+	// BackendGRPC streams the audio to an in-house ASR server implementing docs/asr.proto.
+	BackendGRPC = "grpc"
+)
+
+// grpcEndpointFlagHelp describes the --grpc-endpoint flag of the transcribe and live commands.
+const grpcEndpointFlagHelp = "host:port of the ASR server of the grpc transcriber (default: config grpc-endpoint)"
+
+// transcriberFlagHelp describes the --transcriber flag of the commands transcribing audio.
+const transcriberFlagHelp = "Transcription backend: openai, local, grpc, groq, deepgram, assemblyai (default: config or openai)"
+
+// Aliases of --transcriber and --grpc-endpoint, for scripts written for other
+// speech-to-text tools: --stt-provider grpc --stt-endpoint host:port.
+const (
+	sttProviderFlagHelp = "Same as --transcriber"
+	sttEndpointFlagHelp = "Same as --grpc-endpoint"
+)
+
+// Backend represents a validated transcription backend.
+// Zero value means "not set" and defaults to OpenAI.
+// Use ParseBackend to create from user input, or the pre-parsed constants.
+type Backend struct {
+	name string
+}
+
+// Compile-time interface compliance check.
+var _ fmt.Stringer = Backend{}
+
+// Pre-parsed backend constants for use in code.
+var (
+	OpenAIBackend = Backend{name: BackendOpenAI}
+	LocalBackend  = Backend{name: BackendLocal}
+	GRPCBackend   = Backend{name: BackendGRPC}
+)
+
+// ParseBackend validates and parses a transcription backend name: openai,
+// local, grpc, or a provider registered in the transcribe package.
+// Returns ErrInvalidBackend if the name is not recognized.
+func ParseBackend(s string) (Backend, error) {
+	switch s {
+	case BackendOpenAI, BackendLocal, BackendGRPC:
+		return Backend{name: s}, nil
+	case "":
+		return Backend{}, fmt.Errorf("transcriber cannot be empty: %w", ErrInvalidBackend)
+	}
+	if _, err := transcribe.LookupProvider(s); err == nil {
+		return Backend{name: s}, nil
+	}
+	return Backend{}, fmt.Errorf("unknown transcriber %q (available: %s): %w",
+		s, strings.Join(backendNames(), ", "), ErrInvalidBackend)
+}
+
+// backendNames returns the names of the transcription backends, sorted.
+func backendNames() []string {
+	names := append(transcribe.ProviderNames(), BackendLocal, BackendGRPC)
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// String returns the backend name string.
+// Returns empty string for zero value.
+func (b Backend) String() string {
+	return b.name
+}
+
+// IsZero returns true if this is the zero value (no backend set).
+func (b Backend) IsZero() bool {
+	return b.name == ""
+}
+
+// IsLocal returns true if this backend transcribes locally.
+func (b Backend) IsLocal() bool {
+	return b.name == BackendLocal
+}
+
+// IsGRPC returns true if this backend streams the audio to a gRPC server.
+func (b Backend) IsGRPC() bool {
+	return b.name == BackendGRPC
+}
+
+// IsOpenAI returns true if this backend transcribes with the OpenAI API,
+// including the zero value.
+func (b Backend) IsOpenAI() bool {
+	return b.OrDefault().name == BackendOpenAI
+}
+
+// provider returns the speech-to-text API backend transcribes with, OpenAI
+// included. ok is false for the local and gRPC backends.
+func (b Backend) provider() (p transcribe.Provider, ok bool) {
+	if b.IsLocal() || b.IsGRPC() {
+		return transcribe.Provider{}, false
+	}
+	p, err := transcribe.LookupProvider(b.OrDefault().name)
+	return p, err == nil
+}
+
+// transcriptionModel returns the model transcribing with opts, for cost
+// estimates: empty for the local and gRPC backends, which cost nothing, the
+// provider name if its model is unknown.
+func (b Backend) transcriptionModel(opts transcribe.Options) string {
+	p, ok := b.provider()
+	switch {
+	case !ok:
+		return ""
+	case p.Model == nil:
+		return p.Name
+	}
+	return p.Model(opts)
+}
+
+// OrDefault returns the backend, or OpenAIBackend if zero.
+func (b Backend) OrDefault() Backend {
+	if b.IsZero() {
+		return OpenAIBackend
+	}
+	return b
+}
+
+// resolveBackend returns the transcription backend to use: the --transcriber
+// flag if given, else the configured transcriber, else OpenAI.
+func resolveBackend(flag Backend, cfg config.Config) (Backend, error) {
+	if !flag.IsZero() {
+		return flag, nil
+	}
+	if cfg.Transcriber == "" {
+		return OpenAIBackend, nil
+	}
+	backend, err := ParseBackend(cfg.Transcriber)
+	if err != nil {
+		return Backend{}, fmt.Errorf("invalid %s setting: %w", config.KeyTranscriber, err)
+	}
+	return backend, nil
+}
+
+// validateBackend checks the requirements of backend: the OpenAI API key (and
+// the Azure deployment with azure-endpoint), the gRPC endpoint, the API key of
+// another provider and its support of diarization, or a local model and no
+// diarization (local transcription cannot identify speakers).
+func validateBackend(env *Env, backend Backend, cfg config.Config, diarize bool) error {
+	if backend.IsOpenAI() {
+		if apiKey(env, EnvOpenAIAPIKey) == "" {
+			return fmt.Errorf("%w (%s)", ErrAPIKeyMissing, setKeyHint(EnvOpenAIAPIKey, "sk-...", OpenAIProvider))
+		}
+		return validateAzureTranscription(azureConfig(cfg))
+	}
+	if backend.IsGRPC() {
+		if cfg.GRPCEndpoint == "" {
+			return fmt.Errorf("%w (set it with: --grpc-endpoint host:port, or transcript config set %s host:port)",
+				ErrGRPCEndpointMissing, config.KeyGRPCEndpoint)
+		}
+		return nil
+	}
+	if p, ok := backend.provider(); ok {
+		if apiKey(env, p.APIKeyEnv) == "" {
+			return fmt.Errorf("%s: %w (set it with: export %s=...)", p.APIKeyEnv, ErrTranscriberKeyMissing, p.APIKeyEnv)
+		}
+		if diarize && !p.Diarize {
+			return fmt.Errorf("--diarize is not supported by the %s transcriber: %w", p.Name, transcribe.ErrDiarizeUnsupported)
+		}
+		return nil
+	}
+	if diarize {
+		return fmt.Errorf("--diarize requires the openai transcriber: %w", transcribe.ErrDiarizeUnsupported)
+	}
+	if cfg.WhisperModel == "" && cfg.WhisperURL == "" {
+		return fmt.Errorf("%w (set it with: transcript config set %s <path>)",
+			transcribe.ErrModelNotFound, config.KeyWhisperModel)
+	}
+	return nil
+}
+
+// validateTranslateAudio checks that backend can translate speech to English
+// (--translate-audio): the local backend (whisper.cpp), and the providers
+// supporting it, OpenAI included.
+func validateTranslateAudio(backend Backend) error {
+	if p, ok := backend.provider(); backend.IsLocal() || (ok && p.Translate) {
+		return nil
+	}
+	return fmt.Errorf("--translate-audio is not supported by the %s transcriber: %w", backend, transcribe.ErrTranslateUnsupported)
+}
+
+// newTranscriber creates the transcriber of backend.
+// Local transcribers fail here if the model or binary is missing, gRPC
+// transcribers if the endpoint or the TLS settings are invalid.
+func newTranscriber(env *Env, backend Backend, cfg config.Config, ffmpegPath string) (transcribe.Transcriber, error) {
+	if backend.IsOpenAI() {
+		return newOpenAITranscriber(env, azureConfig(cfg)), nil
+	}
+	if p, ok := backend.provider(); ok {
+		return env.TranscriberFactory.NewProviderTranscriber(p.Name, apiKey(env, p.APIKeyEnv))
+	}
+	if backend.IsGRPC() {
+		tlsCfg, err := tlsOptions(cfg).Config()
+		if err != nil {
+			return nil, err
+		}
+		return env.TranscriberFactory.NewGRPCTranscriber(GRPCTranscriberConfig{
+			Endpoint:  cfg.GRPCEndpoint,
+			Plaintext: cfg.GRPCPlaintext,
+			Timeout:   cfg.GRPCTimeout,
+			TLS:       tlsCfg,
+		})
+	}
+	return env.TranscriberFactory.NewLocalTranscriber(LocalTranscriberConfig{
+		ModelPath:  cfg.WhisperModel,
+		BinaryPath: cfg.WhisperBin,
+		ServerURL:  cfg.WhisperURL,
+		FFmpegPath: ffmpegPath,
+	})
+}
+
+// withLocalFallback returns t, sending the chunks too large for the API of
+// backend to the local backend when one is configured (whisper-model or
+// whisper-url), instead of failing the run. Each switch is logged and recorded
+// in the session metadata. The local transcriber is only created if needed.
+func withLocalFallback(env *Env, t transcribe.Transcriber, backend Backend, cfg config.Config, ffmpegPath string, chunks []audio.Chunk, sess *session) transcribe.Transcriber {
+	if _, ok := backend.provider(); !ok || (cfg.WhisperModel == "" && cfg.WhisperURL == "") {
+		return t
+	}
+	indexes := make(map[string]int, len(chunks))
+	for _, c := range chunks {
+		indexes[c.Path] = c.Index
+	}
+	local := &lazyTranscriber{create: func() (transcribe.Transcriber, error) {
+		local, err := newTranscriber(env, LocalBackend, cfg, ffmpegPath)
+		if err != nil {
+			return nil, fmt.Errorf("local fallback: %w", err)
+		}
+		return local, nil
+	}}
+	return transcribe.NewFallbackTranscriber(t, local, func(audioPath string, err error) {
+		index := indexes[audioPath]
+		warnf(env.Stderr, warnLocalFallback, "chunk %d: %v, transcribing it with the local backend", index+1, err)
+		if err := sess.recordFallback(index); err != nil {
+			warnf(env.Stderr, warnFileNotSaved, "failed to save session metadata: %v", err)
+		}
+	})
+}
+
+// lazyTranscriber creates its transcriber on first use.
+type lazyTranscriber struct {
+	create func() (transcribe.Transcriber, error)
+
+	once sync.Once
+	t    transcribe.Transcriber
+	err  error
+}
+
+// Transcribe creates the transcriber if needed, then transcribes audioPath.
+func (l *lazyTranscriber) Transcribe(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+	l.once.Do(func() { l.t, l.err = l.create() })
+	if l.err != nil {
+		return "", l.err
+	}
+	return l.t.Transcribe(ctx, audioPath, opts)
+}
+
+// newRateLimiter creates the rate limiter shared by the parallel chunks of a
+// run, from the configured rates (unlimited if not configured). It slows
+// down all chunks once the API answers with a rate limit.
+func newRateLimiter(cfg config.Config) *transcribe.RateLimiter {
+	return transcribe.NewRateLimiter(cfg.RateLimitRequests, time.Duration(cfg.RateLimitAudio)*time.Second)
+}
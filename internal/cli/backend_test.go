@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestParseBackend(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    Backend
+		wantErr bool
+	}{
+		{name: "openai valid", input: "openai", want: OpenAIBackend},
+		{name: "local valid", input: "local", want: LocalBackend},
+		{name: "grpc valid", input: "grpc", want: GRPCBackend},
+		{name: "registered provider valid", input: "deepgram", want: Backend{name: transcribe.ProviderDeepgram}},
+		{name: "empty string returns error", input: "", wantErr: true},
+		{name: "invalid backend returns error", input: "whisper", wantErr: true},
+		{name: "case sensitive - LOCAL invalid", input: "LOCAL", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseBackend(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseBackend(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseBackend(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidBackend) {
+				t.Errorf("ParseBackend(%q) error should wrap ErrInvalidBackend, got %v", tt.input, err)
+			}
+		})
+	}
+}
+
+func TestBackend_Methods(t *testing.T) {
+	t.Parallel()
+
+	var zero Backend
+	if !zero.IsZero() || zero.String() != "" {
+		t.Errorf("zero Backend: IsZero() = %v, String() = %q", zero.IsZero(), zero.String())
+	}
+	if zero.OrDefault() != OpenAIBackend {
+		t.Errorf("zero.OrDefault() = %v, want %v", zero.OrDefault(), OpenAIBackend)
+	}
+	if LocalBackend.OrDefault() != LocalBackend {
+		t.Errorf("LocalBackend.OrDefault() = %v, want %v", LocalBackend.OrDefault(), LocalBackend)
+	}
+	if !LocalBackend.IsLocal() || OpenAIBackend.IsLocal() {
+		t.Error("IsLocal() should be true only for LocalBackend")
+	}
+	if !GRPCBackend.IsGRPC() || OpenAIBackend.IsGRPC() || LocalBackend.IsGRPC() {
+		t.Error("IsGRPC() should be true only for GRPCBackend")
+	}
+	if !zero.IsOpenAI() || !OpenAIBackend.IsOpenAI() || LocalBackend.IsOpenAI() || GRPCBackend.IsOpenAI() {
+		t.Error("IsOpenAI() should be true only for OpenAIBackend and the zero value")
+	}
+}
+
+func TestResolveBackend(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		flag    Backend
+		cfg     config.Config
+		want    Backend
+		wantErr bool
+	}{
+		{name: "defaults to openai", want: OpenAIBackend},
+		{name: "config is used without flag", cfg: config.Config{Transcriber: "local"}, want: LocalBackend},
+		{name: "flag overrides config", flag: OpenAIBackend, cfg: config.Config{Transcriber: "local"}, want: OpenAIBackend},
+		{name: "invalid config value", cfg: config.Config{Transcriber: "bogus"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := resolveBackend(tt.flag, tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveBackend() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidBackend) {
+					t.Errorf("resolveBackend() error should wrap ErrInvalidBackend, got %v", err)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("resolveBackend() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBackend(t *testing.T) {
+	t.Parallel()
+
+	noKey := func(string) string { return "" }
+
+	tests := []struct {
+		name    string
+		getenv  func(string) string
+		backend Backend
+		cfg     config.Config
+		diarize bool
+		wantErr error
+	}{
+		{name: "openai with key", getenv: defaultTestEnv, backend: OpenAIBackend},
+		{name: "openai without key", getenv: noKey, backend: OpenAIBackend, wantErr: ErrAPIKeyMissing},
+		{name: "azure with deployment", getenv: defaultTestEnv, backend: OpenAIBackend, cfg: config.Config{AzureEndpoint: "https://contoso.openai.azure.com", AzureTranscribeDeployment: "whisper"}},
+		{name: "azure without deployment", getenv: defaultTestEnv, backend: OpenAIBackend, cfg: config.Config{AzureEndpoint: "https://contoso.openai.azure.com"}, wantErr: ErrAzureDeploymentMissing},
+		{name: "local needs no key", getenv: noKey, backend: LocalBackend, cfg: config.Config{WhisperModel: "/models/base.bin"}},
+		{name: "local server needs no model", getenv: noKey, backend: LocalBackend, cfg: config.Config{WhisperURL: "http://localhost:8080"}},
+		{name: "local without model", getenv: noKey, backend: LocalBackend, wantErr: transcribe.ErrModelNotFound},
+		{name: "grpc needs no key", getenv: noKey, backend: GRPCBackend, cfg: config.Config{GRPCEndpoint: "asr.internal:50051"}, diarize: true},
+		{name: "grpc without endpoint", getenv: noKey, backend: GRPCBackend, wantErr: ErrGRPCEndpointMissing},
+		{name: "local rejects diarize", getenv: noKey, backend: LocalBackend, cfg: config.Config{WhisperModel: "/models/base.bin"}, diarize: true, wantErr: transcribe.ErrDiarizeUnsupported},
+		{name: "provider with key", getenv: staticEnv(map[string]string{"DEEPGRAM_API_KEY": "dg-key"}), backend: Backend{name: transcribe.ProviderDeepgram}, diarize: true},
+		{name: "provider without key", getenv: defaultTestEnv, backend: Backend{name: transcribe.ProviderAssemblyAI}, wantErr: ErrTranscriberKeyMissing},
+		{name: "provider rejects diarize", getenv: staticEnv(map[string]string{"GROQ_API_KEY": "gsk-key"}), backend: Backend{name: transcribe.ProviderGroq}, diarize: true, wantErr: transcribe.ErrDiarizeUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := &Env{Getenv: tt.getenv}
+			err := validateBackend(env, tt.backend, tt.cfg, tt.diarize)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("validateBackend() unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateBackend() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTranslateAudio(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		backend Backend
+		wantErr bool
+	}{
+		{backend: Backend{}},
+		{backend: OpenAIBackend},
+		{backend: LocalBackend},
+		{backend: Backend{name: transcribe.ProviderGroq}},
+		{backend: Backend{name: transcribe.ProviderDeepgram}, wantErr: true},
+		{backend: Backend{name: transcribe.ProviderAssemblyAI}, wantErr: true},
+		{backend: GRPCBackend, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend.OrDefault().String(), func(t *testing.T) {
+			t.Parallel()
+
+			err := validateTranslateAudio(tt.backend)
+			if tt.wantErr != errors.Is(err, transcribe.ErrTranslateUnsupported) {
+				t.Errorf("validateTranslateAudio(%q) error = %v, wantErr %v", tt.backend, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBackend_TranscriptionModel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		backend Backend
+		opts    transcribe.Options
+		want    string
+	}{
+		{"openai", Backend{}, transcribe.Options{Diarize: true}, transcribe.ModelGPT4oTranscribeDiarize},
+		{"provider", Backend{name: transcribe.ProviderGroq}, transcribe.Options{}, transcribe.ModelGroqWhisperLargeV3},
+		{"local is free", LocalBackend, transcribe.Options{}, ""},
+		{"grpc is free", GRPCBackend, transcribe.Options{}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := tt.backend.transcriptionModel(tt.opts); got != tt.want {
+			t.Errorf("%s: transcriptionModel() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewTranscriber_Provider(t *testing.T) {
+	t.Parallel()
+
+	env, mocks := testEnv(func(o *testEnvOptions) {
+		o.getenv = staticEnv(map[string]string{"ASSEMBLYAI_API_KEY": "aai-key"})
+	})
+	backend, err := ParseBackend(transcribe.ProviderAssemblyAI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newTranscriber(env, backend, config.Config{}, "/usr/bin/ffmpeg"); err != nil {
+		t.Fatalf("newTranscriber() error = %v", err)
+	}
+	want := []providerTranscriberCall{{Provider: transcribe.ProviderAssemblyAI, APIKey: "aai-key"}}
+	if got := mocks.transcriber.ProviderCalls(); !slices.Equal(got, want) {
+		t.Errorf("NewProviderTranscriber() calls = %v, want %v", got, want)
+	}
+	if got := mocks.transcriber.NewTranscriberCalls(); len(got) != 0 {
+		t.Errorf("NewTranscriber() calls = %v, want none", got)
+	}
+}
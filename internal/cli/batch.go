@@ -0,0 +1,449 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/format"
+	"github.com/alnah/go-transcript/internal/notes"
+	"github.com/alnah/go-transcript/internal/progress"
+)
+
+// defaultBatchJobs is the default number of files transcribed concurrently in batch mode.
+// Each file also runs up to --parallel chunk requests, so the total number of
+// concurrent API requests is jobs × parallel.
+const defaultBatchJobs = 2
+
+// batchOptions holds validated options for batch transcription.
+type batchOptions struct {
+	inputs    []string          // Files and directories given on the command line
+	recursive bool              // Descend into subdirectories (--recursive)
+	jobs      int               // Files processed concurrently (--jobs)
+	file      transcribeOptions // Per-file options; inputPath and output are set per file
+}
+
+// batchResult is the outcome of one file in a batch.
+type batchResult struct {
+	input   string
+	output  string
+	err     error
+	elapsed time.Duration
+}
+
+// isBatchInput reports whether args must be processed in batch mode:
+// several inputs, or a directory.
+func isBatchInput(args []string) bool {
+	if len(args) > 1 {
+		return true
+	}
+	info, err := os.Stat(args[0])
+	return err == nil && info.IsDir()
+}
+
+// discoverAudioFiles expands inputs into the list of audio files to transcribe.
+// Files are kept in command-line order; directory contents are sorted by path.
+// Hidden files and directories are skipped. Duplicates are removed.
+// Explicit files must exist and hold media (fail-fast, see checkFormat);
+// directories contribute the files with a supported extension only.
+func discoverAudioFiles(inputs []string, recursive bool) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		key := path
+		if abs, err := filepath.Abs(path); err == nil {
+			key = abs
+		}
+		if !seen[key] {
+			seen[key] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, input := range inputs {
+		info, err := os.Stat(input)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("%w: %s", ErrFileNotFound, input)
+			}
+			return nil, fmt.Errorf("cannot access input: %w", err)
+		}
+
+		// Explicit files are transcribed whatever their extension, unless
+		// their content is not media: the input check of each rejects what
+		// FFmpeg cannot decode
+		if !info.IsDir() {
+			if err := checkFormat(input); err != nil {
+				return nil, fmt.Errorf("%s: %w", input, err)
+			}
+			add(input)
+			continue
+		}
+
+		found, err := findAudioFiles(input, recursive)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range found {
+			add(f)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no supported audio files in %s (supported: %s)",
+			ErrFileNotFound, strings.Join(inputs, ", "), supportedFormatsList())
+	}
+	return files, nil
+}
+
+// findAudioFiles returns the supported audio files in dir, sorted by path.
+// The files of a segmented recording are left out when its list is there.
+func findAudioFiles(dir string, recursive bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		hidden := strings.HasPrefix(d.Name(), ".") && path != dir
+		if d.IsDir() {
+			if path != dir && (hidden || !recursive) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !hidden && supportedFormats[strings.ToLower(filepath.Ext(path))] && !isListedSegment(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read directory %s: %w", dir, err)
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// isListedSegment reports whether path is a file of a segmented recording
+// whose list exists: the list is transcribed instead, as one recording.
+func isListedSegment(path string) bool {
+	list, ok := audio.SegmentListOf(path)
+	if !ok {
+		return false
+	}
+	_, err := os.Stat(list)
+	return err == nil
+}
+
+// batchOutputPaths returns the output path of each file in format f, in the
+// same order, named by the output-name pattern of cfg when set (see
+// outputNamer). outputDir (from --output) takes precedence over the
+// configured output-dir.
+// Paths are absolute so runTranscribe does not join them with output-dir again.
+// Returns an error if two inputs would write the same output.
+func batchOutputPaths(files []string, outputDir string, cfg config.Config, fields notes.NameFields, f OutputFormat) ([]string, error) {
+	name, err := outputNamer(cfg, fields, f.Extension(), func(base string) string {
+		return deriveOutputPath(base, f)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outputPaths(files, outputDir, cfg.OutputDir, name)
+}
+
+// outputPaths returns the output path of each file, named by derive from the
+// file basename. See batchOutputPaths.
+func outputPaths(files []string, outputDir, configOutputDir string, derive func(base string) string) ([]string, error) {
+	outputs := make([]string, len(files))
+	owner := make(map[string]string)
+
+	for i, file := range files {
+		name := derive(filepath.Base(file))
+		var output string
+		if outputDir != "" {
+			output = filepath.Join(outputDir, name)
+		} else {
+			output = config.ResolveOutputPath("", configOutputDir, name)
+		}
+		abs, err := filepath.Abs(output)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve output path: %w", err)
+		}
+
+		if other, ok := owner[abs]; ok {
+			return nil, fmt.Errorf("%s and %s would both be written to %s (use separate runs or rename the files)",
+				other, file, abs)
+		}
+		owner[abs] = file
+		outputs[i] = abs
+	}
+	return outputs, nil
+}
+
+// runTranscribeBatch transcribes several files with a bounded worker pool.
+// A failing file does not stop the others. Each file's progress is prefixed
+// with its name, followed by a one-line summary; a final report lists failures.
+// Returns an error joining all failures (so exit codes reflect their causes).
+func runTranscribeBatch(cmd *cobra.Command, env *Env, opts batchOptions) error {
+	ctx := cmd.Context()
+
+	// === VALIDATION (fail-fast) ===
+
+	files, err := discoverAudioFiles(opts.inputs, opts.recursive)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := env.ConfigLoader.Load()
+	if err != nil {
+		warnf(env.Stderr, warnConfigLoad, "failed to load config: %v", err)
+	}
+	if cfg, err = cfg.WithProfile(opts.file.profile); err != nil {
+		return err
+	}
+	if opts.file.grpcEndpoint != "" {
+		cfg.GRPCEndpoint = opts.file.grpcEndpoint
+	}
+
+	opts.file.backend, err = resolveBackend(opts.file.backend, cfg)
+	if err != nil {
+		return err
+	}
+	opts.file.tag, err = resolveTag(opts.file.tag, cfg)
+	if err != nil {
+		return err
+	}
+	if err := validateTranscribeRequirements(env, opts.file, cfg); err != nil {
+		return err
+	}
+
+	// --output names a directory in batch mode.
+	outputDir := opts.file.output
+	if outputDir != "" {
+		if strings.EqualFold(filepath.Ext(outputDir), opts.file.format.Extension()) {
+			return fmt.Errorf("--output must be a directory when transcribing several files, got %s", outputDir)
+		}
+		outputDir = config.ExpandPath(outputDir)
+		if err := config.EnsureOutputDir(outputDir); err != nil {
+			return fmt.Errorf("invalid output directory: %w", err)
+		}
+	}
+
+	// The Obsidian vault is where outputs go; per-file runs get its path, so
+	// that their notes are linked even when it comes from the config.
+	opts.file.obsidianVault = obsidianVaultRoot(opts.file.obsidianVault, outputDir != "" || opts.file.sessionDir != "", cfg)
+	vault, err := openObsidianVault(opts.file.obsidianVault, cfg, opts.file.format)
+	if err != nil {
+		return err
+	}
+	if vault != nil {
+		cfg.OutputDir = vault.Dir()
+	}
+
+	// With --session-dir, each file gets its own session directory instead.
+	outputs := make([]string, len(files))
+	if opts.file.sessionDir == "" {
+		outputs, err = batchOutputPaths(files, outputDir, cfg, opts.file.nameFields(env.Now()), opts.file.format)
+		if err != nil {
+			return err
+		}
+	} else {
+		for i := range outputs {
+			outputs[i] = opts.file.sessionDir
+		}
+	}
+
+	// === SETUP ===
+
+	ffmpegPath, err := resolveFFmpegOnce(ctx, env)
+	if err != nil {
+		return err
+	}
+
+	started := env.Now()
+	jobs := max(1, min(opts.jobs, len(files)))
+	fmt.Fprintf(env.Stderr, "Transcribing %d files (%d at a time)...\n", len(files), jobs)
+
+	// === TRANSCRIPTION ===
+
+	var mu sync.Mutex // Serializes writes to env.Stderr across jobs.
+	results := make([]batchResult, len(files))
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	completed := 0
+
+	for i, file := range files {
+		results[i] = batchResult{input: file, output: outputs[i]}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i].err = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			if ctx.Err() != nil {
+				results[i].err = ctx.Err()
+				return
+			}
+
+			stderr := &linePrefixWriter{mu: &mu, w: env.Stderr, prefix: "[" + filepath.Base(file) + "] "}
+			fileEnv := *env
+			fileEnv.Stderr = stderr
+			fileEnv.FFmpegResolver = resolvedFFmpeg(ffmpegPath)
+
+			fileOpts := opts.file
+			fileOpts.inputPath = file
+			fileOpts.notify = false // Notified once for the batch
+			if fileOpts.sessionDir == "" {
+				fileOpts.output = outputs[i]
+			}
+
+			// With --progress json, events of each file carry its name and stages.
+			fileCmd, flush := cmd, stderr.Flush
+			if p, ok := env.Stderr.(*jsonProgress); ok {
+				fileProgress := p.forInput(file)
+				fileEnv.Stderr = fileProgress
+				fileCmd = &cobra.Command{}
+				fileCmd.SetContext(progress.WithHooks(ctx, fileProgress.hooks(progress.Hooks{})))
+				fileCmd.SetOut(cmd.OutOrStdout())
+				flush = fileProgress.flush
+			}
+
+			start := env.Now()
+			results[i].err = runTranscribe(fileCmd, &fileEnv, fileOpts)
+			results[i].elapsed = env.Now().Sub(start)
+			flush()
+
+			mu.Lock()
+			defer mu.Unlock()
+			completed++
+			if results[i].err != nil {
+				fmt.Fprintf(env.Stderr, "[%d/%d] Failed: %s: %v\n", completed, len(files), file, results[i].err)
+			} else {
+				fmt.Fprintf(env.Stderr, "[%d/%d] Done: %s -> %s (%s)\n",
+					completed, len(files), file, outputs[i], results[i].elapsed.Round(time.Second))
+			}
+		}()
+	}
+	wg.Wait()
+
+	err = reportBatch(env.Stderr, results)
+	if opts.file.notify {
+		title, message := batchNotice(results, env.Now().Sub(started), err)
+		notifyDesktop(ctx, env.Stderr, env.Notifier, title, message)
+	}
+	return err
+}
+
+// batchNotice returns the title and message of the desktop notification of a
+// batch run for elapsed, that returned err.
+func batchNotice(results []batchResult, elapsed time.Duration, err error) (title, message string) {
+	duration := format.DurationHuman(elapsed.Round(time.Second))
+	if err != nil {
+		failed := 0
+		for _, r := range results {
+			if r.err != nil {
+				failed++
+			}
+		}
+		return "transcript transcribe failed", fmt.Sprintf("%d of %d files failed after %s", failed, len(results), duration)
+	}
+	return "transcript transcribe done", fmt.Sprintf("%d files in %s", len(results), duration)
+}
+
+// reportBatch writes the final batch report and returns the aggregated error.
+func reportBatch(w io.Writer, results []batchResult) error {
+	var errs []error
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.input, r.err))
+		}
+	}
+
+	fmt.Fprintf(w, "\nBatch complete: %d succeeded, %d failed\n", len(results)-len(errs), len(errs))
+	if len(errs) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w, "Failed:")
+	for _, err := range errs {
+		fmt.Fprintf(w, "  %v\n", err)
+	}
+	return fmt.Errorf("%d of %d files failed: %w", len(errs), len(results), errors.Join(errs...))
+}
+
+// resolveFFmpegOnce resolves and checks the FFmpeg binary of env for front
+// ends running concurrent jobs (batch, watch, bot, serve), which hand it to
+// each job as a resolvedFFmpeg: jobs must not each trigger a download.
+func resolveFFmpegOnce(ctx context.Context, env *Env) (string, error) {
+	ffmpegPath, err := env.FFmpegResolver.Resolve(ctx)
+	if err != nil {
+		return "", err
+	}
+	env.FFmpegResolver.CheckVersion(ctx, ffmpegPath)
+	return ffmpegPath, nil
+}
+
+// resolvedFFmpeg is an FFmpegResolver for an already resolved and checked binary.
+type resolvedFFmpeg string
+
+func (r resolvedFFmpeg) Resolve(context.Context) (string, error) { return string(r), nil }
+
+func (resolvedFFmpeg) CheckVersion(context.Context, string) {}
+
+// linePrefixWriter prefixes every line with prefix and forwards complete lines
+// to w while holding mu, so output of concurrent jobs never interleaves mid-line.
+// Call Flush to emit a trailing partial line.
+type linePrefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *linePrefixWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if _, err := fmt.Fprintf(p.w, "%s%s", p.prefix, p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+	return len(b), nil
+}
+
+// Flush writes any buffered partial line, terminated by a newline.
+func (p *linePrefixWriter) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.buf) > 0 {
+		_, _ = fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf)
+		p.buf = nil
+	}
+}
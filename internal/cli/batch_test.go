@@ -0,0 +1,484 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/notes"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// Notes:
+// - Discovery and output naming are tested on real temp directories
+// - runTranscribeBatch is tested end-to-end with the same mocks as runTranscribe
+
+// writeAudioFiles creates empty-but-non-empty audio files under dir.
+func writeAudioFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("fake audio content"), 0644); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests for isBatchInput
+// ---------------------------------------------------------------------------
+
+func TestIsBatchInput(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeAudioFiles(t, dir, "a.ogg")
+	file := filepath.Join(dir, "a.ogg")
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{"single file", []string{file}, false},
+		{"missing file", []string{filepath.Join(dir, "missing.ogg")}, false},
+		{"directory", []string{dir}, true},
+		{"several files", []string{file, file}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := IsBatchInput(tt.args); got != tt.expected {
+				t.Errorf("IsBatchInput(%v) = %v, want %v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests for discoverAudioFiles
+// ---------------------------------------------------------------------------
+
+func TestDiscoverAudioFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeAudioFiles(t, dir,
+		"b.mp3", "a.ogg", "notes.txt", "UPPER.WAV", ".hidden.ogg",
+		"sub/c.m4a", ".cache/d.ogg",
+	)
+	rel := func(paths []string) []string {
+		out := make([]string, len(paths))
+		for i, p := range paths {
+			r, _ := filepath.Rel(dir, p)
+			out[i] = filepath.ToSlash(r)
+		}
+		return out
+	}
+
+	t.Run("directory lists supported files sorted", func(t *testing.T) {
+		t.Parallel()
+
+		files, err := DiscoverAudioFiles([]string{dir}, false)
+		if err != nil {
+			t.Fatalf("DiscoverAudioFiles() unexpected error: %v", err)
+		}
+		want := []string{"UPPER.WAV", "a.ogg", "b.mp3"}
+		if got := rel(files); !slices.Equal(got, want) {
+			t.Errorf("DiscoverAudioFiles() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("recursive includes subdirectories but not hidden ones", func(t *testing.T) {
+		t.Parallel()
+
+		files, err := DiscoverAudioFiles([]string{dir}, true)
+		if err != nil {
+			t.Fatalf("DiscoverAudioFiles() unexpected error: %v", err)
+		}
+		want := []string{"UPPER.WAV", "a.ogg", "b.mp3", "sub/c.m4a"}
+		if got := rel(files); !slices.Equal(got, want) {
+			t.Errorf("DiscoverAudioFiles() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("explicit files keep order and are deduplicated", func(t *testing.T) {
+		t.Parallel()
+
+		b := filepath.Join(dir, "b.mp3")
+		files, err := DiscoverAudioFiles([]string{b, dir}, false)
+		if err != nil {
+			t.Fatalf("DiscoverAudioFiles() unexpected error: %v", err)
+		}
+		want := []string{"b.mp3", "UPPER.WAV", "a.ogg"}
+		if got := rel(files); !slices.Equal(got, want) {
+			t.Errorf("DiscoverAudioFiles() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("missing input returns ErrFileNotFound", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := DiscoverAudioFiles([]string{filepath.Join(dir, "missing.ogg")}, false)
+		if !errors.Is(err, ErrFileNotFound) {
+			t.Errorf("DiscoverAudioFiles() error = %v, want ErrFileNotFound", err)
+		}
+	})
+
+	t.Run("unsupported explicit file returns ErrUnsupportedFormat", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := DiscoverAudioFiles([]string{filepath.Join(dir, "notes.txt")}, false)
+		if !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("DiscoverAudioFiles() error = %v, want ErrUnsupportedFormat", err)
+		}
+	})
+
+	t.Run("explicit file with an unlisted extension is kept", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "audio.opus")
+		if err := os.WriteFile(path, []byte("OggS\x00\x02 opus audio"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files, err := DiscoverAudioFiles([]string{path}, false)
+		if err != nil {
+			t.Fatalf("DiscoverAudioFiles() unexpected error: %v", err)
+		}
+		if len(files) != 1 || files[0] != path {
+			t.Errorf("DiscoverAudioFiles() = %v, want [%s]", files, path)
+		}
+	})
+
+	t.Run("directory without audio returns ErrFileNotFound", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := DiscoverAudioFiles([]string{t.TempDir()}, true)
+		if !errors.Is(err, ErrFileNotFound) {
+			t.Errorf("DiscoverAudioFiles() error = %v, want ErrFileNotFound", err)
+		}
+	})
+}
+
+func TestDiscoverAudioFiles_SegmentedRecording(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeAudioFiles(t, dir, "day.ffconcat", "day_0000.ogg", "day_0001.ogg", "call_0000.ogg")
+
+	files, err := DiscoverAudioFiles([]string{dir}, false)
+	if err != nil {
+		t.Fatalf("DiscoverAudioFiles() unexpected error: %v", err)
+	}
+	// Files without their list (call.ffconcat) are transcribed on their own
+	want := []string{filepath.Join(dir, "call_0000.ogg"), filepath.Join(dir, "day.ffconcat")}
+	if !slices.Equal(files, want) {
+		t.Errorf("DiscoverAudioFiles() = %v, want %v", files, want)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests for batchOutputPaths
+// ---------------------------------------------------------------------------
+
+func TestBatchOutputPaths(t *testing.T) {
+	t.Parallel()
+
+	t.Run("output dir takes precedence over config", func(t *testing.T) {
+		t.Parallel()
+
+		outputs, err := BatchOutputPaths([]string{"rec/a.ogg", "rec/b.mp3"}, "/out", config.Config{OutputDir: "/config"}, notes.NameFields{}, MarkdownFormat)
+		if err != nil {
+			t.Fatalf("BatchOutputPaths() unexpected error: %v", err)
+		}
+		want := []string{filepath.Join("/out", "a.md"), filepath.Join("/out", "b.md")}
+		if !slices.Equal(outputs, want) {
+			t.Errorf("BatchOutputPaths() = %v, want %v", outputs, want)
+		}
+	})
+
+	t.Run("config output dir used by default", func(t *testing.T) {
+		t.Parallel()
+
+		outputs, err := BatchOutputPaths([]string{"rec/a.ogg"}, "", config.Config{OutputDir: "/config"}, notes.NameFields{}, MarkdownFormat)
+		if err != nil {
+			t.Fatalf("BatchOutputPaths() unexpected error: %v", err)
+		}
+		if want := filepath.Join("/config", "a.md"); outputs[0] != want {
+			t.Errorf("BatchOutputPaths() = %v, want [%s]", outputs, want)
+		}
+	})
+
+	t.Run("paths are absolute", func(t *testing.T) {
+		t.Parallel()
+
+		outputs, err := BatchOutputPaths([]string{"rec/a.ogg"}, "", config.Config{}, notes.NameFields{}, MarkdownFormat)
+		if err != nil {
+			t.Fatalf("BatchOutputPaths() unexpected error: %v", err)
+		}
+		if !filepath.IsAbs(outputs[0]) {
+			t.Errorf("BatchOutputPaths() = %v, want absolute path", outputs)
+		}
+	})
+
+	t.Run("same output name is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := BatchOutputPaths([]string{"x/talk.ogg", "y/talk.mp3"}, "/out", config.Config{}, notes.NameFields{}, MarkdownFormat)
+		if err == nil || !strings.Contains(err.Error(), "would both be written") {
+			t.Errorf("BatchOutputPaths() error = %v, want collision error", err)
+		}
+	})
+
+	t.Run("named by the output-name pattern", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.Config{OutputName: "{{date}}_{{template}}_{{input_stem}}.md"}
+		fields := notes.NameFields{Date: time.Date(2026, 10, 17, 14, 5, 0, 0, time.Local), Template: "meeting"}
+		outputs, err := BatchOutputPaths([]string{"rec/a.ogg", "rec/b.mp3"}, "/out", cfg, fields, TextFormat)
+		if err != nil {
+			t.Fatalf("BatchOutputPaths() unexpected error: %v", err)
+		}
+		want := []string{filepath.Join("/out", "2026-10-17_meeting_a.txt"), filepath.Join("/out", "2026-10-17_meeting_b.txt")}
+		if !slices.Equal(outputs, want) {
+			t.Errorf("BatchOutputPaths() = %v, want %v", outputs, want)
+		}
+	})
+
+	t.Run("pattern without the input stem collides", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.Config{OutputName: "{{date}}.md"}
+		_, err := BatchOutputPaths([]string{"rec/a.ogg", "rec/b.mp3"}, "/out", cfg, notes.NameFields{}, MarkdownFormat)
+		if err == nil || !strings.Contains(err.Error(), "would both be written") {
+			t.Errorf("BatchOutputPaths() error = %v, want collision error", err)
+		}
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.Config{OutputName: "{{author}}.md"}
+		_, err := BatchOutputPaths([]string{"rec/a.ogg"}, "/out", cfg, notes.NameFields{}, MarkdownFormat)
+		if !errors.Is(err, notes.ErrInvalidName) {
+			t.Errorf("BatchOutputPaths() error = %v, want ErrInvalidName", err)
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// Tests for linePrefixWriter
+// ---------------------------------------------------------------------------
+
+func TestLinePrefixWriter(t *testing.T) {
+	t.Parallel()
+
+	var out syncBuffer
+	w := &LinePrefixWriter{mu: &sync.Mutex{}, w: &out, prefix: "[a] "}
+
+	_, _ = w.Write([]byte("one\ntw"))
+	_, _ = w.Write([]byte("o\nthree"))
+	if got, want := out.String(), "[a] one\n[a] two\n"; got != want {
+		t.Errorf("output before Flush = %q, want %q", got, want)
+	}
+
+	w.Flush()
+	if got, want := out.String(), "[a] one\n[a] two\n[a] three\n"; got != want {
+		t.Errorf("output after Flush = %q, want %q", got, want)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests for runTranscribeBatch
+// ---------------------------------------------------------------------------
+
+// batchTestEnv returns an Env whose chunker yields one chunk per file (named
+// after the input) and whose transcriber is transcribeFunc.
+func batchTestEnv(stderr *syncBuffer, transcribeFunc func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error)) *Env {
+	return &Env{
+		Stderr:             stderr,
+		Getenv:             defaultTestEnv,
+		Now:                fixedTime(time.Now()),
+		FFmpegResolver:     &mockFFmpegResolver{},
+		ConfigLoader:       &mockConfigLoader{},
+		MediaProberFactory: &mockMediaProberFactory{},
+		ChunkerFactory: &mockChunkerFactory{
+			NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
+				return &mockChunker{
+					ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+						return []audio.Chunk{{Path: audioPath + ".chunk", Index: 0}}, nil
+					},
+				}, nil
+			},
+		},
+		TranscriberFactory: &mockTranscriberFactory{
+			NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+				return &mockTranscriber{TranscribeFunc: transcribeFunc}
+			},
+		},
+	}
+}
+
+func TestRunTranscribeBatch_Success(t *testing.T) {
+	t.Parallel()
+
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	writeAudioFiles(t, inputDir, "a.ogg", "b.mp3", "sub/c.wav")
+
+	stderr := &syncBuffer{}
+	env := batchTestEnv(stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "text of " + filepath.Base(strings.TrimSuffix(audioPath, ".chunk")), nil
+	})
+
+	opts := mustParseTranscribeOptions(t, "", outputDir, "", false, 2, "", "", "deepseek")
+	err := RunTranscribeBatch(createTranscribeCmd(context.Background()), env, BatchOptions{
+		inputs:    []string{inputDir},
+		recursive: true,
+		jobs:      2,
+		file:      opts,
+	})
+	if err != nil {
+		t.Fatalf("RunTranscribeBatch() unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"a", "b", "c"} {
+		content, err := os.ReadFile(filepath.Join(outputDir, name+".md"))
+		if err != nil {
+			t.Fatalf("output for %s not written: %v", name, err)
+		}
+		if !strings.HasPrefix(string(content), "text of "+name) {
+			t.Errorf("%s.md = %q, want transcript of %s", name, content, name)
+		}
+	}
+
+	output := stderr.String()
+	for _, want := range []string{"Transcribing 3 files (2 at a time)", "[a.ogg] Transcribing...", "[3/3] Done:", "3 succeeded, 0 failed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("stderr = %q, want containing %q", output, want)
+		}
+	}
+}
+
+func TestRunTranscribeBatch_PartialFailure(t *testing.T) {
+	t.Parallel()
+
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	writeAudioFiles(t, inputDir, "bad.ogg", "good.ogg")
+
+	stderr := &syncBuffer{}
+	env := batchTestEnv(stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		if strings.Contains(audioPath, "bad.ogg") {
+			return "", apierr.ErrQuotaExceeded
+		}
+		return "good text", nil
+	})
+
+	opts := mustParseTranscribeOptions(t, "", outputDir, "", false, 1, "", "", "deepseek")
+	err := RunTranscribeBatch(createTranscribeCmd(context.Background()), env, BatchOptions{
+		inputs: []string{inputDir},
+		jobs:   1,
+		file:   opts,
+	})
+
+	if err == nil {
+		t.Fatal("RunTranscribeBatch() expected error when a file fails")
+	}
+	if !errors.Is(err, apierr.ErrQuotaExceeded) {
+		t.Errorf("RunTranscribeBatch() error = %v, want wrapping ErrQuotaExceeded (for exit code)", err)
+	}
+	if !strings.Contains(err.Error(), "1 of 2 files failed") {
+		t.Errorf("RunTranscribeBatch() error = %q, want containing %q", err.Error(), "1 of 2 files failed")
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "good.md")); err != nil {
+		t.Errorf("good.md not written despite other file failing: %v", err)
+	}
+	output := stderr.String()
+	if !strings.Contains(output, "1 succeeded, 1 failed") {
+		t.Errorf("stderr = %q, want containing %q", output, "1 succeeded, 1 failed")
+	}
+}
+
+func TestRunTranscribeBatch_FailFast(t *testing.T) {
+	t.Parallel()
+
+	inputDir := t.TempDir()
+	writeAudioFiles(t, inputDir, "a.ogg")
+
+	t.Run("missing API key", func(t *testing.T) {
+		t.Parallel()
+
+		env := batchTestEnv(&syncBuffer{}, nil)
+		env.Getenv = staticEnv(map[string]string{})
+
+		opts := mustParseTranscribeOptions(t, "", "", "", false, 1, "", "", "deepseek")
+		err := RunTranscribeBatch(createTranscribeCmd(context.Background()), env, BatchOptions{
+			inputs: []string{inputDir},
+			jobs:   1,
+			file:   opts,
+		})
+		if !errors.Is(err, ErrAPIKeyMissing) {
+			t.Errorf("RunTranscribeBatch() error = %v, want ErrAPIKeyMissing", err)
+		}
+	})
+
+	t.Run("output must be a directory", func(t *testing.T) {
+		t.Parallel()
+
+		env := batchTestEnv(&syncBuffer{}, nil)
+		opts := mustParseTranscribeOptions(t, "", filepath.Join(t.TempDir(), "notes.md"), "", false, 1, "", "", "deepseek")
+		err := RunTranscribeBatch(createTranscribeCmd(context.Background()), env, BatchOptions{
+			inputs: []string{inputDir},
+			jobs:   1,
+			file:   opts,
+		})
+		if err == nil || !strings.Contains(err.Error(), "must be a directory") {
+			t.Errorf("RunTranscribeBatch() error = %v, want directory error", err)
+		}
+	})
+}
+
+func TestRunTranscribeBatch_SessionDir(t *testing.T) {
+	t.Parallel()
+
+	inputDir := t.TempDir()
+	sessionDir := t.TempDir()
+	writeAudioFiles(t, inputDir, "a.ogg", "b.mp3")
+
+	env := batchTestEnv(&syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "text", nil
+	})
+
+	opts := mustParseTranscribeOptions(t, "", "", "", false, 1, "", "", "deepseek")
+	opts.sessionDir = sessionDir
+	err := RunTranscribeBatch(createTranscribeCmd(context.Background()), env, BatchOptions{
+		inputs: []string{inputDir},
+		jobs:   2,
+		file:   opts,
+	})
+	if err != nil {
+		t.Fatalf("RunTranscribeBatch() unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"a", "b"} {
+		matches, _ := filepath.Glob(filepath.Join(sessionDir, name+"_*", "transcript.md"))
+		if len(matches) != 1 {
+			t.Errorf("session transcripts for %s = %v, want one", name, matches)
+		}
+	}
+}
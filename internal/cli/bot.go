@@ -0,0 +1,418 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/jobs"
+	"github.com/alnah/go-transcript/internal/telegram"
+)
+
+// Bot configuration.
+const (
+	// botQueueCapacity is the number of recordings that can wait for a worker.
+	botQueueCapacity = 20
+	// botRetryDelay is the wait before polling again after a network error.
+	botRetryDelay = 5 * time.Second
+	// botAllowAll in --allow lets anyone use the bot.
+	botAllowAll = "*"
+)
+
+// Replies sent to Telegram users.
+const (
+	botHelpReply      = "Send me a voice note or an audio file, and I'll reply with its transcript."
+	botDeniedReply    = "Sorry, this bot only transcribes for its owner."
+	botBusyReply      = "I'm busy with other recordings. Please send it again in a few minutes."
+	botAcceptedReply  = "Got it, transcribing..."
+	botNoSpeechReply  = "No speech found in this recording."
+	botTooLargeFormat = "This file is too large: bots can download files up to %d MB. Send a shorter or compressed recording."
+)
+
+// botOptions configures the bot command.
+type botOptions struct {
+	file  transcribeOptions // Pipeline options of every recording (without input and output)
+	allow []string          // Telegram user IDs and lowercase usernames allowed, or botAllowAll
+	jobs  int               // Recordings transcribed concurrently
+}
+
+// BotCmd creates the bot command.
+// Answers voice notes and audio files sent to a Telegram bot with their transcript.
+func BotCmd(env *Env) *cobra.Command {
+	var (
+		tmpl       string
+		diarize    bool
+		parallel   string
+		language   string
+		outputLang string
+		provider   string
+		model      string
+		backend    string
+		allow      []string
+		jobs       int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bot",
+		Short: "Transcribe voice notes sent to a Telegram bot",
+		Long: `Run a Telegram bot that transcribes the voice notes and audio files sent to it.
+
+Create a bot with @BotFather on Telegram and set its token in TELEGRAM_BOT_TOKEN.
+The bot polls Telegram for direct messages (no public address is needed), runs
+each recording through the same pipeline as transcribe, and replies with the
+transcript, or with the notes when --template is set. Replies longer than a
+Telegram message are sent as a Markdown file.
+
+Anyone can find a bot and message it, and every recording uses your API keys:
+--allow lists the Telegram users the bot answers, by numeric ID or @username
+(use * to answer everyone). Other users get a short refusal.
+
+Recordings are queued and transcribed --jobs at a time. Bots can only download
+files up to 20 MB (about 20 minutes of voice notes). Press Ctrl+C to stop the bot;
+recordings being transcribed are canceled.`,
+		Example: `  transcript bot --allow @ada
+  transcript bot --allow 123456789 -t notes
+  transcript bot --allow @ada,@grace -t meeting --provider openai -l fr`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, auto, err := parseParallelFlag(cmd, parallel)
+			if err != nil {
+				return err
+			}
+			opts, err := parseTranscribeOptions("", "", tmpl, diarize, n, language, outputLang, provider, userTemplatesDir(env))
+			if err != nil {
+				return err
+			}
+			// No --var here: a template with placeholders cannot be filled.
+			if opts.template, err = fillTemplate(env.Stderr, opts.template, nil, ""); err != nil {
+				return err
+			}
+			opts.auto = auto
+			opts.model = model
+			if backend != "" {
+				if opts.backend, err = ParseBackend(backend); err != nil {
+					return err
+				}
+			}
+			return runBot(cmd, env, botOptions{file: opts, allow: parseAllowedUsers(allow), jobs: jobs})
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&allow, "allow", nil, "Telegram users the bot answers: numeric IDs or @usernames, or * for anyone (required)")
+	cmd.Flags().StringVarP(&tmpl, "template", "t", "", "Restructure template: brainstorm, meeting, lecture, notes, podcast, chapters, digest, or a user template")
+	cmd.Flags().BoolVar(&diarize, "diarize", false, "Enable speaker identification")
+	cmd.Flags().StringVarP(&parallel, "parallel", "p", defaultParallel, "Max concurrent API requests per recording (1-10, or auto; default: sized from the chunks)")
+	cmd.Flags().StringVarP(&language, "language", "l", "", "Audio language (ISO 639-1 code, e.g., en, fr, pt-BR)")
+	cmd.Flags().StringVarP(&outputLang, "translate", "T", "", "Translate output to language (ISO 639-1 code, requires --template)")
+	cmd.Flags().StringVar(&provider, "provider", ProviderDeepSeek, "LLM provider for restructuring: deepseek, openai, anthropic, ollama")
+	cmd.Flags().StringVar(&model, "restructure-model", "", "Model of the restructure provider (default: config or provider default)")
+	cmd.Flags().StringVar(&backend, "transcriber", "", transcriberFlagHelp)
+	cmd.Flags().StringVar(&backend, "stt-provider", "", sttProviderFlagHelp)
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", 1, "Max recordings transcribed concurrently")
+
+	return cmd
+}
+
+// parseAllowedUsers normalizes --allow values: usernames lose their @ and
+// are lowercased (Telegram usernames are case-insensitive).
+func parseAllowedUsers(values []string) []string {
+	allowed := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(v), "@"))
+		if v != "" {
+			allowed = append(allowed, v)
+		}
+	}
+	return allowed
+}
+
+// runBot answers the messages of a Telegram bot until the command context is canceled.
+func runBot(cmd *cobra.Command, env *Env, opts botOptions) error {
+	ctx := cmd.Context()
+
+	// === VALIDATION (fail-fast) ===
+
+	token := env.Getenv(EnvTelegramBotToken)
+	if token == "" {
+		return ErrTelegramTokenMissing
+	}
+	if len(opts.allow) == 0 {
+		return fmt.Errorf("--allow is required: list the Telegram users the bot answers (IDs or @usernames), or %s for anyone", botAllowAll)
+	}
+
+	cfg, err := env.ConfigLoader.Load()
+	if err != nil {
+		warnf(env.Stderr, warnConfigLoad, "failed to load config: %v", err)
+	}
+	opts.file.backend, err = resolveBackend(opts.file.backend, cfg)
+	if err != nil {
+		return err
+	}
+	if err := validateTranscribeRequirements(env, opts.file, cfg); err != nil {
+		return err
+	}
+
+	// === SETUP ===
+
+	ffmpegPath, err := resolveFFmpegOnce(ctx, env)
+	if err != nil {
+		return err
+	}
+
+	client, err := env.BotFactory.NewTelegramBot(token)
+	if err != nil {
+		return err
+	}
+
+	b := &bot{
+		env:    env,
+		client: client,
+		opts:   opts,
+		ffmpeg: ffmpegPath,
+		queue:  jobs.New(botQueueCapacity),
+	}
+
+	// Recordings in progress are canceled when polling stops.
+	queueCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		b.queue.Run(queueCtx, opts.jobs)
+	}()
+
+	b.logf("Bot started: send voice notes or audio files to it on Telegram (Ctrl+C to stop)\n")
+	err = b.poll(ctx)
+	cancel()
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+	b.logf("Bot stopped\n")
+	return nil
+}
+
+// bot answers Telegram messages with transcripts.
+type bot struct {
+	env    *Env
+	client telegram.Bot
+	opts   botOptions
+	ffmpeg string
+	queue  *jobs.Queue
+
+	mu sync.Mutex // Serializes writes to env.Stderr across jobs.
+}
+
+// poll receives messages until ctx is canceled. Network errors are retried;
+// a rejected token stops the bot.
+func (b *bot) poll(ctx context.Context) error {
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := b.client.Updates(ctx, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			if errors.Is(err, apierr.ErrAuthFailed) {
+				return fmt.Errorf("telegram rejected the bot token (check %s): %w", EnvTelegramBotToken, err)
+			}
+			b.warnf("%v (retrying in %s)", err, botRetryDelay)
+			select {
+			case <-ctx.Done():
+			case <-time.After(botRetryDelay):
+			}
+			continue
+		}
+		for _, u := range updates {
+			offset = u.ID + 1
+			if u.Message != nil {
+				b.handle(ctx, u.Message)
+			}
+		}
+	}
+	return nil
+}
+
+// handle answers a message: recordings are queued, anything else gets help.
+// Only direct messages from allowed users are answered.
+func (b *bot) handle(ctx context.Context, msg *telegram.Message) {
+	if msg.Chat.Type != "private" || msg.From == nil {
+		return
+	}
+	if !b.allowed(msg.From) {
+		b.logf("Ignored message from user %d (@%s): not in --allow\n", msg.From.ID, msg.From.Username)
+		b.reply(ctx, msg.Chat.ID, botDeniedReply)
+		return
+	}
+
+	file, ok := msg.Attachment()
+	if !ok {
+		b.reply(ctx, msg.Chat.ID, botHelpReply)
+		return
+	}
+	if file.FileSize > telegram.MaxDownloadSize {
+		b.reply(ctx, msg.Chat.ID, fmt.Sprintf(botTooLargeFormat, telegram.MaxDownloadSize/1024/1024))
+		return
+	}
+
+	chatID := msg.Chat.ID
+	_, err := b.queue.Submit(func(ctx context.Context) error {
+		return b.transcribe(ctx, chatID, file)
+	})
+	switch {
+	case errors.Is(err, jobs.ErrQueueFull):
+		b.reply(ctx, chatID, botBusyReply)
+	case err != nil:
+		return // Stopping
+	default:
+		b.reply(ctx, chatID, botAcceptedReply)
+	}
+}
+
+// allowed reports whether user is listed in --allow.
+func (b *bot) allowed(user *telegram.User) bool {
+	id := strconv.FormatInt(user.ID, 10)
+	name := strings.ToLower(user.Username)
+	for _, a := range b.opts.allow {
+		if a == botAllowAll || a == id || (name != "" && a == name) {
+			return true
+		}
+	}
+	return false
+}
+
+// transcribe runs the pipeline on a recording and replies to chatID with the
+// result, or with the error.
+func (b *bot) transcribe(ctx context.Context, chatID int64, file telegram.File) error {
+	stderr := &linePrefixWriter{mu: &b.mu, w: b.env.Stderr, prefix: fmt.Sprintf("[chat %d] ", chatID)}
+	defer stderr.Flush()
+
+	name, err := b.runPipeline(ctx, chatID, file, stderr)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintf(stderr, "Failed: %v\n", err)
+			b.reply(ctx, chatID, "Transcription failed: "+FormatError(err))
+		}
+		return err
+	}
+	fmt.Fprintf(stderr, "Done: %s\n", name)
+	return nil
+}
+
+// runPipeline downloads a recording into a temporary directory, transcribes
+// it like the transcribe command, and sends the output to chatID.
+// Returns the name of the recording.
+func (b *bot) runPipeline(ctx context.Context, chatID int64, file telegram.File, stderr io.Writer) (string, error) {
+	dir, err := os.MkdirTemp("", "transcript-bot-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	input, name, err := b.download(ctx, dir, file)
+	if err != nil {
+		return "", err
+	}
+
+	fileEnv := *b.env
+	fileEnv.Stderr = stderr
+	fileEnv.FFmpegResolver = resolvedFFmpeg(b.ffmpeg)
+
+	opts := b.opts.file
+	opts.inputPath = input
+	opts.output = filepath.Join(dir, "transcript"+opts.format.Extension())
+
+	// runTranscribe takes its context from the command.
+	fileCmd := &cobra.Command{}
+	fileCmd.SetContext(ctx)
+	fileCmd.SetOut(io.Discard)
+	if err := runTranscribe(fileCmd, &fileEnv, opts); err != nil {
+		return name, err
+	}
+
+	content, err := os.ReadFile(opts.output)
+	if err != nil {
+		return name, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return name, b.send(ctx, chatID, name+opts.format.Extension(), string(content))
+}
+
+// download saves a recording into dir. Returns its path and a name for the
+// reply (the original file name, without extension).
+func (b *bot) download(ctx context.Context, dir string, file telegram.File) (input, name string, err error) {
+	f, err := os.CreateTemp(dir, "audio-*")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create audio file: %w", err)
+	}
+	remotePath, err := b.client.Download(ctx, file.FileID, f)
+	if closeErr := f.Close(); closeErr != nil && err == nil {
+		err = fmt.Errorf("failed to write audio file: %w", closeErr)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	name = file.FileName
+	if name == "" {
+		name = path.Base(remotePath)
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	name = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	// Voice notes are Ogg Opus, named .oga by Telegram.
+	if ext == ".oga" || ext == ".opus" {
+		ext = ".ogg"
+	}
+
+	input = f.Name() + ext
+	if err := os.Rename(f.Name(), input); err != nil {
+		return "", "", fmt.Errorf("failed to rename audio file: %w", err)
+	}
+	return input, name, nil
+}
+
+// send replies with content: as a message if it fits, as a file otherwise.
+func (b *bot) send(ctx context.Context, chatID int64, fileName, content string) error {
+	content = strings.TrimSpace(content)
+	switch {
+	case content == "":
+		return b.client.SendMessage(ctx, chatID, botNoSpeechReply)
+	case utf8.RuneCountInString(content) <= telegram.MaxMessageLength:
+		return b.client.SendMessage(ctx, chatID, content)
+	default:
+		return b.client.SendDocument(ctx, chatID, fileName, []byte(content+"\n"), "Too long for a message: transcript attached.")
+	}
+}
+
+// reply sends a short message, logging failures (the bot keeps running).
+func (b *bot) reply(ctx context.Context, chatID int64, text string) {
+	if err := b.client.SendMessage(ctx, chatID, text); err != nil && ctx.Err() == nil {
+		b.warnf("failed to reply to chat %d: %v", chatID, err)
+	}
+}
+
+// logf writes a line to env.Stderr without interleaving with job output.
+func (b *bot) logf(format string, args ...any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fmt.Fprintf(b.env.Stderr, format, args...)
+}
+
+// warnf writes a Telegram delivery warning like logf.
+func (b *bot) warnf(format string, args ...any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	warnf(b.env.Stderr, warnBotDelivery, format, args...)
+}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/telegram"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// botTestEnv returns an Env whose transcriber returns transcript.
+func botTestEnv(bot *mockTelegramBot, transcript string) (*Env, *mockTranscriber) {
+	transcriber := &mockTranscriber{
+		TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return transcript, nil
+		},
+	}
+	chunker := &mockChunker{
+		ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+			chunkPath := filepath.Join(filepath.Dir(audioPath), "chunk_0.ogg")
+			if err := os.WriteFile(chunkPath, []byte("chunk audio"), 0o644); err != nil {
+				return nil, err
+			}
+			return []audio.Chunk{{Path: chunkPath, Index: 0, EndTime: time.Minute}}, nil
+		},
+	}
+	return &Env{
+		Stderr:             &syncBuffer{},
+		Getenv:             staticEnv(map[string]string{EnvOpenAIAPIKey: "sk-test", EnvTelegramBotToken: "123:token"}),
+		Now:                fixedTime(time.Now()),
+		FFmpegResolver:     &mockFFmpegResolver{},
+		ConfigLoader:       &mockConfigLoader{},
+		ChunkerFactory:     &mockChunkerFactory{NewSilenceChunkerFunc: func(string) (audio.Chunker, error) { return chunker, nil }},
+		MediaProberFactory: &mockMediaProberFactory{},
+		TranscriberFactory: &mockTranscriberFactory{
+			NewTranscriberFunc: func(string) transcribe.Transcriber { return transcriber },
+		},
+		BotFactory: &mockBotFactory{Bot: bot},
+	}, transcriber
+}
+
+// runBotUntil runs the bot until it has sent n messages, then stops it.
+func runBotUntil(t *testing.T, env *Env, bot *mockTelegramBot, opts botOptions, n int) []sentMessage {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd := &cobra.Command{}
+	cmd.SetContext(ctx)
+
+	done := make(chan error, 1)
+	go func() { done <- runBot(cmd, env, opts) }()
+
+	deadline := time.After(5 * time.Second)
+	for len(bot.Sent()) < n {
+		select {
+		case err := <-done:
+			t.Fatalf("runBot() returned early: %v", err)
+		case <-deadline:
+			t.Fatalf("bot sent %d messages, want %d: %+v", len(bot.Sent()), n, bot.Sent())
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("runBot() unexpected error: %v", err)
+	}
+	return bot.Sent()
+}
+
+// directMessage returns an update with a direct message from a user.
+func directMessage(id, userID int64, username string, msg telegram.Message) telegram.Update {
+	msg.ID = id
+	msg.From = &telegram.User{ID: userID, Username: username}
+	msg.Chat = telegram.Chat{ID: userID, Type: "private"}
+	return telegram.Update{ID: id, Message: &msg}
+}
+
+func TestRunBot_RepliesWithTranscript(t *testing.T) {
+	t.Parallel()
+
+	bot := &mockTelegramBot{
+		Batches: [][]telegram.Update{{
+			directMessage(1, 1, "ada", telegram.Message{Text: "/start"}),
+			directMessage(2, 2, "mallory", telegram.Message{Voice: &telegram.File{FileID: "v2"}}),
+			directMessage(3, 1, "ada", telegram.Message{Voice: &telegram.File{FileID: "v1", FileSize: 1000}}),
+		}},
+		FilePaths: map[string]string{"v1": "voice/file_1.oga"},
+	}
+	env, transcriber := botTestEnv(bot, "Hello from the voice note.")
+
+	opts := botOptions{file: mustParseTranscribeOptions(t, "", "", "", false, 5, "", "", "deepseek"), allow: parseAllowedUsers([]string{"@Ada"}), jobs: 1}
+	sent := runBotUntil(t, env, bot, opts, 4)
+
+	want := []sentMessage{
+		{chatID: 1, text: botHelpReply},
+		{chatID: 2, text: botDeniedReply},
+		{chatID: 1, text: botAcceptedReply},
+		{chatID: 1, text: "Hello from the voice note."},
+	}
+	if !slices.Equal(sent, want) {
+		t.Errorf("sent = %+v, want %+v", sent, want)
+	}
+
+	// The .oga voice note was accepted as .ogg and transcribed once
+	calls := transcriber.TranscribeCalls()
+	if len(calls) != 1 {
+		t.Fatalf("Transcribe() called %d times, want 1", len(calls))
+	}
+}
+
+func TestRunBot_LongTranscriptAsDocument(t *testing.T) {
+	t.Parallel()
+
+	bot := &mockTelegramBot{
+		Batches: [][]telegram.Update{{
+			directMessage(1, 1, "", telegram.Message{Audio: &telegram.File{FileID: "a1", FileName: "Talk.mp3"}}),
+		}},
+		FilePaths: map[string]string{"a1": "music/file_2.mp3"},
+	}
+	long := strings.Repeat("word ", telegram.MaxMessageLength)
+	env, _ := botTestEnv(bot, long)
+
+	opts := botOptions{file: mustParseTranscribeOptions(t, "", "", "", false, 5, "", "", "deepseek"), allow: []string{"1"}, jobs: 1}
+	sent := runBotUntil(t, env, bot, opts, 2)
+
+	doc := sent[1]
+	if doc.document != "Talk.md" || !strings.HasPrefix(doc.text, "word word") {
+		t.Errorf("reply = %q (%d bytes), want the transcript as Talk.md", doc.document, len(doc.text))
+	}
+}
+
+func TestRunBot_Errors(t *testing.T) {
+	t.Parallel()
+
+	fileOpts := func(t *testing.T) transcribeOptions {
+		return mustParseTranscribeOptions(t, "", "", "", false, 5, "", "", "deepseek")
+	}
+
+	t.Run("missing token", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := botTestEnv(&mockTelegramBot{}, "")
+		env.Getenv = staticEnv(map[string]string{EnvOpenAIAPIKey: "sk-test"})
+		err := runBot(createTranscribeCmd(context.Background()), env, botOptions{file: fileOpts(t), allow: []string{"*"}})
+		if !errors.Is(err, ErrTelegramTokenMissing) {
+			t.Errorf("runBot() error = %v, want ErrTelegramTokenMissing", err)
+		}
+	})
+
+	t.Run("missing allow list", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := botTestEnv(&mockTelegramBot{}, "")
+		err := runBot(createTranscribeCmd(context.Background()), env, botOptions{file: fileOpts(t)})
+		if err == nil || !strings.Contains(err.Error(), "--allow") {
+			t.Errorf("runBot() error = %v, want --allow required", err)
+		}
+	})
+
+	t.Run("rejected token", func(t *testing.T) {
+		t.Parallel()
+
+		bot := &mockTelegramBot{UpdatesErr: apierr.ErrAuthFailed}
+		env, _ := botTestEnv(bot, "")
+		err := runBot(createTranscribeCmd(context.Background()), env, botOptions{file: fileOpts(t), allow: []string{"*"}})
+		if !errors.Is(err, apierr.ErrAuthFailed) {
+			t.Errorf("runBot() error = %v, want ErrAuthFailed", err)
+		}
+	})
+}
+
+func TestParseAllowedUsers(t *testing.T) {
+	t.Parallel()
+
+	got := parseAllowedUsers([]string{"@Ada", " 12345 ", "", "*"})
+	want := []string{"ada", "12345", "*"}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseAllowedUsers() = %q, want %q", got, want)
+	}
+}
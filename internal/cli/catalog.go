@@ -0,0 +1,885 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/fetch"
+	"github.com/alnah/go-transcript/internal/ffmpeg"
+	"github.com/alnah/go-transcript/internal/integrations/obsidian"
+	"github.com/alnah/go-transcript/internal/keyring"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/notes"
+	"github.com/alnah/go-transcript/internal/provenance"
+	"github.com/alnah/go-transcript/internal/redact"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/schemas"
+	"github.com/alnah/go-transcript/internal/template"
+	"github.com/alnah/go-transcript/internal/tlsconfig"
+	"github.com/alnah/go-transcript/internal/transcribe"
+	"github.com/alnah/go-transcript/internal/trash"
+	"github.com/alnah/go-transcript/internal/vocab"
+)
+
+// ErrorInfo documents an error cause: a stable code, what it means, and how
+// to fix it. Codes never change meaning, so scripts and support requests can
+// rely on them.
+type ErrorInfo struct {
+	Code        string   // Stable identifier, e.g. "TR-0301"
+	Summary     string   // One-line description
+	Explanation string   // Why it happens
+	Remediation []string // Steps to fix it, most likely first
+	errs        []error  // Sentinels matched with errors.Is
+}
+
+// errorCatalog lists the documented error causes.
+// Codes are grouped by exit code: TR-03xx setup, TR-04xx validation,
+// TR-05xx API, TR-06xx restructure, TR-07xx partial output. Entries are matched in order, so an
+// error wrapping several sentinels gets the first (most specific) entry.
+var errorCatalog = []ErrorInfo{
+	// Setup (exit code 3).
+	{
+		Code:        "TR-0301",
+		Summary:     "FFmpeg not found",
+		Explanation: "FFmpeg records and converts audio. It was not found in --ffmpeg-path, ffmpeg-path, FFMPEG_PATH, PATH, or the auto-download cache.",
+		Remediation: []string{
+			"Run transcript ffmpeg upgrade to download it, then transcript ffmpeg status to check it",
+			"Or install FFmpeg with your package manager (brew install ffmpeg, apt install ffmpeg, choco install ffmpeg)",
+			"Or point FFMPEG_PATH, the ffmpeg-path setting or --ffmpeg-path at an existing ffmpeg binary",
+		},
+		errs: []error{ffmpeg.ErrNotFound},
+	},
+	{
+		Code:        "TR-0302",
+		Summary:     "FFmpeg auto-download not available on this platform",
+		Explanation: "FFmpeg is downloaded automatically only on the platforms with a published build. This OS or architecture has none.",
+		Remediation: []string{
+			"Install FFmpeg manually and make sure it is in PATH",
+			"Or set FFMPEG_PATH to the ffmpeg binary",
+		},
+		errs: []error{ffmpeg.ErrUnsupportedPlatform},
+	},
+	{
+		Code:        "TR-0303",
+		Summary:     "FFmpeg download failed verification",
+		Explanation: "The downloaded FFmpeg archive does not match its expected checksum. The download was corrupted or tampered with, and was discarded.",
+		Remediation: []string{
+			"Retry: transient network errors can truncate the download",
+			"If it persists, install FFmpeg manually or set FFMPEG_PATH",
+		},
+		errs: []error{ffmpeg.ErrChecksumMismatch},
+	},
+	{
+		Code:        "TR-0304",
+		Summary:     "FFmpeg download failed",
+		Explanation: "FFmpeg could not be downloaded, usually because of the network or a proxy.",
+		Remediation: []string{
+			"Check your internet connection, then retry with transcript ffmpeg upgrade",
+			"Behind a proxy, set HTTPS_PROXY (e.g. http://proxy:3128), and ca-bundle if it intercepts TLS",
+			"Or install FFmpeg manually and set ffmpeg-path",
+		},
+		errs: []error{ffmpeg.ErrDownloadFailed},
+	},
+	{
+		Code:        "TR-0310",
+		Summary:     "OpenAI API key missing",
+		Explanation: "Transcription with OpenAI (the default backend) and restructuring with --provider openai need an API key.",
+		Remediation: []string{
+			"Set OPENAI_API_KEY in your environment or in a .env file",
+			"Or transcribe offline with --transcriber local",
+		},
+		errs: []error{ErrAPIKeyMissing},
+	},
+	{
+		Code:        "TR-0311",
+		Summary:     "DeepSeek API key missing",
+		Explanation: "Restructuring with --template uses DeepSeek by default, which needs an API key.",
+		Remediation: []string{
+			"Set DEEPSEEK_API_KEY in your environment or in a .env file",
+			"Or choose another provider with --provider openai, anthropic or ollama",
+		},
+		errs: []error{ErrDeepSeekKeyMissing},
+	},
+	{
+		Code:        "TR-0312",
+		Summary:     "Anthropic API key missing",
+		Explanation: "Restructuring with --provider anthropic needs an API key.",
+		Remediation: []string{
+			"Set ANTHROPIC_API_KEY in your environment or in a .env file",
+			"Or choose another provider with --provider",
+		},
+		errs: []error{ErrAnthropicKeyMissing},
+	},
+	{
+		Code:        "TR-0313",
+		Summary:     "Unsupported restructuring provider",
+		Explanation: "The restructuring provider is not one this build supports. This is a bug if it happens with a valid --provider value.",
+		Remediation: []string{
+			"Use --provider deepseek, openai, anthropic or ollama",
+			"If the value was valid, report the issue with the full command",
+		},
+		errs: []error{ErrUnsupportedProvider},
+	},
+	{
+		Code:        "TR-0314",
+		Summary:     "Ollama server not reachable",
+		Explanation: "Restructuring with --provider ollama sends requests to a local Ollama server, which did not accept the connection.",
+		Remediation: []string{
+			"Start the server: ollama serve",
+			"Check the ollama-url config key (default: http://localhost:11434)",
+		},
+		errs: []error{restructure.ErrOllamaUnreachable},
+	},
+	{
+		Code:        "TR-0315",
+		Summary:     "Telegram bot token missing",
+		Explanation: "The bot command connects to Telegram as the bot whose token is set in TELEGRAM_BOT_TOKEN.",
+		Remediation: []string{
+			"Create a bot with @BotFather on Telegram and copy its token",
+			"Set TELEGRAM_BOT_TOKEN in your environment or in a .env file",
+		},
+		errs: []error{ErrTelegramTokenMissing},
+	},
+	{
+		Code:        "TR-0316",
+		Summary:     "gRPC endpoint missing",
+		Explanation: "The grpc transcriber streams the audio to an ASR server implementing docs/asr.proto, whose address is not configured.",
+		Remediation: []string{
+			"Pass the server address with --grpc-endpoint host:port",
+			"Or save it with: transcript config set grpc-endpoint host:port",
+		},
+		errs: []error{ErrGRPCEndpointMissing},
+	},
+	{
+		Code:        "TR-0317",
+		Summary:     "Configuration checks failed",
+		Explanation: "config validate or config doctor found a problem that will make transcript fail: an invalid setting, a rejected API key, an unwritable output directory...",
+		Remediation: []string{
+			"Follow the hint printed under each failed check",
+			"Fix a setting with: transcript config set <key> <value>",
+			"Run transcript config doctor again to confirm",
+		},
+		errs: []error{ErrChecksFailed},
+	},
+	{
+		Code:        "TR-0318",
+		Summary:     "Keyring unavailable",
+		Explanation: "config set-key stores API keys in the keyring of the OS, which cannot be used here: secret-tool is not installed, or no Secret Service runs (headless Linux sessions), or the Keychain refused access.",
+		Remediation: []string{
+			"Export the key in your environment or put it in a .env file instead",
+			"On Linux, install secret-tool (libsecret-tools) and run a keyring such as GNOME Keyring",
+		},
+		errs: []error{keyring.ErrUnavailable},
+	},
+	{
+		Code:        "TR-0319",
+		Summary:     "Azure OpenAI deployment missing",
+		Explanation: "azure-endpoint sends OpenAI transcription and restructuring to an Azure OpenAI resource, which calls models by the name of their deployment. The deployment of this request is not configured.",
+		Remediation: []string{
+			"Save the transcription deployment with: transcript config set azure-transcribe-deployment <name>",
+			"Save the restructuring deployment with: transcript config set azure-chat-deployment <name>",
+			"Or unset azure-endpoint to call OpenAI",
+		},
+		errs: []error{ErrAzureDeploymentMissing},
+	},
+	{
+		Code:        "TR-0320",
+		Summary:     "No audio input device",
+		Explanation: "Recording needs a microphone, and FFmpeg found none.",
+		Remediation: []string{
+			"Connect a microphone and check it is enabled in your system settings",
+			"Grant microphone access to your terminal (macOS: System Settings > Privacy & Security > Microphone)",
+			"List the devices FFmpeg sees with: transcript devices",
+		},
+		errs: []error{audio.ErrNoAudioDevice},
+	},
+	{
+		Code:        "TR-0321",
+		Summary:     "Loopback device not found",
+		Explanation: "System audio capture (--system-record, --mix) needs a loopback device: BlackHole on macOS, a PulseAudio/PipeWire monitor on Linux, Stereo Mix or VB-Cable on Windows.",
+		Remediation: []string{
+			"Install the virtual audio driver for your OS (see Troubleshooting in the README)",
+			"Or record the microphone only, without --system-record or --mix",
+		},
+		errs: []error{audio.ErrLoopbackNotFound},
+	},
+	{
+		Code:        "TR-0330",
+		Summary:     "whisper.cpp binary not found",
+		Explanation: "Local transcription (--transcriber local) runs whisper.cpp, which was not found in whisper-bin or PATH.",
+		Remediation: []string{
+			"Install whisper.cpp (brew install whisper-cpp, or build it from source)",
+			"Or set the whisper-bin config key to the binary",
+			"Or run a local whisper server and set whisper-url",
+		},
+		errs: []error{transcribe.ErrWhisperNotFound},
+	},
+	{
+		Code:        "TR-0331",
+		Summary:     "whisper model not found",
+		Explanation: "Local transcription needs a ggml model file, set with the whisper-model config key.",
+		Remediation: []string{
+			"Download a model, e.g. ggml-base.en.bin from the whisper.cpp repository",
+			"Set it with: transcript config set whisper-model <path>",
+		},
+		errs: []error{transcribe.ErrModelNotFound},
+	},
+	{
+		Code:        "TR-0332",
+		Summary:     "Transcription provider API key missing",
+		Explanation: "Transcription with groq, deepgram or assemblyai (--transcriber) needs the API key of the provider: GROQ_API_KEY, DEEPGRAM_API_KEY or ASSEMBLYAI_API_KEY.",
+		Remediation: []string{
+			"Set the variable named in the error in your environment or in a .env file",
+			"Or choose another backend with --transcriber",
+		},
+		errs: []error{ErrTranscriberKeyMissing},
+	},
+	{
+		Code:        "TR-0340",
+		Summary:     "Invalid TLS configuration",
+		Explanation: "The CA bundle or client certificate set with ca-bundle, client-cert and client-key cannot be read or parsed.",
+		Remediation: []string{
+			"Check the files exist and hold PEM data (-----BEGIN CERTIFICATE-----)",
+			"client-cert and client-key must be set together, and the key must match the certificate",
+		},
+		errs: []error{tlsconfig.ErrInvalid},
+	},
+	{
+		Code:        "TR-0341",
+		Summary:     "Server certificate not trusted",
+		Explanation: "The HTTPS certificate of the server failed verification. On corporate networks, a proxy usually intercepts TLS and re-signs traffic with its own certificate authority.",
+		Remediation: []string{
+			"Ask your IT department for the proxy CA certificate (PEM), then: transcript config set ca-bundle <file>",
+			"Or set TRANSCRIPT_CA_BUNDLE for a single run",
+		},
+		errs: []error{tlsconfig.ErrUntrusted},
+	},
+	{
+		Code:        "TR-0350",
+		Summary:     "Invalid signing key",
+		Explanation: "--sign-key signs outputs with a minisign secret key. The key cannot be read, is not a minisign secret key, or is password-protected: outputs are signed without asking for a password.",
+		Remediation: []string{
+			"Create a key without password for transcripts: minisign -G -W -s transcript.key -p transcript.pub",
+			"Check --sign-key points at the secret key, not the .pub public key",
+		},
+		errs: []error{provenance.ErrInvalidKey},
+	},
+	{
+		Code:        "TR-0351",
+		Summary:     "No interactive terminal",
+		Explanation: "live --tui reads key presses and redraws the screen: stdin and stderr must be a terminal, where stty can switch to reading single keys (macOS, Linux).",
+		Remediation: []string{
+			"Run the command in a terminal, without redirecting stdin or stderr",
+			"Or drop --tui and use --stream, which prints the transcript as it goes",
+		},
+		errs: []error{ErrNoTerminal},
+	},
+
+	// Validation (exit code 4).
+	{
+		Code:        "TR-0401",
+		Summary:     "Invalid duration",
+		Explanation: "Durations use Go syntax: a number followed by a unit, like 30s, 45m, 2h or 1h30m. They must be positive.",
+		Remediation: []string{"Pass a duration like -d 1h30m"},
+		errs:        []error{ErrInvalidDuration},
+	},
+	{
+		Code:        "TR-0402",
+		Summary:     "Unsupported audio format",
+		Explanation: "Uploads to 'transcript serve' are recognized by file extension, and only some formats are accepted. Files given to transcribe by name with another extension are rejected when their content is not media (a text, PDF or image file), before the API key is read; other files are checked by FFmpeg.",
+		Remediation: []string{
+			"Use .ogg, .mp3, .wav, .m4a, .flac, .mp4, .mpeg, .mpga, .webm, .mkv or .mov",
+			"Or convert the file first: ffmpeg -i input.xyz output.ogg",
+		},
+		errs: []error{ErrUnsupportedFormat},
+	},
+	{
+		Code:        "TR-0403",
+		Summary:     "Input file not found",
+		Explanation: "The file given on the command line does not exist or is not accessible.",
+		Remediation: []string{"Check the path and its permissions"},
+		errs:        []error{ErrFileNotFound, audio.ErrFileNotFound},
+	},
+	{
+		Code:        "TR-0404",
+		Summary:     "Output file already exists",
+		Explanation: "Outputs are never overwritten implicitly, so an earlier transcript cannot be lost by mistake.",
+		Remediation: []string{
+			"Choose another output path with -o, or move the existing file away",
+			"Or, with transcribe, live and structure: --force replaces it (transcript undo restores it), --append adds to it",
+		},
+		errs: []error{ErrOutputExists},
+	},
+	{
+		Code:        "TR-0405",
+		Summary:     "Invalid language code",
+		Explanation: "Languages are ISO 639-1 codes (en, fr, pt), optionally with a region (pt-BR).",
+		Remediation: []string{"Pass a code like -l fr or -T en"},
+		errs:        []error{lang.ErrInvalid},
+	},
+	{
+		Code:        "TR-0406",
+		Summary:     "Invalid parallel value",
+		Explanation: "--parallel takes a number of concurrent requests, or auto to size it from the measured upload speed.",
+		Remediation: []string{"Pass -p 4 or -p auto"},
+		errs:        []error{ErrInvalidParallel},
+	},
+	{
+		Code:        "TR-0407",
+		Summary:     "Invalid transcriber",
+		Explanation: "The transcription backend is openai, groq, deepgram or assemblyai (cloud), local (whisper.cpp) or grpc (an in-house ASR server).",
+		Remediation: []string{
+			"Pass --transcriber openai, local, grpc, groq, deepgram or assemblyai",
+			"Check the transcriber config key and TRANSCRIPT_TRANSCRIBER",
+		},
+		errs: []error{ErrInvalidBackend},
+	},
+	{
+		Code:        "TR-0408",
+		Summary:     "Invalid output format",
+		Explanation: "The output format is md, txt, srt or vtt.",
+		Remediation: []string{"Pass --format md, txt, srt or vtt"},
+		errs:        []error{ErrInvalidOutputFormat},
+	},
+	{
+		Code:        "TR-0409",
+		Summary:     "Invalid intro-outro mode",
+		Explanation: "--intro-outro tells what to do with intros and outros repeated from earlier recordings: skip them or mark them.",
+		Remediation: []string{"Pass --intro-outro skip or --intro-outro mark"},
+		errs:        []error{ErrInvalidIntroOutro},
+	},
+	{
+		Code:        "TR-0410",
+		Summary:     "Invalid provider",
+		Explanation: "The restructuring provider is deepseek, openai, anthropic or ollama.",
+		Remediation: []string{"Pass --provider deepseek, openai, anthropic or ollama"},
+		errs:        []error{ErrInvalidProvider},
+	},
+	{
+		Code:        "TR-0411",
+		Summary:     "Invalid tag",
+		Explanation: "Tags name the vocabulary file of a series of sessions, so they are restricted to characters safe in file names.",
+		Remediation: []string{"Use lowercase letters, digits, '.', '_' and '-', like --tag project-apollo"},
+		errs:        []error{vocab.ErrInvalidTag},
+	},
+	{
+		Code:        "TR-0420",
+		Summary:     "Invalid user template",
+		Explanation: "A user template file could not be parsed: unclosed or malformed front-matter, unknown front-matter key, invalid or built-in name, or empty prompt.",
+		Remediation: []string{
+			"Fix the file named in the error message",
+			"List the valid templates with: transcript templates list",
+		},
+		errs: []error{template.ErrInvalid},
+	},
+	{
+		Code:        "TR-0421",
+		Summary:     "Unknown template",
+		Explanation: "--template takes a built-in template name, the path of a template file, or the name of a template in the templates directory.",
+		Remediation: []string{
+			"List the available templates with: transcript templates list",
+			"Pass a file path containing '/' or ending in .md",
+		},
+		errs: []error{template.ErrUnknown},
+	},
+	{
+		Code:        "TR-0422",
+		Summary:     "Unknown schema",
+		Explanation: "The schema command prints the schemas of the machine-readable outputs, by name.",
+		Remediation: []string{"Run transcript schema --help for the schema names"},
+		errs:        []error{schemas.ErrUnknown},
+	},
+	{
+		Code:        "TR-0423",
+		Summary:     "Audio chunking failed",
+		Explanation: "Long recordings are split at silences into chunks under the file size limit of the transcription API (25MB for OpenAI). FFmpeg failed to analyze or split the file, which is often corrupted or not really audio.",
+		Remediation: []string{
+			"Check the file plays in a media player",
+			"Re-encode it: ffmpeg -i input output.ogg",
+		},
+		errs: []error{audio.ErrChunkingFailed},
+	},
+	{
+		Code:        "TR-0424",
+		Summary:     "Audio chunk too large",
+		Explanation: "A chunk is still above the file size limit of the transcription API after splitting, usually because the audio has no silence to split at or a very high bitrate.",
+		Remediation: []string{
+			"Re-encode at a lower bitrate: ffmpeg -i input -b:a 64k output.ogg",
+			"Or configure whisper-model (or whisper-url): oversized chunks are then sent to the local backend automatically",
+			"Or transcribe offline with --transcriber local, which has no size limit",
+		},
+		errs: []error{audio.ErrChunkTooLarge},
+	},
+	{
+		Code:        "TR-0425",
+		Summary:     "Diarization not supported",
+		Explanation: "Speaker identification (--diarize) needs the OpenAI backend. The local backend cannot tell speakers apart.",
+		Remediation: []string{"Drop --diarize, or use --transcriber openai"},
+		errs:        []error{transcribe.ErrDiarizeUnsupported},
+	},
+	{
+		Code:        "TR-0426",
+		Summary:     "Nothing to undo",
+		Explanation: "undo restores the last output replaced in a directory, from its .trash directory. That trash is empty or missing.",
+		Remediation: []string{"Pass the output directory the file was replaced in: transcript undo <dir>"},
+		errs:        []error{trash.ErrEmpty},
+	},
+	{
+		Code:        "TR-0427",
+		Summary:     "Unknown error code",
+		Explanation: "explain documents the codes printed with error messages, like TR-0301.",
+		Remediation: []string{"List all codes with: transcript explain"},
+		errs:        []error{ErrUnknownErrorCode},
+	},
+	{
+		Code:        "TR-0428",
+		Summary:     "Estimated cost above limit",
+		Explanation: "--self-consistency restructures the transcript several times and merges the results, multiplying the cost. The run is refused when its estimate exceeds --max-cost.",
+		Remediation: []string{
+			"Check the estimate with: transcript transcribe <file> -t <template> --self-consistency <n> --dry-run",
+			"Lower --self-consistency, or raise --max-cost",
+		},
+		errs: []error{ErrCostLimit},
+	},
+	{
+		Code:        "TR-0429",
+		Summary:     "Invalid progress format",
+		Explanation: "--progress selects how progress is reported on stderr: text for people, or json for programs wrapping transcript (one JSON object per line).",
+		Remediation: []string{"Pass --progress text or --progress json"},
+		errs:        []error{ErrInvalidProgress},
+	},
+	{
+		Code:        "TR-0430",
+		Summary:     "Invalid configuration",
+		Explanation: "The config file (~/.config/go-transcript/config) has a malformed line, or a setting has a value that cannot be parsed.",
+		Remediation: []string{
+			"Check the config with: transcript config list",
+			"Fix the setting with: transcript config set <key> <value>",
+		},
+		errs: []error{config.ErrInvalidSyntax, config.ErrInvalidKey, config.ErrInvalidValue},
+	},
+	{
+		Code:        "TR-0431",
+		Summary:     "Output directory not usable",
+		Explanation: "The output directory is not a directory, or cannot be written to.",
+		Remediation: []string{
+			"Check the path and its permissions",
+			"Or choose another directory: transcript config set output-dir <dir>",
+		},
+		errs: []error{config.ErrNotWritable, config.ErrNotDirectory},
+	},
+	{
+		Code:        "TR-0432",
+		Summary:     "Unknown chunking strategy",
+		Explanation: "--chunker selects how audio is split into chunks: silence cuts at pauses, time cuts fixed-duration chunks, size cuts fixed-size chunks.",
+		Remediation: []string{"Pass --chunker silence, time or size"},
+		errs:        []error{audio.ErrUnknownChunker},
+	},
+	{
+		Code:        "TR-0433",
+		Summary:     "Invalid chunk size",
+		Explanation: "--chunk-size is a whole number of bytes with an optional unit (KB, MB, GB), of at least 64KB.",
+		Remediation: []string{"Pass a size such as --chunk-size 10MB"},
+		errs:        []error{audio.ErrInvalidChunkSize},
+	},
+	{
+		Code:        "TR-0434",
+		Summary:     "No audio track",
+		Explanation: "Files are transcribed from their first audio track. This file has none, for instance a screen recording made without sound, or a video renamed with an audio extension.",
+		Remediation: []string{"Check the file has sound in a media player, or record again with audio"},
+		errs:        []error{audio.ErrNoAudioTrack},
+	},
+	{
+		Code:        "TR-0435",
+		Summary:     "Audio extraction failed",
+		Explanation: "FFmpeg failed to extract the audio track of a video file, which is often corrupted or in a codec this FFmpeg build cannot decode.",
+		Remediation: []string{
+			"Check the file plays in a media player",
+			"Or extract the audio yourself: ffmpeg -i input.mkv -vn output.ogg",
+		},
+		errs: []error{audio.ErrExtractionFailed},
+	},
+	{
+		Code:        "TR-0436",
+		Summary:     "Download failed",
+		Explanation: "A URL input could not be downloaded: the server refused it (not found, access denied) or the connection dropped.",
+		Remediation: []string{
+			"Check the URL opens in a browser, without a login",
+			"Run the same command again: an interrupted download resumes where it stopped",
+		},
+		errs: []error{fetch.ErrDownloadFailed},
+	},
+	{
+		Code:        "TR-0437",
+		Summary:     "Download too large",
+		Explanation: "The file at the URL is over the download size limit (--max-download, 2GB by default).",
+		Remediation: []string{
+			"Raise the limit: --max-download 4GB",
+			"Or download the file yourself and transcribe it from disk",
+		},
+		errs: []error{fetch.ErrTooLarge},
+	},
+	{
+		Code:        "TR-0438",
+		Summary:     "Podcast feed without episode",
+		Explanation: "The URL serves an RSS feed, whose latest episode is transcribed, but no item of the feed has an audio enclosure.",
+		Remediation: []string{"Give the URL of the episode audio file instead of the feed"},
+		errs:        []error{fetch.ErrNoEpisode},
+	},
+	{
+		Code:        "TR-0439",
+		Summary:     "Invalid download size limit",
+		Explanation: "--max-download takes a size with an optional unit: B, KB, MB or GB.",
+		Remediation: []string{"Use a size such as --max-download 500MB"},
+		errs:        []error{ErrInvalidMaxDownload},
+	},
+	{
+		Code:        "TR-0440",
+		Summary:     "Warning treated as error",
+		Explanation: "--warn-as-error makes the command fail once it finished if it printed a warning. The output is written as without the flag.",
+		Remediation: []string{
+			"Fix the cause of the warning: transcript explain <warning code>",
+			"Or accept this warning: --suppress-warn <warning code>",
+		},
+		errs: []error{ErrWarningAsError},
+	},
+	{
+		Code:        "TR-0441",
+		Summary:     "Unknown warning code",
+		Explanation: "--suppress-warn takes the codes printed with warnings, like TR-W001.",
+		Remediation: []string{"List all codes with: transcript explain"},
+		errs:        []error{ErrUnknownWarning},
+	},
+	{
+		Code:        "TR-0442",
+		Summary:     "No session to digest",
+		Explanation: "digest reads the finished sessions of the --session-dir directories given. None started within --since, or none has the --tag.",
+		Remediation: []string{
+			"Widen the period: --since 30d",
+			"Check the directory is the one passed to --session-dir, and the tag is spelled as in the sessions",
+		},
+		errs: []error{ErrNoSessions},
+	},
+	{
+		Code:        "TR-0443",
+		Summary:     "Invalid speaker labels",
+		Explanation: "--speaker-labels tells how subtitles of a diarized transcript name the speaker: [Alice] in brackets, an Alice: prefix, a WebVTT voice tag, or not at all.",
+		Remediation: []string{"Pass --speaker-labels bracket, prefix, voice (with --format vtt) or none"},
+		errs:        []error{ErrInvalidSpeakerLabels},
+	},
+	{
+		Code:        "TR-0444",
+		Summary:     "Unknown config profile",
+		Explanation: "The profile selected with --profile or TRANSCRIPT_PROFILE has no [profile.NAME] section in the config file.",
+		Remediation: []string{
+			"List the profiles with: transcript config list-profiles",
+			"Create the profile with: transcript config set --profile <name> <key> <value>",
+		},
+		errs: []error{config.ErrUnknownProfile},
+	},
+	{
+		Code:        "TR-0445",
+		Summary:     "Audio translation not supported",
+		Explanation: "--translate-audio needs a backend that translates speech to English: openai, groq or local. The deepgram, assemblyai and grpc transcribers only transcribe.",
+		Remediation: []string{
+			"Use --transcriber openai, groq or local",
+			"Or drop --translate-audio and translate the notes instead: --template notes --translate en",
+		},
+		errs: []error{transcribe.ErrTranslateUnsupported},
+	},
+	{
+		Code:        "TR-0446",
+		Summary:     "Invalid glossary",
+		Explanation: "The glossary file of --glossary (or glossary in the config or project file) could not be read, has no terms, or has a line too long to be a term.",
+		Remediation: []string{
+			"Check the path printed in the error message",
+			"Write one term per line; blank lines and lines starting with # are ignored",
+		},
+		errs: []error{vocab.ErrInvalidGlossary},
+	},
+	{
+		Code:        "TR-0447",
+		Summary:     "Invalid timestamps interval",
+		Explanation: "--timestamps puts a time marker in the transcript every interval, or before every segment. The interval is a duration of at least one second.",
+		Remediation: []string{"Pass --timestamps=5m, --timestamps=30s or --timestamps=segment (with the =, since the value is optional)"},
+		errs:        []error{ErrInvalidTimestamps},
+	},
+	{
+		Code:        "TR-0448",
+		Summary:     "Invalid scheduled start",
+		Explanation: "--start-at takes a time of day on the 24-hour clock, HH:MM, and starts at its next occurrence (tomorrow if it is already past). --start-in takes a positive delay, like 10m or 1h30m.",
+		Remediation: []string{"Pass --start-at 14:00 or --start-in 10m, not both"},
+		errs:        []error{ErrInvalidStartTime},
+	},
+	{
+		Code:        "TR-0449",
+		Summary:     "Recording repair failed",
+		Explanation: "FFmpeg could not remux an interrupted recording. The crash may have come before any audio was written, or the file is damaged beyond its last page.",
+		Remediation: []string{
+			"Play the file to check whether it holds any audio",
+			"Try transcribing it as is: transcript transcribe <file>",
+		},
+		errs: []error{audio.ErrRepairFailed},
+	},
+	{
+		Code:        "TR-0450",
+		Summary:     "Ambiguous audio device",
+		Explanation: "--device (or the default-device config key) matches devices by any part of their name, ignoring case. The name given is part of the names of several devices.",
+		Remediation: []string{
+			"Run 'transcript devices' and pass more of the name, or the full name",
+			"Check the default-device config key: transcript config get default-device",
+		},
+		errs: []error{audio.ErrAmbiguousDevice},
+	},
+	{
+		Code:        "TR-0451",
+		Summary:     "Invalid mix gain",
+		Explanation: "--mic-gain and --system-gain take a gain in dB between -30 and 30, with or without the unit: 6 raises the input, -3dB lowers it. They and --auto-balance set the levels of the inputs of a --mix recording, and require --mix.",
+		Remediation: []string{"Record with --mix --mic-gain 6, or --mix --auto-balance"},
+		errs:        []error{ErrInvalidGain},
+	},
+	{
+		Code:        "TR-0452",
+		Summary:     "Audio preprocessing failed",
+		Explanation: "FFmpeg failed to run the filters of --denoise or --normalize on the audio, which is often corrupted or in a codec this FFmpeg build cannot decode.",
+		Remediation: []string{
+			"Check the file plays in a media player",
+			"Transcribe it without --denoise and --normalize",
+		},
+		errs: []error{audio.ErrPreprocessingFailed},
+	},
+	{
+		Code:        "TR-0453",
+		Summary:     "Audio protected by DRM",
+		Explanation: "The audio of this file is encrypted, as purchases of the iTunes Store (.m4p) or downloads of streaming services are. FFmpeg cannot decode it, so it cannot be transcribed.",
+		Remediation: []string{"Transcribe an unprotected copy of the audio, such as your own recording of it"},
+		errs:        []error{ErrProtectedMedia},
+	},
+	{
+		Code:        "TR-0454",
+		Summary:     "Unreadable audio",
+		Explanation: "The file is checked before transcription: FFmpeg must find and decode its audio. This one is corrupt, truncated beyond repair, in a codec this FFmpeg build cannot decode, or not a media file despite its extension.",
+		Remediation: []string{
+			"Check the file plays in a media player",
+			"For a recording cut short by a crash, try: transcript recover",
+			"Or convert it yourself: ffmpeg -i input output.ogg",
+		},
+		errs: []error{ErrUnreadableMedia},
+	},
+	{
+		Code:        "TR-0455",
+		Summary:     "Invalid redaction pattern",
+		Explanation: "A --redact-pattern, or a line of the redact-patterns file, is not a valid regular expression (RE2 syntax) or matches empty text; or the redact-patterns file cannot be read or has no patterns.",
+		Remediation: []string{
+			"Check the pattern, e.g.: --redact-pattern 'EMP-\\d{5}'",
+			"Put one pattern per line in the redact-patterns file; lines starting with # are ignored",
+			"Check the file: transcript config get redact-patterns",
+		},
+		errs: []error{redact.ErrInvalidPattern},
+	},
+	{
+		Code:        "TR-0456",
+		Summary:     "Invalid output name pattern",
+		Explanation: "The output-name setting names outputs with a placeholder that does not exist or is not closed, contains a directory, or has no placeholder at all.",
+		Remediation: []string{
+			"Use the placeholders {{date}}, {{time}}, {{template}}, {{input_stem}} and {{language}}, e.g.: transcript config set output-name '{{date}}_{{input_stem}}.md'",
+			"Choose the folder with output-dir, not in the pattern",
+			"Check the setting: transcript config get output-name",
+		},
+		errs: []error{notes.ErrInvalidName},
+	},
+	{
+		Code:        "TR-0457",
+		Summary:     "Not an Obsidian vault",
+		Explanation: "The --obsidian-vault path or obsidian-vault setting is not an Obsidian vault (it has no .obsidian directory), its daily notes settings cannot be read, or the obsidian-folder setting is outside of the vault.",
+		Remediation: []string{
+			"Give the root directory of the vault, the one holding .obsidian",
+			"Open the folder as a vault in Obsidian once, which creates .obsidian",
+			"Use a folder inside the vault, e.g.: transcript config set obsidian-folder Meetings",
+		},
+		errs: []error{obsidian.ErrNotVault},
+	},
+	{
+		Code:        "TR-0458",
+		Summary:     "Unknown exit code",
+		Explanation: "explain-exit documents the exit codes transcript uses. The given code is not one of them, or not a number.",
+		Remediation: []string{"List the exit codes: transcript explain-exit"},
+		errs:        []error{ErrUnknownExitCode},
+	},
+	{
+		Code:        "TR-0459",
+		Summary:     "Invalid statistics format",
+		Explanation: "--stats selects where the speaker statistics of a diarized transcript go: md appends a section to the output, json writes them to <output>.stats.json.",
+		Remediation: []string{"Pass --stats=md or --stats=json (the = is required), or --stats alone for md"},
+		errs:        []error{ErrInvalidStats},
+	},
+	{
+		Code:        "TR-0460",
+		Summary:     "Invalid confidence threshold",
+		Explanation: "--unclear lists the passages transcribed with a confidence below its threshold, a percentage or a fraction strictly between 0 and 1.",
+		Remediation: []string{"Pass --unclear=70% or --unclear=0.7 (the = is required), or --unclear alone for 50%"},
+		errs:        []error{ErrInvalidConfidence},
+	},
+	{
+		Code:        "TR-0461",
+		Summary:     "Invalid template variable",
+		Explanation: "A placeholder of the template, like {{attendees}}, has no value; or a --var is not name=value; or the --vars-file file cannot be read or has a line that is not name=value.",
+		Remediation: []string{
+			"Give every placeholder a value, e.g.: --var attendees=\"Alice, Bob\"",
+			"Write one name=value per line in the vars file; lines starting with # are ignored",
+		},
+		errs: []error{template.ErrInvalidVar},
+	},
+	{
+		Code:        "TR-0462",
+		Summary:     "Invalid summary level",
+		Explanation: "--summary-level sets the length of the restructured notes: short for a 5-line executive summary, medium for the template as written, detailed for a breakdown of every point.",
+		Remediation: []string{"Pass --summary-level short, medium or detailed"},
+		errs:        []error{template.ErrInvalidLevel},
+	},
+	{
+		Code:        "TR-0463",
+		Summary:     "Invalid action items format",
+		Explanation: "--actions selects the format of the action items written next to the output: json for <output>.tasks.json, following the tasks schema, or csv for <output>.tasks.csv.",
+		Remediation: []string{"Pass --actions=json or --actions=csv (the = is required), or --actions alone for json"},
+		errs:        []error{ErrInvalidActionsFormat},
+	},
+
+	// API (exit code 5).
+	{
+		Code:        "TR-0501",
+		Summary:     "API rate limit exceeded",
+		Explanation: "The provider rejected requests sent too fast. They were retried with backoff before giving up.",
+		Remediation: []string{
+			"Lower the concurrency: -p 1, or -p auto",
+			"Wait a minute and retry; interrupted transcriptions continue with --resume",
+		},
+		errs: []error{apierr.ErrRateLimit},
+	},
+	{
+		Code:        "TR-0502",
+		Summary:     "API quota exceeded",
+		Explanation: "The provider account has no credit left or reached its spending limit.",
+		Remediation: []string{"Check billing in the provider dashboard, then retry"},
+		errs:        []error{apierr.ErrQuotaExceeded},
+	},
+	{
+		Code:        "TR-0503",
+		Summary:     "API request timeout",
+		Explanation: "A request did not complete in time, or an upload stalled. Retries were exhausted.",
+		Remediation: []string{
+			"Check your connection, then retry with --resume to keep finished chunks",
+			"On slow connections, lower the concurrency with -p auto",
+		},
+		errs: []error{apierr.ErrTimeout},
+	},
+	{
+		Code:        "TR-0504",
+		Summary:     "API authentication failed",
+		Explanation: "The provider rejected the API key: it is wrong, revoked, or belongs to another provider.",
+		Remediation: []string{"Check the API key of the provider in use (OPENAI_API_KEY, DEEPSEEK_API_KEY or ANTHROPIC_API_KEY)"},
+		errs:        []error{apierr.ErrAuthFailed},
+	},
+	{
+		Code:        "TR-0505",
+		Summary:     "API rejected the request",
+		Explanation: "The provider rejected the request as invalid, for instance an unknown model or unsupported audio.",
+		Remediation: []string{
+			"Read the provider message in the error for the cause",
+			"With Ollama, pull the configured model: ollama pull <model>",
+		},
+		errs: []error{apierr.ErrBadRequest},
+	},
+
+	// Restructure (exit code 6).
+	{
+		Code:        "TR-0601",
+		Summary:     "Transcript too long to restructure",
+		Explanation: "The transcript, one of its parts, or the notes merged from them exceed the input limit of the restructuring provider.",
+		Remediation: []string{
+			"Try --provider openai or anthropic, which accept longer inputs",
+			"Use smaller parts (--restructure-chunk-tokens) or more merge levels (--max-reduce-levels)",
+			"Keep the raw transcript and restructure parts of it with transcript structure",
+		},
+		errs: []error{restructure.ErrTranscriptTooLong},
+	},
+	{
+		Code:        "TR-0602",
+		Summary:     "Streamed restructuring interrupted",
+		Explanation: "With --stream-restructure, the response of the provider broke off after part of the notes was written. It is not retried, so that the notes are not written twice; the output file keeps the part written, marked as partial.",
+		Remediation: []string{
+			"Check the network connection and run the command again",
+			"Run without --stream-restructure, so that the failed call is retried",
+			"Restructure the raw transcript with transcript structure",
+		},
+		errs: []error{restructure.ErrStreamInterrupted},
+	},
+
+	// Partial output (exit code 7).
+	{
+		Code:        "TR-0701",
+		Summary:     "Some chunks could not be transcribed",
+		Explanation: "With --allow-partial, chunks that still failed after being retried were replaced by a [transcription failed] marker. The output was written without them.",
+		Remediation: []string{
+			"Read the warnings for the cause of each failed chunk",
+			"Re-run with --resume --force: only the missing chunks are transcribed, and the partial output is replaced",
+		},
+		errs: []error{transcribe.ErrPartial},
+	},
+}
+
+// LookupError returns the catalog entry of err: the first entry whose
+// sentinel err wraps. Returns false if err has no documented cause.
+func LookupError(err error) (ErrorInfo, bool) {
+	if err == nil {
+		return ErrorInfo{}, false
+	}
+	for _, info := range errorCatalog {
+		for _, target := range info.errs {
+			if errors.Is(err, target) {
+				return info, true
+			}
+		}
+	}
+	return ErrorInfo{}, false
+}
+
+// FormatError returns the message of err, followed by its error code and
+// how to get help about it when the cause is documented.
+func FormatError(err error) string {
+	info, ok := LookupError(err)
+	if !ok {
+		return err.Error()
+	}
+	return fmt.Sprintf("%v (code %s, see 'transcript explain %s')", err, info.Code, info.Code)
+}
+
+// lookupCode returns the catalog entry of the error or warning code,
+// case-insensitively. Returns ErrUnknownErrorCode if no entry has this code.
+func lookupCode(code string) (ErrorInfo, error) {
+	for _, info := range errorCatalog {
+		if strings.EqualFold(info.Code, code) {
+			return info, nil
+		}
+	}
+	if info, ok := lookupWarning(code); ok {
+		return info, nil
+	}
+	return ErrorInfo{}, fmt.Errorf("%q: %w", code, ErrUnknownErrorCode)
+}
+
+// writeErrorInfo renders a catalog entry for the explain command.
+func writeErrorInfo(w io.Writer, info ErrorInfo) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %s\n\n%s\n\nWhat to do:\n", info.Code, info.Summary, info.Explanation)
+	for _, step := range info.Remediation {
+		fmt.Fprintf(&b, "  - %s\n", step)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
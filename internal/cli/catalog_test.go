@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/template"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// TestErrorCatalog_Entries guards the catalog invariants: well-formed,
+// unique codes, complete documentation, and sentinels owned by one entry.
+func TestErrorCatalog_Entries(t *testing.T) {
+	t.Parallel()
+
+	codePattern := regexp.MustCompile(`^TR-0[3-7]\d\d$`)
+	codes := make(map[string]bool)
+	owners := make(map[error]string)
+
+	for _, info := range ErrorCatalog {
+		if !codePattern.MatchString(info.Code) {
+			t.Errorf("code %q does not match %s", info.Code, codePattern)
+		}
+		if codes[info.Code] {
+			t.Errorf("code %q is used twice", info.Code)
+		}
+		codes[info.Code] = true
+
+		if info.Summary == "" || info.Explanation == "" || len(info.Remediation) == 0 {
+			t.Errorf("%s: summary, explanation and remediation are required", info.Code)
+		}
+		errs := CatalogErrors(info)
+		if len(errs) == 0 {
+			t.Errorf("%s: no sentinel error", info.Code)
+		}
+		for _, err := range errs {
+			if owner, ok := owners[err]; ok {
+				t.Errorf("%s: %v is already documented by %s", info.Code, err, owner)
+			}
+			owners[err] = info.Code
+		}
+	}
+}
+
+func TestLookupError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+	}{
+		{"sentinel", ErrAPIKeyMissing, "TR-0310"},
+		{"wrapped", fmt.Errorf("chunk 3: %w", apierr.ErrRateLimit), "TR-0501"},
+		{"second sentinel of an entry", audio.ErrFileNotFound, "TR-0403"},
+		{"joined, most specific first", errors.Join(template.ErrUnknown, template.ErrInvalid), "TR-0420"},
+		{"partial output", &transcribe.PartialError{}, "TR-0701"},
+		{"undocumented", errors.New("boom"), ""},
+		{"nil", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			info, ok := LookupError(tt.err)
+			if ok != (tt.wantCode != "") || info.Code != tt.wantCode {
+				t.Errorf("LookupError(%v) = %q, %v, want %q", tt.err, info.Code, ok, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	t.Parallel()
+
+	err := fmt.Errorf("cannot start: %w", audio.ErrNoAudioDevice)
+	want := "cannot start: no audio input device found (code TR-0320, see 'transcript explain TR-0320')"
+	if got := FormatError(err); got != want {
+		t.Errorf("FormatError() = %q, want %q", got, want)
+	}
+
+	plain := errors.New("boom")
+	if got := FormatError(plain); got != "boom" {
+		t.Errorf("FormatError() = %q, want %q", got, "boom")
+	}
+}
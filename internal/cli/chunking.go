@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// chunkerFlagHelp and chunkSizeFlagHelp describe the chunking flags of the
+// transcribe and live commands.
+const (
+	chunkerFlagHelp   = "Chunking strategy: silence (cut at pauses), energy (cut at the quietest moments), time (fixed duration), size (fixed file size)"
+	chunkSizeFlagHelp = "Chunk size, e.g. 10MB: the max size of silence and energy chunks, the exact size of size chunks (default: strategy default)"
+)
+
+// chunking holds the validated chunking options of a run (--chunker, --chunk-size).
+// The zero value selects silence chunks of the default size.
+type chunking struct {
+	strategy string // Registered chunking strategy (see audio.ChunkerNames)
+	size     int64  // Chunk size in bytes; 0 means the strategy default
+}
+
+// parseChunking validates the --chunker and --chunk-size values.
+// An empty strategy means silence, an empty size the strategy default.
+// Returns audio.ErrUnknownChunker or audio.ErrInvalidChunkSize.
+func parseChunking(strategy, size string) (chunking, error) {
+	strategy = strings.ToLower(strings.TrimSpace(strategy))
+	if strategy == "" {
+		strategy = audio.StrategySilence
+	}
+	if names := audio.ChunkerNames(); !slices.Contains(names, strategy) {
+		return chunking{}, fmt.Errorf("%w: %q (available: %s)",
+			audio.ErrUnknownChunker, strategy, strings.Join(names, ", "))
+	}
+
+	c := chunking{strategy: strategy}
+	if strings.TrimSpace(size) == "" {
+		return c, nil
+	}
+	n, err := format.ParseSize(size)
+	if err != nil {
+		return chunking{}, fmt.Errorf("%w: %v", audio.ErrInvalidChunkSize, err)
+	}
+	if n < audio.MinChunkSize {
+		return chunking{}, fmt.Errorf("%w: %s is below the minimum of %s",
+			audio.ErrInvalidChunkSize, format.Size(n), format.Size(audio.MinChunkSize))
+	}
+	c.size = n
+	return c, nil
+}
+
+// validateFor checks that the chunks of c fit the file size limit of the API
+// backend transcribes with. The local and gRPC backends have no limit.
+// Returns audio.ErrInvalidChunkSize.
+func (c chunking) validateFor(backend Backend) error {
+	p, ok := backend.provider()
+	if !ok || c.size <= p.MaxFileSize {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is over the %s limit of the %s transcriber",
+		audio.ErrInvalidChunkSize, format.Size(c.size), format.Size(p.MaxFileSize), p.Name)
+}
+
+// newChunker creates the chunker of the strategy with the env chunker factory.
+// runID names the temp directories of the chunks (see audio.NewRunID), so
+// that runs executing concurrently never share nor clean up each other's chunks.
+func (c chunking) newChunker(env *Env, ffmpegPath, runID string) (audio.Chunker, error) {
+	return env.ChunkerFactory.NewChunker(c.strategy, audio.ChunkerConfig{FFmpegPath: ffmpegPath, ChunkSize: c.size, RunID: runID})
+}
+
+// message returns the progress message printed while chunking.
+func (c chunking) message() string {
+	switch c.strategy {
+	case "", audio.StrategySilence:
+		return "Detecting silences..."
+	case audio.StrategyEnergy:
+		return "Measuring loudness..."
+	default:
+		return fmt.Sprintf("Splitting audio by %s...", c.strategy)
+	}
+}
@@ -0,0 +1,261 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestParseChunking(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		strategy string
+		size     string
+		want     chunking
+		wantErr  error
+	}{
+		{"defaults", "", "", chunking{strategy: audio.StrategySilence}, nil},
+		{"silence with max size", "silence", "10MB", chunking{strategy: audio.StrategySilence, size: 10 << 20}, nil},
+		{"time", "time", "", chunking{strategy: audio.StrategyTime}, nil},
+		{"size", "size", "4MB", chunking{strategy: audio.StrategySize, size: 4 << 20}, nil},
+		{"case and spaces", " Size ", "512 kb", chunking{strategy: audio.StrategySize, size: 512 << 10}, nil},
+		{"unknown strategy", "vad", "", chunking{}, audio.ErrUnknownChunker},
+		{"malformed size", "size", "big", chunking{}, audio.ErrInvalidChunkSize},
+		{"size below minimum", "size", "1KB", chunking{}, audio.ErrInvalidChunkSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseChunking(tt.strategy, tt.size)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("parseChunking(%q, %q) error = %v, want %v", tt.strategy, tt.size, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseChunking(%q, %q) = %+v, %v; want %+v", tt.strategy, tt.size, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunking_ValidateFor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		chunking chunking
+		backend  Backend
+		wantErr  bool
+	}{
+		{"default size", chunking{}, OpenAIBackend, false},
+		{"under the openai limit", chunking{size: 24 << 20}, OpenAIBackend, false},
+		{"over the openai limit", chunking{size: 30 << 20}, Backend{}, true},
+		{"over the groq limit", chunking{size: 30 << 20}, Backend{name: transcribe.ProviderGroq}, true},
+		{"under the deepgram limit", chunking{size: 100 << 20}, Backend{name: transcribe.ProviderDeepgram}, false},
+		{"local has no limit", chunking{size: 100 << 20}, LocalBackend, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.chunking.validateFor(tt.backend)
+		if tt.wantErr != errors.Is(err, audio.ErrInvalidChunkSize) {
+			t.Errorf("%s: validateFor() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestChunking_Message(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		chunking chunking
+		want     string
+	}{
+		{chunking{}, "Detecting silences..."},
+		{chunking{strategy: audio.StrategySilence}, "Detecting silences..."},
+		{chunking{strategy: audio.StrategySize}, "Splitting audio by size..."},
+		{chunking{strategy: audio.StrategyEnergy}, "Measuring loudness..."},
+	}
+
+	for _, tt := range tests {
+		if got := tt.chunking.message(); got != tt.want {
+			t.Errorf("%+v.message() = %q, want %q", tt.chunking, got, tt.want)
+		}
+	}
+}
+
+func TestRunTranscribe_Chunking(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	cmd := createTranscribeCmd(context.Background())
+	cmd.SetOut(&bytes.Buffer{})
+
+	chunkerFactory := &mockChunkerFactory{}
+	env := &Env{
+		Stderr:              &syncBuffer{},
+		Getenv:              func(string) string { return "" },
+		Now:                 fixedTime(time.Now()),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        &mockConfigLoader{},
+		ChunkerFactory:      chunkerFactory,
+		MediaProberFactory:  &mockMediaProberFactory{},
+		TranscriberFactory:  &mockTranscriberFactory{},
+		RestructurerFactory: &mockRestructurerFactory{},
+	}
+
+	opts := mustParseTranscribeOptions(t, inputPath, "", "", false, 5, "", "", "deepseek")
+	opts.dryRun = true
+	opts.chunking = chunking{strategy: audio.StrategySize, size: 4 << 20}
+	if err := RunTranscribe(cmd, env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+
+	calls := chunkerFactory.NewChunkerCalls()
+	if len(calls) != 1 || calls[0].strategy != audio.StrategySize || calls[0].cfg.ChunkSize != 4<<20 || calls[0].cfg.FFmpegPath == "" {
+		t.Errorf("NewChunker() calls = %+v, want one size chunker of 4MB", calls)
+	}
+}
+
+func TestTranscribeCmd_InvalidChunking(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{
+		{"unknown chunker", []string{"--chunker", "vad"}, audio.ErrUnknownChunker},
+		{"invalid chunk size", []string{"--chunker", "size", "--chunk-size", "10"}, audio.ErrInvalidChunkSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inputPath := createTestAudioFile(t, "audio.ogg")
+			env, _ := testEnv()
+			cmd := TranscribeCmd(env)
+
+			cmd.SetArgs(append([]string{inputPath}, tt.args...))
+			if err := cmd.Execute(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("cmd.Execute() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// chunkerFactoryFunc adapts a function to ChunkerFactory.
+type chunkerFactoryFunc func(strategy string, cfg audio.ChunkerConfig) (audio.Chunker, error)
+
+func (f chunkerFactoryFunc) NewChunker(strategy string, cfg audio.ChunkerConfig) (audio.Chunker, error) {
+	return f(strategy, cfg)
+}
+
+// fakeFFmpegRunner answers the FFmpeg commands of the chunkers for a 2-minute
+// audio file: the file info when probed, a chunk file when extracting.
+type fakeFFmpegRunner struct{}
+
+func (fakeFFmpegRunner) CombinedOutput(_ context.Context, _ string, args []string) ([]byte, error) {
+	if !slices.Contains(args, "-ss") {
+		return []byte("Duration: 00:02:00.00, start: 0.000000"), nil
+	}
+	return nil, os.WriteFile(args[len(args)-1], []byte("chunk audio"), 0o600)
+}
+
+// rootTempDirCreator creates temp directories in the directory it names.
+type rootTempDirCreator string
+
+func (r rootTempDirCreator) MkdirTemp(_, pattern string) (string, error) {
+	return os.MkdirTemp(string(r), pattern)
+}
+
+func TestRunTranscribe_ConcurrentRuns(t *testing.T) {
+	t.Parallel()
+
+	const runs = 6
+	root := t.TempDir()
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	outputDir := t.TempDir()
+
+	var mu sync.Mutex
+	runIDs := make(map[string]bool)
+	chunkDirs := make([]map[string]bool, runs)
+	errs := make([]error, runs)
+
+	var wg sync.WaitGroup
+	for i := range runs {
+		chunkDirs[i] = make(map[string]bool)
+		env, _ := testEnv()
+		env.ChunkerFactory = chunkerFactoryFunc(func(_ string, cfg audio.ChunkerConfig) (audio.Chunker, error) {
+			mu.Lock()
+			runIDs[cfg.RunID] = true
+			mu.Unlock()
+			return audio.NewTimeChunker(cfg.FFmpegPath, 30*time.Second, 5*time.Second,
+				audio.WithTimeChunkerCommandRunner(fakeFFmpegRunner{}),
+				audio.WithTimeChunkerTempDir(rootTempDirCreator(root)),
+				audio.WithTimeChunkerRunID(cfg.RunID))
+		})
+		env.TranscriberFactory = &mockTranscriberFactory{
+			NewTranscriberFunc: func(string) transcribe.Transcriber {
+				return &mockTranscriber{
+					TranscribeFunc: func(_ context.Context, path string, _ transcribe.Options) (string, error) {
+						// Fails if another run cleaned up this chunk
+						if _, err := os.Stat(path); err != nil {
+							return "", err
+						}
+						mu.Lock()
+						chunkDirs[i][filepath.Dir(path)] = true
+						mu.Unlock()
+						return filepath.Base(path), nil
+					},
+				}
+			},
+		}
+
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(outputDir, fmt.Sprintf("run%d.md", i)), "", false, 2, "", "", "deepseek")
+		wg.Go(func() {
+			errs[i] = RunTranscribe(createTranscribeCmd(context.Background()), env, opts)
+		})
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("run %d: RunTranscribe() error = %v", i, err)
+		}
+	}
+	if len(runIDs) != runs || runIDs[""] {
+		t.Errorf("run IDs = %v, want %d distinct IDs", runIDs, runs)
+	}
+	owners := make(map[string]int)
+	for i, dirs := range chunkDirs {
+		if len(dirs) != 1 {
+			t.Errorf("run %d: chunks transcribed from %d directories, want 1", i, len(dirs))
+		}
+		for dir := range dirs {
+			if owner, ok := owners[dir]; ok {
+				t.Errorf("runs %d and %d share chunk directory %s", owner, i, dir)
+			}
+			owners[dir] = i
+		}
+	}
+	if entries, err := os.ReadDir(root); err != nil || len(entries) != 0 {
+		t.Errorf("temp directory entries = %v (%v), want every chunk directory cleaned up", entries, err)
+	}
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"slices"
+
+	"github.com/alnah/go-transcript/internal/cleanup"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// cleanFlagHelp describes the --clean flag of the transcribe and live commands.
+const cleanFlagHelp = "Remove filler words, hesitations and repeated words, and normalize punctuation (rules of --language)"
+
+// newCleaner returns the cleaner of --clean for transcripts in language,
+// with the fillers configured for it. Returns nil without --clean.
+func newCleaner(clean bool, language lang.Language, cfg config.Config) *cleanup.Cleaner {
+	if !clean {
+		return nil
+	}
+	extra := slices.Concat(cfg.CleanFillers["*"], cfg.CleanFillers[language.BaseCode()])
+	return cleanup.New(language, extra)
+}
+
+// cleanResults cleans the transcription results with cleaner (--clean).
+// Timestamped results (see transcribe.Options.Timestamps) are cleaned
+// segment by segment, and segments left empty are dropped (see
+// transcribe.EncodeSegments).
+// Returns results unchanged with a nil cleaner.
+func cleanResults(cleaner *cleanup.Cleaner, results []string, timestamps bool) ([]string, error) {
+	if cleaner == nil {
+		return results, nil
+	}
+	cleaned := make([]string, len(results))
+	for i, result := range results {
+		if !timestamps {
+			cleaned[i] = cleaner.Clean(result)
+			continue
+		}
+		segments, err := transcribe.ParseSegments(result)
+		if err != nil {
+			return nil, err
+		}
+		for j := range segments {
+			segments[j].Text = cleaner.Clean(segments[j].Text)
+		}
+		if cleaned[i], err = transcribe.EncodeSegments(segments); err != nil {
+			return nil, err
+		}
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// ---------------------------------------------------------------------------
+// Tests for cleanResults
+// ---------------------------------------------------------------------------
+
+func TestCleanResults(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{CleanFillers: map[string][]string{"*": {"okay"}, "en": {"basically"}}}
+	cleaner := newCleaner(true, lang.MustParse("en-US"), cfg)
+
+	t.Run("without clean", func(t *testing.T) {
+		t.Parallel()
+
+		results := []string{"Um, hi."}
+		got, err := cleanResults(newCleaner(false, lang.Language{}, cfg), results, false)
+		if err != nil {
+			t.Fatalf("cleanResults() unexpected error: %v", err)
+		}
+		if !slices.Equal(got, results) {
+			t.Errorf("cleanResults() = %q, want %q", got, results)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := cleanResults(cleaner, []string{"Um, hi.", "Okay, it is, basically, done."}, false)
+		if err != nil {
+			t.Fatalf("cleanResults() unexpected error: %v", err)
+		}
+		want := []string{"Hi.", "It is, done."}
+		if !slices.Equal(got, want) {
+			t.Errorf("cleanResults() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("segments", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := transcribe.EncodeSegments([]transcribe.TimedSegment{
+			{Start: 0, End: 1e9, Text: "Um."},
+			{Start: 1e9, End: 2e9, Text: "The the end."},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := cleanResults(cleaner, []string{result}, true)
+		if err != nil {
+			t.Fatalf("cleanResults() unexpected error: %v", err)
+		}
+		segments, err := transcribe.ParseSegments(got[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(segments) != 1 || segments[0].Text != "The end." || segments[0].Start != 1e9 {
+			t.Errorf("segments = %+v, want only the cleaned second segment", segments)
+		}
+	})
+}
@@ -2,16 +2,107 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"slices"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/ffmpeg"
+	"github.com/alnah/go-transcript/internal/integrations/obsidian"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/notes"
+	"github.com/alnah/go-transcript/internal/redact"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/tlsconfig"
+	"github.com/alnah/go-transcript/internal/vocab"
 )
 
 // validConfigKeys lists all supported configuration keys.
 var validConfigKeys = []string{
 	config.KeyOutputDir,
+	config.KeyOutputName,
+	config.KeyFrontMatter,
+	config.KeyObsidianVault,
+	config.KeyObsidianFolder,
+	config.KeyPromptTokenWarning,
+	config.KeyTranscriber,
+	config.KeyWhisperModel,
+	config.KeyWhisperBin,
+	config.KeyWhisperURL,
+	config.KeyGRPCEndpoint,
+	config.KeyGRPCPlaintext,
+	config.KeyGRPCTimeout,
+	config.KeyAzureEndpoint,
+	config.KeyAzureTranscribe,
+	config.KeyAzureChat,
+	config.KeyAzureAPIVersion,
+	config.KeyTagsDir,
+	config.KeyIntroLibrary,
+	config.KeyGlossary,
+	config.KeyCleanFillers,
+	config.KeyRedactPatterns,
+	config.KeyOllamaURL,
+	config.KeyOllamaModel,
+	config.KeyTemplatesDir,
+	config.KeyRestructureModel,
+	config.KeyContextWindows,
+	config.KeyRestructureSplit,
+	config.KeyRestructureChunk,
+	config.KeyRestructureOverlap,
+	config.KeyDefaultCommand,
+	config.KeyDefaultDevice,
+	config.KeyFFmpegPath,
+	config.KeyCABundle,
+	config.KeyClientCert,
+	config.KeyClientKey,
+	config.KeyRateLimitRequests,
+	config.KeyRateLimitAudio,
+	config.KeyNotifyWebhook,
+}
+
+// configEnvVars maps configuration keys to their environment variable fallbacks.
+var configEnvVars = map[string]string{
+	config.KeyOutputDir:          config.EnvOutputDir,
+	config.KeyOutputName:         config.EnvOutputName,
+	config.KeyFrontMatter:        config.EnvFrontMatter,
+	config.KeyObsidianVault:      config.EnvObsidianVault,
+	config.KeyObsidianFolder:     config.EnvObsidianFolder,
+	config.KeyPromptTokenWarning: config.EnvPromptTokenWarning,
+	config.KeyTranscriber:        config.EnvTranscriber,
+	config.KeyWhisperModel:       config.EnvWhisperModel,
+	config.KeyWhisperBin:         config.EnvWhisperBin,
+	config.KeyWhisperURL:         config.EnvWhisperURL,
+	config.KeyGRPCEndpoint:       config.EnvGRPCEndpoint,
+	config.KeyGRPCPlaintext:      config.EnvGRPCPlaintext,
+	config.KeyGRPCTimeout:        config.EnvGRPCTimeout,
+	config.KeyAzureEndpoint:      config.EnvAzureEndpoint,
+	config.KeyAzureTranscribe:    config.EnvAzureTranscribe,
+	config.KeyAzureChat:          config.EnvAzureChat,
+	config.KeyAzureAPIVersion:    config.EnvAzureAPIVersion,
+	config.KeyTagsDir:            config.EnvTagsDir,
+	config.KeyIntroLibrary:       config.EnvIntroLibrary,
+	config.KeyGlossary:           config.EnvGlossary,
+	config.KeyCleanFillers:       config.EnvCleanFillers,
+	config.KeyRedactPatterns:     config.EnvRedactPatterns,
+	config.KeyOllamaURL:          config.EnvOllamaURL,
+	config.KeyOllamaModel:        config.EnvOllamaModel,
+	config.KeyTemplatesDir:       config.EnvTemplatesDir,
+	config.KeyRestructureModel:   config.EnvRestructureModel,
+	config.KeyContextWindows:     config.EnvContextWindows,
+	config.KeyRestructureSplit:   config.EnvRestructureSplit,
+	config.KeyRestructureChunk:   config.EnvRestructureChunk,
+	config.KeyRestructureOverlap: config.EnvRestructureOverlap,
+	config.KeyDefaultCommand:     config.EnvDefaultCommand,
+	config.KeyDefaultDevice:      config.EnvDefaultDevice,
+	config.KeyCABundle:           config.EnvCABundle,
+	config.KeyClientCert:         config.EnvClientCert,
+	config.KeyClientKey:          config.EnvClientKey,
+	config.KeyRateLimitRequests:  config.EnvRateLimitRequests,
+	config.KeyRateLimitAudio:     config.EnvRateLimitAudio,
+	config.KeyNotifyWebhook:      config.EnvNotifyWebhook,
 }
 
 // ConfigCmd creates the config command with subcommands.
@@ -22,58 +113,245 @@ func ConfigCmd(env *Env) *cobra.Command {
 		Short: "Manage configuration settings",
 		Long: `Manage persistent configuration settings.
 
-Configuration is stored in ~/.config/go-transcript/config.
+Configuration is stored in the config file of the user config directory:
+~/.config/go-transcript/config on Linux (or $XDG_CONFIG_HOME/go-transcript),
+~/Library/Application Support/go-transcript/config on macOS, and
+%APPDATA%\go-transcript\config on Windows. The global --config flag (or
+TRANSCRIPT_CONFIG) uses another file. Run "transcript config path" to see where
+the config, templates, tag vocabulary and FFmpeg download are.
 Settings can also be overridden via environment variables.
 
+A .transcript.toml file in the working directory or a parent directory
+overrides output-dir, templates-dir, tags-dir and glossary for the recordings
+of that project, and can set a default tag and vocabulary (see the README).
+
+Profiles are named sets of defaults, in [profile.NAME] sections of the config
+file: template, language, provider, diarize, parallel and output-dir. Select
+one with --profile on transcribe and live, or with TRANSCRIPT_PROFILE; flags
+given on the command line override it. "transcript config set --profile NAME"
+sets them, "transcript config list-profiles" lists them.
+
+"transcript config validate" checks the config file for errors, and
+"transcript config doctor" checks that FFmpeg, the API keys, the audio
+devices and the output directory are ready.
+
+"transcript config set-key --provider openai" stores an API key in the OS
+keyring instead of an environment variable or .env file.
+
 Supported settings:
-  output-dir    Default directory for output files (env: TRANSCRIPT_OUTPUT_DIR)`,
+  output-dir              Default directory for output files (env: TRANSCRIPT_OUTPUT_DIR)
+  output-name             Pattern of default output file names, with {{date}}, {{time}},
+                          {{template}}, {{input_stem}} and {{language}}, like
+                          {{date}}_{{template}}_{{input_stem}}.md
+                          (default: named after the input, env: TRANSCRIPT_OUTPUT_NAME)
+  front-matter            Put YAML front matter (date, duration, language, template,
+                          audio, speakers) at the top of Markdown outputs: true or false
+                          (default: false, env: TRANSCRIPT_FRONT_MATTER)
+  obsidian-vault          Obsidian vault outputs are written into when no output is
+                          given, like --obsidian-vault (env: TRANSCRIPT_OBSIDIAN_VAULT)
+  obsidian-folder         Folder of obsidian-vault outputs are written to
+                          (default: Transcripts, env: TRANSCRIPT_OBSIDIAN_FOLDER)
+  prompt-token-warning    Warn when a restructure call's prompt exceeds this many tokens
+                          (default: 100000, env: TRANSCRIPT_PROMPT_TOKEN_WARNING)
+  transcriber             Transcription backend: openai, local, grpc, groq,
+                          deepgram or assemblyai
+                          (default: openai, env: TRANSCRIPT_TRANSCRIBER)
+  whisper-model           whisper.cpp model file for local transcription
+                          (env: TRANSCRIPT_WHISPER_MODEL)
+  whisper-bin             whisper.cpp binary (default: whisper-cli in PATH,
+                          env: TRANSCRIPT_WHISPER_BIN)
+  whisper-url             OpenAI-compatible local whisper server, used instead of
+                          whisper.cpp (env: TRANSCRIPT_WHISPER_URL)
+  grpc-endpoint           host:port of the ASR server of the grpc transcriber,
+                          implementing docs/asr.proto (env: TRANSCRIPT_GRPC_ENDPOINT)
+  grpc-plaintext          Connect to grpc-endpoint without TLS: true or false
+                          (default: false, env: TRANSCRIPT_GRPC_PLAINTEXT)
+  grpc-timeout            Deadline of each chunk sent to grpc-endpoint
+                          (default: 5m, env: TRANSCRIPT_GRPC_TIMEOUT)
+  azure-endpoint          Azure OpenAI resource called instead of OpenAI by the openai
+                          transcriber and --provider openai, like
+                          https://NAME.openai.azure.com (env: TRANSCRIPT_AZURE_ENDPOINT)
+  azure-transcribe-deployment
+                          Deployment of the transcription model on azure-endpoint
+                          (env: TRANSCRIPT_AZURE_TRANSCRIBE_DEPLOYMENT)
+  azure-chat-deployment   Deployment of the restructuring model on azure-endpoint
+                          (env: TRANSCRIPT_AZURE_CHAT_DEPLOYMENT)
+  azure-api-version       api-version of the Azure OpenAI requests
+                          (default: 2024-10-21, env: TRANSCRIPT_AZURE_API_VERSION)
+  tags-dir                Vocabulary recorded for each --tag (default: tags/ in
+                          the state directory, env: TRANSCRIPT_TAGS_DIR)
+  intro-library           Intros and outros of earlier recordings (--intro-outro)
+                          (default: intros.json in the state directory,
+                          env: TRANSCRIPT_INTRO_LIBRARY)
+  glossary                File of terms (one per line) put in the transcription and
+                          restructure prompts, like --glossary (env: TRANSCRIPT_GLOSSARY)
+  clean-fillers           Fillers removed by --clean besides the built-in ones, as
+                          language=filler pairs separated by commas (* for every
+                          language, env: TRANSCRIPT_CLEAN_FILLERS)
+  redact-patterns         File of regular expressions (one per line) also masked
+                          by --redact (env: TRANSCRIPT_REDACT_PATTERNS)
+  ollama-url              Ollama server for --provider ollama
+                          (default: http://localhost:11434, env: TRANSCRIPT_OLLAMA_URL)
+  ollama-model            Ollama model for --provider ollama
+                          (default: llama3.1, env: TRANSCRIPT_OLLAMA_MODEL)
+  templates-dir           User templates selectable by name with --template
+                          (default: templates/ next to the config file,
+                          env: TRANSCRIPT_TEMPLATES_DIR)
+  restructure-model       Model of the restructure provider, like --restructure-model
+                          (default: provider default, env: TRANSCRIPT_RESTRUCTURE_MODEL)
+  context-windows         Context windows of models missing from the built-in table,
+                          as model=tokens pairs separated by commas
+                          (env: TRANSCRIPT_CONTEXT_WINDOWS)
+  restructure-split       How long transcripts are divided into parts: paragraphs, or
+                          speakers to cut at speaker turns of diarized transcripts
+                          (default: paragraphs, env: TRANSCRIPT_RESTRUCTURE_SPLIT)
+  restructure-chunk-tokens
+                          Size of the parts of long transcripts in tokens, at least 1000
+                          (default: from the model context window,
+                          env: TRANSCRIPT_RESTRUCTURE_CHUNK_TOKENS)
+  restructure-overlap     Tokens of the previous part repeated at the start of each part
+                          (default: 0, env: TRANSCRIPT_RESTRUCTURE_OVERLAP)
+  default-command         Command run by "transcript <file>": transcribe or structure
+                          (default: transcribe, env: TRANSCRIPT_DEFAULT_COMMAND)
+  ffmpeg-path             ffmpeg binary used instead of FFMPEG_PATH, PATH and the
+                          downloaded one, like --ffmpeg-path
+  ca-bundle               PEM file of certificate authorities trusted in addition to
+                          the system ones, for proxies intercepting TLS
+                          (env: TRANSCRIPT_CA_BUNDLE)
+  client-cert             PEM client certificate for proxies requiring mutual TLS
+                          (env: TRANSCRIPT_CLIENT_CERT)
+  client-key              PEM private key of client-cert (env: TRANSCRIPT_CLIENT_KEY)
+  rate-limit-requests     Transcription requests per minute, shared by parallel chunks
+                          (default: unlimited, env: TRANSCRIPT_RATE_LIMIT_REQUESTS)
+  rate-limit-audio        Seconds of audio transcribed per minute, shared by parallel
+                          chunks (default: unlimited, env: TRANSCRIPT_RATE_LIMIT_AUDIO)
+  notify-webhook          URL receiving a JSON notification when a transcription
+                          finishes, like --notify-webhook (env: TRANSCRIPT_NOTIFY_WEBHOOK)`,
 		Example: `  transcript config set output-dir ~/Documents/transcripts
+  transcript config set prompt-token-warning 50000
+  transcript config set transcriber local
+  transcript config set whisper-model ~/models/ggml-base.en.bin
+  transcript config set azure-endpoint https://contoso.openai.azure.com
   transcript config get output-dir
-  transcript config list`,
+  transcript config list
+  transcript config set --profile meetings template meeting
+  transcript config list-profiles
+  transcript config path
+  transcript config validate
+  transcript config doctor
+  transcript config set-key --provider openai
+  transcript --config ./work.conf config list`,
 	}
 
 	cmd.AddCommand(configSetCmd(env))
 	cmd.AddCommand(configGetCmd(env))
 	cmd.AddCommand(configListCmd(env))
+	cmd.AddCommand(configListProfilesCmd(env))
+	cmd.AddCommand(configPathCmd(env))
+	cmd.AddCommand(configValidateCmd(env))
+	cmd.AddCommand(configDoctorCmd(env))
+	cmd.AddCommand(configSetKeyCmd(env))
 
 	return cmd
 }
 
 // configSetCmd creates the "config set" subcommand.
 func configSetCmd(env *Env) *cobra.Command {
-	return &cobra.Command{
+	var profile string
+
+	cmd := &cobra.Command{
 		Use:   "set <key> <value>",
 		Short: "Set a configuration value",
 		Long: `Set a configuration value.
 
 Supported keys:
-  output-dir    Default directory for output files
-
-The directory will be created if it doesn't exist.`,
+  output-dir              Default directory for output files
+  output-name             Pattern of default output names, e.g. {{date}}_{{input_stem}}.md
+  front-matter            YAML front matter at the top of Markdown outputs: true or false
+  obsidian-vault          Obsidian vault outputs are written into (--obsidian-vault)
+  obsidian-folder         Folder of the vault outputs are written to (default: Transcripts)
+  prompt-token-warning    Prompt size (tokens) above which a restructure call warns
+  transcriber             Transcription backend: openai, local, grpc, groq,
+                          deepgram or assemblyai
+  whisper-model           whisper.cpp model file for local transcription
+  whisper-bin             whisper.cpp binary
+  whisper-url             OpenAI-compatible local whisper server URL
+  tags-dir                Directory of the vocabulary recorded for each --tag
+  intro-library           File of the intros and outros of earlier recordings
+  glossary                File of terms put in the prompts (--glossary)
+  clean-fillers           Extra fillers removed by --clean, e.g. fr=du coup,en=basically
+  redact-patterns         File of regular expressions also masked by --redact
+  ollama-url              Ollama server URL (--provider ollama)
+  ollama-model            Ollama model (--provider ollama)
+  templates-dir           Directory of the user templates (--template)
+  restructure-model       Model of the restructure provider (--restructure-model)
+  context-windows         Model context windows, e.g. my-model=32000,gpt-4o=128000
+  ca-bundle               PEM file of extra trusted certificate authorities
+  client-cert             PEM client certificate (mutual TLS)
+  client-key              PEM private key of client-cert
+  ffmpeg-path             ffmpeg binary used instead of the downloaded one
+  rate-limit-requests     Transcription requests per minute (all chunks together)
+  rate-limit-audio        Seconds of audio transcribed per minute (all chunks together)
+  notify-webhook          URL notified when a transcription finishes (--notify-webhook)
+
+With --profile, sets a default of the profile instead:
+  template                Restructure template (--template)
+  language                Audio language (--language)
+  provider                LLM provider for restructuring (--provider)
+  diarize                 Speaker identification: true or false (--diarize)
+  parallel                Max concurrent API requests, or auto (--parallel)
+  output-dir              Directory for output files, instead of output-dir
+
+The output directory will be created if it doesn't exist.`,
 		Example: `  transcript config set output-dir ~/Documents/transcripts
-  transcript config set output-dir /tmp/recordings`,
+  transcript config set output-dir /tmp/recordings
+  transcript config set output-name '{{date}}_{{template}}_{{input_stem}}.md'
+  transcript config set front-matter true
+  transcript config set prompt-token-warning 50000
+  transcript config set context-windows qwen2.5:14b=32768
+  transcript config set ca-bundle ~/certs/corporate-ca.pem
+  transcript config set --profile meetings template meeting
+  transcript config set --profile meetings diarize true
+  transcript config set --profile lectures output-dir ~/Documents/lectures`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			key, value := args[0], args[1]
+			if profile != "" {
+				return runConfigSetProfile(env, profile, key, value)
+			}
 			return runConfigSet(env, key, value)
 		},
 	}
+
+	cmd.Flags().StringVar(&profile, "profile", "", "Set a default of this profile, created if needed")
+
+	return cmd
 }
 
 // configGetCmd creates the "config get" subcommand.
 func configGetCmd(env *Env) *cobra.Command {
-	return &cobra.Command{
+	var profile string
+
+	cmd := &cobra.Command{
 		Use:   "get <key>",
 		Short: "Get a configuration value",
 		Long: `Get a configuration value.
 
 Prints the value to stdout, or nothing if not set.`,
-		Example: `  transcript config get output-dir`,
-		Args:    cobra.ExactArgs(1),
+		Example: `  transcript config get output-dir
+  transcript config get --profile meetings template`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if profile != "" {
+				return runConfigGetProfile(cmd.OutOrStdout(), profile, args[0])
+			}
 			return runConfigGet(env, args[0])
 		},
 	}
+
+	cmd.Flags().StringVar(&profile, "profile", "", "Get a default of this profile")
+
+	return cmd
 }
 
 // configListCmd creates the "config list" subcommand.
@@ -92,6 +370,43 @@ Shows both values from the config file and environment variable overrides.`,
 	}
 }
 
+// configListProfilesCmd creates the "config list-profiles" subcommand.
+func configListProfilesCmd(env *Env) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list-profiles",
+		Short: "List the config profiles",
+		Long: `List the profiles of the config file, with the defaults each sets.
+
+The profile selected by TRANSCRIPT_PROFILE is marked active.`,
+		Example: `  transcript config list-profiles`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigListProfiles(cmd.OutOrStdout(), env)
+		},
+	}
+}
+
+// configPathCmd creates the "config path" subcommand.
+func configPathCmd(env *Env) *cobra.Command {
+	return &cobra.Command{
+		Use:   "path",
+		Short: "Show where configuration and state are stored",
+		Long: `Show where configuration and state are stored: the config file, the user
+templates, the vocabulary of session tags, the intro library, the FFmpeg
+download, and the project file applying to the working directory, if any.
+
+Locations follow the XDG base directories on Linux and BSDs
+(XDG_CONFIG_HOME, XDG_STATE_HOME, XDG_CACHE_HOME), and platform equivalents
+on macOS (~/Library) and Windows (%APPDATA%, %LOCALAPPDATA%). Files written
+by earlier versions to ~/.config/go-transcript keep being used.`,
+		Example: `  transcript config path`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigPath(cmd.OutOrStdout(), env)
+		},
+	}
+}
+
 // runConfigSet handles the "config set" command.
 func runConfigSet(env *Env, key, value string) error {
 	// Validate key.
@@ -99,16 +414,14 @@ func runConfigSet(env *Env, key, value string) error {
 		return fmt.Errorf("unknown config key %q (valid keys: %v)", key, validConfigKeys)
 	}
 
-	// Key-specific validation.
-	switch key {
-	case config.KeyOutputDir:
-		// Expand ~ and validate directory.
-		expanded := config.ExpandPath(value)
-		if err := config.EnsureOutputDir(expanded); err != nil {
+	value, err := validateConfigValue(key, value)
+	if err != nil {
+		return err
+	}
+	if key == config.KeyOutputDir {
+		if err := config.EnsureOutputDir(value); err != nil {
 			return fmt.Errorf("invalid output-dir: %w", err)
 		}
-		// Store the expanded path for consistency.
-		value = expanded
 	}
 
 	// Save to config file.
@@ -120,6 +433,144 @@ func runConfigSet(env *Env, key, value string) error {
 	return nil
 }
 
+// validateConfigValue checks the value of a configuration key, and returns it
+// as saved (paths expanded). It does not create output-dir.
+func validateConfigValue(key, value string) (string, error) {
+	switch key {
+	case config.KeyOutputDir, config.KeyWhisperModel, config.KeyWhisperBin, config.KeyTemplatesDir,
+		config.KeyClientCert, config.KeyClientKey, config.KeyFFmpegPath:
+		value = config.ExpandPath(value)
+	case config.KeyGlossary:
+		value = config.ExpandPath(value)
+		if _, err := vocab.ReadGlossary(value); err != nil {
+			return "", err
+		}
+	case config.KeyRedactPatterns:
+		value = config.ExpandPath(value)
+		patterns, err := redact.ReadPatterns(value)
+		if err != nil {
+			return "", err
+		}
+		if _, err := redact.New(lang.Language{}, patterns); err != nil {
+			return "", err
+		}
+	case config.KeyPromptTokenWarning:
+		if _, err := config.ParsePromptTokenWarning(value); err != nil {
+			return "", err
+		}
+	case config.KeyTranscriber:
+		if _, err := ParseBackend(value); err != nil {
+			return "", err
+		}
+	case config.KeyGRPCEndpoint:
+		if _, err := config.ParseGRPCEndpoint(value); err != nil {
+			return "", err
+		}
+	case config.KeyOutputName:
+		if _, err := notes.ParseName(value); err != nil {
+			return "", err
+		}
+	case config.KeyObsidianVault:
+		value = config.ExpandPath(value)
+		if _, err := obsidian.Open(value, ""); err != nil {
+			return "", err
+		}
+	case config.KeyObsidianFolder:
+		if err := obsidian.CheckFolder(value); err != nil {
+			return "", err
+		}
+	case config.KeyFrontMatter:
+		if _, err := config.ParseFrontMatter(value); err != nil {
+			return "", err
+		}
+	case config.KeyGRPCPlaintext:
+		if _, err := config.ParseGRPCPlaintext(value); err != nil {
+			return "", err
+		}
+	case config.KeyGRPCTimeout:
+		if _, err := config.ParseGRPCTimeout(value); err != nil {
+			return "", err
+		}
+	case config.KeyAzureEndpoint:
+		return config.ParseAzureEndpoint(value)
+	case config.KeyContextWindows:
+		if _, err := config.ParseContextWindows(value); err != nil {
+			return "", err
+		}
+	case config.KeyCleanFillers:
+		if _, err := config.ParseCleanFillers(value); err != nil {
+			return "", err
+		}
+	case config.KeyRestructureSplit:
+		if _, err := restructure.ParseSplit(value); err != nil {
+			return "", err
+		}
+	case config.KeyRestructureChunk:
+		if _, err := config.ParseRestructureChunkTokens(value); err != nil {
+			return "", err
+		}
+	case config.KeyRestructureOverlap:
+		if _, err := config.ParseRestructureOverlap(value); err != nil {
+			return "", err
+		}
+	case config.KeyDefaultCommand:
+		if err := validateDefaultCommand(value); err != nil {
+			return "", err
+		}
+	case config.KeyRateLimitRequests, config.KeyRateLimitAudio:
+		if _, err := config.ParseRateLimit(key, value); err != nil {
+			return "", err
+		}
+	case config.KeyNotifyWebhook:
+		if _, err := config.ParseWebhookURL(value); err != nil {
+			return "", err
+		}
+	case config.KeyCABundle:
+		value = config.ExpandPath(value)
+		if _, err := (tlsconfig.Options{CABundle: value}).Config(); err != nil {
+			return "", err
+		}
+	}
+	return value, nil
+}
+
+// runConfigSetProfile handles the "config set --profile" command.
+func runConfigSetProfile(env *Env, profile, key, value string) error {
+	if err := config.ValidateProfileName(profile); err != nil {
+		return err
+	}
+	value, err := validateProfileSetting(env, key, value)
+	if err != nil {
+		return err
+	}
+
+	if err := config.Save(config.ProfileKey(profile, key), value); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stderr, "Set %s = %s in profile %s\n", key, value, profile)
+	return nil
+}
+
+// runConfigGetProfile handles the "config get --profile" command.
+func runConfigGetProfile(w io.Writer, profile, key string) error {
+	if err := config.ValidateProfileName(profile); err != nil {
+		return err
+	}
+	if err := validateProfileKey(key); err != nil {
+		return err
+	}
+
+	value, err := config.Get(config.ProfileKey(profile, key))
+	if err != nil {
+		return err
+	}
+	if value != "" {
+		fmt.Fprintln(w, value)
+	}
+	return nil
+}
+
 // runConfigGet handles the "config get" command.
 func runConfigGet(env *Env, key string) error {
 	// Validate key.
@@ -134,10 +585,7 @@ func runConfigGet(env *Env, key string) error {
 
 	// Check environment variable fallback.
 	if value == "" {
-		switch key {
-		case config.KeyOutputDir:
-			value = env.Getenv(config.EnvOutputDir)
-		}
+		value = env.Getenv(configEnvVars[key])
 	}
 
 	if value != "" {
@@ -155,9 +603,11 @@ func runConfigList(env *Env) error {
 	}
 
 	// Add environment variable values for completeness.
-	if _, ok := data[config.KeyOutputDir]; !ok {
-		if envVal := env.Getenv(config.EnvOutputDir); envVal != "" {
-			data[config.KeyOutputDir] = envVal + " (from env)"
+	for _, key := range validConfigKeys {
+		if _, ok := data[key]; !ok {
+			if envVal := env.Getenv(configEnvVars[key]); envVal != "" {
+				data[key] = envVal + " (from env)"
+			}
 		}
 	}
 
@@ -184,6 +634,41 @@ func runConfigList(env *Env) error {
 	return nil
 }
 
+// runConfigPath writes the resolved locations of configuration and state to w.
+func runConfigPath(w io.Writer, env *Env) error {
+	file, err := config.Path()
+	if err != nil {
+		return err
+	}
+	cfg, err := env.ConfigLoader.Load()
+	if err != nil {
+		return err
+	}
+	ffmpegDir := cfg.FFmpegPath
+	if ffmpegDir == "" {
+		ffmpegDir = env.Getenv("FFMPEG_PATH")
+	}
+	if ffmpegDir == "" {
+		if ffmpegDir, err = ffmpeg.InstallDir(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(file); err != nil {
+		file += " (not created yet)"
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "config file\t%s\n", file)
+	fmt.Fprintf(tw, "templates\t%s\n", cfg.TemplatesDir)
+	fmt.Fprintf(tw, "tags\t%s\n", cfg.TagsDir)
+	fmt.Fprintf(tw, "intro library\t%s\n", cfg.IntroLibrary)
+	fmt.Fprintf(tw, "ffmpeg\t%s\n", ffmpegDir)
+	if cfg.Project != "" {
+		fmt.Fprintf(tw, "project file\t%s\n", cfg.Project)
+	}
+	return tw.Flush()
+}
+
 // isValidConfigKey checks if a key is a valid configuration key.
 func isValidConfigKey(key string) bool {
 	return slices.Contains(validConfigKeys, key)
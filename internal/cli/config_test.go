@@ -1,12 +1,14 @@
 package cli
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/restructure"
 )
 
 // ---------------------------------------------------------------------------
@@ -22,6 +24,7 @@ func TestIsValidConfigKey(t *testing.T) {
 		expected bool
 	}{
 		{"valid output dir", config.KeyOutputDir, true},
+		{"valid prompt token warning", config.KeyPromptTokenWarning, true},
 		{"invalid random key", "random-key", false},
 		{"empty string", "", false},
 		{"wrong format with underscore", "output_dir", false}, // Wrong format (underscore vs dash)
@@ -168,6 +171,408 @@ func TestRunConfigSet_InvalidOutputDir(t *testing.T) {
 	}
 }
 
+func TestRunConfigSet_Transcriber(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"openai", "openai", false},
+		{"local", "local", false},
+		{"unknown", "cloud", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", tempDir)
+
+			env := &Env{
+				Stderr: &syncBuffer{},
+				Getenv: os.Getenv,
+			}
+
+			err := RunConfigSet(env, config.KeyTranscriber, tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidBackend) {
+					t.Errorf("RunConfigSet(%q, %q) error = %v, want ErrInvalidBackend", config.KeyTranscriber, tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunConfigSet(%q, %q) unexpected error: %v", config.KeyTranscriber, tt.value, err)
+			}
+
+			got, err := config.Get(config.KeyTranscriber)
+			if err != nil {
+				t.Fatalf("config.Get() unexpected error: %v", err)
+			}
+			if got != tt.value {
+				t.Errorf("config.Get(%q) = %q, want %q", config.KeyTranscriber, got, tt.value)
+			}
+		})
+	}
+}
+
+func TestRunConfigSet_PromptTokenWarning(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"positive integer", "50000", false},
+		{"zero", "0", true},
+		{"negative", "-1", true},
+		{"not a number", "lots", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", tempDir)
+
+			env := &Env{
+				Stderr: &syncBuffer{},
+				Getenv: os.Getenv,
+			}
+
+			err := RunConfigSet(env, config.KeyPromptTokenWarning, tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, config.ErrInvalidValue) {
+					t.Errorf("RunConfigSet(%q, %q) error = %v, want ErrInvalidValue", config.KeyPromptTokenWarning, tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunConfigSet(%q, %q) unexpected error: %v", config.KeyPromptTokenWarning, tt.value, err)
+			}
+
+			got, err := config.Get(config.KeyPromptTokenWarning)
+			if err != nil {
+				t.Fatalf("config.Get() unexpected error: %v", err)
+			}
+			if got != tt.value {
+				t.Errorf("config.Get(%q) = %q, want %q", config.KeyPromptTokenWarning, got, tt.value)
+			}
+		})
+	}
+}
+
+func TestRunConfigSet_ContextWindows(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"single model", "qwen2.5:14b=32768", false},
+		{"several models", "my-model=32000,gpt-4o=128000", false},
+		{"missing tokens", "my-model", true},
+		{"not a number", "my-model=32K", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", tempDir)
+
+			env := &Env{
+				Stderr: &syncBuffer{},
+				Getenv: os.Getenv,
+			}
+
+			err := RunConfigSet(env, config.KeyContextWindows, tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, config.ErrInvalidValue) {
+					t.Errorf("RunConfigSet(%q, %q) error = %v, want ErrInvalidValue", config.KeyContextWindows, tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunConfigSet(%q, %q) unexpected error: %v", config.KeyContextWindows, tt.value, err)
+			}
+
+			got, err := config.Get(config.KeyContextWindows)
+			if err != nil {
+				t.Fatalf("config.Get() unexpected error: %v", err)
+			}
+			if got != tt.value {
+				t.Errorf("config.Get(%q) = %q, want %q", config.KeyContextWindows, got, tt.value)
+			}
+		})
+	}
+}
+
+func TestRunConfigSet_RestructureSplit(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"paragraphs", "paragraphs", false},
+		{"speakers", "speakers", false},
+		{"unknown", "turns", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", tempDir)
+
+			env := &Env{
+				Stderr: &syncBuffer{},
+				Getenv: os.Getenv,
+			}
+
+			err := RunConfigSet(env, config.KeyRestructureSplit, tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, restructure.ErrUnknownSplit) {
+					t.Errorf("RunConfigSet(%q, %q) error = %v, want ErrUnknownSplit", config.KeyRestructureSplit, tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunConfigSet(%q, %q) unexpected error: %v", config.KeyRestructureSplit, tt.value, err)
+			}
+		})
+	}
+}
+
+func TestRunConfigSet_DefaultCommand(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"transcribe", "transcribe", false},
+		{"structure", "structure", false},
+		{"not an input command", "live", true},
+		{"alias", "t", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", tempDir)
+
+			env := &Env{
+				Stderr: &syncBuffer{},
+				Getenv: os.Getenv,
+			}
+
+			err := RunConfigSet(env, config.KeyDefaultCommand, tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, config.ErrInvalidValue) {
+					t.Errorf("RunConfigSet(%q, %q) error = %v, want ErrInvalidValue", config.KeyDefaultCommand, tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunConfigSet(%q, %q) unexpected error: %v", config.KeyDefaultCommand, tt.value, err)
+			}
+		})
+	}
+}
+
+func TestRunConfigSet_RateLimit(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"requests per minute", config.KeyRateLimitRequests, "50", false},
+		{"audio seconds per minute", config.KeyRateLimitAudio, "7200", false},
+		{"zero", config.KeyRateLimitRequests, "0", true},
+		{"duration", config.KeyRateLimitAudio, "2h", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", tempDir)
+
+			env := &Env{
+				Stderr: &syncBuffer{},
+				Getenv: os.Getenv,
+			}
+
+			err := RunConfigSet(env, tt.key, tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, config.ErrInvalidValue) {
+					t.Errorf("RunConfigSet(%q, %q) error = %v, want ErrInvalidValue", tt.key, tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunConfigSet(%q, %q) unexpected error: %v", tt.key, tt.value, err)
+			}
+
+			got, err := config.Get(tt.key)
+			if err != nil {
+				t.Fatalf("config.Get() unexpected error: %v", err)
+			}
+			if got != tt.value {
+				t.Errorf("config.Get(%q) = %q, want %q", tt.key, got, tt.value)
+			}
+		})
+	}
+}
+
+func TestRunConfigSet_GRPC(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"endpoint", config.KeyGRPCEndpoint, "asr.internal:50051", false},
+		{"plaintext", config.KeyGRPCPlaintext, "true", false},
+		{"timeout", config.KeyGRPCTimeout, "90s", false},
+		{"endpoint without port", config.KeyGRPCEndpoint, "asr.internal", true},
+		{"plaintext not a boolean", config.KeyGRPCPlaintext, "sometimes", true},
+		{"timeout without unit", config.KeyGRPCTimeout, "90", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", tempDir)
+
+			env := &Env{
+				Stderr: &syncBuffer{},
+				Getenv: os.Getenv,
+			}
+
+			err := RunConfigSet(env, tt.key, tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, config.ErrInvalidValue) {
+					t.Errorf("RunConfigSet(%q, %q) error = %v, want ErrInvalidValue", tt.key, tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunConfigSet(%q, %q) unexpected error: %v", tt.key, tt.value, err)
+			}
+
+			got, err := config.Get(tt.key)
+			if err != nil {
+				t.Fatalf("config.Get() unexpected error: %v", err)
+			}
+			if got != tt.value {
+				t.Errorf("config.Get(%q) = %q, want %q", tt.key, got, tt.value)
+			}
+		})
+	}
+}
+
+func TestRunConfigSet_Azure(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"endpoint", config.KeyAzureEndpoint, "https://contoso.openai.azure.com/", "https://contoso.openai.azure.com", false},
+		{"transcribe deployment", config.KeyAzureTranscribe, "whisper", "whisper", false},
+		{"chat deployment", config.KeyAzureChat, "gpt-4o", "gpt-4o", false},
+		{"api version", config.KeyAzureAPIVersion, "2025-03-01-preview", "2025-03-01-preview", false},
+		{"endpoint without scheme", config.KeyAzureEndpoint, "contoso.openai.azure.com", "", true},
+		{"endpoint with deployment path", config.KeyAzureEndpoint, "https://contoso.openai.azure.com/openai/deployments/gpt-4o", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", tempDir)
+
+			env := &Env{
+				Stderr: &syncBuffer{},
+				Getenv: os.Getenv,
+			}
+
+			err := RunConfigSet(env, tt.key, tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, config.ErrInvalidValue) {
+					t.Errorf("RunConfigSet(%q, %q) error = %v, want ErrInvalidValue", tt.key, tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunConfigSet(%q, %q) unexpected error: %v", tt.key, tt.value, err)
+			}
+
+			got, err := config.Get(tt.key)
+			if err != nil {
+				t.Fatalf("config.Get() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("config.Get(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunConfigSet_NotifyWebhook(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"https URL", "https://hooks.example.com/run", false},
+		{"missing scheme", "hooks.example.com/run", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", tempDir)
+
+			env := &Env{
+				Stderr: &syncBuffer{},
+				Getenv: os.Getenv,
+			}
+
+			err := RunConfigSet(env, config.KeyNotifyWebhook, tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, config.ErrInvalidValue) {
+					t.Errorf("RunConfigSet(%q, %q) error = %v, want ErrInvalidValue", config.KeyNotifyWebhook, tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunConfigSet(%q, %q) unexpected error: %v", config.KeyNotifyWebhook, tt.value, err)
+			}
+
+			got, err := config.Get(config.KeyNotifyWebhook)
+			if err != nil {
+				t.Fatalf("config.Get() unexpected error: %v", err)
+			}
+			if got != tt.value {
+				t.Errorf("config.Get(%q) = %q, want %q", config.KeyNotifyWebhook, got, tt.value)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Tests for runConfigGet
 // ---------------------------------------------------------------------------
@@ -302,6 +707,72 @@ func TestRunConfigList_WithEnvOverride(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Tests for runConfigPath
+// ---------------------------------------------------------------------------
+
+func TestRunConfigPath(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tempDir, "config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(tempDir, "state"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(tempDir, "cache"))
+	t.Setenv(config.EnvConfigFile, "")
+
+	env := &Env{
+		Getenv:       func(string) string { return "" },
+		ConfigLoader: defaultConfigLoader{},
+	}
+
+	var out strings.Builder
+	if err := RunConfigPath(&out, env); err != nil {
+		t.Fatalf("RunConfigPath() unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		filepath.Join(tempDir, "config", "go-transcript", "config") + " (not created yet)",
+		filepath.Join(tempDir, "config", "go-transcript", "templates"),
+		filepath.Join(tempDir, "state", "go-transcript", "tags"),
+		filepath.Join(tempDir, "state", "go-transcript", "intros.json"),
+		filepath.Join(tempDir, "cache", "go-transcript", "bin"),
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("RunConfigPath() output = %q, want containing %q", out.String(), want)
+		}
+	}
+}
+
+func TestRunConfigPath_CustomConfigFile(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	tempDir := t.TempDir()
+	file := filepath.Join(tempDir, "work.conf")
+	t.Setenv(config.EnvConfigFile, file)
+	if err := config.Save(config.KeyOutputDir, tempDir); err != nil {
+		t.Fatalf("config.Save() unexpected error: %v", err)
+	}
+
+	env := &Env{
+		Getenv:       staticEnv(map[string]string{"FFMPEG_PATH": "/opt/ffmpeg"}),
+		ConfigLoader: defaultConfigLoader{},
+	}
+
+	var out strings.Builder
+	if err := RunConfigPath(&out, env); err != nil {
+		t.Fatalf("RunConfigPath() unexpected error: %v", err)
+	}
+
+	if strings.Contains(out.String(), "not created yet") {
+		t.Errorf("RunConfigPath() output = %q, want the config file existing", out.String())
+	}
+	for _, want := range []string{file, filepath.Join(tempDir, "templates"), "/opt/ffmpeg"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("RunConfigPath() output = %q, want containing %q", out.String(), want)
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Tests for ConfigCmd (Cobra integration)
 // ---------------------------------------------------------------------------
@@ -318,7 +789,7 @@ func TestConfigCmd_HasSubcommands(t *testing.T) {
 		subcommands[sub.Name()] = true
 	}
 
-	expected := []string{"set", "get", "list"}
+	expected := []string{"set", "get", "list", "list-profiles", "path", "validate", "doctor", "set-key"}
 	for _, name := range expected {
 		if !subcommands[name] {
 			t.Errorf("expected subcommand %q", name)
@@ -384,3 +855,127 @@ func TestConfigCmd_ListNoArgs(t *testing.T) {
 		t.Fatalf("ConfigCmd.Execute() with args [\"list\"] unexpected error: %v", err)
 	}
 }
+
+func TestRunConfigSet_CABundle(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	env := &Env{Stderr: &syncBuffer{}, Getenv: os.Getenv}
+
+	notPEM := filepath.Join(tempDir, "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := RunConfigSet(env, config.KeyCABundle, notPEM); err == nil {
+		t.Errorf("RunConfigSet(%q, %q) error = nil, want error for a file without certificates", config.KeyCABundle, notPEM)
+	}
+	if got, _ := config.Get(config.KeyCABundle); got != "" {
+		t.Errorf("config.Get(%q) = %q, want unset after invalid value", config.KeyCABundle, got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests for profiles
+// ---------------------------------------------------------------------------
+
+func TestRunConfigSetProfile(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv(config.EnvConfigFile, "")
+	t.Setenv(config.EnvProfile, "")
+	stderr := &syncBuffer{}
+	env := &Env{Stderr: stderr, Getenv: os.Getenv, ConfigLoader: defaultConfigLoader{}}
+
+	for _, kv := range [][2]string{
+		{config.KeyTemplate, "meeting"},
+		{config.KeyDiarize, "true"},
+	} {
+		if err := RunConfigSetProfile(env, "meetings", kv[0], kv[1]); err != nil {
+			t.Fatalf("RunConfigSetProfile(meetings, %q, %q) unexpected error: %v", kv[0], kv[1], err)
+		}
+	}
+	if !strings.Contains(stderr.String(), "Set diarize = true in profile meetings") {
+		t.Errorf("RunConfigSetProfile() output = %q, want naming the profile", stderr.String())
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() unexpected error: %v", err)
+	}
+	p, err := cfg.LookupProfile("meetings")
+	if err != nil || p.Template != "meeting" || p.Diarize == nil || !*p.Diarize {
+		t.Errorf("profile meetings = %+v, %v, want the settings saved", p, err)
+	}
+
+	var out strings.Builder
+	if err := RunConfigGetProfile(&out, "meetings", config.KeyTemplate); err != nil || out.String() != "meeting\n" {
+		t.Errorf("RunConfigGetProfile() = %q, %v, want meeting", out.String(), err)
+	}
+}
+
+func TestRunConfigSetProfile_Invalid(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tempDir)
+	t.Setenv(config.EnvConfigFile, "")
+	env := &Env{Stderr: &syncBuffer{}, Getenv: os.Getenv, ConfigLoader: defaultConfigLoader{}}
+
+	tests := []struct {
+		name, profile, key, value string
+		wantErr                   error
+	}{
+		{name: "invalid profile name", profile: "Team Meetings", key: config.KeyTemplate, value: "meeting", wantErr: config.ErrInvalidKey},
+		{name: "unknown key", profile: "meetings", key: config.KeyTranscriber, value: "local", wantErr: config.ErrInvalidKey},
+		{name: "invalid value", profile: "meetings", key: config.KeyDiarize, value: "yes please", wantErr: config.ErrInvalidValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RunConfigSetProfile(env, tt.profile, tt.key, tt.value); !errors.Is(err, tt.wantErr) {
+				t.Errorf("RunConfigSetProfile() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if data, _ := config.List(); len(data) != 0 {
+		t.Errorf("config.List() = %v, want nothing saved", data)
+	}
+}
+
+func TestRunConfigListProfiles(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lists the settings and marks the active profile", func(t *testing.T) {
+		t.Parallel()
+
+		env, mocks := testEnv()
+		mocks.configLoader.LoadFunc = profileConfigLoader("meetings", "/notes/lectures").LoadFunc
+
+		var out strings.Builder
+		if err := RunConfigListProfiles(&out, env); err != nil {
+			t.Fatalf("RunConfigListProfiles() unexpected error: %v", err)
+		}
+		want := "lectures           language=fr parallel=auto output-dir=/notes/lectures\n" +
+			"meetings (active)  template=meeting provider=openai diarize=true\n"
+		if out.String() != want {
+			t.Errorf("RunConfigListProfiles() output = %q, want %q", out.String(), want)
+		}
+	})
+
+	t.Run("no profiles", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		var out strings.Builder
+		if err := RunConfigListProfiles(&out, env); err != nil {
+			t.Fatalf("RunConfigListProfiles() unexpected error: %v", err)
+		}
+		if !strings.Contains(out.String(), "No profiles defined") {
+			t.Errorf("RunConfigListProfiles() output = %q, want saying there are none", out.String())
+		}
+	})
+}
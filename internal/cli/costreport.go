@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/format"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// runReport is the actual API usage of a transcribe, live or structure run.
+// Transcription usage is recorded by the chunk functions (see
+// transcribe.WithUsageTracker), restructuring usage by restructureContent.
+// A nil *runReport records nothing.
+type runReport struct {
+	command string
+	input   string // Empty for live
+	output  string
+
+	transcription      *transcribe.UsageTracker // nil if the run does not transcribe
+	transcriptionModel string                   // Empty for the local and gRPC backends
+
+	restructureModel string // Empty if the run did not restructure
+	restructureLocal bool
+	restructure      restructure.Usage
+	restructureCalls int
+}
+
+// newRunReport creates the report of a run that does not transcribe (structure).
+func newRunReport(command, input, output string) *runReport {
+	return &runReport{command: command, input: input, output: output}
+}
+
+// newTranscribeReport creates the report of a run transcribing with backend and opts.
+func newTranscribeReport(command, input, output string, backend Backend, opts transcribe.Options) *runReport {
+	r := newRunReport(command, input, output)
+	r.transcription = transcribe.NewUsageTracker()
+	r.transcriptionModel = backend.transcriptionModel(opts)
+	return r
+}
+
+// recordRestructure stores the usage of restructuring with model.
+func (r *runReport) recordRestructure(model string, local bool, usage *restructure.UsageTracker) {
+	if r == nil {
+		return
+	}
+	r.restructureModel = model
+	r.restructureLocal = local
+	r.restructure = usage.Total()
+	r.restructureCalls = usage.Calls()
+}
+
+// transcriptionCost returns the cost of transcription, in US dollars.
+// known is false if the model has no known price.
+func (r *runReport) transcriptionCost() (cost float64, known bool) {
+	if r.transcriptionModel == "" {
+		return 0, true // Local backend
+	}
+	price, ok := transcribe.PricePerMinute(r.transcriptionModel)
+	if !ok {
+		return 0, false
+	}
+	return r.transcription.Total().Audio.Minutes() * price, true
+}
+
+// restructureCost returns the cost of restructuring, in US dollars.
+// known is false if the model has no known price.
+func (r *runReport) restructureCost() (cost float64, known bool) {
+	if r.restructureModel == "" || r.restructureLocal {
+		return 0, true
+	}
+	price, ok := restructure.LookupPrice(r.restructureModel)
+	if !ok {
+		return 0, false
+	}
+	return price.Cost(r.restructure), true
+}
+
+// finishRunReport prints the cost summary of r to stderr and appends it to
+// path, if set. Failing to append only warns: the output is already written.
+func finishRunReport(env *Env, r *runReport, path string) {
+	if r == nil {
+		return
+	}
+	printCostSummary(env.Stderr, r)
+	if path == "" {
+		return
+	}
+	if err := appendCostReport(path, env.Now(), r); err != nil {
+		warnf(env.Stderr, warnFileNotSaved, "failed to write cost report: %v", err)
+	}
+}
+
+// printCostSummary writes the usage and cost of r.
+// Prints nothing if the run neither transcribed nor restructured.
+func printCostSummary(w io.Writer, r *runReport) {
+	if r.transcription == nil && r.restructureModel == "" {
+		return
+	}
+	transcriptionCost, transcriptionKnown := r.transcriptionCost()
+	restructureCost, restructureKnown := r.restructureCost()
+
+	total := fmt.Sprintf("Cost: ~$%.2f", transcriptionCost+restructureCost)
+	if !transcriptionKnown || !restructureKnown {
+		total += " (excluding models with unknown prices)"
+	}
+	fmt.Fprintln(w, total)
+
+	if r.transcription != nil {
+		usage := r.transcription.Total()
+		line := fmt.Sprintf("  Transcription: %s, %s of audio", r.transcriptionModel, format.DurationHuman(usage.Audio))
+		if r.transcriptionModel == "" {
+			line = fmt.Sprintf("  Transcription: local backend, %s of audio", format.DurationHuman(usage.Audio))
+		}
+		if usage.InputTokens > 0 || usage.OutputTokens > 0 {
+			line += fmt.Sprintf(", %d input + %d output tokens", usage.InputTokens, usage.OutputTokens)
+		}
+		fmt.Fprintln(w, line+costSuffix(transcriptionCost, transcriptionKnown, r.transcriptionModel == ""))
+	}
+
+	if r.restructureModel != "" {
+		fmt.Fprintf(w, "  Restructuring: %s, %d prompt + %d completion tokens%s\n",
+			r.restructureModel, r.restructure.PromptTokens, r.restructure.CompletionTokens,
+			costSuffix(restructureCost, restructureKnown, r.restructureLocal))
+	}
+}
+
+// costSuffix formats the cost of a summary line.
+func costSuffix(cost float64, known, local bool) string {
+	switch {
+	case local:
+		return ", free"
+	case known:
+		return fmt.Sprintf(", ~$%.2f", cost)
+	default:
+		return ", price unknown"
+	}
+}
+
+// costRecord is a line of a cost report. Costs of models with unknown
+// prices are zero. Field order is the CSV column order.
+type costRecord struct {
+	Time                      string  `json:"time"`
+	Command                   string  `json:"command"`
+	Input                     string  `json:"input"`
+	Output                    string  `json:"output"`
+	TranscriptionModel        string  `json:"transcription_model"`
+	AudioSeconds              float64 `json:"audio_seconds"`
+	TranscriptionInputTokens  int     `json:"transcription_input_tokens"`
+	TranscriptionOutputTokens int     `json:"transcription_output_tokens"`
+	TranscriptionCost         float64 `json:"transcription_cost_usd"`
+	RestructureModel          string  `json:"restructure_model"`
+	RestructureCalls          int     `json:"restructure_calls"`
+	PromptTokens              int     `json:"prompt_tokens"`
+	CompletionTokens          int     `json:"completion_tokens"`
+	RestructureCost           float64 `json:"restructure_cost_usd"`
+	TotalCost                 float64 `json:"total_cost_usd"`
+}
+
+// costReportHeader is the header of CSV cost reports.
+var costReportHeader = []string{
+	"time", "command", "input", "output",
+	"transcription_model", "audio_seconds", "transcription_input_tokens", "transcription_output_tokens", "transcription_cost_usd",
+	"restructure_model", "restructure_calls", "prompt_tokens", "completion_tokens", "restructure_cost_usd",
+	"total_cost_usd",
+}
+
+// newCostRecord returns the cost report line of r, run at now.
+func newCostRecord(now time.Time, r *runReport) costRecord {
+	usage := r.transcription.Total()
+	transcriptionCost, _ := r.transcriptionCost()
+	restructureCost, _ := r.restructureCost()
+	return costRecord{
+		Time:                      now.UTC().Format(time.RFC3339),
+		Command:                   r.command,
+		Input:                     r.input,
+		Output:                    r.output,
+		TranscriptionModel:        r.transcriptionModel,
+		AudioSeconds:              math.Round(usage.Audio.Seconds()*1000) / 1000,
+		TranscriptionInputTokens:  usage.InputTokens,
+		TranscriptionOutputTokens: usage.OutputTokens,
+		TranscriptionCost:         roundCost(transcriptionCost),
+		RestructureModel:          r.restructureModel,
+		RestructureCalls:          r.restructureCalls,
+		PromptTokens:              r.restructure.PromptTokens,
+		CompletionTokens:          r.restructure.CompletionTokens,
+		RestructureCost:           roundCost(restructureCost),
+		TotalCost:                 roundCost(transcriptionCost + restructureCost),
+	}
+}
+
+// roundCost rounds a cost to a millionth of a dollar.
+func roundCost(cost float64) float64 {
+	return math.Round(cost*1e6) / 1e6
+}
+
+// csvRow returns the fields of rec in costReportHeader order.
+func (rec costRecord) csvRow() []string {
+	float := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return []string{
+		rec.Time, rec.Command, rec.Input, rec.Output,
+		rec.TranscriptionModel, float(rec.AudioSeconds),
+		strconv.Itoa(rec.TranscriptionInputTokens), strconv.Itoa(rec.TranscriptionOutputTokens),
+		float(rec.TranscriptionCost),
+		rec.RestructureModel, strconv.Itoa(rec.RestructureCalls),
+		strconv.Itoa(rec.PromptTokens), strconv.Itoa(rec.CompletionTokens),
+		float(rec.RestructureCost), float(rec.TotalCost),
+	}
+}
+
+// costReportMu serializes the appends of concurrent runs (batch mode),
+// so that a new CSV report gets a single header.
+var costReportMu sync.Mutex
+
+// appendCostReport appends the usage of r to the cost report at path:
+// a CSV row if path ends with .csv (with a header in a new file), a JSON line otherwise.
+func appendCostReport(path string, now time.Time, r *runReport) (err error) {
+	costReportMu.Lock()
+	defer costReportMu.Unlock()
+
+	// #nosec G302 G304 -- user-specified report file with standard permissions
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open cost report: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to write cost report: %w", closeErr)
+		}
+	}()
+
+	rec := newCostRecord(now, r)
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return fmt.Errorf("failed to encode cost report: %w", err)
+		}
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			return fmt.Errorf("failed to write cost report: %w", err)
+		}
+		return nil
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("cannot access cost report: %w", err)
+	}
+	w := csv.NewWriter(f)
+	if info.Size() == 0 {
+		_ = w.Write(costReportHeader) // Errors are reported by Flush.
+	}
+	_ = w.Write(rec.csvRow())
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write cost report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// testRunReport returns the report of a run that transcribed 10 minutes with
+// gpt-4o-mini-transcribe and restructured with deepseek-chat.
+func testRunReport() *runReport {
+	r := newTranscribeReport("transcribe", "talk.ogg", "talk.md", Backend{}, transcribe.Options{})
+	r.transcription.Record(transcribe.Usage{Audio: 10 * time.Minute, InputTokens: 500, OutputTokens: 100})
+
+	usage := restructure.NewUsageTracker()
+	usage.Record(restructure.Usage{PromptTokens: 1_000_000, CompletionTokens: 0})
+	usage.Record(restructure.Usage{PromptTokens: 0, CompletionTokens: 1_000_000})
+	r.recordRestructure("deepseek-chat", false, usage)
+	return r
+}
+
+// mustParseBackend parses s or fails the test.
+func mustParseBackend(t *testing.T, s string) Backend {
+	t.Helper()
+	b, err := ParseBackend(s)
+	if err != nil {
+		t.Fatalf("ParseBackend(%q) error = %v", s, err)
+	}
+	return b
+}
+
+func TestPrintCostSummary(t *testing.T) {
+	t.Parallel()
+
+	local := newTranscribeReport("live", "", "notes.md", mustParseBackend(t, BackendLocal), transcribe.Options{})
+	local.transcription.Record(transcribe.Usage{Audio: 5 * time.Minute})
+
+	unknown := newRunReport("structure", "raw.md", "notes.md")
+	unknown.recordRestructure("my-model", false, restructure.NewUsageTracker())
+
+	tests := []struct {
+		name   string
+		report *runReport
+		want   []string
+	}{
+		{
+			name:   "hosted models",
+			report: testRunReport(),
+			want: []string{
+				"Cost: ~$0.73\n",
+				"  Transcription: gpt-4o-mini-transcribe, 10m of audio, 500 input + 100 output tokens, ~$0.03\n",
+				"  Restructuring: deepseek-chat, 1000000 prompt + 1000000 completion tokens, ~$0.70\n",
+			},
+		},
+		{
+			name:   "local backend",
+			report: local,
+			want:   []string{"Cost: ~$0.00\n", "  Transcription: local backend, 5m of audio, free\n"},
+		},
+		{
+			name:   "unknown price",
+			report: unknown,
+			want: []string{
+				"Cost: ~$0.00 (excluding models with unknown prices)\n",
+				"  Restructuring: my-model, 0 prompt + 0 completion tokens, price unknown\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			printCostSummary(&buf, tt.report)
+			if got, want := buf.String(), strings.Join(tt.want, ""); got != want {
+				t.Errorf("printCostSummary() =\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+
+	t.Run("nothing to report", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		printCostSummary(&buf, newRunReport("structure", "raw.md", "notes.md"))
+		if buf.Len() != 0 {
+			t.Errorf("printCostSummary() = %q, want nothing", buf.String())
+		}
+	})
+}
+
+func TestAppendCostReport(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	t.Run("JSON lines", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "costs.jsonl")
+		for range 2 {
+			if err := appendCostReport(path, now, testRunReport()); err != nil {
+				t.Fatalf("appendCostReport() error = %v", err)
+			}
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("report has %d lines, want 2:\n%s", len(lines), data)
+		}
+		var rec costRecord
+		if err := json.Unmarshal([]byte(lines[1]), &rec); err != nil {
+			t.Fatalf("line is not JSON: %v", err)
+		}
+		want := costRecord{
+			Time:                      "2026-03-01T09:30:00Z",
+			Command:                   "transcribe",
+			Input:                     "talk.ogg",
+			Output:                    "talk.md",
+			TranscriptionModel:        transcribe.ModelGPT4oMiniTranscribe,
+			AudioSeconds:              600,
+			TranscriptionInputTokens:  500,
+			TranscriptionOutputTokens: 100,
+			TranscriptionCost:         0.03,
+			RestructureModel:          "deepseek-chat",
+			RestructureCalls:          2,
+			PromptTokens:              1_000_000,
+			CompletionTokens:          1_000_000,
+			RestructureCost:           0.7,
+			TotalCost:                 0.73,
+		}
+		if rec != want {
+			t.Errorf("record = %+v, want %+v", rec, want)
+		}
+	})
+
+	t.Run("CSV with a single header", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "costs.csv")
+		for range 2 {
+			if err := appendCostReport(path, now, testRunReport()); err != nil {
+				t.Fatalf("appendCostReport() error = %v", err)
+			}
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = f.Close() }()
+		rows, err := csv.NewReader(f).ReadAll()
+		if err != nil {
+			t.Fatalf("report is not CSV: %v", err)
+		}
+		if len(rows) != 3 {
+			t.Fatalf("report has %d rows, want header + 2", len(rows))
+		}
+		if got := strings.Join(rows[0], ","); got != strings.Join(costReportHeader, ",") {
+			t.Errorf("header = %s", got)
+		}
+		want := "2026-03-01T09:30:00Z,transcribe,talk.ogg,talk.md,gpt-4o-mini-transcribe,600,500,100,0.03,deepseek-chat,2,1000000,1000000,0.7,0.73"
+		if got := strings.Join(rows[2], ","); got != want {
+			t.Errorf("row = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("unwritable path", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "missing", "costs.jsonl")
+		if err := appendCostReport(path, now, testRunReport()); err == nil {
+			t.Error("appendCostReport() expected error, got nil")
+		}
+	})
+}
+
+func TestRunTranscribe_CostReport(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "talk.ogg")
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "talk.md")
+	reportPath := filepath.Join(dir, "costs.jsonl")
+
+	chunkPath := filepath.Join(t.TempDir(), "chunk_0.ogg")
+	if err := os.WriteFile(chunkPath, []byte("chunk audio"), 0644); err != nil {
+		t.Fatalf("failed to create chunk file: %v", err)
+	}
+	chunkerFactory := &mockChunkerFactory{
+		NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
+			return &mockChunker{
+				ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+					return []audio.Chunk{{Path: chunkPath, Index: 0, EndTime: 2 * time.Minute}}, nil
+				},
+			}, nil
+		},
+	}
+	transcriber := &mockTranscriber{
+		TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "Hello.", nil
+		},
+	}
+
+	stderr := &syncBuffer{}
+	env := &Env{
+		Stderr:             stderr,
+		Getenv:             defaultTestEnv,
+		Now:                fixedTime(time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)),
+		FFmpegResolver:     &mockFFmpegResolver{},
+		ConfigLoader:       &mockConfigLoader{},
+		ChunkerFactory:     chunkerFactory,
+		MediaProberFactory: &mockMediaProberFactory{},
+		TranscriberFactory: &mockTranscriberFactory{NewTranscriberFunc: func(string) transcribe.Transcriber { return transcriber }},
+	}
+
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "", false, 1, "", "", "")
+	opts.costReport = reportPath
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+
+	if !strings.Contains(stderr.String(), "  Transcription: gpt-4o-mini-transcribe, 2m of audio, ~$0.01\n") {
+		t.Errorf("stderr has no cost summary:\n%s", stderr.String())
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("cost report not written: %v", err)
+	}
+	var rec costRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatalf("cost report is not JSON: %v", err)
+	}
+	if rec.Command != "transcribe" || rec.Output != outputPath || rec.AudioSeconds != 120 || rec.TotalCost != 0.006 {
+		t.Errorf("record = %+v, want transcribe of 120s for $0.006", rec)
+	}
+}
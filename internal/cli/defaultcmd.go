@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/config"
+)
+
+// defaultCommands are the commands that can run without being named
+// (config default-command): those taking input files as arguments.
+var defaultCommands = []string{"transcribe", "structure"}
+
+// builtinCommands are the commands Cobra adds when executing the root command,
+// missing from its subcommands until then.
+var builtinCommands = []string{"help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd}
+
+// validateDefaultCommand checks a default-command value.
+func validateDefaultCommand(name string) error {
+	if !slices.Contains(defaultCommands, name) {
+		return fmt.Errorf("%w: %s must be one of %s, got %q",
+			config.ErrInvalidValue, config.KeyDefaultCommand, strings.Join(defaultCommands, ", "), name)
+	}
+	return nil
+}
+
+// UseConfigFile makes the configuration load from path (the global --config
+// flag) instead of the default config file. Empty keeps the default.
+func UseConfigFile(path string) {
+	if path != "" {
+		_ = os.Setenv(config.EnvConfigFile, config.ExpandPath(path))
+	}
+}
+
+// DefaultCommandArgs returns the command-line arguments of root with the
+// default command inserted when the first argument is an input rather than a
+// command: "transcript file.ogg" runs "transcript transcribe file.ogg".
+// Inputs are existing files and directories, glob patterns, and "-" (stdin).
+// The default command is the configured default-command, or transcribe.
+// Other arguments are returned unchanged, so unknown commands are still
+// reported as such.
+func DefaultCommandArgs(root *cobra.Command, env *Env, args []string) []string {
+	first := -1
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--config" {
+			if i+1 < len(args) {
+				UseConfigFile(args[i+1])
+			}
+			i++
+			continue
+		}
+		if path, ok := strings.CutPrefix(arg, "--config="); ok {
+			UseConfigFile(path)
+			continue
+		}
+		if arg == "--suppress-warn" || arg == "--ffmpeg-path" {
+			i++ // Its value is a warning code or a binary, not an input
+			continue
+		}
+		if arg != stdinInput && strings.HasPrefix(arg, "-") {
+			continue
+		}
+		first = i
+		break
+	}
+	if first < 0 || isCommand(root, args[first]) || !isInput(args[first]) {
+		return args
+	}
+
+	name := defaultCommands[0]
+	cfg, err := env.ConfigLoader.Load()
+	if err == nil && cfg.DefaultCommand != "" {
+		if err := validateDefaultCommand(cfg.DefaultCommand); err != nil {
+			warnf(env.Stderr, warnInvalidSetting, "ignoring default-command: %v", err)
+		} else {
+			name = cfg.DefaultCommand
+		}
+	}
+	return append([]string{name}, args...)
+}
+
+// isCommand reports whether name is a command of root, or one of its aliases.
+func isCommand(root *cobra.Command, name string) bool {
+	if slices.Contains(builtinCommands, name) {
+		return true
+	}
+	for _, c := range root.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isInput reports whether arg names an input of a default command.
+func isInput(arg string) bool {
+	if arg == stdinInput || strings.ContainsAny(arg, "*?[") {
+		return true
+	}
+	_, err := os.Stat(arg)
+	return err == nil
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/config"
+)
+
+// defaultCommandRoot returns a root command with the commands of transcript
+// that take inputs.
+func defaultCommandRoot(env *Env) *cobra.Command {
+	root := &cobra.Command{Use: "transcript"}
+	root.AddCommand(TranscribeCmd(env), StructureCmd(env), LiveCmd(env), RecordCmd(env), ConfigCmd(env))
+	return root
+}
+
+func TestDefaultCommandArgs(t *testing.T) {
+	t.Parallel()
+
+	audio := createTestAudioFile(t, "audio.ogg")
+	dir := filepath.Dir(audio)
+
+	tests := []struct {
+		name           string
+		args           []string
+		defaultCommand string
+		want           []string
+	}{
+		{name: "file runs transcribe", args: []string{audio}, want: []string{"transcribe", audio}},
+		{name: "flags before the file", args: []string{"--verbose", audio, "-t", "notes"}, want: []string{"transcribe", "--verbose", audio, "-t", "notes"}},
+		{name: "directory", args: []string{dir}, want: []string{"transcribe", dir}},
+		{name: "glob pattern", args: []string{filepath.Join(dir, "*.ogg")}, want: []string{"transcribe", filepath.Join(dir, "*.ogg")}},
+		{name: "configured default command", args: []string{"-", "-t", "notes"}, defaultCommand: "structure", want: []string{"structure", "-", "-t", "notes"}},
+		{name: "invalid default command ignored", args: []string{audio}, defaultCommand: "live", want: []string{"transcribe", audio}},
+		{name: "command kept", args: []string{"transcribe", audio}, want: []string{"transcribe", audio}},
+		{name: "alias kept", args: []string{"t", audio}, want: []string{"t", audio}},
+		{name: "help kept", args: []string{"help", "transcribe"}, want: []string{"help", "transcribe"}},
+		{name: "unknown command kept", args: []string{"transcibe", audio}, want: []string{"transcibe", audio}},
+		{name: "ffmpeg binary is not an input", args: []string{"--ffmpeg-path", audio, "-"}, want: []string{"transcribe", "--ffmpeg-path", audio, "-"}},
+		{name: "flags only", args: []string{"--version"}, want: []string{"--version"}},
+		{name: "no arguments", args: nil, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env, mocks := testEnv()
+			mocks.configLoader.LoadFunc = func() (config.Config, error) {
+				return config.Config{DefaultCommand: tt.defaultCommand}, nil
+			}
+
+			got := DefaultCommandArgs(defaultCommandRoot(env), env, tt.args)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("DefaultCommandArgs(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultCommandArgs_ConfigFlag(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+
+	t.Setenv(config.EnvConfigFile, "")
+	file := filepath.Join(t.TempDir(), "work.conf")
+	if err := os.WriteFile(file, []byte("default-command=structure\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	env := &Env{Stderr: &syncBuffer{}, Getenv: os.Getenv, ConfigLoader: defaultConfigLoader{}}
+
+	args := []string{"--config", file, "-", "-t", "notes"}
+	got := DefaultCommandArgs(defaultCommandRoot(env), env, args)
+	if want := append([]string{"structure"}, args...); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("DefaultCommandArgs(%q) = %q, want %q", args, got, want)
+	}
+}
+
+func TestCommandAliases(t *testing.T) {
+	t.Parallel()
+
+	env, _ := testEnv()
+	root := defaultCommandRoot(env)
+	for alias, want := range map[string]string{"t": "transcribe", "l": "live", "r": "record", "s": "structure"} {
+		cmd, _, err := root.Find([]string{alias})
+		if err != nil || cmd.Name() != want {
+			t.Errorf("Find(%q) = %v, %v, want the %s command", alias, cmd.Name(), err, want)
+		}
+	}
+}
+
+func TestValidateDefaultCommand(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"transcribe", "structure"} {
+		if err := validateDefaultCommand(name); err != nil {
+			t.Errorf("validateDefaultCommand(%q) unexpected error: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "live", "t"} {
+		if err := validateDefaultCommand(name); !errors.Is(err, config.ErrInvalidValue) {
+			t.Errorf("validateDefaultCommand(%q) error = %v, want ErrInvalidValue", name, err)
+		}
+	}
+}
@@ -3,26 +3,52 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/audio"
 )
 
+// deviceTestDuration is how long devices --test records.
+const deviceTestDuration = 3 * time.Second
+
 // DevicesCmd creates the devices command.
 // Lists available audio input devices for use with --device.
 func DevicesCmd(env *Env) *cobra.Command {
-	return &cobra.Command{
+	var testDevice string
+
+	cmd := &cobra.Command{
 		Use:   "devices",
-		Short: "List available audio input devices",
+		Short: "List and test audio input devices",
 		Long: `List available audio input devices detected by FFmpeg.
 
-Use the device name with --device in the record or live commands.
-Devices are sorted with real microphones first, virtual devices last.`,
+Use the device name with --device in the record or live commands, or any
+part of it ("yeti" for "Yeti Stereo Microphone"). Set the default-device
+config key to use a device without passing --device.
+Devices are sorted with real microphones first, virtual devices last.
+Loopback devices and monitors, which capture system audio, are tagged.
+
+With --test, records 3 seconds from the device and reports its peak and
+mean levels, so you can check it picks up sound before a long session.`,
 		Example: `  transcript devices
-  transcript record -d 30m --device "MacBook Pro Microphone"`,
+  transcript devices --test "MacBook Pro Microphone"
+  transcript record -d 30m --device "MacBook Pro Microphone"
+  transcript record -d 30m --device yeti
+  transcript config set default-device yeti`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("test") {
+				return runTestDevice(cmd.Context(), env, testDevice)
+			}
 			return runListDevices(cmd.Context(), env)
 		},
 	}
+
+	cmd.Flags().StringVar(&testDevice, "test", "", "Record 3 seconds from a device and report its levels")
+
+	return cmd
 }
 
 // runListDevices resolves FFmpeg and lists available audio devices.
@@ -48,7 +74,100 @@ func runListDevices(ctx context.Context, env *Env) error {
 	}
 
 	for _, d := range devices {
+		if audio.IsLoopbackDevice(d) {
+			fmt.Fprintf(env.Stderr, "%s  (loopback)\n", d)
+			continue
+		}
 		fmt.Fprintln(env.Stderr, d)
 	}
 	return nil
 }
+
+// runTestDevice records a short sample from device and reports its levels.
+// An empty device tests the default input.
+func runTestDevice(ctx context.Context, env *Env, device string) error {
+	ffmpegPath, err := env.FFmpegResolver.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	recorder, err := env.RecorderFactory.NewRecorder(ffmpegPath, device)
+	if err != nil {
+		return err
+	}
+	meter, err := env.LevelMeterFactory.NewLevelMeter(ffmpegPath)
+	if err != nil {
+		return err
+	}
+
+	tempDir, err := os.MkdirTemp("", "go-transcript-devices-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+	samplePath := filepath.Join(tempDir, "test.ogg")
+
+	name := device
+	if name == "" {
+		name = "default input"
+	}
+	fmt.Fprintf(env.Stderr, "Recording %s from %s, make some noise...\n", deviceTestDuration, name)
+	if err := recorder.Record(ctx, deviceTestDuration, samplePath); err != nil {
+		return err
+	}
+
+	levels, err := meter.MeasureLevels(ctx, samplePath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stderr, "Peak: %.1f dBFS, mean: %.1f dBFS\n", levels.Peak, levels.Mean)
+	fmt.Fprintln(env.Stderr, levelsVerdict(levels))
+	return nil
+}
+
+// resolveDevice returns the input device to record from: device (--device),
+// or the configured default-device if empty, matched against the listed
+// devices by audio.MatchDevice. An empty result means the system default.
+// If the devices cannot be listed, the name is passed to FFmpeg as is.
+func resolveDevice(ctx context.Context, env *Env, ffmpegPath, device string) (string, error) {
+	if device == "" {
+		// A config that fails to load was already reported by the command.
+		cfg, _ := env.ConfigLoader.Load()
+		device = cfg.DefaultDevice
+	}
+	if device == "" {
+		return "", nil
+	}
+
+	lister, err := env.DeviceListerFactory.NewDeviceLister(ffmpegPath)
+	if err != nil {
+		return device, nil
+	}
+	devices, err := lister.ListDevices(ctx)
+	if err != nil {
+		return device, nil
+	}
+	id, name, err := audio.MatchDevice(devices, device)
+	if err != nil {
+		return "", err
+	}
+	if name != "" && name != device {
+		fmt.Fprintf(env.Stderr, "Using device: %s\n", name)
+	}
+	return id, nil
+}
+
+// levelsVerdict describes whether levels are suitable for transcription.
+func levelsVerdict(l audio.Levels) string {
+	switch {
+	case l.Silent():
+		return "No signal: the device may be muted, disconnected, or not the one you speak into."
+	case l.Quiet():
+		return "Signal is faint: move closer to the microphone or raise the input gain."
+	case l.Clipping():
+		return "Signal is clipping: lower the input gain to avoid distortion."
+	default:
+		return "Levels look good."
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/alnah/go-transcript/internal/audio"
 )
@@ -203,3 +204,151 @@ func TestDevicesCmd_PassesFFmpegPath(t *testing.T) {
 		t.Errorf("DeviceLister received ffmpegPath = %q, want %q", capturedPath, "/custom/ffmpeg")
 	}
 }
+
+func TestRunListDevices_TagsLoopback(t *testing.T) {
+	t.Parallel()
+
+	stderr := &syncBuffer{}
+	env := &Env{
+		Stderr:         stderr,
+		FFmpegResolver: &mockFFmpegResolver{},
+		DeviceListerFactory: &mockDeviceListerFactory{
+			mockDeviceLister: &mockDeviceLister{
+				ListDevicesFunc: func(ctx context.Context) ([]string, error) {
+					return []string{":1\tMacBook Pro Microphone", ":2\tBlackHole 2ch"}, nil
+				},
+			},
+		},
+	}
+
+	if err := RunListDevices(context.Background(), env); err != nil {
+		t.Fatalf("RunListDevices() unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), stderr.String())
+	}
+	if strings.Contains(lines[0], "(loopback)") {
+		t.Errorf("microphone tagged as loopback: %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "(loopback)") {
+		t.Errorf("loopback device not tagged: %q", lines[1])
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests for runTestDevice
+// ---------------------------------------------------------------------------
+
+func TestRunTestDevice_Verdicts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		levels audio.Levels
+		want   string
+	}{
+		{"good", audio.Levels{Peak: -6, Mean: -24}, "Levels look good"},
+		{"silent", audio.Levels{Peak: -70, Mean: -90}, "No signal"},
+		{"quiet", audio.Levels{Peak: -40, Mean: -55}, "faint"},
+		{"clipping", audio.Levels{Peak: 0, Mean: -10}, "clipping"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env, mocks := testEnv()
+			env.LevelMeterFactory = &mockLevelMeterFactory{
+				mockLevelMeter: &mockLevelMeter{
+					MeasureLevelsFunc: func(ctx context.Context, audioPath string) (audio.Levels, error) {
+						return tt.levels, nil
+					},
+				},
+			}
+
+			if err := RunTestDevice(context.Background(), env, ":1"); err != nil {
+				t.Fatalf("RunTestDevice() unexpected error: %v", err)
+			}
+
+			output := env.Stderr.(*syncBuffer).String()
+			if !strings.Contains(output, tt.want) {
+				t.Errorf("output = %q, want containing %q", output, tt.want)
+			}
+			calls := mocks.recorder.newRecorderCalls
+			if len(calls) != 1 || calls[0].Device != ":1" {
+				t.Errorf("NewRecorder calls = %+v, want one for device :1", calls)
+			}
+		})
+	}
+}
+
+func TestRunTestDevice_RecordsThreeSeconds(t *testing.T) {
+	t.Parallel()
+
+	recorder := &mockRecorder{}
+	env, mocks := testEnv()
+	mocks.recorder.mockRecorder = recorder
+	var measured string
+	env.LevelMeterFactory = &mockLevelMeterFactory{
+		mockLevelMeter: &mockLevelMeter{
+			MeasureLevelsFunc: func(ctx context.Context, audioPath string) (audio.Levels, error) {
+				measured = audioPath
+				return audio.Levels{Peak: -6, Mean: -24}, nil
+			},
+		},
+	}
+
+	if err := RunTestDevice(context.Background(), env, ""); err != nil {
+		t.Fatalf("RunTestDevice() unexpected error: %v", err)
+	}
+
+	calls := recorder.RecordCalls()
+	if len(calls) != 1 || calls[0].Duration != 3*time.Second {
+		t.Fatalf("Record calls = %+v, want one of 3s", calls)
+	}
+	if measured != calls[0].Output {
+		t.Errorf("measured %q, want the recorded sample %q", measured, calls[0].Output)
+	}
+	if output := env.Stderr.(*syncBuffer).String(); !strings.Contains(output, "default input") {
+		t.Errorf("output = %q, want mention of the default input", output)
+	}
+}
+
+func TestRunTestDevice_RecordError(t *testing.T) {
+	t.Parallel()
+
+	recordErr := errors.New("device busy")
+	env, mocks := testEnv()
+	mocks.recorder.mockRecorder = &mockRecorder{
+		RecordFunc: func(ctx context.Context, duration time.Duration, output string) error {
+			return recordErr
+		},
+	}
+	env.LevelMeterFactory = &mockLevelMeterFactory{}
+
+	if err := RunTestDevice(context.Background(), env, ":1"); !errors.Is(err, recordErr) {
+		t.Errorf("RunTestDevice() error = %v, want %v", err, recordErr)
+	}
+}
+
+func TestDevicesCmd_TestFlag(t *testing.T) {
+	t.Parallel()
+
+	env, mocks := testEnv()
+	env.LevelMeterFactory = &mockLevelMeterFactory{}
+
+	cmd := DevicesCmd(env)
+	cmd.SetArgs([]string{"--test", "BlackHole 2ch"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("DevicesCmd.Execute() unexpected error: %v", err)
+	}
+
+	if calls := mocks.recorder.newRecorderCalls; len(calls) != 1 || calls[0].Device != "BlackHole 2ch" {
+		t.Errorf("NewRecorder calls = %+v, want one for BlackHole 2ch", calls)
+	}
+	if mocks.deviceLister.newDeviceListerCalls != nil {
+		t.Error("--test should not list devices")
+	}
+}
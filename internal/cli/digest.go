@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// defaultDigestSince is the default --since of the digest command: a weekly rollup.
+const defaultDigestSince = "7d"
+
+// digestOptions holds validated options for the digest command.
+type digestOptions struct {
+	dirs       []string      // Session directories (--session-dir of the runs)
+	since      time.Duration // Sessions started within this period are digested
+	tag        string        // Only sessions with this tag (--tag); empty for all
+	output     string
+	outputLang lang.Language
+	provider   Provider
+	model      string // Restructure model (--restructure-model); empty means configured or provider default
+	costReport string // Append the usage and cost of the run to this file (--cost-report)
+}
+
+// digestSession is a finished session included in a digest.
+type digestSession struct {
+	dir     string
+	started time.Time
+	notes   string // Final output, or raw transcript
+}
+
+// DigestCmd creates the digest command (combine the notes of recent sessions).
+// The env parameter provides injectable dependencies for testing.
+func DigestCmd(env *Env) *cobra.Command {
+	var (
+		since      string
+		tag        string
+		output     string
+		outputLang string
+		provider   string
+		model      string
+		costReport string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "digest <session-dir>...",
+		Short: "Combine recent sessions into a single digest",
+		Long: `Combine the notes of recent sessions into a single digest of decisions,
+action items and open questions, such as a weekly rollup of meetings.
+
+The arguments are directories passed to --session-dir of transcribe or live.
+Every finished session in them started within --since is read, oldest first,
+optionally only the sessions recorded with --tag. The notes of each session
+(its final output, or raw.md) are restructured together with the digest
+template.
+
+--since takes a duration like 36h, or a number of days (7d) or weeks (2w).
+
+The digest is written to digest_<date>.md (in output-dir when configured), or
+to --output.`,
+		Example: `  transcript digest ./sessions
+  transcript digest ./sessions --since 7d --tag team
+  transcript digest ./sessions ./calls --since 1d -o today.md
+  transcript digest ./sessions --since 2w -T fr`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Parse all inputs at the CLI boundary
+			d, err := parseSince(since)
+			if err != nil {
+				return err
+			}
+			parsedProvider, err := ParseProvider(provider)
+			if err != nil {
+				return err
+			}
+			var parsedLang lang.Language
+			if outputLang != "" {
+				if parsedLang, err = lang.Parse(outputLang); err != nil {
+					return err
+				}
+			}
+			return runDigest(cmd, env, digestOptions{
+				dirs:       args,
+				since:      d,
+				tag:        tag,
+				output:     output,
+				outputLang: parsedLang,
+				provider:   parsedProvider,
+				model:      model,
+				costReport: costReport,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", defaultDigestSince, "Digest the sessions started within this period (e.g. 36h, 7d, 2w)")
+	cmd.Flags().StringVar(&tag, "tag", "", "Only digest the sessions recorded with this tag")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path (default: digest_<date>.md)")
+	cmd.Flags().StringVarP(&outputLang, "translate", "T", "", "Translate output to language (ISO 639-1 code, e.g., en, fr)")
+	cmd.Flags().StringVar(&provider, "provider", ProviderDeepSeek, "LLM provider for restructuring: deepseek, openai, anthropic, ollama")
+	cmd.Flags().StringVar(&model, "restructure-model", "", "Model of the restructure provider (default: config or provider default)")
+	cmd.Flags().StringVar(&costReport, "cost-report", "", "Append the usage and cost of the run to this file (JSON lines, or CSV with a .csv extension)")
+
+	return cmd
+}
+
+// parseSince parses a --since period: a Go duration (36h, 90m), or a whole
+// number of days (7d) or weeks (2w). Returns ErrInvalidDuration otherwise.
+func parseSince(s string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	switch unit := strings.TrimLeft(s, "0123456789"); unit {
+	case "d", "w":
+		var n int
+		n, err = strconv.Atoi(strings.TrimSuffix(s, unit))
+		d = time.Duration(n) * 24 * time.Hour
+		if unit == "w" {
+			d *= 7
+		}
+	default:
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid period %q (use format like 36h, 7d, 2w): %w", s, ErrInvalidDuration)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("period must be positive: %w", ErrInvalidDuration)
+	}
+	return d, nil
+}
+
+// runDigest executes the digest command with validated options.
+func runDigest(cmd *cobra.Command, env *Env, opts digestOptions) error {
+	ctx := cmd.Context()
+
+	// === VALIDATION (fail-fast) ===
+
+	for _, dir := range opts.dirs {
+		info, err := os.Stat(config.ExpandPath(dir))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("%w: %s", ErrFileNotFound, dir)
+			}
+			return fmt.Errorf("cannot access directory: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory (pass the --session-dir of the sessions)", dir)
+		}
+	}
+
+	cfg, err := env.ConfigLoader.Load()
+	if err != nil {
+		warnf(env.Stderr, warnConfigLoad, "failed to load config: %v", err)
+	}
+
+	now := env.Now()
+	defaultOutput := fmt.Sprintf("digest_%s.md", now.Format("20060102"))
+	output := config.ResolveOutputPath(opts.output, cfg.OutputDir, defaultOutput)
+	output = config.EnsureExtension(output, ".md")
+	warnNonMarkdownExtension(env.Stderr, output)
+
+	provider := opts.provider.OrDefault()
+
+	// === READ SESSIONS ===
+
+	sessions := findDigestSessions(env, opts.dirs, now.Add(-opts.since), opts.tag)
+	if len(sessions) == 0 {
+		filter := ""
+		if opts.tag != "" {
+			filter = fmt.Sprintf(" with tag %q", opts.tag)
+		}
+		return fmt.Errorf("%w: no finished session%s since %s", ErrNoSessions, filter, now.Add(-opts.since).Format("2006-01-02 15:04"))
+	}
+	fmt.Fprintf(env.Stderr, "Digesting %d sessions from %s to %s...\n", len(sessions),
+		sessions[0].started.Format("2006-01-02"), sessions[len(sessions)-1].started.Format("2006-01-02"))
+
+	// === RESTRUCTURE ===
+
+	report := newRunReport("digest", strings.Join(opts.dirs, ","), output)
+	result, err := restructureContent(ctx, env, digestInput(sessions), RestructureOptions{
+		Template:           template.DigestName,
+		Provider:           provider,
+		OutputLang:         opts.outputLang,
+		OnProgress:         defaultProgressCallback(env.Stderr),
+		PromptTokenWarning: cfg.PromptTokenWarning,
+		Ollama:             ollamaConfig(cfg),
+		Azure:              azureConfig(cfg),
+		Model:              restructureModel(opts.model, cfg),
+		ContextWindows:     cfg.ContextWindows,
+		Split:              cfg.RestructureSplit,
+		ChunkTokens:        cfg.RestructureChunkTokens,
+		Overlap:            cfg.RestructureOverlap,
+		report:             report,
+	})
+	if err != nil {
+		return err
+	}
+
+	// === WRITE OUTPUT ===
+
+	if err := writeFileAtomic(output, result); err != nil {
+		return err
+	}
+	fmt.Fprintf(env.Stderr, "Done: %s\n", output)
+	finishRunReport(env, report, opts.costReport)
+	return nil
+}
+
+// findDigestSessions returns the finished sessions of dirs started at or
+// after since, with tag unless empty, oldest first.
+// Entries that are not sessions are ignored; a matching session without
+// readable notes is reported and skipped.
+func findDigestSessions(env *Env, dirs []string, since time.Time, tag string) []digestSession {
+	var sessions []digestSession
+	for _, parent := range dirs {
+		entries, err := os.ReadDir(config.ExpandPath(parent))
+		if err != nil {
+			continue // Checked by the caller
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+			dir := filepath.Join(config.ExpandPath(parent), e.Name())
+			meta, err := readSessionMetadata(dir)
+			if err != nil || meta.Status != sessionComplete || meta.StartedAt.Before(since) || (tag != "" && meta.Tag != tag) {
+				continue
+			}
+			notes, err := readSessionNotes(dir, meta)
+			if err != nil {
+				warnf(env.Stderr, warnSessionSkipped, "session %s left out: %v", dir, err)
+				continue
+			}
+			sessions = append(sessions, digestSession{dir: dir, started: meta.StartedAt, notes: notes})
+		}
+	}
+	slices.SortStableFunc(sessions, func(a, b digestSession) int {
+		return a.started.Compare(b.started)
+	})
+	return sessions
+}
+
+// readSessionNotes returns the final output of the session in dir, or its
+// raw transcript when the output is missing or empty.
+func readSessionNotes(dir string, meta sessionMetadata) (string, error) {
+	output := sessionOutputFile
+	if f, err := ParseOutputFormat(meta.Format); err == nil {
+		output = sessionOutput(f)
+	}
+	for _, name := range []string{output, sessionRawFile} {
+		data, err := os.ReadFile(filepath.Join(dir, name)) // #nosec G304 -- path inside a session directory
+		if err == nil && strings.TrimSpace(string(data)) != "" {
+			return string(data), nil
+		}
+	}
+	return "", fmt.Errorf("no %s or %s", output, sessionRawFile)
+}
+
+// digestInput joins the notes of sessions into the input of the digest
+// template: one "=== Session: <name> (<date>) ===" section per session.
+func digestInput(sessions []digestSession) string {
+	var b strings.Builder
+	for _, s := range sessions {
+		fmt.Fprintf(&b, "=== Session: %s (%s) ===\n\n%s\n\n",
+			filepath.Base(s.dir), s.started.Format("2006-01-02 15:04"), strings.TrimSpace(s.notes))
+	}
+	return b.String()
+}
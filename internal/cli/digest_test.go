@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// writeTestSession creates a session directory named name in parent with
+// meta and files (name -> content).
+func writeTestSession(t *testing.T, parent, name string, meta sessionMetadata, files map[string]string) {
+	t.Helper()
+	dir := filepath.Join(parent, name)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, sessionMetadataFile), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for file, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// runDigestCmd runs the digest command on dirs with a 7-day period.
+func runDigestCmd(env *Env, dirs []string, tag, output string) error {
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	return runDigest(cmd, env, digestOptions{dirs: dirs, since: 7 * 24 * time.Hour, tag: tag, output: output})
+}
+
+func TestParseSince(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "7d", want: 7 * 24 * time.Hour},
+		{input: "1d", want: 24 * time.Hour},
+		{input: "2w", want: 14 * 24 * time.Hour},
+		{input: "36h", want: 36 * time.Hour},
+		{input: "1h30m", want: 90 * time.Minute},
+		{input: "", wantErr: true},
+		{input: "d", wantErr: true},
+		{input: "0d", wantErr: true},
+		{input: "-1h", wantErr: true},
+		{input: "1.5d", wantErr: true},
+		{input: "week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseSince(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDuration) {
+					t.Errorf("parseSince(%q) error = %v, want ErrInvalidDuration", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSince(%q) unexpected error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseSince(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunDigest_Success(t *testing.T) {
+	t.Parallel()
+
+	parent := t.TempDir()
+	now := time.Date(2026, 1, 26, 14, 30, 52, 0, time.UTC)
+	session := func(tag, status string, age time.Duration) sessionMetadata {
+		return sessionMetadata{Command: "transcribe", Tag: tag, Status: status, StartedAt: now.Add(-age)}
+	}
+	writeTestSession(t, parent, "monday", session("team", sessionComplete, 5*24*time.Hour),
+		map[string]string{sessionOutputFile: "# Monday sync\n\nShip on Friday."})
+	writeTestSession(t, parent, "thursday", session("team", sessionComplete, 2*24*time.Hour),
+		map[string]string{sessionRawFile: "raw notes of thursday"})
+	writeTestSession(t, parent, "subtitles", sessionMetadata{Command: "transcribe", Tag: "team", Format: "srt", Status: sessionComplete, StartedAt: now.Add(-time.Hour)},
+		map[string]string{"transcript.srt": "1\n00:00:00,000 --> 00:00:02,000\nHello"})
+	writeTestSession(t, parent, "other-tag", session("sales", sessionComplete, time.Hour), map[string]string{sessionOutputFile: "sales notes"})
+	writeTestSession(t, parent, "old", session("team", sessionComplete, 10*24*time.Hour), map[string]string{sessionOutputFile: "old notes"})
+	writeTestSession(t, parent, "failed", session("team", sessionFailed, time.Hour), map[string]string{sessionRawFile: "failed notes"})
+	if err := os.WriteFile(filepath.Join(parent, "notes.md"), []byte("not a session"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	mockMR := &mockMapReduceRestructurer{}
+	env, mocks := testEnv()
+	mocks.restructurer.mockMapReducer = mockMR
+	output := filepath.Join(t.TempDir(), "weekly.md")
+	if err := runDigestCmd(env, []string{parent}, "team", output); err != nil {
+		t.Fatalf("runDigest() unexpected error: %v", err)
+	}
+
+	calls := mockMR.RestructureCalls()
+	if len(calls) != 1 {
+		t.Fatalf("Restructure() called %d times, want 1", len(calls))
+	}
+	if calls[0].TemplateName != template.DigestName {
+		t.Errorf("template = %q, want %q", calls[0].TemplateName, template.DigestName)
+	}
+	input := calls[0].Transcript
+	monday := strings.Index(input, "=== Session: monday (2026-01-21 14:30) ===")
+	thursday := strings.Index(input, "=== Session: thursday")
+	subtitles := strings.Index(input, "=== Session: subtitles")
+	if monday < 0 || thursday < monday || subtitles < thursday {
+		t.Errorf("digest input = %q, want the 3 team sessions, oldest first", input)
+	}
+	for _, want := range []string{"Ship on Friday.", "raw notes of thursday", "Hello"} {
+		if !strings.Contains(input, want) {
+			t.Errorf("digest input = %q, want containing %q", input, want)
+		}
+	}
+	for _, excluded := range []string{"sales notes", "old notes", "failed notes", "not a session"} {
+		if strings.Contains(input, excluded) {
+			t.Errorf("digest input = %q, want without %q", input, excluded)
+		}
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "restructured text" {
+		t.Errorf("output = %q, want the restructured digest", content)
+	}
+}
+
+func TestRunDigest_DefaultOutput(t *testing.T) {
+	t.Parallel()
+
+	parent := t.TempDir()
+	outputDir := t.TempDir()
+	writeTestSession(t, parent, "standup", sessionMetadata{Status: sessionComplete, StartedAt: time.Date(2026, 1, 26, 9, 0, 0, 0, time.UTC)},
+		map[string]string{sessionOutputFile: "standup notes"})
+
+	env, mocks := testEnv()
+	mocks.configLoader.LoadFunc = func() (config.Config, error) {
+		return config.Config{OutputDir: outputDir}, nil
+	}
+	if err := runDigestCmd(env, []string{parent}, "", ""); err != nil {
+		t.Fatalf("runDigest() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "digest_20260126.md")); err != nil {
+		t.Errorf("digest not written to output-dir/digest_<date>.md: %v", err)
+	}
+}
+
+func TestRunDigest_SessionWithoutNotes(t *testing.T) {
+	t.Parallel()
+
+	parent := t.TempDir()
+	started := time.Date(2026, 1, 25, 9, 0, 0, 0, time.UTC)
+	writeTestSession(t, parent, "empty", sessionMetadata{Status: sessionComplete, StartedAt: started}, nil)
+	writeTestSession(t, parent, "kept", sessionMetadata{Status: sessionComplete, StartedAt: started},
+		map[string]string{sessionOutputFile: "kept notes"})
+
+	stderr := &syncBuffer{}
+	env, _ := testEnv()
+	env.Stderr = stderr
+	if err := runDigestCmd(env, []string{parent}, "", filepath.Join(t.TempDir(), "digest.md")); err != nil {
+		t.Fatalf("runDigest() unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "left out") || !strings.Contains(stderr.String(), warnSessionSkipped) {
+		t.Errorf("stderr = %q, want the session without notes reported", stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "Digesting 1 sessions") {
+		t.Errorf("stderr = %q, want the other session digested", stderr.String())
+	}
+}
+
+func TestRunDigest_Errors(t *testing.T) {
+	t.Parallel()
+
+	file := createTestTranscriptFile(t, "notes")
+	emptyDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		dir     string
+		tag     string
+		wantErr error
+		wantMsg string
+	}{
+		{name: "missing directory", dir: filepath.Join(t.TempDir(), "missing"), wantErr: ErrFileNotFound},
+		{name: "file instead of directory", dir: file, wantMsg: "is not a directory"},
+		{name: "no session", dir: emptyDir, wantErr: ErrNoSessions},
+		{name: "no session with tag", dir: emptyDir, tag: "team", wantMsg: `with tag "team"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env, _ := testEnv()
+			err := runDigestCmd(env, []string{tt.dir}, tt.tag, filepath.Join(t.TempDir(), "digest.md"))
+			if err == nil {
+				t.Fatal("runDigest() error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("runDigest() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantMsg != "" && !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("runDigest() error = %v, want containing %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestRunDigest_MissingAPIKey(t *testing.T) {
+	t.Parallel()
+
+	parent := t.TempDir()
+	writeTestSession(t, parent, "standup", sessionMetadata{Status: sessionComplete, StartedAt: time.Date(2026, 1, 26, 9, 0, 0, 0, time.UTC)},
+		map[string]string{sessionOutputFile: "standup notes"})
+
+	env, _ := testEnv()
+	env.Getenv = staticEnv(nil)
+	err := runDigestCmd(env, []string{parent}, "", filepath.Join(t.TempDir(), "digest.md"))
+	if !errors.Is(err, ErrDeepSeekKeyMissing) {
+		t.Errorf("runDigest() error = %v, want ErrDeepSeekKeyMissing", err)
+	}
+}
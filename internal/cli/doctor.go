@@ -0,0 +1,429 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// verifyKeyTimeout bounds each API key verification of config doctor.
+const verifyKeyTimeout = 15 * time.Second
+
+// checkStatus is the outcome of a config doctor check.
+type checkStatus int
+
+const (
+	checkOK   checkStatus = iota
+	checkWarn             // Works, but some features will not
+	checkFail             // transcript will fail
+)
+
+// doctorCheck is a line of the config doctor checklist.
+type doctorCheck struct {
+	status checkStatus
+	name   string
+	detail string
+	hint   string // How to fix a warning or failure
+}
+
+// configValidateCmd creates the "config validate" subcommand.
+func configValidateCmd(env *Env) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check the config file for errors",
+		Long: `Check the config file for errors: syntax, unknown keys, and values that
+transcript would reject, including those of profiles. Environment variable
+overrides and the project file are checked too.
+
+Prints each problem found, and exits with an error if there is any.`,
+		Example: `  transcript config validate
+  transcript --config ./work.conf config validate`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigValidate(cmd.OutOrStdout(), env)
+		},
+	}
+}
+
+// configDoctorCmd creates the "config doctor" subcommand.
+func configDoctorCmd(env *Env) *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that transcript is ready to run",
+		Long: `Check that transcript is ready to run, and print a checklist with a hint
+for each problem found:
+
+  config file    Syntax and values, like "transcript config validate"
+  ffmpeg         Installed, and its version (nothing is downloaded)
+  API keys       OPENAI_API_KEY, DEEPSEEK_API_KEY and ANTHROPIC_API_KEY,
+                 each checked by listing the models of the provider (free)
+  audio input    A microphone FFmpeg can record from
+  loopback       A loopback device for --system-record and --mix
+  output dir     output-dir, or the working directory, is writable
+
+Warnings are for features that will not work, failures for problems that
+will make transcript fail. Exits with an error if any check fails.
+Set NO_COLOR to print without colors.`,
+		Example: `  transcript config doctor`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigDoctor(cmd.Context(), cmd.OutOrStdout(), env)
+		},
+	}
+}
+
+// runConfigValidate writes the problems of the config file to w.
+func runConfigValidate(w io.Writer, env *Env) error {
+	path, problems, err := validateConfigFile(env)
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		fmt.Fprintf(w, "%s: OK\n", path)
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s:\n", path)
+	for _, problem := range problems {
+		fmt.Fprintf(w, "  %s\n", problem)
+	}
+	return fmt.Errorf("%w: %s in %s", ErrChecksFailed, countProblems(len(problems)), path)
+}
+
+// validateConfigFile returns the path of the config file and the problems
+// found in it. Values are checked as config set checks them, except that
+// output directories are not created.
+func validateConfigFile(env *Env) (string, []string, error) {
+	path, err := config.Path()
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := config.List()
+	if errors.Is(err, config.ErrInvalidSyntax) {
+		return path, []string{err.Error()}, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var problems []string
+	for _, key := range keys {
+		if profile, setting, ok := config.SplitProfileKey(key); ok {
+			if err := validateProfileKey(setting); err != nil {
+				problems = append(problems, fmt.Sprintf("profile %s: %v", profile, err))
+				continue
+			}
+			if setting == config.KeyOutputDir {
+				continue // Created when used
+			}
+			if _, err := validateProfileSetting(env, setting, data[key]); err != nil {
+				problems = append(problems, fmt.Sprintf("profile %s: %s: %v", profile, setting, err))
+			}
+			continue
+		}
+		if !isValidConfigKey(key) {
+			problems = append(problems, fmt.Sprintf("unknown config key %q", key))
+			continue
+		}
+		if _, err := validateConfigValue(key, data[key]); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+		}
+	}
+
+	// Environment variables and the project file are only read on load.
+	if len(problems) == 0 {
+		if _, err := env.ConfigLoader.Load(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return path, problems, nil
+}
+
+// runConfigDoctor checks the environment transcript runs in, and writes the
+// checklist to w. Returns ErrChecksFailed if any check fails.
+func runConfigDoctor(ctx context.Context, w io.Writer, env *Env) error {
+	var checks []doctorCheck
+	checks = append(checks, checkConfigFile(env))
+
+	cfg, _ := env.ConfigLoader.Load() // Problems reported by checkConfigFile
+
+	ffmpegCheck, ffmpegPath := checkFFmpeg(ctx, env)
+	checks = append(checks, ffmpegCheck)
+	checks = append(checks, checkAPIKeys(ctx, env, cfg)...)
+	checks = append(checks, checkAudioInput(ctx, env, ffmpegPath), checkLoopback(ctx, env, ffmpegPath))
+	checks = append(checks, checkOutputDir(cfg))
+
+	color := isTerminal(w) && env.Getenv("NO_COLOR") == ""
+	var warnings, failures int
+	for _, c := range checks {
+		printCheck(w, c, color)
+		switch c.status {
+		case checkWarn:
+			warnings++
+		case checkFail:
+			failures++
+		}
+	}
+
+	fmt.Fprintln(w)
+	switch {
+	case failures > 0:
+		fmt.Fprintf(w, "%d failed, %d warning(s)\n", failures, warnings)
+		return fmt.Errorf("%w: %d of %d checks failed", ErrChecksFailed, failures, len(checks))
+	case warnings > 0:
+		fmt.Fprintf(w, "All checks passed, %d warning(s)\n", warnings)
+	default:
+		fmt.Fprintln(w, "All checks passed")
+	}
+	return nil
+}
+
+// printCheck writes a line of the checklist to w, and the hint of warnings and
+// failures below it. color adds ANSI colors to the status mark.
+func printCheck(w io.Writer, c doctorCheck, color bool) {
+	mark, code := "✓", "32" // green
+	switch c.status {
+	case checkWarn:
+		mark, code = "!", "33" // yellow
+	case checkFail:
+		mark, code = "✗", "31" // red
+	}
+	if color {
+		mark = "\x1b[" + code + "m" + mark + "\x1b[0m"
+	}
+
+	fmt.Fprintf(w, "%s %-17s %s\n", mark, c.name, c.detail)
+	if c.status != checkOK && c.hint != "" {
+		for _, line := range strings.Split(c.hint, "\n") {
+			fmt.Fprintf(w, "    → %s\n", line)
+		}
+	}
+}
+
+// checkConfigFile checks the config file like config validate.
+func checkConfigFile(env *Env) doctorCheck {
+	check := doctorCheck{name: "config file"}
+	path, problems, err := validateConfigFile(env)
+	switch {
+	case err != nil:
+		check.status, check.detail = checkFail, err.Error()
+	case len(problems) > 0:
+		check.status = checkFail
+		check.detail = fmt.Sprintf("%s: %s", path, problems[0])
+		if len(problems) > 1 {
+			check.detail += fmt.Sprintf(" (and %d more)", len(problems)-1)
+		}
+		check.hint = "Run transcript config validate to list the problems, then fix them with transcript config set"
+	default:
+		check.detail = path
+	}
+	return check
+}
+
+// checkFFmpeg checks that FFmpeg is installed, and returns its path (empty if
+// it is not).
+func checkFFmpeg(ctx context.Context, env *Env) (doctorCheck, string) {
+	check := doctorCheck{name: "ffmpeg"}
+	path, version, err := env.Prober.LookupFFmpeg(ctx)
+	switch {
+	case err != nil && env.Getenv("FFMPEG_PATH") != "":
+		check.status, check.detail = checkFail, err.Error()
+		check.hint = "Point FFMPEG_PATH to an FFmpeg binary, or unset it"
+	case err != nil:
+		check.status, check.detail = checkWarn, "not installed"
+		check.hint = "It is downloaded on first use, or install FFmpeg 4+ and set FFMPEG_PATH"
+	case version == "":
+		check.detail = path
+	default:
+		check.detail = fmt.Sprintf("%s (%s)", version, path)
+	}
+	return check, path
+}
+
+// checkAPIKeys verifies the API key of each provider that has one, and checks
+// the key of the configured transcriber if it is another provider. Only the
+// key of the configured transcriber is required.
+func checkAPIKeys(ctx context.Context, env *Env, cfg config.Config) []doctorCheck {
+	backend, err := resolveBackend(Backend{}, cfg)
+	if err != nil {
+		backend = OpenAIBackend
+	}
+
+	keys := []struct {
+		provider Provider
+		envVar   string
+		missing  checkStatus
+		usedFor  string
+	}{
+		{OpenAIProvider, EnvOpenAIAPIKey, checkWarn, "needed by --transcriber openai and --provider openai"},
+		{DeepSeekProvider, EnvDeepSeekAPIKey, checkWarn, "needed to restructure with the default provider"},
+		{AnthropicProvider, EnvAnthropicAPIKey, checkOK, "only needed by --provider anthropic"},
+	}
+	if backend.IsOpenAI() {
+		keys[0].missing, keys[0].usedFor = checkFail, "needed to transcribe with OpenAI (the configured transcriber)"
+	}
+
+	checks := make([]doctorCheck, 0, len(keys))
+	for _, k := range keys {
+		check := doctorCheck{name: k.envVar}
+		key, fromKeyring := lookupAPIKey(env, k.envVar)
+		if key == "" {
+			check.status, check.detail = k.missing, "not set, "+k.usedFor
+			check.hint = fmt.Sprintf("Set it with: export %s=... (or in a .env file), or: transcript config set-key --provider %s", k.envVar, k.provider)
+			checks = append(checks, check)
+			continue
+		}
+		source := ""
+		if fromKeyring {
+			source = " (keyring)"
+		}
+		if k.provider.IsOpenAI() && cfg.AzureEndpoint != "" {
+			// Azure keys are not accepted by the OpenAI API the key is verified with
+			check.detail = "set" + source + ", for " + cfg.AzureEndpoint + " (not verified)"
+			checks = append(checks, check)
+			continue
+		}
+
+		verifyCtx, cancel := context.WithTimeout(ctx, verifyKeyTimeout)
+		err := env.Prober.VerifyAPIKey(verifyCtx, k.provider, key)
+		cancel()
+		switch {
+		case err == nil:
+			check.detail = "valid" + source
+		case errors.Is(err, apierr.ErrAuthFailed):
+			check.status, check.detail = checkFail, "rejected by "+k.provider.String()+source
+			check.hint = fmt.Sprintf("Create a new key in the %s console and export %s", k.provider, k.envVar)
+			if fromKeyring {
+				check.hint = fmt.Sprintf("Create a new key in the %s console and store it with: transcript config set-key --provider %s", k.provider, k.provider)
+			}
+		default:
+			check.status, check.detail = checkWarn, "could not be verified"+source+": "+err.Error()
+			check.hint = "Check your network connection, or ca-bundle behind a proxy intercepting TLS"
+		}
+		checks = append(checks, check)
+	}
+	if p, ok := backend.provider(); ok && !backend.IsOpenAI() {
+		checks = append(checks, checkTranscriberKey(env, p))
+	}
+	return checks
+}
+
+// checkTranscriberKey checks that the API key of p, the configured
+// transcription provider, is set. It is not verified: only transcribing
+// audio would tell.
+func checkTranscriberKey(env *Env, p transcribe.Provider) doctorCheck {
+	check := doctorCheck{name: p.APIKeyEnv}
+	key, fromKeyring := lookupAPIKey(env, p.APIKeyEnv)
+	switch {
+	case key == "":
+		check.status, check.detail = checkFail, "not set, needed to transcribe with "+p.Name+" (the configured transcriber)"
+		check.hint = fmt.Sprintf("Set it with: export %s=... (or in a .env file)", p.APIKeyEnv)
+	case fromKeyring:
+		check.detail = "set (keyring, not verified)"
+	default:
+		check.detail = "set (not verified)"
+	}
+	return check
+}
+
+// checkAudioInput checks that FFmpeg finds a microphone.
+func checkAudioInput(ctx context.Context, env *Env, ffmpegPath string) doctorCheck {
+	check := doctorCheck{name: "audio input"}
+	if ffmpegPath == "" {
+		check.status, check.detail = checkWarn, "not checked, FFmpeg is not installed"
+		return check
+	}
+
+	lister, err := env.DeviceListerFactory.NewDeviceLister(ffmpegPath)
+	var devices []string
+	if err == nil {
+		devices, err = lister.ListDevices(ctx)
+	}
+	if err != nil {
+		check.status, check.detail = checkWarn, "cannot list devices: "+err.Error()
+		check.hint = "Run transcript devices to see the error of FFmpeg"
+		return check
+	}
+
+	var inputs []string
+	for _, d := range devices {
+		if !audio.IsLoopbackDevice(d) {
+			inputs = append(inputs, d)
+		}
+	}
+	if len(inputs) == 0 {
+		check.status, check.detail = checkWarn, "no microphone found, record and live will fail"
+		check.hint = "Connect a microphone and grant your terminal access to it"
+		return check
+	}
+	check.detail = inputs[0]
+	if len(inputs) > 1 {
+		check.detail += fmt.Sprintf(" (and %d more)", len(inputs)-1)
+	}
+	return check
+}
+
+// checkLoopback checks for the loopback device of --system-record and --mix.
+func checkLoopback(ctx context.Context, env *Env, ffmpegPath string) doctorCheck {
+	check := doctorCheck{name: "loopback"}
+	if ffmpegPath == "" {
+		check.status, check.detail = checkWarn, "not checked, FFmpeg is not installed"
+		return check
+	}
+
+	name, err := env.Prober.DetectLoopback(ctx, ffmpegPath)
+	if err != nil {
+		check.status, check.detail = checkWarn, "not found, --system-record and --mix will fail"
+		// The error ends with installation instructions.
+		msg := err.Error()
+		if _, help, ok := strings.Cut(msg, "\n\n"); ok {
+			msg = help
+		}
+		check.hint = msg
+		return check
+	}
+	check.detail = name
+	return check
+}
+
+// checkOutputDir checks that the output directory is writable, creating it
+// if needed.
+func checkOutputDir(cfg config.Config) doctorCheck {
+	check := doctorCheck{name: "output dir"}
+	dir := config.ExpandPath(cfg.OutputDir)
+	if dir == "" {
+		dir = "."
+	}
+	if err := config.EnsureOutputDir(dir); err != nil {
+		check.status, check.detail = checkFail, err.Error()
+		check.hint = "Choose another directory with: transcript config set output-dir <dir>"
+		return check
+	}
+	check.detail = dir
+	return check
+}
+
+// countProblems returns "1 problem" or "n problems".
+func countProblems(n int) string {
+	if n == 1 {
+		return "1 problem"
+	}
+	return fmt.Sprintf("%d problems", n)
+}
@@ -0,0 +1,320 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/ffmpeg"
+)
+
+// writeTestConfig writes content to a config file selected with
+// TRANSCRIPT_CONFIG, and returns its path.
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(config.EnvConfigFile, path)
+	return path
+}
+
+func TestRunConfigValidate(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+	tests := []struct {
+		name      string
+		content   string
+		wantOut   []string
+		wantError bool
+	}{
+		{name: "no config file", wantOut: []string{": OK"}},
+		{
+			name:    "valid settings and profile",
+			content: "transcriber=local\nrate-limit-requests=50\n\n[profile.meetings]\ntemplate=meeting\ndiarize=true\n",
+			wantOut: []string{": OK"},
+		},
+		{
+			name:      "invalid value",
+			content:   "transcriber=cloud\ngrpc-timeout=soon\n",
+			wantOut:   []string{"grpc-timeout:", "transcriber:"},
+			wantError: true,
+		},
+		{
+			name:      "unknown key",
+			content:   "output-directory=/tmp\n",
+			wantOut:   []string{`unknown config key "output-directory"`},
+			wantError: true,
+		},
+		{
+			name:      "invalid profile setting",
+			content:   "[profile.meetings]\ndiarize=maybe\ncolor=blue\n",
+			wantOut:   []string{"profile meetings: diarize:", `profile meetings: invalid config key: unknown profile key "color"`},
+			wantError: true,
+		},
+		{
+			name:      "invalid syntax",
+			content:   "[server]\nport=8080\n",
+			wantOut:   []string{"invalid config syntax"},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestConfig(t, tt.content)
+			env, _ := testEnv()
+
+			var out bytes.Buffer
+			err := runConfigValidate(&out, env)
+			if tt.wantError != (err != nil) {
+				t.Fatalf("runConfigValidate() error = %v, wantError %v", err, tt.wantError)
+			}
+			if err != nil && !errors.Is(err, ErrChecksFailed) {
+				t.Errorf("runConfigValidate() error = %v, want ErrChecksFailed", err)
+			}
+			if !strings.Contains(out.String(), path) {
+				t.Errorf("output = %q, want the config path %s", out.String(), path)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output = %q, want containing %q", out.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunConfigValidate_EnvOverride(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+	writeTestConfig(t, "")
+	env, mocks := testEnv()
+	mocks.configLoader.LoadFunc = func() (config.Config, error) {
+		return config.Config{}, errors.New("invalid TRANSCRIPT_RATE_LIMIT_AUDIO")
+	}
+
+	var out bytes.Buffer
+	if err := runConfigValidate(&out, env); !errors.Is(err, ErrChecksFailed) {
+		t.Fatalf("runConfigValidate() error = %v, want ErrChecksFailed", err)
+	}
+	if !strings.Contains(out.String(), "TRANSCRIPT_RATE_LIMIT_AUDIO") {
+		t.Errorf("output = %q, want the error of the environment variable", out.String())
+	}
+}
+
+func TestRunConfigDoctor(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+	t.Run("all checks pass", func(t *testing.T) {
+		writeTestConfig(t, "")
+		env, mocks := testEnv()
+		mocks.configLoader.LoadFunc = func() (config.Config, error) {
+			return config.Config{OutputDir: t.TempDir()}, nil
+		}
+		mocks.deviceLister.mockDeviceLister = &mockDeviceLister{
+			ListDevicesFunc: func(ctx context.Context) ([]string, error) {
+				return []string{"MacBook Pro Microphone", "BlackHole 2ch"}, nil
+			},
+		}
+
+		var out bytes.Buffer
+		if err := runConfigDoctor(context.Background(), &out, env); err != nil {
+			t.Fatalf("runConfigDoctor() error = %v\n%s", err, out.String())
+		}
+		for _, want := range []string{
+			"✓ ffmpeg            6.1.1 (/usr/bin/ffmpeg)",
+			"✓ OPENAI_API_KEY    valid",
+			"✓ audio input       MacBook Pro Microphone\n",
+			"✓ loopback          BlackHole 2ch",
+			"All checks passed\n",
+		} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("output = %q, want containing %q", out.String(), want)
+			}
+		}
+		if got := len(mocks.prober.Verified()); got != 3 {
+			t.Errorf("verified %d keys, want 3", got)
+		}
+		if strings.Contains(out.String(), "\x1b[") {
+			t.Errorf("output = %q, want no colors outside a terminal", out.String())
+		}
+	})
+
+	t.Run("rejected key fails", func(t *testing.T) {
+		writeTestConfig(t, "")
+		env, mocks := testEnv()
+		mocks.configLoader.LoadFunc = func() (config.Config, error) {
+			return config.Config{OutputDir: t.TempDir()}, nil
+		}
+		mocks.deviceLister.mockDeviceLister = &mockDeviceLister{
+			ListDevicesFunc: func(ctx context.Context) ([]string, error) { return []string{"Microphone"}, nil },
+		}
+		mocks.prober.VerifyAPIKeyFunc = func(ctx context.Context, provider Provider, key string) error {
+			if provider.IsDeepSeek() {
+				return fmt.Errorf("invalid api key: %w", apierr.ErrAuthFailed)
+			}
+			return nil
+		}
+
+		var out bytes.Buffer
+		err := runConfigDoctor(context.Background(), &out, env)
+		if !errors.Is(err, ErrChecksFailed) {
+			t.Fatalf("runConfigDoctor() error = %v, want ErrChecksFailed", err)
+		}
+		for _, want := range []string{"✗ DEEPSEEK_API_KEY  rejected by deepseek", "→ Create a new key", "1 failed"} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("output = %q, want containing %q", out.String(), want)
+			}
+		}
+	})
+
+	t.Run("missing ffmpeg and loopback warn", func(t *testing.T) {
+		writeTestConfig(t, "")
+		env, mocks := testEnv(func(o *testEnvOptions) { o.getenv = staticEnv(map[string]string{EnvOpenAIAPIKey: "sk-test"}) })
+		mocks.configLoader.LoadFunc = func() (config.Config, error) {
+			return config.Config{OutputDir: t.TempDir()}, nil
+		}
+		mocks.prober.LookupFFmpegFunc = func(ctx context.Context) (string, string, error) {
+			return "", "", ffmpeg.ErrNotFound
+		}
+
+		var out bytes.Buffer
+		if err := runConfigDoctor(context.Background(), &out, env); err != nil {
+			t.Fatalf("runConfigDoctor() error = %v, want warnings only\n%s", err, out.String())
+		}
+		for _, want := range []string{
+			"! ffmpeg            not installed",
+			"! DEEPSEEK_API_KEY  not set",
+			"! loopback          not checked",
+			"All checks passed, 4 warning(s)",
+		} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("output = %q, want containing %q", out.String(), want)
+			}
+		}
+	})
+
+	t.Run("keys from the keyring", func(t *testing.T) {
+		writeTestConfig(t, "")
+		env, mocks := testEnv(func(o *testEnvOptions) { o.getenv = staticEnv(nil) })
+		env.Keyring = &mockKeyring{secrets: map[string]string{EnvOpenAIAPIKey: "sk-stored", EnvDeepSeekAPIKey: "sk-stored"}}
+		mocks.configLoader.LoadFunc = func() (config.Config, error) {
+			return config.Config{OutputDir: t.TempDir()}, nil
+		}
+		mocks.prober.VerifyAPIKeyFunc = func(ctx context.Context, provider Provider, key string) error {
+			if provider.IsDeepSeek() {
+				return fmt.Errorf("invalid api key: %w", apierr.ErrAuthFailed)
+			}
+			return nil
+		}
+
+		var out bytes.Buffer
+		_ = runConfigDoctor(context.Background(), &out, env)
+		for _, want := range []string{
+			"✓ OPENAI_API_KEY    valid (keyring)",
+			"✗ DEEPSEEK_API_KEY  rejected by deepseek (keyring)",
+			"store it with: transcript config set-key --provider deepseek",
+			"✓ ANTHROPIC_API_KEY not set",
+		} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("output = %q, want containing %q", out.String(), want)
+			}
+		}
+	})
+
+	t.Run("Azure OpenAI key is not verified with OpenAI", func(t *testing.T) {
+		writeTestConfig(t, "")
+		env, mocks := testEnv(func(o *testEnvOptions) {
+			o.getenv = staticEnv(map[string]string{EnvOpenAIAPIKey: "azure-key", EnvDeepSeekAPIKey: "sk-test"})
+		})
+		mocks.configLoader.LoadFunc = func() (config.Config, error) {
+			return config.Config{OutputDir: t.TempDir(), AzureEndpoint: "https://contoso.openai.azure.com"}, nil
+		}
+		var verified []Provider
+		mocks.prober.VerifyAPIKeyFunc = func(ctx context.Context, provider Provider, key string) error {
+			verified = append(verified, provider)
+			return nil
+		}
+
+		var out bytes.Buffer
+		_ = runConfigDoctor(context.Background(), &out, env)
+		if want := "✓ OPENAI_API_KEY    set, for https://contoso.openai.azure.com (not verified)"; !strings.Contains(out.String(), want) {
+			t.Errorf("output = %q, want containing %q", out.String(), want)
+		}
+		if slices.Contains(verified, OpenAIProvider) {
+			t.Errorf("verified providers = %v, want the OpenAI key not verified", verified)
+		}
+	})
+
+	t.Run("key of the configured transcription provider", func(t *testing.T) {
+		writeTestConfig(t, "")
+		env, mocks := testEnv(func(o *testEnvOptions) { o.getenv = staticEnv(map[string]string{EnvDeepSeekAPIKey: "sk-test"}) })
+		mocks.configLoader.LoadFunc = func() (config.Config, error) {
+			return config.Config{OutputDir: t.TempDir(), Transcriber: "groq"}, nil
+		}
+
+		var out bytes.Buffer
+		err := runConfigDoctor(context.Background(), &out, env)
+		if !errors.Is(err, ErrChecksFailed) {
+			t.Fatalf("runConfigDoctor() error = %v, want ErrChecksFailed", err)
+		}
+		for _, want := range []string{
+			"! OPENAI_API_KEY    not set",
+			"✗ GROQ_API_KEY      not set, needed to transcribe with groq",
+			"→ Set it with: export GROQ_API_KEY=...",
+		} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("output = %q, want containing %q", out.String(), want)
+			}
+		}
+	})
+
+	t.Run("missing OpenAI key of the transcriber fails", func(t *testing.T) {
+		writeTestConfig(t, "")
+		env, mocks := testEnv(func(o *testEnvOptions) { o.getenv = staticEnv(nil) })
+		mocks.configLoader.LoadFunc = func() (config.Config, error) {
+			return config.Config{OutputDir: t.TempDir()}, nil
+		}
+		mocks.prober.DetectLoopbackFunc = func(ctx context.Context, ffmpegPath string) (string, error) {
+			return "", fmt.Errorf("%w\n\nInstall BlackHole: brew install blackhole-2ch", audio.ErrLoopbackNotFound)
+		}
+
+		var out bytes.Buffer
+		err := runConfigDoctor(context.Background(), &out, env)
+		if !errors.Is(err, ErrChecksFailed) {
+			t.Fatalf("runConfigDoctor() error = %v, want ErrChecksFailed", err)
+		}
+		for _, want := range []string{
+			"✗ OPENAI_API_KEY    not set",
+			"→ Install BlackHole: brew install blackhole-2ch",
+		} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("output = %q, want containing %q", out.String(), want)
+			}
+		}
+		if len(mocks.prober.Verified()) != 0 {
+			t.Errorf("verified %v, want no key verified", mocks.prober.Verified())
+		}
+	})
+}
+
+func TestPrintCheck_Color(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	printCheck(&out, doctorCheck{status: checkFail, name: "output dir", detail: "not writable", hint: "Choose another"}, true)
+	want := "\x1b[31m✗\x1b[0m output dir        not writable\n    → Choose another\n"
+	if out.String() != want {
+		t.Errorf("printCheck() = %q, want %q", out.String(), want)
+	}
+}
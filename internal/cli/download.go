@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/alnah/go-transcript/internal/appdirs"
+	"github.com/alnah/go-transcript/internal/format"
+	"github.com/alnah/go-transcript/internal/progress"
+)
+
+// defaultMaxDownload is the default size limit of URL inputs (--max-download).
+const defaultMaxDownload = "2GB"
+
+// downloadingMessage starts the download progress line.
+const downloadingMessage = "Downloading..."
+
+// downloadInput downloads the URL input rawURL into a directory of its own
+// in the cache directory, refusing files over maxSize bytes (0: the downloader
+// default). The directory depends only on the URL, so that an interrupted
+// download resumes, and a failed transcription finds the file again when run
+// with --resume. done removes the download once transcribed (err nil), and
+// keeps it otherwise.
+func downloadInput(ctx context.Context, env *Env, rawURL string, maxSize int64) (path string, done func(err error), err error) {
+	dir, err := downloadDir(env, rawURL)
+	if err != nil {
+		return "", nil, err
+	}
+
+	progress.PhaseChange(ctx, progress.PhaseDownloading)
+	fmt.Fprint(env.Stderr, downloadingMessage)
+	draw := isTerminal(env.Stderr)
+	last := ""
+	path, err = env.DownloaderFactory.NewDownloader(maxSize).Download(ctx, rawURL, dir, func(n, total int64) {
+		progress.Download(ctx, n, total)
+		if !draw {
+			return
+		}
+		status := format.Size(n)
+		if total > 0 {
+			status = fmt.Sprintf("%d%%", min(100*n/total, 100))
+		}
+		if status != last {
+			last = status
+			fmt.Fprintf(env.Stderr, "\r%s %s", downloadingMessage, status)
+		}
+	})
+	if err != nil {
+		fmt.Fprintln(env.Stderr)
+		return "", nil, err
+	}
+	fmt.Fprintf(env.Stderr, "\r%s done: %s\n", downloadingMessage, filepath.Base(path))
+
+	done = func(err error) {
+		if err != nil {
+			return // Kept for the next run
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			warnf(env.Stderr, warnFileNotSaved, "failed to remove download: %v", err)
+		}
+	}
+	return path, done, nil
+}
+
+// downloadDir returns the download directory of rawURL, named after its hash
+// in the cache directory.
+func downloadDir(env *Env, rawURL string) (string, error) {
+	dirs, err := appdirs.Resolve(runtime.GOOS, env.Getenv, os.UserHomeDir)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(dirs.Cache, "downloads", hex.EncodeToString(sum[:8])), nil
+}
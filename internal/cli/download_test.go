@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownloadDir(t *testing.T) {
+	t.Parallel()
+
+	cache := t.TempDir()
+	env, _ := testEnv(func(o *testEnvOptions) {
+		o.getenv = func(key string) string {
+			if key == "XDG_CACHE_HOME" {
+				return cache
+			}
+			return ""
+		}
+	})
+
+	first, err := downloadDir(env, "https://example.com/episode.mp3")
+	if err != nil {
+		t.Fatalf("downloadDir() unexpected error: %v", err)
+	}
+	again, _ := downloadDir(env, "https://example.com/episode.mp3")
+	other, _ := downloadDir(env, "https://example.com/episode.mp3?part=2")
+
+	if want := filepath.Join(cache, "go-transcript", "downloads"); !strings.HasPrefix(first, want) {
+		t.Errorf("downloadDir() = %q, want in %s", first, want)
+	}
+	if first != again {
+		t.Errorf("downloadDir() = %q then %q, want the same directory for a URL", first, again)
+	}
+	if first == other {
+		t.Errorf("downloadDir() = %q for two URLs, want a directory each", first)
+	}
+}
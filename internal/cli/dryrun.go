@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/format"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// transcribePlan is the estimated work and cost of a transcribe run.
+type transcribePlan struct {
+	chunks []audio.Chunk
+	audio  time.Duration // Audio sent for transcription (sum of the chunks)
+
+	backend            Backend
+	transcriptionModel string  // Empty for the local and gRPC backends
+	transcriptionCost  float64 // US dollars
+	transcriptionKnown bool    // The model has a known price
+
+	restructureModel string // Empty without --template
+	restructure      restructure.Estimate
+	restructureCost  float64 // US dollars
+	restructureKnown bool    // The model has a known price (local models are free)
+	restructureLocal bool
+}
+
+// total returns the estimated cost of the run, in US dollars.
+func (p transcribePlan) total() float64 {
+	return p.transcriptionCost + p.restructureCost
+}
+
+// runTranscribeDryRun plans the transcription of opts.inputPath and prints
+// the chunks and the estimated API usage and cost to w, without calling any API.
+func runTranscribeDryRun(ctx context.Context, env *Env, w io.Writer, opts transcribeOptions, cfg config.Config) error {
+	ffmpegPath, err := env.FFmpegResolver.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	chunker, err := opts.chunking.newChunker(env, ffmpegPath, "") // Planning creates no temp directory
+	if err != nil {
+		return err
+	}
+	planner, ok := chunker.(audio.Planner)
+	if !ok {
+		return fmt.Errorf("chunker cannot plan chunks without extracting them")
+	}
+	chunks, err := planner.Plan(ctx, opts.inputPath)
+	if err != nil {
+		return err
+	}
+
+	plan := planTranscribe(chunks, opts, cfg)
+	printTranscribePlan(w, opts, plan)
+	return nil
+}
+
+// planTranscribe estimates the usage and cost of transcribing chunks, and of
+// restructuring the transcript if opts has a template.
+func planTranscribe(chunks []audio.Chunk, opts transcribeOptions, cfg config.Config) transcribePlan {
+	plan := transcribePlan{chunks: chunks, backend: opts.backend.OrDefault()}
+	for _, c := range chunks {
+		plan.audio += c.Duration()
+	}
+	minutes := plan.audio.Minutes()
+
+	plan.transcriptionModel = opts.backend.transcriptionModel(transcribe.Options{
+		Diarize:    opts.diarize,
+		Timestamps: timedResults(opts.format, opts.template, opts.timestamps),
+		Translate:  opts.translateAudio,
+	})
+	if plan.transcriptionModel != "" {
+		if price, ok := transcribe.PricePerMinute(plan.transcriptionModel); ok {
+			plan.transcriptionCost = minutes * price
+			plan.transcriptionKnown = true
+		}
+	}
+
+	if opts.template.IsZero() {
+		return plan
+	}
+	provider := opts.provider.OrDefault()
+	plan.restructureModel = providerModel(provider, restructureModel(opts.model, cfg), ollamaConfig(cfg))
+	plan.restructureLocal = provider.IsOllama()
+
+	transcriptTokens := int(minutes * restructure.TokensPerMinute)
+	plan.restructure, plan.restructureCost, plan.restructureKnown = estimateRestructure(
+		provider, plan.restructureModel, cfg.ContextWindows, cfg.RestructureChunkTokens, transcriptTokens, opts.template, opts.selfConsistency)
+	return plan
+}
+
+// providerModel returns the model restructuring uses with provider:
+// model if set, otherwise the provider default.
+func providerModel(provider Provider, model string, ollama OllamaConfig) string {
+	if model != "" {
+		return model
+	}
+	switch {
+	case provider.IsOpenAI():
+		return restructure.DefaultOpenAIModel
+	case provider.IsAnthropic():
+		return restructure.DefaultAnthropicModel
+	case provider.IsOllama():
+		if ollama.Model != "" {
+			return ollama.Model
+		}
+		return restructure.DefaultOllamaModel
+	default:
+		return restructure.DefaultDeepSeekModel
+	}
+}
+
+// printTranscribePlan writes the chunks and estimates of plan.
+func printTranscribePlan(w io.Writer, opts transcribeOptions, plan transcribePlan) {
+	fmt.Fprintf(w, "Dry run: %s (no API calls made)\n\n", opts.inputPath)
+
+	fmt.Fprintf(w, "Chunks: %d (%s of audio)\n", len(plan.chunks), format.DurationHuman(plan.audio))
+	for _, c := range plan.chunks {
+		fmt.Fprintf(w, "  %3d  %s - %s  (%s)\n", c.Index,
+			format.Duration(c.StartTime), format.Duration(c.EndTime), format.DurationHuman(c.Duration()))
+	}
+	fmt.Fprintln(w)
+
+	switch {
+	case plan.transcriptionModel == "":
+		fmt.Fprintf(w, "Transcription: %s backend, free\n", plan.backend)
+	case plan.transcriptionKnown:
+		fmt.Fprintf(w, "Transcription: %s, %.1f min, ~$%.2f\n",
+			plan.transcriptionModel, plan.audio.Minutes(), plan.transcriptionCost)
+	default:
+		fmt.Fprintf(w, "Transcription: %s, %.1f min, price unknown\n", plan.transcriptionModel, plan.audio.Minutes())
+	}
+
+	if plan.restructureModel != "" {
+		est := plan.restructure
+		calls := "1 call"
+		switch {
+		case est.Runs > 0 && est.Parts > 0:
+			calls = fmt.Sprintf("%d calls: %d runs of %d parts + merge, then a final merge", est.Calls, est.Runs, est.Parts)
+		case est.Runs > 0:
+			calls = fmt.Sprintf("%d calls: %d runs + merge", est.Calls, est.Runs)
+		case est.Parts > 0:
+			calls = fmt.Sprintf("%d calls: %d parts + merge", est.Calls, est.Parts)
+		}
+		fmt.Fprintf(w, "Restructuring: %s (%s), %s, ~%d prompt + ~%d completion tokens",
+			plan.restructureModel, opts.provider.OrDefault(), calls,
+			est.Usage.PromptTokens, est.Usage.CompletionTokens)
+		switch {
+		case plan.restructureLocal:
+			fmt.Fprintln(w, ", free")
+		case plan.restructureKnown && est.Runs > 0 && plan.restructureCost > maxCost(opts.maxCost):
+			fmt.Fprintf(w, ", ~$%.2f (above --max-cost $%.2f: the run would be refused)\n",
+				plan.restructureCost, maxCost(opts.maxCost))
+		case plan.restructureKnown:
+			fmt.Fprintf(w, ", ~$%.2f\n", plan.restructureCost)
+		default:
+			fmt.Fprintln(w, ", price unknown")
+		}
+	}
+
+	fmt.Fprintf(w, "Estimated total: ~$%.2f\n", plan.total())
+	fmt.Fprintln(w, "Estimates assume ~150 spoken words per minute; actual usage depends on the speech.")
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// tenMinuteChunks returns two 5-minute chunks.
+func tenMinuteChunks() []audio.Chunk {
+	return []audio.Chunk{
+		{Index: 0, StartTime: 0, EndTime: 5 * time.Minute},
+		{Index: 1, StartTime: 5 * time.Minute, EndTime: 10 * time.Minute},
+	}
+}
+
+func TestRunTranscribe_DryRun(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	stdout := &bytes.Buffer{}
+	cmd := createTranscribeCmd(context.Background())
+	cmd.SetOut(stdout)
+
+	chunker := &mockChunker{
+		ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+			return tenMinuteChunks(), nil
+		},
+	}
+	transcriberFactory := &mockTranscriberFactory{}
+	env := &Env{
+		Stderr:              &syncBuffer{},
+		Getenv:              func(string) string { return "" }, // No API key needed
+		Now:                 fixedTime(time.Now()),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        &mockConfigLoader{},
+		ChunkerFactory:      &mockChunkerFactory{NewSilenceChunkerFunc: func(string) (audio.Chunker, error) { return chunker, nil }},
+		TranscriberFactory:  transcriberFactory,
+		RestructurerFactory: &mockRestructurerFactory{},
+	}
+
+	opts := mustParseTranscribeOptions(t, inputPath, "", "meeting", false, 5, "", "", "deepseek")
+	opts.dryRun = true
+	if err := RunTranscribe(cmd, env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+
+	output := stdout.String()
+	for _, want := range []string{
+		"Chunks: 2 (10m of audio)",
+		"05:00 - 10:00",
+		"Transcription: " + transcribe.ModelGPT4oMiniTranscribe + ", 10.0 min, ~$0.03",
+		"Restructuring: " + restructure.DefaultDeepSeekModel + " (deepseek), 1 call",
+		"Estimated total:",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("dry run output missing %q:\n%s", want, output)
+		}
+	}
+	if len(transcriberFactory.newTranscriberCalls) != 0 || len(chunker.chunkCalls) != 1 {
+		t.Errorf("dry run created a transcriber or extracted chunks more than once")
+	}
+}
+
+func TestPlanTranscribe(t *testing.T) {
+	t.Parallel()
+
+	meeting := template.MustParseName("meeting")
+
+	t.Run("local backend is free", func(t *testing.T) {
+		t.Parallel()
+
+		plan := planTranscribe(tenMinuteChunks(), transcribeOptions{backend: LocalBackend}, config.Config{})
+		if plan.transcriptionModel != "" || plan.total() != 0 {
+			t.Errorf("plan = %+v, want a free local transcription", plan)
+		}
+	})
+
+	t.Run("diarization model", func(t *testing.T) {
+		t.Parallel()
+
+		plan := planTranscribe(tenMinuteChunks(), transcribeOptions{diarize: true}, config.Config{})
+		if plan.transcriptionModel != transcribe.ModelGPT4oTranscribeDiarize {
+			t.Errorf("transcription model = %q, want %q", plan.transcriptionModel, transcribe.ModelGPT4oTranscribeDiarize)
+		}
+	})
+
+	t.Run("provider model is priced", func(t *testing.T) {
+		t.Parallel()
+
+		plan := planTranscribe(tenMinuteChunks(), transcribeOptions{backend: Backend{name: transcribe.ProviderDeepgram}}, config.Config{})
+		if plan.transcriptionModel != transcribe.ModelDeepgramNova3 || !plan.transcriptionKnown || plan.transcriptionCost <= 0 {
+			t.Errorf("plan = %+v, want a priced %s transcription", plan, transcribe.ModelDeepgramNova3)
+		}
+	})
+
+	t.Run("ollama restructuring is free", func(t *testing.T) {
+		t.Parallel()
+
+		opts := transcribeOptions{template: meeting, provider: OllamaProvider}
+		plan := planTranscribe(tenMinuteChunks(), opts, config.Config{OllamaModel: "qwen2.5"})
+		if plan.restructureModel != "qwen2.5" || plan.restructureCost != 0 {
+			t.Errorf("plan = %+v, want free qwen2.5 restructuring", plan)
+		}
+	})
+
+	t.Run("long recording needs map reduce", func(t *testing.T) {
+		t.Parallel()
+
+		chunks := []audio.Chunk{{Index: 0, EndTime: 10 * time.Hour}}
+		opts := transcribeOptions{template: meeting, provider: OpenAIProvider, model: "gpt-4o"}
+		plan := planTranscribe(chunks, opts, config.Config{})
+		if plan.restructure.Parts < 2 || !plan.restructureKnown || plan.restructureCost <= 0 {
+			t.Errorf("plan = %+v, want a priced map-reduce", plan)
+		}
+	})
+
+	t.Run("self-consistency multiplies restructuring", func(t *testing.T) {
+		t.Parallel()
+
+		single := planTranscribe(tenMinuteChunks(), transcribeOptions{template: meeting}, config.Config{})
+		plan := planTranscribe(tenMinuteChunks(), transcribeOptions{template: meeting, selfConsistency: 3}, config.Config{})
+		if plan.restructure.Runs != 3 || plan.restructure.Calls != 4 {
+			t.Errorf("plan = %+v, want 3 runs and 4 calls", plan.restructure)
+		}
+		if plan.restructureCost <= 3*single.restructureCost {
+			t.Errorf("restructure cost = %v, want more than 3 runs of %v", plan.restructureCost, single.restructureCost)
+		}
+
+		var buf bytes.Buffer
+		printTranscribePlan(&buf, transcribeOptions{template: meeting, selfConsistency: 3, maxCost: 0.000001}, plan)
+		for _, want := range []string{"4 calls: 3 runs + merge", "the run would be refused"} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("plan output missing %q:\n%s", want, buf.String())
+			}
+		}
+	})
+}
+
+func TestProviderModel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		provider Provider
+		model    string
+		ollama   OllamaConfig
+		want     string
+	}{
+		{"explicit model", OpenAIProvider, "gpt-4.1", OllamaConfig{}, "gpt-4.1"},
+		{"deepseek default", DeepSeekProvider, "", OllamaConfig{}, restructure.DefaultDeepSeekModel},
+		{"openai default", OpenAIProvider, "", OllamaConfig{}, restructure.DefaultOpenAIModel},
+		{"anthropic default", AnthropicProvider, "", OllamaConfig{}, restructure.DefaultAnthropicModel},
+		{"ollama configured", OllamaProvider, "", OllamaConfig{Model: "mistral"}, "mistral"},
+		{"ollama default", OllamaProvider, "", OllamaConfig{}, restructure.DefaultOllamaModel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := providerModel(tt.provider, tt.model, tt.ollama); got != tt.want {
+				t.Errorf("providerModel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+//go:build e2e
+
+package cli
+
+// Notes:
+// - These tests run the commands end to end: real FFmpeg chunking and real
+//   API clients, against servers replaying the cassettes of testdata/cassettes
+// - No API key is needed to replay. With TRANSCRIPT_RECORD=1 and the keys set,
+//   the same tests call the real APIs and record the cassettes again
+// - The cassettes committed are synthetic fixtures, written by hand in the
+//   format of the recorder, not recorded from the APIs: they check the
+//   requests the flows make and replay plausible answers. Recording them with
+//   make record-e2e replaces them with real exchanges
+// - Tests of audio flows skip when FFmpeg is not installed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/ffmpeg"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/testkit"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// Base URLs of the real APIs, forwarded to when recording.
+const (
+	openAIUpstream   = "https://api.openai.com"
+	deepSeekUpstream = "https://api.deepseek.com"
+)
+
+// e2eSpeech is the synthetic audio of the tests: five utterances of four
+// seconds separated by pauses of two seconds.
+var e2eSpeech = testkit.Speech(5, 4*time.Second, 2*time.Second)
+
+// ---------------------------------------------------------------------------
+// Harness
+// ---------------------------------------------------------------------------
+
+// e2eEnv returns an Env running the real pipeline, with the APIs of apis
+// ("openai", "deepseek") served from the cassettes
+// testdata/cassettes/<test name>_<api>.json. Requests to other APIs fail the
+// test.
+func e2eEnv(t *testing.T, stderr *syncBuffer, apis ...string) *Env {
+	t.Helper()
+
+	urls := map[string]string{}
+	for _, api := range []string{"openai", "deepseek"} {
+		urls[api] = unexpectedAPI(t, api)
+	}
+	upstreams := map[string]string{"openai": openAIUpstream, "deepseek": deepSeekUpstream}
+	for _, api := range apis {
+		path := filepath.Join("testdata", "cassettes", t.Name()+"_"+api+".json")
+		urls[api] = testkit.NewServer(t, path, upstreams[api]).URL
+	}
+
+	return NewEnv(
+		WithStderr(stderr),
+		WithGetenv(e2eGetenv),
+		WithFFmpegResolver(lookupFFmpegResolver{}),
+		WithConfigLoader(&mockConfigLoader{LoadFunc: func() (config.Config, error) {
+			return config.Config{}, nil
+		}}),
+		WithTranscriberFactory(cassetteTranscriberFactory{baseURL: urls["openai"]}),
+		WithRestructurerFactory(cassetteRestructurerFactory{openAIURL: urls["openai"], deepSeekURL: urls["deepseek"]}),
+		WithNotifier(&mockNotifier{}),
+	)
+}
+
+// e2eGetenv returns placeholder API keys, or the real ones when recording.
+// Other variables are unset, so that the environment of the machine does not
+// change the flows.
+func e2eGetenv(key string) string {
+	switch key {
+	case EnvOpenAIAPIKey, EnvDeepSeekAPIKey:
+		if testkit.Recording() {
+			return os.Getenv(key)
+		}
+		return "test-key"
+	}
+	return ""
+}
+
+// unexpectedAPI returns the URL of a server failing the test on any request,
+// standing in for an API the test has no cassette of.
+func unexpectedAPI(t *testing.T, api string) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected %s request: %s %s", api, r.Method, r.URL.Path)
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// requireFFmpeg skips the test if FFmpeg is not installed.
+func requireFFmpeg(t *testing.T) {
+	t.Helper()
+	if _, err := ffmpeg.Lookup(); err != nil {
+		t.Skipf("skipping: %v", err)
+	}
+}
+
+// writeSpeech writes the synthetic speech of the tests to a file named name.
+func writeSpeech(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := testkit.WriteWAV(path, e2eSpeech...); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// runE2E runs the command of newCmd with args, and returns the content of
+// the output file.
+func runE2E(t *testing.T, newCmd func(*Env) *cobra.Command, env *Env, args []string, output string) string {
+	t.Helper()
+
+	cmd := newCmd(env)
+	cmd.SetArgs(args)
+	cmd.SetContext(context.Background())
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("%s %s: unexpected error: %v\nstderr:\n%s", cmd.Name(), strings.Join(args, " "), err, env.Stderr)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	return string(data)
+}
+
+// lookupFFmpegResolver resolves the installed FFmpeg, never downloading it.
+type lookupFFmpegResolver struct{}
+
+func (lookupFFmpegResolver) Resolve(context.Context) (string, error) { return ffmpeg.Lookup() }
+func (lookupFFmpegResolver) CheckVersion(context.Context, string)    {}
+
+// cassetteTranscriberFactory creates OpenAI transcribers calling baseURL.
+type cassetteTranscriberFactory struct {
+	defaultTranscriberFactory
+	baseURL string
+}
+
+func (f cassetteTranscriberFactory) NewTranscriber(apiKey string) transcribe.Transcriber {
+	return transcribe.NewOpenAITranscriber(apiKey, transcribe.WithBaseURL(f.baseURL))
+}
+
+// cassetteRestructurerFactory creates OpenAI and DeepSeek restructurers
+// calling the given URLs.
+type cassetteRestructurerFactory struct {
+	defaultRestructurerFactory
+	openAIURL, deepSeekURL string
+}
+
+func (f cassetteRestructurerFactory) NewMapReducer(provider Provider, apiKey, model string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
+	switch {
+	case provider.IsDeepSeek():
+		dsOpts := []restructure.DeepSeekOption{restructure.WithDeepSeekBaseURL(f.deepSeekURL)}
+		if model != "" {
+			dsOpts = append(dsOpts, restructure.WithDeepSeekModel(model))
+		}
+		r, err := restructure.NewDeepSeekRestructurer(apiKey, dsOpts...)
+		if err != nil {
+			return nil, err
+		}
+		return restructure.NewMapReduceRestructurer(r, opts...), nil
+	case provider.IsOpenAI():
+		oaOpts := []restructure.Option{restructure.WithBaseURL(f.openAIURL)}
+		if model != "" {
+			oaOpts = append(oaOpts, restructure.WithModel(model))
+		}
+		return restructure.NewMapReduceRestructurer(restructure.NewOpenAIRestructurer(apiKey, oaOpts...), opts...), nil
+	}
+	return f.defaultRestructurerFactory.NewMapReducer(provider, apiKey, model, opts...)
+}
+
+// speechRecorderFactory creates recorders writing the synthetic speech of the
+// tests instead of recording a device.
+type speechRecorderFactory struct{}
+
+func (speechRecorderFactory) NewRecorder(string, string) (audio.Recorder, error) {
+	return speechRecorder{}, nil
+}
+
+func (speechRecorderFactory) NewLoopbackRecorder(context.Context, string) (audio.Recorder, error) {
+	return speechRecorder{}, nil
+}
+
+func (speechRecorderFactory) NewMixRecorder(context.Context, string, string) (audio.Recorder, error) {
+	return speechRecorder{}, nil
+}
+
+type speechRecorder struct{}
+
+// Record writes WAV data whatever the extension of output: FFmpeg detects
+// the format of its inputs from their content.
+func (speechRecorder) Record(_ context.Context, _ time.Duration, output string) error {
+	return testkit.WriteWAV(output, e2eSpeech...)
+}
+
+// ---------------------------------------------------------------------------
+// Flows
+// ---------------------------------------------------------------------------
+
+func TestE2E_Transcribe(t *testing.T) {
+	requireFFmpeg(t)
+
+	input := writeSpeech(t, "standup.wav")
+	output := filepath.Join(t.TempDir(), "standup.md")
+	env := e2eEnv(t, &syncBuffer{}, "openai")
+
+	got := runE2E(t, TranscribeCmd, env, []string{input, "-o", output}, output)
+
+	if !strings.Contains(got, "Yesterday I finished the release notes.") {
+		t.Errorf("output = %q, want the recorded transcript", got)
+	}
+}
+
+func TestE2E_TranscribeWithTemplate(t *testing.T) {
+	requireFFmpeg(t)
+
+	input := writeSpeech(t, "standup.wav")
+	output := filepath.Join(t.TempDir(), "standup.md")
+	env := e2eEnv(t, &syncBuffer{}, "openai", "deepseek")
+
+	got := runE2E(t, TranscribeCmd, env, []string{input, "-o", output, "-t", "meeting"}, output)
+
+	if !strings.Contains(got, "## Action Items") || !strings.Contains(got, "Review the release notes") {
+		t.Errorf("output = %q, want the recorded meeting notes", got)
+	}
+}
+
+func TestE2E_Live(t *testing.T) {
+	requireFFmpeg(t)
+
+	output := filepath.Join(t.TempDir(), "live.md")
+	env := e2eEnv(t, &syncBuffer{}, "openai", "deepseek")
+	env.RecorderFactory = speechRecorderFactory{}
+
+	got := runE2E(t, LiveCmd, env, []string{"-d", "30s", "-o", output, "-t", "notes"}, output)
+
+	if !strings.Contains(got, "release notes") {
+		t.Errorf("output = %q, want the recorded notes", got)
+	}
+}
+
+func TestE2E_Structure(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "standup.md")
+	transcript := "Yesterday I finished the release notes. Today I review the changelog with Sam. " +
+		"No blockers on my side. Let's ship on Thursday then."
+	if err := os.WriteFile(input, []byte(transcript), 0600); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(t.TempDir(), "standup_meeting.md")
+	env := e2eEnv(t, &syncBuffer{}, "openai")
+
+	got := runE2E(t, StructureCmd, env, []string{input, "-o", output, "-t", "meeting", "--provider", "openai"}, output)
+
+	if !strings.Contains(got, "Ship on Thursday") {
+		t.Errorf("output = %q, want the recorded meeting notes", got)
+	}
+}
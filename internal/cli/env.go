@@ -2,6 +2,8 @@ package cli
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -9,9 +11,14 @@ import (
 
 	"github.com/alnah/go-transcript/internal/audio"
 	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/desktop"
+	"github.com/alnah/go-transcript/internal/fetch"
 	"github.com/alnah/go-transcript/internal/ffmpeg"
+	"github.com/alnah/go-transcript/internal/keyring"
 	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/telegram"
 	"github.com/alnah/go-transcript/internal/transcribe"
+	"github.com/alnah/go-transcript/internal/watch"
 )
 
 // Env holds injectable dependencies for CLI commands.
@@ -27,6 +34,9 @@ type Env struct {
 	Stderr io.Writer
 	Getenv func(string) string
 	Now    func() time.Time
+	// Version is the version of transcript, recorded in provenance footers.
+	// Empty means a development build.
+	Version string
 
 	// Factories for domain objects
 	FFmpegResolver      FFmpegResolver
@@ -36,6 +46,36 @@ type Env struct {
 	ChunkerFactory      ChunkerFactory
 	RecorderFactory     RecorderFactory
 	DeviceListerFactory DeviceListerFactory
+	// FingerprinterFactory detects intros and outros repeated from earlier recordings.
+	FingerprinterFactory FingerprinterFactory
+	// AudioExtractorFactory extracts the audio track of video inputs.
+	AudioExtractorFactory AudioExtractorFactory
+	// RepairerFactory repairs recordings cut short by a crash, for recover.
+	RepairerFactory RepairerFactory
+	// PreprocessorFactory denoises and normalizes audio before transcription.
+	PreprocessorFactory PreprocessorFactory
+	// MediaProberFactory checks input files before transcription.
+	MediaProberFactory MediaProberFactory
+	// DownloaderFactory downloads URL inputs.
+	DownloaderFactory DownloaderFactory
+	// LevelMeterFactory measures input levels for devices --test.
+	LevelMeterFactory LevelMeterFactory
+	// BotFactory connects to the Telegram Bot API for the bot command.
+	BotFactory BotFactory
+	// WatcherFactory watches directories for the watch command.
+	WatcherFactory WatcherFactory
+	// Notifier shows the desktop notifications of --notify.
+	Notifier desktop.Notifier
+	// Prober checks the environment for config doctor.
+	Prober Prober
+	// FFmpegInstaller reports and upgrades the FFmpeg download, for the ffmpeg command.
+	FFmpegInstaller FFmpegInstaller
+	// Keyring holds the API keys stored with config set-key (optional: nil
+	// reads keys from the environment only).
+	Keyring keyring.Keyring
+	// TerminalFactory opens the interactive terminal of live --tui
+	// (optional: nil means no terminal).
+	TerminalFactory TerminalFactory
 }
 
 // FFmpegResolver resolves the path to the FFmpeg binary.
@@ -52,6 +92,40 @@ type ConfigLoader interface {
 // TranscriberFactory creates transcribers for audio-to-text conversion.
 type TranscriberFactory interface {
 	NewTranscriber(apiKey string) transcribe.Transcriber
+	// NewLocalTranscriber creates an offline transcriber (--transcriber local).
+	NewLocalTranscriber(cfg LocalTranscriberConfig) (transcribe.Transcriber, error)
+	// NewGRPCTranscriber creates a transcriber calling an ASR server (--transcriber grpc).
+	NewGRPCTranscriber(cfg GRPCTranscriberConfig) (transcribe.Transcriber, error)
+	// NewAzureTranscriber creates a transcriber calling the transcription
+	// deployment of an Azure OpenAI resource (--transcriber openai with azure-endpoint).
+	NewAzureTranscriber(apiKey string, cfg AzureConfig) transcribe.Transcriber
+	// NewProviderTranscriber creates a transcriber calling the speech-to-text
+	// API registered as provider in the transcribe package (--transcriber groq, ...).
+	NewProviderTranscriber(provider, apiKey string) (transcribe.Transcriber, error)
+}
+
+// LocalTranscriberConfig configures local transcription.
+type LocalTranscriberConfig struct {
+	ModelPath  string // whisper.cpp model file
+	BinaryPath string // whisper.cpp binary (empty: look up in PATH)
+	ServerURL  string // OpenAI-compatible local server, used instead of whisper.cpp if set
+	FFmpegPath string // Converts chunks to the format whisper.cpp reads
+}
+
+// GRPCTranscriberConfig configures transcription by a gRPC ASR server.
+type GRPCTranscriberConfig struct {
+	Endpoint  string        // host:port of the server
+	Plaintext bool          // Connect without TLS
+	Timeout   time.Duration // Deadline of each chunk (zero: default)
+	TLS       *tls.Config   // CA bundle and client certificate (nil: system defaults)
+}
+
+// AzureConfig configures OpenAI requests to an Azure OpenAI resource.
+type AzureConfig struct {
+	Endpoint             string // https://NAME.openai.azure.com (empty: OpenAI is called)
+	TranscribeDeployment string // Deployment of the transcription model
+	ChatDeployment       string // Deployment of the restructuring model
+	APIVersion           string // api-version of the requests (empty: package default)
 }
 
 // Restructuring provider constants.
@@ -60,19 +134,39 @@ const (
 	ProviderDeepSeek = "deepseek"
 	// ProviderOpenAI uses OpenAI API for restructuring.
 	ProviderOpenAI = "openai"
+	// ProviderAnthropic uses Anthropic API (Claude) for restructuring.
+	ProviderAnthropic = "anthropic"
+	// ProviderOllama uses a local Ollama server for offline restructuring.
+	ProviderOllama = "ollama"
 )
 
 // RestructurerFactory creates restructurers for transcript formatting.
 type RestructurerFactory interface {
-	// NewMapReducer creates a MapReducer configured with the given provider, API key, and options.
-	// Provider must be a valid Provider (DeepSeekProvider or OpenAIProvider).
+	// NewMapReducer creates a MapReducer configured with the given provider, API key, model, and options.
+	// Provider must be a valid Provider (DeepSeekProvider, OpenAIProvider,
+	// AnthropicProvider or OllamaProvider, which ignores apiKey and uses the
+	// default server). An empty model selects the provider default.
 	// This is the primary method for creating restructurers in CLI commands.
-	NewMapReducer(provider Provider, apiKey string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error)
+	NewMapReducer(provider Provider, apiKey, model string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error)
+	// NewOllamaMapReducer creates a MapReducer using a local Ollama server (--provider ollama).
+	NewOllamaMapReducer(cfg OllamaConfig, opts ...restructure.MapReduceOption) (restructure.MapReducer, error)
+	// NewAzureMapReducer creates a MapReducer calling the chat deployment of an
+	// Azure OpenAI resource (--provider openai with azure-endpoint). model is
+	// the model of the deployment, sizing the chunks (empty: the OpenAI default).
+	NewAzureMapReducer(apiKey, model string, cfg AzureConfig, opts ...restructure.MapReduceOption) (restructure.MapReducer, error)
+}
+
+// OllamaConfig configures restructuring with Ollama.
+type OllamaConfig struct {
+	BaseURL string // Ollama server (empty: restructure.DefaultOllamaBaseURL)
+	Model   string // Model name (empty: restructure.DefaultOllamaModel)
 }
 
 // ChunkerFactory creates audio chunkers.
 type ChunkerFactory interface {
-	NewSilenceChunker(ffmpegPath string) (audio.Chunker, error)
+	// NewChunker creates the chunker of a strategy registered in the audio
+	// package (see audio.NewChunker). An empty strategy means silence.
+	NewChunker(strategy string, cfg audio.ChunkerConfig) (audio.Chunker, error)
 }
 
 // RecorderFactory creates audio recorders.
@@ -87,6 +181,102 @@ type DeviceListerFactory interface {
 	NewDeviceLister(ffmpegPath string) (audio.DeviceLister, error)
 }
 
+// FingerprinterFactory creates fingerprinters for intro and outro detection.
+type FingerprinterFactory interface {
+	NewFingerprinter(ffmpegPath string) (audio.Fingerprinter, error)
+}
+
+// AudioExtractorFactory creates extractors of the audio track of video files.
+type AudioExtractorFactory interface {
+	NewAudioExtractor(ffmpegPath string) (audio.AudioExtractor, error)
+}
+
+// RepairerFactory creates repairers of recordings cut short by a crash.
+type RepairerFactory interface {
+	NewRepairer(ffmpegPath string) (audio.Repairer, error)
+}
+
+// PreprocessorFactory creates preprocessors cleaning up audio before transcription.
+type PreprocessorFactory interface {
+	NewPreprocessor(ffmpegPath string) (audio.Preprocessor, error)
+}
+
+// MediaProberFactory creates probers checking input files before transcription.
+type MediaProberFactory interface {
+	NewMediaProber(ffmpegPath string) (MediaProber, error)
+}
+
+// MediaProber reads input files with FFmpeg (or ffprobe).
+type MediaProber interface {
+	// Probe returns the metadata of the media file at path.
+	Probe(ctx context.Context, path string) (ffmpeg.Metadata, error)
+	// VerifyAudio decodes the beginning of the first audio stream of path.
+	VerifyAudio(ctx context.Context, path string) error
+}
+
+// DownloaderFactory creates downloaders of remote inputs.
+type DownloaderFactory interface {
+	// NewDownloader creates a downloader refusing files over maxSize bytes.
+	NewDownloader(maxSize int64) fetch.Downloader
+}
+
+// LevelMeterFactory creates level meters for audio device checks.
+type LevelMeterFactory interface {
+	NewLevelMeter(ffmpegPath string) (audio.LevelMeter, error)
+}
+
+// BotFactory creates chat bot clients.
+type BotFactory interface {
+	NewTelegramBot(token string) (telegram.Bot, error)
+}
+
+// WatcherFactory creates directory watchers.
+type WatcherFactory interface {
+	// NewWatcher creates a watcher reporting files unchanged for settle.
+	NewWatcher(settle time.Duration) watch.Watcher
+}
+
+// Prober checks the environment without changing it, for config doctor.
+type Prober interface {
+	// LookupFFmpeg returns the FFmpeg binary in use and its version,
+	// without downloading it.
+	LookupFFmpeg(ctx context.Context) (path, version string, err error)
+	// VerifyAPIKey checks key with an authenticated call to provider.
+	VerifyAPIKey(ctx context.Context, provider Provider, key string) error
+	// DetectLoopback returns the name of the loopback device of --system-record.
+	DetectLoopback(ctx context.Context, ffmpegPath string) (string, error)
+}
+
+// FFmpegInstaller manages the FFmpeg binary downloaded by transcript.
+type FFmpegInstaller interface {
+	// Status returns the FFmpeg binary in use and the state of the download,
+	// without downloading anything.
+	Status() (ffmpeg.Status, error)
+	// Upgrade downloads the pinned FFmpeg version unless it is already
+	// installed, and returns whether it downloaded it.
+	Upgrade(ctx context.Context) (bool, error)
+	// Version returns the version printed by ffmpegPath.
+	Version(ctx context.Context, ffmpegPath string) (string, error)
+}
+
+// TerminalFactory opens the interactive terminal.
+type TerminalFactory interface {
+	// OpenTerminal switches the terminal to reading single key presses,
+	// without echo. Returns ErrNoTerminal if there is no terminal to use.
+	OpenTerminal() (Terminal, error)
+}
+
+// Terminal is an interactive terminal: key presses are read as they are
+// typed, and the screen is redrawn in place.
+type Terminal interface {
+	io.Reader // Key presses
+	io.Writer // Screen
+	// Size returns the width and height of the screen, in cells.
+	Size() (width, height int)
+	// Close restores the terminal settings.
+	Close() error
+}
+
 // EnvOption configures an Env.
 type EnvOption func(*Env)
 
@@ -160,19 +350,131 @@ func WithDeviceListerFactory(f DeviceListerFactory) EnvOption {
 	}
 }
 
+// WithFingerprinterFactory sets the fingerprinter factory.
+func WithFingerprinterFactory(f FingerprinterFactory) EnvOption {
+	return func(e *Env) {
+		e.FingerprinterFactory = f
+	}
+}
+
+// WithAudioExtractorFactory sets the audio extractor factory.
+func WithAudioExtractorFactory(f AudioExtractorFactory) EnvOption {
+	return func(e *Env) {
+		e.AudioExtractorFactory = f
+	}
+}
+
+// WithRepairerFactory sets the recording repairer factory.
+func WithRepairerFactory(f RepairerFactory) EnvOption {
+	return func(e *Env) {
+		e.RepairerFactory = f
+	}
+}
+
+// WithPreprocessorFactory sets the audio preprocessor factory.
+func WithPreprocessorFactory(f PreprocessorFactory) EnvOption {
+	return func(e *Env) {
+		e.PreprocessorFactory = f
+	}
+}
+
+// WithMediaProberFactory sets the media prober factory.
+func WithMediaProberFactory(f MediaProberFactory) EnvOption {
+	return func(e *Env) {
+		e.MediaProberFactory = f
+	}
+}
+
+// WithDownloaderFactory sets the downloader factory.
+func WithDownloaderFactory(f DownloaderFactory) EnvOption {
+	return func(e *Env) {
+		e.DownloaderFactory = f
+	}
+}
+
+// WithLevelMeterFactory sets the level meter factory.
+func WithLevelMeterFactory(f LevelMeterFactory) EnvOption {
+	return func(e *Env) {
+		e.LevelMeterFactory = f
+	}
+}
+
+// WithBotFactory sets the chat bot client factory.
+func WithBotFactory(f BotFactory) EnvOption {
+	return func(e *Env) {
+		e.BotFactory = f
+	}
+}
+
+// WithWatcherFactory sets the directory watcher factory.
+func WithWatcherFactory(f WatcherFactory) EnvOption {
+	return func(e *Env) {
+		e.WatcherFactory = f
+	}
+}
+
+// WithNotifier sets the desktop notifier.
+func WithNotifier(n desktop.Notifier) EnvOption {
+	return func(e *Env) {
+		e.Notifier = n
+	}
+}
+
+// WithProber sets the environment prober.
+func WithProber(p Prober) EnvOption {
+	return func(e *Env) {
+		e.Prober = p
+	}
+}
+
+// WithFFmpegInstaller sets the manager of the FFmpeg download.
+func WithFFmpegInstaller(i FFmpegInstaller) EnvOption {
+	return func(e *Env) {
+		e.FFmpegInstaller = i
+	}
+}
+
+// WithTerminalFactory sets the factory of the interactive terminal.
+func WithTerminalFactory(f TerminalFactory) EnvOption {
+	return func(e *Env) {
+		e.TerminalFactory = f
+	}
+}
+
+// WithKeyring sets the keyring holding API keys.
+func WithKeyring(k keyring.Keyring) EnvOption {
+	return func(e *Env) {
+		e.Keyring = k
+	}
+}
+
 // DefaultEnv returns an Env with production defaults.
 func DefaultEnv() *Env {
 	return &Env{
-		Stderr:              os.Stderr,
-		Getenv:              os.Getenv,
-		Now:                 time.Now,
-		FFmpegResolver:      &defaultFFmpegResolver{},
-		ConfigLoader:        &defaultConfigLoader{},
-		TranscriberFactory:  &defaultTranscriberFactory{},
-		RestructurerFactory: &defaultRestructurerFactory{},
-		ChunkerFactory:      &defaultChunkerFactory{},
-		RecorderFactory:     &defaultRecorderFactory{},
-		DeviceListerFactory: &defaultDeviceListerFactory{},
+		Stderr:                os.Stderr,
+		Getenv:                os.Getenv,
+		Now:                   time.Now,
+		FFmpegResolver:        &defaultFFmpegResolver{},
+		ConfigLoader:          &defaultConfigLoader{},
+		TranscriberFactory:    &defaultTranscriberFactory{},
+		RestructurerFactory:   &defaultRestructurerFactory{},
+		ChunkerFactory:        &defaultChunkerFactory{},
+		RecorderFactory:       &defaultRecorderFactory{},
+		DeviceListerFactory:   &defaultDeviceListerFactory{},
+		FingerprinterFactory:  &defaultFingerprinterFactory{},
+		AudioExtractorFactory: &defaultAudioExtractorFactory{},
+		RepairerFactory:       &defaultRepairerFactory{},
+		PreprocessorFactory:   &defaultPreprocessorFactory{},
+		MediaProberFactory:    &defaultMediaProberFactory{},
+		DownloaderFactory:     &defaultDownloaderFactory{},
+		LevelMeterFactory:     &defaultLevelMeterFactory{},
+		BotFactory:            &defaultBotFactory{},
+		WatcherFactory:        &defaultWatcherFactory{},
+		Notifier:              desktop.New(),
+		Prober:                &defaultProber{},
+		FFmpegInstaller:       &defaultFFmpegInstaller{},
+		Keyring:               keyring.New(),
+		TerminalFactory:       &defaultTerminalFactory{},
 	}
 }
 
@@ -201,19 +503,61 @@ func (defaultFFmpegResolver) CheckVersion(ctx context.Context, ffmpegPath string
 }
 
 // defaultConfigLoader implements ConfigLoader using the config package.
+// The project file of the working directory overrides the user config.
 type defaultConfigLoader struct{}
 
 func (defaultConfigLoader) Load() (config.Config, error) {
-	return config.Load()
+	cfg, err := config.Load()
+	wd, wdErr := os.Getwd()
+	if wdErr != nil {
+		return cfg, err
+	}
+	cfg, projectErr := config.ApplyProject(cfg, wd)
+	return cfg, errors.Join(err, projectErr)
 }
 
-// defaultTranscriberFactory implements TranscriberFactory using OpenAI.
+// defaultTranscriberFactory implements TranscriberFactory using OpenAI, another
+// registered provider, whisper.cpp or a gRPC server.
 type defaultTranscriberFactory struct{}
 
 func (defaultTranscriberFactory) NewTranscriber(apiKey string) transcribe.Transcriber {
 	return transcribe.NewOpenAITranscriber(apiKey)
 }
 
+func (defaultTranscriberFactory) NewAzureTranscriber(apiKey string, cfg AzureConfig) transcribe.Transcriber {
+	return transcribe.NewOpenAITranscriber(apiKey,
+		transcribe.WithBaseURL(cfg.Endpoint),
+		transcribe.WithAzure(cfg.TranscribeDeployment, cfg.APIVersion),
+	)
+}
+
+func (defaultTranscriberFactory) NewProviderTranscriber(provider, apiKey string) (transcribe.Transcriber, error) {
+	return transcribe.NewProviderTranscriber(provider, transcribe.ProviderConfig{APIKey: apiKey})
+}
+
+func (defaultTranscriberFactory) NewLocalTranscriber(cfg LocalTranscriberConfig) (transcribe.Transcriber, error) {
+	if cfg.ServerURL != "" {
+		// Local servers need no API key.
+		return transcribe.NewOpenAITranscriber("", transcribe.WithBaseURL(cfg.ServerURL)), nil
+	}
+	var opts []transcribe.LocalTranscriberOption
+	if cfg.BinaryPath != "" {
+		opts = append(opts, transcribe.WithWhisperBinary(cfg.BinaryPath))
+	}
+	return transcribe.NewLocalTranscriber(cfg.ModelPath, cfg.FFmpegPath, opts...)
+}
+
+func (defaultTranscriberFactory) NewGRPCTranscriber(cfg GRPCTranscriberConfig) (transcribe.Transcriber, error) {
+	opts := []transcribe.GRPCOption{
+		transcribe.WithGRPCPlaintext(cfg.Plaintext),
+		transcribe.WithGRPCTLSConfig(cfg.TLS),
+	}
+	if cfg.Timeout > 0 {
+		opts = append(opts, transcribe.WithGRPCTimeout(cfg.Timeout))
+	}
+	return transcribe.NewGRPCTranscriber(cfg.Endpoint, opts...)
+}
+
 // defaultRestructurerFactory implements RestructurerFactory with provider selection.
 type defaultRestructurerFactory struct{}
 
@@ -222,19 +566,41 @@ type defaultRestructurerFactory struct{}
 // 1. A zero-value Provider is passed without defaulting
 // 2. The Provider type is extended but the factory is not updated
 // Normal CLI flows default zero providers to DeepSeek before calling the factory.
-var ErrUnsupportedProvider = fmt.Errorf("unsupported provider (use %q or %q)", ProviderDeepSeek, ProviderOpenAI)
+var ErrUnsupportedProvider = fmt.Errorf("unsupported provider (use %q, %q, %q or %q)",
+	ProviderDeepSeek, ProviderOpenAI, ProviderAnthropic, ProviderOllama)
 
-func (defaultRestructurerFactory) NewMapReducer(provider Provider, apiKey string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
+// The MapReducer sizes its chunks from the context window of the model.
+func (f defaultRestructurerFactory) NewMapReducer(provider Provider, apiKey, model string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
 	switch {
 	case provider.IsDeepSeek():
-		restructurer, err := restructure.NewDeepSeekRestructurer(apiKey)
+		var dsOpts []restructure.DeepSeekOption
+		if model != "" {
+			dsOpts = append(dsOpts, restructure.WithDeepSeekModel(model))
+		}
+		restructurer, err := restructure.NewDeepSeekRestructurer(apiKey, dsOpts...)
 		if err != nil {
 			return nil, err
 		}
 		return restructure.NewMapReduceRestructurer(restructurer, opts...), nil
 	case provider.IsOpenAI():
-		restructurer := restructure.NewOpenAIRestructurer(apiKey)
+		var oaOpts []restructure.Option
+		if model != "" {
+			oaOpts = append(oaOpts, restructure.WithModel(model))
+		}
+		restructurer := restructure.NewOpenAIRestructurer(apiKey, oaOpts...)
 		return restructure.NewMapReduceRestructurer(restructurer, opts...), nil
+	case provider.IsAnthropic():
+		var anOpts []restructure.AnthropicOption
+		if model != "" {
+			anOpts = append(anOpts, restructure.WithAnthropicModel(model))
+		}
+		restructurer, err := restructure.NewAnthropicRestructurer(apiKey, anOpts...)
+		if err != nil {
+			return nil, err
+		}
+		return restructure.NewMapReduceRestructurer(restructurer, opts...), nil
+	case provider.IsOllama():
+		return f.NewOllamaMapReducer(OllamaConfig{Model: model}, opts...)
 	default:
 		// Defensive: Provider type guarantees validity, but handle zero value
 		// or future provider additions gracefully.
@@ -242,11 +608,33 @@ func (defaultRestructurerFactory) NewMapReducer(provider Provider, apiKey string
 	}
 }
 
+func (defaultRestructurerFactory) NewOllamaMapReducer(cfg OllamaConfig, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
+	restructurer := restructure.NewOllamaRestructurer(
+		restructure.WithOllamaBaseURL(cfg.BaseURL),
+		restructure.WithOllamaModel(cfg.Model),
+	)
+	// Local models have small context windows: chunks must fit in them.
+	opts = append([]restructure.MapReduceOption{restructure.WithMapReduceMaxTokens(restructurer.MaxInputTokens())}, opts...)
+	return restructure.NewMapReduceRestructurer(restructurer, opts...), nil
+}
+
+func (defaultRestructurerFactory) NewAzureMapReducer(apiKey, model string, cfg AzureConfig, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
+	oaOpts := []restructure.Option{
+		restructure.WithBaseURL(cfg.Endpoint),
+		restructure.WithAzure(cfg.ChatDeployment, cfg.APIVersion),
+	}
+	if model != "" {
+		oaOpts = append(oaOpts, restructure.WithModel(model))
+	}
+	restructurer := restructure.NewOpenAIRestructurer(apiKey, oaOpts...)
+	return restructure.NewMapReduceRestructurer(restructurer, opts...), nil
+}
+
 // defaultChunkerFactory implements ChunkerFactory using audio package.
 type defaultChunkerFactory struct{}
 
-func (defaultChunkerFactory) NewSilenceChunker(ffmpegPath string) (audio.Chunker, error) {
-	return audio.NewSilenceChunker(ffmpegPath)
+func (defaultChunkerFactory) NewChunker(strategy string, cfg audio.ChunkerConfig) (audio.Chunker, error) {
+	return audio.NewChunker(strategy, cfg)
 }
 
 // defaultDeviceListerFactory implements DeviceListerFactory using audio package.
@@ -256,7 +644,69 @@ func (defaultDeviceListerFactory) NewDeviceLister(ffmpegPath string) (audio.Devi
 	return audio.NewFFmpegRecorder(ffmpegPath, "")
 }
 
+// defaultFingerprinterFactory implements FingerprinterFactory using audio package.
+type defaultFingerprinterFactory struct{}
+
+func (defaultFingerprinterFactory) NewFingerprinter(ffmpegPath string) (audio.Fingerprinter, error) {
+	return audio.NewFingerprinter(ffmpegPath)
+}
+
+// defaultAudioExtractorFactory implements AudioExtractorFactory using audio package.
+type defaultAudioExtractorFactory struct{}
+
+func (defaultAudioExtractorFactory) NewAudioExtractor(ffmpegPath string) (audio.AudioExtractor, error) {
+	return audio.NewAudioExtractor(ffmpegPath)
+}
+
+// defaultRepairerFactory implements RepairerFactory using audio package.
+type defaultRepairerFactory struct{}
+
+func (defaultRepairerFactory) NewRepairer(ffmpegPath string) (audio.Repairer, error) {
+	return audio.NewRepairer(ffmpegPath)
+}
+
+// defaultPreprocessorFactory implements PreprocessorFactory using audio package.
+type defaultPreprocessorFactory struct{}
+
+func (defaultPreprocessorFactory) NewPreprocessor(ffmpegPath string) (audio.Preprocessor, error) {
+	return audio.NewPreprocessor(ffmpegPath)
+}
+
+// defaultMediaProberFactory implements MediaProberFactory using ffmpeg package.
+type defaultMediaProberFactory struct{}
+
+func (defaultMediaProberFactory) NewMediaProber(ffmpegPath string) (MediaProber, error) {
+	return ffmpeg.NewMetadataProber(ffmpegPath), nil
+}
+
+// defaultDownloaderFactory implements DownloaderFactory using fetch package.
+type defaultDownloaderFactory struct{}
+
+func (defaultDownloaderFactory) NewDownloader(maxSize int64) fetch.Downloader {
+	return fetch.NewHTTPDownloader(fetch.WithMaxSize(maxSize))
+}
+
+// defaultLevelMeterFactory implements LevelMeterFactory using audio package.
+type defaultLevelMeterFactory struct{}
+
+func (defaultLevelMeterFactory) NewLevelMeter(ffmpegPath string) (audio.LevelMeter, error) {
+	return audio.NewLevelMeter(ffmpegPath)
+}
+
 // defaultRecorderFactory implements RecorderFactory using audio package.
+type defaultBotFactory struct{}
+
+func (defaultBotFactory) NewTelegramBot(token string) (telegram.Bot, error) {
+	return telegram.NewClient(token)
+}
+
+// defaultWatcherFactory implements WatcherFactory using watch package.
+type defaultWatcherFactory struct{}
+
+func (defaultWatcherFactory) NewWatcher(settle time.Duration) watch.Watcher {
+	return watch.NewFSWatcher(watch.WithSettle(settle))
+}
+
 type defaultRecorderFactory struct{}
 
 func (defaultRecorderFactory) NewRecorder(ffmpegPath, device string) (audio.Recorder, error) {
@@ -271,13 +721,83 @@ func (defaultRecorderFactory) NewMixRecorder(ctx context.Context, ffmpegPath, mi
 	return audio.NewFFmpegMixRecorder(ctx, ffmpegPath, micDevice)
 }
 
+// defaultProber implements Prober using the ffmpeg, restructure and audio packages.
+type defaultProber struct{}
+
+func (defaultProber) LookupFFmpeg(ctx context.Context) (string, string, error) {
+	path, err := ffmpeg.Lookup()
+	if err != nil {
+		return "", "", err
+	}
+	version, err := ffmpeg.Version(ctx, path)
+	return path, version, err
+}
+
+// The key is checked by listing the models of the provider, which is free.
+func (defaultProber) VerifyAPIKey(ctx context.Context, provider Provider, key string) error {
+	switch {
+	case provider.IsDeepSeek():
+		r, err := restructure.NewDeepSeekRestructurer(key)
+		if err != nil {
+			return err
+		}
+		return r.VerifyKey(ctx)
+	case provider.IsOpenAI():
+		return restructure.NewOpenAIRestructurer(key).VerifyKey(ctx)
+	case provider.IsAnthropic():
+		r, err := restructure.NewAnthropicRestructurer(key)
+		if err != nil {
+			return err
+		}
+		return r.VerifyKey(ctx)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedProvider, provider)
+	}
+}
+
+func (defaultProber) DetectLoopback(ctx context.Context, ffmpegPath string) (string, error) {
+	device, err := audio.DetectLoopbackDevice(ctx, ffmpegPath)
+	if err != nil {
+		return "", err
+	}
+	return device.Name(), nil
+}
+
+// defaultFFmpegInstaller implements FFmpegInstaller using the ffmpeg package.
+type defaultFFmpegInstaller struct{}
+
+func (defaultFFmpegInstaller) Status() (ffmpeg.Status, error) {
+	return ffmpeg.InstallStatus()
+}
+
+func (defaultFFmpegInstaller) Upgrade(ctx context.Context) (bool, error) {
+	return ffmpeg.Upgrade(ctx)
+}
+
+func (defaultFFmpegInstaller) Version(ctx context.Context, ffmpegPath string) (string, error) {
+	return ffmpeg.Version(ctx, ffmpegPath)
+}
+
 // Compile-time interface verification.
 var (
-	_ FFmpegResolver      = (*defaultFFmpegResolver)(nil)
-	_ ConfigLoader        = (*defaultConfigLoader)(nil)
-	_ TranscriberFactory  = (*defaultTranscriberFactory)(nil)
-	_ RestructurerFactory = (*defaultRestructurerFactory)(nil)
-	_ ChunkerFactory      = (*defaultChunkerFactory)(nil)
-	_ RecorderFactory     = (*defaultRecorderFactory)(nil)
-	_ DeviceListerFactory = (*defaultDeviceListerFactory)(nil)
+	_ FFmpegResolver        = (*defaultFFmpegResolver)(nil)
+	_ ConfigLoader          = (*defaultConfigLoader)(nil)
+	_ TranscriberFactory    = (*defaultTranscriberFactory)(nil)
+	_ RestructurerFactory   = (*defaultRestructurerFactory)(nil)
+	_ ChunkerFactory        = (*defaultChunkerFactory)(nil)
+	_ RecorderFactory       = (*defaultRecorderFactory)(nil)
+	_ DeviceListerFactory   = (*defaultDeviceListerFactory)(nil)
+	_ FingerprinterFactory  = (*defaultFingerprinterFactory)(nil)
+	_ AudioExtractorFactory = (*defaultAudioExtractorFactory)(nil)
+	_ RepairerFactory       = (*defaultRepairerFactory)(nil)
+	_ PreprocessorFactory   = (*defaultPreprocessorFactory)(nil)
+	_ MediaProberFactory    = (*defaultMediaProberFactory)(nil)
+	_ MediaProber           = (*ffmpeg.MetadataProber)(nil)
+	_ DownloaderFactory     = (*defaultDownloaderFactory)(nil)
+	_ LevelMeterFactory     = (*defaultLevelMeterFactory)(nil)
+	_ WatcherFactory        = (*defaultWatcherFactory)(nil)
+	_ Prober                = (*defaultProber)(nil)
+	_ FFmpegInstaller       = (*defaultFFmpegInstaller)(nil)
+	_ TerminalFactory       = (*defaultTerminalFactory)(nil)
+	_ Terminal              = (*sttyTerminal)(nil)
 )
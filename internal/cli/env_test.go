@@ -48,6 +48,36 @@ func TestDefaultEnvReturnsValidEnv(t *testing.T) {
 	if env.RecorderFactory == nil {
 		t.Error("DefaultEnv() RecorderFactory = nil, want non-nil")
 	}
+	if env.FingerprinterFactory == nil {
+		t.Error("DefaultEnv() FingerprinterFactory = nil, want non-nil")
+	}
+	if env.AudioExtractorFactory == nil {
+		t.Error("DefaultEnv() AudioExtractorFactory = nil, want non-nil")
+	}
+	if env.RepairerFactory == nil {
+		t.Error("DefaultEnv() RepairerFactory = nil, want non-nil")
+	}
+	if env.PreprocessorFactory == nil {
+		t.Error("DefaultEnv() PreprocessorFactory = nil, want non-nil")
+	}
+	if env.DownloaderFactory == nil {
+		t.Error("DefaultEnv() DownloaderFactory = nil, want non-nil")
+	}
+	if env.BotFactory == nil {
+		t.Error("DefaultEnv() BotFactory = nil, want non-nil")
+	}
+	if env.WatcherFactory == nil {
+		t.Error("DefaultEnv() WatcherFactory = nil, want non-nil")
+	}
+	if env.Notifier == nil {
+		t.Error("DefaultEnv() Notifier = nil, want non-nil")
+	}
+	if env.Prober == nil {
+		t.Error("DefaultEnv() Prober = nil, want non-nil")
+	}
+	if env.Keyring == nil {
+		t.Error("DefaultEnv() Keyring = nil, want non-nil")
+	}
 }
 
 func TestDefaultEnvStderrIsOsStderr(t *testing.T) {
@@ -204,6 +234,127 @@ func TestNewEnvWithRecorderFactory(t *testing.T) {
 	}
 }
 
+func TestNewEnvWithFingerprinterFactory(t *testing.T) {
+	t.Parallel()
+
+	factory := &mockFingerprinterFactory{}
+	env := NewEnv(WithFingerprinterFactory(factory))
+
+	if env.FingerprinterFactory != factory {
+		t.Errorf("NewEnv(WithFingerprinterFactory(factory)) FingerprinterFactory = %v, want %v", env.FingerprinterFactory, factory)
+	}
+}
+
+func TestNewEnvWithAudioExtractorFactory(t *testing.T) {
+	t.Parallel()
+
+	factory := &mockAudioExtractorFactory{}
+	env := NewEnv(WithAudioExtractorFactory(factory))
+
+	if env.AudioExtractorFactory != factory {
+		t.Errorf("NewEnv(WithAudioExtractorFactory(factory)) AudioExtractorFactory = %v, want %v", env.AudioExtractorFactory, factory)
+	}
+}
+
+func TestNewEnvWithRepairerFactory(t *testing.T) {
+	t.Parallel()
+
+	factory := &mockRepairerFactory{}
+	env := NewEnv(WithRepairerFactory(factory))
+
+	if env.RepairerFactory != factory {
+		t.Errorf("NewEnv(WithRepairerFactory(factory)) RepairerFactory = %v, want %v", env.RepairerFactory, factory)
+	}
+}
+
+func TestNewEnvWithPreprocessorFactory(t *testing.T) {
+	t.Parallel()
+
+	factory := &mockPreprocessorFactory{}
+	env := NewEnv(WithPreprocessorFactory(factory))
+
+	if env.PreprocessorFactory != factory {
+		t.Errorf("NewEnv(WithPreprocessorFactory(factory)) PreprocessorFactory = %v, want %v", env.PreprocessorFactory, factory)
+	}
+}
+
+func TestNewEnvWithDownloaderFactory(t *testing.T) {
+	t.Parallel()
+
+	factory := &mockDownloaderFactory{}
+	env := NewEnv(WithDownloaderFactory(factory))
+
+	if env.DownloaderFactory != factory {
+		t.Errorf("NewEnv(WithDownloaderFactory(factory)) DownloaderFactory = %v, want %v", env.DownloaderFactory, factory)
+	}
+}
+
+func TestNewEnvWithBotFactory(t *testing.T) {
+	t.Parallel()
+
+	factory := &mockBotFactory{}
+	env := NewEnv(WithBotFactory(factory))
+
+	if env.BotFactory != factory {
+		t.Errorf("NewEnv(WithBotFactory(factory)) BotFactory = %v, want %v", env.BotFactory, factory)
+	}
+}
+
+func TestNewEnvWithWatcherFactory(t *testing.T) {
+	t.Parallel()
+
+	factory := &mockWatcherFactory{}
+	env := NewEnv(WithWatcherFactory(factory))
+
+	if env.WatcherFactory != factory {
+		t.Errorf("NewEnv(WithWatcherFactory(factory)) WatcherFactory = %v, want %v", env.WatcherFactory, factory)
+	}
+}
+
+func TestNewEnvWithNotifier(t *testing.T) {
+	t.Parallel()
+
+	notifier := &mockNotifier{}
+	env := NewEnv(WithNotifier(notifier))
+
+	if env.Notifier != notifier {
+		t.Errorf("NewEnv(WithNotifier(notifier)) Notifier = %v, want %v", env.Notifier, notifier)
+	}
+}
+
+func TestNewEnvWithProber(t *testing.T) {
+	t.Parallel()
+
+	prober := &mockProber{}
+	env := NewEnv(WithProber(prober))
+
+	if env.Prober != prober {
+		t.Errorf("NewEnv(WithProber(prober)) Prober = %v, want %v", env.Prober, prober)
+	}
+}
+
+func TestNewEnvWithKeyring(t *testing.T) {
+	t.Parallel()
+
+	kr := &mockKeyring{}
+	env := NewEnv(WithKeyring(kr))
+
+	if env.Keyring != kr {
+		t.Errorf("NewEnv(WithKeyring(kr)) Keyring = %v, want %v", env.Keyring, kr)
+	}
+}
+
+func TestNewEnvWithTerminalFactory(t *testing.T) {
+	t.Parallel()
+
+	terminals := &mockTerminalFactory{}
+	env := NewEnv(WithTerminalFactory(terminals))
+
+	if env.TerminalFactory != terminals {
+		t.Errorf("NewEnv(WithTerminalFactory(terminals)) TerminalFactory = %v, want %v", env.TerminalFactory, terminals)
+	}
+}
+
 func TestNewEnvMultipleOptions(t *testing.T) {
 	t.Parallel()
 
@@ -8,8 +8,12 @@ import "errors"
 // Environment variable names for API keys.
 // #nosec G101 -- these are env var names, not credentials
 const (
-	EnvOpenAIAPIKey   = "OPENAI_API_KEY"
-	EnvDeepSeekAPIKey = "DEEPSEEK_API_KEY"
+	EnvOpenAIAPIKey    = "OPENAI_API_KEY"
+	EnvDeepSeekAPIKey  = "DEEPSEEK_API_KEY"
+	EnvAnthropicAPIKey = "ANTHROPIC_API_KEY"
+
+	// EnvTelegramBotToken holds the token of the bot command's Telegram bot.
+	EnvTelegramBotToken = "TELEGRAM_BOT_TOKEN"
 )
 
 var (
@@ -19,6 +23,26 @@ var (
 	// ErrDeepSeekKeyMissing indicates DEEPSEEK_API_KEY environment variable is not set.
 	ErrDeepSeekKeyMissing = errors.New("DEEPSEEK_API_KEY environment variable not set")
 
+	// ErrAnthropicKeyMissing indicates ANTHROPIC_API_KEY environment variable is not set.
+	ErrAnthropicKeyMissing = errors.New("ANTHROPIC_API_KEY environment variable not set")
+
+	// ErrTranscriberKeyMissing indicates the API key of a transcription
+	// provider other than OpenAI (GROQ_API_KEY, ...) is not set.
+	ErrTranscriberKeyMissing = errors.New("transcription API key not set")
+
+	// ErrTelegramTokenMissing indicates TELEGRAM_BOT_TOKEN environment variable is not set.
+	ErrTelegramTokenMissing = errors.New("TELEGRAM_BOT_TOKEN environment variable not set")
+
+	// ErrGRPCEndpointMissing indicates the grpc transcriber has no endpoint.
+	ErrGRPCEndpointMissing = errors.New("grpc endpoint not configured")
+
+	// ErrAzureDeploymentMissing indicates azure-endpoint is set without the
+	// deployment of the request.
+	ErrAzureDeploymentMissing = errors.New("azure deployment not configured")
+
+	// ErrChecksFailed indicates config validate or config doctor found problems.
+	ErrChecksFailed = errors.New("configuration checks failed")
+
 	// ErrInvalidDuration indicates a duration string could not be parsed.
 	ErrInvalidDuration = errors.New("invalid duration format")
 
@@ -28,6 +52,81 @@ var (
 	// ErrFileNotFound indicates the specified input file does not exist.
 	ErrFileNotFound = errors.New("file not found")
 
+	// ErrProtectedMedia indicates an input file whose audio is protected by DRM.
+	ErrProtectedMedia = errors.New("audio protected by DRM")
+
+	// ErrUnreadableMedia indicates an input file FFmpeg cannot read or decode:
+	// corrupt, or not a media file despite its extension.
+	ErrUnreadableMedia = errors.New("unreadable audio")
+
 	// ErrOutputExists indicates the output file already exists.
 	ErrOutputExists = errors.New("output file already exists")
+
+	// ErrInvalidParallel indicates a --parallel value is neither a number nor "auto".
+	ErrInvalidParallel = errors.New("invalid parallel value")
+
+	// ErrInvalidBackend indicates an unknown --transcriber value.
+	ErrInvalidBackend = errors.New("invalid transcriber")
+
+	// ErrInvalidOutputFormat indicates an unknown --format value.
+	ErrInvalidOutputFormat = errors.New("invalid output format")
+
+	// ErrInvalidIntroOutro indicates an unknown --intro-outro value.
+	ErrInvalidIntroOutro = errors.New("invalid intro-outro mode")
+
+	// ErrInvalidSpeakerLabels indicates an unknown --speaker-labels value.
+	ErrInvalidSpeakerLabels = errors.New("invalid speaker labels")
+
+	// ErrInvalidTimestamps indicates a --timestamps value that is neither
+	// "segment" nor a positive duration.
+	ErrInvalidTimestamps = errors.New("invalid timestamps interval")
+
+	// ErrInvalidStartTime indicates a --start-at time or --start-in delay
+	// that cannot be parsed.
+	ErrInvalidStartTime = errors.New("invalid start time")
+
+	// ErrInvalidGain indicates a --mic-gain or --system-gain value that is not
+	// a gain in dB, or a mix balance flag used without --mix.
+	ErrInvalidGain = errors.New("invalid mix gain")
+
+	// ErrCostLimit indicates the estimated cost of a run exceeds --max-cost.
+	ErrCostLimit = errors.New("estimated cost above limit")
+
+	// ErrInvalidProgress indicates an unknown --progress value.
+	ErrInvalidProgress = errors.New("invalid progress format")
+
+	// ErrInvalidMaxDownload indicates an invalid --max-download size.
+	ErrInvalidMaxDownload = errors.New("invalid download size limit")
+
+	// ErrWarningAsError indicates a warning was printed with --warn-as-error.
+	ErrWarningAsError = errors.New("warning treated as error")
+
+	// ErrUnknownWarning indicates a --suppress-warn code missing from the warning catalog.
+	ErrUnknownWarning = errors.New("unknown warning code")
+
+	// ErrNoSessions indicates no finished session matched the digest filters.
+	ErrNoSessions = errors.New("no matching session")
+
+	// ErrUnknownErrorCode indicates an error code missing from the catalog (explain).
+	ErrUnknownErrorCode = errors.New("unknown error code")
+
+	// ErrNoTerminal indicates live --tui run without an interactive terminal.
+	ErrNoTerminal = errors.New("no interactive terminal")
+
+	// ErrUnknownExitCode indicates an exit code transcript does not use (explain-exit).
+	ErrUnknownExitCode = errors.New("unknown exit code")
+
+	// ErrInvalidStats indicates an unknown --stats value.
+	ErrInvalidStats = errors.New("invalid statistics format")
+
+	// ErrInvalidConfidence indicates an invalid --unclear confidence threshold.
+	ErrInvalidConfidence = errors.New("invalid confidence threshold")
+
+	// ErrInvalidActionsFormat indicates an unknown --actions value.
+	ErrInvalidActionsFormat = errors.New("invalid action items format")
+
+	// ErrUsage indicates a command line Cobra cannot parse: unknown flag,
+	// invalid flag value, missing required flag, conflicting flags, or wrong
+	// number of arguments. Matched by UsageError (see WrapUsageErrors).
+	ErrUsage = errors.New("invalid usage")
 )
@@ -19,6 +19,8 @@ func TestSentinelErrorsAreDistinct(t *testing.T) {
 		ErrUnsupportedFormat,
 		ErrFileNotFound,
 		ErrOutputExists,
+		ErrCostLimit,
+		ErrInvalidProgress,
 	}
 
 	// Verify all sentinels are distinct from each other
@@ -43,6 +45,8 @@ func TestSentinelErrorsCanBeWrapped(t *testing.T) {
 		{"ErrUnsupportedFormat", ErrUnsupportedFormat},
 		{"ErrFileNotFound", ErrFileNotFound},
 		{"ErrOutputExists", ErrOutputExists},
+		{"ErrCostLimit", ErrCostLimit},
+		{"ErrInvalidProgress", ErrInvalidProgress},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/interrupt"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// Exit codes of transcript. They are a contract: scripts can branch on them,
+// and a code never changes meaning (see explain-exit).
+const (
+	ExitOK            = 0
+	ExitGeneral       = 1
+	ExitUsage         = 2
+	ExitSetup         = 3
+	ExitValidation    = 4
+	ExitTranscription = 5
+	ExitRestructure   = 6
+	ExitPartial       = 7
+	ExitInterrupt     = interrupt.ExitInterrupt
+)
+
+// ExitCode returns the exit code of err (see explain-exit): ExitOK for nil,
+// ExitInterrupt on cancellation, ExitPartial for a partial output, ExitUsage
+// for command line errors, then the exit code of its error code in
+// errorCatalog (TR-03xx exits with 3, and so on), or ExitGeneral if its
+// cause is not documented.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupt
+	case errors.Is(err, transcribe.ErrPartial):
+		return ExitPartial
+	case errors.Is(err, ErrUsage):
+		return ExitUsage
+	}
+	if info, ok := LookupError(err); ok {
+		return info.exitCode()
+	}
+	return ExitGeneral
+}
+
+// exitCode returns the exit code of the group of the error code: 3 for
+// "TR-0301". Returns ExitGeneral if the code is malformed.
+func (info ErrorInfo) exitCode() int {
+	group, ok := strings.CutPrefix(info.Code, "TR-")
+	if !ok || len(group) < 2 {
+		return ExitGeneral
+	}
+	n, err := strconv.Atoi(group[:2])
+	if err != nil {
+		return ExitGeneral
+	}
+	return n
+}
+
+// ExitCodeInfo documents an exit code for scripting users.
+type ExitCodeInfo struct {
+	Code        int
+	Name        string // Short name, e.g. "usage"
+	Summary     string // One-line description
+	Explanation string // What causes it, and what a script can do about it
+}
+
+// exitCodeCatalog lists the exit codes, in increasing order.
+var exitCodeCatalog = []ExitCodeInfo{
+	{
+		Code:        ExitOK,
+		Name:        "ok",
+		Summary:     "Success",
+		Explanation: "The command finished and wrote its outputs. With --warn-as-error, a printed warning turns it into exit code 4.",
+	},
+	{
+		Code:        ExitGeneral,
+		Name:        "general",
+		Summary:     "Unexpected error",
+		Explanation: "An error without a more specific code: unknown command, file system or network error outside API calls. The message tells the cause; retrying may help if it was transient.",
+	},
+	{
+		Code:        ExitUsage,
+		Name:        "usage",
+		Summary:     "Invalid command line",
+		Explanation: "The command line cannot be parsed: unknown flag, invalid flag value, missing required flag (like --duration of live), conflicting flags (like --output and --session-dir), or wrong number of arguments. Nothing was done; fix the command, see 'transcript <command> --help'.",
+	},
+	{
+		Code:        ExitSetup,
+		Name:        "setup",
+		Summary:     "Missing or broken setup",
+		Explanation: "Something the command needs is missing: FFmpeg, an API key, an audio device, a local model, certificates. Nothing was sent; fix the setup ('transcript config doctor' helps) and run again.",
+	},
+	{
+		Code:        ExitValidation,
+		Name:        "validation",
+		Summary:     "Invalid input",
+		Explanation: "An input, flag value or setting was rejected: missing or unsupported file, existing output, unknown template or language, cost above the limit. Checked before any API call when possible; running again with the same input fails the same way.",
+	},
+	{
+		Code:        ExitTranscription,
+		Name:        "transcription",
+		Summary:     "Transcription API error",
+		Explanation: "The API failed after the retries: rate limit, exhausted quota, timeout, rejected key. Completed chunks are checkpointed: after waiting or fixing the account, run again with --resume.",
+	},
+	{
+		Code:        ExitRestructure,
+		Name:        "restructure",
+		Summary:     "Restructuring error",
+		Explanation: "The transcript was written but could not be restructured: too long for the model, or the stream broke off. Restructure it again with 'transcript structure'.",
+	},
+	{
+		Code:        ExitPartial,
+		Name:        "partial",
+		Summary:     "Partial output",
+		Explanation: "With --allow-partial, the output was written with markers where chunks kept failing. Run again with --resume --force to transcribe them.",
+	},
+	{
+		Code:        ExitInterrupt,
+		Name:        "interrupt",
+		Summary:     "Interrupted",
+		Explanation: "Ctrl+C or SIGTERM stopped the command (128 + SIGINT). Completed chunks and the raw transcript are kept: run again with --resume, or restructure the saved raw transcript.",
+	},
+}
+
+// ExplainExitCmd creates the explain-exit command (describe an exit code).
+// The env parameter provides injectable dependencies for testing.
+func ExplainExitCmd(env *Env) *cobra.Command {
+	return &cobra.Command{
+		Use:   "explain-exit [code]",
+		Short: "Explain an exit code, for scripts",
+		Long: `Explain an exit code of transcript, for scripts.
+
+Exit codes are stable: scripts can branch on them, like retrying later on 5
+(API error) but not on 4 (invalid input). The error codes printed with error
+messages (see 'transcript explain') tell the cause within an exit code:
+TR-03xx are exit code 3, TR-04xx exit code 4, and so on.
+
+Without a code, lists all exit codes.`,
+		Example: `  transcript explain-exit 5
+  transcript explain-exit`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var code string
+			if len(args) == 1 {
+				code = args[0]
+			}
+			return runExplainExit(cmd.OutOrStdout(), code)
+		},
+	}
+}
+
+// runExplainExit writes the description of the exit code to w, with the
+// error codes it covers, or the list of all exit codes if code is empty.
+// Returns ErrUnknownExitCode if transcript does not use code.
+func runExplainExit(w io.Writer, code string) error {
+	if code == "" {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, info := range exitCodeCatalog {
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", info.Code, info.Name, info.Summary)
+		}
+		return tw.Flush()
+	}
+
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return fmt.Errorf("%q: %w (see 'transcript explain-exit')", code, ErrUnknownExitCode)
+	}
+	for _, info := range exitCodeCatalog {
+		if info.Code == n {
+			return writeExitCodeInfo(w, info)
+		}
+	}
+	return fmt.Errorf("%d: %w (see 'transcript explain-exit')", n, ErrUnknownExitCode)
+}
+
+// writeExitCodeInfo renders an exit code for the explain-exit command.
+func writeExitCodeInfo(w io.Writer, info ExitCodeInfo) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d  %s: %s\n\n%s\n", info.Code, info.Name, info.Summary, info.Explanation)
+	prefix := fmt.Sprintf("TR-%02d", info.Code)
+	first := true
+	for _, e := range errorCatalog {
+		if !strings.HasPrefix(e.Code, prefix) {
+			continue
+		}
+		if first {
+			b.WriteString("\nError codes:\n")
+			first = false
+		}
+		fmt.Fprintf(&b, "  %s  %s\n", e.Code, e.Summary)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestRunExplainExit(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	if err := runExplainExit(&out, "5"); err != nil {
+		t.Fatalf("runExplainExit() unexpected error: %v", err)
+	}
+	for _, want := range []string{"5  transcription: Transcription API error", "--resume", "Error codes:", "  TR-0501  API rate limit exceeded"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("runExplainExit() output = %q, want containing %q", out.String(), want)
+		}
+	}
+	if strings.Contains(out.String(), "TR-04") {
+		t.Errorf("runExplainExit() output = %q, want only the TR-05xx codes", out.String())
+	}
+}
+
+func TestRunExplainExit_List(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	if err := runExplainExit(&out, ""); err != nil {
+		t.Fatalf("runExplainExit() unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(exitCodeCatalog) {
+		t.Fatalf("runExplainExit() listed %d codes, want %d", len(lines), len(exitCodeCatalog))
+	}
+	if !strings.HasPrefix(lines[len(lines)-1], "130 ") {
+		t.Errorf("last line = %q, want the interrupt code", lines[len(lines)-1])
+	}
+}
+
+func TestRunExplainExit_UnknownCode(t *testing.T) {
+	t.Parallel()
+
+	for _, code := range []string{"8", "two"} {
+		var out bytes.Buffer
+		if err := runExplainExit(&out, code); !errors.Is(err, ErrUnknownExitCode) {
+			t.Errorf("runExplainExit(%q) error = %v, want ErrUnknownExitCode", code, err)
+		}
+		if out.Len() != 0 {
+			t.Errorf("runExplainExit(%q) wrote %q on error", code, out.String())
+		}
+	}
+}
+
+func TestExitCodeCatalog_CoversErrorCatalog(t *testing.T) {
+	t.Parallel()
+
+	// Every error code must belong to a documented exit code.
+	for _, e := range errorCatalog {
+		found := false
+		for _, info := range exitCodeCatalog {
+			if strings.HasPrefix(e.Code, fmt.Sprintf("TR-%02d", info.Code)) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s has no exit code in exitCodeCatalog", e.Code)
+		}
+	}
+}
+
+func TestExitCodeCatalog_CommandsExist(t *testing.T) {
+	t.Parallel()
+
+	env, _ := testEnv()
+	root := &cobra.Command{Use: "transcript"}
+	root.AddCommand(RecordCmd(env), TranscribeCmd(env), LiveCmd(env), BotCmd(env), WatchCmd(env),
+		DigestCmd(env), ServeCmd(env), StructureCmd(env), ConfigCmd(env), DevicesCmd(env), SchemaCmd(env),
+		TemplatesCmd(env), UndoCmd(env), RecoverCmd(env), ExplainCmd(env), ExplainExitCmd(env), FFmpegCmd(env))
+
+	// Explanations name commands as 'transcript <command> [<subcommand>]'.
+	command := regexp.MustCompile(`'transcript ([a-z-]+(?: [a-z-]+)*)`)
+	for _, info := range exitCodeCatalog {
+		for _, m := range command.FindAllStringSubmatch(info.Explanation, -1) {
+			cmd, _, err := root.Find(strings.Fields(m[1]))
+			if err != nil || cmd.CommandPath() != "transcript "+m[1] {
+				t.Errorf("exit code %d names 'transcript %s', which is not a command", info.Code, m[1])
+			}
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "nil", err: nil, want: ExitOK},
+		{name: "canceled", err: fmt.Errorf("recording: %w", context.Canceled), want: ExitInterrupt},
+		{name: "usage", err: fmt.Errorf("unknown flag: %w", ErrUsage), want: ExitUsage},
+		{name: "partial", err: fmt.Errorf("2 chunks failed: %w", transcribe.ErrPartial), want: ExitPartial},
+		{name: "setup", err: fmt.Errorf("open: %w", ErrAPIKeyMissing), want: ExitSetup},
+		{name: "audio file not found", err: fmt.Errorf("open: %w", audio.ErrFileNotFound), want: ExitValidation},
+		{name: "invalid provider", err: fmt.Errorf("--provider: %w", ErrInvalidProvider), want: ExitValidation},
+		{name: "invalid config syntax", err: fmt.Errorf("line 3: %w", config.ErrInvalidSyntax), want: ExitValidation},
+		{name: "output dir not writable", err: fmt.Errorf("notes: %w", config.ErrNotWritable), want: ExitValidation},
+		{name: "invalid speaker labels", err: fmt.Errorf("--speaker-labels: %w", ErrInvalidSpeakerLabels), want: ExitValidation},
+		{name: "bad request", err: fmt.Errorf("chunk 2: %w", apierr.ErrBadRequest), want: ExitTranscription},
+		{name: "first entry wins", err: fmt.Errorf("%w: %w", ErrOutputExists, ErrAPIKeyMissing), want: ExitSetup},
+		{name: "undocumented", err: errors.New("disk full"), want: ExitGeneral},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitCode_ErrorCatalog(t *testing.T) {
+	t.Parallel()
+
+	// Every documented sentinel exits with the code its TR-NN prefix claims,
+	// as explain-exit lists it.
+	for _, info := range errorCatalog {
+		want, err := strconv.Atoi(info.Code[3:5])
+		if err != nil {
+			t.Fatalf("%s: malformed code", info.Code)
+		}
+		for _, sentinel := range info.errs {
+			if got := ExitCode(fmt.Errorf("wrapped: %w", sentinel)); got != want {
+				t.Errorf("%s: ExitCode(%v) = %d, want %d", info.Code, sentinel, got, want)
+			}
+		}
+	}
+}
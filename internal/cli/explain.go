@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// ExplainCmd creates the explain command (describe an error code).
+// The env parameter provides injectable dependencies for testing.
+func ExplainCmd(env *Env) *cobra.Command {
+	return &cobra.Command{
+		Use:   "explain [code]",
+		Short: "Explain an error or warning code and how to fix it",
+		Long: `Explain an error or warning code and how to fix it.
+
+Error messages end with a code, like "(code TR-0301, see 'transcript explain
+TR-0301')", and warnings too, like "(code TR-W001)". Codes are stable: scripts
+can match them, and they identify the cause in support requests.
+
+Without a code, lists all codes.`,
+		Example: `  transcript explain TR-0301
+  transcript explain TR-W001
+  transcript explain`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var code string
+			if len(args) == 1 {
+				code = args[0]
+			}
+			return runExplain(cmd.OutOrStdout(), code)
+		},
+	}
+}
+
+// runExplain writes the catalog entry of code to w, or the list of all
+// codes if code is empty.
+func runExplain(w io.Writer, code string) error {
+	if code != "" {
+		info, err := lookupCode(code)
+		if err != nil {
+			return err
+		}
+		return writeErrorInfo(w, info)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, info := range errorCatalog {
+		fmt.Fprintf(tw, "%s\t%s\n", info.Code, info.Summary)
+	}
+	for _, info := range warningCatalog {
+		fmt.Fprintf(tw, "%s\t%s\n", info.Code, info.Summary)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunExplain(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	if err := RunExplain(&out, "tr-0301"); err != nil {
+		t.Fatalf("RunExplain() unexpected error: %v", err)
+	}
+	for _, want := range []string{"TR-0301  FFmpeg not found", "What to do:", "  - Or point FFMPEG_PATH"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("RunExplain() output = %q, want containing %q", out.String(), want)
+		}
+	}
+}
+
+func TestRunExplain_Warning(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	if err := RunExplain(&out, "tr-w001"); err != nil {
+		t.Fatalf("RunExplain() unexpected error: %v", err)
+	}
+	if want := "TR-W001  Output extension does not match the format"; !strings.Contains(out.String(), want) {
+		t.Errorf("RunExplain() output = %q, want containing %q", out.String(), want)
+	}
+}
+
+func TestRunExplain_List(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	if err := RunExplain(&out, ""); err != nil {
+		t.Fatalf("RunExplain() unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if want := len(ErrorCatalog) + len(WarningCatalog); len(lines) != want {
+		t.Fatalf("RunExplain() listed %d codes, want %d", len(lines), want)
+	}
+	if !strings.HasPrefix(lines[0], ErrorCatalog[0].Code) {
+		t.Errorf("first line = %q, want starting with %s", lines[0], ErrorCatalog[0].Code)
+	}
+	if last := lines[len(lines)-1]; !strings.HasPrefix(last, WarningCatalog[len(WarningCatalog)-1].Code) {
+		t.Errorf("last line = %q, want the warnings listed after the errors", last)
+	}
+}
+
+func TestRunExplain_UnknownCode(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	if err := RunExplain(&out, "TR-9999"); !errors.Is(err, ErrUnknownErrorCode) {
+		t.Errorf("RunExplain() error = %v, want ErrUnknownErrorCode", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("RunExplain() wrote %q on error", out.String())
+	}
+}
@@ -17,9 +17,15 @@ var RunConfigGet = runConfigGet
 // RunConfigList exports runConfigList for testing.
 var RunConfigList = runConfigList
 
+// RunConfigPath exports runConfigPath for testing.
+var RunConfigPath = runConfigPath
+
 // ClampParallel exports clampParallel for testing.
 var ClampParallel = clampParallel
 
+// ParseParallel exports parseParallel for testing.
+var ParseParallel = parseParallel
+
 // DeriveOutputPath exports deriveOutputPath for testing.
 var DeriveOutputPath = deriveOutputPath
 
@@ -62,6 +68,9 @@ var RunStructure = runStructure
 // ParseStructureOptions exports parseStructureOptions for testing.
 var ParseStructureOptions = parseStructureOptions
 
+// ExpandStructureInputs exports expandStructureInputs for testing.
+var ExpandStructureInputs = expandStructureInputs
+
 // StructureOptions exports structureOptions for testing.
 type StructureOptions = structureOptions
 
@@ -71,6 +80,33 @@ var RestructureContent = restructureContent
 // RunListDevices exports runListDevices for testing.
 var RunListDevices = runListDevices
 
+// RunTestDevice exports runTestDevice for testing.
+var RunTestDevice = runTestDevice
+
+// RunSchema exports runSchema for testing.
+var RunSchema = runSchema
+
+// RunTemplatesList exports runTemplatesList for testing.
+var RunTemplatesList = runTemplatesList
+
+// RunUndo exports runUndo for testing.
+var RunUndo = runUndo
+
+// RunExplain exports runExplain for testing.
+var RunExplain = runExplain
+
+// ErrorCatalog exports errorCatalog for testing.
+var ErrorCatalog = errorCatalog
+
+// WarningCatalog exports warningCatalog for testing.
+var WarningCatalog = warningCatalog
+
+// Warnf exports warnf for testing.
+var Warnf = warnf
+
+// CatalogErrors returns the sentinels matched by a catalog entry, for testing.
+func CatalogErrors(info ErrorInfo) []error { return info.errs }
+
 // RunTranscribe exports runTranscribe for testing.
 var RunTranscribe = runTranscribe
 
@@ -79,3 +115,33 @@ var ParseTranscribeOptions = parseTranscribeOptions
 
 // TranscribeOptions exports transcribeOptions for testing.
 type TranscribeOptions = transcribeOptions
+
+// CheckpointPath exports checkpointPath for testing.
+var CheckpointPath = checkpointPath
+
+// IsBatchInput exports isBatchInput for testing.
+var IsBatchInput = isBatchInput
+
+// DiscoverAudioFiles exports discoverAudioFiles for testing.
+var DiscoverAudioFiles = discoverAudioFiles
+
+// BatchOutputPaths exports batchOutputPaths for testing.
+var BatchOutputPaths = batchOutputPaths
+
+// RunTranscribeBatch exports runTranscribeBatch for testing.
+var RunTranscribeBatch = runTranscribeBatch
+
+// BatchOptions exports batchOptions for testing.
+type BatchOptions = batchOptions
+
+// LinePrefixWriter exports linePrefixWriter for testing.
+type LinePrefixWriter = linePrefixWriter
+
+// RunConfigSetProfile exports runConfigSetProfile for testing.
+var RunConfigSetProfile = runConfigSetProfile
+
+// RunConfigGetProfile exports runConfigGetProfile for testing.
+var RunConfigGetProfile = runConfigGetProfile
+
+// RunConfigListProfiles exports runConfigListProfiles for testing.
+var RunConfigListProfiles = runConfigListProfiles
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// fallbackProviderFlagHelp describes the --fallback-provider flag of the
+// transcribe, live and structure commands.
+const fallbackProviderFlagHelp = "LLM provider restructuring again, with its default model, when --provider fails with an exhausted quota or a rejected key: deepseek, openai, anthropic, ollama (requires --template)"
+
+// parseFallbackProvider parses the --fallback-provider value. Empty means no
+// fallback. Returns ErrInvalidProvider if the name is not recognized.
+func parseFallbackProvider(name string) (Provider, error) {
+	if name == "" {
+		return Provider{}, nil
+	}
+	p, err := ParseProvider(name)
+	if err != nil {
+		return Provider{}, fmt.Errorf("--fallback-provider: %w", err)
+	}
+	return p, nil
+}
+
+// validateFallbackProvider checks that --fallback-provider has a restructuring
+// to retry (tmpl is set), with another provider than provider.
+func validateFallbackProvider(fallback, provider Provider, tmpl template.Name) error {
+	switch {
+	case fallback.IsZero():
+		return nil
+	case tmpl.IsZero():
+		return fmt.Errorf("--fallback-provider requires --template")
+	case fallback == provider.OrDefault():
+		return fmt.Errorf("--fallback-provider must differ from --provider (%s)", provider.OrDefault())
+	}
+	return nil
+}
+
+// validateFallbackKey checks that the API key of --fallback-provider is set
+// before recording or transcribing, as for --provider: the fallback would
+// otherwise only fail once the provider did.
+func validateFallbackKey(env *Env, fallback Provider, azure AzureConfig) error {
+	if fallback.IsZero() {
+		return nil
+	}
+	if _, err := restructureAPIKey(env, fallback); err != nil {
+		return fmt.Errorf("--fallback-provider: %w", err)
+	}
+	return validateAzureChat(fallback, azure)
+}
+
+// fallbackError reports whether err is a provider failure another provider
+// may not have: an exhausted quota or a rejected key. Rate limits and
+// timeouts are already retried with the same provider.
+func fallbackError(err error) bool {
+	return errors.Is(err, apierr.ErrQuotaExceeded) || errors.Is(err, apierr.ErrAuthFailed)
+}
+
+// rewindStream drops the output streamed by the provider that failed before
+// the fallback streams its own (--stream-restructure). Streams other than the
+// output file, like stderr with --stdout, are left as they are.
+func rewindStream(stream io.Writer) error {
+	if o, ok := stream.(*streamedOutput); ok {
+		return o.rewind()
+	}
+	return nil
+}
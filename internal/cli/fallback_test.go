@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+func TestParseFallbackProvider(t *testing.T) {
+	t.Parallel()
+
+	if got, err := parseFallbackProvider(""); err != nil || !got.IsZero() {
+		t.Errorf("parseFallbackProvider(\"\") = %v, %v, want no fallback", got, err)
+	}
+	if got, err := parseFallbackProvider("anthropic"); err != nil || got != AnthropicProvider {
+		t.Errorf("parseFallbackProvider(anthropic) = %v, %v, want anthropic", got, err)
+	}
+	if _, err := parseFallbackProvider("mistral"); !errors.Is(err, ErrInvalidProvider) {
+		t.Errorf("parseFallbackProvider(mistral) error = %v, want ErrInvalidProvider", err)
+	}
+}
+
+func TestValidateFallbackProvider(t *testing.T) {
+	t.Parallel()
+
+	meeting := template.MustParseName("meeting")
+	tests := []struct {
+		name     string
+		fallback Provider
+		provider Provider
+		tmpl     template.Name
+		wantErr  string
+	}{
+		{name: "none", provider: OpenAIProvider},
+		{name: "other provider", fallback: AnthropicProvider, provider: OpenAIProvider, tmpl: meeting},
+		{name: "without template", fallback: AnthropicProvider, provider: OpenAIProvider, wantErr: "--template"},
+		{name: "same provider", fallback: OpenAIProvider, provider: OpenAIProvider, tmpl: meeting, wantErr: "must differ"},
+		{name: "default provider", fallback: DeepSeekProvider, tmpl: meeting, wantErr: "must differ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateFallbackProvider(tt.fallback, tt.provider, tt.tmpl)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validateFallbackProvider() unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validateFallbackProvider() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateFallbackKey(t *testing.T) {
+	t.Parallel()
+
+	env := &Env{Getenv: func(key string) string {
+		if key == EnvOpenAIAPIKey {
+			return "sk-test"
+		}
+		return ""
+	}}
+	if err := validateFallbackKey(env, OpenAIProvider, AzureConfig{}); err != nil {
+		t.Errorf("validateFallbackKey(openai) unexpected error: %v", err)
+	}
+	if err := validateFallbackKey(env, Provider{}, AzureConfig{}); err != nil {
+		t.Errorf("validateFallbackKey(none) unexpected error: %v", err)
+	}
+	err := validateFallbackKey(env, AnthropicProvider, AzureConfig{})
+	if !errors.Is(err, ErrAnthropicKeyMissing) || !strings.Contains(err.Error(), "--fallback-provider") {
+		t.Errorf("validateFallbackKey(anthropic) error = %v, want ErrAnthropicKeyMissing of --fallback-provider", err)
+	}
+}
+
+func TestRestructureContent_Fallback(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		err          error // Of the provider
+		fallback     Provider
+		wantProvider []Provider
+		wantErr      error
+	}{
+		{
+			name:         "quota exceeded",
+			err:          fmt.Errorf("insufficient balance: %w", apierr.ErrQuotaExceeded),
+			fallback:     AnthropicProvider,
+			wantProvider: []Provider{DeepSeekProvider, AnthropicProvider},
+		},
+		{
+			name:         "key rejected",
+			err:          fmt.Errorf("invalid key: %w", apierr.ErrAuthFailed),
+			fallback:     OllamaProvider,
+			wantProvider: []Provider{DeepSeekProvider, OllamaProvider},
+		},
+		{
+			name:         "other error",
+			err:          fmt.Errorf("model not found: %w", apierr.ErrBadRequest),
+			fallback:     AnthropicProvider,
+			wantProvider: []Provider{DeepSeekProvider},
+			wantErr:      apierr.ErrBadRequest,
+		},
+		{
+			name:         "no fallback",
+			err:          fmt.Errorf("insufficient balance: %w", apierr.ErrQuotaExceeded),
+			wantProvider: []Provider{DeepSeekProvider},
+			wantErr:      apierr.ErrQuotaExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			factory := &mockRestructurerFactory{
+				NewMapReducerFunc: func(provider Provider, apiKey, model string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
+					return &mockMapReduceRestructurer{
+						RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+							if provider == DeepSeekProvider {
+								return "", false, tt.err
+							}
+							return "notes of " + provider.String(), false, nil
+						},
+					}, nil
+				},
+			}
+			stderr := &syncBuffer{}
+			env := &Env{Stderr: stderr, Getenv: defaultTestEnv, RestructurerFactory: factory}
+
+			got, err := RestructureContent(context.Background(), env, "content", RestructureOptions{
+				Template:         template.MustParseName("meeting"),
+				Provider:         DeepSeekProvider,
+				Model:            "deepseek-reasoner",
+				FallbackProvider: tt.fallback,
+			})
+
+			calls := factory.NewMapReducerCalls()
+			if len(calls) != len(tt.wantProvider) {
+				t.Fatalf("NewMapReducer() calls = %+v, want %v", calls, tt.wantProvider)
+			}
+			for i, call := range calls {
+				if call.Provider != tt.wantProvider[i] {
+					t.Errorf("NewMapReducer() call %d provider = %v, want %v", i, call.Provider, tt.wantProvider[i])
+				}
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("RestructureContent() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RestructureContent() unexpected error: %v", err)
+			}
+			if want := "notes of " + tt.fallback.String(); got != want {
+				t.Errorf("RestructureContent() = %q, want %q", got, want)
+			}
+			if last := calls[len(calls)-1]; last.Model != "" {
+				t.Errorf("fallback model = %q, want the provider default", last.Model)
+			}
+			if !strings.Contains(stderr.String(), warnRestructureFallback) {
+				t.Errorf("stderr = %q, want the %s warning", stderr.String(), warnRestructureFallback)
+			}
+		})
+	}
+}
+
+func TestTranscribeCmd_FallbackProvider(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr error
+		wantMsg string
+	}{
+		{name: "unknown", args: []string{"-t", "meeting", "--fallback-provider", "mistral"}, wantErr: ErrInvalidProvider},
+		{name: "without template", args: []string{"--fallback-provider", "openai"}, wantMsg: "--template"},
+		{name: "same provider", args: []string{"-t", "meeting", "--provider", "openai", "--fallback-provider", "openai"}, wantMsg: "must differ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env, _ := testEnv()
+			cmd := TranscribeCmd(env)
+			cmd.SetArgs(append([]string{inputPath}, tt.args...))
+			err := cmd.Execute()
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("cmd.Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantMsg != "" && (err == nil || !strings.Contains(err.Error(), tt.wantMsg)) {
+				t.Errorf("cmd.Execute() error = %v, want %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
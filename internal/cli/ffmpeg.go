@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/ffmpeg"
+)
+
+// FFmpegCmd creates the ffmpeg command with subcommands.
+// The env parameter provides injectable dependencies for testing.
+func FFmpegCmd(env *Env) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ffmpeg",
+		Short: "Show, upgrade or locate the FFmpeg binary",
+		Long: `Show, upgrade or locate the FFmpeg binary used to record and convert audio.
+
+FFmpeg is looked up in this order:
+  1. --ffmpeg-path, or the ffmpeg-path setting
+  2. FFMPEG_PATH
+  3. The FFmpeg downloaded by transcript, in the cache directory
+  4. ffmpeg in PATH
+
+When none is found, the pinned FFmpeg version is downloaded for the platform
+and verified against its SHA-256 checksum. Downloads go through the proxy of
+HTTPS_PROXY, if set. A download of an older pinned version is replaced on the
+next run, or with "transcript ffmpeg upgrade".`,
+		Example: `  transcript ffmpeg status
+  transcript ffmpeg upgrade
+  transcript ffmpeg path
+  transcript --ffmpeg-path /opt/ffmpeg/bin/ffmpeg ffmpeg status`,
+	}
+
+	cmd.AddCommand(ffmpegStatusCmd(env))
+	cmd.AddCommand(ffmpegUpgradeCmd(env))
+	cmd.AddCommand(ffmpegPathCmd(env))
+
+	return cmd
+}
+
+// ffmpegStatusCmd creates the "ffmpeg status" subcommand.
+func ffmpegStatusCmd(env *Env) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the FFmpeg binary in use and the state of the download",
+		Long: `Show the FFmpeg binary in use, where it was found and its version, the
+pinned version of downloads, the version downloaded, and the proxy of
+downloads. Nothing is downloaded.`,
+		Example: `  transcript ffmpeg status`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFFmpegStatus(cmd.Context(), cmd.OutOrStdout(), env)
+		},
+	}
+}
+
+// ffmpegUpgradeCmd creates the "ffmpeg upgrade" subcommand.
+func ffmpegUpgradeCmd(env *Env) *cobra.Command {
+	return &cobra.Command{
+		Use:   "upgrade",
+		Short: "Download the pinned FFmpeg version",
+		Long: `Download the pinned FFmpeg version into the cache directory, unless it is
+already there. The download is verified against its SHA-256 checksum.
+
+--ffmpeg-path, the ffmpeg-path setting and FFMPEG_PATH still take precedence
+over the downloaded binary.`,
+		Example: `  transcript ffmpeg upgrade
+  HTTPS_PROXY=http://proxy:3128 transcript ffmpeg upgrade`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFFmpegUpgrade(cmd.Context(), env)
+		},
+	}
+}
+
+// ffmpegPathCmd creates the "ffmpeg path" subcommand.
+func ffmpegPathCmd(env *Env) *cobra.Command {
+	return &cobra.Command{
+		Use:   "path",
+		Short: "Print the path of the FFmpeg binary in use",
+		Long: `Print the path of the FFmpeg binary in use, for scripts. Nothing is
+downloaded: without FFmpeg, the command fails.`,
+		Example: `  transcript ffmpeg path
+  "$(transcript ffmpeg path)" -hide_banner -i recording.ogg`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFFmpegPath(cmd.OutOrStdout(), env)
+		},
+	}
+}
+
+// ConfigureFFmpeg makes the ffmpeg binary of --ffmpeg-path (flagPath), or
+// else of the ffmpeg-path setting, take precedence over FFMPEG_PATH and
+// downloads. It must run before any command.
+func ConfigureFFmpeg(env *Env, flagPath string) {
+	ffmpeg.SetPath(ffmpegPathOverride(env, flagPath))
+}
+
+// ffmpegPathOverride returns the ffmpeg binary of flagPath, or else of the
+// ffmpeg-path setting (empty if neither is set).
+func ffmpegPathOverride(env *Env, flagPath string) string {
+	if flagPath != "" {
+		return config.ExpandPath(flagPath)
+	}
+	cfg, _ := env.ConfigLoader.Load() // Load errors are reported by the commands.
+	return cfg.FFmpegPath
+}
+
+// runFFmpegStatus writes the status of FFmpeg to w. An override pointing to a
+// missing binary is reported, then returned as an error.
+func runFFmpegStatus(ctx context.Context, w io.Writer, env *Env) error {
+	st, err := env.FFmpegInstaller.Status()
+	if err != nil && st.InstallDir == "" {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	switch {
+	case err != nil:
+		fmt.Fprintf(tw, "ffmpeg\tnot found (%s)\n", describeFFmpegSource(st.Source))
+	case st.Path == "":
+		fmt.Fprintf(tw, "ffmpeg\tnot installed (downloaded on first use, or run transcript ffmpeg upgrade)\n")
+	default:
+		fmt.Fprintf(tw, "ffmpeg\t%s (%s)\n", st.Path, describeFFmpegSource(st.Source))
+		version, verr := env.FFmpegInstaller.Version(ctx, st.Path)
+		if verr != nil {
+			version = "unknown: " + verr.Error()
+		}
+		fmt.Fprintf(tw, "version\t%s\n", version)
+	}
+
+	if st.Supported {
+		fmt.Fprintf(tw, "pinned\t%s (%s, verified by SHA-256)\n", st.Pinned, st.Platform)
+	} else {
+		fmt.Fprintf(tw, "pinned\t%s (no build for %s: install FFmpeg yourself)\n", st.Pinned, st.Platform)
+	}
+	switch {
+	case st.Installed == "":
+		fmt.Fprintf(tw, "downloaded\tnone\n")
+	case st.UpToDate():
+		fmt.Fprintf(tw, "downloaded\t%s in %s\n", st.Installed, st.InstallDir)
+	default:
+		fmt.Fprintf(tw, "downloaded\t%s in %s (outdated: run transcript ffmpeg upgrade)\n", st.Installed, st.InstallDir)
+	}
+	if st.Proxy != "" {
+		fmt.Fprintf(tw, "proxy\t%s (HTTPS_PROXY)\n", st.Proxy)
+	} else {
+		fmt.Fprintf(tw, "proxy\tnone\n")
+	}
+	if ferr := tw.Flush(); ferr != nil {
+		return ferr
+	}
+	return err
+}
+
+// runFFmpegUpgrade downloads the pinned FFmpeg version, unless it is
+// already installed, and tells if an override still takes precedence.
+func runFFmpegUpgrade(ctx context.Context, env *Env) error {
+	st, err := env.FFmpegInstaller.Status()
+	if err != nil && !errors.Is(err, ffmpeg.ErrNotFound) {
+		return err
+	}
+	if st.UpToDate() {
+		fmt.Fprintf(env.Stderr, "FFmpeg %s is up to date in %s\n", st.Installed, st.InstallDir)
+	} else {
+		fmt.Fprintf(env.Stderr, "Downloading FFmpeg %s...\n", st.Pinned)
+		if _, err := env.FFmpegInstaller.Upgrade(ctx); err != nil {
+			return err
+		}
+		fmt.Fprintf(env.Stderr, "Installed FFmpeg %s in %s\n", st.Pinned, st.InstallDir)
+	}
+
+	if st.Source == ffmpeg.SourceOverride || st.Source == ffmpeg.SourceEnv {
+		fmt.Fprintf(env.Stderr, "Note: the FFmpeg of %s is still used first\n", describeFFmpegSource(st.Source))
+	}
+	return nil
+}
+
+// runFFmpegPath writes the path of the FFmpeg binary in use to w.
+func runFFmpegPath(w io.Writer, env *Env) error {
+	st, err := env.FFmpegInstaller.Status()
+	if err != nil {
+		return err
+	}
+	if st.Path == "" {
+		return fmt.Errorf("%w: run transcript ffmpeg upgrade to download it", ffmpeg.ErrNotFound)
+	}
+	fmt.Fprintln(w, st.Path)
+	return nil
+}
+
+// describeFFmpegSource returns where an FFmpeg binary of source was set.
+func describeFFmpegSource(source ffmpeg.Source) string {
+	switch source {
+	case ffmpeg.SourceOverride:
+		return "--ffmpeg-path or ffmpeg-path"
+	case ffmpeg.SourceEnv:
+		return "FFMPEG_PATH"
+	case ffmpeg.SourceDownloaded:
+		return "downloaded by transcript"
+	default:
+		return string(source)
+	}
+}
@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/ffmpeg"
+)
+
+func TestRunFFmpegStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		status  ffmpeg.Status
+		err     error
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "pinned download in use",
+			want: []string{
+				"ffmpeg      /cache/go-transcript/bin/ffmpeg (downloaded by transcript)",
+				"version     6.1.1",
+				"pinned      6.1.1 (linux-amd64, verified by SHA-256)",
+				"downloaded  6.1.1 in /cache/go-transcript/bin",
+				"proxy       none",
+			},
+		},
+		{
+			name: "outdated download behind a proxy",
+			status: ffmpeg.Status{
+				Path: "/usr/bin/ffmpeg", Source: ffmpeg.SourceSystem, InstallDir: "/cache/bin",
+				Installed: "5.1", Pinned: "6.1.1", Platform: "linux-amd64", Supported: true,
+				Proxy: "http://proxy:3128",
+			},
+			want: []string{
+				"/usr/bin/ffmpeg (system PATH)",
+				"5.1 in /cache/bin (outdated: run transcript ffmpeg upgrade)",
+				"http://proxy:3128 (HTTPS_PROXY)",
+			},
+		},
+		{
+			name: "nothing installed on an unsupported platform",
+			status: ffmpeg.Status{
+				InstallDir: "/cache/bin", Pinned: "6.1.1", Platform: "linux-arm64",
+			},
+			want: []string{
+				"not installed (downloaded on first use, or run transcript ffmpeg upgrade)",
+				"6.1.1 (no build for linux-arm64: install FFmpeg yourself)",
+				"downloaded  none",
+			},
+		},
+		{
+			name: "missing override",
+			status: ffmpeg.Status{
+				Source: ffmpeg.SourceOverride, InstallDir: "/cache/bin", Pinned: "6.1.1", Platform: "linux-amd64", Supported: true,
+			},
+			err:     fmt.Errorf("%w: ffmpeg-path is set to %q but binary not found", ffmpeg.ErrNotFound, "/opt/ffmpeg"),
+			want:    []string{"not found (--ffmpeg-path or ffmpeg-path)"},
+			wantErr: ffmpeg.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			installer := &mockFFmpegInstaller{}
+			if tt.status.InstallDir != "" {
+				installer.StatusFunc = func() (ffmpeg.Status, error) { return tt.status, tt.err }
+			}
+			env := &Env{Stderr: &syncBuffer{}, FFmpegInstaller: installer}
+
+			var out strings.Builder
+			err := runFFmpegStatus(context.Background(), &out, env)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("runFFmpegStatus() unexpected error: %v", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("runFFmpegStatus() error = %v, want %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("runFFmpegStatus() output = %q, want %q", out.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunFFmpegUpgrade(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		status       ffmpeg.Status
+		upgradeErr   error
+		wantUpgrades int
+		wantStderr   []string
+		wantErr      error
+	}{
+		{
+			name:       "up to date",
+			status:     ffmpeg.Status{InstallDir: "/cache/bin", Installed: "6.1.1", Pinned: "6.1.1", Source: ffmpeg.SourceDownloaded},
+			wantStderr: []string{"FFmpeg 6.1.1 is up to date in /cache/bin"},
+		},
+		{
+			name:         "outdated download",
+			status:       ffmpeg.Status{InstallDir: "/cache/bin", Installed: "5.1", Pinned: "6.1.1", Source: ffmpeg.SourceSystem},
+			wantUpgrades: 1,
+			wantStderr:   []string{"Downloading FFmpeg 6.1.1...", "Installed FFmpeg 6.1.1 in /cache/bin"},
+		},
+		{
+			name:         "FFMPEG_PATH still used first",
+			status:       ffmpeg.Status{InstallDir: "/cache/bin", Pinned: "6.1.1", Source: ffmpeg.SourceEnv},
+			wantUpgrades: 1,
+			wantStderr:   []string{"Installed FFmpeg 6.1.1", "Note: the FFmpeg of FFMPEG_PATH is still used first"},
+		},
+		{
+			name:         "download fails",
+			status:       ffmpeg.Status{InstallDir: "/cache/bin", Pinned: "6.1.1"},
+			upgradeErr:   fmt.Errorf("%w: HTTP 407", ffmpeg.ErrDownloadFailed),
+			wantUpgrades: 1,
+			wantErr:      ffmpeg.ErrDownloadFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			installer := &mockFFmpegInstaller{
+				StatusFunc:  func() (ffmpeg.Status, error) { return tt.status, nil },
+				UpgradeFunc: func(ctx context.Context) (bool, error) { return tt.upgradeErr == nil, tt.upgradeErr },
+			}
+			stderr := &syncBuffer{}
+			env := &Env{Stderr: stderr, FFmpegInstaller: installer}
+
+			err := runFFmpegUpgrade(context.Background(), env)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("runFFmpegUpgrade() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := installer.Upgrades(); got != tt.wantUpgrades {
+				t.Errorf("Upgrade() called %d times, want %d", got, tt.wantUpgrades)
+			}
+			for _, want := range tt.wantStderr {
+				if !strings.Contains(stderr.String(), want) {
+					t.Errorf("stderr = %q, want %q", stderr.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunFFmpegPath(t *testing.T) {
+	t.Parallel()
+
+	t.Run("prints the binary in use", func(t *testing.T) {
+		t.Parallel()
+
+		var out strings.Builder
+		if err := runFFmpegPath(&out, &Env{FFmpegInstaller: &mockFFmpegInstaller{}}); err != nil {
+			t.Fatalf("runFFmpegPath() unexpected error: %v", err)
+		}
+		if out.String() != "/cache/go-transcript/bin/ffmpeg\n" {
+			t.Errorf("runFFmpegPath() output = %q, want the downloaded binary", out.String())
+		}
+	})
+
+	t.Run("not installed", func(t *testing.T) {
+		t.Parallel()
+
+		installer := &mockFFmpegInstaller{
+			StatusFunc: func() (ffmpeg.Status, error) { return ffmpeg.Status{InstallDir: "/cache/bin"}, nil },
+		}
+		var out strings.Builder
+		if err := runFFmpegPath(&out, &Env{FFmpegInstaller: installer}); !errors.Is(err, ffmpeg.ErrNotFound) {
+			t.Errorf("runFFmpegPath() error = %v, want ErrNotFound", err)
+		}
+		if out.Len() != 0 {
+			t.Errorf("runFFmpegPath() output = %q, want nothing", out.String())
+		}
+	})
+}
+
+func TestFFmpegPathOverride(t *testing.T) {
+	t.Parallel()
+
+	env := &Env{ConfigLoader: &mockConfigLoader{LoadFunc: func() (config.Config, error) {
+		return config.Config{FFmpegPath: "/opt/ffmpeg/bin/ffmpeg"}, nil
+	}}}
+
+	if got := ffmpegPathOverride(env, "/usr/local/bin/ffmpeg"); got != "/usr/local/bin/ffmpeg" {
+		t.Errorf("ffmpegPathOverride() with --ffmpeg-path = %q, want the flag", got)
+	}
+	if got := ffmpegPathOverride(env, ""); got != "/opt/ffmpeg/bin/ffmpeg" {
+		t.Errorf("ffmpegPathOverride() = %q, want the ffmpeg-path setting", got)
+	}
+}
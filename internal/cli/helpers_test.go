@@ -56,6 +56,12 @@ type testMocks struct {
 	chunker        *mockChunkerFactory
 	recorder       *mockRecorderFactory
 	deviceLister   *mockDeviceListerFactory
+	audioExtractor *mockAudioExtractorFactory
+	mediaProber    *mockMediaProberFactory
+	downloader     *mockDownloaderFactory
+	watcher        *mockWatcherFactory
+	notifier       *mockNotifier
+	prober         *mockProber
 }
 
 func newTestMocks() *testMocks {
@@ -67,6 +73,12 @@ func newTestMocks() *testMocks {
 		chunker:        &mockChunkerFactory{},
 		recorder:       &mockRecorderFactory{},
 		deviceLister:   &mockDeviceListerFactory{},
+		audioExtractor: &mockAudioExtractorFactory{},
+		mediaProber:    &mockMediaProberFactory{},
+		downloader:     &mockDownloaderFactory{},
+		watcher:        &mockWatcherFactory{},
+		notifier:       &mockNotifier{},
+		prober:         &mockProber{},
 	}
 }
 
@@ -102,16 +114,22 @@ func testEnv(opts ...testEnvOption) (*Env, *testMocks) {
 	}
 
 	env := &Env{
-		Stderr:              options.stderr,
-		Getenv:              options.getenv,
-		Now:                 options.now,
-		FFmpegResolver:      options.mocks.ffmpegResolver,
-		ConfigLoader:        options.mocks.configLoader,
-		TranscriberFactory:  options.mocks.transcriber,
-		RestructurerFactory: options.mocks.restructurer,
-		ChunkerFactory:      options.mocks.chunker,
-		RecorderFactory:     options.mocks.recorder,
-		DeviceListerFactory: options.mocks.deviceLister,
+		Stderr:                options.stderr,
+		Getenv:                options.getenv,
+		Now:                   options.now,
+		FFmpegResolver:        options.mocks.ffmpegResolver,
+		ConfigLoader:          options.mocks.configLoader,
+		TranscriberFactory:    options.mocks.transcriber,
+		RestructurerFactory:   options.mocks.restructurer,
+		ChunkerFactory:        options.mocks.chunker,
+		RecorderFactory:       options.mocks.recorder,
+		DeviceListerFactory:   options.mocks.deviceLister,
+		AudioExtractorFactory: options.mocks.audioExtractor,
+		MediaProberFactory:    options.mocks.mediaProber,
+		DownloaderFactory:     options.mocks.downloader,
+		WatcherFactory:        options.mocks.watcher,
+		Notifier:              options.mocks.notifier,
+		Prober:                options.mocks.prober,
 	}
 
 	return env, options.mocks
@@ -140,6 +158,8 @@ func defaultTestEnv(key string) string {
 		return "test-openai-key"
 	case EnvDeepSeekAPIKey:
 		return "test-deepseek-key"
+	case EnvAnthropicAPIKey:
+		return "test-anthropic-key"
 	default:
 		return ""
 	}
@@ -184,11 +204,12 @@ func transcribeEnvForExtensionTest(t *testing.T) (*Env, func() string) {
 
 	stderr := &syncBuffer{}
 	env := &Env{
-		Stderr:         stderr,
-		Getenv:         defaultTestEnv,
-		Now:            fixedTime(time.Now()),
-		FFmpegResolver: &mockFFmpegResolver{},
-		ConfigLoader:   &mockConfigLoader{},
+		Stderr:             stderr,
+		Getenv:             defaultTestEnv,
+		Now:                fixedTime(time.Now()),
+		FFmpegResolver:     &mockFFmpegResolver{},
+		ConfigLoader:       &mockConfigLoader{},
+		MediaProberFactory: &mockMediaProberFactory{},
 		ChunkerFactory: &mockChunkerFactory{
 			mockChunker: &mockChunker{
 				ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
@@ -196,7 +217,8 @@ func transcribeEnvForExtensionTest(t *testing.T) (*Env, func() string) {
 				},
 			},
 		},
-		TranscriberFactory: &mockTranscriberFactory{},
+		TranscriberFactory:  &mockTranscriberFactory{},
+		DeviceListerFactory: &mockDeviceListerFactory{},
 	}
 
 	return env, stderr.String
@@ -211,6 +233,7 @@ func structureEnvForExtensionTest(t *testing.T) (*Env, func() string) {
 	env := &Env{
 		Stderr:       stderr,
 		Getenv:       defaultTestEnv,
+		Now:          time.Now,
 		ConfigLoader: &mockConfigLoader{},
 		RestructurerFactory: &mockRestructurerFactory{
 			mockMapReducer: &mockMapReduceRestructurer{},
@@ -251,8 +274,23 @@ func liveEnvForExtensionTest(t *testing.T, outputDir string) (*Env, func() strin
 				},
 			},
 		},
-		TranscriberFactory: &mockTranscriberFactory{},
+		TranscriberFactory:  &mockTranscriberFactory{},
+		DeviceListerFactory: &mockDeviceListerFactory{},
 	}
 
 	return env, stderr.String
 }
+
+// createTestVault creates an Obsidian vault whose daily notes are in the
+// Daily folder, and returns its root.
+func createTestVault(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".obsidian"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".obsidian", "daily-notes.json"), []byte(`{"folder": "Daily"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
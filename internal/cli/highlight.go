@@ -0,0 +1,290 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// highlightsExt ends the file of the highlight markers dropped while recording
+// with record, next to the audio: "day.ogg" -> "day.ogg.highlights".
+// transcribe reads it to render the markers.
+const highlightsExt = ".highlights"
+
+// highlightsPath returns the file of the highlight markers of audioPath.
+func highlightsPath(audioPath string) string {
+	return audioPath + highlightsExt
+}
+
+// highlightInput returns the input read for highlight markers while
+// recording: stdin when it is a terminal, where Enter drops a marker, or the
+// input set on cmd. Nil for piped stdin, which is not typed by the speaker.
+func highlightInput(cmd *cobra.Command) io.Reader {
+	in := cmd.InOrStdin()
+	if f, ok := in.(*os.File); ok && !isTerminal(f) {
+		return nil
+	}
+	return in
+}
+
+// watchHighlights calls mark on each line read from in (nil: none) and on
+// each SIGUSR2 (not on Windows), until stop is called. Reading a terminal
+// cannot be interrupted: the last read ends with the process.
+func watchHighlights(in io.Reader, mark func()) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	notifyHighlightSignal(signals)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-signals:
+				mark()
+			case <-done:
+				return
+			}
+		}
+	}()
+	if in != nil {
+		go func() {
+			scanner := bufio.NewScanner(in)
+			for scanner.Scan() {
+				select {
+				case <-done:
+					return
+				default:
+					mark()
+				}
+			}
+		}()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+// highlights collects the highlight markers dropped while recording, as
+// times since the start of the recording. Each marker is confirmed on w.
+//
+// Methods of a nil *highlights do nothing, so that callers do not check
+// whether markers are collected.
+type highlights struct {
+	now func() time.Time
+	w   io.Writer
+
+	mu      sync.Mutex
+	start   time.Time
+	marks   []liveMark
+	merged  int  // Marks already merged into the transcript
+	stopped bool // Markers dropped after stop are ignored
+	unwatch func()
+}
+
+// startHighlights starts the recording clock and collects the highlight
+// markers dropped from in (nil: none) and with SIGUSR2, until stop is called.
+func startHighlights(env *Env, in io.Reader) *highlights {
+	h := &highlights{now: env.Now, w: env.Stderr, start: env.Now()}
+	h.unwatch = watchHighlights(in, h.mark)
+	if in != nil {
+		fmt.Fprintln(env.Stderr, "Press Enter to mark a highlight.")
+	}
+	return h
+}
+
+// mark drops a highlight marker at the current time of the recording.
+func (h *highlights) mark() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.stopped {
+		return
+	}
+	m := liveMark{at: h.now().Sub(h.start)}
+	h.marks = append(h.marks, m)
+	fmt.Fprintf(h.w, "%s marked\n", m.markdown())
+}
+
+// stop stops collecting markers, once the recording ends.
+func (h *highlights) stop() {
+	if h == nil {
+		return
+	}
+	h.unwatch()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stopped = true
+}
+
+// take returns the markers dropped before the time at of the recording, and
+// not taken yet: they follow the segment ending at at.
+func (h *highlights) take(at time.Duration) []liveMark {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	start := h.merged
+	for h.merged < len(h.marks) && h.marks[h.merged].at < at {
+		h.merged++
+	}
+	return h.marks[start:h.merged:h.merged]
+}
+
+// pending returns the markers not taken yet, dropped after the last segment.
+func (h *highlights) pending() []liveMark {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	marks := h.marks[h.merged:]
+	h.merged = len(h.marks)
+	return marks
+}
+
+// times returns the times of the markers dropped, in order.
+func (h *highlights) times() []time.Duration {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return highlightTimes(h.marks)
+}
+
+// highlightTimes returns the times of the highlights among marks, without
+// the notes.
+func highlightTimes(marks []liveMark) []time.Duration {
+	var times []time.Duration
+	for _, m := range marks {
+		if m.note == "" {
+			times = append(times, m.at)
+		}
+	}
+	return times
+}
+
+// writeHighlights writes the markers to path, one "⭐ HH:MM:SS" line each.
+func writeHighlights(path string, marks []time.Duration) error {
+	var b strings.Builder
+	for _, m := range marks {
+		b.WriteString(format.Highlight(m) + "\n")
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// saveHighlights writes the markers of a recording to path, warning if they
+// cannot be written: the recording is kept either way.
+func saveHighlights(env *Env, path string, marks []time.Duration) {
+	if len(marks) == 0 {
+		return
+	}
+	if err := writeHighlights(path, marks); err != nil {
+		warnf(env.Stderr, warnFileNotSaved, "failed to save highlights: %v", err)
+		return
+	}
+	fmt.Fprintf(env.Stderr, "Highlights saved: %s (%s)\n", path, plural(len(marks), "marker"))
+}
+
+// readHighlights reads the markers written by writeHighlights. A missing file
+// has no markers; blank lines are skipped.
+func readHighlights(path string) ([]time.Duration, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var marks []time.Duration
+	for line := range strings.Lines(string(data)) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		m, err := format.ParseHighlight(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		marks = append(marks, m)
+	}
+	return marks, nil
+}
+
+// loadHighlights returns the markers saved by record next to inputPath,
+// warning if they cannot be read: the input is transcribed without them.
+func loadHighlights(env *Env, inputPath string) []time.Duration {
+	marks, err := readHighlights(highlightsPath(inputPath))
+	if err != nil {
+		warnf(env.Stderr, warnHighlights, "ignoring highlights: %v", err)
+		return nil
+	}
+	if len(marks) > 0 {
+		fmt.Fprintf(env.Stderr, "Highlights: %s from %s\n", plural(len(marks), "marker"), highlightsPath(inputPath))
+	}
+	return marks
+}
+
+// renderedHighlights returns the markers rendered in a transcript of format
+// f: none in subtitles, which have no room for them.
+func renderedHighlights(f OutputFormat, marks []time.Duration) []time.Duration {
+	if f.IsSubtitle() {
+		return nil
+	}
+	return marks
+}
+
+// renderHighlighted renders the transcript of chunks and results with render,
+// with the markers following the chunk they were dropped in, as paragraphs.
+func renderHighlighted(marks []time.Duration, chunks []audio.Chunk, results []string,
+	render func([]audio.Chunk, []string) (string, error)) (string, error) {
+	if len(marks) == 0 {
+		return render(chunks, results)
+	}
+
+	var (
+		parts []string
+		from  int // First chunk not rendered yet
+		next  int // First marker not placed yet
+	)
+	for i, c := range chunks {
+		last := i == len(chunks)-1
+		end := next
+		for end < len(marks) && (last || marks[end] < c.EndTime) {
+			end++
+		}
+		if end == next && !last {
+			continue
+		}
+		text, err := render(chunks[from:i+1], results[from:i+1])
+		if err != nil {
+			return "", err
+		}
+		if text = strings.TrimRight(text, "\n"); text != "" {
+			parts = append(parts, text)
+		}
+		for _, m := range marks[next:end] {
+			parts = append(parts, format.Highlight(m))
+		}
+		from, next = i+1, end
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package cli
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyHighlightSignal relays SIGUSR2, which drops a highlight marker, to c.
+func notifyHighlightSignal(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGUSR2)
+}
@@ -0,0 +1,7 @@
+package cli
+
+import "os"
+
+// notifyHighlightSignal does nothing: Windows has no SIGUSR2, highlight
+// markers are dropped with Enter only.
+func notifyHighlightSignal(chan<- os.Signal) {}
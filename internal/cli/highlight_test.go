@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+func TestHighlights(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	clock := &tuiClock{now: start}
+	stderr := &syncBuffer{}
+	in, typed := io.Pipe()
+	t.Cleanup(func() { _ = typed.Close() })
+
+	marks := startHighlights(&Env{Stderr: stderr, Now: clock.Now}, in)
+	if !strings.Contains(stderr.String(), "Press Enter to mark a highlight.") {
+		t.Errorf("stderr = %q, want the Enter hint", stderr.String())
+	}
+
+	want := []time.Duration{5 * time.Second, 42*time.Minute + 15*time.Second, time.Hour}
+	for i, at := range want {
+		clock.Set(start.Add(at))
+		if _, err := fmt.Fprintln(typed); err != nil {
+			t.Fatal(err)
+		}
+		waitForMarks(t, stderr, i+1)
+	}
+	marks.stop()
+	marks.mark() // Ignored once stopped
+
+	if got := marks.times(); !reflect.DeepEqual(got, want) {
+		t.Errorf("times() = %v, want %v", got, want)
+	}
+	if !strings.Contains(stderr.String(), "⭐ 00:42:15 marked") {
+		t.Errorf("stderr = %q, want the marker confirmed", stderr.String())
+	}
+
+	if taken := marks.take(time.Minute); len(taken) != 1 || taken[0].at != 5*time.Second {
+		t.Errorf("take(1m) = %v, want the marker at 5s", taken)
+	}
+	if pending := marks.pending(); len(pending) != 2 {
+		t.Errorf("pending() = %v, want the 2 markers left", pending)
+	}
+
+	var none *highlights
+	none.stop()
+	if none.times() != nil || none.take(time.Hour) != nil || none.pending() != nil {
+		t.Error("nil highlights returned markers")
+	}
+}
+
+// waitForMarks waits until n markers are confirmed on stderr.
+func waitForMarks(t *testing.T, stderr *syncBuffer, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for strings.Count(stderr.String(), " marked\n") < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("stderr = %q, want %d markers", stderr.String(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHighlightInput(t *testing.T) {
+	t.Parallel()
+
+	cmd := RecordCmd(&Env{})
+	cmd.SetIn(strings.NewReader("\n"))
+	if highlightInput(cmd) == nil {
+		t.Error("highlightInput() = nil, want the input set on the command")
+	}
+
+	piped, err := os.Create(filepath.Join(t.TempDir(), "stdin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = piped.Close() })
+	cmd.SetIn(piped)
+	if highlightInput(cmd) != nil {
+		t.Error("highlightInput() read a file that is not a terminal")
+	}
+}
+
+func TestWriteReadHighlights(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := highlightsPath(filepath.Join(dir, "day.ogg"))
+	if want := filepath.Join(dir, "day.ogg.highlights"); path != want {
+		t.Errorf("highlightsPath() = %q, want %q", path, want)
+	}
+
+	marks := []time.Duration{45 * time.Second, 42*time.Minute + 15*time.Second}
+	if err := writeHighlights(path, marks); err != nil {
+		t.Fatalf("writeHighlights() unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "⭐ 00:00:45\n⭐ 00:42:15\n"; string(data) != want {
+		t.Errorf("highlights file = %q, want %q", data, want)
+	}
+	got, err := readHighlights(path)
+	if err != nil || !reflect.DeepEqual(got, marks) {
+		t.Errorf("readHighlights() = %v, %v, want %v", got, err, marks)
+	}
+
+	if got, err := readHighlights(filepath.Join(dir, "missing.highlights")); err != nil || got != nil {
+		t.Errorf("readHighlights(missing) = %v, %v, want no markers", got, err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.highlights")
+	if err := os.WriteFile(invalid, []byte("⭐ 00:00:45\n\nlunch\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readHighlights(invalid); err == nil || !strings.Contains(err.Error(), "lunch") {
+		t.Errorf("readHighlights(invalid) error = %v, want the invalid line", err)
+	}
+}
+
+func TestRenderHighlighted(t *testing.T) {
+	t.Parallel()
+
+	chunks := []audio.Chunk{
+		{Index: 0, StartTime: 0, EndTime: 5 * time.Minute},
+		{Index: 1, StartTime: 5 * time.Minute, EndTime: 10 * time.Minute},
+		{Index: 2, StartTime: 10 * time.Minute, EndTime: 15 * time.Minute},
+	}
+	results := []string{"First.", "Second.", "Third."}
+	render := func(chunks []audio.Chunk, results []string) (string, error) {
+		return renderTranscript(TextFormat, SpeakerLabels{}, chunks, results)
+	}
+
+	tests := []struct {
+		name  string
+		marks []time.Duration
+		want  string
+	}{
+		{
+			name: "no markers",
+			want: "First.\n\nSecond.\n\nThird.",
+		},
+		{
+			name:  "markers follow their chunk",
+			marks: []time.Duration{time.Minute, 2 * time.Minute, 11 * time.Minute},
+			want:  "First.\n\n⭐ 00:01:00\n\n⭐ 00:02:00\n\nSecond.\n\nThird.\n\n⭐ 00:11:00",
+		},
+		{
+			name:  "markers past the audio end it",
+			marks: []time.Duration{6 * time.Minute, 20 * time.Minute},
+			want:  "First.\n\nSecond.\n\n⭐ 00:06:00\n\nThird.\n\n⭐ 00:20:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := renderHighlighted(tt.marks, chunks, results, render)
+			if err != nil {
+				t.Fatalf("renderHighlighted() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renderHighlighted() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if marks := renderedHighlights(SRTFormat, []time.Duration{time.Minute}); marks != nil {
+		t.Errorf("renderedHighlights(srt) = %v, want none in subtitles", marks)
+	}
+}
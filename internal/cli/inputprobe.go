@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/ffmpeg"
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// Rough transcription speeds, in seconds of audio per second, for the
+// estimate printed before transcribing: hosted APIs with parallel requests,
+// and whisper.cpp on a laptop CPU. Actual speeds vary with the network and
+// the hardware.
+const (
+	remoteTranscriptionSpeed = 20
+	localTranscriptionSpeed  = 2
+)
+
+// checkFormat rejects inputPath when it is not a media file, before the API
+// key or FFmpeg is looked up (fail-fast). Files with a supported extension
+// are accepted; the content of others is sniffed, and a document (text, PDF,
+// image, archive) fails with ErrUnsupportedFormat. What is left is decoded
+// by FFmpeg in the input check (see probeInput).
+func checkFormat(inputPath string) error {
+	ext := strings.ToLower(filepath.Ext(inputPath))
+	if supportedFormats[ext] {
+		return nil
+	}
+
+	f, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("cannot read input file: %w", err)
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return fmt.Errorf("cannot read input file: %w", err)
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	if mediaType, _, _ := strings.Cut(contentType, ";"); !isMediaType(mediaType) {
+		return fmt.Errorf("unsupported format %q, content %s (supported: %s): %w",
+			ext, mediaType, supportedFormatsList(), ErrUnsupportedFormat)
+	}
+	return nil
+}
+
+// isMediaType reports whether a sniffed media type may hold audio: audio and
+// video types, and unrecognized binary data, which FFmpeg may still decode.
+func isMediaType(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "audio/"), strings.HasPrefix(mediaType, "video/"):
+		return true
+	default:
+		return mediaType == "application/ogg" || mediaType == "application/octet-stream"
+	}
+}
+
+// checkInput checks that inputPath can be transcribed (see probeInput), and
+// prints its duration and the estimated transcription time with backend.
+func checkInput(ctx context.Context, env *Env, ffmpegPath, inputPath string, backend Backend) error {
+	m, err := probeInput(ctx, env, ffmpegPath, inputPath)
+	if err != nil {
+		return err
+	}
+	printInputSummary(env.Stderr, m, backend)
+	return nil
+}
+
+// probeInput checks that inputPath has an audio stream FFmpeg can decode,
+// before anything is extracted or uploaded, and returns its metadata.
+// Returns ErrProtectedMedia for audio protected by DRM, audio.ErrNoAudioTrack
+// for a file without audio, and ErrUnreadableMedia for a corrupt file or one
+// that is not a media file at all (e.g. a renamed document).
+func probeInput(ctx context.Context, env *Env, ffmpegPath, inputPath string) (ffmpeg.Metadata, error) {
+	prober, err := env.MediaProberFactory.NewMediaProber(ffmpegPath)
+	if err != nil {
+		return ffmpeg.Metadata{}, err
+	}
+
+	m, err := prober.Probe(ctx, inputPath)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ffmpeg.Metadata{}, ctx.Err()
+		}
+		return ffmpeg.Metadata{}, fmt.Errorf("%w: %s: %v", ErrUnreadableMedia, inputPath, err)
+	}
+	switch {
+	case m.Encrypted:
+		return m, fmt.Errorf("%w: %s", ErrProtectedMedia, inputPath)
+	case m.AudioStreams == 0:
+		return m, fmt.Errorf("%w in %s", audio.ErrNoAudioTrack, inputPath)
+	}
+
+	if err := prober.VerifyAudio(ctx, inputPath); err != nil {
+		if ctx.Err() != nil {
+			return m, ctx.Err()
+		}
+		return m, fmt.Errorf("%w: %s: %v", ErrUnreadableMedia, inputPath, err)
+	}
+	return m, nil
+}
+
+// printInputSummary writes the duration and encoding of the input, and how
+// long transcribing it should take with backend.
+func printInputSummary(w io.Writer, m ffmpeg.Metadata, backend Backend) {
+	speed := time.Duration(remoteTranscriptionSpeed)
+	if backend.IsLocal() {
+		speed = localTranscriptionSpeed
+	}
+	estimate := max(m.Duration/speed, time.Second)
+	fmt.Fprintf(w, "Input: %s of audio (%s), transcription ~%s\n",
+		format.DurationHuman(m.Duration), describeEncoding(m), format.DurationHuman(estimate))
+}
+
+// describeEncoding returns the codec, channels and sample rate of the audio
+// of m, e.g. "opus, mono, 48kHz".
+func describeEncoding(m ffmpeg.Metadata) string {
+	s := m.Codec
+	switch m.Channels {
+	case 0:
+	case 1:
+		s += ", mono"
+	case 2:
+		s += ", stereo"
+	default:
+		s += fmt.Sprintf(", %d channels", m.Channels)
+	}
+	if m.SampleRate > 0 {
+		s += fmt.Sprintf(", %gkHz", float64(m.SampleRate)/1000)
+	}
+	return s
+}
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/ffmpeg"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestCheckFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		file    string
+		content []byte
+		wantErr error
+	}{
+		{name: "supported extension", file: "audio.ogg", content: []byte("fake audio content")},
+		{name: "ogg content", file: "audio.opus", content: []byte("OggS\x00\x02 opus audio")},
+		{name: "unrecognized binary", file: "audio.ts", content: []byte{0x47, 0x40, 0x00, 0x10}},
+		{name: "text", file: "notes.txt", content: []byte("meeting notes"), wantErr: ErrUnsupportedFormat},
+		{name: "renamed PDF", file: "audio.xyz", content: []byte("%PDF-1.7\n"), wantErr: ErrUnsupportedFormat},
+		{name: "empty", file: "audio.aac", wantErr: ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, tt.content, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := checkFormat(path); !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkFormat(%s) error = %v, want %v", tt.file, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProbeInput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		probe    func(ctx context.Context, path string) (ffmpeg.Metadata, error)
+		verify   func(ctx context.Context, path string) error
+		wantErr  error
+		wantText string
+	}{
+		{
+			name: "decodable audio",
+		},
+		{
+			name: "protected purchase",
+			probe: func(ctx context.Context, path string) (ffmpeg.Metadata, error) {
+				return ffmpeg.Metadata{Duration: 3 * time.Minute, Codec: "none", AudioStreams: 1, Encrypted: true}, nil
+			},
+			wantErr: ErrProtectedMedia,
+		},
+		{
+			name: "renamed video without sound",
+			probe: func(ctx context.Context, path string) (ffmpeg.Metadata, error) {
+				return ffmpeg.Metadata{Format: "mov,mp4,m4a,3gp,3g2,mj2", Duration: time.Minute}, nil
+			},
+			wantErr: audio.ErrNoAudioTrack,
+		},
+		{
+			name: "not a media file",
+			probe: func(ctx context.Context, path string) (ffmpeg.Metadata, error) {
+				return ffmpeg.Metadata{}, errors.New("could not parse duration from ffmpeg output")
+			},
+			wantErr:  ErrUnreadableMedia,
+			wantText: "could not parse duration",
+		},
+		{
+			name: "corrupt audio",
+			verify: func(ctx context.Context, path string) error {
+				return errors.New("Invalid data found when processing input")
+			},
+			wantErr:  ErrUnreadableMedia,
+			wantText: "Invalid data found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			prober := &mockMediaProber{ProbeFunc: tt.probe, VerifyAudioFunc: tt.verify}
+			env := &Env{MediaProberFactory: &mockMediaProberFactory{mockMediaProber: prober}}
+
+			_, err := probeInput(context.Background(), env, "/usr/bin/ffmpeg", "/tmp/input.ogg")
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("probeInput() unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("probeInput() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), "/tmp/input.ogg") || !strings.Contains(err.Error(), tt.wantText) {
+				t.Errorf("probeInput() error = %q, want the file and %q", err, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestPrintInputSummary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		m       ffmpeg.Metadata
+		backend Backend
+		want    string
+	}{
+		{
+			name:    "recording with a hosted API",
+			m:       ffmpeg.Metadata{Duration: time.Hour, Codec: "opus", Channels: 1, SampleRate: 48000},
+			backend: OpenAIBackend,
+			want:    "Input: 1h of audio (opus, mono, 48kHz), transcription ~3m\n",
+		},
+		{
+			name:    "podcast with whisper.cpp",
+			m:       ffmpeg.Metadata{Duration: 30 * time.Minute, Codec: "mp3", Channels: 2, SampleRate: 44100},
+			backend: LocalBackend,
+			want:    "Input: 30m of audio (mp3, stereo, 44.1kHz), transcription ~15m\n",
+		},
+		{
+			name:    "short surround clip",
+			m:       ffmpeg.Metadata{Duration: 10 * time.Second, Codec: "ac3", Channels: 6, SampleRate: 48000},
+			backend: OpenAIBackend,
+			want:    "Input: 10s of audio (ac3, 6 channels, 48kHz), transcription ~1s\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var b strings.Builder
+		printInputSummary(&b, tt.m, tt.backend)
+		if b.String() != tt.want {
+			t.Errorf("%s: printInputSummary() = %q, want %q", tt.name, b.String(), tt.want)
+		}
+	}
+}
+
+func TestRunTranscribe_InputCheck(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rejects unreadable input before chunking", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			t.Error("Transcribe() called, want the input rejected first")
+			return "", nil
+		})
+		env.ChunkerFactory = &mockChunkerFactory{mockChunker: &mockChunker{
+			ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+				t.Error("Chunk() called, want the input rejected first")
+				return nil, nil
+			},
+		}}
+		env.MediaProberFactory = &mockMediaProberFactory{mockMediaProber: &mockMediaProber{
+			VerifyAudioFunc: func(ctx context.Context, path string) error {
+				return errors.New("Invalid data found when processing input")
+			},
+		}}
+
+		inputPath := createTestAudioFile(t, "notes.ogg")
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "notes.md"), "", false, 1, "", "", "deepseek")
+		err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts)
+		if !errors.Is(err, ErrUnreadableMedia) {
+			t.Errorf("RunTranscribe() error = %v, want ErrUnreadableMedia", err)
+		}
+	})
+
+	t.Run("reports the input", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "Hello.", nil
+		})
+		prober := &mockMediaProber{}
+		env.MediaProberFactory = &mockMediaProberFactory{mockMediaProber: prober}
+
+		inputPath := createTestAudioFile(t, "talk.ogg")
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "talk.md"), "", false, 1, "", "", "deepseek")
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+			t.Fatalf("RunTranscribe() unexpected error: %v", err)
+		}
+		if probed := prober.Probed(); len(probed) != 1 || probed[0] != inputPath {
+			t.Errorf("probed %v, want %q", probed, inputPath)
+		}
+		if !strings.Contains(stderr.String(), "Input: 10m of audio (opus, mono, 48kHz)") {
+			t.Errorf("stderr = %q, want the input reported", stderr.String())
+		}
+	})
+}
@@ -2,7 +2,6 @@ package cli
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -13,12 +12,19 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/cleanup"
 	"github.com/alnah/go-transcript/internal/config"
 	"github.com/alnah/go-transcript/internal/format"
+	"github.com/alnah/go-transcript/internal/integrations/obsidian"
 	"github.com/alnah/go-transcript/internal/interrupt"
 	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/notes"
+	"github.com/alnah/go-transcript/internal/progress"
+	"github.com/alnah/go-transcript/internal/redact"
+	"github.com/alnah/go-transcript/internal/speakerstats"
 	"github.com/alnah/go-transcript/internal/template"
 	"github.com/alnah/go-transcript/internal/transcribe"
+	"github.com/alnah/go-transcript/internal/vocab"
 )
 
 // postInterruptTimeout is the maximum time allowed for transcription and
@@ -26,6 +32,16 @@ import (
 // context since the original context is cancelled by the interrupt.
 const postInterruptTimeout = 30 * time.Minute
 
+// Streaming mode parameters.
+const (
+	// streamSegmentDuration is the length of each segment transcribed while recording.
+	// Short enough for near-real-time feedback, long enough to give the model context.
+	streamSegmentDuration = 30 * time.Second
+
+	// streamPollInterval is how often the segment directory is checked for new segments.
+	streamPollInterval = 500 * time.Millisecond
+)
+
 // LiveCmd creates the live command (record + transcribe in one step).
 // The env parameter provides injectable dependencies for testing.
 func LiveCmd(env *Env) *cobra.Command {
@@ -34,39 +50,212 @@ func LiveCmd(env *Env) *cobra.Command {
 		output            string
 		tmpl              string
 		diarize           bool
-		parallel          int
+		parallel          string
 		keepAudio         bool
 		keepRawTranscript bool
 		keepAll           bool
 		device            string
 		systemRecord      bool
 		mix               bool
+		micGain           string
+		systemGain        string
+		autoBalance       bool
+		separate          bool
+		stopOnSilence     string
+		startAt           string
+		startIn           string
 		language          string
 		translate         string
 		provider          string
+		model             string
+		stream            bool
+		tui               bool
+		sessionDir        string
+		obsidianVault     string
+		backend           string
+		outFormat         string
+		speakerLabels     string
+		timestamps        string
+		stats             string
+		unclear           string
+		actions           string
+		anchors           bool
+		vars              []string
+		varsFile          string
+		summaryLevel      string
+		tag               string
+		costReport        string
+		progressFmt       string
+		selfConsistency   int
+		maxCost           float64
+		chunker           string
+		chunkSize         string
+		withProvenance    bool
+		signKey           string
+		notifyWebhook     string
+		notify            bool
+		profile           string
+		grpcEndpoint      string
+		glossary          string
+		clean             bool
+		redact            bool
+		redactPatterns    []string
+		denoise           bool
+		normalize         bool
+		chunkTokens       int
+		overlap           int
+		reduceLevels      int
+		streamRestructure bool
+		fallback          string
+		force             bool
+		appendOutput      bool
 	)
 
 	cmd := &cobra.Command{
-		Use:   "live",
-		Short: "Record and transcribe in one command",
+		Use:     "live",
+		Aliases: []string{"l"},
+		Short:   "Record and transcribe in one command",
 		Long: `Record audio and transcribe it in a single operation.
 
 This command combines 'record' and 'transcribe' for convenience.
 The audio is recorded to a temporary file, transcribed, and optionally
 restructured using a template. Use --keep-audio to preserve the recording.
 
-Transcription always uses OpenAI. Restructuring (--template) uses DeepSeek by default,
-or OpenAI with --provider openai.
+Transcription uses OpenAI by default, or whisper.cpp offline with --transcriber local
+(see 'transcript transcribe --help'). Restructuring (--template) uses DeepSeek by
+default, or OpenAI with --provider openai, or a local Ollama server with
+--provider ollama. --fallback-provider restructures again with another provider
+when --provider fails with an exhausted quota or a rejected key, without
+recording or transcribing again. --var and --vars-file fill the placeholders of
+a user template (see 'transcript transcribe --help'), checked before recording.
+--summary-level short or detailed shortens or details the notes of any template.
+
+With --start-at 14:00 or --start-in 10m, recording starts later, e.g. at the
+start of a scheduled meeting; a countdown is shown until then, and Ctrl+C
+cancels it without recording anything.
+
+With --mix, --mic-gain and --system-gain raise or lower the microphone and the
+system audio (in dB), and --auto-balance brings both to the same loudness first
+(see 'transcript record --help').
+
+With --mix --separate-tracks, the microphone and the system audio are also
+recorded to their own file, transcribed separately and merged by time: what the
+microphone heard is attributed to "Me", the rest of the call to "Remote", which
+is more accurate than --diarize on calls. With -k, the tracks are kept next to
+the audio (<output>.mic.ogg, <output>.system.ogg). It cannot be combined with
+--diarize, --stream or --session-dir.
 
 Recording can be interrupted with Ctrl+C to stop early and continue transcription.
-Press Ctrl+C twice within 2 seconds to abort entirely.`,
+Press Ctrl+C twice within 2 seconds to abort entirely. Ctrl+C during restructuring
+saves the raw transcript to <output>.raw.md (unless already kept) and exits.
+
+With --stream, audio is transcribed in 30-second segments while recording is
+still in progress. Partial segments are printed as they complete and appended
+to the output file (or to the raw transcript when using --template, which is
+then restructured once recording ends). Segments are cut at fixed intervals,
+so words at segment boundaries may be split.
+
+With --tui, streaming is shown full-screen while recording: elapsed time, level
+meter, segments transcribed and the end of the transcript. Keys: q stops the
+recording early, h marks a highlight and n adds a note (Enter adds it, Esc
+cancels it). Highlights and notes are put in the transcript after the segment
+they were added in, like "> Note at 12:40: follow up"; with --template, they are
+also listed at the end of the output. --tui needs a terminal (stty, on macOS and
+Linux) and cannot be combined with --progress json.
+
+While recording, Enter (or SIGUSR2, e.g. 'pkill -USR2 transcript') drops a
+highlight marker, rendered as "⭐ 00:42:15" after the chunk (or segment, with
+--stream) it falls in. With --template, restructuring emphasizes the marked
+moments. Subtitle formats have no markers.
+
+With --parallel auto, the first chunk is uploaded alone to measure the upload
+throughput before transcribing the rest (see 'transcript transcribe --help').
+Segments of --stream are short and uploaded as they are recorded, so --stream
+uses the default parallelism instead.
+
+With --session-dir, all artifacts of the run are written into a timestamped
+directory (live_<timestamp>/): audio.ogg, transcript.md, raw.md (with --template),
+chunks.json, session.json (options and status) and session.log. The audio is
+recorded straight into that directory, so it survives a crash.
+
+With --format srt or vtt, the output is a timestamped subtitle file (see
+'transcript transcribe --help'); it cannot be combined with --template or --stream.
+--timestamps puts time markers in the Markdown or text transcript instead, every
+5 minutes or every --timestamps=10m.
+
+With --diarize or --separate-tracks, --stats appends the statistics of each
+speaker to the output, or --stats=json writes them to <output>.stats.json (see
+'transcript transcribe --help'). It cannot be combined with --stream.
+
+--unclear lists the passages transcribed with low confidence after the
+transcript (see 'transcript transcribe --help'). It cannot be combined with
+--stream either.
+
+--template --actions also extracts the action items to <output>.tasks.json, or
+<output>.tasks.csv with --actions=csv, to import into a task manager (see
+'transcript transcribe --help').
+
+After each run, the actual usage and cost are printed; --cost-report appends
+them to a file (see 'transcript transcribe --help').
+
+With --self-consistency N, the transcript is restructured N times and the results
+are merged, within --max-cost (see 'transcript transcribe --help').
+--restructure-chunk-tokens, --restructure-overlap and --max-reduce-levels
+size and merge the parts of long transcripts (see 'transcript transcribe --help').
+
+--chunker and --chunk-size select how the recording is split into chunks (see
+'transcript transcribe --help').
+
+--denoise and --normalize clean up the recording before it is chunked (see
+'transcript transcribe --help'); the audio kept with -k is left as recorded.
+They cannot be combined with --stream.
+
+An existing output file is an error; --force replaces it, with the audio kept
+with -k and the raw transcript, and --append adds to it after a separator with
+the date and time (see 'transcript transcribe --help'). With --append, the kept
+audio and raw transcript must not exist yet.
+
+The output-name and front-matter settings apply to live outputs too, with
+"live" as {{input_stem}} (see 'transcript transcribe --help'). Streamed without
+--template, the front matter has no duration nor speakers. So does
+--obsidian-vault, the note linking the recording when it is kept (-k).
+
+--clean and --redact clean up and mask emails, phone and card numbers in the
+transcript before it is written or restructured, segment by segment with
+--stream (see 'transcript transcribe --help').
+
+--provenance appends a footer recording the version, models, date and SHA-256 of
+the recording, and --sign-key signs the output with a minisign key (see
+'transcript transcribe --help'). With --stream, they require --template.
+
+With --progress json, progress is written to stderr as JSON lines (see
+'transcript transcribe --help'); recording reports the time recorded every second.`,
 		Example: `  transcript live -d 2h -o ideas.md -t brainstorm
   transcript live -d 1h -t meeting --diarize -k       # Keep audio
   transcript live -d 1h -s -t meeting                 # System audio (video call)
   transcript live -d 1h --mix -t meeting              # Mic + system audio
+  transcript live -d 1h --mix --auto-balance -t meeting  # Mic as loud as the call
+  transcript live -d 1h --mix --separate-tracks -t meeting  # "Me" and "Remote" speakers
+  transcript live -d 1h --mix --separate-tracks --stats  # Who talked how much
+  transcript live -d 1h -t meeting --actions          # Task list next to the notes
+  transcript live -d 2h -t meeting --stop-on-silence 5m  # End when the meeting does
+  transcript live -d 1h -t meeting --start-at 14:00   # Start with the meeting at 2 PM
   transcript live -d 1h -l fr -T en -t brainstorm     # French audio, English output
-  transcript live -d 1h -t meeting -K                 # Keep audio and raw transcript`,
+  transcript live -d 1h -t meeting -K                 # Keep audio and raw transcript
+  transcript live -d 1h --stream -o notes.md          # Print partial transcript while recording
+  transcript live -d 1h --tui -t meeting              # Full-screen view, highlights and notes
+  transcript live -d 30m -t notes -o journal.md --append  # One notes file for every session
+  transcript live -d 1h -f vtt                        # Timestamped WebVTT subtitles
+  transcript live -d 1h -t meeting --tag apollo       # Prompt with names from earlier sessions
+  transcript live -d 1h -t meeting --anchors          # Notes citing their audio ranges
+  transcript live -d 3h -t lecture --session-dir ./sessions  # Keep everything in one directory`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// The profile sets the flags not given, before they are parsed.
+			profileName, err := applyProfile(cmd, env, profile)
+			if err != nil {
+				return err
+			}
+
 			// Parse duration.
 			duration, err := time.ParseDuration(durationStr)
 			if err != nil {
@@ -75,6 +264,26 @@ Press Ctrl+C twice within 2 seconds to abort entirely.`,
 			if duration <= 0 {
 				return fmt.Errorf("duration must be positive: %w", ErrInvalidDuration)
 			}
+			silence, err := parseStopOnSilence(stopOnSilence)
+			if err != nil {
+				return err
+			}
+			start, err := parseStartTime(startAt, startIn, env.Now())
+			if err != nil {
+				return err
+			}
+			balance, err := parseMixBalance(mix, micGain, systemGain, autoBalance)
+			if err != nil {
+				return err
+			}
+			if err := validateSeparateTracks(separate, mix); err != nil {
+				return err
+			}
+
+			parsedParallel, autoParallel, err := parseParallelFlag(cmd, parallel)
+			if err != nil {
+				return err
+			}
 
 			// Parse language flags at the boundary.
 			parsedLanguage, err := lang.Parse(language)
@@ -89,7 +298,7 @@ Press Ctrl+C twice within 2 seconds to abort entirely.`,
 			// Parse template at the boundary (empty string is allowed - means no restructuring).
 			var parsedTemplate template.Name
 			if tmpl != "" {
-				parsedTemplate, err = template.ParseName(tmpl)
+				parsedTemplate, err = template.Resolve(tmpl, userTemplatesDir(env))
 				if err != nil {
 					return err
 				}
@@ -104,54 +313,267 @@ Press Ctrl+C twice within 2 seconds to abort entirely.`,
 				}
 			}
 
+			// Parse transcriber at the boundary (empty string means config or OpenAI).
+			var parsedBackend Backend
+			if backend != "" {
+				parsedBackend, err = ParseBackend(backend)
+				if err != nil {
+					return err
+				}
+			}
+			if grpcEndpoint != "" {
+				if grpcEndpoint, err = config.ParseGRPCEndpoint(grpcEndpoint); err != nil {
+					return err
+				}
+			}
+
+			// Parse output format at the boundary (empty string means Markdown).
+			var parsedFormat OutputFormat
+			if outFormat != "" {
+				parsedFormat, err = ParseOutputFormat(outFormat)
+				if err != nil {
+					return err
+				}
+			}
+
+			var parsedSpeakerLabels SpeakerLabels
+			if speakerLabels != "" {
+				parsedSpeakerLabels, err = ParseSpeakerLabels(speakerLabels)
+				if err != nil {
+					return err
+				}
+			}
+
+			var parsedTimestamps Timestamps
+			if timestamps != "" {
+				parsedTimestamps, err = ParseTimestamps(timestamps)
+				if err != nil {
+					return err
+				}
+			}
+
+			parsedStats, err := parseStats(stats)
+			if err != nil {
+				return err
+			}
+			parsedUnclear, err := parseUnclear(unclear)
+			if err != nil {
+				return err
+			}
+			parsedActions, err := parseActions(actions)
+			if err != nil {
+				return err
+			}
+			parsedFallback, err := parseFallbackProvider(fallback)
+			if err != nil {
+				return err
+			}
+
+			if parsedTemplate, err = anchorTemplate(parsedTemplate, anchors); err != nil {
+				return err
+			}
+			// The values are journaled too, for recover to fill the template again.
+			templateVars, _, err := templateVarValues(vars, varsFile)
+			if err != nil {
+				return err
+			}
+			if parsedTemplate, err = fillTemplate(env.Stderr, parsedTemplate, vars, varsFile); err != nil {
+				return err
+			}
+			if parsedTemplate, err = levelTemplate(parsedTemplate, summaryLevel); err != nil {
+				return err
+			}
+			if err := validateStreamRestructure(streamRestructure, parsedTemplate, progressFmt); err != nil {
+				return err
+			}
+			if tui && progressFmt == ProgressJSON {
+				return fmt.Errorf("--tui cannot be combined with --progress %s (the screen would be mixed with the JSON lines)", ProgressJSON)
+			}
+
+			// Parse tag at the boundary (empty string means untagged).
+			var parsedTag string
+			if tag != "" {
+				parsedTag, err = vocab.ParseTag(tag)
+				if err != nil {
+					return err
+				}
+			}
+
+			parsedChunking, err := parseChunking(chunker, chunkSize)
+			if err != nil {
+				return err
+			}
+
+			parsedWebhook, err := parseNotifyWebhook(notifyWebhook)
+			if err != nil {
+				return err
+			}
+
+			// Load the signing key at the boundary, before recording.
+			stamp, err := newProvenanceStamp(env, withProvenance, signKey)
+			if err != nil {
+				return err
+			}
+
 			// Note: output path resolution (including output-dir) is done in runLive.
 			// --keep-all expands to --keep-audio + --keep-raw-transcript
 			effectiveKeepAudio := keepAudio || keepAll
 			effectiveKeepRaw := keepRawTranscript || keepAll
 
-			return runLive(cmd.Context(), env, liveOptions{
+			opts := liveOptions{
 				duration:          duration,
 				output:            output,
 				template:          parsedTemplate,
+				templateVars:      templateVars,
 				diarize:           diarize,
-				parallel:          parallel,
+				parallel:          parsedParallel,
+				autoParallel:      autoParallel,
 				keepAudio:         effectiveKeepAudio,
 				keepRawTranscript: effectiveKeepRaw,
 				device:            device,
 				systemRecord:      systemRecord,
 				mix:               mix,
+				balance:           balance,
+				separateTracks:    separate,
+				stopOnSilence:     silence,
+				start:             start,
 				language:          parsedLanguage,
 				translate:         parsedTranslate,
 				provider:          parsedProvider,
+				model:             model,
+				stream:            stream || tui,
+				tui:               tui,
+				sessionDir:        sessionDir,
+				obsidianVault:     obsidianVault,
+				backend:           parsedBackend,
+				format:            parsedFormat,
+				speakerLabels:     parsedSpeakerLabels,
+				timestamps:        parsedTimestamps,
+				stats:             parsedStats,
+				unclear:           parsedUnclear,
+				actions:           parsedActions,
+				tag:               parsedTag,
+				costReport:        costReport,
+				selfConsistency:   selfConsistency,
+				maxCost:           maxCost,
+				chunking:          parsedChunking,
+				provenance:        stamp,
+				notifyWebhook:     parsedWebhook,
+				notify:            notify,
+				profile:           profileName,
+				grpcEndpoint:      grpcEndpoint,
+				glossary:          config.ExpandPath(glossary),
+				clean:             clean,
+				redact:            redact,
+				redactPatterns:    redactPatterns,
+				preprocessing:     audio.Preprocessing{Denoise: denoise, Normalize: normalize},
+				chunkTokens:       chunkTokens,
+				overlap:           overlap,
+				reduceLevels:      reduceLevels,
+				streamRestructure: streamRestructure,
+				fallback:          parsedFallback,
+				outputMode:        newOutputMode(force, appendOutput),
+			}
+			// The TUI reads the keys itself: h marks a highlight
+			if !tui {
+				opts.highlightInput = highlightInput(cmd)
+			}
+			return runWithProgress(cmd, env, progressFmt, progress.PhaseRecording, func(env *Env) error {
+				return runLive(cmd.Context(), env, opts)
 			})
 		},
 	}
 
 	// Recording flags.
 	cmd.Flags().StringVarP(&durationStr, "duration", "d", "", "Recording duration (e.g., 2h, 30m, 1h30m)")
-	cmd.Flags().StringVar(&device, "device", "", "Audio input device (default: system default)")
+	cmd.Flags().StringVar(&device, "device", "", "Audio input device, or part of its name (default: default-device config, or system default)")
 	cmd.Flags().BoolVarP(&systemRecord, "system-record", "s", false, "Capture system audio instead of microphone")
 	cmd.Flags().BoolVar(&mix, "mix", false, "Capture both microphone and system audio")
+	cmd.Flags().StringVar(&micGain, "mic-gain", "", micGainFlagHelp)
+	cmd.Flags().StringVar(&systemGain, "system-gain", "", systemGainFlagHelp)
+	cmd.Flags().BoolVar(&autoBalance, "auto-balance", false, autoBalanceFlagHelp)
+	cmd.Flags().BoolVar(&separate, "separate-tracks", false, liveSeparateTracksFlagHelp)
+	cmd.Flags().StringVar(&stopOnSilence, "stop-on-silence", "", "Stop recording after this much silence (e.g., 5m)")
+	cmd.Flags().StringVar(&startAt, "start-at", "", startAtFlagHelp)
+	cmd.Flags().StringVar(&startIn, "start-in", "", startInFlagHelp)
 
 	// Transcription flags.
 	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path (default: transcript_<timestamp>.md)")
-	cmd.Flags().StringVarP(&tmpl, "template", "t", "", "Restructure template: brainstorm, meeting, lecture, notes")
+	cmd.Flags().StringVarP(&tmpl, "template", "t", "", "Restructure template: brainstorm, meeting, lecture, notes, podcast, chapters, digest, or a user template")
 	cmd.Flags().BoolVar(&diarize, "diarize", false, "Enable speaker identification")
-	cmd.Flags().IntVarP(&parallel, "parallel", "p", transcribe.MaxRecommendedParallel, "Max concurrent API requests (1-10)")
+	cmd.Flags().StringVarP(&parallel, "parallel", "p", defaultParallel, "Max concurrent API requests (1-10, or auto; default: sized from the chunks and rate limits)")
 	cmd.Flags().StringVarP(&language, "language", "l", "", "Audio language (ISO 639-1 code, e.g., en, fr, pt-BR)")
 	cmd.Flags().StringVarP(&translate, "translate", "T", "", "Translate output to language (ISO 639-1 code, requires --template)")
-	cmd.Flags().StringVar(&provider, "provider", ProviderDeepSeek, "LLM provider for restructuring: deepseek, openai")
+	cmd.Flags().StringVar(&provider, "provider", ProviderDeepSeek, "LLM provider for restructuring: deepseek, openai, anthropic, ollama")
+	cmd.Flags().StringVar(&model, "restructure-model", "", "Model of the restructure provider (default: config or provider default)")
+	cmd.Flags().StringVar(&fallback, "fallback-provider", "", fallbackProviderFlagHelp)
+	cmd.Flags().StringVar(&backend, "transcriber", "", transcriberFlagHelp)
+	cmd.Flags().StringVar(&backend, "stt-provider", "", sttProviderFlagHelp)
+	cmd.Flags().StringVar(&grpcEndpoint, "grpc-endpoint", "", grpcEndpointFlagHelp)
+	cmd.Flags().StringVar(&grpcEndpoint, "stt-endpoint", "", sttEndpointFlagHelp)
+	cmd.Flags().StringVarP(&outFormat, "format", "f", FormatMarkdown, "Output format: md, txt, srt, vtt (srt and vtt are timestamped subtitles)")
+	cmd.Flags().StringVar(&speakerLabels, "speaker-labels", "", speakerLabelsFlagHelp)
+	cmd.Flags().StringVar(&timestamps, "timestamps", "", timestampsFlagHelp)
+	cmd.Flags().Lookup("timestamps").NoOptDefVal = defaultTimestamps
+	cmd.Flags().StringVar(&stats, "stats", "", statsFlagHelp)
+	cmd.Flags().Lookup("stats").NoOptDefVal = StatsMarkdown
+	cmd.Flags().StringVar(&unclear, "unclear", "", unclearFlagHelp)
+	cmd.Flags().Lookup("unclear").NoOptDefVal = defaultUnclear
+	cmd.Flags().StringVar(&actions, "actions", "", actionsFlagHelp)
+	cmd.Flags().Lookup("actions").NoOptDefVal = ActionsJSON
+	cmd.Flags().BoolVar(&anchors, "anchors", false, anchorsFlagHelp)
+	cmd.Flags().StringArrayVar(&vars, "var", nil, varFlagHelp)
+	cmd.Flags().StringVar(&varsFile, "vars-file", "", varsFileFlagHelp)
+	cmd.Flags().StringVar(&summaryLevel, "summary-level", "", summaryLevelFlagHelp)
+	cmd.Flags().StringVar(&tag, "tag", "", "Tag the session and prompt with names from previous sessions with the same tag (default: tag of .transcript.toml)")
+	cmd.Flags().StringVar(&glossary, "glossary", "", glossaryFlagHelp)
+	cmd.Flags().BoolVar(&clean, "clean", false, cleanFlagHelp)
+	cmd.Flags().BoolVar(&redact, "redact", false, redactFlagHelp)
+	cmd.Flags().StringArrayVar(&redactPatterns, "redact-pattern", nil, redactPatternFlagHelp)
+	cmd.Flags().BoolVar(&denoise, "denoise", false, denoiseFlagHelp)
+	cmd.Flags().BoolVar(&normalize, "normalize", false, normalizeFlagHelp)
+	cmd.Flags().IntVar(&selfConsistency, "self-consistency", 0, "Restructure N times and merge the results (2-5, requires --template)")
+	cmd.Flags().Float64Var(&maxCost, "max-cost", defaultMaxCost, "Max estimated restructuring cost of --self-consistency, in US dollars")
+	cmd.Flags().IntVar(&chunkTokens, "restructure-chunk-tokens", 0, chunkTokensFlagHelp)
+	cmd.Flags().IntVar(&overlap, "restructure-overlap", 0, overlapFlagHelp)
+	cmd.Flags().IntVar(&reduceLevels, "max-reduce-levels", 0, reduceLevelsFlagHelp)
+	cmd.Flags().BoolVar(&streamRestructure, "stream-restructure", false, streamRestructureFlagHelp)
+	cmd.Flags().BoolVar(&force, "force", false, forceFlagHelp)
+	cmd.Flags().BoolVar(&appendOutput, "append", false, appendFlagHelp)
+	cmd.Flags().StringVar(&costReport, "cost-report", "", "Append the usage and cost of the run to this file (JSON lines, or CSV with a .csv extension)")
+	cmd.Flags().StringVar(&progressFmt, "progress", ProgressText, "Progress output on stderr: text, or json for JSON lines")
+	cmd.Flags().StringVar(&chunker, "chunker", audio.StrategySilence, chunkerFlagHelp)
+	cmd.Flags().StringVar(&chunkSize, "chunk-size", "", chunkSizeFlagHelp)
+	cmd.Flags().BoolVar(&withProvenance, "provenance", false, provenanceFlagHelp)
+	cmd.Flags().StringVar(&signKey, "sign-key", "", signKeyFlagHelp)
+	cmd.Flags().StringVar(&notifyWebhook, "notify-webhook", "", notifyWebhookFlagHelp)
+	cmd.Flags().BoolVar(&notify, "notify", false, notifyFlagHelp)
+	cmd.Flags().StringVar(&profile, "profile", "", profileFlagHelp)
 
 	// Live-specific flags.
 	cmd.Flags().BoolVarP(&keepAudio, "keep-audio", "k", false, "Keep the audio file after transcription")
-	cmd.Flags().BoolVarP(&keepRawTranscript, "keep-raw-transcript", "r", false, "Keep raw transcript before restructuring (requires --template)")
+	cmd.Flags().BoolVarP(&keepRawTranscript, "keep-raw-transcript", "r", false, "Keep the raw transcript before restructuring, in <output>.raw.md (requires --template)")
 	cmd.Flags().BoolVarP(&keepAll, "keep-all", "K", false, "Keep both audio and raw transcript (equivalent to -k -r)")
+	cmd.Flags().BoolVar(&stream, "stream", false, "Transcribe while recording, printing partial results as segments complete")
+	cmd.Flags().BoolVar(&tui, "tui", false, "Full-screen view while recording, with keys to stop, mark highlights and add notes (implies --stream)")
+	cmd.Flags().StringVar(&sessionDir, "session-dir", "", "Write all artifacts into a timestamped directory here (implies -K)")
+	cmd.Flags().StringVar(&obsidianVault, "obsidian-vault", "", obsidianVaultFlagHelp)
 
 	// Duration is required.
 	_ = cmd.MarkFlagRequired("duration")
 
-	// System-record and mix are mutually exclusive.
+	// System-record and mix are mutually exclusive, and so are the start flags.
 	cmd.MarkFlagsMutuallyExclusive("system-record", "mix")
+	cmd.MarkFlagsMutuallyExclusive("start-at", "start-in")
+
+	// The session directory and the vault decide where the output goes.
+	cmd.MarkFlagsMutuallyExclusive("output", "session-dir", "obsidian-vault")
+	cmd.MarkFlagsMutuallyExclusive("force", "append")
+
+	// Separate tracks are transcribed once recorded, and labeled by track.
+	cmd.MarkFlagsMutuallyExclusive("separate-tracks", "stream", "tui")
+	cmd.MarkFlagsMutuallyExclusive("separate-tracks", "diarize")
+	cmd.MarkFlagsMutuallyExclusive("separate-tracks", "session-dir")
 
 	return cmd
 }
@@ -161,16 +583,56 @@ type liveOptions struct {
 	duration          time.Duration
 	output            string // Markdown output path
 	template          template.Name
+	templateVars      map[string]string // Values of the template placeholders (--var, --vars-file)
 	diarize           bool
 	parallel          int
+	autoParallel      bool // Size parallelism from measured upload throughput (--parallel auto)
 	keepAudio         bool
 	keepRawTranscript bool // Keep raw transcript when using --template (-r)
 	device            string
 	systemRecord      bool // Capture system audio instead of microphone (-s)
 	mix               bool
-	language          lang.Language // Audio input language
-	translate         lang.Language // Output language for restructuring (-T)
-	provider          Provider      // LLM provider for restructuring
+	balance           audio.MixBalance    // Levels of the inputs with --mix (--mic-gain, --system-gain, --auto-balance)
+	separateTracks    bool                // Record and transcribe the inputs of --mix separately (--separate-tracks)
+	stopOnSilence     time.Duration       // End the recording after this much silence (0: never)
+	start             time.Time           // Scheduled start (--start-at, --start-in); zero means now
+	language          lang.Language       // Audio input language
+	translate         lang.Language       // Output language for restructuring (-T)
+	provider          Provider            // LLM provider for restructuring
+	model             string              // Restructure model (--restructure-model); empty means configured or provider default
+	stream            bool                // Transcribe segments while recording (--stream)
+	tui               bool                // Full-screen view while recording (--tui); implies stream
+	sessionDir        string              // Write all artifacts into a session directory here (--session-dir)
+	obsidianVault     string              // Write the output into this Obsidian vault (--obsidian-vault); empty means configured
+	backend           Backend             // Transcription backend (--transcriber); resolved in runLive
+	format            OutputFormat        // Output format (--format); zero means Markdown
+	speakerLabels     SpeakerLabels       // Speakers in subtitles (--speaker-labels); zero means the format default
+	timestamps        Timestamps          // Time markers in md and txt transcripts (--timestamps); zero means none
+	stats             string              // Speaker statistics of diarized transcripts (--stats): md, json; empty means none
+	unclear           float64             // List the passages transcribed below this confidence (--unclear); 0 means none
+	actions           string              // Action items written next to the output (--actions): json, csv; empty means none
+	tag               string              // Session tag whose vocabulary biases transcription (--tag)
+	costReport        string              // Append the usage and cost of the run to this file (--cost-report)
+	selfConsistency   int                 // Restructurings merged into the output (--self-consistency); <= 1 disables it
+	maxCost           float64             // Max estimated cost of self-consistency, in US dollars (--max-cost)
+	chunking          chunking            // Chunking strategy and chunk size (--chunker, --chunk-size)
+	provenance        *provenanceStamp    // Provenance footer and signature (--provenance, --sign-key); nil disables them
+	notifyWebhook     string              // POST a notification here when the run finishes (--notify-webhook); empty means configured
+	notify            bool                // Show a desktop notification when the run finishes (--notify)
+	profile           string              // Config profile whose output-dir applies (--profile, TRANSCRIPT_PROFILE)
+	grpcEndpoint      string              // ASR server of the grpc transcriber (--grpc-endpoint); empty means configured
+	glossary          string              // File of terms put in the prompts (--glossary); empty means configured
+	clean             bool                // Remove fillers and repeated words, normalize punctuation (--clean)
+	redact            bool                // Mask emails, phone and card numbers (--redact)
+	redactPatterns    []string            // Other text masked (--redact-pattern); implies redact
+	preprocessing     audio.Preprocessing // Audio cleanup before chunking (--denoise, --normalize)
+	chunkTokens       int                 // Size of the parts of long transcripts (--restructure-chunk-tokens); 0 means configured
+	overlap           int                 // Tokens repeated between parts (--restructure-overlap); 0 means configured
+	reduceLevels      int                 // Max levels merging the parts (--max-reduce-levels); 0 means the default
+	streamRestructure bool                // Write the restructured output as it is generated (--stream-restructure)
+	fallback          Provider            // Restructure with this provider if --provider fails (--fallback-provider); zero means none
+	outputMode        outputMode          // Replace (--force) or append to (--append) an existing output file
+	highlightInput    io.Reader           // Lines dropping highlight markers while recording (Enter); nil means SIGUSR2 only
 }
 
 // audioOutputPath derives the audio file path from the markdown output path.
@@ -180,13 +642,6 @@ func audioOutputPath(mdPath string) string {
 	return strings.TrimSuffix(mdPath, ext) + ".ogg"
 }
 
-// rawTranscriptPath derives the raw transcript path from the final output path.
-// Example: "notes.md" -> "notes_raw.md"
-func rawTranscriptPath(mdPath string) string {
-	ext := filepath.Ext(mdPath)
-	return strings.TrimSuffix(mdPath, ext) + "_raw" + ext
-}
-
 // defaultLiveFilename generates a default output filename with timestamp.
 // Format: transcript_20260125_143052.md
 func defaultLiveFilename(now func() time.Time) string {
@@ -196,37 +651,103 @@ func defaultLiveFilename(now func() time.Time) string {
 // liveContext holds validated context for live command execution.
 // This is separate from cli.Env to hold command-specific resolved values.
 type liveContext struct {
-	openaiKey           string   // OpenAI API key (always needed for transcription)
-	restructureAPIKey   string   // API key for restructuring (depends on provider)
-	restructureProvider Provider // LLM provider for restructuring
+	transcriber         transcribe.Transcriber // Local transcriber, created up front (nil for OpenAI)
+	restructureAPIKey   string                 // API key for restructuring (depends on provider)
+	restructureProvider Provider               // LLM provider for restructuring
 	ffmpegPath          string
 	audioPath           string // Final audio path (if --keep-audio / -k)
 	rawTranscriptPath   string // Path for raw transcript (if --keep-raw-transcript / -r)
 	parallel            int
+	promptTokenWarning  int                     // From config (zero = default)
+	ollama              OllamaConfig            // From config (--provider ollama)
+	azure               AzureConfig             // From config (empty endpoint = OpenAI)
+	restructureModel    string                  // --restructure-model, or from config
+	contextWindows      map[string]int          // From config (nil = built-in table)
+	restructureSplit    string                  // From config (empty = paragraphs)
+	chunkTokens         int                     // --restructure-chunk-tokens, or from config (zero = from the model)
+	overlap             int                     // --restructure-overlap, or from config (zero = none)
+	rateLimiter         *transcribe.RateLimiter // Shared by the parallel chunks
+	rateLimitRequests   int                     // From config (zero = unlimited), sizes the default parallelism
+	rateLimitAudio      int                     // From config (zero = unlimited), sizes the default parallelism
+	session             *session                // Session directory (nil without --session-dir)
+	vocabulary          *tagVocabulary          // Vocabulary of --tag (nil without a tag)
+	cleaner             *cleanup.Cleaner        // Cleanup of --clean (nil without it)
+	redactor            *redact.Redactor        // Redaction of --redact (nil without it)
+	report              *runReport              // Actual usage of the run
+	streamed            *streamedOutput         // Output written as it is restructured (nil without --stream-restructure)
+	frontMatter         bool                    // From config: front matter on Markdown outputs
+	vault               *obsidian.Vault         // Vault the output is a note of (nil without --obsidian-vault)
+	started             time.Time               // Start of the recording, for front matter
+	recorded            time.Duration           // Length of the recording, for front matter
+	highlights          []time.Duration         // Highlight markers dropped while recording
+	stats               *speakerstats.Stats     // Speaker statistics of the transcript (nil without --stats)
+	unclear             string                  // Section of the unclear passages (empty without --unclear)
+	actions             *actionsRun             // Action items of the transcript (nil without --actions)
+}
+
+// outputLanguage returns the language of a live output: --translate once
+// restructured, else the audio language.
+func (opts liveOptions) outputLanguage() lang.Language {
+	if !opts.translate.IsZero() {
+		return opts.translate
+	}
+	return opts.language
+}
+
+// noteFrontMatter returns the front matter of the live output (the
+// front-matter setting). transcript gives the speakers with --diarize, and
+// audioPath the recording, named only when kept.
+func (lctx *liveContext) noteFrontMatter(opts liveOptions, audioPath, transcript string) notes.FrontMatter {
+	m := notes.FrontMatter{
+		Date:     lctx.started,
+		Duration: lctx.recorded,
+		Language: opts.outputLanguage().String(),
+		Template: opts.template.String(),
+	}
+	if opts.keepAudio && audioPath == lctx.audioPath {
+		m.Audio = absPath(audioPath)
+	}
+	if opts.diarize {
+		m.Speakers = notes.Speakers(transcript)
+	}
+	return m
+}
+
+// note returns content, written to output with mode, as the live output note:
+// with its front matter (front-matter setting), and with --obsidian-vault,
+// linked to the daily note and the kept recording (see noteFrontMatter).
+func (lctx *liveContext) note(opts liveOptions, content, output string, mode outputMode, audioPath, transcript string) string {
+	m := lctx.noteFrontMatter(opts, audioPath, transcript)
+	content = obsidianNote(lctx.vault, content, output, mode, lctx.started, m.Audio, &m)
+	return addFrontMatter(lctx.frontMatter || lctx.vault != nil, content, output, mode, opts.format, m)
 }
 
 // validateLiveContext performs fail-fast validation before any I/O.
-func validateLiveContext(ctx context.Context, env *Env, opts liveOptions) (*liveContext, error) {
+func validateLiveContext(ctx context.Context, env *Env, opts liveOptions, cfg config.Config) (*liveContext, error) {
 	// 1. Provider defaulting (validation done at parse time in RunE)
 	provider := opts.provider.OrDefault()
 
-	// 2. OpenAI API key present (always needed for transcription)
-	openaiKey := env.Getenv(EnvOpenAIAPIKey)
-	if openaiKey == "" {
-		return nil, fmt.Errorf("%w (set it with: export %s=sk-...)", ErrAPIKeyMissing, EnvOpenAIAPIKey)
+	// 2. Transcription requirements (API key, or local model), and chunks
+	// the API accepts
+	if err := validateBackend(env, opts.backend, cfg, opts.diarize); err != nil {
+		return nil, err
+	}
+	if err := opts.chunking.validateFor(opts.backend); err != nil {
+		return nil, err
 	}
 
 	// 3. Restructuring API key (only if template specified)
-	var restructureAPIKey string
+	var apiKey string
 	if !opts.template.IsZero() {
-		switch {
-		case provider.IsDeepSeek():
-			restructureAPIKey = env.Getenv(EnvDeepSeekAPIKey)
-			if restructureAPIKey == "" {
-				return nil, fmt.Errorf("%w (set it with: export %s=sk-...)", ErrDeepSeekKeyMissing, EnvDeepSeekAPIKey)
-			}
-		case provider.IsOpenAI():
-			restructureAPIKey = openaiKey // Reuse OpenAI key
+		var err error
+		if apiKey, err = restructureAPIKey(env, provider); err != nil {
+			return nil, err
+		}
+		if err = validateAzureChat(provider, azureConfig(cfg)); err != nil {
+			return nil, err
+		}
+		if err = validateFallbackKey(env, opts.fallback, azureConfig(cfg)); err != nil {
+			return nil, err
 		}
 	}
 
@@ -237,7 +758,7 @@ func validateLiveContext(ctx context.Context, env *Env, opts liveOptions) (*live
 	}
 	env.FFmpegResolver.CheckVersion(ctx, ffmpegPath)
 
-	// 5. Template validation: already done at parse time (template.ParseName in RunE)
+	// 5. Template validation: already done at parse time (template.Resolve in RunE)
 
 	// 6. Language validation: already done at parse time (lang.Parse in RunE)
 
@@ -246,50 +767,144 @@ func validateLiveContext(ctx context.Context, env *Env, opts liveOptions) (*live
 		return nil, fmt.Errorf("--translate requires --template (raw transcripts use the audio's language)")
 	}
 
-	// 8. Keep raw transcript requires template
+	// 8. Subtitles are rendered from the raw transcript once every chunk is transcribed
+	if opts.format.IsSubtitle() && !opts.template.IsZero() {
+		return nil, fmt.Errorf("--format %s cannot be combined with --template (subtitles use the raw transcript)", opts.format)
+	}
+	if opts.format.IsSubtitle() && opts.stream {
+		return nil, fmt.Errorf("--format %s cannot be combined with --stream", opts.format)
+	}
+	if err := validateSpeakerLabels(opts.speakerLabels, opts.format); err != nil {
+		return nil, err
+	}
+	if err := validateTimestamps(opts.timestamps, opts.format, opts.template); err != nil {
+		return nil, err
+	}
+	if !opts.timestamps.IsZero() && opts.stream {
+		return nil, fmt.Errorf("--timestamps cannot be combined with --stream (segments are written as they are transcribed)")
+	}
+	if opts.template.Timed() && opts.stream {
+		return nil, fmt.Errorf("--template %s cannot be combined with --stream (it needs segment timestamps)", opts.template)
+	}
+	if !opts.preprocessing.IsZero() && opts.stream {
+		return nil, fmt.Errorf("--denoise and --normalize cannot be combined with --stream (segments are transcribed as they are recorded)")
+	}
+	if err := validateStats(opts.stats, opts.diarize || opts.separateTracks, opts.format, false); err != nil {
+		return nil, err
+	}
+	if opts.stats != "" && opts.stream {
+		return nil, fmt.Errorf("--stats cannot be combined with --stream (segments are written as they are transcribed)")
+	}
+	if err := validateActions(opts.actions, opts.template, false); err != nil {
+		return nil, err
+	}
+	if err := validateUnclear(opts.unclear, opts.format); err != nil {
+		return nil, err
+	}
+	if opts.unclear > 0 && opts.stream {
+		return nil, fmt.Errorf("--unclear cannot be combined with --stream (segments are written as they are transcribed)")
+	}
+
+	// 9. Self-consistency merges several restructurings
+	if err := validateSelfConsistency(opts.selfConsistency, opts.template); err != nil {
+		return nil, err
+	}
+	if err := validateFallbackProvider(opts.fallback, opts.provider, opts.template); err != nil {
+		return nil, err
+	}
+	if err := validateChunking(opts.chunkTokens, opts.overlap, opts.reduceLevels); err != nil {
+		return nil, err
+	}
+
+	// 10. Provenance footers are appended to the final output, which --stream
+	// writes while recording unless it is restructured
+	if err := validateProvenanceFormat(opts.provenance, opts.format); err != nil {
+		return nil, err
+	}
+	if opts.provenance != nil && opts.stream && opts.template.IsZero() {
+		return nil, fmt.Errorf("--provenance with --stream requires --template (the raw transcript is written while recording)")
+	}
+
+	// 11. Keep raw transcript requires template
 	if opts.keepRawTranscript && opts.template.IsZero() {
 		return nil, fmt.Errorf("--keep-raw-transcript requires --template (without template, output is already the raw transcript)")
 	}
 
-	// 9. Output file doesn't exist
-	if _, err := os.Stat(opts.output); err == nil {
+	// 12. Output file doesn't exist, unless replaced or appended to
+	if err := validateOutputMode(opts.outputMode, opts.format, opts.provenance.signed()); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(opts.output); err == nil && opts.outputMode == outputCreate {
 		return nil, fmt.Errorf("output file already exists: %s: %w", opts.output, ErrOutputExists)
 	}
 
-	// 10. Audio output path doesn't exist (if --keep-audio)
+	// 13. Audio output path doesn't exist (if --keep-audio), unless replaced
 	audioPath := audioOutputPath(opts.output)
-	if opts.keepAudio {
-		if _, err := os.Stat(audioPath); err == nil {
-			return nil, fmt.Errorf("audio file already exists: %s: %w", audioPath, ErrOutputExists)
+	if opts.keepAudio && opts.outputMode != outputForce {
+		kept := []string{audioPath}
+		if opts.separateTracks {
+			mic, system := trackPaths(audioPath)
+			kept = append(kept, mic, system)
+		}
+		for _, path := range kept {
+			if _, err := os.Stat(path); err == nil {
+				return nil, fmt.Errorf("audio file already exists: %s: %w", path, ErrOutputExists)
+			}
 		}
 	}
 
-	// 11. Raw transcript path doesn't exist (if --keep-raw-transcript)
+	// 14. Raw transcript path doesn't exist (if --keep-raw-transcript, or streamed
+	// before restructuring), unless replaced
 	rawPath := rawTranscriptPath(opts.output)
-	if opts.keepRawTranscript {
+	if (opts.keepRawTranscript || (opts.stream && !opts.template.IsZero())) && opts.outputMode != outputForce {
 		if _, err := os.Stat(rawPath); err == nil {
 			return nil, fmt.Errorf("raw transcript file already exists: %s: %w", rawPath, ErrOutputExists)
 		}
 	}
 
-	// 12. System audio device available (if needed)
+	// 15. System audio device available (if needed)
 	if opts.systemRecord || opts.mix {
 		if _, err := audio.DetectLoopbackDevice(ctx, ffmpegPath); err != nil {
 			return nil, err
 		}
 	}
 
+	// 16. Local or gRPC transcriber: model and whisper.cpp present, or endpoint
+	// valid, before recording
+	var transcriber transcribe.Transcriber
+	parallel := opts.parallel // Zero: chosen once the chunks are known
+	if !opts.backend.IsOpenAI() {
+		transcriber, err = newTranscriber(env, opts.backend, cfg, ffmpegPath)
+		if err != nil {
+			return nil, err
+		}
+		if opts.backend.IsLocal() && cfg.WhisperURL == "" {
+			parallel = 1 // whisper.cpp already uses every CPU core for one chunk
+		}
+	}
+
 	return &liveContext{
-		openaiKey:           openaiKey,
-		restructureAPIKey:   restructureAPIKey,
+		transcriber:         transcriber,
+		restructureAPIKey:   apiKey,
 		restructureProvider: provider,
 		ffmpegPath:          ffmpegPath,
 		audioPath:           audioPath,
 		rawTranscriptPath:   rawPath,
-		parallel:            clampParallel(opts.parallel),
+		parallel:            parallel,
+		actions:             newActionsRun(opts.actions),
 	}, nil
 }
 
+// liveTranscriber returns the transcriber of the run: the local transcriber
+// if one was created during validation, the OpenAI (or Azure OpenAI)
+// transcriber otherwise.
+func (lctx *liveContext) liveTranscriber(env *Env) transcribe.Transcriber {
+	if lctx.transcriber != nil {
+		return lctx.transcriber
+	}
+	return newOpenAITranscriber(env, lctx.azure)
+}
+
 // liveRecordResult holds the result of the recording phase.
 type liveRecordResult struct {
 	audioPath      string // Path to the recorded audio
@@ -299,17 +914,25 @@ type liveRecordResult struct {
 
 // liveRecordPhase executes the recording phase.
 func liveRecordPhase(ctx context.Context, env *Env, lctx *liveContext, opts liveOptions) (*liveRecordResult, error) {
-	// Create temporary file for recording
-	tempDir, err := os.MkdirTemp("", "go-transcript-live-*")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp directory: %w", err)
-	}
-	tempAudioPath := filepath.Join(tempDir, "recording.ogg")
+	var tempAudioPath string
+	var result *liveRecordResult
+	if lctx.session != nil {
+		// Record straight into the session directory, so a crash never loses the audio
+		tempAudioPath = lctx.audioPath
+		result = &liveRecordResult{audioPath: tempAudioPath}
+	} else {
+		// Create temporary file for recording
+		tempDir, err := os.MkdirTemp("", liveTempDirPattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		tempAudioPath = filepath.Join(tempDir, "recording.ogg")
 
-	result := &liveRecordResult{
-		audioPath:      tempAudioPath,
-		tempDir:        tempDir,
-		cleanupTempDir: true,
+		result = &liveRecordResult{
+			audioPath:      tempAudioPath,
+			tempDir:        tempDir,
+			cleanupTempDir: true,
+		}
 	}
 
 	// Create recorder
@@ -318,10 +941,34 @@ func liveRecordPhase(ctx context.Context, env *Env, lctx *liveContext, opts live
 		return result, err
 	}
 
+	if err := balanceMix(recorder, opts.balance); err != nil {
+		return result, err
+	}
+	if err := separateTracks(recorder, opts.separateTracks, tempAudioPath); err != nil {
+		return result, err
+	}
+	silenceStopped, err := stopOnSilence(recorder, opts.stopOnSilence)
+	if err != nil {
+		return result, err
+	}
+
+	progress.PhaseChange(ctx, progress.PhaseRecording)
 	fmt.Fprintf(env.Stderr, "Recording for %s... (press Ctrl+C to stop early)\n", format.DurationHuman(opts.duration))
 
-	// Record to temp file
+	// Record to temp file, showing the input level
+	marks := startHighlights(env, opts.highlightInput)
+	stopMeter := monitorLevels(env, recorder, true)
+	stopReport := reportRecording(ctx, env.Now, opts.duration)
+	endJournal := startRecordingJournal(env, liveRecordingJournal(lctx, opts, tempAudioPath))
 	recordErr := recorder.Record(ctx, opts.duration, tempAudioPath)
+	endJournal()
+	stopReport()
+	stopMeter()
+	marks.stop()
+	lctx.highlights = marks.times()
+	if silenceStopped() {
+		fmt.Fprintf(env.Stderr, "No sound for %s, recording stopped\n", format.DurationHuman(opts.stopOnSilence))
+	}
 
 	// Check for interrupt during recording
 	if ctx.Err() != nil {
@@ -349,8 +996,8 @@ func liveRecordPhase(ctx context.Context, env *Env, lctx *liveContext, opts live
 	fmt.Fprintf(env.Stderr, "Recording complete: %s\n", format.Size(audioSize))
 
 	// Move audio to final location if --keep-audio
-	if opts.keepAudio {
-		if err := moveFile(tempAudioPath, lctx.audioPath); err != nil {
+	if opts.keepAudio && tempAudioPath != lctx.audioPath {
+		if err := keepAudioFile(env, opts, tempAudioPath, lctx.audioPath); err != nil {
 			return result, fmt.Errorf("failed to save audio file: %w", err)
 		}
 		result.audioPath = lctx.audioPath
@@ -362,59 +1009,153 @@ func liveRecordPhase(ctx context.Context, env *Env, lctx *liveContext, opts live
 
 // liveTranscribePhase executes chunking and transcription.
 func liveTranscribePhase(ctx context.Context, env *Env, lctx *liveContext, opts liveOptions, audioPath string) (string, error) {
-	fmt.Fprintln(env.Stderr, "Detecting silences...")
+	if opts.separateTracks {
+		return liveTranscribeTracks(ctx, env, lctx, opts, audioPath)
+	}
 
-	chunker, err := env.ChunkerFactory.NewSilenceChunker(lctx.ffmpegPath)
+	timestamps := timedResults(opts.format, opts.template, opts.timestamps) || opts.stats != "" || opts.unclear > 0 || opts.actions != ""
+	chunks, results, cleanup, err := liveTranscribeChunks(ctx, env, lctx, opts, audioPath, timestamps)
 	if err != nil {
 		return "", err
 	}
+	defer cleanup()
 
-	chunks, err := chunker.Chunk(ctx, audioPath)
+	if lctx.stats, err = speakerStats(opts.stats, chunks, results); err != nil {
+		return "", err
+	}
+	if lctx.unclear, err = unclearSection(env.Stderr, opts.unclear, opts.format, chunks, results); err != nil {
+		return "", err
+	}
+	// Action items are extracted from the timed transcript, to know when they were said
+	if lctx.actions != nil {
+		if lctx.actions.input, err = renderTimedTranscript(chunks, results); err != nil {
+			return "", err
+		}
+	}
+	transcript, err := renderHighlighted(renderedHighlights(opts.format, lctx.highlights), chunks, results,
+		func(chunks []audio.Chunk, results []string) (string, error) {
+			switch {
+			case opts.template.Timed():
+				return renderTimedTranscript(chunks, results)
+			case !opts.timestamps.IsZero():
+				return renderMarkedTranscript(opts.format, opts.timestamps, chunks, results)
+			case timestamps && !opts.format.IsSubtitle():
+				// Timed for --stats, --unclear or --actions only
+				untimed, err := untimedResults(results)
+				if err != nil {
+					return "", err
+				}
+				results = untimed
+			}
+			return renderTranscript(opts.format, opts.speakerLabels, chunks, results)
+		})
 	if err != nil {
 		return "", err
 	}
-	defer func() {
+	fmt.Fprintln(env.Stderr, "Transcription complete")
+	lctx.vocabulary.record(env, results, timestamps)
+	return transcript, nil
+}
+
+// liveTranscribeChunks chunks the recording at audioPath and transcribes the
+// chunks, with timestamps if set, then cleans and redacts the results. The
+// returned function removes the chunks.
+func liveTranscribeChunks(ctx context.Context, env *Env, lctx *liveContext, opts liveOptions, audioPath string, timestamps bool) ([]audio.Chunk, []string, func(), error) {
+	// The recording is preprocessed into a copy, so that the kept audio is unchanged
+	chunkedPath := audioPath
+	if !opts.preprocessing.IsZero() {
+		var (
+			cleanup func()
+			err     error
+		)
+		if chunkedPath, cleanup, err = preprocessAudio(ctx, env, lctx.ffmpegPath, audioPath, opts.preprocessing); err != nil {
+			return nil, nil, nil, err
+		}
+		defer cleanup()
+	}
+
+	progress.PhaseChange(ctx, progress.PhaseChunking)
+	fmt.Fprintln(env.Stderr, opts.chunking.message())
+
+	chunker, err := opts.chunking.newChunker(env, lctx.ffmpegPath, audio.NewRunID())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	chunks, err := chunker.Chunk(ctx, chunkedPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cleanupChunks := func() {
 		if cleanupErr := audio.CleanupChunks(chunks); cleanupErr != nil {
-			fmt.Fprintf(env.Stderr, "Warning: failed to cleanup chunks: %v\n", cleanupErr)
+			warnf(env.Stderr, warnFileNotSaved, "failed to cleanup chunks: %v", cleanupErr)
 		}
-	}()
+	}
 
 	fmt.Fprintf(env.Stderr, "Chunking audio... %d chunks\n", len(chunks))
+	lctx.recorded = audioDuration(chunks)
+	if err := lctx.session.writeChunks(chunks); err != nil {
+		warnf(env.Stderr, warnFileNotSaved, "failed to save chunks manifest: %v", err)
+	}
 
-	transcriber := env.TranscriberFactory.NewTranscriber(lctx.openaiKey)
+	run := []transcribe.RunOption{
+		transcribe.WithUsageTracker(lctx.report.transcription),
+		transcribe.WithRateLimiter(lctx.rateLimiter),
+	}
+	transcriber := lctx.liveTranscriber(env)
 	transcribeOpts := transcribe.Options{
-		Diarize:  opts.diarize,
-		Language: opts.language,
+		Diarize:    opts.diarize,
+		Prompt:     lctx.vocabulary.transcriptionPrompt(),
+		Language:   opts.language,
+		Timestamps: timestamps,
 	}
 
+	progress.PhaseChange(ctx, progress.PhaseTranscribing)
 	fmt.Fprintln(env.Stderr, "Transcribing...")
 
-	results, err := transcribe.TranscribeAll(ctx, chunks, transcriber, transcribeOpts, lctx.parallel)
+	var results []string
+	if opts.autoParallel {
+		results, err = transcribe.TranscribeRemainingAuto(ctx, chunks, transcriber, transcribeOpts, nil, nil,
+			func(n int, bps float64) {
+				printAutoParallel(env.Stderr, n, bps)
+			}, run...)
+	} else {
+		parallel := resolveParallel(env.Stderr, lctx.parallel, chunks, lctx.rateLimitRequests, lctx.rateLimitAudio)
+		results, err = transcribe.TranscribeAll(ctx, chunks, transcriber, transcribeOpts, parallel, run...)
+	}
+	if err == nil {
+		results, err = cleanResults(lctx.cleaner, results, timestamps)
+	} else if opts.keepAudio {
+		fmt.Fprintf(env.Stderr, "\nTranscription failed. Audio is available at: %s\n", audioPath)
+	}
+	if err == nil {
+		results, err = redactResults(env.Stderr, lctx.redactor, results, timestamps)
+	}
 	if err != nil {
-		if opts.keepAudio {
-			fmt.Fprintf(env.Stderr, "\nTranscription failed. Audio is available at: %s\n", audioPath)
-		}
-		return "", err
+		cleanupChunks()
+		return nil, nil, nil, err
 	}
-
-	fmt.Fprintln(env.Stderr, "Transcription complete")
-	return strings.Join(results, "\n\n"), nil
+	return chunks, results, cleanupChunks, nil
 }
 
 // liveRestructurePhase optionally restructures the transcript.
 // If opts.keepRawTranscript is true, saves the raw transcript before restructuring.
-func liveRestructurePhase(ctx context.Context, env *Env, lctx *liveContext, opts liveOptions, transcript, audioPath string) (string, error) {
+// savedPath is where the raw transcript was already written (empty if nowhere):
+// it is not written again if restructuring is interrupted.
+func liveRestructurePhase(ctx context.Context, env *Env, lctx *liveContext, opts liveOptions, transcript, audioPath, savedPath string) (string, error) {
 	if opts.template.IsZero() {
 		return transcript, nil
 	}
 
 	// Save raw transcript if requested (before restructuring, so it's available on failure)
 	if opts.keepRawTranscript {
-		if err := writeRawTranscript(env, lctx.rawTranscriptPath, transcript); err != nil {
+		if err := writeRawTranscript(env, opts.outputMode, lctx.rawTranscriptPath, transcript); err != nil {
 			return "", err
 		}
+		savedPath = lctx.rawTranscriptPath
 	}
 
+	progress.PhaseChange(ctx, progress.PhaseRestructuring)
 	fmt.Fprintf(env.Stderr, "Restructuring with template '%s' (provider: %s)...\n", opts.template, lctx.restructureProvider)
 
 	// Default output language to input language if not specified
@@ -423,88 +1164,233 @@ func liveRestructurePhase(ctx context.Context, env *Env, lctx *liveContext, opts
 		effectiveOutputLang = opts.language
 	}
 
+	if opts.streamRestructure {
+		var err error
+		if lctx.streamed, err = openStreamedOutput(env, opts.output, opts.outputMode, opts.format); err != nil {
+			return "", err
+		}
+	}
 	result, err := restructureContent(ctx, env, transcript, RestructureOptions{
-		Template:   opts.template,
-		Provider:   lctx.restructureProvider,
-		OutputLang: effectiveOutputLang,
-		OnProgress: defaultProgressCallback(env.Stderr),
+		Template:           opts.template,
+		Provider:           lctx.restructureProvider,
+		OutputLang:         effectiveOutputLang,
+		OnProgress:         defaultProgressCallback(env.Stderr),
+		PromptTokenWarning: lctx.promptTokenWarning,
+		Ollama:             lctx.ollama,
+		Azure:              lctx.azure,
+		Model:              lctx.restructureModel,
+		ContextWindows:     lctx.contextWindows,
+		Split:              lctx.restructureSplit,
+		ChunkTokens:        lctx.chunkTokens,
+		Overlap:            lctx.overlap,
+		ReduceLevels:       opts.reduceLevels,
+		SelfConsistency:    opts.selfConsistency,
+		MaxCost:            opts.maxCost,
+		Glossary:           lctx.vocabulary.glossaryTerms(),
+		Highlights:         lctx.highlights,
+		Stream:             lctx.streamed.writer(),
+		FallbackProvider:   opts.fallback,
+		report:             lctx.report,
+		actions:            lctx.actions,
 	})
 	if err != nil {
+		lctx.streamed.abort()
 		if opts.keepAudio {
 			fmt.Fprintf(env.Stderr, "\nRestructuring failed. Audio is available at: %s\n", audioPath)
 		}
-		if opts.keepRawTranscript {
-			fmt.Fprintf(env.Stderr, "Raw transcript is available at: %s\n", lctx.rawTranscriptPath)
+		if savedPath != "" {
+			fmt.Fprintf(env.Stderr, "Raw transcript is available at: %s\n", savedPath)
 		}
-		return "", err
+		return "", restructureFailed(ctx, env, err, transcript, opts.output, savedPath, opts.template)
 	}
 
 	return result, nil
 }
 
-// writeRawTranscript saves the raw transcript to a file.
-func writeRawTranscript(env *Env, path, content string) error {
-	// #nosec G302 G304 -- user-specified output file with standard permissions
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
-	if err != nil {
-		if errors.Is(err, os.ErrExist) {
-			return fmt.Errorf("raw transcript file already exists: %s: %w", path, ErrOutputExists)
-		}
-		return fmt.Errorf("cannot create raw transcript file: %w", err)
-	}
-
-	writeErr := func() error {
-		defer func() { _ = f.Close() }()
-		if _, err := f.WriteString(content); err != nil {
-			return fmt.Errorf("failed to write raw transcript: %w", err)
-		}
-		return nil
-	}()
-
-	if writeErr != nil {
-		_ = os.Remove(path)
-		return writeErr
+// liveWritePhase writes the final output atomically, or replaces or appends
+// to an existing one with mode (see writeOutput).
+func liveWritePhase(env *Env, output, content string, mode outputMode, f OutputFormat) error {
+	if err := writeOutput(env, output, content, mode, f); err != nil {
+		return err
 	}
-
-	fmt.Fprintf(env.Stderr, "Raw transcript saved: %s\n", path)
+	fmt.Fprintf(env.Stderr, "Done: %s\n", output)
 	return nil
 }
 
-// liveWritePhase writes the final output atomically.
-func liveWritePhase(env *Env, output, content string) error {
-	if err := writeFileAtomic(output, content); err != nil {
+// liveWriteStamped writes the final output with its front matter, vault
+// links and provenance footer, and signs it (front-matter setting,
+// --obsidian-vault, --provenance, --sign-key). audioPath is the recording, and transcript the transcript
+// restructured into content.
+// With --stream-restructure, it replaces the streamed output.
+// The speaker statistics of --stats are appended to content, or saved next to
+// it, then the unclear passages of --unclear.
+func liveWriteStamped(env *Env, lctx *liveContext, opts liveOptions, audioPath, transcript, content string) error {
+	content = appendStats(content, opts.stats, lctx.stats)
+	content = appendSection(content, lctx.unclear)
+	content = lctx.note(opts, content, opts.output, opts.outputMode, audioPath, transcript)
+	content, err := opts.provenance.apply(env, content, opts.output, audioPath, opts.format, lctx.report, opts.template)
+	if err != nil {
+		lctx.streamed.abort()
 		return err
 	}
-	fmt.Fprintf(env.Stderr, "Done: %s\n", output)
+	if lctx.streamed != nil {
+		if err := lctx.streamed.finish(content); err != nil {
+			return err
+		}
+		fmt.Fprintf(env.Stderr, "Done: %s\n", opts.output)
+	} else if err := liveWritePhase(env, opts.output, content, opts.outputMode, opts.format); err != nil {
+		return err
+	}
+	if err := opts.provenance.sign(env, opts.output, content); err != nil {
+		return err
+	}
+	saveStats(env, opts.stats, opts.output, lctx.stats)
+	var recording string // Source of the action items, if kept
+	if opts.keepAudio {
+		recording = absPath(audioPath)
+	}
+	saveActions(env, opts.actions, opts.output, recording, lctx.actions)
 	return nil
 }
 
 // runLive executes the live recording and transcription pipeline.
 // Supports graceful interrupt: first Ctrl+C stops recording and continues transcription,
 // second Ctrl+C within 2s aborts entirely.
-func runLive(parentCtx context.Context, env *Env, opts liveOptions) error {
+func runLive(parentCtx context.Context, env *Env, opts liveOptions) (err error) {
+	started := env.Now()
+
 	// Load config for output-dir.
 	cfg, err := env.ConfigLoader.Load()
 	if err != nil {
-		fmt.Fprintf(env.Stderr, "Warning: failed to load config: %v\n", err)
+		warnf(env.Stderr, warnConfigLoad, "failed to load config: %v", err)
+	}
+	if cfg, err = cfg.WithProfile(opts.profile); err != nil {
+		return err
+	}
+	if opts.grpcEndpoint != "" {
+		cfg.GRPCEndpoint = opts.grpcEndpoint
+	}
+	if opts.glossary != "" {
+		cfg.Glossary = opts.glossary
+	}
+	applyChunking(&cfg, opts.chunkTokens, opts.overlap)
+
+	// The Obsidian vault, when given, is where the default output goes.
+	vault, err := openObsidianVault(obsidianVaultRoot(opts.obsidianVault, opts.output != "" || opts.sessionDir != "", cfg), cfg, opts.format)
+	if err != nil {
+		return err
+	}
+	if vault != nil && opts.output == "" {
+		cfg.OutputDir = vault.Dir()
 	}
 
 	// Resolve output path using config output-dir.
-	// EnsureExtension adds .md only when path has no extension.
-	// Paths with non-.md extensions are preserved and trigger a warning below.
-	opts.output = config.ResolveOutputPath(opts.output, cfg.OutputDir, defaultLiveFilename(env.Now))
-	opts.output = config.EnsureExtension(opts.output, ".md")
-	warnNonMarkdownExtension(env.Stderr, opts.output)
+	// The default is named by the output-name pattern when set, "live" being
+	// the input stem.
+	// EnsureExtension adds the format's extension (.md by default) only when
+	// path has no extension. Other extensions are preserved and trigger a warning below.
+	outputName, err := outputNamer(cfg, notes.NameFields{
+		Date:     started,
+		Template: opts.template.String(),
+		Language: opts.outputLanguage().String(),
+	}, opts.format.Extension(), func(string) string {
+		return deriveOutputPath(defaultLiveFilename(env.Now), opts.format)
+	})
+	if err != nil {
+		return err
+	}
+	opts.output = config.ResolveOutputPath(opts.output, cfg.OutputDir, outputName("live"))
+	opts.output = config.EnsureExtension(opts.output, opts.format.Extension())
+	warnExtensionMismatch(env.Stderr, opts.output, opts.format.OrDefault())
 
 	// Set up interrupt handler for double Ctrl+C detection.
 	interruptHandler, ctx := interrupt.NewHandler(parentCtx)
 	defer interruptHandler.Stop()
 
+	// Resolve the transcription backend (flag, then config, then OpenAI)
+	opts.backend, err = resolveBackend(opts.backend, cfg)
+	if err != nil {
+		return err
+	}
+	// Resolve the session tag (flag, then project file)
+	opts.tag, err = resolveTag(opts.tag, cfg)
+	if err != nil {
+		return err
+	}
+	glossary, err := loadGlossary(env, cfg)
+	if err != nil {
+		return err
+	}
+
 	// Validate environment (fail-fast)
-	lctx, err := validateLiveContext(ctx, env, opts)
+	lctx, err := validateLiveContext(ctx, env, opts, cfg)
 	if err != nil {
 		return err
 	}
+	lctx.promptTokenWarning = cfg.PromptTokenWarning
+	lctx.ollama = ollamaConfig(cfg)
+	lctx.azure = azureConfig(cfg)
+	lctx.restructureModel = restructureModel(opts.model, cfg)
+	lctx.contextWindows = cfg.ContextWindows
+	lctx.restructureSplit = cfg.RestructureSplit
+	lctx.chunkTokens = cfg.RestructureChunkTokens
+	lctx.overlap = cfg.RestructureOverlap
+	lctx.rateLimiter = newRateLimiter(cfg)
+	lctx.rateLimitRequests = cfg.RateLimitRequests
+	lctx.rateLimitAudio = cfg.RateLimitAudio
+	lctx.vocabulary = loadTagVocabulary(env, cfg, opts.tag, glossary)
+	lctx.cleaner = newCleaner(opts.clean, opts.language, cfg)
+	lctx.frontMatter = cfg.FrontMatter
+	lctx.vault = vault
+	if lctx.redactor, err = newRedactor(opts.redact, opts.redactPatterns, opts.language, cfg); err != nil {
+		return err
+	}
+
+	// Wait for the scheduled start, once everything is validated: nothing is
+	// created if it is canceled.
+	if err := waitForStart(ctx, env, opts.start); err != nil {
+		return err
+	}
+	started = env.Now()
+	lctx.started = started
+
+	// Session directory: keep every artifact there, and log progress there too
+	if opts.sessionDir != "" {
+		stderr := env.Stderr
+		lctx.session, env, opts, err = startLiveSession(env, lctx, opts)
+		if err != nil {
+			return err
+		}
+		defer func() { lctx.session.finish(stderr, err) }()
+	}
+
+	// Printed once the output is written, including after an interrupted recording
+	lctx.report = newTranscribeReport("live", "", opts.output, opts.backend, transcribe.Options{
+		Diarize:    opts.diarize,
+		Timestamps: timedResults(opts.format, opts.template, opts.timestamps),
+	})
+	defer func() {
+		if err == nil {
+			finishRunReport(env, lctx.report, opts.costReport)
+		}
+	}()
+	if webhook := notifyWebhookURL(opts.notifyWebhook, cfg); webhook != "" {
+		defer func() {
+			notifyWebhook(ctx, env.Stderr, webhook,
+				newWebhookNotification("live", "", opts.output, started, env.Now(), lctx.report, err))
+		}()
+	}
+	if opts.notify {
+		defer func() {
+			title, message := desktopNotice("live", opts.output, env.Now().Sub(started), err)
+			notifyDesktop(ctx, env.Stderr, env.Notifier, title, message)
+		}()
+	}
+
+	// Streaming mode records and transcribes concurrently
+	if opts.stream {
+		return runLiveStream(ctx, env, interruptHandler, lctx, opts)
+	}
 
 	// Recording phase
 	recordResult, recordErr := liveRecordPhase(ctx, env, lctx, opts)
@@ -523,6 +1409,39 @@ func runLive(parentCtx context.Context, env *Env, opts liveOptions) error {
 	return runLiveTranscriptionPipeline(ctx, env, lctx, opts, recordResult.audioPath)
 }
 
+// startLiveSession creates the session directory of a live run and points the
+// output, audio and raw transcript paths into it. Audio and raw transcript are
+// always kept in a session. Returns the environment logging to the session.
+func startLiveSession(env *Env, lctx *liveContext, opts liveOptions) (*session, *Env, liveOptions, error) {
+	meta := sessionMetadata{
+		Command:   "live",
+		Template:  opts.template.String(),
+		Language:  opts.language.String(),
+		Translate: opts.translate.String(),
+		Diarize:   opts.diarize,
+		Format:    opts.format.String(),
+		Tag:       opts.tag,
+	}
+	if !opts.template.IsZero() {
+		meta.Provider = lctx.restructureProvider.String()
+	}
+	sess, err := newSession(config.ExpandPath(opts.sessionDir), "live", meta, env.Now)
+	if err != nil {
+		return nil, env, opts, err
+	}
+
+	opts.output = sess.path(sessionOutput(opts.format))
+	opts.keepAudio = true
+	opts.keepRawTranscript = !opts.template.IsZero()
+	lctx.audioPath = sess.path(sessionAudioFile)
+	lctx.rawTranscriptPath = sess.path(sessionRawFile)
+
+	sessionEnv := *env
+	sessionEnv.Stderr = sess.stderr(env.Stderr)
+	fmt.Fprintf(sessionEnv.Stderr, "Session: %s\n", sess.dir)
+	return sess, &sessionEnv, opts, nil
+}
+
 // handleRecordingInterrupt handles the case where recording was interrupted.
 // If a valid partial recording exists, it asks the user whether to continue
 // with transcription or abort. Returns the original recordErr if not a recoverable
@@ -551,8 +1470,8 @@ func handleRecordingInterrupt(env *Env, handler *interrupt.Handler, result *live
 
 	// Move audio to final location if --keep-audio
 	if opts.keepAudio {
-		if moveErr := moveFile(result.audioPath, lctx.audioPath); moveErr != nil {
-			fmt.Fprintf(env.Stderr, "Warning: failed to save audio: %v\n", moveErr)
+		if moveErr := keepAudioFile(env, opts, result.audioPath, lctx.audioPath); moveErr != nil {
+			warnf(env.Stderr, warnFileNotSaved, "failed to save audio: %v", moveErr)
 		} else {
 			result.audioPath = lctx.audioPath
 			fmt.Fprintf(env.Stderr, "Audio saved: %s\n", lctx.audioPath)
@@ -561,7 +1480,8 @@ func handleRecordingInterrupt(env *Env, handler *interrupt.Handler, result *live
 
 	// Create fresh context for transcription (original is cancelled by interrupt).
 	// We use context.Background() because the parent context is already done.
-	transcribeCtx, cancel := context.WithTimeout(context.Background(), postInterruptTimeout)
+	// The next Ctrl+C stops transcription or restructuring of the partial recording.
+	transcribeCtx, cancel := context.WithTimeout(handler.Rearm(context.Background()), postInterruptTimeout)
 	defer cancel()
 
 	return runLiveTranscriptionPipeline(transcribeCtx, env, lctx, opts, result.audioPath)
@@ -576,13 +1496,337 @@ func runLiveTranscriptionPipeline(ctx context.Context, env *Env, lctx *liveConte
 	}
 
 	// Restructure phase (optional)
-	finalOutput, err := liveRestructurePhase(ctx, env, lctx, opts, transcript, audioPath)
+	finalOutput, err := liveRestructurePhase(ctx, env, lctx, opts, transcript, audioPath, "")
 	if err != nil {
 		return err
 	}
 
 	// Write output
-	return liveWritePhase(env, opts.output, finalOutput)
+	return liveWriteStamped(env, lctx, opts, audioPath, transcript, finalOutput)
+}
+
+// streamTranscriptPath returns the file partial segments are appended to in
+// streaming mode: the output itself, or the raw transcript when restructuring.
+func streamTranscriptPath(lctx *liveContext, opts liveOptions) string {
+	if opts.template.IsZero() {
+		return opts.output
+	}
+	return lctx.rawTranscriptPath
+}
+
+// runLiveStream records and transcribes concurrently (--stream).
+// The recorder writes fixed-length segments alongside the full recording; each
+// completed segment is transcribed right away, printed, and appended to the
+// stream transcript. Once recording stops, the remaining segments are flushed
+// and the transcript is optionally restructured.
+//
+// The first Ctrl+C stops recording, while transcription of the segments
+// already recorded continues. A second Ctrl+C aborts via the interrupt handler.
+func runLiveStream(ctx context.Context, env *Env, handler *interrupt.Handler, lctx *liveContext, opts liveOptions) error {
+	tempDir, err := os.MkdirTemp("", liveTempDirPattern)
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	segmentDir := filepath.Join(tempDir, "segments")
+	if err := os.Mkdir(segmentDir, 0o750); err != nil {
+		return fmt.Errorf("failed to create segment directory: %w", err)
+	}
+	tempAudioPath := filepath.Join(tempDir, "recording.ogg")
+
+	recorder, err := createRecorder(ctx, env, lctx.ffmpegPath, opts.device, opts.systemRecord, opts.mix)
+	if err != nil {
+		return err
+	}
+	streamer, ok := recorder.(audio.StreamRecorder)
+	if !ok {
+		return fmt.Errorf("recorder does not support --stream")
+	}
+
+	// The TUI takes over the terminal while recording (--tui): messages
+	// printed meanwhile go to its status line.
+	var tui *liveTUI
+	recordEnv := env
+	if opts.tui {
+		term, err := openTerminal(env)
+		if err != nil {
+			return err
+		}
+		tui = newLiveTUI(term, env.Now, opts.duration)
+		defer tui.close()
+		tuiEnv := *env
+		tuiEnv.Stderr = tui
+		recordEnv = &tuiEnv
+	}
+
+	// The raw transcript streamed before restructuring is only appended to
+	// with the output
+	streamPath := streamTranscriptPath(lctx, opts)
+	streamMode := opts.outputMode
+	if streamPath != opts.output && streamMode == outputAppend {
+		streamMode = outputCreate
+	}
+	// With --force, the transcript is streamed to a temporary file, which only
+	// replaces the existing output once closed (see closeOutput).
+	streamFile, kept, err := openOutput(env, streamPath, streamMode, opts.format)
+	if err != nil {
+		return err
+	}
+	streamClosed := false
+	defer func() {
+		if !streamClosed {
+			_ = streamFile.Close()
+			discardOutput(streamFile.Name(), kept)
+		}
+	}()
+	// Streamed without restructuring, the output starts with its front matter
+	// and vault links: the length and speakers are not known yet
+	if streamPath == opts.output && kept == 0 {
+		audioPath := ""
+		if opts.keepAudio {
+			audioPath = lctx.audioPath
+		}
+		if header := lctx.note(opts, "", streamPath, outputCreate, audioPath, ""); header != "" {
+			if _, err := streamFile.WriteString(header); err != nil {
+				return fmt.Errorf("failed to write %s: %w", streamPath, err)
+			}
+		}
+	}
+
+	// Transcription outlives the recording context, so that the first Ctrl+C
+	// only stops recording. Canceling it also stops the segment watcher.
+	transcribeCtx, cancelTranscribe := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelTranscribe()
+
+	// The recording is stopped early if transcription fails.
+	recordCtx, cancelRecord := context.WithCancel(ctx)
+	defer cancelRecord()
+	if tui != nil {
+		tui.stop = cancelRecord
+	}
+
+	if err := balanceMix(recorder, opts.balance); err != nil {
+		return err
+	}
+	silenceStopped, err := stopOnSilence(recorder, opts.stopOnSilence)
+	if err != nil {
+		return err
+	}
+
+	// Recording and transcription overlap: the transcription phase is implied.
+	progress.PhaseChange(ctx, progress.PhaseRecording)
+	tui.run()
+	fmt.Fprintf(recordEnv.Stderr, "Recording for %s with streaming transcription... (press Ctrl+C to stop early)\n",
+		format.DurationHuman(opts.duration))
+
+	// Highlight markers are dropped with h in the TUI, else with Enter, and
+	// with SIGUSR2.
+	var marks *highlights
+	var stopHighlights func()
+	if tui != nil {
+		stopHighlights = watchHighlights(nil, tui.highlight)
+	} else {
+		marks = startHighlights(recordEnv, opts.highlightInput)
+		stopHighlights = marks.stop
+	}
+
+	// Segment progress is printed while recording: only warn about silence,
+	// unless the TUI shows the level.
+	var stopMeter func()
+	if tui != nil {
+		stopMeter = monitorTUILevels(env, recorder, tui)
+	} else {
+		stopMeter = monitorLevels(env, recorder, false)
+	}
+	stopReport := reportRecording(ctx, env.Now, opts.duration)
+	recordDone := make(chan struct{})
+	var recordErr error
+	endJournal := startRecordingJournal(recordEnv, liveRecordingJournal(lctx, opts, tempAudioPath))
+	go func() {
+		defer close(recordDone)
+		defer stopMeter()
+		defer stopReport()
+		defer endJournal()
+		recordErr = streamer.RecordStream(recordCtx, opts.duration, tempAudioPath, segmentDir, streamSegmentDuration)
+		stopHighlights()
+		tui.recordingStopped()
+	}()
+
+	watcher := audio.NewSegmentWatcher(segmentDir, streamSegmentDuration)
+	segments := watcher.Watch(transcribeCtx, streamPollInterval, recordDone)
+
+	transcriber := lctx.liveTranscriber(recordEnv)
+	transcribeOpts := transcribe.Options{
+		Diarize:  opts.diarize,
+		Prompt:   lctx.vocabulary.transcriptionPrompt(),
+		Language: opts.language,
+	}
+
+	var (
+		written  int
+		writeErr error
+		marked   strings.Builder // Streamed transcript, with the marks
+	)
+	parallel := lctx.parallel
+	if opts.autoParallel {
+		parallel = 0 // Nothing to measure on short segments: the default parallelism
+	}
+	parallel = resolveParallel(recordEnv.Stderr, parallel, nil, lctx.rateLimitRequests, lctx.rateLimitAudio)
+	results, err := transcribe.TranscribeStream(transcribeCtx, segments, transcriber, transcribeOpts, parallel,
+		func(seg transcribe.Segment) {
+			if tui != nil {
+				tui.segment(seg.Chunk.StartTime, seg.Text)
+			} else {
+				fmt.Fprintf(env.Stderr, "[%s] %s\n", format.Duration(seg.Chunk.StartTime), seg.Text)
+			}
+			lctx.recorded = max(lctx.recorded, seg.Chunk.EndTime)
+			text := seg.Text
+			if lctx.cleaner != nil {
+				text = lctx.cleaner.Clean(text)
+			}
+			if lctx.redactor != nil {
+				text, _ = lctx.redactor.Redact(text)
+			}
+			// Highlights and notes follow the segment they were added in
+			text = appendMarks(text, append(tui.takeMarks(seg.Chunk.EndTime), marks.take(seg.Chunk.EndTime)...))
+			if writeErr != nil || text == "" {
+				return
+			}
+			if written > 0 {
+				text = "\n\n" + text
+			}
+			if _, err := streamFile.WriteString(text); err != nil {
+				writeErr = fmt.Errorf("failed to append to %s: %w", streamPath, err)
+			}
+			marked.WriteString(text)
+			written++
+		}, transcribe.WithUsageTracker(lctx.report.transcription), transcribe.WithRateLimiter(lctx.rateLimiter))
+	if err != nil {
+		cancelRecord()
+		<-recordDone
+		tui.close()
+		streamClosed = true
+		if closeErr := closeOutput(env, streamFile, streamPath); closeErr != nil {
+			warnf(env.Stderr, warnFileNotSaved, "failed to write %s: %v", streamPath, closeErr)
+			return err
+		}
+		fmt.Fprintf(env.Stderr, "\nTranscription failed. Partial transcript is available at: %s\n", streamPath)
+		return err
+	}
+	<-recordDone
+	tui.close()
+
+	// Highlights and notes added after the last segment end the transcript
+	if text := appendMarks("", append(tui.pendingMarks(), marks.pending()...)); text != "" && writeErr == nil {
+		if written > 0 {
+			text = "\n\n" + text
+		}
+		if _, err := streamFile.WriteString(text); err != nil {
+			writeErr = fmt.Errorf("failed to append to %s: %w", streamPath, err)
+		}
+		marked.WriteString(text)
+	}
+	lctx.highlights = append(highlightTimes(tui.allMarks()), marks.times()...)
+	if writeErr != nil {
+		streamClosed = true
+		_ = closeOutput(env, streamFile, streamPath)
+		return writeErr
+	}
+	// Without any segment, the output is discarded (deferred above)
+	if len(results) > 0 {
+		streamClosed = true
+		if err := closeOutput(env, streamFile, streamPath); err != nil {
+			return fmt.Errorf("failed to write %s: %w", streamPath, err)
+		}
+	}
+
+	if recordErr != nil && !handler.WasInterrupted() && !tui.stopped() {
+		return recordErr
+	}
+	if handler.WasInterrupted() || tui.stopped() {
+		fmt.Fprintln(env.Stderr, "\nRecording stopped early.")
+	}
+	if marks := tui.allMarks(); len(marks) > 0 {
+		fmt.Fprintf(env.Stderr, "Added: %s\n", marksCount(marks))
+	}
+	if silenceStopped() {
+		fmt.Fprintf(env.Stderr, "No sound for %s, recording stopped\n", format.DurationHuman(opts.stopOnSilence))
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("recording produced no audio (check your audio device)")
+	}
+
+	fmt.Fprintf(env.Stderr, "Transcription complete: %d segments\n", len(results))
+	// Cleaned and redacted like the segments written while streaming
+	if results, err = cleanResults(lctx.cleaner, results, false); err != nil {
+		return err
+	}
+	if results, err = redactResults(env.Stderr, lctx.redactor, results, false); err != nil {
+		return err
+	}
+	lctx.vocabulary.record(env, results, false)
+
+	// Move audio to final location if --keep-audio
+	audioPath := tempAudioPath
+	if opts.keepAudio {
+		if err := keepAudioFile(env, opts, tempAudioPath, lctx.audioPath); err != nil {
+			warnf(env.Stderr, warnFileNotSaved, "failed to save audio: %v", err)
+		} else {
+			audioPath = lctx.audioPath
+			fmt.Fprintf(env.Stderr, "Audio saved: %s\n", lctx.audioPath)
+		}
+	}
+
+	if opts.template.IsZero() {
+		fmt.Fprintf(env.Stderr, "Done: %s\n", streamPath)
+		return nil
+	}
+
+	fmt.Fprintf(env.Stderr, "Raw transcript saved: %s\n", streamPath)
+
+	// The raw transcript was already written while streaming. Transcription
+	// ignored Ctrl+C once recording stopped, restructuring does not.
+	restructureOpts := opts
+	restructureOpts.keepRawTranscript = false
+	restructureCtx := handler.Rearm(transcribeCtx)
+	transcript := strings.Join(results, "\n\n")
+	if len(lctx.highlights) > 0 {
+		// The markers locate the highlights for restructuring
+		transcript = marked.String()
+	}
+	finalOutput, err := liveRestructurePhase(restructureCtx, env, lctx, restructureOpts, transcript, audioPath, streamPath)
+	if err != nil {
+		return err
+	}
+	// Restructuring rewrites the transcript: the marks are listed at the end
+	if marks := tui.allMarks(); len(marks) > 0 {
+		finalOutput = strings.TrimRight(finalOutput, "\n") + marksSection(marks)
+	}
+
+	return liveWriteStamped(env, lctx, opts, audioPath, transcript, finalOutput)
+}
+
+// keepAudioFile moves the recording at src to dst (--keep-audio), with its
+// tracks (--separate-tracks), replacing existing files with --force.
+func keepAudioFile(env *Env, opts liveOptions, src, dst string) error {
+	moves := [][2]string{{src, dst}}
+	if opts.separateTracks {
+		srcMic, srcSystem := trackPaths(src)
+		dstMic, dstSystem := trackPaths(dst)
+		moves = append(moves, [2]string{srcMic, dstMic}, [2]string{srcSystem, dstSystem})
+	}
+	for _, move := range moves {
+		if opts.outputMode == outputForce {
+			if err := trashOutput(env, move[1]); err != nil {
+				return err
+			}
+		}
+		if err := moveFile(move[0], move[1]); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // moveFile moves a file from src to dst.
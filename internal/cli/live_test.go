@@ -3,6 +3,8 @@ package cli
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,9 +12,11 @@ import (
 	"time"
 
 	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
 	"github.com/alnah/go-transcript/internal/lang"
 	"github.com/alnah/go-transcript/internal/template"
 	"github.com/alnah/go-transcript/internal/transcribe"
+	"github.com/alnah/go-transcript/internal/trash"
 )
 
 // ---------------------------------------------------------------------------
@@ -745,6 +749,20 @@ func TestLiveCmd_MutuallyExclusiveFlags(t *testing.T) {
 	}
 }
 
+func TestLiveCmd_TUIRejectsJSONProgress(t *testing.T) {
+	t.Parallel()
+
+	env, _ := testEnv()
+	cmd := LiveCmd(env)
+
+	cmd.SetArgs([]string{"-d", "30m", "--tui", "--progress", "json"})
+	err := cmd.Execute()
+
+	if err == nil || !strings.Contains(err.Error(), "--tui cannot be combined with --progress json") {
+		t.Errorf("cmd.Execute() error = %v, want --tui rejected with --progress json", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Tests for restructuring path in live
 // ---------------------------------------------------------------------------
@@ -850,6 +868,111 @@ func TestRunLive_WithTemplate(t *testing.T) {
 	}
 }
 
+func TestRunLive_SessionDir(t *testing.T) {
+	t.Parallel()
+
+	sessionDir := t.TempDir()
+	fixedNow := time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)
+	stderr := &syncBuffer{}
+
+	var recordedTo string
+	recorderFactory := &mockRecorderFactory{
+		NewRecorderFunc: func(ffmpegPath, device string) (audio.Recorder, error) {
+			return &mockRecorder{
+				RecordFunc: func(ctx context.Context, duration time.Duration, output string) error {
+					recordedTo = output
+					return os.WriteFile(output, []byte("audio data"), 0644)
+				},
+			}, nil
+		},
+	}
+
+	chunkPath := filepath.Join(t.TempDir(), "chunk_0.ogg")
+	if err := os.WriteFile(chunkPath, []byte("chunk"), 0644); err != nil {
+		t.Fatalf("failed to create chunk: %v", err)
+	}
+	chunkerFactory := &mockChunkerFactory{
+		NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
+			return &mockChunker{
+				ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+					return []audio.Chunk{{Path: chunkPath, Index: 0, EndTime: time.Minute}}, nil
+				},
+			}, nil
+		},
+	}
+	transcriberFactory := &mockTranscriberFactory{
+		NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+			return &mockTranscriber{
+				TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+					return "Raw live transcript.", nil
+				},
+			}
+		},
+	}
+	restructurerFactory := &mockRestructurerFactory{
+		mockMapReducer: &mockMapReduceRestructurer{
+			RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+				return "# Meeting Notes", false, nil
+			},
+		},
+	}
+
+	env := &Env{
+		Stderr:              stderr,
+		Getenv:              defaultTestEnv,
+		Now:                 fixedTime(fixedNow),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        &mockConfigLoader{},
+		RecorderFactory:     recorderFactory,
+		ChunkerFactory:      chunkerFactory,
+		TranscriberFactory:  transcriberFactory,
+		RestructurerFactory: restructurerFactory,
+	}
+
+	opts := liveOptions{
+		provider:   DeepSeekProvider,
+		duration:   30 * time.Minute,
+		template:   template.MustParseName("meeting"),
+		sessionDir: sessionDir,
+	}
+
+	if err := RunLive(context.Background(), env, opts); err != nil {
+		t.Fatalf("RunLive() unexpected error: %v", err)
+	}
+
+	dir := filepath.Join(sessionDir, "live_20260125_143052")
+	if recordedTo != filepath.Join(dir, "audio.ogg") {
+		t.Errorf("recorded to %q, want straight into the session directory", recordedTo)
+	}
+	wantFiles := map[string]string{
+		"audio.ogg":     "audio data",
+		"raw.md":        "Raw live transcript.",
+		"transcript.md": "# Meeting Notes",
+	}
+	for name, want := range wantFiles {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("%s not written in session: %v", name, err)
+			continue
+		}
+		if string(content) != want {
+			t.Errorf("%s = %q, want %q", name, content, want)
+		}
+	}
+
+	meta, err := readSessionMetadata(dir)
+	if err != nil {
+		t.Fatalf("readSessionMetadata() unexpected error: %v", err)
+	}
+	if meta.Command != "live" || meta.Status != sessionComplete || meta.Template != "meeting" || meta.Provider != "deepseek" {
+		t.Errorf("metadata = %+v, want complete live session with template and provider", meta)
+	}
+	log, _ := os.ReadFile(filepath.Join(dir, "session.log"))
+	if !strings.Contains(string(log), "Restructuring") {
+		t.Errorf("session log = %q, want progress output", log)
+	}
+}
+
 func TestRunLive_RestructureError(t *testing.T) {
 	t.Parallel()
 
@@ -938,6 +1061,86 @@ func TestRunLive_RestructureError(t *testing.T) {
 	}
 }
 
+func TestRunLive_RestructureInterrupted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		keepRawTranscript bool
+		wantRaw           string // Raw transcript file, relative to the output directory
+	}{
+		{name: "raw transcript saved next to output", wantRaw: "live.raw.md"},
+		{name: "raw transcript already kept", keepRawTranscript: true, wantRaw: "live.raw.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			outputDir := t.TempDir()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			stderr := &syncBuffer{}
+			env := &Env{
+				Stderr:         stderr,
+				Getenv:         defaultTestEnv,
+				Now:            fixedTime(time.Now()),
+				FFmpegResolver: &mockFFmpegResolver{},
+				ConfigLoader:   &mockConfigLoader{},
+				RecorderFactory: &mockRecorderFactory{mockRecorder: &mockRecorder{
+					RecordFunc: func(ctx context.Context, duration time.Duration, output string) error {
+						return os.WriteFile(output, []byte("audio"), 0644)
+					},
+				}},
+				ChunkerFactory: &mockChunkerFactory{
+					NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
+						return &mockChunker{ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+							return []audio.Chunk{{Path: filepath.Join(t.TempDir(), "chunk_0.ogg"), Index: 0}}, nil
+						}}, nil
+					},
+				},
+				TranscriberFactory: &mockTranscriberFactory{
+					NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+						return &mockTranscriber{TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+							return "Live transcript.", nil
+						}}
+					},
+				},
+				// Ctrl+C while restructuring
+				RestructurerFactory: &mockRestructurerFactory{mockMapReducer: &mockMapReduceRestructurer{
+					RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+						cancel()
+						<-ctx.Done()
+						return "", false, ctx.Err()
+					},
+				}},
+			}
+			opts := liveOptions{
+				provider:          DeepSeekProvider,
+				duration:          time.Minute,
+				output:            filepath.Join(outputDir, "live.md"),
+				template:          template.MustParseName("brainstorm"),
+				keepRawTranscript: tt.keepRawTranscript,
+			}
+
+			err := RunLive(ctx, env, opts)
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("RunLive() error = %v, want context.Canceled", err)
+			}
+
+			rawPath := filepath.Join(outputDir, tt.wantRaw)
+			raw, readErr := os.ReadFile(rawPath)
+			if readErr != nil || string(raw) != "Live transcript." {
+				t.Errorf("raw transcript = %q (err: %v), want %q", raw, readErr, "Live transcript.")
+			}
+			if want := "Raw transcript saved: " + rawPath; !strings.Contains(stderr.String(), want) {
+				t.Errorf("stderr = %q, want containing %q", stderr.String(), want)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Tests for moveFile and copyFile
 // ---------------------------------------------------------------------------
@@ -1121,7 +1324,7 @@ func TestLiveWritePhase_Success(t *testing.T) {
 	}
 
 	content := "# Test Output\n\nSome content here."
-	err := LiveWritePhase(env, outputPath, content)
+	err := LiveWritePhase(env, outputPath, content, outputCreate, MarkdownFormat)
 	if err != nil {
 		t.Fatalf("LiveWritePhase(%q, %q) unexpected error: %v", outputPath, content, err)
 	}
@@ -1157,7 +1360,7 @@ func TestLiveWritePhase_OutputExists(t *testing.T) {
 		Stderr: &syncBuffer{},
 	}
 
-	err := LiveWritePhase(env, outputPath, "new content")
+	err := LiveWritePhase(env, outputPath, "new content", outputCreate, MarkdownFormat)
 	if err == nil {
 		t.Fatal("LiveWritePhase() with existing output file: expected error, got nil")
 	}
@@ -1174,7 +1377,7 @@ func TestLiveWritePhase_InvalidPath(t *testing.T) {
 	}
 
 	// Try to write to a path in a nonexistent directory
-	err := LiveWritePhase(env, "/nonexistent/dir/output.md", "content")
+	err := LiveWritePhase(env, "/nonexistent/dir/output.md", "content", outputCreate, MarkdownFormat)
 	if err == nil {
 		t.Fatal("LiveWritePhase() with invalid path: expected error, got nil")
 	}
@@ -1442,3 +1645,895 @@ func TestRunLive_MdExtensionNoWarning(t *testing.T) {
 		t.Errorf("stderr output = %q, want not containing extension warning", getStderr())
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Tests for streaming mode (--stream)
+// ---------------------------------------------------------------------------
+
+// streamingRecorder returns a mock recorder that writes the full recording and
+// the given segments, as FFmpeg's tee + segment muxers would.
+func streamingRecorder(segments int) *mockRecorder {
+	return &mockRecorder{
+		RecordStreamFunc: func(ctx context.Context, duration time.Duration, output, segmentDir string, segmentDuration time.Duration) error {
+			for i := range segments {
+				if err := os.WriteFile(audio.SegmentPath(segmentDir, i), []byte("segment"), 0644); err != nil {
+					return err
+				}
+			}
+			return os.WriteFile(output, []byte("audio data"), 0644)
+		},
+	}
+}
+
+// streamTranscriber returns a mock transcriber echoing the segment file name.
+func streamTranscriber() *mockTranscriberFactory {
+	return &mockTranscriberFactory{
+		NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+			return &mockTranscriber{
+				TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+					return "text of " + filepath.Base(audioPath), nil
+				},
+			}
+		},
+	}
+}
+
+func TestRunLive_Stream(t *testing.T) {
+	t.Parallel()
+
+	outputDir := t.TempDir()
+	output := filepath.Join(outputDir, "notes.md")
+	stderr := &syncBuffer{}
+	recorder := streamingRecorder(3)
+
+	env := &Env{
+		Stderr:             stderr,
+		Getenv:             defaultTestEnv,
+		Now:                fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver:     &mockFFmpegResolver{},
+		ConfigLoader:       &mockConfigLoader{},
+		RecorderFactory:    &mockRecorderFactory{mockRecorder: recorder},
+		TranscriberFactory: streamTranscriber(),
+	}
+
+	err := RunLive(context.Background(), env, liveOptions{
+		duration: 2 * time.Minute,
+		output:   output,
+		parallel: 2,
+		stream:   true,
+	})
+	if err != nil {
+		t.Fatalf("RunLive() unexpected error: %v", err)
+	}
+
+	calls := recorder.RecordStreamCalls()
+	if len(calls) != 1 {
+		t.Fatalf("RecordStream called %d times, want 1", len(calls))
+	}
+	if calls[0].SegmentDuration != streamSegmentDuration {
+		t.Errorf("segment duration = %v, want %v", calls[0].SegmentDuration, streamSegmentDuration)
+	}
+	if len(recorder.RecordCalls()) != 0 {
+		t.Error("Record() called in streaming mode, want RecordStream only")
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("os.ReadFile(%q) unexpected error: %v", output, err)
+	}
+	want := "text of segment_0000.ogg\n\ntext of segment_0001.ogg\n\ntext of segment_0002.ogg"
+	if string(content) != want {
+		t.Errorf("output content = %q, want %q", string(content), want)
+	}
+
+	out := stderr.String()
+	for _, s := range []string{"[00:00] text of segment_0000.ogg", "[01:00] text of segment_0002.ogg", "Done"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("stderr = %q, want containing %q", out, s)
+		}
+	}
+}
+
+func TestRunLive_StreamForce(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		segments int
+		want     string
+		wantErr  bool
+	}{
+		{name: "replaces the existing output once transcribed", segments: 2, want: "text of segment_0000.ogg\n\ntext of segment_0001.ogg"},
+		{name: "keeps the existing output without audio", segments: 0, want: "# Previous\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			output := filepath.Join(t.TempDir(), "notes.md")
+			if err := os.WriteFile(output, []byte("# Previous\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			env := &Env{
+				Stderr:             &syncBuffer{},
+				Getenv:             defaultTestEnv,
+				Now:                fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+				FFmpegResolver:     &mockFFmpegResolver{},
+				ConfigLoader:       &mockConfigLoader{},
+				RecorderFactory:    &mockRecorderFactory{mockRecorder: streamingRecorder(tt.segments)},
+				TranscriberFactory: streamTranscriber(),
+			}
+
+			err := RunLive(context.Background(), env, liveOptions{
+				duration:   2 * time.Minute,
+				output:     output,
+				outputMode: outputForce,
+				stream:     true,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunLive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got, _ := os.ReadFile(output); string(got) != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			matches, _ := filepath.Glob(filepath.Join(filepath.Dir(output), ".notes.md.*.tmp"))
+			if len(matches) != 0 {
+				t.Errorf("temporary files left: %v", matches)
+			}
+		})
+	}
+}
+
+func TestRunLive_StreamHighlights(t *testing.T) {
+	t.Parallel()
+
+	output := filepath.Join(t.TempDir(), "notes.md")
+	stderr := &syncBuffer{}
+	in, typed := io.Pipe()
+	t.Cleanup(func() { _ = typed.Close() })
+	segments := streamingRecorder(2).RecordStreamFunc
+	recorder := &mockRecorder{
+		RecordStreamFunc: func(ctx context.Context, duration time.Duration, output, segmentDir string, segmentDuration time.Duration) error {
+			if _, err := fmt.Fprintln(typed); err != nil {
+				return err
+			}
+			waitForMarks(t, stderr, 1)
+			return segments(ctx, duration, output, segmentDir, segmentDuration)
+		},
+	}
+
+	env := &Env{
+		Stderr:             stderr,
+		Getenv:             defaultTestEnv,
+		Now:                fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver:     &mockFFmpegResolver{},
+		ConfigLoader:       &mockConfigLoader{},
+		RecorderFactory:    &mockRecorderFactory{mockRecorder: recorder},
+		TranscriberFactory: streamTranscriber(),
+	}
+
+	err := RunLive(context.Background(), env, liveOptions{
+		duration:       2 * time.Minute,
+		output:         output,
+		parallel:       1,
+		stream:         true,
+		highlightInput: in,
+	})
+	if err != nil {
+		t.Fatalf("RunLive() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("os.ReadFile(%q) unexpected error: %v", output, err)
+	}
+	want := "text of segment_0000.ogg\n\n⭐ 00:00:00\n\ntext of segment_0001.ogg"
+	if string(content) != want {
+		t.Errorf("output content = %q, want %q", string(content), want)
+	}
+}
+
+func TestRunLive_StreamAppend(t *testing.T) {
+	t.Parallel()
+
+	outputDir := t.TempDir()
+	output := filepath.Join(outputDir, "journal.md")
+	if err := os.WriteFile(output, []byte("Earlier session.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	env := &Env{
+		Stderr:             &syncBuffer{},
+		Getenv:             defaultTestEnv,
+		Now:                fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.Local)),
+		FFmpegResolver:     &mockFFmpegResolver{},
+		ConfigLoader:       &mockConfigLoader{},
+		RecorderFactory:    &mockRecorderFactory{mockRecorder: streamingRecorder(1)},
+		TranscriberFactory: streamTranscriber(),
+	}
+
+	err := RunLive(context.Background(), env, liveOptions{
+		duration:   time.Minute,
+		output:     output,
+		parallel:   1,
+		stream:     true,
+		outputMode: outputAppend,
+	})
+	if err != nil {
+		t.Fatalf("RunLive() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Earlier session.\n\n---\n\n**2026-01-25 14:30**\n\ntext of segment_0000.ogg"
+	if string(content) != want {
+		t.Errorf("output content = %q, want %q", string(content), want)
+	}
+}
+
+func TestRunLive_KeepAudioForce(t *testing.T) {
+	t.Parallel()
+
+	outputDir := t.TempDir()
+	output := filepath.Join(outputDir, "notes.md")
+	for _, path := range []string{output, audioOutputPath(output)} {
+		if err := os.WriteFile(path, []byte("previous"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	env := &Env{
+		Stderr:             &syncBuffer{},
+		Getenv:             defaultTestEnv,
+		Now:                fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver:     &mockFFmpegResolver{},
+		ConfigLoader:       &mockConfigLoader{},
+		RecorderFactory:    &mockRecorderFactory{mockRecorder: streamingRecorder(1)},
+		TranscriberFactory: streamTranscriber(),
+	}
+
+	err := RunLive(context.Background(), env, liveOptions{
+		duration:   time.Minute,
+		output:     output,
+		parallel:   1,
+		stream:     true,
+		keepAudio:  true,
+		outputMode: outputForce,
+	})
+	if err != nil {
+		t.Fatalf("RunLive() unexpected error: %v", err)
+	}
+
+	if content, _ := os.ReadFile(output); string(content) != "text of segment_0000.ogg" {
+		t.Errorf("output content = %q, want the new transcript", content)
+	}
+	if content, _ := os.ReadFile(audioOutputPath(output)); string(content) != "audio data" {
+		t.Errorf("audio content = %q, want the new recording", content)
+	}
+	entries, err := trash.New(outputDir).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("trash has %d files, want the previous output and audio", len(entries))
+	}
+}
+
+func TestRunLive_StreamWithTemplate(t *testing.T) {
+	t.Parallel()
+
+	outputDir := t.TempDir()
+	output := filepath.Join(outputDir, "meeting.md")
+	restructurer := &mockMapReduceRestructurer{
+		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+			return "# Meeting", false, nil
+		},
+	}
+
+	env := &Env{
+		Stderr:              &syncBuffer{},
+		Getenv:              defaultTestEnv,
+		Now:                 fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        &mockConfigLoader{},
+		RecorderFactory:     &mockRecorderFactory{mockRecorder: streamingRecorder(2)},
+		TranscriberFactory:  streamTranscriber(),
+		RestructurerFactory: &mockRestructurerFactory{mockMapReducer: restructurer},
+	}
+
+	err := RunLive(context.Background(), env, liveOptions{
+		duration: time.Minute,
+		output:   output,
+		template: template.MustParseName("meeting"),
+		provider: DeepSeekProvider,
+		stream:   true,
+	})
+	if err != nil {
+		t.Fatalf("RunLive() unexpected error: %v", err)
+	}
+
+	raw, err := os.ReadFile(rawTranscriptPath(output))
+	if err != nil {
+		t.Fatalf("raw transcript not kept: %v", err)
+	}
+	if string(raw) != "text of segment_0000.ogg\n\ntext of segment_0001.ogg" {
+		t.Errorf("raw transcript = %q", string(raw))
+	}
+
+	calls := restructurer.RestructureCalls()
+	if len(calls) != 1 || calls[0].Transcript != string(raw) {
+		t.Errorf("Restructure() calls = %+v, want one call with the streamed transcript", calls)
+	}
+
+	final, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("os.ReadFile(%q) unexpected error: %v", output, err)
+	}
+	if string(final) != "# Meeting" {
+		t.Errorf("output content = %q, want %q", string(final), "# Meeting")
+	}
+}
+
+// tuiRecorder returns a mock recorder recording until stopped, then writing
+// the given segments: keys typed in the TUI come before any segment.
+func tuiRecorder(segments int) *mockRecorder {
+	recorder := streamingRecorder(segments)
+	write := recorder.RecordStreamFunc
+	recorder.RecordStreamFunc = func(ctx context.Context, duration time.Duration, output, segmentDir string, segmentDuration time.Duration) error {
+		<-ctx.Done()
+		return write(context.Background(), duration, output, segmentDir, segmentDuration)
+	}
+	return recorder
+}
+
+func TestRunLive_TUI(t *testing.T) {
+	t.Parallel()
+
+	output := filepath.Join(t.TempDir(), "notes.md")
+	stderr := &syncBuffer{}
+	terminals := &mockTerminalFactory{Keys: "hnCheck the budget\rq"}
+	env := &Env{
+		Stderr:             stderr,
+		Getenv:             defaultTestEnv,
+		Now:                fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver:     &mockFFmpegResolver{},
+		ConfigLoader:       &mockConfigLoader{},
+		RecorderFactory:    &mockRecorderFactory{mockRecorder: tuiRecorder(2)},
+		TranscriberFactory: streamTranscriber(),
+		TerminalFactory:    terminals,
+	}
+
+	err := RunLive(context.Background(), env, liveOptions{
+		duration: time.Hour,
+		output:   output,
+		stream:   true,
+		tui:      true,
+	})
+	if err != nil {
+		t.Fatalf("RunLive() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("os.ReadFile(%q) unexpected error: %v", output, err)
+	}
+	want := "text of segment_0000.ogg\n\n⭐ 00:00:00\n\n> Note at 00:00: Check the budget\n\ntext of segment_0001.ogg"
+	if string(content) != want {
+		t.Errorf("output content = %q, want %q", string(content), want)
+	}
+
+	term := terminals.terminal
+	if !term.Closed() {
+		t.Error("terminal not restored")
+	}
+	screen := term.screen.String()
+	for _, s := range []string{ansiEnterScreen, "[00:30] text of segment_0001.ogg", "Recording for 1h", ansiLeaveScreen} {
+		if !strings.Contains(screen, s) {
+			t.Errorf("screen = %q, want containing %q", screen, s)
+		}
+	}
+	out := stderr.String()
+	if strings.Contains(out, "text of segment") {
+		t.Errorf("stderr = %q, want the segments on the TUI only", out)
+	}
+	for _, s := range []string{"Recording stopped early.", "Added: 1 highlight, 1 note", "Done"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("stderr = %q, want containing %q", out, s)
+		}
+	}
+}
+
+func TestRunLive_TUIWithTemplate(t *testing.T) {
+	t.Parallel()
+
+	output := filepath.Join(t.TempDir(), "meeting.md")
+	restructurer := &mockMapReduceRestructurer{
+		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+			return "# Meeting\n", false, nil
+		},
+	}
+	env := &Env{
+		Stderr:              &syncBuffer{},
+		Getenv:              defaultTestEnv,
+		Now:                 fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        &mockConfigLoader{},
+		RecorderFactory:     &mockRecorderFactory{mockRecorder: tuiRecorder(1)},
+		TranscriberFactory:  streamTranscriber(),
+		RestructurerFactory: &mockRestructurerFactory{mockMapReducer: restructurer},
+		TerminalFactory:     &mockTerminalFactory{Keys: "nAction item\rq"},
+	}
+
+	err := RunLive(context.Background(), env, liveOptions{
+		duration: time.Hour,
+		output:   output,
+		template: template.MustParseName("meeting"),
+		provider: DeepSeekProvider,
+		stream:   true,
+		tui:      true,
+	})
+	if err != nil {
+		t.Fatalf("RunLive() unexpected error: %v", err)
+	}
+
+	final, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("os.ReadFile(%q) unexpected error: %v", output, err)
+	}
+	if want := "# Meeting\n\n## Highlights and notes\n\n- 00:00 Action item\n"; string(final) != want {
+		t.Errorf("output content = %q, want %q", string(final), want)
+	}
+	raw, err := os.ReadFile(rawTranscriptPath(output))
+	if err != nil {
+		t.Fatalf("raw transcript not kept: %v", err)
+	}
+	if want := "text of segment_0000.ogg\n\n> Note at 00:00: Action item"; string(raw) != want {
+		t.Errorf("raw transcript = %q, want %q", string(raw), want)
+	}
+}
+
+func TestRunLive_TUINoTerminal(t *testing.T) {
+	t.Parallel()
+
+	recorder := tuiRecorder(1)
+	env := &Env{
+		Stderr:             &syncBuffer{},
+		Getenv:             defaultTestEnv,
+		Now:                fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver:     &mockFFmpegResolver{},
+		ConfigLoader:       &mockConfigLoader{},
+		RecorderFactory:    &mockRecorderFactory{mockRecorder: recorder},
+		TranscriberFactory: streamTranscriber(),
+	}
+
+	err := RunLive(context.Background(), env, liveOptions{
+		duration: time.Hour,
+		output:   filepath.Join(t.TempDir(), "notes.md"),
+		stream:   true,
+		tui:      true,
+	})
+	if !errors.Is(err, ErrNoTerminal) {
+		t.Errorf("RunLive() error = %v, want ErrNoTerminal", err)
+	}
+	if len(recorder.RecordStreamCalls()) != 0 {
+		t.Error("RecordStream() called without terminal")
+	}
+}
+
+func TestRunLive_StreamTranscriptionFails(t *testing.T) {
+	t.Parallel()
+
+	output := filepath.Join(t.TempDir(), "notes.md")
+	apiErr := errors.New("rate limited")
+	recorder := &mockRecorder{
+		RecordStreamFunc: func(ctx context.Context, duration time.Duration, output, segmentDir string, segmentDuration time.Duration) error {
+			for i := range 2 {
+				if err := os.WriteFile(audio.SegmentPath(segmentDir, i), []byte("segment"), 0644); err != nil {
+					return err
+				}
+			}
+			// Keep "recording" until the failed transcription stops us.
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+
+	env := &Env{
+		Stderr:          &syncBuffer{},
+		Getenv:          defaultTestEnv,
+		Now:             fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver:  &mockFFmpegResolver{},
+		ConfigLoader:    &mockConfigLoader{},
+		RecorderFactory: &mockRecorderFactory{mockRecorder: recorder},
+		TranscriberFactory: &mockTranscriberFactory{
+			NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+				return &mockTranscriber{
+					TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+						return "", apiErr
+					},
+				}
+			},
+		},
+	}
+
+	err := RunLive(context.Background(), env, liveOptions{
+		duration: time.Hour,
+		output:   output,
+		stream:   true,
+	})
+	if !errors.Is(err, apiErr) {
+		t.Errorf("RunLive() error = %v, want %v", err, apiErr)
+	}
+}
+
+func TestRunLive_StreamRawTranscriptExists(t *testing.T) {
+	t.Parallel()
+
+	output := filepath.Join(t.TempDir(), "meeting.md")
+	if err := os.WriteFile(rawTranscriptPath(output), []byte("existing"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	env := &Env{
+		Stderr:         &syncBuffer{},
+		Getenv:         defaultTestEnv,
+		Now:            fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver: &mockFFmpegResolver{},
+		ConfigLoader:   &mockConfigLoader{},
+	}
+
+	err := RunLive(context.Background(), env, liveOptions{
+		duration: time.Minute,
+		output:   output,
+		template: template.MustParseName("meeting"),
+		stream:   true,
+	})
+	if !errors.Is(err, ErrOutputExists) {
+		t.Errorf("RunLive() error = %v, want ErrOutputExists", err)
+	}
+}
+
+func TestRunLive_LocalTranscriber(t *testing.T) {
+	t.Parallel()
+
+	outputPath := filepath.Join(t.TempDir(), "live.md")
+
+	recorderFactory := &mockRecorderFactory{
+		NewRecorderFunc: func(ffmpegPath, device string) (audio.Recorder, error) {
+			return &mockRecorder{
+				RecordFunc: func(ctx context.Context, duration time.Duration, output string) error {
+					return os.WriteFile(output, []byte("audio data"), 0644)
+				},
+			}, nil
+		},
+	}
+	chunkPath := filepath.Join(t.TempDir(), "chunk_0.ogg")
+	if err := os.WriteFile(chunkPath, []byte("chunk"), 0644); err != nil {
+		t.Fatalf("failed to create chunk: %v", err)
+	}
+	chunkerFactory := &mockChunkerFactory{
+		NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
+			return &mockChunker{
+				ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+					return []audio.Chunk{{Path: chunkPath, Index: 0, EndTime: time.Minute}}, nil
+				},
+			}, nil
+		},
+	}
+	transcriberFactory := &mockTranscriberFactory{
+		NewLocalTranscriberFunc: func(cfg LocalTranscriberConfig) (transcribe.Transcriber, error) {
+			return &mockTranscriber{
+				TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+					return "Offline transcript.", nil
+				},
+			}, nil
+		},
+	}
+
+	env := &Env{
+		Stderr:         &syncBuffer{},
+		Getenv:         func(string) string { return "" }, // No API key at all
+		Now:            fixedTime(time.Now()),
+		FFmpegResolver: &mockFFmpegResolver{},
+		ConfigLoader: &mockConfigLoader{
+			LoadFunc: func() (config.Config, error) {
+				return config.Config{WhisperModel: "/models/ggml-base.bin"}, nil
+			},
+		},
+		RecorderFactory:    recorderFactory,
+		ChunkerFactory:     chunkerFactory,
+		TranscriberFactory: transcriberFactory,
+	}
+
+	opts := liveOptions{
+		duration: 30 * time.Minute,
+		output:   outputPath,
+		parallel: 5,
+		backend:  LocalBackend,
+	}
+
+	if err := RunLive(context.Background(), env, opts); err != nil {
+		t.Fatalf("RunLive() unexpected error: %v", err)
+	}
+
+	if configs := transcriberFactory.LocalConfigs(); len(configs) != 1 {
+		t.Errorf("local transcriber created %d times, want 1", len(configs))
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if string(content) != "Offline transcript." {
+		t.Errorf("output = %q, want %q", content, "Offline transcript.")
+	}
+}
+
+func TestRunLive_LocalTranscriberModelMissing(t *testing.T) {
+	t.Parallel()
+
+	recorderFactory := &mockRecorderFactory{}
+	env := &Env{
+		Stderr:         &syncBuffer{},
+		Getenv:         defaultTestEnv,
+		Now:            fixedTime(time.Now()),
+		FFmpegResolver: &mockFFmpegResolver{},
+		ConfigLoader: &mockConfigLoader{
+			LoadFunc: func() (config.Config, error) {
+				return config.Config{WhisperModel: "/models/missing.bin"}, nil
+			},
+		},
+		RecorderFactory: recorderFactory,
+		TranscriberFactory: &mockTranscriberFactory{
+			NewLocalTranscriberFunc: func(cfg LocalTranscriberConfig) (transcribe.Transcriber, error) {
+				return nil, fmt.Errorf("%w: %s", transcribe.ErrModelNotFound, cfg.ModelPath)
+			},
+		},
+	}
+
+	opts := liveOptions{
+		duration: 30 * time.Minute,
+		output:   filepath.Join(t.TempDir(), "live.md"),
+		backend:  LocalBackend,
+	}
+
+	err := RunLive(context.Background(), env, opts)
+	if !errors.Is(err, transcribe.ErrModelNotFound) {
+		t.Errorf("RunLive() error = %v, want ErrModelNotFound", err)
+	}
+	if len(recorderFactory.NewRecorderCalls()) != 0 {
+		t.Error("recorder created, want failure before recording")
+	}
+}
+
+func TestRunLive_SubtitleFormat(t *testing.T) {
+	t.Parallel()
+
+	outputDir := t.TempDir()
+	fixedNow := time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)
+
+	recorderFactory := &mockRecorderFactory{
+		NewRecorderFunc: func(ffmpegPath, device string) (audio.Recorder, error) {
+			return &mockRecorder{
+				RecordFunc: func(ctx context.Context, duration time.Duration, output string) error {
+					return os.WriteFile(output, []byte("audio data"), 0644)
+				},
+			}, nil
+		},
+	}
+	chunkPath := filepath.Join(t.TempDir(), "chunk_0.ogg")
+	if err := os.WriteFile(chunkPath, []byte("chunk"), 0644); err != nil {
+		t.Fatalf("failed to create chunk: %v", err)
+	}
+	chunkerFactory := &mockChunkerFactory{
+		NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
+			return &mockChunker{
+				ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+					return []audio.Chunk{{Path: chunkPath, Index: 0, EndTime: time.Minute}}, nil
+				},
+			}, nil
+		},
+	}
+	transcriberFactory := &mockTranscriberFactory{
+		NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+			return &mockTranscriber{
+				TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+					if !opts.Timestamps {
+						return "", errors.New("timestamps not requested")
+					}
+					return `[{"start":0.5,"end":3,"text":"Live subtitle."}]`, nil
+				},
+			}
+		},
+	}
+
+	env := &Env{
+		Stderr:             &syncBuffer{},
+		Getenv:             defaultTestEnv,
+		Now:                fixedTime(fixedNow),
+		FFmpegResolver:     &mockFFmpegResolver{},
+		ConfigLoader:       configWithOutputDir(outputDir),
+		RecorderFactory:    recorderFactory,
+		ChunkerFactory:     chunkerFactory,
+		TranscriberFactory: transcriberFactory,
+	}
+
+	opts := liveOptions{
+		duration: 30 * time.Minute,
+		parallel: 5,
+		format:   VTTFormat,
+	}
+
+	if err := RunLive(context.Background(), env, opts); err != nil {
+		t.Fatalf("RunLive() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "transcript_20260125_143052.vtt"))
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	want := "WEBVTT\n\n00:00:00.500 --> 00:00:03.000\nLive subtitle.\n"
+	if string(content) != want {
+		t.Errorf("output = %q, want %q", content, want)
+	}
+}
+
+func TestRunLive_SubtitleFormatConflicts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		opts        liveOptions
+		wantContain string
+	}{
+		{
+			name:        "template",
+			opts:        liveOptions{format: SRTFormat, template: template.MustParseName("meeting"), provider: DeepSeekProvider},
+			wantContain: "--template",
+		},
+		{
+			name:        "stream",
+			opts:        liveOptions{format: SRTFormat, stream: true},
+			wantContain: "--stream",
+		},
+		{
+			name:        "voice tags",
+			opts:        liveOptions{format: SRTFormat, speakerLabels: SpeakerLabels{name: SpeakerLabelsVoice}},
+			wantContain: "--speaker-labels",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := &Env{
+				Stderr:          &syncBuffer{},
+				Getenv:          defaultTestEnv,
+				Now:             fixedTime(time.Now()),
+				FFmpegResolver:  &mockFFmpegResolver{},
+				ConfigLoader:    &mockConfigLoader{},
+				RecorderFactory: &mockRecorderFactory{},
+			}
+			opts := tt.opts
+			opts.duration = time.Minute
+			opts.output = filepath.Join(t.TempDir(), "live.srt")
+
+			err := RunLive(context.Background(), env, opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantContain) {
+				t.Errorf("RunLive() error = %v, want conflict with %s", err, tt.wantContain)
+			}
+		})
+	}
+}
+
+func TestRunLive_TimedTemplateRejectsStream(t *testing.T) {
+	t.Parallel()
+
+	env := &Env{
+		Stderr:          &syncBuffer{},
+		Getenv:          defaultTestEnv,
+		Now:             fixedTime(time.Now()),
+		FFmpegResolver:  &mockFFmpegResolver{},
+		ConfigLoader:    &mockConfigLoader{},
+		RecorderFactory: &mockRecorderFactory{},
+	}
+	opts := liveOptions{
+		duration: time.Minute,
+		output:   filepath.Join(t.TempDir(), "live.md"),
+		template: template.PodcastName,
+		provider: DeepSeekProvider,
+		stream:   true,
+	}
+
+	err := RunLive(context.Background(), env, opts)
+	if err == nil || !strings.Contains(err.Error(), "--stream") {
+		t.Errorf("RunLive() error = %v, want conflict with --stream", err)
+	}
+}
+
+func TestRunLive_Preprocessing(t *testing.T) {
+	t.Parallel()
+
+	outputDir := t.TempDir()
+	output := filepath.Join(outputDir, "cafe.md")
+
+	recorder := &mockRecorder{
+		RecordFunc: func(ctx context.Context, duration time.Duration, output string) error {
+			return os.WriteFile(output, []byte("noisy audio"), 0644)
+		},
+	}
+	var chunked string
+	chunker := &mockChunker{
+		ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+			chunked = audioPath
+			return []audio.Chunk{{Path: filepath.Join(t.TempDir(), "chunk_0.ogg"), Index: 0}}, nil
+		},
+	}
+	preprocessor := &mockPreprocessor{
+		PreprocessFunc: func(ctx context.Context, inputPath, outputPath string, p audio.Preprocessing, onProgress func(done, total time.Duration)) error {
+			return os.WriteFile(outputPath, []byte("clean audio"), 0600)
+		},
+	}
+	env := &Env{
+		Stderr:              &syncBuffer{},
+		Getenv:              defaultTestEnv,
+		Now:                 fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        configWithOutputDir(outputDir),
+		RecorderFactory:     &mockRecorderFactory{mockRecorder: recorder},
+		ChunkerFactory:      &mockChunkerFactory{mockChunker: chunker},
+		TranscriberFactory:  &mockTranscriberFactory{},
+		PreprocessorFactory: &mockPreprocessorFactory{mockPreprocessor: preprocessor},
+	}
+	opts := liveOptions{
+		output:        output,
+		provider:      DeepSeekProvider,
+		duration:      time.Minute,
+		keepAudio:     true,
+		preprocessing: audio.Preprocessing{Denoise: true},
+	}
+
+	if err := RunLive(context.Background(), env, opts); err != nil {
+		t.Fatalf("RunLive() unexpected error: %v", err)
+	}
+
+	audioPath := filepath.Join(outputDir, "cafe.ogg")
+	calls := preprocessor.Calls()
+	if len(calls) != 1 || calls[0].p != opts.preprocessing {
+		t.Fatalf("Preprocess() calls = %+v, want one with %+v", calls, opts.preprocessing)
+	}
+	if chunked == "" || chunked == calls[0].inputPath {
+		t.Errorf("chunked %q, want the preprocessed audio", chunked)
+	}
+	if data, err := os.ReadFile(audioPath); err != nil || string(data) != "noisy audio" {
+		t.Errorf("kept audio = %q, %v, want the recording unchanged", data, err)
+	}
+}
+
+func TestRunLive_PreprocessingRejectsStream(t *testing.T) {
+	t.Parallel()
+
+	env := &Env{
+		Stderr:          &syncBuffer{},
+		Getenv:          defaultTestEnv,
+		Now:             fixedTime(time.Now()),
+		FFmpegResolver:  &mockFFmpegResolver{},
+		ConfigLoader:    &mockConfigLoader{},
+		RecorderFactory: &mockRecorderFactory{},
+	}
+	opts := liveOptions{
+		duration:      time.Minute,
+		output:        filepath.Join(t.TempDir(), "live.md"),
+		provider:      DeepSeekProvider,
+		stream:        true,
+		preprocessing: audio.Preprocessing{Normalize: true},
+	}
+
+	err := RunLive(context.Background(), env, opts)
+	if err == nil || !strings.Contains(err.Error(), "--normalize") {
+		t.Errorf("RunLive() error = %v, want conflict with --stream", err)
+	}
+}
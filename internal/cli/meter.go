@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// Level meter display, in LUFS (loudness units relative to full scale).
+const (
+	meterWidth   = 20                     // Number of cells in the meter bar.
+	meterFloor   = -60.0                  // Loudness shown as an empty bar.
+	meterRefresh = 250 * time.Millisecond // Minimum delay between redraws.
+)
+
+// Silence detection at the start of a recording.
+const (
+	// silenceLoudness is the momentary loudness below which the input is
+	// considered silent: a muted microphone measures around -120 LUFS.
+	silenceLoudness = -60.0
+	// silenceWarnAfter is how long the input must stay silent before warning.
+	silenceWarnAfter = 10 * time.Second
+	// silenceWatchPeriod is the start of the recording during which silence
+	// is reported; later silences are usually pauses.
+	silenceWatchPeriod = 30 * time.Second
+)
+
+// levelMeter displays the input loudness on a single line, redrawn in place,
+// and warns once if the input stays silent at the start of the recording.
+type levelMeter struct {
+	w    io.Writer
+	now  func() time.Time
+	draw bool // Draw the meter bar (false: silence warning only).
+
+	mu          sync.Mutex
+	start       time.Time // First measurement.
+	silentSince time.Time // Start of the current silence (zero: not silent).
+	lastDraw    time.Time
+	drawn       bool // The meter line is on screen and not yet ended.
+	warned      bool
+	stopped     bool
+}
+
+// monitorLevels shows a level meter on env.Stderr while recorder records,
+// if it can report levels. With draw false, only the silence warning is shown,
+// for outputs where the meter line would interleave with other progress.
+// The returned function ends the meter; call it once recording stops.
+func monitorLevels(env *Env, recorder audio.Recorder, draw bool) (stop func()) {
+	monitor, ok := recorder.(audio.LevelMonitor)
+	if !ok {
+		return func() {}
+	}
+	m := newLevelMeter(env.Stderr, env.Now, draw && isTerminal(env.Stderr))
+	monitor.MonitorLevels(m.update)
+	return m.stop
+}
+
+// newLevelMeter creates a level meter writing to w.
+func newLevelMeter(w io.Writer, now func() time.Time, draw bool) *levelMeter {
+	return &levelMeter{w: w, now: now, draw: draw}
+}
+
+// update records a momentary loudness measurement and redraws the meter.
+func (m *levelMeter) update(loudness float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopped {
+		return
+	}
+	now := m.now()
+	if m.start.IsZero() {
+		m.start = now
+	}
+	m.checkSilence(now, loudness)
+
+	if !m.draw || (m.drawn && now.Sub(m.lastDraw) < meterRefresh) {
+		return
+	}
+	fmt.Fprintf(m.w, "\rLevel %s %s", meterBar(loudness), loudnessLabel(loudness))
+	m.lastDraw = now
+	m.drawn = true
+}
+
+// checkSilence warns once if the input stays silent for silenceWarnAfter
+// during the first silenceWatchPeriod of the recording.
+func (m *levelMeter) checkSilence(now time.Time, loudness float64) {
+	if m.warned || now.Sub(m.start) > silenceWatchPeriod {
+		return
+	}
+	if loudness >= silenceLoudness {
+		m.silentSince = time.Time{}
+		return
+	}
+	if m.silentSince.IsZero() {
+		m.silentSince = now
+	}
+	if now.Sub(m.silentSince) < silenceWarnAfter {
+		return
+	}
+	m.endLine()
+	warnf(m.w, warnSilence, "no sound detected for %s. Is the microphone muted? Check the input with: transcript devices --test",
+		silenceWarnAfter)
+	m.warned = true
+}
+
+// stop ends the meter line. Later measurements are ignored.
+func (m *levelMeter) stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.endLine()
+	m.stopped = true
+}
+
+// endLine moves past the meter line, so that the next output starts on its own line.
+func (m *levelMeter) endLine() {
+	if m.drawn {
+		fmt.Fprintln(m.w)
+		m.drawn = false
+	}
+}
+
+// meterBar renders loudness as a bar from meterFloor (empty) to 0 LUFS (full).
+func meterBar(loudness float64) string {
+	filled := int((loudness - meterFloor) / -meterFloor * meterWidth)
+	filled = max(0, min(meterWidth, filled))
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", meterWidth-filled) + "]"
+}
+
+// loudnessLabel formats loudness with a fixed width, so that redraws
+// overwrite the previous value entirely.
+func loudnessLabel(loudness float64) string {
+	if loudness < meterFloor {
+		return "    silent"
+	}
+	return fmt.Sprintf("%5.1f LUFS", loudness)
+}
+
+// isTerminal reports whether w is a terminal, where a line can be redrawn in place.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
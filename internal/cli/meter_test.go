@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// ---------------------------------------------------------------------------
+// Tests for levelMeter
+// ---------------------------------------------------------------------------
+
+// stepClock returns a clock advancing by step on each call.
+func stepClock(step time.Duration) func() time.Time {
+	now := time.Date(2026, 1, 26, 14, 30, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestLevelMeter_Draw(t *testing.T) {
+	t.Parallel()
+
+	stderr := &syncBuffer{}
+	m := newLevelMeter(stderr, stepClock(time.Second), true)
+
+	m.update(-30)
+	m.stop()
+	m.update(-10) // Ignored after stop.
+
+	want := "\rLevel [##########----------] -30.0 LUFS\n"
+	if got := stderr.String(); got != want {
+		t.Errorf("levelMeter output = %q, want %q", got, want)
+	}
+}
+
+func TestLevelMeter_Throttle(t *testing.T) {
+	t.Parallel()
+
+	stderr := &syncBuffer{}
+	m := newLevelMeter(stderr, stepClock(100*time.Millisecond), true)
+
+	for range 5 {
+		m.update(-20)
+	}
+
+	// Drawn at 0ms and 300ms: updates within meterRefresh are skipped.
+	if got := strings.Count(stderr.String(), "\r"); got != 2 {
+		t.Errorf("levelMeter drew %d times, want 2 (output %q)", got, stderr.String())
+	}
+}
+
+func TestLevelMeter_SilenceWarning(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		levels   []float64 // One measurement per second.
+		wantWarn bool
+	}{
+		{"sustained silence", repeatLevel(-120, 12), true},
+		{"sound", repeatLevel(-25, 12), false},
+		{"short silences", append(append(repeatLevel(-120, 8), -25), repeatLevel(-120, 8)...), false},
+		{"silence after watch period", append(repeatLevel(-25, 30), repeatLevel(-120, 15)...), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stderr := &syncBuffer{}
+			m := newLevelMeter(stderr, stepClock(time.Second), false)
+			for _, l := range tt.levels {
+				m.update(l)
+			}
+			m.stop()
+
+			output := stderr.String()
+			if warned := strings.Contains(output, "no sound detected"); warned != tt.wantWarn {
+				t.Errorf("levelMeter output = %q, want warning = %v", output, tt.wantWarn)
+			}
+			if strings.Contains(output, "Level [") {
+				t.Errorf("levelMeter output = %q, want no meter when draw is false", output)
+			}
+		})
+	}
+}
+
+func TestLevelMeter_WarningEndsMeterLine(t *testing.T) {
+	t.Parallel()
+
+	stderr := &syncBuffer{}
+	m := newLevelMeter(stderr, stepClock(time.Second), true)
+	for _, l := range repeatLevel(-120, 11) {
+		m.update(l)
+	}
+
+	if !strings.Contains(stderr.String(), "silent\nWarning: no sound detected") {
+		t.Errorf("levelMeter output = %q, want warning on its own line", stderr.String())
+	}
+}
+
+func TestMeterBar(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		loudness float64
+		want     string
+	}{
+		{-120, "[--------------------]"},
+		{-60, "[--------------------]"},
+		{-30, "[##########----------]"},
+		{0, "[####################]"},
+		{3, "[####################]"},
+	}
+
+	for _, tt := range tests {
+		if got := meterBar(tt.loudness); got != tt.want {
+			t.Errorf("meterBar(%v) = %q, want %q", tt.loudness, got, tt.want)
+		}
+	}
+}
+
+func TestMonitorLevels_UnsupportedRecorder(t *testing.T) {
+	t.Parallel()
+
+	env, _ := testEnv()
+	stop := monitorLevels(env, recorderOnly{}, true)
+	stop() // Must not panic.
+}
+
+// recorderOnly is a recorder without level monitoring.
+type recorderOnly struct{ audio.Recorder }
+
+// repeatLevel returns n copies of loudness.
+func repeatLevel(loudness float64, n int) []float64 {
+	levels := make([]float64, n)
+	for i := range levels {
+		levels[i] = loudness
+	}
+	return levels
+}
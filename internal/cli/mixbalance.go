@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// maxMixGain bounds --mic-gain and --system-gain, in dB: beyond it, the
+// input is distorted or muted rather than balanced.
+const maxMixGain = 30
+
+// Help of the --mic-gain, --system-gain and --auto-balance flags of the
+// record and live commands.
+const (
+	micGainFlagHelp     = "With --mix, gain of the microphone in dB (e.g., 6, -3dB)"
+	systemGainFlagHelp  = "With --mix, gain of the system audio in dB (e.g., -6, 3dB)"
+	autoBalanceFlagHelp = "With --mix, normalize the loudness of the microphone and system audio before mixing"
+)
+
+// parseMixBalance returns the levels of the inputs of a --mix recording from
+// the --mic-gain, --system-gain and --auto-balance flags, which require --mix.
+func parseMixBalance(mix bool, micGain, systemGain string, autoBalance bool) (audio.MixBalance, error) {
+	var (
+		b   = audio.MixBalance{Auto: autoBalance}
+		err error
+	)
+	if b.MicGain, err = parseGain("--mic-gain", micGain); err != nil {
+		return audio.MixBalance{}, err
+	}
+	if b.SystemGain, err = parseGain("--system-gain", systemGain); err != nil {
+		return audio.MixBalance{}, err
+	}
+	if !mix && (micGain != "" || systemGain != "" || autoBalance) {
+		return audio.MixBalance{}, fmt.Errorf("--mic-gain, --system-gain and --auto-balance require --mix: %w", ErrInvalidGain)
+	}
+	return b, nil
+}
+
+// parseGain parses a gain in dB, with or without the dB unit. Empty means 0.
+func parseGain(flag, value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	number := strings.TrimSpace(value)
+	if len(number) > 2 && strings.EqualFold(number[len(number)-2:], "db") {
+		number = strings.TrimSpace(number[:len(number)-2])
+	}
+	gain, err := strconv.ParseFloat(number, 64)
+	if err != nil || math.IsNaN(gain) || math.Abs(gain) > maxMixGain {
+		return 0, fmt.Errorf("invalid %s %q: %w (use a gain in dB between -%d and %d, like 6 or -3dB)",
+			flag, value, ErrInvalidGain, maxMixGain, maxMixGain)
+	}
+	return gain, nil
+}
+
+// balanceMix sets the levels of the inputs of recorder, a --mix recorder.
+// The zero balance leaves the recorder as it is.
+func balanceMix(recorder audio.Recorder, b audio.MixBalance) error {
+	if b.IsZero() {
+		return nil
+	}
+	balancer, ok := recorder.(audio.MixBalancer)
+	if !ok {
+		return fmt.Errorf("recorder does not support --mic-gain, --system-gain or --auto-balance")
+	}
+	balancer.BalanceMix(b)
+	return nil
+}
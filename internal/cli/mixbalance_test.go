@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+func TestParseMixBalance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		mix         bool
+		micGain     string
+		systemGain  string
+		autoBalance bool
+		want        audio.MixBalance
+		wantErr     bool
+	}{
+		{name: "no flags", mix: true},
+		{name: "no flags without mix"},
+		{name: "gains", mix: true, micGain: "6", systemGain: "-3.5", want: audio.MixBalance{MicGain: 6, SystemGain: -3.5}},
+		{name: "dB unit", mix: true, micGain: "+6dB", systemGain: "-3 DB", want: audio.MixBalance{MicGain: 6, SystemGain: -3}},
+		{name: "auto balance", mix: true, autoBalance: true, want: audio.MixBalance{Auto: true}},
+		{name: "limit", mix: true, micGain: "30", want: audio.MixBalance{MicGain: 30}},
+		{name: "above limit", mix: true, micGain: "31", wantErr: true},
+		{name: "not a number", mix: true, systemGain: "loud", wantErr: true},
+		{name: "unit only", mix: true, micGain: "dB", wantErr: true},
+		{name: "infinite", mix: true, micGain: "inf", wantErr: true},
+		{name: "gain without mix", micGain: "6", wantErr: true},
+		{name: "auto balance without mix", autoBalance: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseMixBalance(tt.mix, tt.micGain, tt.systemGain, tt.autoBalance)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidGain) {
+					t.Errorf("parseMixBalance() error = %v, want ErrInvalidGain", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMixBalance() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseMixBalance() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunRecord_MixBalance(t *testing.T) {
+	t.Parallel()
+
+	recorder := &mockRecorder{
+		RecordFunc: func(ctx context.Context, duration time.Duration, output string) error {
+			return os.WriteFile(output, []byte("audio"), 0600)
+		},
+	}
+	env := &Env{
+		Stderr:              &syncBuffer{},
+		Getenv:              func(string) string { return "" },
+		Now:                 fixedTime(time.Now()),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        &mockConfigLoader{},
+		RecorderFactory:     &mockRecorderFactory{mockRecorder: recorder},
+		DeviceListerFactory: &mockDeviceListerFactory{},
+	}
+
+	balance := audio.MixBalance{MicGain: 6, Auto: true}
+	opts := recordOptions{
+		duration: time.Minute,
+		output:   filepath.Join(t.TempDir(), "call.ogg"),
+		mix:      true,
+		balance:  balance,
+	}
+	if err := RunRecord(context.Background(), env, opts); err != nil {
+		t.Fatalf("RunRecord() unexpected error: %v", err)
+	}
+	if got := recorder.Balance(); got != balance {
+		t.Errorf("recorder balance = %+v, want %+v", got, balance)
+	}
+}
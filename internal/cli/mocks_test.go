@@ -2,15 +2,27 @@ package cli
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/alnah/go-transcript/internal/audio"
 	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/desktop"
+	"github.com/alnah/go-transcript/internal/fetch"
+	"github.com/alnah/go-transcript/internal/ffmpeg"
+	"github.com/alnah/go-transcript/internal/keyring"
 	"github.com/alnah/go-transcript/internal/lang"
 	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/telegram"
 	"github.com/alnah/go-transcript/internal/template"
 	"github.com/alnah/go-transcript/internal/transcribe"
+	"github.com/alnah/go-transcript/internal/watch"
 )
 
 // ---------------------------------------------------------------------------
@@ -81,10 +93,22 @@ func (m *mockConfigLoader) LoadCalls() int {
 // ---------------------------------------------------------------------------
 
 type mockTranscriberFactory struct {
-	NewTranscriberFunc func(apiKey string) transcribe.Transcriber
+	NewTranscriberFunc      func(apiKey string) transcribe.Transcriber
+	NewLocalTranscriberFunc func(cfg LocalTranscriberConfig) (transcribe.Transcriber, error)
+	NewGRPCTranscriberFunc  func(cfg GRPCTranscriberConfig) (transcribe.Transcriber, error)
 
 	mu                  sync.Mutex
 	newTranscriberCalls []string // API keys passed
+	localConfigs        []LocalTranscriberConfig
+	grpcConfigs         []GRPCTranscriberConfig
+	azureConfigs        []AzureConfig
+	providerCalls       []providerTranscriberCall
+}
+
+// providerTranscriberCall records a NewProviderTranscriber call.
+type providerTranscriberCall struct {
+	Provider string
+	APIKey   string
 }
 
 func (m *mockTranscriberFactory) NewTranscriber(apiKey string) transcribe.Transcriber {
@@ -98,6 +122,74 @@ func (m *mockTranscriberFactory) NewTranscriber(apiKey string) transcribe.Transc
 	return &mockTranscriber{}
 }
 
+func (m *mockTranscriberFactory) NewLocalTranscriber(cfg LocalTranscriberConfig) (transcribe.Transcriber, error) {
+	m.mu.Lock()
+	m.localConfigs = append(m.localConfigs, cfg)
+	m.mu.Unlock()
+
+	if m.NewLocalTranscriberFunc != nil {
+		return m.NewLocalTranscriberFunc(cfg)
+	}
+	return &mockTranscriber{}, nil
+}
+
+func (m *mockTranscriberFactory) NewGRPCTranscriber(cfg GRPCTranscriberConfig) (transcribe.Transcriber, error) {
+	m.mu.Lock()
+	m.grpcConfigs = append(m.grpcConfigs, cfg)
+	m.mu.Unlock()
+
+	if m.NewGRPCTranscriberFunc != nil {
+		return m.NewGRPCTranscriberFunc(cfg)
+	}
+	return &mockTranscriber{}, nil
+}
+
+func (m *mockTranscriberFactory) NewAzureTranscriber(apiKey string, cfg AzureConfig) transcribe.Transcriber {
+	m.mu.Lock()
+	m.azureConfigs = append(m.azureConfigs, cfg)
+	m.mu.Unlock()
+
+	if m.NewTranscriberFunc != nil {
+		return m.NewTranscriberFunc(apiKey)
+	}
+	return &mockTranscriber{}
+}
+
+func (m *mockTranscriberFactory) NewProviderTranscriber(provider, apiKey string) (transcribe.Transcriber, error) {
+	m.mu.Lock()
+	m.providerCalls = append(m.providerCalls, providerTranscriberCall{Provider: provider, APIKey: apiKey})
+	m.mu.Unlock()
+
+	if m.NewTranscriberFunc != nil {
+		return m.NewTranscriberFunc(apiKey), nil
+	}
+	return &mockTranscriber{}, nil
+}
+
+func (m *mockTranscriberFactory) ProviderCalls() []providerTranscriberCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]providerTranscriberCall(nil), m.providerCalls...)
+}
+
+func (m *mockTranscriberFactory) AzureConfigs() []AzureConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]AzureConfig(nil), m.azureConfigs...)
+}
+
+func (m *mockTranscriberFactory) GRPCConfigs() []GRPCTranscriberConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]GRPCTranscriberConfig(nil), m.grpcConfigs...)
+}
+
+func (m *mockTranscriberFactory) LocalConfigs() []LocalTranscriberConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]LocalTranscriberConfig(nil), m.localConfigs...)
+}
+
 func (m *mockTranscriberFactory) NewTranscriberCalls() []string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -140,7 +232,7 @@ func (m *mockTranscriber) TranscribeCalls() []transcribeCall {
 // ---------------------------------------------------------------------------
 
 type mockRestructurerFactory struct {
-	NewMapReducerFunc func(provider Provider, apiKey string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error)
+	NewMapReducerFunc func(provider Provider, apiKey, model string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error)
 	NewMapReducerErr  error // Error to return from NewMapReducer
 
 	mu                 sync.Mutex
@@ -151,18 +243,55 @@ type mockRestructurerFactory struct {
 type mapReducerCall struct {
 	Provider Provider
 	APIKey   string
+	Model    string
+	Ollama   OllamaConfig // Set by NewOllamaMapReducer
+	Azure    AzureConfig  // Set by NewAzureMapReducer
+}
+
+func (m *mockRestructurerFactory) NewMapReducer(provider Provider, apiKey, model string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
+	m.mu.Lock()
+	m.newMapReducerCalls = append(m.newMapReducerCalls, mapReducerCall{Provider: provider, APIKey: apiKey, Model: model})
+	m.mu.Unlock()
+
+	if m.NewMapReducerErr != nil {
+		return nil, m.NewMapReducerErr
+	}
+	if m.NewMapReducerFunc != nil {
+		return m.NewMapReducerFunc(provider, apiKey, model, opts...)
+	}
+	if m.mockMapReducer != nil {
+		return m.mockMapReducer, nil
+	}
+	return &mockMapReduceRestructurer{}, nil
+}
+
+func (m *mockRestructurerFactory) NewOllamaMapReducer(cfg OllamaConfig, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
+	m.mu.Lock()
+	m.newMapReducerCalls = append(m.newMapReducerCalls, mapReducerCall{Provider: OllamaProvider, Ollama: cfg})
+	m.mu.Unlock()
+
+	if m.NewMapReducerErr != nil {
+		return nil, m.NewMapReducerErr
+	}
+	if m.NewMapReducerFunc != nil {
+		return m.NewMapReducerFunc(OllamaProvider, "", cfg.Model, opts...)
+	}
+	if m.mockMapReducer != nil {
+		return m.mockMapReducer, nil
+	}
+	return &mockMapReduceRestructurer{}, nil
 }
 
-func (m *mockRestructurerFactory) NewMapReducer(provider Provider, apiKey string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
+func (m *mockRestructurerFactory) NewAzureMapReducer(apiKey, model string, cfg AzureConfig, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
 	m.mu.Lock()
-	m.newMapReducerCalls = append(m.newMapReducerCalls, mapReducerCall{Provider: provider, APIKey: apiKey})
+	m.newMapReducerCalls = append(m.newMapReducerCalls, mapReducerCall{Provider: OpenAIProvider, APIKey: apiKey, Model: model, Azure: cfg})
 	m.mu.Unlock()
 
 	if m.NewMapReducerErr != nil {
 		return nil, m.NewMapReducerErr
 	}
 	if m.NewMapReducerFunc != nil {
-		return m.NewMapReducerFunc(provider, apiKey, opts...)
+		return m.NewMapReducerFunc(OpenAIProvider, apiKey, model, opts...)
 	}
 	if m.mockMapReducer != nil {
 		return m.mockMapReducer, nil
@@ -183,20 +312,28 @@ func (m *mockRestructurerFactory) NewMapReducerCalls() []mapReducerCall {
 // ---------------------------------------------------------------------------
 
 type mockChunkerFactory struct {
+	// NewSilenceChunkerFunc, if set, creates the chunkers of all strategies.
 	NewSilenceChunkerFunc func(ffmpegPath string) (audio.Chunker, error)
 
 	mu                     sync.Mutex
 	newSilenceChunkerCalls []string
+	newChunkerCalls        []mockNewChunkerCall
 	mockChunker            *mockChunker
 }
 
-func (m *mockChunkerFactory) NewSilenceChunker(ffmpegPath string) (audio.Chunker, error) {
+type mockNewChunkerCall struct {
+	strategy string
+	cfg      audio.ChunkerConfig
+}
+
+func (m *mockChunkerFactory) NewChunker(strategy string, cfg audio.ChunkerConfig) (audio.Chunker, error) {
 	m.mu.Lock()
-	m.newSilenceChunkerCalls = append(m.newSilenceChunkerCalls, ffmpegPath)
+	m.newSilenceChunkerCalls = append(m.newSilenceChunkerCalls, cfg.FFmpegPath)
+	m.newChunkerCalls = append(m.newChunkerCalls, mockNewChunkerCall{strategy: strategy, cfg: cfg})
 	m.mu.Unlock()
 
 	if m.NewSilenceChunkerFunc != nil {
-		return m.NewSilenceChunkerFunc(ffmpegPath)
+		return m.NewSilenceChunkerFunc(cfg.FFmpegPath)
 	}
 	if m.mockChunker != nil {
 		return m.mockChunker, nil
@@ -204,12 +341,19 @@ func (m *mockChunkerFactory) NewSilenceChunker(ffmpegPath string) (audio.Chunker
 	return &mockChunker{}, nil
 }
 
+// NewSilenceChunkerCalls returns the FFmpeg paths of the chunkers created.
 func (m *mockChunkerFactory) NewSilenceChunkerCalls() []string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	return append([]string(nil), m.newSilenceChunkerCalls...)
 }
 
+func (m *mockChunkerFactory) NewChunkerCalls() []mockNewChunkerCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mockNewChunkerCall(nil), m.newChunkerCalls...)
+}
+
 type mockChunker struct {
 	ChunkFunc func(ctx context.Context, audioPath string) ([]audio.Chunk, error)
 
@@ -236,6 +380,15 @@ func (m *mockChunker) Chunk(ctx context.Context, audioPath string) ([]audio.Chun
 	}, nil
 }
 
+// Plan returns the chunks of Chunk, without paths.
+func (m *mockChunker) Plan(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+	chunks, err := m.Chunk(ctx, audioPath)
+	for i := range chunks {
+		chunks[i].Path = ""
+	}
+	return chunks, err
+}
+
 func (m *mockChunker) ChunkCalls() []string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -333,10 +486,25 @@ func (m *mockRecorderFactory) NewMixRecorderCalls() []mixRecorderCall {
 }
 
 type mockRecorder struct {
-	RecordFunc func(ctx context.Context, duration time.Duration, output string) error
+	RecordFunc       func(ctx context.Context, duration time.Duration, output string) error
+	RecordStreamFunc func(ctx context.Context, duration time.Duration, output, segmentDir string, segmentDuration time.Duration) error
+
+	mu                   sync.Mutex
+	recordCalls          []recordCall
+	recordStreamCalls    []recordStreamCall
+	recordSegmentedCalls []recordStreamCall
+	levelFn              func(float64)
+	silenceStop          time.Duration
+	silenceFn            func()
+	balance              audio.MixBalance
+	tracks               [2]string
+}
 
-	mu          sync.Mutex
-	recordCalls []recordCall
+type recordStreamCall struct {
+	Duration        time.Duration
+	Output          string
+	SegmentDir      string
+	SegmentDuration time.Duration
 }
 
 type recordCall struct {
@@ -363,12 +531,127 @@ func (m *mockRecorder) RecordCalls() []recordCall {
 	return result
 }
 
+func (m *mockRecorder) RecordStream(ctx context.Context, duration time.Duration, output, segmentDir string, segmentDuration time.Duration) error {
+	m.mu.Lock()
+	m.recordStreamCalls = append(m.recordStreamCalls, recordStreamCall{
+		Duration:        duration,
+		Output:          output,
+		SegmentDir:      segmentDir,
+		SegmentDuration: segmentDuration,
+	})
+	m.mu.Unlock()
+
+	if m.RecordStreamFunc != nil {
+		return m.RecordStreamFunc(ctx, duration, output, segmentDir, segmentDuration)
+	}
+	return nil
+}
+
+func (m *mockRecorder) RecordStreamCalls() []recordStreamCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]recordStreamCall, len(m.recordStreamCalls))
+	copy(result, m.recordStreamCalls)
+	return result
+}
+
+// RecordSegmented records the call (SegmentDir is empty), and writes two
+// files and their list, as FFmpeg would.
+func (m *mockRecorder) RecordSegmented(ctx context.Context, duration time.Duration, output string, segmentDuration time.Duration) error {
+	m.mu.Lock()
+	m.recordSegmentedCalls = append(m.recordSegmentedCalls, recordStreamCall{
+		Duration:        duration,
+		Output:          output,
+		SegmentDuration: segmentDuration,
+	})
+	m.mu.Unlock()
+
+	list := "ffconcat version 1.0\n"
+	for i := range 2 {
+		path := audio.RecordingSegmentPath(output, i)
+		if err := os.WriteFile(path, []byte("fake audio data"), 0644); err != nil {
+			return err
+		}
+		list += "file " + filepath.Base(path) + "\n"
+	}
+	return os.WriteFile(audio.SegmentListPath(output), []byte(list), 0644)
+}
+
+func (m *mockRecorder) RecordSegmentedCalls() []recordStreamCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]recordStreamCall, len(m.recordSegmentedCalls))
+	copy(result, m.recordSegmentedCalls)
+	return result
+}
+
+func (m *mockRecorder) MonitorLevels(fn func(loudness float64)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.levelFn = fn
+}
+
+// emitLevel reports a loudness measurement to the monitoring callback, if any.
+func (m *mockRecorder) emitLevel(loudness float64) {
+	m.mu.Lock()
+	fn := m.levelFn
+	m.mu.Unlock()
+	if fn != nil {
+		fn(loudness)
+	}
+}
+
+func (m *mockRecorder) StopOnSilence(d time.Duration, onStop func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.silenceStop = d
+	m.silenceFn = onStop
+}
+
+func (m *mockRecorder) BalanceMix(b audio.MixBalance) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.balance = b
+}
+
+// Balance returns the levels set by BalanceMix.
+func (m *mockRecorder) Balance() audio.MixBalance {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.balance
+}
+
+func (m *mockRecorder) SeparateTracks(mic, system string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tracks = [2]string{mic, system}
+}
+
+// Tracks returns the files set by SeparateTracks.
+func (m *mockRecorder) Tracks() (mic, system string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tracks[0], m.tracks[1]
+}
+
+// stopOnSilence reports the recording as stopped by silence, as the recorder
+// does once the input stays silent for the configured duration.
+func (m *mockRecorder) stopOnSilence() {
+	m.mu.Lock()
+	fn := m.silenceFn
+	m.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Mock MapReduceRestructurer for testing restructure path
 // ---------------------------------------------------------------------------
 
 type mockMapReduceRestructurer struct {
-	RestructureFunc func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error)
+	RestructureFunc    func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error)
+	ExtractActionsFunc func(ctx context.Context, transcript string, outputLang lang.Language) ([]restructure.ActionItem, error)
 
 	mu               sync.Mutex
 	restructureCalls []mapReduceRestructureCall
@@ -395,6 +678,13 @@ func (m *mockMapReduceRestructurer) Restructure(ctx context.Context, transcript
 	return "restructured text", false, nil
 }
 
+func (m *mockMapReduceRestructurer) ExtractActions(ctx context.Context, transcript string, outputLang lang.Language) ([]restructure.ActionItem, error) {
+	if m.ExtractActionsFunc != nil {
+		return m.ExtractActionsFunc(ctx, transcript, outputLang)
+	}
+	return nil, nil
+}
+
 func (m *mockMapReduceRestructurer) RestructureCalls() []mapReduceRestructureCall {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -440,6 +730,690 @@ func (m *mockDeviceLister) ListDevices(ctx context.Context) ([]string, error) {
 	return nil, nil
 }
 
+// ---------------------------------------------------------------------------
+// Mock FingerprinterFactory + Fingerprinter
+// ---------------------------------------------------------------------------
+
+type mockFingerprinterFactory struct {
+	NewFingerprinterFunc func(ffmpegPath string) (audio.Fingerprinter, error)
+
+	mockFingerprinter *mockFingerprinter
+}
+
+func (m *mockFingerprinterFactory) NewFingerprinter(ffmpegPath string) (audio.Fingerprinter, error) {
+	if m.NewFingerprinterFunc != nil {
+		return m.NewFingerprinterFunc(ffmpegPath)
+	}
+	if m.mockFingerprinter != nil {
+		return m.mockFingerprinter, nil
+	}
+	return &mockFingerprinter{}, nil
+}
+
+type extractCall struct {
+	AudioPath  string
+	OutputPath string
+	Start, End time.Duration
+}
+
+type mockFingerprinter struct {
+	DurationFunc    func(ctx context.Context, audioPath string) (time.Duration, error)
+	FingerprintFunc func(ctx context.Context, audioPath string, start, length time.Duration) (audio.Fingerprint, error)
+	ExtractFunc     func(ctx context.Context, audioPath, outputPath string, start, end time.Duration) error
+
+	mu           sync.Mutex
+	extractCalls []extractCall
+}
+
+func (m *mockFingerprinter) Duration(ctx context.Context, audioPath string) (time.Duration, error) {
+	if m.DurationFunc != nil {
+		return m.DurationFunc(ctx, audioPath)
+	}
+	return 10 * time.Minute, nil
+}
+
+func (m *mockFingerprinter) Fingerprint(ctx context.Context, audioPath string, start, length time.Duration) (audio.Fingerprint, error) {
+	if m.FingerprintFunc != nil {
+		return m.FingerprintFunc(ctx, audioPath, start, length)
+	}
+	return nil, nil
+}
+
+// Extract writes a placeholder file by default, so that the piece can be chunked.
+func (m *mockFingerprinter) Extract(ctx context.Context, audioPath, outputPath string, start, end time.Duration) error {
+	m.mu.Lock()
+	m.extractCalls = append(m.extractCalls, extractCall{audioPath, outputPath, start, end})
+	m.mu.Unlock()
+
+	if m.ExtractFunc != nil {
+		return m.ExtractFunc(ctx, audioPath, outputPath, start, end)
+	}
+	return os.WriteFile(outputPath, []byte("piece"), 0600)
+}
+
+func (m *mockFingerprinter) ExtractCalls() []extractCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]extractCall(nil), m.extractCalls...)
+}
+
+// ---------------------------------------------------------------------------
+// Mock AudioExtractorFactory + AudioExtractor
+// ---------------------------------------------------------------------------
+
+type mockAudioExtractorFactory struct {
+	NewAudioExtractorFunc func(ffmpegPath string) (audio.AudioExtractor, error)
+
+	mockAudioExtractor *mockAudioExtractor
+}
+
+func (m *mockAudioExtractorFactory) NewAudioExtractor(ffmpegPath string) (audio.AudioExtractor, error) {
+	if m.NewAudioExtractorFunc != nil {
+		return m.NewAudioExtractorFunc(ffmpegPath)
+	}
+	if m.mockAudioExtractor != nil {
+		return m.mockAudioExtractor, nil
+	}
+	return &mockAudioExtractor{}, nil
+}
+
+type mockAudioExtractor struct {
+	ExtractAudioFunc func(ctx context.Context, videoPath, outputPath string, onProgress func(done, total time.Duration)) error
+}
+
+// ExtractAudio writes a placeholder file by default, so that it can be chunked.
+func (m *mockAudioExtractor) ExtractAudio(ctx context.Context, videoPath, outputPath string, onProgress func(done, total time.Duration)) error {
+	if m.ExtractAudioFunc != nil {
+		return m.ExtractAudioFunc(ctx, videoPath, outputPath, onProgress)
+	}
+	return os.WriteFile(outputPath, []byte("extracted audio"), 0600)
+}
+
+// ---------------------------------------------------------------------------
+// Mock RepairerFactory + Repairer
+// ---------------------------------------------------------------------------
+
+type mockRepairerFactory struct {
+	NewRepairerFunc func(ffmpegPath string) (audio.Repairer, error)
+
+	mockRepairer *mockRepairer
+}
+
+func (m *mockRepairerFactory) NewRepairer(ffmpegPath string) (audio.Repairer, error) {
+	if m.NewRepairerFunc != nil {
+		return m.NewRepairerFunc(ffmpegPath)
+	}
+	if m.mockRepairer != nil {
+		return m.mockRepairer, nil
+	}
+	return &mockRepairer{}, nil
+}
+
+type mockRepairer struct {
+	RepairFunc func(ctx context.Context, inputPath, outputPath string) error
+
+	mu      sync.Mutex
+	repairs []string // Input paths
+}
+
+// Repair copies inputPath to outputPath by default, as a remux would.
+func (m *mockRepairer) Repair(ctx context.Context, inputPath, outputPath string) error {
+	m.mu.Lock()
+	m.repairs = append(m.repairs, inputPath)
+	m.mu.Unlock()
+
+	if m.RepairFunc != nil {
+		return m.RepairFunc(ctx, inputPath, outputPath)
+	}
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, data, 0600)
+}
+
+func (m *mockRepairer) Repairs() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.repairs...)
+}
+
+// ---------------------------------------------------------------------------
+// Mock PreprocessorFactory + Preprocessor
+// ---------------------------------------------------------------------------
+
+type mockPreprocessorFactory struct {
+	NewPreprocessorFunc func(ffmpegPath string) (audio.Preprocessor, error)
+
+	mockPreprocessor *mockPreprocessor
+}
+
+func (m *mockPreprocessorFactory) NewPreprocessor(ffmpegPath string) (audio.Preprocessor, error) {
+	if m.NewPreprocessorFunc != nil {
+		return m.NewPreprocessorFunc(ffmpegPath)
+	}
+	if m.mockPreprocessor != nil {
+		return m.mockPreprocessor, nil
+	}
+	return &mockPreprocessor{}, nil
+}
+
+type preprocessCall struct {
+	inputPath string
+	p         audio.Preprocessing
+}
+
+type mockPreprocessor struct {
+	PreprocessFunc func(ctx context.Context, inputPath, outputPath string, p audio.Preprocessing, onProgress func(done, total time.Duration)) error
+
+	mu    sync.Mutex
+	calls []preprocessCall
+}
+
+// Preprocess copies inputPath to outputPath by default, as a filter would.
+func (m *mockPreprocessor) Preprocess(ctx context.Context, inputPath, outputPath string, p audio.Preprocessing, onProgress func(done, total time.Duration)) error {
+	m.mu.Lock()
+	m.calls = append(m.calls, preprocessCall{inputPath: inputPath, p: p})
+	m.mu.Unlock()
+
+	if m.PreprocessFunc != nil {
+		return m.PreprocessFunc(ctx, inputPath, outputPath, p, onProgress)
+	}
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, data, 0600)
+}
+
+func (m *mockPreprocessor) Calls() []preprocessCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]preprocessCall(nil), m.calls...)
+}
+
+// ---------------------------------------------------------------------------
+// Mock MediaProberFactory + MediaProber
+// ---------------------------------------------------------------------------
+
+type mockMediaProberFactory struct {
+	NewMediaProberFunc func(ffmpegPath string) (MediaProber, error)
+
+	mockMediaProber *mockMediaProber
+}
+
+func (m *mockMediaProberFactory) NewMediaProber(ffmpegPath string) (MediaProber, error) {
+	if m.NewMediaProberFunc != nil {
+		return m.NewMediaProberFunc(ffmpegPath)
+	}
+	if m.mockMediaProber != nil {
+		return m.mockMediaProber, nil
+	}
+	return &mockMediaProber{}, nil
+}
+
+type mockMediaProber struct {
+	ProbeFunc       func(ctx context.Context, path string) (ffmpeg.Metadata, error)
+	VerifyAudioFunc func(ctx context.Context, path string) error
+
+	mu     sync.Mutex
+	probed []string
+}
+
+// Probe returns 10 minutes of mono Opus by default.
+func (m *mockMediaProber) Probe(ctx context.Context, path string) (ffmpeg.Metadata, error) {
+	m.mu.Lock()
+	m.probed = append(m.probed, path)
+	m.mu.Unlock()
+
+	if m.ProbeFunc != nil {
+		return m.ProbeFunc(ctx, path)
+	}
+	return ffmpeg.Metadata{
+		Format:       "ogg",
+		Duration:     10 * time.Minute,
+		Codec:        "opus",
+		Channels:     1,
+		SampleRate:   48000,
+		AudioStreams: 1,
+	}, nil
+}
+
+func (m *mockMediaProber) VerifyAudio(ctx context.Context, path string) error {
+	if m.VerifyAudioFunc != nil {
+		return m.VerifyAudioFunc(ctx, path)
+	}
+	return nil
+}
+
+func (m *mockMediaProber) Probed() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.probed...)
+}
+
+// ---------------------------------------------------------------------------
+// Mock DownloaderFactory + Downloader
+// ---------------------------------------------------------------------------
+
+type mockDownloaderFactory struct {
+	mu       sync.Mutex
+	maxSizes []int64
+
+	mockDownloader *mockDownloader
+}
+
+func (m *mockDownloaderFactory) NewDownloader(maxSize int64) fetch.Downloader {
+	m.mu.Lock()
+	m.maxSizes = append(m.maxSizes, maxSize)
+	m.mu.Unlock()
+	if m.mockDownloader != nil {
+		return m.mockDownloader
+	}
+	return &mockDownloader{}
+}
+
+// MaxSizes returns the size limit of each downloader created.
+func (m *mockDownloaderFactory) MaxSizes() []int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]int64(nil), m.maxSizes...)
+}
+
+type mockDownloader struct {
+	DownloadFunc func(ctx context.Context, rawURL, dir string, onProgress func(done, total int64)) (string, error)
+}
+
+// Download writes a placeholder file named after the URL by default.
+func (m *mockDownloader) Download(ctx context.Context, rawURL, dir string, onProgress func(done, total int64)) (string, error) {
+	if m.DownloadFunc != nil {
+		return m.DownloadFunc(ctx, rawURL, dir, onProgress)
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", err
+	}
+	out := filepath.Join(dir, path.Base(rawURL))
+	return out, os.WriteFile(out, []byte("downloaded audio"), 0600)
+}
+
+// ---------------------------------------------------------------------------
+// Mock LevelMeterFactory + LevelMeter
+// ---------------------------------------------------------------------------
+
+type mockLevelMeterFactory struct {
+	NewLevelMeterFunc func(ffmpegPath string) (audio.LevelMeter, error)
+
+	mockLevelMeter *mockLevelMeter
+}
+
+func (m *mockLevelMeterFactory) NewLevelMeter(ffmpegPath string) (audio.LevelMeter, error) {
+	if m.NewLevelMeterFunc != nil {
+		return m.NewLevelMeterFunc(ffmpegPath)
+	}
+	if m.mockLevelMeter != nil {
+		return m.mockLevelMeter, nil
+	}
+	return &mockLevelMeter{}, nil
+}
+
+type mockLevelMeter struct {
+	MeasureLevelsFunc func(ctx context.Context, audioPath string) (audio.Levels, error)
+}
+
+func (m *mockLevelMeter) MeasureLevels(ctx context.Context, audioPath string) (audio.Levels, error) {
+	if m.MeasureLevelsFunc != nil {
+		return m.MeasureLevelsFunc(ctx, audioPath)
+	}
+	return audio.Levels{Peak: -6, Mean: -24}, nil
+}
+
+// ---------------------------------------------------------------------------
+// Mock BotFactory
+// ---------------------------------------------------------------------------
+
+type mockBotFactory struct {
+	Bot *mockTelegramBot
+	Err error
+
+	mu     sync.Mutex
+	tokens []string
+}
+
+func (m *mockBotFactory) NewTelegramBot(token string) (telegram.Bot, error) {
+	m.mu.Lock()
+	m.tokens = append(m.tokens, token)
+	m.mu.Unlock()
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return m.Bot, nil
+}
+
+// ---------------------------------------------------------------------------
+// Mock telegram.Bot
+// ---------------------------------------------------------------------------
+
+// sentMessage is a message or document sent by the bot.
+type sentMessage struct {
+	chatID   int64
+	text     string // Message text, or document content
+	document string // Document file name, empty for messages
+}
+
+// mockTelegramBot returns each of Batches once from Updates, then blocks until the
+// context is canceled. Files are downloaded with the content "audio".
+type mockTelegramBot struct {
+	Batches    [][]telegram.Update
+	UpdatesErr error             // Returned by every Updates call if set
+	FilePaths  map[string]string // File ID -> path on the Telegram servers
+
+	mu   sync.Mutex
+	sent []sentMessage
+}
+
+func (m *mockTelegramBot) Updates(ctx context.Context, offset int64) ([]telegram.Update, error) {
+	m.mu.Lock()
+	if m.UpdatesErr != nil {
+		m.mu.Unlock()
+		return nil, m.UpdatesErr
+	}
+	if len(m.Batches) > 0 {
+		batch := m.Batches[0]
+		m.Batches = m.Batches[1:]
+		m.mu.Unlock()
+		return batch, nil
+	}
+	m.mu.Unlock()
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (m *mockTelegramBot) Download(ctx context.Context, fileID string, w io.Writer) (string, error) {
+	_, err := io.WriteString(w, "audio")
+	return m.FilePaths[fileID], err
+}
+
+func (m *mockTelegramBot) SendMessage(ctx context.Context, chatID int64, text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, sentMessage{chatID: chatID, text: text})
+	return nil
+}
+
+func (m *mockTelegramBot) SendDocument(ctx context.Context, chatID int64, name string, content []byte, caption string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, sentMessage{chatID: chatID, text: string(content), document: name})
+	return nil
+}
+
+// Sent returns the messages and documents sent so far.
+func (m *mockTelegramBot) Sent() []sentMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]sentMessage(nil), m.sent...)
+}
+
+// ---------------------------------------------------------------------------
+// Mock WatcherFactory + Watcher
+// ---------------------------------------------------------------------------
+
+type mockWatcherFactory struct {
+	mu      sync.Mutex
+	settles []time.Duration
+
+	mockWatcher *mockWatcher
+}
+
+func (m *mockWatcherFactory) NewWatcher(settle time.Duration) watch.Watcher {
+	m.mu.Lock()
+	m.settles = append(m.settles, settle)
+	m.mu.Unlock()
+	if m.mockWatcher != nil {
+		return m.mockWatcher
+	}
+	return &mockWatcher{}
+}
+
+// Settles returns the settle delay of each watcher created.
+func (m *mockWatcherFactory) Settles() []time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]time.Duration(nil), m.settles...)
+}
+
+// mockWatcher reports the matching Files, in order, then stops as if the
+// directory were no longer watched.
+type mockWatcher struct {
+	Files     []string
+	WatchFunc func(ctx context.Context, dir string, match func(path string) bool, ready func(path string)) error
+}
+
+func (m *mockWatcher) Watch(ctx context.Context, dir string, match func(path string) bool, ready func(path string)) error {
+	if m.WatchFunc != nil {
+		return m.WatchFunc(ctx, dir, match, ready)
+	}
+	for _, f := range m.Files {
+		if match(f) {
+			ready(f)
+		}
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Mock Notifier
+// ---------------------------------------------------------------------------
+
+// desktopNotification is a notification shown by mockNotifier.
+type desktopNotification struct {
+	Title   string
+	Message string
+}
+
+type mockNotifier struct {
+	mu            sync.Mutex
+	notifications []desktopNotification
+
+	NotifyFunc func(ctx context.Context, title, message string) error
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, title, message string) error {
+	m.mu.Lock()
+	m.notifications = append(m.notifications, desktopNotification{Title: title, Message: message})
+	m.mu.Unlock()
+	if m.NotifyFunc != nil {
+		return m.NotifyFunc(ctx, title, message)
+	}
+	return nil
+}
+
+// Notifications returns the notifications shown so far.
+func (m *mockNotifier) Notifications() []desktopNotification {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]desktopNotification(nil), m.notifications...)
+}
+
+// ---------------------------------------------------------------------------
+// Mock Prober
+// ---------------------------------------------------------------------------
+
+type mockProber struct {
+	LookupFFmpegFunc   func(ctx context.Context) (string, string, error)
+	VerifyAPIKeyFunc   func(ctx context.Context, provider Provider, key string) error
+	DetectLoopbackFunc func(ctx context.Context, ffmpegPath string) (string, error)
+
+	mu       sync.Mutex
+	verified []Provider
+}
+
+func (m *mockProber) LookupFFmpeg(ctx context.Context) (string, string, error) {
+	if m.LookupFFmpegFunc != nil {
+		return m.LookupFFmpegFunc(ctx)
+	}
+	return "/usr/bin/ffmpeg", "6.1.1", nil
+}
+
+func (m *mockProber) VerifyAPIKey(ctx context.Context, provider Provider, key string) error {
+	m.mu.Lock()
+	m.verified = append(m.verified, provider)
+	m.mu.Unlock()
+	if m.VerifyAPIKeyFunc != nil {
+		return m.VerifyAPIKeyFunc(ctx, provider, key)
+	}
+	return nil
+}
+
+func (m *mockProber) DetectLoopback(ctx context.Context, ffmpegPath string) (string, error) {
+	if m.DetectLoopbackFunc != nil {
+		return m.DetectLoopbackFunc(ctx, ffmpegPath)
+	}
+	return "BlackHole 2ch", nil
+}
+
+// Verified returns the providers whose key was verified so far.
+func (m *mockProber) Verified() []Provider {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Provider(nil), m.verified...)
+}
+
+// ---------------------------------------------------------------------------
+// Mock FFmpegInstaller
+// ---------------------------------------------------------------------------
+
+// mockFFmpegInstaller reports the pinned FFmpeg downloaded by default.
+type mockFFmpegInstaller struct {
+	StatusFunc  func() (ffmpeg.Status, error)
+	UpgradeFunc func(ctx context.Context) (bool, error)
+
+	mu       sync.Mutex
+	upgrades int
+}
+
+func (m *mockFFmpegInstaller) Status() (ffmpeg.Status, error) {
+	if m.StatusFunc != nil {
+		return m.StatusFunc()
+	}
+	return ffmpeg.Status{
+		Path:       "/cache/go-transcript/bin/ffmpeg",
+		Source:     ffmpeg.SourceDownloaded,
+		InstallDir: "/cache/go-transcript/bin",
+		Installed:  "6.1.1",
+		Pinned:     "6.1.1",
+		Platform:   "linux-amd64",
+		Supported:  true,
+	}, nil
+}
+
+func (m *mockFFmpegInstaller) Upgrade(ctx context.Context) (bool, error) {
+	m.mu.Lock()
+	m.upgrades++
+	m.mu.Unlock()
+	if m.UpgradeFunc != nil {
+		return m.UpgradeFunc(ctx)
+	}
+	return true, nil
+}
+
+func (m *mockFFmpegInstaller) Version(ctx context.Context, ffmpegPath string) (string, error) {
+	return "6.1.1", nil
+}
+
+// Upgrades returns the number of Upgrade calls so far.
+func (m *mockFFmpegInstaller) Upgrades() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.upgrades
+}
+
+// ---------------------------------------------------------------------------
+// Mock TerminalFactory
+// ---------------------------------------------------------------------------
+
+// mockTerminalFactory opens a terminal typing Keys, 80x24. With Err set,
+// opening fails with it.
+type mockTerminalFactory struct {
+	Keys string
+	Err  error
+
+	terminal *mockTerminal
+}
+
+func (m *mockTerminalFactory) OpenTerminal() (Terminal, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	m.terminal = &mockTerminal{keys: strings.NewReader(m.Keys)}
+	return m.terminal, nil
+}
+
+// mockTerminal records the screen drawn and whether it was closed.
+type mockTerminal struct {
+	keys   io.Reader
+	screen syncBuffer
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func (m *mockTerminal) Read(p []byte) (int, error)  { return m.keys.Read(p) }
+func (m *mockTerminal) Write(p []byte) (int, error) { return m.screen.Write(p) }
+func (m *mockTerminal) Size() (width, height int)   { return 80, 24 }
+
+func (m *mockTerminal) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+// Closed reports whether the terminal was restored.
+func (m *mockTerminal) Closed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closed
+}
+
+// ---------------------------------------------------------------------------
+// Mock Keyring
+// ---------------------------------------------------------------------------
+
+// mockKeyring holds secrets in memory. With Err set, every call fails with it.
+type mockKeyring struct {
+	Err error
+
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+func (m *mockKeyring) Get(name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return "", m.Err
+	}
+	secret, ok := m.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", keyring.ErrNotFound, name)
+	}
+	return secret, nil
+}
+
+func (m *mockKeyring) Set(name, secret string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if m.secrets == nil {
+		m.secrets = map[string]string{}
+	}
+	m.secrets[name] = secret
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // Compile-time interface verification
 // ---------------------------------------------------------------------------
@@ -455,6 +1429,34 @@ var (
 	_ audio.Chunker          = (*mockChunker)(nil)
 	_ RecorderFactory        = (*mockRecorderFactory)(nil)
 	_ audio.Recorder         = (*mockRecorder)(nil)
+	_ audio.LevelMonitor     = (*mockRecorder)(nil)
+	_ audio.SilenceStopper   = (*mockRecorder)(nil)
+	_ audio.MixBalancer      = (*mockRecorder)(nil)
+	_ audio.Planner          = (*mockChunker)(nil)
 	_ DeviceListerFactory    = (*mockDeviceListerFactory)(nil)
 	_ audio.DeviceLister     = (*mockDeviceLister)(nil)
+	_ FingerprinterFactory   = (*mockFingerprinterFactory)(nil)
+	_ audio.Fingerprinter    = (*mockFingerprinter)(nil)
+	_ AudioExtractorFactory  = (*mockAudioExtractorFactory)(nil)
+	_ audio.AudioExtractor   = (*mockAudioExtractor)(nil)
+	_ RepairerFactory        = (*mockRepairerFactory)(nil)
+	_ audio.Repairer         = (*mockRepairer)(nil)
+	_ PreprocessorFactory    = (*mockPreprocessorFactory)(nil)
+	_ MediaProberFactory     = (*mockMediaProberFactory)(nil)
+	_ MediaProber            = (*mockMediaProber)(nil)
+	_ audio.Preprocessor     = (*mockPreprocessor)(nil)
+	_ DownloaderFactory      = (*mockDownloaderFactory)(nil)
+	_ fetch.Downloader       = (*mockDownloader)(nil)
+	_ LevelMeterFactory      = (*mockLevelMeterFactory)(nil)
+	_ audio.LevelMeter       = (*mockLevelMeter)(nil)
+	_ BotFactory             = (*mockBotFactory)(nil)
+	_ telegram.Bot           = (*mockTelegramBot)(nil)
+	_ WatcherFactory         = (*mockWatcherFactory)(nil)
+	_ watch.Watcher          = (*mockWatcher)(nil)
+	_ desktop.Notifier       = (*mockNotifier)(nil)
+	_ keyring.Keyring        = (*mockKeyring)(nil)
+	_ Prober                 = (*mockProber)(nil)
+	_ FFmpegInstaller        = (*mockFFmpegInstaller)(nil)
+	_ TerminalFactory        = (*mockTerminalFactory)(nil)
+	_ Terminal               = (*mockTerminal)(nil)
 )
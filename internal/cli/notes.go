@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/notes"
+)
+
+// outputNamer returns the function naming the default output of an input,
+// from its basename: by the output-name pattern of cfg when set, with fields
+// and the input stem, ending with ext; else by fallback.
+// Returns notes.ErrInvalidName if the pattern is invalid.
+func outputNamer(cfg config.Config, fields notes.NameFields, ext string, fallback func(base string) string) (func(base string) string, error) {
+	if cfg.OutputName == "" {
+		return fallback, nil
+	}
+	pattern, err := notes.ParseName(cfg.OutputName)
+	if err != nil {
+		return nil, err
+	}
+	return func(base string) string {
+		fields := fields
+		fields.InputStem = strings.TrimSuffix(base, filepath.Ext(base))
+		return pattern.Expand(fields, ext)
+	}, nil
+}
+
+// addFrontMatter puts the front matter m at the top of content, an output of
+// format f written to output with mode, when enabled (the front-matter
+// setting). Only Markdown outputs get it, and not when appended to a file that
+// already has content (--append): front matter belongs at the top of a file.
+func addFrontMatter(enabled bool, content, output string, mode outputMode, f OutputFormat, m notes.FrontMatter) string {
+	if !enabled || f.OrDefault() != MarkdownFormat {
+		return content
+	}
+	if appendsToContent(output, mode) {
+		return content
+	}
+	return m.Prepend(content)
+}
+
+// appendsToContent reports whether writing to output with mode appends to a
+// file that already has content.
+func appendsToContent(output string, mode outputMode) bool {
+	if mode != outputAppend {
+		return false
+	}
+	size, err := fileSize(output)
+	return err == nil && size > 0
+}
+
+// audioDuration returns the length of the audio split into chunks: the end
+// of the last one.
+func audioDuration(chunks []audio.Chunk) time.Duration {
+	var d time.Duration
+	for _, c := range chunks {
+		d = max(d, c.EndTime)
+	}
+	return d
+}
+
+// absPath returns path made absolute for front matter, or path itself if it
+// cannot be, or is empty.
+func absPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
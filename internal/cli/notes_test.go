@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/notes"
+)
+
+func TestAddFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "journal.md")
+	if err := os.WriteFile(existing, []byte("Monday.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty.md")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := notes.FrontMatter{Language: "en"}
+	withFrontMatter := "---\nlanguage: en\n---\n\nHello."
+
+	tests := []struct {
+		name    string
+		enabled bool
+		output  string
+		mode    outputMode
+		f       OutputFormat
+		want    string
+	}{
+		{"markdown", true, filepath.Join(dir, "new.md"), outputCreate, MarkdownFormat, withFrontMatter},
+		{"disabled", false, filepath.Join(dir, "new.md"), outputCreate, MarkdownFormat, "Hello."},
+		{"plain text", true, filepath.Join(dir, "new.txt"), outputCreate, TextFormat, "Hello."},
+		{"replacing a file", true, existing, outputForce, MarkdownFormat, withFrontMatter},
+		{"appending to a file", true, existing, outputAppend, MarkdownFormat, "Hello."},
+		{"appending to an empty file", true, empty, outputAppend, MarkdownFormat, withFrontMatter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := addFrontMatter(tt.enabled, "Hello.", tt.output, tt.mode, tt.f, m); got != tt.want {
+				t.Errorf("addFrontMatter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/desktop"
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// notifyWebhookFlagHelp describes the --notify-webhook flag of the transcribe,
+// live and watch commands.
+const notifyWebhookFlagHelp = "POST a JSON notification to this URL when the transcription finishes (default: config notify-webhook)"
+
+// notifyFlagHelp describes the --notify flag of the transcribe and live commands.
+const notifyFlagHelp = "Show a desktop notification when the transcription finishes or fails"
+
+// notifyTimeout bounds a webhook notification, so that an unreachable
+// endpoint does not hold the end of a run.
+const notifyTimeout = 10 * time.Second
+
+// webhookNotification is the JSON body POSTed to the webhook when a run finishes.
+// Text and Content hold the same summary line, displayed by Slack (and
+// Mattermost) and by Discord incoming webhooks respectively.
+type webhookNotification struct {
+	Text            string  `json:"text"`
+	Content         string  `json:"content"`
+	Command         string  `json:"command"`
+	Input           string  `json:"input,omitempty"` // Empty for live
+	Output          string  `json:"output"`
+	Success         bool    `json:"success"`
+	Error           string  `json:"error,omitempty"`
+	Code            string  `json:"code,omitempty"` // Error code (see transcript explain)
+	Started         string  `json:"started"`
+	Finished        string  `json:"finished"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	CostUSD         float64 `json:"cost_usd"` // Estimated; models with unknown prices count as zero
+}
+
+// parseNotifyWebhook validates a --notify-webhook value. Empty is allowed.
+func parseNotifyWebhook(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	return config.ParseWebhookURL(s)
+}
+
+// notifyWebhookURL returns the webhook notified at the end of a run: the
+// flag value, or the configured one.
+func notifyWebhookURL(flag string, cfg config.Config) string {
+	if flag != "" {
+		return flag
+	}
+	return cfg.NotifyWebhook
+}
+
+// newWebhookNotification returns the notification of a command run from
+// started to finished, with the usage of r (nil if unknown) and the error
+// the run returned.
+func newWebhookNotification(command, input, output string, started, finished time.Time, r *runReport, err error) webhookNotification {
+	n := webhookNotification{
+		Command:         command,
+		Input:           input,
+		Output:          output,
+		Success:         err == nil,
+		Started:         started.UTC().Format(time.RFC3339),
+		Finished:        finished.UTC().Format(time.RFC3339),
+		DurationSeconds: finished.Sub(started).Round(time.Second).Seconds(),
+	}
+	if r != nil {
+		transcriptionCost, _ := r.transcriptionCost()
+		restructureCost, _ := r.restructureCost()
+		n.CostUSD = roundCost(transcriptionCost + restructureCost)
+	}
+
+	duration := format.DurationHuman(finished.Sub(started).Round(time.Second))
+	if err != nil {
+		n.Error = err.Error()
+		if info, ok := LookupError(err); ok {
+			n.Code = info.Code
+		}
+		subject := input
+		if subject == "" {
+			subject = output
+		}
+		n.Text = fmt.Sprintf("transcript %s failed after %s: %s: %v", command, duration, subject, err)
+	} else {
+		n.Text = fmt.Sprintf("transcript %s done in %s (~$%.2f): %s", command, duration, n.CostUSD, output)
+	}
+	n.Content = n.Text
+	return n
+}
+
+// notifyWebhook POSTs n to webhookURL, if set. Failing only warns: the run is
+// already over. The notification is sent even if ctx was canceled (e.g. an
+// interrupted run), within notifyTimeout.
+func notifyWebhook(ctx context.Context, stderr io.Writer, webhookURL string, n webhookNotification) {
+	if webhookURL == "" {
+		return
+	}
+	if err := postWebhook(context.WithoutCancel(ctx), webhookURL, n); err != nil {
+		warnf(stderr, warnNotifyFailed, "failed to send webhook notification: %v", err)
+	}
+}
+
+// postWebhook POSTs n as JSON to webhookURL. Errors leave the URL out, as
+// webhook URLs usually embed a secret token.
+func postWebhook(ctx context.Context, webhookURL string, n webhookNotification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Drain to reuse the connection
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// desktopNotice returns the title and message of the desktop notification of
+// a command run on subject (a file name) for elapsed, that returned err.
+func desktopNotice(command, subject string, elapsed time.Duration, err error) (title, message string) {
+	duration := format.DurationHuman(elapsed.Round(time.Second))
+	if err != nil {
+		return fmt.Sprintf("transcript %s failed", command), fmt.Sprintf("%s after %s: %v", filepath.Base(subject), duration, err)
+	}
+	return fmt.Sprintf("transcript %s done", command), fmt.Sprintf("%s in %s", filepath.Base(subject), duration)
+}
+
+// notifyDesktop shows a desktop notification with n. Failing only warns: the
+// run is already over. The notification is shown even if ctx was canceled.
+func notifyDesktop(ctx context.Context, stderr io.Writer, n desktop.Notifier, title, message string) {
+	if err := n.Notify(context.WithoutCancel(ctx), title, message); err != nil {
+		warnf(stderr, warnDesktopNotifyFailed, "failed to show desktop notification: %v", err)
+	}
+}
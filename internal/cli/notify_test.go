@@ -0,0 +1,358 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/desktop"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// webhookRecorder is a webhook endpoint recording the notifications it receives.
+type webhookRecorder struct {
+	mu            sync.Mutex
+	notifications []webhookNotification
+	contentTypes  []string
+}
+
+// startWebhook starts a webhook endpoint responding with status.
+func startWebhook(t *testing.T, status int) (*webhookRecorder, string) {
+	t.Helper()
+	rec := &webhookRecorder{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n webhookNotification
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&n) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rec.mu.Lock()
+		rec.notifications = append(rec.notifications, n)
+		rec.contentTypes = append(rec.contentTypes, r.Header.Get("Content-Type"))
+		rec.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return rec, srv.URL + "/hooks/secret-token"
+}
+
+// received returns the notifications received so far.
+func (r *webhookRecorder) received() []webhookNotification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]webhookNotification(nil), r.notifications...)
+}
+
+func TestNewWebhookNotification(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2026, 1, 26, 14, 0, 0, 0, time.UTC)
+	finished := started.Add(2*time.Minute + 5*time.Second)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		report := newRunReport("transcribe", "talk.ogg", "talk.md")
+		report.restructureModel = "deepseek-chat"
+		report.restructure.PromptTokens = 1_000_000
+
+		n := newWebhookNotification("transcribe", "talk.ogg", "talk.md", started, finished, report, nil)
+		if !n.Success || n.Error != "" || n.Code != "" {
+			t.Errorf("notification = %+v, want a success", n)
+		}
+		if n.Started != "2026-01-26T14:00:00Z" || n.Finished != "2026-01-26T14:02:05Z" || n.DurationSeconds != 125 {
+			t.Errorf("notification times = %s, %s, %vs, want the run times", n.Started, n.Finished, n.DurationSeconds)
+		}
+		if n.CostUSD <= 0 {
+			t.Errorf("CostUSD = %v, want the restructuring cost", n.CostUSD)
+		}
+		if !strings.Contains(n.Text, "done") || !strings.Contains(n.Text, "talk.md") || n.Content != n.Text {
+			t.Errorf("Text = %q, Content = %q, want the same summary naming the output", n.Text, n.Content)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+
+		err := fmt.Errorf("%w: notes.ogg", ErrFileNotFound)
+		n := newWebhookNotification("live", "", "notes.md", started, finished, nil, err)
+		if n.Success || n.Error == "" || n.Code == "" {
+			t.Errorf("notification = %+v, want a failure with its code", n)
+		}
+		if n.CostUSD != 0 || n.Input != "" {
+			t.Errorf("notification = %+v, want no cost nor input", n)
+		}
+		if !strings.Contains(n.Text, "failed") || !strings.Contains(n.Text, "notes.md") {
+			t.Errorf("Text = %q, want a failure naming the output", n.Text)
+		}
+	})
+}
+
+func TestNotifyWebhook(t *testing.T) {
+	t.Parallel()
+
+	n := webhookNotification{Command: "transcribe", Output: "talk.md", Success: true}
+
+	t.Run("posts JSON", func(t *testing.T) {
+		t.Parallel()
+
+		rec, url := startWebhook(t, http.StatusNoContent)
+		stderr := &syncBuffer{}
+		notifyWebhook(context.Background(), stderr, url, n)
+
+		got := rec.received()
+		if len(got) != 1 || got[0].Output != "talk.md" {
+			t.Fatalf("notifications = %+v, want the notification", got)
+		}
+		if rec.contentTypes[0] != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", rec.contentTypes[0])
+		}
+		if stderr.String() != "" {
+			t.Errorf("stderr = %q, want nothing", stderr.String())
+		}
+	})
+
+	t.Run("sent after cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		rec, url := startWebhook(t, http.StatusOK)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		notifyWebhook(ctx, &syncBuffer{}, url, n)
+		if len(rec.received()) != 1 {
+			t.Error("notification not sent once the run context is canceled")
+		}
+	})
+
+	t.Run("rejected notification warns", func(t *testing.T) {
+		t.Parallel()
+
+		_, url := startWebhook(t, http.StatusNotFound)
+		stderr := &syncBuffer{}
+		notifyWebhook(context.Background(), stderr, url, n)
+		if !strings.Contains(stderr.String(), warnNotifyFailed) || !strings.Contains(stderr.String(), "404") {
+			t.Errorf("stderr = %q, want a warning with the status", stderr.String())
+		}
+		if strings.Contains(stderr.String(), "secret-token") {
+			t.Errorf("stderr = %q, want the URL left out", stderr.String())
+		}
+	})
+
+	t.Run("unreachable endpoint warns without the URL", func(t *testing.T) {
+		t.Parallel()
+
+		url := "http://127.0.0.1:1/hooks/secret-token" // Nothing listens on port 1
+		stderr := &syncBuffer{}
+		notifyWebhook(context.Background(), stderr, url, n)
+		if !strings.Contains(stderr.String(), warnNotifyFailed) || strings.Contains(stderr.String(), "secret-token") {
+			t.Errorf("stderr = %q, want a warning without the URL", stderr.String())
+		}
+	})
+
+	t.Run("no URL does nothing", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		notifyWebhook(context.Background(), stderr, "", n)
+		if stderr.String() != "" {
+			t.Errorf("stderr = %q, want nothing", stderr.String())
+		}
+	})
+}
+
+func TestRunTranscribe_NotifyWebhook(t *testing.T) {
+	t.Parallel()
+
+	t.Run("flag", func(t *testing.T) {
+		t.Parallel()
+
+		rec, url := startWebhook(t, http.StatusOK)
+		env, _ := testEnv()
+		inputPath := createTestAudioFile(t, "talk.ogg")
+		output := filepath.Join(t.TempDir(), "talk.md")
+		opts := mustParseTranscribeOptions(t, inputPath, output, "", false, 2, "", "", "deepseek")
+		opts.notifyWebhook = url
+
+		if err := runTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+			t.Fatalf("runTranscribe() unexpected error: %v", err)
+		}
+		got := rec.received()
+		if len(got) != 1 {
+			t.Fatalf("received %d notifications, want 1", len(got))
+		}
+		n := got[0]
+		if n.Command != "transcribe" || n.Input != inputPath || n.Output != output || !n.Success {
+			t.Errorf("notification = %+v, want the success of the run", n)
+		}
+	})
+
+	t.Run("config on failure", func(t *testing.T) {
+		t.Parallel()
+
+		rec, url := startWebhook(t, http.StatusOK)
+		env, mocks := testEnv()
+		mocks.configLoader.LoadFunc = func() (config.Config, error) {
+			return config.Config{NotifyWebhook: url}, nil
+		}
+		mocks.chunker.mockChunker = &mockChunker{
+			ChunkFunc: func(context.Context, string) ([]audio.Chunk, error) {
+				return nil, audio.ErrChunkingFailed
+			},
+		}
+		inputPath := createTestAudioFile(t, "talk.ogg")
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "talk.md"), "", false, 2, "", "", "deepseek")
+
+		if err := runTranscribe(createTranscribeCmd(context.Background()), env, opts); !errors.Is(err, audio.ErrChunkingFailed) {
+			t.Fatalf("runTranscribe() error = %v, want ErrChunkingFailed", err)
+		}
+		got := rec.received()
+		if len(got) != 1 || got[0].Success || !strings.Contains(got[0].Error, audio.ErrChunkingFailed.Error()) {
+			t.Errorf("notifications = %+v, want the failure of the run", got)
+		}
+	})
+}
+
+func TestTranscribeCmd_InvalidNotifyWebhook(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	env, _ := testEnv()
+	cmd := TranscribeCmd(env)
+	cmd.SetArgs([]string{inputPath, "--notify-webhook", "hooks.example.com/run"})
+	if err := cmd.Execute(); !errors.Is(err, config.ErrInvalidValue) {
+		t.Errorf("cmd.Execute() error = %v, want config.ErrInvalidValue", err)
+	}
+}
+
+func TestDesktopNotice(t *testing.T) {
+	t.Parallel()
+
+	title, message := desktopNotice("transcribe", "/notes/talk.md", 2*time.Minute+5*time.Second, nil)
+	if title != "transcript transcribe done" || message != "talk.md in 2m" {
+		t.Errorf("desktopNotice() = %q, %q, want the output and duration", title, message)
+	}
+
+	title, message = desktopNotice("live", "/notes/standup.md", time.Minute, fmt.Errorf("%w: notes.ogg", ErrFileNotFound))
+	if title != "transcript live failed" || !strings.HasPrefix(message, "standup.md after 1m: ") || !strings.Contains(message, "notes.ogg") {
+		t.Errorf("desktopNotice() = %q, %q, want the failure", title, message)
+	}
+}
+
+func TestRunTranscribe_Notify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		env, mocks := testEnv()
+		inputPath := createTestAudioFile(t, "talk.ogg")
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "talk.md"), "", false, 2, "", "", "deepseek")
+		opts.notify = true
+
+		if err := runTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+			t.Fatalf("runTranscribe() unexpected error: %v", err)
+		}
+		got := mocks.notifier.Notifications()
+		if len(got) != 1 || got[0].Title != "transcript transcribe done" || !strings.HasPrefix(got[0].Message, "talk.md") {
+			t.Errorf("notifications = %+v, want the success of the run", got)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+
+		env, mocks := testEnv()
+		mocks.chunker.mockChunker = &mockChunker{
+			ChunkFunc: func(context.Context, string) ([]audio.Chunk, error) {
+				return nil, audio.ErrChunkingFailed
+			},
+		}
+		inputPath := createTestAudioFile(t, "talk.ogg")
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "talk.md"), "", false, 2, "", "", "deepseek")
+		opts.notify = true
+
+		if err := runTranscribe(createTranscribeCmd(context.Background()), env, opts); !errors.Is(err, audio.ErrChunkingFailed) {
+			t.Fatalf("runTranscribe() error = %v, want ErrChunkingFailed", err)
+		}
+		got := mocks.notifier.Notifications()
+		if len(got) != 1 || got[0].Title != "transcript transcribe failed" {
+			t.Errorf("notifications = %+v, want the failure of the run", got)
+		}
+	})
+
+	t.Run("notification error only warns", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		env, mocks := testEnv()
+		env.Stderr = stderr
+		mocks.notifier.NotifyFunc = func(context.Context, string, string) error {
+			return fmt.Errorf("%w: notify-send not found in PATH", desktop.ErrUnsupported)
+		}
+		inputPath := createTestAudioFile(t, "talk.ogg")
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "talk.md"), "", false, 2, "", "", "deepseek")
+		opts.notify = true
+
+		if err := runTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+			t.Fatalf("runTranscribe() unexpected error: %v", err)
+		}
+		if !strings.Contains(stderr.String(), warnDesktopNotifyFailed) || !strings.Contains(stderr.String(), "notify-send") {
+			t.Errorf("stderr = %q, want a warning naming the tool", stderr.String())
+		}
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		t.Parallel()
+
+		env, mocks := testEnv()
+		inputPath := createTestAudioFile(t, "talk.ogg")
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "talk.md"), "", false, 2, "", "", "deepseek")
+
+		if err := runTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+			t.Fatalf("runTranscribe() unexpected error: %v", err)
+		}
+		if got := mocks.notifier.Notifications(); len(got) != 0 {
+			t.Errorf("notifications = %+v, want none without --notify", got)
+		}
+	})
+}
+
+func TestRunTranscribeBatch_Notify(t *testing.T) {
+	t.Parallel()
+
+	inputDir := t.TempDir()
+	writeAudioFiles(t, inputDir, "a.ogg", "b.mp3", "c.wav")
+
+	notifier := &mockNotifier{}
+	env := batchTestEnv(&syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		if strings.Contains(audioPath, "b.mp3") {
+			return "", errors.New("quota exceeded")
+		}
+		return "text", nil
+	})
+	env.Notifier = notifier
+	opts := mustParseTranscribeOptions(t, "", t.TempDir(), "", false, 2, "", "", "deepseek")
+	opts.notify = true
+
+	err := RunTranscribeBatch(createTranscribeCmd(context.Background()), env, BatchOptions{inputs: []string{inputDir}, jobs: 2, file: opts})
+	if err == nil {
+		t.Fatal("RunTranscribeBatch() error = nil, want the failed file")
+	}
+	got := notifier.Notifications()
+	if len(got) != 1 {
+		t.Fatalf("notifications = %+v, want one for the batch", got)
+	}
+	if got[0].Title != "transcript transcribe failed" || !strings.HasPrefix(got[0].Message, "1 of 3 files failed") {
+		t.Errorf("notification = %+v, want the batch failure", got[0])
+	}
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/integrations/obsidian"
+	"github.com/alnah/go-transcript/internal/notes"
+)
+
+const obsidianVaultFlagHelp = "Write the output as a note of this Obsidian vault, linked to the daily note (default: obsidian-vault setting)"
+
+// obsidianVaultRoot returns the root of the Obsidian vault the output is
+// written into: flag (--obsidian-vault), else the obsidian-vault setting when
+// no output is given (-o, --session-dir). Empty means none.
+func obsidianVaultRoot(flag string, outputGiven bool, cfg config.Config) string {
+	if flag != "" {
+		return config.ExpandPath(flag)
+	}
+	if outputGiven {
+		return ""
+	}
+	return cfg.ObsidianVault
+}
+
+// openObsidianVault opens the vault at root, and creates its folder (the
+// obsidian-folder setting). Returns nil without vault (root is empty).
+// Returns obsidian.ErrNotVault if root is not a vault, ErrInvalidOutputFormat
+// if f is not Markdown: notes are Markdown files.
+func openObsidianVault(root string, cfg config.Config, f OutputFormat) (*obsidian.Vault, error) {
+	if root == "" {
+		return nil, nil
+	}
+	if f.OrDefault() != MarkdownFormat {
+		return nil, fmt.Errorf("%w: --obsidian-vault writes Markdown notes, not %s", ErrInvalidOutputFormat, f.OrDefault())
+	}
+	vault, err := obsidian.Open(root, cfg.ObsidianFolder)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.EnsureOutputDir(vault.Dir()); err != nil {
+		return nil, fmt.Errorf("invalid vault folder: %w", err)
+	}
+	return vault, nil
+}
+
+// obsidianNote makes content, written to output with mode, a note of vault
+// dated date: linked to the daily note and to the audio at audioPath (none if
+// empty), and tagged from the template of m. Does nothing without vault, or
+// when appended to a file that already has content (see addFrontMatter).
+func obsidianNote(vault *obsidian.Vault, content, output string, mode outputMode, date time.Time, audioPath string, m *notes.FrontMatter) string {
+	if vault == nil || appendsToContent(output, mode) {
+		return content
+	}
+	m.Tags = obsidian.Tags(m.Template)
+	return vault.Note(content, date, audioPath)
+}
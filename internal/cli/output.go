@@ -7,25 +7,44 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/trash"
 )
 
 // warnNonMarkdownExtension writes a warning to w if path has an extension
 // that is not .md. This alerts users that the output will be Markdown
 // regardless of the file extension they specified.
 func warnNonMarkdownExtension(w io.Writer, path string) {
+	warnExtensionMismatch(w, path, MarkdownFormat)
+}
+
+// warnExtensionMismatch writes a warning to w if path has an extension that
+// does not match the output format f (see --format).
+func warnExtensionMismatch(w io.Writer, path string, f OutputFormat) {
 	ext := strings.ToLower(filepath.Ext(path))
-	if ext != "" && ext != ".md" {
-		_, _ = fmt.Fprintf(w, "Warning: output is Markdown regardless of %s extension\n", ext)
+	if ext != "" && ext != f.Extension() {
+		warnf(w, warnExtension, "output is %s regardless of %s extension", f.label(), ext)
 	}
 }
 
 // defaultProgressCallback returns a progress callback that writes status
-// messages to w. Used by restructuring operations in live and transcribe commands.
+// messages to w. Used by restructuring operations in the live, transcribe and
+// structure commands.
 func defaultProgressCallback(w io.Writer) func(phase string, current, total int) {
 	return func(phase string, current, total int) {
-		if phase == "map" {
+		switch phase {
+		case "map":
 			_, _ = fmt.Fprintf(w, "  Processing part %d/%d...\n", current, total)
-		} else {
+		case "sample":
+			_, _ = fmt.Fprintf(w, "  Restructuring run %d/%d...\n", current, total)
+		case "consensus":
+			_, _ = fmt.Fprintln(w, "  Merging runs...")
+		case "condense":
+			_, _ = fmt.Fprintf(w, "  Condensing parts, group %d/%d...\n", current, total)
+		default:
 			_, _ = fmt.Fprintln(w, "  Merging parts...")
 		}
 	}
@@ -59,3 +78,378 @@ func writeFileAtomic(path, content string) error {
 
 	return nil
 }
+
+// outputMode is what writing an output does when the file already exists.
+type outputMode int
+
+const (
+	outputCreate outputMode = iota // Fail with ErrOutputExists
+	outputForce                    // Replace it, moving it to the trash first (--force)
+	outputAppend                   // Append to it after a timestamped separator (--append)
+)
+
+// Help of the --force and --append flags of the transcribe, live and structure commands.
+const (
+	forceFlagHelp  = "Replace an existing output file (its previous version is kept: transcript undo restores it)"
+	appendFlagHelp = "Append to an existing output file, after a separator with the date and time (md and txt)"
+)
+
+// newOutputMode returns the mode of the --force and --append flags, which
+// are mutually exclusive.
+func newOutputMode(force, appendOutput bool) outputMode {
+	switch {
+	case force:
+		return outputForce
+	case appendOutput:
+		return outputAppend
+	default:
+		return outputCreate
+	}
+}
+
+// validateOutputMode checks that outputs of format f can be appended to:
+// subtitles cannot, and neither can signed outputs (the signature covers one
+// output, not the whole file).
+func validateOutputMode(mode outputMode, f OutputFormat, signed bool) error {
+	if mode != outputAppend {
+		return nil
+	}
+	if f.IsSubtitle() {
+		return fmt.Errorf("--append cannot add to %s subtitles, whose cues are numbered and timed from the start (use --format md or txt)", f.label())
+	}
+	if signed {
+		return fmt.Errorf("--append cannot be combined with --sign-key (the signature covers one output, not the whole file)")
+	}
+	return nil
+}
+
+// stdoutOutput is the --output value printing the output to stdout, like
+// --stdout.
+const stdoutOutput = "-"
+
+const stdoutFlagHelp = "Print the output to stdout instead of writing a file (same as -o -), progress staying on stderr"
+
+// toStdout reports whether the output is printed to stdout: --stdout, or
+// --output is stdoutOutput.
+func toStdout(stdout bool, output string) bool {
+	return stdout || output == stdoutOutput
+}
+
+// validateStdout checks that none of the flags of cmd named, which write
+// files next to the output or replace it, is set when the output is printed
+// to stdout.
+func validateStdout(cmd *cobra.Command, names ...string) error {
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be combined with --stdout or -o - (nothing is written next to stdout)", name)
+		}
+	}
+	return nil
+}
+
+// appendSeparator returns the separator written before an output appended
+// to a file of format f on date now. tail is the end of the file, to start
+// the separator on a new line.
+func appendSeparator(f OutputFormat, now time.Time, tail byte) string {
+	stamp := now.Local().Format("2006-01-02 15:04")
+	sep := "\n---\n\n**" + stamp + "**\n\n"
+	if f.OrDefault() == TextFormat {
+		sep = "\n--- " + stamp + " ---\n\n"
+	}
+	if tail != '\n' {
+		sep = "\n" + sep
+	}
+	return sep
+}
+
+// trashOutput moves the existing file at path to the trash of its directory
+// before it is replaced (--force). A missing file is not an error.
+func trashOutput(env *Env, path string) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	t := trash.New(filepath.Dir(path), trash.WithNow(env.Now))
+	if err := t.Move(path); err != nil {
+		return fmt.Errorf("cannot keep the previous %s: %w", path, err)
+	}
+	fmt.Fprintf(env.Stderr, "Replacing %s (previous version in %s, restore it with: transcript undo)\n", path, t.Dir())
+	return nil
+}
+
+// openOutput opens path to write an output with mode, and returns the file,
+// positioned where the output starts, and the size of the content kept before
+// it (non-zero only when appending to a file). Without --force or --append,
+// it fails if the file already exists, like writeFileAtomic. With --force,
+// the file is a temporary file of the same directory, which replaces the
+// existing one once closed with closeOutput.
+func openOutput(env *Env, path string, mode outputMode, f OutputFormat) (*os.File, int64, error) {
+	if mode == outputForce {
+		file, err := createTempOutput(path)
+		if err != nil {
+			return nil, 0, err
+		}
+		return file, 0, nil
+	}
+	if mode != outputAppend {
+		// #nosec G302 G304 -- user-specified output file with standard permissions
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			if errors.Is(err, os.ErrExist) {
+				return nil, 0, fmt.Errorf("output file already exists: %s: %w", path, ErrOutputExists)
+			}
+			return nil, 0, fmt.Errorf("cannot create output file: %w", err)
+		}
+		return file, 0, nil
+	}
+
+	// #nosec G302 G304 -- user-specified output file with standard permissions
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot open output file: %w", err)
+	}
+	kept, err := file.Seek(0, io.SeekEnd)
+	if err != nil || kept == 0 {
+		return file, 0, err
+	}
+	tail := make([]byte, 1)
+	if _, err := file.ReadAt(tail, kept-1); err != nil {
+		_ = file.Close()
+		return nil, 0, fmt.Errorf("cannot read output file: %w", err)
+	}
+	if _, err := file.WriteString(appendSeparator(f, env.Now(), tail[0])); err != nil {
+		_ = file.Truncate(kept)
+		_ = file.Close()
+		return nil, 0, fmt.Errorf("failed to write output: %w", err)
+	}
+	fmt.Fprintf(env.Stderr, "Appending to %s\n", path)
+	return file, kept, nil
+}
+
+// closeOutput closes the output file at path opened by openOutput, once
+// written: with --force, the temporary file then replaces the existing one
+// (see commitOutput).
+func closeOutput(env *Env, file *os.File, path string) error {
+	err := file.Close()
+	if file.Name() == path {
+		return err
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	return commitOutput(env, file.Name(), path)
+}
+
+// discardOutput undoes a failed write of an output opened by openOutput, once
+// closed: the file is removed, or truncated back to the kept content it was
+// appended to. path is the name of the file, temporary with --force.
+func discardOutput(path string, kept int64) {
+	if kept > 0 {
+		_ = os.Truncate(path, kept)
+		return
+	}
+	_ = os.Remove(path)
+}
+
+// writeOutput writes content to the output file at path with mode (see
+// openOutput). On write failure, the file is left as it was before.
+func writeOutput(env *Env, path, content string, mode outputMode, f OutputFormat) error {
+	switch mode {
+	case outputCreate:
+		return writeFileAtomic(path, content)
+	case outputForce:
+		return replaceOutput(env, path, func(w io.Writer) error {
+			_, err := io.WriteString(w, content)
+			return err
+		})
+	}
+	file, kept, err := openOutput(env, path, mode, f)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(content); err != nil {
+		_ = file.Close()
+		discardOutput(path, kept)
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// replaceOutput writes the output file at path with write (--force): into a
+// temporary file of the same directory first, which replaces the existing
+// file once complete, the existing file moving to the trash. On write
+// failure, the existing file is left in place.
+func replaceOutput(env *Env, path string, write func(io.Writer) error) error {
+	tmp, err := createTempOutput(path)
+	if err != nil {
+		return err
+	}
+	writeErr := func() error {
+		defer func() { _ = tmp.Close() }()
+		if err := write(tmp); err != nil {
+			return err
+		}
+		return tmp.Close()
+	}()
+	if writeErr != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write output: %w", writeErr)
+	}
+	return commitOutput(env, tmp.Name(), path)
+}
+
+// createTempOutput creates the temporary file of the same directory an output
+// replacing path is written to (see commitOutput).
+func createTempOutput(path string) (*os.File, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create output file: %w", err)
+	}
+	// #nosec G302 -- user-specified output file with standard permissions
+	if err := tmp.Chmod(0644); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return nil, fmt.Errorf("cannot create output file: %w", err)
+	}
+	return tmp, nil
+}
+
+// commitOutput replaces the file at path with the complete temporary file tmp
+// (see createTempOutput), the existing file moving to the trash. On failure,
+// tmp is removed.
+func commitOutput(env *Env, tmp, path string) error {
+	if err := trashOutput(env, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("cannot replace output file (restore the previous version with: transcript undo): %w", err)
+	}
+	return nil
+}
+
+// streamInterruptedMarker ends an output file whose restructuring failed or
+// was interrupted while it was streamed (--stream-restructure).
+const streamInterruptedMarker = "\n\n<!-- restructuring interrupted: partial output -->\n"
+
+// streamedOutput is the output file of --stream-restructure: the restructured
+// markdown is written to it, and echoed to stderr, as it is generated. Once
+// restructuring is done, finish replaces it with the final output.
+// With --append, the output follows the content kept in the file. With
+// --force, it is streamed to a temporary file, and the existing file is only
+// replaced once the output is closed (see openOutput).
+type streamedOutput struct {
+	env     *Env
+	path    string
+	f       *os.File
+	echo    io.Writer
+	kept    int64 // Size of the content appended to
+	start   int64 // Offset of the output, after kept and its separator
+	written bool
+}
+
+// openStreamedOutput creates the output file of a streamed restructuring,
+// with mode (see openOutput).
+func openStreamedOutput(env *Env, path string, mode outputMode, f OutputFormat) (*streamedOutput, error) {
+	file, kept, err := openOutput(env, path, mode, f)
+	if err != nil {
+		return nil, err
+	}
+	start, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		_ = file.Close()
+		discardOutput(file.Name(), kept)
+		return nil, fmt.Errorf("cannot open output file: %w", err)
+	}
+	return &streamedOutput{env: env, path: path, f: file, echo: env.Stderr, kept: kept, start: start}, nil
+}
+
+// writer returns the writer restructuring streams to: nil without
+// --stream-restructure (o is nil).
+func (o *streamedOutput) writer() io.Writer {
+	if o == nil {
+		return nil
+	}
+	return o
+}
+
+// Write writes streamed output to the file, and echoes it to stderr.
+func (o *streamedOutput) Write(p []byte) (int, error) {
+	n, err := o.f.Write(p)
+	if n > 0 {
+		o.written = true
+		_, _ = o.echo.Write(p[:n])
+	}
+	return n, err
+}
+
+// rewind drops the output streamed so far, to stream it again from the start
+// (see rewindStream). What was echoed to stderr stays.
+func (o *streamedOutput) rewind() error {
+	if !o.written {
+		return nil
+	}
+	_, _ = fmt.Fprintln(o.echo)
+	if err := o.f.Truncate(o.start); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	if _, err := o.f.Seek(o.start, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	o.written = false
+	return nil
+}
+
+// finish replaces the streamed output with content, the final output.
+// On failure, the file is removed, as by writeFileAtomic, or truncated back
+// to the content appended to; an existing file replaced (--force) is kept.
+func (o *streamedOutput) finish(content string) error {
+	if o.written {
+		_, _ = fmt.Fprintln(o.echo)
+	}
+	writeErr := func() error {
+		if err := o.f.Truncate(o.start); err != nil {
+			_ = o.f.Close()
+			return err
+		}
+		if _, err := o.f.WriteAt([]byte(content), o.start); err != nil {
+			_ = o.f.Close()
+			return err
+		}
+		return closeOutput(o.env, o.f, o.path)
+	}()
+	if writeErr != nil {
+		discardOutput(o.f.Name(), o.kept)
+		return fmt.Errorf("failed to write output: %w", writeErr)
+	}
+	return nil
+}
+
+// abort closes the output of a restructuring that failed or was interrupted.
+// The output streamed before is kept, ending with streamInterruptedMarker, and
+// its path reported on stderr; an empty output is removed (see
+// discardOutput), leaving an existing file replaced (--force) in place.
+// Does nothing if o is nil (no --stream-restructure).
+func (o *streamedOutput) abort() {
+	if o == nil {
+		return
+	}
+	if !o.written {
+		_ = o.f.Close()
+		discardOutput(o.f.Name(), o.kept)
+		return
+	}
+	_, err := o.f.WriteString(streamInterruptedMarker)
+	if closeErr := closeOutput(o.env, o.f, o.path); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		warnf(o.echo, warnFileNotSaved, "failed to mark partial output %s: %v", o.path, err)
+		return
+	}
+	fmt.Fprintf(o.echo, "\nPartial output kept: %s\n", o.path)
+}
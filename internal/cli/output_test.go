@@ -2,8 +2,15 @@ package cli
 
 import (
 	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/trash"
 )
 
 // Notes:
@@ -158,3 +165,417 @@ func TestWarnNonMarkdownExtensionCaseNormalization(t *testing.T) {
 		t.Errorf("warnNonMarkdownExtension(%q) output = %q, should not contain %q (case normalization failed)", "output.TXT", output, ".TXT")
 	}
 }
+
+func TestWarnExtensionMismatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		path        string
+		format      OutputFormat
+		wantContain string // Empty means no warning
+	}{
+		{name: "matching srt", path: "talk.srt", format: SRTFormat},
+		{name: "matching vtt uppercase", path: "talk.VTT", format: VTTFormat},
+		{name: "no extension", path: "talk", format: TextFormat},
+		{name: "md for srt output", path: "talk.md", format: SRTFormat, wantContain: "output is SRT regardless of .md"},
+		{name: "txt for vtt output", path: "talk.txt", format: VTTFormat, wantContain: "output is WebVTT regardless of .txt"},
+		{name: "md for text output", path: "talk.md", format: TextFormat, wantContain: "output is plain text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			warnExtensionMismatch(&buf, tt.path, tt.format)
+
+			output := buf.String()
+			if tt.wantContain == "" {
+				if output != "" {
+					t.Errorf("warnExtensionMismatch(%q, %s) wrote %q, want nothing", tt.path, tt.format, output)
+				}
+				return
+			}
+			if !strings.Contains(output, tt.wantContain) {
+				t.Errorf("warnExtensionMismatch(%q, %s) = %q, want containing %q", tt.path, tt.format, output, tt.wantContain)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestDefaultProgressCallback - Restructuring progress messages
+// ---------------------------------------------------------------------------
+
+func TestDefaultProgressCallback(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		phase          string
+		current, total int
+		want           string
+	}{
+		{"map", 2, 3, "  Processing part 2/3...\n"},
+		{"condense", 1, 4, "  Condensing parts, group 1/4...\n"},
+		{"reduce", 1, 1, "  Merging parts...\n"},
+		{"sample", 1, 3, "  Restructuring run 1/3...\n"},
+		{"consensus", 1, 1, "  Merging runs...\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phase, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			defaultProgressCallback(&buf)(tt.phase, tt.current, tt.total)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("progress = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestStreamedOutput - Output written as it is restructured
+// ---------------------------------------------------------------------------
+
+func TestStreamedOutput(t *testing.T) {
+	t.Parallel()
+
+	t.Run("finish replaces streamed output", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "notes.md")
+		var stderr bytes.Buffer
+		out, err := openStreamedOutput(&Env{Stderr: &stderr, Now: time.Now}, path, outputCreate, MarkdownFormat)
+		if err != nil {
+			t.Fatalf("openStreamedOutput() error = %v", err)
+		}
+		if _, err := io.WriteString(out.writer(), "# Notes\n\n- draft"); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if err := out.finish("# Notes\n\n- final\n"); err != nil {
+			t.Fatalf("finish() error = %v", err)
+		}
+
+		got, _ := os.ReadFile(path)
+		if string(got) != "# Notes\n\n- final\n" {
+			t.Errorf("output = %q, want the final output", got)
+		}
+		if !strings.Contains(stderr.String(), "- draft") {
+			t.Errorf("stderr = %q, want the streamed output", stderr.String())
+		}
+	})
+
+	t.Run("abort marks partial output", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "notes.md")
+		var stderr bytes.Buffer
+		out, err := openStreamedOutput(&Env{Stderr: &stderr, Now: time.Now}, path, outputCreate, MarkdownFormat)
+		if err != nil {
+			t.Fatalf("openStreamedOutput() error = %v", err)
+		}
+		_, _ = io.WriteString(out, "# Notes\n\n- first")
+		out.abort()
+
+		got, _ := os.ReadFile(path)
+		if string(got) != "# Notes\n\n- first"+streamInterruptedMarker {
+			t.Errorf("output = %q, want the partial output and its marker", got)
+		}
+		if !strings.Contains(stderr.String(), "Partial output kept: "+path) {
+			t.Errorf("stderr = %q, want the partial output path", stderr.String())
+		}
+	})
+
+	t.Run("rewind drops the streamed output", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "notes.md")
+		out, err := openStreamedOutput(&Env{Stderr: io.Discard, Now: time.Now}, path, outputCreate, MarkdownFormat)
+		if err != nil {
+			t.Fatalf("openStreamedOutput() error = %v", err)
+		}
+		_, _ = io.WriteString(out, "# Notes\n\n- first attempt")
+		if err := out.rewind(); err != nil {
+			t.Fatalf("rewind() error = %v", err)
+		}
+		_, _ = io.WriteString(out, "# Notes\n\n- retry")
+		out.abort()
+
+		got, _ := os.ReadFile(path)
+		if string(got) != "# Notes\n\n- retry"+streamInterruptedMarker {
+			t.Errorf("output = %q, want the output streamed after rewind only", got)
+		}
+	})
+
+	t.Run("abort removes empty output", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "notes.md")
+		out, err := openStreamedOutput(&Env{Stderr: io.Discard, Now: time.Now}, path, outputCreate, MarkdownFormat)
+		if err != nil {
+			t.Fatalf("openStreamedOutput() error = %v", err)
+		}
+		out.abort()
+
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("output exists after abort before any output: %v", err)
+		}
+	})
+
+	t.Run("existing output", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "notes.md")
+		if err := os.WriteFile(path, []byte("keep"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := openStreamedOutput(&Env{Stderr: io.Discard, Now: time.Now}, path, outputCreate, MarkdownFormat); !errors.Is(err, ErrOutputExists) {
+			t.Errorf("openStreamedOutput() error = %v, want ErrOutputExists", err)
+		}
+	})
+
+	t.Run("append keeps the file content", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "journal.md")
+		if err := os.WriteFile(path, []byte("# Monday\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		out, err := openStreamedOutput(outputTestEnv(io.Discard), path, outputAppend, MarkdownFormat)
+		if err != nil {
+			t.Fatalf("openStreamedOutput() error = %v", err)
+		}
+		_, _ = io.WriteString(out, "# Tuesday\n\n- draft")
+		if err := out.finish("# Tuesday\n\n- final\n"); err != nil {
+			t.Fatalf("finish() error = %v", err)
+		}
+
+		got, _ := os.ReadFile(path)
+		if want := "# Monday\n\n---\n\n**2026-10-17 14:05**\n\n# Tuesday\n\n- final\n"; string(got) != want {
+			t.Errorf("output = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("abort of an append restores the file", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "journal.md")
+		if err := os.WriteFile(path, []byte("# Monday\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		out, err := openStreamedOutput(outputTestEnv(io.Discard), path, outputAppend, MarkdownFormat)
+		if err != nil {
+			t.Fatalf("openStreamedOutput() error = %v", err)
+		}
+		out.abort()
+
+		if got, _ := os.ReadFile(path); string(got) != "# Monday\n" {
+			t.Errorf("output = %q, want the file as it was", got)
+		}
+	})
+
+	t.Run("force replaces the existing file once finished", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "notes.md")
+		if err := os.WriteFile(path, []byte("# Previous\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		out, err := openStreamedOutput(outputTestEnv(io.Discard), path, outputForce, MarkdownFormat)
+		if err != nil {
+			t.Fatalf("openStreamedOutput() error = %v", err)
+		}
+		_, _ = io.WriteString(out, "# Notes\n\n- draft")
+		if got, _ := os.ReadFile(path); string(got) != "# Previous\n" {
+			t.Errorf("output while streaming = %q, want the existing file untouched", got)
+		}
+		if err := out.finish("# Notes\n\n- final\n"); err != nil {
+			t.Fatalf("finish() error = %v", err)
+		}
+
+		if got, _ := os.ReadFile(path); string(got) != "# Notes\n\n- final\n" {
+			t.Errorf("output = %q, want the final output", got)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+			t.Errorf("directory = %v, want the output and the trash only", entries)
+		}
+	})
+
+	t.Run("abort of a force keeps the existing file", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "notes.md")
+		if err := os.WriteFile(path, []byte("# Previous\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		out, err := openStreamedOutput(outputTestEnv(io.Discard), path, outputForce, MarkdownFormat)
+		if err != nil {
+			t.Fatalf("openStreamedOutput() error = %v", err)
+		}
+		out.abort()
+
+		if got, _ := os.ReadFile(path); string(got) != "# Previous\n" {
+			t.Errorf("output = %q, want the existing file", got)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Errorf("directory = %v, want the output only", entries)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		var out *streamedOutput
+		if out.writer() != nil {
+			t.Error("writer() of no streamed output is not nil")
+		}
+		out.abort() // Does nothing
+	})
+}
+
+// ---------------------------------------------------------------------------
+// TestWriteOutput - Existing output files (--force, --append)
+// ---------------------------------------------------------------------------
+
+// outputTestEnv returns an Env whose clock is fixed at 2026-10-17 14:05 local time.
+func outputTestEnv(stderr io.Writer) *Env {
+	return &Env{
+		Stderr: stderr,
+		Now:    func() time.Time { return time.Date(2026, 10, 17, 14, 5, 0, 0, time.Local) },
+	}
+}
+
+func TestWriteOutput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		existing string
+		exists   bool
+		mode     outputMode
+		format   OutputFormat
+		want     string
+		wantErr  error
+	}{
+		{name: "new file", mode: outputCreate, want: "new"},
+		{name: "existing file", existing: "old", exists: true, mode: outputCreate, want: "old", wantErr: ErrOutputExists},
+		{name: "force replaces", existing: "old", exists: true, mode: outputForce, want: "new"},
+		{name: "force without a file", mode: outputForce, want: "new"},
+		{name: "append to markdown", existing: "old\n", exists: true, mode: outputAppend, want: "old\n\n---\n\n**2026-10-17 14:05**\n\nnew"},
+		{name: "append after a line without newline", existing: "old", exists: true, mode: outputAppend, want: "old\n\n---\n\n**2026-10-17 14:05**\n\nnew"},
+		{name: "append to text", existing: "old\n", exists: true, mode: outputAppend, format: TextFormat, want: "old\n\n--- 2026-10-17 14:05 ---\n\nnew"},
+		{name: "append to an empty file", existing: "", exists: true, mode: outputAppend, want: "new"},
+		{name: "append without a file", mode: outputAppend, want: "new"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "notes.md")
+			if tt.exists {
+				if err := os.WriteFile(path, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := writeOutput(outputTestEnv(io.Discard), path, "new", tt.mode, tt.format)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("writeOutput() error = %v, want %v", err, tt.wantErr)
+			}
+			if got, _ := os.ReadFile(path); string(got) != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteOutput_ForceKeepsPreviousVersion(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stderr bytes.Buffer
+	env := outputTestEnv(&stderr)
+	if err := writeOutput(env, path, "new", outputForce, MarkdownFormat); err != nil {
+		t.Fatalf("writeOutput() error = %v", err)
+	}
+	if !strings.Contains(stderr.String(), "transcript undo") {
+		t.Errorf("stderr = %q, want how to restore the previous version", stderr.String())
+	}
+
+	if _, err := trash.New(dir, trash.WithNow(env.Now)).Restore(); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "old" {
+		t.Errorf("restored output = %q, want the previous version", got)
+	}
+}
+
+func TestReplaceOutput_WriteFailureKeepsExistingFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	diskFull := errors.New("no space left on device")
+	err := replaceOutput(outputTestEnv(io.Discard), path, func(w io.Writer) error {
+		_, _ = io.WriteString(w, "partial")
+		return diskFull
+	})
+	if !errors.Is(err, diskFull) {
+		t.Fatalf("replaceOutput() error = %v, want %v", err, diskFull)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "old" {
+		t.Errorf("output = %q, want the existing file untouched", got)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory = %v, want only the output (no temporary file, nothing trashed)", entries)
+	}
+}
+
+func TestValidateOutputMode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		mode    outputMode
+		format  OutputFormat
+		signed  bool
+		wantErr bool
+	}{
+		{"append to markdown", outputAppend, MarkdownFormat, false, false},
+		{"append to text", outputAppend, TextFormat, false, false},
+		{"append to subtitles", outputAppend, SRTFormat, false, true},
+		{"append signed output", outputAppend, MarkdownFormat, true, true},
+		{"force subtitles", outputForce, VTTFormat, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateOutputMode(tt.mode, tt.format, tt.signed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateOutputMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
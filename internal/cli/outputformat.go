@@ -0,0 +1,319 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/format"
+	"github.com/alnah/go-transcript/internal/template"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// Output format names (--format).
+const (
+	// FormatMarkdown writes the transcript (or restructured notes) as Markdown.
+	FormatMarkdown = "md"
+	// FormatText writes the same content as plain text.
+	FormatText = "txt"
+	// FormatSRT writes timestamped SubRip subtitles.
+	FormatSRT = "srt"
+	// FormatVTT writes timestamped WebVTT subtitles.
+	FormatVTT = "vtt"
+)
+
+// OutputFormat represents a validated output format.
+// Zero value means "not set" and defaults to Markdown.
+// Use ParseOutputFormat to create from user input, or the pre-parsed constants.
+type OutputFormat struct {
+	name string
+}
+
+// Compile-time interface compliance check.
+var _ fmt.Stringer = OutputFormat{}
+
+// Pre-parsed output format constants for use in code.
+var (
+	MarkdownFormat = OutputFormat{name: FormatMarkdown}
+	TextFormat     = OutputFormat{name: FormatText}
+	SRTFormat      = OutputFormat{name: FormatSRT}
+	VTTFormat      = OutputFormat{name: FormatVTT}
+)
+
+// ParseOutputFormat validates and parses an output format name.
+// Returns ErrInvalidOutputFormat if the name is not recognized.
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch s {
+	case FormatMarkdown, FormatText, FormatSRT, FormatVTT:
+		return OutputFormat{name: s}, nil
+	case "":
+		return OutputFormat{}, fmt.Errorf("format cannot be empty: %w", ErrInvalidOutputFormat)
+	default:
+		return OutputFormat{}, fmt.Errorf("unknown format %q (use %s, %s, %s or %s): %w",
+			s, FormatMarkdown, FormatText, FormatSRT, FormatVTT, ErrInvalidOutputFormat)
+	}
+}
+
+// String returns the format name string.
+// Returns empty string for zero value.
+func (f OutputFormat) String() string {
+	return f.name
+}
+
+// IsZero returns true if this is the zero value (no format set).
+func (f OutputFormat) IsZero() bool {
+	return f.name == ""
+}
+
+// OrDefault returns the format, or MarkdownFormat if zero.
+func (f OutputFormat) OrDefault() OutputFormat {
+	if f.IsZero() {
+		return MarkdownFormat
+	}
+	return f
+}
+
+// IsSubtitle returns true for the timestamped subtitle formats (srt, vtt).
+func (f OutputFormat) IsSubtitle() bool {
+	return f.name == FormatSRT || f.name == FormatVTT
+}
+
+// Extension returns the file extension of the format, including the dot.
+func (f OutputFormat) Extension() string {
+	return "." + f.OrDefault().name
+}
+
+// label returns the human-readable name of the format, for messages.
+func (f OutputFormat) label() string {
+	switch f.OrDefault().name {
+	case FormatText:
+		return "plain text"
+	case FormatSRT:
+		return "SRT"
+	case FormatVTT:
+		return "WebVTT"
+	default:
+		return "Markdown"
+	}
+}
+
+// Speaker label names of subtitles (--speaker-labels).
+const (
+	// SpeakerLabelsBracket prefixes captions with the speaker in brackets:
+	// "[Alice] Hello". The default of srt.
+	SpeakerLabelsBracket = "bracket"
+	// SpeakerLabelsPrefix prefixes captions with the speaker name: "Alice: Hello".
+	SpeakerLabelsPrefix = "prefix"
+	// SpeakerLabelsVoice tags captions with a WebVTT voice tag:
+	// "<v Alice>Hello". The default of vtt, which alone supports it.
+	SpeakerLabelsVoice = "voice"
+	// SpeakerLabelsNone leaves speakers out of captions.
+	SpeakerLabelsNone = "none"
+)
+
+// speakerLabelsFlagHelp describes the --speaker-labels flag of the transcribe
+// and live commands.
+const speakerLabelsFlagHelp = "Speakers in srt and vtt captions with --diarize: bracket, prefix, voice (vtt only) or none (default: bracket in srt, voice in vtt)"
+
+// SpeakerLabels represents a validated --speaker-labels value.
+// Zero value means "not set": the default of the subtitle format.
+type SpeakerLabels struct {
+	name string
+}
+
+// Compile-time interface compliance check.
+var _ fmt.Stringer = SpeakerLabels{}
+
+// ParseSpeakerLabels validates and parses a --speaker-labels value.
+// Returns ErrInvalidSpeakerLabels if the value is not recognized.
+func ParseSpeakerLabels(s string) (SpeakerLabels, error) {
+	switch s {
+	case SpeakerLabelsBracket, SpeakerLabelsPrefix, SpeakerLabelsVoice, SpeakerLabelsNone:
+		return SpeakerLabels{name: s}, nil
+	default:
+		return SpeakerLabels{}, fmt.Errorf("unknown speaker labels %q (use %s, %s, %s or %s): %w",
+			s, SpeakerLabelsBracket, SpeakerLabelsPrefix, SpeakerLabelsVoice, SpeakerLabelsNone, ErrInvalidSpeakerLabels)
+	}
+}
+
+// String returns the speaker labels name string.
+// Returns empty string for zero value.
+func (l SpeakerLabels) String() string {
+	return l.name
+}
+
+// IsZero returns true if this is the zero value (no speaker labels set).
+func (l SpeakerLabels) IsZero() bool {
+	return l.name == ""
+}
+
+// validateSpeakerLabels checks that speaker labels l can be rendered in format f.
+func validateSpeakerLabels(l SpeakerLabels, f OutputFormat) error {
+	if l.IsZero() {
+		return nil
+	}
+	if !f.IsSubtitle() {
+		return fmt.Errorf("--speaker-labels requires --format %s or %s", FormatSRT, FormatVTT)
+	}
+	if l.name == SpeakerLabelsVoice && f != VTTFormat {
+		return fmt.Errorf("--speaker-labels %s requires --format %s (voice tags are WebVTT only)", SpeakerLabelsVoice, FormatVTT)
+	}
+	return nil
+}
+
+// apply moves the speaker of cues into their text as l requires. Cues keeping
+// a speaker are labeled by the subtitle format: a bracket in SRT, a voice tag
+// in WebVTT.
+func (l SpeakerLabels) apply(cues []format.Cue) {
+	for i, c := range cues {
+		if c.Speaker == "" {
+			continue
+		}
+		switch l.name {
+		case SpeakerLabelsBracket:
+			cues[i].Text = fmt.Sprintf("[%s] %s", c.Speaker, c.Text)
+		case SpeakerLabelsPrefix:
+			cues[i].Text = fmt.Sprintf("%s: %s", c.Speaker, c.Text)
+		case SpeakerLabelsNone:
+		default:
+			continue
+		}
+		cues[i].Speaker = ""
+	}
+}
+
+// TimestampsSegment is the --timestamps value putting a time marker before
+// every segment instead of every interval.
+const TimestampsSegment = "segment"
+
+// defaultTimestamps is the --timestamps value without an interval.
+const defaultTimestamps = "5m"
+
+// timestampsFlagHelp describes the --timestamps flag of the transcribe and
+// live commands.
+const timestampsFlagHelp = "Time markers in md and txt transcripts: every interval (--timestamps=10m), or before every segment (--timestamps=segment); default interval 5m"
+
+// Timestamps represents a validated --timestamps value: time markers in
+// Markdown and text transcripts.
+// Zero value means "not set": no markers.
+type Timestamps struct {
+	set      bool
+	interval time.Duration // Zero puts a marker before every segment
+}
+
+// Compile-time interface compliance check.
+var _ fmt.Stringer = Timestamps{}
+
+// ParseTimestamps validates and parses a --timestamps value: "segment", or
+// a positive duration like "5m".
+// Returns ErrInvalidTimestamps if the value is neither.
+func ParseTimestamps(s string) (Timestamps, error) {
+	if s == TimestampsSegment {
+		return Timestamps{set: true}, nil
+	}
+	interval, err := time.ParseDuration(s)
+	if err != nil || interval < time.Second {
+		return Timestamps{}, fmt.Errorf("--timestamps must be %s or a duration of at least 1s (e.g., 5m), got %q: %w",
+			TimestampsSegment, s, ErrInvalidTimestamps)
+	}
+	return Timestamps{set: true, interval: interval}, nil
+}
+
+// String returns the timestamps value, as accepted by ParseTimestamps.
+// Returns empty string for zero value.
+func (t Timestamps) String() string {
+	switch {
+	case !t.set:
+		return ""
+	case t.interval == 0:
+		return TimestampsSegment
+	default:
+		return t.interval.String()
+	}
+}
+
+// IsZero returns true if this is the zero value (no timestamps set).
+func (t Timestamps) IsZero() bool {
+	return !t.set
+}
+
+// validateTimestamps checks that time markers t can be put in format f: the
+// raw transcript in Markdown or text. Subtitles are already timed, and
+// restructured notes are rewritten without the markers.
+func validateTimestamps(t Timestamps, f OutputFormat, tmpl template.Name) error {
+	if t.IsZero() {
+		return nil
+	}
+	if f.IsSubtitle() {
+		return fmt.Errorf("--timestamps cannot be combined with --format %s (subtitles are already timed)", f)
+	}
+	if !tmpl.IsZero() {
+		return fmt.Errorf("--timestamps cannot be combined with --template (markers are put in the raw transcript)")
+	}
+	return nil
+}
+
+// timedResults reports whether chunks must be transcribed with segment times
+// (see transcribe.Options.Timestamps): for subtitles, timed templates, and
+// time markers.
+func timedResults(f OutputFormat, tmpl template.Name, t Timestamps) bool {
+	return f.IsSubtitle() || tmpl.Timed() || !t.IsZero()
+}
+
+// renderTranscript assembles the results of transcribing chunks in format f:
+// paragraphs for md and txt, subtitles for srt and vtt (which expect results
+// transcribed with transcribe.Options.Timestamps) with the speakers labeled
+// as labels requires.
+func renderTranscript(f OutputFormat, labels SpeakerLabels, chunks []audio.Chunk, results []string) (string, error) {
+	if !f.IsSubtitle() {
+		return strings.Join(transcribe.StitchOverlap(results), "\n\n"), nil
+	}
+
+	cues, err := mergeCues(chunks, results)
+	if err != nil {
+		return "", err
+	}
+	labels.apply(cues)
+	if f == VTTFormat {
+		return format.VTT(cues), nil
+	}
+	return format.SRT(cues), nil
+}
+
+// timedPause is the shortest silence marked as a pause in timed transcripts.
+const timedPause = 3 * time.Second
+
+// renderTimedTranscript assembles results transcribed with timestamps as the
+// input of a timed template (see template.Name.Timed): one "[HH:MM:SS]" line
+// per segment, with long silences marked as pauses.
+func renderTimedTranscript(chunks []audio.Chunk, results []string) (string, error) {
+	cues, err := mergeCues(chunks, results)
+	if err != nil {
+		return "", err
+	}
+	return format.Timed(cues, timedPause), nil
+}
+
+// renderMarkedTranscript assembles results transcribed with timestamps as
+// paragraphs led by the time markers of t, bold in Markdown (see format.Marked).
+func renderMarkedTranscript(f OutputFormat, t Timestamps, chunks []audio.Chunk, results []string) (string, error) {
+	cues, err := mergeCues(chunks, results)
+	if err != nil {
+		return "", err
+	}
+	return format.Marked(cues, t.interval, f.OrDefault() == MarkdownFormat), nil
+}
+
+// mergeCues decodes timestamped results into cues relative to the start of the audio.
+func mergeCues(chunks []audio.Chunk, results []string) ([]format.Cue, error) {
+	segments, err := transcribe.MergeSegments(chunks, results)
+	if err != nil {
+		return nil, err
+	}
+	cues := make([]format.Cue, len(segments))
+	for i, s := range segments {
+		cues[i] = format.Cue{Start: s.Start, End: s.End, Speaker: s.Speaker, Text: s.Text, Confidence: s.Confidence}
+	}
+	return cues, nil
+}
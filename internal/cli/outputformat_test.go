@@ -0,0 +1,336 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    OutputFormat
+		wantErr bool
+	}{
+		{name: "md valid", input: "md", want: MarkdownFormat},
+		{name: "txt valid", input: "txt", want: TextFormat},
+		{name: "srt valid", input: "srt", want: SRTFormat},
+		{name: "vtt valid", input: "vtt", want: VTTFormat},
+		{name: "empty string returns error", input: "", wantErr: true},
+		{name: "invalid format returns error", input: "docx", wantErr: true},
+		{name: "case sensitive - SRT invalid", input: "SRT", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseOutputFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOutputFormat(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseOutputFormat(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidOutputFormat) {
+				t.Errorf("ParseOutputFormat(%q) error should wrap ErrInvalidOutputFormat, got %v", tt.input, err)
+			}
+		})
+	}
+}
+
+func TestOutputFormat_Methods(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format       OutputFormat
+		wantExt      string
+		wantSubtitle bool
+	}{
+		{format: OutputFormat{}, wantExt: ".md"},
+		{format: MarkdownFormat, wantExt: ".md"},
+		{format: TextFormat, wantExt: ".txt"},
+		{format: SRTFormat, wantExt: ".srt", wantSubtitle: true},
+		{format: VTTFormat, wantExt: ".vtt", wantSubtitle: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantExt, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.format.Extension(); got != tt.wantExt {
+				t.Errorf("Extension() = %q, want %q", got, tt.wantExt)
+			}
+			if got := tt.format.IsSubtitle(); got != tt.wantSubtitle {
+				t.Errorf("IsSubtitle() = %v, want %v", got, tt.wantSubtitle)
+			}
+		})
+	}
+
+	if (OutputFormat{}).OrDefault() != MarkdownFormat {
+		t.Error("zero OutputFormat should default to Markdown")
+	}
+}
+
+func TestParseSpeakerLabels(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{"bracket", "prefix", "voice", "none"} {
+		got, err := ParseSpeakerLabels(input)
+		if err != nil || got.String() != input {
+			t.Errorf("ParseSpeakerLabels(%q) = %v, %v, want %s", input, got, err, input)
+		}
+	}
+	for _, input := range []string{"", "name", "Voice"} {
+		if _, err := ParseSpeakerLabels(input); !errors.Is(err, ErrInvalidSpeakerLabels) {
+			t.Errorf("ParseSpeakerLabels(%q) error = %v, want ErrInvalidSpeakerLabels", input, err)
+		}
+	}
+}
+
+func TestValidateSpeakerLabels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		labels  string
+		format  OutputFormat
+		wantErr bool
+	}{
+		{name: "unset with markdown", format: MarkdownFormat},
+		{name: "prefix with srt", labels: SpeakerLabelsPrefix, format: SRTFormat},
+		{name: "voice with vtt", labels: SpeakerLabelsVoice, format: VTTFormat},
+		{name: "none with vtt", labels: SpeakerLabelsNone, format: VTTFormat},
+		{name: "voice with srt", labels: SpeakerLabelsVoice, format: SRTFormat, wantErr: true},
+		{name: "bracket with markdown", labels: SpeakerLabelsBracket, format: MarkdownFormat, wantErr: true},
+		{name: "prefix with default format", labels: SpeakerLabelsPrefix, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateSpeakerLabels(SpeakerLabels{name: tt.labels}, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSpeakerLabels(%q, %v) error = %v, wantErr %v", tt.labels, tt.format, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderTranscript(t *testing.T) {
+	t.Parallel()
+
+	chunks := []audio.Chunk{
+		{Index: 0, StartTime: 0, EndTime: time.Minute},
+		{Index: 1, StartTime: time.Minute, EndTime: 2 * time.Minute},
+	}
+	timed := []string{
+		`[{"start":0,"end":1.5,"text":"Hello."}]`,
+		`[{"start":0.25,"end":2,"speaker":"B","text":"Bye."}]`,
+	}
+
+	tests := []struct {
+		name    string
+		format  OutputFormat
+		labels  SpeakerLabels
+		results []string
+		want    string
+	}{
+		{
+			name:    "markdown joins paragraphs",
+			format:  MarkdownFormat,
+			results: []string{"Hello.", "Bye."},
+			want:    "Hello.\n\nBye.",
+		},
+		{
+			name:    "text joins paragraphs",
+			format:  TextFormat,
+			results: []string{"Hello.", "Bye."},
+			want:    "Hello.\n\nBye.",
+		},
+		{
+			name:    "overlap kept once",
+			format:  MarkdownFormat,
+			results: []string{"Hello and welcome to the show.", "welcome to the show. Today we talk about bees."},
+			want:    "Hello and welcome to the show.\n\nToday we talk about bees.",
+		},
+		{
+			name:    "srt offsets chunks",
+			format:  SRTFormat,
+			results: timed,
+			want: "1\n00:00:00,000 --> 00:00:01,500\nHello.\n\n" +
+				"2\n00:01:00,250 --> 00:01:02,000\n[B] Bye.\n",
+		},
+		{
+			name:    "vtt offsets chunks",
+			format:  VTTFormat,
+			results: timed,
+			want: "WEBVTT\n\n00:00:00.000 --> 00:00:01.500\nHello.\n\n" +
+				"00:01:00.250 --> 00:01:02.000\n<v B>Bye.\n",
+		},
+		{
+			name:    "srt speaker prefix",
+			format:  SRTFormat,
+			labels:  SpeakerLabels{name: SpeakerLabelsPrefix},
+			results: timed,
+			want: "1\n00:00:00,000 --> 00:00:01,500\nHello.\n\n" +
+				"2\n00:01:00,250 --> 00:01:02,000\nB: Bye.\n",
+		},
+		{
+			name:    "vtt speaker in brackets",
+			format:  VTTFormat,
+			labels:  SpeakerLabels{name: SpeakerLabelsBracket},
+			results: timed,
+			want: "WEBVTT\n\n00:00:00.000 --> 00:00:01.500\nHello.\n\n" +
+				"00:01:00.250 --> 00:01:02.000\n[B] Bye.\n",
+		},
+		{
+			name:    "vtt voice tag",
+			format:  VTTFormat,
+			labels:  SpeakerLabels{name: SpeakerLabelsVoice},
+			results: timed,
+			want: "WEBVTT\n\n00:00:00.000 --> 00:00:01.500\nHello.\n\n" +
+				"00:01:00.250 --> 00:01:02.000\n<v B>Bye.\n",
+		},
+		{
+			name:    "srt without speakers",
+			format:  SRTFormat,
+			labels:  SpeakerLabels{name: SpeakerLabelsNone},
+			results: timed,
+			want: "1\n00:00:00,000 --> 00:00:01,500\nHello.\n\n" +
+				"2\n00:01:00,250 --> 00:01:02,000\nBye.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := renderTranscript(tt.format, tt.labels, chunks, tt.results)
+			if err != nil {
+				t.Fatalf("renderTranscript() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renderTranscript() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("subtitles reject plain text results", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := renderTranscript(SRTFormat, SpeakerLabels{}, chunks, []string{"Hello.", "Bye."}); err == nil {
+			t.Error("renderTranscript() expected error for untimed results")
+		}
+	})
+}
+
+func TestParseTimestamps(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]string{"segment": "segment", "5m": "5m0s", "90s": "1m30s"} {
+		got, err := ParseTimestamps(input)
+		if err != nil || got.String() != want {
+			t.Errorf("ParseTimestamps(%q) = %v, %v, want %s", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "often", "0", "-5m", "500ms", "Segment"} {
+		if _, err := ParseTimestamps(input); !errors.Is(err, ErrInvalidTimestamps) {
+			t.Errorf("ParseTimestamps(%q) error = %v, want ErrInvalidTimestamps", input, err)
+		}
+	}
+}
+
+func TestValidateTimestamps(t *testing.T) {
+	t.Parallel()
+
+	every5m := Timestamps{set: true, interval: 5 * time.Minute}
+	tests := []struct {
+		name       string
+		timestamps Timestamps
+		format     OutputFormat
+		template   template.Name
+		wantErr    bool
+	}{
+		{name: "unset with srt", format: SRTFormat},
+		{name: "markdown", timestamps: every5m, format: MarkdownFormat},
+		{name: "default format", timestamps: every5m},
+		{name: "text", timestamps: every5m, format: TextFormat},
+		{name: "vtt", timestamps: every5m, format: VTTFormat, wantErr: true},
+		{name: "template", timestamps: every5m, template: template.MustParseName("meeting"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateTimestamps(tt.timestamps, tt.format, tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateTimestamps(%v, %v) error = %v, wantErr %v", tt.timestamps, tt.format, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderMarkedTranscript(t *testing.T) {
+	t.Parallel()
+
+	chunks := []audio.Chunk{
+		{Index: 0, StartTime: 0, EndTime: time.Minute},
+		{Index: 1, StartTime: time.Minute, EndTime: 2 * time.Minute},
+	}
+	results := []string{
+		`[{"start":0,"end":1.5,"text":"Hello."}]`,
+		`[{"start":5,"end":7,"text":"Bye."}]`,
+	}
+
+	tests := []struct {
+		format OutputFormat
+		want   string
+	}{
+		{MarkdownFormat, "**[00:00:00]** Hello.\n\n**[00:01:05]** Bye."},
+		{TextFormat, "[00:00:00] Hello.\n\n[00:01:05] Bye."},
+	}
+	for _, tt := range tests {
+		got, err := renderMarkedTranscript(tt.format, Timestamps{set: true}, chunks, results)
+		if err != nil {
+			t.Fatalf("renderMarkedTranscript(%v) unexpected error: %v", tt.format, err)
+		}
+		if got != tt.want {
+			t.Errorf("renderMarkedTranscript(%v) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestRenderTimedTranscript(t *testing.T) {
+	t.Parallel()
+
+	chunks := []audio.Chunk{
+		{Index: 0, StartTime: 0, EndTime: time.Minute},
+		{Index: 1, StartTime: time.Minute, EndTime: 2 * time.Minute},
+	}
+	results := []string{
+		`[{"start":0,"end":1.5,"text":"Hello."}]`,
+		`[{"start":5,"end":7,"speaker":"B","text":"Bye."}]`,
+	}
+
+	got, err := renderTimedTranscript(chunks, results)
+	if err != nil {
+		t.Fatalf("renderTimedTranscript() unexpected error: %v", err)
+	}
+	want := "[00:00:00] Hello.\n[pause 64s]\n[00:01:05] B: Bye.\n"
+	if got != want {
+		t.Errorf("renderTimedTranscript() = %q, want %q", got, want)
+	}
+
+	if _, err := renderTimedTranscript(chunks, []string{"Hello.", "Bye."}); err == nil {
+		t.Error("renderTimedTranscript() expected error for untimed results")
+	}
+}
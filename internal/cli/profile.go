@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// profileFlagHelp describes the --profile flag of the transcribe and live commands.
+const profileFlagHelp = "Use the defaults of this config profile, overridden by the flags given (default: " + config.EnvProfile + ")"
+
+// applyProfile sets the flags of cmd that the profile name sets and that were
+// not given on the command line. An empty name selects the profile of
+// TRANSCRIPT_PROFILE. Returns the name of the profile applied, empty if none.
+// The output-dir of the profile is applied to the config by the command (see
+// config.Config.WithProfile).
+func applyProfile(cmd *cobra.Command, env *Env, name string) (string, error) {
+	cfg, err := env.ConfigLoader.Load()
+	if name == "" {
+		name = cfg.Profile
+	}
+	if name == "" {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot use profile %s: %w", name, err)
+	}
+	p, err := cfg.LookupProfile(name)
+	if err != nil {
+		return "", err
+	}
+
+	flags := cmd.Flags()
+	for key, value := range p.Values() {
+		if key == config.KeyOutputDir || flags.Changed(key) {
+			continue
+		}
+		if err := flags.Set(key, value); err != nil {
+			return "", fmt.Errorf("%w: profile %s: %s: %v", config.ErrInvalidValue, name, key, err)
+		}
+	}
+	return name, nil
+}
+
+// validateProfileSetting checks the value of a profile setting, and returns it
+// as saved (paths expanded).
+func validateProfileSetting(env *Env, key, value string) (string, error) {
+	if err := validateProfileKey(key); err != nil {
+		return "", err
+	}
+	switch key {
+	case config.KeyTemplate:
+		if _, err := template.Resolve(value, userTemplatesDir(env)); err != nil {
+			return "", err
+		}
+	case config.KeyLanguage:
+		if _, err := lang.Parse(value); err != nil {
+			return "", err
+		}
+	case config.KeyProvider:
+		if _, err := ParseProvider(value); err != nil {
+			return "", err
+		}
+	case config.KeyDiarize:
+		if _, err := config.ParseDiarize(value); err != nil {
+			return "", err
+		}
+	case config.KeyParallel:
+		if _, _, err := parseParallel(value); err != nil {
+			return "", err
+		}
+	case config.KeyOutputDir:
+		value = config.ExpandPath(value)
+		if err := config.EnsureOutputDir(value); err != nil {
+			return "", fmt.Errorf("invalid output-dir: %w", err)
+		}
+	}
+	return value, nil
+}
+
+// validateProfileKey checks that key is a setting of profiles.
+func validateProfileKey(key string) error {
+	if !slices.Contains(config.ProfileKeys, key) {
+		return fmt.Errorf("%w: unknown profile key %q (valid keys: %s)",
+			config.ErrInvalidKey, key, strings.Join(config.ProfileKeys, ", "))
+	}
+	return nil
+}
+
+// runConfigListProfiles writes the profiles of the config file to w, with the
+// settings of each. The profile selected by TRANSCRIPT_PROFILE is marked.
+func runConfigListProfiles(w io.Writer, env *Env) error {
+	cfg, err := env.ConfigLoader.Load()
+	if err != nil {
+		return err
+	}
+
+	names := cfg.ProfileNames()
+	if len(names) == 0 {
+		fmt.Fprintln(w, "No profiles defined.")
+		fmt.Fprintln(w, "Create one with: transcript config set --profile meetings template meeting")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		values := cfg.Profiles[name].Values()
+		var settings []string
+		for _, key := range config.ProfileKeys {
+			if value, ok := values[key]; ok {
+				settings = append(settings, key+"="+value)
+			}
+		}
+		if name == cfg.Profile {
+			name += " (active)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", name, strings.Join(settings, " "))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// profileConfigLoader returns a ConfigLoader whose config defines a meetings
+// and a lectures profile, with selected set as by TRANSCRIPT_PROFILE.
+func profileConfigLoader(selected, lecturesDir string) *mockConfigLoader {
+	diarize := true
+	return &mockConfigLoader{
+		LoadFunc: func() (config.Config, error) {
+			return config.Config{
+				Profile: selected,
+				Profiles: map[string]config.Profile{
+					"meetings": {Name: "meetings", Template: "meeting", Provider: "openai", Diarize: &diarize},
+					"lectures": {Name: "lectures", Language: "fr", Parallel: "auto", OutputDir: lecturesDir},
+				},
+			}, nil
+		},
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		selected string // Profile of TRANSCRIPT_PROFILE
+		profile  string // --profile
+		want     map[string]string
+		wantName string
+	}{
+		{
+			name:     "sets the flags not given",
+			profile:  "meetings",
+			want:     map[string]string{"template": "meeting", "provider": "openai", "diarize": "true", "parallel": defaultParallel},
+			wantName: "meetings",
+		},
+		{
+			name:     "flags given win",
+			args:     []string{"--template", "notes", "--diarize=false"},
+			profile:  "meetings",
+			want:     map[string]string{"template": "notes", "provider": "openai", "diarize": "false"},
+			wantName: "meetings",
+		},
+		{
+			name:     "selected by the environment",
+			selected: "lectures",
+			want:     map[string]string{"language": "fr", "parallel": "auto", "template": ""},
+			wantName: "lectures",
+		},
+		{
+			name:     "flag overrides the environment",
+			selected: "lectures",
+			profile:  "meetings",
+			want:     map[string]string{"template": "meeting", "language": ""},
+			wantName: "meetings",
+		},
+		{
+			name: "no profile",
+			want: map[string]string{"template": "", "provider": ProviderDeepSeek, "diarize": "false"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env, mocks := testEnv()
+			mocks.configLoader.LoadFunc = profileConfigLoader(tt.selected, "").LoadFunc
+			cmd := TranscribeCmd(env)
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags() unexpected error: %v", err)
+			}
+
+			name, err := applyProfile(cmd, env, tt.profile)
+			if err != nil {
+				t.Fatalf("applyProfile() unexpected error: %v", err)
+			}
+			if name != tt.wantName {
+				t.Errorf("applyProfile() = %q, want %q", name, tt.wantName)
+			}
+			for flag, want := range tt.want {
+				if got := cmd.Flags().Lookup(flag).Value.String(); got != want {
+					t.Errorf("--%s = %q, want %q", flag, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyProfile_Unknown(t *testing.T) {
+	t.Parallel()
+
+	env, mocks := testEnv()
+	mocks.configLoader.LoadFunc = profileConfigLoader("", "").LoadFunc
+
+	_, err := applyProfile(TranscribeCmd(env), env, "podcasts")
+	if !errors.Is(err, config.ErrUnknownProfile) || !strings.Contains(err.Error(), "lectures, meetings") {
+		t.Errorf("applyProfile() error = %v, want ErrUnknownProfile listing the profiles", err)
+	}
+}
+
+func TestTranscribeCmd_ProfileOutputDir(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "meeting.ogg")
+	lecturesDir := t.TempDir()
+	env := checkpointTestEnv(t, &syncBuffer{}, nil)
+	env.ConfigLoader = profileConfigLoader("", lecturesDir)
+
+	cmd := TranscribeCmd(env)
+	cmd.SetContext(context.Background())
+	cmd.SetArgs([]string{inputPath, "--profile", "lectures"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(lecturesDir, "meeting.md")); err != nil {
+		t.Errorf("output not written to the output-dir of the profile: %v", err)
+	}
+}
+
+func TestValidateProfileSetting(t *testing.T) {
+	t.Parallel()
+
+	env, _ := testEnv()
+	outputDir := t.TempDir()
+
+	valid := []struct{ key, value string }{
+		{config.KeyTemplate, template.Meeting},
+		{config.KeyLanguage, "pt-BR"},
+		{config.KeyProvider, ProviderAnthropic},
+		{config.KeyDiarize, "true"},
+		{config.KeyParallel, "auto"},
+		{config.KeyParallel, "3"},
+		{config.KeyOutputDir, outputDir},
+	}
+	for _, tt := range valid {
+		if got, err := validateProfileSetting(env, tt.key, tt.value); err != nil || got != tt.value {
+			t.Errorf("validateProfileSetting(%q, %q) = %q, %v, want the value", tt.key, tt.value, got, err)
+		}
+	}
+
+	invalid := []struct {
+		key, value string
+		wantErr    error
+	}{
+		{config.KeyTemplate, "nonexistent", template.ErrUnknown},
+		{config.KeyProvider, "mistral", ErrInvalidProvider},
+		{config.KeyDiarize, "sometimes", config.ErrInvalidValue},
+		{config.KeyParallel, "many", ErrInvalidParallel},
+		{"model", "gpt-4o", config.ErrInvalidKey},
+	}
+	for _, tt := range invalid {
+		if _, err := validateProfileSetting(env, tt.key, tt.value); !errors.Is(err, tt.wantErr) {
+			t.Errorf("validateProfileSetting(%q, %q) error = %v, want %v", tt.key, tt.value, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,423 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/format"
+	"github.com/alnah/go-transcript/internal/progress"
+	"github.com/alnah/go-transcript/internal/schemas"
+)
+
+// Progress formats (--progress).
+const (
+	// ProgressText writes progress as messages for people.
+	ProgressText = "text"
+	// ProgressJSON writes progress events as JSON lines, for programs wrapping
+	// transcript. Events follow the progress schema (see schemas.Progress).
+	ProgressJSON = "json"
+)
+
+// Statuses of progress events.
+const (
+	statusStarted   = "started"
+	statusProgress  = "progress"
+	statusCompleted = "completed"
+	statusFailed    = "failed"
+)
+
+// recordingTick is the interval of recording progress reports.
+const recordingTick = time.Second
+
+// parseProgress validates a --progress value. Returns true for JSON lines.
+// Returns ErrInvalidProgress if the value is not recognized.
+func parseProgress(s string) (jsonLines bool, err error) {
+	switch s {
+	case ProgressText, "":
+		return false, nil
+	case ProgressJSON:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown progress format %q (use %s or %s): %w",
+			s, ProgressText, ProgressJSON, ErrInvalidProgress)
+	}
+}
+
+// runWithProgress runs fn with the --progress format of a command, whose
+// pipeline starts with phase first.
+// With text, cmd's context carries hooks reporting waits requested by APIs.
+// With json, fn gets an env whose stderr turns text lines into events, and
+// cmd's context carries hooks writing progress events; a final completed or
+// failed event reports the outcome of fn.
+func runWithProgress(cmd *cobra.Command, env *Env, progressFmt string, first progress.Phase, fn func(env *Env) error) error {
+	jsonLines, err := parseProgress(progressFmt)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !jsonLines {
+		cmd.SetContext(progress.WithHooks(ctx, textHooks(env.Stderr, progress.FromContext(ctx))))
+		return fn(env)
+	}
+
+	p := newJSONProgress(env.Stderr, env.Now, first)
+	cmd.SetContext(progress.WithHooks(ctx, p.hooks(progress.FromContext(ctx))))
+
+	jsonEnv := *env
+	jsonEnv.Stderr = p
+	err = fn(&jsonEnv)
+	p.finish(err)
+	return err
+}
+
+// progressEvent is a line of --progress json output.
+// Its format is described by the progress schema (see schemas.Progress).
+type progressEvent struct {
+	SchemaVersion int    `json:"schema_version"`
+	Time          string `json:"time"`
+	Input         string `json:"input,omitempty"` // Input file, when transcribing several files
+	Stage         string `json:"stage"`
+	Status        string `json:"status"`
+	// Chunks transcribed, restructuring step or seconds recorded, out of total.
+	Current int    `json:"current,omitempty"`
+	Total   int    `json:"total,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	Chunk      int      `json:"chunk,omitempty"` // 1-based number of the chunk transcribed
+	Step       string   `json:"step,omitempty"`  // Restructuring step: map, condense, reduce, sample, consensus
+	Percent    *float64 `json:"percent,omitempty"`
+	ETASeconds *float64 `json:"eta_seconds,omitempty"`
+	ErrorCode  string   `json:"error_code,omitempty"` // Code of a failed run (see transcript explain)
+}
+
+// stages maps pipeline phases to the stages of progress events.
+var stages = map[progress.Phase]string{
+	progress.PhaseRecording:     "record",
+	progress.PhaseDownloading:   "download",
+	progress.PhaseExtracting:    "extract",
+	progress.PhaseChunking:      "chunk",
+	progress.PhaseTranscribing:  "transcribe",
+	progress.PhaseRestructuring: "restructure",
+}
+
+// jsonProgress writes progress events as JSON lines to w.
+// As an io.Writer, it turns each line of text into a progress event.
+// It is safe for concurrent use.
+type jsonProgress struct {
+	w       io.Writer
+	writeMu *sync.Mutex // Shared with the writers of each input, see forInput
+	now     func() time.Time
+	input   string
+
+	mu         sync.Mutex // Guards the fields below
+	phase      progress.Phase
+	started    bool // Whether the started event of phase was written
+	phaseStart time.Time
+	done       int    // Chunks transcribed in the phase
+	partial    []byte // Text written without a final newline yet
+}
+
+// newJSONProgress creates a JSON lines progress writer to w, for a pipeline
+// starting with phase first: errors before it starts are reported in it.
+func newJSONProgress(w io.Writer, now func() time.Time, first progress.Phase) *jsonProgress {
+	return &jsonProgress{w: w, writeMu: &sync.Mutex{}, now: now, phase: first}
+}
+
+// forInput returns a progress writer for the transcription of input, among
+// several: its events carry the input name and its own stages.
+func (p *jsonProgress) forInput(input string) *jsonProgress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return &jsonProgress{w: p.w, writeMu: p.writeMu, now: p.now, input: input, phase: p.phase}
+}
+
+// hooks returns the progress hooks writing events, which also call prev.
+func (p *jsonProgress) hooks(prev progress.Hooks) progress.Hooks {
+	return progress.Hooks{
+		OnPhaseChange: func(phase progress.Phase) {
+			p.phaseChange(phase)
+			if prev.OnPhaseChange != nil {
+				prev.OnPhaseChange(phase)
+			}
+		},
+		OnChunkStart: prev.OnChunkStart,
+		OnChunkDone: func(index, total int, err error) {
+			p.chunkDone(index, total, err)
+			if prev.OnChunkDone != nil {
+				prev.OnChunkDone(index, total, err)
+			}
+		},
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			p.emit(progressEvent{Status: statusProgress, Message: retryMessage(attempt, delay, err)})
+			if prev.OnRetry != nil {
+				prev.OnRetry(attempt, delay, err)
+			}
+		},
+		OnRecording: func(elapsed, duration time.Duration) {
+			p.recording(elapsed, duration)
+			if prev.OnRecording != nil {
+				prev.OnRecording(elapsed, duration)
+			}
+		},
+		OnDownload: func(done, total int64) {
+			p.download(done, total)
+			if prev.OnDownload != nil {
+				prev.OnDownload(done, total)
+			}
+		},
+		OnExtraction: func(done, total time.Duration) {
+			p.extraction(done, total)
+			if prev.OnExtraction != nil {
+				prev.OnExtraction(done, total)
+			}
+		},
+		OnStep: func(step string, current, total int) {
+			p.emit(progressEvent{Status: statusProgress, Step: step, Current: current, Total: total})
+			if prev.OnStep != nil {
+				prev.OnStep(step, current, total)
+			}
+		},
+	}
+}
+
+// phaseChange completes the current stage, if started, and starts phase.
+func (p *jsonProgress) phaseChange(phase progress.Phase) {
+	p.mu.Lock()
+	previous, started := p.phase, p.started
+	p.phase, p.started, p.phaseStart, p.done = phase, true, p.now(), 0
+	p.mu.Unlock()
+
+	if started && previous != phase {
+		p.emitIn(previous, progressEvent{Status: statusCompleted})
+	}
+	p.emit(progressEvent{Status: statusStarted})
+}
+
+// chunkDone reports a transcribed chunk, with the percent of chunks done and
+// the time left at the pace of the stage so far. Chunks reused by --resume
+// are not reported, so the percent only reaches 100 on a fresh run.
+func (p *jsonProgress) chunkDone(index, total int, err error) {
+	event := progressEvent{Status: statusProgress, Chunk: index + 1, Total: total}
+	if err != nil {
+		event.Message = fmt.Sprintf("Chunk %d failed: %v", index+1, err)
+	}
+	p.mu.Lock()
+	if err == nil {
+		p.done++
+	}
+	event.Current = p.done
+	if total > 0 && p.done > 0 {
+		elapsed := p.now().Sub(p.phaseStart)
+		event.Percent = percent(float64(p.done), float64(total))
+		event.ETASeconds = seconds(elapsed / time.Duration(p.done) * time.Duration(max(total-p.done, 0)))
+	}
+	p.mu.Unlock()
+	p.emit(event)
+}
+
+// recording reports the time recorded so far, in seconds.
+func (p *jsonProgress) recording(elapsed, duration time.Duration) {
+	event := progressEvent{
+		Status:  statusProgress,
+		Current: int(elapsed.Round(time.Second).Seconds()),
+		Total:   int(duration.Round(time.Second).Seconds()),
+	}
+	if duration > 0 {
+		event.Percent = percent(elapsed.Seconds(), duration.Seconds())
+		event.ETASeconds = seconds(max(duration-elapsed, 0))
+	}
+	p.emit(event)
+}
+
+// download reports the bytes downloaded so far, with the time left at the
+// pace of the stage so far.
+func (p *jsonProgress) download(done, total int64) {
+	event := progressEvent{Status: statusProgress, Current: int(done), Total: int(total)}
+	if total > 0 && done > 0 {
+		p.mu.Lock()
+		elapsed := p.now().Sub(p.phaseStart)
+		p.mu.Unlock()
+		event.Percent = percent(float64(done), float64(total))
+		event.ETASeconds = seconds(time.Duration(float64(elapsed) * float64(max(total-done, 0)) / float64(done)))
+	}
+	p.emit(event)
+}
+
+// extraction reports the seconds of audio extracted so far, with the time left
+// at the pace of the stage so far.
+func (p *jsonProgress) extraction(done, total time.Duration) {
+	event := progressEvent{
+		Status:  statusProgress,
+		Current: int(done.Round(time.Second).Seconds()),
+		Total:   int(total.Round(time.Second).Seconds()),
+	}
+	if total > 0 && done > 0 {
+		p.mu.Lock()
+		elapsed := p.now().Sub(p.phaseStart)
+		p.mu.Unlock()
+		event.Percent = percent(done.Seconds(), total.Seconds())
+		event.ETASeconds = seconds(time.Duration(float64(elapsed) * max(total-done, 0).Seconds() / done.Seconds()))
+	}
+	p.emit(event)
+}
+
+// Write turns each complete line of b into a progress event with a message.
+// Empty lines are dropped; a partial line waits for the next write or flush.
+func (p *jsonProgress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	p.partial = append(p.partial, b...)
+	var lines []string
+	for {
+		i := bytes.IndexByte(p.partial, '\n')
+		if i < 0 {
+			break
+		}
+		lines = append(lines, string(p.partial[:i]))
+		p.partial = p.partial[i+1:]
+	}
+	p.mu.Unlock()
+
+	for _, line := range lines {
+		p.message(line)
+	}
+	return len(b), nil
+}
+
+// message emits a progress event for a line of text.
+func (p *jsonProgress) message(line string) {
+	// Carriage returns redraw terminal lines: keep the last drawing
+	if i := strings.LastIndexByte(line, '\r'); i >= 0 {
+		line = line[i+1:]
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		p.emit(progressEvent{Status: statusProgress, Message: line})
+	}
+}
+
+// flush emits a pending partial line.
+func (p *jsonProgress) flush() {
+	p.mu.Lock()
+	partial := string(p.partial)
+	p.partial = nil
+	p.mu.Unlock()
+	p.message(partial)
+}
+
+// finish flushes a pending partial line and reports the outcome of the run in
+// the current stage: completed, or failed with the error code, if documented.
+func (p *jsonProgress) finish(err error) {
+	p.flush()
+	if err == nil {
+		p.emit(progressEvent{Status: statusCompleted})
+		return
+	}
+	event := progressEvent{Status: statusFailed, Message: err.Error()}
+	if info, ok := LookupError(err); ok {
+		event.ErrorCode = info.Code
+	}
+	p.emit(event)
+}
+
+// emit writes event in the current stage.
+func (p *jsonProgress) emit(event progressEvent) {
+	p.mu.Lock()
+	phase := p.phase
+	p.mu.Unlock()
+	p.emitIn(phase, event)
+}
+
+// emitIn writes event as a JSON line in the stage of phase.
+func (p *jsonProgress) emitIn(phase progress.Phase, event progressEvent) {
+	event.SchemaVersion = schemas.ProgressVersion
+	event.Time = p.now().UTC().Format(time.RFC3339Nano)
+	event.Input = p.input
+	event.Stage = stages[phase]
+	line, err := json.Marshal(event)
+	if err != nil {
+		return // Unreachable: events only hold encodable values
+	}
+
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	_, _ = p.w.Write(append(line, '\n'))
+}
+
+// textHooks returns the progress hooks of text output to w, which also call
+// prev: other progress is printed by the commands themselves.
+func textHooks(w io.Writer, prev progress.Hooks) progress.Hooks {
+	hooks := prev
+	hooks.OnRetry = func(attempt int, delay time.Duration, err error) {
+		// Backoff retries are short and silent; a wait requested by the API
+		// may be long enough to look like a hang.
+		if _, ok := apierr.RetryAfter(err); ok {
+			fmt.Fprintln(w, retryMessage(attempt, delay, err))
+		}
+		if prev.OnRetry != nil {
+			prev.OnRetry(attempt, delay, err)
+		}
+	}
+	return hooks
+}
+
+// retryMessage describes a retry, and whether the API requested its delay.
+func retryMessage(attempt int, delay time.Duration, err error) string {
+	if _, ok := apierr.RetryAfter(err); ok {
+		return fmt.Sprintf("Retry %d in %s, as requested by the API: %v", attempt, format.DurationHuman(delay), err)
+	}
+	return fmt.Sprintf("Retry %d in %s: %v", attempt, format.DurationHuman(delay), err)
+}
+
+// percent returns done out of total, in percent with one decimal.
+func percent(done, total float64) *float64 {
+	v := math.Round(min(done/total, 1)*1000) / 10
+	return &v
+}
+
+// seconds returns d in whole seconds.
+func seconds(d time.Duration) *float64 {
+	v := math.Round(d.Seconds())
+	return &v
+}
+
+// reportRecording reports the time recorded to the progress hooks of ctx every
+// recordingTick, for a recording of duration, until the returned function is
+// called. Does nothing if ctx has no OnRecording hook.
+func reportRecording(ctx context.Context, now func() time.Time, duration time.Duration) (stop func()) {
+	if progress.FromContext(ctx).OnRecording == nil {
+		return func() {}
+	}
+	start := now()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(recordingTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				progress.Recording(ctx, min(now().Sub(start), duration), duration)
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
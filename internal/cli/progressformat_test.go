@@ -0,0 +1,449 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/progress"
+	"github.com/alnah/go-transcript/internal/schemas"
+)
+
+// decodeProgress parses the JSON lines written by a jsonProgress.
+func decodeProgress(t *testing.T, out string) []progressEvent {
+	t.Helper()
+	var events []progressEvent
+	for line := range strings.Lines(out) {
+		var event progressEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// eventNames returns the stage and status of events.
+func eventNames(events []progressEvent) []string {
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = e.Stage + " " + e.Status
+	}
+	return names
+}
+
+// valueOf formats an optional number for messages.
+func valueOf(v *float64) string {
+	if v == nil {
+		return "<nil>"
+	}
+	return fmt.Sprint(*v)
+}
+
+func TestParseProgress(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input     string
+		wantJSON  bool
+		wantError bool
+	}{
+		{"text", false, false},
+		{"", false, false},
+		{"json", true, false},
+		{"JSON", false, true},
+		{"xml", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseProgress(tt.input)
+			if tt.wantError {
+				if !errors.Is(err, ErrInvalidProgress) {
+					t.Errorf("parseProgress(%q) error = %v, want ErrInvalidProgress", tt.input, err)
+				}
+				return
+			}
+			if err != nil || got != tt.wantJSON {
+				t.Errorf("parseProgress(%q) = %v, %v; want %v", tt.input, got, err, tt.wantJSON)
+			}
+		})
+	}
+}
+
+func TestJSONProgress_Events(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	now := start
+	var out bytes.Buffer
+	p := newJSONProgress(&out, func() time.Time { return now }, progress.PhaseRecording)
+	hooks := p.hooks(progress.Hooks{})
+
+	hooks.OnPhaseChange(progress.PhaseRecording)
+	hooks.OnRecording(30*time.Second, 2*time.Minute)
+	hooks.OnPhaseChange(progress.PhaseTranscribing)
+	now = start.Add(10 * time.Second)
+	hooks.OnChunkDone(0, 4, nil)
+	hooks.OnRetry(1, 2*time.Second, errors.New("rate limited"))
+	hooks.OnChunkDone(1, 4, errors.New("timeout"))
+	hooks.OnChunkDone(2, 0, nil) // live --stream: total unknown
+	hooks.OnPhaseChange(progress.PhaseRestructuring)
+	hooks.OnStep("map", 2, 3)
+
+	events := decodeProgress(t, out.String())
+	want := []string{
+		"record started", "record progress", "record completed", "transcribe started",
+		"transcribe progress", "transcribe progress", "transcribe progress", "transcribe progress",
+		"transcribe completed", "restructure started", "restructure progress",
+	}
+	if got := eventNames(events); !slices.Equal(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+
+	for _, e := range events {
+		if e.SchemaVersion != schemas.ProgressVersion || e.Input != "" {
+			t.Fatalf("event = %+v, want schema_version %d and no input", e, schemas.ProgressVersion)
+		}
+	}
+	if e := events[0]; e.Time != "2026-03-01T09:30:00Z" {
+		t.Errorf("time = %q, want 2026-03-01T09:30:00Z", e.Time)
+	}
+	if e := events[1]; e.Current != 30 || e.Total != 120 || valueOf(e.Percent) != "25" || valueOf(e.ETASeconds) != "90" {
+		t.Errorf("recording = %+v, percent %s, eta %s", e, valueOf(e.Percent), valueOf(e.ETASeconds))
+	}
+	// 1 of 4 chunks in 10s: 3 left at the same pace
+	if e := events[4]; e.Chunk != 1 || e.Current != 1 || e.Total != 4 ||
+		valueOf(e.Percent) != "25" || valueOf(e.ETASeconds) != "30" {
+		t.Errorf("chunk done = %+v, percent %s, eta %s", e, valueOf(e.Percent), valueOf(e.ETASeconds))
+	}
+	if e := events[5]; e.Message != "Retry 1 in 2s: rate limited" {
+		t.Errorf("retry = %+v", e)
+	}
+	if e := events[6]; e.Chunk != 2 || e.Current != 1 || e.Message != "Chunk 2 failed: timeout" {
+		t.Errorf("failed chunk = %+v", e)
+	}
+	if e := events[7]; e.Chunk != 3 || e.Total != 0 || e.Percent != nil || e.ETASeconds != nil {
+		t.Errorf("streamed chunk = %+v, want no total, percent nor eta", e)
+	}
+	if e := events[10]; e.Step != "map" || e.Current != 2 || e.Total != 3 {
+		t.Errorf("step = %+v", e)
+	}
+}
+
+func TestJSONProgress_Extraction(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	now := start
+	var out bytes.Buffer
+	p := newJSONProgress(&out, func() time.Time { return now }, progress.PhaseExtracting)
+	hooks := p.hooks(progress.Hooks{})
+
+	hooks.OnPhaseChange(progress.PhaseExtracting)
+	now = start.Add(10 * time.Second)
+	hooks.OnExtraction(15*time.Minute, time.Hour)
+	hooks.OnExtraction(time.Minute, 0) // Duration unknown
+	hooks.OnPhaseChange(progress.PhaseChunking)
+
+	events := decodeProgress(t, out.String())
+	want := []string{"extract started", "extract progress", "extract progress", "extract completed", "chunk started"}
+	if got := eventNames(events); !slices.Equal(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	// A quarter extracted in 10s: three quarters left at the same pace
+	if e := events[1]; e.Current != 900 || e.Total != 3600 || valueOf(e.Percent) != "25" || valueOf(e.ETASeconds) != "30" {
+		t.Errorf("extraction = %+v, percent %s, eta %s", e, valueOf(e.Percent), valueOf(e.ETASeconds))
+	}
+	if e := events[2]; e.Current != 60 || e.Total != 0 || e.Percent != nil || e.ETASeconds != nil {
+		t.Errorf("extraction without duration = %+v, want no total, percent nor eta", e)
+	}
+}
+
+func TestJSONProgress_Download(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	now := start
+	var out bytes.Buffer
+	p := newJSONProgress(&out, func() time.Time { return now }, progress.PhaseDownloading)
+	hooks := p.hooks(progress.Hooks{})
+
+	hooks.OnPhaseChange(progress.PhaseDownloading)
+	now = start.Add(20 * time.Second)
+	hooks.OnDownload(1<<20, 5<<20)
+	hooks.OnDownload(2<<20, 0) // Size unknown
+	hooks.OnPhaseChange(progress.PhaseChunking)
+
+	events := decodeProgress(t, out.String())
+	want := []string{"download started", "download progress", "download progress", "download completed", "chunk started"}
+	if got := eventNames(events); !slices.Equal(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	// A fifth downloaded in 20s: four fifths left at the same pace
+	if e := events[1]; e.Current != 1<<20 || e.Total != 5<<20 || valueOf(e.Percent) != "20" || valueOf(e.ETASeconds) != "80" {
+		t.Errorf("download = %+v, percent %s, eta %s", e, valueOf(e.Percent), valueOf(e.ETASeconds))
+	}
+	if e := events[2]; e.Current != 2<<20 || e.Total != 0 || e.Percent != nil || e.ETASeconds != nil {
+		t.Errorf("download without size = %+v, want no total, percent nor eta", e)
+	}
+}
+
+func TestJSONProgress_Messages(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	p := newJSONProgress(&out, fixedTime(time.Now()), progress.PhaseChunking)
+
+	fmt.Fprintln(p, "Chunking audio...")
+	fmt.Fprint(p, "\n  \n")
+	fmt.Fprint(p, "\r  [#---]  -30 dB\r  [##--]  -20 dB")
+	fmt.Fprint(p, "\nSaving ")
+	fmt.Fprint(p, "output")
+	p.finish(nil)
+
+	events := decodeProgress(t, out.String())
+	var messages []string
+	for _, e := range events[:len(events)-1] {
+		messages = append(messages, e.Message)
+	}
+	want := []string{"Chunking audio...", "[##--]  -20 dB", "Saving output"}
+	if !slices.Equal(messages, want) {
+		t.Errorf("messages = %q, want %q", messages, want)
+	}
+	if got := eventNames(events[len(events)-1:]); got[0] != "chunk completed" {
+		t.Errorf("last event = %v, want chunk completed", got)
+	}
+}
+
+func TestJSONProgress_FinishWithError(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	p := newJSONProgress(&out, fixedTime(time.Now()), progress.PhaseChunking)
+	p.finish(fmt.Errorf("unsupported format %q: %w", ".xyz", ErrUnsupportedFormat))
+
+	events := decodeProgress(t, out.String())
+	if len(events) != 1 {
+		t.Fatalf("events = %+v, want 1", events)
+	}
+	if e := events[0]; e.Stage != "chunk" || e.Status != "failed" || e.ErrorCode != "TR-0402" || !strings.Contains(e.Message, ".xyz") {
+		t.Errorf("failed event = %+v", e)
+	}
+}
+
+func TestJSONProgress_ForInput(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	p := newJSONProgress(&out, fixedTime(time.Now()), progress.PhaseChunking)
+	a, b := p.forInput("a.ogg"), p.forInput("b.ogg")
+
+	a.hooks(progress.Hooks{}).OnPhaseChange(progress.PhaseTranscribing)
+	fmt.Fprintln(b, "Chunking audio...")
+	fmt.Fprintln(p, "[1/2] Done: a.ogg")
+
+	events := decodeProgress(t, out.String())
+	if len(events) != 3 {
+		t.Fatalf("events = %+v, want 3", events)
+	}
+	if e := events[0]; e.Input != "a.ogg" || e.Stage != "transcribe" || e.Status != "started" {
+		t.Errorf("event of a.ogg = %+v", e)
+	}
+	if e := events[1]; e.Input != "b.ogg" || e.Stage != "chunk" || e.Message != "Chunking audio..." {
+		t.Errorf("event of b.ogg = %+v", e)
+	}
+	if e := events[2]; e.Input != "" || e.Message != "[1/2] Done: a.ogg" {
+		t.Errorf("batch event = %+v", e)
+	}
+}
+
+func TestRunWithProgress(t *testing.T) {
+	t.Parallel()
+
+	t.Run("text runs with the command env", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		var got *Env
+		err := runWithProgress(&cobra.Command{}, env, ProgressText, progress.PhaseChunking, func(env *Env) error {
+			got = env
+			return nil
+		})
+		if err != nil || got != env {
+			t.Errorf("runWithProgress() = %v, env %p; want nil, %p", err, got, env)
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		called := false
+		err := runWithProgress(&cobra.Command{}, env, "xml", progress.PhaseChunking, func(*Env) error {
+			called = true
+			return nil
+		})
+		if !errors.Is(err, ErrInvalidProgress) || called {
+			t.Errorf("runWithProgress() = %v, called %v; want ErrInvalidProgress, not called", err, called)
+		}
+	})
+
+	t.Run("json writes events and keeps earlier hooks", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		stderr := env.Stderr.(*syncBuffer)
+		var phases []progress.Phase
+		cmd := &cobra.Command{}
+		cmd.SetContext(progress.WithHooks(context.Background(), progress.Hooks{
+			OnPhaseChange: func(phase progress.Phase) { phases = append(phases, phase) },
+		}))
+
+		err := runWithProgress(cmd, env, ProgressJSON, progress.PhaseChunking, func(env *Env) error {
+			progress.PhaseChange(cmd.Context(), progress.PhaseChunking)
+			fmt.Fprintln(env.Stderr, "Chunking audio...")
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("runWithProgress() error = %v", err)
+		}
+
+		events := decodeProgress(t, stderr.String())
+		if got, want := eventNames(events), []string{"chunk started", "chunk progress", "chunk completed"}; !slices.Equal(got, want) {
+			t.Errorf("events = %v, want %v", got, want)
+		}
+		if len(phases) != 1 || phases[0] != progress.PhaseChunking {
+			t.Errorf("earlier hook got phases %v, want [chunking]", phases)
+		}
+	})
+
+	t.Run("json reports validation errors", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		stderr := env.Stderr.(*syncBuffer)
+		cmd := TranscribeCmd(env)
+		cmd.SetArgs([]string{createTestAudioFile(t, "audio.xyz"), "--progress", "json"})
+
+		if err := cmd.Execute(); !errors.Is(err, ErrUnsupportedFormat) {
+			t.Fatalf("cmd.Execute() error = %v, want ErrUnsupportedFormat", err)
+		}
+		events := decodeProgress(t, stderr.String())
+		if last := events[len(events)-1]; last.Status != "failed" || last.ErrorCode != "TR-0402" {
+			t.Errorf("last event = %+v, want failed with TR-0402", last)
+		}
+	})
+}
+
+func TestRunWithProgress_RetryAfter(t *testing.T) {
+	t.Parallel()
+
+	requested := apierr.WithRetryAfter(fmt.Errorf("slow down: %w", apierr.ErrRateLimit), 20*time.Second)
+
+	t.Run("text reports waits requested by the API", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		stderr := env.Stderr.(*syncBuffer)
+		cmd := &cobra.Command{}
+		err := runWithProgress(cmd, env, ProgressText, progress.PhaseTranscribing, func(env *Env) error {
+			progress.Retry(cmd.Context(), 1, time.Second, errors.New("timeout"))
+			progress.Retry(cmd.Context(), 2, 20*time.Second, requested)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("runWithProgress() error = %v", err)
+		}
+
+		want := "Retry 2 in 20s, as requested by the API: slow down: rate limit exceeded\n"
+		if got := stderr.String(); got != want {
+			t.Errorf("stderr = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("json marks waits requested by the API", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		stderr := env.Stderr.(*syncBuffer)
+		cmd := &cobra.Command{}
+		err := runWithProgress(cmd, env, ProgressJSON, progress.PhaseTranscribing, func(env *Env) error {
+			progress.Retry(cmd.Context(), 1, 20*time.Second, requested)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("runWithProgress() error = %v", err)
+		}
+
+		events := decodeProgress(t, stderr.String())
+		if want := "Retry 1 in 20s, as requested by the API: slow down: rate limit exceeded"; events[0].Message != want {
+			t.Errorf("retry event = %+v, want message %q", events[0], want)
+		}
+	})
+}
+
+func TestReportRecording_WithoutHook(t *testing.T) {
+	t.Parallel()
+
+	// Without an OnRecording hook, nothing runs: stop returns at once.
+	stop := reportRecording(context.Background(), time.Now, time.Hour)
+	stop()
+}
+
+func TestProgressEvent_MatchesSchema(t *testing.T) {
+	t.Parallel()
+
+	data, err := schemas.Get(schemas.Progress)
+	if err != nil {
+		t.Fatalf("schemas.Get() error = %v", err)
+	}
+	var schema struct {
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("invalid progress schema: %v", err)
+	}
+
+	v := 1.0
+	line, err := json.Marshal(progressEvent{
+		SchemaVersion: 1, Time: "t", Input: "a.ogg", Stage: "transcribe", Status: statusProgress,
+		Current: 1, Total: 2, Message: "m", Chunk: 1, Step: "map", Percent: &v, ETASeconds: &v, ErrorCode: "TR-0402",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(line, &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	for name := range fields {
+		if _, ok := schema.Properties[name]; !ok {
+			t.Errorf("field %q is not in the progress schema", name)
+		}
+	}
+	for _, name := range schema.Required {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("required field %q is missing", name)
+		}
+	}
+	for stage := range maps.Values(stages) {
+		if !strings.Contains(string(schema.Properties["stage"]), `"`+stage+`"`) {
+			t.Errorf("stage %q is not in the progress schema", stage)
+		}
+	}
+}
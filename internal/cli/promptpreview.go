@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/alnah/go-transcript/internal/restructure"
+)
+
+// Characters of the transcript kept at the start and end of each user message
+// printed by --show-prompt. The rest is elided.
+const (
+	previewHeadChars = 1000
+	previewTailChars = 500
+)
+
+// printPromptPreview writes the calls restructuring would make to w: the
+// system prompt of each in full, and its user message with the middle of the
+// transcript elided.
+func printPromptPreview(w io.Writer, provider Provider, model string, calls []restructure.PromptPreview) {
+	plural := "s"
+	if len(calls) == 1 {
+		plural = ""
+	}
+	fmt.Fprintf(w, "Restructuring with %s (%s): %d call%s, nothing sent\n", provider, model, len(calls), plural)
+
+	for i, call := range calls {
+		step := call.Step
+		if call.Part > 0 {
+			step = fmt.Sprintf("%s, part %d", step, call.Part)
+		}
+		fmt.Fprintf(w, "\n=== Call %d/%d: %s ===\n", i+1, len(calls), step)
+		fmt.Fprintf(w, "\n--- system ---\n%s\n", call.System)
+		fmt.Fprintf(w, "\n--- user ---\n%s\n", elideMiddle(call.User, previewHeadChars, previewTailChars))
+	}
+}
+
+// elideMiddle returns s with all but its first head and last tail characters
+// replaced by a note of how many were left out. s is returned unchanged if
+// eliding would not shorten it.
+func elideMiddle(s string, head, tail int) string {
+	runes := []rune(s)
+	elided := len(runes) - head - tail
+	note := fmt.Sprintf("\n[... %d characters elided ...]\n", elided)
+	if elided <= len(note) {
+		return s
+	}
+	return string(runes[:head]) + note + string(runes[len(runes)-tail:])
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestElideMiddle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "short text unchanged", s: "héllo wörld", want: "héllo wörld"},
+		{name: "barely longer unchanged", s: strings.Repeat("a", 40), want: strings.Repeat("a", 40)},
+		{
+			name: "middle elided",
+			s:    "début " + strings.Repeat("x", 100) + " fin",
+			want: "début\n[... 101 characters elided ...]\n fin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := elideMiddle(tt.s, 5, 4); got != tt.want {
+				t.Errorf("elideMiddle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
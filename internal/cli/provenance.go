@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/provenance"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// Provenance flag help, shared by the transcribe and live commands.
+const (
+	provenanceFlagHelp = "Append a provenance footer: version, models, date and SHA-256 of the audio"
+	signKeyFlagHelp    = "Sign the output with this minisign secret key into <output>.minisig (implies --provenance)"
+)
+
+// provenanceStamp appends provenance footers to outputs and signs them
+// (--provenance, --sign-key). A nil *provenanceStamp leaves outputs unchanged.
+type provenanceStamp struct {
+	tool string
+	key  *provenance.Key // nil without --sign-key
+}
+
+// newProvenanceStamp creates the stamp of the --provenance and --sign-key flags.
+// Returns nil if neither is set. The key is loaded up front, so that an
+// unusable key fails before any API call (provenance.ErrInvalidKey).
+func newProvenanceStamp(env *Env, enabled bool, keyPath string) (*provenanceStamp, error) {
+	if !enabled && keyPath == "" {
+		return nil, nil
+	}
+	version := env.Version
+	if version == "" {
+		version = "dev"
+	}
+	s := &provenanceStamp{tool: "go-transcript " + version}
+	if keyPath != "" {
+		key, err := provenance.LoadKey(config.ExpandPath(keyPath))
+		if err != nil {
+			return nil, err
+		}
+		s.key = key
+	}
+	return s, nil
+}
+
+// validateProvenanceFormat checks that outputs of format f can hold a footer.
+func validateProvenanceFormat(s *provenanceStamp, f OutputFormat) error {
+	if s != nil && f.OrDefault() == SRTFormat {
+		return fmt.Errorf("--provenance cannot be written to SRT subtitles, which have no comments (use --format vtt)")
+	}
+	return nil
+}
+
+// signed reports whether outputs are signed (--sign-key).
+func (s *provenanceStamp) signed() bool {
+	return s != nil && s.key != nil
+}
+
+// signaturePath returns the path of the signature of output.
+func signaturePath(output string) string {
+	return output + ".minisig"
+}
+
+// apply returns content, written to output in format f, with the provenance
+// footer of the run appended: the models recorded by r and the hash of the
+// audio at audioPath.
+func (s *provenanceStamp) apply(env *Env, content, output, audioPath string, f OutputFormat, r *runReport, tmpl template.Name) (string, error) {
+	if s == nil {
+		return content, nil
+	}
+	hash, err := provenance.HashFile(audioPath)
+	if err != nil {
+		return "", err
+	}
+
+	record := provenance.Record{
+		Tool:          s.tool,
+		Transcription: r.transcriptionModel,
+		Date:          env.Now(),
+		AudioSHA256:   hash,
+	}
+	if record.Transcription == "" {
+		record.Transcription = "local backend"
+	}
+	if r.restructureModel != "" {
+		record.Restructuring = fmt.Sprintf("%s, template %s", r.restructureModel, tmpl)
+	}
+	if s.key != nil {
+		record.Signature = fmt.Sprintf("%s, minisign key %s", filepath.Base(signaturePath(output)), s.key.ID())
+	}
+
+	footer := record.Markdown()
+	switch f.OrDefault() {
+	case TextFormat:
+		footer = record.Text()
+	case VTTFormat:
+		footer = record.VTT()
+	}
+	return strings.TrimRight(content, "\n") + footer, nil
+}
+
+// sign writes the signature of content, the content written to output, next
+// to it. Does nothing without --sign-key.
+func (s *provenanceStamp) sign(env *Env, output, content string) error {
+	if s == nil || s.key == nil {
+		return nil
+	}
+	comment := fmt.Sprintf("timestamp:%d\tfile:%s", env.Now().Unix(), filepath.Base(output))
+	path := signaturePath(output)
+	if err := os.WriteFile(path, []byte(s.key.Sign([]byte(content), comment)), 0644); err != nil { // #nosec G306 -- same permissions as outputs
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	fmt.Fprintf(env.Stderr, "Signed: %s\n", path)
+	return nil
+}
@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/provenance"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// writeMinisignKey writes priv as an unencrypted minisign secret key and
+// returns its path.
+func writeMinisignKey(t *testing.T, priv ed25519.PrivateKey) string {
+	t.Helper()
+	raw := make([]byte, 0, 158)
+	raw = append(raw, "Ed\x00\x00B2"...)
+	raw = append(raw, make([]byte, 48)...)
+	raw = append(raw, 1, 2, 3, 4, 5, 6, 7, 8)
+	raw = append(raw, priv...)
+	raw = append(raw, make([]byte, 32)...)
+
+	path := filepath.Join(t.TempDir(), "transcript.key")
+	data := "untrusted comment: minisign secret key\n" + base64.StdEncoding.EncodeToString(raw) + "\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return path
+}
+
+func TestNewProvenanceStamp(t *testing.T) {
+	t.Parallel()
+
+	t.Run("disabled without flags", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		stamp, err := newProvenanceStamp(env, false, "")
+		if err != nil || stamp != nil {
+			t.Errorf("newProvenanceStamp() = %v, %v, want nil, nil", stamp, err)
+		}
+	})
+
+	t.Run("records the version", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		env.Version = "1.4.0 (commit: 1a2b3c4)"
+		stamp, err := newProvenanceStamp(env, true, "")
+		if err != nil {
+			t.Fatalf("newProvenanceStamp() unexpected error: %v", err)
+		}
+		if want := "go-transcript 1.4.0 (commit: 1a2b3c4)"; stamp.tool != want {
+			t.Errorf("tool = %q, want %q", stamp.tool, want)
+		}
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		_, err := newProvenanceStamp(env, false, filepath.Join(t.TempDir(), "missing.key"))
+		if !errors.Is(err, provenance.ErrInvalidKey) {
+			t.Errorf("newProvenanceStamp() error = %v, want ErrInvalidKey", err)
+		}
+	})
+}
+
+func TestRunTranscribe_Provenance(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	output := filepath.Join(t.TempDir(), "out.md")
+
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "Hello.", nil
+	})
+	env.Now = fixedTime(time.Date(2026, 1, 26, 14, 30, 52, 0, time.UTC))
+	opts := mustParseTranscribeOptions(t, inputPath, output, "", false, 5, "", "", "deepseek")
+	if opts.provenance, err = newProvenanceStamp(env, false, writeMinisignKey(t, priv)); err != nil {
+		t.Fatalf("newProvenanceStamp() unexpected error: %v", err)
+	}
+
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	hash, _ := provenance.HashFile(inputPath)
+	for _, want := range []string{
+		"Hello.\n\nHello.\n\n---\n\n**Provenance**\n\n",
+		"- Tool: go-transcript dev\n",
+		"- Transcription: " + transcribe.Model(transcribe.Options{}) + "\n",
+		"- Date: 2026-01-26T14:30:52Z\n",
+		"- Audio SHA-256: `" + hash + "`\n",
+		"- Signature: out.md.minisig, minisign key 0807060504030201\n",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("output = %q, want it to contain %q", content, want)
+		}
+	}
+
+	sig, err := os.ReadFile(output + ".minisig")
+	if err != nil {
+		t.Fatalf("failed to read signature: %v", err)
+	}
+	lines := strings.Split(string(sig), "\n")
+	sigLine, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sigLine) != 74 {
+		t.Fatalf("signature line = %q (err: %v)", lines[1], err)
+	}
+	if !ed25519.Verify(pub, content, sigLine[10:]) {
+		t.Error("signature does not verify the output")
+	}
+	if lines[2] != "trusted comment: timestamp:1769437852\tfile:out.md" {
+		t.Errorf("trusted comment = %q", lines[2])
+	}
+}
+
+func TestRunTranscribe_ProvenanceRejectsSRT(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	env := checkpointTestEnv(t, &syncBuffer{}, nil)
+
+	opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "out.srt"), "", false, 5, "", "", "deepseek")
+	opts.format = SRTFormat
+	opts.provenance = &provenanceStamp{tool: "go-transcript dev"}
+	err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts)
+	if err == nil || !strings.Contains(err.Error(), "--format vtt") {
+		t.Errorf("RunTranscribe() error = %v, want SRT rejected", err)
+	}
+}
+
+func TestRunLive_Provenance(t *testing.T) {
+	t.Parallel()
+
+	output := filepath.Join(t.TempDir(), "live.md")
+	recorder := &mockRecorder{
+		RecordFunc: func(ctx context.Context, duration time.Duration, output string) error {
+			return os.WriteFile(output, []byte("audio data"), 0644)
+		},
+	}
+	env := &Env{
+		Stderr:             &syncBuffer{},
+		Getenv:             defaultTestEnv,
+		Now:                fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver:     &mockFFmpegResolver{},
+		ConfigLoader:       &mockConfigLoader{},
+		RecorderFactory:    &mockRecorderFactory{mockRecorder: recorder},
+		MediaProberFactory: &mockMediaProberFactory{},
+		ChunkerFactory: &mockChunkerFactory{
+			NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
+				return &mockChunker{ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+					return []audio.Chunk{{Path: filepath.Join(t.TempDir(), "chunk_0.ogg"), Index: 0}}, nil
+				}}, nil
+			},
+		},
+		TranscriberFactory: &mockTranscriberFactory{
+			NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+				return &mockTranscriber{TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+					return "Live.", nil
+				}}
+			},
+		},
+	}
+	opts := liveOptions{
+		provider:   DeepSeekProvider,
+		duration:   time.Minute,
+		output:     output,
+		format:     TextFormat,
+		provenance: &provenanceStamp{tool: "go-transcript dev"},
+	}
+
+	if err := RunLive(context.Background(), env, opts); err != nil {
+		t.Fatalf("RunLive() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	// SHA-256 of the recording ("audio data").
+	want := "Live.\n\nProvenance\n" +
+		"  Tool: go-transcript dev\n" +
+		"  Transcription: " + transcribe.Model(transcribe.Options{}) + "\n" +
+		"  Date: 2026-01-25T14:30:52Z\n" +
+		"  Audio SHA-256: bb24d9039ce8110cea840a7e6bc521b33448439fd79f119dff175de8796c8574\n"
+	if string(content) != want {
+		t.Errorf("output = %q, want %q", content, want)
+	}
+	if _, err := os.Stat(output + ".minisig"); !os.IsNotExist(err) {
+		t.Errorf("signature written without --sign-key (stat error: %v)", err)
+	}
+}
+
+func TestRunLive_ProvenanceConflicts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		opts        liveOptions
+		wantContain string
+	}{
+		{
+			name:        "srt",
+			opts:        liveOptions{format: SRTFormat},
+			wantContain: "--format vtt",
+		},
+		{
+			name:        "stream without template",
+			opts:        liveOptions{stream: true},
+			wantContain: "--stream requires --template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := &Env{
+				Stderr:          &syncBuffer{},
+				Getenv:          defaultTestEnv,
+				Now:             fixedTime(time.Now()),
+				FFmpegResolver:  &mockFFmpegResolver{},
+				ConfigLoader:    &mockConfigLoader{},
+				RecorderFactory: &mockRecorderFactory{},
+			}
+			opts := tt.opts
+			opts.duration = time.Minute
+			opts.output = filepath.Join(t.TempDir(), "live"+opts.format.Extension())
+			opts.provenance = &provenanceStamp{tool: "go-transcript dev"}
+
+			err := RunLive(context.Background(), env, opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantContain) {
+				t.Errorf("RunLive() error = %v, want conflict with %s", err, tt.wantContain)
+			}
+		})
+	}
+}
@@ -21,14 +21,18 @@ var ErrInvalidProvider = errors.New("invalid provider")
 // Pre-parsed provider constants for use in code.
 // These avoid parsing overhead and provide compile-time safety.
 var (
-	DeepSeekProvider = Provider{name: ProviderDeepSeek}
-	OpenAIProvider   = Provider{name: ProviderOpenAI}
+	DeepSeekProvider  = Provider{name: ProviderDeepSeek}
+	OpenAIProvider    = Provider{name: ProviderOpenAI}
+	AnthropicProvider = Provider{name: ProviderAnthropic}
+	OllamaProvider    = Provider{name: ProviderOllama}
 )
 
 // validProviders contains the set of valid provider names.
 var validProviders = map[string]bool{
-	ProviderDeepSeek: true,
-	ProviderOpenAI:   true,
+	ProviderDeepSeek:  true,
+	ProviderOpenAI:    true,
+	ProviderAnthropic: true,
+	ProviderOllama:    true,
 }
 
 // ParseProvider validates and parses a provider name string.
@@ -39,7 +43,7 @@ func ParseProvider(s string) (Provider, error) {
 		return Provider{}, fmt.Errorf("provider cannot be empty: %w", ErrInvalidProvider)
 	}
 	if !validProviders[s] {
-		return Provider{}, fmt.Errorf("unknown provider %q (use 'deepseek' or 'openai'): %w", s, ErrInvalidProvider)
+		return Provider{}, fmt.Errorf("unknown provider %q (use 'deepseek', 'openai', 'anthropic' or 'ollama'): %w", s, ErrInvalidProvider)
 	}
 	return Provider{name: s}, nil
 }
@@ -78,6 +82,16 @@ func (p Provider) IsOpenAI() bool {
 	return p.name == ProviderOpenAI
 }
 
+// IsAnthropic returns true if this provider is Anthropic.
+func (p Provider) IsAnthropic() bool {
+	return p.name == ProviderAnthropic
+}
+
+// IsOllama returns true if this provider is a local Ollama server.
+func (p Provider) IsOllama() bool {
+	return p.name == ProviderOllama
+}
+
 // OrDefault returns the provider, or DeepSeekProvider if zero.
 // Use this to apply the default provider consistently.
 func (p Provider) OrDefault() Provider {
@@ -27,6 +27,18 @@ func TestParseProvider(t *testing.T) {
 			want:    OpenAIProvider,
 			wantErr: false,
 		},
+		{
+			name:    "anthropic valid",
+			input:   "anthropic",
+			want:    AnthropicProvider,
+			wantErr: false,
+		},
+		{
+			name:    "ollama valid",
+			input:   "ollama",
+			want:    OllamaProvider,
+			wantErr: false,
+		},
 		{
 			name:    "empty string returns error",
 			input:   "",
@@ -125,6 +137,8 @@ func TestProvider_String(t *testing.T) {
 	}{
 		{"deepseek", DeepSeekProvider, "deepseek"},
 		{"openai", OpenAIProvider, "openai"},
+		{"anthropic", AnthropicProvider, "anthropic"},
+		{"ollama", OllamaProvider, "ollama"},
 		{"zero value", Provider{}, ""},
 	}
 
@@ -211,6 +225,54 @@ func TestProvider_IsOpenAI(t *testing.T) {
 	}
 }
 
+func TestProvider_IsAnthropic(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		provider Provider
+		want     bool
+	}{
+		{"anthropic returns true", AnthropicProvider, true},
+		{"openai returns false", OpenAIProvider, false},
+		{"zero value returns false", Provider{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.provider.IsAnthropic(); got != tt.want {
+				t.Errorf("Provider.IsAnthropic() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvider_IsOllama(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		provider Provider
+		want     bool
+	}{
+		{"ollama returns true", OllamaProvider, true},
+		{"openai returns false", OpenAIProvider, false},
+		{"zero value returns false", Provider{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.provider.IsOllama(); got != tt.want {
+				t.Errorf("Provider.IsOllama() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestProvider_PreParsedConstants(t *testing.T) {
 	t.Parallel()
 
@@ -230,6 +292,22 @@ func TestProvider_PreParsedConstants(t *testing.T) {
 	if openai != OpenAIProvider {
 		t.Errorf("OpenAIProvider != ParseProvider(\"openai\")")
 	}
+
+	anthropic, err := ParseProvider("anthropic")
+	if err != nil {
+		t.Fatalf("ParseProvider(\"anthropic\") unexpected error: %v", err)
+	}
+	if anthropic != AnthropicProvider {
+		t.Errorf("AnthropicProvider != ParseProvider(\"anthropic\")")
+	}
+
+	ollama, err := ParseProvider("ollama")
+	if err != nil {
+		t.Fatalf("ParseProvider(\"ollama\") unexpected error: %v", err)
+	}
+	if ollama != OllamaProvider {
+		t.Errorf("OllamaProvider != ParseProvider(\"ollama\")")
+	}
 }
 
 func TestProvider_OrDefault(t *testing.T) {
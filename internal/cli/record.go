@@ -3,9 +3,11 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -13,38 +15,87 @@ import (
 	"github.com/alnah/go-transcript/internal/audio"
 	"github.com/alnah/go-transcript/internal/config"
 	"github.com/alnah/go-transcript/internal/format"
+	"github.com/alnah/go-transcript/internal/progress"
 )
 
 // recordOptions holds the validated options for the record command.
 type recordOptions struct {
-	duration     time.Duration
-	output       string
-	device       string
-	systemRecord bool // Capture system audio instead of microphone (-s)
-	mix          bool
+	duration      time.Duration
+	output        string
+	device        string
+	systemRecord  bool // Capture system audio instead of microphone (-s)
+	mix           bool
+	stopOnSilence time.Duration    // End the recording after this much silence (0: never)
+	start         time.Time        // Scheduled start (--start-at, --start-in); zero means now
+	segment       time.Duration    // Length of each rotated file (0: a single file)
+	balance       audio.MixBalance // Levels of the inputs with --mix (--mic-gain, --system-gain, --auto-balance)
+	tracks        bool             // Also write the inputs of --mix to their own file (--separate-tracks)
+	highlights    io.Reader        // Lines dropping highlight markers (Enter); nil means SIGUSR2 only
 }
 
 // RecordCmd creates the record command.
 // The env parameter provides injectable dependencies for testing.
 func RecordCmd(env *Env) *cobra.Command {
 	var (
-		durationStr  string
-		output       string
-		device       string
-		systemRecord bool
-		mix          bool
+		durationStr   string
+		output        string
+		device        string
+		systemRecord  bool
+		mix           bool
+		stopOnSilence string
+		progressFmt   string
+		startAt       string
+		startIn       string
+		segment       string
+		micGain       string
+		systemGain    string
+		autoBalance   bool
+		separate      bool
 	)
 
 	cmd := &cobra.Command{
-		Use:   "record",
-		Short: "Record audio from microphone or system audio",
+		Use:     "record",
+		Aliases: []string{"r"},
+		Short:   "Record audio from microphone or system audio",
 		Long: `Record audio from microphone, system audio (--system-record), or both mixed.
 
 The output format is OGG Opus optimized for voice (~50kbps, 16kHz mono).
-Recording can be interrupted with Ctrl+C to stop early - the file will be properly finalized.`,
+Recording can be interrupted with Ctrl+C to stop early - the file will be properly finalized.
+
+With --mix, both inputs are mixed at their own level. --mic-gain and
+--system-gain raise or lower each one (in dB), e.g. when the call drowns your
+voice; --auto-balance first brings both to the same loudness.
+--separate-tracks also writes the microphone and the system audio, as recorded,
+to <output>.mic.ogg and <output>.system.ogg, synchronized with the mix (see
+'transcript live --help' to transcribe them as "Me" and "Remote").
+
+With --stop-on-silence, the recording also stops once no sound has been heard
+for the given duration, e.g. when a meeting ended but the recording was left running.
+
+With --start-at 14:00 or --start-in 10m, the recording starts later, e.g. at the
+start of a scheduled meeting; a countdown is shown until then, and Ctrl+C
+cancels it without recording anything.
+
+With --segment 30m, long sessions are written to a new file every 30 minutes
+(session_0000.ogg, session_0001.ogg...) instead of one, so that a crash only
+loses the file being written. The files are listed in order in session.ffconcat,
+which 'transcript transcribe' accepts as a single recording.
+
+While recording, Enter (or SIGUSR2, e.g. 'pkill -USR2 transcript') drops a
+highlight marker. The markers are saved next to the audio, one "⭐ HH:MM:SS"
+line each in <output>.highlights, and 'transcript transcribe' renders them in
+the transcript and emphasizes the marked moments when restructuring.
+
+With --progress json, progress is written to stderr as JSON lines (see
+'transcript transcribe --help'): the time recorded is reported every second.`,
 		Example: `  transcript record -d 2h -o session.ogg           # Microphone only
   transcript record -d 30m -s                      # System audio only
-  transcript record -d 1h --mix -o meeting.ogg     # Mic + system audio`,
+  transcript record -d 1h --mix -o meeting.ogg     # Mic + system audio
+  transcript record -d 1h --mix --mic-gain 6       # Louder microphone
+  transcript record -d 1h --mix --separate-tracks  # Mic and system audio files too
+  transcript record -d 2h --stop-on-silence 5m     # Stop after 5 minutes of silence
+  transcript record -d 1h --start-at 14:00         # Start at 2 PM
+  transcript record -d 8h --segment 30m -o day.ogg # One file every 30 minutes`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Parse duration.
 			duration, err := time.ParseDuration(durationStr)
@@ -54,32 +105,72 @@ Recording can be interrupted with Ctrl+C to stop early - the file will be proper
 			if duration <= 0 {
 				return fmt.Errorf("duration must be positive: %w", ErrInvalidDuration)
 			}
+			silence, err := parseStopOnSilence(stopOnSilence)
+			if err != nil {
+				return err
+			}
+			start, err := parseStartTime(startAt, startIn, env.Now())
+			if err != nil {
+				return err
+			}
+			segmentDuration, err := parseSegment(segment)
+			if err != nil {
+				return err
+			}
+			balance, err := parseMixBalance(mix, micGain, systemGain, autoBalance)
+			if err != nil {
+				return err
+			}
+			if err := validateSeparateTracks(separate, mix); err != nil {
+				return err
+			}
 
 			// Note: output path resolution (including output-dir) is done in runRecord.
 			opts := recordOptions{
-				duration:     duration,
-				output:       output,
-				device:       device,
-				systemRecord: systemRecord,
-				mix:          mix,
+				duration:      duration,
+				output:        output,
+				device:        device,
+				systemRecord:  systemRecord,
+				mix:           mix,
+				stopOnSilence: silence,
+				start:         start,
+				segment:       segmentDuration,
+				balance:       balance,
+				tracks:        separate,
+				highlights:    highlightInput(cmd),
 			}
 
-			return runRecord(cmd.Context(), env, opts)
+			return runWithProgress(cmd, env, progressFmt, progress.PhaseRecording, func(env *Env) error {
+				return runRecord(cmd.Context(), env, opts)
+			})
 		},
 	}
 
 	// Flags.
 	cmd.Flags().StringVarP(&durationStr, "duration", "d", "", "Recording duration (e.g., 2h, 30m, 1h30m)")
 	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path (default: recording_<timestamp>.ogg)")
-	cmd.Flags().StringVar(&device, "device", "", "Audio input device (default: system default)")
+	cmd.Flags().StringVar(&device, "device", "", "Audio input device, or part of its name (default: default-device config, or system default)")
 	cmd.Flags().BoolVarP(&systemRecord, "system-record", "s", false, "Capture system audio instead of microphone")
 	cmd.Flags().BoolVar(&mix, "mix", false, "Capture both microphone and system audio")
+	cmd.Flags().StringVar(&micGain, "mic-gain", "", micGainFlagHelp)
+	cmd.Flags().StringVar(&systemGain, "system-gain", "", systemGainFlagHelp)
+	cmd.Flags().BoolVar(&autoBalance, "auto-balance", false, autoBalanceFlagHelp)
+	cmd.Flags().BoolVar(&separate, "separate-tracks", false, recordSeparateTracksFlagHelp)
+	cmd.Flags().StringVar(&stopOnSilence, "stop-on-silence", "", "Stop recording after this much silence (e.g., 5m)")
+	cmd.Flags().StringVar(&startAt, "start-at", "", startAtFlagHelp)
+	cmd.Flags().StringVar(&startIn, "start-in", "", startInFlagHelp)
+	cmd.Flags().StringVar(&segment, "segment", "", "Write a new file every this long, e.g., 30m (listed in <output>.ffconcat)")
+	cmd.Flags().StringVar(&progressFmt, "progress", ProgressText, "Progress output on stderr: text, or json for JSON lines")
 
 	// Duration is required.
 	_ = cmd.MarkFlagRequired("duration")
 
-	// System-record and mix are mutually exclusive.
+	// System-record and mix are mutually exclusive, and so are the start flags.
 	cmd.MarkFlagsMutuallyExclusive("system-record", "mix")
+	cmd.MarkFlagsMutuallyExclusive("start-at", "start-in")
+
+	// The tracks are single files, not rotated.
+	cmd.MarkFlagsMutuallyExclusive("separate-tracks", "segment")
 
 	return cmd
 }
@@ -89,7 +180,7 @@ func runRecord(ctx context.Context, env *Env, opts recordOptions) error {
 	// Load config for output-dir.
 	cfg, err := env.ConfigLoader.Load()
 	if err != nil {
-		fmt.Fprintf(env.Stderr, "Warning: failed to load config: %v\n", err)
+		warnf(env.Stderr, warnConfigLoad, "failed to load config: %v", err)
 	}
 
 	// Resolve output path using config output-dir.
@@ -103,12 +194,12 @@ func runRecord(ctx context.Context, env *Env, opts recordOptions) error {
 	// Warn if output extension is not .ogg.
 	ext := strings.ToLower(filepath.Ext(opts.output))
 	if ext != "" && ext != ".ogg" {
-		fmt.Fprintf(env.Stderr, "Warning: output will be OGG Opus format regardless of %s extension\n", ext)
+		warnf(env.Stderr, warnExtension, "output will be OGG Opus format regardless of %s extension", ext)
 	}
 
 	// Check output file doesn't already exist.
-	if _, err := os.Stat(opts.output); err == nil {
-		return fmt.Errorf("output file already exists: %s: %w", opts.output, ErrOutputExists)
+	if err := checkRecordOutput(opts.output, opts.segment, opts.tracks); err != nil {
+		return err
 	}
 
 	// Resolve FFmpeg.
@@ -126,11 +217,56 @@ func runRecord(ctx context.Context, env *Env, opts recordOptions) error {
 		return err
 	}
 
+	if err := balanceMix(recorder, opts.balance); err != nil {
+		return err
+	}
+	if err := separateTracks(recorder, opts.tracks, opts.output); err != nil {
+		return err
+	}
+	silenceStopped, err := stopOnSilence(recorder, opts.stopOnSilence)
+	if err != nil {
+		return err
+	}
+	record, err := recordFunc(recorder, opts.segment)
+	if err != nil {
+		return err
+	}
+
+	// Wait for the scheduled start, once everything is ready to record.
+	if err := waitForStart(ctx, env, opts.start); err != nil {
+		return err
+	}
+
 	// Print start message.
-	fmt.Fprintf(env.Stderr, "Recording for %s to %s... (press Ctrl+C to stop)\n", format.DurationHuman(opts.duration), opts.output)
+	progress.PhaseChange(ctx, progress.PhaseRecording)
+	if opts.segment > 0 {
+		fmt.Fprintf(env.Stderr, "Recording for %s to %s, a new file every %s... (press Ctrl+C to stop)\n",
+			format.DurationHuman(opts.duration), audio.RecordingSegmentPath(opts.output, 0), format.DurationHuman(opts.segment))
+	} else {
+		fmt.Fprintf(env.Stderr, "Recording for %s to %s... (press Ctrl+C to stop)\n", format.DurationHuman(opts.duration), opts.output)
+	}
+
+	// Journal the recording while it runs, for recover if the process dies.
+	journaled := opts.output
+	if opts.segment > 0 {
+		journaled = audio.SegmentListPath(opts.output)
+	}
+	endJournal := startRecordingJournal(env, recordingJournal{Command: "record", Audio: journaled})
 
-	// Record.
-	if err := recorder.Record(ctx, opts.duration, opts.output); err != nil {
+	// Record, showing the input level.
+	marks := startHighlights(env, opts.highlights)
+	stopMeter := monitorLevels(env, recorder, true)
+	stopReport := reportRecording(ctx, env.Now, opts.duration)
+	err = record(ctx, opts.duration, opts.output)
+	endJournal()
+	stopReport()
+	stopMeter()
+	marks.stop()
+	saveHighlights(env, highlightsPath(journaled), marks.times())
+	if silenceStopped() {
+		fmt.Fprintf(env.Stderr, "No sound for %s, recording stopped\n", format.DurationHuman(opts.stopOnSilence))
+	}
+	if err != nil {
 		// Check if it was an interrupt - file may still be valid.
 		if ctx.Err() != nil {
 			fmt.Fprintln(env.Stderr, "Interrupted, finalizing...")
@@ -139,6 +275,10 @@ func runRecord(ctx context.Context, env *Env, opts recordOptions) error {
 		}
 	}
 
+	if opts.segment > 0 {
+		return reportSegments(env, opts.output)
+	}
+
 	// Print completion message with file size.
 	size, err := fileSize(opts.output)
 	if err != nil {
@@ -147,19 +287,130 @@ func runRecord(ctx context.Context, env *Env, opts recordOptions) error {
 	}
 
 	fmt.Fprintf(env.Stderr, "Recording complete: %s (%s)\n", opts.output, format.Size(size))
+	if opts.tracks {
+		mic, system := trackPaths(opts.output)
+		fmt.Fprintf(env.Stderr, "Tracks: %s, %s\n", mic, system)
+	}
 	return nil
 }
 
 // createRecorder creates the appropriate recorder based on capture mode.
 func createRecorder(ctx context.Context, env *Env, ffmpegPath, device string, systemRecord, mix bool) (audio.Recorder, error) {
-	switch {
-	case systemRecord:
+	if systemRecord {
 		return env.RecorderFactory.NewLoopbackRecorder(ctx, ffmpegPath)
-	case mix:
+	}
+	device, err := resolveDevice(ctx, env, ffmpegPath, device)
+	if err != nil {
+		return nil, err
+	}
+	if mix {
 		return env.RecorderFactory.NewMixRecorder(ctx, ffmpegPath, device)
-	default:
-		return env.RecorderFactory.NewRecorder(ffmpegPath, device)
 	}
+	return env.RecorderFactory.NewRecorder(ffmpegPath, device)
+}
+
+// parseStopOnSilence parses the --stop-on-silence value. Empty means never.
+func parseStopOnSilence(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --stop-on-silence %q: %w (use format like 5m, 90s)", value, ErrInvalidDuration)
+	}
+	if d < time.Second {
+		return 0, fmt.Errorf("--stop-on-silence must be at least 1s: %w", ErrInvalidDuration)
+	}
+	return d, nil
+}
+
+// stopOnSilence makes recorder end the recording once the input has been
+// silent for d (0: never). The returned function reports whether it did.
+func stopOnSilence(recorder audio.Recorder, d time.Duration) (stopped func() bool, err error) {
+	var silenced atomic.Bool
+	if d <= 0 {
+		return silenced.Load, nil
+	}
+	stopper, ok := recorder.(audio.SilenceStopper)
+	if !ok {
+		return nil, fmt.Errorf("recorder does not support --stop-on-silence")
+	}
+	stopper.StopOnSilence(d, func() { silenced.Store(true) })
+	return silenced.Load, nil
+}
+
+// parseSegment parses the --segment value. Empty means a single file.
+// Files shorter than a minute would only multiply uploads and cuts.
+func parseSegment(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --segment %q: %w (use format like 30m, 1h)", value, ErrInvalidDuration)
+	}
+	if d < time.Minute {
+		return 0, fmt.Errorf("--segment must be at least 1m: %w", ErrInvalidDuration)
+	}
+	return d, nil
+}
+
+// recordFunc returns the function recording with recorder: to output, or to
+// rotating files of segment named after output (0: a single file).
+func recordFunc(recorder audio.Recorder, segment time.Duration) (func(ctx context.Context, duration time.Duration, output string) error, error) {
+	if segment <= 0 {
+		return recorder.Record, nil
+	}
+	segmented, ok := recorder.(audio.SegmentedRecorder)
+	if !ok {
+		return nil, fmt.Errorf("recorder does not support --segment")
+	}
+	return func(ctx context.Context, duration time.Duration, output string) error {
+		return segmented.RecordSegmented(ctx, duration, output, segment)
+	}, nil
+}
+
+// checkRecordOutput returns ErrOutputExists if recording to output would
+// overwrite a file: output itself and its tracks, or the files and list of a
+// segmented recording named after it.
+func checkRecordOutput(output string, segment time.Duration, tracks bool) error {
+	paths := []string{output}
+	if segment > 0 {
+		paths = []string{audio.SegmentListPath(output), audio.RecordingSegmentPath(output, 0)}
+	}
+	if tracks {
+		mic, system := trackPaths(output)
+		paths = append(paths, mic, system)
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("output file already exists: %s: %w", path, ErrOutputExists)
+		}
+	}
+	return nil
+}
+
+// reportSegments prints the files of the segmented recording output, and how
+// to transcribe them as one recording.
+func reportSegments(env *Env, output string) error {
+	var count int
+	var size int64
+	for ; ; count++ {
+		n, err := fileSize(audio.RecordingSegmentPath(output, count))
+		if err != nil {
+			if count == 0 {
+				// Files might not exist if recording failed early.
+				return fmt.Errorf("recording failed: output file not created: %w", err)
+			}
+			break
+		}
+		size += n
+	}
+
+	list := audio.SegmentListPath(output)
+	fmt.Fprintf(env.Stderr, "Recording complete: %d files (%s), listed in %s\n", count, format.Size(size), list)
+	fmt.Fprintf(env.Stderr, "Transcribe them as one recording with: transcript transcribe %s\n", list)
+	return nil
 }
 
 // defaultRecordingFilename generates a default output filename with timestamp.
@@ -3,6 +3,8 @@ package cli
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -96,6 +98,286 @@ func TestRunRecord_Success(t *testing.T) {
 	}
 }
 
+func TestRunRecord_Highlights(t *testing.T) {
+	t.Parallel()
+
+	outputPath := filepath.Join(t.TempDir(), "test.ogg")
+	stderr := &syncBuffer{}
+	in, typed := io.Pipe()
+	t.Cleanup(func() { _ = typed.Close() })
+
+	recorder := &mockRecorder{
+		RecordFunc: func(ctx context.Context, duration time.Duration, output string) error {
+			for i := range 2 {
+				if _, err := fmt.Fprintln(typed); err != nil {
+					return err
+				}
+				waitForMarks(t, stderr, i+1)
+			}
+			return os.WriteFile(output, []byte("fake audio data"), 0644)
+		},
+	}
+	env := &Env{
+		Stderr:          stderr,
+		Getenv:          func(string) string { return "" },
+		Now:             fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver:  &mockFFmpegResolver{},
+		ConfigLoader:    &mockConfigLoader{},
+		RecorderFactory: &mockRecorderFactory{mockRecorder: recorder},
+	}
+
+	err := RunRecord(context.Background(), env, recordOptions{
+		duration:   30 * time.Minute,
+		output:     outputPath,
+		highlights: in,
+	})
+	if err != nil {
+		t.Fatalf("RunRecord() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(outputPath + ".highlights")
+	if err != nil {
+		t.Fatalf("highlights not saved: %v", err)
+	}
+	if want := "⭐ 00:00:00\n⭐ 00:00:00\n"; string(data) != want {
+		t.Errorf("highlights file = %q, want %q", data, want)
+	}
+	if !strings.Contains(stderr.String(), "Highlights saved: "+outputPath+".highlights (2 markers)") {
+		t.Errorf("stderr = %q, want the highlights file reported", stderr.String())
+	}
+}
+
+func TestRunRecord_SilenceWarning(t *testing.T) {
+	t.Parallel()
+
+	outputPath := filepath.Join(t.TempDir(), "test.ogg")
+	stderr := &syncBuffer{}
+
+	recorder := &mockRecorder{}
+	recorder.RecordFunc = func(ctx context.Context, duration time.Duration, output string) error {
+		for range 12 {
+			recorder.emitLevel(-120)
+		}
+		return os.WriteFile(output, []byte("fake audio data"), 0644)
+	}
+
+	env := &Env{
+		Stderr:          stderr,
+		Getenv:          func(string) string { return "" },
+		Now:             stepClock(time.Second),
+		FFmpegResolver:  &mockFFmpegResolver{},
+		ConfigLoader:    &mockConfigLoader{},
+		RecorderFactory: &mockRecorderFactory{mockRecorder: recorder},
+	}
+
+	if err := RunRecord(context.Background(), env, recordOptions{duration: time.Minute, output: outputPath}); err != nil {
+		t.Fatalf("RunRecord() unexpected error: %v", err)
+	}
+	if output := stderr.String(); !strings.Contains(output, "no sound detected") {
+		t.Errorf("RunRecord() stderr = %q, want silence warning", output)
+	}
+}
+
+func TestRunRecord_StopOnSilence(t *testing.T) {
+	t.Parallel()
+
+	outputPath := filepath.Join(t.TempDir(), "test.ogg")
+	stderr := &syncBuffer{}
+
+	recorder := &mockRecorder{}
+	recorder.RecordFunc = func(ctx context.Context, duration time.Duration, output string) error {
+		recorder.stopOnSilence()
+		return os.WriteFile(output, []byte("fake audio data"), 0644)
+	}
+
+	env := &Env{
+		Stderr:          stderr,
+		Getenv:          func(string) string { return "" },
+		Now:             time.Now,
+		FFmpegResolver:  &mockFFmpegResolver{},
+		ConfigLoader:    &mockConfigLoader{},
+		RecorderFactory: &mockRecorderFactory{mockRecorder: recorder},
+	}
+
+	opts := recordOptions{duration: time.Hour, output: outputPath, stopOnSilence: 5 * time.Minute}
+	if err := RunRecord(context.Background(), env, opts); err != nil {
+		t.Fatalf("RunRecord() unexpected error: %v", err)
+	}
+	if recorder.silenceStop != 5*time.Minute {
+		t.Errorf("StopOnSilence() duration = %v, want 5m", recorder.silenceStop)
+	}
+	output := stderr.String()
+	if !strings.Contains(output, "No sound for 5m") {
+		t.Errorf("RunRecord() stderr = %q, want silence stop message", output)
+	}
+	if !strings.Contains(output, "Recording complete") {
+		t.Errorf("RunRecord() stderr = %q, want completion message", output)
+	}
+}
+
+func TestParseStopOnSilence(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"empty means never", "", 0, false},
+		{"minutes", "5m", 5 * time.Minute, false},
+		{"seconds", "90s", 90 * time.Second, false},
+		{"invalid", "five", 0, true},
+		{"below one second", "500ms", 0, true},
+		{"negative", "-1m", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseStopOnSilence(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDuration) {
+					t.Errorf("parseStopOnSilence(%q) error = %v, want ErrInvalidDuration", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseStopOnSilence(%q) unexpected error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseStopOnSilence(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunRecord_Segmented(t *testing.T) {
+	t.Parallel()
+
+	outputPath := filepath.Join(t.TempDir(), "day.ogg")
+	stderr := &syncBuffer{}
+	recorder := &mockRecorder{}
+	env := &Env{
+		Stderr:          stderr,
+		Getenv:          func(string) string { return "" },
+		Now:             fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver:  &mockFFmpegResolver{},
+		ConfigLoader:    &mockConfigLoader{},
+		RecorderFactory: &mockRecorderFactory{mockRecorder: recorder},
+	}
+
+	opts := recordOptions{duration: 8 * time.Hour, output: outputPath, segment: 30 * time.Minute}
+	if err := RunRecord(context.Background(), env, opts); err != nil {
+		t.Fatalf("RunRecord() unexpected error: %v", err)
+	}
+
+	if n := len(recorder.RecordCalls()); n != 0 {
+		t.Errorf("recorder.RecordCalls() = %d calls, want 0", n)
+	}
+	calls := recorder.RecordSegmentedCalls()
+	if len(calls) != 1 {
+		t.Fatalf("recorder.RecordSegmentedCalls() = %d calls, want 1", len(calls))
+	}
+	if calls[0].Output != outputPath || calls[0].SegmentDuration != 30*time.Minute {
+		t.Errorf("recorder.RecordSegmented() = %+v, want output %q every 30m", calls[0], outputPath)
+	}
+
+	output := stderr.String()
+	for _, want := range []string{
+		"day_0000.ogg, a new file every 30m",
+		"Recording complete: 2 files",
+		"transcript transcribe " + filepath.Join(filepath.Dir(outputPath), "day.ffconcat"),
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("RunRecord() stderr = %q, want containing %q", output, want)
+		}
+	}
+
+	// The files are not overwritten by a second recording
+	err := RunRecord(context.Background(), env, opts)
+	if !errors.Is(err, ErrOutputExists) {
+		t.Errorf("RunRecord() again error = %v, want ErrOutputExists", err)
+	}
+}
+
+func TestRunRecord_Journal(t *testing.T) {
+	t.Parallel()
+
+	outputPath := filepath.Join(t.TempDir(), "day.ogg")
+	journalPath := recordingJournalPath(outputPath)
+	var journal recordingJournal
+	recorder := &mockRecorder{
+		RecordFunc: func(ctx context.Context, duration time.Duration, output string) error {
+			// The journal exists while recording
+			var err error
+			journal, err = loadRecordingJournal(journalPath)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(output, []byte("audio"), 0600)
+		},
+	}
+	env := &Env{
+		Stderr:          &syncBuffer{},
+		Getenv:          func(string) string { return "" },
+		Now:             fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver:  &mockFFmpegResolver{},
+		ConfigLoader:    &mockConfigLoader{},
+		RecorderFactory: &mockRecorderFactory{mockRecorder: recorder},
+	}
+
+	if err := RunRecord(context.Background(), env, recordOptions{duration: time.Hour, output: outputPath}); err != nil {
+		t.Fatalf("RunRecord() unexpected error: %v", err)
+	}
+
+	if journal.Command != "record" || journal.Audio != outputPath || journal.PID != os.Getpid() {
+		t.Errorf("journal while recording = %+v, want record of %q by this process", journal, outputPath)
+	}
+	if _, err := os.Stat(journalPath); !os.IsNotExist(err) {
+		t.Errorf("journal after recording: stat error = %v, want not exist", err)
+	}
+}
+
+func TestParseSegment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"empty means a single file", "", 0, false},
+		{"minutes", "30m", 30 * time.Minute, false},
+		{"hours", "1h", time.Hour, false},
+		{"invalid", "half an hour", 0, true},
+		{"below one minute", "30s", 0, true},
+		{"negative", "-30m", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseSegment(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDuration) {
+					t.Errorf("parseSegment(%q) error = %v, want ErrInvalidDuration", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSegment(%q) unexpected error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseSegment(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRunRecord_DefaultFilename(t *testing.T) {
 	t.Parallel()
 
@@ -312,6 +594,78 @@ func TestRunRecord_LoopbackRecorder(t *testing.T) {
 	}
 }
 
+func TestRunRecord_DeviceMatching(t *testing.T) {
+	t.Parallel()
+
+	devices := []string{":1\tYeti Stereo Microphone", ":0\tMacBook Pro Microphone"}
+	tests := []struct {
+		name          string
+		device        string
+		defaultDevice string
+		wantDevice    string
+		wantErr       error
+	}{
+		{name: "part of a name", device: "yeti", wantDevice: ":1"},
+		{name: "default-device config", defaultDevice: "macbook", wantDevice: ":0"},
+		{name: "flag over default-device", device: "yeti", defaultDevice: "macbook", wantDevice: ":1"},
+		{name: "unlisted device as is", device: "hw:1,0", wantDevice: "hw:1,0"},
+		{name: "system default", wantDevice: ""},
+		{name: "ambiguous", device: "microphone", wantErr: audio.ErrAmbiguousDevice},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stderr := &syncBuffer{}
+			recorderFactory := &mockRecorderFactory{
+				mockRecorder: &mockRecorder{
+					RecordFunc: func(ctx context.Context, duration time.Duration, output string) error {
+						return os.WriteFile(output, []byte("audio"), 0600)
+					},
+				},
+			}
+			env := &Env{
+				Stderr:         stderr,
+				Getenv:         func(string) string { return "" },
+				Now:            fixedTime(time.Now()),
+				FFmpegResolver: &mockFFmpegResolver{},
+				ConfigLoader: &mockConfigLoader{
+					LoadFunc: func() (config.Config, error) {
+						return config.Config{DefaultDevice: tt.defaultDevice}, nil
+					},
+				},
+				RecorderFactory: recorderFactory,
+				DeviceListerFactory: &mockDeviceListerFactory{
+					mockDeviceLister: &mockDeviceLister{
+						ListDevicesFunc: func(ctx context.Context) ([]string, error) { return devices, nil },
+					},
+				},
+			}
+
+			opts := recordOptions{duration: time.Minute, output: filepath.Join(t.TempDir(), "day.ogg"), device: tt.device}
+			err := RunRecord(context.Background(), env, opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("RunRecord() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunRecord() unexpected error: %v", err)
+			}
+
+			calls := recorderFactory.newRecorderCalls
+			if len(calls) != 1 || calls[0].Device != tt.wantDevice {
+				t.Errorf("NewRecorder calls = %+v, want one for device %q", calls, tt.wantDevice)
+			}
+			if tt.wantDevice == ":1" && !strings.Contains(stderr.String(), "Using device: Yeti Stereo Microphone") {
+				t.Errorf("RunRecord() stderr = %q, want the matched device named", stderr.String())
+			}
+		})
+	}
+}
+
 func TestRunRecord_MixRecorder(t *testing.T) {
 	t.Parallel()
 
@@ -335,12 +689,13 @@ func TestRunRecord_MixRecorder(t *testing.T) {
 	}
 
 	env := &Env{
-		Stderr:          &syncBuffer{},
-		Getenv:          func(string) string { return "" },
-		Now:             fixedTime(time.Now()),
-		FFmpegResolver:  &mockFFmpegResolver{},
-		ConfigLoader:    &mockConfigLoader{},
-		RecorderFactory: recorderFactory,
+		Stderr:              &syncBuffer{},
+		Getenv:              func(string) string { return "" },
+		Now:                 fixedTime(time.Now()),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        &mockConfigLoader{},
+		RecorderFactory:     recorderFactory,
+		DeviceListerFactory: &mockDeviceListerFactory{},
 	}
 
 	opts := recordOptions{
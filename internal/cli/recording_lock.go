@@ -0,0 +1,27 @@
+//go:build !windows
+
+package cli
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockRecordingJournal takes an exclusive advisory lock on the open journal
+// f, held until f is closed or the process dies.
+func lockRecordingJournal(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+// recordingJournalLocked reports whether a process holds the lock of the
+// journal at path (see lockRecordingJournal).
+func recordingJournalLocked(path string) bool {
+	f, err := os.Open(path) // #nosec G304 -- journal found by recover in the searched directories
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB)
+	return errors.Is(err, syscall.EWOULDBLOCK)
+}
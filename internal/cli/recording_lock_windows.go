@@ -0,0 +1,13 @@
+package cli
+
+import (
+	"errors"
+	"os"
+)
+
+// lockRecordingJournal does nothing: Windows locks are mandatory, recover
+// could not read a locked journal. recover checks the PID instead.
+func lockRecordingJournal(*os.File) error { return errors.ErrUnsupported }
+
+// recordingJournalLocked reports false: journals are never locked on Windows.
+func recordingJournalLocked(string) bool { return false }
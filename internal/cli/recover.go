@@ -0,0 +1,580 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// recordingJournalSuffix names the journal of a recording in progress, a
+// hidden file next to the audio.
+// Example: "day.ogg" -> ".day.ogg.transcript-recording.json"
+const recordingJournalSuffix = ".transcript-recording.json"
+
+// recordingJournalVersion is the schema version of recording journals.
+const recordingJournalVersion = 1
+
+// liveTempDirPattern names the temporary directories live records into.
+const liveTempDirPattern = "go-transcript-live-*"
+
+// recordingJournal describes a recording while it is in progress. It is
+// removed once the recorder returns: a journal left behind means the process
+// died mid-recording, and recover repairs the audio it points to.
+type recordingJournal struct {
+	SchemaVersion int       `json:"schema_version"`
+	Command       string    `json:"command"` // record or live
+	Audio         string    `json:"audio"`   // Absolute path of the recording (.ffconcat list with --segment)
+	PID           int       `json:"pid"`     // Process recording it
+	StartedAt     time.Time `json:"started_at"`
+	// Locked is set when the recording process holds a lock on the journal
+	// until it exits: the lock, not the PID, tells whether it still runs,
+	// as the PID may be reused by another process after a crash or reboot.
+	Locked bool `json:"locked,omitempty"`
+
+	// Options of live, applied when the recording is transcribed.
+	Output    string            `json:"output,omitempty"`
+	Template  string            `json:"template,omitempty"`
+	Vars      map[string]string `json:"vars,omitempty"` // Values of the template placeholders
+	Provider  string            `json:"provider,omitempty"`
+	Language  string            `json:"language,omitempty"`
+	Translate string            `json:"translate,omitempty"`
+	Diarize   bool              `json:"diarize,omitempty"`
+	Format    string            `json:"format,omitempty"`
+	Tag       string            `json:"tag,omitempty"`
+}
+
+// recordingJournalPath returns the path of the journal of the recording at audioPath.
+func recordingJournalPath(audioPath string) string {
+	return filepath.Join(filepath.Dir(audioPath), "."+filepath.Base(audioPath)+recordingJournalSuffix)
+}
+
+// startRecordingJournal writes the journal of the recording j.Audio, which
+// is about to start, and locks it while recording (see recordingInProgress).
+// The returned function removes it: call it once the recorder returns.
+// Failing to write it only costs recover: a warning is printed and recording
+// goes on.
+func startRecordingJournal(env *Env, j recordingJournal) (end func()) {
+	abs, err := filepath.Abs(j.Audio)
+	var f *os.File
+	if err == nil {
+		j.Audio = abs
+		j.SchemaVersion = recordingJournalVersion
+		j.PID = os.Getpid()
+		j.StartedAt = env.Now().UTC()
+		f, err = createRecordingJournal(recordingJournalPath(abs), j)
+	}
+	if err != nil {
+		warnf(env.Stderr, warnFileNotSaved, "failed to write the recording journal, recover will not find this recording: %v", err)
+		return func() {}
+	}
+	return func() {
+		_ = os.Remove(recordingJournalPath(abs))
+		_ = f.Close() // Releases the lock
+	}
+}
+
+// createRecordingJournal writes j to path, and returns the journal open and
+// locked, when the system supports it (j.Locked). Closing it releases the lock.
+func createRecordingJournal(path string, j recordingJournal) (*os.File, error) {
+	// #nosec G304 -- journal next to the recording
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	j.Locked = lockRecordingJournal(f) == nil
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err == nil {
+		_, err = f.Write(append(data, '\n'))
+	}
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(path)
+		return nil, err
+	}
+	return f, nil
+}
+
+// recordingInProgress reports whether the recording of the journal j at path
+// is still running: its process holds the lock of the journal, or, for a
+// journal written without lock (Windows), the process j.PID is running.
+func recordingInProgress(path string, j recordingJournal) bool {
+	if j.Locked {
+		return recordingJournalLocked(path)
+	}
+	return processAlive(j.PID)
+}
+
+// loadRecordingJournal reads the journal at path.
+func loadRecordingJournal(path string) (recordingJournal, error) {
+	// #nosec G304 -- journal found by recover in the searched directories
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return recordingJournal{}, err
+	}
+	var j recordingJournal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return recordingJournal{}, fmt.Errorf("invalid recording journal %s: %w", path, err)
+	}
+	if j.Audio == "" {
+		return recordingJournal{}, fmt.Errorf("invalid recording journal %s: no audio path", path)
+	}
+	return j, nil
+}
+
+// liveRecordingJournal returns the journal of the live recording at
+// audioPath, with the options its transcription uses.
+func liveRecordingJournal(lctx *liveContext, opts liveOptions, audioPath string) recordingJournal {
+	j := recordingJournal{
+		Command:   "live",
+		Audio:     audioPath,
+		Output:    opts.output,
+		Template:  opts.template.String(),
+		Vars:      opts.templateVars,
+		Language:  opts.language.String(),
+		Translate: opts.translate.String(),
+		Diarize:   opts.diarize,
+		Format:    opts.format.String(),
+		Tag:       opts.tag,
+	}
+	if !opts.template.IsZero() {
+		j.Provider = lctx.restructureProvider.String()
+	}
+	return j
+}
+
+// transcribeOptions returns the options transcribing the recovered recording
+// at audioPath with the options saved in the journal.
+func (j recordingJournal) transcribeOptions(audioPath, templatesDir string) (transcribeOptions, error) {
+	opts, err := parseTranscribeOptions(audioPath, j.Output, j.Template, j.Diarize, 0, j.Language, j.Translate, j.Provider, templatesDir)
+	if err != nil {
+		return transcribeOptions{}, err
+	}
+	if opts.template, err = opts.template.WithVars(j.Vars); err != nil {
+		return transcribeOptions{}, err
+	}
+	if j.Format != "" {
+		if opts.format, err = ParseOutputFormat(j.Format); err != nil {
+			return transcribeOptions{}, err
+		}
+	}
+	opts.tag = j.Tag
+	return opts, nil
+}
+
+// transcribeCommand returns the transcribe command line equivalent to the
+// options saved in the journal, for the recording at audioPath.
+func (j recordingJournal) transcribeCommand(audioPath string) string {
+	args := []string{"transcript", "transcribe", audioPath}
+	flag := func(name, value string) {
+		if value != "" {
+			args = append(args, name, value)
+		}
+	}
+	flag("-o", j.Output)
+	flag("-t", j.Template)
+	flag("-l", j.Language)
+	flag("-T", j.Translate)
+	flag("--provider", j.Provider)
+	if j.Format != FormatMarkdown {
+		flag("-f", j.Format)
+	}
+	flag("--tag", j.Tag)
+	for _, name := range slices.Sorted(maps.Keys(j.Vars)) {
+		flag("--var", name+"="+j.Vars[name])
+	}
+	if j.Diarize {
+		args = append(args, "--diarize")
+	}
+	for i, arg := range args {
+		if strings.ContainsAny(arg, " \t'\"") {
+			args[i] = fmt.Sprintf("%q", arg)
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// processAlive reports whether the process pid is running. Signal 0 checks
+// that the process exists without signaling it. Windows has no signal 0, but
+// only finds running processes.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	switch {
+	case err == nil, errors.Is(err, syscall.EPERM):
+		return true // EPERM: running as another user
+	case errors.Is(err, os.ErrProcessDone):
+		return false
+	default:
+		return runtime.GOOS == "windows"
+	}
+}
+
+// recoverOptions holds the options of the recover command.
+type recoverOptions struct {
+	dirs       []string      // Directories searched for recordings (empty: output-dir, or the current directory)
+	tempDir    string        // Parent of the temporary directories of live
+	transcribe bool          // Transcribe the recovered recordings without asking (--transcribe)
+	answers    *bufio.Reader // Answers to "Transcribe it now?" (nil: never asks)
+}
+
+// RecoverCmd creates the recover command.
+// The env parameter provides injectable dependencies for testing.
+func RecoverCmd(env *Env) *cobra.Command {
+	var transcribeAll bool
+
+	cmd := &cobra.Command{
+		Use:   "recover [dir...]",
+		Short: "Repair and transcribe recordings interrupted by a crash",
+		Long: `Repair and transcribe recordings left behind when record or live died mid-recording.
+
+While recording, record and live keep a journal next to the audio. A journal
+left behind by a process that is no longer running marks an interrupted
+recording. recover searches the given directories (default: the configured
+output-dir, or the current directory) and the temporary directories of live
+for them.
+
+Each recording is repaired by remuxing it with FFmpeg, which writes the end of
+the file the crash left out, without re-encoding. The files of a segmented
+recording (record --segment) written after the last listed one are repaired and
+added to its .ffconcat list. Recordings of live are moved next to their
+transcript, out of the temporary directory.
+
+recover then offers to transcribe each recording with the options of the
+interrupted command (template, language, output...). --transcribe does it
+without asking; without a terminal, the transcribe command is printed instead.`,
+		Example: `  transcript recover
+  transcript recover ~/Recordings
+  transcript recover --transcribe`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := recoverOptions{
+				dirs:       args,
+				tempDir:    os.TempDir(),
+				transcribe: transcribeAll,
+			}
+			if f, ok := cmd.InOrStdin().(*os.File); ok && isTerminal(f) && isTerminal(env.Stderr) {
+				opts.answers = bufio.NewReader(f)
+			}
+			return runRecover(cmd, env, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&transcribeAll, "transcribe", false, "Transcribe the recovered recordings without asking")
+
+	return cmd
+}
+
+// runRecover repairs the interrupted recordings found in opts.dirs and the
+// temporary directories of live, and transcribes them when asked to.
+// Every recording is tried; the first failure is returned at the end.
+func runRecover(cmd *cobra.Command, env *Env, opts recoverOptions) error {
+	ctx := cmd.Context()
+
+	dirs := opts.dirs
+	if len(dirs) == 0 {
+		cfg, err := env.ConfigLoader.Load()
+		if err != nil {
+			warnf(env.Stderr, warnConfigLoad, "failed to load config: %v", err)
+		}
+		dir := cfg.OutputDir
+		if dir == "" {
+			dir = "."
+		}
+		dirs = []string{dir}
+	}
+	journals, err := findRecordingJournals(dirs, opts.tempDir)
+	if err != nil {
+		return err
+	}
+
+	var (
+		repairer audio.Repairer // Created with the first recording to repair
+		failed   []error
+	)
+	for _, path := range journals {
+		j, err := loadRecordingJournal(path)
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "Skipped: %v\n", err)
+			failed = append(failed, err)
+			continue
+		}
+		if recordingInProgress(path, j) {
+			fmt.Fprintf(env.Stderr, "Still recording (process %d): %s\n", j.PID, j.Audio)
+			continue
+		}
+
+		if repairer == nil {
+			ffmpegPath, err := env.FFmpegResolver.Resolve(ctx)
+			if err != nil {
+				return err
+			}
+			if repairer, err = env.RepairerFactory.NewRepairer(ffmpegPath); err != nil {
+				return err
+			}
+		}
+
+		audioPath, err := recoverRecording(ctx, env, repairer, path, j)
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "Failed to recover %s: %v\n", j.Audio, err)
+			failed = append(failed, err)
+			continue
+		}
+		if audioPath == "" {
+			continue // Nothing was recorded
+		}
+		if err := offerTranscription(cmd, env, opts, j, audioPath); err != nil {
+			fmt.Fprintf(env.Stderr, "Failed to transcribe %s: %v\n", audioPath, err)
+			failed = append(failed, err)
+		}
+	}
+
+	if len(journals) == 0 {
+		fmt.Fprintln(env.Stderr, "No interrupted recording found")
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d interrupted recordings failed: %w", len(failed), len(journals), failed[0])
+	}
+	return nil
+}
+
+// findRecordingJournals returns the recording journals of dirs and of the
+// temporary directories of live in tempDir, sorted by path.
+func findRecordingJournals(dirs []string, tempDir string) ([]string, error) {
+	patterns := make([]string, 0, len(dirs)+1)
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("%w: %s", ErrFileNotFound, dir)
+			}
+			return nil, fmt.Errorf("cannot access directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("not a directory: %s", dir)
+		}
+		patterns = append(patterns, filepath.Join(dir, ".*"+recordingJournalSuffix))
+	}
+	if tempDir != "" {
+		patterns = append(patterns, filepath.Join(tempDir, liveTempDirPattern, ".*"+recordingJournalSuffix))
+	}
+
+	var journals []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("cannot search for recordings: %w", err)
+		}
+		journals = append(journals, matches...)
+	}
+	slices.Sort(journals)
+	return slices.Compact(journals), nil
+}
+
+// recoverRecording repairs the recording of the journal at journalPath, then
+// removes the journal. A live recording in a temporary directory is moved next
+// to its transcript, and the directory removed.
+// Returns the path to transcribe, or an empty path if nothing was recorded.
+func recoverRecording(ctx context.Context, env *Env, repairer audio.Repairer, journalPath string, j recordingJournal) (string, error) {
+	recover := recoverFile
+	if audio.IsSegmentList(j.Audio) {
+		recover = recoverSegments
+	}
+	audioPath, err := recover(ctx, env, repairer, j.Audio)
+	if err != nil {
+		return "", err
+	}
+
+	tempDir := filepath.Dir(j.Audio)
+	if matched, _ := filepath.Match(liveTempDirPattern, filepath.Base(tempDir)); !matched || j.Output == "" {
+		tempDir = "" // Not a temporary directory of live
+	}
+	if audioPath != "" && tempDir != "" {
+		dest := audioOutputPath(j.Output)
+		if _, err := os.Stat(dest); err == nil {
+			tempDir = "" // Kept where it is rather than replacing a file
+		} else if err := moveFile(audioPath, dest); err != nil {
+			return "", fmt.Errorf("failed to move the recording next to its transcript: %w", err)
+		} else {
+			audioPath = dest
+			fmt.Fprintf(env.Stderr, "Moved to: %s\n", dest)
+		}
+	}
+
+	if err := os.Remove(journalPath); err != nil && !os.IsNotExist(err) {
+		warnf(env.Stderr, warnFileNotSaved, "failed to remove the recording journal: %v", err)
+	}
+	if tempDir != "" {
+		_ = os.RemoveAll(tempDir)
+	}
+	return audioPath, nil
+}
+
+// recoverFile repairs the recording at path in place.
+// Returns an empty path if nothing was recorded.
+func recoverFile(ctx context.Context, env *Env, repairer audio.Repairer, path string) (string, error) {
+	size, err := fileSize(path)
+	if err != nil || size == 0 {
+		fmt.Fprintf(env.Stderr, "Nothing was recorded: %s\n", path)
+		return "", nil
+	}
+	if err := repairFile(ctx, repairer, path); err != nil {
+		return "", err
+	}
+	size, _ = fileSize(path)
+	fmt.Fprintf(env.Stderr, "Recovered: %s (%s)\n", path, format.Size(size))
+	return path, nil
+}
+
+// recoverSegments repairs the files of the segmented recording listed in
+// list that the crash left out of it, and lists them.
+// Returns an empty path if nothing was recorded.
+func recoverSegments(ctx context.Context, env *Env, repairer audio.Repairer, list string) (string, error) {
+	// The files are named after the recording: day.ffconcat lists day_NNNN.ogg
+	recording := strings.TrimSuffix(list, audio.SegmentListExt) + ".ogg"
+	listed, err := countListedSegments(list)
+	if err != nil {
+		return "", err
+	}
+
+	added := 0
+	for i := listed; ; i++ {
+		path := audio.RecordingSegmentPath(recording, i)
+		size, err := fileSize(path)
+		if err != nil {
+			break
+		}
+		if size == 0 {
+			continue // Opened by FFmpeg, nothing written
+		}
+		if err := repairFile(ctx, repairer, path); err != nil {
+			return "", err
+		}
+		if err := appendSegment(list, path); err != nil {
+			return "", err
+		}
+		added++
+	}
+
+	if listed+added == 0 {
+		fmt.Fprintf(env.Stderr, "Nothing was recorded: %s\n", list)
+		return "", nil
+	}
+	fmt.Fprintf(env.Stderr, "Recovered: %s (%d files, %d repaired)\n", list, listed+added, added)
+	return list, nil
+}
+
+// repairFile repairs the recording at path, through a repaired copy that
+// replaces it: path is left untouched if the repair fails.
+func repairFile(ctx context.Context, repairer audio.Repairer, path string) error {
+	ext := filepath.Ext(path)
+	repaired := strings.TrimSuffix(path, ext) + ".repaired" + ext
+	if err := repairer.Repair(ctx, path, repaired); err != nil {
+		_ = os.Remove(repaired)
+		return err
+	}
+	if err := os.Rename(repaired, path); err != nil {
+		_ = os.Remove(repaired)
+		return fmt.Errorf("cannot replace the recording with its repaired copy: %w", err)
+	}
+	return nil
+}
+
+// countListedSegments returns the number of files in the segment list,
+// zero if the crash came before FFmpeg wrote it.
+func countListedSegments(list string) (int, error) {
+	// #nosec G304 -- list named in a recording journal
+	data, err := os.ReadFile(list)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for line := range strings.Lines(string(data)) {
+		if strings.HasPrefix(strings.TrimSpace(line), "file ") {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// appendSegment adds the file at path to the segment list, creating it if needed.
+func appendSegment(list, path string) error {
+	_, err := os.Stat(list)
+	header := os.IsNotExist(err)
+
+	// #nosec G302 G304 -- list named in a recording journal
+	f, err := os.OpenFile(list, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot update the segment list: %w", err)
+	}
+	var entry string
+	if header {
+		entry = "ffconcat version 1.0\n"
+	}
+	// Single quotes, closed around each quote of the name: 'it'\''s.ogg'
+	entry += "file '" + strings.ReplaceAll(filepath.Base(path), "'", `'\''`) + "'\n"
+	if _, err := io.WriteString(f, entry); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("cannot update the segment list: %w", err)
+	}
+	return f.Close()
+}
+
+// offerTranscription transcribes the recovered recording at audioPath with
+// the options saved in j: right away with --transcribe, or if the user agrees.
+// Otherwise, prints the equivalent transcribe command.
+func offerTranscription(cmd *cobra.Command, env *Env, opts recoverOptions, j recordingJournal, audioPath string) error {
+	command := j.transcribeCommand(audioPath)
+	if !opts.transcribe && !confirm(env, opts.answers, fmt.Sprintf("Transcribe it now (%s)?", command)) {
+		fmt.Fprintf(env.Stderr, "Transcribe it with: %s\n", command)
+		return nil
+	}
+
+	topts, err := j.transcribeOptions(audioPath, userTemplatesDir(env))
+	if err != nil {
+		return err
+	}
+	// runTranscribe takes its context from the command.
+	fileCmd := &cobra.Command{}
+	fileCmd.SetContext(cmd.Context())
+	fileCmd.SetOut(cmd.OutOrStdout())
+	return runTranscribe(fileCmd, env, topts)
+}
+
+// confirm asks question on env.Stderr and reads a yes or no from answers.
+// Anything but y or yes is a no, and so is a nil reader (not a terminal).
+func confirm(env *Env, answers *bufio.Reader, question string) bool {
+	if answers == nil {
+		return false
+	}
+	fmt.Fprintf(env.Stderr, "%s [y/N] ", question)
+	line, _ := answers.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
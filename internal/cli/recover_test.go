@@ -0,0 +1,411 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/template"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// deadPID returns the PID of a process that has exited.
+func deadPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run a process: %v", err)
+	}
+	return cmd.Process.Pid
+}
+
+// writeRecordingJournal writes j to path, without locking it.
+func writeRecordingJournal(path string, j recordingJournal) error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// writeInterruptedRecording writes the recording at path and the journal a
+// crash left behind for it.
+func writeInterruptedRecording(t *testing.T, path string, content string, j recordingJournal) {
+	t.Helper()
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write recording: %v", err)
+		}
+	}
+	j.Audio = path
+	if err := writeRecordingJournal(recordingJournalPath(path), j); err != nil {
+		t.Fatalf("failed to write journal: %v", err)
+	}
+}
+
+func recoverTestEnv(stderr *syncBuffer, repairer *mockRepairer) *Env {
+	return &Env{
+		Stderr:          stderr,
+		Getenv:          func(string) string { return "" },
+		Now:             fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)),
+		FFmpegResolver:  &mockFFmpegResolver{},
+		ConfigLoader:    &mockConfigLoader{},
+		RepairerFactory: &mockRepairerFactory{mockRepairer: repairer},
+	}
+}
+
+func recoverTestCmd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	return cmd
+}
+
+func TestProcessAlive(t *testing.T) {
+	t.Parallel()
+
+	if !processAlive(os.Getpid()) {
+		t.Error("processAlive(this process) = false, want true")
+	}
+	if processAlive(0) {
+		t.Error("processAlive(0) = true, want false")
+	}
+	if processAlive(deadPID(t)) {
+		t.Error("processAlive(exited process) = true, want false")
+	}
+}
+
+func TestRunRecover_RepairsRecording(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "day.ogg")
+	writeInterruptedRecording(t, path, "truncated", recordingJournal{Command: "record", PID: deadPID(t)})
+
+	stderr := &syncBuffer{}
+	repairer := &mockRepairer{}
+	env := recoverTestEnv(stderr, repairer)
+	if err := runRecover(recoverTestCmd(), env, recoverOptions{dirs: []string{dir}}); err != nil {
+		t.Fatalf("runRecover() unexpected error: %v", err)
+	}
+
+	if got := repairer.Repairs(); len(got) != 1 || got[0] != path {
+		t.Errorf("repairer.Repairs() = %v, want [%s]", got, path)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "truncated" {
+		t.Errorf("recording = %q, want the repaired copy", content)
+	}
+	if _, err := os.Stat(recordingJournalPath(path)); !os.IsNotExist(err) {
+		t.Errorf("journal after recover: stat error = %v, want not exist", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "day.repaired.ogg")); !os.IsNotExist(err) {
+		t.Errorf("repaired copy left behind: stat error = %v", err)
+	}
+	output := stderr.String()
+	for _, want := range []string{"Recovered: " + path, "Transcribe it with: transcript transcribe " + path} {
+		if !strings.Contains(output, want) {
+			t.Errorf("runRecover() stderr = %q, want containing %q", output, want)
+		}
+	}
+}
+
+func TestRunRecover_SkipsRunningRecording(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "day.ogg")
+	writeInterruptedRecording(t, path, "recording", recordingJournal{Command: "record", PID: os.Getpid()})
+
+	stderr := &syncBuffer{}
+	repairer := &mockRepairer{}
+	if err := runRecover(recoverTestCmd(), recoverTestEnv(stderr, repairer), recoverOptions{dirs: []string{dir}}); err != nil {
+		t.Fatalf("runRecover() unexpected error: %v", err)
+	}
+
+	if got := repairer.Repairs(); len(got) != 0 {
+		t.Errorf("repairer.Repairs() = %v, want none", got)
+	}
+	if _, err := os.Stat(recordingJournalPath(path)); err != nil {
+		t.Errorf("journal of a running recording removed: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "Still recording") {
+		t.Errorf("runRecover() stderr = %q, want containing %q", stderr.String(), "Still recording")
+	}
+}
+
+func TestRunRecover_LockedJournal(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("journals are not locked on Windows")
+	}
+
+	t.Run("held lock is still recording", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "day.ogg")
+		if err := os.WriteFile(path, []byte("recording"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		f, err := createRecordingJournal(recordingJournalPath(path), recordingJournal{Command: "record", Audio: path, PID: deadPID(t)})
+		if err != nil {
+			t.Fatalf("createRecordingJournal() error = %v", err)
+		}
+		defer f.Close()
+
+		stderr := &syncBuffer{}
+		repairer := &mockRepairer{}
+		if err := runRecover(recoverTestCmd(), recoverTestEnv(stderr, repairer), recoverOptions{dirs: []string{dir}}); err != nil {
+			t.Fatalf("runRecover() unexpected error: %v", err)
+		}
+		if got := repairer.Repairs(); len(got) != 0 {
+			t.Errorf("repairer.Repairs() = %v, want none while the lock is held", got)
+		}
+		if !strings.Contains(stderr.String(), "Still recording") {
+			t.Errorf("runRecover() stderr = %q, want containing %q", stderr.String(), "Still recording")
+		}
+	})
+
+	t.Run("released lock with a reused PID is interrupted", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "day.ogg")
+		// The PID now names a running process (this one), but no process
+		// holds the lock: the recorder died.
+		writeInterruptedRecording(t, path, "truncated", recordingJournal{Command: "record", PID: os.Getpid(), Locked: true})
+
+		repairer := &mockRepairer{}
+		if err := runRecover(recoverTestCmd(), recoverTestEnv(&syncBuffer{}, repairer), recoverOptions{dirs: []string{dir}}); err != nil {
+			t.Fatalf("runRecover() unexpected error: %v", err)
+		}
+		if got := repairer.Repairs(); len(got) != 1 || got[0] != path {
+			t.Errorf("repairer.Repairs() = %v, want [%s]", got, path)
+		}
+	})
+}
+
+func TestRunRecover_NothingRecorded(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "day.ogg")
+	writeInterruptedRecording(t, path, "", recordingJournal{Command: "record", PID: deadPID(t)})
+
+	stderr := &syncBuffer{}
+	repairer := &mockRepairer{}
+	if err := runRecover(recoverTestCmd(), recoverTestEnv(stderr, repairer), recoverOptions{dirs: []string{dir}}); err != nil {
+		t.Fatalf("runRecover() unexpected error: %v", err)
+	}
+
+	if got := repairer.Repairs(); len(got) != 0 {
+		t.Errorf("repairer.Repairs() = %v, want none", got)
+	}
+	if _, err := os.Stat(recordingJournalPath(path)); !os.IsNotExist(err) {
+		t.Errorf("journal after recover: stat error = %v, want not exist", err)
+	}
+	if !strings.Contains(stderr.String(), "Nothing was recorded") {
+		t.Errorf("runRecover() stderr = %q, want containing %q", stderr.String(), "Nothing was recorded")
+	}
+}
+
+func TestRunRecover_NoJournal(t *testing.T) {
+	t.Parallel()
+
+	stderr := &syncBuffer{}
+	opts := recoverOptions{dirs: []string{t.TempDir()}, tempDir: t.TempDir()}
+	if err := runRecover(recoverTestCmd(), recoverTestEnv(stderr, &mockRepairer{}), opts); err != nil {
+		t.Fatalf("runRecover() unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "No interrupted recording found") {
+		t.Errorf("runRecover() stderr = %q, want containing %q", stderr.String(), "No interrupted recording found")
+	}
+}
+
+func TestRunRecover_RepairFails(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "day.ogg")
+	writeInterruptedRecording(t, path, "damaged", recordingJournal{Command: "record", PID: deadPID(t)})
+
+	repairer := &mockRepairer{
+		RepairFunc: func(ctx context.Context, inputPath, outputPath string) error {
+			_ = os.WriteFile(outputPath, []byte("partial"), 0600)
+			return audio.ErrRepairFailed
+		},
+	}
+	err := runRecover(recoverTestCmd(), recoverTestEnv(&syncBuffer{}, repairer), recoverOptions{dirs: []string{dir}})
+	if !errors.Is(err, audio.ErrRepairFailed) {
+		t.Fatalf("runRecover() error = %v, want ErrRepairFailed", err)
+	}
+
+	// The recording and its journal are kept to try again
+	if content, _ := os.ReadFile(path); string(content) != "damaged" {
+		t.Errorf("recording = %q, want it untouched", content)
+	}
+	if _, err := os.Stat(recordingJournalPath(path)); err != nil {
+		t.Errorf("journal removed after a failed repair: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "day.repaired.ogg")); !os.IsNotExist(err) {
+		t.Errorf("repaired copy left behind: stat error = %v", err)
+	}
+}
+
+func TestRunRecover_Segments(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	list := filepath.Join(dir, "day.ffconcat")
+	for i := range 3 {
+		if err := os.WriteFile(audio.RecordingSegmentPath(filepath.Join(dir, "day.ogg"), i), []byte("audio"), 0600); err != nil {
+			t.Fatalf("failed to write segment: %v", err)
+		}
+	}
+	// The crash came before the list got the last two files
+	if err := os.WriteFile(list, []byte("ffconcat version 1.0\nfile day_0000.ogg\n"), 0600); err != nil {
+		t.Fatalf("failed to write list: %v", err)
+	}
+	writeInterruptedRecording(t, list, "", recordingJournal{Command: "record", PID: deadPID(t)})
+
+	stderr := &syncBuffer{}
+	repairer := &mockRepairer{}
+	if err := runRecover(recoverTestCmd(), recoverTestEnv(stderr, repairer), recoverOptions{dirs: []string{dir}}); err != nil {
+		t.Fatalf("runRecover() unexpected error: %v", err)
+	}
+
+	wantRepairs := []string{filepath.Join(dir, "day_0001.ogg"), filepath.Join(dir, "day_0002.ogg")}
+	if got := repairer.Repairs(); strings.Join(got, ",") != strings.Join(wantRepairs, ",") {
+		t.Errorf("repairer.Repairs() = %v, want %v", got, wantRepairs)
+	}
+	content, _ := os.ReadFile(list)
+	want := "ffconcat version 1.0\nfile day_0000.ogg\nfile 'day_0001.ogg'\nfile 'day_0002.ogg'\n"
+	if string(content) != want {
+		t.Errorf("segment list = %q, want %q", content, want)
+	}
+	if !strings.Contains(stderr.String(), "Recovered: "+list+" (3 files, 2 repaired)") {
+		t.Errorf("runRecover() stderr = %q, want the recovered list", stderr.String())
+	}
+}
+
+func TestRunRecover_TranscribesLiveRecording(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	liveDir := filepath.Join(tempDir, "go-transcript-live-123")
+	if err := os.Mkdir(liveDir, 0o750); err != nil {
+		t.Fatalf("failed to create live directory: %v", err)
+	}
+	outDir := t.TempDir()
+	output := filepath.Join(outDir, "notes.md")
+	writeInterruptedRecording(t, filepath.Join(liveDir, "recording.ogg"), "audio", recordingJournal{
+		Command:  "live",
+		PID:      deadPID(t),
+		Output:   output,
+		Language: "fr",
+	})
+
+	stderr := &syncBuffer{}
+	var gotLanguage string
+	env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		gotLanguage = opts.Language.String()
+		return "Bonjour.", nil
+	})
+	repairer := &mockRepairer{}
+	env.RepairerFactory = &mockRepairerFactory{mockRepairer: repairer}
+
+	opts := recoverOptions{dirs: []string{outDir}, tempDir: tempDir, transcribe: true}
+	if err := runRecover(recoverTestCmd(), env, opts); err != nil {
+		t.Fatalf("runRecover() unexpected error: %v\nstderr: %s", err, stderr.String())
+	}
+
+	audioPath := filepath.Join(outDir, "notes.ogg")
+	if content, _ := os.ReadFile(audioPath); string(content) != "audio" {
+		t.Errorf("recording moved next to the transcript = %q, want %q", content, "audio")
+	}
+	if _, err := os.Stat(liveDir); !os.IsNotExist(err) {
+		t.Errorf("live temporary directory after recover: stat error = %v, want not exist", err)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read transcript: %v", err)
+	}
+	if !strings.Contains(string(content), "Bonjour.") {
+		t.Errorf("transcript = %q, want containing %q", content, "Bonjour.")
+	}
+	if gotLanguage != "fr" {
+		t.Errorf("transcription language = %q, want the saved %q", gotLanguage, "fr")
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		answers *bufio.Reader
+		want    bool
+	}{
+		{"yes", bufio.NewReader(strings.NewReader("y\n")), true},
+		{"full yes", bufio.NewReader(strings.NewReader("YES\n")), true},
+		{"no", bufio.NewReader(strings.NewReader("n\n")), false},
+		{"empty answer", bufio.NewReader(strings.NewReader("\n")), false},
+		{"not a terminal", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			env := &Env{Stderr: &syncBuffer{}}
+			if got := confirm(env, tt.answers, "Transcribe it now?"); got != tt.want {
+				t.Errorf("confirm() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordingJournal_TranscribeCommand(t *testing.T) {
+	t.Parallel()
+
+	j := recordingJournal{Output: "my notes.md", Template: "brainstorm", Language: "fr", Diarize: true, Format: "md"}
+	got := j.transcribeCommand("/rec/recording.ogg")
+	want := `transcript transcribe /rec/recording.ogg -o "my notes.md" -t brainstorm -l fr --diarize`
+	if got != want {
+		t.Errorf("transcribeCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestRecordingJournal_TemplateVars(t *testing.T) {
+	t.Parallel()
+
+	tmpl := writeVarsTemplate(t)
+	j := recordingJournal{Template: tmpl.Path(), Vars: map[string]string{"project": "Atlas", "attendees": "Alice, Bob"}, Format: "md"}
+
+	opts, err := j.transcribeOptions("/rec/recording.ogg", "")
+	if err != nil {
+		t.Fatalf("transcribeOptions() unexpected error: %v", err)
+	}
+	if want := "Standup of Atlas with Alice, Bob."; opts.template.Prompt() != want {
+		t.Errorf("template prompt = %q, want %q", opts.template.Prompt(), want)
+	}
+	want := `transcript transcribe /rec/recording.ogg -t ` + tmpl.Path() + ` --var "attendees=Alice, Bob" --var project=Atlas`
+	if got := j.transcribeCommand("/rec/recording.ogg"); got != want {
+		t.Errorf("transcribeCommand() = %q, want %q", got, want)
+	}
+
+	j.Vars = nil
+	if _, err := j.transcribeOptions("/rec/recording.ogg", ""); !errors.Is(err, template.ErrInvalidVar) {
+		t.Errorf("transcribeOptions() without values error = %v, want ErrInvalidVar", err)
+	}
+}
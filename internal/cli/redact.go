@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/redact"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// Help of the --redact and --redact-pattern flags of the transcribe and live commands.
+const (
+	redactFlagHelp        = "Mask emails, phone numbers and card numbers before the transcript is written or restructured (rules of --language)"
+	redactPatternFlagHelp = "Also mask text matching this regular expression (repeatable, implies --redact)"
+)
+
+// newRedactor returns the redactor of --redact for transcripts in language,
+// masking patterns and the patterns of the redact-patterns file too.
+// Returns nil without --redact or patterns.
+func newRedactor(enabled bool, patterns []string, language lang.Language, cfg config.Config) (*redact.Redactor, error) {
+	if !enabled && len(patterns) == 0 {
+		return nil, nil
+	}
+	if cfg.RedactPatterns != "" {
+		filePatterns, err := redact.ReadPatterns(cfg.RedactPatterns)
+		if err != nil {
+			return nil, err
+		}
+		patterns = slices.Concat(filePatterns, patterns)
+	}
+	return redact.New(language, patterns)
+}
+
+// redactResults masks sensitive data in the transcription results with
+// redactor (--redact), and reports how much was masked to w.
+// Timestamped results (see transcribe.Options.Timestamps) are redacted
+// segment by segment.
+// Returns results unchanged with a nil redactor.
+func redactResults(w io.Writer, redactor *redact.Redactor, results []string, timestamps bool) ([]string, error) {
+	if redactor == nil {
+		return results, nil
+	}
+	total := 0
+	redacted := make([]string, len(results))
+	for i, result := range results {
+		if !timestamps {
+			var n int
+			redacted[i], n = redactor.Redact(result)
+			total += n
+			continue
+		}
+		segments, err := transcribe.ParseSegments(result)
+		if err != nil {
+			return nil, err
+		}
+		for j := range segments {
+			var n int
+			segments[j].Text, n = redactor.Redact(segments[j].Text)
+			total += n
+		}
+		if redacted[i], err = transcribe.EncodeSegments(segments); err != nil {
+			return nil, err
+		}
+	}
+	fmt.Fprintf(w, "Redacted %d items\n", total)
+	return redacted, nil
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/redact"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// ---------------------------------------------------------------------------
+// Tests for newRedactor
+// ---------------------------------------------------------------------------
+
+func TestNewRedactor(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	patternsFile := filepath.Join(dir, "patterns.txt")
+	if err := os.WriteFile(patternsFile, []byte("# Employee IDs\nEMP-\\d{5}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	en := lang.MustParse("en")
+
+	t.Run("without redact", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newRedactor(false, nil, en, config.Config{RedactPatterns: patternsFile})
+		if err != nil || r != nil {
+			t.Errorf("newRedactor() = %v, %v, want nil, nil", r, err)
+		}
+	})
+
+	t.Run("patterns of the file and flags", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newRedactor(false, []string{`(?i)project falcon`}, en, config.Config{RedactPatterns: patternsFile})
+		if err != nil {
+			t.Fatalf("newRedactor() unexpected error: %v", err)
+		}
+		if got, n := r.Redact("EMP-12345 leads Project Falcon."); got != "[redacted] leads [redacted]." || n != 2 {
+			t.Errorf("Redact() = %q, %d, want both patterns masked", got, n)
+		}
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := newRedactor(true, []string{`EMP-(`}, en, config.Config{}); !errors.Is(err, redact.ErrInvalidPattern) {
+			t.Errorf("newRedactor() error = %v, want ErrInvalidPattern", err)
+		}
+	})
+
+	t.Run("missing patterns file", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.Config{RedactPatterns: filepath.Join(dir, "missing.txt")}
+		if _, err := newRedactor(true, nil, en, cfg); !errors.Is(err, redact.ErrInvalidPattern) {
+			t.Errorf("newRedactor() error = %v, want ErrInvalidPattern", err)
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// Tests for redactResults
+// ---------------------------------------------------------------------------
+
+func TestRedactResults(t *testing.T) {
+	t.Parallel()
+
+	redactor, err := newRedactor(true, nil, lang.MustParse("en"), config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("without redact", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		results := []string{"Mail jane@example.com."}
+		got, err := redactResults(stderr, nil, results, false)
+		if err != nil {
+			t.Fatalf("redactResults() unexpected error: %v", err)
+		}
+		if !slices.Equal(got, results) || stderr.String() != "" {
+			t.Errorf("redactResults() = %q, stderr %q, want results unchanged", got, stderr.String())
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		got, err := redactResults(stderr, redactor, []string{"Mail jane@example.com.", "Call 555-123-4567."}, false)
+		if err != nil {
+			t.Fatalf("redactResults() unexpected error: %v", err)
+		}
+		want := []string{"Mail [email].", "Call [phone]."}
+		if !slices.Equal(got, want) {
+			t.Errorf("redactResults() = %q, want %q", got, want)
+		}
+		if !strings.Contains(stderr.String(), "Redacted 2 items") {
+			t.Errorf("stderr = %q, want the count", stderr.String())
+		}
+	})
+
+	t.Run("segments", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := transcribe.EncodeSegments([]transcribe.TimedSegment{
+			{Start: 0, End: 1e9, Text: "Hi."},
+			{Start: 1e9, End: 2e9, Text: "Card 4111 1111 1111 1111."},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := redactResults(&syncBuffer{}, redactor, []string{result}, true)
+		if err != nil {
+			t.Fatalf("redactResults() unexpected error: %v", err)
+		}
+		segments, err := transcribe.ParseSegments(got[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(segments) != 2 || segments[1].Text != "Card [card]." || segments[1].Start != 1e9 {
+			t.Errorf("segments = %+v, want the card of the second segment masked", segments)
+		}
+	})
+}
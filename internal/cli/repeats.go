@@ -0,0 +1,274 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// Intro and outro handling names (--intro-outro).
+const (
+	// IntroOutroSkip leaves repeated intros and outros out of the transcript.
+	IntroOutroSkip = "skip"
+	// IntroOutroMark replaces them with an [intro] or [outro] marker.
+	IntroOutroMark = "mark"
+)
+
+// IntroOutroMode represents a validated --intro-outro value.
+// Zero value means "not set": intros and outros are transcribed like the rest.
+type IntroOutroMode struct {
+	name string
+}
+
+// Compile-time interface compliance check.
+var _ fmt.Stringer = IntroOutroMode{}
+
+// ParseIntroOutroMode validates and parses an --intro-outro value.
+// Returns ErrInvalidIntroOutro if the value is not recognized.
+func ParseIntroOutroMode(s string) (IntroOutroMode, error) {
+	switch s {
+	case IntroOutroSkip, IntroOutroMark:
+		return IntroOutroMode{name: s}, nil
+	default:
+		return IntroOutroMode{}, fmt.Errorf("unknown intro-outro mode %q (use %s or %s): %w",
+			s, IntroOutroSkip, IntroOutroMark, ErrInvalidIntroOutro)
+	}
+}
+
+// String returns the mode name string.
+// Returns empty string for zero value.
+func (m IntroOutroMode) String() string {
+	return m.name
+}
+
+// IsZero returns true if this is the zero value (no mode set).
+func (m IntroOutroMode) IsZero() bool {
+	return m.name == ""
+}
+
+// minKeptPiece is the shortest audio kept between repeated segments.
+// Shorter leftovers are the edges of the match, not content.
+const minKeptPiece = time.Second
+
+// introLibraryMu serializes updates of the intro library, whose
+// read-modify-write would otherwise lose entries in batch mode.
+var introLibraryMu sync.Mutex
+
+// repeatedSegment is an intro or outro found in an earlier recording,
+// positioned in the input.
+type repeatedSegment struct {
+	label      string // "intro" or "outro"
+	start, end time.Duration
+}
+
+// introOutro holds the intros and outros detected in an input (--intro-outro).
+// A nil *introOutro is valid and does nothing, so callers need not check
+// whether --intro-outro was given.
+type introOutro struct {
+	mode        IntroOutroMode
+	libraryPath string
+	fp          audio.Fingerprinter
+	duration    time.Duration
+	entry       audio.IntroEntry // Fingerprints of the input, learned after success
+	segments    []repeatedSegment
+}
+
+// detectIntroOutro fingerprints the beginning and end of inputPath and looks
+// them up in the intro library. Returns nil without mode. Detection failures
+// only warn: the whole input is then transcribed.
+func detectIntroOutro(ctx context.Context, env *Env, cfg config.Config, ffmpegPath, inputPath string, mode IntroOutroMode) *introOutro {
+	if mode.IsZero() {
+		return nil
+	}
+	if cfg.IntroLibrary == "" {
+		warnf(env.Stderr, warnSettingMissing, "%s is not configured, --intro-outro is ignored", config.KeyIntroLibrary)
+		return nil
+	}
+
+	r, err := newIntroOutro(ctx, env, cfg.IntroLibrary, ffmpegPath, inputPath, mode)
+	if err != nil {
+		warnf(env.Stderr, warnIntroOutro, "intro/outro detection failed: %v", err)
+		return nil
+	}
+	for _, s := range r.segments {
+		fmt.Fprintf(env.Stderr, "Found %s heard in an earlier recording: %s-%s\n",
+			s.label, s.start.Round(time.Second), s.end.Round(time.Second))
+	}
+	return r
+}
+
+// newIntroOutro computes the fingerprints of inputPath and finds its repeated segments.
+func newIntroOutro(ctx context.Context, env *Env, libraryPath, ffmpegPath, inputPath string, mode IntroOutroMode) (*introOutro, error) {
+	source, err := filepath.Abs(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve input path: %w", err)
+	}
+	fp, err := env.FingerprinterFactory.NewFingerprinter(ffmpegPath)
+	if err != nil {
+		return nil, err
+	}
+	duration, err := fp.Duration(ctx, inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tailStart := max(0, duration-audio.IntroWindow)
+	head, err := fp.Fingerprint(ctx, inputPath, 0, audio.IntroWindow)
+	if err != nil {
+		return nil, err
+	}
+	tail, err := fp.Fingerprint(ctx, inputPath, tailStart, audio.IntroWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	library, err := audio.LoadIntroLibrary(libraryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &introOutro{
+		mode:        mode,
+		libraryPath: libraryPath,
+		fp:          fp,
+		duration:    duration,
+		entry:       audio.IntroEntry{Source: source, Head: head, Tail: tail},
+	}
+	if intro, ok := library.FindIntro(source, head); ok {
+		r.segments = append(r.segments, repeatedSegment{label: "intro", start: intro.Start, end: intro.End})
+	}
+	if outro, ok := library.FindOutro(source, tail); ok {
+		s := repeatedSegment{label: "outro", start: tailStart + outro.Start, end: tailStart + outro.End}
+		// In a short recording the head and tail overlap: the intro wins
+		if len(r.segments) == 0 || s.start >= r.segments[0].end {
+			r.segments = append(r.segments, s)
+		}
+	}
+	return r, nil
+}
+
+// keptRanges returns the parts of the input outside the repeated segments.
+func (r *introOutro) keptRanges() [][2]time.Duration {
+	var ranges [][2]time.Duration
+	start := time.Duration(0)
+	for _, s := range r.segments {
+		if s.start-start >= minKeptPiece {
+			ranges = append(ranges, [2]time.Duration{start, s.start})
+		}
+		start = s.end
+	}
+	if r.duration-start >= minKeptPiece {
+		ranges = append(ranges, [2]time.Duration{start, r.duration})
+	}
+	return ranges
+}
+
+// chunk splits inputPath into chunks with chunker, leaving the repeated
+// segments out. Chunk times stay relative to the input, so subtitles and
+// markers line up with the original audio.
+func (r *introOutro) chunk(ctx context.Context, chunker audio.Chunker, inputPath string) ([]audio.Chunk, error) {
+	if r == nil || len(r.segments) == 0 {
+		return chunker.Chunk(ctx, inputPath)
+	}
+
+	dir, err := os.MkdirTemp("", "go-transcript-pieces-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	// Chunks are extracted into their own directories: pieces are not needed afterwards
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	var chunks []audio.Chunk
+	for i, kept := range r.keptRanges() {
+		piece := filepath.Join(dir, fmt.Sprintf("piece_%03d.ogg", i))
+		if err := r.fp.Extract(ctx, inputPath, piece, kept[0], kept[1]); err != nil {
+			_ = audio.CleanupChunks(chunks)
+			return nil, err
+		}
+		pieceChunks, err := chunker.Chunk(ctx, piece)
+		if err != nil {
+			_ = audio.CleanupChunks(chunks)
+			return nil, err
+		}
+		for _, c := range pieceChunks {
+			c.Index = len(chunks)
+			c.StartTime += kept[0]
+			c.EndTime += kept[0]
+			chunks = append(chunks, c)
+		}
+	}
+	return chunks, nil
+}
+
+// mark inserts an [intro] or [outro] marker in place of each repeated
+// segment into the transcription results of chunks, for rendering.
+// Returns chunks and results unchanged unless the mode is mark.
+// Timestamped results (see transcribe.Options.Timestamps) get a timed marker.
+func (r *introOutro) mark(chunks []audio.Chunk, results []string, timestamps bool) ([]audio.Chunk, []string, error) {
+	if r == nil || r.mode.name != IntroOutroMark || len(r.segments) == 0 {
+		return chunks, results, nil
+	}
+
+	type item struct {
+		chunk  audio.Chunk
+		result string
+	}
+	items := make([]item, 0, len(chunks)+len(r.segments))
+	for i, c := range chunks {
+		items = append(items, item{c, results[i]})
+	}
+	for _, s := range r.segments {
+		text := "[" + s.label + "]"
+		if timestamps {
+			var err error
+			text, err = transcribe.EncodeSegments([]transcribe.TimedSegment{
+				{Start: 0, End: s.end - s.start, Text: text},
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		items = append(items, item{audio.Chunk{StartTime: s.start, EndTime: s.end}, text})
+	}
+	slices.SortStableFunc(items, func(a, b item) int {
+		return int(a.chunk.StartTime - b.chunk.StartTime)
+	})
+
+	markedChunks := make([]audio.Chunk, len(items))
+	markedResults := make([]string, len(items))
+	for i, it := range items {
+		markedChunks[i] = it.chunk
+		markedChunks[i].Index = i
+		markedResults[i] = it.result
+	}
+	return markedChunks, markedResults, nil
+}
+
+// learn adds the fingerprints of the input to the intro library, so that
+// later recordings can recognize its intro and outro. Failures only warn.
+func (r *introOutro) learn(env *Env) {
+	if r == nil {
+		return
+	}
+	introLibraryMu.Lock()
+	defer introLibraryMu.Unlock()
+
+	library, err := audio.LoadIntroLibrary(r.libraryPath)
+	if err == nil {
+		entry := r.entry
+		entry.Added = env.Now()
+		library.Add(entry)
+		err = library.Save(r.libraryPath)
+	}
+	if err != nil {
+		warnf(env.Stderr, warnIntroOutro, "failed to update intro library: %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestParseIntroOutroMode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "skip", want: IntroOutroSkip},
+		{input: "mark", want: IntroOutroMark},
+		{input: "", wantErr: true},
+		{input: "Skip", wantErr: true},
+		{input: "remove", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseIntroOutroMode(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidIntroOutro) {
+					t.Errorf("ParseIntroOutroMode(%q) error = %v, want ErrInvalidIntroOutro", tt.input, err)
+				}
+				return
+			}
+			if err != nil || got.String() != tt.want {
+				t.Errorf("ParseIntroOutroMode(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntroOutro_KeptRanges(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		segments []repeatedSegment
+		want     [][2]time.Duration
+	}{
+		{
+			name: "intro and outro",
+			segments: []repeatedSegment{
+				{label: "intro", start: 0, end: 30 * time.Second},
+				{label: "outro", start: 9 * time.Minute, end: 10 * time.Minute},
+			},
+			want: [][2]time.Duration{{30 * time.Second, 9 * time.Minute}},
+		},
+		{
+			name:     "cold open before the intro",
+			segments: []repeatedSegment{{label: "intro", start: 20 * time.Second, end: time.Minute}},
+			want:     [][2]time.Duration{{0, 20 * time.Second}, {time.Minute, 10 * time.Minute}},
+		},
+		{
+			name:     "leftovers shorter than a second dropped",
+			segments: []repeatedSegment{{label: "outro", start: 9 * time.Minute, end: 10*time.Minute - 500*time.Millisecond}},
+			want:     [][2]time.Duration{{0, 9 * time.Minute}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &introOutro{duration: 10 * time.Minute, segments: tt.segments}
+			if got := r.keptRanges(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keptRanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntroOutro_Chunk(t *testing.T) {
+	t.Parallel()
+
+	fp := &mockFingerprinter{}
+	r := &introOutro{
+		fp:       fp,
+		duration: 10 * time.Minute,
+		segments: []repeatedSegment{{label: "intro", start: 20 * time.Second, end: time.Minute}},
+	}
+	chunker := &mockChunker{
+		ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+			return []audio.Chunk{{Path: audioPath, StartTime: 0, EndTime: 10 * time.Second}}, nil
+		},
+	}
+
+	chunks, err := r.chunk(context.Background(), chunker, "episode.mp3")
+	if err != nil {
+		t.Fatalf("chunk() unexpected error: %v", err)
+	}
+
+	calls := fp.ExtractCalls()
+	if len(calls) != 2 {
+		t.Fatalf("Extract() called %d times, want 2", len(calls))
+	}
+	if calls[1].Start != time.Minute || calls[1].End != 10*time.Minute {
+		t.Errorf("second piece = %v-%v, want 1m0s-10m0s", calls[1].Start, calls[1].End)
+	}
+
+	// Times are shifted back to the input timeline, indices renumbered
+	want := []audio.Chunk{
+		{Path: calls[0].OutputPath, Index: 0, StartTime: 0, EndTime: 10 * time.Second},
+		{Path: calls[1].OutputPath, Index: 1, StartTime: time.Minute, EndTime: time.Minute + 10*time.Second},
+	}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("chunk() = %v, want %v", chunks, want)
+	}
+
+	// Pieces are removed once chunked
+	if _, err := os.Stat(filepath.Dir(calls[0].OutputPath)); !os.IsNotExist(err) {
+		t.Errorf("pieces directory still exists: %v", err)
+	}
+}
+
+func TestIntroOutro_ChunkWithoutRepeats(t *testing.T) {
+	t.Parallel()
+
+	var r *introOutro
+	chunker := &mockChunker{}
+	if _, err := r.chunk(context.Background(), chunker, "episode.mp3"); err != nil {
+		t.Fatalf("chunk() unexpected error: %v", err)
+	}
+	if calls := chunker.chunkCalls; len(calls) != 1 || calls[0] != "episode.mp3" {
+		t.Errorf("Chunk() calls = %v, want the input itself", calls)
+	}
+}
+
+func TestIntroOutro_Mark(t *testing.T) {
+	t.Parallel()
+
+	markMode, _ := ParseIntroOutroMode(IntroOutroMark)
+	skipMode, _ := ParseIntroOutroMode(IntroOutroSkip)
+	segments := []repeatedSegment{
+		{label: "intro", start: 0, end: 30 * time.Second},
+		{label: "outro", start: 9 * time.Minute, end: 10 * time.Minute},
+	}
+	chunks := []audio.Chunk{{Index: 0, StartTime: 30 * time.Second, EndTime: 9 * time.Minute}}
+
+	t.Run("text", func(t *testing.T) {
+		t.Parallel()
+
+		r := &introOutro{mode: markMode, segments: segments}
+		gotChunks, gotResults, err := r.mark(chunks, []string{"Welcome back."}, false)
+		if err != nil {
+			t.Fatalf("mark() unexpected error: %v", err)
+		}
+		if want := []string{"[intro]", "Welcome back.", "[outro]"}; !reflect.DeepEqual(gotResults, want) {
+			t.Errorf("mark() results = %q, want %q", gotResults, want)
+		}
+		for i, c := range gotChunks {
+			if c.Index != i {
+				t.Errorf("chunk %d Index = %d", i, c.Index)
+			}
+		}
+	})
+
+	t.Run("timestamps", func(t *testing.T) {
+		t.Parallel()
+
+		r := &introOutro{mode: markMode, segments: segments}
+		result := `[{"start":1,"end":2,"text":"Welcome back."}]`
+		gotChunks, gotResults, err := r.mark(chunks, []string{result}, true)
+		if err != nil {
+			t.Fatalf("mark() unexpected error: %v", err)
+		}
+		merged, err := transcribe.MergeSegments(gotChunks, gotResults)
+		if err != nil {
+			t.Fatalf("MergeSegments() unexpected error: %v", err)
+		}
+		want := []transcribe.TimedSegment{
+			{Start: 0, End: 30 * time.Second, Text: "[intro]"},
+			{Start: 31 * time.Second, End: 32 * time.Second, Text: "Welcome back."},
+			{Start: 9 * time.Minute, End: 10 * time.Minute, Text: "[outro]"},
+		}
+		if !reflect.DeepEqual(merged, want) {
+			t.Errorf("merged segments = %+v, want %+v", merged, want)
+		}
+	})
+
+	t.Run("skip leaves results unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		r := &introOutro{mode: skipMode, segments: segments}
+		_, gotResults, err := r.mark(chunks, []string{"Welcome back."}, false)
+		if err != nil || !reflect.DeepEqual(gotResults, []string{"Welcome back."}) {
+			t.Errorf("mark() = %q, %v; want results unchanged", gotResults, err)
+		}
+	})
+}
+
+func TestDetectIntroOutro_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	stderr := &syncBuffer{}
+	mode, _ := ParseIntroOutroMode(IntroOutroSkip)
+	r := detectIntroOutro(context.Background(), &Env{Stderr: stderr}, config.Config{}, "ffmpeg", "episode.mp3", mode)
+	if r != nil {
+		t.Errorf("detectIntroOutro() = %+v, want nil", r)
+	}
+	if !strings.Contains(stderr.String(), config.KeyIntroLibrary) {
+		t.Errorf("stderr = %q, want warning about %s", stderr.String(), config.KeyIntroLibrary)
+	}
+}
+
+func TestDetectIntroOutro_FingerprintFailureWarns(t *testing.T) {
+	t.Parallel()
+
+	stderr := &syncBuffer{}
+	env := &Env{
+		Stderr: stderr,
+		FingerprinterFactory: &mockFingerprinterFactory{mockFingerprinter: &mockFingerprinter{
+			DurationFunc: func(ctx context.Context, audioPath string) (time.Duration, error) {
+				return 0, errors.New("no duration")
+			},
+		}},
+	}
+	mode, _ := ParseIntroOutroMode(IntroOutroSkip)
+	cfg := config.Config{IntroLibrary: filepath.Join(t.TempDir(), "intros.json")}
+
+	if r := detectIntroOutro(context.Background(), env, cfg, "ffmpeg", "episode.mp3", mode); r != nil {
+		t.Errorf("detectIntroOutro() = %+v, want nil", r)
+	}
+	if !strings.Contains(stderr.String(), "no duration") {
+		t.Errorf("stderr = %q, want the detection error", stderr.String())
+	}
+}
@@ -2,9 +2,17 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/alnah/go-transcript/internal/config"
 	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/progress"
 	"github.com/alnah/go-transcript/internal/restructure"
 	"github.com/alnah/go-transcript/internal/template"
 )
@@ -19,42 +27,502 @@ type RestructureOptions struct {
 	OutputLang lang.Language
 	// Optional progress callback for long transcripts
 	OnProgress func(phase string, current, total int)
+	// Prompt size (tokens) above which a single call warns: zero = default
+	PromptTokenWarning int
+	// Ollama server and model (optional, --provider ollama): zero values = defaults
+	Ollama OllamaConfig
+	// Azure OpenAI resource of --provider openai (optional): empty endpoint = OpenAI
+	Azure AzureConfig
+	// Provider model (optional, --restructure-model): empty = provider default.
+	// Overrides the Ollama model.
+	Model string
+	// Context windows added to or overriding the built-in table (optional)
+	ContextWindows restructure.ContextWindows
+	// How long transcripts are divided into parts (config restructure-split): empty = paragraphs
+	Split string
+	// Size of the parts of long transcripts, in tokens (--restructure-chunk-tokens): zero = from the model
+	ChunkTokens int
+	// Tokens of the previous part repeated in each part (--restructure-overlap): zero = none
+	Overlap int
+	// Max reduce levels merging the parts of long transcripts (--max-reduce-levels): zero = default
+	ReduceLevels int
+	// Self-consistency runs merged into the output (optional, --self-consistency): <= 1 = disabled
+	SelfConsistency int
+	// Max estimated cost of a self-consistency run, in US dollars (--max-cost): zero = default
+	MaxCost float64
+	// Terms listed in the system prompt, in order of priority (optional, --glossary)
+	Glossary []string
+	// Times of the highlight markers dropped while recording (optional)
+	Highlights []time.Duration
+	// Writer receiving the output as it is generated (optional, --stream-restructure)
+	Stream io.Writer
+	// Provider retrying with its default model when Provider fails with an
+	// exhausted quota or a rejected key (optional, --fallback-provider)
+	FallbackProvider Provider
+
+	// Run report receiving the model and token usage (optional)
+	report *runReport
+	// Action items extracted once the templates succeeded (optional, --actions)
+	actions *actionsRun
+}
+
+// Help of the --restructure-chunk-tokens and --restructure-overlap flags of
+// the transcribe, live and structure commands.
+const (
+	chunkTokensFlagHelp = "Size of the parts of long transcripts, in tokens (at least 1000; default: configured, or from the model context window)"
+	overlapFlagHelp     = "Tokens of the end of each part repeated at the start of the next, as context (default: configured, or none)"
+)
+
+// reduceLevelsFlagHelp describes the --max-reduce-levels flag of the
+// transcribe, live and structure commands.
+var reduceLevelsFlagHelp = fmt.Sprintf("Max levels merging the parts of long transcripts, condensing groups of parts first when they do not fit in one call (default %d; 1 merges all parts in one call)", restructure.DefaultReduceLevels)
+
+// validateChunking checks the --restructure-chunk-tokens,
+// --restructure-overlap and --max-reduce-levels flags: zero means configured
+// (the default for reduce levels).
+func validateChunking(chunkTokens, overlap, reduceLevels int) error {
+	if chunkTokens != 0 && chunkTokens < config.MinRestructureChunkTokens {
+		return fmt.Errorf("--restructure-chunk-tokens must be at least %d, got %d", config.MinRestructureChunkTokens, chunkTokens)
+	}
+	if overlap < 0 {
+		return fmt.Errorf("--restructure-overlap must not be negative, got %d", overlap)
+	}
+	if reduceLevels < 0 {
+		return fmt.Errorf("--max-reduce-levels must not be negative, got %d", reduceLevels)
+	}
+	return nil
+}
+
+// applyChunking overrides the configured part size and overlap with the
+// --restructure-chunk-tokens and --restructure-overlap flags that are set.
+func applyChunking(cfg *config.Config, chunkTokens, overlap int) {
+	if chunkTokens > 0 {
+		cfg.RestructureChunkTokens = chunkTokens
+	}
+	if overlap > 0 {
+		cfg.RestructureOverlap = overlap
+	}
+}
+
+// anchorsFlagHelp describes the --anchors flag of the transcribe and live commands.
+const anchorsFlagHelp = "End each bullet and section of the restructured notes with its audio range, like (12:30–15:10) (requires --template)"
+
+// anchorTemplate returns the anchored variant of tmpl with --anchors (see
+// template.Name.Anchored), or tmpl unchanged without it.
+func anchorTemplate(tmpl template.Name, anchors bool) (template.Name, error) {
+	if !anchors {
+		return tmpl, nil
+	}
+	if tmpl.IsZero() {
+		return template.Name{}, fmt.Errorf("--anchors requires --template (anchors link restructured notes to the audio)")
+	}
+	return tmpl.Anchored(), nil
+}
+
+// summaryLevelFlagHelp describes the --summary-level flag of the transcribe,
+// live and structure commands.
+const summaryLevelFlagHelp = "Length of the restructured notes: short (5-line executive summary), medium (the template as written) or detailed (every point, with its context)"
+
+// levelTemplate returns tmpl at the summary level of --summary-level (see
+// template.Name.WithLevel). Empty keeps the template as written.
+// Returns template.ErrInvalidLevel if level is unknown.
+func levelTemplate(tmpl template.Name, level string) (template.Name, error) {
+	if level == "" {
+		return tmpl, nil
+	}
+	parsed, err := template.ParseLevel(level)
+	if err != nil {
+		return template.Name{}, err
+	}
+	if tmpl.IsZero() {
+		return template.Name{}, fmt.Errorf("--summary-level requires --template (it sets the length of the restructured notes)")
+	}
+	return tmpl.WithLevel(parsed), nil
+}
+
+// restructureAPIKey returns the API key of provider from the environment or
+// the keyring.
+// OpenAI restructuring reuses the transcription key. Ollama needs none.
+func restructureAPIKey(env *Env, provider Provider) (string, error) {
+	switch {
+	case provider.IsOllama():
+		return "", nil
+	case provider.IsOpenAI():
+		if key := apiKey(env, EnvOpenAIAPIKey); key != "" {
+			return key, nil
+		}
+		return "", fmt.Errorf("%w (%s)", ErrAPIKeyMissing, setKeyHint(EnvOpenAIAPIKey, "sk-...", provider))
+	case provider.IsAnthropic():
+		if key := apiKey(env, EnvAnthropicAPIKey); key != "" {
+			return key, nil
+		}
+		return "", fmt.Errorf("%w (%s)", ErrAnthropicKeyMissing, setKeyHint(EnvAnthropicAPIKey, "sk-ant-...", provider))
+	default:
+		if key := apiKey(env, EnvDeepSeekAPIKey); key != "" {
+			return key, nil
+		}
+		return "", fmt.Errorf("%w (%s)", ErrDeepSeekKeyMissing, setKeyHint(EnvDeepSeekAPIKey, "sk-...", DeepSeekProvider))
+	}
+}
+
+// restructureModel returns the model flag, or the configured restructure
+// model if the flag is empty.
+func restructureModel(flag string, cfg config.Config) string {
+	if flag != "" {
+		return flag
+	}
+	return cfg.RestructureModel
+}
+
+// defaultMaxCost is the default --max-cost, in US dollars.
+const defaultMaxCost = 1.0
+
+// maxCost returns the --max-cost flag, or the default if it is not set.
+func maxCost(flag float64) float64 {
+	if flag > 0 {
+		return flag
+	}
+	return defaultMaxCost
+}
+
+// streamRestructureFlagHelp describes the --stream-restructure flag of the
+// transcribe, live and structure commands.
+const streamRestructureFlagHelp = "Write the restructured notes to the output file and stderr as they are generated (requires --template)"
+
+// validateStreamRestructure checks --stream-restructure: it needs a template
+// to restructure with, and the streamed text would break --progress json lines.
+func validateStreamRestructure(stream bool, tmpl template.Name, progressFmt string) error {
+	if !stream {
+		return nil
+	}
+	if tmpl.IsZero() {
+		return fmt.Errorf("--stream-restructure requires --template")
+	}
+	if progressFmt == ProgressJSON {
+		return fmt.Errorf("--stream-restructure cannot be combined with --progress %s (the notes would be mixed with the JSON lines)", ProgressJSON)
+	}
+	return nil
+}
+
+// validateSelfConsistency checks a --self-consistency value: 0 or 1 disable it,
+// and it needs a template to restructure with.
+func validateSelfConsistency(n int, tmpl template.Name) error {
+	if n < 0 || n > restructure.MaxSelfConsistency {
+		return fmt.Errorf("--self-consistency must be between 1 and %d, got %d", restructure.MaxSelfConsistency, n)
+	}
+	if n > 1 && tmpl.IsZero() {
+		return fmt.Errorf("--self-consistency requires --template")
+	}
+	return nil
+}
+
+// estimateRestructure estimates restructuring a transcript of transcriptTokens
+// with tmpl on provider and model, in runs self-consistency runs (<= 1: a regular run).
+// known is false if the model has no known price, including local models (free).
+func estimateRestructure(provider Provider, model string, windows restructure.ContextWindows, chunkTokens, transcriptTokens int, tmpl template.Name, runs int) (est restructure.Estimate, cost float64, known bool) {
+	est = restructure.EstimateSelfConsistency(transcriptTokens, tmpl, partTokens(provider, model, windows, chunkTokens), runs)
+
+	if price, ok := restructure.LookupPrice(model); ok && !provider.IsOllama() {
+		return est, price.Cost(est.Usage), true
+	}
+	return est, 0, false
+}
+
+// partTokens returns the MapReduce part size of model on provider: chunkTokens
+// if set, otherwise sized from the model (local models have small context windows).
+func partTokens(provider Provider, model string, windows restructure.ContextWindows, chunkTokens int) int {
+	if chunkTokens > 0 {
+		return chunkTokens
+	}
+	if provider.IsOllama() {
+		return restructure.NewOllamaRestructurer().MaxInputTokens()
+	}
+	return restructure.PartTokens(model, windows)
+}
+
+// checkSelfConsistencyCost prints the estimate of restructuring content with
+// opts.SelfConsistency runs, and returns ErrCostLimit if it exceeds opts.MaxCost.
+// Local models are free; models without a known price are not checked.
+func checkSelfConsistencyCost(env *Env, content string, opts RestructureOptions) error {
+	model := providerModel(opts.Provider, opts.Model, opts.Ollama)
+	est, cost, known := estimateRestructure(opts.Provider, model, opts.ContextWindows, opts.ChunkTokens,
+		restructure.EstimateTokens(content), opts.Template, opts.SelfConsistency)
+
+	limit := maxCost(opts.MaxCost)
+	summary := fmt.Sprintf("Self-consistency: %d runs + merge, ~%d calls, ~%d tokens",
+		est.Runs, est.Calls, est.Usage.TotalTokens())
+	switch {
+	case opts.Provider.IsOllama():
+		fmt.Fprintln(env.Stderr, summary+", free")
+	case !known:
+		fmt.Fprintf(env.Stderr, "%s, price unknown (--max-cost not checked)\n", summary)
+	case cost > limit:
+		return fmt.Errorf("self-consistency with %d runs is estimated at ~$%.2f, above --max-cost $%.2f: %w",
+			est.Runs, cost, limit, ErrCostLimit)
+	default:
+		fmt.Fprintf(env.Stderr, "%s, ~$%.2f\n", summary, cost)
+	}
+	return nil
+}
+
+// ollamaConfig returns the Ollama settings of cfg.
+func ollamaConfig(cfg config.Config) OllamaConfig {
+	return OllamaConfig{BaseURL: cfg.OllamaURL, Model: cfg.OllamaModel}
+}
+
+// stepProgress returns a restructuring progress callback calling onProgress and
+// reporting the step to the progress hooks of ctx. Returns nil if neither is set.
+func stepProgress(ctx context.Context, onProgress func(step string, current, total int)) func(string, int, int) {
+	if progress.FromContext(ctx).OnStep == nil {
+		return onProgress
+	}
+	return func(step string, current, total int) {
+		progress.Step(ctx, step, current, total)
+		if onProgress != nil {
+			onProgress(step, current, total)
+		}
+	}
 }
 
 // restructureContent transforms content using a template and LLM.
 // Resolves API key internally based on opts.Provider.
 // Template and Provider must be validated before calling this function.
+// If opts.Provider fails with an exhausted quota or a rejected key, the
+// transcript is restructured again with opts.FallbackProvider, if set.
 func restructureContent(ctx context.Context, env *Env, content string, opts RestructureOptions) (string, error) {
+	results, err := restructureTemplates(ctx, env, content, []template.Name{opts.Template}, opts)
+	if err != nil {
+		return "", err
+	}
+	return results[0], nil
+}
+
+// restructureTemplates transforms content with each of tmpls (opts.Template
+// is ignored), returning their outputs in order. Long transcripts are mapped
+// once for all the templates that can share it (see
+// restructure.TemplatesRestructurer). Falls back like restructureContent.
+func restructureTemplates(ctx context.Context, env *Env, content string, tmpls []template.Name, opts RestructureOptions) ([]string, error) {
+	results, err := restructureWith(ctx, env, content, tmpls, opts)
+	if err == nil || opts.FallbackProvider.IsZero() || !fallbackError(err) || ctx.Err() != nil {
+		return results, err
+	}
+	warnf(env.Stderr, warnRestructureFallback, "restructuring with %s failed: %v", opts.Provider.OrDefault(), err)
+	fmt.Fprintf(env.Stderr, "Restructuring again with provider %s...\n", opts.FallbackProvider)
+	if err := rewindStream(opts.Stream); err != nil {
+		return nil, err
+	}
+
+	// --restructure-model names a model of the provider that failed: use the fallback's default
+	opts.Provider, opts.FallbackProvider = opts.FallbackProvider, Provider{}
+	opts.Model = ""
+	return restructureWith(ctx, env, content, tmpls, opts)
+}
+
+// restructureWith restructures content with each of tmpls and opts.Provider
+// (see restructureTemplates).
+func restructureWith(ctx context.Context, env *Env, content string, tmpls []template.Name, opts RestructureOptions) ([]string, error) {
 	// 1. Default provider to DeepSeek if not specified
 	opts.Provider = opts.Provider.OrDefault()
 
 	// 2. Resolve API key based on provider
-	var apiKey string
-	if opts.Provider.IsDeepSeek() {
-		apiKey = env.Getenv(EnvDeepSeekAPIKey)
-		if apiKey == "" {
-			return "", fmt.Errorf("%w (set it with: export %s=sk-...)", ErrDeepSeekKeyMissing, EnvDeepSeekAPIKey)
-		}
-	} else if opts.Provider.IsOpenAI() {
-		apiKey = env.Getenv(EnvOpenAIAPIKey)
-		if apiKey == "" {
-			return "", fmt.Errorf("%w (set it with: export %s=sk-...)", ErrAPIKeyMissing, EnvOpenAIAPIKey)
-		}
+	apiKey, err := restructureAPIKey(env, opts.Provider)
+	if err != nil {
+		return nil, err
 	}
-	// Note: invalid provider case is now impossible since Provider type guarantees validity
 
 	// 3. Create restructurer with options
-	var mrOpts []restructure.MapReduceOption
-	if opts.OnProgress != nil {
-		mrOpts = append(mrOpts, restructure.WithMapReduceProgress(opts.OnProgress))
+	split, err := restructure.ParseSplit(opts.Split)
+	if err != nil {
+		return nil, err
+	}
+	if opts.PromptTokenWarning <= 0 {
+		opts.PromptTokenWarning = restructure.DefaultPromptTokenWarning
+	}
+	usage := restructure.NewUsageTracker(
+		restructure.WithPromptTokenWarning(opts.PromptTokenWarning, func(call int, u restructure.Usage) {
+			warnf(env.Stderr, warnPromptSize, "restructure call %d used %d prompt tokens (threshold %d)",
+				call, u.PromptTokens, opts.PromptTokenWarning)
+		}),
+	)
+	mrOpts := []restructure.MapReduceOption{restructure.WithMapReduceUsageTracker(usage)}
+	if onProgress := stepProgress(ctx, opts.OnProgress); onProgress != nil {
+		mrOpts = append(mrOpts, restructure.WithMapReduceProgress(onProgress))
+	}
+	if len(opts.ContextWindows) > 0 {
+		mrOpts = append(mrOpts, restructure.WithMapReduceContextWindows(opts.ContextWindows))
+	}
+	if split != restructure.SplitParagraphs {
+		mrOpts = append(mrOpts, restructure.WithMapReduceSplit(split))
+	}
+	if opts.ChunkTokens > 0 {
+		mrOpts = append(mrOpts, restructure.WithMapReduceMaxTokens(opts.ChunkTokens))
+	}
+	if opts.Overlap > 0 {
+		mrOpts = append(mrOpts, restructure.WithMapReduceOverlap(opts.Overlap))
+	}
+	if opts.ReduceLevels > 0 {
+		mrOpts = append(mrOpts, restructure.WithMapReduceLevels(opts.ReduceLevels))
+	}
+	if len(opts.Glossary) > 0 {
+		mrOpts = append(mrOpts, restructure.WithMapReduceGlossary(opts.Glossary))
+	}
+	if len(opts.Highlights) > 0 {
+		mrOpts = append(mrOpts, restructure.WithMapReduceHighlights(opts.Highlights))
+	}
+	if opts.Stream != nil {
+		mrOpts = append(mrOpts, restructure.WithMapReduceStream(opts.Stream))
+	}
+	if opts.SelfConsistency > 1 {
+		// Each run costs as much as a regular restructuring: check the estimate first
+		if err := checkSelfConsistencyCost(env, content, opts); err != nil {
+			return nil, err
+		}
+		mrOpts = append(mrOpts, restructure.WithMapReduceSelfConsistency(opts.SelfConsistency))
 	}
 
-	mr, err := env.RestructurerFactory.NewMapReducer(opts.Provider, apiKey, mrOpts...)
+	var mr restructure.MapReducer
+	switch {
+	case opts.Provider.IsOllama():
+		if opts.Model != "" {
+			opts.Ollama.Model = opts.Model
+		}
+		mr, err = env.RestructurerFactory.NewOllamaMapReducer(opts.Ollama, mrOpts...)
+	case opts.Provider.IsOpenAI() && opts.Azure.Endpoint != "":
+		if err := validateAzureChat(opts.Provider, opts.Azure); err != nil {
+			return nil, err
+		}
+		mr, err = env.RestructurerFactory.NewAzureMapReducer(apiKey, opts.Model, opts.Azure, mrOpts...)
+	default:
+		mr, err = env.RestructurerFactory.NewMapReducer(opts.Provider, apiKey, opts.Model, mrOpts...)
+	}
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// 4. Restructure content
-	result, _, err := mr.Restructure(ctx, content, opts.Template, opts.OutputLang)
-	return result, err
+	results, err := runTemplates(ctx, mr, content, tmpls, opts.OutputLang)
+	if err == nil {
+		opts.actions.extract(ctx, env, mr, content, opts.OutputLang)
+	}
+	printUsageSummary(env, usage)
+	opts.report.recordRestructure(providerModel(opts.Provider, opts.Model, opts.Ollama), opts.Provider.IsOllama(), usage)
+	return results, err
+}
+
+// runTemplates restructures content with each of tmpls, sharing the map
+// phase between them if mr supports it.
+func runTemplates(ctx context.Context, mr restructure.MapReducer, content string, tmpls []template.Name, outputLang lang.Language) ([]string, error) {
+	if tr, ok := mr.(restructure.TemplatesRestructurer); ok && len(tmpls) > 1 {
+		return tr.RestructureTemplates(ctx, content, tmpls, outputLang)
+	}
+	results := make([]string, len(tmpls))
+	for i, tmpl := range tmpls {
+		result, _, err := mr.Restructure(ctx, content, tmpl, outputLang)
+		if err != nil {
+			if len(tmpls) > 1 {
+				err = fmt.Errorf("template %s: %w", tmpl, err)
+			}
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// printUsageSummary writes the aggregated token usage of a restructure run to stderr.
+// Prints nothing if no call reported usage (e.g., the request failed).
+func printUsageSummary(env *Env, usage *restructure.UsageTracker) {
+	if usage.Calls() == 0 {
+		return
+	}
+	calls := "1 call"
+	if n := usage.Calls(); n > 1 {
+		calls = fmt.Sprintf("%d calls", n)
+	}
+	total := usage.Total()
+	fmt.Fprintf(env.Stderr, "Token usage: %d prompt + %d completion = %d tokens (%s)\n",
+		total.PromptTokens, total.CompletionTokens, total.TotalTokens(), calls)
+}
+
+// rawTranscriptPath returns where the raw transcript of output is kept (-r),
+// or saved when restructuring into output is interrupted: notes.md gives
+// notes.raw.md.
+func rawTranscriptPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".raw.md"
+}
+
+// writeRawTranscript saves the raw transcript to a file, replacing an
+// existing one with --force (mode).
+func writeRawTranscript(env *Env, mode outputMode, path, content string) error {
+	if mode == outputForce {
+		return replaceOutput(env, path, func(w io.Writer) error {
+			_, err := io.WriteString(w, content)
+			return err
+		})
+	}
+	// #nosec G302 G304 -- user-specified output file with standard permissions
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("raw transcript file already exists: %s: %w", path, ErrOutputExists)
+		}
+		return fmt.Errorf("cannot create raw transcript file: %w", err)
+	}
+
+	writeErr := func() error {
+		defer func() { _ = f.Close() }()
+		if _, err := f.WriteString(content); err != nil {
+			return fmt.Errorf("failed to write raw transcript: %w", err)
+		}
+		return nil
+	}()
+
+	if writeErr != nil {
+		_ = os.Remove(path)
+		return writeErr
+	}
+
+	fmt.Fprintf(env.Stderr, "Raw transcript saved: %s\n", path)
+	return nil
+}
+
+// restructureFailed handles a restructuring error. If restructuring was
+// interrupted (Ctrl+C), the raw transcript is saved so that the transcription
+// is not paid for again: savedPath is where it was already written (session,
+// --keep-raw-transcript, --stream), empty to write it to rawTranscriptPath.
+// Returns an error wrapping context.Canceled (exit code 130) when interrupted,
+// err otherwise.
+func restructureFailed(ctx context.Context, env *Env, err error, transcript, output, savedPath string, tmpl template.Name) error {
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		return err
+	}
+
+	path := savedPath
+	if path == "" {
+		path = rawTranscriptPath(output)
+		// Replaces the raw transcript of an earlier interrupted run of the same output.
+		// #nosec G306 -- transcript next to the user-specified output, standard permissions
+		if writeErr := os.WriteFile(path, []byte(transcript), 0644); writeErr != nil {
+			fmt.Fprint(env.Stderr, "\nInterrupted. ")
+			warnf(env.Stderr, warnFileNotSaved, "failed to save raw transcript: %v", writeErr)
+			return fmt.Errorf("restructuring interrupted: %w", context.Canceled)
+		}
+	}
+	fmt.Fprintf(env.Stderr, "\nInterrupted. Raw transcript saved: %s\n", path)
+	fmt.Fprintf(env.Stderr, "Restructure it without transcribing again: transcript structure %s -t %s\n", path, tmpl)
+	return fmt.Errorf("restructuring interrupted: %w", context.Canceled)
+}
+
+// restructureFailedStdout is restructureFailed for outputs printed to stdout
+// (--stdout): when interrupted, the raw transcript is printed to stdout
+// instead of saved, since nothing is written to files.
+func restructureFailedStdout(ctx context.Context, env *Env, stdout io.Writer, err error, transcript string) error {
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		return err
+	}
+	fmt.Fprintln(env.Stderr, "\nInterrupted. Raw transcript printed to stdout")
+	if _, writeErr := io.WriteString(stdout, transcript); writeErr != nil {
+		warnf(env.Stderr, warnFileNotSaved, "failed to print raw transcript: %v", writeErr)
+	}
+	return fmt.Errorf("restructuring interrupted: %w", context.Canceled)
 }
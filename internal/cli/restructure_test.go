@@ -3,9 +3,18 @@ package cli
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/alnah/go-transcript/internal/config"
 	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/progress"
 	"github.com/alnah/go-transcript/internal/restructure"
 	"github.com/alnah/go-transcript/internal/template"
 )
@@ -110,6 +119,121 @@ func TestRestructureContent_OpenAIMissingKey(t *testing.T) {
 	}
 }
 
+func TestRestructureContent_AnthropicMissingKey(t *testing.T) {
+	t.Parallel()
+
+	env := &Env{
+		Stderr: &syncBuffer{},
+		Getenv: func(key string) string {
+			if key == EnvDeepSeekAPIKey || key == EnvOpenAIAPIKey {
+				return "other-key"
+			}
+			return "" // No Anthropic key
+		},
+		RestructurerFactory: &mockRestructurerFactory{},
+	}
+
+	_, err := RestructureContent(context.Background(), env, "content", RestructureOptions{
+		Template: template.MustParseName("brainstorm"),
+		Provider: AnthropicProvider,
+	})
+
+	if !errors.Is(err, ErrAnthropicKeyMissing) {
+		t.Errorf("RestructureContent() error = %v, want ErrAnthropicKeyMissing", err)
+	}
+}
+
+func TestRestructureContent_OllamaNeedsNoKey(t *testing.T) {
+	t.Parallel()
+
+	restructurerFactory := &mockRestructurerFactory{}
+	env := &Env{
+		Stderr:              &syncBuffer{},
+		Getenv:              func(string) string { return "" }, // No API key at all
+		RestructurerFactory: restructurerFactory,
+	}
+
+	ollama := OllamaConfig{BaseURL: "http://gpu-box:11434", Model: "qwen2.5:14b"}
+	_, err := RestructureContent(context.Background(), env, "content", RestructureOptions{
+		Template: template.MustParseName("brainstorm"),
+		Provider: OllamaProvider,
+		Ollama:   ollama,
+	})
+	if err != nil {
+		t.Fatalf("RestructureContent() unexpected error: %v", err)
+	}
+
+	calls := restructurerFactory.NewMapReducerCalls()
+	if len(calls) != 1 || calls[0].Provider != OllamaProvider || calls[0].Ollama != ollama {
+		t.Errorf("factory calls = %+v, want one Ollama call with %+v", calls, ollama)
+	}
+}
+
+func TestRestructureContent_Model(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		provider   Provider
+		wantModel  string
+		wantOllama string
+	}{
+		{"hosted provider", OpenAIProvider, "gpt-4.1", ""},
+		{"ollama overrides the configured model", OllamaProvider, "", "gpt-4.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var capturedOpts []restructure.MapReduceOption
+			restructurerFactory := &mockRestructurerFactory{
+				NewMapReducerFunc: func(provider Provider, apiKey, model string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
+					capturedOpts = opts
+					return &mockMapReduceRestructurer{}, nil
+				},
+			}
+			env := &Env{
+				Stderr:              &syncBuffer{},
+				Getenv:              defaultTestEnv,
+				RestructurerFactory: restructurerFactory,
+			}
+
+			_, err := RestructureContent(context.Background(), env, "content", RestructureOptions{
+				Template:       template.MustParseName("brainstorm"),
+				Provider:       tt.provider,
+				Ollama:         OllamaConfig{Model: "qwen2.5:14b"},
+				Model:          "gpt-4.1",
+				ContextWindows: restructure.ContextWindows{"gpt-4.1": 64000},
+			})
+			if err != nil {
+				t.Fatalf("RestructureContent() unexpected error: %v", err)
+			}
+
+			calls := restructurerFactory.NewMapReducerCalls()
+			if len(calls) != 1 || calls[0].Model != tt.wantModel || calls[0].Ollama.Model != tt.wantOllama {
+				t.Errorf("factory calls = %+v, want model %q, Ollama model %q", calls, tt.wantModel, tt.wantOllama)
+			}
+			// Usage tracker + context windows
+			if len(capturedOpts) != 2 {
+				t.Errorf("NewMapReducer() options = %d, want 2", len(capturedOpts))
+			}
+		})
+	}
+}
+
+func TestRestructureModel(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{RestructureModel: "gpt-4o"}
+	if got := restructureModel("gpt-4.1", cfg); got != "gpt-4.1" {
+		t.Errorf("restructureModel(flag) = %q, want %q", got, "gpt-4.1")
+	}
+	if got := restructureModel("", cfg); got != "gpt-4o" {
+		t.Errorf("restructureModel(no flag) = %q, want %q", got, "gpt-4o")
+	}
+}
+
 func TestRestructureContent_FactoryError(t *testing.T) {
 	t.Parallel()
 
@@ -225,7 +349,7 @@ func TestRestructureContent_WithProgressCallback(t *testing.T) {
 
 	var capturedOpts []restructure.MapReduceOption
 	restructurerFactory := &mockRestructurerFactory{
-		NewMapReducerFunc: func(provider Provider, apiKey string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
+		NewMapReducerFunc: func(provider Provider, apiKey, model string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
 			capturedOpts = opts
 			return mockMR, nil
 		},
@@ -257,6 +381,39 @@ func TestRestructureContent_WithProgressCallback(t *testing.T) {
 	}
 }
 
+func TestStepProgress(t *testing.T) {
+	t.Parallel()
+
+	t.Run("without OnStep hook", func(t *testing.T) {
+		t.Parallel()
+
+		if got := stepProgress(context.Background(), nil); got != nil {
+			t.Error("stepProgress() = callback, want nil")
+		}
+	})
+
+	t.Run("reports steps to the hook and the callback", func(t *testing.T) {
+		t.Parallel()
+
+		var hooked, called []string
+		ctx := progress.WithHooks(context.Background(), progress.Hooks{
+			OnStep: func(step string, current, total int) {
+				hooked = append(hooked, fmt.Sprintf("%s %d/%d", step, current, total))
+			},
+		})
+		onProgress := stepProgress(ctx, func(step string, current, total int) {
+			called = append(called, fmt.Sprintf("%s %d/%d", step, current, total))
+		})
+		onProgress("map", 1, 2)
+		onProgress("reduce", 1, 1)
+
+		want := []string{"map 1/2", "reduce 1/1"}
+		if !slices.Equal(hooked, want) || !slices.Equal(called, want) {
+			t.Errorf("hook got %v, callback got %v; want %v", hooked, called, want)
+		}
+	})
+}
+
 func TestRestructureContent_RestructureError(t *testing.T) {
 	t.Parallel()
 
@@ -299,6 +456,7 @@ func TestRestructureContent_CorrectAPIKeyUsed(t *testing.T) {
 	}{
 		{"deepseek uses deepseek key", DeepSeekProvider, "test-deepseek-key"},
 		{"openai uses openai key", OpenAIProvider, "test-openai-key"},
+		{"anthropic uses anthropic key", AnthropicProvider, "test-anthropic-key"},
 	}
 
 	for _, tt := range tests {
@@ -339,3 +497,351 @@ func TestRestructureContent_CorrectAPIKeyUsed(t *testing.T) {
 		})
 	}
 }
+
+func TestRestructureContent_ReportsTokenUsage(t *testing.T) {
+	t.Parallel()
+
+	// Real MapReduceRestructurer over a DeepSeek mock server: usage is reported
+	// by the provider, so it cannot be faked through mockMapReduceRestructurer.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"# Notes"}}],` +
+			`"usage":{"prompt_tokens":1200,"completion_tokens":300,"total_tokens":1500}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	restructurerFactory := &mockRestructurerFactory{
+		NewMapReducerFunc: func(provider Provider, apiKey, model string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
+			r, err := restructure.NewDeepSeekRestructurer(apiKey, restructure.WithDeepSeekBaseURL(server.URL))
+			if err != nil {
+				return nil, err
+			}
+			return restructure.NewMapReduceRestructurer(r, opts...), nil
+		},
+	}
+
+	tests := []struct {
+		name        string
+		threshold   int
+		wantWarning bool
+	}{
+		{"below threshold", 0, false}, // Default threshold
+		{"above threshold", 1000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stderr := &syncBuffer{}
+			env := &Env{
+				Stderr:              stderr,
+				Getenv:              defaultTestEnv,
+				RestructurerFactory: restructurerFactory,
+			}
+
+			_, err := RestructureContent(context.Background(), env, "content", RestructureOptions{
+				Template:           template.MustParseName("brainstorm"),
+				Provider:           DeepSeekProvider,
+				PromptTokenWarning: tt.threshold,
+			})
+			if err != nil {
+				t.Fatalf("RestructureContent() unexpected error: %v", err)
+			}
+
+			output := stderr.String()
+			wantSummary := "Token usage: 1200 prompt + 300 completion = 1500 tokens (1 call)"
+			if !strings.Contains(output, wantSummary) {
+				t.Errorf("stderr = %q, want containing %q", output, wantSummary)
+			}
+			if got := strings.Contains(output, "Warning: restructure call 1 used 1200 prompt tokens"); got != tt.wantWarning {
+				t.Errorf("stderr = %q, warning present = %v, want %v", output, got, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestValidateSelfConsistency(t *testing.T) {
+	t.Parallel()
+
+	meeting := template.MustParseName("meeting")
+	tests := []struct {
+		name    string
+		n       int
+		tmpl    template.Name
+		wantErr string
+	}{
+		{"disabled", 0, template.Name{}, ""},
+		{"single run without template", 1, template.Name{}, ""},
+		{"several runs", 3, meeting, ""},
+		{"maximum", restructure.MaxSelfConsistency, meeting, ""},
+		{"negative", -1, meeting, "must be between"},
+		{"above maximum", restructure.MaxSelfConsistency + 1, meeting, "must be between"},
+		{"missing template", 3, template.Name{}, "requires --template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateSelfConsistency(tt.n, tt.tmpl)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateSelfConsistency() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateSelfConsistency() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateChunking(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		chunkTokens  int
+		overlap      int
+		reduceLevels int
+		wantErr      string
+	}{
+		{"configured", 0, 0, 0, ""},
+		{"chunk tokens, overlap and levels", 20000, 500, 2, ""},
+		{"minimum chunk tokens", config.MinRestructureChunkTokens, 0, 0, ""},
+		{"chunk tokens too small", 500, 0, 0, "must be at least"},
+		{"negative overlap", 0, -1, 0, "must not be negative"},
+		{"negative reduce levels", 0, 0, -1, "--max-reduce-levels must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateChunking(tt.chunkTokens, tt.overlap, tt.reduceLevels)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateChunking() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateChunking() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateStreamRestructure(t *testing.T) {
+	t.Parallel()
+
+	meeting := template.MustParseName("meeting")
+	tests := []struct {
+		name     string
+		stream   bool
+		tmpl     template.Name
+		progress string
+		wantErr  string
+	}{
+		{"disabled", false, template.Name{}, ProgressJSON, ""},
+		{"with template", true, meeting, ProgressText, ""},
+		{"without template", true, template.Name{}, ProgressText, "requires --template"},
+		{"json progress", true, meeting, ProgressJSON, "--progress json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateStreamRestructure(tt.stream, tt.tmpl, tt.progress)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateStreamRestructure() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateStreamRestructure() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyChunking(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{RestructureChunkTokens: 30000, RestructureOverlap: 200}
+	applyChunking(&cfg, 0, 0)
+	if cfg.RestructureChunkTokens != 30000 || cfg.RestructureOverlap != 200 {
+		t.Errorf("applyChunking() without flags = %d, %d, want the configured 30000, 200",
+			cfg.RestructureChunkTokens, cfg.RestructureOverlap)
+	}
+	applyChunking(&cfg, 10000, 500)
+	if cfg.RestructureChunkTokens != 10000 || cfg.RestructureOverlap != 500 {
+		t.Errorf("applyChunking() with flags = %d, %d, want 10000, 500",
+			cfg.RestructureChunkTokens, cfg.RestructureOverlap)
+	}
+}
+
+func TestRestructureContent_SelfConsistency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		provider   Provider
+		model      string
+		maxCost    float64
+		wantErr    error
+		wantStderr string
+	}{
+		{"within limit", DeepSeekProvider, "", 0, nil, "Self-consistency: 3 runs + merge, ~4 calls"},
+		{"above limit", DeepSeekProvider, "", 0.000001, ErrCostLimit, ""},
+		{"local model is free", OllamaProvider, "", 0.000001, nil, ", free\n"},
+		{"unknown price is not checked", OpenAIProvider, "my-model", 0.000001, nil, "price unknown (--max-cost not checked)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var capturedOpts []restructure.MapReduceOption
+			restructurerFactory := &mockRestructurerFactory{
+				NewMapReducerFunc: func(provider Provider, apiKey, model string, opts ...restructure.MapReduceOption) (restructure.MapReducer, error) {
+					capturedOpts = opts
+					return &mockMapReduceRestructurer{}, nil
+				},
+			}
+			stderr := &syncBuffer{}
+			env := &Env{
+				Stderr:              stderr,
+				Getenv:              defaultTestEnv,
+				RestructurerFactory: restructurerFactory,
+			}
+
+			_, err := RestructureContent(context.Background(), env, strings.Repeat("word ", 2000), RestructureOptions{
+				Template:        template.MustParseName("meeting"),
+				Provider:        tt.provider,
+				Model:           tt.model,
+				SelfConsistency: 3,
+				MaxCost:         tt.maxCost,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RestructureContent() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if calls := restructurerFactory.NewMapReducerCalls(); len(calls) != 0 {
+					t.Errorf("restructurer created despite the cost limit")
+				}
+				return
+			}
+
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want containing %q", stderr.String(), tt.wantStderr)
+			}
+			// Usage tracker + self-consistency
+			if len(capturedOpts) != 2 {
+				t.Errorf("options = %d, want 2", len(capturedOpts))
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests for restructureFailed - Interrupted restructuring
+// ---------------------------------------------------------------------------
+
+func TestInterruptedRawPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"notes.md", "notes.raw.md"},
+		{"out/meeting.txt", "out/meeting.raw.md"},
+		{"notes", "notes.raw.md"},
+	}
+
+	for _, tt := range tests {
+		if got := rawTranscriptPath(tt.output); got != tt.want {
+			t.Errorf("rawTranscriptPath(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestRestructureFailed(t *testing.T) {
+	t.Parallel()
+
+	tmpl := template.MustParseName("meeting")
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("not interrupted", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		env := &Env{Stderr: stderr}
+		output := filepath.Join(t.TempDir(), "notes.md")
+		apiErr := errors.New("LLM API error")
+
+		err := restructureFailed(context.Background(), env, apiErr, "raw", output, "", tmpl)
+		if err != apiErr {
+			t.Errorf("restructureFailed() = %v, want the restructure error", err)
+		}
+		if _, statErr := os.Stat(rawTranscriptPath(output)); !os.IsNotExist(statErr) {
+			t.Errorf("raw transcript written without interrupt (stat error: %v)", statErr)
+		}
+		if stderr.String() != "" {
+			t.Errorf("stderr = %q, want empty", stderr.String())
+		}
+	})
+
+	t.Run("interrupted", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		env := &Env{Stderr: stderr}
+		output := filepath.Join(t.TempDir(), "notes.md")
+		rawPath := rawTranscriptPath(output)
+
+		err := restructureFailed(canceled, env, errors.New("request failed"), "raw transcript", output, "", tmpl)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("restructureFailed() = %v, want context.Canceled", err)
+		}
+		got, readErr := os.ReadFile(rawPath)
+		if readErr != nil || string(got) != "raw transcript" {
+			t.Errorf("raw transcript = %q (err: %v), want %q", got, readErr, "raw transcript")
+		}
+		for _, want := range []string{
+			"Raw transcript saved: " + rawPath,
+			"transcript structure " + rawPath + " -t meeting",
+		} {
+			if !strings.Contains(stderr.String(), want) {
+				t.Errorf("stderr = %q, want containing %q", stderr.String(), want)
+			}
+		}
+	})
+
+	t.Run("interrupted with raw transcript already saved", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		env := &Env{Stderr: stderr}
+		dir := t.TempDir()
+		output := filepath.Join(dir, "notes.md")
+		savedPath := filepath.Join(dir, "session", "raw.md")
+
+		err := restructureFailed(canceled, env, context.Canceled, "raw transcript", output, savedPath, tmpl)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("restructureFailed() = %v, want context.Canceled", err)
+		}
+		if _, statErr := os.Stat(rawTranscriptPath(output)); !os.IsNotExist(statErr) {
+			t.Errorf("raw transcript written twice (stat error: %v)", statErr)
+		}
+		if want := "Raw transcript saved: " + savedPath; !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr = %q, want containing %q", stderr.String(), want)
+		}
+	})
+}
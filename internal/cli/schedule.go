@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// countdownRefresh is the delay between redraws of the countdown before a
+// scheduled start.
+const countdownRefresh = time.Second
+
+// Help of the --start-at and --start-in flags of the record and live commands.
+const (
+	startAtFlagHelp = "Start recording at this time of day, HH:MM (e.g., 14:00; tomorrow if already past)"
+	startInFlagHelp = "Start recording after this delay (e.g., 10m, 1h30m)"
+)
+
+// parseStartTime returns when recording starts: at the next occurrence of
+// startAt (HH:MM, today or tomorrow), or startIn after now. Both empty means
+// now, returned as the zero time. The flags are mutually exclusive.
+func parseStartTime(startAt, startIn string, now time.Time) (time.Time, error) {
+	switch {
+	case startAt != "" && startIn != "":
+		return time.Time{}, fmt.Errorf("--start-at and --start-in cannot be combined: %w", ErrInvalidStartTime)
+	case startAt != "":
+		t, err := time.Parse("15:04", startAt)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid --start-at %q: %w (use a time like 14:00)", startAt, ErrInvalidStartTime)
+		}
+		start := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+		if !start.After(now) {
+			start = start.AddDate(0, 0, 1)
+		}
+		return start, nil
+	case startIn != "":
+		d, err := time.ParseDuration(startIn)
+		if err != nil || d <= 0 {
+			return time.Time{}, fmt.Errorf("invalid --start-in %q: %w (use a positive delay like 10m)", startIn, ErrInvalidStartTime)
+		}
+		return now.Add(d), nil
+	}
+	return time.Time{}, nil
+}
+
+// waitForStart waits until start (the zero time: no wait), with a countdown
+// on env.Stderr: redrawn in place on a terminal, printed once otherwise.
+// Returns an error wrapping context.Canceled if ctx is canceled first (Ctrl+C),
+// before anything is recorded.
+func waitForStart(ctx context.Context, env *Env, start time.Time) error {
+	wait := start.Sub(env.Now())
+	if start.IsZero() || wait <= 0 {
+		return nil
+	}
+
+	draw := isTerminal(env.Stderr)
+	at := start.Format("15:04")
+	if !dayOf(start).Equal(dayOf(env.Now().In(start.Location()))) {
+		at = start.Format("Mon 15:04") // Tomorrow
+	}
+	if !draw {
+		fmt.Fprintf(env.Stderr, "Recording starts at %s, in %s... (press Ctrl+C to cancel)\n", at, format.DurationHuman(wait))
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	ticker := time.NewTicker(countdownRefresh)
+	defer ticker.Stop()
+	for {
+		if draw {
+			left := max(start.Sub(env.Now()).Round(time.Second), 0)
+			fmt.Fprintf(env.Stderr, "\rRecording starts at %s, in %-8s (press Ctrl+C to cancel)", at, format.Duration(left))
+		}
+		select {
+		case <-timer.C:
+			if draw {
+				fmt.Fprintln(env.Stderr)
+			}
+			return nil
+		case <-ctx.Done():
+			if draw {
+				fmt.Fprintln(env.Stderr)
+			}
+			fmt.Fprintln(env.Stderr, "Scheduled start canceled, nothing recorded")
+			return fmt.Errorf("scheduled start canceled: %w", context.Canceled)
+		case <-ticker.C:
+		}
+	}
+}
+
+// dayOf returns midnight of the day of t.
+func dayOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
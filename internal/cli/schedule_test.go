@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+func TestParseStartTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC)
+	tests := []struct {
+		name             string
+		startAt, startIn string
+		want             time.Time
+		wantErr          bool
+	}{
+		{name: "now"},
+		{name: "later today", startAt: "15:00", want: time.Date(2026, 1, 25, 15, 0, 0, 0, time.UTC)},
+		{name: "past time is tomorrow", startAt: "09:15", want: time.Date(2026, 1, 26, 9, 15, 0, 0, time.UTC)},
+		{name: "current minute is tomorrow", startAt: "14:30", want: time.Date(2026, 1, 26, 14, 30, 0, 0, time.UTC)},
+		{name: "delay", startIn: "10m", want: now.Add(10 * time.Minute)},
+		{name: "invalid time", startAt: "2pm", wantErr: true},
+		{name: "out of range time", startAt: "25:00", wantErr: true},
+		{name: "invalid delay", startIn: "soon", wantErr: true},
+		{name: "negative delay", startIn: "-5m", wantErr: true},
+		{name: "both", startAt: "15:00", startIn: "10m", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseStartTime(tt.startAt, tt.startIn, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidStartTime) {
+					t.Errorf("parseStartTime() error = %v, want ErrInvalidStartTime", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseStartTime() unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseStartTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaitForStart(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no scheduled start", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		env := &Env{Stderr: stderr, Now: time.Now}
+		if err := waitForStart(context.Background(), env, time.Time{}); err != nil {
+			t.Fatalf("waitForStart() unexpected error: %v", err)
+		}
+		if stderr.String() != "" {
+			t.Errorf("stderr = %q, want empty", stderr.String())
+		}
+	})
+
+	t.Run("waits until start", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		env := &Env{Stderr: stderr, Now: time.Now}
+		start := time.Now().Add(50 * time.Millisecond)
+		if err := waitForStart(context.Background(), env, start); err != nil {
+			t.Fatalf("waitForStart() unexpected error: %v", err)
+		}
+		if time.Now().Before(start) {
+			t.Error("waitForStart() returned before the start")
+		}
+		if !strings.Contains(stderr.String(), "Recording starts at "+start.Format("15:04")) {
+			t.Errorf("stderr = %q, want the countdown", stderr.String())
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		env := &Env{Stderr: stderr, Now: time.Now}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := waitForStart(ctx, env, time.Now().Add(time.Hour))
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("waitForStart() error = %v, want context.Canceled", err)
+		}
+		if !strings.Contains(stderr.String(), "nothing recorded") {
+			t.Errorf("stderr = %q, want the cancellation", stderr.String())
+		}
+	})
+}
+
+func TestRunRecord_ScheduledStartCanceled(t *testing.T) {
+	t.Parallel()
+
+	outputPath := filepath.Join(t.TempDir(), "test.ogg")
+	recorder := &mockRecorder{}
+	env := &Env{
+		Stderr:         &syncBuffer{},
+		Getenv:         func(string) string { return "" },
+		Now:            time.Now,
+		FFmpegResolver: &mockFFmpegResolver{},
+		ConfigLoader:   &mockConfigLoader{},
+		RecorderFactory: &mockRecorderFactory{
+			NewRecorderFunc: func(ffmpegPath, device string) (audio.Recorder, error) {
+				return recorder, nil
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // Ctrl+C during the countdown
+	err := RunRecord(ctx, env, recordOptions{
+		duration: 5 * time.Minute,
+		output:   outputPath,
+		start:    time.Now().Add(time.Hour),
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("RunRecord() error = %v, want context.Canceled", err)
+	}
+	if calls := recorder.RecordCalls(); len(calls) != 0 {
+		t.Errorf("recorder.Record() called %d times, want 0", len(calls))
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Errorf("output exists after a canceled start: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/schemas"
+)
+
+// SchemaCmd creates the schema command.
+// Prints the JSON schema of a machine-readable output.
+func SchemaCmd(env *Env) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "schema <name>",
+		Short: "Print the JSON schema of a machine-readable output",
+		Long: `Print the JSON schema of a machine-readable output.
+
+Schemas: ` + strings.Join(schemas.Names(), ", ") + `
+
+Every output carries a schema_version field. Within a version, fields are
+only added, never removed, renamed or retyped: tools written against a
+version keep working. Any other change bumps the version.`,
+		Example: `  transcript schema metadata
+  transcript schema progress > progress.schema.json
+  transcript schema progress -o progress.schema.json`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: schemas.Names(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output == "" {
+				return runSchema(cmd.OutOrStdout(), args[0])
+			}
+			var b strings.Builder
+			if err := runSchema(&b, args[0]); err != nil {
+				return err
+			}
+			return writeFileAtomic(config.ExpandPath(output), b.String())
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the schema to this file (default: stdout)")
+
+	return cmd
+}
+
+// runSchema writes the named schema to w.
+func runSchema(w io.Writer, name string) error {
+	data, err := schemas.Get(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/schemas"
+)
+
+func TestRunSchema(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	if err := RunSchema(&out, schemas.Progress); err != nil {
+		t.Fatalf("RunSchema() unexpected error: %v", err)
+	}
+	want, _ := schemas.Get(schemas.Progress)
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Errorf("RunSchema() output = %q, want the progress schema", out.String())
+	}
+}
+
+func TestRunSchema_Unknown(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	err := RunSchema(&out, "session")
+	if !errors.Is(err, schemas.ErrUnknown) {
+		t.Errorf("RunSchema() error = %v, want ErrUnknown", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("RunSchema() wrote %q on error", out.String())
+	}
+}
+
+// TestSessionMetadata_MatchesSchema guards against fields added to
+// session.json without being documented in the metadata schema.
+func TestSessionMetadata_MatchesSchema(t *testing.T) {
+	t.Parallel()
+
+	data, err := schemas.Get(schemas.Metadata)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("invalid metadata schema: %v", err)
+	}
+
+	typ := reflect.TypeOf(sessionMetadata{})
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if _, ok := schema.Properties[name]; !ok {
+			t.Errorf("sessionMetadata field %q missing from the metadata schema", name)
+		}
+	}
+	if len(schema.Properties) != typ.NumField() {
+		t.Errorf("metadata schema has %d properties, sessionMetadata has %d fields", len(schema.Properties), typ.NumField())
+	}
+}
+
+func TestSchemaCmd_Output(t *testing.T) {
+	t.Parallel()
+
+	output := filepath.Join(t.TempDir(), "progress.schema.json")
+	env, _ := testEnv()
+	cmd := SchemaCmd(env)
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{schemas.Progress, "-o", output})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("SchemaCmd.Execute() unexpected error: %v", err)
+	}
+
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("os.ReadFile(%q) unexpected error: %v", output, err)
+	}
+	want, _ := schemas.Get(schemas.Progress)
+	if !bytes.Equal(got, want) {
+		t.Errorf("output file = %q, want the progress schema", got)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q, want empty with --output", stdout.String())
+	}
+}
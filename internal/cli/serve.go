@@ -0,0 +1,630 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/format"
+	"github.com/alnah/go-transcript/internal/jobs"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// Server configuration.
+const (
+	// defaultServeAddr listens on the loopback interface only: every request
+	// uses your API keys.
+	defaultServeAddr = "127.0.0.1:8080"
+	// defaultMaxUpload is the default size limit of uploaded files (--max-upload).
+	defaultMaxUpload = "2GB"
+	// serveQueueCapacity is the number of uploads that can wait for a worker.
+	serveQueueCapacity = 20
+	// serveFormMemory is the part of an upload kept in memory, the rest is
+	// spooled to a temporary file.
+	serveFormMemory = 32 << 20
+	// serveJobRetention is how long the status and transcript of a finished
+	// job can be polled.
+	serveJobRetention = jobs.DefaultRetention
+	// serveRetryAfter is the Retry-After of uploads refused because the queue is full.
+	serveRetryAfter = 60 * time.Second
+	// serveShutdownTimeout bounds how long stopping waits for open requests.
+	serveShutdownTimeout = 10 * time.Second
+	// serveTokenEnv sets the bearer token of the API, instead of a random one.
+	serveTokenEnv = "TRANSCRIPT_SERVE_TOKEN"
+)
+
+// serveOptions configures the serve command.
+type serveOptions struct {
+	addr      string            // Listen address (--addr)
+	file      transcribeOptions // Default pipeline options of every upload (without input and output)
+	jobs      int               // Uploads transcribed concurrently
+	maxUpload int64             // Size limit of an upload, in bytes (--max-upload)
+	token     string            // Bearer token required by every request
+}
+
+// ServeCmd creates the serve command.
+// Exposes the transcription pipeline as a local HTTP API.
+func ServeCmd(env *Env) *cobra.Command {
+	var (
+		addr       string
+		tmpl       string
+		diarize    bool
+		parallel   string
+		language   string
+		outputLang string
+		provider   string
+		model      string
+		backend    string
+		jobs       int
+		maxUpload  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the transcription pipeline over a local HTTP API",
+		Long: `Start a local HTTP server transcribing the audio files posted to it, so that
+other tools can reuse the pipeline (chunking, transcription, restructuring)
+without running the CLI.
+
+  POST /v1/transcriptions   Upload a file (multipart/form-data, field "file")
+  GET  /v1/jobs/{id}        Status of a transcription, with its transcript once done
+
+Uploads are queued and transcribed --jobs at a time. By default, the request
+waits for the transcript; with the form field async=true, it returns at once
+with the job ID to poll. The form fields template, language, translate and
+diarize override the flags for one upload (templates are selected by name).
+
+Every request carries the header "Authorization: Bearer <token>". The token
+is printed at startup; set TRANSCRIPT_SERVE_TOKEN to choose it. Requests from
+web pages of other origins, and with a Host other than the listen address or
+localhost, are refused (403), so that sites open in a browser cannot use it.
+
+Responses are JSON. Transcripts are kept in memory for an hour after their
+job finished: later, its ID is unknown (404).
+
+The server listens on 127.0.0.1 only, unless --addr says otherwise. Every
+request uses your API keys: do not expose it to other machines. Press Ctrl+C
+to stop; transcriptions in progress are canceled.`,
+		Example: `  transcript serve
+  transcript serve --addr 127.0.0.1:9000 -t meeting --jobs 2
+  curl -H "Authorization: Bearer $TOKEN" -F file=@meeting.ogg -F template=meeting http://127.0.0.1:8080/v1/transcriptions
+  curl -H "Authorization: Bearer $TOKEN" -F file=@lecture.mp3 -F async=true http://127.0.0.1:8080/v1/transcriptions`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, auto, err := parseParallelFlag(cmd, parallel)
+			if err != nil {
+				return err
+			}
+			opts, err := parseTranscribeOptions("", "", tmpl, diarize, n, language, outputLang, provider, userTemplatesDir(env))
+			if err != nil {
+				return err
+			}
+			// No --var here: a template with placeholders cannot be filled.
+			if opts.template, err = fillTemplate(env.Stderr, opts.template, nil, ""); err != nil {
+				return err
+			}
+			opts.auto = auto
+			opts.model = model
+			if backend != "" {
+				if opts.backend, err = ParseBackend(backend); err != nil {
+					return err
+				}
+			}
+			limit, err := format.ParseSize(maxUpload)
+			if err != nil || limit == 0 {
+				return fmt.Errorf("invalid --max-upload %q (e.g., 500MB)", maxUpload)
+			}
+			return runServe(cmd, env, serveOptions{addr: addr, file: opts, jobs: jobs, maxUpload: limit, token: env.Getenv(serveTokenEnv)})
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", defaultServeAddr, "Address to listen on (host:port)")
+	cmd.Flags().StringVarP(&tmpl, "template", "t", "", "Restructure template: brainstorm, meeting, lecture, notes, podcast, chapters, digest, or a user template")
+	cmd.Flags().BoolVar(&diarize, "diarize", false, "Enable speaker identification")
+	cmd.Flags().StringVarP(&parallel, "parallel", "p", defaultParallel, "Max concurrent API requests per upload (1-10, or auto; default: sized from the chunks)")
+	cmd.Flags().StringVarP(&language, "language", "l", "", "Audio language (ISO 639-1 code, e.g., en, fr, pt-BR)")
+	cmd.Flags().StringVarP(&outputLang, "translate", "T", "", "Translate output to language (ISO 639-1 code, requires --template)")
+	cmd.Flags().StringVar(&provider, "provider", ProviderDeepSeek, "LLM provider for restructuring: deepseek, openai, anthropic, ollama")
+	cmd.Flags().StringVar(&model, "restructure-model", "", "Model of the restructure provider (default: config or provider default)")
+	cmd.Flags().StringVar(&backend, "transcriber", "", transcriberFlagHelp)
+	cmd.Flags().StringVar(&backend, "stt-provider", "", sttProviderFlagHelp)
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", 1, "Max uploads transcribed concurrently")
+	cmd.Flags().StringVar(&maxUpload, "max-upload", defaultMaxUpload, "Max size of an uploaded file (e.g., 500MB)")
+
+	return cmd
+}
+
+// runServe serves the HTTP API until the command context is canceled.
+func runServe(cmd *cobra.Command, env *Env, opts serveOptions) error {
+	ctx := cmd.Context()
+
+	// === VALIDATION (fail-fast) ===
+
+	cfg, err := env.ConfigLoader.Load()
+	if err != nil {
+		warnf(env.Stderr, warnConfigLoad, "failed to load config: %v", err)
+	}
+	opts.file.backend, err = resolveBackend(opts.file.backend, cfg)
+	if err != nil {
+		return err
+	}
+	if err := validateTranscribeRequirements(env, opts.file, cfg); err != nil {
+		return err
+	}
+
+	// === SETUP ===
+
+	if opts.token == "" {
+		opts.token = newServeToken()
+	}
+	ffmpegPath, err := resolveFFmpegOnce(ctx, env)
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", opts.addr)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", opts.addr, err)
+	}
+
+	// Transcriptions in progress are canceled when the server stops.
+	queueCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s := newServer(queueCtx, env, opts, cfg, ffmpegPath)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.queue.Run(queueCtx, opts.jobs)
+	}()
+
+	// === SERVE ===
+
+	httpServer := &http.Server{Handler: s.handler(), ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(ln)
+	}()
+	s.logf("Listening on http://%s (Ctrl+C to stop)\n", ln.Addr())
+	s.logf("Token: %s (header \"Authorization: Bearer <token>\")\n", opts.token)
+
+	select {
+	case <-ctx.Done():
+	case err = <-serveErr:
+	}
+	cancel()
+	wg.Wait()
+	shutdownCtx, stop := context.WithTimeout(context.WithoutCancel(ctx), serveShutdownTimeout)
+	defer stop()
+	_ = httpServer.Shutdown(shutdownCtx)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	s.logf("Server stopped\n")
+	return nil
+}
+
+// server transcribes the files uploaded to the HTTP API.
+type server struct {
+	ctx    context.Context // Canceled when the server stops
+	env    *Env
+	opts   serveOptions
+	cfg    config.Config
+	ffmpeg string
+	queue  *jobs.Queue
+
+	mu      sync.Mutex           // Serializes writes to env.Stderr across jobs, guards results.
+	results map[string]*serveJob // Jobs by ID, until serveJobRetention after they finished
+}
+
+// serveJob is an upload submitted to the queue.
+type serveJob struct {
+	name   string        // Uploaded file name
+	format OutputFormat  // Format of the transcript
+	done   chan struct{} // Closed once the job returned
+
+	// Set before done is closed, guarded by server.mu.
+	transcript string
+	err        error
+	finished   time.Time
+}
+
+// serveJobResponse is the JSON body describing a job.
+type serveJobResponse struct {
+	ID         string      `json:"id"`
+	Status     jobs.Status `json:"status"`
+	File       string      `json:"file"`
+	Format     string      `json:"format"`
+	Transcript string      `json:"transcript,omitempty"` // Once done
+	Error      string      `json:"error,omitempty"`      // Once failed
+	Code       string      `json:"code,omitempty"`       // Error code, see 'transcript explain'
+	Submitted  time.Time   `json:"submitted"`
+	Started    *time.Time  `json:"started,omitempty"`
+	Finished   *time.Time  `json:"finished,omitempty"`
+}
+
+// serveErrorResponse is the JSON body of a rejected request.
+type serveErrorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code,omitempty"` // Error code, see 'transcript explain'
+}
+
+// newServer creates the server of the HTTP API. ctx is canceled when it stops.
+func newServer(ctx context.Context, env *Env, opts serveOptions, cfg config.Config, ffmpegPath string) *server {
+	return &server{
+		ctx:     ctx,
+		env:     env,
+		opts:    opts,
+		cfg:     cfg,
+		ffmpeg:  ffmpegPath,
+		queue:   jobs.New(serveQueueCapacity, jobs.WithNow(env.Now), jobs.WithRetention(serveJobRetention)),
+		results: make(map[string]*serveJob),
+	}
+}
+
+// handler returns the routes of the HTTP API.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/transcriptions", s.handleTranscription)
+	mux.HandleFunc("GET /v1/jobs/{id}", s.handleJob)
+	return s.authorize(mux)
+}
+
+// authorize serves the requests of next from this machine's clients only.
+// Any web page can post a form to the loopback address, and DNS rebinding
+// lets it read the answers: requests from another origin, or naming another
+// host, are refused, and every request must carry the bearer token.
+func (s *server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowedHost(r.Host) {
+			writeServeError(w, http.StatusForbidden, fmt.Errorf("host %q not allowed: use the listen address or localhost", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || u.Host != r.Host {
+				writeServeError(w, http.StatusForbidden, fmt.Errorf("cross-origin request from %q not allowed", origin))
+				return
+			}
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeServeError(w, http.StatusUnauthorized, errors.New("missing or invalid token (header \"Authorization: Bearer <token>\")"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether the Host header names this server: an IP
+// address, localhost, or the host of --addr. Other names may resolve to the
+// loopback address through DNS rebinding.
+func (s *server) allowedHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport // No port
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	if net.ParseIP(host) != nil || strings.EqualFold(host, "localhost") {
+		return true
+	}
+	listen, _, err := net.SplitHostPort(s.opts.addr)
+	return err == nil && listen != "" && strings.EqualFold(host, listen)
+}
+
+// newServeToken returns a random bearer token.
+func newServeToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) // crypto/rand.Read never fails
+	return hex.EncodeToString(b)
+}
+
+// handleTranscription queues an uploaded file. Answers once it is transcribed,
+// or at once with the job to poll when the form field async is true.
+func (s *server) handleTranscription(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, s.opts.maxUpload)
+	if err := r.ParseMultipartForm(serveFormMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeServeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("upload exceeds %s (--max-upload)", format.Size(s.opts.maxUpload)))
+			return
+		}
+		writeServeError(w, http.StatusBadRequest, fmt.Errorf("invalid multipart form: %w", err))
+		return
+	}
+	defer func() { _ = r.MultipartForm.RemoveAll() }()
+
+	async, err := formBool(r, "async")
+	if err != nil {
+		writeServeError(w, http.StatusBadRequest, err)
+		return
+	}
+	opts, err := s.requestOptions(r)
+	if err != nil {
+		writeServeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	job, dir, err := s.saveUpload(r, &opts)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrUnsupportedFormat) {
+			status = http.StatusUnsupportedMediaType
+		}
+		writeServeError(w, status, err)
+		return
+	}
+
+	id, err := s.queue.Submit(func(ctx context.Context) error {
+		return s.transcribe(ctx, job, opts, dir)
+	})
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		if errors.Is(err, jobs.ErrQueueFull) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(serveRetryAfter.Seconds())))
+		}
+		writeServeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	s.mu.Lock()
+	s.evict()
+	s.results[id] = job
+	s.mu.Unlock()
+	s.logf("Queued %s: job %s\n", job.name, id)
+
+	if async {
+		w.Header().Set("Location", "/v1/jobs/"+id)
+		writeServeJSON(w, http.StatusAccepted, s.response(id, job))
+		return
+	}
+
+	select {
+	case <-job.done:
+	case <-r.Context().Done():
+		return // Client gone: the job keeps running, its result can be polled
+	case <-s.ctx.Done():
+	}
+	resp := s.response(id, job)
+	status := http.StatusOK
+	if resp.Status == jobs.StatusFailed {
+		status = http.StatusInternalServerError
+	}
+	writeServeJSON(w, status, resp)
+}
+
+// handleJob reports the status of a job, with its transcript once done.
+func (s *server) handleJob(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	job, ok := s.results[id]
+	if ok && s.expired(job) {
+		delete(s.results, id)
+		ok = false
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeServeError(w, http.StatusNotFound, fmt.Errorf("unknown job %q", id))
+		return
+	}
+	writeServeJSON(w, http.StatusOK, s.response(id, job))
+}
+
+// requestOptions returns the pipeline options of an upload: the flags,
+// overridden by the form fields template, language, translate and diarize.
+func (s *server) requestOptions(r *http.Request) (transcribeOptions, error) {
+	opts := s.opts.file
+	var err error
+	if v := r.FormValue("template"); v != "" {
+		// Clients select templates by name: they cannot read files of the server.
+		if filepath.Base(v) != v || strings.HasSuffix(v, template.FileExt) {
+			return opts, fmt.Errorf("template %q: select templates by name: %w", v, template.ErrUnknown)
+		}
+		if opts.template, err = template.Resolve(v, userTemplatesDir(s.env)); err != nil {
+			return opts, err
+		}
+		if opts.template, err = fillTemplate(io.Discard, opts.template, nil, ""); err != nil {
+			return opts, err
+		}
+	}
+	if v := r.FormValue("language"); v != "" {
+		if opts.language, err = lang.Parse(v); err != nil {
+			return opts, err
+		}
+	}
+	if v := r.FormValue("translate"); v != "" {
+		if opts.outputLang, err = lang.Parse(v); err != nil {
+			return opts, err
+		}
+	}
+	if r.FormValue("diarize") != "" {
+		if opts.diarize, err = formBool(r, "diarize"); err != nil {
+			return opts, err
+		}
+	}
+	return opts, validateTranscribeRequirements(s.env, opts, s.cfg)
+}
+
+// saveUpload copies the uploaded file into a new temporary directory, and
+// sets the input and output of opts in it.
+func (s *server) saveUpload(r *http.Request, opts *transcribeOptions) (*serveJob, string, error) {
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, "", fmt.Errorf(`missing audio file (form field "file"): %w`, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	if !supportedFormats[ext] {
+		return nil, "", fmt.Errorf("unsupported format %q (supported: %s): %w",
+			ext, supportedFormatsList(), ErrUnsupportedFormat)
+	}
+
+	dir, err := os.MkdirTemp("", "transcript-serve-*")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	opts.inputPath = filepath.Join(dir, "audio"+ext)
+	opts.output = filepath.Join(dir, "transcript"+opts.format.Extension())
+
+	// #nosec G304 -- file created in our temp directory
+	f, err := os.OpenFile(opts.inputPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err == nil {
+		_, err = io.Copy(f, file)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, "", fmt.Errorf("failed to save upload: %w", err)
+	}
+
+	job := &serveJob{name: filepath.Base(header.Filename), format: opts.format, done: make(chan struct{})}
+	return job, dir, nil
+}
+
+// transcribe runs the pipeline on an upload saved in dir, and keeps the
+// transcript in job. dir is removed once done.
+func (s *server) transcribe(ctx context.Context, job *serveJob, opts transcribeOptions, dir string) error {
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	stderr := &linePrefixWriter{mu: &s.mu, w: s.env.Stderr, prefix: "[" + job.name + "] "}
+	fileEnv := *s.env
+	fileEnv.Stderr = stderr
+	fileEnv.FFmpegResolver = resolvedFFmpeg(s.ffmpeg)
+
+	// runTranscribe takes its context from the command.
+	fileCmd := &cobra.Command{}
+	fileCmd.SetContext(ctx)
+	fileCmd.SetOut(io.Discard)
+	err := runTranscribe(fileCmd, &fileEnv, opts)
+	var content []byte
+	if err == nil {
+		if content, err = os.ReadFile(opts.output); err != nil {
+			err = fmt.Errorf("failed to read transcript: %w", err)
+		}
+	}
+	stderr.Flush()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.transcript, job.err, job.finished = string(content), err, s.env.Now()
+	close(job.done)
+	if err != nil {
+		fmt.Fprintf(s.env.Stderr, "Failed: %s: %v\n", job.name, err)
+		return err
+	}
+	fmt.Fprintf(s.env.Stderr, "Done: %s\n", job.name)
+	return nil
+}
+
+// response describes the job id.
+func (s *server) response(id string, job *serveJob) serveJobResponse {
+	snapshot, _ := s.queue.Job(id)
+	resp := serveJobResponse{
+		ID:        id,
+		Status:    snapshot.Status,
+		File:      job.name,
+		Format:    job.format.OrDefault().String(),
+		Submitted: snapshot.Submitted,
+	}
+	if !snapshot.Started.IsZero() {
+		resp.Started = &snapshot.Started
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err, finished := snapshot.Err, snapshot.Finished
+	select {
+	case <-job.done:
+		// The queue records the outcome once the job returned: until then,
+		// the job's own outcome is reported.
+		err, finished = job.err, job.finished
+		resp.Status = jobs.StatusDone
+		resp.Transcript = job.transcript
+	default:
+	}
+	if err != nil {
+		resp.Status = jobs.StatusFailed
+		resp.Error = err.Error()
+		if info, ok := LookupError(err); ok {
+			resp.Code = info.Code
+		}
+	}
+	if !finished.IsZero() {
+		resp.Finished = &finished
+	}
+	return resp
+}
+
+// evict removes the jobs finished longer than serveJobRetention ago, with
+// their transcript. s.mu must be held.
+func (s *server) evict() {
+	for id, job := range s.results {
+		if s.expired(job) {
+			delete(s.results, id)
+		}
+	}
+}
+
+// expired reports whether job finished longer than serveJobRetention ago.
+// s.mu must be held.
+func (s *server) expired(job *serveJob) bool {
+	return !job.finished.IsZero() && s.env.Now().Sub(job.finished) >= serveJobRetention
+}
+
+// logf writes a line to env.Stderr without interleaving with job output.
+func (s *server) logf(format string, args ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.env.Stderr, format, args...)
+}
+
+// formBool parses the boolean form field name (true, false, 1, 0...).
+// A missing field is false.
+func formBool(r *http.Request, name string) (bool, error) {
+	v := r.FormValue(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q (use true or false)", name, v)
+	}
+	return b, nil
+}
+
+// writeServeJSON writes v as the JSON body of a response with status.
+func writeServeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v) // The client may be gone: nothing to do
+}
+
+// writeServeError writes err as the JSON body of a response with status.
+func writeServeError(w http.ResponseWriter, status int, err error) {
+	resp := serveErrorResponse{Error: err.Error()}
+	if info, ok := LookupError(err); ok {
+		resp.Code = info.Code
+	}
+	writeServeJSON(w, status, resp)
+}
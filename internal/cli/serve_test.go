@@ -0,0 +1,551 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/jobs"
+	"github.com/alnah/go-transcript/internal/template"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// serveTestOptions returns the options of a server transcribing raw transcripts.
+func serveTestOptions(t *testing.T) serveOptions {
+	t.Helper()
+	return serveOptions{
+		file:      mustParseTranscribeOptions(t, "", "", "", false, 5, "", "", "deepseek"),
+		jobs:      1,
+		maxUpload: 1 << 20,
+		token:     serveTestToken,
+	}
+}
+
+// serveTestToken is the bearer token of the test servers.
+const serveTestToken = "test-token"
+
+// startTestServer serves the HTTP API of env until the test ends.
+func startTestServer(t *testing.T, env *Env, opts serveOptions) *httptest.Server {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := newServer(ctx, env, opts, config.Config{}, "ffmpeg")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.queue.Run(ctx, opts.jobs)
+	}()
+	ts := httptest.NewServer(s.handler())
+	t.Cleanup(func() {
+		cancel() // Releases requests waiting for a job
+		ts.Close()
+		<-done
+	})
+	return ts
+}
+
+// upload posts a file named name with the form fields to the server.
+// Returns the response status and its decoded JSON body.
+func upload(t *testing.T, url, name string, fields map[string]string) (int, map[string]any) {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for k, v := range fields {
+		if err := form.WriteField(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if name != "" {
+		part, err := form.CreateFormFile("file", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := part.Write([]byte("audio")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url+"/v1/transcriptions", &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+serveTestToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, decodeBody(t, resp)
+}
+
+// getJob fetches the status of the job id.
+func getJob(t *testing.T, url, id string) (int, map[string]any) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url+"/v1/jobs/"+id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+serveTestToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, decodeBody(t, resp)
+}
+
+func decodeBody(t *testing.T, resp *http.Response) map[string]any {
+	t.Helper()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var v map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		t.Fatalf("invalid JSON body: %v", err)
+	}
+	return v
+}
+
+// chunkText returns the transcript of each chunk: its file name.
+func chunkText(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+	return "text of " + filepath.Base(audioPath), nil
+}
+
+func TestServe_WaitsForTranscript(t *testing.T) {
+	t.Parallel()
+
+	env := checkpointTestEnv(t, &syncBuffer{}, chunkText)
+	ts := startTestServer(t, env, serveTestOptions(t))
+
+	status, body := upload(t, ts.URL, "meeting.ogg", nil)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body %v)", status, body)
+	}
+	if body["status"] != string(jobs.StatusDone) || body["file"] != "meeting.ogg" || body["format"] != "md" {
+		t.Errorf("body = %v, want a done job for meeting.ogg", body)
+	}
+	transcript, _ := body["transcript"].(string)
+	if !strings.Contains(transcript, "text of chunk_0.ogg") || !strings.Contains(transcript, "text of chunk_1.ogg") {
+		t.Errorf("transcript = %q, want both chunks", transcript)
+	}
+
+	// The result stays available
+	id, _ := body["id"].(string)
+	if status, job := getJob(t, ts.URL, id); status != http.StatusOK || job["transcript"] != transcript {
+		t.Errorf("GET job = %d %v, want the same transcript", status, job)
+	}
+}
+
+func TestServe_Async(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		<-release
+		return "text", nil
+	})
+	ts := startTestServer(t, env, serveTestOptions(t))
+
+	status, body := upload(t, ts.URL, "lecture.mp3", map[string]string{"async": "true"})
+	if status != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202 (body %v)", status, body)
+	}
+	id, _ := body["id"].(string)
+	if id == "" || body["transcript"] != nil {
+		t.Fatalf("body = %v, want a job ID without transcript", body)
+	}
+	if _, job := getJob(t, ts.URL, id); job["status"] == string(jobs.StatusDone) {
+		t.Errorf("job = %v, want not done before the transcription returns", job)
+	}
+
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, job := getJob(t, ts.URL, id)
+		if job["status"] == string(jobs.StatusDone) {
+			if job["transcript"] == nil || job["finished"] == nil {
+				t.Errorf("job = %v, want the transcript and finish time", job)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job = %v, want done", job)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServe_TemplateField(t *testing.T) {
+	t.Parallel()
+
+	env := checkpointTestEnv(t, &syncBuffer{}, chunkText)
+	mockMR := &mockMapReduceRestructurer{}
+	env.RestructurerFactory = &mockRestructurerFactory{mockMapReducer: mockMR}
+	ts := startTestServer(t, env, serveTestOptions(t))
+
+	status, body := upload(t, ts.URL, "meeting.ogg", map[string]string{"template": "meeting"})
+	if status != http.StatusOK || body["transcript"] != "restructured text" {
+		t.Fatalf("upload = %d %v, want the restructured transcript", status, body)
+	}
+	calls := mockMR.RestructureCalls()
+	if len(calls) != 1 || calls[0].TemplateName != template.MeetingName {
+		t.Errorf("Restructure() calls = %+v, want one with the meeting template", calls)
+	}
+}
+
+func TestServe_TranscriptionFailed(t *testing.T) {
+	t.Parallel()
+
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "", errors.New("network down")
+	})
+	ts := startTestServer(t, env, serveTestOptions(t))
+
+	status, body := upload(t, ts.URL, "meeting.ogg", nil)
+	if status != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", status)
+	}
+	if body["status"] != string(jobs.StatusFailed) || !strings.Contains(body["error"].(string), "network down") {
+		t.Errorf("body = %v, want the failure", body)
+	}
+}
+
+func TestServe_RejectedRequests(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		file       string
+		fields     map[string]string
+		maxUpload  int64
+		wantStatus int
+		wantMsg    string
+		wantCode   string
+	}{
+		{name: "missing file", wantStatus: http.StatusBadRequest, wantMsg: `form field "file"`},
+		{name: "unsupported format", file: "notes.txt", wantStatus: http.StatusUnsupportedMediaType, wantMsg: "unsupported format", wantCode: "TR-0402"},
+		{name: "unknown template", file: "a.ogg", fields: map[string]string{"template": "minutes"}, wantStatus: http.StatusBadRequest, wantMsg: "unknown template"},
+		{name: "template path", file: "a.ogg", fields: map[string]string{"template": "../secret.md"}, wantStatus: http.StatusBadRequest, wantMsg: "select templates by name"},
+		{name: "translate without template", file: "a.ogg", fields: map[string]string{"translate": "fr"}, wantStatus: http.StatusBadRequest, wantMsg: "--translate requires --template"},
+		{name: "invalid language", file: "a.ogg", fields: map[string]string{"language": "english"}, wantStatus: http.StatusBadRequest},
+		{name: "invalid async", file: "a.ogg", fields: map[string]string{"async": "later"}, wantStatus: http.StatusBadRequest, wantMsg: "invalid async"},
+		{name: "too large", file: "a.ogg", maxUpload: 10, wantStatus: http.StatusRequestEntityTooLarge, wantMsg: "--max-upload"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := serveTestOptions(t)
+			if tt.maxUpload > 0 {
+				opts.maxUpload = tt.maxUpload
+			}
+			ts := startTestServer(t, checkpointTestEnv(t, &syncBuffer{}, chunkText), opts)
+
+			status, body := upload(t, ts.URL, tt.file, tt.fields)
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %v)", status, tt.wantStatus, body)
+			}
+			msg, _ := body["error"].(string)
+			if msg == "" || !strings.Contains(msg, tt.wantMsg) {
+				t.Errorf("error = %q, want containing %q", msg, tt.wantMsg)
+			}
+			if tt.wantCode != "" && body["code"] != tt.wantCode {
+				t.Errorf("code = %v, want %s", body["code"], tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestServe_RejectsForeignRequests(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		host       string
+		origin     string
+		auth       string
+		wantStatus int
+	}{
+		{name: "missing token", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", auth: "Bearer other", wantStatus: http.StatusUnauthorized},
+		{name: "cross-origin page", origin: "https://evil.example", auth: "Bearer " + serveTestToken, wantStatus: http.StatusForbidden},
+		{name: "null origin", origin: "null", auth: "Bearer " + serveTestToken, wantStatus: http.StatusForbidden},
+		{name: "rebinding host", host: "evil.example:8080", auth: "Bearer " + serveTestToken, wantStatus: http.StatusForbidden},
+		{name: "rebinding host with same-origin page", host: "evil.example:8080", origin: "http://evil.example:8080", auth: "Bearer " + serveTestToken, wantStatus: http.StatusForbidden},
+		{name: "localhost", host: "localhost:8080", auth: "Bearer " + serveTestToken, wantStatus: http.StatusNotFound},
+		{name: "same origin", origin: "same", auth: "Bearer " + serveTestToken, wantStatus: http.StatusNotFound},
+	}
+
+	ts := startTestServer(t, checkpointTestEnv(t, &syncBuffer{}, chunkText), serveTestOptions(t))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(http.MethodGet, ts.URL+"/v1/jobs/0123456789abcdef", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			switch tt.origin {
+			case "":
+			case "same":
+				req.Header.Set("Origin", ts.URL)
+			default:
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			// 404: the request reached the API, for a job that does not exist.
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestServe_RejectsCrossOriginUpload(t *testing.T) {
+	t.Parallel()
+
+	var called bool
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		called = true
+		return "text", nil
+	})
+	ts := startTestServer(t, env, serveTestOptions(t))
+
+	// A form posted by a web page: a simple request, without token.
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "meeting.ogg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = part.Write([]byte("audio"))
+	_ = form.Close()
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/v1/transcriptions", &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Origin", "https://evil.example")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", resp.StatusCode)
+	}
+	if called {
+		t.Error("upload transcribed, want refused before the pipeline")
+	}
+}
+
+func TestRunServe_PrintsToken(t *testing.T) {
+	t.Parallel()
+
+	stderr := &syncBuffer{}
+	env := checkpointTestEnv(t, stderr, chunkText)
+	opts := serveTestOptions(t)
+	opts.addr = "127.0.0.1:0"
+	opts.token = ""
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := &cobra.Command{}
+	cmd.SetContext(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- runServe(cmd, env, opts)
+	}()
+
+	token := regexp.MustCompile(`Token: ([0-9a-f]{32})\b`)
+	deadline := time.Now().Add(5 * time.Second)
+	for !token.MatchString(stderr.String()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("stderr = %q, want a generated token", stderr.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("runServe() unexpected error: %v", err)
+	}
+}
+
+func TestServe_TemplateWithPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "standup.md"), []byte("Standup of {{project}}."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	env := checkpointTestEnv(t, &syncBuffer{}, chunkText)
+	env.ConfigLoader = &mockConfigLoader{LoadFunc: func() (config.Config, error) {
+		return config.Config{TemplatesDir: dir}, nil
+	}}
+	ts := startTestServer(t, env, serveTestOptions(t))
+
+	// Uploads have no --var: the placeholder cannot be filled.
+	status, body := upload(t, ts.URL, "meeting.ogg", map[string]string{"template": "standup"})
+	if status != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400 (body %v)", status, body)
+	}
+	if msg, _ := body["error"].(string); !strings.Contains(msg, "project") {
+		t.Errorf("error = %q, want the placeholder without value", msg)
+	}
+}
+
+func TestServe_UnknownJob(t *testing.T) {
+	t.Parallel()
+
+	ts := startTestServer(t, checkpointTestEnv(t, &syncBuffer{}, chunkText), serveTestOptions(t))
+	if status, body := getJob(t, ts.URL, "0123456789abcdef"); status != http.StatusNotFound || body["error"] == nil {
+		t.Errorf("GET unknown job = %d %v, want 404 with an error", status, body)
+	}
+}
+
+func TestServe_EvictsFinishedJobs(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu  sync.Mutex
+		now = time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	)
+	env := checkpointTestEnv(t, &syncBuffer{}, chunkText)
+	env.Now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	ts := startTestServer(t, env, serveTestOptions(t))
+
+	status, body := upload(t, ts.URL, "meeting.ogg", nil)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body %v)", status, body)
+	}
+	id, _ := body["id"].(string)
+	if status, _ := getJob(t, ts.URL, id); status != http.StatusOK {
+		t.Fatalf("GET job = %d, want 200 within the retention", status)
+	}
+
+	mu.Lock()
+	now = now.Add(serveJobRetention)
+	mu.Unlock()
+	if status, body := getJob(t, ts.URL, id); status != http.StatusNotFound {
+		t.Errorf("GET job = %d %v, want 404 once evicted", status, body)
+	}
+}
+
+func TestRunServe_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	stderr := &syncBuffer{}
+	env := checkpointTestEnv(t, stderr, chunkText)
+	opts := serveTestOptions(t)
+	opts.addr = "127.0.0.1:0"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd := &cobra.Command{}
+	cmd.SetContext(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- runServe(cmd, env, opts)
+	}()
+
+	listening := regexp.MustCompile(`Listening on (http://\S+)`)
+	var url string
+	deadline := time.Now().Add(5 * time.Second)
+	for url == "" {
+		if m := listening.FindStringSubmatch(stderr.String()); m != nil {
+			url = m[1]
+		} else if time.Now().After(deadline) {
+			t.Fatalf("stderr = %q, want the listen address", stderr.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if status, body := upload(t, url, "meeting.ogg", nil); status != http.StatusOK {
+		t.Errorf("upload = %d %v, want 200", status, body)
+	}
+	if !strings.Contains(stderr.String(), "[meeting.ogg] ") || !strings.Contains(stderr.String(), "Done: meeting.ogg") {
+		t.Errorf("stderr = %q, want the job progress prefixed with the file name", stderr.String())
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("runServe() unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "Server stopped") {
+		t.Errorf("stderr = %q, want the server stopped", stderr.String())
+	}
+}
+
+func TestRunServe_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		addr    string
+		getenv  func(string) string
+		wantErr error
+		wantMsg string
+	}{
+		{name: "no API key", addr: "127.0.0.1:0", getenv: staticEnv(nil), wantErr: ErrAPIKeyMissing},
+		{name: "invalid address", addr: "127.0.0.1:99999", wantMsg: "cannot listen"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := checkpointTestEnv(t, &syncBuffer{}, chunkText)
+			if tt.getenv != nil {
+				env.Getenv = tt.getenv
+			}
+			opts := serveTestOptions(t)
+			opts.addr = tt.addr
+			cmd := &cobra.Command{}
+			cmd.SetContext(context.Background())
+
+			err := runServe(cmd, env, opts)
+			if err == nil {
+				t.Fatal("runServe() error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("runServe() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantMsg != "" && !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("runServe() error = %v, want containing %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/schemas"
+)
+
+// Session directory layout (--session-dir).
+// Every artifact of a run lives in one directory, so a session can be
+// inspected, bundled or deleted as a whole.
+const (
+	sessionMetadataFile = "session.json"  // sessionMetadata, updated when the run ends
+	sessionLogFile      = "session.log"   // Copy of the progress output
+	sessionAudioFile    = "audio.ogg"     // Recording (live only)
+	sessionChunksFile   = "chunks.json"   // Chunk boundaries used for transcription
+	sessionRawFile      = "raw.md"        // Raw transcript (with --template)
+	sessionOutputFile   = "transcript.md" // Final output (extension follows --format, see sessionOutput)
+)
+
+// Session status values.
+const (
+	sessionRunning  = "running"
+	sessionComplete = "complete"
+	sessionFailed   = "failed"
+)
+
+// sessionMetadata describes a run. It is written when the session starts
+// and rewritten with the final status when the run ends.
+// Its format is described by the metadata schema (see schemas.Metadata).
+type sessionMetadata struct {
+	SchemaVersion int    `json:"schema_version"`
+	Command       string `json:"command"`
+	Input         string `json:"input,omitempty"` // Absolute input path (transcribe only)
+	Template      string `json:"template,omitempty"`
+	Provider      string `json:"provider,omitempty"`
+	Language      string `json:"language,omitempty"`
+	Translate     string `json:"translate,omitempty"`
+	Diarize       bool   `json:"diarize,omitempty"`
+	Format        string `json:"format,omitempty"`
+	Tag           string `json:"tag,omitempty"`
+	IntroOutro    string `json:"intro_outro,omitempty"`
+	Chunks        int    `json:"chunks,omitempty"`
+	// FallbackChunks are the indexes of the chunks too large for the OpenAI
+	// API, transcribed with the local backend instead.
+	FallbackChunks []int      `json:"fallback_chunks,omitempty"`
+	Status         string     `json:"status"`
+	Error          string     `json:"error,omitempty"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+}
+
+// sessionChunk is one entry of the chunks manifest.
+type sessionChunk struct {
+	Index int     `json:"index"`
+	Start float64 `json:"start"` // Seconds from the start of the audio
+	End   float64 `json:"end"`
+}
+
+// session is a run writing its artifacts into a session directory.
+// A nil *session is valid and does nothing, so callers need not check
+// whether --session-dir was given.
+type session struct {
+	dir  string
+	meta sessionMetadata
+	log  *os.File
+	now  func() time.Time
+
+	mu sync.Mutex // Serializes recordFallback, called from parallel chunks
+}
+
+// newSession creates a session directory in parent, named after the run and
+// its start time (e.g. "meeting_20260125_143052"), and writes its metadata.
+func newSession(parent, name string, meta sessionMetadata, now func() time.Time) (*session, error) {
+	if err := os.MkdirAll(parent, 0o750); err != nil {
+		return nil, fmt.Errorf("cannot create session directory: %w", err)
+	}
+
+	started := now()
+	base := fmt.Sprintf("%s_%s", name, started.Format("20060102_150405"))
+	dir := filepath.Join(parent, base)
+	// Runs started in the same second (batch mode) get a numeric suffix.
+	for n := 2; ; n++ {
+		err := os.Mkdir(dir, 0o750)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("cannot create session directory: %w", err)
+		}
+		dir = filepath.Join(parent, fmt.Sprintf("%s_%d", base, n))
+	}
+
+	meta.Status = sessionRunning
+	meta.StartedAt = started
+	return startSession(dir, meta, now)
+}
+
+// openSession reopens an unfinished session directory to resume it.
+func openSession(dir string, now func() time.Time) (*session, error) {
+	meta, err := readSessionMetadata(dir)
+	if err != nil {
+		return nil, err
+	}
+	meta.Status = sessionRunning
+	meta.Error = ""
+	meta.FinishedAt = nil
+	return startSession(dir, meta, now)
+}
+
+// startSession opens the session log and writes the metadata.
+func startSession(dir string, meta sessionMetadata, now func() time.Time) (*session, error) {
+	// #nosec G302 G304 -- log file inside the session directory
+	log, err := os.OpenFile(filepath.Join(dir, sessionLogFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create session log: %w", err)
+	}
+
+	// Resumed sessions written by an older version are upgraded
+	meta.SchemaVersion = schemas.MetadataVersion
+	s := &session{dir: dir, meta: meta, log: log, now: now}
+	if err := s.saveMetadata(); err != nil {
+		_ = log.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// readSessionMetadata reads the metadata of the session in dir.
+func readSessionMetadata(dir string) (sessionMetadata, error) {
+	var meta sessionMetadata
+	data, err := os.ReadFile(filepath.Join(dir, sessionMetadataFile)) // #nosec G304 -- path inside a session directory
+	if err != nil {
+		return meta, err
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, fmt.Errorf("invalid session metadata in %s: %w", dir, err)
+	}
+	return meta, nil
+}
+
+// findResumableSession returns the most recent unfinished session in parent
+// for command and input, or "" if there is none.
+// Unreadable entries are ignored: they cannot be resumed anyway.
+func findResumableSession(parent, command, input string) string {
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return ""
+	}
+
+	var found string
+	var latest time.Time
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(parent, e.Name())
+		meta, err := readSessionMetadata(dir)
+		if err != nil || meta.Command != command || meta.Input != input || meta.Status == sessionComplete {
+			continue
+		}
+		if found == "" || meta.StartedAt.After(latest) {
+			found, latest = dir, meta.StartedAt
+		}
+	}
+	return found
+}
+
+// sessionOutput returns the name of the final output in a session written in
+// format f: transcript.md, transcript.srt, ...
+func sessionOutput(f OutputFormat) string {
+	return strings.TrimSuffix(sessionOutputFile, filepath.Ext(sessionOutputFile)) + f.Extension()
+}
+
+// path returns the path of an artifact inside the session directory.
+func (s *session) path(name string) string {
+	return filepath.Join(s.dir, name)
+}
+
+// stderr returns a writer copying w to the session log.
+func (s *session) stderr(w io.Writer) io.Writer {
+	if s == nil {
+		return w
+	}
+	return io.MultiWriter(w, s.log)
+}
+
+// writeChunks writes the chunks manifest and records the chunk count.
+func (s *session) writeChunks(chunks []audio.Chunk) error {
+	if s == nil {
+		return nil
+	}
+	manifest := make([]sessionChunk, len(chunks))
+	for i, c := range chunks {
+		manifest[i] = sessionChunk{Index: c.Index, Start: c.StartTime.Seconds(), End: c.EndTime.Seconds()}
+	}
+	if err := s.writeJSON(sessionChunksFile, manifest); err != nil {
+		return err
+	}
+	s.meta.Chunks = len(chunks)
+	return s.saveMetadata()
+}
+
+// recordFallback records that chunk index was transcribed by the local
+// fallback (see withLocalFallback), and saves the metadata.
+func (s *session) recordFallback(index int) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if slices.Contains(s.meta.FallbackChunks, index) {
+		return nil // Recorded by the run resumed
+	}
+	s.meta.FallbackChunks = append(s.meta.FallbackChunks, index)
+	slices.Sort(s.meta.FallbackChunks)
+	return s.saveMetadata()
+}
+
+// writeFile writes an artifact, replacing any previous version.
+func (s *session) writeFile(name, content string) error {
+	if s == nil {
+		return nil
+	}
+	path := s.path(name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0644); err != nil { // #nosec G306 -- same permissions as outputs
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeJSON writes v as an indented JSON artifact.
+func (s *session) writeJSON(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode %s: %w", name, err)
+	}
+	return s.writeFile(name, string(data)+"\n")
+}
+
+// saveMetadata writes the session metadata.
+func (s *session) saveMetadata() error {
+	return s.writeJSON(sessionMetadataFile, s.meta)
+}
+
+// finish records the outcome of the run and closes the session log.
+// Errors are reported to w: the run's own error matters more.
+func (s *session) finish(w io.Writer, runErr error) {
+	if s == nil {
+		return
+	}
+	finished := s.now()
+	s.meta.FinishedAt = &finished
+	s.meta.Status = sessionComplete
+	if runErr != nil {
+		s.meta.Status = sessionFailed
+		s.meta.Error = runErr.Error()
+	}
+	if err := s.saveMetadata(); err != nil {
+		warnf(w, warnFileNotSaved, "failed to save session metadata: %v", err)
+	}
+	_ = s.log.Close()
+}
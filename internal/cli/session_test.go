@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/schemas"
+)
+
+func TestNewSession(t *testing.T) {
+	t.Parallel()
+
+	parent := filepath.Join(t.TempDir(), "sessions")
+	now := fixedTime(time.Date(2026, 1, 25, 14, 30, 52, 0, time.UTC))
+
+	first, err := newSession(parent, "meeting", sessionMetadata{Command: "transcribe"}, now)
+	if err != nil {
+		t.Fatalf("newSession() unexpected error: %v", err)
+	}
+	defer first.finish(&syncBuffer{}, nil)
+	second, err := newSession(parent, "meeting", sessionMetadata{Command: "transcribe"}, now)
+	if err != nil {
+		t.Fatalf("newSession() unexpected error: %v", err)
+	}
+	defer second.finish(&syncBuffer{}, nil)
+
+	if want := filepath.Join(parent, "meeting_20260125_143052"); first.dir != want {
+		t.Errorf("first session dir = %q, want %q", first.dir, want)
+	}
+	if want := filepath.Join(parent, "meeting_20260125_143052_2"); second.dir != want {
+		t.Errorf("second session dir = %q, want %q (same second)", second.dir, want)
+	}
+
+	meta, err := readSessionMetadata(first.dir)
+	if err != nil {
+		t.Fatalf("readSessionMetadata() unexpected error: %v", err)
+	}
+	if meta.Status != sessionRunning || meta.Command != "transcribe" || !meta.StartedAt.Equal(now()) {
+		t.Errorf("metadata = %+v, want running transcribe session started now", meta)
+	}
+	if meta.SchemaVersion != schemas.MetadataVersion {
+		t.Errorf("metadata schema_version = %d, want %d", meta.SchemaVersion, schemas.MetadataVersion)
+	}
+	if _, err := os.Stat(first.path(sessionLogFile)); err != nil {
+		t.Errorf("session log not created: %v", err)
+	}
+}
+
+func TestSession_Finish(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		runErr     error
+		wantStatus string
+		wantError  string
+	}{
+		{"success", nil, sessionComplete, ""},
+		{"failure", errors.New("rate limit"), sessionFailed, "rate limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sess, err := newSession(t.TempDir(), "live", sessionMetadata{Command: "live"}, fixedTime(time.Now()))
+			if err != nil {
+				t.Fatalf("newSession() unexpected error: %v", err)
+			}
+			stderr := &syncBuffer{}
+			sess.stderr(stderr).Write([]byte("Transcribing...\n"))
+			sess.finish(stderr, tt.runErr)
+
+			meta, err := readSessionMetadata(sess.dir)
+			if err != nil {
+				t.Fatalf("readSessionMetadata() unexpected error: %v", err)
+			}
+			if meta.Status != tt.wantStatus || meta.Error != tt.wantError || meta.FinishedAt == nil {
+				t.Errorf("metadata = %+v, want status %q, error %q and finish time", meta, tt.wantStatus, tt.wantError)
+			}
+			log, err := os.ReadFile(sess.path(sessionLogFile))
+			if err != nil {
+				t.Fatalf("failed to read session log: %v", err)
+			}
+			if string(log) != "Transcribing...\n" || stderr.String() != "Transcribing...\n" {
+				t.Errorf("log = %q, stderr = %q, want progress copied to both", log, stderr.String())
+			}
+		})
+	}
+}
+
+func TestSession_WriteChunks(t *testing.T) {
+	t.Parallel()
+
+	sess, err := newSession(t.TempDir(), "talk", sessionMetadata{Command: "transcribe"}, fixedTime(time.Now()))
+	if err != nil {
+		t.Fatalf("newSession() unexpected error: %v", err)
+	}
+	defer sess.finish(&syncBuffer{}, nil)
+
+	chunks := []audio.Chunk{
+		{Path: "/tmp/chunk_0.ogg", Index: 0, StartTime: 0, EndTime: 90 * time.Second},
+		{Path: "/tmp/chunk_1.ogg", Index: 1, StartTime: 90 * time.Second, EndTime: 150 * time.Second},
+	}
+	if err := sess.writeChunks(chunks); err != nil {
+		t.Fatalf("writeChunks() unexpected error: %v", err)
+	}
+
+	manifest, err := os.ReadFile(sess.path(sessionChunksFile))
+	if err != nil {
+		t.Fatalf("failed to read manifest: %v", err)
+	}
+	if !strings.Contains(string(manifest), `"start": 90`) || !strings.Contains(string(manifest), `"end": 150`) {
+		t.Errorf("manifest = %s, want chunk boundaries in seconds", manifest)
+	}
+	if strings.Contains(string(manifest), "/tmp/") {
+		t.Errorf("manifest = %s, must not reference temporary chunk files", manifest)
+	}
+	meta, _ := readSessionMetadata(sess.dir)
+	if meta.Chunks != 2 {
+		t.Errorf("metadata chunks = %d, want 2", meta.Chunks)
+	}
+}
+
+func TestSession_RecordFallback(t *testing.T) {
+	t.Parallel()
+
+	sess, err := newSession(t.TempDir(), "talk", sessionMetadata{Command: "transcribe"}, fixedTime(time.Now()))
+	if err != nil {
+		t.Fatalf("newSession() unexpected error: %v", err)
+	}
+	defer sess.finish(&syncBuffer{}, nil)
+
+	for _, index := range []int{3, 1, 3} {
+		if err := sess.recordFallback(index); err != nil {
+			t.Fatalf("recordFallback(%d) unexpected error: %v", index, err)
+		}
+	}
+	meta, _ := readSessionMetadata(sess.dir)
+	if !slices.Equal(meta.FallbackChunks, []int{1, 3}) {
+		t.Errorf("metadata fallback chunks = %v, want [1 3]", meta.FallbackChunks)
+	}
+}
+
+func TestSession_Nil(t *testing.T) {
+	t.Parallel()
+
+	var sess *session
+	stderr := &syncBuffer{}
+
+	if w := sess.stderr(stderr); w != stderr {
+		t.Error("nil session stderr() should return the writer unchanged")
+	}
+	if err := sess.writeChunks([]audio.Chunk{{Index: 0}}); err != nil {
+		t.Errorf("nil session writeChunks() error = %v", err)
+	}
+	if err := sess.writeFile(sessionRawFile, "raw"); err != nil {
+		t.Errorf("nil session writeFile() error = %v", err)
+	}
+	if err := sess.recordFallback(0); err != nil {
+		t.Errorf("nil session recordFallback() error = %v", err)
+	}
+	sess.finish(stderr, nil)
+}
+
+func TestFindResumableSession(t *testing.T) {
+	t.Parallel()
+
+	parent := t.TempDir()
+	base := time.Date(2026, 1, 25, 14, 0, 0, 0, time.UTC)
+
+	create := func(name string, meta sessionMetadata, runErr error, finish bool) string {
+		t.Helper()
+		sess, err := newSession(parent, name, meta, fixedTime(meta.StartedAt))
+		if err != nil {
+			t.Fatalf("newSession() unexpected error: %v", err)
+		}
+		if finish {
+			sess.finish(&syncBuffer{}, runErr)
+		} else {
+			_ = sess.log.Close()
+		}
+		return sess.dir
+	}
+
+	meta := func(input string, started time.Time) sessionMetadata {
+		return sessionMetadata{Command: "transcribe", Input: input, StartedAt: started}
+	}
+
+	create("a", meta("/audio/a.ogg", base), nil, true)                                     // complete
+	older := create("a", meta("/audio/a.ogg", base.Add(time.Hour)), errors.New("x"), true) // failed
+	newer := create("a", meta("/audio/a.ogg", base.Add(2*time.Hour)), nil, false)          // interrupted
+	create("b", meta("/audio/b.ogg", base.Add(3*time.Hour)), errors.New("x"), true)        // other input
+
+	if got := findResumableSession(parent, "transcribe", "/audio/a.ogg"); got != newer {
+		t.Errorf("findResumableSession() = %q, want most recent unfinished %q (not %q)", got, newer, older)
+	}
+	if got := findResumableSession(parent, "transcribe", "/audio/c.ogg"); got != "" {
+		t.Errorf("findResumableSession() = %q, want none for unknown input", got)
+	}
+	if got := findResumableSession(parent, "live", "/audio/a.ogg"); got != "" {
+		t.Errorf("findResumableSession() = %q, want none for another command", got)
+	}
+	if got := findResumableSession(filepath.Join(parent, "missing"), "transcribe", "/audio/a.ogg"); got != "" {
+		t.Errorf("findResumableSession() = %q, want none for missing directory", got)
+	}
+}
+
+func TestOpenSession(t *testing.T) {
+	t.Parallel()
+
+	sess, err := newSession(t.TempDir(), "a", sessionMetadata{Command: "transcribe"}, fixedTime(time.Now()))
+	if err != nil {
+		t.Fatalf("newSession() unexpected error: %v", err)
+	}
+	sess.stderr(&syncBuffer{}).Write([]byte("first run\n"))
+	sess.finish(&syncBuffer{}, errors.New("interrupted"))
+
+	reopened, err := openSession(sess.dir, fixedTime(time.Now()))
+	if err != nil {
+		t.Fatalf("openSession() unexpected error: %v", err)
+	}
+	reopened.stderr(&syncBuffer{}).Write([]byte("second run\n"))
+	reopened.finish(&syncBuffer{}, nil)
+
+	meta, _ := readSessionMetadata(sess.dir)
+	if meta.Status != sessionComplete || meta.Error != "" {
+		t.Errorf("metadata = %+v, want complete without error", meta)
+	}
+	log, _ := os.ReadFile(sess.path(sessionLogFile))
+	if string(log) != "first run\nsecond run\n" {
+		t.Errorf("log = %q, want both runs appended", log)
+	}
+}
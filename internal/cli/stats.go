@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/format"
+	"github.com/alnah/go-transcript/internal/schemas"
+	"github.com/alnah/go-transcript/internal/speakerstats"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// --stats values: where the speaker statistics of a diarized transcript go.
+const (
+	// StatsMarkdown appends a "## Speaker statistics" section to the output.
+	StatsMarkdown = "md"
+
+	// StatsJSON writes the statistics next to the output, in <output>.stats.json.
+	StatsJSON = "json"
+)
+
+// statsFlagHelp describes the --stats flag of the transcribe and live commands.
+const statsFlagHelp = "With --diarize, report talk time, turns, longest monologue, interruptions and words per minute per speaker: md (appended section) or json (<output>.stats.json)"
+
+// parseStats parses the --stats value. Empty means no statistics.
+// Returns ErrInvalidStats if the value is not recognized.
+func parseStats(value string) (string, error) {
+	switch value {
+	case "", StatsMarkdown, StatsJSON:
+		return value, nil
+	default:
+		return "", fmt.Errorf("unknown --stats value %q (use %s or %s): %w", value, StatsMarkdown, StatsJSON, ErrInvalidStats)
+	}
+}
+
+// validateStats checks that --stats has speakers to measure (diarized is
+// set), and somewhere to go: a section appended to md and txt outputs, or a
+// file next to the output, which stdout has not.
+func validateStats(stats string, diarized bool, f OutputFormat, stdout bool) error {
+	switch {
+	case stats == "":
+		return nil
+	case !diarized:
+		return fmt.Errorf("--stats requires --diarize (statistics are per speaker)")
+	case stats == StatsMarkdown && f.IsSubtitle():
+		return fmt.Errorf("--stats=%s cannot be combined with --format %s (use --stats=%s)", StatsMarkdown, f, StatsJSON)
+	case stats == StatsJSON && stdout:
+		return fmt.Errorf("--stats=%s cannot be combined with --stdout (use --stats=%s)", StatsJSON, StatsMarkdown)
+	}
+	return nil
+}
+
+// statsPath returns the statistics file of output: "notes.md" -> "notes.stats.json".
+func statsPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".stats.json"
+}
+
+// speakerStats returns the statistics of the speakers of results, transcribed
+// from chunks with timestamps, or nil without --stats.
+func speakerStats(stats string, chunks []audio.Chunk, results []string) (*speakerstats.Stats, error) {
+	if stats == "" {
+		return nil, nil
+	}
+	cues, err := mergeCues(chunks, results)
+	if err != nil {
+		return nil, err
+	}
+	s := speakerstats.Compute(cues)
+	return &s, nil
+}
+
+// untimedResults returns timestamped results (see transcribe.Options.Timestamps)
+// as if transcribed without timestamps: one "[Speaker] text" line per segment
+// of a speaker, as in diarized results, and the other segments joined in a
+// paragraph. --stats and --unclear time transcripts rendered without timestamps.
+func untimedResults(results []string) ([]string, error) {
+	untimed := make([]string, len(results))
+	for i, result := range results {
+		segments, err := transcribe.ParseSegments(result)
+		if err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		for j, s := range segments {
+			switch {
+			case j == 0:
+			case s.Speaker != "" || segments[j-1].Speaker != "":
+				b.WriteString("\n")
+			default:
+				b.WriteString(" ")
+			}
+			if s.Speaker != "" {
+				fmt.Fprintf(&b, "[%s] ", s.Speaker)
+			}
+			b.WriteString(s.Text)
+		}
+		untimed[i] = b.String()
+	}
+	return untimed, nil
+}
+
+// appendStats returns content with the statistics section of s appended
+// (--stats md). Other values leave content as it is.
+func appendStats(content, stats string, s *speakerstats.Stats) string {
+	if stats != StatsMarkdown || s == nil {
+		return content
+	}
+	return appendSection(content, s.Markdown())
+}
+
+// statsDocument is the JSON of --stats json, following the stats schema
+// (see schemas.Stats). Durations are in seconds.
+type statsDocument struct {
+	SchemaVersion int                 `json:"schema_version"`
+	Duration      float64             `json:"duration"`
+	Words         int                 `json:"words"`
+	Speakers      []speakerStatsEntry `json:"speakers"`
+}
+
+// speakerStatsEntry is a speaker of statsDocument.
+type speakerStatsEntry struct {
+	Speaker          string  `json:"speaker"`
+	Words            int     `json:"words"`
+	Duration         float64 `json:"duration"`
+	Turns            int     `json:"turns"`
+	Share            float64 `json:"share"`
+	LongestMonologue float64 `json:"longest_monologue"`
+	Interruptions    int     `json:"interruptions"`
+	WordsPerMinute   float64 `json:"words_per_minute"`
+}
+
+// newStatsDocument returns s as a statsDocument.
+func newStatsDocument(s speakerstats.Stats) statsDocument {
+	doc := statsDocument{
+		SchemaVersion: schemas.StatsVersion,
+		Duration:      s.Duration.Seconds(),
+		Words:         s.Words(),
+		Speakers:      make([]speakerStatsEntry, len(s.Speakers)),
+	}
+	for i, sp := range s.Speakers {
+		doc.Speakers[i] = speakerStatsEntry{
+			Speaker:          sp.Name,
+			Words:            sp.Words,
+			Duration:         sp.TalkTime.Seconds(),
+			Turns:            sp.Turns,
+			Share:            sp.Share,
+			LongestMonologue: sp.LongestMonologue.Seconds(),
+			Interruptions:    sp.Interruptions,
+			WordsPerMinute:   sp.WordsPerMinute(),
+		}
+	}
+	return doc
+}
+
+// saveStats writes the statistics of the speakers of output to statsPath
+// (--stats json), replacing an earlier run's. A failure is only a warning:
+// the output is already written.
+func saveStats(env *Env, stats, output string, s *speakerstats.Stats) {
+	if stats != StatsJSON || s == nil {
+		return
+	}
+	path := statsPath(output)
+	data, err := json.MarshalIndent(newStatsDocument(*s), "", "  ")
+	if err == nil {
+		err = os.WriteFile(path, append(data, '\n'), 0644) // #nosec G306 -- statistics next to the user's output
+	}
+	if err != nil {
+		warnf(env.Stderr, warnFileNotSaved, "failed to save speaker statistics: %v", err)
+		return
+	}
+	fmt.Fprintf(env.Stderr, "Speaker statistics saved: %s\n", path)
+}
+
+// statsCues returns the statistics of the speakers of cues, or nil without --stats.
+func statsCues(stats string, cues []format.Cue) *speakerstats.Stats {
+	if stats == "" {
+		return nil
+	}
+	s := speakerstats.Compute(cues)
+	return &s
+}
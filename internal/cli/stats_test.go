@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestParseStats(t *testing.T) {
+	t.Parallel()
+
+	for _, value := range []string{"", StatsMarkdown, StatsJSON} {
+		if got, err := parseStats(value); err != nil || got != value {
+			t.Errorf("parseStats(%q) = %q, %v, want it unchanged", value, got, err)
+		}
+	}
+	if _, err := parseStats("csv"); !errors.Is(err, ErrInvalidStats) {
+		t.Errorf("parseStats(csv) error = %v, want ErrInvalidStats", err)
+	}
+}
+
+func TestValidateStats(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		stats    string
+		diarized bool
+		format   OutputFormat
+		stdout   bool
+		wantErr  string
+	}{
+		{name: "none", format: SRTFormat, stdout: true},
+		{name: "markdown", stats: StatsMarkdown, diarized: true},
+		{name: "text to stdout", stats: StatsMarkdown, diarized: true, format: TextFormat, stdout: true},
+		{name: "json of subtitles", stats: StatsJSON, diarized: true, format: VTTFormat},
+		{name: "not diarized", stats: StatsMarkdown, wantErr: "--diarize"},
+		{name: "markdown in subtitles", stats: StatsMarkdown, diarized: true, format: SRTFormat, wantErr: "--stats=json"},
+		{name: "json to stdout", stats: StatsJSON, diarized: true, stdout: true, wantErr: "--stdout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateStats(tt.stats, tt.diarized, tt.format, tt.stdout)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validateStats() unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validateStats() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUntimedResults(t *testing.T) {
+	t.Parallel()
+
+	result, err := transcribe.EncodeSegments([]transcribe.TimedSegment{
+		{Start: 0, End: 1e9, Speaker: "Alice", Text: "Hello."},
+		{Start: 1e9, End: 2e9, Speaker: "Bob", Text: "Hi."},
+		{Start: 2e9, End: 3e9, Text: "[transcription failed]"},
+		{Start: 3e9, End: 4e9, Text: "Then silence."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := untimedResults([]string{result})
+	if err != nil {
+		t.Fatalf("untimedResults() unexpected error: %v", err)
+	}
+	want := []string{"[Alice] Hello.\n[Bob] Hi.\n[transcription failed] Then silence."}
+	if !slices.Equal(got, want) {
+		t.Errorf("untimedResults() = %q, want %q", got, want)
+	}
+
+	if _, err := untimedResults([]string{"not segments"}); err == nil {
+		t.Error("untimedResults() = nil error, want the results rejected")
+	}
+}
+
+func TestRunTranscribe_Stats(t *testing.T) {
+	t.Parallel()
+
+	transcribeDiarized := func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		if !opts.Timestamps || !opts.Diarize {
+			return "", errors.New("diarized timestamps not requested")
+		}
+		return `[{"start":0,"end":40,"speaker":"Alice","text":"Welcome everyone to the weekly review."},` +
+			`{"start":38,"end":50,"speaker":"Bob","text":"Thanks Alice."}]`, nil
+	}
+
+	t.Run("markdown", func(t *testing.T) {
+		t.Parallel()
+
+		inputPath := createTestAudioFile(t, "audio.ogg")
+		output := filepath.Join(t.TempDir(), "out.md")
+		env := checkpointTestEnv(t, &syncBuffer{}, transcribeDiarized)
+
+		opts := mustParseTranscribeOptions(t, inputPath, output, "", true, 5, "", "", "deepseek")
+		opts.stats = StatsMarkdown
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+			t.Fatalf("RunTranscribe() unexpected error: %v", err)
+		}
+
+		content, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatalf("failed to read output: %v", err)
+		}
+		for _, want := range []string{
+			"[Alice] Welcome everyone to the weekly review.",
+			"\n\n## Speaker statistics\n\n",
+			"| Alice | 00:40 | 77% | 1 | 00:40 | 0 | 9 |",
+			"| Bob | 00:12 | 23% | 1 | 00:12 | 1 | 10 |",
+		} {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("output = %q, want %q", content, want)
+			}
+		}
+		if strings.Contains(string(content), `"start"`) {
+			t.Errorf("output = %q, want the segments rendered as text", content)
+		}
+		if _, err := os.Stat(statsPath(output)); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("stats file error = %v, want none written", err)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		inputPath := createTestAudioFile(t, "audio.ogg")
+		output := filepath.Join(t.TempDir(), "out.srt")
+		stderr := &syncBuffer{}
+		env := checkpointTestEnv(t, stderr, transcribeDiarized)
+
+		opts := mustParseTranscribeOptions(t, inputPath, output, "", true, 5, "", "", "deepseek")
+		opts.format = SRTFormat
+		opts.stats = StatsJSON
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+			t.Fatalf("RunTranscribe() unexpected error: %v", err)
+		}
+
+		path := filepath.Join(filepath.Dir(output), "out.stats.json")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read statistics: %v", err)
+		}
+		var doc statsDocument
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("statistics are not JSON: %v", err)
+		}
+		if doc.SchemaVersion != 1 || doc.Words != 8 || len(doc.Speakers) != 2 {
+			t.Fatalf("statistics = %+v, want version 1, 8 words of 2 speakers", doc)
+		}
+		if bob := doc.Speakers[1]; bob.Speaker != "Bob" || bob.Duration != 12 || bob.Interruptions != 1 || bob.LongestMonologue != 12 {
+			t.Errorf("statistics of Bob = %+v, want 12s, an interruption and a 12s monologue", bob)
+		}
+		if !strings.Contains(stderr.String(), "Speaker statistics saved: "+path) {
+			t.Errorf("stderr = %q, want the statistics file", stderr.String())
+		}
+		content, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatalf("failed to read output: %v", err)
+		}
+		if strings.Contains(string(content), "Speaker statistics") {
+			t.Errorf("output = %q, want subtitles only", content)
+		}
+	})
+
+	t.Run("not diarized", func(t *testing.T) {
+		t.Parallel()
+
+		inputPath := createTestAudioFile(t, "audio.ogg")
+		env := checkpointTestEnv(t, &syncBuffer{}, nil)
+
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "out.md"), "", false, 5, "", "", "deepseek")
+		opts.stats = StatsMarkdown
+		err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts)
+		if err == nil || !strings.Contains(err.Error(), "--diarize") {
+			t.Errorf("RunTranscribe() error = %v, want --stats to require --diarize", err)
+		}
+	})
+}
+
+func TestTranscribeCmd_InvalidStats(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+
+	env, _ := testEnv()
+	cmd := TranscribeCmd(env)
+
+	cmd.SetArgs([]string{inputPath, "--diarize", "--stats=csv"})
+	err := cmd.Execute()
+
+	if !errors.Is(err, ErrInvalidStats) {
+		t.Errorf("cmd.Execute() error = %v, want ErrInvalidStats", err)
+	}
+}
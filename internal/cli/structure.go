@@ -2,24 +2,52 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/alnah/go-transcript/internal/config"
 	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/notes"
+	"github.com/alnah/go-transcript/internal/progress"
+	"github.com/alnah/go-transcript/internal/restructure"
 	"github.com/alnah/go-transcript/internal/template"
 )
 
+// stdinInput is the input argument reading the transcript from stdin.
+const stdinInput = "-"
+
 // structureOptions holds validated options for the structure command.
 type structureOptions struct {
-	inputPath  string
+	inputPath  string // Transcript file, or stdinInput
 	output     string
 	template   template.Name
+	templates  []template.Name // All the templates of --template when several are given, template first; nil otherwise
 	outputLang lang.Language
 	provider   Provider
+	model      string // Restructure model (--restructure-model); empty means configured or provider default
+	costReport string // Append the usage and cost of the run to this file (--cost-report)
+	glossary   string // File of terms put in the restructure prompts (--glossary); empty means configured
+
+	selfConsistency int      // Restructurings merged into the output (--self-consistency); <= 1 disables it
+	maxCost         float64  // Max estimated cost of self-consistency, in US dollars (--max-cost)
+	showPrompt      bool     // Print the messages that would be sent instead of restructuring (--show-prompt)
+	chunkTokens     int      // Size of the parts of long transcripts (--restructure-chunk-tokens); 0 means configured
+	overlap         int      // Tokens repeated between parts (--restructure-overlap); 0 means configured
+	reduceLevels    int      // Max levels merging the parts (--max-reduce-levels); 0 means the default
+	stream          bool     // Write the output as it is generated (--stream-restructure)
+	fallback        Provider // Restructure with this provider if --provider fails (--fallback-provider); zero means none
+	obsidianVault   string   // Write the output into this Obsidian vault (--obsidian-vault); empty means configured
+	stdout          bool     // Print the output to stdout instead of a file (--stdout, -o -)
+	actions         string   // Action items written next to the output (--actions): json, csv; empty means none
+
+	outputMode outputMode // Replace (--force) or append to (--append) an existing output file
 }
 
 // StructureCmd creates the structure command (restructure an existing transcript).
@@ -27,63 +55,306 @@ type structureOptions struct {
 func StructureCmd(env *Env) *cobra.Command {
 	var (
 		output     string
-		tmpl       string
+		tmpls      []string
 		outputLang string
 		provider   string
+		model      string
+		costReport string
+		glossary   string
+
+		selfConsistency int
+		maxCost         float64
+		progressFmt     string
+		showPrompt      bool
+		chunkTokens     int
+		overlap         int
+		reduceLevels    int
+		stream          bool
+		fallback        string
+		vars            []string
+		varsFile        string
+		summaryLevel    string
+		actions         string
+		force           bool
+		appendOutput    bool
+		obsidianVault   string
+		stdout          bool
 	)
 
 	cmd := &cobra.Command{
-		Use:   "structure <transcript-file>",
-		Short: "Restructure an existing transcript",
+		Use:     "structure <transcript-file>... | -",
+		Aliases: []string{"s"},
+		Short:   "Restructure an existing transcript",
 		Long: `Restructure an existing transcript file using a template.
 
 This command takes a raw transcript (typically generated without --template)
 and restructures it into organized markdown using an LLM.
 
-Restructuring uses DeepSeek by default, or OpenAI with --provider openai.`,
-		Example: `  transcript structure meeting_raw.md -t meeting -o meeting.md
+With "-", the transcript is read from stdin and the result written to stdout
+(or to --output). --stdout (or -o -) prints the result of a transcript file
+to stdout too. With several files or glob patterns ("notes/*.txt", quoted
+so that the shell does not expand them), each file is restructured into its
+own output; --output then names a directory. A failing file does not stop
+the others.
+
+With several templates (--template meeting,digest or a repeated --template),
+one transcript is restructured into one output per template, named
+<input>_<template>.md, --output naming their directory. The parts of a long
+transcript are processed once for all templates, each template making a
+single call from their notes, instead of once per template; templates reading
+timestamps (chapters) are restructured on their own.
+
+Restructuring uses DeepSeek by default, or OpenAI with --provider openai.
+With --provider ollama, it runs offline on a local Ollama server (see the
+ollama-url and ollama-model config keys). --fallback-provider restructures
+again with another provider, and its default model, when --provider fails
+with an exhausted quota or a rejected key.
+
+--var and --vars-file fill the placeholders of a user template, like
+{{attendees}} (see 'transcript transcribe --help'). --summary-level short
+turns any template into a 5-line executive summary, detailed into a breakdown
+of every point.
+
+--actions also extracts the action items of the transcript, with the same
+provider, to <output>.tasks.json, or <output>.tasks.csv with --actions=csv, to
+import into a task manager (see 'transcript transcribe --help'). With several
+templates, the file is named after the input, next to the outputs.
+
+--restructure-model selects the provider model. Long transcripts are split
+into parts sized from the model's context window, or of
+--restructure-chunk-tokens, cut between paragraphs or sentences. With
+--restructure-overlap N, each part starts with up to N tokens of the end of
+the previous one, so that nothing said across the cut is lost. When the notes
+of all parts are too long to be merged in one call, groups of parts are
+condensed first, up to --max-reduce-levels levels. With --stream-restructure,
+the notes are written to the output and stderr as they are generated; if
+restructuring fails or is interrupted, the output keeps them, marked as partial.
+
+With --self-consistency N, the transcript is restructured N times and the results
+are merged, within --max-cost (see 'transcript transcribe --help').
+
+An existing output file is an error; --force replaces it and --append adds
+the notes at the end of it, after a separator with the date and time (see
+'transcript transcribe --help'). The output-name and front-matter settings
+apply to the notes too, speakers coming from the labels of the transcript,
+and so does --obsidian-vault, which also writes stdin to the vault.
+
+With --show-prompt, nothing is sent: the system and user messages of each
+call restructuring would make are printed to stdout, with the middle of the
+transcript elided, to review a template or what leaves the machine. No API
+key is needed.
+
+With --progress json, progress is written to stderr as JSON lines (see
+'transcript transcribe --help').`,
+		Example: `  transcript structure meeting.raw.md -t meeting -o meeting.md
   transcript structure notes.md -t brainstorm
   transcript structure lecture.md -t lecture -T fr  # Translate to French
-  transcript structure raw.md -t notes --provider openai`,
-		Args: cobra.ExactArgs(1),
+  transcript structure raw.md -t notes --provider openai
+  transcript structure raw.md -t notes --provider openai --restructure-model gpt-4.1
+  transcript structure raw.md -t ./standup.md        # User template file
+  transcript structure raw.md -t meeting --self-consistency 3
+  transcript structure raw.md -t meeting,digest,brainstorm -o notes/
+  transcript structure raw.md -t meeting --summary-level short  # Executive summary
+  transcript structure raw.md -t meeting --actions=csv  # Task list for a task manager
+  transcript structure standup.md -t notes -o journal.md --append
+  transcript structure raw.md -t ./standup.md --show-prompt  # Review the prompts
+  cat notes.txt | transcript structure -t meeting -
+  transcript structure raw.md -t notes --stdout | pandoc -o notes.docx
+  transcript structure "notes/*.txt" -t notes -o structured/`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Parse all inputs at the CLI boundary
-			opts, err := parseStructureOptions(args[0], output, tmpl, outputLang, provider)
+			inputs, err := expandStructureInputs(args)
+			if err != nil {
+				return err
+			}
+			var first string
+			if len(tmpls) > 0 {
+				first = tmpls[0]
+			}
+			opts, err := parseStructureOptions(inputs[0], output, first, outputLang, provider, userTemplatesDir(env))
 			if err != nil {
 				return err
 			}
-			return runStructure(cmd, env, opts)
+			if len(tmpls) > 1 {
+				if opts.templates, err = parseStructureTemplates(tmpls, userTemplatesDir(env)); err != nil {
+					return err
+				}
+				if err := validateStructureTemplates(cmd, inputs, toStdout(stdout, output)); err != nil {
+					return err
+				}
+				if opts.templates, err = fillTemplates(env.Stderr, opts.templates, vars, varsFile); err != nil {
+					return err
+				}
+				for i, tmpl := range opts.templates {
+					if opts.templates[i], err = levelTemplate(tmpl, summaryLevel); err != nil {
+						return err
+					}
+				}
+				opts.template = opts.templates[0]
+			} else {
+				if opts.template, err = fillTemplate(env.Stderr, opts.template, vars, varsFile); err != nil {
+					return err
+				}
+				if opts.template, err = levelTemplate(opts.template, summaryLevel); err != nil {
+					return err
+				}
+			}
+			opts.model = model
+			opts.costReport = costReport
+			opts.glossary = config.ExpandPath(glossary)
+			opts.selfConsistency = selfConsistency
+			opts.maxCost = maxCost
+			opts.showPrompt = showPrompt
+			opts.obsidianVault = obsidianVault
+			if opts.stdout = toStdout(stdout, output); opts.stdout {
+				opts.output = ""
+				if err := validateStdout(cmd, "force", "append"); err != nil {
+					return err
+				}
+				if len(inputs) > 1 {
+					return fmt.Errorf("--stdout takes a single transcript")
+				}
+			}
+			opts.chunkTokens = chunkTokens
+			opts.overlap = overlap
+			opts.reduceLevels = reduceLevels
+			opts.stream = stream
+			opts.outputMode = newOutputMode(force, appendOutput)
+			if opts.fallback, err = parseFallbackProvider(fallback); err != nil {
+				return err
+			}
+			if opts.actions, err = parseActions(actions); err != nil {
+				return err
+			}
+			if err := validateFallbackProvider(opts.fallback, opts.provider, opts.template); err != nil {
+				return err
+			}
+			if err := validateSelfConsistency(opts.selfConsistency, opts.template); err != nil {
+				return err
+			}
+			if err := validateStreamRestructure(opts.stream, opts.template, progressFmt); err != nil {
+				return err
+			}
+			if err := validateChunking(opts.chunkTokens, opts.overlap, opts.reduceLevels); err != nil {
+				return err
+			}
+			if showPrompt && len(inputs) > 1 {
+				return fmt.Errorf("--show-prompt takes a single transcript")
+			}
+			return runWithProgress(cmd, env, progressFmt, progress.PhaseRestructuring, func(env *Env) error {
+				if len(inputs) > 1 {
+					return runStructureBatch(cmd, env, inputs, opts)
+				}
+				return runStructure(cmd, env, opts)
+			})
 		},
 	}
 
-	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path (default: <input>_structured.md)")
-	cmd.Flags().StringVarP(&tmpl, "template", "t", "", "Restructure template: brainstorm, meeting, lecture, notes (required)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path, or directory with several inputs or templates (default: <input>_structured.md, stdout for -)")
+	cmd.Flags().StringSliceVarP(&tmpls, "template", "t", nil, "Restructure template: brainstorm, meeting, lecture, notes, podcast, chapters, digest, or a user template; several (comma-separated or repeated) write one output each (required)")
 	cmd.Flags().StringVarP(&outputLang, "translate", "T", "", "Translate output to language (ISO 639-1 code, e.g., en, fr)")
-	cmd.Flags().StringVar(&provider, "provider", ProviderDeepSeek, "LLM provider for restructuring: deepseek, openai")
+	cmd.Flags().StringVar(&provider, "provider", ProviderDeepSeek, "LLM provider for restructuring: deepseek, openai, anthropic, ollama")
+	cmd.Flags().StringVar(&model, "restructure-model", "", "Model of the restructure provider (default: config or provider default)")
+	cmd.Flags().StringVar(&fallback, "fallback-provider", "", fallbackProviderFlagHelp)
+	cmd.Flags().StringArrayVar(&vars, "var", nil, varFlagHelp)
+	cmd.Flags().StringVar(&varsFile, "vars-file", "", varsFileFlagHelp)
+	cmd.Flags().StringVar(&summaryLevel, "summary-level", "", summaryLevelFlagHelp)
+	cmd.Flags().StringVar(&glossary, "glossary", "", glossaryFlagHelp)
+	cmd.Flags().StringVar(&actions, "actions", "", actionsFlagHelp)
+	cmd.Flags().Lookup("actions").NoOptDefVal = ActionsJSON
+	cmd.Flags().IntVar(&selfConsistency, "self-consistency", 0, "Restructure N times and merge the results (2-5)")
+	cmd.Flags().IntVar(&chunkTokens, "restructure-chunk-tokens", 0, chunkTokensFlagHelp)
+	cmd.Flags().IntVar(&overlap, "restructure-overlap", 0, overlapFlagHelp)
+	cmd.Flags().IntVar(&reduceLevels, "max-reduce-levels", 0, reduceLevelsFlagHelp)
+	cmd.Flags().BoolVar(&stream, "stream-restructure", false, streamRestructureFlagHelp)
+	cmd.Flags().BoolVar(&force, "force", false, forceFlagHelp)
+	cmd.Flags().BoolVar(&appendOutput, "append", false, appendFlagHelp)
+	cmd.Flags().Float64Var(&maxCost, "max-cost", defaultMaxCost, "Max estimated restructuring cost of --self-consistency, in US dollars")
+	cmd.Flags().StringVar(&costReport, "cost-report", "", "Append the usage and cost of the run to this file (JSON lines, or CSV with a .csv extension)")
+	cmd.Flags().StringVar(&progressFmt, "progress", ProgressText, "Progress output on stderr: text, or json for JSON lines")
+	cmd.Flags().BoolVar(&showPrompt, "show-prompt", false, "Print the messages that would be sent to the provider, without calling it")
+	cmd.Flags().StringVar(&obsidianVault, "obsidian-vault", "", obsidianVaultFlagHelp)
+	cmd.Flags().BoolVar(&stdout, "stdout", false, stdoutFlagHelp)
 
 	// Template is required for structure command.
 	// Error is ignored: MarkFlagRequired only fails if flag doesn't exist,
 	// which is a programming error caught at development time.
 	_ = cmd.MarkFlagRequired("template")
+	cmd.MarkFlagsMutuallyExclusive("force", "append")
+	cmd.MarkFlagsMutuallyExclusive("output", "obsidian-vault", "stdout")
 
 	return cmd
 }
 
+// expandStructureInputs expands the glob patterns of args into the transcript
+// files to restructure, in command-line order, without duplicates.
+// A pattern matching no file is an error. stdinInput must be the only input.
+func expandStructureInputs(args []string) ([]string, error) {
+	if slices.Contains(args, stdinInput) {
+		if len(args) > 1 {
+			return nil, fmt.Errorf("%q reads stdin and cannot be combined with other inputs", stdinInput)
+		}
+		return args, nil
+	}
+
+	var inputs []string
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		matches := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(arg); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("%w: no file matches %s", ErrFileNotFound, arg)
+			}
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				inputs = append(inputs, m)
+			}
+		}
+	}
+	return inputs, nil
+}
+
 // deriveStructuredOutputPath converts an input path to a structured output path.
 // Example: "meeting.md" -> "meeting_structured.md"
 func deriveStructuredOutputPath(inputPath string) string {
 	ext := filepath.Ext(inputPath)
-	base := strings.TrimSuffix(inputPath, ext)
-	// Remove _raw suffix if present to avoid meeting_raw_structured.md
-	base = strings.TrimSuffix(base, "_raw")
-	return base + "_structured" + ext
+	// Remove the raw suffix to avoid meeting.raw_structured.md
+	return trimRawSuffix(strings.TrimSuffix(inputPath, ext)) + "_structured" + ext
+}
+
+// trimRawSuffix removes the suffix of a raw transcript from a path without
+// extension: ".raw" as written by -r (see rawTranscriptPath), or "_raw" as
+// written by earlier versions.
+func trimRawSuffix(base string) string {
+	if trimmed, ok := strings.CutSuffix(base, ".raw"); ok {
+		return trimmed
+	}
+	return strings.TrimSuffix(base, "_raw")
+}
+
+// structureOutputNamer returns the function naming the default output of a
+// transcript restructured at started (see outputNamer).
+func structureOutputNamer(cfg config.Config, opts structureOptions, started time.Time) (func(base string) string, error) {
+	return outputNamer(cfg, notes.NameFields{
+		Date:     started,
+		Template: opts.template.String(),
+		Language: opts.outputLang.String(),
+	}, ".md", deriveStructuredOutputPath)
 }
 
 // parseStructureOptions validates and parses CLI inputs into structureOptions.
-// All parsing happens at the CLI boundary.
-func parseStructureOptions(inputPath, output, tmpl, outputLang, provider string) (structureOptions, error) {
+// All parsing happens at the CLI boundary. templatesDir holds the user
+// templates selectable by name; empty allows only built-in names and paths.
+func parseStructureOptions(inputPath, output, tmpl, outputLang, provider, templatesDir string) (structureOptions, error) {
 	// Parse template (required for structure command)
-	parsedTemplate, err := template.ParseName(tmpl)
+	parsedTemplate, err := template.Resolve(tmpl, templatesDir)
 	if err != nil {
 		return structureOptions{}, err
 	}
@@ -112,78 +383,418 @@ func parseStructureOptions(inputPath, output, tmpl, outputLang, provider string)
 	}, nil
 }
 
+// parseStructureTemplates resolves the templates of a repeated or
+// comma-separated --template (see parseStructureOptions). A template given
+// twice is an error: both would be written to the same output.
+func parseStructureTemplates(values []string, templatesDir string) ([]template.Name, error) {
+	tmpls := make([]template.Name, 0, len(values))
+	for _, v := range values {
+		tmpl, err := template.Resolve(v, templatesDir)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(tmpls, tmpl) {
+			return nil, fmt.Errorf("template %s is given twice", tmpl)
+		}
+		tmpls = append(tmpls, tmpl)
+	}
+	return tmpls, nil
+}
+
+// validateStructureTemplates checks the inputs and flags of a transcript
+// restructured with several templates: one transcript, written to one file
+// per template, each restructured once and without streaming.
+func validateStructureTemplates(cmd *cobra.Command, inputs []string, stdout bool) error {
+	if len(inputs) > 1 {
+		return fmt.Errorf("several templates take a single transcript")
+	}
+	if stdout {
+		return fmt.Errorf("several templates write one file each and cannot be printed to stdout")
+	}
+	for _, name := range []string{"stream-restructure", "self-consistency", "show-prompt"} {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s takes a single template", name)
+		}
+	}
+	return nil
+}
+
+// deriveTemplateOutputPath converts an input path to the output path of tmpl,
+// when restructured with several templates.
+// Example: "meeting.raw.md" -> "meeting_digest.md"
+func deriveTemplateOutputPath(inputPath string, tmpl template.Name) string {
+	ext := filepath.Ext(inputPath)
+	base := trimRawSuffix(strings.TrimSuffix(inputPath, ext))
+	return base + "_" + tmpl.String() + ext
+}
+
+// templateOutputPaths returns the output of each template of opts, in
+// outputDir (--output, a directory), or else in the configured output
+// directory. Outputs are named like the default output, with the template's
+// name instead of "_structured" (see deriveTemplateOutputPath), or by the
+// output-name pattern, which must then tell the templates apart. Existing
+// outputs are an error, unless replaced or appended to.
+func templateOutputPaths(cfg config.Config, opts structureOptions, base, outputDir string, started time.Time) ([]string, error) {
+	if outputDir != "" {
+		if strings.EqualFold(filepath.Ext(outputDir), ".md") {
+			return nil, fmt.Errorf("--output must be a directory with several templates, got %s", outputDir)
+		}
+		outputDir = config.ExpandPath(outputDir)
+		if err := config.EnsureOutputDir(outputDir); err != nil {
+			return nil, fmt.Errorf("invalid output directory: %w", err)
+		}
+	}
+
+	outputs := make([]string, len(opts.templates))
+	for i, tmpl := range opts.templates {
+		tmplOpts := opts
+		tmplOpts.template = tmpl
+		outputName, err := structureOutputNamer(cfg, tmplOpts, started)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.OutputName == "" {
+			outputName = func(base string) string { return deriveTemplateOutputPath(base, tmpl) }
+		}
+		dir := outputDir
+		if dir == "" {
+			dir = cfg.OutputDir
+		}
+		outputs[i] = config.EnsureExtension(config.ResolveOutputPath("", dir, outputName(base)), ".md")
+		if j := slices.Index(outputs[:i], outputs[i]); j >= 0 {
+			return nil, fmt.Errorf("templates %s and %s would both be written to %s (add {{template}} to the output-name setting)",
+				opts.templates[j], tmpl, outputs[i])
+		}
+		// Fail before any call rather than after restructuring
+		if _, err := os.Stat(outputs[i]); err == nil && opts.outputMode == outputCreate {
+			return nil, fmt.Errorf("output file already exists: %s: %w", outputs[i], ErrOutputExists)
+		}
+	}
+	return outputs, nil
+}
+
 // runStructure executes the structure command with validated options.
+// With stdinInput as input and no output, the result is written to stdout.
 func runStructure(cmd *cobra.Command, env *Env, opts structureOptions) error {
 	ctx := cmd.Context()
+	fromStdin := opts.inputPath == stdinInput
 
 	// === VALIDATION (fail-fast) ===
 
 	// 1. File exists
-	if _, err := os.Stat(opts.inputPath); err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("file not found: %s", opts.inputPath)
+	if !fromStdin {
+		if _, err := os.Stat(opts.inputPath); err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("file not found: %s", opts.inputPath)
+			}
+			return fmt.Errorf("cannot access file: %w", err)
 		}
-		return fmt.Errorf("cannot access file: %w", err)
 	}
 
 	// 2. Load config for output-dir
 	cfg, err := env.ConfigLoader.Load()
 	if err != nil {
-		fmt.Fprintf(env.Stderr, "Warning: failed to load config: %v\n", err)
+		warnf(env.Stderr, warnConfigLoad, "failed to load config: %v", err)
+	}
+	applyChunking(&cfg, opts.chunkTokens, opts.overlap)
+	if opts.glossary != "" {
+		cfg.Glossary = opts.glossary
+	}
+	glossary, err := loadGlossary(env, cfg)
+	if err != nil {
+		return err
+	}
+
+	// 3. The Obsidian vault, when given, is where the default output goes.
+	// stdin goes to stdout unless --obsidian-vault is given.
+	vault, err := openObsidianVault(obsidianVaultRoot(opts.obsidianVault, opts.output != "" || fromStdin || opts.stdout, cfg), cfg, MarkdownFormat)
+	if err != nil {
+		return err
+	}
+	if vault != nil && opts.output == "" {
+		cfg.OutputDir = vault.Dir()
+	}
+	toStdout := opts.stdout || (fromStdin && opts.output == "" && vault == nil)
+	tmpls := []template.Name{opts.template}
+	if len(opts.templates) > 1 {
+		if toStdout {
+			return fmt.Errorf("several templates write one file each: --output must name their directory")
+		}
+		tmpls = opts.templates
+	}
+	if err := validateActions(opts.actions, opts.template, toStdout); err != nil {
+		return err
 	}
 
-	// 3. Resolve output path (derive default from input basename only)
+	// 4. Resolve output path (derive default from input basename only, or
+	// name it by the output-name pattern when set)
 	// EnsureExtension adds .md only when path has no extension.
 	// Paths with non-.md extensions are preserved and trigger a warning below.
-	defaultOutput := deriveStructuredOutputPath(filepath.Base(opts.inputPath))
-	output := config.ResolveOutputPath(opts.output, cfg.OutputDir, defaultOutput)
-	output = config.EnsureExtension(output, ".md")
-	warnNonMarkdownExtension(env.Stderr, output)
+	started := env.Now()
+	base := filepath.Base(opts.inputPath)
+	if fromStdin {
+		base = "stdin.md"
+	}
+	output := "stdout"
+	var outputs []string // With several templates, one per template
+	switch {
+	case len(tmpls) > 1:
+		if outputs, err = templateOutputPaths(cfg, opts, base, opts.output, started); err != nil {
+			return err
+		}
+		output = strings.Join(outputs, ", ")
+		for _, o := range outputs {
+			warnNonMarkdownExtension(env.Stderr, o)
+		}
+	case !toStdout:
+		outputName, err := structureOutputNamer(cfg, opts, started)
+		if err != nil {
+			return err
+		}
+		output = config.ResolveOutputPath(opts.output, cfg.OutputDir, outputName(base))
+		output = config.EnsureExtension(output, ".md")
+		warnNonMarkdownExtension(env.Stderr, output)
+	}
 
-	// 4. Provider defaulting
+	// 5. Provider defaulting, and the key of the fallback, which would
+	// otherwise only be missed once the provider failed
 	provider := opts.provider.OrDefault()
+	if err := validateFallbackKey(env, opts.fallback, azureConfig(cfg)); err != nil {
+		return err
+	}
 
 	// === READ INPUT ===
 
-	fmt.Fprintf(env.Stderr, "Reading %s...\n", opts.inputPath)
-
-	// #nosec G304 -- inputPath is user-provided, validated above
-	content, err := os.ReadFile(opts.inputPath)
+	input := opts.inputPath
+	var content []byte
+	if fromStdin {
+		input = "stdin"
+		fmt.Fprintln(env.Stderr, "Reading stdin...")
+		content, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		fmt.Fprintf(env.Stderr, "Reading %s...\n", opts.inputPath)
+		// #nosec G304 -- inputPath is user-provided, validated above
+		content, err = os.ReadFile(opts.inputPath)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return fmt.Errorf("failed to read %s: %w", input, err)
 	}
 
 	transcript := string(content)
 	if strings.TrimSpace(transcript) == "" {
-		return fmt.Errorf("input file is empty: %s", opts.inputPath)
+		return fmt.Errorf("input file is empty: %s", input)
+	}
+
+	if opts.showPrompt {
+		split, err := restructure.ParseSplit(cfg.RestructureSplit)
+		if err != nil {
+			return err
+		}
+		ollama := ollamaConfig(cfg)
+		model := providerModel(provider, restructureModel(opts.model, cfg), ollama)
+		calls := restructure.PreviewPrompts(transcript, opts.template, opts.outputLang,
+			partTokens(provider, model, cfg.ContextWindows, cfg.RestructureChunkTokens), split, cfg.RestructureOverlap, glossary)
+		printPromptPreview(cmd.OutOrStdout(), provider, model, calls)
+		return nil
 	}
 
 	// === RESTRUCTURE ===
 
-	fmt.Fprintf(env.Stderr, "Restructuring with template '%s' (provider: %s)...\n", opts.template, provider)
+	progress.PhaseChange(ctx, progress.PhaseRestructuring)
+	if len(tmpls) > 1 {
+		names := make([]string, len(tmpls))
+		for i, tmpl := range tmpls {
+			names[i] = tmpl.String()
+		}
+		fmt.Fprintf(env.Stderr, "Restructuring with templates '%s' (provider: %s)...\n", strings.Join(names, "', '"), provider)
+	} else {
+		fmt.Fprintf(env.Stderr, "Restructuring with template '%s' (provider: %s)...\n", opts.template, provider)
+	}
 
-	result, err := restructureContent(ctx, env, transcript, RestructureOptions{
-		Template:   opts.template,
-		Provider:   provider,
-		OutputLang: opts.outputLang,
-		OnProgress: func(phase string, current, total int) {
-			if phase == "map" {
-				fmt.Fprintf(env.Stderr, "  Processing part %d/%d...\n", current, total)
-			} else {
-				fmt.Fprintln(env.Stderr, "  Merging parts...")
-			}
-		},
+	// With --stream-restructure, the output is written as it is generated:
+	// to the output file and stderr, or to stderr only when writing to stdout
+	var streamed *streamedOutput
+	var stream io.Writer
+	if opts.stream && toStdout {
+		stream = env.Stderr
+	} else if opts.stream {
+		if streamed, err = openStreamedOutput(env, output, opts.outputMode, MarkdownFormat); err != nil {
+			return err
+		}
+		stream = streamed
+	}
+
+	report := newRunReport("structure", input, output)
+	actions := newActionsRun(opts.actions)
+	results, err := restructureTemplates(ctx, env, transcript, tmpls, RestructureOptions{
+		Template:           opts.template,
+		Provider:           provider,
+		OutputLang:         opts.outputLang,
+		OnProgress:         defaultProgressCallback(env.Stderr),
+		PromptTokenWarning: cfg.PromptTokenWarning,
+		Ollama:             ollamaConfig(cfg),
+		Azure:              azureConfig(cfg),
+		Model:              restructureModel(opts.model, cfg),
+		ContextWindows:     cfg.ContextWindows,
+		Split:              cfg.RestructureSplit,
+		ChunkTokens:        cfg.RestructureChunkTokens,
+		Overlap:            cfg.RestructureOverlap,
+		ReduceLevels:       opts.reduceLevels,
+		SelfConsistency:    opts.selfConsistency,
+		MaxCost:            opts.maxCost,
+		Glossary:           glossary,
+		Stream:             stream,
+		FallbackProvider:   opts.fallback,
+		report:             report,
+		actions:            actions,
 	})
 	if err != nil {
+		streamed.abort()
 		return err
 	}
 
 	// === WRITE OUTPUT ===
 
-	if err := writeFileAtomic(output, result); err != nil {
+	for i, result := range results {
+		if outputs != nil {
+			output = outputs[i]
+		}
+		frontMatter := notes.FrontMatter{
+			Date:     started,
+			Language: opts.outputLang.String(),
+			Template: tmpls[i].String(),
+			Speakers: notes.Speakers(transcript),
+		}
+		result = obsidianNote(vault, result, output, opts.outputMode, started, "", &frontMatter)
+		result = addFrontMatter(cfg.FrontMatter || vault != nil, result, output, opts.outputMode, MarkdownFormat, frontMatter)
+		switch {
+		case toStdout:
+			if opts.stream {
+				fmt.Fprintln(env.Stderr)
+			}
+			if _, err := io.WriteString(cmd.OutOrStdout(), result); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+		case streamed != nil:
+			if err := streamed.finish(result); err != nil {
+				return err
+			}
+			fmt.Fprintf(env.Stderr, "Done: %s\n", output)
+		default:
+			if err := writeOutput(env, output, result, opts.outputMode, MarkdownFormat); err != nil {
+				return err
+			}
+			fmt.Fprintf(env.Stderr, "Done: %s\n", output)
+		}
+	}
+	if actions != nil {
+		// With several templates, named after the input, in the directory of the outputs
+		tasksOutput := output
+		if outputs != nil {
+			tasksOutput = filepath.Join(filepath.Dir(outputs[0]), base)
+		}
+		var source string
+		if !fromStdin {
+			source = absPath(opts.inputPath)
+		}
+		saveActions(env, opts.actions, tasksOutput, source, actions)
+	}
+	finishRunReport(env, report, opts.costReport)
+	return nil
+}
+
+// runStructureBatch restructures several transcript files, one after the
+// other, each into its own output. --output names a directory. A failing file
+// does not stop the others; each file's progress is prefixed with its name,
+// and a final report lists failures (see reportBatch).
+func runStructureBatch(cmd *cobra.Command, env *Env, inputs []string, opts structureOptions) error {
+	// === VALIDATION (fail-fast) ===
+
+	for _, input := range inputs {
+		if _, err := os.Stat(input); err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("%w: %s", ErrFileNotFound, input)
+			}
+			return fmt.Errorf("cannot access input: %w", err)
+		}
+	}
+
+	cfg, err := env.ConfigLoader.Load()
+	if err != nil {
+		warnf(env.Stderr, warnConfigLoad, "failed to load config: %v", err)
+	}
+
+	outputDir := opts.output
+	if outputDir != "" {
+		if strings.EqualFold(filepath.Ext(outputDir), ".md") {
+			return fmt.Errorf("--output must be a directory when restructuring several files, got %s", outputDir)
+		}
+		outputDir = config.ExpandPath(outputDir)
+		if err := config.EnsureOutputDir(outputDir); err != nil {
+			return fmt.Errorf("invalid output directory: %w", err)
+		}
+	}
+	// The Obsidian vault is where outputs go (see runTranscribeBatch).
+	opts.obsidianVault = obsidianVaultRoot(opts.obsidianVault, outputDir != "", cfg)
+	vault, err := openObsidianVault(opts.obsidianVault, cfg, MarkdownFormat)
+	if err != nil {
+		return err
+	}
+	if vault != nil {
+		cfg.OutputDir = vault.Dir()
+	}
+	outputName, err := structureOutputNamer(cfg, opts, env.Now())
+	if err != nil {
+		return err
+	}
+	outputs, err := outputPaths(inputs, outputDir, cfg.OutputDir, outputName)
+	if err != nil {
 		return err
 	}
 
-	fmt.Fprintf(env.Stderr, "Done: %s\n", output)
-	return nil
+	// === RESTRUCTURING ===
+
+	fmt.Fprintf(env.Stderr, "Restructuring %d files...\n", len(inputs))
+	var mu sync.Mutex
+	results := make([]batchResult, len(inputs))
+	for i, input := range inputs {
+		results[i] = batchResult{input: input, output: outputs[i]}
+		if err := cmd.Context().Err(); err != nil {
+			results[i].err = err
+			continue
+		}
+
+		stderr := &linePrefixWriter{mu: &mu, w: env.Stderr, prefix: "[" + filepath.Base(input) + "] "}
+		fileEnv := *env
+		fileEnv.Stderr = stderr
+
+		// With --progress json, events of each file carry its name and stages.
+		fileCmd, flush := cmd, stderr.Flush
+		if p, ok := env.Stderr.(*jsonProgress); ok {
+			fileProgress := p.forInput(input)
+			fileEnv.Stderr = fileProgress
+			fileCmd = &cobra.Command{}
+			fileCmd.SetContext(progress.WithHooks(cmd.Context(), fileProgress.hooks(progress.Hooks{})))
+			fileCmd.SetOut(cmd.OutOrStdout())
+			flush = fileProgress.flush
+		}
+
+		fileOpts := opts
+		fileOpts.inputPath = input
+		fileOpts.output = outputs[i]
+
+		start := env.Now()
+		results[i].err = runStructure(fileCmd, &fileEnv, fileOpts)
+		results[i].elapsed = env.Now().Sub(start)
+		flush()
+
+		if results[i].err != nil {
+			fmt.Fprintf(env.Stderr, "[%d/%d] Failed: %s: %v\n", i+1, len(inputs), input, results[i].err)
+		} else {
+			fmt.Fprintf(env.Stderr, "[%d/%d] Done: %s -> %s (%s)\n",
+				i+1, len(inputs), input, outputs[i], results[i].elapsed.Round(time.Second))
+		}
+	}
+
+	return reportBatch(env.Stderr, results)
 }
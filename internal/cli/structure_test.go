@@ -7,12 +7,15 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/alnah/go-transcript/internal/config"
 	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
 	"github.com/alnah/go-transcript/internal/template"
+	"github.com/alnah/go-transcript/internal/trash"
 )
 
 // Notes:
@@ -34,10 +37,12 @@ func TestDeriveStructuredOutputPath(t *testing.T) {
 	}{
 		{"simple md file", "meeting.md", "meeting_structured.md"},
 		{"removes raw suffix", "meeting_raw.md", "meeting_structured.md"},
+		{"removes raw extension", "notes.raw.md", "notes_structured.md"},
 		{"preserves extension", "notes.txt", "notes_structured.txt"},
 		{"no extension", "transcript", "transcript_structured"},
 		{"preserves path", "/path/to/meeting.md", "/path/to/meeting_structured.md"},
 		{"path with raw suffix", "/path/to/notes_raw.md", "/path/to/notes_structured.md"},
+		{"path with raw extension", "/path/to/notes.raw.md", "/path/to/notes_structured.md"},
 		{"double extension", "file.backup.md", "file.backup_structured.md"},
 		{"empty string", "", "_structured"},
 	}
@@ -123,7 +128,7 @@ func TestParseStructureOptions(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			_, err := ParseStructureOptions(tt.inputPath, tt.output, tt.tmpl, tt.outputLang, tt.provider)
+			_, err := ParseStructureOptions(tt.inputPath, tt.output, tt.tmpl, tt.outputLang, tt.provider, "")
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("ParseStructureOptions(%q, %q, %q, %q, %q) error = %v, wantErr %v", tt.inputPath, tt.output, tt.tmpl, tt.outputLang, tt.provider, err, tt.wantErr)
 			}
@@ -188,6 +193,7 @@ func TestStructureCmd_DefaultProvider(t *testing.T) {
 	}
 
 	env := &Env{
+		Now:                 time.Now,
 		Stderr:              &syncBuffer{},
 		Getenv:              defaultTestEnv,
 		FFmpegResolver:      &mockFFmpegResolver{},
@@ -213,6 +219,71 @@ func TestStructureCmd_DefaultProvider(t *testing.T) {
 	}
 }
 
+func TestStructureCmd_UserTemplate(t *testing.T) {
+	t.Parallel()
+
+	templatesDir := t.TempDir()
+	content := "---\nname: standup\ndescription: Daily standup\n---\nYou restructure a standup."
+	if err := os.WriteFile(filepath.Join(templatesDir, "standup.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	var gotPrompt string
+	mockMR := &mockMapReduceRestructurer{
+		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+			gotPrompt = tmpl.Prompt()
+			return "restructured", false, nil
+		},
+	}
+	env := &Env{
+		Now:            time.Now,
+		Stderr:         &syncBuffer{},
+		Getenv:         defaultTestEnv,
+		FFmpegResolver: &mockFFmpegResolver{},
+		ConfigLoader: &mockConfigLoader{LoadFunc: func() (config.Config, error) {
+			return config.Config{TemplatesDir: templatesDir}, nil
+		}},
+		RestructurerFactory: &mockRestructurerFactory{mockMapReducer: mockMR},
+	}
+
+	cmd := StructureCmd(env)
+	cmd.SetArgs([]string{createTestTranscriptFile(t, "test content"), "-t", "standup", "-o", filepath.Join(t.TempDir(), "out.md")})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPrompt != "You restructure a standup." {
+		t.Errorf("restructured with prompt %q, want the user template", gotPrompt)
+	}
+}
+
+func TestStructureCmd_RestructureModel(t *testing.T) {
+	t.Parallel()
+
+	factory := &mockRestructurerFactory{}
+	env := &Env{
+		Now:            time.Now,
+		Stderr:         &syncBuffer{},
+		Getenv:         defaultTestEnv,
+		FFmpegResolver: &mockFFmpegResolver{},
+		ConfigLoader: &mockConfigLoader{LoadFunc: func() (config.Config, error) {
+			return config.Config{RestructureModel: "deepseek-chat"}, nil
+		}},
+		RestructurerFactory: factory,
+	}
+
+	cmd := StructureCmd(env)
+	cmd.SetArgs([]string{createTestTranscriptFile(t, "test content"), "-t", "notes",
+		"--restructure-model", "deepseek-reasoner", "-o", filepath.Join(t.TempDir(), "out.md")})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	calls := factory.NewMapReducerCalls()
+	if len(calls) != 1 || calls[0].Model != "deepseek-reasoner" {
+		t.Errorf("factory calls = %+v, want model from --restructure-model", calls)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Tests for runStructure - Core restructuring logic
 // ---------------------------------------------------------------------------
@@ -238,7 +309,7 @@ func createTestTranscriptFile(t *testing.T, content string) string {
 // mustParseStructureOptions is a test helper that parses options or fails the test.
 func mustParseStructureOptions(t *testing.T, inputPath, output, tmpl, outputLang, provider string) StructureOptions {
 	t.Helper()
-	opts, err := ParseStructureOptions(inputPath, output, tmpl, outputLang, provider)
+	opts, err := ParseStructureOptions(inputPath, output, tmpl, outputLang, provider, "")
 	if err != nil {
 		t.Fatalf("ParseStructureOptions failed: %v", err)
 	}
@@ -322,6 +393,105 @@ func TestRunStructure_OutputExists(t *testing.T) {
 	}
 }
 
+func TestRunStructure_ExistingOutputModes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		mode       outputMode
+		wantPrefix string
+		wantTrash  int
+	}{
+		{name: "force", mode: outputForce, wantTrash: 1},
+		{name: "append", mode: outputAppend, wantPrefix: "existing\n\n---\n\n**"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inputPath := createTestTranscriptFile(t, "test content")
+			outputDir := t.TempDir()
+			outputPath := filepath.Join(outputDir, "existing.md")
+			if err := os.WriteFile(outputPath, []byte("existing"), 0644); err != nil {
+				t.Fatalf("failed to create existing file: %v", err)
+			}
+
+			env, _ := testEnv()
+			opts := mustParseStructureOptions(t, inputPath, outputPath, "brainstorm", "", "deepseek")
+			opts.outputMode = tt.mode
+			if err := RunStructure(createStructureCmd(context.Background()), env, opts); err != nil {
+				t.Fatalf("RunStructure() unexpected error: %v", err)
+			}
+
+			data, err := os.ReadFile(outputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := string(data)
+			if got == "existing" || !strings.HasPrefix(got, tt.wantPrefix) {
+				t.Errorf("output = %q, want the notes after %q", got, tt.wantPrefix)
+			}
+			entries, err := trash.New(outputDir).List()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != tt.wantTrash {
+				t.Errorf("trash has %d files, want %d", len(entries), tt.wantTrash)
+			}
+		})
+	}
+}
+
+func TestRunStructure_OutputNameAndFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestTranscriptFile(t, "[Alice] Hello.\n[Bob] Hi.\n[Alice] Let's start.\n")
+	outputDir := t.TempDir()
+	env, mocks := testEnv()
+	env.Now = fixedTime(time.Date(2026, 10, 17, 14, 5, 0, 0, time.Local))
+	mocks.configLoader.LoadFunc = func() (config.Config, error) {
+		return config.Config{OutputDir: outputDir, OutputName: "{{date}} {{template}} {{input_stem}}", FrontMatter: true}, nil
+	}
+
+	opts := mustParseStructureOptions(t, inputPath, "", "brainstorm", "", "deepseek")
+	if err := RunStructure(createStructureCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunStructure() unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "2026-10-17 brainstorm transcript.md"))
+	if err != nil {
+		t.Fatalf("output not named by the pattern: %v", err)
+	}
+	want := "---\ndate: 2026-10-17T14:05\ntemplate: brainstorm\nspeakers:\n  - Alice\n  - Bob\n---\n\n"
+	if got := string(data); !strings.HasPrefix(got, want) {
+		t.Errorf("output = %q, want it to start with %q", got, want)
+	}
+}
+
+func TestRunStructure_ObsidianVault(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestTranscriptFile(t, "Hello.")
+	vault := createTestVault(t)
+	env, _ := testEnv()
+	env.Now = fixedTime(time.Date(2026, 10, 17, 14, 5, 0, 0, time.Local))
+
+	opts := mustParseStructureOptions(t, inputPath, "", "meeting", "", "deepseek")
+	opts.obsidianVault = vault
+	if err := RunStructure(createStructureCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunStructure() unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(vault, "Transcripts", "transcript_structured.md"))
+	if err != nil {
+		t.Fatalf("output not written to the vault folder: %v", err)
+	}
+	want := "---\ndate: 2026-10-17T14:05\ntemplate: meeting\ntags:\n  - transcript\n  - meeting\n---\n\n" +
+		"Daily note: [[Daily/2026-10-17|2026-10-17]]\n\n"
+	if got := string(data); !strings.HasPrefix(got, want) {
+		t.Errorf("output = %q, want it to start with %q", got, want)
+	}
+}
+
 func TestRunStructure_MissingDeepSeekKey(t *testing.T) {
 	t.Parallel()
 
@@ -330,6 +500,7 @@ func TestRunStructure_MissingDeepSeekKey(t *testing.T) {
 	outputPath := filepath.Join(outputDir, "output.md")
 
 	env := &Env{
+		Now:    time.Now,
 		Stderr: &syncBuffer{},
 		Getenv: func(key string) string {
 			if key == EnvOpenAIAPIKey {
@@ -360,6 +531,7 @@ func TestRunStructure_MissingOpenAIKey(t *testing.T) {
 	outputPath := filepath.Join(outputDir, "output.md")
 
 	env := &Env{
+		Now:    time.Now,
 		Stderr: &syncBuffer{},
 		Getenv: func(key string) string {
 			if key == EnvDeepSeekAPIKey {
@@ -400,6 +572,7 @@ func TestRunStructure_Success(t *testing.T) {
 	}
 
 	env := &Env{
+		Now:                 time.Now,
 		Stderr:              stderr,
 		Getenv:              defaultTestEnv,
 		ConfigLoader:        &mockConfigLoader{},
@@ -453,6 +626,7 @@ func TestRunStructure_SuccessWithOpenAI(t *testing.T) {
 
 	// Only provide OpenAI key
 	env := &Env{
+		Now:    time.Now,
 		Stderr: &syncBuffer{},
 		Getenv: func(key string) string {
 			if key == EnvOpenAIAPIKey {
@@ -500,6 +674,7 @@ func TestRunStructure_WithOutputLang(t *testing.T) {
 	}
 
 	env := &Env{
+		Now:                 time.Now,
 		Stderr:              &syncBuffer{},
 		Getenv:              defaultTestEnv,
 		ConfigLoader:        &mockConfigLoader{},
@@ -536,6 +711,7 @@ func TestRunStructure_RestructureError(t *testing.T) {
 	}
 
 	env := &Env{
+		Now:                 time.Now,
 		Stderr:              &syncBuffer{},
 		Getenv:              defaultTestEnv,
 		ConfigLoader:        &mockConfigLoader{},
@@ -580,6 +756,7 @@ func TestRunStructure_DefaultOutputPath(t *testing.T) {
 	}
 
 	env := &Env{
+		Now:                 time.Now,
 		Stderr:              &syncBuffer{},
 		Getenv:              defaultTestEnv,
 		ConfigLoader:        configLoader,
@@ -619,6 +796,7 @@ func TestRunStructure_ProgressCallback(t *testing.T) {
 	}
 
 	env := &Env{
+		Now:                 time.Now,
 		Stderr:              stderr,
 		Getenv:              defaultTestEnv,
 		ConfigLoader:        &mockConfigLoader{},
@@ -710,3 +888,519 @@ func TestRunStructure_ValidationOrder(t *testing.T) {
 		}
 	})
 }
+
+// ---------------------------------------------------------------------------
+// Tests for stdin and several inputs
+// ---------------------------------------------------------------------------
+
+func TestExpandStructureInputs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("content"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	a, b, c := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt"), filepath.Join(dir, "c.md")
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr error
+	}{
+		{name: "single file", args: []string{a}, want: []string{a}},
+		{name: "stdin", args: []string{"-"}, want: []string{"-"}},
+		{name: "glob sorted", args: []string{filepath.Join(dir, "*.txt")}, want: []string{a, b}},
+		{name: "command-line order kept", args: []string{c, filepath.Join(dir, "*.txt")}, want: []string{c, a, b}},
+		{name: "duplicates removed", args: []string{a, filepath.Join(dir, "*.txt")}, want: []string{a, b}},
+		{name: "glob without match", args: []string{filepath.Join(dir, "*.srt")}, wantErr: ErrFileNotFound},
+		{name: "stdin with files", args: []string{"-", a}, wantErr: errors.New("stdin")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ExpandStructureInputs(tt.args)
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("ExpandStructureInputs(%v) = %v, want error", tt.args, got)
+				}
+				if errors.Is(tt.wantErr, ErrFileNotFound) && !errors.Is(err, ErrFileNotFound) {
+					t.Errorf("ExpandStructureInputs(%v) error = %v, want ErrFileNotFound", tt.args, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandStructureInputs(%v) unexpected error: %v", tt.args, err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ExpandStructureInputs(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+// echoStructureEnv returns an Env whose restructurer prefixes the transcript
+// with "restructured: ", failing on transcripts containing "fail".
+func echoStructureEnv(stderr *syncBuffer) *Env {
+	return &Env{
+		Stderr:         stderr,
+		Getenv:         defaultTestEnv,
+		Now:            time.Now,
+		FFmpegResolver: &mockFFmpegResolver{},
+		ConfigLoader:   &mockConfigLoader{},
+		RestructurerFactory: &mockRestructurerFactory{mockMapReducer: &mockMapReduceRestructurer{
+			RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+				if strings.Contains(transcript, "fail") {
+					return "", false, errors.New("restructuring failed")
+				}
+				return "restructured: " + transcript, false, nil
+			},
+		}},
+	}
+}
+
+func TestStructureCmd_Stdin(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes to stdout", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		var stdout strings.Builder
+		cmd := StructureCmd(echoStructureEnv(stderr))
+		cmd.SetIn(strings.NewReader("piped notes"))
+		cmd.SetOut(&stdout)
+		cmd.SetArgs([]string{"-", "-t", "meeting"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("StructureCmd.Execute() unexpected error: %v", err)
+		}
+
+		if stdout.String() != "restructured: piped notes" {
+			t.Errorf("stdout = %q, want the restructured stdin", stdout.String())
+		}
+		if !strings.Contains(stderr.String(), "Reading stdin") {
+			t.Errorf("stderr = %q, want containing %q", stderr.String(), "Reading stdin")
+		}
+	})
+
+	t.Run("writes to --output", func(t *testing.T) {
+		t.Parallel()
+
+		output := filepath.Join(t.TempDir(), "notes.md")
+		var stdout strings.Builder
+		cmd := StructureCmd(echoStructureEnv(&syncBuffer{}))
+		cmd.SetIn(strings.NewReader("piped notes"))
+		cmd.SetOut(&stdout)
+		cmd.SetArgs([]string{"-", "-t", "meeting", "-o", output})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("StructureCmd.Execute() unexpected error: %v", err)
+		}
+
+		content, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatalf("os.ReadFile(%q) unexpected error: %v", output, err)
+		}
+		if string(content) != "restructured: piped notes" {
+			t.Errorf("output = %q, want the restructured stdin", content)
+		}
+		if stdout.String() != "" {
+			t.Errorf("stdout = %q, want empty", stdout.String())
+		}
+	})
+
+	t.Run("file to stdout", func(t *testing.T) {
+		t.Parallel()
+
+		input := createTestTranscriptFile(t, "file notes")
+		var stdout strings.Builder
+		cmd := StructureCmd(echoStructureEnv(&syncBuffer{}))
+		cmd.SetOut(&stdout)
+		cmd.SetArgs([]string{input, "-t", "meeting", "-o", "-"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("StructureCmd.Execute() unexpected error: %v", err)
+		}
+
+		if stdout.String() != "restructured: file notes" {
+			t.Errorf("stdout = %q, want the restructured file", stdout.String())
+		}
+		if _, err := os.Stat(deriveStructuredOutputPath(input)); !os.IsNotExist(err) {
+			t.Errorf("output file written next to the input, want none (err = %v)", err)
+		}
+	})
+
+	t.Run("empty stdin", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := StructureCmd(echoStructureEnv(&syncBuffer{}))
+		cmd.SetIn(strings.NewReader("  \n"))
+		cmd.SetArgs([]string{"-", "-t", "meeting"})
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "empty") {
+			t.Errorf("StructureCmd.Execute() error = %v, want empty input error", err)
+		}
+	})
+}
+
+func TestStructureCmd_SeveralInputs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("one output per input", func(t *testing.T) {
+		t.Parallel()
+
+		dir, outputDir := t.TempDir(), t.TempDir()
+		for name, content := range map[string]string{"monday_raw.txt": "monday", "tuesday.txt": "tuesday"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write %s: %v", name, err)
+			}
+		}
+
+		stderr := &syncBuffer{}
+		cmd := StructureCmd(echoStructureEnv(stderr))
+		cmd.SetArgs([]string{filepath.Join(dir, "*.txt"), "-t", "notes", "-o", outputDir})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("StructureCmd.Execute() unexpected error: %v", err)
+		}
+
+		for name, want := range map[string]string{
+			"monday_structured.txt":  "restructured: monday",
+			"tuesday_structured.txt": "restructured: tuesday",
+		} {
+			content, err := os.ReadFile(filepath.Join(outputDir, name))
+			if err != nil {
+				t.Fatalf("os.ReadFile(%q) unexpected error: %v", name, err)
+			}
+			if string(content) != want {
+				t.Errorf("%s = %q, want %q", name, content, want)
+			}
+		}
+		for _, want := range []string{"Restructuring 2 files", "[monday_raw.txt] Reading", "2 succeeded, 0 failed"} {
+			if !strings.Contains(stderr.String(), want) {
+				t.Errorf("stderr = %q, want containing %q", stderr.String(), want)
+			}
+		}
+	})
+
+	t.Run("failing file does not stop the others", func(t *testing.T) {
+		t.Parallel()
+
+		dir, outputDir := t.TempDir(), t.TempDir()
+		bad, good := filepath.Join(dir, "bad.md"), filepath.Join(dir, "good.md")
+		for path, content := range map[string]string{bad: "fail", good: "good"} {
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write %s: %v", path, err)
+			}
+		}
+
+		stderr := &syncBuffer{}
+		cmd := StructureCmd(echoStructureEnv(stderr))
+		cmd.SetArgs([]string{bad, good, "-t", "notes", "-o", outputDir})
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), "1 of 2 files failed") {
+			t.Fatalf("StructureCmd.Execute() error = %v, want 1 of 2 files failed", err)
+		}
+		if _, err := os.Stat(filepath.Join(outputDir, "good_structured.md")); err != nil {
+			t.Errorf("good_structured.md not written: %v", err)
+		}
+	})
+
+	t.Run("output must be a directory", func(t *testing.T) {
+		t.Parallel()
+
+		a := createTestTranscriptFile(t, "a")
+		b := createTestTranscriptFile(t, "b")
+		cmd := StructureCmd(echoStructureEnv(&syncBuffer{}))
+		cmd.SetArgs([]string{a, b, "-t", "notes", "-o", "out.md"})
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "must be a directory") {
+			t.Errorf("StructureCmd.Execute() error = %v, want --output directory error", err)
+		}
+	})
+}
+
+func TestStructureCmd_SeveralTemplates(t *testing.T) {
+	t.Parallel()
+
+	t.Run("one output per template", func(t *testing.T) {
+		t.Parallel()
+
+		dir, outputDir := t.TempDir(), t.TempDir()
+		input := filepath.Join(dir, "standup_raw.md")
+		if err := os.WriteFile(input, []byte("standup"), 0644); err != nil {
+			t.Fatalf("failed to write input: %v", err)
+		}
+
+		stderr := &syncBuffer{}
+		env := echoStructureEnv(stderr)
+		factory := &mockRestructurerFactory{mockMapReducer: &mockMapReduceRestructurer{
+			RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+				return tmpl.String() + ": " + transcript, false, nil
+			},
+		}}
+		env.RestructurerFactory = factory
+		cmd := StructureCmd(env)
+		cmd.SetArgs([]string{input, "-t", "meeting,digest", "-t", "brainstorm", "-o", outputDir})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("StructureCmd.Execute() unexpected error: %v", err)
+		}
+
+		for _, tmpl := range []string{"meeting", "digest", "brainstorm"} {
+			name := "standup_" + tmpl + ".md"
+			content, err := os.ReadFile(filepath.Join(outputDir, name))
+			if err != nil {
+				t.Fatalf("os.ReadFile(%q) unexpected error: %v", name, err)
+			}
+			if want := tmpl + ": standup"; string(content) != want {
+				t.Errorf("%s = %q, want %q", name, content, want)
+			}
+		}
+		if calls := factory.NewMapReducerCalls(); len(calls) != 1 {
+			t.Errorf("NewMapReducer() called %d times, want once for all templates", len(calls))
+		}
+		if want := "Restructuring with templates 'meeting', 'digest', 'brainstorm'"; !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr = %q, want containing %q", stderr.String(), want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		a := createTestTranscriptFile(t, "a")
+		b := createTestTranscriptFile(t, "b")
+		tests := []struct {
+			name    string
+			args    []string
+			wantMsg string
+		}{
+			{name: "given twice", args: []string{a, "-t", "meeting,digest,meeting"}, wantMsg: "given twice"},
+			{name: "several inputs", args: []string{a, b, "-t", "meeting,digest"}, wantMsg: "single transcript"},
+			{name: "stdout", args: []string{a, "-t", "meeting,digest", "--stdout"}, wantMsg: "stdout"},
+			{name: "stdin", args: []string{"-", "-t", "meeting,digest"}, wantMsg: "--output"},
+			{name: "output file", args: []string{a, "-t", "meeting,digest", "-o", "notes.md"}, wantMsg: "must be a directory"},
+			{name: "stream", args: []string{a, "-t", "meeting,digest", "--stream-restructure"}, wantMsg: "--stream-restructure takes a single template"},
+			{name: "self-consistency", args: []string{a, "-t", "meeting,digest", "--self-consistency", "3"}, wantMsg: "--self-consistency takes a single template"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				cmd := StructureCmd(echoStructureEnv(&syncBuffer{}))
+				cmd.SetIn(strings.NewReader("stdin"))
+				cmd.SetArgs(tt.args)
+				if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), tt.wantMsg) {
+					t.Errorf("StructureCmd.Execute() error = %v, want %q", err, tt.wantMsg)
+				}
+			})
+		}
+	})
+
+	t.Run("existing output", func(t *testing.T) {
+		t.Parallel()
+
+		outputDir := t.TempDir()
+		input := createTestTranscriptFile(t, "content")
+		existing := filepath.Join(outputDir, "transcript_digest.md")
+		if err := os.WriteFile(existing, []byte("kept"), 0644); err != nil {
+			t.Fatalf("failed to write existing output: %v", err)
+		}
+
+		env := echoStructureEnv(&syncBuffer{})
+		factory := &mockRestructurerFactory{}
+		env.RestructurerFactory = factory
+		cmd := StructureCmd(env)
+		cmd.SetArgs([]string{input, "-t", "meeting,digest", "-o", outputDir})
+		if err := cmd.Execute(); !errors.Is(err, ErrOutputExists) {
+			t.Errorf("StructureCmd.Execute() error = %v, want ErrOutputExists", err)
+		}
+		if calls := factory.NewMapReducerCalls(); len(calls) != 0 {
+			t.Errorf("restructured before checking the outputs: %d calls", len(calls))
+		}
+	})
+}
+
+func TestStructureCmd_SummaryLevel(t *testing.T) {
+	t.Parallel()
+
+	input := createTestTranscriptFile(t, "content")
+	outputDir := t.TempDir()
+
+	var levels []string
+	env := echoStructureEnv(&syncBuffer{})
+	env.RestructurerFactory = &mockRestructurerFactory{mockMapReducer: &mockMapReduceRestructurer{
+		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+			levels = append(levels, tmpl.String()+":"+tmpl.Level().String())
+			return "notes", false, nil
+		},
+	}}
+	cmd := StructureCmd(env)
+	cmd.SetArgs([]string{input, "-t", "meeting,notes", "--summary-level", "short", "-o", outputDir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("StructureCmd.Execute() unexpected error: %v", err)
+	}
+	if got := strings.Join(levels, ", "); got != "meeting:short, notes:short" {
+		t.Errorf("restructured templates = %s, want meeting and notes at the short level", got)
+	}
+
+	cmd = StructureCmd(echoStructureEnv(&syncBuffer{}))
+	cmd.SetArgs([]string{input, "-t", "meeting", "--summary-level", "brief"})
+	if err := cmd.Execute(); !errors.Is(err, template.ErrInvalidLevel) {
+		t.Errorf("StructureCmd.Execute() error = %v, want ErrInvalidLevel", err)
+	}
+}
+
+func TestTemplateOutputPaths(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	opts := structureOptions{templates: []template.Name{template.MeetingName, template.DigestName}}
+	started := time.Date(2026, 10, 17, 14, 5, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		input   string
+		pattern string // output-name setting
+		want    []string
+		wantErr string
+	}{
+		{name: "default names", input: "standup_raw.md", want: []string{"standup_meeting.md", "standup_digest.md"}},
+		{name: "raw transcript of -r", input: "standup.raw.md", want: []string{"standup_meeting.md", "standup_digest.md"}},
+		{name: "pattern", input: "standup_raw.md", pattern: "{{date}} {{template}}", want: []string{"2026-10-17 meeting.md", "2026-10-17 digest.md"}},
+		{name: "pattern without template", input: "standup_raw.md", pattern: "{{date}} {{input_stem}}", wantErr: "{{template}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := templateOutputPaths(config.Config{OutputName: tt.pattern}, opts, tt.input, dir, started)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("templateOutputPaths() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("templateOutputPaths() unexpected error: %v", err)
+			}
+			for i, name := range tt.want {
+				if want := filepath.Join(dir, name); got[i] != want {
+					t.Errorf("templateOutputPaths()[%d] = %q, want %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunStructure_UnknownSplit(t *testing.T) {
+	t.Parallel()
+
+	env := echoStructureEnv(&syncBuffer{})
+	env.ConfigLoader = &mockConfigLoader{LoadFunc: func() (config.Config, error) {
+		return config.Config{RestructureSplit: "turns"}, nil
+	}}
+	opts := mustParseStructureOptions(t, createTestTranscriptFile(t, "content"),
+		filepath.Join(t.TempDir(), "out.md"), "notes", "", "deepseek")
+	err := RunStructure(createStructureCmd(context.Background()), env, opts)
+	if !errors.Is(err, restructure.ErrUnknownSplit) {
+		t.Errorf("RunStructure() error = %v, want ErrUnknownSplit", err)
+	}
+}
+
+func TestStructureCmd_ShowPrompt(t *testing.T) {
+	t.Parallel()
+
+	t.Run("prints the messages without calling the provider", func(t *testing.T) {
+		t.Parallel()
+
+		transcript := "Alice: let's ship on Friday. " + strings.Repeat("Details of the plan. ", 200)
+		inputPath := createTestTranscriptFile(t, transcript)
+		output := filepath.Join(t.TempDir(), "notes.md")
+		env := echoStructureEnv(&syncBuffer{})
+		env.Getenv = func(string) string { return "" } // No API key needed
+		factory := &mockRestructurerFactory{}
+		env.RestructurerFactory = factory
+
+		var stdout strings.Builder
+		cmd := StructureCmd(env)
+		cmd.SetOut(&stdout)
+		cmd.SetArgs([]string{inputPath, "-t", "meeting", "-T", "fr", "-o", output, "--show-prompt"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("StructureCmd.Execute() unexpected error: %v", err)
+		}
+
+		got := stdout.String()
+		for _, want := range []string{
+			"Restructuring with deepseek (" + restructure.DefaultDeepSeekModel + "): 1 call, nothing sent",
+			"=== Call 1/1: restructure ===",
+			"--- system ---\nRespond in French.\n\n" + template.MustParseName("meeting").Prompt(),
+			"--- user ---\nAlice: let's ship on Friday.",
+			"characters elided ...]",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("stdout = %q, want containing %q", got, want)
+			}
+		}
+		if len(got) > 5000 {
+			t.Errorf("stdout is %d characters, want the transcript elided", len(got))
+		}
+		if calls := factory.NewMapReducerCalls(); len(calls) != 0 {
+			t.Errorf("restructurer created %d times, want 0", len(calls))
+		}
+		if _, err := os.Stat(output); !os.IsNotExist(err) {
+			t.Errorf("output written with --show-prompt: %v", err)
+		}
+	})
+
+	t.Run("lists the glossary terms", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		configured := filepath.Join(dir, "configured.txt")
+		flagged := filepath.Join(dir, "flagged.txt")
+		if err := os.WriteFile(configured, []byte("Kubernetes\nOKR\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(flagged, []byte("Zyzzyva\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		for _, tt := range []struct {
+			name string
+			args []string
+			want string
+		}{
+			{"configured", nil, "Kubernetes, OKR"},
+			{"--glossary", []string{"--glossary", flagged}, "Zyzzyva"},
+		} {
+			env := echoStructureEnv(&syncBuffer{})
+			env.Getenv = func(string) string { return "" }
+			env.ConfigLoader = &mockConfigLoader{LoadFunc: func() (config.Config, error) {
+				return config.Config{Glossary: configured}, nil
+			}}
+
+			var stdout strings.Builder
+			cmd := StructureCmd(env)
+			cmd.SetOut(&stdout)
+			cmd.SetArgs(append([]string{createTestTranscriptFile(t, "Hello."), "-t", "notes", "--show-prompt"}, tt.args...))
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("%s: StructureCmd.Execute() unexpected error: %v", tt.name, err)
+			}
+			if got := stdout.String(); !strings.Contains(got, "misspells them:\n"+tt.want+"\n") || (tt.name != "configured" && strings.Contains(got, "Kubernetes")) {
+				t.Errorf("%s: stdout = %q, want the terms %q only", tt.name, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("takes a single transcript", func(t *testing.T) {
+		t.Parallel()
+
+		a := createTestTranscriptFile(t, "a")
+		b := createTestTranscriptFile(t, "b")
+		cmd := StructureCmd(echoStructureEnv(&syncBuffer{}))
+		cmd.SetArgs([]string{a, b, "-t", "notes", "--show-prompt"})
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "single transcript") {
+			t.Errorf("StructureCmd.Execute() error = %v, want --show-prompt error", err)
+		}
+	})
+}
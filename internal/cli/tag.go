@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/transcribe"
+	"github.com/alnah/go-transcript/internal/vocab"
+)
+
+// glossaryFlagHelp describes the --glossary flag of the transcribe, live and
+// structure commands.
+const glossaryFlagHelp = "File of terms (one per line, most important first) put in the transcription and restructure prompts (default: config glossary)"
+
+// tagVocabulary is the vocabulary of a session tag (--tag): the proper nouns
+// of previous transcripts with the same tag, used to bias transcription,
+// after the glossary (--glossary) and the vocab of the project file if any.
+// A nil *tagVocabulary is valid and does nothing, so callers need not check
+// whether --tag was given.
+type tagVocabulary struct {
+	tag      string
+	store    *vocab.Store // nil without a tag: nothing is recorded
+	prompt   string
+	glossary []string // Also listed in the restructure prompt
+}
+
+// loadGlossary reads the glossary of cfg: the --glossary file, or the
+// glossary of the config or project file. Returns nil if none is set, and
+// vocab.ErrInvalidGlossary if it cannot be read.
+func loadGlossary(env *Env, cfg config.Config) ([]string, error) {
+	if cfg.Glossary == "" {
+		return nil, nil
+	}
+	terms, err := vocab.ReadGlossary(cfg.Glossary)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(env.Stderr, "Glossary: %d terms from %s\n", len(terms), cfg.Glossary)
+	return terms, nil
+}
+
+// resolveTag returns the session tag to use: the --tag flag if given, else
+// the tag of the project file, if any.
+func resolveTag(flag string, cfg config.Config) (string, error) {
+	if flag != "" || cfg.Tag == "" {
+		return flag, nil
+	}
+	tag, err := vocab.ParseTag(cfg.Tag)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s in %s: %w", config.KeyTag, cfg.Project, err)
+	}
+	return tag, nil
+}
+
+// loadTagVocabulary loads the vocabulary of tag from the configured tags
+// directory, after the glossary terms and the vocab of the project file.
+// Returns nil without tag, glossary nor vocab. A history that cannot be read
+// only warns: the run continues with the glossary and project vocab only.
+func loadTagVocabulary(env *Env, cfg config.Config, tag string, glossary []string) *tagVocabulary {
+	if tag == "" && len(cfg.Vocab) == 0 && len(glossary) == 0 {
+		return nil
+	}
+	fixed := slices.Concat(glossary, cfg.Vocab)
+	v := &tagVocabulary{tag: tag, prompt: vocab.History{}.PromptWith(fixed, vocab.DefaultPromptTerms), glossary: glossary}
+	if len(cfg.Vocab) > 0 {
+		fmt.Fprintf(env.Stderr, "Project: prompting with %d names from %s\n",
+			min(len(cfg.Vocab), vocab.DefaultPromptTerms), cfg.Project)
+	}
+	if tag == "" {
+		return v
+	}
+	if cfg.TagsDir == "" {
+		warnf(env.Stderr, warnSettingMissing, "%s is not configured, tag '%s' is ignored", config.KeyTagsDir, tag)
+		if len(cfg.Vocab) == 0 && len(glossary) == 0 {
+			return nil
+		}
+		return v
+	}
+
+	v.store = vocab.NewStore(cfg.TagsDir)
+	history, err := v.store.Load(tag)
+	if err != nil {
+		warnf(env.Stderr, warnTagVocabulary, "%v", err)
+		return v
+	}
+
+	terms := history.TopTerms(vocab.DefaultPromptTerms)
+	if len(terms) == 0 {
+		fmt.Fprintf(env.Stderr, "Tag '%s': no recurring names yet (%d previous sessions)\n", tag, history.Sessions)
+		return v
+	}
+	v.prompt = history.PromptWith(fixed, vocab.DefaultPromptTerms)
+	fmt.Fprintf(env.Stderr, "Tag '%s': prompting with %d names from %d previous sessions\n",
+		tag, len(terms), history.Sessions)
+	return v
+}
+
+// transcriptionPrompt returns the prompt built from the tag's vocabulary.
+func (v *tagVocabulary) transcriptionPrompt() string {
+	if v == nil {
+		return ""
+	}
+	return v.prompt
+}
+
+// glossaryTerms returns the glossary terms, listed in the restructure prompt.
+func (v *tagVocabulary) glossaryTerms() []string {
+	if v == nil {
+		return nil
+	}
+	return v.glossary
+}
+
+// record adds the proper nouns of the transcription results to the tag's
+// vocabulary, for the next sessions. Failures only warn.
+func (v *tagVocabulary) record(env *Env, results []string, timestamps bool) {
+	if v == nil || v.store == nil {
+		return
+	}
+	text, err := resultsText(results, timestamps)
+	if err == nil {
+		err = v.store.Record(v.tag, text)
+	}
+	if err != nil {
+		warnf(env.Stderr, warnTagVocabulary, "failed to record vocabulary of tag '%s': %v", v.tag, err)
+	}
+}
+
+// resultsText returns the plain text of transcription results.
+// Timestamped results (see transcribe.Options.Timestamps) are decoded first.
+func resultsText(results []string, timestamps bool) (string, error) {
+	if !timestamps {
+		return strings.Join(results, "\n\n"), nil
+	}
+	var b strings.Builder
+	for _, result := range results {
+		segments, err := transcribe.ParseSegments(result)
+		if err != nil {
+			return "", err
+		}
+		for _, s := range segments {
+			b.WriteString(s.Text)
+			b.WriteString("\n")
+		}
+	}
+	return b.String(), nil
+}
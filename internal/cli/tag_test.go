@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/vocab"
+)
+
+func TestLoadTagVocabulary(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no tag", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		v := loadTagVocabulary(&Env{Stderr: stderr}, config.Config{TagsDir: t.TempDir()}, "", nil)
+		if v != nil {
+			t.Errorf("loadTagVocabulary() = %+v, want nil", v)
+		}
+		// A nil vocabulary is usable
+		if p := v.transcriptionPrompt(); p != "" {
+			t.Errorf("transcriptionPrompt() = %q, want empty", p)
+		}
+		v.record(&Env{Stderr: stderr}, []string{"Hello Alice."}, false)
+		if stderr.String() != "" {
+			t.Errorf("stderr = %q, want empty", stderr.String())
+		}
+	})
+
+	t.Run("tags-dir not configured", func(t *testing.T) {
+		t.Parallel()
+
+		stderr := &syncBuffer{}
+		if v := loadTagVocabulary(&Env{Stderr: stderr}, config.Config{}, "apollo", nil); v != nil {
+			t.Errorf("loadTagVocabulary() = %+v, want nil", v)
+		}
+		if !strings.Contains(stderr.String(), config.KeyTagsDir) {
+			t.Errorf("stderr = %q, want warning about %s", stderr.String(), config.KeyTagsDir)
+		}
+	})
+
+	t.Run("recorded names become the prompt", func(t *testing.T) {
+		t.Parallel()
+
+		dir := filepath.Join(t.TempDir(), "tags")
+		cfg := config.Config{TagsDir: dir}
+		stderr := &syncBuffer{}
+		env := &Env{Stderr: stderr}
+
+		first := loadTagVocabulary(env, cfg, "apollo", nil)
+		if first.transcriptionPrompt() != "" {
+			t.Errorf("first prompt = %q, want empty", first.transcriptionPrompt())
+		}
+		first.record(env, []string{"We met Aldrin.", "Then we met Aldrin again."}, false)
+
+		second := loadTagVocabulary(env, cfg, "apollo", nil)
+		if got := second.transcriptionPrompt(); got != "Aldrin." {
+			t.Errorf("second prompt = %q, want %q", got, "Aldrin.")
+		}
+		if !strings.Contains(stderr.String(), "prompting with 1 names from 1 previous sessions") {
+			t.Errorf("stderr = %q, want prompt summary", stderr.String())
+		}
+	})
+
+	t.Run("project vocab comes first", func(t *testing.T) {
+		t.Parallel()
+
+		dir := filepath.Join(t.TempDir(), "tags")
+		if err := vocab.NewStore(dir).Record("apollo", "We met Aldrin. Then Aldrin left."); err != nil {
+			t.Fatal(err)
+		}
+		cfg := config.Config{TagsDir: dir, Vocab: []string{"Kubernetes"}, Project: "/work/acme/.transcript.toml"}
+		stderr := &syncBuffer{}
+
+		v := loadTagVocabulary(&Env{Stderr: stderr}, cfg, "apollo", nil)
+		if got, want := v.transcriptionPrompt(), "Kubernetes, Aldrin."; got != want {
+			t.Errorf("prompt = %q, want %q", got, want)
+		}
+		if !strings.Contains(stderr.String(), "Project: prompting with 1 names from /work/acme/.transcript.toml") {
+			t.Errorf("stderr = %q, want project summary", stderr.String())
+		}
+	})
+
+	t.Run("project vocab without tag", func(t *testing.T) {
+		t.Parallel()
+
+		env := &Env{Stderr: &syncBuffer{}}
+		v := loadTagVocabulary(env, config.Config{Vocab: []string{"Kubernetes", "Acme"}}, "", nil)
+		if got, want := v.transcriptionPrompt(), "Kubernetes, Acme."; got != want {
+			t.Errorf("prompt = %q, want %q", got, want)
+		}
+		// Nothing to record without a tag
+		v.record(env, []string{"Hello Alice."}, false)
+	})
+
+	t.Run("glossary comes before project vocab", func(t *testing.T) {
+		t.Parallel()
+
+		env := &Env{Stderr: &syncBuffer{}}
+		cfg := config.Config{Vocab: []string{"Kubernetes", "Acme"}}
+		v := loadTagVocabulary(env, cfg, "", []string{"Acme", "OKR"})
+		if got, want := v.transcriptionPrompt(), "Acme, OKR, Kubernetes."; got != want {
+			t.Errorf("prompt = %q, want %q", got, want)
+		}
+		if got := v.glossaryTerms(); !slices.Equal(got, []string{"Acme", "OKR"}) {
+			t.Errorf("glossaryTerms() = %q, want the glossary", got)
+		}
+	})
+}
+
+func TestLoadGlossary(t *testing.T) {
+	t.Parallel()
+
+	t.Run("none configured", func(t *testing.T) {
+		t.Parallel()
+
+		terms, err := loadGlossary(&Env{Stderr: &syncBuffer{}}, config.Config{})
+		if err != nil || terms != nil {
+			t.Errorf("loadGlossary() = %q, %v; want nil", terms, err)
+		}
+	})
+
+	t.Run("reads the file", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "glossary.txt")
+		if err := os.WriteFile(path, []byte("Kubernetes\nOKR\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		stderr := &syncBuffer{}
+		terms, err := loadGlossary(&Env{Stderr: stderr}, config.Config{Glossary: path})
+		if err != nil || !slices.Equal(terms, []string{"Kubernetes", "OKR"}) {
+			t.Errorf("loadGlossary() = %q, %v", terms, err)
+		}
+		if !strings.Contains(stderr.String(), "Glossary: 2 terms") {
+			t.Errorf("stderr = %q, want glossary summary", stderr.String())
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.Config{Glossary: filepath.Join(t.TempDir(), "missing.txt")}
+		if _, err := loadGlossary(&Env{Stderr: &syncBuffer{}}, cfg); !errors.Is(err, vocab.ErrInvalidGlossary) {
+			t.Errorf("loadGlossary() error = %v, want ErrInvalidGlossary", err)
+		}
+	})
+}
+
+func TestResolveTag(t *testing.T) {
+	t.Parallel()
+
+	project := config.Config{Tag: "acme-standup", Project: "/work/acme/.transcript.toml"}
+	tests := []struct {
+		name    string
+		flag    string
+		cfg     config.Config
+		want    string
+		wantErr bool
+	}{
+		{"no tag", "", config.Config{}, "", false},
+		{"flag", "apollo", config.Config{}, "apollo", false},
+		{"project tag", "", project, "acme-standup", false},
+		{"flag overrides project", "apollo", project, "apollo", false},
+		{"invalid project tag", "", config.Config{Tag: "Acme Standup"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := resolveTag(tt.flag, tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, vocab.ErrInvalidTag) {
+				t.Errorf("resolveTag() error = %v, want ErrInvalidTag", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveTag() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultsText(t *testing.T) {
+	t.Parallel()
+
+	got, err := resultsText([]string{"First.", "Second."}, false)
+	if err != nil || got != "First.\n\nSecond." {
+		t.Errorf("resultsText(plain) = %q, %v", got, err)
+	}
+
+	got, err = resultsText([]string{`[{"start":0,"end":1,"text":"Hi Alice."}]`, `[{"start":0,"end":1,"speaker":"A","text":"Hi Bob."}]`}, true)
+	if err != nil || got != "Hi Alice.\nHi Bob.\n" {
+		t.Errorf("resultsText(timestamps) = %q, %v", got, err)
+	}
+
+	if _, err := resultsText([]string{"not json"}, true); err == nil {
+		t.Error("resultsText(invalid timestamps) expected error")
+	}
+}
+
+func TestTagVocabulary_RecordInvalidResults(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	stderr := &syncBuffer{}
+	env := &Env{Stderr: stderr}
+
+	v := loadTagVocabulary(env, config.Config{TagsDir: dir}, "apollo", nil)
+	v.record(env, []string{"not json"}, true)
+	if !strings.Contains(stderr.String(), "failed to record vocabulary") {
+		t.Errorf("stderr = %q, want record warning", stderr.String())
+	}
+
+	history, err := vocab.NewStore(dir).Load("apollo")
+	if err != nil || history.Sessions != 0 {
+		t.Errorf("history = %+v, %v; want nothing recorded", history, err)
+	}
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// TemplatesCmd creates the templates command with subcommands.
+// The env parameter provides injectable dependencies for testing.
+func TemplatesCmd(env *Env) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "templates",
+		Short: "Manage restructure templates",
+		Long: `Manage restructure templates.
+
+Besides the built-in templates, --template accepts user templates: the path
+of a markdown file, or the name of a template stored in the templates
+directory (default: ~/.config/go-transcript/templates, see the templates-dir
+config key).
+
+A template file holds the prompt sent to the LLM, optionally preceded by a
+front-matter block. The name defaults to the file name without .md:
+
+  ---
+  name: standup
+  description: Daily standup notes
+  ---
+  You restructure a standup meeting transcript into markdown.
+  ...
+
+The prompt may hold placeholders, like {{attendees}}, filled with --var
+attendees="Alice, Bob" or a --vars-file of name=value lines.`,
+		Example: `  transcript templates list
+  transcript structure raw.md -t ./my-template.md
+  transcript structure raw.md -t standup  # From the templates directory`,
+	}
+
+	cmd.AddCommand(templatesListCmd(env))
+
+	return cmd
+}
+
+// templatesListCmd creates the "templates list" subcommand.
+func templatesListCmd(env *Env) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List built-in and user templates",
+		Long: `List built-in and user templates.
+
+User templates are read from the templates directory. Invalid template
+files are reported on stderr.`,
+		Example: `  transcript templates list`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTemplatesList(cmd.OutOrStdout(), env)
+		},
+	}
+}
+
+// runTemplatesList writes the available templates to w, built-in first.
+func runTemplatesList(w io.Writer, env *Env) error {
+	var user []template.Name
+	if dir := userTemplatesDir(env); dir != "" {
+		var err error
+		if user, err = template.LoadDir(dir); err != nil {
+			warnf(env.Stderr, warnInvalidSetting, "%v", err)
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSOURCE\tDESCRIPTION")
+	for _, n := range template.Builtins() {
+		fmt.Fprintf(tw, "%s\tbuilt-in\t%s\n", n, n.Description())
+	}
+	for _, n := range user {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", n, n.Path(), n.Description())
+	}
+	return tw.Flush()
+}
+
+// userTemplatesDir returns the configured templates directory.
+// Returns empty if the config cannot be loaded: user templates are then
+// only reachable by path. Commands warn about the config later.
+func userTemplatesDir(env *Env) string {
+	cfg, err := env.ConfigLoader.Load()
+	if err != nil {
+		return ""
+	}
+	return cfg.TemplatesDir
+}
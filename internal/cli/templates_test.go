@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// templatesEnv returns a test Env whose config points at templatesDir.
+func templatesEnv(templatesDir string) (*Env, *syncBuffer) {
+	stderr := &syncBuffer{}
+	env, mocks := testEnv(func(o *testEnvOptions) { o.stderr = stderr })
+	mocks.configLoader.LoadFunc = func() (config.Config, error) {
+		return config.Config{TemplatesDir: templatesDir}, nil
+	}
+	return env, stderr
+}
+
+func TestRunTemplatesList(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"standup.md": "---\ndescription: Daily standup notes\n---\nPrompt",
+		"broken.md":  "---\nname: broken\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write template: %v", err)
+		}
+	}
+	env, stderr := templatesEnv(dir)
+
+	var out bytes.Buffer
+	if err := RunTemplatesList(&out, env); err != nil {
+		t.Fatalf("RunTemplatesList() unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if want := 1 + len(template.Names()) + 1; len(lines) != want {
+		t.Fatalf("RunTemplatesList() printed %d lines, want %d:\n%s", len(lines), want, out.String())
+	}
+	if !strings.HasPrefix(lines[1], template.Brainstorm) || !strings.Contains(lines[1], "built-in") {
+		t.Errorf("first template line = %q, want built-in brainstorm", lines[1])
+	}
+	last := lines[len(lines)-1]
+	for _, want := range []string{"standup", filepath.Join(dir, "standup.md"), "Daily standup notes"} {
+		if !strings.Contains(last, want) {
+			t.Errorf("user template line = %q, want containing %q", last, want)
+		}
+	}
+	if !strings.Contains(stderr.String(), "broken.md") {
+		t.Errorf("stderr = %q, want a warning about broken.md", stderr.String())
+	}
+}
+
+func TestRunTemplatesList_NoUserTemplates(t *testing.T) {
+	t.Parallel()
+
+	env, stderr := templatesEnv(filepath.Join(t.TempDir(), "missing"))
+
+	var out bytes.Buffer
+	if err := RunTemplatesList(&out, env); err != nil {
+		t.Fatalf("RunTemplatesList() unexpected error: %v", err)
+	}
+	if got := strings.Count(out.String(), "built-in"); got != len(template.Names()) {
+		t.Errorf("RunTemplatesList() listed %d built-in templates, want %d", got, len(template.Names()))
+	}
+	if stderr.String() != "" {
+		t.Errorf("stderr = %q, want empty", stderr.String())
+	}
+}
+
+func TestParseStructureOptions_UserTemplate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "standup.md"), []byte("Prompt"), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	opts, err := ParseStructureOptions("in.md", "", "standup", "", "", dir)
+	if err != nil {
+		t.Fatalf("ParseStructureOptions() unexpected error: %v", err)
+	}
+	if opts.template.String() != "standup" {
+		t.Errorf("template = %q, want %q", opts.template, "standup")
+	}
+
+	if _, err := ParseStructureOptions("in.md", "", "standup", "", "", ""); !errors.Is(err, template.ErrUnknown) {
+		t.Errorf("ParseStructureOptions() without templates dir error = %v, want ErrUnknown", err)
+	}
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// Help of the --var and --vars-file flags of the transcribe, live and
+// structure commands.
+const (
+	varFlagHelp      = `Value of a placeholder of the template, as name=value, e.g. attendees="Alice, Bob" (repeatable)`
+	varsFileFlagHelp = "File of values of the template placeholders, one name=value per line (--var overrides it)"
+)
+
+// fillTemplates fills the placeholders of tmpls, like "{{attendees}}", with
+// the values of the --vars-file file and of the --var flags, which override
+// it (see template.Name.WithVars). A --var used by none of tmpls warns: its
+// name is likely misspelled. Values without a template are an error.
+// Returns template.ErrInvalidVar if a value is malformed or missing: a
+// template with placeholders fails without values too.
+func fillTemplates(w io.Writer, tmpls []template.Name, vars []string, varsFile string) ([]template.Name, error) {
+	if (len(vars) > 0 || varsFile != "") && (len(tmpls) == 0 || tmpls[0].IsZero()) {
+		return nil, fmt.Errorf("--var and --vars-file require --template (they fill its placeholders)")
+	}
+	values, names, err := templateVarValues(vars, varsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	filled := make([]template.Name, len(tmpls))
+	var used []string
+	for i, tmpl := range tmpls {
+		if filled[i], err = tmpl.WithVars(values); err != nil {
+			return nil, err
+		}
+		used = append(used, tmpl.Vars()...)
+	}
+	for _, name := range names {
+		if !slices.Contains(used, name) {
+			warnf(w, warnUnusedTemplateVar, "--var %s is not used by the template (no {{%s}} placeholder)", name, name)
+		}
+	}
+	return filled, nil
+}
+
+// templateVarValues returns the values of the template placeholders given by
+// the --vars-file file and the --var flags, which override it, and the names
+// of the --var flags, in order.
+func templateVarValues(vars []string, varsFile string) (map[string]string, []string, error) {
+	values := make(map[string]string)
+	if varsFile != "" {
+		var err error
+		if values, err = template.ReadVars(config.ExpandPath(varsFile)); err != nil {
+			return nil, nil, err
+		}
+	}
+	var names []string
+	for _, v := range vars {
+		name, value, err := template.ParseVar(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("--var %w", err)
+		}
+		values[name] = value
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return values, names, nil
+}
+
+// fillTemplate fills the placeholders of tmpl (see fillTemplates). A zero
+// tmpl is returned unchanged without values.
+func fillTemplate(w io.Writer, tmpl template.Name, vars []string, varsFile string) (template.Name, error) {
+	filled, err := fillTemplates(w, []template.Name{tmpl}, vars, varsFile)
+	if err != nil {
+		return template.Name{}, err
+	}
+	return filled[0], nil
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// writeVarsTemplate writes a user template with the placeholders
+// {{project}} and {{attendees}}, and returns it parsed.
+func writeVarsTemplate(t *testing.T) template.Name {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "standup.md")
+	if err := os.WriteFile(path, []byte("Standup of {{project}} with {{attendees}}."), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	tmpl, err := template.ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() unexpected error: %v", err)
+	}
+	return tmpl
+}
+
+func TestFillTemplates(t *testing.T) {
+	t.Parallel()
+
+	tmpl := writeVarsTemplate(t)
+	varsFile := filepath.Join(t.TempDir(), "vars.txt")
+	if err := os.WriteFile(varsFile, []byte("# Team\nproject=Atlas\nattendees=the team\n"), 0644); err != nil {
+		t.Fatalf("failed to write vars file: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		tmpl       template.Name
+		vars       []string
+		varsFile   string
+		wantPrompt string
+		wantWarn   bool
+		wantErr    error
+		wantMsg    string
+	}{
+		{name: "no values", tmpl: tmpl, wantErr: template.ErrInvalidVar, wantMsg: "project, attendees"},
+		{name: "flags", tmpl: tmpl, vars: []string{"project=Atlas", `attendees="Alice, Bob"`}, wantPrompt: "Standup of Atlas with Alice, Bob."},
+		{name: "file", tmpl: tmpl, varsFile: varsFile, wantPrompt: "Standup of Atlas with the team."},
+		{name: "flag overrides file", tmpl: tmpl, vars: []string{"attendees=Alice"}, varsFile: varsFile, wantPrompt: "Standup of Atlas with Alice."},
+		{name: "unused flag", tmpl: tmpl, vars: []string{"atendees=Alice"}, varsFile: varsFile, wantPrompt: "Standup of Atlas with the team.", wantWarn: true},
+		{name: "missing value", tmpl: tmpl, vars: []string{"project=Atlas"}, wantErr: template.ErrInvalidVar, wantMsg: "attendees"},
+		{name: "malformed flag", tmpl: tmpl, vars: []string{"project"}, wantErr: template.ErrInvalidVar, wantMsg: "--var"},
+		{name: "without template", vars: []string{"project=Atlas"}, wantMsg: "require --template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stderr := &syncBuffer{}
+			got, err := fillTemplate(stderr, tt.tmpl, tt.vars, tt.varsFile)
+			if tt.wantErr != nil || tt.wantMsg != "" {
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("fillTemplate() error = %v, want %v", err, tt.wantErr)
+				}
+				if err == nil || !strings.Contains(err.Error(), tt.wantMsg) {
+					t.Errorf("fillTemplate() error = %v, want %q", err, tt.wantMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fillTemplate() unexpected error: %v", err)
+			}
+			if got.Prompt() != tt.wantPrompt {
+				t.Errorf("fillTemplate().Prompt() = %q, want %q", got.Prompt(), tt.wantPrompt)
+			}
+			if warned := strings.Contains(stderr.String(), warnUnusedTemplateVar); warned != tt.wantWarn {
+				t.Errorf("stderr = %q, want %s warning: %v", stderr.String(), warnUnusedTemplateVar, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestStructureCmd_TemplateVars(t *testing.T) {
+	t.Parallel()
+
+	tmpl := writeVarsTemplate(t)
+	input := createTestTranscriptFile(t, "content")
+	output := filepath.Join(t.TempDir(), "notes.md")
+
+	var prompt string
+	env := echoStructureEnv(&syncBuffer{})
+	env.RestructurerFactory = &mockRestructurerFactory{mockMapReducer: &mockMapReduceRestructurer{
+		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+			prompt = tmpl.Prompt()
+			return "notes", false, nil
+		},
+	}}
+	cmd := StructureCmd(env)
+	cmd.SetArgs([]string{input, "-t", tmpl.Path(), "--var", "project=Atlas", "--var", "attendees=Alice, Bob", "-o", output})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("StructureCmd.Execute() unexpected error: %v", err)
+	}
+	if want := "Standup of Atlas with Alice, Bob."; prompt != want {
+		t.Errorf("template prompt = %q, want %q", prompt, want)
+	}
+
+	cmd = StructureCmd(echoStructureEnv(&syncBuffer{}))
+	cmd.SetArgs([]string{input, "-t", tmpl.Path(), "--var", "project=Atlas", "-o", output + ".2"})
+	if err := cmd.Execute(); !errors.Is(err, template.ErrInvalidVar) {
+		t.Errorf("StructureCmd.Execute() without attendees error = %v, want ErrInvalidVar", err)
+	}
+
+	// Without any value, nothing is sent either, even to print the prompt.
+	cmd = StructureCmd(echoStructureEnv(&syncBuffer{}))
+	cmd.SetOut(&syncBuffer{})
+	cmd.SetArgs([]string{input, "-t", tmpl.Path(), "--show-prompt"})
+	if err := cmd.Execute(); !errors.Is(err, template.ErrInvalidVar) {
+		t.Errorf("StructureCmd.Execute() --show-prompt without values error = %v, want ErrInvalidVar", err)
+	}
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Screen size used when the terminal does not report one.
+const (
+	defaultTerminalWidth  = 80
+	defaultTerminalHeight = 24
+)
+
+// defaultTerminalFactory implements TerminalFactory with the terminal of
+// stdin and stderr, set up with stty.
+type defaultTerminalFactory struct{}
+
+// OpenTerminal switches the terminal of stdin to reading single key presses,
+// without echo. Ctrl+C still sends an interrupt.
+func (defaultTerminalFactory) OpenTerminal() (Terminal, error) {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stderr) {
+		return nil, fmt.Errorf("%w: stdin and stderr must be a terminal", ErrNoTerminal)
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read the terminal settings: %v", ErrNoTerminal, err)
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return nil, fmt.Errorf("%w: cannot set up the terminal: %v", ErrNoTerminal, err)
+	}
+	return &sttyTerminal{saved: strings.TrimSpace(saved)}, nil
+}
+
+// sttyTerminal is the terminal of stdin and stderr, set up by
+// defaultTerminalFactory.
+type sttyTerminal struct {
+	saved string // Settings restored by Close, as printed by stty -g
+}
+
+// Read reads key presses from stdin.
+func (*sttyTerminal) Read(p []byte) (int, error) {
+	return os.Stdin.Read(p)
+}
+
+// Write writes to the screen, through stderr.
+func (*sttyTerminal) Write(p []byte) (int, error) {
+	return os.Stderr.Write(p)
+}
+
+// Size returns the screen size reported by stty, or 80x24.
+func (*sttyTerminal) Size() (width, height int) {
+	out, err := stty("size")
+	if err == nil {
+		_, err = fmt.Sscan(out, &height, &width)
+	}
+	if err != nil || width <= 0 || height <= 0 {
+		return defaultTerminalWidth, defaultTerminalHeight
+	}
+	return width, height
+}
+
+// Close restores the settings the terminal had when opened.
+func (t *sttyTerminal) Close() error {
+	_, err := stty(t.saved)
+	return err
+}
+
+// stty runs stty on the terminal of stdin and returns its output.
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...) // #nosec G204 -- fixed arguments, or settings printed by stty -g
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/format"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// slowestChunks is how many chunks the timing report lists.
+const slowestChunks = 3
+
+// timingReport is where the time of a transcribe run went (--verbose).
+// A nil *timingReport records and prints nothing.
+type timingReport struct {
+	chunks        *transcribe.Timings // Recorded by the chunk functions (see transcribe.WithTimings)
+	chunking      time.Duration       // Cutting the audio into chunks
+	transcription time.Duration       // Transcribing the chunks
+	parallel      int                 // Parallel requests, updated by --parallel auto
+}
+
+// newTimingReport creates the report of a run that took chunking to cut the
+// audio and transcribes with parallel requests.
+func newTimingReport(chunking time.Duration, parallel int) *timingReport {
+	return &timingReport{chunks: transcribe.NewTimings(), chunking: chunking, parallel: parallel}
+}
+
+// printTimingReport writes the bottleneck report of r: time in chunking vs
+// transcription, average API latency, the slowest chunks, and a hint on what
+// to tune. Prints nothing if no chunk was transcribed (all resumed).
+func printTimingReport(w io.Writer, r *timingReport) {
+	if r == nil {
+		return
+	}
+	chunks := r.chunks.Chunks()
+	if len(chunks) == 0 {
+		return
+	}
+
+	total := r.chunking + r.transcription
+	fmt.Fprintln(w, "Timings:")
+	fmt.Fprintf(w, "  Chunking: %s (%d%%), transcription: %s (%d%%)\n",
+		formatSeconds(r.chunking), share(r.chunking, total), formatSeconds(r.transcription), share(r.transcription, total))
+
+	var api, upload time.Duration
+	retries := 0
+	for _, c := range chunks {
+		api += c.API
+		upload += c.Upload
+		retries += c.Retries
+	}
+	n := time.Duration(len(chunks))
+	line := fmt.Sprintf("  Average API latency: %s over %d chunks", formatSeconds(api/n), len(chunks))
+	if upload > 0 {
+		line += fmt.Sprintf(" (upload %s)", formatSeconds(upload/n))
+	}
+	fmt.Fprintf(w, "%s, retries: %d\n", line, retries)
+
+	slowest := slices.Clone(chunks)
+	slices.SortStableFunc(slowest, func(a, b transcribe.ChunkTiming) int { return cmp.Compare(b.API, a.API) })
+	fmt.Fprintln(w, "  Slowest chunks:")
+	for _, c := range slowest[:min(slowestChunks, len(slowest))] {
+		line := fmt.Sprintf("    chunk %d (%s–%s): %s API", c.Chunk.Index,
+			format.Duration(c.Chunk.StartTime), format.Duration(c.Chunk.EndTime), formatSeconds(c.API))
+		if c.Upload > 0 {
+			line += fmt.Sprintf(", %s upload", formatSeconds(c.Upload))
+		}
+		if c.Wait > 0 {
+			line += fmt.Sprintf(", %s rate limited", formatSeconds(c.Wait))
+		}
+		if c.Retries > 0 {
+			line += fmt.Sprintf(", retries: %d", c.Retries)
+		}
+		if c.Chunk.Extraction > 0 {
+			line += fmt.Sprintf(", extracted in %s", formatSeconds(c.Chunk.Extraction))
+		}
+		if c.Failed {
+			line += ", failed"
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	if hint := timingHint(r, chunks); hint != "" {
+		fmt.Fprintf(w, "  Hint: %s\n", hint)
+	}
+}
+
+// timingHint returns what to tune for the bottleneck shown by chunks,
+// or "" if none stands out.
+func timingHint(r *timingReport, chunks []transcribe.ChunkTiming) string {
+	var api, upload, slowest time.Duration
+	retries, slowestIndex := 0, 0
+	for _, c := range chunks {
+		api += c.API
+		upload += c.Upload
+		retries += c.Retries
+		if c.API > slowest {
+			slowest, slowestIndex = c.API, c.Chunk.Index
+		}
+	}
+	average := api / time.Duration(len(chunks))
+
+	switch {
+	case r.chunking > r.transcription:
+		return "chunking takes longer than transcription: --chunker time skips silence detection, " +
+			"and larger chunks (--chunk-size) mean fewer extractions"
+	case retries*4 > len(chunks):
+		return "retries slowed transcription down: a lower --parallel stays under the API rate limits"
+	case upload*2 > api:
+		return "uploads take most of the API latency: the connection is the bottleneck, " +
+			"--parallel auto sizes parallelism to it"
+	case len(chunks) > 1 && slowest > 2*average:
+		return fmt.Sprintf("chunk %d takes over twice the average: smaller chunks (--chunk-size) "+
+			"spread the work more evenly across parallel requests", slowestIndex)
+	case r.parallel < transcribe.MaxRecommendedParallel && len(chunks) > r.parallel:
+		return fmt.Sprintf("chunks waited for one of the %d parallel requests: a higher --parallel (up to %d) sends more at once",
+			r.parallel, transcribe.MaxRecommendedParallel)
+	}
+	return ""
+}
+
+// formatSeconds formats d in seconds, to the tenth.
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// share returns the share of d in total, in percent.
+func share(d, total time.Duration) int {
+	if total <= 0 {
+		return 0
+	}
+	return int(100 * d / total)
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// timedChunk returns the timing of chunk index, cut every 5 minutes.
+func timedChunk(index int, api, upload time.Duration, retries int) transcribe.ChunkTiming {
+	return transcribe.ChunkTiming{
+		Chunk: audio.Chunk{
+			Index:     index,
+			StartTime: time.Duration(index) * 5 * time.Minute,
+			EndTime:   time.Duration(index+1) * 5 * time.Minute,
+		},
+		API:     api,
+		Upload:  upload,
+		Retries: retries,
+	}
+}
+
+func TestTimingHint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		report   timingReport
+		chunks   []transcribe.ChunkTiming
+		contains string
+	}{
+		{
+			name:     "chunking dominates",
+			report:   timingReport{chunking: time.Minute, transcription: 30 * time.Second, parallel: 10},
+			chunks:   []transcribe.ChunkTiming{timedChunk(0, 10*time.Second, 0, 0)},
+			contains: "--chunker time",
+		},
+		{
+			name:   "many retries",
+			report: timingReport{chunking: time.Second, transcription: time.Minute, parallel: 10},
+			chunks: []transcribe.ChunkTiming{
+				timedChunk(0, 10*time.Second, 0, 2),
+				timedChunk(1, 10*time.Second, 0, 0),
+			},
+			contains: "lower --parallel",
+		},
+		{
+			name:   "slow uploads",
+			report: timingReport{chunking: time.Second, transcription: time.Minute, parallel: 10},
+			chunks: []transcribe.ChunkTiming{
+				timedChunk(0, 10*time.Second, 8*time.Second, 0),
+				timedChunk(1, 10*time.Second, 7*time.Second, 0),
+			},
+			contains: "--parallel auto",
+		},
+		{
+			name:   "one slow chunk",
+			report: timingReport{chunking: time.Second, transcription: time.Minute, parallel: 10},
+			chunks: []transcribe.ChunkTiming{
+				timedChunk(0, 5*time.Second, 0, 0),
+				timedChunk(1, 5*time.Second, 0, 0),
+				timedChunk(2, 40*time.Second, 0, 0),
+			},
+			contains: "chunk 2 takes over twice the average",
+		},
+		{
+			name:   "not enough parallel requests",
+			report: timingReport{chunking: time.Second, transcription: time.Minute, parallel: 2},
+			chunks: []transcribe.ChunkTiming{
+				timedChunk(0, 10*time.Second, 0, 0),
+				timedChunk(1, 10*time.Second, 0, 0),
+				timedChunk(2, 10*time.Second, 0, 0),
+			},
+			contains: "higher --parallel",
+		},
+		{
+			name:   "no bottleneck",
+			report: timingReport{chunking: time.Second, transcription: time.Minute, parallel: 10},
+			chunks: []transcribe.ChunkTiming{
+				timedChunk(0, 10*time.Second, time.Second, 0),
+				timedChunk(1, 12*time.Second, time.Second, 0),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := timingHint(&tt.report, tt.chunks)
+			if tt.contains == "" {
+				if got != "" {
+					t.Errorf("timingHint() = %q, want no hint", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.contains) {
+				t.Errorf("timingHint() = %q, want containing %q", got, tt.contains)
+			}
+		})
+	}
+}
+
+func TestPrintTimingReport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("report", func(t *testing.T) {
+		t.Parallel()
+
+		r := newTimingReport(10*time.Second, 10)
+		r.transcription = 30 * time.Second
+		chunks := []audio.Chunk{
+			{Path: "/tmp/chunk_0.ogg", Index: 0, EndTime: 5 * time.Minute, Extraction: 2 * time.Second},
+			{Path: "/tmp/chunk_1.ogg", Index: 1, StartTime: 5 * time.Minute, EndTime: 10 * time.Minute},
+		}
+		tr := &mockTranscriber{TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "Hello.", nil
+		}}
+		if _, err := transcribe.TranscribeRemaining(context.Background(), chunks, tr, transcribe.Options{}, 1, nil, nil, transcribe.WithTimings(r.chunks)); err != nil {
+			t.Fatalf("TranscribeRemaining() unexpected error: %v", err)
+		}
+
+		var out strings.Builder
+		printTimingReport(&out, r)
+		for _, want := range []string{
+			"Chunking: 10.0s (25%), transcription: 30.0s (75%)",
+			"over 2 chunks, retries: 0",
+			"chunk 0 (00:00–05:00)",
+			"extracted in 2.0s",
+			"chunk 1 (05:00–10:00)",
+		} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("report = %q, want containing %q", out.String(), want)
+			}
+		}
+	})
+
+	t.Run("nothing transcribed", func(t *testing.T) {
+		t.Parallel()
+
+		var out strings.Builder
+		printTimingReport(&out, newTimingReport(time.Second, 10))
+		printTimingReport(&out, nil)
+		if out.String() != "" {
+			t.Errorf("report = %q, want empty", out.String())
+		}
+	})
+}
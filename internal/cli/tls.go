@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"crypto/tls"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/ffmpeg"
+	"github.com/alnah/go-transcript/internal/tlsconfig"
+)
+
+// ConfigureTLS applies the TLS settings of the configuration (ca-bundle,
+// client-cert, client-key) to every outbound HTTPS connection: transcription
+// and restructuring APIs, and FFmpeg downloads. It must run before any command.
+func ConfigureTLS(env *Env) {
+	cfg := loadTLSConfig(env)
+	tlsconfig.Install(cfg)
+	ffmpeg.SetTLSConfig(cfg)
+}
+
+// loadTLSConfig returns the TLS configuration of the config file and
+// environment, or nil for the system defaults. Invalid settings are reported
+// and ignored rather than failing, so that they can still be fixed with the
+// config command.
+func loadTLSConfig(env *Env) *tls.Config {
+	cfg, _ := env.ConfigLoader.Load() // Load errors are reported by the commands.
+	tlsCfg, err := tlsOptions(cfg).Config()
+	if err != nil {
+		warnf(env.Stderr, warnInvalidSetting, "ignoring TLS settings: %v", err)
+		return nil
+	}
+	return tlsCfg
+}
+
+// tlsOptions returns the TLS settings of cfg.
+func tlsOptions(cfg config.Config) tlsconfig.Options {
+	return tlsconfig.Options{
+		CABundle:   cfg.CABundle,
+		ClientCert: cfg.ClientCert,
+		ClientKey:  cfg.ClientKey,
+	}
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/config"
+)
+
+// ---------------------------------------------------------------------------
+// Tests for loadTLSConfig
+// ---------------------------------------------------------------------------
+
+func TestLoadTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+
+	tests := []struct {
+		name        string
+		cfg         config.Config
+		wantWarning bool
+	}{
+		{"not configured", config.Config{}, false},
+		{"invalid CA bundle", config.Config{CABundle: missing}, true},
+		{"certificate without key", config.Config{ClientCert: missing}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stderr := &syncBuffer{}
+			env := &Env{
+				Stderr:       stderr,
+				ConfigLoader: &mockConfigLoader{LoadFunc: func() (config.Config, error) { return tt.cfg, nil }},
+			}
+
+			if got := loadTLSConfig(env); got != nil {
+				t.Errorf("loadTLSConfig() = %v, want nil", got)
+			}
+			if warned := strings.Contains(stderr.String(), "ignoring TLS settings"); warned != tt.wantWarning {
+				t.Errorf("loadTLSConfig() stderr = %q, want warning = %v", stderr.String(), tt.wantWarning)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// Speakers of the tracks of a --separate-tracks recording: the microphone
+// is the user, the system audio everyone else on the call.
+const (
+	micSpeaker    = "Me"
+	systemSpeaker = "Remote"
+)
+
+// Help of the --separate-tracks flag of the record and live commands.
+const (
+	recordSeparateTracksFlagHelp = "With --mix, also write the microphone and system audio to <output>.mic.ogg and <output>.system.ogg"
+	liveSeparateTracksFlagHelp   = "With --mix, transcribe the microphone and system audio separately, as Me and Remote"
+)
+
+// validateSeparateTracks returns a UsageError if --separate-tracks is set
+// without --mix: there is only one input to separate.
+func validateSeparateTracks(separate, mix bool) error {
+	if separate && !mix {
+		return &UsageError{Err: fmt.Errorf("--separate-tracks requires --mix")}
+	}
+	return nil
+}
+
+// trackPaths returns the files of the microphone and system audio tracks of
+// the recording at path: "call.ogg" -> "call.mic.ogg", "call.system.ogg".
+func trackPaths(path string) (mic, system string) {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	return base + ".mic.ogg", base + ".system.ogg"
+}
+
+// separateTracks makes recorder, a --mix recorder, also write the tracks of
+// its recording to output to their own file (see trackPaths). It does nothing
+// unless separate is set.
+func separateTracks(recorder audio.Recorder, separate bool, output string) error {
+	if !separate {
+		return nil
+	}
+	separator, ok := recorder.(audio.TrackSeparator)
+	if !ok {
+		return fmt.Errorf("recorder does not support --separate-tracks")
+	}
+	separator.SeparateTracks(trackPaths(output))
+	return nil
+}
+
+// liveTrack is a track of a --separate-tracks recording, transcribed on its
+// own and attributed to speaker.
+type liveTrack struct {
+	path    string
+	speaker string
+}
+
+// liveTranscribeTracks transcribes the tracks of the recording at audioPath
+// separately, with timestamps, and renders their segments merged by start
+// time, each attributed to the speaker of its track: as paragraphs labeled
+// "Speaker: " in md and txt (see format.Dialogue), or as the timestamps,
+// timed template and subtitles of other transcripts.
+func liveTranscribeTracks(ctx context.Context, env *Env, lctx *liveContext, opts liveOptions, audioPath string) (string, error) {
+	mic, system := trackPaths(audioPath)
+	var (
+		cues    []format.Cue
+		results []string // Of both tracks, for the vocabulary of --tag
+	)
+	for _, track := range []liveTrack{{mic, micSpeaker}, {system, systemSpeaker}} {
+		fmt.Fprintf(env.Stderr, "Track %s: %s\n", track.speaker, track.path)
+		trackCues, trackResults, err := transcribeTrack(ctx, env, lctx, opts, track)
+		if err != nil {
+			return "", err
+		}
+		cues = append(cues, trackCues...)
+		results = append(results, trackResults...)
+	}
+	// Stable: said at the same time, the microphone comes first
+	slices.SortStableFunc(cues, func(a, b format.Cue) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+
+	lctx.stats = statsCues(opts.stats, cues)
+	lctx.unclear = unclearCues(env.Stderr, opts.unclear, opts.format, cues)
+	transcript := renderCuesHighlighted(renderedHighlights(opts.format, lctx.highlights), cues,
+		func(cues []format.Cue) string {
+			switch {
+			case opts.template.Timed():
+				return format.Timed(cues, timedPause)
+			case !opts.timestamps.IsZero():
+				return format.Marked(cues, opts.timestamps.interval, opts.format.OrDefault() == MarkdownFormat)
+			case opts.format == VTTFormat:
+				opts.speakerLabels.apply(cues)
+				return format.VTT(cues)
+			case opts.format == SRTFormat:
+				opts.speakerLabels.apply(cues)
+				return format.SRT(cues)
+			default:
+				return format.Dialogue(cues)
+			}
+		})
+	fmt.Fprintln(env.Stderr, "Transcription complete")
+	lctx.vocabulary.record(env, results, true)
+	return transcript, nil
+}
+
+// transcribeTrack transcribes track with timestamps, returning its cues
+// relative to the start of the recording, and the results they come from.
+func transcribeTrack(ctx context.Context, env *Env, lctx *liveContext, opts liveOptions, track liveTrack) ([]format.Cue, []string, error) {
+	chunks, results, cleanup, err := liveTranscribeChunks(ctx, env, lctx, opts, track.path, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cleanup()
+
+	cues, err := mergeCues(chunks, results)
+	if err != nil {
+		return nil, nil, fmt.Errorf("track %s: %w", track.speaker, err)
+	}
+	for i := range cues {
+		cues[i].Speaker = track.speaker
+	}
+	return cues, results, nil
+}
+
+// renderCuesHighlighted renders cues with render, with the markers following
+// the cues started before them, as paragraphs (see renderHighlighted).
+func renderCuesHighlighted(marks []time.Duration, cues []format.Cue, render func([]format.Cue) string) string {
+	var (
+		parts []string
+		from  int // First cue not rendered yet
+	)
+	add := func(to int) {
+		if text := strings.TrimRight(render(cues[from:to]), "\n"); text != "" {
+			parts = append(parts, text)
+		}
+		from = to
+	}
+	for _, m := range marks {
+		to := from
+		for to < len(cues) && cues[to].Start < m {
+			to++
+		}
+		add(to)
+		parts = append(parts, format.Highlight(m))
+	}
+	add(len(cues))
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestTrackPaths(t *testing.T) {
+	t.Parallel()
+
+	mic, system := trackPaths(filepath.Join("calls", "standup.ogg"))
+	if want := filepath.Join("calls", "standup.mic.ogg"); mic != want {
+		t.Errorf("trackPaths() mic = %q, want %q", mic, want)
+	}
+	if want := filepath.Join("calls", "standup.system.ogg"); system != want {
+		t.Errorf("trackPaths() system = %q, want %q", system, want)
+	}
+}
+
+func TestValidateSeparateTracks(t *testing.T) {
+	t.Parallel()
+
+	if err := validateSeparateTracks(true, false); !errors.Is(err, ErrUsage) {
+		t.Errorf("validateSeparateTracks(without --mix) error = %v, want ErrUsage", err)
+	}
+	if err := validateSeparateTracks(true, true); err != nil {
+		t.Errorf("validateSeparateTracks(--mix) unexpected error: %v", err)
+	}
+	if err := validateSeparateTracks(false, false); err != nil {
+		t.Errorf("validateSeparateTracks(unset) unexpected error: %v", err)
+	}
+}
+
+func TestRunRecord_SeparateTracks(t *testing.T) {
+	t.Parallel()
+
+	recorder := &mockRecorder{
+		RecordFunc: func(ctx context.Context, duration time.Duration, output string) error {
+			return os.WriteFile(output, []byte("audio"), 0600)
+		},
+	}
+	stderr := &syncBuffer{}
+	env := &Env{
+		Stderr:              stderr,
+		Getenv:              func(string) string { return "" },
+		Now:                 fixedTime(time.Now()),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        &mockConfigLoader{},
+		RecorderFactory:     &mockRecorderFactory{mockRecorder: recorder},
+		DeviceListerFactory: &mockDeviceListerFactory{},
+	}
+
+	output := filepath.Join(t.TempDir(), "call.ogg")
+	opts := recordOptions{duration: time.Minute, output: output, mix: true, tracks: true}
+	if err := RunRecord(context.Background(), env, opts); err != nil {
+		t.Fatalf("RunRecord() unexpected error: %v", err)
+	}
+	wantMic, wantSystem := trackPaths(output)
+	if mic, system := recorder.Tracks(); mic != wantMic || system != wantSystem {
+		t.Errorf("recorder tracks = %q, %q, want %q, %q", mic, system, wantMic, wantSystem)
+	}
+	if !strings.Contains(stderr.String(), "Tracks: "+wantMic+", "+wantSystem) {
+		t.Errorf("stderr = %q, want the tracks listed", stderr.String())
+	}
+
+	// A track left by an earlier recording is not overwritten
+	if err := os.Remove(output); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(wantSystem, []byte("earlier"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunRecord(context.Background(), env, opts); !errors.Is(err, ErrOutputExists) {
+		t.Errorf("RunRecord() error = %v, want ErrOutputExists", err)
+	}
+}
+
+func TestLiveTranscribeTracks(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	audioPath := filepath.Join(dir, "recording.ogg")
+	mic, system := trackPaths(audioPath)
+	for _, path := range []string{audioPath, mic, system} {
+		if err := os.WriteFile(path, []byte("audio"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each track is chunked into a copy, named after it
+	chunker := &mockChunker{
+		ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+			chunkDir, err := os.MkdirTemp(dir, "chunks-*")
+			if err != nil {
+				return nil, err
+			}
+			path := filepath.Join(chunkDir, filepath.Base(audioPath))
+			if err := os.WriteFile(path, []byte("chunk"), 0600); err != nil {
+				return nil, err
+			}
+			return []audio.Chunk{{Path: path, EndTime: time.Minute}}, nil
+		},
+	}
+	transcriber := &mockTranscriber{
+		TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			if !opts.Timestamps {
+				return "", errors.New("timestamps not requested")
+			}
+			if strings.HasSuffix(audioPath, ".mic.ogg") {
+				return `[{"start":0,"end":2,"text":"Hi."},{"start":2,"end":4,"text":"Can you hear me?"},{"start":9,"end":10,"text":"Great."}]`, nil
+			}
+			return `[{"start":4.5,"end":6,"text":"Yes."},{"start":6,"end":8,"text":"Loud and clear."}]`, nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		opts       liveOptions
+		highlights []time.Duration
+		want       string
+	}{
+		{
+			name: "dialogue",
+			want: "Me: Hi. Can you hear me?\n\nRemote: Yes. Loud and clear.\n\nMe: Great.",
+		},
+		{
+			name:       "dialogue with highlights",
+			highlights: []time.Duration{5 * time.Second},
+			want:       "Me: Hi. Can you hear me?\n\nRemote: Yes.\n\n⭐ 00:00:05\n\nRemote: Loud and clear.\n\nMe: Great.",
+		},
+		{
+			name: "dialogue with statistics",
+			opts: liveOptions{stats: StatsMarkdown},
+			want: "Me: Hi. Can you hear me?\n\nRemote: Yes. Loud and clear.\n\nMe: Great.",
+		},
+		{
+			name: "subtitles",
+			opts: liveOptions{format: SRTFormat},
+			want: "1\n00:00:00,000 --> 00:00:02,000\n[Me] Hi.\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := &Env{
+				Stderr:         &syncBuffer{},
+				ChunkerFactory: &mockChunkerFactory{NewSilenceChunkerFunc: func(string) (audio.Chunker, error) { return chunker, nil }},
+			}
+			lctx := &liveContext{
+				transcriber: transcriber,
+				ffmpegPath:  "ffmpeg",
+				parallel:    1,
+				report:      newRunReport("live", "", ""),
+				highlights:  tt.highlights,
+			}
+			opts := tt.opts
+			opts.separateTracks = true
+
+			got, err := liveTranscribePhase(context.Background(), env, lctx, opts, audioPath)
+			if err != nil {
+				t.Fatalf("liveTranscribePhase() unexpected error: %v", err)
+			}
+			switch stats := lctx.stats; {
+			case opts.stats == "" && stats != nil:
+				t.Errorf("statistics = %+v, want none without --stats", stats)
+			case opts.stats != "" && (stats == nil || len(stats.Speakers) != 2 || stats.Speakers[0].Name != micSpeaker || stats.Speakers[0].Turns != 2):
+				t.Errorf("statistics = %+v, want Me first, with 2 turns", stats)
+			}
+			if opts.format.IsSubtitle() {
+				if !strings.HasPrefix(got, tt.want) || !strings.Contains(got, "[Remote] Yes.") {
+					t.Errorf("liveTranscribePhase() = %q, want subtitles starting with %q", got, tt.want)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("liveTranscribePhase() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
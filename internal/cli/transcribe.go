@@ -1,22 +1,35 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/alnah/go-transcript/internal/audio"
 	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/fetch"
+	"github.com/alnah/go-transcript/internal/format"
 	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/notes"
+	"github.com/alnah/go-transcript/internal/progress"
 	"github.com/alnah/go-transcript/internal/template"
 	"github.com/alnah/go-transcript/internal/transcribe"
+	"github.com/alnah/go-transcript/internal/vocab"
 )
 
-// supportedFormats lists audio formats accepted by OpenAI's transcription API.
+// supportedFormats lists audio formats accepted by OpenAI's transcription API,
+// video containers whose audio track is extracted first (see audio.IsVideo),
+// and lists of segmented recordings, whose files are joined first
+// (see audio.IsSegmentList). Files of other formats are transcribed when
+// FFmpeg decodes them (see probeInput): the list picks files in directories.
 // Source: https://platform.openai.com/docs/guides/speech-to-text
 var supportedFormats = map[string]bool{
 	".ogg":  true,
@@ -28,6 +41,10 @@ var supportedFormats = map[string]bool{
 	".mpeg": true,
 	".mpga": true,
 	".webm": true,
+	".mkv":  true,
+	".mov":  true,
+
+	audio.SegmentListExt: true,
 }
 
 // supportedFormatsList returns a sorted, comma-separated list for error messages.
@@ -52,26 +69,137 @@ func clampParallel(n int) int {
 	return n
 }
 
+// parallelAuto is the --parallel value selecting adaptive parallelism.
+const parallelAuto = "auto"
+
+// defaultParallel is the default --parallel value: none, the parallelism is
+// chosen once the chunks are known (see resolveParallel).
+const defaultParallel = ""
+
+// parseParallel parses a --parallel value: a number of concurrent requests,
+// or "auto" to size concurrency from the upload throughput measured on the
+// first chunk. Numbers are clamped later (see clampParallel).
+func parseParallel(s string) (n int, auto bool, err error) {
+	if strings.EqualFold(strings.TrimSpace(s), parallelAuto) {
+		return transcribe.MaxRecommendedParallel, true, nil
+	}
+	n, err = strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: %q (expected 1-%d or %s)",
+			ErrInvalidParallel, s, transcribe.MaxRecommendedParallel, parallelAuto)
+	}
+	return n, false, nil
+}
+
+// parseParallelFlag parses the --parallel flag of cmd, clamped (see
+// clampParallel). Returns 0 if the flag is not given: the parallelism is then
+// chosen from the chunks (see resolveParallel).
+func parseParallelFlag(cmd *cobra.Command, value string) (n int, auto bool, err error) {
+	if !cmd.Flags().Changed("parallel") {
+		return 0, false, nil
+	}
+	n, auto, err = parseParallel(value)
+	return clampParallel(n), auto, err
+}
+
+// resolveParallel returns parallel, or if it is zero (--parallel not given)
+// the default parallelism for chunks and the rate limits (rate-limit-requests
+// and rate-limit-audio, zero: unlimited), reported to w. Chunks may be nil
+// when unknown yet.
+func resolveParallel(w io.Writer, parallel int, chunks []audio.Chunk, requestsPerMinute, audioPerMinute int) int {
+	if parallel != 0 {
+		return clampParallel(parallel)
+	}
+	n, limit := transcribe.DefaultParallel(chunks, requestsPerMinute, time.Duration(audioPerMinute)*time.Second)
+	var reason string
+	switch limit {
+	case transcribe.LimitChunks:
+		reason = "one per chunk"
+	case transcribe.LimitRequestRate:
+		reason = fmt.Sprintf("%s %d/min", config.KeyRateLimitRequests, requestsPerMinute)
+	case transcribe.LimitAudioRate:
+		reason = fmt.Sprintf("%s %ds/min", config.KeyRateLimitAudio, audioPerMinute)
+	case transcribe.LimitChunkSize:
+		reason = "large chunks, --parallel auto measures the connection"
+	default:
+		reason = "maximum"
+	}
+	fmt.Fprintf(w, "Using %d parallel requests (%s)\n", n, reason)
+	return n
+}
+
+// printAutoParallel reports the parallelism chosen by --parallel auto.
+func printAutoParallel(w io.Writer, parallel int, bytesPerSecond float64) {
+	if bytesPerSecond <= 0 {
+		fmt.Fprintf(w, "Upload throughput unknown, using %d parallel requests\n", parallel)
+		return
+	}
+	fmt.Fprintf(w, "Upload throughput ~%.0f KB/s, using %d parallel requests\n", bytesPerSecond/1024, parallel)
+}
+
 // transcribeOptions holds validated options for the transcribe command.
 type transcribeOptions struct {
-	inputPath  string
-	output     string
-	template   template.Name
-	diarize    bool
-	parallel   int
-	language   lang.Language
-	outputLang lang.Language
-	provider   Provider
+	inputPath       string
+	output          string
+	template        template.Name
+	diarize         bool
+	parallel        int
+	language        lang.Language
+	outputLang      lang.Language
+	provider        Provider
+	resume          bool                // Reuse chunks from a previous run's checkpoint (--resume)
+	allowPartial    bool                // Write the output with markers for chunks that keep failing (--allow-partial)
+	auto            bool                // Size parallelism from measured upload throughput (--parallel auto)
+	sessionDir      string              // Write all artifacts into a session directory here (--session-dir)
+	backend         Backend             // Transcription backend (--transcriber); zero means configured or OpenAI
+	format          OutputFormat        // Output format (--format); zero means Markdown
+	speakerLabels   SpeakerLabels       // Speakers in subtitles (--speaker-labels); zero means the format default
+	timestamps      Timestamps          // Time markers in md and txt transcripts (--timestamps); zero means none
+	tag             string              // Session tag whose vocabulary biases transcription (--tag)
+	introOutro      IntroOutroMode      // Skip or mark intros and outros of earlier recordings (--intro-outro)
+	model           string              // Restructure model (--restructure-model); empty means configured or provider default
+	dryRun          bool                // Print the chunks and estimated cost without calling any API (--dry-run)
+	costReport      string              // Append the usage and cost of the run to this file (--cost-report)
+	selfConsistency int                 // Restructurings merged into the output (--self-consistency); <= 1 disables it
+	maxCost         float64             // Max estimated cost of self-consistency, in US dollars (--max-cost)
+	chunking        chunking            // Chunking strategy and chunk size (--chunker, --chunk-size)
+	provenance      *provenanceStamp    // Provenance footer and signature (--provenance, --sign-key); nil disables them
+	verbose         bool                // Print per-chunk timings and a bottleneck report (--verbose)
+	maxDownload     int64               // Size limit of a URL input, in bytes (--max-download); 0 means the default
+	notifyWebhook   string              // POST a notification here when the run finishes (--notify-webhook); empty means configured
+	notify          bool                // Show a desktop notification when the run finishes (--notify)
+	profile         string              // Config profile whose output-dir applies (--profile, TRANSCRIPT_PROFILE)
+	grpcEndpoint    string              // ASR server of the grpc transcriber (--grpc-endpoint); empty means configured
+	translateAudio  bool                // Translate the speech to English instead of transcribing it (--translate-audio)
+	glossary        string              // File of terms put in the prompts (--glossary); empty means configured
+	clean           bool                // Remove fillers and repeated words, normalize punctuation (--clean)
+	redact          bool                // Mask emails, phone and card numbers (--redact)
+	obsidianVault   string              // Write the output into this Obsidian vault (--obsidian-vault); empty means configured
+	stdout          bool                // Print the output to stdout instead of a file (--stdout, -o -)
+	redactPatterns  []string            // Other text masked (--redact-pattern); implies redact
+	preprocessing   audio.Preprocessing // Audio cleanup before chunking (--denoise, --normalize)
+	chunkTokens     int                 // Size of the parts of long transcripts (--restructure-chunk-tokens); 0 means configured
+	overlap         int                 // Tokens repeated between parts (--restructure-overlap); 0 means configured
+	reduceLevels    int                 // Max levels merging the parts (--max-reduce-levels); 0 means the default
+	stream          bool                // Write the restructured output as it is generated (--stream-restructure)
+	fallback        Provider            // Restructure with this provider if --provider fails (--fallback-provider); zero means none
+	keepRaw         bool                // Keep the raw transcript before restructuring (--keep-raw-transcript, -r)
+	rawOutput       string              // Where the raw transcript is kept (--raw-output); empty means <output>.raw.md
+	stats           string              // Speaker statistics of diarized transcripts (--stats): md, json; empty means none
+	unclear         float64             // List the passages transcribed below this confidence (--unclear); 0 means none
+	actions         string              // Action items written next to the output (--actions): json, csv; empty means none
+	outputMode      outputMode          // Replace (--force) or append to (--append) an existing output file
 }
 
 // parseTranscribeOptions validates and parses CLI inputs into transcribeOptions.
-// All parsing happens at the CLI boundary.
-func parseTranscribeOptions(inputPath, output, tmpl string, diarize bool, parallel int, language, outputLang, provider string) (transcribeOptions, error) {
+// All parsing happens at the CLI boundary. templatesDir holds the user
+// templates selectable by name; empty allows only built-in names and paths.
+func parseTranscribeOptions(inputPath, output, tmpl string, diarize bool, parallel int, language, outputLang, provider, templatesDir string) (transcribeOptions, error) {
 	// Parse template (optional for transcribe - empty means raw transcript)
 	var parsedTemplate template.Name
 	var err error
 	if tmpl != "" {
-		parsedTemplate, err = template.ParseName(tmpl)
+		parsedTemplate, err = template.Resolve(tmpl, templatesDir)
 		if err != nil {
 			return transcribeOptions{}, err
 		}
@@ -108,72 +236,641 @@ func parseTranscribeOptions(inputPath, output, tmpl string, diarize bool, parall
 	}, nil
 }
 
-// deriveOutputPath converts an audio file path to a markdown output path.
-// Example: "session.ogg" -> "session.md"
-func deriveOutputPath(inputPath string) string {
+// checkpointPath returns the path of the checkpoint file for an output path.
+// The checkpoint is a hidden file next to the output, named after it so that
+// several transcriptions can share an output directory.
+// Example: "notes/session.md" -> "notes/.session.md.transcript-state.json"
+func checkpointPath(output string) string {
+	return filepath.Join(filepath.Dir(output), "."+filepath.Base(output)+".transcript-state.json")
+}
+
+// startTranscribeSession creates the session directory of a transcription, or
+// with --resume, reopens the most recent unfinished session for the same input.
+func startTranscribeSession(env *Env, opts transcribeOptions) (*session, error) {
+	input, err := filepath.Abs(opts.inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve input path: %w", err)
+	}
+	parent := config.ExpandPath(opts.sessionDir)
+
+	if dir := findResumableSession(parent, "transcribe", input); dir != "" {
+		if opts.resume {
+			fmt.Fprintf(env.Stderr, "Resuming session: %s\n", dir)
+			return openSession(dir, env.Now)
+		}
+		warnf(env.Stderr, warnUnfinishedSession, "unfinished session %s for this input (use --resume to continue it)", dir)
+	}
+
+	meta := sessionMetadata{
+		Command:    "transcribe",
+		Input:      input,
+		Template:   opts.template.String(),
+		Language:   opts.language.String(),
+		Translate:  opts.outputLang.String(),
+		Diarize:    opts.diarize,
+		Format:     opts.format.String(),
+		Tag:        opts.tag,
+		IntroOutro: opts.introOutro.String(),
+	}
+	if !opts.template.IsZero() {
+		meta.Provider = opts.provider.OrDefault().String()
+	}
+	name := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+	return newSession(parent, name, meta, env.Now)
+}
+
+// outputLanguage returns the language of the output: --output-lang once
+// restructured, else English for a translated transcript (--translate), else
+// the audio language.
+func (opts transcribeOptions) outputLanguage() lang.Language {
+	switch {
+	case !opts.template.IsZero() && !opts.outputLang.IsZero():
+		return opts.outputLang
+	case opts.translateAudio:
+		return lang.MustParse("en")
+	default:
+		return opts.language
+	}
+}
+
+// keptRawPath returns where the raw transcript of output is kept with
+// --keep-raw-transcript: the --raw-output file, or <output>.raw.md, where an
+// interrupted restructuring saves it too. Empty without the flag.
+func (opts transcribeOptions) keptRawPath(output string) string {
+	switch {
+	case !opts.keepRaw:
+		return ""
+	case opts.rawOutput != "":
+		return opts.rawOutput
+	default:
+		return rawTranscriptPath(output)
+	}
+}
+
+// nameFields returns the fields of the output-name pattern for a run
+// started at started (the input stem is added by outputNamer).
+func (opts transcribeOptions) nameFields(started time.Time) notes.NameFields {
+	return notes.NameFields{
+		Date:     started,
+		Template: opts.template.String(),
+		Language: opts.outputLanguage().String(),
+	}
+}
+
+// deriveOutputPath converts an audio file path to an output path in format f.
+// Example: "session.ogg" -> "session.md" (or "session.srt" with --format srt)
+func deriveOutputPath(inputPath string, f OutputFormat) string {
 	ext := filepath.Ext(inputPath)
-	return strings.TrimSuffix(inputPath, ext) + ".md"
+	return strings.TrimSuffix(inputPath, ext) + f.Extension()
 }
 
 // TranscribeCmd creates the transcribe command.
 // The env parameter provides injectable dependencies for testing.
 func TranscribeCmd(env *Env) *cobra.Command {
 	var (
-		output     string
-		tmpl       string
-		diarize    bool
-		parallel   int
-		language   string
-		outputLang string
-		provider   string
+		output          string
+		tmpl            string
+		diarize         bool
+		parallel        string
+		language        string
+		outputLang      string
+		provider        string
+		model           string
+		resume          bool
+		allowPartial    bool
+		sessionDir      string
+		obsidianVault   string
+		stdout          bool
+		backend         string
+		outFormat       string
+		tag             string
+		introOutro      string
+		speakerLabels   string
+		timestamps      string
+		stats           string
+		unclear         string
+		actions         string
+		anchors         bool
+		vars            []string
+		varsFile        string
+		summaryLevel    string
+		recursive       bool
+		jobs            int
+		dryRun          bool
+		costReport      string
+		selfConsistency int
+		maxCost         float64
+		progressFmt     string
+		chunker         string
+		chunkSize       string
+		withProvenance  bool
+		signKey         string
+		verbose         bool
+		maxDownload     string
+		notifyWebhook   string
+		notify          bool
+		profile         string
+		grpcEndpoint    string
+		translateAudio  bool
+		glossary        string
+		clean           bool
+		redact          bool
+		redactPatterns  []string
+		denoise         bool
+		normalize       bool
+		chunkTokens     int
+		overlap         int
+		reduceLevels    int
+		stream          bool
+		fallback        string
+		keepRaw         bool
+		rawOutput       string
+		force           bool
+		appendOutput    bool
 	)
 
 	cmd := &cobra.Command{
-		Use:   "transcribe <audio-file>",
-		Short: "Transcribe an audio file",
-		Long: `Transcribe an audio file using OpenAI's transcription API.
+		Use:     "transcribe <audio-file|url|directory>...",
+		Aliases: []string{"t"},
+		Short:   "Transcribe audio files",
+		Long: `Transcribe audio files using OpenAI's transcription API.
 
 The audio is split into chunks at natural silence points, transcribed in parallel,
 and optionally restructured using a template.
 
-Transcription always uses OpenAI. Restructuring (--template) uses DeepSeek by default,
-or OpenAI with --provider openai.
+Transcription uses OpenAI by default. With --transcriber local (or the transcriber
+config key), it runs offline with whisper.cpp and the model set by whisper-model,
+or through a local whisper-compatible server set by whisper-url. Restructuring
+(--template) uses DeepSeek by default, or OpenAI with --provider openai, or a
+local Ollama server with --provider ollama: both steps local means fully offline.
+--transcriber groq, deepgram or assemblyai uses another speech-to-text API, with
+its key in GROQ_API_KEY, DEEPGRAM_API_KEY or ASSEMBLYAI_API_KEY (groq cannot
+identify speakers with --diarize).
+
+--fallback-provider restructures again with another provider, and its default
+model, when --provider fails with an exhausted quota or a rejected key: the
+transcript is kept, only the restructuring is redone.
 
-Supported formats: ogg, mp3, wav, m4a, flac, mp4, mpeg, mpga, webm`,
+--translate-audio translates the speech to English while transcribing, with the
+translations endpoint (openai, groq or local transcriber): an English transcript
+of any recording, without a template.
+
+--clean removes hesitations ("um", "euh"), filler words set off by commas
+("you know", "tu vois") and repeated words, and normalizes spacing and
+punctuation, locally, before the transcript is written or restructured. Add
+fillers per language with the clean-fillers config key (fr=du coup,en=basically).
+
+--redact masks email addresses, phone numbers and payment card numbers as
+[email], [phone] and [card], locally, after --clean: they are neither written
+nor sent to the restructure provider. --redact-pattern masks other text, like
+employee IDs, as [redacted]; list patterns in the redact-patterns config file to
+use them in every run. No --resume checkpoint is saved, as it would keep the
+text unredacted on disk.
+
+--denoise reduces background noise (hum, hiss, fans, traffic) and --normalize
+brings the loudness to a steady level, with FFmpeg filters run before chunking:
+worth it for noisy rooms and faint speakers, at the cost of one more pass.
+
+An existing output file is an error (TR-0404). --force replaces it, keeping
+the previous version in the .trash directory next to it ('transcript undo'
+restores it). --append adds the output at the end of the file, after a
+separator with the date and time, so that repeated sessions accumulate into
+one notes file (md and txt only).
+
+--stdout (or -o -) prints the output to stdout instead, for shell pipelines;
+progress stays on stderr. No file is written next to it: there is no
+checkpoint to --resume, and an interrupted restructuring prints the raw
+transcript instead.
+
+For note vaults (Obsidian, Zettelkasten), the output-name setting names outputs
+with a pattern like {{date}}_{{template}}_{{input_stem}}.md ({{time}} and
+{{language}} too), and the front-matter setting puts YAML front matter at the
+top of Markdown outputs: date, duration, language, template, audio path and
+speakers (--diarize). See 'transcript config --help'.
+
+--obsidian-vault (or the obsidian-vault setting, when no output is given)
+writes the output as a note of an Obsidian vault, in its Transcripts folder
+(obsidian-folder setting): with front matter tagged "transcript" and the
+template name, a link to the daily note of the day (the folder and date
+format of the Daily notes plugin), and a link to the audio.
+
+Progress is checkpointed next to the output file (.<output>.transcript-state.json).
+If a run fails or is interrupted, re-run it with --resume to only transcribe the
+remaining chunks. The checkpoint is removed once the output is written.
+
+By default, a chunk that still fails after the retries stops the run. With
+--allow-partial, the other chunks keep going and failed chunks are retried once
+at the end; chunks still failing are replaced by a marker such as
+[transcription failed 12:30–15:00], and the run exits with code 7 after writing
+the output. The checkpoint is kept, so the missing chunks can be transcribed
+later with --resume --force (or after moving the output away).
+If restructuring is interrupted with Ctrl+C, the raw transcript is saved to
+<output>.raw.md (e.g. notes.raw.md): restructure it with 'transcript structure'.
+With --keep-raw-transcript (-r), it is kept there in any case, or in the file
+of --raw-output, to restructure it later with other templates without
+transcribing again.
+
+With --session-dir, each run writes all its artifacts into its own timestamped
+directory (<input>_<timestamp>/): transcript.md, raw.md (with --template),
+chunks.json, session.json (options and status) and session.log. With --resume,
+the most recent unfinished session for the same input is continued.
+
+Without --parallel, the number of concurrent requests is chosen once the audio
+is chunked, and printed: up to 10, but no more than there are chunks, than
+rate-limit-requests and rate-limit-audio let start while a request runs, or
+than uploads of the largest chunk a modest connection carries at once.
+With --parallel auto, the first chunk is uploaded alone to measure the upload
+throughput, and the remaining chunks use as many concurrent requests as the
+connection can carry without timing out (up to 10).
+
+Several files or directories can be given at once (batch mode). Directories are
+searched for supported audio files (with --recursive, including subdirectories).
+Each input is written to its own <input>.md (or the --format extension);
+--output then names a directory.
+Up to --jobs files are transcribed concurrently, and a failing file does not stop
+the others. The exit code reflects the failures, if any.
+
+With --tag, the proper nouns of the transcript are recorded under the tag, and
+later sessions with the same tag are prompted with the most frequent ones, so
+recurring names are recognized more reliably.
+
+With --format srt or vtt, the output is a subtitle file: segment timestamps are
+kept through chunking (with speakers when --diarize is set). Subtitles use the
+raw transcript, so they cannot be combined with --template.
+
+With --timestamps, the Markdown or text transcript has a time marker like
+**[00:15:02]** every 5 minutes, at the start of the segment said then, to jump
+back into the audio. Set the interval with --timestamps=10m, or put a marker
+before every segment with --timestamps=segment (the = is required).
+
+With --diarize --stats, a "Speaker statistics" section is appended to the
+transcript or notes: talk time and share, turns, longest monologue,
+interruptions (turns started before the previous speaker finished) and words
+per minute of each speaker. --stats=json writes them to <output>.stats.json
+instead (the = is required), following the stats schema (see 'transcript
+schema stats'); it is the one to use with subtitles.
+
+With --unclear, an "Unclear passages" section lists the segments transcribed
+with less than 50% confidence (--unclear=70% for another threshold), with their
+time, to check them against the audio. Confidence comes from the transcriber:
+the OpenAI diarization model and gRPC servers give none. It cannot be combined
+with subtitles.
+
+With --anchors, the transcript is restructured from its timestamped segments,
+and each bullet point and section of the notes ends with the range of the
+recording it comes from, like (12:30–15:10).
+
+A user template can hold placeholders, like {{attendees}} or {{project}}: --var
+attendees="Alice, Bob" gives their values, or --vars-file a file of name=value
+lines, which --var overrides. A placeholder without a value is an error.
+
+--summary-level sets the length of the notes of any template: short for a
+5-line executive summary, detailed for a breakdown of every point with its
+context, medium (default) for the template as written.
+
+With --template --actions, the action items are also extracted, with the same
+provider, to <output>.tasks.json following the tasks schema (see 'transcript
+schema tasks'), or to <output>.tasks.csv with --actions=csv (the = is
+required): description, owner, due date as stated, and when it was said, to
+import into a task manager. A failed extraction is a warning: the notes are
+written anyway.
+
+With --intro-outro, the beginning and end of each input are fingerprinted and
+compared with earlier recordings transcribed with the flag (see intro-library in
+"transcript config"). A repeated intro or outro, such as a podcast jingle, is left
+out of the transcript (skip), or replaced by an [intro] or [outro] marker (mark).
+
+With --dry-run, the audio is only analyzed: the planned chunks and the estimated
+API usage and cost of transcription and restructuring (--template) are printed,
+and no API is called. Estimates assume ~150 spoken words per minute.
+
+After each run, the actual usage (audio transcribed, tokens reported by the APIs)
+and its cost are printed. --cost-report appends them to a file, one line per run:
+JSON lines, or CSV if the file name ends with .csv.
+
+With --self-consistency N, the transcript is restructured N times (up to 5) with
+some randomness, and the model merges the N results into the final output: action
+items and decisions missed by one run are kept from the others, which helps with
+noisy transcripts. It costs about N+1 times a regular restructuring: the estimate
+is printed first, and the run is refused if it exceeds --max-cost (default $1).
+
+Transcripts too long for a single call are restructured in parts sized from
+the model's context window, or of --restructure-chunk-tokens, cut between
+paragraphs or sentences. With --restructure-overlap N, each part starts with
+up to N tokens of the end of the previous one, as context. When the notes of
+all parts are too long to be merged in one call, groups of consecutive parts
+are condensed first, level by level, up to --max-reduce-levels (default 3).
+
+Chunks are cut at silences under 20MB by default (--chunker
+silence). Audio without silences, like speech over continuous background noise,
+is cut at its quietest moment near each chunk boundary instead, which --chunker
+energy always does. --chunker time cuts fixed 10-minute chunks, and --chunker size cuts
+chunks of exactly --chunk-size bytes (default 2MB) whatever their duration, for
+providers with strict payload limits; chunks cut mid-speech overlap slightly.
+--chunk-size cannot exceed the file size limit of the transcriber (25MB for
+openai and groq).
+
+With --progress json, stderr carries JSON lines for programs wrapping transcript
+(CI jobs, GUIs): phase changes, chunks started and done with the percent done and
+the estimated seconds left, retries, restructuring steps, other messages as
+message events, and a final done or error event with the error code.
+
+With --provenance, a footer records which pipeline produced the output: version,
+transcription and restructuring models, date, and the SHA-256 of the audio (in a
+NOTE block for vtt; srt has no comments). --sign-key also signs the output with a
+minisign secret key created without password (minisign -G -W) into
+<output>.minisig, so recipients can verify it with: minisign -Vm <output> -p <key.pub>
+
+With --verbose, the time of each chunk is recorded (extraction, rate limit wait,
+API latency, upload, retries), and a report at the end shows the slowest chunks,
+the average API latency and the time in chunking vs transcription, with a hint
+on whether to tune --parallel or the chunk size.
+
+Supported formats: ogg, mp3, wav, m4a, flac, mp4, mpeg, mpga, webm, mkv, mov.
+The audio track of video files (mp4, mkv, mov, webm) is extracted with FFmpeg
+before chunking.
+
+An http(s) URL is downloaded first, then transcribed like a local file named
+after it (episode.mp3 gives episode.md). The URL of a podcast RSS feed downloads
+its latest episode. Downloads over --max-download (default 2GB) are refused. An
+interrupted download resumes where it stopped when run again, and the file is
+kept in the cache directory until it is transcribed.`,
 		Example: `  transcript transcribe session.ogg -o notes.md -t brainstorm
   transcript transcribe meeting.ogg -t meeting --diarize
   transcript transcribe lecture.ogg -t lecture -l en
   transcript transcribe session.ogg -l fr -T en -t meeting  # French audio, English output
   transcript transcribe session.ogg -t meeting --provider openai
-  transcript transcribe session.ogg  # Raw transcript, no restructuring`,
-		Args: cobra.ExactArgs(1),
+  transcript transcribe session.ogg  # Raw transcript, no restructuring
+  transcript transcribe talk.mp4 -f srt                  # Timestamped subtitles
+  transcript transcribe lecture.ogg --timestamps=10m     # A time marker every 10 minutes
+  transcript transcribe call.ogg --diarize --stats       # Who talked how much
+  transcript transcribe talk.ogg --unclear=70%           # Passages to check against the audio
+  transcript transcribe call.ogg -t meeting --actions=csv  # Task list for a task manager
+  transcript transcribe lecture.ogg -t notes --anchors   # Notes citing their audio ranges
+  transcript transcribe meeting.mkv -t meeting           # Audio track of a video
+  transcript transcribe https://example.com/episode.mp3  # Downloaded first
+  transcript transcribe standup.ogg --tag apollo         # Names from earlier "apollo" sessions
+  transcript transcribe standup.ogg --glossary terms.txt # Product names and acronyms spelled right
+  transcript transcribe interview.ogg -l fr --clean      # Without "euh" and "tu vois"
+  transcript transcribe support-call.ogg -t notes --redact
+  transcript transcribe call.ogg --redact-pattern 'EMP-\d{5}'
+  transcript transcribe episode42.mp3 --intro-outro skip # Leave out the usual intro
+  transcript transcribe cafe.ogg --denoise --normalize   # Noisy room, faint speakers
+  transcript transcribe session.ogg --transcriber local  # Offline, with whisper.cpp
+  transcript transcribe cours.ogg --translate-audio      # English transcript of French audio
+  transcript transcribe session.ogg -t meeting --resume  # Continue an interrupted run
+  transcript transcribe standup.ogg -t notes -o notes.md --append
+  transcript transcribe session.ogg -t meeting --force   # Replace the previous output
+  transcript transcribe call.ogg --stdout | less         # Read it without writing a file
+  transcript transcribe long.ogg --allow-partial         # Mark failed chunks instead of stopping
+  transcript transcribe session.ogg -p auto              # Adapt to a slow connection
+  transcript transcribe long.ogg --verbose               # What slowed the run down
+  transcript transcribe lecture.ogg -t lecture --dry-run # Chunks and estimated cost
+  transcript transcribe session.ogg -t notes --cost-report costs.csv
+  transcript transcribe noisy.ogg -t meeting --self-consistency 3
+  transcript transcribe session.ogg -t meeting --session-dir ./sessions
+  transcript transcribe standup.ogg -t meeting --obsidian-vault ~/Notes
+  transcript transcribe session.ogg --chunker size --chunk-size 4MB
+  transcript transcribe session.ogg -t notes --progress json 2> progress.jsonl
+  transcript transcribe hearing.ogg -t meeting --sign-key ~/.minisign/transcript.key
+  transcript transcribe a.ogg b.mp3 -t notes             # One output per file
+  transcript transcribe ./recordings/ --recursive -o ./notes/`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// The profile sets the flags not given, before they are parsed.
+			profileName, err := applyProfile(cmd, env, profile)
+			if err != nil {
+				return err
+			}
+
 			// Parse all inputs at the CLI boundary
-			opts, err := parseTranscribeOptions(args[0], output, tmpl, diarize, parallel, language, outputLang, provider)
+			n, auto, err := parseParallelFlag(cmd, parallel)
 			if err != nil {
 				return err
 			}
-			return runTranscribe(cmd, env, opts)
+			opts, err := parseTranscribeOptions(args[0], output, tmpl, diarize, n, language, outputLang, provider, userTemplatesDir(env))
+			if err != nil {
+				return err
+			}
+			opts.resume = resume
+			opts.allowPartial = allowPartial
+			opts.auto = auto
+			opts.model = model
+			opts.sessionDir = sessionDir
+			opts.obsidianVault = obsidianVault
+			if opts.stdout = toStdout(stdout, output); opts.stdout {
+				opts.output = ""
+				if err := validateStdout(cmd, "force", "append", "resume", "sign-key", "keep-raw-transcript"); err != nil {
+					return err
+				}
+			}
+			opts.dryRun = dryRun
+			opts.costReport = costReport
+			opts.selfConsistency = selfConsistency
+			opts.maxCost = maxCost
+			opts.verbose = verbose
+			opts.notify = notify
+			opts.profile = profileName
+			opts.translateAudio = translateAudio
+			opts.glossary = config.ExpandPath(glossary)
+			opts.clean = clean
+			opts.redact = redact
+			opts.redactPatterns = redactPatterns
+			opts.preprocessing = audio.Preprocessing{Denoise: denoise, Normalize: normalize}
+			opts.chunkTokens = chunkTokens
+			opts.overlap = overlap
+			opts.reduceLevels = reduceLevels
+			opts.stream = stream
+			opts.keepRaw = keepRaw || rawOutput != ""
+			opts.rawOutput = config.ExpandPath(rawOutput)
+			opts.outputMode = newOutputMode(force, appendOutput)
+			if opts.maxDownload, err = format.ParseSize(maxDownload); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidMaxDownload, err)
+			}
+			if opts.notifyWebhook, err = parseNotifyWebhook(notifyWebhook); err != nil {
+				return err
+			}
+			if opts.chunking, err = parseChunking(chunker, chunkSize); err != nil {
+				return err
+			}
+			if backend != "" {
+				if opts.backend, err = ParseBackend(backend); err != nil {
+					return err
+				}
+			}
+			if grpcEndpoint != "" {
+				if opts.grpcEndpoint, err = config.ParseGRPCEndpoint(grpcEndpoint); err != nil {
+					return err
+				}
+			}
+			if outFormat != "" {
+				if opts.format, err = ParseOutputFormat(outFormat); err != nil {
+					return err
+				}
+			}
+			if tag != "" {
+				if opts.tag, err = vocab.ParseTag(tag); err != nil {
+					return err
+				}
+			}
+			if speakerLabels != "" {
+				if opts.speakerLabels, err = ParseSpeakerLabels(speakerLabels); err != nil {
+					return err
+				}
+			}
+			if timestamps != "" {
+				if opts.timestamps, err = ParseTimestamps(timestamps); err != nil {
+					return err
+				}
+			}
+			if opts.stats, err = parseStats(stats); err != nil {
+				return err
+			}
+			if opts.unclear, err = parseUnclear(unclear); err != nil {
+				return err
+			}
+			if opts.actions, err = parseActions(actions); err != nil {
+				return err
+			}
+			if opts.fallback, err = parseFallbackProvider(fallback); err != nil {
+				return err
+			}
+			if opts.template, err = anchorTemplate(opts.template, anchors); err != nil {
+				return err
+			}
+			if opts.template, err = fillTemplate(env.Stderr, opts.template, vars, varsFile); err != nil {
+				return err
+			}
+			if opts.template, err = levelTemplate(opts.template, summaryLevel); err != nil {
+				return err
+			}
+			if err := validateStreamRestructure(opts.stream, opts.template, progressFmt); err != nil {
+				return err
+			}
+			if introOutro != "" {
+				if opts.introOutro, err = ParseIntroOutroMode(introOutro); err != nil {
+					return err
+				}
+			}
+			if opts.provenance, err = newProvenanceStamp(env, withProvenance, signKey); err != nil {
+				return err
+			}
+
+			if isBatchInput(args) && dryRun {
+				return fmt.Errorf("--dry-run takes a single audio file")
+			}
+			if isBatchInput(args) && opts.stdout {
+				return fmt.Errorf("--stdout takes a single audio file")
+			}
+			if isBatchInput(args) && opts.rawOutput != "" {
+				return fmt.Errorf("--raw-output takes a single audio file (use --keep-raw-transcript)")
+			}
+			return runWithProgress(cmd, env, progressFmt, progress.PhaseChunking, func(env *Env) error {
+				if isBatchInput(args) {
+					return runTranscribeBatch(cmd, env, batchOptions{
+						inputs:    args,
+						recursive: recursive,
+						jobs:      jobs,
+						file:      opts,
+					})
+				}
+				return runTranscribe(cmd, env, opts)
+			})
 		},
 	}
 
-	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path (default: <input>.md)")
-	cmd.Flags().StringVarP(&tmpl, "template", "t", "", "Restructure template: brainstorm, meeting, lecture, notes")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path, or directory with several inputs (default: <input>.md)")
+	cmd.Flags().StringVarP(&tmpl, "template", "t", "", "Restructure template: brainstorm, meeting, lecture, notes, podcast, chapters, digest, or a user template")
 	cmd.Flags().BoolVar(&diarize, "diarize", false, "Enable speaker identification")
-	cmd.Flags().IntVarP(&parallel, "parallel", "p", transcribe.MaxRecommendedParallel, "Max concurrent API requests (1-10)")
+	cmd.Flags().StringVarP(&parallel, "parallel", "p", defaultParallel, "Max concurrent API requests (1-10, or auto; default: sized from the chunks and rate limits)")
 	cmd.Flags().StringVarP(&language, "language", "l", "", "Audio language (ISO 639-1 code, e.g., en, fr, pt-BR)")
 	cmd.Flags().StringVarP(&outputLang, "translate", "T", "", "Translate output to language (ISO 639-1 code, requires --template)")
-	cmd.Flags().StringVar(&provider, "provider", ProviderDeepSeek, "LLM provider for restructuring: deepseek, openai")
+	cmd.Flags().BoolVar(&translateAudio, "translate-audio", false, "Translate the speech to English while transcribing (openai, groq or local transcriber)")
+	cmd.Flags().StringVar(&provider, "provider", ProviderDeepSeek, "LLM provider for restructuring: deepseek, openai, anthropic, ollama")
+	cmd.Flags().StringVar(&model, "restructure-model", "", "Model of the restructure provider (default: config or provider default)")
+	cmd.Flags().StringVar(&fallback, "fallback-provider", "", fallbackProviderFlagHelp)
+	cmd.Flags().BoolVarP(&keepRaw, "keep-raw-transcript", "r", false, "Keep the raw transcript before restructuring, in <output>.raw.md (requires --template)")
+	cmd.Flags().StringVar(&rawOutput, "raw-output", "", "Keep the raw transcript before restructuring in this file (implies --keep-raw-transcript)")
+	cmd.Flags().StringVarP(&outFormat, "format", "f", FormatMarkdown, "Output format: md, txt, srt, vtt (srt and vtt are timestamped subtitles)")
+	cmd.Flags().StringVar(&speakerLabels, "speaker-labels", "", speakerLabelsFlagHelp)
+	cmd.Flags().StringVar(&timestamps, "timestamps", "", timestampsFlagHelp)
+	cmd.Flags().Lookup("timestamps").NoOptDefVal = defaultTimestamps
+	cmd.Flags().StringVar(&stats, "stats", "", statsFlagHelp)
+	cmd.Flags().Lookup("stats").NoOptDefVal = StatsMarkdown
+	cmd.Flags().StringVar(&unclear, "unclear", "", unclearFlagHelp)
+	cmd.Flags().Lookup("unclear").NoOptDefVal = defaultUnclear
+	cmd.Flags().StringVar(&actions, "actions", "", actionsFlagHelp)
+	cmd.Flags().Lookup("actions").NoOptDefVal = ActionsJSON
+	cmd.Flags().BoolVar(&anchors, "anchors", false, anchorsFlagHelp)
+	cmd.Flags().StringArrayVar(&vars, "var", nil, varFlagHelp)
+	cmd.Flags().StringVar(&varsFile, "vars-file", "", varsFileFlagHelp)
+	cmd.Flags().StringVar(&summaryLevel, "summary-level", "", summaryLevelFlagHelp)
+	cmd.Flags().StringVar(&backend, "transcriber", "", transcriberFlagHelp)
+	cmd.Flags().StringVar(&backend, "stt-provider", "", sttProviderFlagHelp)
+	cmd.Flags().StringVar(&grpcEndpoint, "grpc-endpoint", "", grpcEndpointFlagHelp)
+	cmd.Flags().StringVar(&grpcEndpoint, "stt-endpoint", "", sttEndpointFlagHelp)
+	cmd.Flags().StringVar(&tag, "tag", "", "Tag the session and prompt with names from previous sessions with the same tag (default: tag of .transcript.toml)")
+	cmd.Flags().StringVar(&glossary, "glossary", "", glossaryFlagHelp)
+	cmd.Flags().BoolVar(&clean, "clean", false, cleanFlagHelp)
+	cmd.Flags().BoolVar(&redact, "redact", false, redactFlagHelp)
+	cmd.Flags().StringArrayVar(&redactPatterns, "redact-pattern", nil, redactPatternFlagHelp)
+	cmd.Flags().BoolVar(&denoise, "denoise", false, denoiseFlagHelp)
+	cmd.Flags().BoolVar(&normalize, "normalize", false, normalizeFlagHelp)
+	cmd.Flags().StringVar(&introOutro, "intro-outro", "", "Intros and outros heard in earlier recordings: skip, or mark as [intro]/[outro]")
+	cmd.Flags().BoolVar(&resume, "resume", false, "Reuse chunks transcribed by a previous interrupted run")
+	cmd.Flags().BoolVar(&allowPartial, "allow-partial", false, "Keep going when chunks fail, and mark them in the output (exit code 7)")
+	cmd.Flags().StringVar(&sessionDir, "session-dir", "", "Write all artifacts of each run into a timestamped directory here")
+	cmd.Flags().StringVar(&obsidianVault, "obsidian-vault", "", obsidianVaultFlagHelp)
+	cmd.Flags().BoolVar(&stdout, "stdout", false, stdoutFlagHelp)
+	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "Search directories recursively for audio files")
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", defaultBatchJobs, "Max files transcribed concurrently with several inputs")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the planned chunks and estimated cost without calling any API")
+	cmd.Flags().IntVar(&selfConsistency, "self-consistency", 0, "Restructure N times and merge the results (2-5, requires --template)")
+	cmd.Flags().Float64Var(&maxCost, "max-cost", defaultMaxCost, "Max estimated restructuring cost of --self-consistency, in US dollars")
+	cmd.Flags().IntVar(&chunkTokens, "restructure-chunk-tokens", 0, chunkTokensFlagHelp)
+	cmd.Flags().IntVar(&overlap, "restructure-overlap", 0, overlapFlagHelp)
+	cmd.Flags().IntVar(&reduceLevels, "max-reduce-levels", 0, reduceLevelsFlagHelp)
+	cmd.Flags().BoolVar(&stream, "stream-restructure", false, streamRestructureFlagHelp)
+	cmd.Flags().BoolVar(&force, "force", false, forceFlagHelp)
+	cmd.Flags().BoolVar(&appendOutput, "append", false, appendFlagHelp)
+	cmd.Flags().StringVar(&costReport, "cost-report", "", "Append the usage and cost of each run to this file (JSON lines, or CSV with a .csv extension)")
+	cmd.Flags().StringVar(&progressFmt, "progress", ProgressText, "Progress output on stderr: text, or json for JSON lines")
+	cmd.Flags().StringVar(&chunker, "chunker", audio.StrategySilence, chunkerFlagHelp)
+	cmd.Flags().StringVar(&chunkSize, "chunk-size", "", chunkSizeFlagHelp)
+	cmd.Flags().BoolVar(&withProvenance, "provenance", false, provenanceFlagHelp)
+	cmd.Flags().StringVar(&signKey, "sign-key", "", signKeyFlagHelp)
+	cmd.Flags().BoolVar(&verbose, "verbose", false, "Print per-chunk timings and what slowed the run down")
+	cmd.Flags().StringVar(&maxDownload, "max-download", defaultMaxDownload, "Max size of a URL input to download (e.g., 500MB)")
+	cmd.Flags().StringVar(&notifyWebhook, "notify-webhook", "", notifyWebhookFlagHelp)
+	cmd.Flags().BoolVar(&notify, "notify", false, notifyFlagHelp)
+	cmd.Flags().StringVar(&profile, "profile", "", profileFlagHelp)
+
+	// The session directory, the vault and stdout decide where the output goes.
+	cmd.MarkFlagsMutuallyExclusive("output", "session-dir", "obsidian-vault", "stdout")
+	// The session keeps the raw transcript (raw.md)
+	cmd.MarkFlagsMutuallyExclusive("keep-raw-transcript", "session-dir")
+	cmd.MarkFlagsMutuallyExclusive("force", "append")
 
 	return cmd
 }
 
 // runTranscribe executes the transcription pipeline with validated options.
-func runTranscribe(cmd *cobra.Command, env *Env, opts transcribeOptions) error {
+func runTranscribe(cmd *cobra.Command, env *Env, opts transcribeOptions) (err error) {
 	ctx := cmd.Context()
+	started, input := env.Now(), opts.inputPath // Input before a URL is downloaded
 
 	// === VALIDATION (fail-fast) ===
 
+	// 0. URL input: downloaded, then transcribed as a local file
+	if fetch.IsURL(opts.inputPath) {
+		var done func(error)
+		opts.inputPath, done, err = downloadInput(ctx, env, opts.inputPath, opts.maxDownload)
+		if err != nil {
+			return err
+		}
+		defer func() { done(err) }()
+	}
+
 	// 1. File exists
 	if _, err := os.Stat(opts.inputPath); err != nil {
 		if os.IsNotExist(err) {
@@ -182,51 +879,134 @@ func runTranscribe(cmd *cobra.Command, env *Env, opts transcribeOptions) error {
 		return fmt.Errorf("cannot access input file: %w", err)
 	}
 
-	// 2. Format supported
-	ext := strings.ToLower(filepath.Ext(opts.inputPath))
-	if !supportedFormats[ext] {
-		return fmt.Errorf("unsupported format %q (supported: %s): %w",
-			ext, supportedFormatsList(), ErrUnsupportedFormat)
+	// 2. Format: files with a listed extension are read as they are. Others
+	// are sniffed, which rejects a renamed document here, then left to the
+	// input check below, and their audio track is extracted
+	if err := checkFormat(opts.inputPath); err != nil {
+		return err
 	}
+	knownFormat := supportedFormats[strings.ToLower(filepath.Ext(opts.inputPath))]
 
 	// 3. Load config for output-dir
 	cfg, err := env.ConfigLoader.Load()
 	if err != nil {
-		fmt.Fprintf(env.Stderr, "Warning: failed to load config: %v\n", err)
+		warnf(env.Stderr, warnConfigLoad, "failed to load config: %v", err)
+	}
+	if cfg, err = cfg.WithProfile(opts.profile); err != nil {
+		return err
+	}
+	if opts.grpcEndpoint != "" {
+		cfg.GRPCEndpoint = opts.grpcEndpoint
+	}
+	if opts.glossary != "" {
+		cfg.Glossary = opts.glossary
 	}
+	applyChunking(&cfg, opts.chunkTokens, opts.overlap)
 
+	// Cleanup and redaction rules are those of the transcript language
+	transcriptLang := opts.language
+	if opts.translateAudio {
+		transcriptLang = lang.MustParse("en")
+	}
 	// 4. Output path (resolve with output-dir, derive default from input if needed)
-	// EnsureExtension adds .md only when path has no extension.
-	// Paths with non-.md extensions are preserved and trigger a warning below.
-	defaultOutput := deriveOutputPath(filepath.Base(opts.inputPath))
-	output := config.ResolveOutputPath(opts.output, cfg.OutputDir, defaultOutput)
-	output = config.EnsureExtension(output, ".md")
-	warnNonMarkdownExtension(env.Stderr, output)
-
-	// 5. Translate requires template
-	if !opts.outputLang.IsZero() && opts.template.IsZero() {
-		return fmt.Errorf("--translate requires --template (raw transcripts use the audio's language)")
+	// The default is named by the output-name pattern when set.
+	// EnsureExtension adds the format's extension (.md by default) only when
+	// path has no extension. Other extensions are preserved and trigger a warning below.
+	// The Obsidian vault, when given, is where the default output goes.
+	// With --stdout, there is no output file: "stdout" names it in messages.
+	outputGiven := opts.output != "" || opts.sessionDir != "" || opts.stdout
+	vault, err := openObsidianVault(obsidianVaultRoot(opts.obsidianVault, outputGiven, cfg), cfg, opts.format)
+	if err != nil {
+		return err
+	}
+	if vault != nil && opts.output == "" {
+		cfg.OutputDir = vault.Dir()
+	}
+	output := "stdout"
+	if !opts.stdout {
+		outputName, err := outputNamer(cfg, opts.nameFields(started), opts.format.Extension(), func(base string) string {
+			return deriveOutputPath(base, opts.format)
+		})
+		if err != nil {
+			return err
+		}
+		output = config.ResolveOutputPath(opts.output, cfg.OutputDir, outputName(filepath.Base(opts.inputPath)))
+		output = config.EnsureExtension(output, opts.format.Extension())
+		warnExtensionMismatch(env.Stderr, output, opts.format.OrDefault())
+	}
+	keptRawPath := opts.keptRawPath(output)
+	if keptRawPath != "" && opts.outputMode != outputForce {
+		if _, err := os.Stat(keptRawPath); err == nil {
+			return fmt.Errorf("raw transcript file already exists: %s: %w", keptRawPath, ErrOutputExists)
+		}
 	}
 
-	// 6. Provider defaulting
+	// 5-7. Transcription backend, session tag, flag combinations and API keys
+	opts.backend, err = resolveBackend(opts.backend, cfg)
+	if err != nil {
+		return err
+	}
+	opts.tag, err = resolveTag(opts.tag, cfg)
+	if err != nil {
+		return err
+	}
+	glossary, err := loadGlossary(env, cfg)
+	if err != nil {
+		return err
+	}
+	redactor, err := newRedactor(opts.redact, opts.redactPatterns, transcriptLang, cfg)
+	if err != nil {
+		return err
+	}
+	if opts.dryRun {
+		// No API is called: keys are not needed
+		if err := validateTranscribeFlags(opts); err != nil {
+			return err
+		}
+		return runTranscribeDryRun(ctx, env, cmd.OutOrStdout(), opts, cfg)
+	}
+	if err := validateTranscribeRequirements(env, opts, cfg); err != nil {
+		return err
+	}
 	provider := opts.provider.OrDefault()
+	parallel := opts.parallel // Zero: chosen once the chunks are known
 
-	// 7. Parallel bounds (clamp to 1-10)
-	parallel := clampParallel(opts.parallel)
-
-	// 8. API keys present (OpenAI always needed for transcription)
-	openaiKey := env.Getenv(EnvOpenAIAPIKey)
-	if openaiKey == "" {
-		return fmt.Errorf("%w (set it with: export %s=sk-...)", ErrAPIKeyMissing, EnvOpenAIAPIKey)
+	// Notified once the run is over, whatever its outcome (after the session is finished)
+	var report *runReport
+	if webhook := notifyWebhookURL(opts.notifyWebhook, cfg); webhook != "" {
+		stderr := env.Stderr
+		defer func() {
+			notifyWebhook(ctx, stderr, webhook,
+				newWebhookNotification("transcribe", input, output, started, env.Now(), report, err))
+		}()
+	}
+	if opts.notify {
+		stderr := env.Stderr
+		defer func() {
+			title, message := desktopNotice("transcribe", output, env.Now().Sub(started), err)
+			notifyDesktop(ctx, stderr, env.Notifier, title, message)
+		}()
+	}
+	if opts.backend.IsLocal() && cfg.WhisperURL == "" {
+		// whisper.cpp already uses every CPU core for one chunk
+		parallel, opts.auto = 1, false
 	}
 
-	// 9. Restructuring API key validation (only if template specified)
-	// The actual key resolution is done in restructureContent()
-	// Note: OpenAI key already validated above, so only check DeepSeek
-	if !opts.template.IsZero() && provider.IsDeepSeek() {
-		if env.Getenv(EnvDeepSeekAPIKey) == "" {
-			return fmt.Errorf("%w (set it with: export %s=sk-...)", ErrDeepSeekKeyMissing, EnvDeepSeekAPIKey)
+	// 8. Session directory: all artifacts go there, progress is also logged there
+	var sess *session
+	if opts.sessionDir != "" {
+		sess, err = startTranscribeSession(env, opts)
+		if err != nil {
+			return err
 		}
+		stderr := env.Stderr
+		defer func() { sess.finish(stderr, err) }()
+
+		sessionEnv := *env
+		sessionEnv.Stderr = sess.stderr(stderr)
+		env = &sessionEnv
+		output = sess.path(sessionOutput(opts.format))
+		fmt.Fprintf(env.Stderr, "Session: %s\n", sess.dir)
 	}
 
 	// === SETUP ===
@@ -238,16 +1018,51 @@ func runTranscribe(cmd *cobra.Command, env *Env, opts transcribeOptions) error {
 	}
 	env.FFmpegResolver.CheckVersion(ctx, ffmpegPath)
 
+	// Check the audio can be decoded, rather than fail after chunking or
+	// uploading. A segment list is a text file only the join below can read:
+	// its files, written by record, are decoded there
+	if !audio.IsSegmentList(opts.inputPath) {
+		if err = checkInput(ctx, env, ffmpegPath, opts.inputPath, opts.backend); err != nil {
+			return err
+		}
+	}
+
+	// === EXTRACTION (video inputs, unlisted formats, segmented recordings, --denoise, --normalize) ===
+
+	// Chunks are cut from the audio track alone, or from the joined files of
+	// a segmented recording; timestamps are unchanged. Preprocessing extracts
+	// the audio track as it filters it
+	audioPath := opts.inputPath
+	if !opts.preprocessing.IsZero() {
+		var cleanup func()
+		audioPath, cleanup, err = preprocessAudio(ctx, env, ffmpegPath, opts.inputPath, opts.preprocessing)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	} else if audio.IsVideo(opts.inputPath) || audio.IsSegmentList(opts.inputPath) || !knownFormat {
+		var cleanup func()
+		audioPath, cleanup, err = extractVideoAudio(ctx, env, ffmpegPath, opts.inputPath)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+
 	// === CHUNKING ===
 
-	fmt.Fprintln(env.Stderr, "Detecting silences...")
+	progress.PhaseChange(ctx, progress.PhaseChunking)
+	fmt.Fprintln(env.Stderr, opts.chunking.message())
+	chunkingStart := env.Now()
 
-	chunker, err := env.ChunkerFactory.NewSilenceChunker(ffmpegPath)
+	chunker, err := opts.chunking.newChunker(env, ffmpegPath, audio.NewRunID())
 	if err != nil {
 		return err
 	}
 
-	chunks, err := chunker.Chunk(ctx, opts.inputPath)
+	highlights := loadHighlights(env, opts.inputPath)
+	repeats := detectIntroOutro(ctx, env, cfg, ffmpegPath, opts.inputPath, opts.introOutro)
+	chunks, err := repeats.chunk(ctx, chunker, audioPath)
 	if err != nil {
 		return err
 	}
@@ -255,59 +1070,451 @@ func runTranscribe(cmd *cobra.Command, env *Env, opts transcribeOptions) error {
 	// Ensure cleanup even on error or interrupt
 	defer func() {
 		if cleanupErr := audio.CleanupChunks(chunks); cleanupErr != nil {
-			fmt.Fprintf(env.Stderr, "Warning: failed to cleanup chunks: %v\n", cleanupErr)
+			warnf(env.Stderr, warnFileNotSaved, "failed to cleanup chunks: %v", cleanupErr)
 		}
 	}()
 
 	fmt.Fprintf(env.Stderr, "Chunking audio... %d chunks\n", len(chunks))
+	if err := sess.writeChunks(chunks); err != nil {
+		warnf(env.Stderr, warnFileNotSaved, "failed to save chunks manifest: %v", err)
+	}
+	if !opts.auto {
+		parallel = resolveParallel(env.Stderr, parallel, chunks, cfg.RateLimitRequests, cfg.RateLimitAudio)
+	}
 
 	// === TRANSCRIPTION ===
 
-	transcriber := env.TranscriberFactory.NewTranscriber(openaiKey)
+	transcriber, err := newTranscriber(env, opts.backend, cfg, ffmpegPath)
+	if err != nil {
+		return err
+	}
+	transcriber = withLocalFallback(env, transcriber, opts.backend, cfg, ffmpegPath, chunks, sess)
+	vocabulary := loadTagVocabulary(env, cfg, opts.tag, glossary)
 	transcribeOpts := transcribe.Options{
-		Diarize:  opts.diarize,
-		Language: opts.language,
+		Diarize:    opts.diarize,
+		Prompt:     vocabulary.transcriptionPrompt(),
+		Language:   opts.language,
+		Timestamps: timedResults(opts.format, opts.template, opts.timestamps) || opts.stats != "" || opts.unclear > 0 || opts.actions != "",
+		Translate:  opts.translateAudio,
+	}
+	report = newTranscribeReport("transcribe", opts.inputPath, output, opts.backend, transcribeOpts)
+	run := []transcribe.RunOption{
+		transcribe.WithUsageTracker(report.transcription),
+		transcribe.WithRateLimiter(newRateLimiter(cfg)),
+	}
+	if opts.allowPartial {
+		run = append(run, transcribe.WithAllowPartial())
+	}
+	var timing *timingReport
+	if opts.verbose {
+		timing = newTimingReport(env.Now().Sub(chunkingStart), parallel)
+		run = append(run, transcribe.WithTimings(timing.chunks))
+	}
+
+	// Checkpoint completed chunks so an interrupted run can be resumed
+	// (not with --stdout, which writes no file). With --redact, none is saved:
+	// it would keep the unredacted text on disk.
+	var statePath string
+	if !opts.stdout {
+		statePath = checkpointPath(output)
+	}
+	saveCheckpoint := statePath != "" && redactor == nil
+	checkpoint, err := loadTranscribeCheckpoint(env, statePath, opts.inputPath, transcribeOpts, len(chunks), opts.resume)
+	if err != nil {
+		return err
 	}
 
 	// Transcribe with progress output
+	progress.PhaseChange(ctx, progress.PhaseTranscribing)
 	fmt.Fprintln(env.Stderr, "Transcribing...")
-	results, err := transcribe.TranscribeAll(ctx, chunks, transcriber, transcribeOpts, parallel)
+	transcriptionStart := env.Now()
+	onChunk := func(index int, text string) {
+		checkpoint.Record(index, text)
+		if !saveCheckpoint {
+			return
+		}
+		if err := checkpoint.Save(statePath); err != nil {
+			warnf(env.Stderr, warnFileNotSaved, "failed to save checkpoint: %v", err)
+		}
+	}
+	var results []string
+	if opts.auto {
+		results, err = transcribe.TranscribeRemainingAuto(ctx, chunks, transcriber, transcribeOpts,
+			checkpoint.Results, onChunk, func(n int, bps float64) {
+				printAutoParallel(env.Stderr, n, bps)
+				if timing != nil {
+					timing.parallel = n
+				}
+			}, run...)
+	} else {
+		results, err = transcribe.TranscribeRemaining(ctx, chunks, transcriber, transcribeOpts, parallel,
+			checkpoint.Results, onChunk, run...)
+	}
+	if timing != nil {
+		timing.transcription = env.Now().Sub(transcriptionStart)
+	}
+	// With --allow-partial, chunks that kept failing are marked and the output still written
+	var partial *transcribe.PartialError
+	if errors.As(err, &partial) {
+		err = markFailedChunks(env.Stderr, chunks, results, partial, transcribeOpts.Timestamps)
+	}
 	if err != nil {
+		if len(checkpoint.Results) > 0 && saveCheckpoint {
+			fmt.Fprintf(env.Stderr, "%d/%d chunks saved, re-run with --resume to continue\n",
+				len(checkpoint.Results), len(chunks))
+		}
+		return err
+	}
+
+	// Cleaned and redacted results are not checkpointed: --resume with another
+	// --clean or --redact works
+	if results, err = cleanResults(newCleaner(opts.clean, transcriptLang, cfg), results, transcribeOpts.Timestamps); err != nil {
+		return err
+	}
+	if results, err = redactResults(env.Stderr, redactor, results, transcribeOpts.Timestamps); err != nil {
 		return err
 	}
 
-	transcript := strings.Join(results, "\n\n")
+	markedChunks, markedResults, err := repeats.mark(chunks, results, transcribeOpts.Timestamps)
+	if err != nil {
+		return err
+	}
+	stats, err := speakerStats(opts.stats, markedChunks, markedResults)
+	if err != nil {
+		return err
+	}
+	unclear, err := unclearSection(env.Stderr, opts.unclear, opts.format, markedChunks, markedResults)
+	if err != nil {
+		return err
+	}
+	transcript, err := renderHighlighted(renderedHighlights(opts.format, highlights), markedChunks, markedResults,
+		func(chunks []audio.Chunk, results []string) (string, error) {
+			switch {
+			case opts.template.Timed():
+				return renderTimedTranscript(chunks, results)
+			case !opts.timestamps.IsZero():
+				return renderMarkedTranscript(opts.format, opts.timestamps, chunks, results)
+			case transcribeOpts.Timestamps && !opts.format.IsSubtitle():
+				// Timed for --stats, --unclear or --actions only
+				untimed, err := untimedResults(results)
+				if err != nil {
+					return "", err
+				}
+				results = untimed
+			}
+			return renderTranscript(opts.format, opts.speakerLabels, chunks, results)
+		})
+	if err != nil {
+		return err
+	}
 	fmt.Fprintln(env.Stderr, "Transcription complete")
 
+	// Action items are extracted from the timed transcript, to know when they were said
+	actions := newActionsRun(opts.actions)
+	if actions != nil {
+		if actions.input, err = renderTimedTranscript(markedChunks, markedResults); err != nil {
+			return err
+		}
+	}
+
 	// === RESTRUCTURE (optional) ===
 
 	finalOutput := transcript
+	var streamed *streamedOutput // Output file written as it is restructured (--stream-restructure)
 	if !opts.template.IsZero() && strings.TrimSpace(transcript) != "" {
+		// Keep the raw transcript in the session (before restructuring, so it survives a failure)
+		var rawPath string
+		if sess != nil {
+			if err := sess.writeFile(sessionRawFile, transcript); err != nil {
+				warnf(env.Stderr, warnFileNotSaved, "failed to save raw transcript: %v", err)
+			} else {
+				rawPath = sess.path(sessionRawFile)
+				fmt.Fprintf(env.Stderr, "Raw transcript saved: %s\n", rawPath)
+			}
+		}
+		// Kept for 'transcript structure' with other templates (--keep-raw-transcript)
+		if keptRawPath != "" {
+			if err := writeRawTranscript(env, opts.outputMode, keptRawPath, transcript); err != nil {
+				return err
+			}
+			rawPath = keptRawPath
+		}
+
+		progress.PhaseChange(ctx, progress.PhaseRestructuring)
 		fmt.Fprintf(env.Stderr, "Restructuring with template '%s' (provider: %s)...\n", opts.template, provider)
 
-		// Default output language to input language if not specified
+		// Default output language to the transcript language if not specified:
+		// English for a translated transcript, else the input language
 		effectiveOutputLang := opts.outputLang
+		if effectiveOutputLang.IsZero() && opts.translateAudio {
+			effectiveOutputLang = lang.MustParse("en")
+		}
 		if effectiveOutputLang.IsZero() && !opts.language.IsZero() {
 			effectiveOutputLang = opts.language
 		}
 
+		// With --stdout, the output is streamed to stderr only
+		var stream io.Writer
+		if opts.stream && opts.stdout {
+			stream = env.Stderr
+		} else if opts.stream {
+			if streamed, err = openStreamedOutput(env, output, opts.outputMode, opts.format); err != nil {
+				return err
+			}
+			stream = streamed
+		}
 		finalOutput, err = restructureContent(ctx, env, transcript, RestructureOptions{
-			Template:   opts.template,
-			Provider:   provider,
-			OutputLang: effectiveOutputLang,
-			OnProgress: defaultProgressCallback(env.Stderr),
+			Template:           opts.template,
+			Provider:           provider,
+			OutputLang:         effectiveOutputLang,
+			OnProgress:         defaultProgressCallback(env.Stderr),
+			PromptTokenWarning: cfg.PromptTokenWarning,
+			Ollama:             ollamaConfig(cfg),
+			Azure:              azureConfig(cfg),
+			Model:              restructureModel(opts.model, cfg),
+			ContextWindows:     cfg.ContextWindows,
+			Split:              cfg.RestructureSplit,
+			ChunkTokens:        cfg.RestructureChunkTokens,
+			Overlap:            cfg.RestructureOverlap,
+			ReduceLevels:       opts.reduceLevels,
+			SelfConsistency:    opts.selfConsistency,
+			MaxCost:            opts.maxCost,
+			Glossary:           vocabulary.glossaryTerms(),
+			Highlights:         highlights,
+			Stream:             stream,
+			FallbackProvider:   opts.fallback,
+			report:             report,
+			actions:            actions,
 		})
 		if err != nil {
-			return err
+			streamed.abort()
+			if opts.stdout {
+				return restructureFailedStdout(ctx, env, cmd.OutOrStdout(), err, transcript)
+			}
+			return restructureFailed(ctx, env, err, transcript, output, rawPath, opts.template)
 		}
 	}
 
 	// === WRITE OUTPUT ===
 
-	if err := writeFileAtomic(output, finalOutput); err != nil {
+	finalOutput = appendStats(finalOutput, opts.stats, stats)
+	finalOutput = appendSection(finalOutput, unclear)
+
+	// Front matter and vault links are signed with the output
+	audioSource := input
+	if !fetch.IsURL(input) {
+		audioSource = absPath(input)
+	}
+	frontMatter := notes.FrontMatter{
+		Date:     started,
+		Duration: audioDuration(markedChunks),
+		Language: opts.outputLanguage().String(),
+		Template: opts.template.String(),
+		Audio:    audioSource,
+	}
+	if opts.diarize {
+		frontMatter.Speakers = notes.Speakers(transcript)
+	}
+	finalOutput = obsidianNote(vault, finalOutput, output, opts.outputMode, started, audioSource, &frontMatter)
+	finalOutput = addFrontMatter(cfg.FrontMatter || vault != nil, finalOutput, output, opts.outputMode, opts.format, frontMatter)
+	finalOutput, err = opts.provenance.apply(env, finalOutput, output, opts.inputPath, opts.format, report, opts.template)
+	if err != nil {
+		streamed.abort()
+		return err
+	}
+	switch {
+	case opts.stdout:
+		if opts.stream {
+			fmt.Fprintln(env.Stderr)
+		}
+		if _, err = io.WriteString(cmd.OutOrStdout(), finalOutput); err != nil {
+			err = fmt.Errorf("failed to write output: %w", err)
+		}
+	case streamed != nil:
+		err = streamed.finish(finalOutput)
+	default:
+		err = writeOutput(env, output, finalOutput, opts.outputMode, opts.format)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Output written: the checkpoint is no longer needed, unless chunks are missing
+	if partial == nil && statePath != "" {
+		if err := os.Remove(statePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			warnf(env.Stderr, warnFileNotSaved, "failed to remove checkpoint: %v", err)
+		}
+	}
+	if err := opts.provenance.sign(env, output, finalOutput); err != nil {
 		return err
 	}
+	saveStats(env, opts.stats, output, stats)
+	saveActions(env, opts.actions, output, audioSource, actions)
+
+	// Recorded once the output is complete, so that a re-run does not count it twice
+	if partial == nil {
+		vocabulary.record(env, results, transcribeOpts.Timestamps)
+	}
+	repeats.learn(env)
 
+	finishRunReport(env, report, opts.costReport)
+	printTimingReport(env.Stderr, timing)
+	if partial != nil {
+		fmt.Fprintf(env.Stderr, "Done with %d/%d chunks missing: %s\n", len(partial.Failed), len(chunks), output)
+		if saveCheckpoint {
+			fmt.Fprintf(env.Stderr, "Re-run with --resume --force to transcribe them\n")
+		}
+		return partial
+	}
 	fmt.Fprintf(env.Stderr, "Done: %s\n", output)
 	return nil
 }
+
+// markFailedChunks replaces the results of the chunks that failed with a
+// [transcription failed MM:SS–MM:SS] marker, and lists them on w.
+// Timestamped results (see transcribe.Options.Timestamps) get a timed marker.
+func markFailedChunks(w io.Writer, chunks []audio.Chunk, results []string, partial *transcribe.PartialError, timestamps bool) error {
+	for _, failed := range partial.Failed {
+		i := slices.IndexFunc(chunks, func(c audio.Chunk) bool { return c.Index == failed.Chunk.Index })
+		if i < 0 {
+			continue
+		}
+		c := chunks[i]
+		marker := fmt.Sprintf("[transcription failed %s–%s]", format.Duration(c.StartTime), format.Duration(c.EndTime))
+		warnf(w, warnChunkFailed, "%v", failed)
+		if timestamps {
+			var err error
+			marker, err = transcribe.EncodeSegments([]transcribe.TimedSegment{
+				{Start: 0, End: c.Duration(), Text: marker},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		results[i] = marker
+	}
+	return nil
+}
+
+// validateTranscribeRequirements checks flag combinations and the API keys
+// needed by opts. It does not touch the file system, so batch mode can run it
+// once before processing any file.
+func validateTranscribeRequirements(env *Env, opts transcribeOptions, cfg config.Config) error {
+	if err := validateTranscribeFlags(opts); err != nil {
+		return err
+	}
+
+	// Transcription requirements (API key, or local model), and chunks the
+	// API accepts
+	if err := validateBackend(env, opts.backend, cfg, opts.diarize); err != nil {
+		return err
+	}
+	if err := opts.chunking.validateFor(opts.backend); err != nil {
+		return err
+	}
+	if opts.translateAudio {
+		if err := validateTranslateAudio(opts.backend); err != nil {
+			return err
+		}
+	}
+
+	// Restructuring API key validation (only if template specified)
+	// The actual key resolution is done in restructureContent()
+	if !opts.template.IsZero() {
+		if _, err := restructureAPIKey(env, opts.provider.OrDefault()); err != nil {
+			return err
+		}
+		if err := validateAzureChat(opts.provider.OrDefault(), azureConfig(cfg)); err != nil {
+			return err
+		}
+		if err := validateFallbackKey(env, opts.fallback, azureConfig(cfg)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateTranscribeFlags checks the flag combinations of opts.
+func validateTranscribeFlags(opts transcribeOptions) error {
+	// Translate requires template
+	if !opts.outputLang.IsZero() && opts.template.IsZero() {
+		return fmt.Errorf("--translate requires --template (raw transcripts use the audio's language)")
+	}
+
+	// The translations endpoint cannot identify speakers
+	if opts.translateAudio && opts.diarize {
+		return fmt.Errorf("--translate-audio cannot be combined with --diarize: %w", transcribe.ErrDiarizeUnsupported)
+	}
+
+	// Subtitles are timed raw transcripts: restructured notes have no timestamps
+	if opts.format.IsSubtitle() && !opts.template.IsZero() {
+		return fmt.Errorf("--format %s cannot be combined with --template (subtitles use the raw transcript)", opts.format)
+	}
+	if err := validateSpeakerLabels(opts.speakerLabels, opts.format); err != nil {
+		return err
+	}
+	if err := validateTimestamps(opts.timestamps, opts.format, opts.template); err != nil {
+		return err
+	}
+	if err := validateStats(opts.stats, opts.diarize, opts.format, opts.stdout); err != nil {
+		return err
+	}
+	if err := validateUnclear(opts.unclear, opts.format); err != nil {
+		return err
+	}
+	if err := validateActions(opts.actions, opts.template, opts.stdout); err != nil {
+		return err
+	}
+
+	// Provenance footers need a comment syntax
+	if err := validateProvenanceFormat(opts.provenance, opts.format); err != nil {
+		return err
+	}
+	if err := validateOutputMode(opts.outputMode, opts.format, opts.provenance.signed()); err != nil {
+		return err
+	}
+
+	// Self-consistency merges several restructurings
+	if err := validateSelfConsistency(opts.selfConsistency, opts.template); err != nil {
+		return err
+	}
+	if err := validateFallbackProvider(opts.fallback, opts.provider, opts.template); err != nil {
+		return err
+	}
+	if opts.keepRaw && opts.template.IsZero() {
+		return fmt.Errorf("--keep-raw-transcript and --raw-output require --template (without template, output is already the raw transcript)")
+	}
+	return validateChunking(opts.chunkTokens, opts.overlap, opts.reduceLevels)
+}
+
+// loadTranscribeCheckpoint returns the checkpoint to use for this run.
+// With resume, a checkpoint matching the input and options is reused;
+// otherwise (or if none matches) a fresh one is returned.
+func loadTranscribeCheckpoint(env *Env, path, inputPath string, opts transcribe.Options, chunkCount int, resume bool) (*transcribe.Checkpoint, error) {
+	fresh, err := transcribe.NewCheckpoint(inputPath, opts, chunkCount)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := transcribe.LoadCheckpoint(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if resume {
+			fmt.Fprintln(env.Stderr, "No checkpoint found, starting from the beginning")
+		}
+		return fresh, nil
+	case err != nil:
+		warnf(env.Stderr, warnCheckpoint, "ignoring checkpoint: %v", err)
+		return fresh, nil
+	case !resume:
+		fmt.Fprintf(env.Stderr, "Found checkpoint from a previous run (use --resume to reuse it), starting over\n")
+		return fresh, nil
+	case !previous.Matches(fresh):
+		warnf(env.Stderr, warnCheckpoint, "checkpoint does not match input file or options, starting over")
+		return fresh, nil
+	}
+
+	fmt.Fprintf(env.Stderr, "Resuming: %d/%d chunks already transcribed\n", len(previous.Results), chunkCount)
+	return previous, nil
+}
@@ -3,9 +3,13 @@ package cli
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,9 +17,16 @@ import (
 
 	"github.com/alnah/go-transcript/internal/audio"
 	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/fetch"
+	"github.com/alnah/go-transcript/internal/ffmpeg"
+	"github.com/alnah/go-transcript/internal/integrations/obsidian"
 	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/notes"
+	"github.com/alnah/go-transcript/internal/progress"
+	"github.com/alnah/go-transcript/internal/redact"
 	"github.com/alnah/go-transcript/internal/template"
 	"github.com/alnah/go-transcript/internal/transcribe"
+	"github.com/alnah/go-transcript/internal/vocab"
 )
 
 // Notes:
@@ -54,25 +65,67 @@ func TestClampParallel(t *testing.T) {
 	}
 }
 
+func TestParseParallel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		wantN    int
+		wantAuto bool
+		wantErr  bool
+	}{
+		{"number", "4", 4, false, false},
+		{"default", "10", 10, false, false},
+		{"out of range is clamped later", "50", 50, false, false},
+		{"auto", "auto", transcribe.MaxRecommendedParallel, true, false},
+		{"auto case insensitive", "AUTO", transcribe.MaxRecommendedParallel, true, false},
+		{"invalid", "fast", 0, false, true},
+		{"empty", "", 0, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			n, auto, err := ParseParallel(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidParallel) {
+					t.Errorf("ParseParallel(%q) error = %v, want ErrInvalidParallel", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseParallel(%q) unexpected error: %v", tt.input, err)
+			}
+			if n != tt.wantN || auto != tt.wantAuto {
+				t.Errorf("ParseParallel(%q) = (%d, %v), want (%d, %v)", tt.input, n, auto, tt.wantN, tt.wantAuto)
+			}
+		})
+	}
+}
+
 func TestDeriveOutputPath(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
 		name     string
 		input    string
+		format   OutputFormat
 		expected string
 	}{
-		{"ogg_to_md", "session.ogg", "session.md"},
-		{"mp3_to_md", "meeting.mp3", "meeting.md"},
-		{"no_extension", "audio", "audio.md"},
-		{"double_extension", "file.backup.ogg", "file.backup.md"},
-		{"path_with_dir", "/home/user/audio.ogg", "/home/user/audio.md"},
+		{"ogg_to_md", "session.ogg", OutputFormat{}, "session.md"},
+		{"mp3_to_md", "meeting.mp3", OutputFormat{}, "meeting.md"},
+		{"no_extension", "audio", OutputFormat{}, "audio.md"},
+		{"double_extension", "file.backup.ogg", OutputFormat{}, "file.backup.md"},
+		{"path_with_dir", "/home/user/audio.ogg", OutputFormat{}, "/home/user/audio.md"},
+		{"ogg_to_srt", "session.ogg", SRTFormat, "session.srt"},
+		{"ogg_to_txt", "session.ogg", TextFormat, "session.txt"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			result := DeriveOutputPath(tt.input)
+			result := DeriveOutputPath(tt.input, tt.format)
 			if result != tt.expected {
 				t.Errorf("DeriveOutputPath(%q) = %q, want %q", tt.input, result, tt.expected)
 			}
@@ -191,7 +244,7 @@ func TestParseTranscribeOptions(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			_, err := ParseTranscribeOptions(tt.inputPath, tt.output, tt.tmpl, tt.diarize, tt.parallel, tt.language, tt.outputLang, tt.provider)
+			_, err := ParseTranscribeOptions(tt.inputPath, tt.output, tt.tmpl, tt.diarize, tt.parallel, tt.language, tt.outputLang, tt.provider, "")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ParseTranscribeOptions() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -218,7 +271,7 @@ func createTranscribeCmd(ctx context.Context) *cobra.Command {
 // mustParseTranscribeOptions is a test helper that parses options or fails the test.
 func mustParseTranscribeOptions(t *testing.T, inputPath, output, tmpl string, diarize bool, parallel int, language, outputLang, provider string) TranscribeOptions {
 	t.Helper()
-	opts, err := ParseTranscribeOptions(inputPath, output, tmpl, diarize, parallel, language, outputLang, provider)
+	opts, err := ParseTranscribeOptions(inputPath, output, tmpl, diarize, parallel, language, outputLang, provider, "")
 	if err != nil {
 		t.Fatalf("ParseTranscribeOptions failed: %v", err)
 	}
@@ -244,7 +297,7 @@ func TestRunTranscribe_FileNotFound(t *testing.T) {
 func TestRunTranscribe_UnsupportedFormat(t *testing.T) {
 	t.Parallel()
 
-	// Create a file with unsupported extension
+	// Create a file with unsupported extension and text content
 	inputPath := createTestAudioFile(t, "audio.txt")
 
 	env, _ := testEnv()
@@ -260,6 +313,56 @@ func TestRunTranscribe_UnsupportedFormat(t *testing.T) {
 	}
 }
 
+func TestRunTranscribe_UnlistedFormat(t *testing.T) {
+	t.Parallel()
+
+	t.Run("binary content left to the input check", func(t *testing.T) {
+		t.Parallel()
+
+		inputPath := filepath.Join(t.TempDir(), "audio.xyz")
+		if err := os.WriteFile(inputPath, []byte{0x00, 0x01, 0x02, 0x03}, 0644); err != nil {
+			t.Fatal(err)
+		}
+		env, _ := testEnv()
+		env.MediaProberFactory = &mockMediaProberFactory{mockMediaProber: &mockMediaProber{
+			ProbeFunc: func(ctx context.Context, path string) (ffmpeg.Metadata, error) {
+				return ffmpeg.Metadata{}, errors.New("Invalid data found when processing input")
+			},
+		}}
+
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "audio.md"), "", false, 5, "", "", "deepseek")
+		err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts)
+		if !errors.Is(err, ErrUnreadableMedia) {
+			t.Errorf("RunTranscribe() error = %v, want ErrUnreadableMedia", err)
+		}
+	})
+
+	t.Run("audio track extracted once decoded", func(t *testing.T) {
+		t.Parallel()
+
+		inputPath := filepath.Join(t.TempDir(), "audio.opus")
+		if err := os.WriteFile(inputPath, []byte("OggS\x00\x02 opus audio"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		env, _ := testEnv()
+		var extracted string
+		env.AudioExtractorFactory = &mockAudioExtractorFactory{mockAudioExtractor: &mockAudioExtractor{
+			ExtractAudioFunc: func(ctx context.Context, videoPath, outputPath string, onProgress func(done, total time.Duration)) error {
+				extracted = videoPath
+				return os.WriteFile(outputPath, []byte("audio"), 0644)
+			},
+		}}
+
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "audio.md"), "", false, 5, "", "", "deepseek")
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+			t.Fatalf("RunTranscribe() unexpected error: %v", err)
+		}
+		if extracted != inputPath {
+			t.Errorf("audio extracted from %q, want %q", extracted, inputPath)
+		}
+	})
+}
+
 func TestRunTranscribe_OutputLangRequiresTemplate(t *testing.T) {
 	t.Parallel()
 
@@ -356,12 +459,13 @@ func TestRunTranscribe_ChunkerFails(t *testing.T) {
 	}
 
 	env := &Env{
-		Stderr:         &syncBuffer{},
-		Getenv:         defaultTestEnv,
-		Now:            fixedTime(time.Now()),
-		FFmpegResolver: &mockFFmpegResolver{},
-		ConfigLoader:   &mockConfigLoader{},
-		ChunkerFactory: chunkerFactory,
+		Stderr:             &syncBuffer{},
+		Getenv:             defaultTestEnv,
+		Now:                fixedTime(time.Now()),
+		FFmpegResolver:     &mockFFmpegResolver{},
+		ConfigLoader:       &mockConfigLoader{},
+		ChunkerFactory:     chunkerFactory,
+		MediaProberFactory: &mockMediaProberFactory{},
 	}
 	cmd := createTranscribeCmd(context.Background())
 
@@ -421,6 +525,7 @@ func TestRunTranscribe_Success(t *testing.T) {
 		FFmpegResolver:     &mockFFmpegResolver{},
 		ConfigLoader:       &mockConfigLoader{},
 		ChunkerFactory:     chunkerFactory,
+		MediaProberFactory: &mockMediaProberFactory{},
 		TranscriberFactory: transcriberFactory,
 	}
 	cmd := createTranscribeCmd(context.Background())
@@ -501,6 +606,7 @@ func TestRunTranscribe_OutputExists(t *testing.T) {
 		FFmpegResolver:     &mockFFmpegResolver{},
 		ConfigLoader:       &mockConfigLoader{},
 		ChunkerFactory:     chunkerFactory,
+		MediaProberFactory: &mockMediaProberFactory{},
 		TranscriberFactory: transcriberFactory,
 	}
 	cmd := createTranscribeCmd(context.Background())
@@ -559,6 +665,7 @@ func TestRunTranscribe_WithLanguage(t *testing.T) {
 		FFmpegResolver:     &mockFFmpegResolver{},
 		ConfigLoader:       &mockConfigLoader{},
 		ChunkerFactory:     chunkerFactory,
+		MediaProberFactory: &mockMediaProberFactory{},
 		TranscriberFactory: transcriberFactory,
 	}
 	cmd := createTranscribeCmd(context.Background())
@@ -618,6 +725,7 @@ func TestRunTranscribe_WithDiarize(t *testing.T) {
 		FFmpegResolver:     &mockFFmpegResolver{},
 		ConfigLoader:       &mockConfigLoader{},
 		ChunkerFactory:     chunkerFactory,
+		MediaProberFactory: &mockMediaProberFactory{},
 		TranscriberFactory: transcriberFactory,
 	}
 	cmd := createTranscribeCmd(context.Background())
@@ -684,6 +792,7 @@ func TestRunTranscribe_DefaultOutputPath(t *testing.T) {
 		FFmpegResolver:     &mockFFmpegResolver{},
 		ConfigLoader:       configLoader,
 		ChunkerFactory:     chunkerFactory,
+		MediaProberFactory: &mockMediaProberFactory{},
 		TranscriberFactory: transcriberFactory,
 	}
 	cmd := createTranscribeCmd(context.Background())
@@ -849,6 +958,7 @@ func TestRunTranscribe_WithTemplate(t *testing.T) {
 		FFmpegResolver:      &mockFFmpegResolver{},
 		ConfigLoader:        &mockConfigLoader{},
 		ChunkerFactory:      chunkerFactory,
+		MediaProberFactory:  &mockMediaProberFactory{},
 		TranscriberFactory:  transcriberFactory,
 		RestructurerFactory: restructurerFactory,
 	}
@@ -881,49 +991,38 @@ func TestRunTranscribe_WithTemplate(t *testing.T) {
 	}
 }
 
-func TestRunTranscribe_WithTemplateAndLanguages(t *testing.T) {
+func TestRunTranscribe_PodcastTemplateUsesTimestamps(t *testing.T) {
 	t.Parallel()
 
-	inputPath := createTestAudioFile(t, "audio.ogg")
-	outputDir := t.TempDir()
-	outputPath := filepath.Join(outputDir, "output.md")
+	inputPath := createTestAudioFile(t, "episode.mp3")
+	outputPath := filepath.Join(t.TempDir(), "notes.md")
 
 	chunkPath := filepath.Join(t.TempDir(), "chunk_0.ogg")
-	if err := os.WriteFile(chunkPath, []byte("chunk"), 0644); err != nil {
-		t.Fatalf("failed to create chunk: %v", err)
-	}
-
-	chunker := &mockChunker{
-		ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
-			return []audio.Chunk{{Path: chunkPath, Index: 0}}, nil
-		},
+	if err := os.WriteFile(chunkPath, []byte("chunk audio"), 0644); err != nil {
+		t.Fatalf("failed to create chunk file: %v", err)
 	}
 	chunkerFactory := &mockChunkerFactory{
 		NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
-			return chunker, nil
+			return &mockChunker{
+				ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+					return []audio.Chunk{{Path: chunkPath, Index: 0, EndTime: 5 * time.Minute}}, nil
+				},
+			}, nil
 		},
 	}
-
 	transcriber := &mockTranscriber{
 		TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
-			return "transcribed", nil
-		},
-	}
-	transcriberFactory := &mockTranscriberFactory{
-		NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
-			return transcriber
-		},
-	}
-
-	var capturedLang lang.Language
-	mockMR := &mockMapReduceRestructurer{
-		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
-			capturedLang = outputLang
-			return "restructured", false, nil
+			return `[{"start":0,"end":2,"speaker":"A","text":"Welcome."},{"start":10,"end":12,"speaker":"B","text":"New topic."}]`, nil
 		},
 	}
+	var restructured string
 	restructurerFactory := &mockRestructurerFactory{
-		mockMapReducer: mockMR,
+		mockMapReducer: &mockMapReduceRestructurer{
+			RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+				restructured = transcript
+				return "# Show notes", false, nil
+			},
+		},
 	}
 
 	env := &Env{
@@ -933,364 +1032,520 @@ func TestRunTranscribe_WithTemplateAndLanguages(t *testing.T) {
 		FFmpegResolver:      &mockFFmpegResolver{},
 		ConfigLoader:        &mockConfigLoader{},
 		ChunkerFactory:      chunkerFactory,
-		TranscriberFactory:  transcriberFactory,
+		MediaProberFactory:  &mockMediaProberFactory{},
+		TranscriberFactory:  &mockTranscriberFactory{NewTranscriberFunc: func(string) transcribe.Transcriber { return transcriber }},
 		RestructurerFactory: restructurerFactory,
 	}
-	cmd := createTranscribeCmd(context.Background())
 
-	// Test: input language fr, output language en
-	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "meeting", false, 5, "fr", "en", "deepseek")
-	err := RunTranscribe(cmd, env, opts)
-	if err != nil {
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "podcast", true, 5, "", "", "deepseek")
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
 		t.Fatalf("RunTranscribe() unexpected error: %v", err)
 	}
 
-	// Output language should be "en" (explicitly specified)
-	if capturedLang.String() != "en" {
-		t.Errorf("restructurer output language = %q, want %q", capturedLang.String(), "en")
+	calls := transcriber.TranscribeCalls()
+	if len(calls) != 1 || !calls[0].Opts.Timestamps || !calls[0].Opts.Diarize {
+		t.Errorf("Transcribe() calls = %+v, want timestamps and diarization", calls)
+	}
+	want := "[00:00:00] A: Welcome.\n[pause 8s]\n[00:00:10] B: New topic.\n"
+	if restructured != want {
+		t.Errorf("restructured transcript = %q, want %q", restructured, want)
 	}
 }
 
-func TestRunTranscribe_WithTemplateInheritLanguage(t *testing.T) {
+func TestRunTranscribe_Anchors(t *testing.T) {
 	t.Parallel()
 
-	inputPath := createTestAudioFile(t, "audio.ogg")
-	outputDir := t.TempDir()
-	outputPath := filepath.Join(outputDir, "output.md")
-
-	chunkPath := filepath.Join(t.TempDir(), "chunk_0.ogg")
-	if err := os.WriteFile(chunkPath, []byte("chunk"), 0644); err != nil {
-		t.Fatalf("failed to create chunk: %v", err)
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		if !opts.Timestamps {
+			return "", errors.New("timestamps not requested")
+		}
+		return `[{"start":30,"end":32,"text":"` + filepath.Base(audioPath) + `"}]`, nil
+	})
+	var (
+		restructured string
+		anchored     bool
+	)
+	env.RestructurerFactory = &mockRestructurerFactory{
+		mockMapReducer: &mockMapReduceRestructurer{
+			RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+				restructured, anchored = transcript, tmpl.IsAnchored()
+				return "# Notes", false, nil
+			},
+		},
 	}
 
-	chunker := &mockChunker{
-		ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
-			return []audio.Chunk{{Path: chunkPath, Index: 0}}, nil
-		},
+	opts := mustParseTranscribeOptions(t, createTestAudioFile(t, "audio.ogg"), filepath.Join(t.TempDir(), "notes.md"), "notes", false, 5, "", "", "deepseek")
+	var err error
+	if opts.template, err = anchorTemplate(opts.template, true); err != nil {
+		t.Fatal(err)
 	}
-	chunkerFactory := &mockChunkerFactory{
-		NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
-			return chunker, nil
-		},
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
 	}
 
-	transcriber := &mockTranscriber{
-		TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
-			return "transcribed", nil
-		},
+	if !anchored {
+		t.Error("restructured with the template, want its anchored variant")
 	}
-	transcriberFactory := &mockTranscriberFactory{
-		NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
-			return transcriber
-		},
+	want := "[00:00:30] chunk_0.ogg\n[pause 298s]\n[00:05:30] chunk_1.ogg\n"
+	if restructured != want {
+		t.Errorf("restructured transcript = %q, want %q", restructured, want)
 	}
+}
 
-	var capturedLang lang.Language
-	mockMR := &mockMapReduceRestructurer{
-		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
-			capturedLang = outputLang
-			return "restructured", false, nil
-		},
+func TestAnchorTemplate(t *testing.T) {
+	t.Parallel()
+
+	if got, err := anchorTemplate(template.NotesName, false); err != nil || got.IsAnchored() {
+		t.Errorf("anchorTemplate(notes, false) = %v, %v, want the template unchanged", got, err)
 	}
-	restructurerFactory := &mockRestructurerFactory{
-		mockMapReducer: mockMR,
+	if got, err := anchorTemplate(template.NotesName, true); err != nil || !got.IsAnchored() {
+		t.Errorf("anchorTemplate(notes, true) = %v, %v, want the anchored variant", got, err)
 	}
-
-	env := &Env{
-		Stderr:              &syncBuffer{},
-		Getenv:              defaultTestEnv,
-		Now:                 fixedTime(time.Now()),
-		FFmpegResolver:      &mockFFmpegResolver{},
-		ConfigLoader:        &mockConfigLoader{},
-		ChunkerFactory:      chunkerFactory,
-		TranscriberFactory:  transcriberFactory,
-		RestructurerFactory: restructurerFactory,
+	if _, err := anchorTemplate(template.Name{}, true); err == nil || !strings.Contains(err.Error(), "--template") {
+		t.Errorf("anchorTemplate(zero, true) error = %v, want --template required", err)
 	}
-	cmd := createTranscribeCmd(context.Background())
+}
 
-	// Test: input language fr, no output language -> should inherit fr
-	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "meeting", false, 5, "fr", "", "deepseek")
-	err := RunTranscribe(cmd, env, opts)
-	if err != nil {
-		t.Fatalf("RunTranscribe() unexpected error: %v", err)
-	}
+func TestLevelTemplate(t *testing.T) {
+	t.Parallel()
 
-	// Output language should be "fr" (inherited from input)
-	if capturedLang.String() != "fr" {
-		t.Errorf("restructurer output language = %q, want %q (inherited from input)", capturedLang.String(), "fr")
+	if got, err := levelTemplate(template.NotesName, ""); err != nil || !got.Level().IsZero() {
+		t.Errorf("levelTemplate(notes, \"\") = %v, %v, want the template unchanged", got, err)
+	}
+	if got, err := levelTemplate(template.NotesName, "short"); err != nil || got.Level() != template.ShortLevel {
+		t.Errorf("levelTemplate(notes, short) = %v, %v, want the short variant", got, err)
+	}
+	if _, err := levelTemplate(template.NotesName, "brief"); !errors.Is(err, template.ErrInvalidLevel) {
+		t.Errorf("levelTemplate(notes, brief) error = %v, want ErrInvalidLevel", err)
+	}
+	if _, err := levelTemplate(template.Name{}, "short"); err == nil || !strings.Contains(err.Error(), "--template") {
+		t.Errorf("levelTemplate(zero, short) error = %v, want --template required", err)
 	}
 }
 
-func TestRunTranscribe_RestructureError(t *testing.T) {
+func TestRunTranscribe_Video(t *testing.T) {
 	t.Parallel()
 
-	inputPath := createTestAudioFile(t, "audio.ogg")
-	outputDir := t.TempDir()
-	outputPath := filepath.Join(outputDir, "output.md")
+	t.Run("chunks the extracted audio", func(t *testing.T) {
+		t.Parallel()
 
-	chunkPath := filepath.Join(t.TempDir(), "chunk_0.ogg")
-	if err := os.WriteFile(chunkPath, []byte("chunk"), 0644); err != nil {
-		t.Fatalf("failed to create chunk: %v", err)
-	}
+		var chunked string
+		stderr := &syncBuffer{}
+		env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "Hello from the meeting.", nil
+		})
+		env.ChunkerFactory = &mockChunkerFactory{mockChunker: &mockChunker{
+			ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+				chunked = audioPath
+				return []audio.Chunk{{Path: audioPath, EndTime: time.Minute}}, nil
+			},
+		}}
+		var extracted string
+		env.AudioExtractorFactory = &mockAudioExtractorFactory{mockAudioExtractor: &mockAudioExtractor{
+			ExtractAudioFunc: func(ctx context.Context, videoPath, outputPath string, onProgress func(done, total time.Duration)) error {
+				extracted = videoPath
+				onProgress(30*time.Second, time.Minute)
+				return os.WriteFile(outputPath, []byte("extracted audio"), 0600)
+			},
+		}}
+		var phases []progress.Phase
+		var extraction []time.Duration
+		ctx := progress.WithHooks(context.Background(), progress.Hooks{
+			OnPhaseChange: func(phase progress.Phase) { phases = append(phases, phase) },
+			OnExtraction:  func(done, total time.Duration) { extraction = append(extraction, done, total) },
+		})
 
-	chunker := &mockChunker{
-		ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
-			return []audio.Chunk{{Path: chunkPath, Index: 0}}, nil
-		},
-	}
-	chunkerFactory := &mockChunkerFactory{
-		NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
-			return chunker, nil
-		},
-	}
+		inputPath := createTestAudioFile(t, "meeting.mkv")
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "meeting.md"), "", false, 1, "", "", "deepseek")
+		if err := RunTranscribe(createTranscribeCmd(ctx), env, opts); err != nil {
+			t.Fatalf("RunTranscribe() unexpected error: %v", err)
+		}
 
-	transcriber := &mockTranscriber{
-		TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
-			return "transcribed", nil
-		},
-	}
-	transcriberFactory := &mockTranscriberFactory{
-		NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
-			return transcriber
-		},
-	}
+		if extracted != inputPath {
+			t.Errorf("ExtractAudio() video = %q, want %q", extracted, inputPath)
+		}
+		if chunked == "" || chunked == inputPath {
+			t.Errorf("chunked %q, want the extracted audio", chunked)
+		}
+		if _, err := os.Stat(chunked); !os.IsNotExist(err) {
+			t.Errorf("extracted audio %q still exists after the run", chunked)
+		}
+		if len(phases) < 2 || phases[0] != progress.PhaseExtracting || phases[1] != progress.PhaseChunking {
+			t.Errorf("phases = %v, want extracting then chunking", phases)
+		}
+		if !slices.Equal(extraction, []time.Duration{30 * time.Second, time.Minute}) {
+			t.Errorf("extraction progress = %v, want 30s of 1m", extraction)
+		}
+		if !strings.Contains(stderr.String(), "Extracting audio from video... done") {
+			t.Errorf("stderr = %q, want the extraction reported", stderr.String())
+		}
+	})
 
-	restructureErr := errors.New("API error during restructuring")
-	mockMR := &mockMapReduceRestructurer{
-		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
-			return "", false, restructureErr
-		},
-	}
-	restructurerFactory := &mockRestructurerFactory{
-		mockMapReducer: mockMR,
-	}
+	t.Run("joins the files of a segmented recording", func(t *testing.T) {
+		t.Parallel()
 
-	env := &Env{
-		Stderr:              &syncBuffer{},
-		Getenv:              defaultTestEnv,
-		Now:                 fixedTime(time.Now()),
-		FFmpegResolver:      &mockFFmpegResolver{},
-		ConfigLoader:        &mockConfigLoader{},
-		ChunkerFactory:      chunkerFactory,
-		TranscriberFactory:  transcriberFactory,
-		RestructurerFactory: restructurerFactory,
-	}
-	cmd := createTranscribeCmd(context.Background())
+		var chunked string
+		stderr := &syncBuffer{}
+		env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "Hello from the meeting.", nil
+		})
+		env.ChunkerFactory = &mockChunkerFactory{mockChunker: &mockChunker{
+			ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+				chunked = audioPath
+				return []audio.Chunk{{Path: audioPath, EndTime: time.Minute}}, nil
+			},
+		}}
+		var extracted string
+		env.AudioExtractorFactory = &mockAudioExtractorFactory{mockAudioExtractor: &mockAudioExtractor{
+			ExtractAudioFunc: func(ctx context.Context, videoPath, outputPath string, onProgress func(done, total time.Duration)) error {
+				extracted = videoPath
+				return os.WriteFile(outputPath, []byte("joined audio"), 0600)
+			},
+		}}
+
+		inputPath := createTestAudioFile(t, "day.ffconcat")
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "day.md"), "", false, 1, "", "", "deepseek")
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+			t.Fatalf("RunTranscribe() unexpected error: %v", err)
+		}
 
-	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "brainstorm", false, 5, "", "", "deepseek")
-	err := RunTranscribe(cmd, env, opts)
-	if err == nil {
-		t.Fatal("RunTranscribe() expected error when restructuring fails")
-	}
-	if !errors.Is(err, restructureErr) {
-		t.Errorf("RunTranscribe() error = %v, want restructureErr", err)
-	}
+		if extracted != inputPath {
+			t.Errorf("ExtractAudio() input = %q, want %q", extracted, inputPath)
+		}
+		if chunked == "" || chunked == inputPath {
+			t.Errorf("chunked %q, want the joined audio", chunked)
+		}
+		if !strings.Contains(stderr.String(), "Joining recording segments... done") {
+			t.Errorf("stderr = %q, want the joining reported", stderr.String())
+		}
+	})
+
+	t.Run("no audio track", func(t *testing.T) {
+		t.Parallel()
+
+		env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			t.Error("Transcribe() called on a video without audio")
+			return "", nil
+		})
+		env.AudioExtractorFactory = &mockAudioExtractorFactory{mockAudioExtractor: &mockAudioExtractor{
+			ExtractAudioFunc: func(ctx context.Context, videoPath, outputPath string, onProgress func(done, total time.Duration)) error {
+				return fmt.Errorf("%w in %s", audio.ErrNoAudioTrack, videoPath)
+			},
+		}}
+
+		opts := mustParseTranscribeOptions(t, createTestAudioFile(t, "screencast.mov"), filepath.Join(t.TempDir(), "out.md"), "", false, 1, "", "", "deepseek")
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); !errors.Is(err, audio.ErrNoAudioTrack) {
+			t.Errorf("RunTranscribe() error = %v, want ErrNoAudioTrack", err)
+		}
+	})
 }
 
-func TestRunTranscribe_EmptyTranscriptSkipsRestructure(t *testing.T) {
+func TestRunTranscribe_Preprocessing(t *testing.T) {
 	t.Parallel()
 
-	inputPath := createTestAudioFile(t, "audio.ogg")
-	outputDir := t.TempDir()
-	outputPath := filepath.Join(outputDir, "output.md")
+	t.Run("chunks the preprocessed audio", func(t *testing.T) {
+		t.Parallel()
 
-	chunkPath := filepath.Join(t.TempDir(), "chunk_0.ogg")
-	if err := os.WriteFile(chunkPath, []byte("chunk"), 0644); err != nil {
-		t.Fatalf("failed to create chunk: %v", err)
-	}
+		var chunked string
+		stderr := &syncBuffer{}
+		env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "Hello from the cafe.", nil
+		})
+		env.ChunkerFactory = &mockChunkerFactory{mockChunker: &mockChunker{
+			ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+				chunked = audioPath
+				return []audio.Chunk{{Path: audioPath, EndTime: time.Minute}}, nil
+			},
+		}}
+		preprocessor := &mockPreprocessor{}
+		env.PreprocessorFactory = &mockPreprocessorFactory{mockPreprocessor: preprocessor}
+		env.AudioExtractorFactory = &mockAudioExtractorFactory{mockAudioExtractor: &mockAudioExtractor{
+			ExtractAudioFunc: func(ctx context.Context, videoPath, outputPath string, onProgress func(done, total time.Duration)) error {
+				t.Error("ExtractAudio() called, want the preprocessor to extract the audio")
+				return nil
+			},
+		}}
+
+		inputPath := createTestAudioFile(t, "cafe.mkv")
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "cafe.md"), "", false, 1, "", "", "deepseek")
+		opts.preprocessing = audio.Preprocessing{Denoise: true, Normalize: true}
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+			t.Fatalf("RunTranscribe() unexpected error: %v", err)
+		}
 
-	chunker := &mockChunker{
-		ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
-			return []audio.Chunk{{Path: chunkPath, Index: 0}}, nil
-		},
-	}
-	chunkerFactory := &mockChunkerFactory{
-		NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
-			return chunker, nil
-		},
-	}
-
-	// Transcriber returns empty/whitespace
-	transcriber := &mockTranscriber{
-		TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
-			return "   ", nil // Whitespace only
-		},
-	}
-	transcriberFactory := &mockTranscriberFactory{
-		NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
-			return transcriber
-		},
-	}
+		calls := preprocessor.Calls()
+		if len(calls) != 1 || calls[0].inputPath != inputPath || calls[0].p != opts.preprocessing {
+			t.Errorf("Preprocess() calls = %+v, want one of %q with %+v", calls, inputPath, opts.preprocessing)
+		}
+		if chunked == "" || chunked == inputPath {
+			t.Errorf("chunked %q, want the preprocessed audio", chunked)
+		}
+		if _, err := os.Stat(chunked); !os.IsNotExist(err) {
+			t.Errorf("preprocessed audio %q still exists after the run", chunked)
+		}
+		if !strings.Contains(stderr.String(), "Reducing noise and normalizing loudness... done") {
+			t.Errorf("stderr = %q, want the preprocessing reported", stderr.String())
+		}
+	})
 
-	var restructureCalled bool
-	mockMR := &mockMapReduceRestructurer{
-		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
-			restructureCalled = true
-			return "should not be called", false, nil
-		},
-	}
-	restructurerFactory := &mockRestructurerFactory{
-		mockMapReducer: mockMR,
-	}
+	t.Run("messages of each stage", func(t *testing.T) {
+		t.Parallel()
 
-	env := &Env{
-		Stderr:              &syncBuffer{},
-		Getenv:              defaultTestEnv,
-		Now:                 fixedTime(time.Now()),
-		FFmpegResolver:      &mockFFmpegResolver{},
-		ConfigLoader:        &mockConfigLoader{},
-		ChunkerFactory:      chunkerFactory,
-		TranscriberFactory:  transcriberFactory,
-		RestructurerFactory: restructurerFactory,
-	}
-	cmd := createTranscribeCmd(context.Background())
+		tests := []struct {
+			p    audio.Preprocessing
+			want string
+		}{
+			{audio.Preprocessing{Denoise: true}, "Reducing noise... done"},
+			{audio.Preprocessing{Normalize: true}, "Normalizing loudness... done"},
+		}
+		for _, tt := range tests {
+			stderr := &syncBuffer{}
+			env := &Env{Stderr: stderr, PreprocessorFactory: &mockPreprocessorFactory{}}
+			_, cleanup, err := preprocessAudio(context.Background(), env, "/usr/bin/ffmpeg", createTestAudioFile(t, "talk.ogg"), tt.p)
+			if err != nil {
+				t.Fatalf("preprocessAudio(%+v) unexpected error: %v", tt.p, err)
+			}
+			cleanup()
+			if !strings.Contains(stderr.String(), tt.want) {
+				t.Errorf("preprocessAudio(%+v) stderr = %q, want %q", tt.p, stderr.String(), tt.want)
+			}
+		}
+	})
 
-	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "brainstorm", false, 5, "", "", "deepseek")
-	err := RunTranscribe(cmd, env, opts)
-	if err != nil {
-		t.Fatalf("RunTranscribe() unexpected error: %v", err)
-	}
+	t.Run("preprocessing failure", func(t *testing.T) {
+		t.Parallel()
 
-	// Restructure should NOT be called for empty transcript
-	if restructureCalled {
-		t.Error("restructure called = true, want false for empty/whitespace transcript")
-	}
+		env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			t.Error("Transcribe() called after preprocessing failed")
+			return "", nil
+		})
+		env.PreprocessorFactory = &mockPreprocessorFactory{mockPreprocessor: &mockPreprocessor{
+			PreprocessFunc: func(ctx context.Context, inputPath, outputPath string, p audio.Preprocessing, onProgress func(done, total time.Duration)) error {
+				return fmt.Errorf("%w: exit status 1", audio.ErrPreprocessingFailed)
+			},
+		}}
+
+		opts := mustParseTranscribeOptions(t, createTestAudioFile(t, "noisy.ogg"), filepath.Join(t.TempDir(), "out.md"), "", false, 1, "", "", "deepseek")
+		opts.preprocessing = audio.Preprocessing{Denoise: true}
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); !errors.Is(err, audio.ErrPreprocessingFailed) {
+			t.Errorf("RunTranscribe() error = %v, want ErrPreprocessingFailed", err)
+		}
+	})
 }
 
-// ---------------------------------------------------------------------------
-// Tests for validation order in runTranscribe
-// ---------------------------------------------------------------------------
-
-func TestRunTranscribe_ValidationOrder(t *testing.T) {
+func TestRunTranscribe_URL(t *testing.T) {
 	t.Parallel()
 
-	t.Run("file not found first", func(t *testing.T) {
-		t.Parallel()
-
-		env, _ := testEnv()
-		cmd := createTranscribeCmd(context.Background())
+	const episodeURL = "https://example.com/shows/episode.mp3"
 
-		opts := mustParseTranscribeOptions(t, "/nonexistent/path.ogg", "", "", false, 5, "", "", "deepseek")
-		err := RunTranscribe(cmd, env, opts)
-		if err == nil {
-			t.Fatal("RunTranscribe() expected error")
-		}
-		if !errors.Is(err, ErrFileNotFound) {
-			t.Errorf("RunTranscribe() error = %v, want ErrFileNotFound", err)
+	// urlTestEnv returns an env whose cache directory is a temporary directory.
+	urlTestEnv := func(t *testing.T, stderr *syncBuffer, transcribeFunc func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error)) (*Env, string) {
+		t.Helper()
+		env := checkpointTestEnv(t, stderr, transcribeFunc)
+		cache := t.TempDir()
+		env.Getenv = func(key string) string {
+			if key == "XDG_CACHE_HOME" {
+				return cache
+			}
+			return defaultTestEnv(key)
 		}
-	})
+		return env, cache
+	}
 
-	t.Run("format check before api key", func(t *testing.T) {
+	t.Run("transcribes the downloaded file", func(t *testing.T) {
 		t.Parallel()
 
-		// Create file with bad extension
-		path := createTestAudioFile(t, "audio.xyz")
-		env := &Env{
-			Stderr:         &syncBuffer{},
-			Getenv:         func(string) string { return "" }, // No API key
-			Now:            fixedTime(time.Now()),
-			FFmpegResolver: &mockFFmpegResolver{},
-			ConfigLoader:   &mockConfigLoader{},
+		stderr := &syncBuffer{}
+		env, cache := urlTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "text " + filepath.Base(audioPath), nil
+		})
+		var chunked string
+		env.ChunkerFactory = &mockChunkerFactory{mockChunker: &mockChunker{
+			ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+				chunked = audioPath
+				return []audio.Chunk{{Path: filepath.Join(t.TempDir(), "chunk_0.ogg"), EndTime: time.Minute}}, nil
+			},
+		}}
+		downloads := &mockDownloaderFactory{mockDownloader: &mockDownloader{
+			DownloadFunc: func(ctx context.Context, rawURL, dir string, onProgress func(done, total int64)) (string, error) {
+				onProgress(512, 1024)
+				return (&mockDownloader{}).Download(ctx, rawURL, dir, nil)
+			},
+		}}
+		env.DownloaderFactory = downloads
+		var phases []progress.Phase
+		var fetched []int64
+		ctx := progress.WithHooks(context.Background(), progress.Hooks{
+			OnPhaseChange: func(phase progress.Phase) { phases = append(phases, phase) },
+			OnDownload:    func(done, total int64) { fetched = append(fetched, done, total) },
+		})
+
+		outputPath := filepath.Join(t.TempDir(), "episode.md")
+		opts := mustParseTranscribeOptions(t, episodeURL, outputPath, "", false, 1, "", "", "deepseek")
+		opts.maxDownload = 500 << 20
+		if err := RunTranscribe(createTranscribeCmd(ctx), env, opts); err != nil {
+			t.Fatalf("RunTranscribe() unexpected error: %v", err)
 		}
-		cmd := createTranscribeCmd(context.Background())
 
-		opts := mustParseTranscribeOptions(t, path, "", "", false, 5, "", "", "deepseek")
-		err := RunTranscribe(cmd, env, opts)
-		if err == nil {
-			t.Fatal("RunTranscribe() expected error")
+		if filepath.Base(chunked) != "episode.mp3" || !strings.HasPrefix(chunked, cache) {
+			t.Errorf("chunked %q, want episode.mp3 downloaded in the cache %s", chunked, cache)
 		}
-		// Format error should come before API key error
-		if !errors.Is(err, ErrUnsupportedFormat) {
-			t.Errorf("RunTranscribe() error = %v, want ErrUnsupportedFormat", err)
+		if got := downloads.MaxSizes(); !slices.Equal(got, []int64{500 << 20}) {
+			t.Errorf("downloader size limits = %v, want [500 MB]", got)
+		}
+		if _, err := os.Stat(chunked); !os.IsNotExist(err) {
+			t.Errorf("download %q still exists after the run", chunked)
+		}
+		if len(phases) < 2 || phases[0] != progress.PhaseDownloading || phases[1] != progress.PhaseChunking {
+			t.Errorf("phases = %v, want downloading then chunking", phases)
+		}
+		if !slices.Equal(fetched, []int64{512, 1024}) {
+			t.Errorf("download progress = %v, want 512 of 1024 bytes", fetched)
+		}
+		if !strings.Contains(stderr.String(), "Downloading... done: episode.mp3") {
+			t.Errorf("stderr = %q, want the download reported", stderr.String())
+		}
+		if content, _ := os.ReadFile(outputPath); string(content) != "text chunk_0.ogg" {
+			t.Errorf("output = %q", content)
 		}
 	})
 
-	t.Run("output lang requires template", func(t *testing.T) {
+	t.Run("download kept when transcription fails", func(t *testing.T) {
 		t.Parallel()
 
-		path := createTestAudioFile(t, "audio.ogg")
-		env := &Env{
-			Stderr:         &syncBuffer{},
-			Getenv:         func(string) string { return "" }, // No API key
-			Now:            fixedTime(time.Now()),
-			FFmpegResolver: &mockFFmpegResolver{},
-			ConfigLoader:   &mockConfigLoader{},
-		}
-		cmd := createTranscribeCmd(context.Background())
-
-		opts := mustParseTranscribeOptions(t, path, "", "", false, 5, "", "en", "deepseek")
-		err := RunTranscribe(cmd, env, opts)
-		if err == nil {
-			t.Fatal("RunTranscribe() expected error")
+		apiErr := errors.New("server error")
+		env, _ := urlTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "", apiErr
+		})
+		var chunked string
+		env.ChunkerFactory = &mockChunkerFactory{mockChunker: &mockChunker{
+			ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+				chunked = audioPath
+				return []audio.Chunk{{Path: filepath.Join(t.TempDir(), "chunk_0.ogg"), EndTime: time.Minute}}, nil
+			},
+		}}
+		env.DownloaderFactory = &mockDownloaderFactory{}
+
+		opts := mustParseTranscribeOptions(t, episodeURL, filepath.Join(t.TempDir(), "episode.md"), "", false, 1, "", "", "deepseek")
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); !errors.Is(err, apiErr) {
+			t.Fatalf("RunTranscribe() error = %v, want apiErr", err)
 		}
-		// Should fail with translate requires template error before API key check
-		if !strings.Contains(err.Error(), "translate") {
-			t.Errorf("RunTranscribe() error = %q, want containing %q", err.Error(), "translate")
+		if _, err := os.Stat(chunked); err != nil {
+			t.Errorf("download not kept for the next run: %v", err)
 		}
 	})
 
-	t.Run("api key check last", func(t *testing.T) {
+	t.Run("download error", func(t *testing.T) {
 		t.Parallel()
 
-		path := createTestAudioFile(t, "audio.ogg")
-		env := &Env{
-			Stderr:         &syncBuffer{},
-			Getenv:         func(string) string { return "" }, // No API key
-			Now:            fixedTime(time.Now()),
-			FFmpegResolver: &mockFFmpegResolver{},
-			ConfigLoader:   &mockConfigLoader{},
-		}
-		cmd := createTranscribeCmd(context.Background())
+		env, _ := urlTestEnv(t, &syncBuffer{}, nil)
+		env.DownloaderFactory = &mockDownloaderFactory{mockDownloader: &mockDownloader{
+			DownloadFunc: func(ctx context.Context, rawURL, dir string, onProgress func(done, total int64)) (string, error) {
+				return "", fmt.Errorf("%s is 3000 MB: %w", rawURL, fetch.ErrTooLarge)
+			},
+		}}
 
-		opts := mustParseTranscribeOptions(t, path, "", "", false, 5, "", "", "deepseek")
-		err := RunTranscribe(cmd, env, opts)
-		if err == nil {
-			t.Fatal("RunTranscribe() expected error")
-		}
-		if !errors.Is(err, ErrAPIKeyMissing) {
-			t.Errorf("RunTranscribe() error = %v, want ErrAPIKeyMissing", err)
+		opts := mustParseTranscribeOptions(t, episodeURL, filepath.Join(t.TempDir(), "episode.md"), "", false, 1, "", "", "deepseek")
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); !errors.Is(err, fetch.ErrTooLarge) {
+			t.Errorf("RunTranscribe() error = %v, want ErrTooLarge", err)
 		}
 	})
 }
 
-// ---------------------------------------------------------------------------
-// Tests for provider flag
-// ---------------------------------------------------------------------------
+func TestTranscribeCmd_InvalidMaxDownload(t *testing.T) {
+	t.Parallel()
 
-func TestRunTranscribe_DeepSeekProvider_MissingKey(t *testing.T) {
+	env, _ := testEnv()
+	cmd := TranscribeCmd(env)
+	cmd.SetArgs([]string{"https://example.com/episode.mp3", "--max-download", "lots"})
+
+	if err := cmd.Execute(); !errors.Is(err, ErrInvalidMaxDownload) {
+		t.Errorf("cmd.Execute() error = %v, want ErrInvalidMaxDownload", err)
+	}
+}
+
+func TestRunTranscribe_ReportsProgress(t *testing.T) {
 	t.Parallel()
 
 	inputPath := createTestAudioFile(t, "audio.ogg")
-	outputDir := t.TempDir()
-	outputPath := filepath.Join(outputDir, "output.md")
+	outputPath := filepath.Join(t.TempDir(), "output.md")
 
-	// Only provide OpenAI key, not DeepSeek key
-	env := &Env{
-		Stderr: &syncBuffer{},
-		Getenv: func(key string) string {
-			if key == EnvOpenAIAPIKey {
-				return "test-openai-key"
+	chunkPath := filepath.Join(t.TempDir(), "chunk_0.ogg")
+	if err := os.WriteFile(chunkPath, []byte("chunk audio"), 0644); err != nil {
+		t.Fatalf("failed to create chunk file: %v", err)
+	}
+	chunkerFactory := &mockChunkerFactory{
+		NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
+			return &mockChunker{
+				ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+					return []audio.Chunk{{Path: chunkPath, Index: 0, EndTime: 5 * time.Minute}}, nil
+				},
+			}, nil
+		},
+	}
+	transcriberFactory := &mockTranscriberFactory{
+		NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+			return &mockTranscriber{
+				TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+					return "Raw transcript content here.", nil
+				},
 			}
-			return "" // No DeepSeek key
 		},
-		Now:            fixedTime(time.Now()),
-		FFmpegResolver: &mockFFmpegResolver{},
-		ConfigLoader:   &mockConfigLoader{},
 	}
-	cmd := createTranscribeCmd(context.Background())
+	restructurerFactory := &mockRestructurerFactory{
+		mockMapReducer: &mockMapReduceRestructurer{
+			RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+				return "# Restructured Output", false, nil
+			},
+		},
+	}
 
-	// Use template to trigger restructuring (which requires DeepSeek key)
+	var (
+		mu     sync.Mutex
+		phases []progress.Phase
+		chunks int
+	)
+	ctx := progress.WithHooks(context.Background(), progress.Hooks{
+		OnPhaseChange: func(phase progress.Phase) {
+			mu.Lock()
+			defer mu.Unlock()
+			phases = append(phases, phase)
+		},
+		OnChunkDone: func(index, total int, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			chunks++
+		},
+	})
+
+	env := &Env{
+		Stderr:              &syncBuffer{},
+		Getenv:              defaultTestEnv,
+		Now:                 fixedTime(time.Now()),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        &mockConfigLoader{},
+		ChunkerFactory:      chunkerFactory,
+		MediaProberFactory:  &mockMediaProberFactory{},
+		TranscriberFactory:  transcriberFactory,
+		RestructurerFactory: restructurerFactory,
+	}
 	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "brainstorm", false, 5, "", "", "deepseek")
-	err := RunTranscribe(cmd, env, opts)
-	if err == nil {
-		t.Fatal("RunTranscribe() expected error for missing DeepSeek API key")
+	if err := RunTranscribe(createTranscribeCmd(ctx), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
 	}
-	if !errors.Is(err, ErrDeepSeekKeyMissing) {
-		t.Errorf("RunTranscribe() error = %v, want ErrDeepSeekKeyMissing", err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []progress.Phase{progress.PhaseChunking, progress.PhaseTranscribing, progress.PhaseRestructuring}
+	if !slices.Equal(phases, want) {
+		t.Errorf("phases = %v, want %v", phases, want)
+	}
+	if chunks != 1 {
+		t.Errorf("chunks done = %d, want 1", chunks)
 	}
 }
 
-func TestRunTranscribe_OpenAIProvider_ReusesKey(t *testing.T) {
+func TestRunTranscribe_WithTemplateAndLanguages(t *testing.T) {
 	t.Parallel()
 
 	inputPath := createTestAudioFile(t, "audio.ogg")
@@ -1324,8 +1579,10 @@ func TestRunTranscribe_OpenAIProvider_ReusesKey(t *testing.T) {
 		},
 	}
 
+	var capturedLang lang.Language
 	mockMR := &mockMapReduceRestructurer{
 		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+			capturedLang = outputLang
 			return "restructured", false, nil
 		},
 	}
@@ -1333,33 +1590,33 @@ func TestRunTranscribe_OpenAIProvider_ReusesKey(t *testing.T) {
 		mockMapReducer: mockMR,
 	}
 
-	// Only provide OpenAI key - should work with --provider openai
 	env := &Env{
-		Stderr: &syncBuffer{},
-		Getenv: func(key string) string {
-			if key == EnvOpenAIAPIKey {
-				return "test-openai-key"
-			}
-			return "" // No DeepSeek key
-		},
+		Stderr:              &syncBuffer{},
+		Getenv:              defaultTestEnv,
 		Now:                 fixedTime(time.Now()),
 		FFmpegResolver:      &mockFFmpegResolver{},
 		ConfigLoader:        &mockConfigLoader{},
 		ChunkerFactory:      chunkerFactory,
+		MediaProberFactory:  &mockMediaProberFactory{},
 		TranscriberFactory:  transcriberFactory,
 		RestructurerFactory: restructurerFactory,
 	}
 	cmd := createTranscribeCmd(context.Background())
 
-	// Use OpenAI provider - should NOT require DeepSeek key
-	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "brainstorm", false, 5, "", "", "openai")
+	// Test: input language fr, output language en
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "meeting", false, 5, "fr", "en", "deepseek")
 	err := RunTranscribe(cmd, env, opts)
 	if err != nil {
-		t.Fatalf("RunTranscribe() with OpenAI provider unexpected error: %v", err)
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+
+	// Output language should be "en" (explicitly specified)
+	if capturedLang.String() != "en" {
+		t.Errorf("restructurer output language = %q, want %q", capturedLang.String(), "en")
 	}
 }
 
-func TestRunTranscribe_ProviderPassedToFactory(t *testing.T) {
+func TestRunTranscribe_WithTemplateInheritLanguage(t *testing.T) {
 	t.Parallel()
 
 	inputPath := createTestAudioFile(t, "audio.ogg")
@@ -1393,8 +1650,10 @@ func TestRunTranscribe_ProviderPassedToFactory(t *testing.T) {
 		},
 	}
 
+	var capturedLang lang.Language
 	mockMR := &mockMapReduceRestructurer{
 		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+			capturedLang = outputLang
 			return "restructured", false, nil
 		},
 	}
@@ -1409,23 +1668,2274 @@ func TestRunTranscribe_ProviderPassedToFactory(t *testing.T) {
 		FFmpegResolver:      &mockFFmpegResolver{},
 		ConfigLoader:        &mockConfigLoader{},
 		ChunkerFactory:      chunkerFactory,
+		MediaProberFactory:  &mockMediaProberFactory{},
 		TranscriberFactory:  transcriberFactory,
 		RestructurerFactory: restructurerFactory,
 	}
 	cmd := createTranscribeCmd(context.Background())
 
-	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "brainstorm", false, 5, "", "", "deepseek")
+	// Test: input language fr, no output language -> should inherit fr
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "meeting", false, 5, "fr", "", "deepseek")
 	err := RunTranscribe(cmd, env, opts)
 	if err != nil {
 		t.Fatalf("RunTranscribe() unexpected error: %v", err)
 	}
 
-	// Verify the correct provider was passed to the factory
-	calls := restructurerFactory.NewMapReducerCalls()
-	if len(calls) != 1 {
-		t.Fatalf("NewMapReducer call count = %d, want 1", len(calls))
+	// Output language should be "fr" (inherited from input)
+	if capturedLang.String() != "fr" {
+		t.Errorf("restructurer output language = %q, want %q (inherited from input)", capturedLang.String(), "fr")
 	}
-	if calls[0].Provider != DeepSeekProvider {
-		t.Errorf("NewMapReducer provider = %q, want %q", calls[0].Provider, DeepSeekProvider)
+}
+
+func TestRunTranscribe_TranslateAudio(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	outputPath := filepath.Join(t.TempDir(), "output.md")
+
+	chunkPath := filepath.Join(t.TempDir(), "chunk_0.ogg")
+	if err := os.WriteFile(chunkPath, []byte("chunk"), 0644); err != nil {
+		t.Fatalf("failed to create chunk: %v", err)
+	}
+	chunkerFactory := &mockChunkerFactory{
+		NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
+			return &mockChunker{
+				ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+					return []audio.Chunk{{Path: chunkPath, Index: 0}}, nil
+				},
+			}, nil
+		},
+	}
+
+	var capturedOpts transcribe.Options
+	transcriberFactory := &mockTranscriberFactory{
+		NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+			return &mockTranscriber{
+				TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+					capturedOpts = opts
+					return "translated", nil
+				},
+			}
+		},
+	}
+
+	var capturedLang lang.Language
+	restructurerFactory := &mockRestructurerFactory{
+		mockMapReducer: &mockMapReduceRestructurer{
+			RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+				capturedLang = outputLang
+				return "restructured", false, nil
+			},
+		},
+	}
+
+	env := &Env{
+		Stderr:              &syncBuffer{},
+		Getenv:              defaultTestEnv,
+		Now:                 fixedTime(time.Now()),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        &mockConfigLoader{},
+		ChunkerFactory:      chunkerFactory,
+		MediaProberFactory:  &mockMediaProberFactory{},
+		TranscriberFactory:  transcriberFactory,
+		RestructurerFactory: restructurerFactory,
+	}
+	cmd := createTranscribeCmd(context.Background())
+
+	// French audio translated while transcribing: the notes are in English
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "meeting", false, 5, "fr", "", "deepseek")
+	opts.translateAudio = true
+	if err := RunTranscribe(cmd, env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+
+	if !capturedOpts.Translate {
+		t.Error("transcribe options Translate = false, want true")
+	}
+	if capturedLang.String() != "en" {
+		t.Errorf("restructurer output language = %q, want %q (translated transcript)", capturedLang.String(), "en")
+	}
+}
+
+func TestRunTranscribe_TranslateAudioRejectsDiarize(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	env, _ := testEnv()
+	cmd := createTranscribeCmd(context.Background())
+
+	opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "output.md"), "", true, 5, "", "", "deepseek")
+	opts.translateAudio = true
+	err := RunTranscribe(cmd, env, opts)
+	if !errors.Is(err, transcribe.ErrDiarizeUnsupported) {
+		t.Errorf("RunTranscribe() error = %v, want ErrDiarizeUnsupported", err)
+	}
+}
+
+func TestRunTranscribe_RestructureError(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	outputDir := t.TempDir()
+	outputPath := filepath.Join(outputDir, "output.md")
+
+	chunkPath := filepath.Join(t.TempDir(), "chunk_0.ogg")
+	if err := os.WriteFile(chunkPath, []byte("chunk"), 0644); err != nil {
+		t.Fatalf("failed to create chunk: %v", err)
+	}
+
+	chunker := &mockChunker{
+		ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+			return []audio.Chunk{{Path: chunkPath, Index: 0}}, nil
+		},
+	}
+	chunkerFactory := &mockChunkerFactory{
+		NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
+			return chunker, nil
+		},
+	}
+
+	transcriber := &mockTranscriber{
+		TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "transcribed", nil
+		},
+	}
+	transcriberFactory := &mockTranscriberFactory{
+		NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+			return transcriber
+		},
+	}
+
+	restructureErr := errors.New("API error during restructuring")
+	mockMR := &mockMapReduceRestructurer{
+		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+			return "", false, restructureErr
+		},
+	}
+	restructurerFactory := &mockRestructurerFactory{
+		mockMapReducer: mockMR,
+	}
+
+	env := &Env{
+		Stderr:              &syncBuffer{},
+		Getenv:              defaultTestEnv,
+		Now:                 fixedTime(time.Now()),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        &mockConfigLoader{},
+		ChunkerFactory:      chunkerFactory,
+		MediaProberFactory:  &mockMediaProberFactory{},
+		TranscriberFactory:  transcriberFactory,
+		RestructurerFactory: restructurerFactory,
+	}
+	cmd := createTranscribeCmd(context.Background())
+
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "brainstorm", false, 5, "", "", "deepseek")
+	err := RunTranscribe(cmd, env, opts)
+	if err == nil {
+		t.Fatal("RunTranscribe() expected error when restructuring fails")
+	}
+	if !errors.Is(err, restructureErr) {
+		t.Errorf("RunTranscribe() error = %v, want restructureErr", err)
+	}
+}
+
+func TestRunTranscribe_EmptyTranscriptSkipsRestructure(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	outputDir := t.TempDir()
+	outputPath := filepath.Join(outputDir, "output.md")
+
+	chunkPath := filepath.Join(t.TempDir(), "chunk_0.ogg")
+	if err := os.WriteFile(chunkPath, []byte("chunk"), 0644); err != nil {
+		t.Fatalf("failed to create chunk: %v", err)
+	}
+
+	chunker := &mockChunker{
+		ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+			return []audio.Chunk{{Path: chunkPath, Index: 0}}, nil
+		},
+	}
+	chunkerFactory := &mockChunkerFactory{
+		NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
+			return chunker, nil
+		},
+	}
+
+	// Transcriber returns empty/whitespace
+	transcriber := &mockTranscriber{
+		TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "   ", nil // Whitespace only
+		},
+	}
+	transcriberFactory := &mockTranscriberFactory{
+		NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+			return transcriber
+		},
+	}
+
+	var restructureCalled bool
+	mockMR := &mockMapReduceRestructurer{
+		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+			restructureCalled = true
+			return "should not be called", false, nil
+		},
+	}
+	restructurerFactory := &mockRestructurerFactory{
+		mockMapReducer: mockMR,
+	}
+
+	env := &Env{
+		Stderr:              &syncBuffer{},
+		Getenv:              defaultTestEnv,
+		Now:                 fixedTime(time.Now()),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        &mockConfigLoader{},
+		ChunkerFactory:      chunkerFactory,
+		MediaProberFactory:  &mockMediaProberFactory{},
+		TranscriberFactory:  transcriberFactory,
+		RestructurerFactory: restructurerFactory,
+	}
+	cmd := createTranscribeCmd(context.Background())
+
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "brainstorm", false, 5, "", "", "deepseek")
+	err := RunTranscribe(cmd, env, opts)
+	if err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+
+	// Restructure should NOT be called for empty transcript
+	if restructureCalled {
+		t.Error("restructure called = true, want false for empty/whitespace transcript")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests for validation order in runTranscribe
+// ---------------------------------------------------------------------------
+
+func TestRunTranscribe_ValidationOrder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("file not found first", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		cmd := createTranscribeCmd(context.Background())
+
+		opts := mustParseTranscribeOptions(t, "/nonexistent/path.ogg", "", "", false, 5, "", "", "deepseek")
+		err := RunTranscribe(cmd, env, opts)
+		if err == nil {
+			t.Fatal("RunTranscribe() expected error")
+		}
+		if !errors.Is(err, ErrFileNotFound) {
+			t.Errorf("RunTranscribe() error = %v, want ErrFileNotFound", err)
+		}
+	})
+
+	t.Run("format check before api key", func(t *testing.T) {
+		t.Parallel()
+
+		// Create a file with bad extension, whose content is not audio
+		path := createTestAudioFile(t, "audio.xyz")
+		env := &Env{
+			Stderr:         &syncBuffer{},
+			Getenv:         func(string) string { return "" }, // No API key
+			Now:            fixedTime(time.Now()),
+			FFmpegResolver: &mockFFmpegResolver{},
+			ConfigLoader:   &mockConfigLoader{},
+		}
+		cmd := createTranscribeCmd(context.Background())
+
+		opts := mustParseTranscribeOptions(t, path, "", "", false, 5, "", "", "deepseek")
+		err := RunTranscribe(cmd, env, opts)
+		if err == nil {
+			t.Fatal("RunTranscribe() expected error")
+		}
+		// Format error should come before API key error
+		if !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("RunTranscribe() error = %v, want ErrUnsupportedFormat", err)
+		}
+	})
+
+	t.Run("output lang requires template", func(t *testing.T) {
+		t.Parallel()
+
+		path := createTestAudioFile(t, "audio.ogg")
+		env := &Env{
+			Stderr:         &syncBuffer{},
+			Getenv:         func(string) string { return "" }, // No API key
+			Now:            fixedTime(time.Now()),
+			FFmpegResolver: &mockFFmpegResolver{},
+			ConfigLoader:   &mockConfigLoader{},
+		}
+		cmd := createTranscribeCmd(context.Background())
+
+		opts := mustParseTranscribeOptions(t, path, "", "", false, 5, "", "en", "deepseek")
+		err := RunTranscribe(cmd, env, opts)
+		if err == nil {
+			t.Fatal("RunTranscribe() expected error")
+		}
+		// Should fail with translate requires template error before API key check
+		if !strings.Contains(err.Error(), "translate") {
+			t.Errorf("RunTranscribe() error = %q, want containing %q", err.Error(), "translate")
+		}
+	})
+
+	t.Run("api key check last", func(t *testing.T) {
+		t.Parallel()
+
+		path := createTestAudioFile(t, "audio.ogg")
+		env := &Env{
+			Stderr:         &syncBuffer{},
+			Getenv:         func(string) string { return "" }, // No API key
+			Now:            fixedTime(time.Now()),
+			FFmpegResolver: &mockFFmpegResolver{},
+			ConfigLoader:   &mockConfigLoader{},
+		}
+		cmd := createTranscribeCmd(context.Background())
+
+		opts := mustParseTranscribeOptions(t, path, "", "", false, 5, "", "", "deepseek")
+		err := RunTranscribe(cmd, env, opts)
+		if err == nil {
+			t.Fatal("RunTranscribe() expected error")
+		}
+		if !errors.Is(err, ErrAPIKeyMissing) {
+			t.Errorf("RunTranscribe() error = %v, want ErrAPIKeyMissing", err)
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// Tests for provider flag
+// ---------------------------------------------------------------------------
+
+func TestRunTranscribe_DeepSeekProvider_MissingKey(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	outputDir := t.TempDir()
+	outputPath := filepath.Join(outputDir, "output.md")
+
+	// Only provide OpenAI key, not DeepSeek key
+	env := &Env{
+		Stderr: &syncBuffer{},
+		Getenv: func(key string) string {
+			if key == EnvOpenAIAPIKey {
+				return "test-openai-key"
+			}
+			return "" // No DeepSeek key
+		},
+		Now:            fixedTime(time.Now()),
+		FFmpegResolver: &mockFFmpegResolver{},
+		ConfigLoader:   &mockConfigLoader{},
+	}
+	cmd := createTranscribeCmd(context.Background())
+
+	// Use template to trigger restructuring (which requires DeepSeek key)
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "brainstorm", false, 5, "", "", "deepseek")
+	err := RunTranscribe(cmd, env, opts)
+	if err == nil {
+		t.Fatal("RunTranscribe() expected error for missing DeepSeek API key")
+	}
+	if !errors.Is(err, ErrDeepSeekKeyMissing) {
+		t.Errorf("RunTranscribe() error = %v, want ErrDeepSeekKeyMissing", err)
+	}
+}
+
+func TestRunTranscribe_OpenAIProvider_ReusesKey(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	outputDir := t.TempDir()
+	outputPath := filepath.Join(outputDir, "output.md")
+
+	chunkPath := filepath.Join(t.TempDir(), "chunk_0.ogg")
+	if err := os.WriteFile(chunkPath, []byte("chunk"), 0644); err != nil {
+		t.Fatalf("failed to create chunk: %v", err)
+	}
+
+	chunker := &mockChunker{
+		ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+			return []audio.Chunk{{Path: chunkPath, Index: 0}}, nil
+		},
+	}
+	chunkerFactory := &mockChunkerFactory{
+		NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
+			return chunker, nil
+		},
+	}
+
+	transcriber := &mockTranscriber{
+		TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "transcribed", nil
+		},
+	}
+	transcriberFactory := &mockTranscriberFactory{
+		NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+			return transcriber
+		},
+	}
+
+	mockMR := &mockMapReduceRestructurer{
+		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+			return "restructured", false, nil
+		},
+	}
+	restructurerFactory := &mockRestructurerFactory{
+		mockMapReducer: mockMR,
+	}
+
+	// Only provide OpenAI key - should work with --provider openai
+	env := &Env{
+		Stderr: &syncBuffer{},
+		Getenv: func(key string) string {
+			if key == EnvOpenAIAPIKey {
+				return "test-openai-key"
+			}
+			return "" // No DeepSeek key
+		},
+		Now:                 fixedTime(time.Now()),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        &mockConfigLoader{},
+		ChunkerFactory:      chunkerFactory,
+		MediaProberFactory:  &mockMediaProberFactory{},
+		TranscriberFactory:  transcriberFactory,
+		RestructurerFactory: restructurerFactory,
+	}
+	cmd := createTranscribeCmd(context.Background())
+
+	// Use OpenAI provider - should NOT require DeepSeek key
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "brainstorm", false, 5, "", "", "openai")
+	err := RunTranscribe(cmd, env, opts)
+	if err != nil {
+		t.Fatalf("RunTranscribe() with OpenAI provider unexpected error: %v", err)
+	}
+}
+
+func TestRunTranscribe_ProviderPassedToFactory(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	outputDir := t.TempDir()
+	outputPath := filepath.Join(outputDir, "output.md")
+
+	chunkPath := filepath.Join(t.TempDir(), "chunk_0.ogg")
+	if err := os.WriteFile(chunkPath, []byte("chunk"), 0644); err != nil {
+		t.Fatalf("failed to create chunk: %v", err)
+	}
+
+	chunker := &mockChunker{
+		ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+			return []audio.Chunk{{Path: chunkPath, Index: 0}}, nil
+		},
+	}
+	chunkerFactory := &mockChunkerFactory{
+		NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
+			return chunker, nil
+		},
+	}
+
+	transcriber := &mockTranscriber{
+		TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "transcribed", nil
+		},
+	}
+	transcriberFactory := &mockTranscriberFactory{
+		NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+			return transcriber
+		},
+	}
+
+	mockMR := &mockMapReduceRestructurer{
+		RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+			return "restructured", false, nil
+		},
+	}
+	restructurerFactory := &mockRestructurerFactory{
+		mockMapReducer: mockMR,
+	}
+
+	env := &Env{
+		Stderr:              &syncBuffer{},
+		Getenv:              defaultTestEnv,
+		Now:                 fixedTime(time.Now()),
+		FFmpegResolver:      &mockFFmpegResolver{},
+		ConfigLoader:        &mockConfigLoader{},
+		ChunkerFactory:      chunkerFactory,
+		MediaProberFactory:  &mockMediaProberFactory{},
+		TranscriberFactory:  transcriberFactory,
+		RestructurerFactory: restructurerFactory,
+	}
+	cmd := createTranscribeCmd(context.Background())
+
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "brainstorm", false, 5, "", "", "deepseek")
+	err := RunTranscribe(cmd, env, opts)
+	if err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+
+	// Verify the correct provider was passed to the factory
+	calls := restructurerFactory.NewMapReducerCalls()
+	if len(calls) != 1 {
+		t.Fatalf("NewMapReducer call count = %d, want 1", len(calls))
+	}
+	if calls[0].Provider != DeepSeekProvider {
+		t.Errorf("NewMapReducer provider = %q, want %q", calls[0].Provider, DeepSeekProvider)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests for checkpoint / --resume
+// ---------------------------------------------------------------------------
+
+func TestCheckpointPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{"relative", "session.md", ".session.md.transcript-state.json"},
+		{"with_dir", filepath.Join("notes", "session.md"), filepath.Join("notes", ".session.md.transcript-state.json")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := CheckpointPath(tt.output); got != tt.expected {
+				t.Errorf("CheckpointPath(%q) = %q, want %q", tt.output, got, tt.expected)
+			}
+		})
+	}
+}
+
+// checkpointTestEnv returns an Env whose chunker yields two chunks and whose
+// transcriber is transcribeFunc.
+func checkpointTestEnv(t *testing.T, stderr *syncBuffer, transcribeFunc func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error)) *Env {
+	t.Helper()
+
+	chunkDir := t.TempDir()
+	chunks := []audio.Chunk{
+		{Path: filepath.Join(chunkDir, "chunk_0.ogg"), Index: 0, StartTime: 0, EndTime: 5 * time.Minute},
+		{Path: filepath.Join(chunkDir, "chunk_1.ogg"), Index: 1, StartTime: 5 * time.Minute, EndTime: 10 * time.Minute},
+	}
+
+	return &Env{
+		Stderr:             stderr,
+		Getenv:             defaultTestEnv,
+		Now:                fixedTime(time.Now()),
+		FFmpegResolver:     &mockFFmpegResolver{},
+		ConfigLoader:       &mockConfigLoader{},
+		MediaProberFactory: &mockMediaProberFactory{},
+		ChunkerFactory: &mockChunkerFactory{
+			NewSilenceChunkerFunc: func(ffmpegPath string) (audio.Chunker, error) {
+				return &mockChunker{
+					ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+						return chunks, nil
+					},
+				}, nil
+			},
+		},
+		TranscriberFactory: &mockTranscriberFactory{
+			NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+				return &mockTranscriber{TranscribeFunc: transcribeFunc}
+			},
+		},
+	}
+}
+
+func TestRunTranscribe_Resume(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	outputPath := filepath.Join(t.TempDir(), "output.md")
+	statePath := CheckpointPath(outputPath)
+
+	// First run: chunk 1 fails once chunk 0 is checkpointed.
+	apiErr := errors.New("network down")
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		if strings.HasSuffix(audioPath, "chunk_1.ogg") {
+			for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+				if _, err := os.Stat(statePath); err == nil {
+					break
+				}
+			}
+			return "", apiErr
+		}
+		return "first part", nil
+	})
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "", false, 2, "", "", "deepseek")
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); !errors.Is(err, apiErr) {
+		t.Fatalf("RunTranscribe() error = %v, want apiErr", err)
+	}
+	if _, err := os.Stat(statePath); err != nil {
+		t.Fatalf("checkpoint not written after failure: %v", err)
+	}
+
+	// Second run with --resume: only chunk 1 is transcribed.
+	var transcribed []string
+	stderr := &syncBuffer{}
+	env = checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		transcribed = append(transcribed, filepath.Base(audioPath))
+		return "second part", nil
+	})
+	opts.resume = true
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe(resume) unexpected error: %v", err)
+	}
+
+	if len(transcribed) != 1 || transcribed[0] != "chunk_1.ogg" {
+		t.Errorf("transcribed chunks = %v, want [chunk_1.ogg]", transcribed)
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("os.ReadFile() unexpected error: %v", err)
+	}
+	if want := "first part\n\nsecond part"; string(content) != want {
+		t.Errorf("output content = %q, want %q", string(content), want)
+	}
+	if !strings.Contains(stderr.String(), "Resuming: 1/2 chunks") {
+		t.Errorf("stderr = %q, want containing %q", stderr.String(), "Resuming: 1/2 chunks")
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Errorf("checkpoint still exists after success (stat error = %v)", err)
+	}
+}
+
+func TestRunTranscribe_CheckpointIgnored(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		resume     bool
+		diarize    bool // Options differ from the checkpoint when true
+		wantStderr string
+	}{
+		{"without resume", false, false, "use --resume"},
+		{"options changed", true, true, "does not match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inputPath := createTestAudioFile(t, "audio.ogg")
+			outputPath := filepath.Join(t.TempDir(), "output.md")
+
+			// Checkpoint from a previous (non-diarized) run with chunk 0 done.
+			cp, err := transcribe.NewCheckpoint(inputPath, transcribe.Options{}, 2)
+			if err != nil {
+				t.Fatalf("NewCheckpoint() unexpected error: %v", err)
+			}
+			cp.Record(0, "stale")
+			if err := cp.Save(CheckpointPath(outputPath)); err != nil {
+				t.Fatalf("Save() unexpected error: %v", err)
+			}
+
+			var calls int
+			stderr := &syncBuffer{}
+			env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+				calls++
+				return "fresh", nil
+			})
+			opts := mustParseTranscribeOptions(t, inputPath, outputPath, "", tt.diarize, 1, "", "", "deepseek")
+			opts.resume = tt.resume
+			if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+				t.Fatalf("RunTranscribe() unexpected error: %v", err)
+			}
+
+			if calls != 2 {
+				t.Errorf("transcriber calls = %d, want 2 (checkpoint must not be reused)", calls)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want containing %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRunTranscribe_AutoParallel(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	outputPath := filepath.Join(t.TempDir(), "output.md")
+
+	var mu sync.Mutex
+	var calls int
+	stderr := &syncBuffer{}
+	env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return "text " + filepath.Base(audioPath), nil
+	})
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "", false, transcribe.MaxRecommendedParallel, "", "", "deepseek")
+	opts.auto = true
+
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("transcriber calls = %d, want 2", calls)
+	}
+	if !strings.Contains(stderr.String(), "parallel requests") {
+		t.Errorf("stderr = %q, want chosen parallelism reported", stderr.String())
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if string(content) != "text chunk_0.ogg\n\ntext chunk_1.ogg" {
+		t.Errorf("output = %q, want both chunks in order", content)
+	}
+}
+
+func TestRunTranscribe_DefaultParallel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		cfg        config.Config
+		wantStderr string
+	}{
+		{name: "one per chunk", wantStderr: "Using 2 parallel requests (one per chunk)"},
+		{
+			name:       "request rate limit",
+			cfg:        config.Config{RateLimitRequests: 3},
+			wantStderr: "Using 1 parallel requests (rate-limit-requests 3/min)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inputPath := createTestAudioFile(t, "audio.ogg")
+			outputPath := filepath.Join(t.TempDir(), "output.md")
+			stderr := &syncBuffer{}
+			env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+				return "text " + filepath.Base(audioPath), nil
+			})
+			env.ConfigLoader = &mockConfigLoader{LoadFunc: func() (config.Config, error) { return tt.cfg, nil }}
+			opts := mustParseTranscribeOptions(t, inputPath, outputPath, "", false, 0, "", "", "deepseek")
+
+			if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+				t.Fatalf("RunTranscribe() unexpected error: %v", err)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want containing %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestResolveParallel_Given(t *testing.T) {
+	t.Parallel()
+
+	var stderr strings.Builder
+	if got := resolveParallel(&stderr, 4, nil, 1, 0); got != 4 {
+		t.Errorf("resolveParallel(4) = %d, want 4", got)
+	}
+	if got := resolveParallel(&stderr, 50, nil, 0, 0); got != transcribe.MaxRecommendedParallel {
+		t.Errorf("resolveParallel(50) = %d, want %d", got, transcribe.MaxRecommendedParallel)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("stderr = %q, want nothing reported for a given --parallel", stderr.String())
+	}
+}
+
+func TestTranscribeCmd_InvalidParallel(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+
+	env, _ := testEnv()
+	cmd := TranscribeCmd(env)
+
+	cmd.SetArgs([]string{inputPath, "--parallel", "fast"})
+	err := cmd.Execute()
+
+	if !errors.Is(err, ErrInvalidParallel) {
+		t.Errorf("cmd.Execute() error = %v, want ErrInvalidParallel", err)
+	}
+}
+
+func TestRunTranscribe_SessionDir(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "talk.ogg")
+	sessionDir := filepath.Join(t.TempDir(), "sessions")
+
+	stderr := &syncBuffer{}
+	env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "text " + filepath.Base(audioPath), nil
+	})
+	opts := mustParseTranscribeOptions(t, inputPath, "", "", false, 1, "", "", "deepseek")
+	opts.sessionDir = sessionDir
+
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+
+	dirs, _ := filepath.Glob(filepath.Join(sessionDir, "talk_*"))
+	if len(dirs) != 1 {
+		t.Fatalf("session directories = %v, want one talk_<timestamp>", dirs)
+	}
+	dir := dirs[0]
+
+	content, err := os.ReadFile(filepath.Join(dir, "transcript.md"))
+	if err != nil {
+		t.Fatalf("transcript not written in session: %v", err)
+	}
+	if string(content) != "text chunk_0.ogg\n\ntext chunk_1.ogg" {
+		t.Errorf("transcript = %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "chunks.json")); err != nil {
+		t.Errorf("chunks manifest not written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "raw.md")); !os.IsNotExist(err) {
+		t.Errorf("raw.md written without template (stat error = %v)", err)
+	}
+
+	meta, err := readSessionMetadata(dir)
+	if err != nil {
+		t.Fatalf("readSessionMetadata() unexpected error: %v", err)
+	}
+	abs, _ := filepath.Abs(inputPath)
+	if meta.Status != sessionComplete || meta.Input != abs || meta.Chunks != 2 {
+		t.Errorf("metadata = %+v, want complete session for %s with 2 chunks", meta, abs)
+	}
+
+	log, err := os.ReadFile(filepath.Join(dir, "session.log"))
+	if err != nil {
+		t.Fatalf("session log not written: %v", err)
+	}
+	if !strings.Contains(string(log), "Done: "+filepath.Join(dir, "transcript.md")) {
+		t.Errorf("session log = %q, want progress output", log)
+	}
+}
+
+func TestRunTranscribe_SessionDirResume(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "talk.ogg")
+	sessionDir := t.TempDir()
+	statePattern := filepath.Join(sessionDir, "talk_*", ".transcript.md.transcript-state.json")
+
+	// First run: chunk 1 fails once chunk 0 is checkpointed.
+	apiErr := errors.New("network down")
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		if strings.HasSuffix(audioPath, "chunk_1.ogg") {
+			for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+				if matches, _ := filepath.Glob(statePattern); len(matches) > 0 {
+					break
+				}
+			}
+			return "", apiErr
+		}
+		return "first part", nil
+	})
+	opts := mustParseTranscribeOptions(t, inputPath, "", "", false, 2, "", "", "deepseek")
+	opts.sessionDir = sessionDir
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); !errors.Is(err, apiErr) {
+		t.Fatalf("RunTranscribe() error = %v, want apiErr", err)
+	}
+
+	dirs, _ := filepath.Glob(filepath.Join(sessionDir, "talk_*"))
+	if len(dirs) != 1 {
+		t.Fatalf("session directories = %v, want one", dirs)
+	}
+	if meta, _ := readSessionMetadata(dirs[0]); meta.Status != sessionFailed || meta.Error == "" {
+		t.Errorf("metadata after failure = %+v, want failed with error", meta)
+	}
+
+	// Second run with --resume continues the same session.
+	stderr := &syncBuffer{}
+	env = checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "second part", nil
+	})
+	opts.resume = true
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe(resume) unexpected error: %v", err)
+	}
+
+	if after, _ := filepath.Glob(filepath.Join(sessionDir, "talk_*")); len(after) != 1 {
+		t.Errorf("session directories = %v, want the same session reused", after)
+	}
+	content, err := os.ReadFile(filepath.Join(dirs[0], "transcript.md"))
+	if err != nil {
+		t.Fatalf("os.ReadFile() unexpected error: %v", err)
+	}
+	if want := "first part\n\nsecond part"; string(content) != want {
+		t.Errorf("output content = %q, want %q", string(content), want)
+	}
+	if !strings.Contains(stderr.String(), "Resuming session") {
+		t.Errorf("stderr = %q, want containing %q", stderr.String(), "Resuming session")
+	}
+	if meta, _ := readSessionMetadata(dirs[0]); meta.Status != sessionComplete {
+		t.Errorf("metadata status = %q, want %q", meta.Status, sessionComplete)
+	}
+}
+
+func TestTranscribeCmd_SessionDirExcludesOutput(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+
+	env, _ := testEnv()
+	cmd := TranscribeCmd(env)
+
+	cmd.SetArgs([]string{inputPath, "-o", "out.md", "--session-dir", t.TempDir()})
+	err := cmd.Execute()
+
+	if err == nil || !strings.Contains(err.Error(), "session-dir") {
+		t.Errorf("cmd.Execute() error = %v, want mutually exclusive flags error", err)
+	}
+}
+
+func TestRunTranscribe_Stdout(t *testing.T) {
+	t.Parallel()
+
+	stderr := &syncBuffer{}
+	env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "Hello.", nil
+	})
+	input := createTestAudioFile(t, "call.ogg")
+	opts := mustParseTranscribeOptions(t, input, "", "", false, 1, "", "", "deepseek")
+	opts.stdout = true
+
+	var stdout strings.Builder
+	cmd := createTranscribeCmd(context.Background())
+	cmd.SetOut(&stdout)
+	if err := RunTranscribe(cmd, env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+	if got := stdout.String(); !strings.HasPrefix(got, "Hello.") {
+		t.Errorf("stdout = %q, want the transcript", got)
+	}
+	if !strings.Contains(stderr.String(), "Transcribing") {
+		t.Errorf("stderr = %q, want the progress", stderr.String())
+	}
+	files, err := os.ReadDir(filepath.Dir(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("files next to the input = %d, want only the input (no output nor checkpoint)", len(files))
+	}
+}
+
+func TestTranscribeCmd_StdoutConflicts(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"force", []string{inputPath, "--stdout", "--force"}, "--force"},
+		{"resume with -o -", []string{inputPath, "-o", "-", "--resume"}, "--resume"},
+		{"session-dir", []string{inputPath, "--stdout", "--session-dir", t.TempDir()}, "session-dir"},
+		{"several inputs", []string{inputPath, createTestAudioFile(t, "other.ogg"), "--stdout"}, "single audio file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			env, _ := testEnv()
+			cmd := TranscribeCmd(env)
+			cmd.SetArgs(tt.args)
+			if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("cmd.Execute() error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunTranscribe_LocalTranscriber(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	outputPath := filepath.Join(t.TempDir(), "output.md")
+
+	var mu sync.Mutex
+	var active, maxActive int
+	transcribeFunc := func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return "local " + filepath.Base(audioPath), nil
+	}
+
+	env := checkpointTestEnv(t, &syncBuffer{}, nil)
+	env.Getenv = func(string) string { return "" } // No OpenAI key needed
+	env.ConfigLoader = &mockConfigLoader{
+		LoadFunc: func() (config.Config, error) {
+			return config.Config{Transcriber: "local", WhisperModel: "/models/ggml-base.bin"}, nil
+		},
+	}
+	factory := &mockTranscriberFactory{
+		NewLocalTranscriberFunc: func(cfg LocalTranscriberConfig) (transcribe.Transcriber, error) {
+			return &mockTranscriber{TranscribeFunc: transcribeFunc}, nil
+		},
+	}
+	env.TranscriberFactory = factory
+
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "", false, 5, "", "", "deepseek")
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+
+	configs := factory.LocalConfigs()
+	if len(configs) != 1 || configs[0].ModelPath != "/models/ggml-base.bin" {
+		t.Errorf("local transcriber configs = %+v, want the configured model", configs)
+	}
+	if calls := factory.NewTranscriberCalls(); len(calls) != 0 {
+		t.Errorf("OpenAI transcriber created %d times, want 0", len(calls))
+	}
+	if maxActive != 1 {
+		t.Errorf("max concurrent chunks = %d, want 1 with whisper.cpp", maxActive)
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if string(content) != "local chunk_0.ogg\n\nlocal chunk_1.ogg" {
+		t.Errorf("output = %q, want both chunks in order", content)
+	}
+}
+
+func TestTranscribeCmd_GRPCTranscriber(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		flags []string
+	}{
+		{name: "transcriber flags", flags: []string{"--transcriber", "grpc", "--grpc-endpoint", "asr.internal:50051"}},
+		{name: "stt aliases", flags: []string{"--stt-provider", "grpc", "--stt-endpoint", "asr.internal:50051"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inputPath := createTestAudioFile(t, "audio.ogg")
+			outputPath := filepath.Join(t.TempDir(), "output.md")
+
+			env := checkpointTestEnv(t, &syncBuffer{}, nil)
+			env.Getenv = func(string) string { return "" } // No OpenAI key needed
+			env.ConfigLoader = &mockConfigLoader{
+				LoadFunc: func() (config.Config, error) {
+					return config.Config{GRPCEndpoint: "asr.internal:443", GRPCPlaintext: true, GRPCTimeout: time.Minute}, nil
+				},
+			}
+			factory := &mockTranscriberFactory{
+				NewGRPCTranscriberFunc: func(cfg GRPCTranscriberConfig) (transcribe.Transcriber, error) {
+					return &mockTranscriber{TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+						return "grpc " + filepath.Base(audioPath), nil
+					}}, nil
+				},
+			}
+			env.TranscriberFactory = factory
+
+			cmd := TranscribeCmd(env)
+			cmd.SetContext(context.Background())
+			cmd.SetArgs(append([]string{inputPath, "-o", outputPath}, tt.flags...))
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() unexpected error: %v", err)
+			}
+
+			configs := factory.GRPCConfigs()
+			want := GRPCTranscriberConfig{Endpoint: "asr.internal:50051", Plaintext: true, Timeout: time.Minute}
+			if len(configs) != 1 || configs[0] != want {
+				t.Errorf("gRPC transcriber configs = %+v, want the flag endpoint with the configured settings", configs)
+			}
+			if calls := factory.NewTranscriberCalls(); len(calls) != 0 {
+				t.Errorf("OpenAI transcriber created %d times, want 0", len(calls))
+			}
+			content, err := os.ReadFile(outputPath)
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			if string(content) != "grpc chunk_0.ogg\n\ngrpc chunk_1.ogg" {
+				t.Errorf("output = %q, want both chunks in order", content)
+			}
+		})
+	}
+}
+
+func TestTranscribeCmd_GRPCEndpointMissing(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	env := checkpointTestEnv(t, &syncBuffer{}, nil)
+
+	cmd := TranscribeCmd(env)
+	cmd.SetContext(context.Background())
+	cmd.SetArgs([]string{inputPath, "--transcriber", "grpc"})
+	if err := cmd.Execute(); !errors.Is(err, ErrGRPCEndpointMissing) {
+		t.Errorf("Execute() error = %v, want ErrGRPCEndpointMissing", err)
+	}
+
+	cmd = TranscribeCmd(env)
+	cmd.SetContext(context.Background())
+	cmd.SetArgs([]string{inputPath, "--transcriber", "grpc", "--grpc-endpoint", "asr.internal"})
+	if err := cmd.Execute(); !errors.Is(err, config.ErrInvalidValue) {
+		t.Errorf("Execute() error = %v, want ErrInvalidValue for an endpoint without port", err)
+	}
+}
+
+func TestRunTranscribe_LocalFallback(t *testing.T) {
+	t.Parallel()
+
+	tooLarge := func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		if filepath.Base(audioPath) == "chunk_1.ogg" {
+			return "", fmt.Errorf("chunk_1.ogg is 26.0 MB: %w", audio.ErrChunkTooLarge)
+		}
+		return "openai " + filepath.Base(audioPath), nil
+	}
+
+	t.Run("oversized chunk sent to the local backend", func(t *testing.T) {
+		t.Parallel()
+
+		inputPath := createTestAudioFile(t, "talk.ogg")
+		sessionDir := filepath.Join(t.TempDir(), "sessions")
+
+		stderr := &syncBuffer{}
+		env := checkpointTestEnv(t, stderr, tooLarge)
+		env.ConfigLoader = &mockConfigLoader{
+			LoadFunc: func() (config.Config, error) {
+				return config.Config{WhisperModel: "/models/ggml-base.bin"}, nil
+			},
+		}
+		factory := env.TranscriberFactory.(*mockTranscriberFactory)
+		factory.NewLocalTranscriberFunc = func(cfg LocalTranscriberConfig) (transcribe.Transcriber, error) {
+			return &mockTranscriber{TranscribeFunc: func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+				return "local " + filepath.Base(audioPath), nil
+			}}, nil
+		}
+
+		opts := mustParseTranscribeOptions(t, inputPath, "", "", false, 1, "", "", "deepseek")
+		opts.sessionDir = sessionDir
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+			t.Fatalf("RunTranscribe() unexpected error: %v", err)
+		}
+
+		dirs, _ := filepath.Glob(filepath.Join(sessionDir, "talk_*"))
+		if len(dirs) != 1 {
+			t.Fatalf("session directories = %v, want one", dirs)
+		}
+		content, err := os.ReadFile(filepath.Join(dirs[0], "transcript.md"))
+		if err != nil {
+			t.Fatalf("transcript not written in session: %v", err)
+		}
+		if string(content) != "openai chunk_0.ogg\n\nlocal chunk_1.ogg" {
+			t.Errorf("transcript = %q, want chunk 1 from the local backend", content)
+		}
+		if !strings.Contains(stderr.String(), "Warning: chunk 2: chunk_1.ogg is 26.0 MB") ||
+			!strings.Contains(stderr.String(), "transcribing it with the local backend") {
+			t.Errorf("stderr = %q, want the fallback logged", stderr.String())
+		}
+
+		meta, err := readSessionMetadata(dirs[0])
+		if err != nil {
+			t.Fatalf("readSessionMetadata() unexpected error: %v", err)
+		}
+		if !slices.Equal(meta.FallbackChunks, []int{1}) {
+			t.Errorf("metadata fallback chunks = %v, want [1]", meta.FallbackChunks)
+		}
+	})
+
+	t.Run("no local backend configured", func(t *testing.T) {
+		t.Parallel()
+
+		inputPath := createTestAudioFile(t, "talk.ogg")
+		env := checkpointTestEnv(t, &syncBuffer{}, tooLarge)
+		factory := env.TranscriberFactory.(*mockTranscriberFactory)
+
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "talk.md"), "", false, 1, "", "", "deepseek")
+		err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts)
+		if !errors.Is(err, audio.ErrChunkTooLarge) {
+			t.Errorf("RunTranscribe() error = %v, want ErrChunkTooLarge", err)
+		}
+		if configs := factory.LocalConfigs(); len(configs) != 0 {
+			t.Errorf("local transcriber created %d times, want 0", len(configs))
+		}
+	})
+}
+
+func TestRunTranscribe_LocalTranscriberErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		diarize bool
+		wantErr error
+	}{
+		{
+			name:    "diarize unsupported",
+			cfg:     config.Config{Transcriber: "local", WhisperModel: "/models/ggml-base.bin"},
+			diarize: true,
+			wantErr: transcribe.ErrDiarizeUnsupported,
+		},
+		{
+			name:    "model not configured",
+			cfg:     config.Config{Transcriber: "local"},
+			wantErr: transcribe.ErrModelNotFound,
+		},
+		{
+			name:    "invalid configured transcriber",
+			cfg:     config.Config{Transcriber: "cloud"},
+			wantErr: ErrInvalidBackend,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inputPath := createTestAudioFile(t, "audio.ogg")
+			outputPath := filepath.Join(t.TempDir(), "output.md")
+
+			env := checkpointTestEnv(t, &syncBuffer{}, nil)
+			env.ConfigLoader = &mockConfigLoader{
+				LoadFunc: func() (config.Config, error) { return tt.cfg, nil },
+			}
+
+			opts := mustParseTranscribeOptions(t, inputPath, outputPath, "", tt.diarize, 5, "", "", "deepseek")
+			err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RunTranscribe() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTranscribeCmd_InvalidTranscriber(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+
+	env, _ := testEnv()
+	cmd := TranscribeCmd(env)
+
+	cmd.SetArgs([]string{inputPath, "--transcriber", "cloud"})
+	err := cmd.Execute()
+
+	if !errors.Is(err, ErrInvalidBackend) {
+		t.Errorf("cmd.Execute() error = %v, want ErrInvalidBackend", err)
+	}
+}
+
+func TestRunTranscribe_SubtitleFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		format   OutputFormat
+		wantFile string
+		want     string
+	}{
+		{
+			name:     "srt",
+			format:   SRTFormat,
+			wantFile: "audio.srt",
+			want: "1\n00:00:01,000 --> 00:00:02,000\nchunk_0.ogg\n\n" +
+				"2\n00:05:01,000 --> 00:05:02,000\nchunk_1.ogg\n",
+		},
+		{
+			name:     "vtt",
+			format:   VTTFormat,
+			wantFile: "audio.vtt",
+			want: "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nchunk_0.ogg\n\n" +
+				"00:05:01.000 --> 00:05:02.000\nchunk_1.ogg\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inputPath := createTestAudioFile(t, "audio.ogg")
+			outputDir := t.TempDir()
+
+			env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+				if !opts.Timestamps {
+					return "", errors.New("timestamps not requested")
+				}
+				return `[{"start":1,"end":2,"text":"` + filepath.Base(audioPath) + `"}]`, nil
+			})
+			env.ConfigLoader = configWithOutputDir(outputDir)
+
+			opts := mustParseTranscribeOptions(t, inputPath, "", "", false, 5, "", "", "deepseek")
+			opts.format = tt.format
+			if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+				t.Fatalf("RunTranscribe() unexpected error: %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(outputDir, tt.wantFile))
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("output = %q, want %q", content, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunTranscribe_SubtitleFormatRejectsTemplate(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	env := checkpointTestEnv(t, &syncBuffer{}, nil)
+
+	opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "out.srt"), "meeting", false, 5, "", "", "deepseek")
+	opts.format = SRTFormat
+	err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts)
+	if err == nil || !strings.Contains(err.Error(), "--template") {
+		t.Errorf("RunTranscribe() error = %v, want --template conflict", err)
+	}
+}
+
+func TestRunTranscribe_SubtitleSpeakerLabels(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	output := filepath.Join(t.TempDir(), "meeting.srt")
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return `[{"start":1,"end":2,"speaker":"Alice","text":"Hello."}]`, nil
+	})
+
+	opts := mustParseTranscribeOptions(t, inputPath, output, "", true, 5, "", "", "deepseek")
+	opts.format = SRTFormat
+	opts.speakerLabels = SpeakerLabels{name: SpeakerLabelsPrefix}
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if !strings.Contains(string(content), "\nAlice: Hello.\n") || strings.Contains(string(content), "[Alice]") {
+		t.Errorf("output = %q, want captions prefixed with the speaker", content)
+	}
+}
+
+func TestRunTranscribe_SpeakerLabelsRequireSubtitles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format OutputFormat
+		labels string
+	}{
+		{name: "markdown", format: MarkdownFormat, labels: SpeakerLabelsPrefix},
+		{name: "voice tags in srt", format: SRTFormat, labels: SpeakerLabelsVoice},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inputPath := createTestAudioFile(t, "audio.ogg")
+			env := checkpointTestEnv(t, &syncBuffer{}, nil)
+
+			opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(t.TempDir(), "out"+tt.format.Extension()), "", true, 5, "", "", "deepseek")
+			opts.format = tt.format
+			opts.speakerLabels = SpeakerLabels{name: tt.labels}
+			err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts)
+			if err == nil || !strings.Contains(err.Error(), "--speaker-labels") {
+				t.Errorf("RunTranscribe() error = %v, want a --speaker-labels conflict", err)
+			}
+		})
+	}
+}
+
+func TestRunTranscribe_Timestamps(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		format     OutputFormat
+		timestamps string
+		want       string
+	}{
+		{
+			name:       "markdown every 5 minutes",
+			format:     MarkdownFormat,
+			timestamps: "5m",
+			want:       "**[00:00:01]** chunk_0.ogg\n\n**[00:05:01]** chunk_1.ogg",
+		},
+		{
+			name:       "text every hour",
+			format:     TextFormat,
+			timestamps: "1h",
+			want:       "[00:00:01] chunk_0.ogg chunk_1.ogg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inputPath := createTestAudioFile(t, "audio.ogg")
+			output := filepath.Join(t.TempDir(), "out"+tt.format.Extension())
+			env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+				if !opts.Timestamps {
+					return "", errors.New("timestamps not requested")
+				}
+				return `[{"start":1,"end":2,"text":"` + filepath.Base(audioPath) + `"}]`, nil
+			})
+
+			opts := mustParseTranscribeOptions(t, inputPath, output, "", false, 5, "", "", "deepseek")
+			opts.format = tt.format
+			var err error
+			if opts.timestamps, err = ParseTimestamps(tt.timestamps); err != nil {
+				t.Fatal(err)
+			}
+			if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+				t.Fatalf("RunTranscribe() unexpected error: %v", err)
+			}
+
+			content, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			if !strings.Contains(string(content), tt.want) {
+				t.Errorf("output = %q, want %q", content, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscribeCmd_InvalidTimestamps(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+
+	env, _ := testEnv()
+	cmd := TranscribeCmd(env)
+
+	cmd.SetArgs([]string{inputPath, "--timestamps=often"})
+	err := cmd.Execute()
+
+	if !errors.Is(err, ErrInvalidTimestamps) {
+		t.Errorf("cmd.Execute() error = %v, want ErrInvalidTimestamps", err)
+	}
+}
+
+func TestTranscribeCmd_InvalidFormat(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+
+	env, _ := testEnv()
+	cmd := TranscribeCmd(env)
+
+	cmd.SetArgs([]string{inputPath, "--format", "docx"})
+	err := cmd.Execute()
+
+	if !errors.Is(err, ErrInvalidOutputFormat) {
+		t.Errorf("cmd.Execute() error = %v, want ErrInvalidOutputFormat", err)
+	}
+}
+
+func TestRunTranscribe_Tag(t *testing.T) {
+	t.Parallel()
+
+	tagsDir := t.TempDir()
+	var (
+		mu      sync.Mutex
+		prompts []string
+	)
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		mu.Lock()
+		prompts = append(prompts, opts.Prompt)
+		mu.Unlock()
+		return "We met Armstrong in Houston.", nil
+	})
+	env.ConfigLoader = &mockConfigLoader{
+		LoadFunc: func() (config.Config, error) {
+			return config.Config{TagsDir: tagsDir}, nil
+		},
+	}
+
+	run := func(output string) {
+		t.Helper()
+		opts := mustParseTranscribeOptions(t, createTestAudioFile(t, "audio.ogg"), filepath.Join(t.TempDir(), output), "", false, 1, "", "", "deepseek")
+		opts.tag = "apollo"
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+			t.Fatalf("RunTranscribe() unexpected error: %v", err)
+		}
+	}
+
+	// First session: nothing recorded yet, so no prompt
+	run("first.md")
+	for _, p := range prompts {
+		if p != "" {
+			t.Errorf("first session prompt = %q, want empty", p)
+		}
+	}
+
+	// Second session: prompted with the names of the first one
+	prompts = nil
+	run("second.md")
+	for _, p := range prompts {
+		if p != "Armstrong, Houston." {
+			t.Errorf("second session prompt = %q, want %q", p, "Armstrong, Houston.")
+		}
+	}
+
+	history, err := vocab.NewStore(tagsDir).Load("apollo")
+	if err != nil {
+		t.Fatalf("failed to load tag history: %v", err)
+	}
+	if history.Sessions != 2 {
+		t.Errorf("history sessions = %d, want 2", history.Sessions)
+	}
+}
+
+func TestTranscribeCmd_InvalidTag(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+
+	env, _ := testEnv()
+	cmd := TranscribeCmd(env)
+
+	cmd.SetArgs([]string{inputPath, "--tag", "../apollo"})
+	err := cmd.Execute()
+
+	if !errors.Is(err, vocab.ErrInvalidTag) {
+		t.Errorf("cmd.Execute() error = %v, want ErrInvalidTag", err)
+	}
+}
+
+func TestRunTranscribe_Glossary(t *testing.T) {
+	t.Parallel()
+
+	glossary := filepath.Join(t.TempDir(), "glossary.txt")
+	if err := os.WriteFile(glossary, []byte("# Acme\nKubernetes\nOKR\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		mu      sync.Mutex
+		prompts []string
+	)
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		mu.Lock()
+		prompts = append(prompts, opts.Prompt)
+		mu.Unlock()
+		return "Kubernetes is up.", nil
+	})
+	env.ConfigLoader = &mockConfigLoader{
+		LoadFunc: func() (config.Config, error) {
+			return config.Config{Vocab: []string{"Zyzzyva"}}, nil
+		},
+	}
+
+	opts := mustParseTranscribeOptions(t, createTestAudioFile(t, "audio.ogg"), filepath.Join(t.TempDir(), "out.md"), "", false, 1, "", "", "deepseek")
+	opts.glossary = glossary
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+	if len(prompts) == 0 {
+		t.Fatal("no chunk transcribed")
+	}
+	for _, p := range prompts {
+		if p != "Kubernetes, OKR, Zyzzyva." {
+			t.Errorf("prompt = %q, want glossary then project vocab", p)
+		}
+	}
+}
+
+func TestTranscribeCmd_MissingGlossary(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+
+	env, _ := testEnv()
+	cmd := TranscribeCmd(env)
+
+	cmd.SetArgs([]string{inputPath, "--glossary", filepath.Join(t.TempDir(), "missing.txt")})
+	err := cmd.Execute()
+
+	if !errors.Is(err, vocab.ErrInvalidGlossary) {
+		t.Errorf("cmd.Execute() error = %v, want ErrInvalidGlossary", err)
+	}
+}
+
+func TestRunTranscribe_Highlights(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	if err := os.WriteFile(highlightsPath(inputPath), []byte("⭐ 00:02:00\n⭐ 00:07:30\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stderr := &syncBuffer{}
+	env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return strings.TrimSuffix(filepath.Base(audioPath), ".ogg") + " text", nil
+	})
+
+	output := filepath.Join(t.TempDir(), "out.md")
+	opts := mustParseTranscribeOptions(t, inputPath, output, "", false, 1, "", "", "deepseek")
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "chunk_0 text\n\n⭐ 00:02:00\n\nchunk_1 text\n\n⭐ 00:07:30"
+	if !strings.Contains(string(content), want) {
+		t.Errorf("output = %q, want markers after their chunk", content)
+	}
+	if !strings.Contains(stderr.String(), "Highlights: 2 markers from") {
+		t.Errorf("stderr = %q, want the markers reported", stderr.String())
+	}
+}
+
+func TestRunTranscribe_InvalidHighlights(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	if err := os.WriteFile(highlightsPath(inputPath), []byte("lunch\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stderr := &syncBuffer{}
+	env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "Hello.", nil
+	})
+
+	output := filepath.Join(t.TempDir(), "out.md")
+	opts := mustParseTranscribeOptions(t, inputPath, output, "", false, 1, "", "", "deepseek")
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), warnHighlights) {
+		t.Errorf("stderr = %q, want warning %s", stderr.String(), warnHighlights)
+	}
+	if content, _ := os.ReadFile(output); strings.Contains(string(content), "⭐") {
+		t.Errorf("output = %q, want no markers", content)
+	}
+}
+
+func TestRunTranscribe_Clean(t *testing.T) {
+	t.Parallel()
+
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "Euh, on a, tu vois, un un problème, du coup.", nil
+	})
+	env.ConfigLoader = &mockConfigLoader{
+		LoadFunc: func() (config.Config, error) {
+			return config.Config{CleanFillers: map[string][]string{"fr": {"du coup"}}}, nil
+		},
+	}
+
+	output := filepath.Join(t.TempDir(), "out.md")
+	opts := mustParseTranscribeOptions(t, createTestAudioFile(t, "audio.ogg"), output, "", false, 1, "fr", "", "deepseek")
+	opts.clean = true
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); strings.Contains(got, "tu vois") || !strings.Contains(got, "On a, un problème.") {
+		t.Errorf("output = %q, want cleaned transcript", got)
+	}
+}
+
+func TestRunTranscribe_Redact(t *testing.T) {
+	t.Parallel()
+
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "Euh, écrivez à marie arobase exemple point fr, ou au 06 12 34 56 78.", nil
+	})
+
+	output := filepath.Join(t.TempDir(), "out.md")
+	opts := mustParseTranscribeOptions(t, createTestAudioFile(t, "audio.ogg"), output, "", false, 1, "fr", "", "deepseek")
+	opts.clean = true
+	opts.redact = true
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); !strings.Contains(got, "Écrivez à [email], ou au [phone].") {
+		t.Errorf("output = %q, want cleaned and redacted transcript", got)
+	}
+}
+
+func TestRunTranscribe_RedactSavesNoCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	apiErr := errors.New("network down")
+	stderr := &syncBuffer{}
+	env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		if strings.HasSuffix(audioPath, "chunk_1.ogg") {
+			return "", apiErr
+		}
+		return "Write to jane@example.com.", nil
+	})
+
+	output := filepath.Join(t.TempDir(), "out.md")
+	opts := mustParseTranscribeOptions(t, createTestAudioFile(t, "audio.ogg"), output, "", false, 1, "", "", "deepseek")
+	opts.redact = true
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); !errors.Is(err, apiErr) {
+		t.Fatalf("RunTranscribe() error = %v, want apiErr", err)
+	}
+	if _, err := os.Stat(CheckpointPath(output)); !os.IsNotExist(err) {
+		t.Errorf("checkpoint with unredacted text written (stat error = %v)", err)
+	}
+	if strings.Contains(stderr.String(), "--resume") {
+		t.Errorf("stderr = %q, want no suggestion to resume", stderr.String())
+	}
+}
+
+func TestRunTranscribe_InvalidRedactPattern(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		calls++
+		return "", nil
+	})
+
+	opts := mustParseTranscribeOptions(t, createTestAudioFile(t, "audio.ogg"), filepath.Join(t.TempDir(), "out.md"), "", false, 1, "", "", "deepseek")
+	opts.redactPatterns = []string{`EMP-(`}
+	err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts)
+	if !errors.Is(err, redact.ErrInvalidPattern) {
+		t.Errorf("RunTranscribe() error = %v, want ErrInvalidPattern", err)
+	}
+	if calls != 0 {
+		t.Errorf("transcriber called %d times, want none before the pattern is checked", calls)
+	}
+}
+
+func TestRunTranscribe_OutputNameAndFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "Bonjour.", nil
+	})
+	env.Now = fixedTime(time.Date(2026, 10, 17, 14, 5, 0, 0, time.Local))
+	outputDir := t.TempDir()
+	env.ConfigLoader = &mockConfigLoader{LoadFunc: func() (config.Config, error) {
+		return config.Config{OutputDir: outputDir, OutputName: "{{date}}_{{template}}_{{input_stem}}.md", FrontMatter: true}, nil
+	}}
+
+	input := createTestAudioFile(t, "standup.ogg")
+	opts := mustParseTranscribeOptions(t, input, "", "", false, 1, "fr", "", "deepseek")
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "2026-10-17_transcript_standup.md"))
+	if err != nil {
+		t.Fatalf("output not named by the pattern: %v", err)
+	}
+	want := "---\ndate: 2026-10-17T14:05\nduration: \"10:00\"\nlanguage: fr\naudio: " + strconv.Quote(input) + "\n---\n\nBonjour."
+	if got := string(data); !strings.HasPrefix(got, want) {
+		t.Errorf("output = %q, want it to start with %q", got, want)
+	}
+}
+
+func TestRunTranscribe_InvalidOutputName(t *testing.T) {
+	t.Parallel()
+
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "Hello.", nil
+	})
+	env.ConfigLoader = &mockConfigLoader{LoadFunc: func() (config.Config, error) {
+		return config.Config{OutputName: "{{author}}.md"}, nil
+	}}
+
+	opts := mustParseTranscribeOptions(t, createTestAudioFile(t, "audio.ogg"), "", "", false, 1, "", "", "deepseek")
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); !errors.Is(err, notes.ErrInvalidName) {
+		t.Errorf("RunTranscribe() error = %v, want ErrInvalidName", err)
+	}
+}
+
+func TestRunTranscribe_ObsidianVault(t *testing.T) {
+	t.Parallel()
+
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "Bonjour.", nil
+	})
+	env.Now = fixedTime(time.Date(2026, 10, 17, 14, 5, 0, 0, time.Local))
+	vault := createTestVault(t)
+	env.ConfigLoader = &mockConfigLoader{LoadFunc: func() (config.Config, error) {
+		return config.Config{ObsidianVault: vault, ObsidianFolder: "Meetings"}, nil
+	}}
+
+	input := createTestAudioFile(t, "standup.ogg")
+	opts := mustParseTranscribeOptions(t, input, "", "", false, 1, "fr", "", "deepseek")
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(vault, "Meetings", "standup.md"))
+	if err != nil {
+		t.Fatalf("output not written to the vault folder: %v", err)
+	}
+	want := "---\ndate: 2026-10-17T14:05\nduration: \"10:00\"\nlanguage: fr\ntags:\n  - transcript\naudio: " + strconv.Quote(input) + "\n---\n\n" +
+		"Daily note: [[Daily/2026-10-17|2026-10-17]]\n\n[standup.ogg](<file://" + filepath.ToSlash(input) + ">)\n\nBonjour."
+	if got := string(data); !strings.HasPrefix(got, want) {
+		t.Errorf("output = %q, want it to start with %q", got, want)
+	}
+}
+
+func TestRunTranscribe_ObsidianVaultErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		vault   string
+		format  OutputFormat
+		wantErr error
+	}{
+		{"not a vault", t.TempDir(), OutputFormat{}, obsidian.ErrNotVault},
+		{"not markdown", createTestVault(t), SRTFormat, ErrInvalidOutputFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+				return "Hello.", nil
+			})
+			opts := mustParseTranscribeOptions(t, createTestAudioFile(t, "audio.ogg"), "", "", false, 1, "", "", "deepseek")
+			opts.obsidianVault = tt.vault
+			opts.format = tt.format
+			if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("RunTranscribe() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunTranscribe_Append(t *testing.T) {
+	t.Parallel()
+
+	sessions := []string{"Monday standup.", "Tuesday standup."}
+	output := filepath.Join(t.TempDir(), "journal.md")
+	for _, text := range sessions {
+		env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return text, nil
+		})
+		opts := mustParseTranscribeOptions(t, createTestAudioFile(t, "audio.ogg"), output, "", false, 1, "", "", "deepseek")
+		opts.outputMode = outputAppend
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+			t.Fatalf("RunTranscribe() unexpected error: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	monday, tuesday := strings.Index(got, sessions[0]), strings.Index(got, sessions[1])
+	if monday < 0 || tuesday < monday || !strings.Contains(got[monday:tuesday], "\n---\n") {
+		t.Errorf("output = %q, want both sessions with a separator between them", got)
+	}
+}
+
+func TestRunTranscribe_AppendSubtitles(t *testing.T) {
+	t.Parallel()
+
+	env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		t.Error("transcriber called, want the flags rejected first")
+		return "", nil
+	})
+	opts := mustParseTranscribeOptions(t, createTestAudioFile(t, "audio.ogg"), filepath.Join(t.TempDir(), "out.srt"), "", false, 1, "", "", "deepseek")
+	opts.format = SRTFormat
+	opts.outputMode = outputAppend
+	err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts)
+	if err == nil || !strings.Contains(err.Error(), "--append") {
+		t.Errorf("RunTranscribe() error = %v, want --append rejected", err)
+	}
+}
+
+// testFingerprint returns a deterministic fingerprint covering d, distinct per seed.
+func testFingerprint(seed uint32, d time.Duration) audio.Fingerprint {
+	fp := make(audio.Fingerprint, d/audio.FingerprintFrameDuration)
+	x := seed
+	for i := range fp {
+		x = x*1664525 + 1013904223
+		fp[i] = x | 1
+	}
+	return fp
+}
+
+func TestRunTranscribe_IntroOutro(t *testing.T) {
+	t.Parallel()
+
+	libraryPath := filepath.Join(t.TempDir(), "intros.json")
+	fingerprinter := &mockFingerprinter{
+		// Every episode starts and ends the same way
+		FingerprintFunc: func(ctx context.Context, audioPath string, start, length time.Duration) (audio.Fingerprint, error) {
+			if start == 0 {
+				return testFingerprint(1, length), nil
+			}
+			return testFingerprint(2, length), nil
+		},
+	}
+	newEnv := func(stderr *syncBuffer) *Env {
+		env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "Welcome back.", nil
+		})
+		env.ChunkerFactory = &mockChunkerFactory{mockChunker: &mockChunker{
+			ChunkFunc: func(ctx context.Context, audioPath string) ([]audio.Chunk, error) {
+				return []audio.Chunk{{Path: audioPath, EndTime: time.Minute}}, nil
+			},
+		}}
+		env.ConfigLoader = &mockConfigLoader{
+			LoadFunc: func() (config.Config, error) {
+				return config.Config{IntroLibrary: libraryPath}, nil
+			},
+		}
+		env.FingerprinterFactory = &mockFingerprinterFactory{mockFingerprinter: fingerprinter}
+		return env
+	}
+	run := func(mode, output string) (string, string) {
+		t.Helper()
+		stderr := &syncBuffer{}
+		opts := mustParseTranscribeOptions(t, createTestAudioFile(t, output+".ogg"), filepath.Join(t.TempDir(), output+".txt"), "", false, 1, "", "", "deepseek")
+		opts.format = TextFormat
+		opts.introOutro, _ = ParseIntroOutroMode(mode)
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), newEnv(stderr), opts); err != nil {
+			t.Fatalf("RunTranscribe() unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(opts.output)
+		if err != nil {
+			t.Fatalf("failed to read output: %v", err)
+		}
+		return string(data), stderr.String()
+	}
+
+	// First episode: nothing to compare with, transcribed entirely
+	got, _ := run(IntroOutroSkip, "episode1")
+	if got != "Welcome back." {
+		t.Errorf("first episode = %q, want %q", got, "Welcome back.")
+	}
+	if len(fingerprinter.ExtractCalls()) != 0 {
+		t.Errorf("first episode extracted %d pieces, want none", len(fingerprinter.ExtractCalls()))
+	}
+
+	// Second episode: intro and outro are recognized and left out
+	got, stderr := run(IntroOutroSkip, "episode2")
+	if got != "Welcome back." {
+		t.Errorf("second episode = %q, want %q", got, "Welcome back.")
+	}
+	if !strings.Contains(stderr, "Found intro") || !strings.Contains(stderr, "Found outro") {
+		t.Errorf("stderr = %q, want intro and outro reported", stderr)
+	}
+	// Fingerprints have a frame granularity
+	calls := fingerprinter.ExtractCalls()
+	if len(calls) != 1 || audio.IntroWindow-calls[0].Start > audio.FingerprintFrameDuration ||
+		calls[0].End != 10*time.Minute-audio.IntroWindow {
+		t.Errorf("Extract() calls = %+v, want the audio between intro and outro", calls)
+	}
+
+	// Mark mode: replaced by markers
+	got, _ = run(IntroOutroMark, "episode3")
+	if want := "[intro]\n\nWelcome back.\n\n[outro]"; got != want {
+		t.Errorf("marked episode = %q, want %q", got, want)
+	}
+
+	library, err := audio.LoadIntroLibrary(libraryPath)
+	if err != nil {
+		t.Fatalf("failed to load intro library: %v", err)
+	}
+	if len(library.Entries) != 3 {
+		t.Errorf("intro library has %d entries, want 3", len(library.Entries))
+	}
+}
+
+func TestTranscribeCmd_InvalidIntroOutro(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+
+	env, _ := testEnv()
+	cmd := TranscribeCmd(env)
+
+	cmd.SetArgs([]string{inputPath, "--intro-outro", "remove"})
+	err := cmd.Execute()
+
+	if !errors.Is(err, ErrInvalidIntroOutro) {
+		t.Errorf("cmd.Execute() error = %v, want ErrInvalidIntroOutro", err)
+	}
+}
+
+func TestTranscribeCmd_InvalidSpeakerLabels(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+
+	env, _ := testEnv()
+	cmd := TranscribeCmd(env)
+
+	cmd.SetArgs([]string{inputPath, "--format", "vtt", "--speaker-labels", "names"})
+	err := cmd.Execute()
+
+	if !errors.Is(err, ErrInvalidSpeakerLabels) {
+		t.Errorf("cmd.Execute() error = %v, want ErrInvalidSpeakerLabels", err)
+	}
+}
+
+func TestRunTranscribe_RestructureInterrupted(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	outputPath := filepath.Join(t.TempDir(), "notes.md")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stderr := &syncBuffer{}
+	env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "Hello.", nil
+	})
+	// Ctrl+C while restructuring
+	env.RestructurerFactory = &mockRestructurerFactory{
+		mockMapReducer: &mockMapReduceRestructurer{
+			RestructureFunc: func(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+				cancel()
+				return "", false, ctx.Err()
+			},
+		},
+	}
+
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "brainstorm", false, 5, "", "", "deepseek")
+	err := RunTranscribe(createTranscribeCmd(ctx), env, opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("RunTranscribe() error = %v, want context.Canceled", err)
+	}
+
+	rawPath := filepath.Join(filepath.Dir(outputPath), "notes.raw.md")
+	raw, err := os.ReadFile(rawPath)
+	if err != nil {
+		t.Fatalf("raw transcript not saved: %v", err)
+	}
+	if !strings.Contains(string(raw), "Hello.") {
+		t.Errorf("raw transcript = %q, want the transcription", raw)
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Errorf("output written after interrupt (stat error: %v)", err)
+	}
+	if want := "Raw transcript saved: " + rawPath; !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr = %q, want containing %q", stderr.String(), want)
+	}
+}
+
+func TestRunTranscribe_KeepRawTranscript(t *testing.T) {
+	t.Parallel()
+
+	newEnv := func(t *testing.T) *Env {
+		env := checkpointTestEnv(t, &syncBuffer{}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "Hello.", nil
+		})
+		env.RestructurerFactory = &mockRestructurerFactory{mockMapReducer: &mockMapReduceRestructurer{}}
+		return env
+	}
+
+	t.Run("next to the output", func(t *testing.T) {
+		t.Parallel()
+
+		inputPath := createTestAudioFile(t, "audio.ogg")
+		outputPath := filepath.Join(t.TempDir(), "notes.md")
+		opts := mustParseTranscribeOptions(t, inputPath, outputPath, "brainstorm", false, 5, "", "", "deepseek")
+		opts.keepRaw = true
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), newEnv(t), opts); err != nil {
+			t.Fatalf("RunTranscribe() unexpected error: %v", err)
+		}
+
+		raw, err := os.ReadFile(filepath.Join(filepath.Dir(outputPath), "notes.raw.md"))
+		if err != nil {
+			t.Fatalf("raw transcript not kept: %v", err)
+		}
+		if !strings.Contains(string(raw), "Hello.") {
+			t.Errorf("raw transcript = %q, want the transcription", raw)
+		}
+		if content, err := os.ReadFile(outputPath); err != nil || string(content) != "restructured text" {
+			t.Errorf("output = %q, %v, want the restructured text", content, err)
+		}
+	})
+
+	t.Run("raw output", func(t *testing.T) {
+		t.Parallel()
+
+		inputPath := createTestAudioFile(t, "audio.ogg")
+		dir := t.TempDir()
+		opts := mustParseTranscribeOptions(t, inputPath, filepath.Join(dir, "notes.md"), "brainstorm", false, 5, "", "", "deepseek")
+		opts.keepRaw = true
+		opts.rawOutput = filepath.Join(dir, "archive.md")
+		if err := RunTranscribe(createTranscribeCmd(context.Background()), newEnv(t), opts); err != nil {
+			t.Fatalf("RunTranscribe() unexpected error: %v", err)
+		}
+
+		if _, err := os.Stat(opts.rawOutput); err != nil {
+			t.Errorf("raw transcript not kept in --raw-output: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "notes.raw.md")); !os.IsNotExist(err) {
+			t.Errorf("raw transcript kept next to the output too (stat error: %v)", err)
+		}
+	})
+
+	t.Run("existing raw transcript", func(t *testing.T) {
+		t.Parallel()
+
+		inputPath := createTestAudioFile(t, "audio.ogg")
+		outputPath := filepath.Join(t.TempDir(), "notes.md")
+		rawPath := filepath.Join(filepath.Dir(outputPath), "notes.raw.md")
+		if err := os.WriteFile(rawPath, []byte("earlier"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		env := newEnv(t)
+		env.TranscriberFactory = &mockTranscriberFactory{
+			NewTranscriberFunc: func(apiKey string) transcribe.Transcriber {
+				t.Error("transcribed although the raw transcript exists")
+				return &mockTranscriber{}
+			},
+		}
+		opts := mustParseTranscribeOptions(t, inputPath, outputPath, "brainstorm", false, 5, "", "", "deepseek")
+		opts.keepRaw = true
+		err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts)
+		if !errors.Is(err, ErrOutputExists) {
+			t.Errorf("RunTranscribe() error = %v, want ErrOutputExists", err)
+		}
+	})
+}
+
+func TestTranscribeCmd_KeepRawTranscriptFlags(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "without template", args: []string{inputPath, "-r"}, wantErr: "require --template"},
+		{name: "raw output without template", args: []string{inputPath, "--raw-output", "raw.md"}, wantErr: "require --template"},
+		{name: "stdout", args: []string{inputPath, "-t", "meeting", "-r", "--stdout"}, wantErr: "--keep-raw-transcript cannot be combined with --stdout"},
+		{name: "session", args: []string{inputPath, "-t", "meeting", "-r", "--session-dir", t.TempDir()}, wantErr: "session-dir"},
+		{name: "several inputs", args: []string{inputPath, inputPath, "-t", "meeting", "--raw-output", "raw.md"}, wantErr: "--raw-output takes a single audio file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env, _ := testEnv()
+			cmd := TranscribeCmd(env)
+			cmd.SetArgs(tt.args)
+			err := cmd.Execute()
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("cmd.Execute() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunTranscribe_AllowPartial(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	outputPath := filepath.Join(t.TempDir(), "notes.md")
+
+	stderr := &syncBuffer{}
+	env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		if filepath.Base(audioPath) == "chunk_1.ogg" {
+			return "", errors.New("server error")
+		}
+		return "Hello.", nil
+	})
+
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "", false, 2, "", "", "deepseek")
+	opts.allowPartial = true
+	err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts)
+	if !errors.Is(err, transcribe.ErrPartial) {
+		t.Fatalf("RunTranscribe() error = %v, want ErrPartial", err)
+	}
+
+	content, readErr := os.ReadFile(outputPath)
+	if readErr != nil {
+		t.Fatalf("output not written: %v", readErr)
+	}
+	if want := "Hello.\n\n[transcription failed 05:00–10:00]"; string(content) != want {
+		t.Errorf("output = %q, want %q", content, want)
+	}
+	if _, statErr := os.Stat(CheckpointPath(outputPath)); statErr != nil {
+		t.Errorf("checkpoint removed, want it kept for --resume: %v", statErr)
+	}
+	for _, want := range []string{"chunk 1 (chunk_1.ogg): server error", "1/2 chunks missing"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr = %q, want containing %q", stderr.String(), want)
+		}
+	}
+}
+
+func TestRunTranscribe_Verbose(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+	outputPath := filepath.Join(t.TempDir(), "notes.md")
+
+	stderr := &syncBuffer{}
+	env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "Hello.", nil
+	})
+
+	opts := mustParseTranscribeOptions(t, inputPath, outputPath, "", false, 2, "", "", "deepseek")
+	opts.verbose = true
+	if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+		t.Fatalf("RunTranscribe() unexpected error: %v", err)
+	}
+	for _, want := range []string{"Timings:", "Slowest chunks:", "over 2 chunks"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr = %q, want containing %q", stderr.String(), want)
+		}
+	}
+}
+
+func TestMarkFailedChunks_Timestamps(t *testing.T) {
+	t.Parallel()
+
+	chunks := []audio.Chunk{
+		{Index: 0, StartTime: 0, EndTime: 90 * time.Second},
+		{Index: 1, StartTime: 90 * time.Second, EndTime: 3 * time.Minute},
+	}
+	results := []string{`[{"start":0,"end":2,"text":"Hello."}]`, ""}
+	partial := &transcribe.PartialError{Failed: []*transcribe.ChunkError{
+		{Chunk: chunks[1], Err: errors.New("server error")},
+	}}
+
+	if err := markFailedChunks(&syncBuffer{}, chunks, results, partial, true); err != nil {
+		t.Fatalf("markFailedChunks() unexpected error: %v", err)
+	}
+	got, err := renderTranscript(SRTFormat, SpeakerLabels{}, chunks, results)
+	if err != nil {
+		t.Fatalf("renderTranscript() unexpected error: %v", err)
+	}
+	want := "00:01:30,000 --> 00:03:00,000\n[transcription failed 01:30–03:00]"
+	if !strings.Contains(got, want) {
+		t.Errorf("subtitles = %q, want containing %q", got, want)
 	}
 }
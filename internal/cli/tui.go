@@ -0,0 +1,523 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// Terminal UI of live --tui.
+const (
+	tuiClockRefresh = time.Second // Redraw period of the elapsed time.
+	tuiMaxPreview   = 200         // Segments kept for the transcript preview.
+	tuiHeaderLines  = 5           // Lines above the transcript preview.
+	tuiFooterLines  = 2           // Lines below the transcript preview.
+)
+
+// Key presses of the live TUI. Ctrl+C keeps interrupting, as without --tui.
+const (
+	keyStop      = 'q'
+	keyHighlight = 'h'
+	keyNote      = 'n'
+	keyEscape    = 0x1b
+	keyBackspace = 0x7f
+)
+
+// ANSI sequences drawing the TUI on the alternate screen, which is left as it
+// was once the TUI closes.
+const (
+	ansiEnterScreen = "\x1b[?1049h\x1b[?25l" // Alternate screen, cursor hidden
+	ansiLeaveScreen = "\x1b[?25h\x1b[?1049l" // Cursor shown, main screen
+	ansiHome        = "\x1b[H"               // Cursor to the top left
+	ansiClearLine   = "\x1b[K"               // Clear to the end of the line
+	ansiClearBelow  = "\x1b[J"               // Clear to the end of the screen
+)
+
+// liveMark is a highlight or a note added while recording, from the live TUI
+// or as a highlight marker (see highlights).
+type liveMark struct {
+	at   time.Duration // Time of the recording
+	note string        // Text of the note (empty: highlight)
+}
+
+// markdown renders the mark as a paragraph of the transcript.
+func (m liveMark) markdown() string {
+	if m.note == "" {
+		return format.Highlight(m.at)
+	}
+	return "> Note at " + format.Duration(m.at) + ": " + m.note
+}
+
+// appendMarks returns text followed by the marks, as paragraphs.
+func appendMarks(text string, marks []liveMark) string {
+	for _, m := range marks {
+		if text != "" {
+			text += "\n\n"
+		}
+		text += m.markdown()
+	}
+	return text
+}
+
+// marksSection returns the marks as a section ending a restructured output,
+// whose text no longer follows the recording. Empty without marks.
+func marksSection(marks []liveMark) string {
+	if len(marks) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\n## Highlights and notes\n\n")
+	for _, m := range marks {
+		text := m.note
+		if text == "" {
+			text = "Highlight"
+		}
+		fmt.Fprintf(&b, "- %s %s\n", format.Duration(m.at), text)
+	}
+	return b.String()
+}
+
+// liveTUI is the terminal UI of live --tui: elapsed time, level meter,
+// segment progress and a preview of the transcript, redrawn in place, with
+// keys to stop the recording early, mark highlights and add notes.
+// Text written to it, like warnings, is shown on its status line.
+//
+// Methods of a nil *liveTUI do nothing, so that callers do not check --tui.
+type liveTUI struct {
+	term     Terminal
+	now      func() time.Time
+	duration time.Duration
+	stop     func() // Stops the recording early; set before run
+
+	mu       sync.Mutex
+	running  bool
+	closed   bool
+	start    time.Time // Start of the recording
+	end      time.Time // End of the recording (zero: recording)
+	width    int
+	height   int
+	loudness float64
+	level    bool // A loudness was measured
+	segments int  // Segments transcribed
+	preview  []string
+	status   string // Last line written to the TUI
+	partial  []byte // Line being written to the TUI
+	marks    []liveMark
+	merged   int // Marks already merged into the transcript
+	typing   bool
+	input    []rune // Note being typed
+	stopping bool   // Stop key pressed
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newLiveTUI creates the TUI of a recording of duration, drawn on term.
+func newLiveTUI(term Terminal, now func() time.Time, duration time.Duration) *liveTUI {
+	return &liveTUI{term: term, now: now, duration: duration, done: make(chan struct{})}
+}
+
+// openTerminal opens the terminal of live --tui. Returns ErrNoTerminal
+// without terminal factory.
+func openTerminal(env *Env) (Terminal, error) {
+	if env.TerminalFactory == nil {
+		return nil, fmt.Errorf("%w: --tui needs a terminal", ErrNoTerminal)
+	}
+	return env.TerminalFactory.OpenTerminal()
+}
+
+// run starts the recording clock, draws the TUI and handles key presses until
+// close is called.
+func (t *liveTUI) run() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.start = t.now()
+	t.running = true
+	t.width, t.height = t.term.Size()
+	_, _ = t.term.Write([]byte(ansiEnterScreen))
+	t.draw()
+	t.mu.Unlock()
+
+	// The key reader may stay blocked in Read after close: it is not waited for.
+	go t.readKeys()
+	t.wg.Go(func() {
+		ticker := time.NewTicker(tuiClockRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				width, height := t.term.Size()
+				t.mu.Lock()
+				t.width, t.height = width, height
+				t.draw()
+				t.mu.Unlock()
+			case <-t.done:
+				return
+			}
+		}
+	})
+}
+
+// readKeys handles key presses until the terminal has no more input.
+func (t *liveTUI) readKeys() {
+	r := bufio.NewReader(t.term)
+	for {
+		key, _, err := r.ReadRune()
+		if err != nil {
+			return
+		}
+		t.key(key)
+	}
+}
+
+// close restores the screen and the terminal. Later updates are ignored.
+func (t *liveTUI) close() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.closed = true
+	running := t.running
+	t.mu.Unlock()
+
+	if running {
+		close(t.done)
+		t.wg.Wait()
+		_, _ = t.term.Write([]byte(ansiLeaveScreen))
+	}
+	_ = t.term.Close()
+}
+
+// key handles a key press: stop, highlight and note keys, or the text of the
+// note being typed (Enter adds it, Esc cancels it).
+func (t *liveTUI) key(r rune) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+
+	if t.typing {
+		switch r {
+		case '\r', '\n':
+			if note := strings.TrimSpace(string(t.input)); note != "" {
+				t.marks = append(t.marks, liveMark{at: t.elapsed(), note: note})
+			}
+			t.typing, t.input = false, nil
+		case keyEscape:
+			t.typing, t.input = false, nil
+		case keyBackspace, '\b':
+			if len(t.input) > 0 {
+				t.input = t.input[:len(t.input)-1]
+			}
+		default:
+			if unicode.IsPrint(r) {
+				t.input = append(t.input, r)
+			}
+		}
+		t.draw()
+		return
+	}
+
+	switch unicode.ToLower(r) {
+	case keyStop:
+		if !t.stopping && t.end.IsZero() && t.stop != nil {
+			t.stopping = true
+			t.stop()
+		}
+	case keyHighlight:
+		t.marks = append(t.marks, liveMark{at: t.elapsed()})
+	case keyNote:
+		t.typing = true
+	}
+	t.draw()
+}
+
+// highlight marks a highlight, as the highlight key does, even while a note
+// is typed (SIGUSR2).
+func (t *liveTUI) highlight() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.marks = append(t.marks, liveMark{at: t.elapsed()})
+	t.draw()
+}
+
+// setLevel shows the momentary loudness of the input, in LUFS.
+func (t *liveTUI) setLevel(loudness float64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.loudness, t.level = loudness, true
+	t.draw()
+}
+
+// segment adds a transcribed segment, starting at start, to the preview.
+func (t *liveTUI) segment(start time.Duration, text string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.segments++
+	t.preview = append(t.preview, "["+format.Duration(start)+"] "+text)
+	if len(t.preview) > tuiMaxPreview {
+		t.preview = t.preview[len(t.preview)-tuiMaxPreview:]
+	}
+	t.draw()
+}
+
+// recordingStopped stops the recording clock: the last segments are still
+// being transcribed.
+func (t *liveTUI) recordingStopped() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.end = t.now()
+	t.draw()
+}
+
+// Write shows the last line written on the status line.
+func (t *liveTUI) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.partial = append(t.partial, p...)
+	for {
+		i := strings.IndexAny(string(t.partial), "\r\n")
+		if i < 0 {
+			break
+		}
+		if line := strings.TrimSpace(string(t.partial[:i])); line != "" {
+			t.status = line
+		}
+		t.partial = t.partial[i+1:]
+	}
+	t.draw()
+	return len(p), nil
+}
+
+// takeMarks returns the marks added before the time at of the recording, and
+// not taken yet: they follow the segment ending at at.
+func (t *liveTUI) takeMarks(at time.Duration) []liveMark {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	start := t.merged
+	for t.merged < len(t.marks) && t.marks[t.merged].at < at {
+		t.merged++
+	}
+	return t.marks[start:t.merged:t.merged]
+}
+
+// pendingMarks returns the marks not taken yet, added after the last segment.
+func (t *liveTUI) pendingMarks() []liveMark {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	marks := t.marks[t.merged:]
+	t.merged = len(t.marks)
+	return marks
+}
+
+// allMarks returns the highlights and notes added, in order.
+func (t *liveTUI) allMarks() []liveMark {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]liveMark(nil), t.marks...)
+}
+
+// stopped reports whether the recording was stopped with the stop key.
+func (t *liveTUI) stopped() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stopping
+}
+
+// elapsed returns the time recorded. The caller holds t.mu.
+func (t *liveTUI) elapsed() time.Duration {
+	if t.start.IsZero() {
+		return 0
+	}
+	end := t.end
+	if end.IsZero() {
+		end = t.now()
+	}
+	return min(max(end.Sub(t.start), 0), t.duration)
+}
+
+// draw redraws the screen. The caller holds t.mu.
+func (t *liveTUI) draw() {
+	if !t.running || t.closed {
+		return
+	}
+	lines := t.render(t.width, t.height)
+	_, _ = t.term.Write([]byte(ansiHome + strings.Join(lines, ansiClearLine+"\r\n") + ansiClearLine + ansiClearBelow))
+}
+
+// render returns the lines of the screen, for a terminal of width x height
+// cells. The caller holds t.mu.
+func (t *liveTUI) render(width, height int) []string {
+	width = max(width, 20)
+	elapsed := t.elapsed()
+
+	state := "● REC"
+	switch {
+	case !t.end.IsZero():
+		state = "■ Stopped, transcribing the last segments"
+	case t.stopping:
+		state = "■ Stopping"
+	}
+	level := "Level " + meterBar(meterFloor-1) + "        n/a"
+	if t.level {
+		level = "Level " + meterBar(t.loudness) + " " + loudnessLabel(t.loudness)
+	}
+	waiting := max(int(elapsed/streamSegmentDuration)-t.segments, 0)
+	rule := strings.Repeat("─", width)
+
+	lines := []string{
+		fmt.Sprintf("%s  %s / %s", state, format.Duration(elapsed), format.Duration(t.duration)),
+		level,
+		fmt.Sprintf("Segments: %d transcribed, %d waiting", t.segments, waiting),
+		t.status,
+		rule,
+	}
+
+	// The end of the transcript fills the lines left
+	rows := max(height-tuiHeaderLines-tuiFooterLines, 1)
+	var preview []string
+	for i := len(t.preview) - 1; i >= 0 && len(preview) < rows; i-- {
+		wrapped := wrapText(t.preview[i], width)
+		preview = append(wrapped[max(len(wrapped)-(rows-len(preview)), 0):], preview...)
+	}
+	for len(preview) < rows {
+		preview = append(preview, "")
+	}
+	lines = append(lines, preview...)
+
+	footer := "q: stop  h: highlight  n: note"
+	if len(t.marks) > 0 {
+		footer += "  (" + marksCount(t.marks) + ")"
+	}
+	if t.typing {
+		footer = "Note: " + string(t.input) + "_  (Enter: add, Esc: cancel)"
+	}
+	lines = append(lines, rule, footer)
+
+	for i, line := range lines {
+		lines[i] = truncateText(line, width)
+	}
+	return lines
+}
+
+// marksCount describes the highlights and notes added, like
+// "2 highlights, 1 note".
+func marksCount(marks []liveMark) string {
+	highlights, notes := 0, 0
+	for _, m := range marks {
+		if m.note == "" {
+			highlights++
+		} else {
+			notes++
+		}
+	}
+	var parts []string
+	if highlights > 0 {
+		parts = append(parts, plural(highlights, "highlight"))
+	}
+	if notes > 0 {
+		parts = append(parts, plural(notes, "note"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// plural returns n and word, with an s unless n is 1.
+func plural(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
+
+// wrapText splits s into lines of at most width characters, between words
+// when possible.
+func wrapText(s string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		for utf8.RuneCountInString(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			r := []rune(word)
+			lines = append(lines, string(r[:width]))
+			word = string(r[width:])
+		}
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// truncateText returns s cut to width characters.
+func truncateText(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width])
+}
+
+// monitorTUILevels shows the input loudness in tui while recorder records,
+// and warns there if the input stays silent, like monitorLevels.
+// The returned function ends the meter; call it once recording stops.
+func monitorTUILevels(env *Env, recorder audio.Recorder, tui *liveTUI) (stop func()) {
+	monitor, ok := recorder.(audio.LevelMonitor)
+	if !ok {
+		return func() {}
+	}
+	m := newLevelMeter(tui, env.Now, false)
+	monitor.MonitorLevels(func(loudness float64) {
+		m.update(loudness)
+		tui.setLevel(loudness)
+	})
+	return m.stop
+}
@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tuiClock is a clock set by the test, read by the TUI redraws.
+type tuiClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *tuiClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *tuiClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// newTestTUI returns the TUI of a 10-minute recording drawn on a mock
+// terminal, and its clock, set to start.
+func newTestTUI(t *testing.T, start time.Time) (*liveTUI, *mockTerminal, *tuiClock) {
+	t.Helper()
+	clock := &tuiClock{now: start}
+	term := &mockTerminal{keys: strings.NewReader("")}
+	tui := newLiveTUI(term, clock.Now, 10*time.Minute)
+	t.Cleanup(tui.close)
+	return tui, term, clock
+}
+
+func TestLiveTUI_Keys(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 10, 17, 14, 0, 0, 0, time.UTC)
+	tui, _, clock := newTestTUI(t, start)
+	stops := 0
+	tui.stop = func() { stops++ }
+	tui.run()
+
+	clock.Set(start.Add(45 * time.Second))
+	tui.key('h')
+	clock.Set(start.Add(75 * time.Second))
+	for _, r := range "nfollow upx\x7f\r" {
+		tui.key(r)
+	}
+	for _, r := range "nnever\x1b" {
+		tui.key(r)
+	}
+	tui.key('q')
+	tui.key('Q')
+
+	want := []liveMark{{at: 45 * time.Second}, {at: 75 * time.Second, note: "follow up"}}
+	if got := tui.allMarks(); !slices.Equal(got, want) {
+		t.Errorf("allMarks() = %v, want %v", got, want)
+	}
+	if stops != 1 || !tui.stopped() {
+		t.Errorf("stop called %d times, stopped() = %v, want once and true", stops, tui.stopped())
+	}
+}
+
+func TestLiveTUI_Highlight(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 10, 17, 14, 0, 0, 0, time.UTC)
+	tui, _, clock := newTestTUI(t, start)
+	tui.run()
+
+	// SIGUSR2 while a note is typed marks a highlight, not a letter of the note
+	clock.Set(start.Add(20 * time.Second))
+	tui.key('n')
+	tui.key('o')
+	tui.highlight()
+	for _, r := range "k\r" {
+		tui.key(r)
+	}
+	tui.close()
+	tui.highlight()
+
+	want := []liveMark{{at: 20 * time.Second}, {at: 20 * time.Second, note: "ok"}}
+	if got := tui.allMarks(); !slices.Equal(got, want) {
+		t.Errorf("allMarks() = %v, want %v", got, want)
+	}
+	if got := highlightTimes(want); !slices.Equal(got, []time.Duration{20 * time.Second}) {
+		t.Errorf("highlightTimes() = %v, want the highlight only", got)
+	}
+}
+
+func TestLiveTUI_TakeMarks(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 10, 17, 14, 0, 0, 0, time.UTC)
+	tui, _, clock := newTestTUI(t, start)
+	tui.run()
+	for _, at := range []time.Duration{10 * time.Second, 40 * time.Second, 50 * time.Second, 2 * time.Minute} {
+		clock.Set(start.Add(at))
+		tui.key('h')
+	}
+
+	if got := tui.takeMarks(30 * time.Second); len(got) != 1 || got[0].at != 10*time.Second {
+		t.Errorf("takeMarks(30s) = %v, want the mark at 10s", got)
+	}
+	if got := tui.takeMarks(time.Minute); len(got) != 2 {
+		t.Errorf("takeMarks(1m) = %v, want the marks at 40s and 50s", got)
+	}
+	if got := tui.takeMarks(90 * time.Second); len(got) != 0 {
+		t.Errorf("takeMarks(1m30s) = %v, want none", got)
+	}
+	if got := tui.pendingMarks(); len(got) != 1 || got[0].at != 2*time.Minute {
+		t.Errorf("pendingMarks() = %v, want the mark at 2m", got)
+	}
+	if got := tui.pendingMarks(); len(got) != 0 {
+		t.Errorf("pendingMarks() again = %v, want none", got)
+	}
+}
+
+func TestLiveTUI_Render(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 10, 17, 14, 0, 0, 0, time.UTC)
+	tui, term, clock := newTestTUI(t, start)
+	tui.run()
+	clock.Set(start.Add(95 * time.Second))
+	tui.setLevel(-23.4)
+	tui.segment(0, "Hello and welcome.")
+	tui.segment(30*time.Second, strings.Repeat("word ", 30))
+	tui.key('h')
+	_, _ = tui.Write([]byte("Warning: slow upload\n"))
+	tui.key('n')
+	tui.key('x')
+
+	tui.mu.Lock()
+	lines := tui.render(40, 12)
+	tui.mu.Unlock()
+
+	if len(lines) != 12 {
+		t.Fatalf("render() returned %d lines, want 12:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	for i, want := range map[int]string{
+		0:  "● REC  01:35 / 10:00",
+		1:  "Level [############--------] -23.4 LUFS",
+		2:  "Segments: 2 transcribed, 1 waiting",
+		3:  "Warning: slow upload",
+		11: "Note: x_  (Enter: add, Esc: cancel)",
+	} {
+		if lines[i] != want {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want)
+		}
+	}
+	// The end of the transcript fills the 5 preview lines, wrapped
+	if lines[5] != "[00:00] Hello and welcome." || !strings.HasPrefix(lines[6], "[00:30] word") {
+		t.Errorf("preview = %q, want both segments", lines[5:10])
+	}
+	for _, line := range lines {
+		if n := len([]rune(line)); n > 40 {
+			t.Errorf("line %q is %d characters wide, want at most 40", line, n)
+		}
+	}
+
+	tui.key('\x1b')
+	tui.mu.Lock()
+	footer := tui.render(80, 12)[11]
+	tui.mu.Unlock()
+	if want := "q: stop  h: highlight  n: note  (1 highlight)"; footer != want {
+		t.Errorf("footer = %q, want %q", footer, want)
+	}
+
+	tui.close()
+	if !term.Closed() || !strings.HasSuffix(term.screen.String(), ansiLeaveScreen) {
+		t.Error("close() did not restore the screen and the terminal")
+	}
+}
+
+func TestAppendMarks(t *testing.T) {
+	t.Parallel()
+
+	marks := []liveMark{{at: 45 * time.Second}, {at: 75 * time.Minute, note: "follow up"}}
+	want := "Hello.\n\n⭐ 00:00:45\n\n> Note at 01:15:00: follow up"
+	if got := appendMarks("Hello.", marks); got != want {
+		t.Errorf("appendMarks() = %q, want %q", got, want)
+	}
+	if got := appendMarks("", marks[:1]); got != "⭐ 00:00:45" {
+		t.Errorf("appendMarks() on empty text = %q", got)
+	}
+
+	wantSection := "\n\n## Highlights and notes\n\n- 00:45 Highlight\n- 01:15:00 follow up\n"
+	if got := marksSection(marks); got != wantSection {
+		t.Errorf("marksSection() = %q, want %q", got, wantSection)
+	}
+	if got := marksSection(nil); got != "" {
+		t.Errorf("marksSection(nil) = %q, want empty", got)
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		s     string
+		width int
+		want  []string
+	}{
+		{"one two three", 7, []string{"one two", "three"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"", 10, []string{""}},
+	}
+	for _, tt := range tests {
+		if got := wrapText(tt.s, tt.width); !slices.Equal(got, tt.want) {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
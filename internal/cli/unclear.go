@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// defaultUnclear is the confidence threshold of --unclear without a value.
+const defaultUnclear = "50%"
+
+// unclearFlagHelp describes the --unclear flag of the transcribe and live commands.
+const unclearFlagHelp = "List the passages transcribed with low confidence, with their time, to check against the audio: below 50%, or --unclear=70%"
+
+// parseUnclear parses the confidence threshold of --unclear: a percentage
+// ("70%") or a fraction ("0.7"). Empty means no list.
+// Returns ErrInvalidConfidence if the value is not a threshold between 0 and 1.
+func parseUnclear(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	number, percent := strings.CutSuffix(value, "%")
+	threshold, err := strconv.ParseFloat(number, 64)
+	if percent {
+		threshold /= 100
+	}
+	if err != nil || threshold <= 0 || threshold >= 1 {
+		return 0, fmt.Errorf("invalid --unclear threshold %q (use a confidence like 50%% or 0.5): %w", value, ErrInvalidConfidence)
+	}
+	return threshold, nil
+}
+
+// validateUnclear checks that the list of --unclear has somewhere to go:
+// subtitles have no room for it.
+func validateUnclear(threshold float64, f OutputFormat) error {
+	if threshold > 0 && f.IsSubtitle() {
+		return fmt.Errorf("--unclear cannot be combined with --format %s (subtitles have no room for the list)", f)
+	}
+	return nil
+}
+
+// unclearSection returns the "## Unclear passages" section listing the
+// segments of results, transcribed from chunks with timestamps, below
+// threshold (see format.Unclear), or "" without --unclear or if none is. It
+// reports the count on w, or warns if the transcriber gave no confidence.
+func unclearSection(w io.Writer, threshold float64, f OutputFormat, chunks []audio.Chunk, results []string) (string, error) {
+	if threshold <= 0 {
+		return "", nil
+	}
+	cues, err := mergeCues(chunks, results)
+	if err != nil {
+		return "", err
+	}
+	return unclearCues(w, threshold, f, cues), nil
+}
+
+// unclearCues is unclearSection for cues already merged.
+func unclearCues(w io.Writer, threshold float64, f OutputFormat, cues []format.Cue) string {
+	if threshold <= 0 {
+		return ""
+	}
+	if !format.HasConfidence(cues) {
+		warnf(w, warnNoConfidence, "the transcriber gave no confidence scores, no passage is listed as unclear")
+		return ""
+	}
+	list := format.Unclear(cues, threshold, f.OrDefault() == MarkdownFormat)
+	fmt.Fprintf(w, "Unclear passages: %d below %.0f%% confidence\n", strings.Count(list, "\n"), threshold*100)
+	if list == "" {
+		return ""
+	}
+	return fmt.Sprintf("## Unclear passages\n\nTranscribed with less than %.0f%% confidence: check them against the audio.\n\n%s", threshold*100, list)
+}
+
+// appendSection returns content with section appended after a blank line,
+// or content as it is if section is empty.
+func appendSection(content, section string) string {
+	if section == "" {
+		return content
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + section
+}
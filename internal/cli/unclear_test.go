@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestParseUnclear(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: defaultUnclear, want: 0.5},
+		{value: "70%", want: 0.7},
+		{value: "0.25", want: 0.25},
+		{value: "0", wantErr: true},
+		{value: "100%", wantErr: true},
+		{value: "70", wantErr: true},
+		{value: "often", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseUnclear(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidConfidence) {
+					t.Errorf("parseUnclear(%q) error = %v, want ErrInvalidConfidence", tt.value, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseUnclear(%q) = %v, %v, want %v", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateUnclear(t *testing.T) {
+	t.Parallel()
+
+	if err := validateUnclear(0.5, TextFormat); err != nil {
+		t.Errorf("validateUnclear(txt) unexpected error: %v", err)
+	}
+	if err := validateUnclear(0, SRTFormat); err != nil {
+		t.Errorf("validateUnclear(off, srt) unexpected error: %v", err)
+	}
+	if err := validateUnclear(0.5, VTTFormat); err == nil || !strings.Contains(err.Error(), "--format vtt") {
+		t.Errorf("validateUnclear(vtt) error = %v, want a subtitle conflict", err)
+	}
+}
+
+func TestRunTranscribe_Unclear(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		response string
+		want     string
+		wantWarn bool
+	}{
+		{
+			name: "listed after the transcript",
+			response: `[{"start":1,"end":2,"text":"Welcome.","confidence":0.93},` +
+				`{"start":2,"end":4,"text":"Kubeflow pipelines.","confidence":0.41}]`,
+			want: "Welcome. Kubeflow pipelines.\n\n## Unclear passages\n\n" +
+				"Transcribed with less than 50% confidence: check them against the audio.\n\n" +
+				"- **[00:00:02]** Kubeflow pipelines. (41%)\n",
+		},
+		{
+			name:     "none below the threshold",
+			response: `[{"start":1,"end":2,"text":"Welcome.","confidence":0.93}]`,
+			want:     "Welcome.",
+		},
+		{
+			name:     "no confidence",
+			response: `[{"start":1,"end":2,"text":"Welcome."}]`,
+			want:     "Welcome.",
+			wantWarn: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inputPath := createTestAudioFile(t, "audio.ogg")
+			output := filepath.Join(t.TempDir(), "out.md")
+			stderr := &syncBuffer{}
+			env := checkpointTestEnv(t, stderr, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+				if !opts.Timestamps {
+					return "", errors.New("timestamps not requested")
+				}
+				if filepath.Base(audioPath) != "chunk_0.ogg" {
+					return "[]", nil
+				}
+				return tt.response, nil
+			})
+
+			opts := mustParseTranscribeOptions(t, inputPath, output, "", false, 5, "", "", "deepseek")
+			opts.unclear = 0.5
+			if err := RunTranscribe(createTranscribeCmd(context.Background()), env, opts); err != nil {
+				t.Fatalf("RunTranscribe() unexpected error: %v", err)
+			}
+
+			content, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			if got := strings.TrimRight(string(content), "\n"); got != strings.TrimRight(tt.want, "\n") {
+				t.Errorf("output = %q, want %q", content, tt.want)
+			}
+			if warned := strings.Contains(stderr.String(), warnNoConfidence); warned != tt.wantWarn {
+				t.Errorf("stderr = %q, want warning %s: %v", stderr.String(), warnNoConfidence, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestTranscribeCmd_InvalidUnclear(t *testing.T) {
+	t.Parallel()
+
+	inputPath := createTestAudioFile(t, "audio.ogg")
+
+	env, _ := testEnv()
+	cmd := TranscribeCmd(env)
+
+	cmd.SetArgs([]string{inputPath, "--unclear=150%"})
+	err := cmd.Execute()
+
+	if !errors.Is(err, ErrInvalidConfidence) {
+		t.Errorf("cmd.Execute() error = %v, want ErrInvalidConfidence", err)
+	}
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/trash"
+)
+
+// UndoCmd creates the undo command (restore the last replaced output file).
+// The env parameter provides injectable dependencies for testing.
+func UndoCmd(env *Env) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "undo [output-dir]",
+		Short: "Restore the last replaced output file",
+		Long: `Restore the last output file replaced by an overwrite.
+
+Replaced files are not deleted: their previous version is moved to a .trash
+directory in the output directory. The trash keeps up to 100 MB; the oldest
+versions are removed first.
+
+undo puts the most recent version back. The file that replaced it is moved to
+the trash in turn, so running undo again reverts the undo.
+
+The output directory, given as argument or with --output, defaults to the
+configured output-dir, or the current directory.`,
+		Example: `  transcript undo
+  transcript undo ~/Documents/transcripts
+  transcript undo -o ~/Documents/transcripts`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := output
+			if len(args) == 1 {
+				if output != "" {
+					return fmt.Errorf("give the output directory as argument or with --output, not both")
+				}
+				dir = args[0]
+			}
+			return runUndo(env, dir)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output directory of the replaced file (default: output-dir, or the current directory)")
+
+	return cmd
+}
+
+// runUndo restores the most recently replaced file of dir.
+// An empty dir means the configured output directory, or the current directory.
+func runUndo(env *Env, dir string) error {
+	if dir == "" {
+		cfg, err := env.ConfigLoader.Load()
+		if err != nil {
+			warnf(env.Stderr, warnConfigLoad, "failed to load config: %v", err)
+		}
+		dir = cfg.OutputDir
+	}
+	if dir == "" {
+		dir = "."
+	}
+
+	entry, err := trash.New(dir, trash.WithNow(env.Now)).Restore()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(env.Stderr, "Restored %s (replaced %s)\n", entry.Original, entry.ReplacedAt.Local().Format("2006-01-02 15:04:05"))
+	return nil
+}
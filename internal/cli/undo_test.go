@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/trash"
+)
+
+func TestRunUndo(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(path, []byte("previous"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := trash.New(dir).Move(path); err != nil {
+		t.Fatalf("Move() unexpected error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("replacement"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	stderr := &syncBuffer{}
+	env, mocks := testEnv(func(o *testEnvOptions) { o.stderr = stderr })
+	mocks.configLoader.LoadFunc = func() (config.Config, error) {
+		return config.Config{OutputDir: dir}, nil
+	}
+
+	if err := RunUndo(env, ""); err != nil {
+		t.Fatalf("RunUndo() unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "previous" {
+		t.Errorf("after RunUndo() content = %q, want %q", data, "previous")
+	}
+	if !strings.Contains(stderr.String(), "Restored "+path) {
+		t.Errorf("stderr = %q, want restore message", stderr.String())
+	}
+}
+
+func TestRunUndo_NothingToUndo(t *testing.T) {
+	t.Parallel()
+
+	env, _ := testEnv()
+	if err := RunUndo(env, t.TempDir()); !errors.Is(err, trash.ErrEmpty) {
+		t.Errorf("RunUndo() error = %v, want ErrEmpty", err)
+	}
+}
+
+func TestUndoCmd_Output(t *testing.T) {
+	t.Parallel()
+
+	t.Run("output directory", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		cmd := UndoCmd(env)
+		cmd.SetArgs([]string{"-o", t.TempDir()})
+		if err := cmd.Execute(); !errors.Is(err, trash.ErrEmpty) {
+			t.Errorf("UndoCmd.Execute() error = %v, want ErrEmpty from the --output directory", err)
+		}
+	})
+
+	t.Run("argument and output", func(t *testing.T) {
+		t.Parallel()
+
+		env, _ := testEnv()
+		cmd := UndoCmd(env)
+		cmd.SetArgs([]string{t.TempDir(), "-o", t.TempDir()})
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "not both") {
+			t.Errorf("UndoCmd.Execute() error = %v, want both-given error", err)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package cli
+
+import "github.com/spf13/cobra"
+
+// UsageError is a flag or argument error of a command: its message is the
+// one of Cobra, and it matches ErrUsage with errors.Is, so that the exit code
+// does not depend on Cobra messages.
+type UsageError struct {
+	Err error
+}
+
+// Error returns the message of the wrapped error.
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrUsage.
+func (e *UsageError) Is(target error) bool {
+	return target == ErrUsage
+}
+
+// WrapUsageErrors makes the flag and argument errors of root and all its
+// subcommands UsageErrors: flag parsing errors, positional argument errors,
+// missing required flags and flag group violations. Call it once all
+// commands are added.
+func WrapUsageErrors(root *cobra.Command) {
+	root.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &UsageError{Err: err}
+	})
+	wrapUsageErrors(root)
+}
+
+// wrapUsageErrors wraps the argument validation of cmd and its subcommands,
+// and validates their flags before they run.
+func wrapUsageErrors(cmd *cobra.Command) {
+	// The root command keeps its default validation, done by Cobra before
+	// any command runs, which reports unknown commands.
+	if cmd.HasParent() {
+		cmd.Args = usageArgs(cmd.Args)
+	}
+	// Cobra checks required flags and flag groups after PreRunE, returning
+	// its errors unwrapped: they are checked first here.
+	preRun, preRunE := cmd.PreRun, cmd.PreRunE
+	cmd.PreRun = nil
+	cmd.PreRunE = func(c *cobra.Command, args []string) error {
+		if err := c.ValidateRequiredFlags(); err != nil {
+			return &UsageError{Err: err}
+		}
+		if err := c.ValidateFlagGroups(); err != nil {
+			return &UsageError{Err: err}
+		}
+		switch {
+		case preRunE != nil:
+			return preRunE(c, args)
+		case preRun != nil:
+			preRun(c, args)
+		}
+		return nil
+	}
+	for _, sub := range cmd.Commands() {
+		wrapUsageErrors(sub)
+	}
+}
+
+// usageArgs returns the argument validation args, with its errors wrapped
+// in UsageError. Without validation, any argument is accepted, as by Cobra
+// for subcommands.
+func usageArgs(args cobra.PositionalArgs) cobra.PositionalArgs {
+	if args == nil {
+		args = cobra.ArbitraryArgs
+	}
+	return func(cmd *cobra.Command, a []string) error {
+		if err := args(cmd, a); err != nil {
+			return &UsageError{Err: err}
+		}
+		return nil
+	}
+}
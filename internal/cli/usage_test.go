@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// usageTestRoot returns a command tree like the one of main, with usage
+// errors wrapped.
+func usageTestRoot() *cobra.Command {
+	root := &cobra.Command{Use: "transcript", SilenceUsage: true, SilenceErrors: true}
+	run := &cobra.Command{
+		Use:  "run <file>",
+		Args: cobra.ExactArgs(1),
+		RunE: func(*cobra.Command, []string) error { return nil },
+	}
+	run.Flags().Int("parallel", 1, "")
+	run.Flags().String("output", "", "")
+	run.Flags().String("session-dir", "", "")
+	run.MarkFlagsMutuallyExclusive("output", "session-dir")
+	live := &cobra.Command{
+		Use:  "live",
+		RunE: func(*cobra.Command, []string) error { return nil },
+	}
+	live.Flags().String("duration", "", "")
+	_ = live.MarkFlagRequired("duration")
+	root.AddCommand(run, live)
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+	WrapUsageErrors(root)
+	return root
+}
+
+func TestWrapUsageErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantMsg string
+	}{
+		{"unknown flag", []string{"run", "a.ogg", "--nope"}, "unknown flag: --nope"},
+		{"unknown shorthand", []string{"run", "a.ogg", "-z"}, "unknown shorthand flag"},
+		{"invalid flag value", []string{"run", "a.ogg", "--parallel", "many"}, "invalid argument"},
+		{"flag without value", []string{"run", "a.ogg", "--output"}, "flag needs an argument"},
+		{"wrong number of arguments", []string{"run"}, "accepts 1 arg(s)"},
+		{"conflicting flags", []string{"run", "a.ogg", "--output", "a.md", "--session-dir", "s"}, "if any flags in the group"},
+		{"missing required flag", []string{"live"}, `required flag(s) "duration" not set`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			root := usageTestRoot()
+			root.SetArgs(tt.args)
+			err := root.Execute()
+			if !errors.Is(err, ErrUsage) {
+				t.Fatalf("Execute() error = %v, want ErrUsage", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("Execute() error = %q, want the Cobra message %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestWrapUsageErrors_NotUsage(t *testing.T) {
+	t.Parallel()
+
+	// Valid command lines run; unknown commands are not usage errors.
+	root := usageTestRoot()
+	root.SetArgs([]string{"run", "a.ogg", "--parallel", "2"})
+	if err := root.Execute(); err != nil {
+		t.Errorf("Execute() unexpected error: %v", err)
+	}
+	root = usageTestRoot()
+	root.SetArgs([]string{"nope"})
+	if err := root.Execute(); err == nil || errors.Is(err, ErrUsage) {
+		t.Errorf("Execute() error = %v, want an error not matching ErrUsage", err)
+	}
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/progress"
+)
+
+// Messages starting the extraction progress line.
+const (
+	extractingMessage    = "Extracting audio from video..."
+	joiningMessage       = "Joining recording segments..."
+	denoisingMessage     = "Reducing noise..."
+	normalizingMessage   = "Normalizing loudness..."
+	preprocessingMessage = "Reducing noise and normalizing loudness..."
+)
+
+// Help of the --denoise and --normalize flags of the transcribe and live commands.
+const (
+	denoiseFlagHelp   = "Reduce background noise (hum, hiss, fans) before transcription"
+	normalizeFlagHelp = "Normalize loudness before transcription, raising faint speakers"
+)
+
+// extractVideoAudio extracts the audio track of the video at inputPath to a
+// temporary file, chunked instead of the video: video streams would inflate
+// chunks and can fail to encode into them. inputPath may also be the list of a
+// segmented recording, whose files are joined into one. cleanup removes the file.
+func extractVideoAudio(ctx context.Context, env *Env, ffmpegPath, inputPath string) (audioPath string, cleanup func(), err error) {
+	extractor, err := env.AudioExtractorFactory.NewAudioExtractor(ffmpegPath)
+	if err != nil {
+		return "", nil, err
+	}
+	message := extractingMessage
+	if audio.IsSegmentList(inputPath) {
+		message = joiningMessage
+	}
+	return convertAudio(ctx, env, "transcript-video-*", message, func(audioPath string, onProgress func(done, total time.Duration)) error {
+		return extractor.ExtractAudio(ctx, inputPath, audioPath, onProgress)
+	})
+}
+
+// preprocessAudio denoises and normalizes the audio of inputPath, as p selects,
+// to a temporary file chunked instead of the input. Like extractVideoAudio,
+// inputPath may be a video or a segment list. cleanup removes the file.
+func preprocessAudio(ctx context.Context, env *Env, ffmpegPath, inputPath string, p audio.Preprocessing) (audioPath string, cleanup func(), err error) {
+	preprocessor, err := env.PreprocessorFactory.NewPreprocessor(ffmpegPath)
+	if err != nil {
+		return "", nil, err
+	}
+	message := preprocessingMessage
+	switch {
+	case !p.Normalize:
+		message = denoisingMessage
+	case !p.Denoise:
+		message = normalizingMessage
+	}
+	return convertAudio(ctx, env, "transcript-preprocess-*", message, func(audioPath string, onProgress func(done, total time.Duration)) error {
+		return preprocessor.Preprocess(ctx, inputPath, audioPath, p, onProgress)
+	})
+}
+
+// convertAudio runs convert to write audio to a file in a temporary directory
+// named after pattern, drawing its progress after message on stderr.
+// cleanup removes the directory.
+func convertAudio(ctx context.Context, env *Env, pattern, message string, convert func(audioPath string, onProgress func(done, total time.Duration)) error) (audioPath string, cleanup func(), err error) {
+	tempDir, err := os.MkdirTemp("", pattern)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot create temp directory: %w", err)
+	}
+	cleanup = func() {
+		if err := os.RemoveAll(tempDir); err != nil {
+			warnf(env.Stderr, warnFileNotSaved, "failed to remove converted audio: %v", err)
+		}
+	}
+	audioPath = filepath.Join(tempDir, "audio.ogg")
+
+	progress.PhaseChange(ctx, progress.PhaseExtracting)
+	fmt.Fprint(env.Stderr, message)
+	draw := isTerminal(env.Stderr)
+	last := -1
+	err = convert(audioPath, func(done, total time.Duration) {
+		progress.Extraction(ctx, done, total)
+		if !draw || total <= 0 {
+			return
+		}
+		if pct := min(int(100*done/total), 100); pct != last {
+			last = pct
+			fmt.Fprintf(env.Stderr, "\r%s %d%%", message, pct)
+		}
+	})
+	if err != nil {
+		fmt.Fprintln(env.Stderr)
+		cleanup()
+		return "", nil, err
+	}
+	fmt.Fprintf(env.Stderr, "\r%s done\n", message)
+	return audioPath, cleanup, nil
+}
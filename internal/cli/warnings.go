@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Warning codes. Like error codes, they are stable: scripts pass them to
+// --suppress-warn, and 'transcript explain' documents them.
+const (
+	warnExtension           = "TR-W001"
+	warnConfigLoad          = "TR-W002"
+	warnSettingMissing      = "TR-W003"
+	warnInvalidSetting      = "TR-W004"
+	warnIntroOutro          = "TR-W005"
+	warnTagVocabulary       = "TR-W006"
+	warnUnfinishedSession   = "TR-W007"
+	warnCheckpoint          = "TR-W008"
+	warnChunkFailed         = "TR-W009"
+	warnLocalFallback       = "TR-W010"
+	warnPromptSize          = "TR-W011"
+	warnSilence             = "TR-W012"
+	warnFileNotSaved        = "TR-W013"
+	warnBotDelivery         = "TR-W014"
+	warnSessionSkipped      = "TR-W015"
+	warnNotifyFailed        = "TR-W016"
+	warnDesktopNotifyFailed = "TR-W017"
+	warnKeyringOverridden   = "TR-W018"
+	warnHighlights          = "TR-W019"
+	warnNoConfidence        = "TR-W020"
+	warnRestructureFallback = "TR-W021"
+	warnUnusedTemplateVar   = "TR-W022"
+	warnActionsFailed       = "TR-W023"
+)
+
+// warningCatalog documents the warnings, in the format of errorCatalog.
+// Warnings never stop a command, unless --warn-as-error is set.
+var warningCatalog = []ErrorInfo{
+	{
+		Code:        warnExtension,
+		Summary:     "Output extension does not match the format",
+		Explanation: "The output file is written in the output format (--format, Markdown by default, OGG for recordings) whatever its extension.",
+		Remediation: []string{"Name the output after its format (e.g. notes.md), or choose the format with --format"},
+	},
+	{
+		Code:        warnConfigLoad,
+		Summary:     "Config file could not be loaded",
+		Explanation: "The config file exists but could not be read, so the defaults are used for every setting.",
+		Remediation: []string{"Check the file shown by 'transcript config path' is readable and valid"},
+	},
+	{
+		Code:        warnSettingMissing,
+		Summary:     "Option ignored: setting not configured",
+		Explanation: "--intro-outro needs intro-library, and --tag needs tags-dir, to know where earlier sessions are kept. Without it, the option is ignored.",
+		Remediation: []string{"Set the directory: transcript config set tags-dir ~/notes/tags"},
+	},
+	{
+		Code:        warnInvalidSetting,
+		Summary:     "Invalid setting ignored",
+		Explanation: "A setting of the config file (TLS certificates, default-command, user templates) is invalid, so it is ignored and the default is used.",
+		Remediation: []string{"Fix the setting named in the warning: transcript config set <key> <value>"},
+	},
+	{
+		Code:        warnIntroOutro,
+		Summary:     "Intro and outro detection failed",
+		Explanation: "The intro library could not be read or updated, so the whole input is transcribed, intro and outro included.",
+		Remediation: []string{"Check the intro-library directory exists and is writable"},
+	},
+	{
+		Code:        warnTagVocabulary,
+		Summary:     "Tag vocabulary could not be read or recorded",
+		Explanation: "The names recorded under the --tag could not be read, so the prompt does not include them, or the names of this session could not be recorded.",
+		Remediation: []string{"Check the tags-dir directory exists and is writable"},
+	},
+	{
+		Code:        warnUnfinishedSession,
+		Summary:     "Unfinished session for this input",
+		Explanation: "An earlier run on the same input did not finish. A new session is started; the earlier one is kept.",
+		Remediation: []string{"Run again with --resume to continue the earlier session instead"},
+	},
+	{
+		Code:        warnCheckpoint,
+		Summary:     "Checkpoint ignored",
+		Explanation: "--resume found a checkpoint it cannot use: unreadable, or made for another input file or other options. Every chunk is transcribed again.",
+		Remediation: []string{"Resume with the same input and options as the interrupted run"},
+	},
+	{
+		Code:        warnChunkFailed,
+		Summary:     "Chunk failed, marked in the output",
+		Explanation: "With --allow-partial, a chunk that kept failing is replaced by a [transcription failed] marker.",
+		Remediation: []string{"Run again with --resume --force to transcribe the missing chunks"},
+	},
+	{
+		Code:        warnLocalFallback,
+		Summary:     "Chunk transcribed with the local fallback",
+		Explanation: "A chunk was over the file size limit of the transcription API, so it was transcribed with the local backend (whisper-model or whisper-url). Its speakers are not labeled with --diarize.",
+		Remediation: []string{"Re-encode the input at a lower bitrate to keep every chunk on the API"},
+	},
+	{
+		Code:        warnPromptSize,
+		Summary:     "Large restructure prompt",
+		Explanation: "A restructure call sent more prompt tokens than prompt-token-warning, which costs more and can be truncated by the provider.",
+		Remediation: []string{
+			"Split long transcripts: transcript config set restructure-split speaker",
+			"Or raise the threshold: transcript config set prompt-token-warning 200000",
+		},
+	},
+	{
+		Code:        warnSilence,
+		Summary:     "No sound detected",
+		Explanation: "The input stayed silent at the start of the recording: the microphone may be muted, or the wrong device selected.",
+		Remediation: []string{"Check the input levels with: transcript devices --test"},
+	},
+	{
+		Code:        warnFileNotSaved,
+		Summary:     "File could not be saved or cleaned up",
+		Explanation: "A file besides the output (checkpoint, session metadata, cost report, recorded audio, temporary files) could not be written or removed. The output itself is not affected.",
+		Remediation: []string{"Check the disk is not full and the directories are writable"},
+	},
+	{
+		Code:        warnBotDelivery,
+		Summary:     "Telegram request failed",
+		Explanation: "The bot could not reach Telegram or reply to a chat. It retries and keeps running.",
+		Remediation: []string{"Check the network connection of the machine running the bot"},
+	},
+	{
+		Code:        warnSessionSkipped,
+		Summary:     "Session left out of the digest",
+		Explanation: "A finished session matching the digest filters has no readable output (transcript or raw.md), so it is not in the digest.",
+		Remediation: []string{"Check the session directory named in the warning was not partly deleted"},
+	},
+	{
+		Code:        warnNotifyFailed,
+		Summary:     "Webhook notification not sent",
+		Explanation: "The run finished, but the URL of --notify-webhook (or notify-webhook in the config) could not be reached or rejected the notification.",
+		Remediation: []string{"Check the webhook URL, e.g. with curl -X POST", "Check the network connection of the machine running the transcription"},
+	},
+	{
+		Code:        warnDesktopNotifyFailed,
+		Summary:     "Desktop notification not shown",
+		Explanation: "The run finished, but --notify could not show a desktop notification: the notification tool (notify-send on Linux, osascript on macOS, PowerShell on Windows) is missing or failed, e.g. without a desktop session over SSH.",
+		Remediation: []string{"On Linux, install notify-send (libnotify-bin on Debian and Ubuntu, libnotify on Fedora and Arch)", "On machines without a desktop, use --notify-webhook instead"},
+	},
+	{
+		Code:        warnKeyringOverridden,
+		Summary:     "Stored key overridden by the environment",
+		Explanation: "config set-key stored the key in the keyring, but the environment variable of the provider is set, and the environment takes precedence: the stored key is only used once the variable is unset.",
+		Remediation: []string{"Unset the variable, and remove it from the .env files, to use the stored key"},
+	},
+	{
+		Code:        warnHighlights,
+		Summary:     "Highlight markers ignored",
+		Explanation: "The highlight markers saved by record next to the input (<input>.highlights) could not be read or have a line other than \"⭐ HH:MM:SS\", so the transcript has no markers.",
+		Remediation: []string{"Fix or remove the line named in the warning, one \"⭐ HH:MM:SS\" marker per line"},
+	},
+	{
+		Code:        warnNoConfidence,
+		Summary:     "No confidence scores",
+		Explanation: "--unclear lists the passages transcribed with low confidence, but the transcriber gave none: the OpenAI diarization model and gRPC servers do not, so no passage is listed.",
+		Remediation: []string{"Transcribe without --diarize on OpenAI, or with --transcriber groq, local, deepgram or assemblyai"},
+	},
+	{
+		Code:        warnRestructureFallback,
+		Summary:     "Restructured with the fallback provider",
+		Explanation: "The restructure provider failed with an exhausted quota or a rejected key, so the transcript was restructured again with --fallback-provider and its default model. The transcription is not redone.",
+		Remediation: []string{"Top up the account of the provider (see TR-0502), or set a valid key (see TR-0504)", "Check the keys with: transcript config doctor"},
+	},
+	{
+		Code:        warnUnusedTemplateVar,
+		Summary:     "Template variable not used",
+		Explanation: "A --var names a variable that no placeholder of the template uses, like {{attendees}}, so its value is not in the prompt. The name may be misspelled.",
+		Remediation: []string{"Check the placeholders of the template file, and the name given to --var"},
+	},
+	{
+		Code:        warnActionsFailed,
+		Summary:     "Action items not extracted",
+		Explanation: "--actions extracts the action items after the template, with the same provider. The call failed, or the model answered twice with something else than the JSON of the action items, so no task list was written. The output is complete.",
+		Remediation: []string{
+			"Run 'transcript structure --actions' on the raw transcript, kept with --keep-raw-transcript, to try again",
+			"Use a provider constraining its answer to a JSON schema (openai, ollama)",
+		},
+	},
+}
+
+// warningPolicy is how warnings are reported (--suppress-warn, --warn-as-error).
+// It is safe for concurrent use.
+type warningPolicy struct {
+	mu         sync.Mutex
+	suppressed map[string]bool
+	asError    bool
+	first      string // First warning reported with asError
+}
+
+// warnings is the policy of the running command (see ConfigureWarnings).
+var warnings = &warningPolicy{}
+
+// ConfigureWarnings sets how the warnings of the command are reported (the
+// global --suppress-warn and --warn-as-error flags): warnings whose code is in
+// suppress are not printed, and with asError, the first warning printed makes
+// the command fail once it finished (see WarningsError).
+// Returns ErrUnknownWarning for a code missing from the warning catalog.
+func ConfigureWarnings(suppress []string, asError bool) error {
+	suppressed := make(map[string]bool, len(suppress))
+	for _, code := range suppress {
+		info, ok := lookupWarning(strings.TrimSpace(code))
+		if !ok {
+			return fmt.Errorf("%w: %q (see 'transcript explain')", ErrUnknownWarning, code)
+		}
+		suppressed[info.Code] = true
+	}
+
+	warnings.mu.Lock()
+	defer warnings.mu.Unlock()
+	warnings.suppressed, warnings.asError, warnings.first = suppressed, asError, ""
+	return nil
+}
+
+// WarningsError returns an error wrapping ErrWarningAsError with the first
+// warning printed under --warn-as-error, or nil.
+func WarningsError() error {
+	warnings.mu.Lock()
+	defer warnings.mu.Unlock()
+	if warnings.first == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrWarningAsError, warnings.first)
+}
+
+// warnf writes a warning with its code to w, unless the code is suppressed:
+// "Warning: <message> (code TR-W001)".
+func warnf(w io.Writer, code, format string, args ...any) {
+	message := fmt.Sprintf(format, args...) + " (code " + code + ")"
+
+	warnings.mu.Lock()
+	if warnings.suppressed[code] {
+		warnings.mu.Unlock()
+		return
+	}
+	if warnings.asError && warnings.first == "" {
+		warnings.first = message
+	}
+	warnings.mu.Unlock()
+
+	fmt.Fprintf(w, "Warning: %s\n", message)
+}
+
+// lookupWarning returns the catalog entry of the warning code,
+// case-insensitively.
+func lookupWarning(code string) (ErrorInfo, bool) {
+	for _, info := range warningCatalog {
+		if strings.EqualFold(info.Code, code) {
+			return info, true
+		}
+	}
+	return ErrorInfo{}, false
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// resetWarnings restores the default warning policy once t finished.
+// Tests changing the policy cannot use t.Parallel(): it is global.
+func resetWarnings(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		if err := ConfigureWarnings(nil, false); err != nil {
+			t.Errorf("failed to reset warnings: %v", err)
+		}
+	})
+}
+
+func TestWarningCatalog_Entries(t *testing.T) {
+	t.Parallel()
+
+	codePattern := regexp.MustCompile(`^TR-W\d{3}$`)
+	seen := make(map[string]bool)
+	for _, info := range WarningCatalog {
+		if !codePattern.MatchString(info.Code) {
+			t.Errorf("code %q does not match %s", info.Code, codePattern)
+		}
+		if seen[info.Code] {
+			t.Errorf("code %s listed twice", info.Code)
+		}
+		seen[info.Code] = true
+		if info.Summary == "" || info.Explanation == "" || len(info.Remediation) == 0 {
+			t.Errorf("%s: summary, explanation and remediation are required", info.Code)
+		}
+		if len(CatalogErrors(info)) != 0 {
+			t.Errorf("%s: warnings match no error", info.Code)
+		}
+	}
+}
+
+func TestWarnf(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	Warnf(&buf, "TR-W001", "output is %s regardless of %s extension", "Markdown", ".txt")
+
+	want := "Warning: output is Markdown regardless of .txt extension (code TR-W001)\n"
+	if buf.String() != want {
+		t.Errorf("Warnf() wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestConfigureWarnings_Suppress(t *testing.T) {
+	// Cannot use t.Parallel(): the warning policy is global
+	resetWarnings(t)
+
+	if err := ConfigureWarnings([]string{"tr-w001", " TR-W002 "}, false); err != nil {
+		t.Fatalf("ConfigureWarnings() unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	Warnf(&buf, "TR-W001", "suppressed")
+	Warnf(&buf, "TR-W002", "suppressed")
+	Warnf(&buf, "TR-W003", "printed")
+	if got := buf.String(); strings.Contains(got, "suppressed") || !strings.Contains(got, "printed") {
+		t.Errorf("warnings = %q, want only TR-W003", got)
+	}
+}
+
+func TestConfigureWarnings_UnknownCode(t *testing.T) {
+	// Cannot use t.Parallel(): the warning policy is global
+	resetWarnings(t)
+
+	for _, code := range []string{"TR-W999", "TR-0301", ""} {
+		if err := ConfigureWarnings([]string{code}, false); !errors.Is(err, ErrUnknownWarning) {
+			t.Errorf("ConfigureWarnings(%q) error = %v, want ErrUnknownWarning", code, err)
+		}
+	}
+}
+
+func TestWarningsError(t *testing.T) {
+	// Cannot use t.Parallel(): the warning policy is global
+	resetWarnings(t)
+
+	t.Run("nil without warn-as-error", func(t *testing.T) {
+		if err := ConfigureWarnings(nil, false); err != nil {
+			t.Fatal(err)
+		}
+		Warnf(&bytes.Buffer{}, "TR-W001", "printed")
+		if err := WarningsError(); err != nil {
+			t.Errorf("WarningsError() = %v, want nil", err)
+		}
+	})
+
+	t.Run("first warning printed", func(t *testing.T) {
+		if err := ConfigureWarnings(nil, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := WarningsError(); err != nil {
+			t.Fatalf("WarningsError() = %v before any warning, want nil", err)
+		}
+		var buf bytes.Buffer
+		Warnf(&buf, "TR-W001", "first")
+		Warnf(&buf, "TR-W002", "second")
+
+		err := WarningsError()
+		if !errors.Is(err, ErrWarningAsError) {
+			t.Fatalf("WarningsError() = %v, want ErrWarningAsError", err)
+		}
+		if !strings.Contains(err.Error(), "first (code TR-W001)") {
+			t.Errorf("WarningsError() = %v, want the first warning", err)
+		}
+		if strings.Count(buf.String(), "Warning:") != 2 {
+			t.Errorf("warnings = %q, want both still printed", buf.String())
+		}
+	})
+
+	t.Run("suppressed warnings ignored", func(t *testing.T) {
+		if err := ConfigureWarnings([]string{"TR-W001"}, true); err != nil {
+			t.Fatal(err)
+		}
+		Warnf(&bytes.Buffer{}, "TR-W001", "suppressed")
+		if err := WarningsError(); err != nil {
+			t.Errorf("WarningsError() = %v, want nil for a suppressed warning", err)
+		}
+	})
+}
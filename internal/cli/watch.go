@@ -0,0 +1,317 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/appdirs"
+	"github.com/alnah/go-transcript/internal/config"
+	"github.com/alnah/go-transcript/internal/watch"
+)
+
+// watchOptions configures the watch command.
+type watchOptions struct {
+	dir    string            // Watched directory
+	output string            // Output directory (--output), empty for output-dir or next to each input
+	file   transcribeOptions // Pipeline options of every file (without input and output)
+	jobs   int               // Files transcribed concurrently
+	settle time.Duration     // How long a file must stay unchanged before it is transcribed
+}
+
+// WatchCmd creates the watch command.
+// Transcribes the audio files added to a directory as they arrive.
+func WatchCmd(env *Env) *cobra.Command {
+	var (
+		output     string
+		tmpl       string
+		diarize    bool
+		parallel   string
+		language   string
+		outputLang string
+		provider   string
+		model      string
+		backend    string
+		jobs       int
+		settle     time.Duration
+		webhook    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "watch <directory>",
+		Short: "Transcribe audio files as they are added to a directory",
+		Long: `Watch a directory and transcribe each audio file added to it.
+
+Files already in the directory are transcribed first, then each new file once
+it is complete: a file still being copied or recorded is transcribed after it
+stopped changing for --settle. Hidden files and subdirectories are ignored.
+
+Every file gets the same options (template, language, provider...). The output
+is written next to the audio file, in output-dir when configured, or in the
+--output directory. A file whose output already exists is skipped.
+
+Transcribed files are remembered across runs, so restarting the watch does not
+transcribe them again, unless they were modified since. Files are transcribed
+--jobs at a time. Press Ctrl+C to stop: files being transcribed are canceled,
+and transcribed again on the next start.`,
+		Example: `  transcript watch ~/Recordings
+  transcript watch ~/Recordings -t meeting -l fr -o ~/Notes
+  transcript watch /mnt/recorder --settle 30s --jobs 2`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, auto, err := parseParallelFlag(cmd, parallel)
+			if err != nil {
+				return err
+			}
+			opts, err := parseTranscribeOptions("", "", tmpl, diarize, n, language, outputLang, provider, userTemplatesDir(env))
+			if err != nil {
+				return err
+			}
+			// No --var here: a template with placeholders cannot be filled.
+			if opts.template, err = fillTemplate(env.Stderr, opts.template, nil, ""); err != nil {
+				return err
+			}
+			opts.auto = auto
+			opts.model = model
+			if opts.notifyWebhook, err = parseNotifyWebhook(webhook); err != nil {
+				return err
+			}
+			if backend != "" {
+				if opts.backend, err = ParseBackend(backend); err != nil {
+					return err
+				}
+			}
+			return runWatch(cmd, env, watchOptions{dir: args[0], output: output, file: opts, jobs: jobs, settle: settle})
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output directory (default: output-dir, or next to each audio file)")
+	cmd.Flags().StringVarP(&tmpl, "template", "t", "", "Restructure template: brainstorm, meeting, lecture, notes, podcast, chapters, digest, or a user template")
+	cmd.Flags().BoolVar(&diarize, "diarize", false, "Enable speaker identification")
+	cmd.Flags().StringVarP(&parallel, "parallel", "p", defaultParallel, "Max concurrent API requests per file (1-10, or auto; default: sized from the chunks)")
+	cmd.Flags().StringVarP(&language, "language", "l", "", "Audio language (ISO 639-1 code, e.g., en, fr, pt-BR)")
+	cmd.Flags().StringVarP(&outputLang, "translate", "T", "", "Translate output to language (ISO 639-1 code, requires --template)")
+	cmd.Flags().StringVar(&provider, "provider", ProviderDeepSeek, "LLM provider for restructuring: deepseek, openai, anthropic, ollama")
+	cmd.Flags().StringVar(&model, "restructure-model", "", "Model of the restructure provider (default: config or provider default)")
+	cmd.Flags().StringVar(&backend, "transcriber", "", transcriberFlagHelp)
+	cmd.Flags().StringVar(&backend, "stt-provider", "", sttProviderFlagHelp)
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", 1, "Max files transcribed concurrently")
+	cmd.Flags().DurationVar(&settle, "settle", watch.DefaultSettle, "How long a new file must stay unchanged before it is transcribed")
+	cmd.Flags().StringVar(&webhook, "notify-webhook", "", "POST a JSON notification to this URL each time a file is transcribed (default: config notify-webhook)")
+
+	return cmd
+}
+
+// runWatch transcribes the audio files of opts.dir as they are added, until
+// the command context is canceled or the watcher stops.
+func runWatch(cmd *cobra.Command, env *Env, opts watchOptions) error {
+	ctx := cmd.Context()
+
+	// === VALIDATION (fail-fast) ===
+
+	dir, err := filepath.Abs(config.ExpandPath(opts.dir))
+	if err != nil {
+		return fmt.Errorf("cannot resolve directory: %w", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrFileNotFound, opts.dir)
+		}
+		return fmt.Errorf("cannot access directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory (use transcribe for a single file)", opts.dir)
+	}
+
+	cfg, err := env.ConfigLoader.Load()
+	if err != nil {
+		warnf(env.Stderr, warnConfigLoad, "failed to load config: %v", err)
+	}
+	opts.file.backend, err = resolveBackend(opts.file.backend, cfg)
+	if err != nil {
+		return err
+	}
+	opts.file.tag, err = resolveTag(opts.file.tag, cfg)
+	if err != nil {
+		return err
+	}
+	if err := validateTranscribeRequirements(env, opts.file, cfg); err != nil {
+		return err
+	}
+
+	outputDir := cfg.OutputDir
+	if opts.output != "" {
+		outputDir = config.ExpandPath(opts.output)
+		if err := config.EnsureOutputDir(outputDir); err != nil {
+			return fmt.Errorf("invalid output directory: %w", err)
+		}
+	}
+
+	ledgerPath, err := watchLedgerPath(env, dir)
+	if err != nil {
+		return err
+	}
+	ledger, err := watch.OpenLedger(ledgerPath, dir)
+	if err != nil {
+		return err
+	}
+
+	// === SETUP ===
+
+	ffmpegPath, err := resolveFFmpegOnce(ctx, env)
+	if err != nil {
+		return err
+	}
+
+	w := &folderWatch{
+		cmd:       cmd,
+		env:       env,
+		opts:      opts,
+		outputDir: outputDir,
+		ffmpeg:    ffmpegPath,
+		ledger:    ledger,
+		sem:       make(chan struct{}, max(1, opts.jobs)),
+		running:   make(map[string]bool),
+	}
+
+	// === WATCH ===
+
+	fmt.Fprintf(env.Stderr, "Watching %s for audio files (Ctrl+C to stop)\n", dir)
+	err = env.WatcherFactory.NewWatcher(opts.settle).Watch(ctx, dir, isSupportedFile, w.submit)
+	w.wg.Wait()
+	fmt.Fprintf(env.Stderr, "Watch stopped: %d transcribed, %d failed\n", w.done, w.failed)
+	return err
+}
+
+// isSupportedFile reports whether path has a supported audio or video format.
+func isSupportedFile(path string) bool {
+	return supportedFormats[strings.ToLower(filepath.Ext(path))]
+}
+
+// watchLedgerPath returns the ledger file of the watched directory dir,
+// named after its hash in the state directory.
+func watchLedgerPath(env *Env, dir string) (string, error) {
+	dirs, err := appdirs.Resolve(runtime.GOOS, env.Getenv, os.UserHomeDir)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(dir))
+	return filepath.Join(dirs.State, "watch", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// folderWatch transcribes the files reported by a watcher.
+type folderWatch struct {
+	cmd       *cobra.Command
+	env       *Env
+	opts      watchOptions
+	outputDir string // Empty: next to each input
+	ffmpeg    string
+	ledger    *watch.Ledger
+	sem       chan struct{} // Limits concurrent files to --jobs
+	wg        sync.WaitGroup
+
+	mu           sync.Mutex // Serializes writes to env.Stderr across jobs, guards the fields below.
+	running      map[string]bool
+	done, failed int
+}
+
+// submit starts transcribing the file at path in the background, unless it
+// was already transcribed as it is, or is being transcribed.
+func (w *folderWatch) submit(path string) {
+	info, err := os.Stat(path)
+	if err != nil || w.ledger.Processed(path, info) {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.running[path] {
+		return
+	}
+	w.running[path] = true
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.transcribe(path, info)
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.running, path)
+	}()
+}
+
+// transcribe runs the pipeline on the file at path, described by info, and
+// records it in the ledger once transcribed.
+func (w *folderWatch) transcribe(path string, info os.FileInfo) {
+	ctx := w.cmd.Context()
+	select {
+	case w.sem <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+	defer func() { <-w.sem }()
+	if ctx.Err() != nil {
+		return
+	}
+
+	output := w.outputPath(path)
+	var err error
+	start := w.env.Now()
+	if _, statErr := os.Stat(output); statErr == nil {
+		err = fmt.Errorf("output file already exists: %s: %w", output, ErrOutputExists)
+	} else {
+		stderr := &linePrefixWriter{mu: &w.mu, w: w.env.Stderr, prefix: "[" + filepath.Base(path) + "] "}
+		fileEnv := *w.env
+		fileEnv.Stderr = stderr
+		fileEnv.FFmpegResolver = resolvedFFmpeg(w.ffmpeg)
+
+		fileOpts := w.opts.file
+		fileOpts.inputPath = path
+		fileOpts.output = output
+		err = runTranscribe(w.cmd, &fileEnv, fileOpts)
+		stderr.Flush()
+	}
+	if ctx.Err() != nil {
+		return // Interrupted: transcribed again on the next start
+	}
+	if err == nil {
+		if recordErr := w.ledger.Record(path, info, output, w.env.Now()); recordErr != nil {
+			w.mu.Lock()
+			warnf(w.env.Stderr, warnFileNotSaved, "failed to save watch ledger: %v", recordErr)
+			w.mu.Unlock()
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.failed++
+		fmt.Fprintf(w.env.Stderr, "Failed: %s: %v\n", path, err)
+		return
+	}
+	w.done++
+	fmt.Fprintf(w.env.Stderr, "Done: %s -> %s (%s)\n", path, output, w.env.Now().Sub(start).Round(time.Second))
+}
+
+// outputPath returns the absolute output path of the file at path, so that
+// runTranscribe does not join it with output-dir again.
+func (w *folderWatch) outputPath(path string) string {
+	dir := w.outputDir
+	if dir == "" {
+		dir = filepath.Dir(path)
+	}
+	output := filepath.Join(dir, deriveOutputPath(filepath.Base(path), w.opts.file.format))
+	if abs, err := filepath.Abs(output); err == nil {
+		return abs
+	}
+	return output
+}
@@ -0,0 +1,286 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// watchTestEnv returns an Env whose watcher reports files and transcriber
+// runs transcribeFunc. The state directory, holding the ledger, is a
+// temporary directory shared by every run of the test.
+func watchTestEnv(t *testing.T, stderr *syncBuffer, files []string, transcribeFunc func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error)) *Env {
+	t.Helper()
+
+	env := checkpointTestEnv(t, stderr, transcribeFunc)
+	state := t.TempDir()
+	env.Getenv = func(key string) string {
+		if key == "XDG_STATE_HOME" {
+			return state
+		}
+		return defaultTestEnv(key)
+	}
+	env.WatcherFactory = &mockWatcherFactory{mockWatcher: &mockWatcher{Files: files}}
+	return env
+}
+
+// runWatchCmd runs the watch command on dir until the watcher stops.
+func runWatchCmd(t *testing.T, env *Env, dir, output string) error {
+	t.Helper()
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	opts := watchOptions{dir: dir, output: output, file: mustParseTranscribeOptions(t, "", "", "", false, 5, "", "", "deepseek"), jobs: 2}
+	return runWatch(cmd, env, opts)
+}
+
+// countingTranscription returns a transcribe function counting its calls.
+func countingTranscription(calls *atomic.Int32) func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+	return func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		calls.Add(1)
+		return "text of " + filepath.Base(audioPath), nil
+	}
+}
+
+func TestRunWatch_TranscribesNewFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	talk := createTestAudioFileIn(t, dir, "talk.ogg")
+	memo := createTestAudioFileIn(t, dir, "memo.mp3")
+	notes := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("not audio"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	stderr := &syncBuffer{}
+	env := watchTestEnv(t, stderr, []string{talk, memo, notes}, countingTranscription(&calls))
+	if err := runWatchCmd(t, env, dir, ""); err != nil {
+		t.Fatalf("runWatch() unexpected error: %v", err)
+	}
+
+	for _, output := range []string{"talk.md", "memo.md"} {
+		if _, err := os.Stat(filepath.Join(dir, output)); err != nil {
+			t.Errorf("output %s not written next to its input: %v", output, err)
+		}
+	}
+	if got := calls.Load(); got != 4 {
+		t.Errorf("Transcribe() called %d times, want 4 (2 chunks of 2 files)", got)
+	}
+	if !strings.Contains(stderr.String(), "Watch stopped: 2 transcribed, 0 failed") {
+		t.Errorf("stderr = %q, want the summary of 2 files", stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "[talk.ogg] ") {
+		t.Errorf("stderr = %q, want progress prefixed with the file name", stderr.String())
+	}
+
+	// Restarted: the ledger remembers the transcribed files
+	stderr.Reset()
+	if err := runWatchCmd(t, env, dir, ""); err != nil {
+		t.Fatalf("runWatch() unexpected error: %v", err)
+	}
+	if got := calls.Load(); got != 4 {
+		t.Errorf("Transcribe() called %d times after restart, want no new call", got)
+	}
+	if !strings.Contains(stderr.String(), "Watch stopped: 0 transcribed, 0 failed") {
+		t.Errorf("stderr = %q, want no file transcribed again", stderr.String())
+	}
+
+	// Modified: transcribed again
+	if err := os.WriteFile(talk, []byte("new recording"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "talk.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := runWatchCmd(t, env, dir, ""); err != nil {
+		t.Fatalf("runWatch() unexpected error: %v", err)
+	}
+	if got := calls.Load(); got != 6 {
+		t.Errorf("Transcribe() called %d times, want the modified file transcribed again", got)
+	}
+}
+
+func TestRunWatch_OutputDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	talk := createTestAudioFileIn(t, dir, "talk.ogg")
+	outputDir := filepath.Join(t.TempDir(), "notes")
+
+	var calls atomic.Int32
+	env := watchTestEnv(t, &syncBuffer{}, []string{talk}, countingTranscription(&calls))
+	if err := runWatchCmd(t, env, dir, outputDir); err != nil {
+		t.Fatalf("runWatch() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "talk.md")); err != nil {
+		t.Errorf("output not written to --output: %v", err)
+	}
+}
+
+func TestRunWatch_FailedFileRetried(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	talk := createTestAudioFileIn(t, dir, "talk.ogg")
+
+	var fail atomic.Bool
+	fail.Store(true)
+	stderr := &syncBuffer{}
+	env := watchTestEnv(t, stderr, []string{talk}, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		if fail.Load() {
+			return "", errors.New("network down")
+		}
+		return "text", nil
+	})
+	if err := runWatchCmd(t, env, dir, ""); err != nil {
+		t.Fatalf("runWatch() unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "Failed: "+talk) || !strings.Contains(stderr.String(), "0 transcribed, 1 failed") {
+		t.Errorf("stderr = %q, want the failure reported", stderr.String())
+	}
+
+	// The failed file is not in the ledger: transcribed on the next start
+	fail.Store(false)
+	stderr.Reset()
+	if err := runWatchCmd(t, env, dir, ""); err != nil {
+		t.Fatalf("runWatch() unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "1 transcribed, 0 failed") {
+		t.Errorf("stderr = %q, want the failed file transcribed on restart", stderr.String())
+	}
+}
+
+func TestRunWatch_OutputExists(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	talk := createTestAudioFileIn(t, dir, "talk.ogg")
+	if err := os.WriteFile(filepath.Join(dir, "talk.md"), []byte("earlier notes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	stderr := &syncBuffer{}
+	env := watchTestEnv(t, stderr, []string{talk}, countingTranscription(&calls))
+	if err := runWatchCmd(t, env, dir, ""); err != nil {
+		t.Fatalf("runWatch() unexpected error: %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Error("file transcribed although its output exists")
+	}
+	if !strings.Contains(stderr.String(), "output file already exists") {
+		t.Errorf("stderr = %q, want the existing output reported", stderr.String())
+	}
+}
+
+func TestRunWatch_Interrupted(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	talk := createTestAudioFileIn(t, dir, "talk.ogg")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := make(chan struct{})
+	var once atomic.Bool
+	stderr := &syncBuffer{}
+	env := watchTestEnv(t, stderr, nil, func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		if once.CompareAndSwap(false, true) {
+			close(started)
+		}
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	// Watches until interrupted, like Ctrl+C
+	env.WatcherFactory = &mockWatcherFactory{mockWatcher: &mockWatcher{
+		WatchFunc: func(ctx context.Context, dir string, match func(string) bool, ready func(string)) error {
+			ready(talk)
+			<-ctx.Done()
+			return nil
+		},
+	}}
+
+	go func() {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+		}
+		cancel()
+	}()
+	cmd := &cobra.Command{}
+	cmd.SetContext(ctx)
+	opts := watchOptions{dir: dir, file: mustParseTranscribeOptions(t, "", "", "", false, 5, "", "", "deepseek"), jobs: 1}
+	if err := runWatch(cmd, env, opts); err != nil {
+		t.Fatalf("runWatch() unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "Watch stopped: 0 transcribed, 0 failed") {
+		t.Errorf("stderr = %q, want the interrupted file neither transcribed nor failed", stderr.String())
+	}
+
+	ledgerPath, err := watchLedgerPath(env, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(ledgerPath); !os.IsNotExist(err) {
+		t.Errorf("ledger written for an interrupted file (stat error = %v)", err)
+	}
+}
+
+func TestRunWatch_Errors(t *testing.T) {
+	t.Parallel()
+
+	file := createTestAudioFile(t, "talk.ogg")
+
+	tests := []struct {
+		name    string
+		dir     string
+		getenv  func(string) string
+		wantErr error
+		wantMsg string
+	}{
+		{name: "missing directory", dir: filepath.Join(t.TempDir(), "missing"), wantErr: ErrFileNotFound},
+		{name: "file instead of directory", dir: file, wantMsg: "is not a directory"},
+		{name: "no API key", dir: t.TempDir(), getenv: staticEnv(nil), wantErr: ErrAPIKeyMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := watchTestEnv(t, &syncBuffer{}, nil, nil)
+			if tt.getenv != nil {
+				env.Getenv = tt.getenv
+			}
+			err := runWatchCmd(t, env, tt.dir, "")
+			if err == nil {
+				t.Fatal("runWatch() error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("runWatch() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantMsg != "" && !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("runWatch() error = %v, want containing %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+// createTestAudioFileIn creates a placeholder audio file named name in dir.
+func createTestAudioFileIn(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("audio"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
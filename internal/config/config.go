@@ -4,22 +4,107 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/appdirs"
 )
 
 // Config keys.
 const (
-	KeyOutputDir = "output-dir"
+	KeyOutputDir          = "output-dir"
+	KeyOutputName         = "output-name"
+	KeyFrontMatter        = "front-matter"
+	KeyObsidianVault      = "obsidian-vault"
+	KeyObsidianFolder     = "obsidian-folder"
+	KeyPromptTokenWarning = "prompt-token-warning"
+	KeyTranscriber        = "transcriber"
+	KeyWhisperModel       = "whisper-model"
+	KeyWhisperBin         = "whisper-bin"
+	KeyWhisperURL         = "whisper-url"
+	KeyTagsDir            = "tags-dir"
+	KeyIntroLibrary       = "intro-library"
+	KeyGlossary           = "glossary"
+	KeyCleanFillers       = "clean-fillers"
+	KeyRedactPatterns     = "redact-patterns"
+	KeyOllamaURL          = "ollama-url"
+	KeyOllamaModel        = "ollama-model"
+	KeyTemplatesDir       = "templates-dir"
+	KeyRestructureModel   = "restructure-model"
+	KeyContextWindows     = "context-windows"
+	KeyRestructureSplit   = "restructure-split"
+	KeyRestructureChunk   = "restructure-chunk-tokens"
+	KeyRestructureOverlap = "restructure-overlap"
+	KeyDefaultCommand     = "default-command"
+	KeyDefaultDevice      = "default-device"
+	KeyFFmpegPath         = "ffmpeg-path"
+	KeyCABundle           = "ca-bundle"
+	KeyClientCert         = "client-cert"
+	KeyClientKey          = "client-key"
+	KeyRateLimitRequests  = "rate-limit-requests"
+	KeyRateLimitAudio     = "rate-limit-audio"
+	KeyNotifyWebhook      = "notify-webhook"
+	KeyGRPCEndpoint       = "grpc-endpoint"
+	KeyGRPCPlaintext      = "grpc-plaintext"
+	KeyGRPCTimeout        = "grpc-timeout"
+	KeyAzureEndpoint      = "azure-endpoint"
+	KeyAzureTranscribe    = "azure-transcribe-deployment"
+	KeyAzureChat          = "azure-chat-deployment"
+	KeyAzureAPIVersion    = "azure-api-version"
 )
 
 // Environment variable fallbacks.
 const (
-	EnvOutputDir = "TRANSCRIPT_OUTPUT_DIR"
+	EnvOutputDir          = "TRANSCRIPT_OUTPUT_DIR"
+	EnvOutputName         = "TRANSCRIPT_OUTPUT_NAME"
+	EnvFrontMatter        = "TRANSCRIPT_FRONT_MATTER"
+	EnvObsidianVault      = "TRANSCRIPT_OBSIDIAN_VAULT"
+	EnvObsidianFolder     = "TRANSCRIPT_OBSIDIAN_FOLDER"
+	EnvPromptTokenWarning = "TRANSCRIPT_PROMPT_TOKEN_WARNING"
+	EnvTranscriber        = "TRANSCRIPT_TRANSCRIBER"
+	EnvWhisperModel       = "TRANSCRIPT_WHISPER_MODEL"
+	EnvWhisperBin         = "TRANSCRIPT_WHISPER_BIN"
+	EnvWhisperURL         = "TRANSCRIPT_WHISPER_URL"
+	EnvTagsDir            = "TRANSCRIPT_TAGS_DIR"
+	EnvIntroLibrary       = "TRANSCRIPT_INTRO_LIBRARY"
+	EnvGlossary           = "TRANSCRIPT_GLOSSARY"
+	EnvCleanFillers       = "TRANSCRIPT_CLEAN_FILLERS"
+	EnvRedactPatterns     = "TRANSCRIPT_REDACT_PATTERNS"
+	EnvOllamaURL          = "TRANSCRIPT_OLLAMA_URL"
+	EnvOllamaModel        = "TRANSCRIPT_OLLAMA_MODEL"
+	EnvTemplatesDir       = "TRANSCRIPT_TEMPLATES_DIR"
+	EnvRestructureModel   = "TRANSCRIPT_RESTRUCTURE_MODEL"
+	EnvContextWindows     = "TRANSCRIPT_CONTEXT_WINDOWS"
+	EnvRestructureSplit   = "TRANSCRIPT_RESTRUCTURE_SPLIT"
+	EnvRestructureChunk   = "TRANSCRIPT_RESTRUCTURE_CHUNK_TOKENS"
+	EnvRestructureOverlap = "TRANSCRIPT_RESTRUCTURE_OVERLAP"
+	EnvDefaultCommand     = "TRANSCRIPT_DEFAULT_COMMAND"
+	EnvDefaultDevice      = "TRANSCRIPT_DEFAULT_DEVICE"
+	EnvCABundle           = "TRANSCRIPT_CA_BUNDLE"
+	EnvClientCert         = "TRANSCRIPT_CLIENT_CERT"
+	EnvClientKey          = "TRANSCRIPT_CLIENT_KEY"
+	EnvRateLimitRequests  = "TRANSCRIPT_RATE_LIMIT_REQUESTS"
+	EnvRateLimitAudio     = "TRANSCRIPT_RATE_LIMIT_AUDIO"
+	EnvNotifyWebhook      = "TRANSCRIPT_NOTIFY_WEBHOOK"
+	EnvGRPCEndpoint       = "TRANSCRIPT_GRPC_ENDPOINT"
+	EnvGRPCPlaintext      = "TRANSCRIPT_GRPC_PLAINTEXT"
+	EnvGRPCTimeout        = "TRANSCRIPT_GRPC_TIMEOUT"
+	EnvAzureEndpoint      = "TRANSCRIPT_AZURE_ENDPOINT"
+	EnvAzureTranscribe    = "TRANSCRIPT_AZURE_TRANSCRIBE_DEPLOYMENT"
+	EnvAzureChat          = "TRANSCRIPT_AZURE_CHAT_DEPLOYMENT"
+	EnvAzureAPIVersion    = "TRANSCRIPT_AZURE_API_VERSION"
 )
 
+// EnvConfigFile overrides the path of the config file (see the global --config flag).
+const EnvConfigFile = "TRANSCRIPT_CONFIG"
+
 // File system permissions.
 const (
 	dirPerm  os.FileMode = 0750
@@ -36,34 +121,167 @@ var (
 	ErrNotWritable = errors.New("directory not writable")
 	// ErrNotDirectory is returned when a path is not a directory.
 	ErrNotDirectory = errors.New("path is not a directory")
+	// ErrInvalidValue is returned when a config value cannot be parsed.
+	ErrInvalidValue = errors.New("invalid config value")
 )
 
-// Config holds user configuration loaded from ~/.config/go-transcript/config.
+// Config holds user configuration loaded from the config file (see Path),
+// possibly overridden by a project file (see ApplyProject).
 type Config struct {
 	OutputDir string
+	// OutputName is the pattern of default output file names, like
+	// "{{date}}_{{template}}_{{input_stem}}.md". Empty means named after the
+	// input. Validated by the CLI.
+	OutputName string
+	// FrontMatter puts YAML front matter (date, duration, language...) at the
+	// top of Markdown outputs.
+	FrontMatter bool
+	// ObsidianVault is the Obsidian vault outputs are written into when no
+	// output is given (--obsidian-vault). Empty means none.
+	ObsidianVault string
+	// ObsidianFolder is the folder of ObsidianVault outputs are written to.
+	// Empty means the obsidian package default. Validated by the CLI.
+	ObsidianFolder string
+	// PromptTokenWarning is the prompt size (in tokens) above which a single
+	// restructure call triggers a warning. Zero means not configured.
+	PromptTokenWarning int
+	// Transcriber selects the transcription backend ("openai", "local" or "grpc").
+	// Empty means not configured. Validated by the CLI.
+	Transcriber string
+	// WhisperModel is the path to the whisper.cpp model for local transcription.
+	WhisperModel string
+	// WhisperBin is the path to the whisper.cpp binary. Empty means look it up in PATH.
+	WhisperBin string
+	// WhisperURL is the base URL of a local OpenAI-compatible whisper server.
+	// When set, local transcription uses it instead of the whisper.cpp binary.
+	WhisperURL string
+	// TagsDir holds the vocabulary recorded for each session tag (--tag).
+	// Defaults to the tags directory in the state directory.
+	TagsDir string
+	// IntroLibrary is the file remembering the intros and outros of earlier
+	// recordings (--intro-outro). Defaults to intros.json in the state directory.
+	IntroLibrary string
+	// Glossary is the file of terms put in the transcription and restructure
+	// prompts when --glossary is not given. Empty means none.
+	Glossary string
+	// CleanFillers are fillers removed by --clean in addition to the built-in
+	// ones, by language code ("*": every language). Nil means not configured.
+	CleanFillers map[string][]string
+	// RedactPatterns is the file of regular expressions also masked by
+	// --redact, one per line. Empty means none.
+	RedactPatterns string
+	// OllamaURL is the base URL of the Ollama server (--provider ollama).
+	// Empty means the restructure package default (localhost).
+	OllamaURL string
+	// OllamaModel is the Ollama model used for restructuring.
+	// Empty means the restructure package default.
+	OllamaModel string
+	// TemplatesDir holds the user templates selectable by name (--template).
+	// Defaults to the templates directory next to the config file.
+	TemplatesDir string
+	// RestructureModel is the model of the restructure provider.
+	// Empty means the provider default.
+	RestructureModel string
+	// ContextWindows adds or overrides model context windows (in tokens),
+	// which size the parts of long transcripts. Nil means not configured.
+	ContextWindows map[string]int
+	// RestructureSplit is how long transcripts are divided into parts:
+	// paragraphs, or speakers for diarized transcripts. Empty means paragraphs.
+	RestructureSplit string
+	// RestructureChunkTokens is the size (in tokens) of the parts of long
+	// transcripts. Zero means sized from the context window of the model.
+	RestructureChunkTokens int
+	// RestructureOverlap is the number of tokens of the previous part
+	// repeated at the start of each part. Zero means no overlap.
+	RestructureOverlap int
+	// DefaultCommand is the command run when the first argument is an input
+	// rather than a command (transcript file.ogg). Empty means transcribe.
+	DefaultCommand string
+	// DefaultDevice is the audio input of record and live when --device is
+	// not given, matched like --device. Empty means the system default.
+	DefaultDevice string
+	// FFmpegPath is the ffmpeg binary used instead of FFMPEG_PATH, PATH and
+	// the downloaded one. It has no environment variable: FFMPEG_PATH is read
+	// by the ffmpeg package after it.
+	FFmpegPath string
+	// CABundle is a PEM file of certificate authorities trusted in addition
+	// to the system ones, for networks intercepting TLS.
+	CABundle string
+	// ClientCert and ClientKey are the PEM client certificate and key
+	// presented to servers and proxies requiring mutual TLS.
+	ClientCert string
+	ClientKey  string
+	// RateLimitRequests and RateLimitAudio cap the transcription requests and
+	// the seconds of audio sent per minute, shared by parallel chunks.
+	// Zero means unlimited.
+	RateLimitRequests int
+	RateLimitAudio    int
+	// NotifyWebhook is the URL receiving a JSON notification when a
+	// transcription finishes (--notify-webhook). Empty means no notification.
+	NotifyWebhook string
+	// GRPCEndpoint is the host:port of the speech recognition server of the
+	// grpc transcriber. GRPCPlaintext connects to it without TLS, and
+	// GRPCTimeout is the deadline of each chunk (zero: the default).
+	GRPCEndpoint  string
+	GRPCPlaintext bool
+	GRPCTimeout   time.Duration
+	// AzureEndpoint is the endpoint of an Azure OpenAI resource
+	// (https://NAME.openai.azure.com). When set, OpenAI transcription and
+	// restructuring call the deployments AzureTranscribeDeployment and
+	// AzureChatDeployment of the resource instead of OpenAI. AzureAPIVersion
+	// is the api-version of the requests (empty: the default).
+	AzureEndpoint             string
+	AzureTranscribeDeployment string
+	AzureChatDeployment       string
+	AzureAPIVersion           string
+	// Profiles are the [profile.NAME] sections of the config file, by name.
+	Profiles map[string]Profile
+	// Profile is the selected profile: TRANSCRIPT_PROFILE, or the profile
+	// applied by WithProfile. Empty means none.
+	Profile string
+
+	// Tag is the session tag used when --tag is not given, and Vocab the
+	// names always put in the transcription prompt. Only set by a project file.
+	Tag   string
+	Vocab []string
+	// Project is the project file applied by ApplyProject. Empty if none.
+	Project string
 }
 
-// dir returns the configuration directory path.
-// Uses XDG_CONFIG_HOME if set, otherwise ~/.config/go-transcript.
-func dir() (string, error) {
-	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
-		return filepath.Join(xdg, "go-transcript"), nil
+// Path returns the path of the config file: TRANSCRIPT_CONFIG if set,
+// otherwise config in the config directory (see appdirs).
+func Path() (string, error) {
+	if p := os.Getenv(EnvConfigFile); p != "" {
+		return ExpandPath(p), nil
 	}
-
-	home, err := os.UserHomeDir()
+	d, err := dir()
 	if err != nil {
-		return "", fmt.Errorf("cannot determine home directory: %w", err)
+		return "", err
 	}
-	return filepath.Join(home, ".config", "go-transcript"), nil
+	return filepath.Join(d, "config"), nil
 }
 
-// path returns the full path to the config file.
-func path() (string, error) {
-	d, err := dir()
+// dir returns the configuration directory path (see appdirs).
+func dir() (string, error) {
+	dirs, err := appdirs.Default()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(d, "config"), nil
+	return dirs.Config, nil
+}
+
+// stateFile returns name in the state directory, or next to the config file
+// p if it is still there (kept by versions before XDG support).
+func stateFile(p, name string) string {
+	legacy := filepath.Join(filepath.Dir(p), name)
+	dirs, err := appdirs.Default()
+	if err != nil {
+		return legacy
+	}
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy
+	}
+	return filepath.Join(dirs.State, name)
 }
 
 // Load reads the configuration file and environment variables.
@@ -72,28 +290,304 @@ func path() (string, error) {
 func Load() (Config, error) {
 	var cfg Config
 
-	p, err := path()
+	p, err := Path()
 	if err != nil {
 		return cfg, err
 	}
 
 	// Read config file if it exists.
-	if data, err := parseFile(p); err == nil {
-		cfg.OutputDir = data[KeyOutputDir]
-	} else if !os.IsNotExist(err) {
+	data, err := parseFile(p)
+	if err != nil && !os.IsNotExist(err) {
 		return cfg, fmt.Errorf("failed to read config: %w", err)
 	}
 
+	cfg.Profile = selectedProfile()
+	if cfg.Profiles, err = parseProfiles(data); err != nil {
+		return cfg, err
+	}
+
 	// Environment variable fallback (only if not set in config).
+	cfg.OutputDir = data[KeyOutputDir]
 	if cfg.OutputDir == "" {
 		cfg.OutputDir = os.Getenv(EnvOutputDir)
 	}
 
+	cfg.OutputName = valueOrEnv(data, KeyOutputName, EnvOutputName)
+	if frontMatter := valueOrEnv(data, KeyFrontMatter, EnvFrontMatter); frontMatter != "" {
+		if cfg.FrontMatter, err = ParseFrontMatter(frontMatter); err != nil {
+			return cfg, err
+		}
+	}
+
+	cfg.ObsidianVault = ExpandPath(valueOrEnv(data, KeyObsidianVault, EnvObsidianVault))
+	cfg.ObsidianFolder = valueOrEnv(data, KeyObsidianFolder, EnvObsidianFolder)
+
+	warning := data[KeyPromptTokenWarning]
+	if warning == "" {
+		warning = os.Getenv(EnvPromptTokenWarning)
+	}
+	if warning != "" {
+		if cfg.PromptTokenWarning, err = ParsePromptTokenWarning(warning); err != nil {
+			return cfg, err
+		}
+	}
+
+	cfg.Transcriber = valueOrEnv(data, KeyTranscriber, EnvTranscriber)
+	cfg.WhisperModel = ExpandPath(valueOrEnv(data, KeyWhisperModel, EnvWhisperModel))
+	cfg.WhisperBin = ExpandPath(valueOrEnv(data, KeyWhisperBin, EnvWhisperBin))
+	cfg.WhisperURL = valueOrEnv(data, KeyWhisperURL, EnvWhisperURL)
+
+	cfg.TagsDir = ExpandPath(valueOrEnv(data, KeyTagsDir, EnvTagsDir))
+	if cfg.TagsDir == "" {
+		cfg.TagsDir = stateFile(p, "tags")
+	}
+
+	cfg.IntroLibrary = ExpandPath(valueOrEnv(data, KeyIntroLibrary, EnvIntroLibrary))
+	if cfg.IntroLibrary == "" {
+		cfg.IntroLibrary = stateFile(p, "intros.json")
+	}
+
+	cfg.Glossary = ExpandPath(valueOrEnv(data, KeyGlossary, EnvGlossary))
+	if fillers := valueOrEnv(data, KeyCleanFillers, EnvCleanFillers); fillers != "" {
+		if cfg.CleanFillers, err = ParseCleanFillers(fillers); err != nil {
+			return cfg, err
+		}
+	}
+
+	cfg.RedactPatterns = ExpandPath(valueOrEnv(data, KeyRedactPatterns, EnvRedactPatterns))
+
+	cfg.OllamaURL = valueOrEnv(data, KeyOllamaURL, EnvOllamaURL)
+	cfg.OllamaModel = valueOrEnv(data, KeyOllamaModel, EnvOllamaModel)
+
+	cfg.TemplatesDir = ExpandPath(valueOrEnv(data, KeyTemplatesDir, EnvTemplatesDir))
+	if cfg.TemplatesDir == "" {
+		cfg.TemplatesDir = filepath.Join(filepath.Dir(p), "templates")
+	}
+
+	cfg.RestructureModel = valueOrEnv(data, KeyRestructureModel, EnvRestructureModel)
+	if windows := valueOrEnv(data, KeyContextWindows, EnvContextWindows); windows != "" {
+		if cfg.ContextWindows, err = ParseContextWindows(windows); err != nil {
+			return cfg, err
+		}
+	}
+
+	cfg.RestructureSplit = valueOrEnv(data, KeyRestructureSplit, EnvRestructureSplit)
+	if tokens := valueOrEnv(data, KeyRestructureChunk, EnvRestructureChunk); tokens != "" {
+		if cfg.RestructureChunkTokens, err = ParseRestructureChunkTokens(tokens); err != nil {
+			return cfg, err
+		}
+	}
+	if tokens := valueOrEnv(data, KeyRestructureOverlap, EnvRestructureOverlap); tokens != "" {
+		if cfg.RestructureOverlap, err = ParseRestructureOverlap(tokens); err != nil {
+			return cfg, err
+		}
+	}
+	cfg.DefaultCommand = valueOrEnv(data, KeyDefaultCommand, EnvDefaultCommand)
+	cfg.DefaultDevice = valueOrEnv(data, KeyDefaultDevice, EnvDefaultDevice)
+	cfg.FFmpegPath = ExpandPath(data[KeyFFmpegPath])
+
+	cfg.CABundle = ExpandPath(valueOrEnv(data, KeyCABundle, EnvCABundle))
+	cfg.ClientCert = ExpandPath(valueOrEnv(data, KeyClientCert, EnvClientCert))
+	cfg.ClientKey = ExpandPath(valueOrEnv(data, KeyClientKey, EnvClientKey))
+
+	if requests := valueOrEnv(data, KeyRateLimitRequests, EnvRateLimitRequests); requests != "" {
+		if cfg.RateLimitRequests, err = ParseRateLimit(KeyRateLimitRequests, requests); err != nil {
+			return cfg, err
+		}
+	}
+	if seconds := valueOrEnv(data, KeyRateLimitAudio, EnvRateLimitAudio); seconds != "" {
+		if cfg.RateLimitAudio, err = ParseRateLimit(KeyRateLimitAudio, seconds); err != nil {
+			return cfg, err
+		}
+	}
+	if webhook := valueOrEnv(data, KeyNotifyWebhook, EnvNotifyWebhook); webhook != "" {
+		if cfg.NotifyWebhook, err = ParseWebhookURL(webhook); err != nil {
+			return cfg, err
+		}
+	}
+
+	if endpoint := valueOrEnv(data, KeyGRPCEndpoint, EnvGRPCEndpoint); endpoint != "" {
+		if cfg.GRPCEndpoint, err = ParseGRPCEndpoint(endpoint); err != nil {
+			return cfg, err
+		}
+	}
+	if plaintext := valueOrEnv(data, KeyGRPCPlaintext, EnvGRPCPlaintext); plaintext != "" {
+		if cfg.GRPCPlaintext, err = ParseGRPCPlaintext(plaintext); err != nil {
+			return cfg, err
+		}
+	}
+	if timeout := valueOrEnv(data, KeyGRPCTimeout, EnvGRPCTimeout); timeout != "" {
+		if cfg.GRPCTimeout, err = ParseGRPCTimeout(timeout); err != nil {
+			return cfg, err
+		}
+	}
+
+	if endpoint := valueOrEnv(data, KeyAzureEndpoint, EnvAzureEndpoint); endpoint != "" {
+		if cfg.AzureEndpoint, err = ParseAzureEndpoint(endpoint); err != nil {
+			return cfg, err
+		}
+	}
+	cfg.AzureTranscribeDeployment = valueOrEnv(data, KeyAzureTranscribe, EnvAzureTranscribe)
+	cfg.AzureChatDeployment = valueOrEnv(data, KeyAzureChat, EnvAzureChat)
+	cfg.AzureAPIVersion = valueOrEnv(data, KeyAzureAPIVersion, EnvAzureAPIVersion)
+
 	return cfg, nil
 }
 
+// valueOrEnv returns the config file value of key, or the environment
+// variable env if the key is not set in the file.
+func valueOrEnv(data map[string]string, key, env string) string {
+	if v := data[key]; v != "" {
+		return v
+	}
+	return os.Getenv(env)
+}
+
+// ParsePromptTokenWarning parses a prompt-token-warning value.
+// The value must be a positive integer.
+func ParsePromptTokenWarning(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%w: %s must be a positive integer, got %q", ErrInvalidValue, KeyPromptTokenWarning, value)
+	}
+	return n, nil
+}
+
+// MinRestructureChunkTokens is the smallest restructure-chunk-tokens: smaller
+// parts lose too much context to summarize.
+const MinRestructureChunkTokens = 1000
+
+// ParseRestructureChunkTokens parses a restructure-chunk-tokens value.
+// The value must be an integer of at least MinRestructureChunkTokens.
+func ParseRestructureChunkTokens(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < MinRestructureChunkTokens {
+		return 0, fmt.Errorf("%w: %s must be an integer of at least %d, got %q",
+			ErrInvalidValue, KeyRestructureChunk, MinRestructureChunkTokens, value)
+	}
+	return n, nil
+}
+
+// ParseRestructureOverlap parses a restructure-overlap value.
+// The value must be a non-negative integer.
+func ParseRestructureOverlap(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: %s must be a non-negative integer, got %q", ErrInvalidValue, KeyRestructureOverlap, value)
+	}
+	return n, nil
+}
+
+// ParseRateLimit parses a rate-limit-requests or rate-limit-audio value.
+// The value must be a positive integer.
+func ParseRateLimit(key, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%w: %s must be a positive integer, got %q", ErrInvalidValue, key, value)
+	}
+	return n, nil
+}
+
+// ParseWebhookURL parses a notify-webhook value.
+// The value must be an absolute http or https URL.
+func ParseWebhookURL(value string) (string, error) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%w: %s must be an http or https URL, got %q", ErrInvalidValue, KeyNotifyWebhook, value)
+	}
+	return value, nil
+}
+
+// ParseGRPCEndpoint parses a grpc-endpoint value: a host:port address.
+func ParseGRPCEndpoint(value string) (string, error) {
+	host, port, err := net.SplitHostPort(value)
+	if err != nil || host == "" || port == "" {
+		return "", fmt.Errorf("%w: %s must be a host:port address, got %q", ErrInvalidValue, KeyGRPCEndpoint, value)
+	}
+	return value, nil
+}
+
+// ParseFrontMatter parses a front-matter value: true or false.
+func ParseFrontMatter(value string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s must be true or false, got %q", ErrInvalidValue, KeyFrontMatter, value)
+	}
+	return b, nil
+}
+
+// ParseGRPCPlaintext parses a grpc-plaintext value: true or false.
+func ParseGRPCPlaintext(value string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s must be true or false, got %q", ErrInvalidValue, KeyGRPCPlaintext, value)
+	}
+	return b, nil
+}
+
+// ParseGRPCTimeout parses a grpc-timeout value: a positive duration, e.g. "2m".
+func ParseGRPCTimeout(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%w: %s must be a positive duration like 2m, got %q", ErrInvalidValue, KeyGRPCTimeout, value)
+	}
+	return d, nil
+}
+
+// ParseAzureEndpoint parses an azure-endpoint value: the https URL of an
+// Azure OpenAI resource, without path. A trailing slash is removed.
+func ParseAzureEndpoint(value string) (string, error) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+		return "", fmt.Errorf("%w: %s must be the URL of the resource, like https://NAME.openai.azure.com, got %q",
+			ErrInvalidValue, KeyAzureEndpoint, value)
+	}
+	return strings.TrimSuffix(value, "/"), nil
+}
+
+// ParseContextWindows parses a context-windows value: comma-separated
+// model=tokens pairs, e.g. "gpt-4.1=1047576,qwen2.5:14b=32768".
+// Token counts must be positive integers.
+func ParseContextWindows(value string) (map[string]int, error) {
+	windows := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		model, tokens, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		model = strings.TrimSpace(model)
+		n, err := strconv.Atoi(strings.TrimSpace(tokens))
+		if !ok || model == "" || err != nil || n <= 0 {
+			return nil, fmt.Errorf("%w: %s must be model=tokens pairs separated by commas, got %q",
+				ErrInvalidValue, KeyContextWindows, pair)
+		}
+		windows[model] = n
+	}
+	return windows, nil
+}
+
+// cleanFillersLanguage matches the language of a clean-fillers pair: an
+// ISO 639-1 code, or * for every language.
+var cleanFillersLanguage = regexp.MustCompile(`^(?:[a-z]{2}|\*)$`)
+
+// ParseCleanFillers parses a clean-fillers value: language=filler pairs
+// separated by commas, like "fr=du coup,en=basically,*=okay". Fillers are
+// returned by language, in order.
+func ParseCleanFillers(value string) (map[string][]string, error) {
+	fillers := make(map[string][]string)
+	for _, pair := range strings.Split(value, ",") {
+		language, filler, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		language, filler = strings.TrimSpace(language), strings.TrimSpace(filler)
+		if !ok || !cleanFillersLanguage.MatchString(language) || filler == "" {
+			return nil, fmt.Errorf("%w: %s must be language=filler pairs separated by commas, got %q",
+				ErrInvalidValue, KeyCleanFillers, pair)
+		}
+		fillers[language] = append(fillers[language], filler)
+	}
+	return fillers, nil
+}
+
 // parseFile reads a key=value config file.
-// Format: one key=value per line, # comments, empty lines ignored.
+// Format: one key=value per line, # comments, empty lines ignored. Keys after
+// a [profile.NAME] line belong to that profile, and are returned as
+// profile.NAME.KEY (see ProfileKey).
 func parseFile(path string) (map[string]string, error) {
 	f, err := os.Open(path) // #nosec G304 -- config path is constructed from home dir
 	if err != nil {
@@ -104,6 +598,7 @@ func parseFile(path string) (map[string]string, error) {
 	data := make(map[string]string)
 	scanner := bufio.NewScanner(f)
 	lineNum := 0
+	profile := "" // Profile of the current section, empty before any
 
 	for scanner.Scan() {
 		lineNum++
@@ -114,6 +609,16 @@ func parseFile(path string) (map[string]string, error) {
 			continue
 		}
 
+		// Parse [profile.NAME] section headers.
+		if section, ok := strings.CutPrefix(line, "["); ok {
+			name, ok := strings.CutPrefix(strings.TrimSuffix(section, "]"), profileSection+".")
+			if !ok || !strings.HasSuffix(section, "]") || ValidateProfileName(name) != nil {
+				return nil, fmt.Errorf("%w: %s:%d: %q (want [%s.NAME])", ErrInvalidSyntax, path, lineNum, line, profileSection)
+			}
+			profile = name
+			continue
+		}
+
 		// Parse key=value.
 		parts := strings.SplitN(line, "=", 2)
 		if len(parts) != 2 {
@@ -122,6 +627,9 @@ func parseFile(path string) (map[string]string, error) {
 
 		key := strings.TrimSpace(parts[0])
 		value := strings.TrimSpace(parts[1])
+		if profile != "" {
+			key = ProfileKey(profile, key)
+		}
 		data[key] = value
 	}
 
@@ -136,6 +644,7 @@ func parseFile(path string) (map[string]string, error) {
 // Creates the config directory and file if they don't exist.
 // Preserves existing key=value pairs but discards comments.
 // Returns ErrInvalidKey if the key contains = or newline characters.
+// Settings of profiles are saved with the keys of ProfileKey.
 //
 // WARNING: This function rewrites the entire config file. Any comments
 // (lines starting with #) in the original file will be lost. This is a
@@ -145,8 +654,13 @@ func Save(key, value string) error {
 	if strings.ContainsAny(key, "=\n\r") || key == "" {
 		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
 	}
+	if name, _, ok := SplitProfileKey(key); ok {
+		if err := ValidateProfileName(name); err != nil {
+			return err
+		}
+	}
 
-	configPath, err := path()
+	configPath, err := Path()
 	if err != nil {
 		return err
 	}
@@ -171,7 +685,8 @@ func Save(key, value string) error {
 }
 
 // writeFile writes the config map to a file.
-// Keys are sorted alphabetically for deterministic output.
+// Keys are sorted alphabetically for deterministic output, and the settings
+// of profiles written last, in their [profile.NAME] sections.
 func writeFile(path string, data map[string]string) error {
 	// #nosec G302 G304 -- config file with standard permissions, path from home dir
 	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, filePerm)
@@ -181,11 +696,16 @@ func writeFile(path string, data map[string]string) error {
 	defer func() { _ = f.Close() }()
 
 	// Sort keys for deterministic output.
-	keys := make([]string, 0, len(data))
+	var keys, profileKeys []string
 	for k := range data {
-		keys = append(keys, k)
+		if _, _, ok := SplitProfileKey(k); ok {
+			profileKeys = append(profileKeys, k)
+		} else {
+			keys = append(keys, k)
+		}
 	}
 	sort.Strings(keys)
+	sort.Strings(profileKeys)
 
 	for _, key := range keys {
 		if _, err := fmt.Fprintf(f, "%s=%s\n", key, data[key]); err != nil {
@@ -193,13 +713,27 @@ func writeFile(path string, data map[string]string) error {
 		}
 	}
 
+	section := ""
+	for _, key := range profileKeys {
+		name, setting, _ := SplitProfileKey(key)
+		if name != section {
+			if _, err := fmt.Fprintf(f, "\n[%s.%s]\n", profileSection, name); err != nil {
+				return fmt.Errorf("failed to write config: %w", err)
+			}
+			section = name
+		}
+		if _, err := fmt.Fprintf(f, "%s=%s\n", setting, data[key]); err != nil {
+			return fmt.Errorf("failed to write config: %w", err)
+		}
+	}
+
 	return nil
 }
 
 // Get reads a single value from the config file.
 // Returns empty string if the key doesn't exist.
 func Get(key string) (string, error) {
-	p, err := path()
+	p, err := Path()
 	if err != nil {
 		return "", err
 	}
@@ -217,7 +751,7 @@ func Get(key string) (string, error) {
 
 // List returns all config values as a map.
 func List() (map[string]string, error) {
-	p, err := path()
+	p, err := Path()
 	if err != nil {
 		return nil, err
 	}
@@ -2,10 +2,14 @@ package config
 
 import (
 	"errors"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Notes:
@@ -16,7 +20,7 @@ import (
 // - Permission tests (chmod) may behave differently on Windows.
 //
 // Coverage gaps (intentional - rare I/O errors not worth mocking):
-// - os.UserHomeDir() failures in dir(), ExpandPath()
+// - os.UserHomeDir() failures in dir(), ExpandPath() (see appdirs)
 // - Non-NotExist errors in Load(), Get(), List()
 // - Write errors in writeFile() (disk full, permission denied mid-write)
 // These are system-level errors that would require extensive mocking for
@@ -352,6 +356,417 @@ func TestLoad(t *testing.T) {
 		}
 	})
 
+	t.Run("reads prompt-token-warning from file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_PROMPT_TOKEN_WARNING", "20000")
+		writeConfigFile(t, tmpDir, "prompt-token-warning=50000\n")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.PromptTokenWarning != 50000 {
+			t.Errorf("PromptTokenWarning = %d, want 50000 (file should take precedence)", cfg.PromptTokenWarning)
+		}
+	})
+
+	t.Run("prompt-token-warning falls back to env var", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_PROMPT_TOKEN_WARNING", "20000")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.PromptTokenWarning != 20000 {
+			t.Errorf("PromptTokenWarning = %d, want 20000", cfg.PromptTokenWarning)
+		}
+	})
+
+	t.Run("returns error for invalid prompt-token-warning", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_PROMPT_TOKEN_WARNING", "")
+		writeConfigFile(t, tmpDir, "prompt-token-warning=many\n")
+
+		_, err := Load()
+		if !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("Load() error = %v, want ErrInvalidValue", err)
+		}
+	})
+
+	t.Run("reads rate limits from file and env var", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_RATE_LIMIT_REQUESTS", "20")
+		t.Setenv("TRANSCRIPT_RATE_LIMIT_AUDIO", "3600")
+		writeConfigFile(t, tmpDir, "rate-limit-requests=50\n")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.RateLimitRequests != 50 {
+			t.Errorf("RateLimitRequests = %d, want 50 (file should take precedence)", cfg.RateLimitRequests)
+		}
+		if cfg.RateLimitAudio != 3600 {
+			t.Errorf("RateLimitAudio = %d, want 3600", cfg.RateLimitAudio)
+		}
+	})
+
+	t.Run("returns error for invalid rate limit", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_RATE_LIMIT_REQUESTS", "")
+		t.Setenv("TRANSCRIPT_RATE_LIMIT_AUDIO", "")
+		writeConfigFile(t, tmpDir, "rate-limit-audio=0\n")
+
+		_, err := Load()
+		if !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("Load() error = %v, want ErrInvalidValue", err)
+		}
+	})
+
+	t.Run("reads default-device from file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_DEFAULT_DEVICE", "")
+		writeConfigFile(t, tmpDir, "default-device=Yeti Stereo Microphone\n")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.DefaultDevice != "Yeti Stereo Microphone" {
+			t.Errorf("DefaultDevice = %q, want the file value", cfg.DefaultDevice)
+		}
+	})
+
+	t.Run("reads notify-webhook from env var", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_NOTIFY_WEBHOOK", "https://hooks.example.com/run")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.NotifyWebhook != "https://hooks.example.com/run" {
+			t.Errorf("NotifyWebhook = %q, want the env var value", cfg.NotifyWebhook)
+		}
+	})
+
+	t.Run("returns error for invalid notify-webhook", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_NOTIFY_WEBHOOK", "")
+		writeConfigFile(t, tmpDir, "notify-webhook=hooks.example.com\n")
+
+		_, err := Load()
+		if !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("Load() error = %v, want ErrInvalidValue", err)
+		}
+	})
+
+	t.Run("reads output naming settings", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_OUTPUT_NAME", "")
+		t.Setenv("TRANSCRIPT_FRONT_MATTER", "true")
+		t.Setenv("TRANSCRIPT_OBSIDIAN_VAULT", "")
+		t.Setenv("TRANSCRIPT_OBSIDIAN_FOLDER", "Meetings")
+		writeConfigFile(t, tmpDir, "output-name={{date}}_{{input_stem}}.md\nobsidian-vault=/vaults/work\n")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.OutputName != "{{date}}_{{input_stem}}.md" || !cfg.FrontMatter {
+			t.Errorf("output naming settings = %q, %v, want the file and env values", cfg.OutputName, cfg.FrontMatter)
+		}
+		if cfg.ObsidianVault != "/vaults/work" || cfg.ObsidianFolder != "Meetings" {
+			t.Errorf("obsidian settings = %q, %q, want the file and env values", cfg.ObsidianVault, cfg.ObsidianFolder)
+		}
+	})
+
+	t.Run("returns error for invalid front-matter", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_FRONT_MATTER", "")
+		writeConfigFile(t, tmpDir, "front-matter=sometimes\n")
+
+		if _, err := Load(); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("Load() error = %v, want ErrInvalidValue", err)
+		}
+	})
+
+	t.Run("reads grpc settings", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_GRPC_ENDPOINT", "")
+		t.Setenv("TRANSCRIPT_GRPC_PLAINTEXT", "true")
+		t.Setenv("TRANSCRIPT_GRPC_TIMEOUT", "")
+		writeConfigFile(t, tmpDir, "grpc-endpoint=asr.internal:50051\ngrpc-timeout=90s\n")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.GRPCEndpoint != "asr.internal:50051" || !cfg.GRPCPlaintext || cfg.GRPCTimeout != 90*time.Second {
+			t.Errorf("grpc settings = %q, %v, %s, want the file and env values", cfg.GRPCEndpoint, cfg.GRPCPlaintext, cfg.GRPCTimeout)
+		}
+	})
+
+	t.Run("returns error for invalid grpc settings", func(t *testing.T) {
+		for _, content := range []string{"grpc-endpoint=asr.internal\n", "grpc-plaintext=maybe\n", "grpc-timeout=0s\n"} {
+			tmpDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", tmpDir)
+			t.Setenv("TRANSCRIPT_GRPC_PLAINTEXT", "")
+			writeConfigFile(t, tmpDir, content)
+
+			if _, err := Load(); !errors.Is(err, ErrInvalidValue) {
+				t.Errorf("Load() with %q error = %v, want ErrInvalidValue", content, err)
+			}
+		}
+	})
+
+	t.Run("reads azure settings", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_AZURE_ENDPOINT", "")
+		t.Setenv("TRANSCRIPT_AZURE_TRANSCRIBE_DEPLOYMENT", "")
+		t.Setenv("TRANSCRIPT_AZURE_CHAT_DEPLOYMENT", "gpt-4o")
+		t.Setenv("TRANSCRIPT_AZURE_API_VERSION", "")
+		writeConfigFile(t, tmpDir, "azure-endpoint=https://contoso.openai.azure.com/\nazure-transcribe-deployment=whisper\nazure-api-version=2025-03-01-preview\n")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.AzureEndpoint != "https://contoso.openai.azure.com" || cfg.AzureTranscribeDeployment != "whisper" ||
+			cfg.AzureChatDeployment != "gpt-4o" || cfg.AzureAPIVersion != "2025-03-01-preview" {
+			t.Errorf("azure settings = %q, %q, %q, %q, want the file and env values", cfg.AzureEndpoint,
+				cfg.AzureTranscribeDeployment, cfg.AzureChatDeployment, cfg.AzureAPIVersion)
+		}
+	})
+
+	t.Run("returns error for invalid azure-endpoint", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_AZURE_ENDPOINT", "")
+		writeConfigFile(t, tmpDir, "azure-endpoint=contoso.openai.azure.com\n")
+
+		if _, err := Load(); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("Load() error = %v, want ErrInvalidValue", err)
+		}
+	})
+
+	t.Run("tags-dir defaults to the state directory", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("XDG_STATE_HOME", filepath.Join(tmpDir, "state"))
+		t.Setenv("TRANSCRIPT_TAGS_DIR", "")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		want := filepath.Join(tmpDir, "state", "go-transcript", "tags")
+		if cfg.TagsDir != want {
+			t.Errorf("TagsDir = %q, want %q", cfg.TagsDir, want)
+		}
+	})
+
+	t.Run("tags-dir kept next to the config file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("XDG_STATE_HOME", filepath.Join(tmpDir, "state"))
+		t.Setenv("TRANSCRIPT_TAGS_DIR", "")
+		legacy := filepath.Join(tmpDir, "go-transcript", "tags")
+		if err := os.MkdirAll(legacy, 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.TagsDir != legacy {
+			t.Errorf("TagsDir = %q, want the existing %q", cfg.TagsDir, legacy)
+		}
+	})
+
+	t.Run("reads tags-dir from env var", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_TAGS_DIR", "/from/env/tags")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.TagsDir != "/from/env/tags" {
+			t.Errorf("TagsDir = %q, want %q", cfg.TagsDir, "/from/env/tags")
+		}
+	})
+
+	t.Run("templates-dir defaults next to the config file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_TEMPLATES_DIR", "")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		want := filepath.Join(tmpDir, "go-transcript", "templates")
+		if cfg.TemplatesDir != want {
+			t.Errorf("TemplatesDir = %q, want %q", cfg.TemplatesDir, want)
+		}
+	})
+
+	t.Run("reads templates-dir from config file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_TEMPLATES_DIR", "/from/env/templates")
+		writeConfigFile(t, tmpDir, "templates-dir=/from/file/templates\n")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.TemplatesDir != "/from/file/templates" {
+			t.Errorf("TemplatesDir = %q, want %q", cfg.TemplatesDir, "/from/file/templates")
+		}
+	})
+
+	t.Run("intro-library defaults to the state directory", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("XDG_STATE_HOME", filepath.Join(tmpDir, "state"))
+		t.Setenv("TRANSCRIPT_INTRO_LIBRARY", "")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		want := filepath.Join(tmpDir, "state", "go-transcript", "intros.json")
+		if cfg.IntroLibrary != want {
+			t.Errorf("IntroLibrary = %q, want %q", cfg.IntroLibrary, want)
+		}
+	})
+
+	t.Run("reads intro-library from env var", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_INTRO_LIBRARY", "/from/env/intros.json")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.IntroLibrary != "/from/env/intros.json" {
+			t.Errorf("IntroLibrary = %q, want %q", cfg.IntroLibrary, "/from/env/intros.json")
+		}
+	})
+
+	t.Run("reads local transcription settings", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_TRANSCRIBER", "openai")
+		t.Setenv("TRANSCRIPT_WHISPER_MODEL", "")
+		t.Setenv("TRANSCRIPT_WHISPER_BIN", "/from/env/whisper-cli")
+		t.Setenv("TRANSCRIPT_WHISPER_URL", "http://localhost:8080")
+		writeConfigFile(t, tmpDir, "transcriber=local\nwhisper-model=/models/ggml-base.bin\n")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.Transcriber != "local" {
+			t.Errorf("Transcriber = %q, want %q (file should take precedence)", cfg.Transcriber, "local")
+		}
+		if cfg.WhisperModel != "/models/ggml-base.bin" {
+			t.Errorf("WhisperModel = %q, want %q", cfg.WhisperModel, "/models/ggml-base.bin")
+		}
+		if cfg.WhisperBin != "/from/env/whisper-cli" {
+			t.Errorf("WhisperBin = %q, want %q", cfg.WhisperBin, "/from/env/whisper-cli")
+		}
+		if cfg.WhisperURL != "http://localhost:8080" {
+			t.Errorf("WhisperURL = %q, want %q", cfg.WhisperURL, "http://localhost:8080")
+		}
+	})
+
+	t.Run("reads ollama settings", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_OLLAMA_URL", "http://gpu-box:11434")
+		t.Setenv("TRANSCRIPT_OLLAMA_MODEL", "mistral")
+		writeConfigFile(t, tmpDir, "ollama-model=qwen2.5:14b\n")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.OllamaURL != "http://gpu-box:11434" {
+			t.Errorf("OllamaURL = %q, want %q", cfg.OllamaURL, "http://gpu-box:11434")
+		}
+		if cfg.OllamaModel != "qwen2.5:14b" {
+			t.Errorf("OllamaModel = %q, want %q (file should take precedence)", cfg.OllamaModel, "qwen2.5:14b")
+		}
+	})
+
+	t.Run("reads restructure model and context windows", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_RESTRUCTURE_MODEL", "gpt-4.1")
+		t.Setenv("TRANSCRIPT_CONTEXT_WINDOWS", "")
+		writeConfigFile(t, tmpDir, "context-windows=my-model=32000, gpt-4o=64000\n")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.RestructureModel != "gpt-4.1" {
+			t.Errorf("RestructureModel = %q, want %q", cfg.RestructureModel, "gpt-4.1")
+		}
+		if cfg.ContextWindows["my-model"] != 32000 || cfg.ContextWindows["gpt-4o"] != 64000 {
+			t.Errorf("ContextWindows = %v, want my-model=32000 and gpt-4o=64000", cfg.ContextWindows)
+		}
+	})
+
+	t.Run("reads TLS settings", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_CA_BUNDLE", "/etc/ssl/corp-ca.pem")
+		t.Setenv("TRANSCRIPT_CLIENT_CERT", "")
+		t.Setenv("TRANSCRIPT_CLIENT_KEY", "")
+		writeConfigFile(t, tmpDir, "client-cert=/certs/me.pem\nclient-key=/certs/me-key.pem\n")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.CABundle != "/etc/ssl/corp-ca.pem" {
+			t.Errorf("CABundle = %q, want %q", cfg.CABundle, "/etc/ssl/corp-ca.pem")
+		}
+		if cfg.ClientCert != "/certs/me.pem" || cfg.ClientKey != "/certs/me-key.pem" {
+			t.Errorf("ClientCert, ClientKey = %q, %q, want %q, %q", cfg.ClientCert, cfg.ClientKey, "/certs/me.pem", "/certs/me-key.pem")
+		}
+	})
+
+	t.Run("returns error for invalid context windows", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_CONTEXT_WINDOWS", "my-model")
+
+		if _, err := Load(); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("Load() error = %v, want ErrInvalidValue", err)
+		}
+	})
+
 	t.Run("returns error for invalid config syntax", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		t.Setenv("XDG_CONFIG_HOME", tmpDir)
@@ -365,6 +780,334 @@ func TestLoad(t *testing.T) {
 	})
 }
 
+// ---------------------------------------------------------------------------
+// TestParsePromptTokenWarning - Threshold validation
+// ---------------------------------------------------------------------------
+
+func TestParsePromptTokenWarning(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"100000", 100000, false},
+		{"1", 1, false},
+		{"0", 0, true},
+		{"-5", 0, true},
+		{"1e5", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParsePromptTokenWarning(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidValue) {
+					t.Errorf("ParsePromptTokenWarning(%q) error = %v, want ErrInvalidValue", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePromptTokenWarning(%q) unexpected error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePromptTokenWarning(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestParseRestructureChunkTokens - Part size validation
+// ---------------------------------------------------------------------------
+
+func TestParseRestructureChunkTokens(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"20000", 20000, false},
+		{"1000", 1000, false},
+		{"999", 0, true},
+		{"-5", 0, true},
+		{"20k", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseRestructureChunkTokens(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidValue) {
+					t.Errorf("ParseRestructureChunkTokens(%q) error = %v, want ErrInvalidValue", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRestructureChunkTokens(%q) unexpected error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRestructureChunkTokens(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestParseRestructureOverlap - Part overlap validation
+// ---------------------------------------------------------------------------
+
+func TestParseRestructureOverlap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"500", 500, false},
+		{"0", 0, false},
+		{"-1", 0, true},
+		{"1.5", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseRestructureOverlap(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidValue) {
+					t.Errorf("ParseRestructureOverlap(%q) error = %v, want ErrInvalidValue", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRestructureOverlap(%q) unexpected error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRestructureOverlap(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestParseWebhookURL - Webhook URL validation
+// ---------------------------------------------------------------------------
+
+func TestParseWebhookURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{"https://hooks.slack.com/services/T000/B000/XXXX", false},
+		{"http://localhost:8080/notify", false},
+		{"hooks.example.com/run", true},
+		{"ftp://example.com/run", true},
+		{"https://", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseWebhookURL(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidValue) {
+					t.Errorf("ParseWebhookURL(%q) error = %v, want ErrInvalidValue", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWebhookURL(%q) unexpected error: %v", tt.value, err)
+			}
+			if got != tt.value {
+				t.Errorf("ParseWebhookURL(%q) = %q, want unchanged", tt.value, got)
+			}
+		})
+	}
+}
+
+func TestParseAzureEndpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "https://contoso.openai.azure.com", want: "https://contoso.openai.azure.com"},
+		{value: "https://contoso.openai.azure.com/", want: "https://contoso.openai.azure.com"},
+		{value: "http://localhost:8080", want: "http://localhost:8080"},
+		{value: "contoso.openai.azure.com", wantErr: true},
+		{value: "https://contoso.openai.azure.com/openai/deployments/gpt-4o", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseAzureEndpoint(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidValue) {
+					t.Errorf("ParseAzureEndpoint(%q) error = %v, want ErrInvalidValue", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAzureEndpoint(%q) unexpected error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAzureEndpoint(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestParseRateLimit - Rate limit validation
+// ---------------------------------------------------------------------------
+
+func TestParseRateLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"500", 500, false},
+		{"1", 1, false},
+		{"0", 0, true},
+		{"-1", 0, true},
+		{"1.5", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseRateLimit(KeyRateLimitRequests, tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidValue) {
+					t.Errorf("ParseRateLimit(%q) error = %v, want ErrInvalidValue", tt.value, err)
+				}
+				if err != nil && !strings.Contains(err.Error(), KeyRateLimitRequests) {
+					t.Errorf("ParseRateLimit(%q) error = %v, want it to name the key", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRateLimit(%q) unexpected error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRateLimit(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestParseContextWindows - model=tokens pairs
+// ---------------------------------------------------------------------------
+
+func TestParseContextWindows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    map[string]int
+		wantErr bool
+	}{
+		{"gpt-4.1=1047576", map[string]int{"gpt-4.1": 1047576}, false},
+		{"a=1000, qwen2.5:14b = 32768", map[string]int{"a": 1000, "qwen2.5:14b": 32768}, false},
+		{"gpt-4.1", nil, true},
+		{"=1000", nil, true},
+		{"a=0", nil, true},
+		{"a=128K", nil, true},
+		{"a=1000,", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseContextWindows(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidValue) {
+					t.Errorf("ParseContextWindows(%q) error = %v, want ErrInvalidValue", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseContextWindows(%q) unexpected error: %v", tt.value, err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("ParseContextWindows(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestParseCleanFillers - language=filler pairs
+// ---------------------------------------------------------------------------
+
+func TestParseCleanFillers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    map[string][]string
+		wantErr bool
+	}{
+		{"fr=du coup", map[string][]string{"fr": {"du coup"}}, false},
+		{"fr=du coup, en = basically,fr=voilà,*=okay", map[string][]string{"fr": {"du coup", "voilà"}, "en": {"basically"}, "*": {"okay"}}, false},
+		{"du coup", nil, true},
+		{"=okay", nil, true},
+		{"fr=", nil, true},
+		{"french=du coup", nil, true},
+		{"FR=du coup", nil, true},
+		{"fr=du coup,", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseCleanFillers(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidValue) {
+					t.Errorf("ParseCleanFillers(%q) error = %v, want ErrInvalidValue", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCleanFillers(%q) unexpected error: %v", tt.value, err)
+			}
+			if !maps.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("ParseCleanFillers(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // TestSave - Config persistence
 // ---------------------------------------------------------------------------
@@ -543,6 +1286,47 @@ func TestGet(t *testing.T) {
 		}
 	})
 
+	t.Run("reads prompt-token-warning from file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_PROMPT_TOKEN_WARNING", "20000")
+		writeConfigFile(t, tmpDir, "prompt-token-warning=50000\n")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.PromptTokenWarning != 50000 {
+			t.Errorf("PromptTokenWarning = %d, want 50000 (file should take precedence)", cfg.PromptTokenWarning)
+		}
+	})
+
+	t.Run("prompt-token-warning falls back to env var", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_PROMPT_TOKEN_WARNING", "20000")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.PromptTokenWarning != 20000 {
+			t.Errorf("PromptTokenWarning = %d, want 20000", cfg.PromptTokenWarning)
+		}
+	})
+
+	t.Run("returns error for invalid prompt-token-warning", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_PROMPT_TOKEN_WARNING", "")
+		writeConfigFile(t, tmpDir, "prompt-token-warning=many\n")
+
+		_, err := Load()
+		if !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("Load() error = %v, want ErrInvalidValue", err)
+		}
+	})
+
 	t.Run("returns error for invalid config syntax", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		t.Setenv("XDG_CONFIG_HOME", tmpDir)
@@ -613,6 +1397,47 @@ func TestList(t *testing.T) {
 		}
 	})
 
+	t.Run("reads prompt-token-warning from file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_PROMPT_TOKEN_WARNING", "20000")
+		writeConfigFile(t, tmpDir, "prompt-token-warning=50000\n")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.PromptTokenWarning != 50000 {
+			t.Errorf("PromptTokenWarning = %d, want 50000 (file should take precedence)", cfg.PromptTokenWarning)
+		}
+	})
+
+	t.Run("prompt-token-warning falls back to env var", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_PROMPT_TOKEN_WARNING", "20000")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.PromptTokenWarning != 20000 {
+			t.Errorf("PromptTokenWarning = %d, want 20000", cfg.PromptTokenWarning)
+		}
+	})
+
+	t.Run("returns error for invalid prompt-token-warning", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_PROMPT_TOKEN_WARNING", "")
+		writeConfigFile(t, tmpDir, "prompt-token-warning=many\n")
+
+		_, err := Load()
+		if !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("Load() error = %v, want ErrInvalidValue", err)
+		}
+	})
+
 	t.Run("returns error for invalid config syntax", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		t.Setenv("XDG_CONFIG_HOME", tmpDir)
@@ -883,6 +1708,54 @@ func TestParseFile(t *testing.T) {
 	})
 }
 
+// ---------------------------------------------------------------------------
+// TestPath - Config file override
+// ---------------------------------------------------------------------------
+
+func TestPath(t *testing.T) {
+	// NO t.Parallel() - uses t.Setenv
+
+	t.Run("config file in the config directory", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv(EnvConfigFile, "")
+
+		got, err := Path()
+		if err != nil {
+			t.Fatalf("Path() unexpected error: %v", err)
+		}
+		if want := filepath.Join(tmpDir, "go-transcript", "config"); got != want {
+			t.Errorf("Path() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("TRANSCRIPT_CONFIG overrides the config file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "unused"))
+		custom := filepath.Join(tmpDir, "work.conf")
+		t.Setenv(EnvConfigFile, custom)
+		t.Setenv("TRANSCRIPT_OUTPUT_DIR", "")
+		t.Setenv("TRANSCRIPT_TEMPLATES_DIR", "")
+
+		if err := Save(KeyOutputDir, "/from/custom"); err != nil {
+			t.Fatalf("Save() unexpected error: %v", err)
+		}
+		if _, err := os.Stat(custom); err != nil {
+			t.Fatalf("Save() did not write the custom config file: %v", err)
+		}
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.OutputDir != "/from/custom" {
+			t.Errorf("OutputDir = %q, want %q", cfg.OutputDir, "/from/custom")
+		}
+		if want := filepath.Join(tmpDir, "templates"); cfg.TemplatesDir != want {
+			t.Errorf("TemplatesDir = %q, want %q next to the config file", cfg.TemplatesDir, want)
+		}
+	})
+}
+
 // ---------------------------------------------------------------------------
 // TestDir - Internal directory resolution
 // ---------------------------------------------------------------------------
@@ -904,6 +1777,9 @@ func TestDir(t *testing.T) {
 	})
 
 	t.Run("uses home/.config when XDG not set", func(t *testing.T) {
+		if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+			t.Skip("platform config directories are tested in appdirs")
+		}
 		t.Setenv("XDG_CONFIG_HOME", "")
 
 		home, err := os.UserHomeDir()
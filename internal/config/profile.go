@@ -0,0 +1,199 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Profile keys, in addition to KeyOutputDir: the defaults of the transcribe
+// and live flags of the same name.
+const (
+	KeyTemplate = "template"
+	KeyLanguage = "language"
+	KeyProvider = "provider"
+	KeyDiarize  = "diarize"
+	KeyParallel = "parallel"
+)
+
+// EnvProfile selects the profile used when --profile is not given.
+const EnvProfile = "TRANSCRIPT_PROFILE"
+
+// profileSection starts the name of profile sections: [profile.NAME] in the
+// config file, and profile.NAME.KEY in the maps of List.
+const profileSection = "profile"
+
+// maxProfileName bounds the length of profile names.
+const maxProfileName = 64
+
+// ProfileKeys lists the keys a profile can set.
+var ProfileKeys = []string{KeyTemplate, KeyLanguage, KeyProvider, KeyDiarize, KeyParallel, KeyOutputDir}
+
+// ErrUnknownProfile indicates the selected profile is not in the config file.
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// Profile is a named set of defaults of the transcribe and live commands,
+// read from a [profile.NAME] section of the config file. Flags given on the
+// command line override them. Empty values are not set.
+type Profile struct {
+	Name string
+	// Template, Language, Provider and Parallel are the values of the flags
+	// of the same name. Validated by the CLI.
+	Template string
+	Language string
+	Provider string
+	Parallel string
+	// Diarize is nil if the profile does not set it.
+	Diarize *bool
+	// OutputDir replaces the configured output-dir.
+	OutputDir string
+}
+
+// Values returns the values set by p, by profile key.
+func (p Profile) Values() map[string]string {
+	values := map[string]string{
+		KeyTemplate:  p.Template,
+		KeyLanguage:  p.Language,
+		KeyProvider:  p.Provider,
+		KeyParallel:  p.Parallel,
+		KeyOutputDir: p.OutputDir,
+	}
+	if p.Diarize != nil {
+		values[KeyDiarize] = strconv.FormatBool(*p.Diarize)
+	}
+	for key, value := range values {
+		if value == "" {
+			delete(values, key)
+		}
+	}
+	return values
+}
+
+// ProfileKey returns the key of setting key of profile name, as passed to
+// Save and returned by List.
+func ProfileKey(name, key string) string {
+	return profileSection + "." + name + "." + key
+}
+
+// SplitProfileKey returns the profile name and setting of a ProfileKey.
+// ok is false if key is not the key of a profile setting.
+func SplitProfileKey(key string) (name, setting string, ok bool) {
+	rest, ok := strings.CutPrefix(key, profileSection+".")
+	if !ok {
+		return "", "", false
+	}
+	return strings.Cut(rest, ".")
+}
+
+// ValidateProfileName checks that name can name a profile: lowercase
+// letters, digits, '-' and '_'.
+func ValidateProfileName(name string) error {
+	valid := name != "" && len(name) <= maxProfileName
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+			valid = false
+		}
+	}
+	if !valid {
+		return fmt.Errorf("%w: profile name %q (use lowercase letters, digits, '-' and '_')", ErrInvalidKey, name)
+	}
+	return nil
+}
+
+// ParseDiarize parses a diarize value of a profile: true or false.
+func ParseDiarize(value string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s must be true or false, got %q", ErrInvalidValue, KeyDiarize, value)
+	}
+	return b, nil
+}
+
+// parseProfiles returns the profiles set in data, the content of a config file.
+func parseProfiles(data map[string]string) (map[string]Profile, error) {
+	var profiles map[string]Profile
+	for key, value := range data {
+		name, setting, ok := SplitProfileKey(key)
+		if !ok {
+			continue
+		}
+		if !slices.Contains(ProfileKeys, setting) {
+			return nil, fmt.Errorf("%w: [%s.%s] %s (valid keys: %s)",
+				ErrInvalidKey, profileSection, name, setting, strings.Join(ProfileKeys, ", "))
+		}
+		if profiles == nil {
+			profiles = make(map[string]Profile)
+		}
+		p := profiles[name]
+		p.Name = name
+		switch setting {
+		case KeyTemplate:
+			p.Template = value
+		case KeyLanguage:
+			p.Language = value
+		case KeyProvider:
+			p.Provider = value
+		case KeyParallel:
+			p.Parallel = value
+		case KeyOutputDir:
+			p.OutputDir = ExpandPath(value)
+		case KeyDiarize:
+			diarize, err := ParseDiarize(value)
+			if err != nil {
+				return nil, fmt.Errorf("[%s.%s] %w", profileSection, name, err)
+			}
+			p.Diarize = &diarize
+		}
+		profiles[name] = p
+	}
+	return profiles, nil
+}
+
+// ProfileNames returns the names of the profiles of c, sorted.
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// LookupProfile returns the profile name.
+// Returns ErrUnknownProfile if the config file does not define it.
+func (c Config) LookupProfile(name string) (Profile, error) {
+	p, ok := c.Profiles[name]
+	if !ok {
+		available := "none defined"
+		if names := c.ProfileNames(); len(names) > 0 {
+			available = "available: " + strings.Join(names, ", ")
+		}
+		return Profile{}, fmt.Errorf("%w %q (%s)", ErrUnknownProfile, name, available)
+	}
+	return p, nil
+}
+
+// WithProfile returns c with the output directory of profile name, which
+// becomes the selected Profile. Returns c unchanged if name is empty.
+func (c Config) WithProfile(name string) (Config, error) {
+	if name == "" {
+		return c, nil
+	}
+	p, err := c.LookupProfile(name)
+	if err != nil {
+		return c, err
+	}
+	if p.OutputDir != "" {
+		c.OutputDir = p.OutputDir
+	}
+	c.Profile = name
+	return c, nil
+}
+
+// selectedProfile returns the profile selected by the environment.
+func selectedProfile() string {
+	return strings.TrimSpace(os.Getenv(EnvProfile))
+}
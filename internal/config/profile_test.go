@@ -0,0 +1,190 @@
+package config
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoad_Profiles(t *testing.T) {
+	// NO t.Parallel() - uses t.Setenv
+
+	t.Run("reads profile sections", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv("TRANSCRIPT_OUTPUT_DIR", "")
+		t.Setenv(EnvProfile, "")
+		writeConfigFile(t, tmpDir, `output-dir=/notes
+# Team meetings
+[profile.meetings]
+template=meeting
+diarize=true
+parallel=auto
+
+[profile.lectures]
+template=lecture
+language=fr
+provider=anthropic
+diarize=false
+output-dir=/notes/lectures
+`)
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.OutputDir != "/notes" {
+			t.Errorf("OutputDir = %q, want the top-level value", cfg.OutputDir)
+		}
+		if got := cfg.ProfileNames(); !slices.Equal(got, []string{"lectures", "meetings"}) {
+			t.Fatalf("ProfileNames() = %v, want [lectures meetings]", got)
+		}
+		meetings := cfg.Profiles["meetings"]
+		if meetings.Name != "meetings" || meetings.Template != "meeting" || meetings.Parallel != "auto" ||
+			meetings.Diarize == nil || !*meetings.Diarize || meetings.OutputDir != "" {
+			t.Errorf("meetings = %+v, want its section", meetings)
+		}
+		lectures := cfg.Profiles["lectures"]
+		want := map[string]string{
+			KeyTemplate: "lecture", KeyLanguage: "fr", KeyProvider: "anthropic",
+			KeyDiarize: "false", KeyOutputDir: "/notes/lectures",
+		}
+		if got := lectures.Values(); !maps.Equal(got, want) {
+			t.Errorf("lectures.Values() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("selects the profile of the environment", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		t.Setenv(EnvProfile, "meetings")
+		writeConfigFile(t, tmpDir, "[profile.meetings]\ntemplate=meeting\n")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.Profile != "meetings" {
+			t.Errorf("Profile = %q, want meetings", cfg.Profile)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{name: "unknown profile key", content: "[profile.meetings]\nmodel=gpt-4o\n", wantErr: ErrInvalidKey},
+		{name: "invalid diarize", content: "[profile.meetings]\ndiarize=sometimes\n", wantErr: ErrInvalidValue},
+		{name: "other section", content: "[server]\nport=8080\n", wantErr: ErrInvalidSyntax},
+		{name: "invalid profile name", content: "[profile.Team Meetings]\ntemplate=meeting\n", wantErr: ErrInvalidSyntax},
+		{name: "unterminated section", content: "[profile.meetings\n", wantErr: ErrInvalidSyntax},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", tmpDir)
+			writeConfigFile(t, tmpDir, tt.content)
+
+			if _, err := Load(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Load() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSave_Profiles(t *testing.T) {
+	// NO t.Parallel() - uses t.Setenv
+
+	t.Run("writes settings into their sections", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+		writeConfigFile(t, tmpDir, "output-dir=/notes\n\n[profile.meetings]\ntemplate=meeting\n")
+
+		for _, kv := range [][2]string{
+			{ProfileKey("meetings", KeyDiarize), "true"},
+			{ProfileKey("lectures", KeyLanguage), "fr"},
+			{"transcriber", "local"},
+		} {
+			if err := Save(kv[0], kv[1]); err != nil {
+				t.Fatalf("Save(%q) unexpected error: %v", kv[0], err)
+			}
+		}
+
+		content, err := os.ReadFile(filepath.Join(tmpDir, "go-transcript", "config"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "output-dir=/notes\ntranscriber=local\n" +
+			"\n[profile.lectures]\nlanguage=fr\n" +
+			"\n[profile.meetings]\ndiarize=true\ntemplate=meeting\n"
+		if string(content) != want {
+			t.Errorf("config file = %q, want %q", content, want)
+		}
+
+		data, err := List()
+		if err != nil {
+			t.Fatalf("List() unexpected error: %v", err)
+		}
+		if data["profile.meetings.template"] != "meeting" || data["output-dir"] != "/notes" {
+			t.Errorf("List() = %v, want the settings read back", data)
+		}
+	})
+
+	t.Run("rejects invalid profile names", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+		if err := Save(ProfileKey("Team]", KeyTemplate), "meeting"); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Save() error = %v, want ErrInvalidKey", err)
+		}
+	})
+}
+
+func TestValidateProfileName(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"meetings", "team-2", "one_on_one"} {
+		if err := ValidateProfileName(name); err != nil {
+			t.Errorf("ValidateProfileName(%q) unexpected error: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "Meetings", "team meetings", "a.b", "x]", strings.Repeat("a", 65)} {
+		if err := ValidateProfileName(name); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ValidateProfileName(%q) error = %v, want ErrInvalidKey", name, err)
+		}
+	}
+}
+
+func TestConfig_WithProfile(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{
+		OutputDir: "/notes",
+		Profiles: map[string]Profile{
+			"lectures": {Name: "lectures", OutputDir: "/notes/lectures"},
+			"meetings": {Name: "meetings", Template: "meeting"},
+		},
+	}
+
+	got, err := cfg.WithProfile("lectures")
+	if err != nil || got.OutputDir != "/notes/lectures" || got.Profile != "lectures" {
+		t.Errorf("WithProfile(lectures) = %q, %q, %v, want the profile output directory", got.OutputDir, got.Profile, err)
+	}
+	got, err = cfg.WithProfile("meetings")
+	if err != nil || got.OutputDir != "/notes" {
+		t.Errorf("WithProfile(meetings) OutputDir = %q, %v, want the configured one", got.OutputDir, err)
+	}
+	if got, err = cfg.WithProfile(""); err != nil || got.Profile != "" {
+		t.Errorf("WithProfile(\"\") = %+v, %v, want cfg unchanged", got, err)
+	}
+
+	_, err = cfg.WithProfile("podcasts")
+	if !errors.Is(err, ErrUnknownProfile) || !strings.Contains(err.Error(), "lectures, meetings") {
+		t.Errorf("WithProfile(podcasts) error = %v, want ErrUnknownProfile listing the profiles", err)
+	}
+}
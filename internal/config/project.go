@@ -0,0 +1,257 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ProjectFile is the project configuration file. Like .editorconfig, it is
+// looked up in the working directory, then in each parent directory.
+const ProjectFile = ".transcript.toml"
+
+// Project file keys, in addition to KeyOutputDir, KeyTemplatesDir, KeyTagsDir
+// and KeyGlossary.
+const (
+	KeyTag   = "tag"
+	KeyVocab = "vocab"
+)
+
+// projectKeys lists the keys allowed in a project file.
+var projectKeys = []string{KeyOutputDir, KeyTemplatesDir, KeyTagsDir, KeyGlossary, KeyTag, KeyVocab}
+
+// Project is the configuration of a project directory, read from its ProjectFile.
+// Set values override the user config and environment variables.
+type Project struct {
+	Path string // The project file
+
+	// OutputDir, TemplatesDir, TagsDir and Glossary are absolute: relative
+	// paths in the file are resolved against its directory.
+	OutputDir    string
+	TemplatesDir string
+	TagsDir      string
+	Glossary     string
+	// Tag is the session tag used when --tag is not given. Validated by the CLI.
+	Tag string
+	// Vocab lists names always put in the transcription prompt.
+	Vocab []string
+}
+
+// FindProject returns the ProjectFile of dir or of its closest parent.
+// Returns "" if there is none.
+func FindProject(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		p := filepath.Join(dir, ProjectFile)
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return p
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// ApplyProject returns cfg overridden by the project file of dir (see FindProject).
+// Returns cfg unchanged if dir is not in a project.
+func ApplyProject(cfg Config, dir string) (Config, error) {
+	p := FindProject(dir)
+	if p == "" {
+		return cfg, nil
+	}
+	project, err := LoadProject(p)
+	if err != nil {
+		return cfg, err
+	}
+	return project.Apply(cfg), nil
+}
+
+// Apply returns cfg with the values set by p.
+func (p Project) Apply(cfg Config) Config {
+	cfg.Project = p.Path
+	if p.OutputDir != "" {
+		cfg.OutputDir = p.OutputDir
+	}
+	if p.TemplatesDir != "" {
+		cfg.TemplatesDir = p.TemplatesDir
+	}
+	if p.TagsDir != "" {
+		cfg.TagsDir = p.TagsDir
+	}
+	if p.Glossary != "" {
+		cfg.Glossary = p.Glossary
+	}
+	if p.Tag != "" {
+		cfg.Tag = p.Tag
+	}
+	if len(p.Vocab) > 0 {
+		cfg.Vocab = p.Vocab
+	}
+	return cfg
+}
+
+// LoadProject reads the project file at path.
+//
+// The file is a subset of TOML: top-level keys set to strings ("..." or
+// '...'), and vocab set to an array of strings, which may span several lines.
+// Unknown keys are rejected with ErrInvalidKey, other errors with ErrInvalidSyntax.
+func LoadProject(path string) (Project, error) {
+	f, err := os.Open(path) // #nosec G304 -- project file found in the working directory tree
+	if err != nil {
+		return Project{}, fmt.Errorf("failed to read project config: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	project := Project{Path: path}
+	dir := filepath.Dir(path)
+	resolve := func(value string) string {
+		value = ExpandPath(value)
+		if value == "" || filepath.IsAbs(value) {
+			return value
+		}
+		return filepath.Join(dir, value)
+	}
+
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(stripTOMLComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return Project{}, fmt.Errorf("%w: %s:%d: %q (want key = \"value\")", ErrInvalidSyntax, path, lineNum, line)
+		}
+		if !slices.Contains(projectKeys, key) {
+			return Project{}, fmt.Errorf("%w: %s:%d: %q (valid keys: %s)",
+				ErrInvalidKey, path, lineNum, key, strings.Join(projectKeys, ", "))
+		}
+
+		if key == KeyVocab {
+			// Arrays may span lines: read up to the closing bracket
+			start := lineNum
+			for !strings.HasSuffix(value, "]") && scanner.Scan() {
+				lineNum++
+				value += " " + strings.TrimSpace(stripTOMLComment(scanner.Text()))
+			}
+			if project.Vocab, err = parseTOMLStringArray(value); err != nil {
+				return Project{}, fmt.Errorf("%w: %s:%d: %s: %v", ErrInvalidSyntax, path, start, key, err)
+			}
+			continue
+		}
+
+		s, err := parseTOMLString(value)
+		if err != nil {
+			return Project{}, fmt.Errorf("%w: %s:%d: %s: %v", ErrInvalidSyntax, path, lineNum, key, err)
+		}
+		switch key {
+		case KeyOutputDir:
+			project.OutputDir = resolve(s)
+		case KeyTemplatesDir:
+			project.TemplatesDir = resolve(s)
+		case KeyTagsDir:
+			project.TagsDir = resolve(s)
+		case KeyGlossary:
+			project.Glossary = resolve(s)
+		case KeyTag:
+			project.Tag = s
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Project{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return project, nil
+}
+
+// stripTOMLComment removes the # comment of line, if any, outside of strings.
+func stripTOMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote == 0 && c == '#':
+			return line[:i]
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == '"' && c == '\\':
+			i++ // Skip the escaped character
+		case c == quote:
+			quote = 0
+		}
+	}
+	return line
+}
+
+// parseTOMLString parses a basic ("...") or literal ('...') TOML string.
+func parseTOMLString(value string) (string, error) {
+	s, rest, err := cutTOMLString(value)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return "", fmt.Errorf("unexpected %q after string", rest)
+	}
+	return s, nil
+}
+
+// cutTOMLString parses the string at the start of value and returns the rest.
+func cutTOMLString(value string) (s, rest string, err error) {
+	if value == "" || (value[0] != '"' && value[0] != '\'') {
+		return "", "", fmt.Errorf("expected a quoted string, got %q", value)
+	}
+	quote := value[0]
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			if quote == '\'' {
+				return value[1:i], value[i+1:], nil
+			}
+			s, err := strconv.Unquote(value[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid string %s", value[:i+1])
+			}
+			return s, value[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string %s", value)
+}
+
+// parseTOMLStringArray parses an array of strings: ["a", 'b',].
+func parseTOMLStringArray(value string) ([]string, error) {
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("expected an array of strings, got %q", value)
+	}
+	rest := strings.TrimSpace(value[1 : len(value)-1])
+	var items []string
+	for rest != "" {
+		s, after, err := cutTOMLString(rest)
+		if err != nil {
+			return nil, err
+		}
+		if s = strings.TrimSpace(s); s != "" {
+			items = append(items, s)
+		}
+		after = strings.TrimSpace(after)
+		if after != "" && after[0] != ',' {
+			return nil, fmt.Errorf("expected ',' between strings, got %q", after)
+		}
+		rest = strings.TrimSpace(strings.TrimPrefix(after, ","))
+	}
+	return items, nil
+}
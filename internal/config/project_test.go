@@ -0,0 +1,182 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeProjectFile creates a project file in dir.
+func writeProjectFile(t *testing.T, dir, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatalf("failed to create project dir: %v", err)
+	}
+	p := filepath.Join(dir, ProjectFile)
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write project file: %v", err)
+	}
+	return p
+}
+
+func TestFindProject(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	project := writeProjectFile(t, filepath.Join(root, "acme"), `tag = "acme"`)
+	nested := filepath.Join(root, "acme", "standups", "2026")
+	if err := os.MkdirAll(nested, 0750); err != nil {
+		t.Fatal(err)
+	}
+	inner := writeProjectFile(t, filepath.Join(root, "acme", "board"), `tag = "board"`)
+
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{"project directory", filepath.Join(root, "acme"), project},
+		{"nested directory", nested, project},
+		{"closest file wins", filepath.Join(inner, ".."), inner},
+		{"outside any project", root, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := FindProject(tt.dir); got != tt.want {
+				t.Errorf("FindProject(%q) = %q, want %q", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProject(t *testing.T) {
+	t.Parallel()
+
+	t.Run("all keys", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		p := writeProjectFile(t, dir, `# Acme standups
+output-dir = "notes"          # relative to this file
+templates-dir = '/srv/templates'
+tags-dir = ".transcript/tags"
+glossary = "glossary.txt"
+tag = "acme-standup"
+vocab = [
+  "Kubernetes", # the platform
+  'Zyzzyva',
+  "Acme \"Cloud\"",
+]
+`)
+
+		got, err := LoadProject(p)
+		if err != nil {
+			t.Fatalf("LoadProject() error = %v", err)
+		}
+		want := Project{
+			Path:         p,
+			OutputDir:    filepath.Join(dir, "notes"),
+			TemplatesDir: "/srv/templates",
+			TagsDir:      filepath.Join(dir, ".transcript", "tags"),
+			Glossary:     filepath.Join(dir, "glossary.txt"),
+			Tag:          "acme-standup",
+			Vocab:        []string{"Kubernetes", "Zyzzyva", `Acme "Cloud"`},
+		}
+		if got.Path != want.Path || got.OutputDir != want.OutputDir || got.TemplatesDir != want.TemplatesDir ||
+			got.TagsDir != want.TagsDir || got.Glossary != want.Glossary || got.Tag != want.Tag ||
+			!slices.Equal(got.Vocab, want.Vocab) {
+			t.Errorf("LoadProject() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("single line array", func(t *testing.T) {
+		t.Parallel()
+
+		p := writeProjectFile(t, t.TempDir(), `vocab = ["Acme", "Zyzzyva"]`)
+		got, err := LoadProject(p)
+		if err != nil {
+			t.Fatalf("LoadProject() error = %v", err)
+		}
+		if !slices.Equal(got.Vocab, []string{"Acme", "Zyzzyva"}) {
+			t.Errorf("Vocab = %q", got.Vocab)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{"unknown key", `transcriber = "local"`, ErrInvalidKey},
+		{"missing value", `tag =`, ErrInvalidSyntax},
+		{"unquoted string", `tag = acme`, ErrInvalidSyntax},
+		{"unterminated string", `tag = "acme`, ErrInvalidSyntax},
+		{"trailing garbage", `tag = "acme" "board"`, ErrInvalidSyntax},
+		{"table", `[project]`, ErrInvalidSyntax},
+		{"vocab not an array", `vocab = "Acme"`, ErrInvalidSyntax},
+		{"missing comma", `vocab = ["Acme" "Zyzzyva"]`, ErrInvalidSyntax},
+		{"unterminated array", "vocab = [\n\"Acme\",", ErrInvalidSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := writeProjectFile(t, t.TempDir(), tt.content)
+			if _, err := LoadProject(p); !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadProject() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyProject(t *testing.T) {
+	t.Parallel()
+
+	user := Config{OutputDir: "/home/me/notes", TemplatesDir: "/home/me/templates", TagsDir: "/home/me/tags"}
+
+	t.Run("project overrides set values only", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		p := writeProjectFile(t, dir, "output-dir = \"notes\"\nvocab = [\"Acme\"]\n")
+
+		cfg, err := ApplyProject(user, dir)
+		if err != nil {
+			t.Fatalf("ApplyProject() error = %v", err)
+		}
+		if cfg.OutputDir != filepath.Join(dir, "notes") || cfg.TemplatesDir != user.TemplatesDir ||
+			cfg.TagsDir != user.TagsDir || cfg.Project != p || !slices.Equal(cfg.Vocab, []string{"Acme"}) {
+			t.Errorf("ApplyProject() = %+v", cfg)
+		}
+	})
+
+	t.Run("outside any project", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := ApplyProject(user, t.TempDir())
+		if err != nil || cfg.OutputDir != user.OutputDir || cfg.Project != "" {
+			t.Errorf("ApplyProject() = %+v, %v; want user config", cfg, err)
+		}
+	})
+
+	t.Run("invalid project file", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeProjectFile(t, dir, "output-dir = notes\n")
+
+		cfg, err := ApplyProject(user, dir)
+		if !errors.Is(err, ErrInvalidSyntax) {
+			t.Errorf("ApplyProject() error = %v, want ErrInvalidSyntax", err)
+		}
+		if cfg.OutputDir != user.OutputDir {
+			t.Errorf("ApplyProject() = %+v, want user config", cfg)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+// Package desktop shows native desktop notifications, so that users who
+// walked away from a long run learn when it is over.
+//
+// Notifications are shown by the tool each platform ships: notify-send on
+// Linux and the BSDs, osascript on macOS, and a PowerShell toast on Windows.
+package desktop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Compile-time interface implementation check.
+var _ Notifier = (*CommandNotifier)(nil)
+
+// AppName is the application name notifications are shown under, where the
+// platform lets the sender choose it.
+const AppName = "transcript"
+
+// notifyTimeout bounds the notification command, which some desktops run
+// synchronously.
+const notifyTimeout = 10 * time.Second
+
+// powerShellAppID is the application user model ID of Windows PowerShell.
+// Windows only shows toasts of registered applications.
+const powerShellAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+// ErrUnsupported indicates desktop notifications cannot be shown on this system.
+var ErrUnsupported = errors.New("desktop notifications not supported")
+
+// Notifier shows desktop notifications.
+type Notifier interface {
+	// Notify shows a notification with title and message.
+	Notify(ctx context.Context, title, message string) error
+}
+
+// commandRunner executes external commands and returns their combined output.
+type commandRunner interface {
+	CombinedOutput(ctx context.Context, name string, args []string) ([]byte, error)
+}
+
+// osCommandRunner implements commandRunner using exec.CommandContext.
+type osCommandRunner struct{}
+
+func (osCommandRunner) CombinedOutput(ctx context.Context, name string, args []string) ([]byte, error) {
+	// #nosec G204 -- name is a fixed tool, args are built here
+	cmd := exec.CommandContext(ctx, name, args...)
+	return cmd.CombinedOutput()
+}
+
+// CommandNotifier implements Notifier with the notification tool of the platform.
+type CommandNotifier struct {
+	goos     string
+	runner   commandRunner
+	lookPath func(file string) (string, error)
+}
+
+// Option configures a CommandNotifier.
+type Option func(*CommandNotifier)
+
+// WithGOOS sets the platform notifications are built for (default: runtime.GOOS).
+func WithGOOS(goos string) Option {
+	return func(n *CommandNotifier) {
+		if goos != "" {
+			n.goos = goos
+		}
+	}
+}
+
+// WithCommandRunner sets the function running the notification command.
+func WithCommandRunner(run func(ctx context.Context, name string, args []string) ([]byte, error)) Option {
+	return func(n *CommandNotifier) {
+		if run != nil {
+			n.runner = commandRunnerFunc(run)
+		}
+	}
+}
+
+// WithLookPath sets the function locating the notification command in PATH.
+func WithLookPath(lookPath func(file string) (string, error)) Option {
+	return func(n *CommandNotifier) {
+		if lookPath != nil {
+			n.lookPath = lookPath
+		}
+	}
+}
+
+// commandRunnerFunc adapts a function to commandRunner.
+type commandRunnerFunc func(ctx context.Context, name string, args []string) ([]byte, error)
+
+func (f commandRunnerFunc) CombinedOutput(ctx context.Context, name string, args []string) ([]byte, error) {
+	return f(ctx, name, args)
+}
+
+// New creates a notifier for the current platform.
+func New(opts ...Option) *CommandNotifier {
+	n := &CommandNotifier{goos: runtime.GOOS, runner: osCommandRunner{}, lookPath: exec.LookPath}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Notify implements Notifier. Returns ErrUnsupported if the platform has no
+// notification tool, or if it is not installed.
+func (n *CommandNotifier) Notify(ctx context.Context, title, message string) error {
+	name, args, err := command(n.goos, title, message)
+	if err != nil {
+		return err
+	}
+	if _, err := n.lookPath(name); err != nil {
+		return fmt.Errorf("%w: %s not found in PATH", ErrUnsupported, name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	if out, err := n.runner.CombinedOutput(ctx, name, args); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s failed: %w: %s", name, err, msg)
+		}
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return nil
+}
+
+// command returns the command showing a notification on goos.
+func command(goos, title, message string) (name string, args []string, err error) {
+	switch goos {
+	case "linux", "freebsd", "openbsd", "netbsd", "dragonfly":
+		return "notify-send", []string{"--app-name=" + AppName, title, message}, nil
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return "osascript", []string{"-e", script}, nil
+	case "windows":
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", toastScript(title, message)}, nil
+	default:
+		return "", nil, fmt.Errorf("%w on %s", ErrUnsupported, goos)
+	}
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// powerShellString quotes s as a verbatim PowerShell string literal.
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// toastScript returns the PowerShell script showing a toast with title and
+// message. The text is set as XML text nodes, so it needs no XML escaping.
+func toastScript(title, message string) string {
+	lines := []string{
+		`$m = [Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime]`,
+		`$xml = $m::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)`,
+		`$text = $xml.GetElementsByTagName('text')`,
+		`$null = $text.Item(0).AppendChild($xml.CreateTextNode(` + powerShellString(title) + `))`,
+		`$null = $text.Item(1).AppendChild($xml.CreateTextNode(` + powerShellString(message) + `))`,
+		`$toast = [Windows.UI.Notifications.ToastNotification]::new($xml)`,
+		`$m::CreateToastNotifier(` + powerShellString(powerShellAppID) + `).Show($toast)`,
+	}
+	return strings.Join(lines, "; ")
+}
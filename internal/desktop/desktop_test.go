@@ -0,0 +1,109 @@
+package desktop_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/desktop"
+)
+
+// recordedCommand is a command run by a notifier.
+type recordedCommand struct {
+	name string
+	args []string
+}
+
+// newTestNotifier returns a notifier for goos recording its commands in cmds,
+// with every tool installed.
+func newTestNotifier(goos string, cmds *[]recordedCommand, opts ...desktop.Option) *desktop.CommandNotifier {
+	opts = append([]desktop.Option{
+		desktop.WithGOOS(goos),
+		desktop.WithLookPath(func(file string) (string, error) { return "/usr/bin/" + file, nil }),
+		desktop.WithCommandRunner(func(_ context.Context, name string, args []string) ([]byte, error) {
+			*cmds = append(*cmds, recordedCommand{name: name, args: args})
+			return nil, nil
+		}),
+	}, opts...)
+	return desktop.New(opts...)
+}
+
+func TestCommandNotifier_Notify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		goos     string
+		wantName string
+		wantArgs []string // Expected in the arguments
+	}{
+		{goos: "linux", wantName: "notify-send", wantArgs: []string{"--app-name=transcript", `Done "talk"`, "It's ready"}},
+		{goos: "freebsd", wantName: "notify-send", wantArgs: []string{`Done "talk"`}},
+		{goos: "darwin", wantName: "osascript", wantArgs: []string{"-e", `display notification "It's ready" with title "Done \"talk\""`}},
+		{goos: "windows", wantName: "powershell", wantArgs: []string{"-NoProfile", `CreateTextNode('Done "talk"')`, `CreateTextNode('It''s ready')`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			t.Parallel()
+
+			var cmds []recordedCommand
+			n := newTestNotifier(tt.goos, &cmds)
+			if err := n.Notify(context.Background(), `Done "talk"`, "It's ready"); err != nil {
+				t.Fatalf("Notify() unexpected error: %v", err)
+			}
+			if len(cmds) != 1 || cmds[0].name != tt.wantName {
+				t.Fatalf("commands = %+v, want one %s", cmds, tt.wantName)
+			}
+			args := strings.Join(cmds[0].args, "\n")
+			for _, want := range tt.wantArgs {
+				if !strings.Contains(args, want) {
+					t.Errorf("args = %q, want containing %q", cmds[0].args, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCommandNotifier_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unsupported platform", func(t *testing.T) {
+		t.Parallel()
+
+		var cmds []recordedCommand
+		err := newTestNotifier("plan9", &cmds).Notify(context.Background(), "title", "message")
+		if !errors.Is(err, desktop.ErrUnsupported) || len(cmds) != 0 {
+			t.Errorf("Notify() error = %v, commands = %+v, want ErrUnsupported and no command", err, cmds)
+		}
+	})
+
+	t.Run("tool not installed", func(t *testing.T) {
+		t.Parallel()
+
+		var cmds []recordedCommand
+		n := newTestNotifier("linux", &cmds, desktop.WithLookPath(func(string) (string, error) {
+			return "", errors.New("not found")
+		}))
+		err := n.Notify(context.Background(), "title", "message")
+		if !errors.Is(err, desktop.ErrUnsupported) || !strings.Contains(err.Error(), "notify-send") {
+			t.Errorf("Notify() error = %v, want ErrUnsupported naming notify-send", err)
+		}
+		if len(cmds) != 0 {
+			t.Errorf("commands = %+v, want none", cmds)
+		}
+	})
+
+	t.Run("command fails", func(t *testing.T) {
+		t.Parallel()
+
+		var cmds []recordedCommand
+		n := newTestNotifier("linux", &cmds, desktop.WithCommandRunner(func(context.Context, string, []string) ([]byte, error) {
+			return []byte("cannot open display\n"), errors.New("exit status 1")
+		}))
+		err := n.Notify(context.Background(), "title", "message")
+		if err == nil || !strings.Contains(err.Error(), "cannot open display") {
+			t.Errorf("Notify() error = %v, want the command output", err)
+		}
+	})
+}
@@ -0,0 +1,12 @@
+package fetch
+
+import "errors"
+
+// ErrDownloadFailed indicates a remote input could not be downloaded.
+var ErrDownloadFailed = errors.New("download failed")
+
+// ErrTooLarge indicates a remote input exceeds the download size limit.
+var ErrTooLarge = errors.New("download too large")
+
+// ErrNoEpisode indicates a podcast feed has no episode with audio.
+var ErrNoEpisode = errors.New("no episode in feed")
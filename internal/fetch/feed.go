@@ -0,0 +1,43 @@
+package fetch
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// rss is the part of an RSS feed naming the audio of its episodes.
+type rss struct {
+	Items []struct {
+		Enclosure struct {
+			URL  string `xml:"url,attr"`
+			Type string `xml:"type,attr"`
+		} `xml:"enclosure"`
+	} `xml:"channel>item"`
+}
+
+// isFeed reports whether contentType is the one of an RSS feed.
+func isFeed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(mediaType, "xml") // application/rss+xml, application/xml, text/xml
+}
+
+// latestEpisode returns the audio URL of the latest episode of the RSS feed
+// read from r: feeds list episodes newest first.
+func latestEpisode(r io.Reader) (string, error) {
+	var feed rss
+	if err := xml.NewDecoder(r).Decode(&feed); err != nil {
+		return "", fmt.Errorf("%w: invalid RSS feed: %v", ErrDownloadFailed, err)
+	}
+	for _, item := range feed.Items {
+		if IsURL(item.Enclosure.URL) {
+			return item.Enclosure.URL, nil
+		}
+	}
+	return "", ErrNoEpisode
+}
@@ -0,0 +1,302 @@
+// Package fetch downloads remote inputs, such as audio files and podcast
+// episodes, so that they can be transcribed like local files.
+//
+// Downloaders are pluggable: HTTPDownloader fetches plain HTTP(S) URLs and
+// the latest episode of podcast RSS feeds; other sources (video platforms)
+// can be supported by another Downloader.
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Compile-time interface implementation check.
+var _ Downloader = (*HTTPDownloader)(nil)
+
+// DefaultMaxSize is the default size limit of a download: 2 GiB, several
+// hours of audio even at high bitrates.
+const DefaultMaxSize = 2 << 30
+
+// progressStep is how many bytes are downloaded between progress reports.
+const progressStep = 1 << 20
+
+// partSuffix is appended to the file name while it is downloaded.
+const partSuffix = ".part"
+
+// maxFeedSize is the size limit of a podcast feed.
+const maxFeedSize = 10 << 20
+
+// Downloader downloads remote inputs.
+type Downloader interface {
+	// Download downloads rawURL into dir and returns the path of the file,
+	// named after the URL. onProgress, if not nil, is called as the download
+	// advances, with the bytes downloaded so far and the size of the file
+	// (0 if unknown). An interrupted download resumes where it stopped when
+	// called again with the same dir.
+	Download(ctx context.Context, rawURL, dir string, onProgress func(done, total int64)) (string, error)
+}
+
+// IsURL reports whether s is an HTTP or HTTPS URL rather than a file path.
+func IsURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// HTTPDownloader implements Downloader over HTTP(S). A URL serving an RSS
+// feed downloads the audio of its latest episode.
+type HTTPDownloader struct {
+	client  *http.Client
+	maxSize int64
+}
+
+// HTTPDownloaderOption configures an HTTPDownloader.
+type HTTPDownloaderOption func(*HTTPDownloader)
+
+// WithHTTPClient sets a custom HTTP client (for testing).
+func WithHTTPClient(c *http.Client) HTTPDownloaderOption {
+	return func(d *HTTPDownloader) {
+		d.client = c
+	}
+}
+
+// WithMaxSize sets the size limit of a download, in bytes.
+// Values <= 0 keep DefaultMaxSize.
+func WithMaxSize(n int64) HTTPDownloaderOption {
+	return func(d *HTTPDownloader) {
+		if n > 0 {
+			d.maxSize = n
+		}
+	}
+}
+
+// NewHTTPDownloader creates an HTTP downloader. The default client has no
+// timeout, as large files take long to download: cancel the context instead.
+func NewHTTPDownloader(opts ...HTTPDownloaderOption) *HTTPDownloader {
+	d := &HTTPDownloader{
+		client:  &http.Client{},
+		maxSize: DefaultMaxSize,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Download downloads rawURL into dir, or the latest episode of the feed it
+// serves. A complete file already in dir is reused without a request.
+func (d *HTTPDownloader) Download(ctx context.Context, rawURL, dir string, onProgress func(done, total int64)) (string, error) {
+	return d.download(ctx, rawURL, dir, onProgress, true)
+}
+
+// download downloads rawURL into dir. followFeed allows one feed to be
+// followed to its episode, not a feed listed as an episode.
+func (d *HTTPDownloader) download(ctx context.Context, rawURL, dir string, onProgress func(done, total int64), followFeed bool) (string, error) {
+	if !IsURL(rawURL) {
+		return "", fmt.Errorf("%w: not an HTTP URL: %s", ErrDownloadFailed, rawURL)
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("cannot create download directory: %w", err)
+	}
+	name := fileName(rawURL)
+	if existing := completeFile(dir, name); existing != "" {
+		return existing, nil
+	}
+
+	part := filepath.Join(dir, name+partSuffix)
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	resp, err := d.get(ctx, rawURL, offset)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+
+	if followFeed && isFeed(resp.Header.Get("Content-Type")) {
+		episode, err := latestEpisode(io.LimitReader(resp.Body, maxFeedSize))
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", rawURL, err)
+		}
+		return d.download(ctx, episode, dir, onProgress, false)
+	}
+
+	var flags int
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		flags = os.O_WRONLY | os.O_APPEND
+	case http.StatusOK:
+		offset, flags = 0, os.O_WRONLY|os.O_CREATE|os.O_TRUNC // Restarted: the server ignored the range
+	default:
+		return "", fmt.Errorf("%w: %s: %s", ErrDownloadFailed, rawURL, resp.Status)
+	}
+	total := contentSize(resp, offset)
+	if total > d.maxSize {
+		return "", fmt.Errorf("%s is %d MB, over the %d MB limit: %w", rawURL, total>>20, d.maxSize>>20, ErrTooLarge)
+	}
+
+	f, err := os.OpenFile(part, flags, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("cannot write download: %w", err)
+	}
+	pw := &progressWriter{w: f, done: offset, total: total, onProgress: onProgress}
+	n, copyErr := io.Copy(pw, io.LimitReader(resp.Body, d.maxSize-offset+1))
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	switch {
+	case offset+n > d.maxSize:
+		_ = os.Remove(part)
+		return "", fmt.Errorf("%s is over the %d MB limit: %w", rawURL, d.maxSize>>20, ErrTooLarge)
+	case copyErr != nil:
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("%w: %s: %v (run again to resume)", ErrDownloadFailed, rawURL, copyErr)
+	case total > 0 && offset+n != total:
+		return "", fmt.Errorf("%w: %s: %d of %d bytes received (run again to resume)", ErrDownloadFailed, rawURL, offset+n, total)
+	}
+	if onProgress != nil {
+		onProgress(offset+n, total)
+	}
+
+	if filepath.Ext(name) == "" {
+		name += extensionOf(resp.Header.Get("Content-Type"))
+	}
+	out := filepath.Join(dir, name)
+	if err := os.Rename(part, out); err != nil {
+		return "", fmt.Errorf("cannot save download: %w", err)
+	}
+	return out, nil
+}
+
+// get requests rawURL, from byte offset if the server supports ranges.
+func (d *HTTPDownloader) get(ctx context.Context, rawURL string, offset int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDownloadFailed, err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDownloadFailed, err)
+	}
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		// The partial file does not match the remote one anymore: start over
+		resp.Body.Close() //nolint:errcheck,gosec // read-only body
+		return d.get(ctx, rawURL, 0)
+	}
+	return resp, nil
+}
+
+// contentSize returns the size of the whole file served by resp, starting at
+// offset for partial content, or 0 if unknown.
+func contentSize(resp *http.Response, offset int64) int64 {
+	if resp.StatusCode == http.StatusPartialContent {
+		// Content-Range: bytes 100-199/200
+		if _, size, ok := strings.Cut(resp.Header.Get("Content-Range"), "/"); ok {
+			if n, err := strconv.ParseInt(size, 10, 64); err == nil {
+				return n
+			}
+		}
+		if resp.ContentLength >= 0 {
+			return offset + resp.ContentLength
+		}
+		return 0
+	}
+	return max(resp.ContentLength, 0)
+}
+
+// fileName returns the name of the file downloaded from rawURL: the last
+// element of its path, or "download" if the path has none.
+func fileName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "download"
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" || strings.HasPrefix(name, ".") {
+		return "download"
+	}
+	return name
+}
+
+// completeFile returns the file name was saved to by a complete download in
+// dir, with the extension added from its content type if any, or "".
+func completeFile(dir, name string) string {
+	candidates := []string{filepath.Join(dir, name)}
+	if filepath.Ext(name) == "" {
+		matches, _ := filepath.Glob(filepath.Join(dir, name+".*"))
+		candidates = append(candidates, matches...)
+	}
+	for _, c := range candidates {
+		if strings.HasSuffix(c, partSuffix) {
+			continue
+		}
+		if info, err := os.Stat(c); err == nil && info.Mode().IsRegular() {
+			return c
+		}
+	}
+	return ""
+}
+
+// audioExtensions maps the content types of audio files to their extension,
+// for URLs without one.
+var audioExtensions = map[string]string{
+	"audio/mpeg":   ".mp3",
+	"audio/mp3":    ".mp3",
+	"audio/mp4":    ".m4a",
+	"audio/x-m4a":  ".m4a",
+	"audio/ogg":    ".ogg",
+	"audio/opus":   ".ogg",
+	"audio/wav":    ".wav",
+	"audio/x-wav":  ".wav",
+	"audio/flac":   ".flac",
+	"audio/x-flac": ".flac",
+	"audio/webm":   ".webm",
+	"video/mp4":    ".mp4",
+	"video/webm":   ".webm",
+}
+
+// extensionOf returns the file extension of contentType, or "".
+func extensionOf(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return audioExtensions[mediaType]
+}
+
+// progressWriter writes to w, reporting progress every progressStep bytes.
+type progressWriter struct {
+	w           io.Writer
+	done, total int64
+	reported    int64
+	onProgress  func(done, total int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.done += int64(n)
+	if p.onProgress != nil && p.done-p.reported >= progressStep {
+		p.reported = p.done
+		p.onProgress(p.done, p.total)
+	}
+	return n, err
+}
@@ -0,0 +1,304 @@
+package fetch_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/fetch"
+)
+
+// testAudio is the content served as audio: 3 MB, so that progress is
+// reported along the way.
+var testAudio = bytes.Repeat([]byte("audio data "), 3<<20/11)
+
+// audioServer serves testAudio at every path, with range support, and records
+// the Range header of each request.
+type audioServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	ranges []string
+}
+
+func newAudioServer(t *testing.T, handler http.HandlerFunc) *audioServer {
+	t.Helper()
+	s := &audioServer{}
+	if handler == nil {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "audio/mpeg")
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(testAudio))
+		}
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.ranges = append(s.ranges, r.Header.Get("Range"))
+		s.mu.Unlock()
+		handler(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// requests returns the Range header of each request received.
+func (s *audioServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.ranges...)
+}
+
+func TestIsURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"https://example.com/episode.mp3", true},
+		{"http://localhost:8080/a.ogg?token=1", true},
+		{"episode.mp3", false},
+		{"/tmp/episode.mp3", false},
+		{"C:\\audio\\episode.mp3", false},
+		{"ftp://example.com/episode.mp3", false},
+		{"https://", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			if got := fetch.IsURL(tt.in); got != tt.want {
+				t.Errorf("IsURL(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPDownloader_Download(t *testing.T) {
+	t.Parallel()
+
+	t.Run("downloads with progress", func(t *testing.T) {
+		t.Parallel()
+
+		server := newAudioServer(t, nil)
+		dir := t.TempDir()
+		var mu sync.Mutex
+		var reports [][2]int64
+		path, err := fetch.NewHTTPDownloader().Download(context.Background(), server.URL+"/shows/episode.mp3?id=7", dir,
+			func(done, total int64) {
+				mu.Lock()
+				reports = append(reports, [2]int64{done, total})
+				mu.Unlock()
+			})
+		if err != nil {
+			t.Fatalf("Download() unexpected error: %v", err)
+		}
+
+		if path != filepath.Join(dir, "episode.mp3") {
+			t.Errorf("Download() = %q, want episode.mp3 in %s", path, dir)
+		}
+		assertContent(t, path, testAudio)
+		size := int64(len(testAudio))
+		if len(reports) < 2 || reports[len(reports)-1] != [2]int64{size, size} {
+			t.Errorf("progress = %v, want several reports ending at %d of %d", reports, size, size)
+		}
+		if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+			t.Errorf("partial file left behind (stat error = %v)", err)
+		}
+	})
+
+	t.Run("resumes a partial download", func(t *testing.T) {
+		t.Parallel()
+
+		server := newAudioServer(t, nil)
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "episode.mp3.part"), testAudio[:1000], 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		path, err := fetch.NewHTTPDownloader().Download(context.Background(), server.URL+"/episode.mp3", dir, nil)
+		if err != nil {
+			t.Fatalf("Download() unexpected error: %v", err)
+		}
+		assertContent(t, path, testAudio)
+		if got := server.requests(); len(got) != 1 || got[0] != "bytes=1000-" {
+			t.Errorf("Range headers = %q, want the download resumed at byte 1000", got)
+		}
+	})
+
+	t.Run("restarts when ranges are not supported", func(t *testing.T) {
+		t.Parallel()
+
+		server := newAudioServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write(testAudio) //nolint:errcheck,gosec // test server
+		})
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "episode.mp3.part"), []byte("stale"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		path, err := fetch.NewHTTPDownloader().Download(context.Background(), server.URL+"/episode.mp3", dir, nil)
+		if err != nil {
+			t.Fatalf("Download() unexpected error: %v", err)
+		}
+		assertContent(t, path, testAudio)
+	})
+
+	t.Run("reuses a complete download", func(t *testing.T) {
+		t.Parallel()
+
+		server := newAudioServer(t, nil)
+		dir := t.TempDir()
+		d := fetch.NewHTTPDownloader()
+		for range 2 {
+			if _, err := d.Download(context.Background(), server.URL+"/episode.mp3", dir, nil); err != nil {
+				t.Fatalf("Download() unexpected error: %v", err)
+			}
+		}
+		if got := server.requests(); len(got) != 1 {
+			t.Errorf("requests = %d, want 1", len(got))
+		}
+	})
+
+	t.Run("extension from content type", func(t *testing.T) {
+		t.Parallel()
+
+		server := newAudioServer(t, nil)
+		dir := t.TempDir()
+		path, err := fetch.NewHTTPDownloader().Download(context.Background(), server.URL+"/media/12345", dir, nil)
+		if err != nil {
+			t.Fatalf("Download() unexpected error: %v", err)
+		}
+		if filepath.Base(path) != "12345.mp3" {
+			t.Errorf("Download() = %q, want 12345.mp3 from audio/mpeg", path)
+		}
+	})
+
+	t.Run("latest episode of a feed", func(t *testing.T) {
+		t.Parallel()
+
+		var server *audioServer
+		server = newAudioServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/feed" {
+				w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+				w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Show</title>` + //nolint:errcheck,gosec // test server
+					`<item><title>New</title><enclosure url="` + server.URL + `/ep2.mp3" type="audio/mpeg" length="1"/></item>` +
+					`<item><title>Old</title><enclosure url="` + server.URL + `/ep1.mp3" type="audio/mpeg" length="1"/></item>` +
+					`</channel></rss>`))
+				return
+			}
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(testAudio))
+		})
+
+		path, err := fetch.NewHTTPDownloader().Download(context.Background(), server.URL+"/feed", t.TempDir(), nil)
+		if err != nil {
+			t.Fatalf("Download() unexpected error: %v", err)
+		}
+		if filepath.Base(path) != "ep2.mp3" {
+			t.Errorf("Download() = %q, want the latest episode ep2.mp3", path)
+		}
+		assertContent(t, path, testAudio)
+	})
+}
+
+func TestHTTPDownloader_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		maxSize int64
+		wantErr error
+	}{
+		{
+			name: "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.NotFound(w, r)
+			},
+			wantErr: fetch.ErrDownloadFailed,
+		},
+		{
+			name:    "content length over the limit",
+			maxSize: 1 << 20,
+			wantErr: fetch.ErrTooLarge,
+		},
+		{
+			name: "unknown length over the limit",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.(http.Flusher).Flush() // Chunked: no Content-Length
+				w.Write(testAudio)       //nolint:errcheck,gosec // test server
+			},
+			maxSize: 1 << 20,
+			wantErr: fetch.ErrTooLarge,
+		},
+		{
+			name: "feed without episode",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/xml")
+				w.Write([]byte(`<rss><channel><item><title>Text only</title></item></channel></rss>`)) //nolint:errcheck,gosec // test server
+			},
+			wantErr: fetch.ErrNoEpisode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newAudioServer(t, tt.handler)
+			dir := t.TempDir()
+			_, err := fetch.NewHTTPDownloader(fetch.WithMaxSize(tt.maxSize)).Download(context.Background(), server.URL+"/episode.mp3", dir, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Download() error = %v, want %v", err, tt.wantErr)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Errorf("files left after error = %v, want none", entries)
+			}
+		})
+	}
+}
+
+func TestHTTPDownloader_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	server := newAudioServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100000000")
+		w.Write(testAudio) //nolint:errcheck,gosec // test server
+		<-r.Context().Done()
+	})
+	dir := t.TempDir()
+
+	// Cancelled once the first megabyte is written
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := fetch.NewHTTPDownloader().Download(ctx, server.URL+"/episode.mp3", dir, func(done, total int64) { cancel() })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Download() error = %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "episode.mp3.part")); err != nil {
+		t.Errorf("partial file not kept for resuming: %v", err)
+	}
+}
+
+// assertContent fails t if the file at path does not hold want.
+func assertContent(t *testing.T, path string, want []byte) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read download: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("download = %d bytes, want the %d bytes served", len(got), len(want))
+	}
+	if strings.HasSuffix(path, ".part") {
+		t.Errorf("download %q still named as partial", path)
+	}
+}
@@ -15,6 +15,12 @@ import (
 // properly (write headers, close container), then waits up to timeout before killing.
 // This approach works cross-platform (Windows/macOS/Linux) unlike SIGTERM.
 func RunGraceful(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration) error {
+	return RunGracefulWithStderr(ctx, ffmpegPath, args, timeout, nil)
+}
+
+// RunGracefulWithStderr is RunGraceful, also streaming FFmpeg stderr to w as it
+// is written, e.g. to follow the progress of a filter. A nil w is ignored.
+func RunGracefulWithStderr(ctx context.Context, ffmpegPath string, args []string, timeout time.Duration, w io.Writer) error {
 	cmd := exec.Command(ffmpegPath, args...)
 
 	// Create stdin pipe for graceful shutdown via 'q' command.
@@ -24,8 +30,12 @@ func RunGraceful(ctx context.Context, ffmpegPath string, args []string, timeout
 	}
 
 	// Capture stderr for error messages (FFmpeg writes most output to stderr).
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+	// Only the tail is kept: a long recording can log for hours.
+	stderr := &tailBuffer{max: maxStderrTail}
+	cmd.Stderr = stderr
+	if w != nil {
+		cmd.Stderr = io.MultiWriter(stderr, w)
+	}
 
 	if err := cmd.Start(); err != nil {
 		_ = stdin.Close() // Clean up pipe on start failure
@@ -72,6 +82,34 @@ func RunGraceful(ctx context.Context, ffmpegPath string, args []string, timeout
 	}
 }
 
+// maxStderrTail bounds the stderr kept for error messages.
+const maxStderrTail = 64 << 10
+
+// tailBuffer is a writer keeping the last max bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+	max int
+}
+
+// Write appends p, dropping the oldest bytes beyond the limit.
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.max; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the bytes kept.
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
+
 // ---------------------------------------------------------------------------
 // Executor - testable FFmpeg execution with dependency injection
 // ---------------------------------------------------------------------------
@@ -216,6 +216,44 @@ func TestVersionChecker_Check(t *testing.T) {
 	}
 }
 
+func TestVersionChecker_Version(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		output  string
+		runErr  error
+		want    string
+		wantErr bool
+	}{
+		{name: "release", output: "ffmpeg version 6.1.1 Copyright (c) 2000-2023\nbuilt with gcc", want: "6.1.1"},
+		{name: "n prefix", output: "ffmpeg version n7.0 Copyright (c) 2000-2024", want: "n7.0"},
+		{name: "unparseable", output: "something unexpected", wantErr: true},
+		{name: "cannot run", runErr: errors.New("exec format error"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			executor := NewExecutor(
+				WithRunOutput(func(ctx context.Context, path string, args []string) (string, error) {
+					return tt.output, tt.runErr
+				}),
+			)
+			checker := NewVersionChecker(WithVersionExecutor(executor))
+
+			got, err := checker.Version(context.Background(), "/usr/bin/ffmpeg")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Version() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Version() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestVersionChecker_Check_RunOutputError(t *testing.T) {
 	t.Parallel()
 
@@ -405,3 +443,34 @@ func TestRunGraceful_Timeout(t *testing.T) {
 		t.Errorf("RunGraceful(%q, %v) did not exit within 3s after timeout", "sleep", []string{"10"})
 	}
 }
+
+func TestRunGracefulWithStderr_StreamsStderr(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on Windows - requires sh")
+	}
+
+	var w strings.Builder
+	args := []string{"-c", "echo level >&2"}
+	if err := RunGracefulWithStderr(context.Background(), "sh", args, time.Second, &w); err != nil {
+		t.Fatalf("RunGracefulWithStderr(%q, %v) unexpected error: %v", "sh", args, err)
+	}
+	if got := w.String(); got != "level\n" {
+		t.Errorf("RunGracefulWithStderr(%q, %v) streamed %q, want %q", "sh", args, got, "level\n")
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	t.Parallel()
+
+	b := &tailBuffer{max: 4}
+	for _, s := range []string{"ab", "cd", "efg"} {
+		if n, err := b.Write([]byte(s)); n != len(s) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v, want %d, nil", s, n, err, len(s))
+		}
+	}
+	if got := b.String(); got != "defg" {
+		t.Errorf("String() = %q, want %q", got, "defg")
+	}
+}
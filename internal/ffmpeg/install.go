@@ -0,0 +1,134 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source tells where the ffmpeg binary in use was found.
+type Source string
+
+// Sources of the ffmpeg binary, in the order they are looked up.
+const (
+	SourceOverride   Source = "ffmpeg-path" // --ffmpeg-path or the ffmpeg-path setting
+	SourceEnv        Source = envFFmpegPath // FFMPEG_PATH environment variable
+	SourceDownloaded Source = "downloaded"  // Downloaded by transcript
+	SourceSystem     Source = "system PATH" // Installed on the system
+)
+
+// Status describes the ffmpeg binary in use and the one downloaded by
+// transcript.
+type Status struct {
+	Path       string // Binary in use, empty if none is installed
+	Source     Source // Where Path was found
+	InstallDir string // Directory of the downloaded binary
+	Installed  string // Version downloaded in InstallDir, empty if none
+	Pinned     string // Version downloads are pinned to
+	Platform   string // Platform of the download, e.g. "linux-amd64"
+	Supported  bool   // Whether a build is published for Platform
+	Proxy      string // Proxy of downloads (password hidden), empty if none
+}
+
+// UpToDate reports whether the downloaded binary is the pinned version.
+func (s Status) UpToDate() bool {
+	return s.Installed == s.Pinned
+}
+
+// PinnedVersion returns the FFmpeg version downloads are pinned to. Each
+// platform build is verified against its SHA-256 checksum.
+func PinnedVersion() string {
+	return ffmpegVersion
+}
+
+// Status returns the ffmpeg binary Resolve would use, and the state of the
+// downloaded one, without downloading anything. An invalid override is
+// returned as an ErrNotFound error with the rest of the status.
+func (r *Resolver) Status() (Status, error) {
+	_, supported := getPlatformInfo(r.goos, r.goarch)
+	st := Status{
+		Pinned:    ffmpegVersion,
+		Platform:  r.goos + "-" + r.goarch,
+		Supported: supported || r.platformInfo != nil,
+		Proxy:     r.proxy(),
+	}
+
+	dir, err := r.installDir()
+	if err != nil {
+		return st, err
+	}
+	st.InstallDir = dir
+	st.Installed = r.installedVersion(dir)
+
+	st.Path, st.Source, err = r.locate()
+	if errors.Is(err, errNotInstalled) {
+		return st, nil
+	}
+	return st, err
+}
+
+// Upgrade downloads the pinned version of ffmpeg into the install directory,
+// unless it is already there. Returns whether it downloaded it. Overrides
+// and the system PATH are ignored: an override still takes precedence over
+// the downloaded binary.
+func (r *Resolver) Upgrade(ctx context.Context) (bool, error) {
+	dir, err := r.installDir()
+	if err != nil {
+		return false, err
+	}
+	if r.isInstalled(dir) {
+		return false, nil
+	}
+	if err := r.downloadAndInstall(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// installedVersion returns the version of the ffmpeg binary downloaded in
+// dir, or an empty string if there is none.
+func (r *Resolver) installedVersion(dir string) string {
+	if _, err := r.reader.Stat(r.binaryPath(dir)); os.IsNotExist(err) {
+		return ""
+	}
+	data, err := r.reader.ReadFile(filepath.Join(dir, versionFileName))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// proxy returns the proxy of HTTPS downloads, as http.ProxyFromEnvironment
+// finds it, with its password hidden. NO_PROXY is not considered.
+func (r *Resolver) proxy() string {
+	for _, key := range []string{"HTTPS_PROXY", "https_proxy"} {
+		v := r.env.Getenv(key)
+		if v == "" {
+			continue
+		}
+		u, err := url.Parse(v)
+		if err != nil || u.Host == "" {
+			// http.ProxyFromEnvironment reads "proxy:3128" as http://proxy:3128.
+			if u, err = url.Parse("http://" + v); err != nil {
+				return v
+			}
+		}
+		return u.Redacted()
+	}
+	return ""
+}
+
+// InstallStatus returns the status of ffmpeg with the default resolver.
+// This is a convenience wrapper around Resolver.Status.
+func InstallStatus() (Status, error) {
+	return getDefaultResolver().Status()
+}
+
+// Upgrade downloads the pinned version of ffmpeg with the default resolver.
+// This is a convenience wrapper around Resolver.Upgrade.
+func Upgrade(ctx context.Context) (bool, error) {
+	return getDefaultResolver().Upgrade(ctx)
+}
@@ -0,0 +1,345 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metadata describes a media file and its first audio stream.
+type Metadata struct {
+	Format       string        // Container format name ("ogg", "mov,mp4,m4a,3gp,3g2,mj2")
+	Duration     time.Duration // Duration of the file
+	Bitrate      int64         // Overall bitrate in bits per second (0: unknown)
+	Codec        string        // Codec of the first audio stream ("opus"), empty if none
+	Channels     int           // Channels of the first audio stream
+	SampleRate   int           // Sample rate of the first audio stream in Hz
+	AudioStreams int           // Number of audio streams
+	Encrypted    bool          // The first audio stream is protected by DRM (FairPlay, Common Encryption)
+}
+
+// probeBinaryName is the base name of the ffprobe binary.
+const probeBinaryName = "ffprobe"
+
+// Environment variable for custom ffprobe path.
+const envFFprobePath = "FFPROBE_PATH"
+
+// LookupProbe returns the ffprobe binary to use along with ffmpegPath:
+// FFPROBE_PATH, the ffprobe installed next to ffmpegPath, then the system PATH.
+// Returns an error if none is installed: the FFmpeg builds we download come
+// without ffprobe.
+func (r *Resolver) LookupProbe(ffmpegPath string) (string, error) {
+	if envPath := r.env.Getenv(envFFprobePath); envPath != "" {
+		if _, err := r.reader.Stat(envPath); err != nil {
+			return "", fmt.Errorf("%s is set to %q but binary not found", envFFprobePath, envPath)
+		}
+		return envPath, nil
+	}
+
+	if ffmpegPath != "" {
+		name := probeBinaryName
+		if strings.EqualFold(filepath.Ext(ffmpegPath), binaryExtWindows) {
+			name += binaryExtWindows
+		}
+		path := filepath.Join(filepath.Dir(ffmpegPath), name)
+		if _, err := r.reader.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	if path, err := r.env.LookPath(probeBinaryName); err == nil {
+		return path, nil
+	}
+	return "", fmt.Errorf("ffprobe: %w", errNotInstalled)
+}
+
+// LookupProbe returns the ffprobe binary to use along with ffmpegPath.
+// This is a convenience wrapper around Resolver.LookupProbe.
+func LookupProbe(ffmpegPath string) (string, error) {
+	return getDefaultResolver().LookupProbe(ffmpegPath)
+}
+
+// ---------------------------------------------------------------------------
+// MetadataProber - media metadata with ffprobe, or FFmpeg as a fallback
+// ---------------------------------------------------------------------------
+
+// probeRunFn runs a command and returns its combined stdout and stderr.
+type probeRunFn func(ctx context.Context, path string, args []string) ([]byte, error)
+
+// MetadataProber reads the metadata of media files. It asks ffprobe for JSON
+// when ffprobe is installed, and otherwise parses the input description FFmpeg
+// prints to stderr, which is meant for humans and changes across versions.
+type MetadataProber struct {
+	ffmpegPath  string
+	ffprobePath string // Empty: ffprobe is not installed
+	run         probeRunFn
+}
+
+// MetadataProberOption configures a MetadataProber.
+type MetadataProberOption func(*MetadataProber)
+
+// WithProbeRun sets the function running ffprobe and FFmpeg (for testing).
+func WithProbeRun(fn probeRunFn) MetadataProberOption {
+	return func(p *MetadataProber) { p.run = fn }
+}
+
+// WithProbePath sets the ffprobe binary, instead of looking it up.
+// An empty path probes with FFmpeg only.
+func WithProbePath(path string) MetadataProberOption {
+	return func(p *MetadataProber) { p.ffprobePath = path }
+}
+
+// NewMetadataProber creates a MetadataProber for the FFmpeg binary at
+// ffmpegPath, using the ffprobe found by LookupProbe if any.
+func NewMetadataProber(ffmpegPath string, opts ...MetadataProberOption) *MetadataProber {
+	p := &MetadataProber{
+		ffmpegPath: ffmpegPath,
+		run:        defaultProbeRun,
+	}
+	p.ffprobePath, _ = LookupProbe(ffmpegPath) // no ffprobe: FFmpeg only
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Probe returns the metadata of the media file at path.
+func (p *MetadataProber) Probe(ctx context.Context, path string) (Metadata, error) {
+	if p.ffprobePath != "" {
+		output, err := p.run(ctx, p.ffprobePath, []string{
+			"-v", "error",
+			"-print_format", "json",
+			"-show_format", "-show_streams",
+			path,
+		})
+		if err == nil {
+			if m, err := ParseProbeJSON(output); err == nil && m.Duration > 0 {
+				return m, nil
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return Metadata{}, err
+		}
+		// Unreadable by ffprobe, or without duration: FFmpeg may still tell.
+	}
+	return p.probeFFmpeg(ctx, path)
+}
+
+// probeFFmpeg reads the metadata of path from the input description of FFmpeg.
+// If the description has no duration (e.g. a recording cut short), the file
+// is decoded to find it.
+func (p *MetadataProber) probeFFmpeg(ctx context.Context, path string) (Metadata, error) {
+	// Without an output, FFmpeg only reads the headers, prints the input
+	// description and exits with an error.
+	output, _ := p.run(ctx, p.ffmpegPath, []string{"-i", path})
+	m := ParseInputDescription(string(output))
+	if m.Duration > 0 {
+		return m, nil
+	}
+
+	output, err := p.run(ctx, p.ffmpegPath, []string{"-i", path, "-f", "null", "-"})
+	if err != nil && len(output) == 0 {
+		// FFmpeg returns non-zero even when it successfully reads the file,
+		// so the output is parsed whenever there is one.
+		return Metadata{}, err
+	}
+	decoded := ParseInputDescription(string(output))
+	if decoded.Duration == 0 {
+		d, ok := lastProgressTime(string(output))
+		if !ok {
+			return Metadata{}, fmt.Errorf("could not parse duration from ffmpeg output")
+		}
+		decoded.Duration = d
+	}
+	return decoded, nil
+}
+
+// verifyDuration is the audio decoded by VerifyAudio.
+const verifyDuration = 10 * time.Second
+
+// VerifyAudio decodes the first seconds of the first audio stream of path,
+// and returns the error of FFmpeg if it cannot: no audio stream, no decoder
+// for its codec, or data too damaged to read.
+func (p *MetadataProber) VerifyAudio(ctx context.Context, path string) error {
+	output, err := p.run(ctx, p.ffmpegPath, []string{
+		"-v", "error",
+		"-i", path,
+		"-map", "0:a:0",
+		"-t", strconv.Itoa(int(verifyDuration.Seconds())),
+		"-f", "null", "-",
+	})
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if msg := lastLine(string(output)); msg != "" {
+		return errors.New(msg)
+	}
+	return err
+}
+
+// lastLine returns the last non-empty line of output, trimmed.
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// defaultProbeRun is the production implementation of probeRunFn.
+func defaultProbeRun(ctx context.Context, path string, args []string) ([]byte, error) {
+	// #nosec G204 -- path is a resolved ffmpeg or ffprobe binary, args are built here
+	cmd := exec.CommandContext(ctx, path, args...)
+	return cmd.CombinedOutput()
+}
+
+// ---------------------------------------------------------------------------
+// Parsers
+// ---------------------------------------------------------------------------
+
+// probeOutput is the part of the JSON output of ffprobe -show_format
+// -show_streams read into Metadata. Numbers of the format are strings.
+type probeOutput struct {
+	Streams []struct {
+		CodecType  string `json:"codec_type"`
+		CodecName  string `json:"codec_name"`
+		CodecTag   string `json:"codec_tag_string"`
+		SampleRate string `json:"sample_rate"`
+		Channels   int    `json:"channels"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+}
+
+// ParseProbeJSON parses the JSON output of ffprobe -show_format -show_streams.
+// Messages printed before the JSON, such as warnings on stderr, are skipped.
+func ParseProbeJSON(data []byte) (Metadata, error) {
+	start := bytes.IndexByte(data, '{')
+	if start < 0 {
+		return Metadata{}, fmt.Errorf("no JSON in ffprobe output")
+	}
+	var out probeOutput
+	if err := json.NewDecoder(bytes.NewReader(data[start:])).Decode(&out); err != nil {
+		return Metadata{}, fmt.Errorf("invalid ffprobe output: %w", err)
+	}
+
+	m := Metadata{Format: out.Format.FormatName}
+	if seconds, err := strconv.ParseFloat(out.Format.Duration, 64); err == nil {
+		m.Duration = time.Duration(math.Round(seconds*1e6)) * time.Microsecond
+	}
+	m.Bitrate, _ = strconv.ParseInt(out.Format.BitRate, 10, 64)
+	for _, s := range out.Streams {
+		if s.CodecType != "audio" {
+			continue
+		}
+		m.AudioStreams++
+		if m.AudioStreams == 1 {
+			m.Codec = s.CodecName
+			m.Channels = s.Channels
+			m.SampleRate, _ = strconv.Atoi(s.SampleRate)
+			m.Encrypted = encryptedCodecTags[s.CodecTag]
+		}
+	}
+	return m, nil
+}
+
+// encryptedCodecTags are the codec tags of audio protected by DRM: FairPlay
+// (iTunes Store purchases, Apple Music downloads) and Common Encryption.
+var encryptedCodecTags = map[string]bool{
+	"drms": true,
+	"enca": true,
+}
+
+// Input description patterns of FFmpeg:
+//
+//	Input #0, ogg, from 'recording.ogg':
+//	  Duration: 00:10:00.02, start: 0.000000, bitrate: 49 kb/s
+//	  Stream #0:0: Audio: opus, 48000 Hz, mono, fltp
+var (
+	inputFormatPattern = regexp.MustCompile(`Input #0, (.+), from `)
+	durationPattern    = regexp.MustCompile(`Duration:\s*(\d+):(\d+):(\d+(?:\.\d+)?)`)
+	bitratePattern     = regexp.MustCompile(`Duration:.*bitrate:\s*(\d+) kb/s`)
+	audioStreamPattern = regexp.MustCompile(`Stream #0:\d+\S*: Audio: (\w+)([^,\n]*), (\d+) Hz, ([^,\n]+)`)
+	codecTagPattern    = regexp.MustCompile(`\((\w+) / 0x[0-9A-Fa-f]+\)`)
+	progressPattern    = regexp.MustCompile(`time=(\d+):(\d+):(\d+(?:\.\d+)?)`)
+)
+
+// ParseInputDescription parses the input description FFmpeg prints to stderr.
+// Fields not found are left zero.
+func ParseInputDescription(output string) Metadata {
+	var m Metadata
+	if match := inputFormatPattern.FindStringSubmatch(output); match != nil {
+		m.Format = match[1]
+	}
+	if match := durationPattern.FindStringSubmatch(output); match != nil {
+		m.Duration = clockDuration(match[1], match[2], match[3])
+	}
+	if match := bitratePattern.FindStringSubmatch(output); match != nil {
+		kbps, _ := strconv.ParseInt(match[1], 10, 64)
+		m.Bitrate = kbps * 1000
+	}
+	streams := audioStreamPattern.FindAllStringSubmatch(output, -1)
+	m.AudioStreams = len(streams)
+	if len(streams) > 0 {
+		m.Codec = streams[0][1]
+		if tag := codecTagPattern.FindStringSubmatch(streams[0][2]); tag != nil {
+			m.Encrypted = encryptedCodecTags[tag[1]]
+		}
+		m.SampleRate, _ = strconv.Atoi(streams[0][3])
+		m.Channels = layoutChannels(streams[0][4])
+	}
+	return m
+}
+
+// layoutChannels returns the number of channels of an FFmpeg channel layout
+// ("mono", "stereo", "5.1", "2 channels"), or 0 if unknown.
+func layoutChannels(layout string) int {
+	layout = strings.TrimSpace(layout)
+	switch layout {
+	case "mono":
+		return 1
+	case "stereo":
+		return 2
+	}
+	if n, ok := strings.CutSuffix(layout, " channels"); ok {
+		channels, _ := strconv.Atoi(n)
+		return channels
+	}
+	// Surround layouts: "5.1" is 5 channels and 1 LFE.
+	var main, lfe int
+	if _, err := fmt.Sscanf(layout, "%d.%d", &main, &lfe); err == nil {
+		return main + lfe
+	}
+	return 0
+}
+
+// lastProgressTime returns the last progress time of FFmpeg stderr
+// ("time=HH:MM:SS.ms"), the duration decoded.
+func lastProgressTime(output string) (time.Duration, bool) {
+	matches := progressPattern.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	last := matches[len(matches)-1]
+	return clockDuration(last[1], last[2], last[3]), true
+}
+
+// clockDuration converts hours, minutes and (fractional) seconds to a duration.
+func clockDuration(hours, minutes, seconds string) time.Duration {
+	h, _ := strconv.Atoi(hours)
+	m, _ := strconv.Atoi(minutes)
+	s, _ := strconv.ParseFloat(seconds, 64)
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(math.Round(s*1000))*time.Millisecond
+}
@@ -0,0 +1,468 @@
+package ffmpeg
+
+// Notes:
+// - Parsers are tested on output captured from ffprobe and FFmpeg 6.1
+// - MetadataProber tests inject the command runner, no binary is run
+// - LookupProbe tests reuse the Resolver mocks of resolve_test.go
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// ParseProbeJSON - ffprobe JSON output
+// ---------------------------------------------------------------------------
+
+const recordingProbeJSON = `{
+    "streams": [
+        {
+            "index": 0,
+            "codec_name": "opus",
+            "codec_type": "audio",
+            "sample_fmt": "fltp",
+            "sample_rate": "48000",
+            "channels": 1,
+            "channel_layout": "mono"
+        }
+    ],
+    "format": {
+        "filename": "recording.ogg",
+        "nb_streams": 1,
+        "format_name": "ogg",
+        "duration": "600.020000",
+        "size": "3751234",
+        "bit_rate": "50015"
+    }
+}`
+
+func TestParseProbeJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("recording", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := ParseProbeJSON([]byte(recordingProbeJSON))
+		if err != nil {
+			t.Fatalf("ParseProbeJSON() error = %v", err)
+		}
+		want := Metadata{
+			Format:       "ogg",
+			Duration:     600*time.Second + 20*time.Millisecond,
+			Bitrate:      50015,
+			Codec:        "opus",
+			Channels:     1,
+			SampleRate:   48000,
+			AudioStreams: 1,
+		}
+		if got != want {
+			t.Errorf("ParseProbeJSON() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("video with two audio tracks", func(t *testing.T) {
+		t.Parallel()
+
+		data := `{"streams": [
+			{"codec_type": "video", "codec_name": "h264"},
+			{"codec_type": "audio", "codec_name": "aac", "sample_rate": "44100", "channels": 2},
+			{"codec_type": "audio", "codec_name": "ac3", "sample_rate": "48000", "channels": 6}
+		], "format": {"format_name": "mov,mp4,m4a,3gp,3g2,mj2", "duration": "12.5"}}`
+
+		got, err := ParseProbeJSON([]byte(data))
+		if err != nil {
+			t.Fatalf("ParseProbeJSON() error = %v", err)
+		}
+		if got.Codec != "aac" || got.Channels != 2 || got.SampleRate != 44100 || got.AudioStreams != 2 {
+			t.Errorf("ParseProbeJSON() = %+v, want the first audio stream of 2", got)
+		}
+		if got.Duration != 12500*time.Millisecond || got.Bitrate != 0 {
+			t.Errorf("ParseProbeJSON() = %+v, want 12.5s at an unknown bitrate", got)
+		}
+	})
+
+	t.Run("protected purchase", func(t *testing.T) {
+		t.Parallel()
+
+		data := `{"streams": [{"codec_type": "audio", "codec_tag_string": "drms", "sample_rate": "44100", "channels": 2}],
+			"format": {"format_name": "mov,mp4,m4a,3gp,3g2,mj2", "duration": "215.3"}}`
+
+		got, err := ParseProbeJSON([]byte(data))
+		if err != nil {
+			t.Fatalf("ParseProbeJSON() error = %v", err)
+		}
+		if !got.Encrypted || got.Codec != "" {
+			t.Errorf("ParseProbeJSON() = %+v, want an encrypted stream without decoder", got)
+		}
+	})
+
+	t.Run("warnings before the JSON", func(t *testing.T) {
+		t.Parallel()
+
+		data := "[ogg @ 0x1] Broken file, keyframe not correctly marked.\n" + recordingProbeJSON
+		got, err := ParseProbeJSON([]byte(data))
+		if err != nil || got.Codec != "opus" {
+			t.Errorf("ParseProbeJSON() = %+v, %v, want the recording", got, err)
+		}
+	})
+
+	t.Run("not JSON", func(t *testing.T) {
+		t.Parallel()
+
+		for _, data := range []string{"", "recording.ogg: Invalid data found when processing input", "{broken"} {
+			if _, err := ParseProbeJSON([]byte(data)); err == nil {
+				t.Errorf("ParseProbeJSON(%q) expected error", data)
+			}
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// ParseInputDescription - FFmpeg stderr
+// ---------------------------------------------------------------------------
+
+func TestParseInputDescription(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		output string
+		want   Metadata
+	}{
+		{
+			name: "recording",
+			output: "Input #0, ogg, from 'recording.ogg':\n" +
+				"  Duration: 00:10:00.02, start: 0.000000, bitrate: 50 kb/s\n" +
+				"  Stream #0:0: Audio: opus, 48000 Hz, mono, fltp\n",
+			want: Metadata{Format: "ogg", Duration: 600*time.Second + 20*time.Millisecond, Bitrate: 50000,
+				Codec: "opus", Channels: 1, SampleRate: 48000, AudioStreams: 1},
+		},
+		{
+			name: "video",
+			output: "Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'talk.mp4':\n" +
+				"  Duration: 01:02:03.45, start: 0.000000, bitrate: 1205 kb/s\n" +
+				"  Stream #0:0[0x1](und): Video: h264 (High) (avc1 / 0x31637661), yuv420p, 1280x720, 1070 kb/s\n" +
+				"  Stream #0:1[0x2](eng): Audio: aac (LC) (mp4a / 0x6134706D), 44100 Hz, stereo, fltp, 128 kb/s\n" +
+				"  Stream #0:2[0x3](eng): Audio: ac3 (ac-3 / 0x332D6361), 48000 Hz, 5.1(side), fltp, 384 kb/s\n",
+			want: Metadata{Format: "mov,mp4,m4a,3gp,3g2,mj2", Duration: time.Hour + 2*time.Minute + 3450*time.Millisecond,
+				Bitrate: 1205000, Codec: "aac", Channels: 2, SampleRate: 44100, AudioStreams: 2},
+		},
+		{
+			name: "protected purchase",
+			output: "Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'song.m4p':\n" +
+				"  Duration: 00:03:35.30, start: 0.000000, bitrate: 270 kb/s\n" +
+				"  Stream #0:0[0x1](eng): Audio: none (drms / 0x736D7264), 44100 Hz, stereo, 256 kb/s (default)\n",
+			want: Metadata{Format: "mov,mp4,m4a,3gp,3g2,mj2", Duration: 215300 * time.Millisecond, Bitrate: 270000,
+				Codec: "none", Channels: 2, SampleRate: 44100, AudioStreams: 1, Encrypted: true},
+		},
+		{
+			name: "unknown duration",
+			output: "Input #0, ogg, from 'cut.ogg':\n" +
+				"  Duration: N/A, start: 0.000000, bitrate: N/A\n" +
+				"  Stream #0:0: Audio: opus, 48000 Hz, 2 channels, fltp\n",
+			want: Metadata{Format: "ogg", Codec: "opus", Channels: 2, SampleRate: 48000, AudioStreams: 1},
+		},
+		{
+			name:   "not a media file",
+			output: "notes.txt: Invalid data found when processing input\n",
+			want:   Metadata{},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := ParseInputDescription(tt.output); got != tt.want {
+			t.Errorf("%s: ParseInputDescription() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutChannels(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]int{
+		"mono":       1,
+		"stereo":     2,
+		"2 channels": 2,
+		"5.1":        6,
+		"5.1(side)":  6,
+		"7.1":        8,
+		"unknown":    0,
+	}
+	for layout, want := range tests {
+		if got := layoutChannels(layout); got != want {
+			t.Errorf("layoutChannels(%q) = %d, want %d", layout, got, want)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// MetadataProber.Probe
+// ---------------------------------------------------------------------------
+
+// probeCall is a command run by MetadataProber.
+type probeCall struct {
+	path string
+	args []string
+}
+
+// recordingRun returns a probeRunFn answering with the given outputs by
+// binary and recording its calls: ffprobe, FFmpeg reading the headers, and
+// FFmpeg decoding the file.
+func recordingRun(calls *[]probeCall, ffprobe, header, decoded string) probeRunFn {
+	return func(ctx context.Context, path string, args []string) ([]byte, error) {
+		*calls = append(*calls, probeCall{path, args})
+		switch {
+		case path == "/usr/bin/ffprobe":
+			if ffprobe == "" {
+				return []byte("error"), errors.New("exit status 1")
+			}
+			return []byte(ffprobe), nil
+		case slices.Contains(args, "null"):
+			return []byte(decoded), errors.New("exit status 1")
+		default:
+			return []byte(header), errors.New("exit status 1") // no output file
+		}
+	}
+}
+
+func TestMetadataProber_Probe(t *testing.T) {
+	t.Parallel()
+
+	header := "Input #0, ogg, from 'recording.ogg':\n" +
+		"  Duration: 00:10:00.02, start: 0.000000, bitrate: 50 kb/s\n" +
+		"  Stream #0:0: Audio: opus, 48000 Hz, mono, fltp\n"
+
+	t.Run("ffprobe", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []probeCall
+		p := NewMetadataProber("/usr/bin/ffmpeg", WithProbePath("/usr/bin/ffprobe"),
+			WithProbeRun(recordingRun(&calls, recordingProbeJSON, header, "")))
+
+		m, err := p.Probe(context.Background(), "recording.ogg")
+		if err != nil {
+			t.Fatalf("Probe() error = %v", err)
+		}
+		if m.Bitrate != 50015 || len(calls) != 1 {
+			t.Errorf("Probe() = %+v with %d calls, want ffprobe metadata only", m, len(calls))
+		}
+		if !slices.Contains(calls[0].args, "json") || calls[0].args[len(calls[0].args)-1] != "recording.ogg" {
+			t.Errorf("ffprobe args = %v, want JSON output of recording.ogg", calls[0].args)
+		}
+	})
+
+	t.Run("ffprobe failure falls back to FFmpeg", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []probeCall
+		p := NewMetadataProber("/usr/bin/ffmpeg", WithProbePath("/usr/bin/ffprobe"),
+			WithProbeRun(recordingRun(&calls, "", header, "")))
+
+		m, err := p.Probe(context.Background(), "recording.ogg")
+		if err != nil {
+			t.Fatalf("Probe() error = %v", err)
+		}
+		if m.Bitrate != 50000 || len(calls) != 2 || calls[1].path != "/usr/bin/ffmpeg" {
+			t.Errorf("Probe() = %+v with calls %v, want FFmpeg metadata", m, calls)
+		}
+	})
+
+	t.Run("without ffprobe, reads the headers only", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []probeCall
+		p := NewMetadataProber("/usr/bin/ffmpeg", WithProbePath(""),
+			WithProbeRun(recordingRun(&calls, "", header, "")))
+
+		m, err := p.Probe(context.Background(), "recording.ogg")
+		if err != nil {
+			t.Fatalf("Probe() error = %v", err)
+		}
+		if m.Duration != 600*time.Second+20*time.Millisecond || len(calls) != 1 {
+			t.Errorf("Probe() = %+v with %d calls, want the headers read once", m, len(calls))
+		}
+	})
+
+	t.Run("decodes when the duration is unknown", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []probeCall
+		cut := "Input #0, ogg, from 'cut.ogg':\n  Duration: N/A, start: 0.000000, bitrate: N/A\n" +
+			"  Stream #0:0: Audio: opus, 48000 Hz, mono, fltp\n"
+		p := NewMetadataProber("/usr/bin/ffmpeg", WithProbePath(""),
+			WithProbeRun(recordingRun(&calls, "", cut, cut+"size=N/A time=00:01:00.00 bitrate=N/A\nsize=N/A time=00:04:30.50 bitrate=N/A\n")))
+
+		m, err := p.Probe(context.Background(), "cut.ogg")
+		if err != nil {
+			t.Fatalf("Probe() error = %v", err)
+		}
+		if m.Duration != 270500*time.Millisecond || m.Codec != "opus" {
+			t.Errorf("Probe() = %+v, want 4m30.5s of opus", m)
+		}
+	})
+
+	t.Run("unreadable file", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []probeCall
+		invalid := "notes.txt: Invalid data found when processing input\n"
+		p := NewMetadataProber("/usr/bin/ffmpeg", WithProbePath(""),
+			WithProbeRun(recordingRun(&calls, "", invalid, invalid)))
+
+		if _, err := p.Probe(context.Background(), "notes.txt"); err == nil {
+			t.Error("Probe() expected error")
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// Resolver.LookupProbe
+// ---------------------------------------------------------------------------
+
+func TestResolverLookupProbe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		envPath    string
+		installed  []string // Files that exist
+		lookPath   string   // ffprobe on the system PATH, if not empty
+		ffmpegPath string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "FFPROBE_PATH",
+			envPath:    "/opt/ffprobe",
+			installed:  []string{"/opt/ffprobe", "/usr/bin/ffprobe"},
+			ffmpegPath: "/usr/bin/ffmpeg",
+			want:       "/opt/ffprobe",
+		},
+		{
+			name:       "FFPROBE_PATH not found",
+			envPath:    "/opt/ffprobe",
+			ffmpegPath: "/usr/bin/ffmpeg",
+			wantErr:    true,
+		},
+		{
+			name:       "next to ffmpeg",
+			installed:  []string{"/opt/ffmpeg/bin/ffprobe"},
+			lookPath:   "/usr/bin/ffprobe",
+			ffmpegPath: "/opt/ffmpeg/bin/ffmpeg",
+			want:       "/opt/ffmpeg/bin/ffprobe",
+		},
+		{
+			name:       "next to ffmpeg on Windows",
+			installed:  []string{"/ffmpeg/ffprobe.exe"},
+			ffmpegPath: "/ffmpeg/ffmpeg.exe",
+			want:       "/ffmpeg/ffprobe.exe",
+		},
+		{
+			name:       "system PATH",
+			lookPath:   "/usr/bin/ffprobe",
+			ffmpegPath: "/cache/bin/ffmpeg",
+			want:       "/usr/bin/ffprobe",
+		},
+		{
+			name:       "not installed",
+			ffmpegPath: "/cache/bin/ffmpeg",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := &mockEnvProvider{
+				getenv: func(key string) string {
+					if key == envFFprobePath {
+						return tt.envPath
+					}
+					return ""
+				},
+				lookPath: func(file string) (string, error) {
+					if file == "ffprobe" && tt.lookPath != "" {
+						return tt.lookPath, nil
+					}
+					return "", errors.New("not found")
+				},
+			}
+			reader := &mockFileReader{
+				stat: func(name string) (os.FileInfo, error) {
+					if slices.Contains(tt.installed, name) {
+						return mockFileInfo{name: name}, nil
+					}
+					return nil, os.ErrNotExist
+				},
+			}
+			resolver := NewResolver(WithEnvProvider(env), WithFileReader(reader))
+
+			got, err := resolver.LookupProbe(tt.ffmpegPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LookupProbe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("LookupProbe() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// MetadataProber.VerifyAudio
+// ---------------------------------------------------------------------------
+
+func TestMetadataProber_VerifyAudio(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		output  string
+		err     error
+		wantErr string
+	}{
+		{name: "decodable"},
+		{
+			name: "corrupt",
+			output: "[ogg @ 0x1] Page at 4096 is missing granule\n" +
+				"broken.ogg: Invalid data found when processing input\n",
+			err:     errors.New("exit status 1"),
+			wantErr: "broken.ogg: Invalid data found when processing input",
+		},
+		{
+			name:    "no output",
+			err:     errors.New("exit status 1"),
+			wantErr: "exit status 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotArgs []string
+			p := NewMetadataProber("/usr/bin/ffmpeg", WithProbePath(""),
+				WithProbeRun(func(ctx context.Context, path string, args []string) ([]byte, error) {
+					gotArgs = args
+					return []byte(tt.output), tt.err
+				}))
+
+			err := p.VerifyAudio(context.Background(), "input.ogg")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("VerifyAudio() error = %v, want nil", err)
+				}
+			} else if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("VerifyAudio() error = %v, want %q", err, tt.wantErr)
+			}
+			if !slices.Contains(gotArgs, "0:a:0") || !slices.Contains(gotArgs, "null") {
+				t.Errorf("ffmpeg args = %v, want the first audio stream decoded to null", gotArgs)
+			}
+		})
+	}
+}
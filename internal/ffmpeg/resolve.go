@@ -4,7 +4,9 @@ import (
 	"compress/gzip"
 	"context"
 	"crypto/sha256"
+	"crypto/tls"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -15,6 +17,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/alnah/go-transcript/internal/appdirs"
 )
 
 // FFmpeg version and download configuration.
@@ -46,14 +50,26 @@ const (
 // Environment variable for custom ffmpeg path.
 const envFFmpegPath = "FFMPEG_PATH"
 
-// defaultHTTPClient is a dedicated HTTP client for FFmpeg downloads with explicit timeouts.
+// downloadTransport is the transport of FFmpeg downloads, with explicit
+// timeouts. Downloads go through the proxy of HTTPS_PROXY, if set.
+var downloadTransport = &http.Transport{
+	Proxy:                 http.ProxyFromEnvironment,
+	DialContext:           (&net.Dialer{Timeout: 30 * time.Second}).DialContext,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ResponseHeaderTimeout: 30 * time.Second,
+}
+
+// defaultHTTPClient is a dedicated HTTP client for FFmpeg downloads.
 var defaultHTTPClient = &http.Client{
-	Timeout: downloadTimeout,
-	Transport: &http.Transport{
-		DialContext:           (&net.Dialer{Timeout: 30 * time.Second}).DialContext,
-		TLSHandshakeTimeout:   10 * time.Second,
-		ResponseHeaderTimeout: 30 * time.Second,
-	},
+	Timeout:   downloadTimeout,
+	Transport: downloadTransport,
+}
+
+// SetTLSConfig sets the TLS configuration of FFmpeg downloads, e.g. to trust a
+// corporate certificate authority (nil: the system defaults).
+// It must be called before any download starts.
+func SetTLSConfig(cfg *tls.Config) {
+	downloadTransport.TLSClientConfig = cfg
 }
 
 // binaryInfo contains download metadata for ffmpeg.
@@ -104,6 +120,7 @@ type Resolver struct {
 	stderr       io.Writer
 	goos         string
 	goarch       string
+	path         string      // Binary set by --ffmpeg-path or ffmpeg-path, before FFMPEG_PATH
 	platformInfo *binaryInfo // Override for testing; nil uses getPlatformInfo
 }
 
@@ -143,6 +160,12 @@ func WithPlatform(goos, goarch string) ResolverOption {
 	}
 }
 
+// WithPath sets the ffmpeg binary to use before any other (--ffmpeg-path or
+// the ffmpeg-path setting). An empty path keeps the lookup order.
+func WithPath(path string) ResolverOption {
+	return func(res *Resolver) { res.path = path }
+}
+
 // WithPlatformInfo overrides the platform download info (for testing downloads).
 func WithPlatformInfo(info binaryInfo) ResolverOption {
 	return func(res *Resolver) {
@@ -169,53 +192,92 @@ func NewResolver(opts ...ResolverOption) *Resolver {
 }
 
 // Resolve finds ffmpeg using the following precedence:
-//  1. FFMPEG_PATH environment variable (error if set but invalid)
-//  2. ~/.go-transcript/bin/ffmpeg (installed by us)
+//  1. The binary of WithPath, then the FFMPEG_PATH environment variable
+//     (error if set but invalid)
+//  2. bin/ffmpeg in the cache directory (installed by us, see appdirs),
+//     or ~/.go-transcript/bin/ffmpeg (installed by earlier versions)
 //  3. System PATH
 //  4. Auto-download if nothing found
 func (r *Resolver) Resolve(ctx context.Context) (string, error) {
-	// 1. Check FFMPEG_PATH environment variable
+	// 1-3. Overrides, install directories, system PATH
+	path, err := r.Lookup()
+	if err == nil || !errors.Is(err, errNotInstalled) {
+		return path, err
+	}
+	dir, err := r.installDir()
+	if err != nil {
+		return "", err
+	}
+
+	// 4. Auto-download
+	fmt.Fprintln(r.stderr, "ffmpeg not found, downloading...")
+	if err := r.downloadAndInstall(ctx); err != nil {
+		return "", fmt.Errorf("%w: auto-download failed: %v\n\n%s",
+			ErrNotFound, err, r.manualInstallInstructions())
+	}
+
+	return r.binaryPath(dir), nil
+}
+
+// errNotInstalled is wrapped by Lookup when no FFmpeg binary is installed.
+var errNotInstalled = errors.New("not installed")
+
+// Lookup returns the ffmpeg binary Resolve would use, without downloading it:
+// the binary of WithPath, FFMPEG_PATH, our install directories, then the
+// system PATH. Returns ErrNotFound if none is installed.
+func (r *Resolver) Lookup() (string, error) {
+	path, _, err := r.locate()
+	return path, err
+}
+
+// locate returns the ffmpeg binary Resolve would use and where it was found.
+func (r *Resolver) locate() (string, Source, error) {
+	// 1. Check the overrides: --ffmpeg-path or ffmpeg-path, then FFMPEG_PATH
+	if r.path != "" {
+		if _, err := r.reader.Stat(r.path); err != nil {
+			return "", SourceOverride, fmt.Errorf("%w: ffmpeg-path is set to %q but binary not found",
+				ErrNotFound, r.path)
+		}
+		return r.path, SourceOverride, nil
+	}
 	if envPath := r.env.Getenv(envFFmpegPath); envPath != "" {
 		if _, err := r.reader.Stat(envPath); err != nil {
-			return "", fmt.Errorf("%w: %s is set to %q but binary not found (unset to enable auto-download)",
+			return "", SourceEnv, fmt.Errorf("%w: %s is set to %q but binary not found (unset to enable auto-download)",
 				ErrNotFound, envFFmpegPath, envPath)
 		}
-		return envPath, nil
+		return envPath, SourceEnv, nil
 	}
 
-	// 2. Check our install directory
-	installed, err := r.isInstalled()
+	// 2. Check our install directory, then the one of earlier versions
+	dir, err := r.installDir()
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
-	if installed {
-		path, _ := r.installedPath()
-		return path, nil
+	home, err := r.env.UserHomeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	for _, d := range []string{dir, filepath.Join(home, ".go-transcript", "bin")} {
+		if r.isInstalled(d) {
+			return r.binaryPath(d), SourceDownloaded, nil
+		}
 	}
 
 	// 3. Check system PATH
 	if path, err := r.env.LookPath("ffmpeg"); err == nil {
-		return path, nil
+		return path, SourceSystem, nil
 	}
-
-	// 4. Auto-download
-	fmt.Fprintln(r.stderr, "ffmpeg not found, downloading...")
-	if err := r.downloadAndInstall(ctx); err != nil {
-		return "", fmt.Errorf("%w: auto-download failed: %v\n\n%s",
-			ErrNotFound, err, r.manualInstallInstructions())
-	}
-
-	path, _ := r.installedPath()
-	return path, nil
+	return "", "", fmt.Errorf("%w: %w", ErrNotFound, errNotInstalled)
 }
 
-// installDir returns the directory where ffmpeg is installed.
+// installDir returns the directory where ffmpeg is installed: bin in the
+// cache directory of the platform (see appdirs).
 func (r *Resolver) installDir() (string, error) {
-	home, err := r.env.UserHomeDir()
+	dirs, err := appdirs.Resolve(r.goos, r.env.Getenv, r.env.UserHomeDir)
 	if err != nil {
-		return "", fmt.Errorf("cannot determine home directory: %w", err)
+		return "", err
 	}
-	return filepath.Join(home, ".go-transcript", "bin"), nil
+	return filepath.Join(dirs.Cache, "bin"), nil
 }
 
 // installedPath returns the path where ffmpeg would be installed.
@@ -224,40 +286,32 @@ func (r *Resolver) installedPath() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	return r.binaryPath(dir), nil
+}
 
+// binaryPath returns the path of the ffmpeg binary installed in dir.
+func (r *Resolver) binaryPath(dir string) string {
 	name := binaryName
 	if r.goos == "windows" {
 		name += binaryExtWindows
 	}
-
-	return filepath.Join(dir, name), nil
+	return filepath.Join(dir, name)
 }
 
-// isInstalled checks if ffmpeg is already installed at the expected location.
+// isInstalled checks if the current ffmpeg version is installed in dir.
 // Note: There is a TOCTOU race between Stat and ReadFile, but this is acceptable
 // because the worst case is a redundant download, which is idempotent.
-func (r *Resolver) isInstalled() (bool, error) {
-	ffmpegPath, err := r.installedPath()
-	if err != nil {
-		return false, err
-	}
-
-	if _, err := r.reader.Stat(ffmpegPath); os.IsNotExist(err) {
-		return false, nil
+func (r *Resolver) isInstalled(dir string) bool {
+	if _, err := r.reader.Stat(r.binaryPath(dir)); os.IsNotExist(err) {
+		return false
 	}
 
 	// Check version file matches current version
-	dir, _ := r.installDir()
-	versionPath := filepath.Join(dir, versionFileName)
-	data, err := r.reader.ReadFile(versionPath)
+	data, err := r.reader.ReadFile(filepath.Join(dir, versionFileName))
 	if err != nil {
-		return false, nil // Version file missing = needs reinstall
+		return false // Version file missing = needs reinstall
 	}
-	if string(data) != ffmpegVersion {
-		return false, nil // Version mismatch = needs upgrade
-	}
-
-	return true, nil
+	return string(data) == ffmpegVersion // Version mismatch = needs upgrade
 }
 
 // downloadAndInstall downloads and installs ffmpeg.
@@ -432,6 +486,24 @@ func Resolve(ctx context.Context) (string, error) {
 	return getDefaultResolver().Resolve(ctx)
 }
 
+// Lookup returns the ffmpeg binary in use, without downloading it.
+// This is a convenience wrapper around Resolver.Lookup.
+func Lookup() (string, error) {
+	return getDefaultResolver().Lookup()
+}
+
+// SetPath sets the ffmpeg binary of the default resolver, used before
+// FFMPEG_PATH and downloads (see WithPath).
+// It must be called before ffmpeg is resolved.
+func SetPath(path string) {
+	getDefaultResolver().path = path
+}
+
+// InstallDir returns the directory where the default resolver installs ffmpeg.
+func InstallDir() (string, error) {
+	return getDefaultResolver().installDir()
+}
+
 // VersionChecker verifies FFmpeg version requirements.
 type VersionChecker struct {
 	executor *Executor
@@ -463,6 +535,21 @@ func NewVersionChecker(opts ...VersionCheckerOption) *VersionChecker {
 	return vc
 }
 
+// Version returns the version of ffmpegPath, as printed by ffmpeg -version
+// ("6.1.1", "n7.0"). Returns an error if ffmpeg cannot run or prints no version.
+func (vc *VersionChecker) Version(ctx context.Context, ffmpegPath string) (string, error) {
+	output, err := vc.executor.RunOutput(ctx, ffmpegPath, []string{"-version"})
+	first, _, _ := strings.Cut(output, "\n")
+	fields := strings.Fields(first)
+	if len(fields) < 3 || fields[0] != "ffmpeg" || fields[1] != "version" {
+		if err != nil {
+			return "", fmt.Errorf("cannot run %s: %w", ffmpegPath, err)
+		}
+		return "", fmt.Errorf("cannot read the version of %s: %q", ffmpegPath, first)
+	}
+	return fields[2], nil
+}
+
 // Check verifies that ffmpeg meets minimum version requirements.
 // Prints a warning to stderr if version is below minimum but doesn't fail.
 // Returns true if version was successfully checked, false if parsing failed.
@@ -495,6 +582,12 @@ func (vc *VersionChecker) Check(ctx context.Context, ffmpegPath string) bool {
 	return true
 }
 
+// Version returns the version of ffmpegPath.
+// This is a convenience wrapper around VersionChecker.Version.
+func Version(ctx context.Context, ffmpegPath string) (string, error) {
+	return NewVersionChecker().Version(ctx, ffmpegPath)
+}
+
 // CheckVersion verifies that ffmpeg meets minimum version requirements.
 // This is a backward-compatible facade for the VersionChecker.Check method.
 func CheckVersion(ctx context.Context, ffmpegPath string) {
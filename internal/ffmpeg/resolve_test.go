@@ -366,6 +366,7 @@ func TestResolverResolveEnvPath(t *testing.T) {
 func TestResolverResolveInstalledPath(t *testing.T) {
 	t.Parallel()
 
+	// Installed by a version before XDG support.
 	homeDir := "/mock/home"
 	installedPath := filepath.Join(homeDir, ".go-transcript", "bin", "ffmpeg")
 	versionPath := filepath.Join(homeDir, ".go-transcript", "bin", ".version")
@@ -406,6 +407,54 @@ func TestResolverResolveInstalledPath(t *testing.T) {
 	}
 }
 
+func TestResolverResolveCacheDir(t *testing.T) {
+	t.Parallel()
+
+	installedPath := filepath.Join("/xdg/cache", "go-transcript", "bin", "ffmpeg")
+	versionPath := filepath.Join("/xdg/cache", "go-transcript", "bin", ".version")
+
+	env := &mockEnvProvider{
+		getenv: func(key string) string {
+			if key == "XDG_CACHE_HOME" {
+				return "/xdg/cache"
+			}
+			return ""
+		},
+		userHomeDir: func() (string, error) { return "/mock/home", nil },
+		lookPath:    func(file string) (string, error) { return "", errors.New("not in PATH") },
+	}
+
+	reader := &mockFileReader{
+		stat: func(name string) (os.FileInfo, error) {
+			if name == installedPath {
+				return mockFileInfo{name: "ffmpeg"}, nil
+			}
+			return nil, os.ErrNotExist
+		},
+		readFile: func(name string) ([]byte, error) {
+			if name == versionPath {
+				return []byte(ffmpegVersion), nil
+			}
+			return nil, os.ErrNotExist
+		},
+	}
+
+	resolver := NewResolver(
+		WithEnvProvider(env),
+		WithFileReader(reader),
+		WithStderr(io.Discard),
+		WithPlatform("linux", "amd64"),
+	)
+
+	got, err := resolver.Resolve(context.Background())
+	if err != nil {
+		t.Fatalf("Resolve() unexpected error: %v", err)
+	}
+	if got != installedPath {
+		t.Errorf("Resolve() = %q, want %q", got, installedPath)
+	}
+}
+
 func TestResolverResolveSystemPath(t *testing.T) {
 	t.Parallel()
 
@@ -443,6 +492,66 @@ func TestResolverResolveSystemPath(t *testing.T) {
 	}
 }
 
+func TestResolverLookup(t *testing.T) {
+	t.Parallel()
+
+	systemFFmpeg := "/usr/local/bin/ffmpeg"
+
+	tests := []struct {
+		name     string
+		lookPath func(string) (string, error)
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "system PATH",
+			lookPath: func(file string) (string, error) { return systemFFmpeg, nil },
+			want:     systemFFmpeg,
+		},
+		{
+			name:     "not installed does not download",
+			lookPath: func(file string) (string, error) { return "", errors.New("not found") },
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := &mockEnvProvider{
+				getenv:      func(key string) string { return "" },
+				userHomeDir: func() (string, error) { return "/mock/home", nil },
+				lookPath:    tt.lookPath,
+			}
+			reader := &mockFileReader{
+				stat: func(name string) (os.FileInfo, error) { return nil, os.ErrNotExist },
+			}
+			var stderr bytes.Buffer
+			resolver := NewResolver(
+				WithEnvProvider(env),
+				WithFileReader(reader),
+				WithStderr(&stderr),
+			)
+
+			got, err := resolver.Lookup()
+			if tt.wantErr {
+				if !errors.Is(err, ErrNotFound) {
+					t.Errorf("Lookup() error = %v, want ErrNotFound", err)
+				}
+			} else if err != nil {
+				t.Fatalf("Lookup() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Lookup() = %q, want %q", got, tt.want)
+			}
+			if stderr.Len() != 0 {
+				t.Errorf("Lookup() printed %q, want nothing", stderr.String())
+			}
+		})
+	}
+}
+
 func TestResolverResolveUnsupportedPlatform(t *testing.T) {
 	t.Parallel()
 
@@ -525,7 +634,7 @@ func TestResolverResolveAutoDownload(t *testing.T) {
 		t.Fatalf("Resolve() unexpected error: %v", err)
 	}
 
-	expectedPath := filepath.Join(tmpDir, ".go-transcript", "bin", "ffmpeg")
+	expectedPath := filepath.Join(tmpDir, ".cache", "go-transcript", "bin", "ffmpeg")
 	if got != expectedPath {
 		t.Errorf("Resolve() = %q, want %q", got, expectedPath)
 	}
@@ -536,7 +645,7 @@ func TestResolverResolveAutoDownload(t *testing.T) {
 	}
 
 	// Verify version file
-	versionPath := filepath.Join(tmpDir, ".go-transcript", "bin", ".version")
+	versionPath := filepath.Join(tmpDir, ".cache", "go-transcript", "bin", ".version")
 	versionData, err := os.ReadFile(versionPath)
 	if err != nil {
 		t.Errorf("Resolve() did not create version file: %v", err)
@@ -2,6 +2,8 @@ package format
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -53,3 +55,30 @@ func Size(bytes int64) string {
 	}
 	return fmt.Sprintf("%d bytes", bytes)
 }
+
+// ParseSize parses a size in bytes, as written by Size: a whole number with an
+// optional unit B, KB, MB or GB (case-insensitive, 1KB = 1024 bytes).
+// Examples: "10MB", "512 KB", "65536"
+func ParseSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		bytes  int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+	number, scale := strings.ToUpper(strings.TrimSpace(s)), int64(1)
+	for _, u := range units {
+		if rest, ok := strings.CutSuffix(number, u.suffix); ok {
+			number, scale = strings.TrimSpace(rest), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 || n > (1<<63-1)/scale {
+		return 0, fmt.Errorf("invalid size %q (e.g., 10MB, 512KB)", s)
+	}
+	return n * scale, nil
+}
@@ -162,6 +162,51 @@ func TestSize(t *testing.T) {
 	}
 }
 
+func TestParseSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{name: "bytes without unit", input: "65536", want: 64 * kb},
+		{name: "bytes with unit", input: "100B", want: 100},
+		{name: "kilobytes", input: "512KB", want: 512 * kb},
+		{name: "megabytes", input: "10MB", want: 10 * mb},
+		{name: "gigabytes", input: "1GB", want: gb},
+		{name: "lowercase unit", input: "25mb", want: 25 * mb},
+		{name: "space before unit", input: "10 MB", want: 10 * mb},
+		{name: "output of Size", input: format.Size(50 * mb), want: 50 * mb},
+		{name: "zero", input: "0", want: 0},
+
+		{name: "empty", input: "", wantErr: true},
+		{name: "unit only", input: "MB", wantErr: true},
+		{name: "negative", input: "-1MB", wantErr: true},
+		{name: "decimal", input: "1.5MB", wantErr: true},
+		{name: "unknown unit", input: "10TB", wantErr: true},
+		{name: "overflow", input: "9999999999999GB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := format.ParseSize(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseSize(%q) = %d, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseSize(%q) = %d, %v; want %d", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Fuzz Tests - Verify functions don't panic on arbitrary inputs
 // ---------------------------------------------------------------------------
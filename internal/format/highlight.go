@@ -0,0 +1,28 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// highlightMark leads the highlight markers of transcripts.
+const highlightMark = "⭐"
+
+// Highlight renders a highlight marker dropped at time at of a recording,
+// as "⭐ HH:MM:SS".
+func Highlight(at time.Duration) string {
+	return highlightMark + " " + clockTimestamp(at)
+}
+
+// ParseHighlight parses a highlight marker rendered by Highlight. The star
+// is optional: "00:42:15" is the same marker.
+func ParseHighlight(s string) (time.Duration, error) {
+	clock := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), highlightMark))
+	var h, m, sec int
+	if n, err := fmt.Sscanf(clock, "%d:%d:%d", &h, &m, &sec); err != nil || n != 3 ||
+		h < 0 || m < 0 || m > 59 || sec < 0 || sec > 59 {
+		return 0, fmt.Errorf("invalid highlight %q (expected HH:MM:SS)", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second, nil
+}
@@ -0,0 +1,68 @@
+package format_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// ---------------------------------------------------------------------------
+// TestHighlight / TestParseHighlight - Highlight markers
+// ---------------------------------------------------------------------------
+
+func TestHighlight(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		at   time.Duration
+		want string
+	}{
+		{0, "⭐ 00:00:00"},
+		{45*time.Second + 900*time.Millisecond, "⭐ 00:00:45"},
+		{42*time.Minute + 15*time.Second, "⭐ 00:42:15"},
+		{2*time.Hour + 3*time.Minute, "⭐ 02:03:00"},
+		{-time.Second, "⭐ 00:00:00"},
+	}
+
+	for _, tt := range tests {
+		if got := format.Highlight(tt.at); got != tt.want {
+			t.Errorf("Highlight(%v) = %q, want %q", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestParseHighlight(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "⭐ 00:42:15", want: 42*time.Minute + 15*time.Second},
+		{input: "  ⭐ 01:00:00\r", want: time.Hour},
+		{input: "00:00:07", want: 7 * time.Second},
+		{input: "⭐", wantErr: true},
+		{input: "42:15", wantErr: true},
+		{input: "00:61:00", wantErr: true},
+		{input: "Highlight", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := format.ParseHighlight(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseHighlight(%q) expected error, got %v", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseHighlight(%q) unexpected error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseHighlight(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
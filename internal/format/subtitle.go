@@ -0,0 +1,149 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Cue is a subtitle: text displayed from Start to End.
+type Cue struct {
+	Start      time.Duration
+	End        time.Duration
+	Speaker    string // Optional, rendered as a label (SRT) or a voice tag (VTT)
+	Text       string
+	Confidence float64 // From 0 to 1; 0 if unknown (see Unclear)
+}
+
+// SRT renders cues as a SubRip (.srt) file.
+func SRT(cues []Cue) string {
+	var b strings.Builder
+	for i, c := range cues {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		text := c.Text
+		if c.Speaker != "" {
+			text = fmt.Sprintf("[%s] %s", c.Speaker, text)
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n", i+1,
+			subtitleTimestamp(c.Start, ','), subtitleTimestamp(c.End, ','), text)
+	}
+	return b.String()
+}
+
+// VTT renders cues as a WebVTT (.vtt) file.
+func VTT(cues []Cue) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	for _, c := range cues {
+		text := c.Text
+		if c.Speaker != "" {
+			text = fmt.Sprintf("<v %s>%s", c.Speaker, text)
+		}
+		fmt.Fprintf(&b, "\n%s --> %s\n%s\n",
+			subtitleTimestamp(c.Start, '.'), subtitleTimestamp(c.End, '.'), text)
+	}
+	return b.String()
+}
+
+// Timed renders cues as a timestamped transcript, for prompts that need to
+// know when things are said: one "[HH:MM:SS] Speaker: text" line per cue,
+// and a "[pause Ns]" line wherever the silence between two cues lasts at
+// least pause.
+func Timed(cues []Cue, pause time.Duration) string {
+	var b strings.Builder
+	for i, c := range cues {
+		if i > 0 {
+			if gap := c.Start - cues[i-1].End; gap >= pause {
+				fmt.Fprintf(&b, "[pause %ds]\n", int(gap.Round(time.Second)/time.Second))
+			}
+		}
+		fmt.Fprintf(&b, "[%s] ", clockTimestamp(c.Start))
+		if c.Speaker != "" {
+			fmt.Fprintf(&b, "%s: ", c.Speaker)
+		}
+		b.WriteString(c.Text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Marked renders cues as transcript paragraphs led by time markers, so readers
+// can jump back into the audio: "**[HH:MM:SS]** text" in Markdown, or
+// "[HH:MM:SS] text" without bold. A marker, at the start of the cue it leads,
+// goes before the first cue past each multiple of interval, or before every
+// cue if interval is zero. Paragraphs also break where the speaker changes,
+// labeled "[Speaker] " as in diarized transcripts.
+func Marked(cues []Cue, interval time.Duration, bold bool) string {
+	var (
+		b    strings.Builder
+		next time.Duration // Time of the next marker
+	)
+	for i, c := range cues {
+		marked := interval <= 0 || c.Start >= next
+		if i > 0 && !marked && c.Speaker == cues[i-1].Speaker {
+			b.WriteString(" " + c.Text)
+			continue
+		}
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		if marked {
+			if bold {
+				fmt.Fprintf(&b, "**[%s]** ", clockTimestamp(c.Start))
+			} else {
+				fmt.Fprintf(&b, "[%s] ", clockTimestamp(c.Start))
+			}
+			if interval > 0 {
+				next = (max(c.Start, 0)/interval + 1) * interval
+			}
+		}
+		if c.Speaker != "" {
+			fmt.Fprintf(&b, "[%s] ", c.Speaker)
+		}
+		b.WriteString(c.Text)
+	}
+	return b.String()
+}
+
+// Dialogue renders cues as the paragraphs of a conversation, one per turn:
+// consecutive cues of the same speaker are joined, labeled "Speaker: text".
+// Cues without a speaker are not labeled.
+func Dialogue(cues []Cue) string {
+	var b strings.Builder
+	for i, c := range cues {
+		if i > 0 && c.Speaker == cues[i-1].Speaker {
+			b.WriteString(" " + c.Text)
+			continue
+		}
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		if c.Speaker != "" {
+			fmt.Fprintf(&b, "%s: ", c.Speaker)
+		}
+		b.WriteString(c.Text)
+	}
+	return b.String()
+}
+
+// subtitleTimestamp formats d as HH:MM:SS followed by sep and milliseconds.
+// SRT separates milliseconds with a comma, WebVTT with a dot.
+func subtitleTimestamp(d time.Duration, sep rune) string {
+	if d < 0 {
+		d = 0
+	}
+	d = d.Round(time.Millisecond)
+	h := d / time.Hour
+	m := (d % time.Hour) / time.Minute
+	s := (d % time.Minute) / time.Second
+	ms := (d % time.Second) / time.Millisecond
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", h, m, s, sep, ms)
+}
+
+// clockTimestamp formats d as HH:MM:SS, truncated to the second.
+func clockTimestamp(d time.Duration) string {
+	d = max(d, 0)
+	return fmt.Sprintf("%02d:%02d:%02d", d/time.Hour, (d%time.Hour)/time.Minute, (d%time.Minute)/time.Second)
+}
@@ -0,0 +1,166 @@
+package format_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// ---------------------------------------------------------------------------
+// TestSRT / TestVTT - Subtitle rendering
+// ---------------------------------------------------------------------------
+
+var subtitleCues = []format.Cue{
+	{Start: 0, End: 2500 * time.Millisecond, Text: "Hello there."},
+	{Start: time.Hour + 2*time.Minute + 3*time.Second + 45*time.Millisecond, End: time.Hour + 2*time.Minute + 5*time.Second, Speaker: "A", Text: "General Kenobi."},
+}
+
+func TestSRT(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cues []format.Cue
+		want string
+	}{
+		{name: "no cues", cues: nil, want: ""},
+		{
+			name: "numbered cues with comma milliseconds",
+			cues: subtitleCues,
+			want: "1\n00:00:00,000 --> 00:00:02,500\nHello there.\n\n" +
+				"2\n01:02:03,045 --> 01:02:05,000\n[A] General Kenobi.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := format.SRT(tt.cues); got != tt.want {
+				t.Errorf("SRT() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVTT(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cues []format.Cue
+		want string
+	}{
+		{name: "no cues", cues: nil, want: "WEBVTT\n"},
+		{
+			name: "header, dot milliseconds and voice tags",
+			cues: subtitleCues,
+			want: "WEBVTT\n\n00:00:00.000 --> 00:00:02.500\nHello there.\n\n" +
+				"01:02:03.045 --> 01:02:05.000\n<v A>General Kenobi.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := format.VTT(tt.cues); got != tt.want {
+				t.Errorf("VTT() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimed(t *testing.T) {
+	t.Parallel()
+
+	cues := []format.Cue{
+		{Start: 0, End: 4 * time.Second, Speaker: "A", Text: "Welcome to the show."},
+		{Start: 4500 * time.Millisecond, End: 9 * time.Second, Speaker: "B", Text: "Thanks for having me."},
+		{Start: time.Hour + 13*time.Second + 900*time.Millisecond, End: time.Hour + 20*time.Second, Text: "Next topic."},
+	}
+	want := "[00:00:00] A: Welcome to the show.\n" +
+		"[00:00:04] B: Thanks for having me.\n" +
+		"[pause 3605s]\n" +
+		"[01:00:13] Next topic.\n"
+
+	if got := format.Timed(cues, 3*time.Second); got != want {
+		t.Errorf("Timed() =\n%s\nwant\n%s", got, want)
+	}
+	if got := format.Timed(nil, time.Second); got != "" {
+		t.Errorf("Timed(nil) = %q, want empty", got)
+	}
+}
+
+func TestMarked(t *testing.T) {
+	t.Parallel()
+
+	cues := []format.Cue{
+		{Start: 0, End: 4 * time.Second, Speaker: "A", Text: "Welcome."},
+		{Start: 4 * time.Second, End: 9 * time.Second, Speaker: "A", Text: "Today, timestamps."},
+		{Start: 9 * time.Second, End: 12 * time.Second, Speaker: "B", Text: "Great."},
+		{Start: 5*time.Minute + 2*time.Second, End: 5*time.Minute + 8*time.Second, Speaker: "B", Text: "Next topic."},
+		{Start: 5*time.Minute + 9*time.Second, End: 5*time.Minute + 12*time.Second, Speaker: "B", Text: "Still B."},
+		{Start: 16 * time.Minute, End: 16*time.Minute + 3*time.Second, Speaker: "B", Text: "Much later."},
+	}
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		bold     bool
+		want     string
+	}{
+		{
+			name:     "every 5 minutes in markdown",
+			interval: 5 * time.Minute,
+			bold:     true,
+			want: "**[00:00:00]** [A] Welcome. Today, timestamps.\n\n" +
+				"[B] Great.\n\n" +
+				"**[00:05:02]** [B] Next topic. Still B.\n\n" +
+				"**[00:16:00]** [B] Much later.",
+		},
+		{
+			name: "every segment in text",
+			want: "[00:00:00] [A] Welcome.\n\n" +
+				"[00:00:04] [A] Today, timestamps.\n\n" +
+				"[00:00:09] [B] Great.\n\n" +
+				"[00:05:02] [B] Next topic.\n\n" +
+				"[00:05:09] [B] Still B.\n\n" +
+				"[00:16:00] [B] Much later.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := format.Marked(cues, tt.interval, tt.bold); got != tt.want {
+				t.Errorf("Marked() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if got := format.Marked(nil, time.Minute, true); got != "" {
+		t.Errorf("Marked(nil) = %q, want empty", got)
+	}
+}
+
+func TestDialogue(t *testing.T) {
+	t.Parallel()
+
+	cues := []format.Cue{
+		{Start: 0, End: 2 * time.Second, Speaker: "Me", Text: "Hi."},
+		{Start: 2 * time.Second, End: 4 * time.Second, Speaker: "Me", Text: "Can you hear me?"},
+		{Start: 4 * time.Second, End: 5 * time.Second, Speaker: "Remote", Text: "Yes."},
+		{Start: 5 * time.Second, End: 7 * time.Second, Speaker: "Me", Text: "Great."},
+		{Start: 7 * time.Second, End: 8 * time.Second, Text: "Unlabeled."},
+	}
+	want := "Me: Hi. Can you hear me?\n\nRemote: Yes.\n\nMe: Great.\n\nUnlabeled."
+	if got := format.Dialogue(cues); got != want {
+		t.Errorf("Dialogue() = %q, want %q", got, want)
+	}
+	if got := format.Dialogue(nil); got != "" {
+		t.Errorf("Dialogue(nil) = %q, want empty", got)
+	}
+}
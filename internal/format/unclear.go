@@ -0,0 +1,41 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unclear renders the cues transcribed with a confidence below threshold as
+// a list, so readers know which passages to check against the audio:
+// "- **[HH:MM:SS]** text (42%)" in Markdown, or "- [HH:MM:SS] text (42%)"
+// without bold, labeled "[Speaker] " as in diarized transcripts. Cues of
+// unknown confidence are left out. Returns "" if no cue is below threshold.
+func Unclear(cues []Cue, threshold float64, bold bool) string {
+	var b strings.Builder
+	for _, c := range cues {
+		if c.Confidence <= 0 || c.Confidence >= threshold {
+			continue
+		}
+		if bold {
+			fmt.Fprintf(&b, "- **[%s]** ", clockTimestamp(c.Start))
+		} else {
+			fmt.Fprintf(&b, "- [%s] ", clockTimestamp(c.Start))
+		}
+		if c.Speaker != "" {
+			fmt.Fprintf(&b, "[%s] ", c.Speaker)
+		}
+		fmt.Fprintf(&b, "%s (%.0f%%)\n", c.Text, c.Confidence*100)
+	}
+	return b.String()
+}
+
+// HasConfidence reports whether any of cues has a known confidence: some
+// transcribers give none.
+func HasConfidence(cues []Cue) bool {
+	for _, c := range cues {
+		if c.Confidence > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package format_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+func TestUnclear(t *testing.T) {
+	t.Parallel()
+
+	cues := []format.Cue{
+		{Start: 0, End: 4 * time.Second, Speaker: "A", Text: "Welcome.", Confidence: 0.95},
+		{Start: 4 * time.Second, End: 9 * time.Second, Speaker: "A", Text: "Kubernetes, sorry, Kubeflow.", Confidence: 0.42},
+		{Start: 9 * time.Second, End: 12 * time.Second, Text: "Unknown.", Confidence: 0},
+		{Start: 5*time.Minute + 2*time.Second, End: 5*time.Minute + 8*time.Second, Text: "Mumbled.", Confidence: 0.3},
+	}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		bold      bool
+		want      string
+	}{
+		{
+			name:      "markdown",
+			threshold: 0.5,
+			bold:      true,
+			want:      "- **[00:00:04]** [A] Kubernetes, sorry, Kubeflow. (42%)\n- **[00:05:02]** Mumbled. (30%)\n",
+		},
+		{
+			name:      "text",
+			threshold: 0.4,
+			want:      "- [00:05:02] Mumbled. (30%)\n",
+		},
+		{
+			name:      "none below",
+			threshold: 0.2,
+			want:      "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := format.Unclear(cues, tt.threshold, tt.bold); got != tt.want {
+				t.Errorf("Unclear() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if !format.HasConfidence(cues) {
+		t.Error("HasConfidence() = false, want true")
+	}
+	if format.HasConfidence(cues[2:3]) {
+		t.Error("HasConfidence(unknown) = true, want false")
+	}
+}
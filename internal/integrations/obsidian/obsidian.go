@@ -0,0 +1,212 @@
+// Package obsidian writes notes into Obsidian vaults: outputs land in a folder
+// of the vault, linked to the daily note of their date and embedding their
+// audio, with tags derived from the restructure template.
+//
+// Only the files of the vault are used (no Obsidian plugin or API): the daily
+// note folder and date format are read from the settings of the Daily notes
+// core plugin, .obsidian/daily-notes.json.
+package obsidian
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/notes"
+)
+
+// ErrNotVault indicates a vault path that is not an Obsidian vault (no
+// .obsidian directory), or a vault folder outside of it.
+var ErrNotVault = errors.New("not an Obsidian vault")
+
+// DefaultFolder is the folder of the vault notes are written to when none is
+// configured.
+const DefaultFolder = "Transcripts"
+
+// configDir is the directory of the vault settings, which marks a vault.
+const configDir = ".obsidian"
+
+// defaultDailyFormat is the date format of daily note names when the Daily
+// notes plugin has none (moment.js syntax).
+const defaultDailyFormat = "YYYY-MM-DD"
+
+// Vault is an Obsidian vault notes are written into. Create it with Open.
+type Vault struct {
+	root        string
+	folder      string
+	dailyFolder string // Folder of daily notes, relative to root (empty: root)
+	dailyFormat string // Name of daily notes, as a Go time layout
+}
+
+// Open returns the vault at root, whose notes are written to folder, a path
+// relative to root (DefaultFolder if empty).
+// Returns ErrNotVault if root has no .obsidian directory, or if folder is not
+// inside the vault.
+func Open(root, folder string) (*Vault, error) {
+	if folder == "" {
+		folder = DefaultFolder
+	}
+	if err := CheckFolder(folder); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(filepath.Join(root, configDir))
+	if err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s has no %s directory (open it as a vault in Obsidian first)", ErrNotVault, root, configDir)
+	}
+
+	v := &Vault{root: root, folder: filepath.Clean(folder), dailyFormat: momentLayout(defaultDailyFormat)}
+	if err := v.readDailyNotes(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// CheckFolder checks that folder, a vault folder, is a relative path inside
+// the vault. Returns ErrNotVault otherwise.
+func CheckFolder(folder string) error {
+	if !filepath.IsLocal(filepath.FromSlash(folder)) {
+		return fmt.Errorf("%w: folder %q must be a path inside the vault, like %s", ErrNotVault, folder, DefaultFolder)
+	}
+	return nil
+}
+
+// dailyNotesSettings are the settings of the Daily notes core plugin.
+type dailyNotesSettings struct {
+	Folder string `json:"folder"`
+	Format string `json:"format"`
+}
+
+// readDailyNotes reads the folder and name format of daily notes. A vault
+// without Daily notes settings uses the defaults of the plugin.
+func (v *Vault) readDailyNotes() error {
+	data, err := os.ReadFile(filepath.Join(v.root, configDir, "daily-notes.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read the daily notes settings of %s: %w", v.root, err)
+	}
+	var settings dailyNotesSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("%w: invalid daily notes settings in %s: %v", ErrNotVault, v.root, err)
+	}
+	v.dailyFolder = strings.Trim(settings.Folder, "/")
+	if settings.Format != "" {
+		v.dailyFormat = momentLayout(settings.Format)
+	}
+	return nil
+}
+
+// Dir returns the directory notes are written to.
+func (v *Vault) Dir() string {
+	return filepath.Join(v.root, v.folder)
+}
+
+// DailyNoteLink returns the wikilink to the daily note of date, like
+// "[[Daily/2026-10-17|2026-10-17]]". The note does not need to exist:
+// Obsidian creates it when the link is followed.
+func (v *Vault) DailyNoteLink(date time.Time) string {
+	name := date.Local().Format(v.dailyFormat)
+	if v.dailyFolder == "" {
+		return "[[" + name + "]]"
+	}
+	return "[[" + v.dailyFolder + "/" + name + "|" + filepath.Base(name) + "]]"
+}
+
+// AudioLink returns the link to the audio file at path: an embed, playable
+// in the note, like "![[Transcripts/standup.ogg]]" when the file is in the
+// vault, else a link opening it outside Obsidian. URLs are linked as is.
+func (v *Vault) AudioLink(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return "[" + filepath.Base(path) + "](<" + path + ">)"
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	if rel, err := filepath.Rel(v.root, abs); err == nil && filepath.IsLocal(rel) {
+		return "![[" + filepath.ToSlash(rel) + "]]"
+	}
+	return "[" + filepath.Base(abs) + "](<file://" + filepath.ToSlash(abs) + ">)"
+}
+
+// Note returns content as a note of the vault dated date: a line linking the
+// daily note, and the audio at audioPath when not empty, are put at the top,
+// after the front matter content may start with.
+func (v *Vault) Note(content string, date time.Time, audioPath string) string {
+	header := "Daily note: " + v.DailyNoteLink(date) + "\n\n"
+	if audioPath != "" {
+		header += v.AudioLink(audioPath) + "\n\n"
+	}
+	front, body := notes.SplitFrontMatter(content)
+	if front != "" {
+		return front + "\n" + header + strings.TrimLeft(body, "\r\n")
+	}
+	return header + content
+}
+
+// Tags returns the tags of a note restructured with template: "transcript",
+// and the template name (none without template). Characters not allowed in
+// tags are replaced by "-".
+func Tags(template string) []string {
+	tags := []string{"transcript"}
+	if template == "" {
+		return tags
+	}
+	tag := strings.Map(func(r rune) rune {
+		switch {
+		case r == '_' || r == '-' || r == '/':
+			return r
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r > 127:
+			return r
+		default:
+			return '-'
+		}
+	}, strings.ToLower(template))
+	if tag != "transcript" {
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// momentTokens maps moment.js date tokens, longest first, to Go layouts.
+var momentTokens = []struct{ moment, layout string }{
+	{"YYYY", "2006"}, {"YY", "06"},
+	{"MMMM", "January"}, {"MMM", "Jan"}, {"MM", "01"}, {"M", "1"},
+	{"dddd", "Monday"}, {"ddd", "Mon"},
+	{"DD", "02"}, {"D", "2"},
+}
+
+// momentLayout converts a moment.js date format, the syntax of Obsidian
+// settings, to a Go time layout. Text in [brackets] is kept as is; other
+// tokens (week numbers, day of year) are not supported and kept as text.
+func momentLayout(format string) string {
+	var b strings.Builder
+	for rest := format; rest != ""; {
+		if rest[0] == '[' {
+			if end := strings.IndexByte(rest, ']'); end > 0 {
+				b.WriteString(rest[1:end])
+				rest = rest[end+1:]
+				continue
+			}
+		}
+		matched := false
+		for _, t := range momentTokens {
+			if strings.HasPrefix(rest, t.moment) {
+				b.WriteString(t.layout)
+				rest = rest[len(t.moment):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(rest[0])
+			rest = rest[1:]
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,182 @@
+package obsidian_test
+
+// Notes:
+// - Black-box testing: all tests use the public API only (obsidian_test package)
+// - Vaults are temp directories with a .obsidian directory, as Obsidian creates
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/integrations/obsidian"
+)
+
+// newVault creates a vault directory, with the daily notes settings given
+// (none if empty).
+func newVault(t *testing.T, dailyNotes string) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".obsidian"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if dailyNotes != "" {
+		if err := os.WriteFile(filepath.Join(root, ".obsidian", "daily-notes.json"), []byte(dailyNotes), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	root := newVault(t, "")
+	v, err := obsidian.Open(root, "")
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	if want := filepath.Join(root, obsidian.DefaultFolder); v.Dir() != want {
+		t.Errorf("Dir() = %q, want %q", v.Dir(), want)
+	}
+
+	v, err = obsidian.Open(root, "Meetings/2026")
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	if want := filepath.Join(root, "Meetings", "2026"); v.Dir() != want {
+		t.Errorf("Dir() = %q, want %q", v.Dir(), want)
+	}
+}
+
+func TestOpen_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		root   string
+		folder string
+	}{
+		{"not a vault", t.TempDir(), ""},
+		{"folder outside the vault", newVault(t, ""), "../notes"},
+		{"absolute folder", newVault(t, ""), "/notes"},
+		{"invalid daily notes settings", newVault(t, "{"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := obsidian.Open(tt.root, tt.folder); !errors.Is(err, obsidian.ErrNotVault) {
+				t.Errorf("Open() error = %v, want ErrNotVault", err)
+			}
+		})
+	}
+}
+
+func TestVault_DailyNoteLink(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 10, 17, 14, 5, 0, 0, time.Local)
+	tests := []struct {
+		name       string
+		dailyNotes string
+		want       string
+	}{
+		{"default settings", "", "[[2026-10-17]]"},
+		{"folder", `{"folder": "Daily/"}`, "[[Daily/2026-10-17|2026-10-17]]"},
+		{"format", `{"format": "dddd D MMMM YYYY"}`, "[[Saturday 17 October 2026]]"},
+		{"format with folders and text", `{"folder": "Journal", "format": "YYYY/MM/[Day] YY.M.DD"}`, "[[Journal/2026/10/Day 26.10.17|Day 26.10.17]]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			v, err := obsidian.Open(newVault(t, tt.dailyNotes), "")
+			if err != nil {
+				t.Fatalf("Open() unexpected error: %v", err)
+			}
+			if got := v.DailyNoteLink(date); got != tt.want {
+				t.Errorf("DailyNoteLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVault_AudioLink(t *testing.T) {
+	t.Parallel()
+
+	root := newVault(t, "")
+	v, err := obsidian.Open(root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "standup.ogg")
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"in the vault", filepath.Join(root, "Transcripts", "standup.ogg"), "![[Transcripts/standup.ogg]]"},
+		{"outside the vault", outside, "[standup.ogg](<file://" + filepath.ToSlash(outside) + ">)"},
+		{"url", "https://example.com/talk.mp3", "[talk.mp3](<https://example.com/talk.mp3>)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := v.AudioLink(tt.path); got != tt.want {
+				t.Errorf("AudioLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVault_Note(t *testing.T) {
+	t.Parallel()
+
+	root := newVault(t, "")
+	v, err := obsidian.Open(root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2026, 10, 17, 14, 5, 0, 0, time.Local)
+	audio := filepath.Join(root, "Transcripts", "standup.ogg")
+
+	tests := []struct {
+		name    string
+		content string
+		audio   string
+		want    string
+	}{
+		{"with audio", "# Standup\n", audio, "Daily note: [[2026-10-17]]\n\n![[Transcripts/standup.ogg]]\n\n# Standup\n"},
+		{"without audio", "# Standup\n", "", "Daily note: [[2026-10-17]]\n\n# Standup\n"},
+		{"after front matter", "---\ntags: [call]\n---\n\n# Standup\n", "", "---\ntags: [call]\n---\n\nDaily note: [[2026-10-17]]\n\n# Standup\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := v.Note(tt.content, date, tt.audio); got != tt.want {
+				t.Errorf("Note() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		template string
+		want     []string
+	}{
+		{"", []string{"transcript"}},
+		{"meeting", []string{"transcript", "meeting"}},
+		{"Weekly Review", []string{"transcript", "weekly-review"}},
+	}
+	for _, tt := range tests {
+		if got := obsidian.Tags(tt.template); !slices.Equal(got, tt.want) {
+			t.Errorf("Tags(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
@@ -172,6 +172,21 @@ func (h *Handler) WasInterrupted() bool {
 	return h.interrupted
 }
 
+// Rearm returns a context derived from parent that is canceled on the next
+// interrupt, which is handled as a first interrupt again. Used once the work
+// stopped by a first Ctrl+C has moved on to a phase that Ctrl+C must also stop,
+// such as restructuring a partial recording.
+func (h *Handler) Rearm(parent context.Context) context.Context {
+	ctx, cancel := context.WithCancel(parent)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.interrupted = false
+	h.firstInterrupt = time.Time{}
+	h.cancelFunc = cancel
+	return ctx
+}
+
 // WaitForDecision waits for the interrupt window and returns the user's intent.
 // If a second Ctrl+C is received within the window, returns Abort.
 // Otherwise, returns Continue after the timeout.
@@ -574,6 +574,53 @@ func TestHandler_Stop(t *testing.T) {
 	h.Stop()
 }
 
+// ---------------------------------------------------------------------------
+// TestHandler_Rearm - Next interrupt cancels the rearmed context
+// ---------------------------------------------------------------------------
+
+func TestHandler_Rearm(t *testing.T) {
+	t.Parallel()
+
+	sigCh := make(chan os.Signal, 2)
+	var exitCode atomic.Int32
+	exitCode.Store(-1)
+	base := time.Now()
+	var now atomic.Int64
+	now.Store(base.UnixNano())
+
+	h, ctx := interrupt.NewHandlerWithOptions(context.Background(), interrupt.Options{
+		SigCh:    sigCh,
+		ExitFunc: func(code int) { exitCode.Store(int32(code)) },
+		NowFunc:  func() time.Time { return time.Unix(0, now.Load()) },
+		Stderr:   &syncBuffer{},
+	})
+	defer h.Stop()
+
+	sigCh <- os.Interrupt
+	<-ctx.Done()
+
+	rearmed := h.Rearm(context.Background())
+	if h.WasInterrupted() {
+		t.Error("WasInterrupted() = true after Rearm(), want false")
+	}
+	if rearmed.Err() != nil {
+		t.Fatalf("Rearm() context already done: %v", rearmed.Err())
+	}
+
+	// Within the window of the first interrupt, but handled as a first one
+	now.Store(base.Add(time.Second).UnixNano())
+	sigCh <- os.Interrupt
+
+	select {
+	case <-rearmed.Done():
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Rearm() context not canceled by the next interrupt")
+	}
+	if got := exitCode.Load(); got != -1 {
+		t.Errorf("exit called with %d, want no exit", got)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // TestHandler_NilSigCh - No listener started
 // ---------------------------------------------------------------------------
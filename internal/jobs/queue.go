@@ -0,0 +1,232 @@
+// Package jobs runs pipeline jobs in the background, a few at a time, and
+// keeps their status for a while so that front ends (bot, server) can
+// report it.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Sentinel errors returned by Submit.
+var (
+	// ErrQueueFull indicates the queue holds as many pending jobs as its capacity.
+	ErrQueueFull = errors.New("job queue is full")
+
+	// ErrClosed indicates the queue stopped running and accepts no more jobs.
+	ErrClosed = errors.New("job queue is closed")
+)
+
+// Status is the state of a job.
+type Status string
+
+// DefaultRetention is how long a queue keeps finished jobs (see WithRetention).
+const DefaultRetention = time.Hour
+
+// Job states, in order.
+const (
+	StatusQueued  Status = "queued"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// Func is the work of a job. ctx is canceled when the queue stops.
+type Func func(ctx context.Context) error
+
+// Job is a snapshot of a submitted job.
+type Job struct {
+	ID        string
+	Status    Status
+	Err       error // Set when Status is StatusFailed
+	Submitted time.Time
+	Started   time.Time // Zero while queued
+	Finished  time.Time // Zero until done or failed
+}
+
+// entry is a pending job.
+type entry struct {
+	id string
+	fn Func
+}
+
+// Queue runs submitted jobs in order with a bounded number of workers.
+// Submit jobs at any time; Run executes them until its context is canceled.
+type Queue struct {
+	now       func() time.Time
+	retention time.Duration
+	pending   chan entry
+
+	mu     sync.Mutex
+	jobs   map[string]*Job
+	closed bool
+}
+
+// Option configures a Queue.
+type Option func(*Queue)
+
+// WithNow sets the clock used for job timestamps (for testing).
+func WithNow(fn func() time.Time) Option {
+	return func(q *Queue) {
+		q.now = fn
+	}
+}
+
+// WithRetention sets how long finished jobs are kept (DefaultRetention by
+// default). Once evicted, Job no longer finds them: without a limit, a
+// long-running queue would keep every job it ever ran.
+func WithRetention(d time.Duration) Option {
+	return func(q *Queue) {
+		q.retention = d
+	}
+}
+
+// New creates a queue holding at most capacity pending jobs (at least 1).
+func New(capacity int, opts ...Option) *Queue {
+	q := &Queue{
+		now:       time.Now,
+		retention: DefaultRetention,
+		pending:   make(chan entry, max(1, capacity)),
+		jobs:      make(map[string]*Job),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// Submit queues fn and returns the ID of its job.
+// Returns ErrQueueFull if capacity jobs are already pending, or ErrClosed
+// once Run has returned.
+func (q *Queue) Submit(fn Func) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return "", ErrClosed
+	}
+	q.evict()
+	id := newID()
+	select {
+	case q.pending <- entry{id: id, fn: fn}:
+	default:
+		return "", ErrQueueFull
+	}
+	q.jobs[id] = &Job{ID: id, Status: StatusQueued, Submitted: q.now()}
+	return id, nil
+}
+
+// Job returns a snapshot of the job with the given ID.
+// Returns false for unknown IDs, and jobs finished longer than the retention ago.
+func (q *Queue) Job(id string) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	if q.expired(job) {
+		delete(q.jobs, id)
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Pending returns the number of jobs waiting for a worker.
+func (q *Queue) Pending() int {
+	return len(q.pending)
+}
+
+// Run executes queued jobs with workers concurrent workers (at least 1)
+// until ctx is canceled, then waits for running jobs to return and closes
+// the queue. Jobs still queued fail with the context error.
+func (q *Queue) Run(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+	for range max(1, workers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case e := <-q.pending:
+					if ctx.Err() != nil {
+						q.finish(e.id, ctx.Err())
+						return
+					}
+					q.start(e.id)
+					q.finish(e.id, e.fn(ctx))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	for {
+		select {
+		case e := <-q.pending:
+			q.setFinished(e.id, ctx.Err())
+		default:
+			return
+		}
+	}
+}
+
+// start marks a job as running.
+func (q *Queue) start(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job := q.jobs[id]
+	job.Status = StatusRunning
+	job.Started = q.now()
+}
+
+// finish marks a job as done, or failed with err.
+func (q *Queue) finish(id string, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.setFinished(id, err)
+}
+
+// setFinished marks a job as done or failed. q.mu must be held.
+func (q *Queue) setFinished(id string, err error) {
+	job := q.jobs[id]
+	job.Finished = q.now()
+	if err != nil {
+		job.Status, job.Err = StatusFailed, err
+		return
+	}
+	job.Status = StatusDone
+}
+
+// evict removes the jobs finished longer than the retention ago.
+// q.mu must be held.
+func (q *Queue) evict() {
+	for id, job := range q.jobs {
+		if q.expired(job) {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+// expired reports whether job finished longer than the retention ago.
+// q.mu must be held.
+func (q *Queue) expired(job *Job) bool {
+	return !job.Finished.IsZero() && q.now().Sub(job.Finished) >= q.retention
+}
+
+// newID returns a random job ID, hard to guess for clients of other jobs.
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b) // crypto/rand.Read never fails
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,171 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/jobs"
+)
+
+// waitFor polls the job until it reaches a final status.
+func waitFor(t *testing.T, q *jobs.Queue, id string) jobs.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, ok := q.Job(id)
+		if !ok {
+			t.Fatalf("Job(%q) not found", id)
+		}
+		if job.Status == jobs.StatusDone || job.Status == jobs.StatusFailed {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %q did not finish", id)
+	return jobs.Job{}
+}
+
+func TestQueue_RunsJobs(t *testing.T) {
+	t.Parallel()
+
+	q := jobs.New(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx, 2)
+
+	errJob := errors.New("job failed")
+	okID, err := q.Submit(func(context.Context) error { return nil })
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+	failID, err := q.Submit(func(context.Context) error { return errJob })
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+
+	if job := waitFor(t, q, okID); job.Status != jobs.StatusDone || job.Err != nil {
+		t.Errorf("ok job = %+v, want done", job)
+	}
+	job := waitFor(t, q, failID)
+	if job.Status != jobs.StatusFailed || !errors.Is(job.Err, errJob) {
+		t.Errorf("failing job = %+v, want failed with %v", job, errJob)
+	}
+	if job.Started.IsZero() || job.Finished.IsZero() {
+		t.Errorf("failing job timestamps not set: %+v", job)
+	}
+	if okID == failID {
+		t.Errorf("job IDs are not unique: %q", okID)
+	}
+}
+
+func TestQueue_Submit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("full queue", func(t *testing.T) {
+		t.Parallel()
+
+		q := jobs.New(1) // Not running: jobs stay pending
+		id, err := q.Submit(func(context.Context) error { return nil })
+		if err != nil {
+			t.Fatalf("Submit() unexpected error: %v", err)
+		}
+		if _, err := q.Submit(func(context.Context) error { return nil }); !errors.Is(err, jobs.ErrQueueFull) {
+			t.Errorf("Submit() error = %v, want ErrQueueFull", err)
+		}
+		if job, _ := q.Job(id); job.Status != jobs.StatusQueued {
+			t.Errorf("Status = %q, want %q", job.Status, jobs.StatusQueued)
+		}
+		if got := q.Pending(); got != 1 {
+			t.Errorf("Pending() = %d, want 1", got)
+		}
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		t.Parallel()
+
+		if _, ok := jobs.New(1).Job("missing"); ok {
+			t.Error("Job(missing) found, want false")
+		}
+	})
+}
+
+func TestQueue_Stop(t *testing.T) {
+	t.Parallel()
+
+	q := jobs.New(10)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+	runningID, _ := q.Submit(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	queuedID, _ := q.Submit(func(context.Context) error { return nil })
+
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx, 1)
+		close(done)
+	}()
+	<-started
+	cancel()
+	<-done
+
+	for _, id := range []string{runningID, queuedID} {
+		if job, _ := q.Job(id); job.Status != jobs.StatusFailed || !errors.Is(job.Err, context.Canceled) {
+			t.Errorf("job %+v, want failed with context.Canceled", job)
+		}
+	}
+	if _, err := q.Submit(func(context.Context) error { return nil }); !errors.Is(err, jobs.ErrClosed) {
+		t.Errorf("Submit() after Run error = %v, want ErrClosed", err)
+	}
+}
+
+func TestQueue_Retention(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu  sync.Mutex
+		now = time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	)
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+
+	q := jobs.New(10, jobs.WithNow(clock), jobs.WithRetention(time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx, 1)
+
+	finishedID, _ := q.Submit(func(context.Context) error { return nil })
+	waitFor(t, q, finishedID)
+	release := make(chan struct{})
+	defer close(release)
+	runningID, _ := q.Submit(func(context.Context) error {
+		<-release
+		return nil
+	})
+
+	advance(59 * time.Minute)
+	if _, ok := q.Job(finishedID); !ok {
+		t.Errorf("Job(%q) evicted before the retention", finishedID)
+	}
+	advance(time.Minute)
+	if _, ok := q.Job(finishedID); ok {
+		t.Errorf("Job(%q) found after the retention, want evicted", finishedID)
+	}
+	if _, ok := q.Job(runningID); !ok {
+		t.Errorf("Job(%q) evicted, want unfinished jobs kept", runningID)
+	}
+}
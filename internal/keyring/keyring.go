@@ -0,0 +1,305 @@
+// Package keyring stores API keys in the credential store of the OS, so that
+// they need not sit in plain text in .env files or shell profiles.
+//
+// Secrets are stored with the tool each platform ships: security for the
+// macOS Keychain, secret-tool for the Secret Service on Linux and the BSDs
+// (GNOME Keyring, KWallet), and PowerShell calling the Credential Manager on
+// Windows. Secrets are passed to these tools on their standard input, never
+// as arguments visible to other users.
+package keyring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Compile-time interface implementation check.
+var _ Keyring = (*CommandKeyring)(nil)
+
+// Service is the name secrets are stored under.
+const Service = "go-transcript"
+
+// commandTimeout bounds the keyring commands. The macOS Keychain may ask the
+// user to allow access first.
+const commandTimeout = 30 * time.Second
+
+// macOSNotFound is the exit status of security when no item matches.
+const macOSNotFound = 44
+
+// windowsNotFound is the exit status of the PowerShell scripts when no
+// credential matches.
+const windowsNotFound = 2
+
+var (
+	// ErrUnavailable indicates the system has no usable keyring: the tool is
+	// not installed, or no keyring service is running (headless sessions).
+	ErrUnavailable = errors.New("keyring unavailable")
+	// ErrNotFound indicates the keyring holds no secret of the name.
+	ErrNotFound = errors.New("secret not found in keyring")
+)
+
+// Keyring stores secrets by name.
+type Keyring interface {
+	// Get returns the secret stored as name.
+	Get(name string) (string, error)
+	// Set stores secret as name, replacing any previous secret of the name.
+	Set(name, secret string) error
+}
+
+// commandRunner executes external commands with stdin as standard input.
+type commandRunner interface {
+	Run(ctx context.Context, name string, args []string, stdin string) (stdout, stderr []byte, err error)
+}
+
+// osCommandRunner implements commandRunner using exec.CommandContext.
+type osCommandRunner struct{}
+
+func (osCommandRunner) Run(ctx context.Context, name string, args []string, stdin string) ([]byte, []byte, error) {
+	// #nosec G204 -- name is a fixed tool, args are built here
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+// commandRunnerFunc adapts a function to commandRunner.
+type commandRunnerFunc func(ctx context.Context, name string, args []string, stdin string) ([]byte, []byte, error)
+
+func (f commandRunnerFunc) Run(ctx context.Context, name string, args []string, stdin string) ([]byte, []byte, error) {
+	return f(ctx, name, args, stdin)
+}
+
+// CommandKeyring stores secrets with the keyring tool of the platform.
+type CommandKeyring struct {
+	goos     string
+	runner   commandRunner
+	lookPath func(file string) (string, error)
+}
+
+// Option configures a CommandKeyring.
+type Option func(*CommandKeyring)
+
+// WithGOOS sets the platform the keyring commands are built for (default: runtime.GOOS).
+func WithGOOS(goos string) Option {
+	return func(k *CommandKeyring) {
+		if goos != "" {
+			k.goos = goos
+		}
+	}
+}
+
+// WithCommandRunner sets the function running the keyring commands.
+func WithCommandRunner(run func(ctx context.Context, name string, args []string, stdin string) (stdout, stderr []byte, err error)) Option {
+	return func(k *CommandKeyring) {
+		if run != nil {
+			k.runner = commandRunnerFunc(run)
+		}
+	}
+}
+
+// WithLookPath sets the function locating the keyring command in PATH.
+func WithLookPath(lookPath func(file string) (string, error)) Option {
+	return func(k *CommandKeyring) {
+		if lookPath != nil {
+			k.lookPath = lookPath
+		}
+	}
+}
+
+// New creates a keyring for the current platform.
+func New(opts ...Option) *CommandKeyring {
+	k := &CommandKeyring{goos: runtime.GOOS, runner: osCommandRunner{}, lookPath: exec.LookPath}
+	for _, opt := range opts {
+		opt(k)
+	}
+	return k
+}
+
+// Get returns the secret stored as name. Returns ErrNotFound if there is
+// none, and ErrUnavailable if the keyring cannot be used.
+func (k *CommandKeyring) Get(name string) (string, error) {
+	tool, args, err := getCommand(k.goos, name)
+	if err != nil {
+		return "", err
+	}
+	stdout, err := k.run(tool, args, "")
+	if err != nil {
+		if k.notFound(err) {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return "", err
+	}
+	secret := strings.TrimRight(string(stdout), "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return secret, nil
+}
+
+// Set stores secret as name, replacing any previous secret of the name.
+// Returns ErrUnavailable if the keyring cannot be used.
+func (k *CommandKeyring) Set(name, secret string) error {
+	if secret == "" || strings.ContainsAny(secret, "\"\\\r\n") {
+		return fmt.Errorf("cannot store %s: the secret is empty or has quotes, backslashes or line breaks", name)
+	}
+	tool, args, stdin, err := setCommand(k.goos, name, secret)
+	if err != nil {
+		return err
+	}
+	_, err = k.run(tool, args, stdin)
+	return err
+}
+
+// commandError is the failure of a keyring command, with its exit status.
+type commandError struct {
+	tool   string
+	status int // -1 if the command did not exit
+	err    error
+	msg    string
+}
+
+func (e *commandError) Error() string {
+	if e.msg != "" {
+		return fmt.Sprintf("%s failed: %v: %s", e.tool, e.err, e.msg)
+	}
+	return fmt.Sprintf("%s failed: %v", e.tool, e.err)
+}
+
+func (e *commandError) Unwrap() []error { return []error{ErrUnavailable, e.err} }
+
+// run runs tool, returning ErrUnavailable if it is not installed, or a
+// commandError wrapping ErrUnavailable if it fails.
+func (k *CommandKeyring) run(tool string, args []string, stdin string) ([]byte, error) {
+	if _, err := k.lookPath(tool); err != nil {
+		return nil, fmt.Errorf("%w: %s not found in PATH", ErrUnavailable, tool)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	stdout, stderr, err := k.runner.Run(ctx, tool, args, stdin)
+	if err != nil {
+		status := -1
+		var exitErr interface{ ExitCode() int }
+		if errors.As(err, &exitErr) {
+			status = exitErr.ExitCode()
+		}
+		return nil, &commandError{tool: tool, status: status, err: err, msg: strings.TrimSpace(string(stderr))}
+	}
+	return stdout, nil
+}
+
+// notFound reports whether err is the failure of a lookup finding nothing.
+// secret-tool exits with 1 and says nothing when no secret matches, but
+// explains its other failures.
+func (k *CommandKeyring) notFound(err error) bool {
+	var cmdErr *commandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+	switch k.goos {
+	case "darwin":
+		return cmdErr.status == macOSNotFound
+	case "windows":
+		return cmdErr.status == windowsNotFound
+	default:
+		return cmdErr.status == 1 && cmdErr.msg == ""
+	}
+}
+
+// getCommand returns the command printing the secret stored as name on goos.
+func getCommand(goos, name string) (tool string, args []string, err error) {
+	switch goos {
+	case "linux", "freebsd", "openbsd", "netbsd", "dragonfly":
+		return "secret-tool", []string{"lookup", "service", Service, "account", name}, nil
+	case "darwin":
+		return "security", []string{"find-generic-password", "-s", Service, "-a", name, "-w"}, nil
+	case "windows":
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", credReadScript(name)}, nil
+	default:
+		return "", nil, fmt.Errorf("%w on %s", ErrUnavailable, goos)
+	}
+}
+
+// setCommand returns the command storing secret as name on goos, and its
+// standard input.
+func setCommand(goos, name, secret string) (tool string, args []string, stdin string, err error) {
+	switch goos {
+	case "linux", "freebsd", "openbsd", "netbsd", "dragonfly":
+		args := []string{"store", "--label=" + Service + " " + name, "service", Service, "account", name}
+		return "secret-tool", args, secret, nil
+	case "darwin":
+		// security -i reads commands from standard input: the secret stays
+		// out of the arguments, which other users can list.
+		cmd := fmt.Sprintf("add-generic-password -U -s %s -a %s -l \"%s %s\" -w \"%s\"\n", Service, name, Service, name, secret)
+		return "security", []string{"-i"}, cmd, nil
+	case "windows":
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", credWriteScript(name)}, secret, nil
+	default:
+		return "", nil, "", fmt.Errorf("%w on %s", ErrUnavailable, goos)
+	}
+}
+
+// credAPI declares the Credential Manager functions of advapi32 for Add-Type.
+const credAPI = `[DllImport("advapi32.dll", EntryPoint = "CredReadW", CharSet = CharSet.Unicode, SetLastError = true)] ` +
+	`public static extern bool CredRead(string target, int type, int flags, out IntPtr cred); ` +
+	`[DllImport("advapi32.dll", EntryPoint = "CredWriteW", CharSet = CharSet.Unicode, SetLastError = true)] ` +
+	`public static extern bool CredWrite(ref CREDENTIAL cred, int flags); ` +
+	`[DllImport("advapi32.dll")] public static extern void CredFree(IntPtr cred); ` +
+	`[StructLayout(LayoutKind.Sequential, CharSet = CharSet.Unicode)] public struct CREDENTIAL { ` +
+	`public int Flags; public int Type; public string TargetName; public string Comment; ` +
+	`public System.Runtime.InteropServices.ComTypes.FILETIME LastWritten; ` +
+	`public int CredentialBlobSize; public IntPtr CredentialBlob; public int Persist; ` +
+	`public int AttributeCount; public IntPtr Attributes; public string TargetAlias; public string UserName; }`
+
+// credTarget returns the Credential Manager target of name.
+func credTarget(name string) string {
+	return Service + ":" + name
+}
+
+// credReadScript returns the PowerShell script printing the generic
+// credential stored as name, exiting with windowsNotFound if there is none.
+func credReadScript(name string) string {
+	lines := []string{
+		`$ErrorActionPreference = 'Stop'`,
+		`Add-Type -Namespace GoTranscript -Name Cred -MemberDefinition '` + credAPI + `'`,
+		`$p = [IntPtr]::Zero`,
+		fmt.Sprintf(`if (-not [GoTranscript.Cred]::CredRead(%s, 1, 0, [ref]$p)) { exit %d }`, powerShellString(credTarget(name)), windowsNotFound),
+		`$c = [Runtime.InteropServices.Marshal]::PtrToStructure($p, [type][GoTranscript.Cred+CREDENTIAL])`,
+		`[Console]::Out.Write([Runtime.InteropServices.Marshal]::PtrToStringUni($c.CredentialBlob, $c.CredentialBlobSize / 2))`,
+		`[GoTranscript.Cred]::CredFree($p)`,
+	}
+	return strings.Join(lines, "; ")
+}
+
+// credWriteScript returns the PowerShell script storing its standard input as
+// the generic credential name, persisted for the local machine.
+func credWriteScript(name string) string {
+	lines := []string{
+		`$ErrorActionPreference = 'Stop'`,
+		`Add-Type -Namespace GoTranscript -Name Cred -MemberDefinition '` + credAPI + `'`,
+		`$s = [Console]::In.ReadToEnd()`,
+		`$c = New-Object GoTranscript.Cred+CREDENTIAL`,
+		`$c.Type = 1`,
+		`$c.TargetName = ` + powerShellString(credTarget(name)),
+		`$c.UserName = ` + powerShellString(name),
+		`$c.Persist = 2`,
+		`$c.CredentialBlobSize = $s.Length * 2`,
+		`$c.CredentialBlob = [Runtime.InteropServices.Marshal]::StringToCoTaskMemUni($s)`,
+		`if (-not [GoTranscript.Cred]::CredWrite([ref]$c, 0)) { exit 1 }`,
+	}
+	return strings.Join(lines, "; ")
+}
+
+// powerShellString quotes s as a verbatim PowerShell string literal.
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,202 @@
+package keyring_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/keyring"
+)
+
+// exitError is a command exiting with a status.
+type exitError int
+
+func (e exitError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+func (e exitError) ExitCode() int { return int(e) }
+
+// recordedCommand is a command run by a keyring.
+type recordedCommand struct {
+	name  string
+	args  []string
+	stdin string
+}
+
+// newTestKeyring returns a keyring for goos recording its commands in cmds,
+// answering them with stdout, stderr and err, with every tool installed.
+func newTestKeyring(goos string, cmds *[]recordedCommand, stdout, stderr string, err error) *keyring.CommandKeyring {
+	return keyring.New(
+		keyring.WithGOOS(goos),
+		keyring.WithLookPath(func(file string) (string, error) { return "/usr/bin/" + file, nil }),
+		keyring.WithCommandRunner(func(_ context.Context, name string, args []string, stdin string) ([]byte, []byte, error) {
+			*cmds = append(*cmds, recordedCommand{name: name, args: args, stdin: stdin})
+			return []byte(stdout), []byte(stderr), err
+		}),
+	)
+}
+
+func TestCommandKeyring_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		goos     string
+		stdout   string
+		wantName string
+		wantArgs []string // Expected in the arguments
+	}{
+		{goos: "linux", stdout: "sk-test", wantName: "secret-tool", wantArgs: []string{"lookup", "service", "go-transcript", "account", "OPENAI_API_KEY"}},
+		{goos: "darwin", stdout: "sk-test\n", wantName: "security", wantArgs: []string{"find-generic-password", "-s", "go-transcript", "-a", "OPENAI_API_KEY", "-w"}},
+		{goos: "windows", stdout: "sk-test", wantName: "powershell", wantArgs: []string{"-NoProfile", "CredRead('go-transcript:OPENAI_API_KEY', 1, 0, [ref]$p)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			t.Parallel()
+
+			var cmds []recordedCommand
+			k := newTestKeyring(tt.goos, &cmds, tt.stdout, "", nil)
+			got, err := k.Get("OPENAI_API_KEY")
+			if err != nil {
+				t.Fatalf("Get() unexpected error: %v", err)
+			}
+			if got != "sk-test" {
+				t.Errorf("Get() = %q, want %q", got, "sk-test")
+			}
+			if len(cmds) != 1 || cmds[0].name != tt.wantName {
+				t.Fatalf("commands = %+v, want one %s", cmds, tt.wantName)
+			}
+			args := strings.Join(cmds[0].args, "\n")
+			for _, want := range tt.wantArgs {
+				if !strings.Contains(args, want) {
+					t.Errorf("args = %q, want containing %q", args, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCommandKeyring_GetErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		goos    string
+		stdout  string
+		stderr  string
+		err     error
+		wantErr error
+	}{
+		{name: "macOS item not found", goos: "darwin", err: exitError(44), wantErr: keyring.ErrNotFound},
+		{name: "macOS keychain locked", goos: "darwin", stderr: "User interaction is not allowed.", err: exitError(36), wantErr: keyring.ErrUnavailable},
+		{name: "Linux secret not found", goos: "linux", err: exitError(1), wantErr: keyring.ErrNotFound},
+		{name: "Linux empty secret", goos: "linux", stdout: "", wantErr: keyring.ErrNotFound},
+		{name: "Linux no secret service", goos: "linux", stderr: "Cannot autolaunch D-Bus without X11 $DISPLAY", err: exitError(1), wantErr: keyring.ErrUnavailable},
+		{name: "Windows credential not found", goos: "windows", err: exitError(2), wantErr: keyring.ErrNotFound},
+		{name: "Windows failure", goos: "windows", err: exitError(1), wantErr: keyring.ErrUnavailable},
+		{name: "unsupported platform", goos: "plan9", wantErr: keyring.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cmds []recordedCommand
+			k := newTestKeyring(tt.goos, &cmds, tt.stdout, tt.stderr, tt.err)
+			_, err := k.Get("DEEPSEEK_API_KEY")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.stderr != "" && !strings.Contains(err.Error(), tt.stderr) {
+				t.Errorf("Get() error = %q, want the message of the tool", err)
+			}
+		})
+	}
+}
+
+func TestCommandKeyring_ToolMissing(t *testing.T) {
+	t.Parallel()
+
+	k := keyring.New(
+		keyring.WithGOOS("linux"),
+		keyring.WithLookPath(func(string) (string, error) { return "", errors.New("not found") }),
+	)
+	if _, err := k.Get("OPENAI_API_KEY"); !errors.Is(err, keyring.ErrUnavailable) || !strings.Contains(err.Error(), "secret-tool") {
+		t.Errorf("Get() error = %v, want ErrUnavailable naming secret-tool", err)
+	}
+	if err := k.Set("OPENAI_API_KEY", "sk-test"); !errors.Is(err, keyring.ErrUnavailable) {
+		t.Errorf("Set() error = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestCommandKeyring_Set(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		goos      string
+		wantName  string
+		wantArgs  []string // Expected in the arguments
+		wantStdin string
+	}{
+		{
+			goos:      "linux",
+			wantName:  "secret-tool",
+			wantArgs:  []string{"store", "--label=go-transcript OPENAI_API_KEY", "service", "go-transcript", "account", "OPENAI_API_KEY"},
+			wantStdin: "sk-test",
+		},
+		{
+			goos:      "darwin",
+			wantName:  "security",
+			wantArgs:  []string{"-i"},
+			wantStdin: "add-generic-password -U -s go-transcript -a OPENAI_API_KEY -l \"go-transcript OPENAI_API_KEY\" -w \"sk-test\"\n",
+		},
+		{
+			goos:      "windows",
+			wantName:  "powershell",
+			wantArgs:  []string{"$c.TargetName = 'go-transcript:OPENAI_API_KEY'", "[Console]::In.ReadToEnd()"},
+			wantStdin: "sk-test",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			t.Parallel()
+
+			var cmds []recordedCommand
+			k := newTestKeyring(tt.goos, &cmds, "", "", nil)
+			if err := k.Set("OPENAI_API_KEY", "sk-test"); err != nil {
+				t.Fatalf("Set() unexpected error: %v", err)
+			}
+			if len(cmds) != 1 || cmds[0].name != tt.wantName {
+				t.Fatalf("commands = %+v, want one %s", cmds, tt.wantName)
+			}
+			args := strings.Join(cmds[0].args, "\n")
+			for _, want := range tt.wantArgs {
+				if !strings.Contains(args, want) {
+					t.Errorf("args = %q, want containing %q", args, want)
+				}
+			}
+			if strings.Contains(args, "sk-test") {
+				t.Errorf("args = %q, want the secret kept out of the arguments", args)
+			}
+			if cmds[0].stdin != tt.wantStdin {
+				t.Errorf("stdin = %q, want %q", cmds[0].stdin, tt.wantStdin)
+			}
+		})
+	}
+}
+
+func TestCommandKeyring_SetInvalidSecret(t *testing.T) {
+	t.Parallel()
+
+	for _, secret := range []string{"", `sk-"test`, `sk-\test`, "sk-test\n-w other"} {
+		var cmds []recordedCommand
+		k := newTestKeyring("darwin", &cmds, "", "", nil)
+		if err := k.Set("OPENAI_API_KEY", secret); err == nil {
+			t.Errorf("Set(%q) expected error, got nil", secret)
+		}
+		if len(cmds) != 0 {
+			t.Errorf("Set(%q) ran %+v, want nothing run", secret, cmds)
+		}
+	}
+}
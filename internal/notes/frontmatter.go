@@ -0,0 +1,146 @@
+package notes
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// FrontMatter describes a transcript in the YAML front matter put at the top
+// of Markdown outputs, read by note-taking apps to sort and query notes.
+// Zero fields are left out.
+type FrontMatter struct {
+	Date     time.Time     // Start of the recording or of the run
+	Duration time.Duration // Length of the audio
+	Language string        // Language code of the transcript
+	Template string        // Restructure template
+	Tags     []string      // Note tags, like "transcript"
+	Audio    string        // Path of the audio file
+	Speakers []string      // Speaker labels, see Speakers
+}
+
+// Markdown returns the front matter block: "key: value" lines between "---"
+// lines, followed by a blank line. Dates are in local time, like
+// "2026-10-17T14:05", the date and time format of Obsidian properties.
+func (m FrontMatter) Markdown() string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	if !m.Date.IsZero() {
+		b.WriteString("date: " + m.Date.Local().Format("2006-01-02T15:04") + "\n")
+	}
+	if m.Duration > 0 {
+		b.WriteString("duration: " + yamlString(format.Duration(m.Duration)) + "\n")
+	}
+	if m.Language != "" {
+		b.WriteString("language: " + yamlString(m.Language) + "\n")
+	}
+	if m.Template != "" {
+		b.WriteString("template: " + yamlString(m.Template) + "\n")
+	}
+	if len(m.Tags) > 0 {
+		b.WriteString("tags:\n")
+		for _, t := range m.Tags {
+			b.WriteString("  - " + yamlString(t) + "\n")
+		}
+	}
+	if m.Audio != "" {
+		b.WriteString("audio: " + yamlString(m.Audio) + "\n")
+	}
+	if len(m.Speakers) > 0 {
+		b.WriteString("speakers:\n")
+		for _, s := range m.Speakers {
+			b.WriteString("  - " + yamlString(s) + "\n")
+		}
+	}
+	b.WriteString("---\n\n")
+	return b.String()
+}
+
+// Prepend returns content with the front matter of m at the top. Content that
+// already starts with front matter, like the output of a user template that
+// writes its own, is returned unchanged.
+func (m FrontMatter) Prepend(content string) string {
+	if HasFrontMatter(content) {
+		return content
+	}
+	return m.Markdown() + content
+}
+
+// HasFrontMatter reports whether content starts with YAML front matter.
+func HasFrontMatter(content string) bool {
+	return strings.HasPrefix(content, "---\n") || strings.HasPrefix(content, "---\r\n")
+}
+
+// SplitFrontMatter returns the front matter content starts with, including
+// its closing "---" line, and the rest of content. front is empty if content
+// has no front matter, or if it is not closed.
+func SplitFrontMatter(content string) (front, body string) {
+	if !HasFrontMatter(content) {
+		return "", content
+	}
+	first := strings.Index(content, "\n") + 1
+	offset := first
+	for line := range strings.Lines(content[first:]) {
+		offset += len(line)
+		if strings.TrimRight(line, "\r\n") == "---" {
+			return content[:offset], content[offset:]
+		}
+	}
+	return "", content
+}
+
+var (
+	// plainScalar matches strings written as YAML plain scalars: others are
+	// quoted, so that "12:30", "yes" or "#1" stay strings.
+	plainScalar = regexp.MustCompile(`^[\pL_][\pL\pN _.+-]*$`)
+
+	// yamlKeywords are plain scalars YAML reads as booleans or null.
+	yamlKeywords = []string{"true", "false", "yes", "no", "on", "off", "null", "y", "n"}
+
+	// labelPrefix matches the "[label] " prefixes of a transcript line, like
+	// "[00:05:00] [Speaker 1] " in timestamped transcripts.
+	labelPrefix = regexp.MustCompile(`^\[([^\]\n]+)\] +`)
+
+	// clockLabel matches timestamp labels: 05:00, 00:05:00, 00:05:00.250.
+	clockLabel = regexp.MustCompile(`^\d+(?::\d+)+(?:[.,]\d+)?$`)
+)
+
+// yamlString returns s as a YAML string scalar.
+func yamlString(s string) string {
+	if plainScalar.MatchString(s) && strings.TrimSpace(s) == s &&
+		!slices.Contains(yamlKeywords, strings.ToLower(s)) {
+		return s
+	}
+	// Go escapes are valid in YAML double-quoted scalars
+	return strconv.Quote(s)
+}
+
+// Speakers returns the speaker labels of a diarized transcript: the "[label]"
+// prefixes of its lines, like "[Speaker 1] Hello", in order of first
+// appearance. Timestamps and markers on lines of their own ([intro],
+// [pause 3s]) are not speakers. Returns nil for a transcript without labels.
+func Speakers(transcript string) []string {
+	var speakers []string
+	for line := range strings.Lines(transcript) {
+		line = strings.TrimSpace(line)
+		label := ""
+		for {
+			m := labelPrefix.FindStringSubmatch(line)
+			if m == nil {
+				break
+			}
+			label, line = m[1], line[len(m[0]):]
+		}
+		if label == "" || line == "" || strings.HasPrefix(line, "[") || clockLabel.MatchString(label) {
+			continue
+		}
+		if !slices.Contains(speakers, label) {
+			speakers = append(speakers, label)
+		}
+	}
+	return speakers
+}
@@ -0,0 +1,158 @@
+// Package notes fits outputs into note vaults (Obsidian, Zettelkasten):
+// output files named by a pattern of the output-name setting, and YAML front
+// matter describing the transcript (the front-matter setting).
+package notes
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidName indicates an output-name pattern with an unknown or unclosed
+// placeholder, or a directory in it.
+var ErrInvalidName = errors.New("invalid output name pattern")
+
+// Placeholders of output-name patterns.
+const (
+	PlaceholderDate      = "date"       // Date of the run: 2006-01-02
+	PlaceholderTime      = "time"       // Time of the run: 15-04
+	PlaceholderTemplate  = "template"   // Restructure template, or "transcript"
+	PlaceholderInputStem = "input_stem" // Input file name without its extension
+	PlaceholderLanguage  = "language"   // Language code, or "auto"
+)
+
+// placeholders lists the placeholders in the order of the help text.
+var placeholders = []string{
+	PlaceholderDate, PlaceholderTime, PlaceholderTemplate, PlaceholderInputStem, PlaceholderLanguage,
+}
+
+// Placeholders returns the placeholders of output-name patterns, as written
+// in them: {{date}}, {{time}}...
+func Placeholders() []string {
+	written := make([]string, len(placeholders))
+	for i, p := range placeholders {
+		written[i] = "{{" + p + "}}"
+	}
+	return written
+}
+
+// NameFields are the values of the placeholders of an output name.
+type NameFields struct {
+	Date      time.Time // Start of the run, in local time
+	Template  string    // Template name; empty names the output "transcript"
+	InputStem string    // Input file name without its extension
+	Language  string    // Language code; empty names it "auto"
+}
+
+// namePart is a literal text or a placeholder of a pattern.
+type namePart struct {
+	text        string
+	placeholder bool
+}
+
+// NamePattern is a parsed output-name pattern, like
+// "{{date}}_{{template}}_{{input_stem}}.md". Create it with ParseName.
+type NamePattern struct {
+	parts []namePart
+}
+
+// ParseName parses an output-name pattern. The extension it ends with, if
+// any, is replaced by the extension of the output format (see Expand).
+// Returns ErrInvalidName if a placeholder is unknown or unclosed, if the
+// pattern names a directory (output-dir and --output choose it), or if it
+// has no placeholder and would name every output the same.
+func ParseName(pattern string) (NamePattern, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return NamePattern{}, fmt.Errorf("%w: empty pattern", ErrInvalidName)
+	}
+	if strings.ContainsAny(pattern, `/\`) {
+		return NamePattern{}, fmt.Errorf("%w: %q contains a directory (set output-dir for the folder)", ErrInvalidName, pattern)
+	}
+
+	var p NamePattern
+	hasPlaceholder := false
+	rest := pattern
+	for rest != "" {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			p.parts = append(p.parts, namePart{text: rest})
+			break
+		}
+		if start > 0 {
+			p.parts = append(p.parts, namePart{text: rest[:start]})
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return NamePattern{}, fmt.Errorf("%w: %q has an unclosed {{", ErrInvalidName, pattern)
+		}
+		name := strings.TrimSpace(rest[start+2 : start+end])
+		if !slices.Contains(placeholders, name) {
+			return NamePattern{}, fmt.Errorf("%w: unknown placeholder {{%s}} (valid: %s)",
+				ErrInvalidName, name, strings.Join(Placeholders(), ", "))
+		}
+		p.parts = append(p.parts, namePart{text: name, placeholder: true})
+		hasPlaceholder = true
+		rest = rest[start+end+2:]
+	}
+	if !hasPlaceholder {
+		return NamePattern{}, fmt.Errorf("%w: %q has no placeholder, every output would have the same name", ErrInvalidName, pattern)
+	}
+
+	// The extension is the output format's: drop the one of the pattern
+	if last := &p.parts[len(p.parts)-1]; !last.placeholder {
+		last.text = strings.TrimSuffix(last.text, filepath.Ext(last.text))
+	}
+	return p, nil
+}
+
+// Expand returns the file name of the pattern with fields, ending with ext
+// (like ".md"). Characters that are not allowed in file names are replaced
+// by "-" in the values.
+func (p NamePattern) Expand(fields NameFields, ext string) string {
+	var b strings.Builder
+	for _, part := range p.parts {
+		if !part.placeholder {
+			b.WriteString(part.text)
+			continue
+		}
+		b.WriteString(sanitize(fields.value(part.text)))
+	}
+	return b.String() + ext
+}
+
+// value returns the value of placeholder.
+func (f NameFields) value(placeholder string) string {
+	switch placeholder {
+	case PlaceholderDate:
+		return f.Date.Local().Format("2006-01-02")
+	case PlaceholderTime:
+		return f.Date.Local().Format("15-04")
+	case PlaceholderTemplate:
+		if f.Template == "" {
+			return "transcript"
+		}
+		return f.Template
+	case PlaceholderInputStem:
+		return f.InputStem
+	default:
+		if f.Language == "" {
+			return "auto"
+		}
+		return f.Language
+	}
+}
+
+// sanitize replaces the characters of value that are not allowed in file
+// names, on any platform, by "-".
+func sanitize(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+		return r
+	}, value)
+}
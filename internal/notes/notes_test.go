@@ -0,0 +1,169 @@
+package notes_test
+
+// Notes:
+// - Black-box testing: all tests use the public API only (notes_test package)
+// - Dates are built in time.Local, since names and front matter use local time
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/notes"
+)
+
+func TestParseName_Expand(t *testing.T) {
+	t.Parallel()
+
+	fields := notes.NameFields{
+		Date:      time.Date(2026, 10, 17, 14, 5, 0, 0, time.Local),
+		Template:  "meeting",
+		InputStem: "standup",
+		Language:  "fr",
+	}
+	tests := []struct {
+		name    string
+		pattern string
+		fields  notes.NameFields
+		ext     string
+		want    string
+	}{
+		{"every placeholder", "{{date}}_{{time}}_{{template}}_{{input_stem}}_{{language}}", fields, ".md", "2026-10-17_14-05_meeting_standup_fr.md"},
+		{"pattern extension replaced", "{{date}}_{{input_stem}}.md", fields, ".srt", "2026-10-17_standup.srt"},
+		{"spaces in placeholders", "{{ date }} {{ input_stem }}", fields, ".md", "2026-10-17 standup.md"},
+		{"no template nor language", "{{template}}-{{language}}-{{date}}", notes.NameFields{Date: fields.Date}, ".md", "transcript-auto-2026-10-17.md"},
+		{"unsafe characters replaced", "{{input_stem}}", notes.NameFields{InputStem: `a:b?c`}, ".txt", "a-b-c.txt"},
+		{"dots in literal text kept", "v1.{{date}}", fields, ".md", "v1.2026-10-17.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p, err := notes.ParseName(tt.pattern)
+			if err != nil {
+				t.Fatalf("ParseName(%q) error = %v", tt.pattern, err)
+			}
+			if got := p.Expand(tt.fields, tt.ext); got != tt.want {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseName_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, pattern := range []string{
+		"",
+		"{{date}}_{{author}}",
+		"{{date",
+		"notes/{{date}}",
+		`notes\{{date}}`,
+		"meeting.md",
+	} {
+		t.Run(pattern, func(t *testing.T) {
+			t.Parallel()
+			if _, err := notes.ParseName(pattern); !errors.Is(err, notes.ErrInvalidName) {
+				t.Errorf("ParseName(%q) error = %v, want ErrInvalidName", pattern, err)
+			}
+		})
+	}
+}
+
+func TestFrontMatter_Markdown(t *testing.T) {
+	t.Parallel()
+
+	m := notes.FrontMatter{
+		Date:     time.Date(2026, 10, 17, 14, 5, 30, 0, time.Local),
+		Duration: 62*time.Minute + 5*time.Second,
+		Language: "fr",
+		Template: "meeting",
+		Tags:     []string{"transcript", "meeting"},
+		Audio:    "/notes/audio/standup.ogg",
+		Speakers: []string{"Alice", "Speaker 2", "no"},
+	}
+	want := `---
+date: 2026-10-17T14:05
+duration: "01:02:05"
+language: fr
+template: meeting
+tags:
+  - transcript
+  - meeting
+audio: "/notes/audio/standup.ogg"
+speakers:
+  - Alice
+  - Speaker 2
+  - "no"
+---
+
+`
+	if got := m.Markdown(); got != want {
+		t.Errorf("Markdown() =\n%s\nwant:\n%s", got, want)
+	}
+
+	if got, want := (notes.FrontMatter{}).Markdown(), "---\n---\n\n"; got != want {
+		t.Errorf("empty Markdown() = %q, want %q", got, want)
+	}
+}
+
+func TestFrontMatter_Prepend(t *testing.T) {
+	t.Parallel()
+
+	m := notes.FrontMatter{Language: "en"}
+	if got, want := m.Prepend("# Notes\n"), "---\nlanguage: en\n---\n\n# Notes\n"; got != want {
+		t.Errorf("Prepend() = %q, want %q", got, want)
+	}
+	own := "---\ntags: [call]\n---\n\n# Notes\n"
+	if got := m.Prepend(own); got != own {
+		t.Errorf("Prepend() on content with front matter = %q, want it unchanged", got)
+	}
+}
+
+func TestSpeakers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		transcript string
+		want       []string
+	}{
+		{"diarized", "[Alice] Hello.\n[Bob] Hi.\n\n[Alice] How are you?\n", []string{"Alice", "Bob"}},
+		{"timestamped", "[00:00:00] [Speaker 1] Hello.\n[00:00:04] [Speaker 2] Hi.\n", []string{"Speaker 1", "Speaker 2"}},
+		{"markers and timestamps only", "[intro]\n\n[00:05:00] Hello.\n[pause 3s]\n[transcription failed 05:00–10:00]\n", nil},
+		{"links are not labels", "[docs](https://example.com) say so.\n", nil},
+		{"plain text", "Hello, world.\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := notes.Speakers(tt.transcript); !slices.Equal(got, tt.want) {
+				t.Errorf("Speakers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		content   string
+		wantFront string
+		wantBody  string
+	}{
+		{"front matter", "---\ntags:\n  - call\n---\n\n# Notes\n", "---\ntags:\n  - call\n---\n", "\n# Notes\n"},
+		{"none", "# Notes\n---\n", "", "# Notes\n---\n"},
+		{"not closed", "---\ntags: call\n# Notes\n", "", "---\ntags: call\n# Notes\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			front, body := notes.SplitFrontMatter(tt.content)
+			if front != tt.wantFront || body != tt.wantBody {
+				t.Errorf("SplitFrontMatter() = %q, %q, want %q, %q", front, body, tt.wantFront, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Package progress reports the progress of the transcription pipeline to the
+// application running it, through callbacks carried by the context.
+//
+// The pipeline reads the hooks from the context it is given, so an embedding
+// application (GUI, bot) can follow phases, chunks and retries without parsing
+// the CLI output:
+//
+//	ctx = progress.WithHooks(ctx, progress.Hooks{
+//		OnChunkDone: func(index, total int, err error) { ... },
+//	})
+//
+// Hooks may be called concurrently from several goroutines and should return
+// quickly: the pipeline waits for them.
+package progress
+
+import (
+	"context"
+	"time"
+)
+
+// Phase is a step of the pipeline.
+type Phase string
+
+// Pipeline phases, in order. A run skips the phases it does not need
+// (e.g. recording for transcribe, downloading for local files, extracting
+// for audio files, restructuring without a template).
+const (
+	PhaseRecording     Phase = "recording"
+	PhaseDownloading   Phase = "downloading"
+	PhaseExtracting    Phase = "extracting"
+	PhaseChunking      Phase = "chunking"
+	PhaseTranscribing  Phase = "transcribing"
+	PhaseRestructuring Phase = "restructuring"
+)
+
+// Hooks are callbacks receiving pipeline progress. Nil hooks are skipped.
+type Hooks struct {
+	// OnPhaseChange is called when the pipeline enters a phase.
+	OnPhaseChange func(phase Phase)
+
+	// OnChunkStart is called when the transcription of a chunk starts.
+	// index is the chunk index; total is the number of chunks, or 0 when it
+	// is not known in advance (live --stream).
+	OnChunkStart func(index, total int)
+
+	// OnChunkDone is called when the transcription of a chunk ends,
+	// with a nil err on success.
+	OnChunkDone func(index, total int, err error)
+
+	// OnRetry is called before an API request is retried: attempt is the
+	// retry number (1 for the first retry), delay the wait before it, and
+	// err the error of the failed attempt.
+	OnRetry func(attempt int, delay time.Duration, err error)
+	// OnRecording is called about every second while recording, with the
+	// time recorded so far and the requested duration.
+	OnRecording func(elapsed, duration time.Duration)
+	// OnDownload is called as a URL input is downloaded, with the bytes
+	// downloaded so far and the size of the file (0 if unknown).
+	OnDownload func(done, total int64)
+	// OnExtraction is called as the audio track of a video is extracted, with
+	// the duration of audio extracted so far and the duration of the video
+	// (0 if unknown).
+	OnExtraction func(done, total time.Duration)
+	// OnStep is called when restructuring starts a step: "map" (part current
+	// of total), "condense" (group current of total of a reduce level),
+	// "reduce", "sample" (self-consistency run current of total) or
+	// "consensus".
+	OnStep func(step string, current, total int)
+}
+
+// hooksKey is the context key of the Hooks.
+type hooksKey struct{}
+
+// WithHooks returns a copy of ctx carrying hooks.
+func WithHooks(ctx context.Context, hooks Hooks) context.Context {
+	return context.WithValue(ctx, hooksKey{}, hooks)
+}
+
+// FromContext returns the hooks carried by ctx, or empty hooks.
+func FromContext(ctx context.Context) Hooks {
+	hooks, _ := ctx.Value(hooksKey{}).(Hooks)
+	return hooks
+}
+
+// PhaseChange reports that the pipeline run with ctx entered phase.
+func PhaseChange(ctx context.Context, phase Phase) {
+	if fn := FromContext(ctx).OnPhaseChange; fn != nil {
+		fn(phase)
+	}
+}
+
+// ChunkStart reports that the transcription of a chunk started.
+func ChunkStart(ctx context.Context, index, total int) {
+	if fn := FromContext(ctx).OnChunkStart; fn != nil {
+		fn(index, total)
+	}
+}
+
+// ChunkDone reports that the transcription of a chunk ended.
+func ChunkDone(ctx context.Context, index, total int, err error) {
+	if fn := FromContext(ctx).OnChunkDone; fn != nil {
+		fn(index, total, err)
+	}
+}
+
+// Retry reports that a request is about to be retried.
+func Retry(ctx context.Context, attempt int, delay time.Duration, err error) {
+	if fn := FromContext(ctx).OnRetry; fn != nil {
+		fn(attempt, delay, err)
+	}
+}
+
+// Recording reports the time recorded so far out of duration.
+func Recording(ctx context.Context, elapsed, duration time.Duration) {
+	if fn := FromContext(ctx).OnRecording; fn != nil {
+		fn(elapsed, duration)
+	}
+}
+
+// Download reports the bytes downloaded so far out of total.
+func Download(ctx context.Context, done, total int64) {
+	if fn := FromContext(ctx).OnDownload; fn != nil {
+		fn(done, total)
+	}
+}
+
+// Extraction reports the audio extracted so far out of total.
+func Extraction(ctx context.Context, done, total time.Duration) {
+	if fn := FromContext(ctx).OnExtraction; fn != nil {
+		fn(done, total)
+	}
+}
+
+// Step reports that restructuring started a step.
+func Step(ctx context.Context, step string, current, total int) {
+	if fn := FromContext(ctx).OnStep; fn != nil {
+		fn(step, current, total)
+	}
+}
@@ -0,0 +1,110 @@
+package progress_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/progress"
+)
+
+func TestHooks(t *testing.T) {
+	t.Parallel()
+
+	var (
+		phases  []progress.Phase
+		started []int
+		done    []error
+		retries []int
+		elapsed []time.Duration
+		fetched []int64
+		extract []time.Duration
+		steps   []string
+	)
+	failed := errors.New("rate limited")
+	ctx := progress.WithHooks(context.Background(), progress.Hooks{
+		OnPhaseChange: func(phase progress.Phase) { phases = append(phases, phase) },
+		OnChunkStart:  func(index, total int) { started = append(started, index, total) },
+		OnChunkDone:   func(index, total int, err error) { done = append(done, err) },
+		OnRetry:       func(attempt int, delay time.Duration, err error) { retries = append(retries, attempt) },
+		OnRecording:   func(e, d time.Duration) { elapsed = append(elapsed, e, d) },
+		OnDownload:    func(done, total int64) { fetched = append(fetched, done, total) },
+		OnExtraction:  func(done, total time.Duration) { extract = append(extract, done, total) },
+		OnStep:        func(step string, current, total int) { steps = append(steps, step) },
+	})
+
+	progress.PhaseChange(ctx, progress.PhaseTranscribing)
+	progress.ChunkStart(ctx, 2, 5)
+	progress.ChunkDone(ctx, 2, 5, failed)
+	progress.Retry(ctx, 1, time.Second, failed)
+	progress.Recording(ctx, time.Minute, time.Hour)
+	progress.Download(ctx, 1024, 4096)
+	progress.Extraction(ctx, 30*time.Second, time.Minute)
+	progress.Step(ctx, "map", 1, 3)
+
+	if len(phases) != 1 || phases[0] != progress.PhaseTranscribing {
+		t.Errorf("phases = %v, want [transcribing]", phases)
+	}
+	if len(started) != 2 || started[0] != 2 || started[1] != 5 {
+		t.Errorf("chunk start = %v, want index 2 of 5", started)
+	}
+	if len(done) != 1 || !errors.Is(done[0], failed) {
+		t.Errorf("chunk done errors = %v, want [%v]", done, failed)
+	}
+	if len(retries) != 1 || retries[0] != 1 {
+		t.Errorf("retries = %v, want [1]", retries)
+	}
+	if len(elapsed) != 2 || elapsed[0] != time.Minute || elapsed[1] != time.Hour {
+		t.Errorf("recording = %v, want 1m of 1h", elapsed)
+	}
+	if len(fetched) != 2 || fetched[0] != 1024 || fetched[1] != 4096 {
+		t.Errorf("download = %v, want 1024 of 4096 bytes", fetched)
+	}
+	if len(extract) != 2 || extract[0] != 30*time.Second || extract[1] != time.Minute {
+		t.Errorf("extraction = %v, want 30s of 1m", extract)
+	}
+	if len(steps) != 1 || steps[0] != "map" {
+		t.Errorf("steps = %v, want [map]", steps)
+	}
+}
+
+func TestHooks_NoneOrPartial(t *testing.T) {
+	t.Parallel()
+
+	// Without hooks, or with some left nil, reporting is a no-op.
+	contexts := []context.Context{
+		context.Background(),
+		progress.WithHooks(context.Background(), progress.Hooks{}),
+	}
+	for _, ctx := range contexts {
+		progress.PhaseChange(ctx, progress.PhaseChunking)
+		progress.ChunkStart(ctx, 0, 1)
+		progress.ChunkDone(ctx, 0, 1, nil)
+		progress.Retry(ctx, 1, time.Second, errors.New("timeout"))
+		progress.Recording(ctx, time.Second, time.Minute)
+		progress.Download(ctx, 1, 2)
+		progress.Extraction(ctx, time.Second, time.Minute)
+		progress.Step(ctx, "reduce", 1, 1)
+	}
+
+	if hooks := progress.FromContext(context.Background()); hooks.OnPhaseChange != nil {
+		t.Error("FromContext() without hooks returned a non-nil hook")
+	}
+}
+
+func TestFromContext_Derived(t *testing.T) {
+	t.Parallel()
+
+	called := false
+	ctx := progress.WithHooks(context.Background(), progress.Hooks{
+		OnPhaseChange: func(progress.Phase) { called = true },
+	})
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	progress.PhaseChange(ctx, progress.PhaseRestructuring)
+	if !called {
+		t.Error("hooks were not found in a derived context")
+	}
+}
@@ -0,0 +1,108 @@
+package provenance
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrInvalidKey indicates a signing key that cannot be read or used.
+var ErrInvalidKey = errors.New("invalid signing key")
+
+// Minisign key and signature layout (see https://jedisct1.github.io/minisign/).
+const (
+	// sigAlgorithm is the Ed25519 algorithm of keys, and of signatures of the
+	// whole message. minisign verifies these without -H (prehashed messages).
+	sigAlgorithm = "Ed"
+
+	// secretKeySize is the size of a decoded secret key: algorithms (6),
+	// KDF salt and limits (48), key ID (8), secret key (64), checksum (32).
+	secretKeySize = 158
+
+	keyIDSize = 8
+)
+
+// Key is a minisign secret key, used to sign outputs. Signatures are
+// minisign signature files, verified with:
+//
+//	minisign -Vm <file> -p <public key>
+//
+// Only unencrypted keys are supported (created with minisign -G -W), since
+// outputs are signed without a terminal to ask for a password.
+type Key struct {
+	id  [keyIDSize]byte
+	key ed25519.PrivateKey
+}
+
+// LoadKey reads the minisign secret key at path.
+// Returns ErrInvalidKey if it cannot be read, is encrypted, or is malformed.
+func LoadKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- key file given by the user
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+	raw, err := decodeKeyFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidKey, path, err)
+	}
+	if len(raw) != secretKeySize || string(raw[:2]) != sigAlgorithm {
+		return nil, fmt.Errorf("%w: %s is not a minisign secret key", ErrInvalidKey, path)
+	}
+	switch kdf := raw[2:4]; {
+	case string(kdf) == "Sc":
+		return nil, fmt.Errorf("%w: %s is password-protected; create a key without password with: minisign -G -W",
+			ErrInvalidKey, path)
+	case kdf[0] != 0 || kdf[1] != 0:
+		return nil, fmt.Errorf("%w: %s uses an unknown key derivation", ErrInvalidKey, path)
+	}
+
+	k := &Key{key: ed25519.PrivateKey(bytes.Clone(raw[62:126]))}
+	copy(k.id[:], raw[54:62])
+	// The public half must derive from the seed: catches corrupted keys.
+	if !bytes.Equal(ed25519.NewKeyFromSeed(k.key.Seed()).Public().(ed25519.PublicKey), k.key[32:]) {
+		return nil, fmt.Errorf("%w: %s is corrupted", ErrInvalidKey, path)
+	}
+	return k, nil
+}
+
+// decodeKeyFile returns the decoded first line of data that is not a comment.
+func decodeKeyFile(data []byte) ([]byte, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "untrusted comment:") {
+			continue
+		}
+		return base64.StdEncoding.DecodeString(line)
+	}
+	return nil, errors.New("no key found")
+}
+
+// ID returns the key ID, as printed by minisign.
+func (k *Key) ID() string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(k.id[:]))
+}
+
+// Sign returns the minisign signature file of message. trustedComment is
+// signed too, and printed by minisign when the signature is verified:
+// newlines are replaced by spaces, as it must hold on one line.
+func (k *Key) Sign(message []byte, trustedComment string) string {
+	trustedComment = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(trustedComment)
+
+	sig := ed25519.Sign(k.key, message)
+	sigLine := make([]byte, 0, 2+keyIDSize+ed25519.SignatureSize)
+	sigLine = append(sigLine, sigAlgorithm...)
+	sigLine = append(sigLine, k.id[:]...)
+	sigLine = append(sigLine, sig...)
+
+	global := ed25519.Sign(k.key, append(bytes.Clone(sig), trustedComment...))
+
+	return fmt.Sprintf("untrusted comment: signature from go-transcript secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(sigLine), trustedComment, base64.StdEncoding.EncodeToString(global))
+}
@@ -0,0 +1,160 @@
+package provenance_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/provenance"
+)
+
+// keyID is the ID of the keys written by writeKey, in file order.
+var keyID = []byte{0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01}
+
+// writeKey writes priv as a minisign secret key file with the given KDF
+// algorithm (two zero bytes for an unencrypted key) and returns its path.
+func writeKey(t *testing.T, priv ed25519.PrivateKey, kdf string) string {
+	t.Helper()
+	var raw []byte
+	raw = append(raw, "Ed"...)
+	raw = append(raw, kdf...)
+	raw = append(raw, "B2"...)
+	raw = append(raw, make([]byte, 48)...) // Salt and limits, unused without KDF
+	raw = append(raw, keyID...)
+	raw = append(raw, priv...)
+	raw = append(raw, make([]byte, 32)...) // Checksum, not verified
+
+	path := filepath.Join(t.TempDir(), "test.key")
+	data := "untrusted comment: minisign secret key\n" + base64.StdEncoding.EncodeToString(raw) + "\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return path
+}
+
+func newTestKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return pub, priv
+}
+
+func TestLoadKey(t *testing.T) {
+	t.Parallel()
+
+	_, priv := newTestKey(t)
+	corrupted := bytes.Clone(priv)
+	corrupted[40] ^= 0xFF
+
+	tests := []struct {
+		name    string
+		path    func(t *testing.T) string
+		wantErr string
+	}{
+		{
+			name: "unencrypted key",
+			path: func(t *testing.T) string { return writeKey(t, priv, "\x00\x00") },
+		},
+		{
+			name:    "password-protected key",
+			path:    func(t *testing.T) string { return writeKey(t, priv, "Sc") },
+			wantErr: "minisign -G -W",
+		},
+		{
+			name:    "corrupted key",
+			path:    func(t *testing.T) string { return writeKey(t, corrupted, "\x00\x00") },
+			wantErr: "corrupted",
+		},
+		{
+			name: "public key",
+			path: func(t *testing.T) string {
+				path := filepath.Join(t.TempDir(), "test.pub")
+				data := "untrusted comment: minisign public key\nRWQBAgMEBQYHCA==\n"
+				if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+					t.Fatalf("failed to write key: %v", err)
+				}
+				return path
+			},
+			wantErr: "not a minisign secret key",
+		},
+		{
+			name:    "missing file",
+			path:    func(t *testing.T) string { return filepath.Join(t.TempDir(), "missing.key") },
+			wantErr: "no such file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			key, err := provenance.LoadKey(tt.path(t))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("LoadKey() unexpected error: %v", err)
+				}
+				if got := key.ID(); got != "0102030405060708" {
+					t.Errorf("ID() = %q, want %q", got, "0102030405060708")
+				}
+				return
+			}
+			if !errors.Is(err, provenance.ErrInvalidKey) {
+				t.Fatalf("LoadKey() error = %v, want ErrInvalidKey", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadKey() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKey_Sign(t *testing.T) {
+	t.Parallel()
+
+	pub, priv := newTestKey(t)
+	key, err := provenance.LoadKey(writeKey(t, priv, "\x00\x00"))
+	if err != nil {
+		t.Fatalf("LoadKey() unexpected error: %v", err)
+	}
+
+	message := []byte("# Notes\n\nSigned transcript.\n")
+	sig := key.Sign(message, "file:notes.md\ttool:go-transcript\nsecond line")
+
+	lines := strings.Split(strings.TrimSuffix(sig, "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Sign() = %d lines, want 4:\n%s", len(lines), sig)
+	}
+	if !strings.HasPrefix(lines[0], "untrusted comment: ") {
+		t.Errorf("line 1 = %q, want an untrusted comment", lines[0])
+	}
+
+	sigLine, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sigLine) != 74 {
+		t.Fatalf("signature line = %q, want 74 base64 bytes (err: %v)", lines[1], err)
+	}
+	if string(sigLine[:2]) != "Ed" || !bytes.Equal(sigLine[2:10], keyID) {
+		t.Errorf("signature header = %q, want algorithm Ed and the key ID", sigLine[:10])
+	}
+	if !ed25519.Verify(pub, message, sigLine[10:]) {
+		t.Error("signature does not verify the message")
+	}
+
+	trusted, ok := strings.CutPrefix(lines[2], "trusted comment: ")
+	if !ok || trusted != "file:notes.md\ttool:go-transcript second line" {
+		t.Errorf("line 3 = %q, want the trusted comment on one line", lines[2])
+	}
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil {
+		t.Fatalf("global signature = %q: %v", lines[3], err)
+	}
+	if !ed25519.Verify(pub, append(bytes.Clone(sigLine[10:]), trusted...), global) {
+		t.Error("global signature does not verify the trusted comment")
+	}
+}
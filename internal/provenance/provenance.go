@@ -0,0 +1,100 @@
+// Package provenance records which pipeline produced a transcript.
+//
+// A provenance footer names the tool version, the models used, the date and
+// the SHA-256 of the source audio. Outputs can also be signed with a minisign
+// key (see Key), so recipients can check with minisign that a transcript and
+// its footer come unmodified from the holder of the key.
+package provenance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Record is the provenance of an output. Empty fields are left out of footers,
+// which start with a blank line: append them to content without trailing newlines.
+type Record struct {
+	Tool          string    // Tool name and version, e.g. "go-transcript 1.4.0 (commit: 1a2b3c4)"
+	Transcription string    // Transcription model, e.g. "gpt-4o-mini-transcribe"
+	Restructuring string    // Restructuring model and template; empty if not restructured
+	Date          time.Time // When the output was produced
+	AudioSHA256   string    // Hex SHA-256 of the source audio
+	Signature     string    // Where the signature is and which key made it; empty if unsigned
+}
+
+// fields returns the labels and values of r, in footer order.
+func (r Record) fields() [][2]string {
+	all := [][2]string{
+		{"Tool", r.Tool},
+		{"Transcription", r.Transcription},
+		{"Restructuring", r.Restructuring},
+		{"Date", ""},
+		{"Audio SHA-256", r.AudioSHA256},
+		{"Signature", r.Signature},
+	}
+	if !r.Date.IsZero() {
+		all[3][1] = r.Date.UTC().Format(time.RFC3339)
+	}
+	fields := all[:0]
+	for _, f := range all {
+		if f[1] != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// Markdown returns the footer of r as a Markdown section, after a rule.
+func (r Record) Markdown() string {
+	var b strings.Builder
+	b.WriteString("\n\n---\n\n**Provenance**\n\n")
+	for _, f := range r.fields() {
+		value := f[1]
+		if f[0] == "Audio SHA-256" {
+			value = "`" + value + "`"
+		}
+		fmt.Fprintf(&b, "- %s: %s\n", f[0], value)
+	}
+	return b.String()
+}
+
+// Text returns the footer of r as plain text.
+func (r Record) Text() string {
+	var b strings.Builder
+	b.WriteString("\n\nProvenance\n")
+	for _, f := range r.fields() {
+		fmt.Fprintf(&b, "  %s: %s\n", f[0], f[1])
+	}
+	return b.String()
+}
+
+// VTT returns the footer of r as a WebVTT NOTE block, ignored by players.
+func (r Record) VTT() string {
+	var b strings.Builder
+	b.WriteString("\n\nNOTE Provenance\n")
+	for _, f := range r.fields() {
+		// "-->" would end the note early; no field is expected to hold it.
+		fmt.Fprintf(&b, "%s: %s\n", f[0], strings.ReplaceAll(f[1], "-->", "->"))
+	}
+	return b.String()
+}
+
+// HashFile returns the hex SHA-256 of the file at path.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path) // #nosec G304 -- audio file given by the user
+	if err != nil {
+		return "", fmt.Errorf("cannot hash audio: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("cannot hash audio: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
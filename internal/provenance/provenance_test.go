@@ -0,0 +1,97 @@
+package provenance_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/provenance"
+)
+
+func testRecord() provenance.Record {
+	return provenance.Record{
+		Tool:          "go-transcript 1.4.0 (commit: 1a2b3c4)",
+		Transcription: "gpt-4o-mini-transcribe",
+		Restructuring: "deepseek-chat, template meeting",
+		Date:          time.Date(2026, 3, 1, 14, 30, 0, 0, time.FixedZone("CET", 3600)),
+		AudioSHA256:   "9f86d081884c7d65",
+		Signature:     "notes.md.minisig, minisign key 0102030405060708",
+	}
+}
+
+func TestRecord_Footers(t *testing.T) {
+	t.Parallel()
+
+	unsigned := testRecord()
+	unsigned.Restructuring = ""
+	unsigned.Signature = ""
+
+	tests := []struct {
+		name   string
+		footer string
+		want   string
+	}{
+		{
+			name:   "markdown",
+			footer: testRecord().Markdown(),
+			want: "\n\n---\n\n**Provenance**\n\n" +
+				"- Tool: go-transcript 1.4.0 (commit: 1a2b3c4)\n" +
+				"- Transcription: gpt-4o-mini-transcribe\n" +
+				"- Restructuring: deepseek-chat, template meeting\n" +
+				"- Date: 2026-03-01T13:30:00Z\n" +
+				"- Audio SHA-256: `9f86d081884c7d65`\n" +
+				"- Signature: notes.md.minisig, minisign key 0102030405060708\n",
+		},
+		{
+			name:   "text leaves out empty fields",
+			footer: unsigned.Text(),
+			want: "\n\nProvenance\n" +
+				"  Tool: go-transcript 1.4.0 (commit: 1a2b3c4)\n" +
+				"  Transcription: gpt-4o-mini-transcribe\n" +
+				"  Date: 2026-03-01T13:30:00Z\n" +
+				"  Audio SHA-256: 9f86d081884c7d65\n",
+		},
+		{
+			name:   "vtt note",
+			footer: unsigned.VTT(),
+			want: "\n\nNOTE Provenance\n" +
+				"Tool: go-transcript 1.4.0 (commit: 1a2b3c4)\n" +
+				"Transcription: gpt-4o-mini-transcribe\n" +
+				"Date: 2026-03-01T13:30:00Z\n" +
+				"Audio SHA-256: 9f86d081884c7d65\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if tt.footer != tt.want {
+				t.Errorf("footer =\n%q\nwant\n%q", tt.footer, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audio.ogg")
+	if err := os.WriteFile(path, []byte("test"), 0o600); err != nil {
+		t.Fatalf("failed to write audio: %v", err)
+	}
+
+	got, err := provenance.HashFile(path)
+	if err != nil {
+		t.Fatalf("HashFile() unexpected error: %v", err)
+	}
+	want := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	if got != want {
+		t.Errorf("HashFile() = %q, want %q", got, want)
+	}
+
+	if _, err := provenance.HashFile(filepath.Join(t.TempDir(), "missing.ogg")); err == nil {
+		t.Error("HashFile(missing) error = nil, want error")
+	}
+}
@@ -0,0 +1,191 @@
+// Package redact masks sensitive data in transcripts: email addresses, phone
+// numbers, payment card numbers, and patterns given by the user.
+//
+// Redaction runs locally, after transcription and cleanup, so the data never
+// reaches the output file or the restructure provider. Phone numbers and
+// spelled-out email addresses are written differently in each language: the
+// rules of the transcript language are used (see Rules).
+package redact
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/alnah/go-transcript/internal/lang"
+)
+
+// ErrInvalidPattern indicates a redaction pattern that is not a valid
+// regular expression, or a patterns file that cannot be read.
+var ErrInvalidPattern = errors.New("invalid redaction pattern")
+
+// Masks replacing the redacted data.
+const (
+	MaskEmail   = "[email]"
+	MaskPhone   = "[phone]"
+	MaskCard    = "[card]"
+	MaskPattern = "[redacted]"
+)
+
+// Phone numbers have 7 to 15 digits (E.164), payment cards 13 to 19.
+const (
+	minPhoneDigits = 7
+	maxPhoneDigits = 15
+	minCardDigits  = 13
+	maxCardDigits  = 19
+)
+
+var (
+	writtenEmail = regexp.MustCompile(`[\pL\pN._%+-]+@[\pL\pN-]+(?:\.[\pL\pN-]+)*\.\pL{2,}`)
+	cardNumber   = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+)
+
+// Redactor masks sensitive data in the transcripts of one language.
+// Create it with New.
+type Redactor struct {
+	patterns     []*regexp.Regexp // Patterns of the user, masked first
+	spokenEmails []*regexp.Regexp // One per language
+	phone        *regexp.Regexp
+}
+
+// New returns a Redactor with the rules of language, or the rules of every
+// language if it is zero or has none (see Rules).
+// patterns are regular expressions of other data to mask, like employee IDs
+// or project names. Returns ErrInvalidPattern if one does not compile.
+func New(language lang.Language, patterns []string) (*Redactor, error) {
+	all := anyLanguageRules()
+	if rules, ok := languageRules[language.BaseCode()]; ok {
+		all = []Rules{rules}
+	}
+
+	r := &Redactor{}
+	phones := []string{internationalPhone}
+	for _, rules := range all {
+		r.spokenEmails = append(r.spokenEmails, spokenEmailPattern(rules.At, rules.Dot, rules.TLDs))
+		phones = append(phones, rules.Phones...)
+	}
+	r.phone = regexp.MustCompile(strings.Join(phones, "|"))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidPattern, p, err)
+		}
+		if re.MatchString("") {
+			return nil, fmt.Errorf("%w: %q matches empty text", ErrInvalidPattern, p)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// spokenEmailPattern returns the pattern of email addresses read aloud with
+// the words at and dot of one language, ending with one of tlds:
+// "jane dot doe at example dot com".
+func spokenEmailPattern(at, dot, tlds []string) *regexp.Regexp {
+	word := `[\pL\pN_-]+`
+	alternatives := func(words []string) string {
+		return strings.Join(slices.Compact(slices.Sorted(slices.Values(words))), "|")
+	}
+	atWords, dotWords := alternatives(at), alternatives(dot)
+	return regexp.MustCompile(`(?i)\b` + word + `(?:\s+(?:` + dotWords + `)\s+` + word + `)*` +
+		`\s+(?:` + atWords + `)\s+` + word + `(?:\s+(?:` + dotWords + `)\s+` + word + `)*` +
+		`\s+(?:` + dotWords + `)\s+(?:` + alternatives(tlds) + `)\b`)
+}
+
+// Redact returns text with the patterns of the user, email addresses, card
+// numbers and phone numbers replaced by their masks, and how many were
+// masked. Card numbers are only masked when their check digit is valid
+// (Luhn), so other long numbers are kept.
+func (r *Redactor) Redact(text string) (string, int) {
+	n := 0
+	mask := func(re *regexp.Regexp, replacement string, valid func(string) bool) {
+		text = re.ReplaceAllStringFunc(text, func(match string) string {
+			if valid != nil && !valid(match) {
+				return match
+			}
+			n++
+			return replacement
+		})
+	}
+
+	for _, re := range r.patterns {
+		mask(re, MaskPattern, nil)
+	}
+	mask(writtenEmail, MaskEmail, nil)
+	for _, re := range r.spokenEmails {
+		mask(re, MaskEmail, nil)
+	}
+	mask(cardNumber, MaskCard, isCardNumber)
+	mask(r.phone, MaskPhone, isPhoneNumber)
+	return text, n
+}
+
+// isCardNumber reports whether s has the length and the check digit of a
+// payment card number.
+func isCardNumber(s string) bool {
+	digits := digitsOf(s)
+	if len(digits) < minCardDigits || len(digits) > maxCardDigits {
+		return false
+	}
+	sum := 0
+	for i := range digits {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// isPhoneNumber reports whether s has as many digits as a phone number.
+func isPhoneNumber(s string) bool {
+	n := len(digitsOf(s))
+	return n >= minPhoneDigits && n <= maxPhoneDigits
+}
+
+// digitsOf returns the ASCII digits of s.
+func digitsOf(s string) []byte {
+	digits := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			digits = append(digits, s[i])
+		}
+	}
+	return digits
+}
+
+// ReadPatterns reads the patterns file at path (the redact-patterns setting):
+// one regular expression per line. Blank lines and lines starting with '#'
+// are ignored, and surrounding spaces are trimmed.
+// Returns ErrInvalidPattern if the file cannot be read or has no patterns.
+func ReadPatterns(path string) ([]string, error) {
+	f, err := os.Open(path) // #nosec G304 -- patterns file given by the user
+	if err != nil {
+		return nil, fmt.Errorf("cannot read redaction patterns: %w: %v", ErrInvalidPattern, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		pattern := strings.TrimSpace(scanner.Text())
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read redaction patterns: %w: %v", ErrInvalidPattern, err)
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("%w: %s has no patterns", ErrInvalidPattern, path)
+	}
+	return patterns, nil
+}
@@ -0,0 +1,138 @@
+package redact_test
+
+// Notes:
+// - Black-box testing: all tests use the public API only (redact_test package)
+// - Phone rules are tested one language at a time, with numbers of other
+//   kinds (years, amounts, times) that must be kept
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/redact"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		language string
+		patterns []string
+		input    string
+		want     string
+		wantN    int
+	}{
+		// Emails
+		{"written email", "en", nil, "Write to jane.doe+news@example.co.uk today.", "Write to [email] today.", 1},
+		{"spelled-out email", "en", nil, "It's jane dot doe at example dot com.", "It's [email].", 1},
+		{"french spelled-out email", "fr", nil, "C'est marie arobase exemple point fr.", "C'est [email].", 1},
+		{"at without a domain", "en", nil, "We met at noon. Dot the i's.", "We met at noon. Dot the i's.", 0},
+		{"spelled-out email in unknown language", "", nil, "It's jane dot doe at example dot co dot uk.", "It's [email].", 1},
+		{"english at and french point", "", nil, "We arrived at the point where we had to stop.", "We arrived at the point where we had to stop.", 0},
+		{"english at and point of view", "", nil, "Look at this point of view.", "Look at this point of view.", 0},
+		{"english at and point before a tld of another language", "", nil, "We got at the point it broke.", "We got at the point it broke.", 0},
+		{"last word is not a domain", "en", nil, "Look at him dot the line.", "Look at him dot the line.", 0},
+
+		// Cards
+		{"card number", "en", nil, "My card is 4111 1111 1111 1111, expires soon.", "My card is [card], expires soon.", 1},
+		{"card number with dashes", "", nil, "Card 5500-0000-0000-0004.", "Card [card].", 1},
+		{"long number failing the check digit", "en", nil, "Order 1234 5678 9012 3456 shipped.", "Order 1234 5678 9012 3456 shipped.", 0},
+
+		// Phones
+		{"international number", "en", nil, "Call +33 6 12 34 56 78 now.", "Call [phone] now.", 1},
+		{"north american number", "en", nil, "Call (555) 123-4567 or 555-765-4321.", "Call [phone] or [phone].", 2},
+		{"british number", "en", nil, "Ring 020 7946 0958.", "Ring [phone].", 1},
+		{"french number", "fr", nil, "Appelez le 06 12 34 56 78.", "Appelez le [phone].", 1},
+		{"french number with dots", "fr", nil, "Au 01.23.45.67.89 demain.", "Au [phone] demain.", 1},
+		{"spanish number", "es", nil, "Llama al 612 345 678.", "Llama al [phone].", 1},
+		{"german number", "de", nil, "Rufen Sie 030 1234 5678 an.", "Rufen Sie [phone] an.", 1},
+		{"brazilian number", "pt", nil, "Ligue (11) 91234-5678.", "Ligue [phone].", 1},
+		{"italian mobile", "it", nil, "Chiama il 312 345 6789.", "Chiama il [phone].", 1},
+		{"unknown language uses every rule", "", nil, "Appelez le 06 12 34 56 78.", "Appelez le [phone].", 1},
+		{"numbers that are not phones", "en", nil, "In 2024, 1,500 people paid $300 at 10:30.", "In 2024, 1,500 people paid $300 at 10:30.", 0},
+		{"plus sign in arithmetic", "en", nil, "Add +2 3 to it.", "Add +2 3 to it.", 0},
+
+		// Patterns of the user
+		{"user pattern", "en", []string{`EMP-\d{5}`, `(?i)project falcon`}, "EMP-12345 leads Project Falcon.", "[redacted] leads [redacted].", 2},
+		{"user pattern before built-in rules", "en", []string{`jane@example\.com`}, "Mail jane@example.com.", "Mail [redacted].", 1},
+
+		// Layout
+		{"speaker labels and lines kept", "en", nil, "[Speaker 1] Call 555-123-4567.\n[Speaker 2] Sure.", "[Speaker 1] Call [phone].\n[Speaker 2] Sure.", 1},
+		{"nothing to redact", "en", nil, "Hello, world.", "Hello, world.", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var language lang.Language
+			if tt.language != "" {
+				language = lang.MustParse(tt.language)
+			}
+			r, err := redact.New(language, tt.patterns)
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+
+			got, n := r.Redact(tt.input)
+			if got != tt.want || n != tt.wantN {
+				t.Errorf("Redact(%q) = %q, %d, want %q, %d", tt.input, got, n, tt.want, tt.wantN)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	for _, pattern := range []string{`EMP-(\d+`, `\d*`} {
+		if _, err := redact.New(lang.Language{}, []string{pattern}); !errors.Is(err, redact.ErrInvalidPattern) {
+			t.Errorf("New(%q) error = %v, want ErrInvalidPattern", pattern, err)
+		}
+	}
+}
+
+func TestReadPatterns(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("patterns", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := redact.ReadPatterns(write("patterns.txt", "# Employee IDs\nEMP-\\d{5}\n\n  (?i)project falcon  \n"))
+		if err != nil {
+			t.Fatalf("ReadPatterns() unexpected error: %v", err)
+		}
+		if len(got) != 2 || got[0] != `EMP-\d{5}` || got[1] != "(?i)project falcon" {
+			t.Errorf("ReadPatterns() = %q, want the two patterns", got)
+		}
+	})
+
+	t.Run("no patterns", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := redact.ReadPatterns(write("empty.txt", "# nothing\n")); !errors.Is(err, redact.ErrInvalidPattern) {
+			t.Errorf("ReadPatterns() error = %v, want ErrInvalidPattern", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := redact.ReadPatterns(filepath.Join(dir, "missing.txt")); !errors.Is(err, redact.ErrInvalidPattern) {
+			t.Errorf("ReadPatterns() error = %v, want ErrInvalidPattern", err)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package redact
+
+// Rules are the redaction rules of a language.
+type Rules struct {
+	// Phones are regular expressions matching phone numbers as written in the
+	// countries of the language, in addition to international numbers
+	// ("+33 6 12 34 56 78"), matched in every language.
+	Phones []string
+	// At and Dot are the words read for "@" and "." in email addresses
+	// spelled out by the speaker ("jane dot doe at example dot com").
+	At  []string
+	Dot []string
+	// TLDs are the top-level domains ending a spelled-out address, the last
+	// word after a Dot word. Other words are not an address: "at the point
+	// where" is a sentence.
+	TLDs []string
+}
+
+// commonTLDs are the generic top-level domains of every language.
+var commonTLDs = []string{"com", "net", "org", "info", "biz", "eu", "io", "dev", "app"}
+
+// internationalPhone matches phone numbers with a country code: "+" then 7
+// to 15 digits, possibly grouped by spaces, dots, dashes or parentheses.
+const internationalPhone = `\+\d{1,3}(?:[\s.-]?\(0\))?(?:[\s.-]?\(?\d+\)?){2,6}`
+
+// languageRules are the rules of each supported language, by ISO 639-1 code.
+var languageRules = map[string]Rules{
+	"en": {
+		Phones: []string{
+			`\(\d{3}\)\s?\d{3}[\s.-]?\d{4}`,    // (555) 123-4567
+			`\b[2-9]\d{2}[.-]\d{3}[.-]\d{4}\b`, // 555-123-4567
+			`\b0\d{2,4}\s?\d{3,4}\s?\d{3,4}\b`, // 020 7946 0958, 07700 900123
+		},
+		At:   []string{"at"},
+		Dot:  []string{"dot"},
+		TLDs: append([]string{"edu", "gov", "uk", "us", "ca", "au", "ie", "co"}, commonTLDs...),
+	},
+	"fr": {
+		Phones: []string{
+			`\b0[1-9](?:[\s.-]?\d{2}){4}\b`, // 06 12 34 56 78
+		},
+		At:   []string{"arobase", "at"},
+		Dot:  []string{"point"},
+		TLDs: append([]string{"fr", "be", "ch", "ca"}, commonTLDs...),
+	},
+	"es": {
+		Phones: []string{
+			`\b[6789]\d{2}(?:[\s.-]?\d{3}){2}\b`, // 612 345 678
+			`\b[6789]\d{2}(?:[\s.-]?\d{2}){3}\b`, // 912 34 56 78
+		},
+		At:   []string{"arroba"},
+		Dot:  []string{"punto"},
+		TLDs: append([]string{"es", "mx", "ar", "cl", "co"}, commonTLDs...),
+	},
+	"de": {
+		Phones: []string{
+			`\b0\d{2,4}[\s/-]?\d{3,4}[\s-]?\d{2,5}\b`, // 030 1234 5678, 0171/1234567
+		},
+		At:   []string{"at", "ät"},
+		Dot:  []string{"punkt"},
+		TLDs: append([]string{"de", "at", "ch"}, commonTLDs...),
+	},
+	"pt": {
+		Phones: []string{
+			`\b9\d{2}(?:[\s.-]?\d{3}){2}\b`, // 912 345 678
+			`\(\d{2}\)\s?9?\d{4}-?\d{4}`,    // (11) 91234-5678
+		},
+		At:   []string{"arroba"},
+		Dot:  []string{"ponto"},
+		TLDs: append([]string{"pt", "br"}, commonTLDs...),
+	},
+	"it": {
+		Phones: []string{
+			`\b3\d{2}[\s.-]?\d{3}[\s.-]?\d{3,4}\b`, // 312 345 6789
+			`\b0\d{1,3}[\s.-]?\d{6,8}\b`,           // 02 12345678
+		},
+		At:   []string{"chiocciola"},
+		Dot:  []string{"punto"},
+		TLDs: append([]string{"it", "ch"}, commonTLDs...),
+	},
+}
+
+// anyLanguageRules returns the rules when the language is unknown
+// (auto-detect): the rules of every language, as missing sensitive data is
+// worse than masking a number that was not a phone number. Each language
+// keeps its own words for spelled-out addresses: the English "at" and the
+// French "point" do not make an address.
+func anyLanguageRules() []Rules {
+	var all []Rules
+	for _, code := range []string{"en", "fr", "es", "de", "pt", "it"} {
+		all = append(all, languageRules[code])
+	}
+	return all
+}
@@ -0,0 +1,268 @@
+package restructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/lang"
+)
+
+// ErrInvalidActions indicates the model answered an action items call with
+// something else than the JSON object of actionsSchema, even when asked again.
+var ErrInvalidActions = errors.New("invalid action items")
+
+// actionsSchema is the JSON schema of the answer of an action items call.
+// Providers supporting structured outputs are constrained to it (see
+// responseSchema); it is in the prompt for the others. Every field is
+// required, empty when unknown, as strict structured outputs want.
+const actionsSchema = `{"type":"object","properties":{"actions":{"type":"array","items":{"type":"object","properties":{"description":{"type":"string"},"owner":{"type":"string"},"due":{"type":"string"},"timestamp":{"type":"string"}},"required":["description","owner","due","timestamp"],"additionalProperties":false}}},"required":["actions"],"additionalProperties":false}`
+
+// actionsSchemaName names actionsSchema in the requests of providers asking
+// for one (OpenAI).
+const actionsSchemaName = "action_items"
+
+// actionsPrompt is the prompt of an action items call.
+// Placeholder: actionsSchema.
+const actionsPrompt = `You extract the action items of a transcript: the tasks someone committed to, or was asked to do.
+Answer with a single JSON object, without code fences or comments, following this JSON schema:
+%s
+
+Rules:
+- One item per task, in the order of the transcript
+- description: the task, as a short imperative sentence
+- owner: the person or team responsible, as named in the transcript; empty if nobody is
+- due: the due date or deadline as stated ("Friday", "end of Q3"); empty if none is
+- timestamp: the [HH:MM:SS] time of the line where the task is stated, without brackets, when the transcript has them; empty otherwise
+- Do not invent tasks, owners or dates: an empty list is a valid answer`
+
+// actionsRetryPrompt asks again for the action items when the previous
+// answer was not valid. Placeholder: the validation error.
+const actionsRetryPrompt = `
+
+Your previous answer was rejected: %v. Answer again with the JSON object only.`
+
+// ActionItem is a task extracted from a transcript (see ExtractActions).
+type ActionItem struct {
+	Description string         // The task
+	Owner       string         // Who is responsible, empty if nobody is named
+	Due         string         // Due date as stated in the transcript, empty if none
+	Timestamp   *time.Duration // Where the task is stated in the audio, nil if unknown
+}
+
+// ActionsExtractor extracts the action items of a transcript.
+type ActionsExtractor interface {
+	// ExtractActions returns the action items of transcript, in order.
+	ExtractActions(ctx context.Context, transcript string, outputLang lang.Language) ([]ActionItem, error)
+}
+
+// Compile-time interface compliance check.
+var _ ActionsExtractor = (*MapReduceRestructurer)(nil)
+
+// ExtractActions returns the action items of transcript, with a call
+// constrained to actionsSchema on the providers supporting it. Timestamps are
+// read from "[HH:MM:SS]" transcript lines, so items of untimed transcripts
+// have none. Long transcripts are divided into parts like for Restructure,
+// without overlap (an item would be found twice), and the items of all parts
+// are returned in order, those found twice once. An answer that does not
+// follow the schema is asked for again once, with the reason; the second
+// failure returns ErrInvalidActions.
+func (mr *MapReduceRestructurer) ExtractActions(ctx context.Context, transcript string, outputLang lang.Language) ([]ActionItem, error) {
+	call := callOptions{schema: &responseSchema{name: actionsSchemaName, schema: json.RawMessage(actionsSchema)}}
+
+	prompt := fmt.Sprintf(actionsPrompt, actionsSchema)
+	if !outputLang.IsZero() && !outputLang.IsEnglish() {
+		prompt = fmt.Sprintf("Write the descriptions in %s.\n\n%s", outputLang.DisplayName(), prompt)
+	}
+	prompt = withGlossary(prompt, mr.glossary)
+
+	parts := []string{transcript}
+	if chunks := splitParts(transcript, mr.maxTokens, mr.split, 0); chunks != nil {
+		parts = make([]string, len(chunks))
+		for i, chunk := range chunks {
+			parts[i] = chunk.Content
+		}
+	}
+
+	var items []ActionItem
+	seen := make(map[string]bool)
+	for i, part := range parts {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		partItems, err := mr.extractPart(ctx, part, prompt, call)
+		if err != nil {
+			if len(parts) > 1 {
+				err = fmt.Errorf("part %d/%d: %w", i+1, len(parts), err)
+			}
+			return nil, err
+		}
+		for _, item := range partItems {
+			key := strings.ToLower(item.Owner + "\x00" + item.Description)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// extractPart returns the action items of part, with calls made with call,
+// asking again once if the answer is not valid.
+func (mr *MapReduceRestructurer) extractPart(ctx context.Context, part, prompt string, call callOptions) ([]ActionItem, error) {
+	output, err := mr.restructurer.restructureCall(ctx, part, prompt, call)
+	if err != nil {
+		return nil, err
+	}
+	items, err := parseActions(output)
+	if err == nil {
+		return items, nil
+	}
+
+	output, err = mr.restructurer.restructureCall(ctx, part, prompt+fmt.Sprintf(actionsRetryPrompt, err), call)
+	if err != nil {
+		return nil, err
+	}
+	items, err = parseActions(output)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidActions, err)
+	}
+	return items, nil
+}
+
+// actionTimestamp matches the timestamp of an action item: "HH:MM:SS" or
+// "MM:SS", with or without brackets, and with the milliseconds of the timed
+// formats ("HH:MM:SS.mmm") or not.
+var actionTimestamp = regexp.MustCompile(`^\[?(?:(\d{1,2}):)?(\d{1,2}):(\d{2})(?:[.,](\d{1,3}))?\]?$`)
+
+// parseActions decodes an answer following actionsSchema, tolerating the code
+// fences some models add. Returns an error if the answer is not the JSON
+// object of the schema, or an item has no description or an invalid timestamp.
+func parseActions(output string) ([]ActionItem, error) {
+	output = strings.TrimSpace(output)
+	if strings.HasPrefix(output, "```") {
+		output = strings.TrimPrefix(output, "```json")
+		output = strings.TrimPrefix(output, "```")
+		output = strings.TrimSpace(strings.TrimSuffix(output, "```"))
+	}
+
+	var answer struct {
+		Actions *[]struct {
+			Description string `json:"description"`
+			Owner       string `json:"owner"`
+			Due         string `json:"due"`
+			Timestamp   string `json:"timestamp"`
+		} `json:"actions"`
+	}
+	dec := json.NewDecoder(strings.NewReader(output))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&answer); err != nil {
+		return nil, fmt.Errorf("not the JSON object of the schema: %v", err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("text after the JSON object")
+	}
+	if answer.Actions == nil {
+		return nil, fmt.Errorf(`no "actions" field`)
+	}
+
+	items := make([]ActionItem, 0, len(*answer.Actions))
+	for i, a := range *answer.Actions {
+		item := ActionItem{
+			Description: strings.TrimSpace(a.Description),
+			Owner:       strings.TrimSpace(a.Owner),
+			Due:         strings.TrimSpace(a.Due),
+		}
+		if item.Description == "" {
+			return nil, fmt.Errorf("action %d has no description", i+1)
+		}
+		if ts := strings.TrimSpace(a.Timestamp); ts != "" {
+			m := actionTimestamp.FindStringSubmatch(ts)
+			if m == nil {
+				return nil, fmt.Errorf("action %d timestamp %q is not HH:MM:SS", i+1, ts)
+			}
+			hours, _ := strconv.Atoi(m[1]) // Empty for MM:SS
+			minutes, _ := strconv.Atoi(m[2])
+			seconds, _ := strconv.Atoi(m[3])
+			if minutes >= 60 || seconds >= 60 {
+				return nil, fmt.Errorf("action %d timestamp %q is out of range (minutes and seconds under 60)", i+1, ts)
+			}
+			millis := 0
+			if m[4] != "" {
+				millis, _ = strconv.Atoi((m[4] + "00")[:3]) // ".5" is 500 ms
+			}
+			d := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+				time.Duration(seconds)*time.Second + time.Duration(millis)*time.Millisecond
+			item.Timestamp = &d
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// responseSchema is a JSON schema constraining the answer of a call (see
+// callOptions): OpenAI and Azure with strict structured outputs, Ollama with
+// a format, DeepSeek with JSON mode (valid JSON, the schema being in the
+// prompt). Anthropic has no such option: the schema is in the prompt only.
+type responseSchema struct {
+	name   string
+	schema json.RawMessage
+}
+
+// openAIResponseFormat is the response_format of an OpenAI chat completion
+// request constrained to a schema.
+type openAIResponseFormat struct {
+	Type       string            `json:"type"` // "json_schema"
+	JSONSchema *openAIJSONSchema `json:"json_schema"`
+}
+
+// openAIJSONSchema is the schema of openAIResponseFormat.
+type openAIJSONSchema struct {
+	Name   string          `json:"name"`
+	Strict bool            `json:"strict"`
+	Schema json.RawMessage `json:"schema"`
+}
+
+// openAIFormat returns the response_format of requests constrained to s, or
+// nil without schema.
+func openAIFormat(s *responseSchema) *openAIResponseFormat {
+	if s == nil {
+		return nil
+	}
+	return &openAIResponseFormat{
+		Type:       "json_schema",
+		JSONSchema: &openAIJSONSchema{Name: s.name, Strict: true, Schema: s.schema},
+	}
+}
+
+// deepSeekResponseFormat is the response_format of a DeepSeek request in
+// JSON mode.
+type deepSeekResponseFormat struct {
+	Type string `json:"type"` // "json_object"
+}
+
+// deepSeekFormat returns the response_format of requests constrained to s,
+// or nil without schema.
+func deepSeekFormat(s *responseSchema) *deepSeekResponseFormat {
+	if s == nil {
+		return nil
+	}
+	return &deepSeekResponseFormat{Type: "json_object"}
+}
+
+// ollamaFormat returns the format of Ollama requests constrained to s, or
+// nil without schema.
+func ollamaFormat(s *responseSchema) json.RawMessage {
+	if s == nil {
+		return nil
+	}
+	return s.schema
+}
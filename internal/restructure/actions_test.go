@@ -0,0 +1,187 @@
+package restructure_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+)
+
+func TestParseActions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		output  string
+		want    []restructure.ActionItem
+		wantErr string
+	}{
+		{
+			name:   "items",
+			output: `{"actions":[{"description":"Send the budget","owner":"Alice","due":"Friday","timestamp":"00:12:30"},{"description":"Book a room","owner":"","due":"","timestamp":""}]}`,
+			want: []restructure.ActionItem{
+				{Description: "Send the budget", Owner: "Alice", Due: "Friday", Timestamp: durationPtr(12*time.Minute + 30*time.Second)},
+				{Description: "Book a room"},
+			},
+		},
+		{name: "no items", output: `{"actions":[]}`, want: []restructure.ActionItem{}},
+		{
+			name:   "code fences and brackets",
+			output: "```json\n{\"actions\":[{\"description\":\"Call Bob\",\"owner\":\"\",\"due\":\"\",\"timestamp\":\"[01:05]\"}]}\n```",
+			want:   []restructure.ActionItem{{Description: "Call Bob", Timestamp: durationPtr(time.Minute + 5*time.Second)}},
+		},
+		{name: "not JSON", output: "- Send the budget", wantErr: "not the JSON object"},
+		{name: "no actions field", output: `{"tasks":[]}`, wantErr: "not the JSON object"},
+		{name: "empty object", output: `{}`, wantErr: `no "actions" field`},
+		{name: "text after", output: `{"actions":[]} Done.`, wantErr: "text after"},
+		{name: "no description", output: `{"actions":[{"description":" ","owner":"","due":"","timestamp":""}]}`, wantErr: "no description"},
+		{name: "bad timestamp", output: `{"actions":[{"description":"Call Bob","owner":"","due":"","timestamp":"soon"}]}`, wantErr: "not HH:MM:SS"},
+		{
+			name:   "milliseconds of timed formats",
+			output: `{"actions":[{"description":"Call Bob","owner":"","due":"","timestamp":"01:02:03.250"}]}`,
+			want:   []restructure.ActionItem{{Description: "Call Bob", Timestamp: durationPtr(time.Hour + 2*time.Minute + 3250*time.Millisecond)}},
+		},
+		{name: "minutes out of range", output: `{"actions":[{"description":"Call Bob","owner":"","due":"","timestamp":"00:75:10"}]}`, wantErr: "out of range"},
+		{name: "seconds out of range", output: `{"actions":[{"description":"Call Bob","owner":"","due":"","timestamp":"12:99"}]}`, wantErr: "out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := restructure.ParseActions(tt.output)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseActions() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseActions() unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseActions() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if !sameActionItem(got[i], tt.want[i]) {
+					t.Errorf("ParseActions()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExtractActions(t *testing.T) {
+	t.Parallel()
+
+	newMapReducer := func(t *testing.T, server *mockOpenAIServer) *restructure.MapReduceRestructurer {
+		t.Helper()
+		base := restructure.NewOpenAIRestructurer("test-key",
+			restructure.WithBaseURL(server.URL),
+			restructure.WithRetryDelays(time.Millisecond, time.Millisecond),
+		)
+		return restructure.NewMapReduceRestructurer(base, restructure.WithMapReduceMaxTokens(200))
+	}
+	budget := `{"actions":[{"description":"Send the budget","owner":"Alice","due":"Friday","timestamp":"00:12:30"}]}`
+
+	t.Run("structured output", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+		server.addResponse(http.StatusOK, openAIResponse(budget))
+
+		got, err := newMapReducer(t, server).ExtractActions(context.Background(), "[00:12:30] Alice: I'll send the budget by Friday.", lang.Language{})
+		if err != nil {
+			t.Fatalf("ExtractActions() unexpected error: %v", err)
+		}
+		if len(got) != 1 || got[0].Owner != "Alice" || got[0].Timestamp == nil || *got[0].Timestamp != 12*time.Minute+30*time.Second {
+			t.Errorf("ExtractActions() = %+v, want the budget item", got)
+		}
+		if server.callCount() != 1 {
+			t.Fatalf("expected 1 API call, got %d", server.callCount())
+		}
+		format := string(server.calls[0].ResponseFormat)
+		if !strings.Contains(format, `"type":"json_schema"`) || !strings.Contains(format, `"strict":true`) {
+			t.Errorf("response_format = %s, want a strict JSON schema", format)
+		}
+	})
+
+	t.Run("invalid answer asked again", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+		server.addResponse(http.StatusOK, openAIResponse("Alice sends the budget."))
+		server.addResponse(http.StatusOK, openAIResponse(budget))
+
+		got, err := newMapReducer(t, server).ExtractActions(context.Background(), "Alice: I'll send the budget.", lang.Language{})
+		if err != nil {
+			t.Fatalf("ExtractActions() unexpected error: %v", err)
+		}
+		if len(got) != 1 {
+			t.Errorf("ExtractActions() = %+v, want 1 item", got)
+		}
+		if server.callCount() != 2 {
+			t.Fatalf("expected 2 API calls, got %d", server.callCount())
+		}
+		if system := server.calls[1].Messages[0]["content"]; !strings.Contains(system, "previous answer was rejected") {
+			t.Errorf("second call does not give the reason of the rejection:\n%s", system)
+		}
+	})
+
+	t.Run("invalid twice", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+		server.addResponse(http.StatusOK, openAIResponse("Alice sends the budget."))
+
+		_, err := newMapReducer(t, server).ExtractActions(context.Background(), "Alice: I'll send the budget.", lang.Language{})
+		if !errors.Is(err, restructure.ErrInvalidActions) {
+			t.Errorf("ExtractActions() error = %v, want ErrInvalidActions", err)
+		}
+	})
+
+	t.Run("long transcript", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+		server.addResponse(http.StatusOK, openAIResponse(budget))
+		server.addResponse(http.StatusOK, openAIResponse(budget))
+		server.addResponse(http.StatusOK, openAIResponse(`{"actions":[{"description":"Book a room","owner":"Bob","due":"","timestamp":""}]}`))
+
+		long := strings.Repeat("a", 500) + "\n\n" + strings.Repeat("b", 500) + "\n\n" + strings.Repeat("c", 500)
+		got, err := newMapReducer(t, server).ExtractActions(context.Background(), long, lang.Language{})
+		if err != nil {
+			t.Fatalf("ExtractActions() unexpected error: %v", err)
+		}
+		if server.callCount() != 3 {
+			t.Fatalf("expected 1 API call per part, got %d", server.callCount())
+		}
+		// The budget, found in two parts, is kept once
+		if len(got) != 2 || got[0].Description != "Send the budget" || got[1].Description != "Book a room" {
+			t.Errorf("ExtractActions() = %+v, want the items of all parts once", got)
+		}
+	})
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
+
+func sameActionItem(a, b restructure.ActionItem) bool {
+	if a.Description != b.Description || a.Owner != b.Owner || a.Due != b.Due {
+		return false
+	}
+	if a.Timestamp == nil || b.Timestamp == nil {
+		return a.Timestamp == b.Timestamp
+	}
+	return *a.Timestamp == *b.Timestamp
+}
@@ -0,0 +1,17 @@
+package restructure
+
+import "github.com/alnah/go-transcript/internal/template"
+
+// anchorInstruction asks the calls merging outputs of an anchored template
+// (see template.Name.Anchored) to keep the audio ranges of the items.
+const anchorInstruction = `Source anchors: bullet points and paragraphs end with the range of the recording they come from, like "(12:30–15:10)".
+Keep every range as written. When merging items, give the merged item the range covering all of them, from the earliest start to the latest end.`
+
+// withAnchors returns prompt followed by the anchor instruction if tmpl is
+// anchored, or prompt unchanged otherwise.
+func withAnchors(prompt string, tmpl template.Name) string {
+	if !tmpl.IsAnchored() {
+		return prompt
+	}
+	return prompt + "\n\n" + anchorInstruction
+}
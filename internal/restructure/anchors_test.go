@@ -0,0 +1,46 @@
+package restructure_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+func TestMapReduce_Anchored(t *testing.T) {
+	t.Parallel()
+
+	server := newMockOpenAIServer()
+	t.Cleanup(server.Close)
+
+	base := restructure.NewOpenAIRestructurer("test-key",
+		restructure.WithBaseURL(server.URL),
+		restructure.WithRetryDelays(time.Millisecond, time.Millisecond),
+	)
+	mr := restructure.NewMapReduceRestructurer(base, restructure.WithMapReduceMaxTokens(50))
+
+	// A timed transcript has no paragraphs: it is split at lines
+	transcript := "[00:00:00] " + strings.Repeat("a", 250) + "\n[00:01:00] " + strings.Repeat("b", 250)
+	tmpl := template.MustParseName("notes").Anchored()
+	if _, mapReduced, err := mr.Restructure(context.Background(), transcript, tmpl, lang.Language{}); err != nil || !mapReduced {
+		t.Fatalf("Restructure() = mapReduced %v, error %v, want MapReduce", mapReduced, err)
+	}
+	if server.callCount() != 3 {
+		t.Fatalf("expected 3 API calls (2 map + 1 reduce), got %d", server.callCount())
+	}
+	for i, call := range server.calls[:2] {
+		if user := call.Messages[1]["content"]; !strings.HasPrefix(user, "[00:0") || strings.Count(user, "\n") != 0 {
+			t.Errorf("map call %d content = %q, want one timed line", i+1, user)
+		}
+		if system := call.Messages[0]["content"]; !strings.Contains(system, "Source anchors") {
+			t.Errorf("map call %d system prompt has no anchor rules:\n%s", i+1, system)
+		}
+	}
+	if system := server.calls[2].Messages[0]["content"]; !strings.Contains(system, "Source anchors") {
+		t.Errorf("reduce system prompt has no anchor instruction:\n%s", system)
+	}
+}
@@ -0,0 +1,530 @@
+package restructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// Anthropic API configuration.
+const (
+	// API endpoint and version header
+	defaultAnthropicBaseURL = "https://api.anthropic.com"
+	anthropicAPIVersion     = "2023-06-01"
+
+	// Model configuration
+	DefaultAnthropicModel           = "claude-sonnet-4-5"
+	defaultAnthropicMaxInputTokens  = 150000 // Conservative limit (200K context)
+	defaultAnthropicMaxOutputTokens = 32000
+
+	// Retry configuration
+	defaultAnthropicMaxRetries  = 3
+	defaultAnthropicBaseDelay   = 1 * time.Second
+	defaultAnthropicMaxDelay    = 30 * time.Second
+	defaultAnthropicHTTPTimeout = 10 * time.Minute // Long timeout for large transcripts
+
+	// statusOverloaded is returned by the Anthropic API when it is temporarily
+	// over capacity (overloaded_error). It is not a standard HTTP status.
+	statusOverloaded = 529
+)
+
+// Compile-time interface compliance check.
+var _ Restructurer = (*AnthropicRestructurer)(nil)
+
+// AnthropicRestructurer restructures transcripts using Anthropic's Messages API.
+// It supports automatic retries with exponential backoff for transient errors.
+type AnthropicRestructurer struct {
+	apiKey          string
+	baseURL         string
+	model           string
+	maxInputTokens  int
+	maxOutputTokens int
+	maxRetries      int
+	baseDelay       time.Duration
+	maxDelay        time.Duration
+	httpTimeout     time.Duration
+	httpClient      httpDoer
+	usage           *UsageTracker // Optional, set by MapReduceRestructurer.
+}
+
+// AnthropicOption configures an AnthropicRestructurer.
+type AnthropicOption func(*AnthropicRestructurer)
+
+// WithAnthropicModel sets the model for restructuring.
+func WithAnthropicModel(model string) AnthropicOption {
+	return func(r *AnthropicRestructurer) {
+		r.model = model
+	}
+}
+
+// WithAnthropicMaxInputTokens sets the maximum input token limit.
+func WithAnthropicMaxInputTokens(max int) AnthropicOption {
+	return func(r *AnthropicRestructurer) {
+		if max > 0 {
+			r.maxInputTokens = max
+		}
+	}
+}
+
+// WithAnthropicMaxOutputTokens sets the maximum output token limit.
+func WithAnthropicMaxOutputTokens(max int) AnthropicOption {
+	return func(r *AnthropicRestructurer) {
+		if max > 0 {
+			r.maxOutputTokens = max
+		}
+	}
+}
+
+// WithAnthropicMaxRetries sets the maximum number of retry attempts.
+func WithAnthropicMaxRetries(n int) AnthropicOption {
+	return func(r *AnthropicRestructurer) {
+		if n >= 0 {
+			r.maxRetries = n
+		}
+	}
+}
+
+// WithAnthropicRetryDelays sets the base and max delays for exponential backoff.
+func WithAnthropicRetryDelays(base, max time.Duration) AnthropicOption {
+	return func(r *AnthropicRestructurer) {
+		if base > 0 {
+			r.baseDelay = base
+		}
+		if max > 0 {
+			r.maxDelay = max
+		}
+	}
+}
+
+// WithAnthropicBaseURL sets a custom base URL (for testing or proxies).
+func WithAnthropicBaseURL(url string) AnthropicOption {
+	return func(r *AnthropicRestructurer) {
+		r.baseURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithAnthropicHTTPTimeout sets the HTTP client timeout.
+func WithAnthropicHTTPTimeout(timeout time.Duration) AnthropicOption {
+	return func(r *AnthropicRestructurer) {
+		if timeout > 0 {
+			r.httpTimeout = timeout
+		}
+	}
+}
+
+// withAnthropicHTTPClient sets a custom HTTP client (for testing).
+func withAnthropicHTTPClient(client httpDoer) AnthropicOption {
+	return func(r *AnthropicRestructurer) {
+		r.httpClient = client
+	}
+}
+
+// NewAnthropicRestructurer creates a new AnthropicRestructurer.
+// apiKey is required and must be a valid Anthropic API key.
+// Returns nil and ErrEmptyAPIKey if apiKey is empty.
+func NewAnthropicRestructurer(apiKey string, opts ...AnthropicOption) (*AnthropicRestructurer, error) {
+	if apiKey == "" {
+		return nil, ErrEmptyAPIKey
+	}
+
+	r := &AnthropicRestructurer{
+		apiKey:          apiKey,
+		baseURL:         defaultAnthropicBaseURL,
+		model:           DefaultAnthropicModel,
+		maxInputTokens:  defaultAnthropicMaxInputTokens,
+		maxOutputTokens: defaultAnthropicMaxOutputTokens,
+		maxRetries:      defaultAnthropicMaxRetries,
+		baseDelay:       defaultAnthropicBaseDelay,
+		maxDelay:        defaultAnthropicMaxDelay,
+		httpTimeout:     defaultAnthropicHTTPTimeout,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	// Create HTTP client after options are applied (timeout may be customized)
+	if r.httpClient == nil {
+		r.httpClient = &http.Client{Timeout: r.httpTimeout}
+	}
+	return r, nil
+}
+
+// Restructure transforms a raw transcript into structured markdown using the specified template.
+// outputLang specifies the output language. Zero value uses template's native language (English).
+// Returns ErrTranscriptTooLong if the transcript exceeds the token limit (estimated).
+// Automatically retries on transient errors (rate limits, overload, timeouts, server errors).
+func (r *AnthropicRestructurer) Restructure(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, error) {
+	// 1. Get prompt from validated template
+	prompt := tmpl.Prompt()
+
+	// 2. Add language instruction if output is not English
+	if !outputLang.IsZero() && !outputLang.IsEnglish() {
+		prompt = fmt.Sprintf("Respond in %s.\n\n%s", outputLang.DisplayName(), prompt)
+	}
+
+	// 3. Estimate tokens and check limit
+	estimatedTokens := estimateTokens(transcript)
+	if estimatedTokens > r.maxInputTokens {
+		return "", fmt.Errorf("transcript too long (%dK tokens estimated, max %dK): %w",
+			estimatedTokens/1000, r.maxInputTokens/1000, ErrTranscriptTooLong)
+	}
+
+	// 4. Call API with retry
+	return r.restructureWithRetry(ctx, r.newRequest(prompt, transcript, callOptions{}), nil)
+}
+
+// RestructureWithCustomPrompt executes restructuring with a custom prompt (used by MapReduce).
+// Unlike Restructure, this does not resolve templates or check token limits.
+func (r *AnthropicRestructurer) RestructureWithCustomPrompt(ctx context.Context, content, prompt string) (string, error) {
+	return r.restructureCall(ctx, content, prompt, callOptions{})
+}
+
+// restructureCall implements customPromptRestructurer.
+func (r *AnthropicRestructurer) restructureCall(ctx context.Context, content, prompt string, call callOptions) (string, error) {
+	return r.restructureWithRetry(ctx, r.newRequest(prompt, content, call), call.stream)
+}
+
+// newRequest builds a Messages API request made with call. The Messages API
+// takes the system prompt as a top-level field rather than as a message.
+func (r *AnthropicRestructurer) newRequest(system, content string, call callOptions) anthropicRequest {
+	return anthropicRequest{
+		Model:       r.model,
+		MaxTokens:   r.maxOutputTokens,
+		Temperature: call.temperature, // Deterministic output unless sampling
+		System:      system,
+		Messages: []anthropicMessage{
+			{Role: "user", Content: content},
+		},
+	}
+}
+
+// restructureWithRetry executes the restructuring with exponential backoff retry,
+// writing the answer to stream as it is generated unless stream is nil.
+func (r *AnthropicRestructurer) restructureWithRetry(ctx context.Context, req anthropicRequest, stream io.Writer) (string, error) {
+	cfg := apierr.RetryConfig{
+		MaxRetries: r.maxRetries,
+		BaseDelay:  r.baseDelay,
+		MaxDelay:   r.maxDelay,
+	}
+
+	out := newStreamOutput(stream)
+	req.Stream = out != nil
+
+	return apierr.RetryWithBackoff(ctx, cfg, func() (string, error) {
+		resp, err := r.callAPI(ctx, req, out)
+		if err != nil {
+			// Keep the wait requested by the API through classification
+			delay, _ := apierr.RetryAfter(err)
+			return "", out.interrupted(apierr.WithRetryAfter(classifyAnthropicError(err), delay))
+		}
+		r.usage.Record(Usage{
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+		})
+
+		var text strings.Builder
+		for _, block := range resp.Content {
+			if block.Type == "text" {
+				text.WriteString(block.Text)
+			}
+		}
+		if text.Len() == 0 {
+			return "", fmt.Errorf("no response from Anthropic API")
+		}
+		return text.String(), nil
+	}, isRetryableAnthropicError)
+}
+
+// Model returns the model used for restructuring.
+func (r *AnthropicRestructurer) Model() string {
+	return r.model
+}
+
+// setMaxInputTokens implements modelSizer.
+func (r *AnthropicRestructurer) setMaxInputTokens(n int) {
+	r.maxInputTokens = n
+}
+
+// setUsageTracker implements usageReporter.
+func (r *AnthropicRestructurer) setUsageTracker(t *UsageTracker) {
+	r.usage = t
+}
+
+// anthropicRequest represents an Anthropic Messages API request.
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float64            `json:"temperature"` // 0 for deterministic output, higher for self-consistency runs
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+// anthropicMessage represents a message in the conversation.
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// anthropicResponse represents an Anthropic Messages API response.
+type anthropicResponse struct {
+	ID         string                  `json:"id"`
+	Type       string                  `json:"type"`
+	Role       string                  `json:"role"`
+	Model      string                  `json:"model"`
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      anthropicUsage          `json:"usage"`
+}
+
+// anthropicContentBlock is a content block of an Anthropic response.
+type anthropicContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// anthropicUsage is the token usage of an Anthropic response.
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// anthropicStreamEvent is an event of a streamed Anthropic response. Only the
+// fields of the events read by readAnthropicStream are kept.
+type anthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"` // message_start
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"` // content_block_delta
+	Usage anthropicUsage `json:"usage"` // message_delta
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"` // error
+}
+
+// anthropicErrorResponse represents an error response from the Anthropic API.
+type anthropicErrorResponse struct {
+	Type  string `json:"type"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// callAPI makes an HTTP request to the Anthropic API.
+// A streamed response (reqBody.Stream) is written to out as it arrives.
+func (r *AnthropicRestructurer) callAPI(ctx context.Context, reqBody anthropicRequest, out *streamOutput) (_ *anthropicResponse, err error) {
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := r.baseURL + "/v1/messages"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", r.apiKey)
+	req.Header.Set("Anthropic-Version", anthropicAPIVersion)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	if resp.StatusCode == http.StatusOK && reqBody.Stream {
+		return readAnthropicStream(resp.Body, out)
+	}
+
+	// Limit response size to prevent OOM from malformed responses
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apierr.WithRetryAfter(parseAnthropicError(resp.StatusCode, respBody), apierr.ParseRetryAfter(resp.Header, time.Now()))
+	}
+
+	var result anthropicResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// readAnthropicStream reads a streamed Anthropic response into a response,
+// writing its text to out as it arrives. An error event sent after the
+// response started (e.g. overloaded_error) is returned as an anthropicAPIError.
+func readAnthropicStream(body io.Reader, out *streamOutput) (*anthropicResponse, error) {
+	var text strings.Builder
+	var result anthropicResponse
+	err := readStream(body, func(data []byte) error {
+		var event anthropicStreamEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("failed to parse streamed response: %w", err)
+		}
+		switch event.Type {
+		case "message_start":
+			result.Usage.InputTokens = event.Message.Usage.InputTokens
+		case "content_block_delta":
+			if event.Delta.Type != "text_delta" {
+				return nil
+			}
+			text.WriteString(event.Delta.Text)
+			if err := out.write(event.Delta.Text); err != nil {
+				return fmt.Errorf("failed to write streamed output: %w", err)
+			}
+		case "message_delta":
+			result.Usage.OutputTokens = event.Usage.OutputTokens
+		case "error":
+			statusCode := http.StatusInternalServerError
+			if event.Error.Type == "overloaded_error" {
+				statusCode = statusOverloaded
+			}
+			return &anthropicAPIError{StatusCode: statusCode, Message: event.Error.Message, Type: event.Error.Type}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if text.Len() > 0 {
+		result.Content = []anthropicContentBlock{{Type: "text", Text: text.String()}}
+	}
+	return &result, nil
+}
+
+// anthropicAPIError represents a typed Anthropic API error.
+type anthropicAPIError struct {
+	StatusCode int
+	Message    string
+	Type       string // e.g. "rate_limit_error", "overloaded_error"
+}
+
+func (e *anthropicAPIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("Anthropic API error %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("Anthropic API error %d", e.StatusCode)
+}
+
+// parseAnthropicError parses an error response from the Anthropic API.
+func parseAnthropicError(statusCode int, body []byte) *anthropicAPIError {
+	var errResp anthropicErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		// If we can't parse the error, return a generic error
+		return &anthropicAPIError{
+			StatusCode: statusCode,
+			Message:    string(body),
+		}
+	}
+
+	return &anthropicAPIError{
+		StatusCode: statusCode,
+		Message:    errResp.Error.Message,
+		Type:       errResp.Error.Type,
+	}
+}
+
+// classifyAnthropicError maps Anthropic API errors to sentinel errors.
+// Overload (529, overloaded_error) and server errors are left unclassified:
+// they are retried, see isRetryableAnthropicError.
+func classifyAnthropicError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *anthropicAPIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests: // 429 - rate_limit_error
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrRateLimit)
+		case http.StatusUnauthorized, http.StatusForbidden: // 401, 403 - authentication_error, permission_error
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrAuthFailed)
+		case http.StatusRequestEntityTooLarge: // 413 - request_too_large
+			return fmt.Errorf("API rejected: %w", ErrTranscriptTooLong)
+		case http.StatusRequestTimeout, http.StatusGatewayTimeout: // 408, 504
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrTimeout)
+		case http.StatusBadRequest: // 400 - invalid_request_error
+			msg := strings.ToLower(apiErr.Message)
+			if strings.Contains(msg, "prompt is too long") ||
+				strings.Contains(msg, "context window") ||
+				strings.Contains(msg, "too many tokens") {
+				return fmt.Errorf("API rejected: %w", ErrTranscriptTooLong)
+			}
+			// Anthropic reports an exhausted prepaid balance as an invalid request
+			if strings.Contains(msg, "credit balance") {
+				return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrQuotaExceeded)
+			}
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrBadRequest)
+		}
+	}
+
+	// Check for context timeout/deadline exceeded
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("request timed out: %w", apierr.ErrTimeout)
+	}
+
+	return err
+}
+
+// isRetryableAnthropicError determines if an error is transient and should be retried.
+func isRetryableAnthropicError(err error) bool {
+	// Part of a streamed response was written: a retry would write it again.
+	if errors.Is(err, ErrStreamInterrupted) {
+		return false
+	}
+
+	// Rate limits are retryable (with backoff)
+	if errors.Is(err, apierr.ErrRateLimit) {
+		return true
+	}
+
+	// Timeouts are retryable
+	if errors.Is(err, apierr.ErrTimeout) {
+		return true
+	}
+
+	// Overload and server errors (5xx) are retryable
+	var apiErr *anthropicAPIError
+	if errors.As(err, &apiErr) {
+		if apiErr.Type == "overloaded_error" {
+			return true
+		}
+		switch apiErr.StatusCode {
+		case http.StatusInternalServerError, // 500 - api_error
+			http.StatusBadGateway,         // 502
+			http.StatusServiceUnavailable, // 503
+			http.StatusGatewayTimeout,     // 504
+			statusOverloaded:              // 529 - overloaded_error
+			return true
+		}
+	}
+
+	// Everything else (canceled context, auth, quota, too long, bad request)
+	// fails the same way on every attempt.
+	return false
+}
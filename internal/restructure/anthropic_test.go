@@ -0,0 +1,545 @@
+package restructure_test
+
+// Notes:
+// - Tests use black-box approach via package restructure_test
+// - Internal functions are tested via export_test.go exports
+// - Uses httptest.Server to mock Anthropic Messages API responses
+// - Retry delays are set to 1ms to keep tests fast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// ---------------------------------------------------------------------------
+// Helpers - Anthropic mock server
+// ---------------------------------------------------------------------------
+
+// anthropicResponse creates a mock Anthropic Messages API response.
+func anthropicResponse(text string) map[string]any {
+	return map[string]any{
+		"id":    "msg_test",
+		"type":  "message",
+		"role":  "assistant",
+		"model": "claude-sonnet-4-5",
+		"content": []map[string]any{
+			{"type": "text", "text": text},
+		},
+		"stop_reason": "end_turn",
+		"usage": map[string]any{
+			"input_tokens":  100,
+			"output_tokens": 50,
+		},
+	}
+}
+
+// anthropicErrorResponse creates a mock Anthropic API error response.
+func anthropicErrorResponse(errType, message string) map[string]any {
+	return map[string]any{
+		"type": "error",
+		"error": map[string]any{
+			"type":    errType,
+			"message": message,
+		},
+	}
+}
+
+// anthropicCall is a request received by the mock server.
+type anthropicCall struct {
+	Model     string
+	System    string
+	MaxTokens int
+	Messages  []map[string]string
+	APIKey    string
+	Version   string
+}
+
+// mockAnthropicServer creates a test server that returns predefined responses.
+type mockAnthropicServer struct {
+	*httptest.Server
+	mu          sync.Mutex
+	calls       []anthropicCall
+	responses   []mockResponse
+	responseIdx int
+}
+
+func newMockAnthropicServer() *mockAnthropicServer {
+	m := &mockAnthropicServer{}
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if r.URL.Path != "/v1/messages" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+
+		var req struct {
+			Model     string              `json:"model"`
+			System    string              `json:"system"`
+			MaxTokens int                 `json:"max_tokens"`
+			Messages  []map[string]string `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		m.calls = append(m.calls, anthropicCall{
+			Model:     req.Model,
+			System:    req.System,
+			MaxTokens: req.MaxTokens,
+			Messages:  req.Messages,
+			APIKey:    r.Header.Get("X-Api-Key"),
+			Version:   r.Header.Get("Anthropic-Version"),
+		})
+
+		var resp mockResponse
+		if m.responseIdx < len(m.responses) {
+			resp = m.responses[m.responseIdx]
+			m.responseIdx++
+		} else if len(m.responses) > 0 {
+			resp = m.responses[len(m.responses)-1]
+		} else {
+			resp = mockResponse{statusCode: http.StatusOK, body: anthropicResponse("Default response")}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.statusCode)
+		json.NewEncoder(w).Encode(resp.body)
+	}))
+	return m
+}
+
+func (m *mockAnthropicServer) addResponse(statusCode int, body any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses = append(m.responses, mockResponse{statusCode, body})
+}
+
+func (m *mockAnthropicServer) callCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls)
+}
+
+func (m *mockAnthropicServer) lastCall() anthropicCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.calls) == 0 {
+		return anthropicCall{}
+	}
+	return m.calls[len(m.calls)-1]
+}
+
+// mustNewAnthropicRestructurer creates an AnthropicRestructurer and fails the test if it errors.
+func mustNewAnthropicRestructurer(t *testing.T, apiKey string, opts ...restructure.AnthropicOption) *restructure.AnthropicRestructurer {
+	t.Helper()
+	r, err := restructure.NewAnthropicRestructurer(apiKey, opts...)
+	if err != nil {
+		t.Fatalf("NewAnthropicRestructurer failed: %v", err)
+	}
+	return r
+}
+
+// ---------------------------------------------------------------------------
+// TestNewAnthropicRestructurer - Constructor validation
+// ---------------------------------------------------------------------------
+
+func TestNewAnthropicRestructurer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty API key returns error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := restructure.NewAnthropicRestructurer("")
+		if !errors.Is(err, restructure.ErrEmptyAPIKey) {
+			t.Errorf("NewAnthropicRestructurer(\"\") error = %v, want ErrEmptyAPIKey", err)
+		}
+	})
+
+	t.Run("options with invalid values are ignored", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := restructure.NewAnthropicRestructurer("test-key",
+			restructure.WithAnthropicMaxInputTokens(0),
+			restructure.WithAnthropicMaxOutputTokens(-1),
+			restructure.WithAnthropicMaxRetries(-1),
+			restructure.WithAnthropicHTTPTimeout(0),
+		)
+		if err != nil || r == nil {
+			t.Fatalf("NewAnthropicRestructurer(\"test-key\", invalid_opts) = %v, %v", r, err)
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// TestClassifyAnthropicError - Error classification
+// ---------------------------------------------------------------------------
+
+func TestClassifyAnthropicError(t *testing.T) {
+	t.Parallel()
+
+	if got := restructure.ClassifyAnthropicError(nil); got != nil {
+		t.Errorf("ClassifyAnthropicError(nil) = %v, want nil", got)
+	}
+	if got := restructure.ClassifyAnthropicError(context.DeadlineExceeded); !errors.Is(got, apierr.ErrTimeout) {
+		t.Errorf("ClassifyAnthropicError(DeadlineExceeded) = %v, want ErrTimeout", got)
+	}
+	random := errors.New("random error")
+	if got := restructure.ClassifyAnthropicError(random); got != random {
+		t.Errorf("ClassifyAnthropicError(random) = %v, want unchanged", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestIsRetryableAnthropicError - Retry decision
+// ---------------------------------------------------------------------------
+
+func TestIsRetryableAnthropicError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "rate limit is retryable", err: apierr.ErrRateLimit, want: true},
+		{name: "timeout is retryable", err: apierr.ErrTimeout, want: true},
+		{name: "context canceled is not retryable", err: context.Canceled, want: false},
+		{name: "auth failed is not retryable", err: apierr.ErrAuthFailed, want: false},
+		{name: "quota exceeded is not retryable", err: apierr.ErrQuotaExceeded, want: false},
+		{name: "transcript too long is not retryable", err: restructure.ErrTranscriptTooLong, want: false},
+		{name: "unknown error is not retryable", err: errors.New("random error"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := restructure.IsRetryableAnthropicError(tt.err); got != tt.want {
+				t.Errorf("IsRetryableAnthropicError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestAnthropicRestructurer_Restructure - Main restructuring
+// ---------------------------------------------------------------------------
+
+func TestAnthropicRestructurer_Restructure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy path sends system prompt and headers", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockAnthropicServer()
+		t.Cleanup(server.Close)
+		server.addResponse(http.StatusOK, anthropicResponse("# Restructured"))
+
+		r := mustNewAnthropicRestructurer(t, "test-api-key",
+			restructure.WithAnthropicBaseURL(server.URL+"/"),
+			restructure.WithAnthropicRetryDelays(time.Millisecond, time.Millisecond),
+		)
+
+		result, err := r.Restructure(context.Background(), "Raw transcript.", template.MustParseName("meeting"), lang.MustParse("fr"))
+		if err != nil {
+			t.Fatalf("Restructure() unexpected error: %v", err)
+		}
+		if result != "# Restructured" {
+			t.Errorf("Restructure() = %q, want %q", result, "# Restructured")
+		}
+
+		call := server.lastCall()
+		if call.APIKey != "test-api-key" || call.Version == "" {
+			t.Errorf("headers: X-Api-Key = %q, Anthropic-Version = %q", call.APIKey, call.Version)
+		}
+		if call.Model != "claude-sonnet-4-5" {
+			t.Errorf("Model = %q, want %q", call.Model, "claude-sonnet-4-5")
+		}
+		if call.MaxTokens <= 0 {
+			t.Errorf("MaxTokens = %d, want > 0 (required by the Messages API)", call.MaxTokens)
+		}
+		if !strings.Contains(call.System, "Respond in French") {
+			t.Errorf("System = %q, want language instruction", call.System)
+		}
+		if len(call.Messages) != 1 || call.Messages[0]["role"] != "user" || call.Messages[0]["content"] != "Raw transcript." {
+			t.Errorf("Messages = %v, want the transcript as the only user message", call.Messages)
+		}
+	})
+
+	t.Run("text blocks are concatenated", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockAnthropicServer()
+		t.Cleanup(server.Close)
+		server.addResponse(http.StatusOK, map[string]any{
+			"content": []map[string]any{
+				{"type": "thinking", "thinking": "..."},
+				{"type": "text", "text": "# Part one"},
+				{"type": "text", "text": "\n\nPart two"},
+			},
+		})
+
+		r := mustNewAnthropicRestructurer(t, "test-api-key", restructure.WithAnthropicBaseURL(server.URL))
+		result, err := r.RestructureWithCustomPrompt(context.Background(), "content", "prompt")
+		if err != nil {
+			t.Fatalf("RestructureWithCustomPrompt() unexpected error: %v", err)
+		}
+		if result != "# Part one\n\nPart two" {
+			t.Errorf("RestructureWithCustomPrompt() = %q", result)
+		}
+	})
+
+	t.Run("empty content returns error", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockAnthropicServer()
+		t.Cleanup(server.Close)
+		server.addResponse(http.StatusOK, map[string]any{"content": []any{}})
+
+		r := mustNewAnthropicRestructurer(t, "test-api-key",
+			restructure.WithAnthropicBaseURL(server.URL),
+			restructure.WithAnthropicMaxRetries(0),
+		)
+		_, err := r.Restructure(context.Background(), "transcript", template.MustParseName("meeting"), lang.Language{})
+		if err == nil || !strings.Contains(err.Error(), "no response") {
+			t.Errorf("Restructure() error = %v, want containing %q", err, "no response")
+		}
+	})
+
+	t.Run("transcript too long returns error without calling API", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockAnthropicServer()
+		t.Cleanup(server.Close)
+
+		r := mustNewAnthropicRestructurer(t, "test-api-key",
+			restructure.WithAnthropicBaseURL(server.URL),
+			restructure.WithAnthropicMaxInputTokens(10),
+		)
+		_, err := r.Restructure(context.Background(), strings.Repeat("x", 100), template.MustParseName("meeting"), lang.Language{})
+		if !errors.Is(err, restructure.ErrTranscriptTooLong) {
+			t.Errorf("Restructure(long_transcript) error = %v, want ErrTranscriptTooLong", err)
+		}
+		if server.callCount() != 0 {
+			t.Errorf("callCount() = %d, want 0", server.callCount())
+		}
+	})
+
+	t.Run("custom model can be set", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockAnthropicServer()
+		t.Cleanup(server.Close)
+
+		r := mustNewAnthropicRestructurer(t, "test-api-key",
+			restructure.WithAnthropicBaseURL(server.URL),
+			restructure.WithAnthropicModel("claude-haiku-4-5"),
+		)
+		if _, err := r.Restructure(context.Background(), "transcript", template.MustParseName("meeting"), lang.Language{}); err != nil {
+			t.Fatalf("Restructure() unexpected error: %v", err)
+		}
+		if got := server.lastCall().Model; got != "claude-haiku-4-5" {
+			t.Errorf("Model = %q, want %q", got, "claude-haiku-4-5")
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// TestAnthropicRestructurer_HTTPErrors - API error handling
+// ---------------------------------------------------------------------------
+
+func TestAnthropicRestructurer_HTTPErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		statusCode int
+		body       any
+		wantErr    error
+		retryable  bool
+	}{
+		{
+			name:       "401 authentication error",
+			statusCode: http.StatusUnauthorized,
+			body:       anthropicErrorResponse("authentication_error", "invalid x-api-key"),
+			wantErr:    apierr.ErrAuthFailed,
+		},
+		{
+			name:       "403 permission error",
+			statusCode: http.StatusForbidden,
+			body:       anthropicErrorResponse("permission_error", "not allowed"),
+			wantErr:    apierr.ErrAuthFailed,
+		},
+		{
+			name:       "400 prompt too long",
+			statusCode: http.StatusBadRequest,
+			body:       anthropicErrorResponse("invalid_request_error", "prompt is too long: 210000 tokens > 200000 maximum"),
+			wantErr:    restructure.ErrTranscriptTooLong,
+		},
+		{
+			name:       "400 credit balance",
+			statusCode: http.StatusBadRequest,
+			body:       anthropicErrorResponse("invalid_request_error", "Your credit balance is too low to access the Anthropic API."),
+			wantErr:    apierr.ErrQuotaExceeded,
+		},
+		{
+			name:       "400 other invalid request",
+			statusCode: http.StatusBadRequest,
+			body:       anthropicErrorResponse("invalid_request_error", "max_tokens: field required"),
+			wantErr:    apierr.ErrBadRequest,
+		},
+		{
+			name:       "413 request too large",
+			statusCode: http.StatusRequestEntityTooLarge,
+			body:       anthropicErrorResponse("request_too_large", "Request exceeds the maximum allowed number of bytes."),
+			wantErr:    restructure.ErrTranscriptTooLong,
+		},
+		{
+			name:       "429 rate limit",
+			statusCode: http.StatusTooManyRequests,
+			body:       anthropicErrorResponse("rate_limit_error", "Number of request tokens has exceeded your per-minute rate limit"),
+			retryable:  true,
+		},
+		{
+			name:       "529 overloaded",
+			statusCode: 529,
+			body:       anthropicErrorResponse("overloaded_error", "Overloaded"),
+			retryable:  true,
+		},
+		{
+			name:       "500 api error",
+			statusCode: http.StatusInternalServerError,
+			body:       anthropicErrorResponse("api_error", "Internal server error"),
+			retryable:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newMockAnthropicServer()
+			t.Cleanup(server.Close)
+
+			// For retryable errors, add multiple failures then success
+			server.addResponse(tt.statusCode, tt.body)
+			if tt.retryable {
+				server.addResponse(tt.statusCode, tt.body)
+				server.addResponse(http.StatusOK, anthropicResponse("success"))
+			}
+
+			r := mustNewAnthropicRestructurer(t, "test-api-key",
+				restructure.WithAnthropicBaseURL(server.URL),
+				restructure.WithAnthropicMaxRetries(2),
+				restructure.WithAnthropicRetryDelays(time.Millisecond, time.Millisecond),
+			)
+
+			result, err := r.Restructure(context.Background(), "transcript", template.MustParseName("meeting"), lang.Language{})
+
+			if tt.retryable {
+				if err != nil {
+					t.Fatalf("Restructure() expected success after retries, got error: %v", err)
+				}
+				if result != "success" {
+					t.Errorf("Restructure() = %q, want %q", result, "success")
+				}
+				if server.callCount() != 3 {
+					t.Errorf("callCount() = %d, want 3 (2 failures + 1 success)", server.callCount())
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Restructure() error = %v, want error wrapping %v", err, tt.wantErr)
+			}
+			if server.callCount() != 1 {
+				t.Errorf("callCount() = %d, want 1 (no retry)", server.callCount())
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestAnthropicRestructurer_Transport - HTTP client failures
+// ---------------------------------------------------------------------------
+
+// timeoutDoer fails every request as if the HTTP client timed out.
+type timeoutDoer struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (d *timeoutDoer) Do(*http.Request) (*http.Response, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls++
+	return nil, context.DeadlineExceeded
+}
+
+func TestAnthropicRestructurer_Transport(t *testing.T) {
+	t.Parallel()
+
+	doer := &timeoutDoer{}
+	r := mustNewAnthropicRestructurer(t, "test-api-key",
+		restructure.WithAnthropicHTTPClient(doer),
+		restructure.WithAnthropicMaxRetries(1),
+		restructure.WithAnthropicRetryDelays(time.Millisecond, time.Millisecond),
+	)
+
+	_, err := r.Restructure(context.Background(), "transcript", template.MustParseName("meeting"), lang.Language{})
+	if !errors.Is(err, apierr.ErrTimeout) {
+		t.Errorf("Restructure() error = %v, want ErrTimeout", err)
+	}
+	if doer.calls != 2 {
+		t.Errorf("calls = %d, want 2 (timeouts are retried)", doer.calls)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestAnthropicRestructurer_WithMapReduce - MapReduce integration
+// ---------------------------------------------------------------------------
+
+func TestAnthropicRestructurer_WithMapReduce(t *testing.T) {
+	t.Parallel()
+
+	server := newMockAnthropicServer()
+	t.Cleanup(server.Close)
+	server.addResponse(http.StatusOK, anthropicResponse("# Part 1"))
+	server.addResponse(http.StatusOK, anthropicResponse("# Part 2"))
+	server.addResponse(http.StatusOK, anthropicResponse("# Merged"))
+
+	base := mustNewAnthropicRestructurer(t, "test-api-key",
+		restructure.WithAnthropicBaseURL(server.URL),
+		restructure.WithAnthropicRetryDelays(time.Millisecond, time.Millisecond),
+	)
+	usage := restructure.NewUsageTracker()
+	mr := restructure.NewMapReduceRestructurer(base,
+		restructure.WithMapReduceMaxTokens(50), // Force splitting
+		restructure.WithMapReduceUsageTracker(usage),
+	)
+
+	transcript := strings.Repeat("a", 300) + "\n\n" + strings.Repeat("b", 300)
+	result, usedMapReduce, err := mr.Restructure(context.Background(), transcript, template.MustParseName("meeting"), lang.Language{})
+	if err != nil {
+		t.Fatalf("Restructure() unexpected error: %v", err)
+	}
+	if !usedMapReduce || result != "# Merged" {
+		t.Errorf("Restructure() = %q, %v; want %q, true", result, usedMapReduce, "# Merged")
+	}
+	if got := usage.Total().PromptTokens; got != 300 {
+		t.Errorf("usage prompt tokens = %d, want 300 (3 calls x 100)", got)
+	}
+}
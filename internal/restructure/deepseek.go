@@ -22,7 +22,7 @@ const (
 	defaultDeepSeekBaseURL = "https://api.deepseek.com"
 
 	// Model configuration
-	defaultDeepSeekModel           = "deepseek-reasoner" // 64K max output, thinking mode
+	DefaultDeepSeekModel           = "deepseek-reasoner" // 64K max output, thinking mode
 	defaultDeepSeekMaxInputTokens  = 100000              // Conservative limit (128K context)
 	defaultDeepSeekMaxOutputTokens = 64000               // deepseek-reasoner max
 
@@ -57,6 +57,7 @@ type DeepSeekRestructurer struct {
 	maxDelay        time.Duration
 	httpTimeout     time.Duration
 	httpClient      httpDoer
+	usage           *UsageTracker // Optional, set by MapReduceRestructurer.
 }
 
 // DeepSeekOption configures a DeepSeekRestructurer.
@@ -144,7 +145,7 @@ func NewDeepSeekRestructurer(apiKey string, opts ...DeepSeekOption) (*DeepSeekRe
 	r := &DeepSeekRestructurer{
 		apiKey:          apiKey,
 		baseURL:         defaultDeepSeekBaseURL,
-		model:           defaultDeepSeekModel,
+		model:           DefaultDeepSeekModel,
 		maxInputTokens:  defaultDeepSeekMaxInputTokens,
 		maxOutputTokens: defaultDeepSeekMaxOutputTokens,
 		maxRetries:      defaultDeepSeekMaxRetries,
@@ -184,9 +185,8 @@ func (r *DeepSeekRestructurer) Restructure(ctx context.Context, transcript strin
 
 	// 4. Build request
 	req := deepSeekRequest{
-		Model:       r.model,
-		MaxTokens:   r.maxOutputTokens,
-		Temperature: 0, // Deterministic output
+		Model:     r.model,
+		MaxTokens: r.maxOutputTokens,
 		Messages: []deepSeekMessage{
 			{Role: "system", Content: prompt},
 			{Role: "user", Content: transcript},
@@ -194,37 +194,56 @@ func (r *DeepSeekRestructurer) Restructure(ctx context.Context, transcript strin
 	}
 
 	// 5. Call API with retry
-	return r.restructureWithRetry(ctx, req)
+	return r.restructureWithRetry(ctx, req, nil)
 }
 
 // RestructureWithCustomPrompt executes restructuring with a custom prompt (used by MapReduce).
 // Unlike Restructure, this does not resolve templates or check token limits.
 func (r *DeepSeekRestructurer) RestructureWithCustomPrompt(ctx context.Context, content, prompt string) (string, error) {
+	return r.restructureCall(ctx, content, prompt, callOptions{})
+}
+
+// restructureCall implements customPromptRestructurer.
+func (r *DeepSeekRestructurer) restructureCall(ctx context.Context, content, prompt string, call callOptions) (string, error) {
 	req := deepSeekRequest{
 		Model:       r.model,
 		MaxTokens:   r.maxOutputTokens,
-		Temperature: 0,
+		Temperature: call.temperature, // Deterministic output unless sampling
 		Messages: []deepSeekMessage{
 			{Role: "system", Content: prompt},
 			{Role: "user", Content: content},
 		},
+		ResponseFormat: deepSeekFormat(call.schema),
 	}
-	return r.restructureWithRetry(ctx, req)
+	return r.restructureWithRetry(ctx, req, call.stream)
 }
 
-// restructureWithRetry executes the restructuring with exponential backoff retry.
-func (r *DeepSeekRestructurer) restructureWithRetry(ctx context.Context, req deepSeekRequest) (string, error) {
+// restructureWithRetry executes the restructuring with exponential backoff retry,
+// writing the answer to stream as it is generated unless stream is nil.
+func (r *DeepSeekRestructurer) restructureWithRetry(ctx context.Context, req deepSeekRequest, stream io.Writer) (string, error) {
 	cfg := apierr.RetryConfig{
 		MaxRetries: r.maxRetries,
 		BaseDelay:  r.baseDelay,
 		MaxDelay:   r.maxDelay,
 	}
 
+	out := newStreamOutput(stream)
+	if out != nil {
+		req.Stream = true
+		req.StreamOptions = &chatStreamOptions{IncludeUsage: true}
+	}
+
 	return apierr.RetryWithBackoff(ctx, cfg, func() (string, error) {
-		resp, err := r.callAPI(ctx, req)
+		resp, err := r.callAPI(ctx, req, out)
 		if err != nil {
-			return "", classifyDeepSeekError(err)
+			// Keep the wait requested by the API through classification
+			delay, _ := apierr.RetryAfter(err)
+			return "", out.interrupted(apierr.WithRetryAfter(classifyDeepSeekError(err), delay))
 		}
+		r.usage.Record(Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+		})
 		if len(resp.Choices) == 0 {
 			return "", fmt.Errorf("no response from DeepSeek API")
 		}
@@ -232,12 +251,30 @@ func (r *DeepSeekRestructurer) restructureWithRetry(ctx context.Context, req dee
 	}, isRetryableDeepSeekError)
 }
 
+// Model returns the model used for restructuring.
+func (r *DeepSeekRestructurer) Model() string {
+	return r.model
+}
+
+// setMaxInputTokens implements modelSizer.
+func (r *DeepSeekRestructurer) setMaxInputTokens(n int) {
+	r.maxInputTokens = n
+}
+
+// setUsageTracker implements usageReporter.
+func (r *DeepSeekRestructurer) setUsageTracker(t *UsageTracker) {
+	r.usage = t
+}
+
 // deepSeekRequest represents a DeepSeek chat completion request.
 type deepSeekRequest struct {
-	Model       string            `json:"model"`
-	Messages    []deepSeekMessage `json:"messages"`
-	MaxTokens   int               `json:"max_tokens,omitempty"`
-	Temperature float64           `json:"temperature"` // 0 for deterministic output
+	Model          string                  `json:"model"`
+	Messages       []deepSeekMessage       `json:"messages"`
+	MaxTokens      int                     `json:"max_tokens,omitempty"`
+	Temperature    float64                 `json:"temperature"` // 0 for deterministic output, higher for self-consistency runs
+	Stream         bool                    `json:"stream,omitempty"`
+	StreamOptions  *chatStreamOptions      `json:"stream_options,omitempty"`
+	ResponseFormat *deepSeekResponseFormat `json:"response_format,omitempty"`
 }
 
 // deepSeekMessage represents a message in the conversation.
@@ -248,25 +285,25 @@ type deepSeekMessage struct {
 
 // deepSeekResponse represents a DeepSeek chat completion response.
 type deepSeekResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	Model   string `json:"model"`
-	Choices []struct {
-		Index   int `json:"index"`
-		Message struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
-		} `json:"message"`
-		FinishReason string `json:"finish_reason"`
-	} `json:"choices"`
-	Usage struct {
+	ID      string           `json:"id"`
+	Object  string           `json:"object"`
+	Created int64            `json:"created"`
+	Model   string           `json:"model"`
+	Choices []deepSeekChoice `json:"choices"`
+	Usage   struct {
 		PromptTokens     int `json:"prompt_tokens"`
 		CompletionTokens int `json:"completion_tokens"`
 		TotalTokens      int `json:"total_tokens"`
 	} `json:"usage"`
 }
 
+// deepSeekChoice is a choice of a DeepSeek chat completion response.
+type deepSeekChoice struct {
+	Index        int             `json:"index"`
+	Message      deepSeekMessage `json:"message"`
+	FinishReason string          `json:"finish_reason"`
+}
+
 // deepSeekErrorResponse represents an error response from the DeepSeek API.
 type deepSeekErrorResponse struct {
 	Error struct {
@@ -277,7 +314,7 @@ type deepSeekErrorResponse struct {
 }
 
 // callAPI makes an HTTP request to the DeepSeek API.
-func (r *DeepSeekRestructurer) callAPI(ctx context.Context, reqBody deepSeekRequest) (_ *deepSeekResponse, err error) {
+func (r *DeepSeekRestructurer) callAPI(ctx context.Context, reqBody deepSeekRequest, out *streamOutput) (_ *deepSeekResponse, err error) {
 	body, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -302,6 +339,10 @@ func (r *DeepSeekRestructurer) callAPI(ctx context.Context, reqBody deepSeekRequ
 		}
 	}()
 
+	if resp.StatusCode == http.StatusOK && reqBody.Stream {
+		return readDeepSeekStream(resp.Body, out)
+	}
+
 	// Limit response size to prevent OOM from malformed responses
 	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
 	if err != nil {
@@ -309,7 +350,7 @@ func (r *DeepSeekRestructurer) callAPI(ctx context.Context, reqBody deepSeekRequ
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, parseDeepSeekError(resp.StatusCode, respBody)
+		return nil, apierr.WithRetryAfter(parseDeepSeekError(resp.StatusCode, respBody), apierr.ParseRetryAfter(resp.Header, time.Now()))
 	}
 
 	var result deepSeekResponse
@@ -320,6 +361,22 @@ func (r *DeepSeekRestructurer) callAPI(ctx context.Context, reqBody deepSeekRequ
 	return &result, nil
 }
 
+// readDeepSeekStream reads a streamed chat completion into a response,
+// writing its content to out as it arrives.
+func readDeepSeekStream(body io.Reader, out *streamOutput) (*deepSeekResponse, error) {
+	content, usage, err := readChatStream(body, out)
+	if err != nil {
+		return nil, err
+	}
+	var result deepSeekResponse
+	result.Usage.PromptTokens = usage.PromptTokens
+	result.Usage.CompletionTokens = usage.CompletionTokens
+	if content != "" {
+		result.Choices = []deepSeekChoice{{Message: deepSeekMessage{Role: "assistant", Content: content}}}
+	}
+	return &result, nil
+}
+
 // deepSeekAPIError represents a typed DeepSeek API error.
 type deepSeekAPIError struct {
 	StatusCode int
@@ -399,6 +456,11 @@ func classifyDeepSeekError(err error) error {
 
 // isRetryableDeepSeekError determines if an error is transient and should be retried.
 func isRetryableDeepSeekError(err error) bool {
+	// Part of a streamed response was written: a retry would write it again
+	if errors.Is(err, ErrStreamInterrupted) {
+		return false
+	}
+
 	// Rate limits are retryable (with backoff)
 	if errors.Is(err, apierr.ErrRateLimit) {
 		return true
@@ -720,4 +720,40 @@ func TestDeepSeekRestructurer_WithMapReduce(t *testing.T) {
 			t.Errorf("callCount() = %d, want 1", server.callCount())
 		}
 	})
+
+	t.Run("usage tracker records every map and reduce call", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockDeepSeekServer()
+		t.Cleanup(server.Close)
+
+		server.addResponse(http.StatusOK, deepSeekResponse("# Part 1 Result"))
+		server.addResponse(http.StatusOK, deepSeekResponse("# Part 2 Result"))
+		server.addResponse(http.StatusOK, deepSeekResponse("# Merged Final Result"))
+
+		base := mustNewDeepSeekRestructurer(t, "test-api-key",
+			restructure.WithDeepSeekBaseURL(server.URL),
+			restructure.WithDeepSeekRetryDelays(time.Millisecond, time.Millisecond),
+		)
+
+		tracker := restructure.NewUsageTracker()
+		mr := restructure.NewMapReduceRestructurer(base,
+			restructure.WithMapReduceMaxTokens(50),
+			restructure.WithMapReduceUsageTracker(tracker),
+		)
+
+		transcript := strings.Repeat("a", 300) + "\n\n" + strings.Repeat("b", 300)
+		if _, _, err := mr.Restructure(context.Background(), transcript, template.MustParseName("meeting"), lang.Language{}); err != nil {
+			t.Fatalf("Restructure() unexpected error: %v", err)
+		}
+
+		// Each mock response reports 100 prompt + 50 completion tokens.
+		if got, want := tracker.Calls(), 3; got != want {
+			t.Errorf("Calls() = %d, want %d (2 map + 1 reduce)", got, want)
+		}
+		want := restructure.Usage{PromptTokens: 300, CompletionTokens: 150}
+		if got := tracker.Total(); got != want {
+			t.Errorf("Total() = %+v, want %+v", got, want)
+		}
+	})
 }
@@ -7,3 +7,14 @@ var ErrTranscriptTooLong = errors.New("transcript exceeds 100K token limit")
 
 // ErrEmptyAPIKey indicates that the API key was not provided.
 var ErrEmptyAPIKey = errors.New("API key is required")
+
+// ErrOllamaUnreachable indicates that no Ollama server answers at the configured URL.
+var ErrOllamaUnreachable = errors.New("ollama server not reachable")
+
+// ErrStreamInterrupted indicates that a streamed response broke off after
+// part of it was written (see WithMapReduceStream). It is not retried: the
+// retry would write the output again after the part already written.
+var ErrStreamInterrupted = errors.New("streamed response interrupted")
+
+// ErrUnknownSplit indicates an invalid split strategy was specified (see ParseSplit).
+var ErrUnknownSplit = errors.New("unknown restructure split")
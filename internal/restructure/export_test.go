@@ -6,6 +6,12 @@ package restructure
 // DeepSeek option exports for dependency injection in tests.
 var WithDeepSeekHTTPClient = withDeepSeekHTTPClient
 
+// Anthropic option exports for dependency injection in tests.
+var WithAnthropicHTTPClient = withAnthropicHTTPClient
+
+// Ollama option exports for dependency injection in tests.
+var WithOllamaHTTPClient = withOllamaHTTPClient
+
 // Function exports for unit testing internal logic.
 var (
 	// OpenAI error handling
@@ -16,8 +22,33 @@ var (
 	ClassifyDeepSeekError    = classifyDeepSeekError
 	IsRetryableDeepSeekError = isRetryableDeepSeekError
 
+	// Anthropic error handling
+	ClassifyAnthropicError    = classifyAnthropicError
+	IsRetryableAnthropicError = isRetryableAnthropicError
+
+	// Ollama error handling
+	ClassifyOllamaError    = classifyOllamaError
+	IsRetryableOllamaError = isRetryableOllamaError
+
 	// Shared functions
-	SplitTranscript = splitTranscript
-	BuildMapPrompt  = buildMapPrompt
-	EstimateTokens  = estimateTokens
+	SplitTranscript        = splitTranscript
+	SplitTranscriptByTurns = splitTranscriptByTurns
+	BuildMapPrompt         = buildMapPrompt
+	GroupOutputs           = groupOutputs
+	ParseActions           = parseActions
 )
+
+// MaxTokens returns the chunk size of a MapReduceRestructurer.
+func (mr *MapReduceRestructurer) MaxTokens() int {
+	return mr.maxTokens
+}
+
+// MaxInputTokens returns the input limit of an OpenAIRestructurer.
+func (r *OpenAIRestructurer) MaxInputTokens() int {
+	return r.maxInputTokens
+}
+
+// DefaultContextWindows returns the built-in context windows.
+func DefaultContextWindows() ContextWindows {
+	return defaultContextWindows
+}
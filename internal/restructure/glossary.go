@@ -0,0 +1,48 @@
+package restructure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// maxGlossaryTerms is the maximum number of glossary terms put in the system
+// prompt. Unlike the transcription prompt, it has room for many, but a long
+// list would dilute the instructions of the template.
+const maxGlossaryTerms = 200
+
+// glossaryInstruction introduces the glossary terms in the system prompt.
+const glossaryInstruction = `Glossary: the speakers use these terms (products, acronyms, people).
+Spell them exactly as written here, and correct the transcript where it misspells them:
+%s`
+
+// WithMapReduceGlossary lists terms in the system prompt of every call, so
+// that names and jargon misrecognized by transcription are spelled right in
+// the output. Terms are in order of priority: beyond maxGlossaryTerms, the
+// last ones are left out.
+func WithMapReduceGlossary(terms []string) MapReduceOption {
+	return func(mr *MapReduceRestructurer) {
+		mr.glossary = terms[:min(len(terms), maxGlossaryTerms)]
+	}
+}
+
+// withGlossary returns prompt followed by the glossary instruction listing
+// terms, or prompt unchanged without terms.
+func withGlossary(prompt string, terms []string) string {
+	if len(terms) == 0 {
+		return prompt
+	}
+	return prompt + "\n\n" + fmt.Sprintf(glossaryInstruction, strings.Join(terms, ", "))
+}
+
+// glossaryPrompt returns the system prompt of a single restructuring call
+// with tmpl in outputLang, followed by the glossary instruction listing terms.
+func glossaryPrompt(tmpl template.Name, outputLang lang.Language, terms []string) string {
+	prompt := tmpl.Prompt()
+	if !outputLang.IsZero() && !outputLang.IsEnglish() {
+		prompt = fmt.Sprintf("Respond in %s.\n\n%s", outputLang.DisplayName(), prompt)
+	}
+	return withGlossary(prompt, terms)
+}
@@ -0,0 +1,72 @@
+package restructure_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+func TestWithMapReduceGlossary(t *testing.T) {
+	t.Parallel()
+
+	t.Run("single call lists the terms after the template", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+		server.addResponse(http.StatusOK, openAIResponse("Notes."))
+
+		base := restructure.NewOpenAIRestructurer("test-key", restructure.WithBaseURL(server.URL))
+		mr := restructure.NewMapReduceRestructurer(base,
+			restructure.WithMapReduceMaxTokens(1000),
+			restructure.WithMapReduceGlossary([]string{"Kubernetes", "OKR"}),
+		)
+
+		tmpl := template.MustParseName("meeting")
+		if _, _, err := mr.Restructure(context.Background(), "Short transcript.", tmpl, lang.MustParse("fr")); err != nil {
+			t.Fatalf("Restructure() unexpected error: %v", err)
+		}
+		prompt := server.systemPrompt()
+		if !strings.HasPrefix(prompt, "Respond in French.") || !strings.Contains(prompt, tmpl.Prompt()) {
+			t.Errorf("system prompt lost the template or language:\n%s", prompt)
+		}
+		if !strings.HasSuffix(prompt, "Kubernetes, OKR") {
+			t.Errorf("system prompt = %q, want glossary terms at the end", prompt)
+		}
+	})
+
+	t.Run("map and reduce calls list the terms", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+
+		base := restructure.NewOpenAIRestructurer("test-key",
+			restructure.WithBaseURL(server.URL),
+			restructure.WithRetryDelays(time.Millisecond, time.Millisecond),
+		)
+		mr := restructure.NewMapReduceRestructurer(base,
+			restructure.WithMapReduceMaxTokens(50),
+			restructure.WithMapReduceGlossary([]string{"Kubernetes"}),
+		)
+
+		transcript := strings.Repeat("a", 300) + "\n\n" + strings.Repeat("b", 300)
+		if _, _, err := mr.Restructure(context.Background(), transcript, template.MustParseName("meeting"), lang.Language{}); err != nil {
+			t.Fatalf("Restructure() unexpected error: %v", err)
+		}
+		if server.callCount() != 3 {
+			t.Fatalf("expected 3 API calls (2 map + 1 reduce), got %d", server.callCount())
+		}
+		for i, call := range server.calls {
+			if system := call.Messages[0]["content"]; !strings.Contains(system, "Glossary") || !strings.Contains(system, "Kubernetes") {
+				t.Errorf("call %d system prompt has no glossary:\n%s", i+1, system)
+			}
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package restructure
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// highlightInstruction introduces the highlight markers in the system prompt.
+const highlightInstruction = `Highlights: while recording, the speaker marked key moments.
+Each marker is a line of the transcript following the passage it marks: %s.
+Give these passages prominence: cover them in the output and emphasize them in summaries.`
+
+// WithMapReduceHighlights lists the times of the highlight markers dropped
+// while recording in the system prompt of every call, so that the output
+// emphasizes the marked moments.
+func WithMapReduceHighlights(marks []time.Duration) MapReduceOption {
+	return func(mr *MapReduceRestructurer) {
+		mr.highlights = marks
+	}
+}
+
+// withHighlights returns prompt followed by the highlight instruction listing
+// the times of marks, or prompt unchanged without marks.
+func withHighlights(prompt string, marks []time.Duration) string {
+	if len(marks) == 0 {
+		return prompt
+	}
+	markers := make([]string, len(marks))
+	for i, m := range marks {
+		markers[i] = format.Highlight(m)
+	}
+	return prompt + "\n\n" + fmt.Sprintf(highlightInstruction, strings.Join(markers, ", "))
+}
@@ -0,0 +1,98 @@
+package restructure_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+func TestWithMapReduceHighlights(t *testing.T) {
+	t.Parallel()
+
+	marks := []time.Duration{45 * time.Second, 42*time.Minute + 15*time.Second}
+
+	t.Run("single call lists the markers after the glossary", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+		server.addResponse(http.StatusOK, openAIResponse("Notes."))
+
+		base := restructure.NewOpenAIRestructurer("test-key", restructure.WithBaseURL(server.URL))
+		mr := restructure.NewMapReduceRestructurer(base,
+			restructure.WithMapReduceMaxTokens(1000),
+			restructure.WithMapReduceGlossary([]string{"Kubernetes"}),
+			restructure.WithMapReduceHighlights(marks),
+		)
+
+		tmpl := template.MustParseName("meeting")
+		if _, _, err := mr.Restructure(context.Background(), "Short transcript.\n\n⭐ 00:00:45", tmpl, lang.Language{}); err != nil {
+			t.Fatalf("Restructure() unexpected error: %v", err)
+		}
+		prompt := server.systemPrompt()
+		if !strings.HasPrefix(prompt, tmpl.Prompt()) {
+			t.Errorf("system prompt lost the template:\n%s", prompt)
+		}
+		glossary := strings.Index(prompt, "Kubernetes")
+		highlights := strings.Index(prompt, "⭐ 00:00:45, ⭐ 00:42:15")
+		if glossary < 0 || highlights < glossary {
+			t.Errorf("system prompt = %q, want the glossary then the highlight markers", prompt)
+		}
+	})
+
+	t.Run("map and reduce calls list the markers", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+
+		base := restructure.NewOpenAIRestructurer("test-key",
+			restructure.WithBaseURL(server.URL),
+			restructure.WithRetryDelays(time.Millisecond, time.Millisecond),
+		)
+		mr := restructure.NewMapReduceRestructurer(base,
+			restructure.WithMapReduceMaxTokens(50),
+			restructure.WithMapReduceHighlights(marks),
+		)
+
+		transcript := strings.Repeat("a", 300) + "\n\n" + strings.Repeat("b", 300)
+		if _, _, err := mr.Restructure(context.Background(), transcript, template.MustParseName("meeting"), lang.Language{}); err != nil {
+			t.Fatalf("Restructure() unexpected error: %v", err)
+		}
+		if server.callCount() != 3 {
+			t.Fatalf("expected 3 API calls (2 map + 1 reduce), got %d", server.callCount())
+		}
+		for i, call := range server.calls {
+			if system := call.Messages[0]["content"]; !strings.Contains(system, "Highlights") || !strings.Contains(system, "⭐ 00:42:15") {
+				t.Errorf("call %d system prompt has no highlights:\n%s", i+1, system)
+			}
+		}
+	})
+
+	t.Run("no markers leaves the template prompt", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+		server.addResponse(http.StatusOK, openAIResponse("Notes."))
+
+		base := restructure.NewOpenAIRestructurer("test-key", restructure.WithBaseURL(server.URL))
+		mr := restructure.NewMapReduceRestructurer(base,
+			restructure.WithMapReduceMaxTokens(1000),
+			restructure.WithMapReduceHighlights(nil),
+		)
+
+		if _, _, err := mr.Restructure(context.Background(), "Short transcript.", template.MustParseName("meeting"), lang.Language{}); err != nil {
+			t.Fatalf("Restructure() unexpected error: %v", err)
+		}
+		if strings.Contains(server.systemPrompt(), "Highlights") {
+			t.Errorf("system prompt lists highlights without markers:\n%s", server.systemPrompt())
+		}
+	})
+}
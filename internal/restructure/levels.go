@@ -0,0 +1,127 @@
+package restructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// DefaultReduceLevels is the default maximum number of reduce levels (see
+// WithMapReduceLevels): enough for recordings of several days with hosted
+// models, and of a full day with local ones.
+const DefaultReduceLevels = 3
+
+// condensePrompt merges the outputs of consecutive parts into one, before the
+// final reduce call: unlike reducePrompt, it may condense, so that each level
+// shrinks what the next one merges.
+const condensePrompt = `You receive consecutive parts of a restructured markdown document, too long to be merged in one pass.
+Merge them into a single shorter document that will itself be merged with other parts.
+
+Rules:
+- Do not add a main title (H1) unless the first part has one
+- Merge sections that cover the same topic
+- Condense repeated or secondary points into concise bullet points
+- Keep every decision, action item, name, number and date
+- Keep "Key Ideas", "Decisions", "Actions" sections at the end (merged if present in multiple parts)
+- Do not alter meaning, do not invent anything`
+
+// WithMapReduceLevels sets the maximum number of reduce levels of long
+// transcripts. When the outputs of the parts are too long to be merged in a
+// single call, consecutive outputs are merged in groups first, and the groups
+// again, up to levels reduce levels in all; the last level merges what is
+// left in one call. 1 merges all parts in one call whatever their size.
+// Zero or less means DefaultReduceLevels.
+func WithMapReduceLevels(levels int) MapReduceOption {
+	return func(mr *MapReduceRestructurer) {
+		mr.levels = levels
+	}
+}
+
+// reduceLevels merges outputs into a single document, level by level: while
+// they do not fit in one call of maxTokens (or the provider rejects them as
+// too long), groups of consecutive outputs are condensed into one each.
+// Every call is made with call.
+func (mr *MapReduceRestructurer) reduceLevels(ctx context.Context, outputs []string, tmpl template.Name, outputLang lang.Language, call callOptions) (string, error) {
+	levels := mr.levels
+	if levels <= 0 {
+		levels = DefaultReduceLevels
+	}
+	condenseCall := call
+	condenseCall.stream = nil // Only the final reduce is streamed
+
+	for level := 1; ; level++ {
+		final := level >= levels
+		groups := groupOutputs(outputs, mr.maxTokens)
+		if len(groups) == 1 || final {
+			if mr.onProgress != nil {
+				mr.onProgress("reduce", 1, 1)
+			}
+			merged, err := mr.reduce(ctx, outputs, tmpl, outputLang, call)
+			if final || len(outputs) < 3 || !errors.Is(err, ErrTranscriptTooLong) {
+				return merged, err
+			}
+			// Estimated to fit, but rejected by the provider: halves first
+			half := len(outputs) / 2
+			groups = [][]string{outputs[:half], outputs[half:]}
+		}
+
+		next := make([]string, len(groups))
+		for i, group := range groups {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			if len(group) == 1 {
+				next[i] = group[0]
+				continue
+			}
+			if mr.onProgress != nil {
+				mr.onProgress("condense", i+1, len(groups))
+			}
+			condensed, err := mr.condense(ctx, group, outputLang, condenseCall)
+			if err != nil {
+				return "", fmt.Errorf("failed to condense group %d/%d of reduce level %d: %w", i+1, len(groups), level, err)
+			}
+			next[i] = condensed
+		}
+		outputs = next
+	}
+}
+
+// condense merges the outputs of consecutive parts into a shorter one.
+func (mr *MapReduceRestructurer) condense(ctx context.Context, outputs []string, outputLang lang.Language, call callOptions) (string, error) {
+	prompt := condensePrompt
+	if !outputLang.IsZero() && !outputLang.IsEnglish() {
+		prompt = fmt.Sprintf("Respond in %s.\n\n%s", outputLang.DisplayName(), prompt)
+	}
+	prompt = withHighlights(withGlossary(prompt, mr.glossary), mr.highlights)
+
+	return mr.restructurer.restructureCall(ctx, reduceInput(outputs), prompt, call)
+}
+
+// groupOutputs groups consecutive outputs whose reduce input fits in
+// maxTokens. Returns a single group if they all fit. Outputs too long to be
+// grouped are paired anyway, so that each level merges at least two outputs.
+func groupOutputs(outputs []string, maxTokens int) [][]string {
+	var groups [][]string
+	start := 0
+	for i := 1; i <= len(outputs); i++ {
+		if i < len(outputs) && estimateTokens(reduceInput(outputs[start:i+1])) <= maxTokens {
+			continue
+		}
+		groups = append(groups, outputs[start:i])
+		start = i
+	}
+	if len(groups) < len(outputs) {
+		return groups
+	}
+
+	// No two outputs fit together: pair them
+	groups = groups[:0]
+	for i := 0; i < len(outputs); i += 2 {
+		groups = append(groups, outputs[i:min(i+2, len(outputs))])
+	}
+	return groups
+}
@@ -0,0 +1,155 @@
+package restructure_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+func TestGroupOutputs(t *testing.T) {
+	t.Parallel()
+
+	short, long := strings.Repeat("s", 30), strings.Repeat("l", 150)
+	tests := []struct {
+		name    string
+		outputs []string
+		want    []int // Outputs per group
+	}{
+		{"all fit", []string{short, short, short}, []int{3}},
+		{"consecutive groups", []string{short, short, short, short, short, short, short}, []int{3, 3, 1}},
+		{"long output alone", []string{short, long, short, short}, []int{1, 1, 2}},
+		{"none fit together", []string{long, long, long}, []int{2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// 50 tokens: three short outputs and their part headers
+			groups := restructure.GroupOutputs(tt.outputs, 50)
+			var got []int
+			for _, group := range groups {
+				got = append(got, len(group))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("GroupOutputs() group sizes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapReduce_Levels(t *testing.T) {
+	t.Parallel()
+
+	// Six parts of one paragraph each, whose outputs do not fit in one call
+	var paragraphs []string
+	for i := range 6 {
+		paragraphs = append(paragraphs, strings.Repeat(string(rune('a'+i)), 135))
+	}
+	transcript := strings.Join(paragraphs, "\n\n")
+
+	run := func(t *testing.T, opts ...restructure.MapReduceOption) (*mockOpenAIServer, string) {
+		t.Helper()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+		for range paragraphs {
+			server.addResponse(http.StatusOK, openAIResponse(strings.Repeat("o", 100)))
+		}
+		server.addResponse(http.StatusOK, openAIResponse("Short"))
+
+		base := restructure.NewOpenAIRestructurer("test-key",
+			restructure.WithBaseURL(server.URL),
+			restructure.WithRetryDelays(time.Millisecond, time.Millisecond),
+		)
+		mr := restructure.NewMapReduceRestructurer(base, append([]restructure.MapReduceOption{restructure.WithMapReduceMaxTokens(50)}, opts...)...)
+		result, _, err := mr.Restructure(context.Background(), transcript, template.MustParseName("notes"), lang.Language{})
+		if err != nil {
+			t.Fatalf("Restructure() unexpected error: %v", err)
+		}
+		return server, result
+	}
+
+	t.Run("outputs condensed in groups", func(t *testing.T) {
+		t.Parallel()
+
+		server, result := run(t)
+		if result != "Short" {
+			t.Errorf("Restructure() = %q, want the final reduce output", result)
+		}
+		// 6 map calls, 3 condense calls of pairs, 1 reduce call
+		if server.callCount() != 10 {
+			t.Fatalf("expected 10 API calls, got %d", server.callCount())
+		}
+		for i, call := range server.calls[6:9] {
+			if system := call.Messages[0]["content"]; !strings.Contains(system, "single shorter document") {
+				t.Errorf("call %d system prompt is not a condense prompt:\n%s", 7+i, system)
+			}
+			if user := call.Messages[1]["content"]; strings.Count(user, "=== PART") != 2 {
+				t.Errorf("condense call %d merges %d parts, want 2", i+1, strings.Count(user, "=== PART"))
+			}
+		}
+		final := server.calls[9]
+		if system := final.Messages[0]["content"]; !strings.Contains(system, "single coherent document") {
+			t.Errorf("last call system prompt is not the reduce prompt:\n%s", system)
+		}
+		if user := final.Messages[1]["content"]; strings.Count(user, "=== PART") != 3 {
+			t.Errorf("reduce call merges %d parts, want 3", strings.Count(user, "=== PART"))
+		}
+	})
+
+	t.Run("single level merges all parts", func(t *testing.T) {
+		t.Parallel()
+
+		server, _ := run(t, restructure.WithMapReduceLevels(1))
+		if server.callCount() != 7 {
+			t.Fatalf("expected 7 API calls (6 map + 1 reduce), got %d", server.callCount())
+		}
+		if user := server.calls[6].Messages[1]["content"]; strings.Count(user, "=== PART") != 6 {
+			t.Errorf("reduce call merges %d parts, want 6", strings.Count(user, "=== PART"))
+		}
+	})
+}
+
+func TestMapReduce_LevelsAfterRejection(t *testing.T) {
+	t.Parallel()
+
+	server := newMockOpenAIServer()
+	t.Cleanup(server.Close)
+	for range 3 {
+		server.addResponse(http.StatusOK, openAIResponse("Part notes"))
+	}
+	// The outputs fit by estimate, but the provider rejects the reduce call
+	server.addResponse(http.StatusBadRequest, openAIErrorResponse("context_length issue", "invalid_request_error"))
+	server.addResponse(http.StatusOK, openAIResponse("Merged"))
+
+	base := restructure.NewOpenAIRestructurer("test-key",
+		restructure.WithBaseURL(server.URL),
+		restructure.WithRetryDelays(time.Millisecond, time.Millisecond),
+	)
+	mr := restructure.NewMapReduceRestructurer(base, restructure.WithMapReduceMaxTokens(200))
+
+	transcript := strings.Repeat("a", 500) + "\n\n" + strings.Repeat("b", 500) + "\n\n" + strings.Repeat("c", 500)
+	result, _, err := mr.Restructure(context.Background(), transcript, template.MustParseName("notes"), lang.Language{})
+	if err != nil {
+		t.Fatalf("Restructure() unexpected error: %v", err)
+	}
+	if result != "Merged" {
+		t.Errorf("Restructure() = %q, want %q", result, "Merged")
+	}
+	// 3 map calls, the rejected reduce call, 1 condense call (the first half
+	// is a single output), and the final reduce call
+	if server.callCount() != 6 {
+		t.Fatalf("expected 6 API calls, got %d", server.callCount())
+	}
+	if user := server.calls[4].Messages[1]["content"]; strings.Count(user, "=== PART") != 2 {
+		t.Errorf("condense call merges %d parts, want the second half (2)", strings.Count(user, "=== PART"))
+	}
+}
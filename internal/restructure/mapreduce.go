@@ -3,7 +3,9 @@ package restructure
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/alnah/go-transcript/internal/lang"
 	"github.com/alnah/go-transcript/internal/template"
@@ -11,7 +13,8 @@ import (
 
 // MapReduce configuration for long transcript handling.
 const (
-	// maxChunkTokens is the target size for each chunk.
+	// maxChunkTokens is the target size for each chunk when the context
+	// window of the model is unknown (see ChunkTokens).
 	// We use 80K to leave room for the prompt and response within the 128K limit.
 	maxChunkTokens = 80000
 
@@ -25,10 +28,13 @@ type TranscriptChunk struct {
 	Index   int    // 0-based index
 	Content string // The chunk content
 	Total   int    // Total number of chunks
+	Context string // End of the previous chunk, repeated before Content (see WithMapReduceOverlap)
 }
 
 // splitTranscript divides a transcript into chunks at paragraph boundaries.
 // Each chunk targets maxTokens size but respects paragraph boundaries.
+// Timed transcripts have no paragraphs (one line per segment, see
+// template.Name.Timed): they are divided at line boundaries.
 // Returns nil if transcript fits in a single chunk.
 func splitTranscript(transcript string, maxTokens int) []TranscriptChunk {
 	totalTokens := estimateTokens(transcript)
@@ -36,17 +42,27 @@ func splitTranscript(transcript string, maxTokens int) []TranscriptChunk {
 		return nil // No splitting needed
 	}
 
-	paragraphs := strings.Split(transcript, "\n\n")
+	if !strings.Contains(transcript, "\n\n") && timestampLine.MatchString(transcript) {
+		return packChunks(strings.Split(transcript, "\n"), "\n", maxTokens)
+	}
+	return packChunks(strings.Split(transcript, "\n\n"), "\n\n", maxTokens)
+}
+
+// packChunks groups consecutive segments, joined with sep, into chunks of
+// up to maxTokens. A segment longer than maxTokens is cut between sentences
+// (see splitSentences), never inside one.
+// Returns nil if they fit in a single chunk.
+func packChunks(segments []string, sep string, maxTokens int) []TranscriptChunk {
 	var chunks []TranscriptChunk
 	var currentChunk strings.Builder
 	currentTokens := 0
 
-	for _, para := range paragraphs {
-		paraTokens := estimateTokens(para)
+	add := func(text, sep string) {
+		tokens := estimateTokens(text)
 
-		// If single paragraph exceeds limit, we must include it anyway
-		// (splitting mid-paragraph would break coherence)
-		if currentTokens+paraTokens > maxTokens && currentChunk.Len() > 0 {
+		// If a single sentence exceeds limit, we must include it anyway
+		// (splitting mid-sentence would break coherence)
+		if currentTokens+tokens > maxTokens && currentChunk.Len() > 0 {
 			// Save current chunk and start new one
 			chunks = append(chunks, TranscriptChunk{
 				Index:   len(chunks),
@@ -57,10 +73,25 @@ func splitTranscript(transcript string, maxTokens int) []TranscriptChunk {
 		}
 
 		if currentChunk.Len() > 0 {
-			currentChunk.WriteString("\n\n")
+			currentChunk.WriteString(sep)
+		}
+		currentChunk.WriteString(text)
+		currentTokens += tokens
+	}
+
+	for _, segment := range segments {
+		if estimateTokens(segment) <= maxTokens {
+			add(segment, sep)
+			continue
+		}
+		// Sentences keep their trailing spaces: joined as they were
+		for i, sentence := range splitSentences(segment) {
+			if i == 0 {
+				add(sentence, sep)
+			} else {
+				add(sentence, "")
+			}
 		}
-		currentChunk.WriteString(para)
-		currentTokens += paraTokens
 	}
 
 	// Don't forget the last chunk
@@ -113,17 +144,47 @@ Rules:
 - Do not alter meaning, do not invent anything`
 )
 
+// splitParts splits transcript into the parts of MapReduce with split, each
+// part but the first repeating up to overlap tokens of the previous one (see
+// WithMapReduceOverlap), or returns nil if it fits in maxTokens.
+func splitParts(transcript string, maxTokens int, split Split, overlap int) []TranscriptChunk {
+	var chunks []TranscriptChunk
+	if split == SplitSpeakers {
+		chunks = splitTranscriptByTurns(transcript, maxTokens)
+	} else {
+		chunks = splitTranscript(transcript, maxTokens)
+	}
+	return addOverlap(chunks, min(overlap, maxTokens/2))
+}
+
 // buildMapPrompt creates the prompt for processing a single chunk.
 func buildMapPrompt(basePrompt string, chunk TranscriptChunk) string {
-	return fmt.Sprintf(mapChunkPromptPrefix, chunk.Index+1, chunk.Total, basePrompt)
+	prompt := fmt.Sprintf(mapChunkPromptPrefix, chunk.Index+1, chunk.Total, basePrompt)
+	if chunk.Context != "" {
+		prompt += "\n\n" + overlapInstruction
+	}
+	return prompt
 }
 
 // customPromptRestructurer is an internal interface for restructurers that support
 // custom prompts (required for MapReduce map/reduce phases).
-// Both OpenAIRestructurer and DeepSeekRestructurer implement this.
+// OpenAIRestructurer, DeepSeekRestructurer, AnthropicRestructurer and
+// OllamaRestructurer implement this.
 type customPromptRestructurer interface {
 	Restructurer
 	RestructureWithCustomPrompt(ctx context.Context, content, prompt string) (string, error)
+
+	// restructureCall is RestructureWithCustomPrompt with the settings of
+	// the call.
+	restructureCall(ctx context.Context, content, prompt string, call callOptions) (string, error)
+}
+
+// callOptions are the settings of a provider call of MapReduceRestructurer,
+// besides its messages. The zero value is a deterministic call.
+type callOptions struct {
+	temperature float64         // Sampling temperature (see WithMapReduceSelfConsistency); 0 means deterministic output
+	schema      *responseSchema // JSON schema the answer must follow (see ExtractActions); nil means free text
+	stream      io.Writer       // Receives the answer as it is generated (see WithMapReduceStream); nil means not streamed
 }
 
 // MapReducer processes transcripts with automatic chunking for long content.
@@ -139,25 +200,45 @@ var _ MapReducer = (*MapReduceRestructurer)(nil)
 
 // MapReduceRestructurer handles long transcripts by splitting, processing, and merging.
 // It works with any restructurer that implements customPromptRestructurer
-// (both OpenAIRestructurer and DeepSeekRestructurer).
+// (OpenAIRestructurer, DeepSeekRestructurer, AnthropicRestructurer or OllamaRestructurer).
 type MapReduceRestructurer struct {
-	restructurer customPromptRestructurer
-	maxTokens    int
-	onProgress   func(phase string, current, total int) // Optional progress callback
+	restructurer   customPromptRestructurer
+	maxTokens      int
+	maxTokensSet   bool                                   // maxTokens set explicitly, not from the model
+	contextWindows ContextWindows                         // Overrides of the default context windows
+	onProgress     func(phase string, current, total int) // Optional progress callback
+	usage          *UsageTracker                          // Optional token usage tracker
+	samples        int                                    // Self-consistency runs (<= 1: disabled)
+	split          Split                                  // How long transcripts are divided (default: paragraphs)
+	overlap        int                                    // Tokens of the previous part repeated in each part (optional)
+	levels         int                                    // Max reduce levels (<= 0: DefaultReduceLevels)
+	stream         io.Writer                              // Output of the final call as it is generated (optional)
+	glossary       []string                               // Terms listed in every system prompt (optional)
+	highlights     []time.Duration                        // Highlight markers listed in every system prompt (optional)
 }
 
 // MapReduceOption configures a MapReduceRestructurer.
 type MapReduceOption func(*MapReduceRestructurer)
 
 // WithMapReduceMaxTokens sets the max tokens per chunk.
+// It takes precedence over the context window of the model.
 func WithMapReduceMaxTokens(max int) MapReduceOption {
 	return func(mr *MapReduceRestructurer) {
 		if max > 0 {
 			mr.maxTokens = max
+			mr.maxTokensSet = true
 		}
 	}
 }
 
+// WithMapReduceContextWindows adds or overrides model context windows.
+// Chunks are sized from the context window of the restructurer's model.
+func WithMapReduceContextWindows(w ContextWindows) MapReduceOption {
+	return func(mr *MapReduceRestructurer) {
+		mr.contextWindows = w
+	}
+}
+
 // WithMapReduceProgress sets a progress callback.
 func WithMapReduceProgress(fn func(phase string, current, total int)) MapReduceOption {
 	return func(mr *MapReduceRestructurer) {
@@ -165,8 +246,19 @@ func WithMapReduceProgress(fn func(phase string, current, total int)) MapReduceO
 	}
 }
 
+// WithMapReduceUsageTracker records the token usage of every map and reduce call.
+// It has no effect if the wrapped restructurer does not report usage.
+func WithMapReduceUsageTracker(t *UsageTracker) MapReduceOption {
+	return func(mr *MapReduceRestructurer) {
+		mr.usage = t
+	}
+}
+
 // NewMapReduceRestructurer creates a MapReduceRestructurer wrapping an existing restructurer.
-// The restructurer must implement customPromptRestructurer (OpenAIRestructurer or DeepSeekRestructurer).
+// The restructurer must implement customPromptRestructurer (OpenAIRestructurer,
+// DeepSeekRestructurer, AnthropicRestructurer or OllamaRestructurer).
+// Chunks are sized from the context window of the restructurer's model, or
+// default to 80K tokens for models missing from the context window table.
 func NewMapReduceRestructurer(r customPromptRestructurer, opts ...MapReduceOption) *MapReduceRestructurer {
 	mr := &MapReduceRestructurer{
 		restructurer: r,
@@ -175,26 +267,47 @@ func NewMapReduceRestructurer(r customPromptRestructurer, opts ...MapReduceOptio
 	for _, opt := range opts {
 		opt(mr)
 	}
+	if ms, ok := r.(modelSizer); ok && !mr.maxTokensSet {
+		if window, ok := mr.contextWindows.merge().Lookup(ms.Model()); ok {
+			mr.maxTokens = ChunkTokens(window)
+			ms.setMaxInputTokens(mr.maxTokens)
+		}
+	}
+	if ur, ok := r.(usageReporter); ok && mr.usage != nil {
+		ur.setUsageTracker(mr.usage)
+	}
 	return mr
 }
 
 // Restructure processes a transcript, using MapReduce if it exceeds the token limit.
+// With self-consistency, the transcript is restructured several times and the
+// outputs are merged (see WithMapReduceSelfConsistency).
 // Returns the restructured output, whether MapReduce was used, and any error.
 func (mr *MapReduceRestructurer) Restructure(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+	if mr.samples > 1 {
+		return mr.selfConsistency(ctx, transcript, tmpl, outputLang)
+	}
+	return mr.restructure(ctx, transcript, tmpl, outputLang, callOptions{stream: mr.stream})
+}
+
+// restructure runs a single restructuring of transcript, using MapReduce if
+// needed, every provider call made with call.
+func (mr *MapReduceRestructurer) restructure(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language, call callOptions) (string, bool, error) {
 	// Check if MapReduce is needed
-	chunks := splitTranscript(transcript, mr.maxTokens)
+	chunks := splitParts(transcript, mr.maxTokens, mr.split, mr.overlap)
 	if chunks == nil {
-		// Fits in one chunk, use standard restructuring
-		result, err := mr.restructurer.Restructure(ctx, transcript, tmpl, outputLang)
+		// Fits in one chunk: the template prompt followed by the glossary and highlights
+		prompt := withHighlights(glossaryPrompt(tmpl, outputLang, mr.glossary), mr.highlights)
+		result, err := mr.restructurer.restructureCall(ctx, transcript, prompt, call)
 		return result, false, err
 	}
 
 	// MapReduce needed
-	return mr.mapReduce(ctx, chunks, tmpl, outputLang)
+	return mr.mapReduce(ctx, chunks, tmpl, outputLang, call)
 }
 
-// mapReduce executes the map and reduce phases.
-func (mr *MapReduceRestructurer) mapReduce(ctx context.Context, chunks []TranscriptChunk, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+// mapReduce executes the map and reduce phases, every call made with call.
+func (mr *MapReduceRestructurer) mapReduce(ctx context.Context, chunks []TranscriptChunk, tmpl template.Name, outputLang lang.Language, call callOptions) (string, bool, error) {
 	// Get base prompt from validated template
 	basePrompt := tmpl.Prompt()
 
@@ -202,8 +315,11 @@ func (mr *MapReduceRestructurer) mapReduce(ctx context.Context, chunks []Transcr
 	if !outputLang.IsZero() && !outputLang.IsEnglish() {
 		basePrompt = fmt.Sprintf("Respond in %s.\n\n%s", outputLang.DisplayName(), basePrompt)
 	}
+	basePrompt = withHighlights(withGlossary(basePrompt, mr.glossary), mr.highlights)
 
-	// Map phase: process each chunk
+	// Map phase: process each chunk (not streamed, only the final output is)
+	mapCall := call
+	mapCall.stream = nil
 	chunkOutputs := make([]string, len(chunks))
 	for i, chunk := range chunks {
 		if ctx.Err() != nil {
@@ -215,19 +331,15 @@ func (mr *MapReduceRestructurer) mapReduce(ctx context.Context, chunks []Transcr
 		}
 
 		mapPrompt := buildMapPrompt(basePrompt, chunk)
-		output, err := mr.restructurer.RestructureWithCustomPrompt(ctx, chunk.Content, mapPrompt)
+		output, err := mr.restructurer.restructureCall(ctx, chunk.input(), mapPrompt, mapCall)
 		if err != nil {
 			return "", true, fmt.Errorf("failed to process chunk %d/%d: %w", i+1, len(chunks), err)
 		}
 		chunkOutputs[i] = output
 	}
 
-	// Reduce phase: merge all outputs
-	if mr.onProgress != nil {
-		mr.onProgress("reduce", 1, 1)
-	}
-
-	merged, err := mr.reduce(ctx, chunkOutputs, outputLang)
+	// Reduce phase: merge all outputs, level by level if they are too long
+	merged, err := mr.reduceLevels(ctx, chunkOutputs, tmpl, outputLang, call)
 	if err != nil {
 		return "", true, fmt.Errorf("failed to merge chunks: %w", err)
 	}
@@ -235,9 +347,21 @@ func (mr *MapReduceRestructurer) mapReduce(ctx context.Context, chunks []Transcr
 	return merged, true, nil
 }
 
-// reduce merges multiple chunk outputs into a coherent document.
-func (mr *MapReduceRestructurer) reduce(ctx context.Context, outputs []string, outputLang lang.Language) (string, error) {
-	// Build the input for the reduce phase
+// reduce merges multiple chunk outputs of tmpl into a coherent document.
+func (mr *MapReduceRestructurer) reduce(ctx context.Context, outputs []string, tmpl template.Name, outputLang lang.Language, call callOptions) (string, error) {
+	// Build reduce prompt with language instruction (skip for English, template's native language)
+	prompt := reducePrompt
+	if !outputLang.IsZero() && !outputLang.IsEnglish() {
+		prompt = fmt.Sprintf("Respond in %s.\n\n%s", outputLang.DisplayName(), prompt)
+	}
+	prompt = withAnchors(prompt, tmpl)
+	prompt = withHighlights(withGlossary(prompt, mr.glossary), mr.highlights)
+
+	return mr.restructurer.restructureCall(ctx, reduceInput(outputs), prompt, call)
+}
+
+// reduceInput builds the content of the reduce call from the outputs of the parts.
+func reduceInput(outputs []string) string {
 	var input strings.Builder
 	for i, output := range outputs {
 		if i > 0 {
@@ -245,12 +369,5 @@ func (mr *MapReduceRestructurer) reduce(ctx context.Context, outputs []string, o
 		}
 		fmt.Fprintf(&input, "=== PART %d ===\n\n%s", i+1, output)
 	}
-
-	// Build reduce prompt with language instruction (skip for English, template's native language)
-	prompt := reducePrompt
-	if !outputLang.IsZero() && !outputLang.IsEnglish() {
-		prompt = fmt.Sprintf("Respond in %s.\n\n%s", outputLang.DisplayName(), prompt)
-	}
-
-	return mr.restructurer.RestructureWithCustomPrompt(ctx, input.String(), prompt)
+	return input.String()
 }
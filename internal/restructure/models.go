@@ -0,0 +1,104 @@
+package restructure
+
+import "strings"
+
+// ContextWindows maps model names to their context window, in tokens.
+// A name also matches its dated snapshots: "gpt-4o" matches "gpt-4o-2024-08-06".
+type ContextWindows map[string]int
+
+// defaultContextWindows are the context windows of the hosted models most
+// used for restructuring. Users extend or override them with
+// WithMapReduceContextWindows (the context-windows config key).
+var defaultContextWindows = ContextWindows{
+	// DeepSeek
+	"deepseek-chat":     128000,
+	"deepseek-reasoner": 128000,
+
+	// OpenAI
+	"o3":           200000,
+	"o3-mini":      200000,
+	"o4-mini":      200000,
+	"gpt-4o":       128000,
+	"gpt-4o-mini":  128000,
+	"gpt-4.1":      1047576,
+	"gpt-4.1-mini": 1047576,
+	"gpt-4.1-nano": 1047576,
+	"gpt-5":        400000,
+	"gpt-5-mini":   400000,
+
+	// Anthropic
+	"claude-3-5-haiku":  200000,
+	"claude-sonnet-4-0": 200000,
+	"claude-sonnet-4-5": 200000,
+	"claude-opus-4-1":   200000,
+	"claude-haiku-4-5":  200000,
+}
+
+// Lookup returns the context window of model.
+// An exact name wins; otherwise the longest name model is a snapshot of.
+func (w ContextWindows) Lookup(model string) (int, bool) {
+	return lookupModel(w, model)
+}
+
+// lookupModel returns the value of model in m. An exact name wins; otherwise
+// the longest name model is a snapshot of ("gpt-4o" for "gpt-4o-2024-08-06").
+func lookupModel[V any](m map[string]V, model string) (V, bool) {
+	if v, ok := m[model]; ok {
+		return v, true
+	}
+	var (
+		best string
+		v    V
+	)
+	for name, value := range m {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best, v = name, value
+		}
+	}
+	return v, best != ""
+}
+
+// merge returns the default windows overridden by w.
+func (w ContextWindows) merge() ContextWindows {
+	merged := make(ContextWindows, len(defaultContextWindows)+len(w))
+	for name, n := range defaultContextWindows {
+		merged[name] = n
+	}
+	for name, n := range w {
+		merged[name] = n
+	}
+	return merged
+}
+
+// PartTokens returns the MapReduce part size MapReduceRestructurer uses for
+// model: ChunkTokens of its context window, looked up in windows and the
+// built-in table, or the default part size for unknown models.
+func PartTokens(model string, windows ContextWindows) int {
+	if window, ok := windows.merge().Lookup(model); ok {
+		return ChunkTokens(window)
+	}
+	return maxChunkTokens
+}
+
+// ChunkTokens returns the MapReduce chunk size for a context window.
+// A chunk takes 5/8 of the window, leaving room for the prompt and the
+// response: 80K tokens for a 128K window.
+func ChunkTokens(contextWindow int) int {
+	return contextWindow * 5 / 8
+}
+
+// modelSizer is implemented by providers whose input limit follows the
+// context window of their model. MapReduceRestructurer sizes its chunks from
+// the model and aligns the provider limit, so that a transcript sent in a
+// single call is never rejected as too long.
+type modelSizer interface {
+	Model() string
+	setMaxInputTokens(n int)
+}
+
+// Compile-time interface compliance checks.
+var (
+	_ modelSizer = (*OpenAIRestructurer)(nil)
+	_ modelSizer = (*DeepSeekRestructurer)(nil)
+	_ modelSizer = (*AnthropicRestructurer)(nil)
+)
@@ -0,0 +1,174 @@
+package restructure_test
+
+import (
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/restructure"
+)
+
+// ---------------------------------------------------------------------------
+// TestContextWindows_Lookup - Exact names and dated snapshots
+// ---------------------------------------------------------------------------
+
+func TestContextWindows_Lookup(t *testing.T) {
+	t.Parallel()
+
+	windows := restructure.ContextWindows{
+		"gpt-4o":      128000,
+		"gpt-4o-mini": 64000,
+		"o3":          200000,
+	}
+
+	tests := []struct {
+		model  string
+		want   int
+		wantOK bool
+	}{
+		{"gpt-4o", 128000, true},
+		{"gpt-4o-2024-08-06", 128000, true},
+		{"gpt-4o-mini-2024-07-18", 64000, true}, // Longest name wins
+		{"o3", 200000, true},
+		{"o3mini", 0, false}, // Not a snapshot of o3
+		{"llama3.1", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := windows.Lookup(tt.model)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Lookup(%q) = %d, %v, want %d, %v", tt.model, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestChunkTokens(t *testing.T) {
+	t.Parallel()
+
+	if got := restructure.ChunkTokens(128000); got != 80000 {
+		t.Errorf("ChunkTokens(128000) = %d, want 80000", got)
+	}
+	if got := restructure.ChunkTokens(200000); got != 125000 {
+		t.Errorf("ChunkTokens(200000) = %d, want 125000", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestMapReduceRestructurer_ChunkSize - Chunks follow the model's context window
+// ---------------------------------------------------------------------------
+
+func TestMapReduceRestructurer_ChunkSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		model   string
+		opts    []restructure.MapReduceOption
+		want    int
+		wantMax int // Provider input limit
+	}{
+		{
+			name:    "known model",
+			model:   "gpt-4.1",
+			want:    restructure.ChunkTokens(1047576),
+			wantMax: restructure.ChunkTokens(1047576),
+		},
+		{
+			name:    "dated snapshot",
+			model:   "gpt-4o-2024-08-06",
+			want:    80000,
+			wantMax: 80000,
+		},
+		{
+			name:    "unknown model keeps the default",
+			model:   "my-finetune",
+			want:    80000,
+			wantMax: 100000,
+		},
+		{
+			name:    "configured window",
+			model:   "my-finetune",
+			opts:    []restructure.MapReduceOption{restructure.WithMapReduceContextWindows(restructure.ContextWindows{"my-finetune": 32000})},
+			want:    20000,
+			wantMax: 20000,
+		},
+		{
+			name:    "configured window overrides the default",
+			model:   "gpt-4o",
+			opts:    []restructure.MapReduceOption{restructure.WithMapReduceContextWindows(restructure.ContextWindows{"gpt-4o": 64000})},
+			want:    40000,
+			wantMax: 40000,
+		},
+		{
+			name:    "explicit max tokens wins",
+			model:   "gpt-4.1",
+			opts:    []restructure.MapReduceOption{restructure.WithMapReduceMaxTokens(5000)},
+			want:    5000,
+			wantMax: 100000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := restructure.NewOpenAIRestructurer("sk-test", restructure.WithModel(tt.model))
+			mr := restructure.NewMapReduceRestructurer(r, tt.opts...)
+
+			if got := mr.MaxTokens(); got != tt.want {
+				t.Errorf("chunk size = %d, want %d", got, tt.want)
+			}
+			if got := r.MaxInputTokens(); got != tt.wantMax {
+				t.Errorf("provider input limit = %d, want %d", got, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestMapReduceRestructurer_DefaultModels(t *testing.T) {
+	t.Parallel()
+
+	deepseek, err := restructure.NewDeepSeekRestructurer("sk-test")
+	if err != nil {
+		t.Fatalf("NewDeepSeekRestructurer() unexpected error: %v", err)
+	}
+	anthropic, err := restructure.NewAnthropicRestructurer("sk-ant-test")
+	if err != nil {
+		t.Fatalf("NewAnthropicRestructurer() unexpected error: %v", err)
+	}
+
+	if got := restructure.NewMapReduceRestructurer(deepseek).MaxTokens(); got != 80000 {
+		t.Errorf("deepseek chunk size = %d, want 80000", got)
+	}
+	if got := restructure.NewMapReduceRestructurer(anthropic).MaxTokens(); got != 125000 {
+		t.Errorf("anthropic chunk size = %d, want 125000", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestPartTokens - MapReduce part size of a model
+// ---------------------------------------------------------------------------
+
+func TestPartTokens(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		model   string
+		windows restructure.ContextWindows
+		want    int
+	}{
+		{"built-in window", "claude-sonnet-4-5", nil, 125000},
+		{"configured window", "my-model", restructure.ContextWindows{"my-model": 16000}, 10000},
+		{"unknown model uses default", "my-model", nil, 80000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := restructure.PartTokens(tt.model, tt.windows); got != tt.want {
+				t.Errorf("PartTokens(%q) = %d, want %d", tt.model, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,463 @@
+package restructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// Ollama configuration.
+const (
+	// API endpoint (local server)
+	DefaultOllamaBaseURL = "http://localhost:11434"
+
+	// Model configuration
+	// Local models have much smaller context windows than hosted ones, and
+	// Ollama silently truncates prompts that do not fit: the window is set
+	// explicitly and long transcripts go through MapReduce.
+	DefaultOllamaModel           = "llama3.1"
+	defaultOllamaContextWindow   = 32768
+	defaultOllamaMaxInputTokens  = 20000 // Leaves room for the prompt and the output
+	defaultOllamaMaxOutputTokens = 8192
+
+	// Retry configuration
+	defaultOllamaMaxRetries  = 2
+	defaultOllamaBaseDelay   = 1 * time.Second
+	defaultOllamaMaxDelay    = 10 * time.Second
+	defaultOllamaHTTPTimeout = 30 * time.Minute // Local inference is slow on CPU
+)
+
+// Compile-time interface compliance check.
+var _ Restructurer = (*OllamaRestructurer)(nil)
+
+// OllamaRestructurer restructures transcripts using a local Ollama server,
+// so restructuring can run fully offline. No API key is needed.
+// It supports automatic retries with exponential backoff for transient errors.
+type OllamaRestructurer struct {
+	baseURL         string
+	model           string
+	contextWindow   int
+	maxInputTokens  int
+	maxOutputTokens int
+	maxRetries      int
+	baseDelay       time.Duration
+	maxDelay        time.Duration
+	httpTimeout     time.Duration
+	httpClient      httpDoer
+	usage           *UsageTracker // Optional, set by MapReduceRestructurer.
+}
+
+// OllamaOption configures an OllamaRestructurer.
+type OllamaOption func(*OllamaRestructurer)
+
+// WithOllamaModel sets the model for restructuring.
+// The model must have been pulled (ollama pull <model>).
+func WithOllamaModel(model string) OllamaOption {
+	return func(r *OllamaRestructurer) {
+		if model != "" {
+			r.model = model
+		}
+	}
+}
+
+// WithOllamaContextWindow sets the context window requested from Ollama (num_ctx).
+func WithOllamaContextWindow(tokens int) OllamaOption {
+	return func(r *OllamaRestructurer) {
+		if tokens > 0 {
+			r.contextWindow = tokens
+		}
+	}
+}
+
+// WithOllamaMaxInputTokens sets the maximum input token limit.
+func WithOllamaMaxInputTokens(max int) OllamaOption {
+	return func(r *OllamaRestructurer) {
+		if max > 0 {
+			r.maxInputTokens = max
+		}
+	}
+}
+
+// WithOllamaMaxOutputTokens sets the maximum output token limit.
+func WithOllamaMaxOutputTokens(max int) OllamaOption {
+	return func(r *OllamaRestructurer) {
+		if max > 0 {
+			r.maxOutputTokens = max
+		}
+	}
+}
+
+// WithOllamaMaxRetries sets the maximum number of retry attempts.
+func WithOllamaMaxRetries(n int) OllamaOption {
+	return func(r *OllamaRestructurer) {
+		if n >= 0 {
+			r.maxRetries = n
+		}
+	}
+}
+
+// WithOllamaRetryDelays sets the base and max delays for exponential backoff.
+func WithOllamaRetryDelays(base, max time.Duration) OllamaOption {
+	return func(r *OllamaRestructurer) {
+		if base > 0 {
+			r.baseDelay = base
+		}
+		if max > 0 {
+			r.maxDelay = max
+		}
+	}
+}
+
+// WithOllamaBaseURL sets the URL of the Ollama server.
+func WithOllamaBaseURL(url string) OllamaOption {
+	return func(r *OllamaRestructurer) {
+		if url != "" {
+			r.baseURL = strings.TrimSuffix(url, "/")
+		}
+	}
+}
+
+// WithOllamaHTTPTimeout sets the HTTP client timeout.
+func WithOllamaHTTPTimeout(timeout time.Duration) OllamaOption {
+	return func(r *OllamaRestructurer) {
+		if timeout > 0 {
+			r.httpTimeout = timeout
+		}
+	}
+}
+
+// withOllamaHTTPClient sets a custom HTTP client (for testing).
+func withOllamaHTTPClient(client httpDoer) OllamaOption {
+	return func(r *OllamaRestructurer) {
+		r.httpClient = client
+	}
+}
+
+// NewOllamaRestructurer creates a new OllamaRestructurer.
+// Without options, it uses DefaultOllamaModel on DefaultOllamaBaseURL.
+func NewOllamaRestructurer(opts ...OllamaOption) *OllamaRestructurer {
+	r := &OllamaRestructurer{
+		baseURL:         DefaultOllamaBaseURL,
+		model:           DefaultOllamaModel,
+		contextWindow:   defaultOllamaContextWindow,
+		maxInputTokens:  defaultOllamaMaxInputTokens,
+		maxOutputTokens: defaultOllamaMaxOutputTokens,
+		maxRetries:      defaultOllamaMaxRetries,
+		baseDelay:       defaultOllamaBaseDelay,
+		maxDelay:        defaultOllamaMaxDelay,
+		httpTimeout:     defaultOllamaHTTPTimeout,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	// Create HTTP client after options are applied (timeout may be customized)
+	if r.httpClient == nil {
+		r.httpClient = &http.Client{Timeout: r.httpTimeout}
+	}
+	return r
+}
+
+// MaxInputTokens returns the largest transcript (in estimated tokens) the
+// model is given at once. MapReduce chunks must not exceed it.
+func (r *OllamaRestructurer) MaxInputTokens() int {
+	return r.maxInputTokens
+}
+
+// Restructure transforms a raw transcript into structured markdown using the specified template.
+// outputLang specifies the output language. Zero value uses template's native language (English).
+// Returns ErrTranscriptTooLong if the transcript exceeds the token limit (estimated).
+// Automatically retries on transient errors (timeouts, server errors while the model loads).
+func (r *OllamaRestructurer) Restructure(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, error) {
+	// 1. Get prompt from validated template
+	prompt := tmpl.Prompt()
+
+	// 2. Add language instruction if output is not English
+	if !outputLang.IsZero() && !outputLang.IsEnglish() {
+		prompt = fmt.Sprintf("Respond in %s.\n\n%s", outputLang.DisplayName(), prompt)
+	}
+
+	// 3. Estimate tokens and check limit
+	estimatedTokens := estimateTokens(transcript)
+	if estimatedTokens > r.maxInputTokens {
+		return "", fmt.Errorf("transcript too long (%dK tokens estimated, max %dK): %w",
+			estimatedTokens/1000, r.maxInputTokens/1000, ErrTranscriptTooLong)
+	}
+
+	// 4. Call API with retry
+	return r.restructureWithRetry(ctx, r.newRequest(prompt, transcript, callOptions{}), nil)
+}
+
+// RestructureWithCustomPrompt executes restructuring with a custom prompt (used by MapReduce).
+// Unlike Restructure, this does not resolve templates or check token limits.
+func (r *OllamaRestructurer) RestructureWithCustomPrompt(ctx context.Context, content, prompt string) (string, error) {
+	return r.restructureCall(ctx, content, prompt, callOptions{})
+}
+
+// restructureCall implements customPromptRestructurer.
+func (r *OllamaRestructurer) restructureCall(ctx context.Context, content, prompt string, call callOptions) (string, error) {
+	return r.restructureWithRetry(ctx, r.newRequest(prompt, content, call), call.stream)
+}
+
+// newRequest builds a chat request made with call.
+func (r *OllamaRestructurer) newRequest(system, content string, call callOptions) ollamaRequest {
+	return ollamaRequest{
+		Model: r.model,
+		Messages: []ollamaMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: content},
+		},
+		Stream: call.stream != nil,
+		Format: ollamaFormat(call.schema),
+		Options: ollamaOptions{
+			Temperature: call.temperature, // Deterministic output unless sampling
+			NumCtx:      r.contextWindow,
+			NumPredict:  r.maxOutputTokens,
+		},
+	}
+}
+
+// restructureWithRetry executes the restructuring with exponential backoff retry,
+// writing the answer to stream as it is generated unless stream is nil.
+func (r *OllamaRestructurer) restructureWithRetry(ctx context.Context, req ollamaRequest, stream io.Writer) (string, error) {
+	cfg := apierr.RetryConfig{
+		MaxRetries: r.maxRetries,
+		BaseDelay:  r.baseDelay,
+		MaxDelay:   r.maxDelay,
+	}
+
+	out := newStreamOutput(stream)
+
+	return apierr.RetryWithBackoff(ctx, cfg, func() (string, error) {
+		resp, err := r.callAPI(ctx, req, out)
+		if err != nil {
+			return "", out.interrupted(classifyOllamaError(err))
+		}
+		r.usage.Record(Usage{
+			PromptTokens:     resp.PromptEvalCount,
+			CompletionTokens: resp.EvalCount,
+		})
+
+		if resp.Message.Content == "" {
+			return "", fmt.Errorf("no response from Ollama")
+		}
+		return resp.Message.Content, nil
+	}, isRetryableOllamaError)
+}
+
+// setUsageTracker implements usageReporter.
+func (r *OllamaRestructurer) setUsageTracker(t *UsageTracker) {
+	r.usage = t
+}
+
+// ollamaRequest represents an Ollama /api/chat request.
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Format   json.RawMessage `json:"format,omitempty"` // JSON schema of the answer
+	Options  ollamaOptions   `json:"options"`
+}
+
+// ollamaMessage represents a message in the conversation.
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ollamaOptions holds the model parameters of a request.
+type ollamaOptions struct {
+	Temperature float64 `json:"temperature"` // 0 for deterministic output, higher for self-consistency runs
+	NumCtx      int     `json:"num_ctx"`     // Context window
+	NumPredict  int     `json:"num_predict"` // Max output tokens
+}
+
+// ollamaResponse represents an Ollama /api/chat response, or a line of a
+// streamed one: the last line is done and carries the token counts.
+type ollamaResponse struct {
+	Model           string        `json:"model"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	Error           string        `json:"error"` // Streamed responses only
+}
+
+// callAPI makes an HTTP request to the Ollama server.
+// A streamed response (reqBody.Stream) is written to out as it arrives.
+func (r *OllamaRestructurer) callAPI(ctx context.Context, reqBody ollamaRequest, out *streamOutput) (_ *ollamaResponse, err error) {
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := r.baseURL + "/api/chat"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	if resp.StatusCode == http.StatusOK && reqBody.Stream {
+		return readOllamaStream(resp.Body, out)
+	}
+
+	// Limit response size to prevent OOM from malformed responses
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseOllamaError(resp.StatusCode, respBody)
+	}
+
+	var result ollamaResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// readOllamaStream reads a streamed /api/chat response into a response,
+// writing its content to out as it arrives. An error line sent after the
+// response started is returned as a server error.
+func readOllamaStream(body io.Reader, out *streamOutput) (*ollamaResponse, error) {
+	var content strings.Builder
+	var result ollamaResponse
+	err := readStream(body, func(data []byte) error {
+		var line ollamaResponse
+		if err := json.Unmarshal(data, &line); err != nil {
+			return fmt.Errorf("failed to parse streamed response: %w", err)
+		}
+		if line.Error != "" {
+			return &ollamaAPIError{StatusCode: http.StatusInternalServerError, Message: line.Error}
+		}
+		content.WriteString(line.Message.Content)
+		if err := out.write(line.Message.Content); err != nil {
+			return fmt.Errorf("failed to write streamed output: %w", err)
+		}
+		if line.Done {
+			result = line
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Message.Content = content.String()
+	return &result, nil
+}
+
+// ollamaAPIError represents a typed Ollama error.
+type ollamaAPIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *ollamaAPIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("Ollama error %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("Ollama error %d", e.StatusCode)
+}
+
+// parseOllamaError parses an error response from Ollama ({"error": "..."}).
+func parseOllamaError(statusCode int, body []byte) *ollamaAPIError {
+	var errResp struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Error == "" {
+		// If we can't parse the error, return a generic error
+		return &ollamaAPIError{
+			StatusCode: statusCode,
+			Message:    string(body),
+		}
+	}
+	return &ollamaAPIError{
+		StatusCode: statusCode,
+		Message:    errResp.Error,
+	}
+}
+
+// classifyOllamaError maps Ollama errors to sentinel errors.
+// Server errors (e.g. while a model loads) are left unclassified:
+// they are retried, see isRetryableOllamaError.
+func classifyOllamaError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *ollamaAPIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusNotFound: // Model not pulled
+			return fmt.Errorf("%s (pull it with: ollama pull <model>): %w", apiErr.Message, apierr.ErrBadRequest)
+		case http.StatusBadRequest:
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrBadRequest)
+		case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrTimeout)
+		}
+	}
+
+	// Nothing listens on the base URL
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return fmt.Errorf("%w (start it with: ollama serve): %v", ErrOllamaUnreachable, err)
+	}
+
+	// Check for context timeout/deadline exceeded
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("request timed out: %w", apierr.ErrTimeout)
+	}
+
+	return err
+}
+
+// isRetryableOllamaError determines if an error is transient and should be retried.
+func isRetryableOllamaError(err error) bool {
+	// Part of a streamed response was written: a retry would write it again.
+	if errors.Is(err, ErrStreamInterrupted) {
+		return false
+	}
+
+	// Timeouts are retryable
+	if errors.Is(err, apierr.ErrTimeout) {
+		return true
+	}
+
+	// Server errors (5xx) are retryable
+	var apiErr *ollamaAPIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
+			return true
+		}
+	}
+
+	// Everything else (canceled context, unreachable server, missing model)
+	// fails the same way on every attempt.
+	return false
+}
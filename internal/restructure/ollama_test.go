@@ -0,0 +1,441 @@
+package restructure_test
+
+// Notes:
+// - Tests use black-box approach via package restructure_test
+// - Internal functions are tested via export_test.go exports
+// - Uses httptest.Server to mock the Ollama /api/chat endpoint
+// - Retry delays are set to 1ms to keep tests fast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// ---------------------------------------------------------------------------
+// Helpers - Ollama mock server
+// ---------------------------------------------------------------------------
+
+// ollamaResponse creates a mock non-streaming /api/chat response.
+func ollamaResponse(content string) map[string]any {
+	return map[string]any{
+		"model":             "llama3.1",
+		"message":           map[string]any{"role": "assistant", "content": content},
+		"done":              true,
+		"done_reason":       "stop",
+		"prompt_eval_count": 100,
+		"eval_count":        50,
+	}
+}
+
+// ollamaCall is a request received by the mock server.
+type ollamaCall struct {
+	Model    string
+	Stream   bool
+	Messages []map[string]string
+	Options  map[string]float64
+}
+
+// mockOllamaServer creates a test server that returns predefined responses.
+type mockOllamaServer struct {
+	*httptest.Server
+	mu          sync.Mutex
+	calls       []ollamaCall
+	responses   []mockResponse
+	responseIdx int
+}
+
+func newMockOllamaServer() *mockOllamaServer {
+	m := &mockOllamaServer{}
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if r.URL.Path != "/api/chat" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+
+		var req ollamaCall
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		m.calls = append(m.calls, req)
+
+		var resp mockResponse
+		if m.responseIdx < len(m.responses) {
+			resp = m.responses[m.responseIdx]
+			m.responseIdx++
+		} else if len(m.responses) > 0 {
+			resp = m.responses[len(m.responses)-1]
+		} else {
+			resp = mockResponse{statusCode: http.StatusOK, body: ollamaResponse("Default response")}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.statusCode)
+		json.NewEncoder(w).Encode(resp.body)
+	}))
+	return m
+}
+
+func (m *mockOllamaServer) addResponse(statusCode int, body any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses = append(m.responses, mockResponse{statusCode, body})
+}
+
+func (m *mockOllamaServer) callCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls)
+}
+
+func (m *mockOllamaServer) lastCall() ollamaCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.calls) == 0 {
+		return ollamaCall{}
+	}
+	return m.calls[len(m.calls)-1]
+}
+
+// ---------------------------------------------------------------------------
+// TestNewOllamaRestructurer - Constructor
+// ---------------------------------------------------------------------------
+
+func TestNewOllamaRestructurer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("needs no API key", func(t *testing.T) {
+		t.Parallel()
+
+		if r := restructure.NewOllamaRestructurer(); r == nil {
+			t.Fatal("NewOllamaRestructurer() = nil")
+		}
+	})
+
+	t.Run("options with invalid values are ignored", func(t *testing.T) {
+		t.Parallel()
+
+		r := restructure.NewOllamaRestructurer(
+			restructure.WithOllamaModel(""),
+			restructure.WithOllamaBaseURL(""),
+			restructure.WithOllamaContextWindow(0),
+			restructure.WithOllamaMaxInputTokens(0),
+			restructure.WithOllamaMaxOutputTokens(-1),
+			restructure.WithOllamaMaxRetries(-1),
+			restructure.WithOllamaHTTPTimeout(0),
+		)
+		if r.MaxInputTokens() <= 0 {
+			t.Errorf("MaxInputTokens() = %d, want default", r.MaxInputTokens())
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// TestClassifyOllamaError - Error classification
+// ---------------------------------------------------------------------------
+
+func TestClassifyOllamaError(t *testing.T) {
+	t.Parallel()
+
+	if got := restructure.ClassifyOllamaError(nil); got != nil {
+		t.Errorf("ClassifyOllamaError(nil) = %v, want nil", got)
+	}
+	if got := restructure.ClassifyOllamaError(context.DeadlineExceeded); !errors.Is(got, apierr.ErrTimeout) {
+		t.Errorf("ClassifyOllamaError(DeadlineExceeded) = %v, want ErrTimeout", got)
+	}
+	dial := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	if got := restructure.ClassifyOllamaError(dial); !errors.Is(got, restructure.ErrOllamaUnreachable) {
+		t.Errorf("ClassifyOllamaError(dial error) = %v, want ErrOllamaUnreachable", got)
+	}
+	random := errors.New("random error")
+	if got := restructure.ClassifyOllamaError(random); got != random {
+		t.Errorf("ClassifyOllamaError(random) = %v, want unchanged", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestIsRetryableOllamaError - Retry decision
+// ---------------------------------------------------------------------------
+
+func TestIsRetryableOllamaError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "timeout is retryable", err: apierr.ErrTimeout, want: true},
+		{name: "context canceled is not retryable", err: context.Canceled, want: false},
+		{name: "unreachable server is not retryable", err: restructure.ErrOllamaUnreachable, want: false},
+		{name: "bad request is not retryable", err: apierr.ErrBadRequest, want: false},
+		{name: "transcript too long is not retryable", err: restructure.ErrTranscriptTooLong, want: false},
+		{name: "unknown error is not retryable", err: errors.New("random error"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := restructure.IsRetryableOllamaError(tt.err); got != tt.want {
+				t.Errorf("IsRetryableOllamaError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestOllamaRestructurer_Restructure - Main restructuring
+// ---------------------------------------------------------------------------
+
+func TestOllamaRestructurer_Restructure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("happy path sends model, messages and options", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOllamaServer()
+		t.Cleanup(server.Close)
+		server.addResponse(http.StatusOK, ollamaResponse("# Restructured"))
+
+		r := restructure.NewOllamaRestructurer(
+			restructure.WithOllamaBaseURL(server.URL+"/"),
+			restructure.WithOllamaModel("qwen2.5:14b"),
+			restructure.WithOllamaContextWindow(16384),
+		)
+
+		result, err := r.Restructure(context.Background(), "Raw transcript.", template.MustParseName("meeting"), lang.MustParse("fr"))
+		if err != nil {
+			t.Fatalf("Restructure() unexpected error: %v", err)
+		}
+		if result != "# Restructured" {
+			t.Errorf("Restructure() = %q, want %q", result, "# Restructured")
+		}
+
+		call := server.lastCall()
+		if call.Model != "qwen2.5:14b" {
+			t.Errorf("Model = %q, want %q", call.Model, "qwen2.5:14b")
+		}
+		if call.Stream {
+			t.Error("Stream = true, want false")
+		}
+		if call.Options["num_ctx"] != 16384 || call.Options["num_predict"] <= 0 {
+			t.Errorf("Options = %v, want num_ctx 16384 and num_predict > 0", call.Options)
+		}
+		if len(call.Messages) != 2 || call.Messages[0]["role"] != "system" || call.Messages[1]["content"] != "Raw transcript." {
+			t.Fatalf("Messages = %v, want system prompt and transcript", call.Messages)
+		}
+		if !strings.Contains(call.Messages[0]["content"], "Respond in French") {
+			t.Errorf("system prompt = %q, want language instruction", call.Messages[0]["content"])
+		}
+	})
+
+	t.Run("default model", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOllamaServer()
+		t.Cleanup(server.Close)
+
+		r := restructure.NewOllamaRestructurer(restructure.WithOllamaBaseURL(server.URL))
+		if _, err := r.RestructureWithCustomPrompt(context.Background(), "content", "prompt"); err != nil {
+			t.Fatalf("RestructureWithCustomPrompt() unexpected error: %v", err)
+		}
+		if got := server.lastCall().Model; got != restructure.DefaultOllamaModel {
+			t.Errorf("Model = %q, want %q", got, restructure.DefaultOllamaModel)
+		}
+	})
+
+	t.Run("empty content returns error", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOllamaServer()
+		t.Cleanup(server.Close)
+		server.addResponse(http.StatusOK, ollamaResponse(""))
+
+		r := restructure.NewOllamaRestructurer(
+			restructure.WithOllamaBaseURL(server.URL),
+			restructure.WithOllamaMaxRetries(0),
+		)
+		_, err := r.Restructure(context.Background(), "transcript", template.MustParseName("meeting"), lang.Language{})
+		if err == nil || !strings.Contains(err.Error(), "no response") {
+			t.Errorf("Restructure() error = %v, want containing %q", err, "no response")
+		}
+	})
+
+	t.Run("transcript too long returns error without calling server", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOllamaServer()
+		t.Cleanup(server.Close)
+
+		r := restructure.NewOllamaRestructurer(
+			restructure.WithOllamaBaseURL(server.URL),
+			restructure.WithOllamaMaxInputTokens(10),
+		)
+		_, err := r.Restructure(context.Background(), strings.Repeat("x", 100), template.MustParseName("meeting"), lang.Language{})
+		if !errors.Is(err, restructure.ErrTranscriptTooLong) {
+			t.Errorf("Restructure(long_transcript) error = %v, want ErrTranscriptTooLong", err)
+		}
+		if server.callCount() != 0 {
+			t.Errorf("callCount() = %d, want 0", server.callCount())
+		}
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		t.Parallel()
+
+		// A closed server refuses connections
+		server := newMockOllamaServer()
+		server.Close()
+
+		r := restructure.NewOllamaRestructurer(
+			restructure.WithOllamaBaseURL(server.URL),
+			restructure.WithOllamaRetryDelays(time.Millisecond, time.Millisecond),
+		)
+		_, err := r.Restructure(context.Background(), "transcript", template.MustParseName("meeting"), lang.Language{})
+		if !errors.Is(err, restructure.ErrOllamaUnreachable) {
+			t.Errorf("Restructure() error = %v, want ErrOllamaUnreachable", err)
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// TestOllamaRestructurer_HTTPErrors - Server error handling
+// ---------------------------------------------------------------------------
+
+func TestOllamaRestructurer_HTTPErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		statusCode int
+		body       any
+		wantErr    error
+		wantMsg    string
+		retryable  bool
+	}{
+		{
+			name:       "404 model not pulled",
+			statusCode: http.StatusNotFound,
+			body:       map[string]any{"error": `model "llama3.1" not found, try pulling it first`},
+			wantErr:    apierr.ErrBadRequest,
+			wantMsg:    "ollama pull",
+		},
+		{
+			name:       "400 invalid request",
+			statusCode: http.StatusBadRequest,
+			body:       map[string]any{"error": "invalid options"},
+			wantErr:    apierr.ErrBadRequest,
+		},
+		{
+			name:       "500 while loading the model",
+			statusCode: http.StatusInternalServerError,
+			body:       map[string]any{"error": "llama runner process has terminated"},
+			retryable:  true,
+		},
+		{
+			name:       "503 server busy",
+			statusCode: http.StatusServiceUnavailable,
+			body:       map[string]any{"error": "server busy, please try again"},
+			retryable:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newMockOllamaServer()
+			t.Cleanup(server.Close)
+
+			// For retryable errors, add multiple failures then success
+			server.addResponse(tt.statusCode, tt.body)
+			if tt.retryable {
+				server.addResponse(tt.statusCode, tt.body)
+				server.addResponse(http.StatusOK, ollamaResponse("success"))
+			}
+
+			r := restructure.NewOllamaRestructurer(
+				restructure.WithOllamaBaseURL(server.URL),
+				restructure.WithOllamaMaxRetries(2),
+				restructure.WithOllamaRetryDelays(time.Millisecond, time.Millisecond),
+			)
+
+			result, err := r.Restructure(context.Background(), "transcript", template.MustParseName("meeting"), lang.Language{})
+
+			if tt.retryable {
+				if err != nil {
+					t.Fatalf("Restructure() expected success after retries, got error: %v", err)
+				}
+				if result != "success" {
+					t.Errorf("Restructure() = %q, want %q", result, "success")
+				}
+				if server.callCount() != 3 {
+					t.Errorf("callCount() = %d, want 3 (2 failures + 1 success)", server.callCount())
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Restructure() error = %v, want error wrapping %v", err, tt.wantErr)
+			}
+			if tt.wantMsg != "" && (err == nil || !strings.Contains(err.Error(), tt.wantMsg)) {
+				t.Errorf("Restructure() error = %v, want containing %q", err, tt.wantMsg)
+			}
+			if server.callCount() != 1 {
+				t.Errorf("callCount() = %d, want 1 (no retry)", server.callCount())
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestOllamaRestructurer_WithMapReduce - MapReduce integration
+// ---------------------------------------------------------------------------
+
+func TestOllamaRestructurer_WithMapReduce(t *testing.T) {
+	t.Parallel()
+
+	server := newMockOllamaServer()
+	t.Cleanup(server.Close)
+	server.addResponse(http.StatusOK, ollamaResponse("# Part 1"))
+	server.addResponse(http.StatusOK, ollamaResponse("# Part 2"))
+	server.addResponse(http.StatusOK, ollamaResponse("# Merged"))
+
+	base := restructure.NewOllamaRestructurer(
+		restructure.WithOllamaBaseURL(server.URL),
+		restructure.WithOllamaMaxInputTokens(50),
+	)
+	usage := restructure.NewUsageTracker()
+	mr := restructure.NewMapReduceRestructurer(base,
+		restructure.WithMapReduceMaxTokens(base.MaxInputTokens()), // Chunks fit the model
+		restructure.WithMapReduceUsageTracker(usage),
+	)
+
+	transcript := strings.Repeat("a", 150) + "\n\n" + strings.Repeat("b", 150)
+	result, usedMapReduce, err := mr.Restructure(context.Background(), transcript, template.MustParseName("meeting"), lang.Language{})
+	if err != nil {
+		t.Fatalf("Restructure() unexpected error: %v", err)
+	}
+	if !usedMapReduce || result != "# Merged" {
+		t.Errorf("Restructure() = %q, %v; want %q, true", result, usedMapReduce, "# Merged")
+	}
+	if got := usage.Total().PromptTokens; got != 300 {
+		t.Errorf("usage prompt tokens = %d, want 300 (3 calls x 100)", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -22,9 +23,9 @@ const (
 	defaultOpenAIBaseURL = "https://api.openai.com"
 
 	// Model configuration.
-	defaultRestructureModel = "o4-mini"
-	defaultMaxInputTokens   = 100000
-	defaultMaxOutputTokens  = 100000 // o4-mini max output tokens
+	DefaultOpenAIModel     = "o4-mini"
+	defaultMaxInputTokens  = 100000
+	defaultMaxOutputTokens = 100000 // o4-mini max output tokens
 
 	// Retry configuration: fewer retries than transcriber (longer latency).
 	defaultRestructureMaxRetries = 3
@@ -33,6 +34,10 @@ const (
 
 	// HTTP timeout for OpenAI chat completion requests.
 	defaultOpenAIHTTPTimeout = 10 * time.Minute
+
+	// DefaultAzureAPIVersion is the Azure OpenAI API version used when none
+	// is configured (see WithAzure).
+	DefaultAzureAPIVersion = "2024-10-21"
 )
 
 // Compile-time interface compliance check.
@@ -50,6 +55,14 @@ type OpenAIRestructurer struct {
 	maxDelay       time.Duration
 	httpTimeout    time.Duration
 	httpClient     httpDoer
+	usage          *UsageTracker    // Optional, set by MapReduceRestructurer.
+	azure          *azureDeployment // Azure OpenAI deployment, nil for OpenAI
+}
+
+// azureDeployment is the Azure OpenAI deployment requests are sent to.
+type azureDeployment struct {
+	name       string
+	apiVersion string
 }
 
 // Option configures an OpenAIRestructurer.
@@ -97,6 +110,20 @@ func WithBaseURL(url string) Option {
 	}
 }
 
+// WithAzure sends the requests to the deployment of an Azure OpenAI resource,
+// whose endpoint (https://NAME.openai.azure.com) is set with WithBaseURL.
+// The API key is sent in the api-key header. An empty apiVersion selects
+// DefaultAzureAPIVersion. The deployment sets the model: WithModel still
+// sizes the chunks of long transcripts and prices the calls.
+func WithAzure(deployment, apiVersion string) Option {
+	return func(r *OpenAIRestructurer) {
+		if apiVersion == "" {
+			apiVersion = DefaultAzureAPIVersion
+		}
+		r.azure = &azureDeployment{name: deployment, apiVersion: apiVersion}
+	}
+}
+
 // WithHTTPClient sets a custom HTTP client (for testing).
 func WithHTTPClient(c httpDoer) Option {
 	return func(r *OpenAIRestructurer) {
@@ -110,7 +137,7 @@ func NewOpenAIRestructurer(apiKey string, opts ...Option) *OpenAIRestructurer {
 	r := &OpenAIRestructurer{
 		apiKey:         apiKey,
 		baseURL:        defaultOpenAIBaseURL,
-		model:          defaultRestructureModel,
+		model:          DefaultOpenAIModel,
 		maxInputTokens: defaultMaxInputTokens,
 		maxRetries:     defaultRestructureMaxRetries,
 		baseDelay:      defaultRestructureBaseDelay,
@@ -155,7 +182,6 @@ func (r *OpenAIRestructurer) Restructure(ctx context.Context, transcript string,
 	req := openAIRequest{
 		Model:               r.model,
 		MaxCompletionTokens: defaultMaxOutputTokens,
-		Temperature:         0, // Deterministic output for reproducibility
 		Messages: []openAIMessage{
 			{Role: "system", Content: prompt},
 			{Role: "user", Content: transcript},
@@ -163,37 +189,56 @@ func (r *OpenAIRestructurer) Restructure(ctx context.Context, transcript string,
 	}
 
 	// 5. Call API with retry
-	return r.restructureWithRetry(ctx, req)
+	return r.restructureWithRetry(ctx, req, nil)
 }
 
 // RestructureWithCustomPrompt executes restructuring with a custom prompt (used by MapReduce).
 // Unlike Restructure, this does not resolve templates or check token limits.
 func (r *OpenAIRestructurer) RestructureWithCustomPrompt(ctx context.Context, content, prompt string) (string, error) {
+	return r.restructureCall(ctx, content, prompt, callOptions{})
+}
+
+// restructureCall implements customPromptRestructurer.
+func (r *OpenAIRestructurer) restructureCall(ctx context.Context, content, prompt string, call callOptions) (string, error) {
 	req := openAIRequest{
 		Model:               r.model,
 		MaxCompletionTokens: defaultMaxOutputTokens,
-		Temperature:         0,
+		Temperature:         call.temperature, // Deterministic output unless sampling
 		Messages: []openAIMessage{
 			{Role: "system", Content: prompt},
 			{Role: "user", Content: content},
 		},
+		ResponseFormat: openAIFormat(call.schema),
 	}
-	return r.restructureWithRetry(ctx, req)
+	return r.restructureWithRetry(ctx, req, call.stream)
 }
 
-// restructureWithRetry executes the restructuring with exponential backoff retry.
-func (r *OpenAIRestructurer) restructureWithRetry(ctx context.Context, req openAIRequest) (string, error) {
+// restructureWithRetry executes the restructuring with exponential backoff retry,
+// writing the answer to stream as it is generated unless stream is nil.
+func (r *OpenAIRestructurer) restructureWithRetry(ctx context.Context, req openAIRequest, stream io.Writer) (string, error) {
 	cfg := apierr.RetryConfig{
 		MaxRetries: r.maxRetries,
 		BaseDelay:  r.baseDelay,
 		MaxDelay:   r.maxDelay,
 	}
 
+	out := newStreamOutput(stream)
+	if out != nil {
+		req.Stream = true
+		req.StreamOptions = &chatStreamOptions{IncludeUsage: true}
+	}
+
 	return apierr.RetryWithBackoff(ctx, cfg, func() (string, error) {
-		resp, err := r.callAPI(ctx, req)
+		resp, err := r.callAPI(ctx, req, out)
 		if err != nil {
-			return "", classifyRestructureError(err)
+			// Keep the wait requested by the API through classification
+			delay, _ := apierr.RetryAfter(err)
+			return "", out.interrupted(apierr.WithRetryAfter(classifyRestructureError(err), delay))
 		}
+		r.usage.Record(Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+		})
 		if len(resp.Choices) == 0 {
 			return "", fmt.Errorf("no response from API")
 		}
@@ -201,14 +246,32 @@ func (r *OpenAIRestructurer) restructureWithRetry(ctx context.Context, req openA
 	}, isRetryableRestructureError)
 }
 
+// Model returns the model used for restructuring.
+func (r *OpenAIRestructurer) Model() string {
+	return r.model
+}
+
+// setMaxInputTokens implements modelSizer.
+func (r *OpenAIRestructurer) setMaxInputTokens(n int) {
+	r.maxInputTokens = n
+}
+
+// setUsageTracker implements usageReporter.
+func (r *OpenAIRestructurer) setUsageTracker(t *UsageTracker) {
+	r.usage = t
+}
+
 // OpenAI chat completion request/response types.
 
 // openAIRequest represents an OpenAI chat completion request.
 type openAIRequest struct {
-	Model               string          `json:"model"`
-	Messages            []openAIMessage `json:"messages"`
-	MaxCompletionTokens int             `json:"max_completion_tokens,omitempty"`
-	Temperature         float64         `json:"temperature"`
+	Model               string                `json:"model"`
+	Messages            []openAIMessage       `json:"messages"`
+	MaxCompletionTokens int                   `json:"max_completion_tokens,omitempty"`
+	Temperature         float64               `json:"temperature"`
+	Stream              bool                  `json:"stream,omitempty"`
+	StreamOptions       *chatStreamOptions    `json:"stream_options,omitempty"`
+	ResponseFormat      *openAIResponseFormat `json:"response_format,omitempty"`
 }
 
 // openAIMessage represents a message in the conversation.
@@ -219,40 +282,54 @@ type openAIMessage struct {
 
 // openAIResponse represents an OpenAI chat completion response.
 type openAIResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	Model   string `json:"model"`
-	Choices []struct {
-		Index   int `json:"index"`
-		Message struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
-		} `json:"message"`
-		FinishReason string `json:"finish_reason"`
-	} `json:"choices"`
-	Usage struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []openAIChoice `json:"choices"`
+	Usage   struct {
 		PromptTokens     int `json:"prompt_tokens"`
 		CompletionTokens int `json:"completion_tokens"`
 		TotalTokens      int `json:"total_tokens"`
 	} `json:"usage"`
 }
 
+// openAIChoice is a choice of an OpenAI chat completion response.
+type openAIChoice struct {
+	Index        int           `json:"index"`
+	Message      openAIMessage `json:"message"`
+	FinishReason string        `json:"finish_reason"`
+}
+
+// requestURL returns the URL of chat completion requests: the OpenAI path, or
+// the path of the Azure OpenAI deployment with its API version.
+func (r *OpenAIRestructurer) requestURL() string {
+	if r.azure == nil {
+		return r.baseURL + "/v1/chat/completions"
+	}
+	return r.baseURL + "/openai/deployments/" + url.PathEscape(r.azure.name) +
+		"/chat/completions?api-version=" + url.QueryEscape(r.azure.apiVersion)
+}
+
 // callAPI makes an HTTP request to the OpenAI chat completion API.
-func (r *OpenAIRestructurer) callAPI(ctx context.Context, reqBody openAIRequest) (_ *openAIResponse, err error) {
+// A streamed response (reqBody.Stream) is written to out as it arrives.
+func (r *OpenAIRestructurer) callAPI(ctx context.Context, reqBody openAIRequest, out *streamOutput) (_ *openAIResponse, err error) {
 	body, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := r.baseURL + "/v1/chat/completions"
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.requestURL(), bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+r.apiKey)
+	if r.azure != nil {
+		req.Header.Set("api-key", r.apiKey)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
 
 	resp, err := r.httpClient.Do(req)
 	if err != nil {
@@ -264,6 +341,10 @@ func (r *OpenAIRestructurer) callAPI(ctx context.Context, reqBody openAIRequest)
 		}
 	}()
 
+	if resp.StatusCode == http.StatusOK && reqBody.Stream {
+		return readOpenAIStream(resp.Body, out)
+	}
+
 	// Limit response size to prevent OOM from malformed responses
 	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
 	if err != nil {
@@ -271,7 +352,7 @@ func (r *OpenAIRestructurer) callAPI(ctx context.Context, reqBody openAIRequest)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, parseOpenAIError(resp.StatusCode, respBody)
+		return nil, apierr.WithRetryAfter(parseOpenAIError(resp.StatusCode, respBody), apierr.ParseRetryAfter(resp.Header, time.Now()))
 	}
 
 	var result openAIResponse
@@ -282,6 +363,22 @@ func (r *OpenAIRestructurer) callAPI(ctx context.Context, reqBody openAIRequest)
 	return &result, nil
 }
 
+// readOpenAIStream reads a streamed chat completion into a response, writing
+// its content to out as it arrives.
+func readOpenAIStream(body io.Reader, out *streamOutput) (*openAIResponse, error) {
+	content, usage, err := readChatStream(body, out)
+	if err != nil {
+		return nil, err
+	}
+	var result openAIResponse
+	result.Usage.PromptTokens = usage.PromptTokens
+	result.Usage.CompletionTokens = usage.CompletionTokens
+	if content != "" {
+		result.Choices = []openAIChoice{{Message: openAIMessage{Role: "assistant", Content: content}}}
+	}
+	return &result, nil
+}
+
 // openAIAPIError represents a typed OpenAI API error.
 // Unexported: only used for error classification within the restructure package.
 type openAIAPIError struct {
@@ -377,6 +474,11 @@ func classifyRestructureError(err error) error {
 
 // isRetryableRestructureError determines if an error is transient and should be retried.
 func isRetryableRestructureError(err error) bool {
+	// Part of a streamed response was written: a retry would write it again.
+	if errors.Is(err, ErrStreamInterrupted) {
+		return false
+	}
+
 	// Rate limits are retryable (with backoff).
 	if errors.Is(err, apierr.ErrRateLimit) {
 		return true
@@ -73,8 +73,10 @@ type mockOpenAIServer struct {
 }
 
 type openAICall struct {
-	Model    string
-	Messages []map[string]string
+	Model          string
+	Messages       []map[string]string
+	Temperature    float64
+	ResponseFormat json.RawMessage
 }
 
 type mockOpenAIResp struct {
@@ -94,6 +96,8 @@ func newMockOpenAIServer() *mockOpenAIServer {
 				Role    string `json:"role"`
 				Content string `json:"content"`
 			} `json:"messages"`
+			Temperature    float64         `json:"temperature"`
+			ResponseFormat json.RawMessage `json:"response_format"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			http.Error(w, "bad request", http.StatusBadRequest)
@@ -108,8 +112,10 @@ func newMockOpenAIServer() *mockOpenAIServer {
 			}
 		}
 		m.calls = append(m.calls, openAICall{
-			Model:    req.Model,
-			Messages: messages,
+			Model:          req.Model,
+			Messages:       messages,
+			Temperature:    req.Temperature,
+			ResponseFormat: req.ResponseFormat,
 		})
 
 		var resp mockOpenAIResp
@@ -702,3 +708,74 @@ func TestOpenAIRetryBehavior(t *testing.T) {
 		}
 	})
 }
+
+// ---------------------------------------------------------------------------
+// TestOpenAIRestructurer_Usage - Token usage reporting
+// ---------------------------------------------------------------------------
+
+func TestOpenAIRestructurer_Usage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records usage of successful calls only", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+
+		server.addResponse(http.StatusTooManyRequests, openAIErrorResponse("rate limit", "rate_limit_error"))
+		server.addResponse(http.StatusOK, openAIResponse("Success"))
+
+		r := restructure.NewOpenAIRestructurer("test-key",
+			restructure.WithBaseURL(server.URL),
+			restructure.WithRetryDelays(time.Millisecond, time.Millisecond),
+		)
+		tracker := restructure.NewUsageTracker()
+		mr := restructure.NewMapReduceRestructurer(r, restructure.WithMapReduceUsageTracker(tracker))
+
+		if _, _, err := mr.Restructure(context.Background(), "transcript", template.MustParseName("meeting"), lang.Language{}); err != nil {
+			t.Fatalf("Restructure() unexpected error: %v", err)
+		}
+
+		if got, want := tracker.Calls(), 1; got != want {
+			t.Errorf("Calls() = %d, want %d", got, want)
+		}
+		want := restructure.Usage{PromptTokens: 100, CompletionTokens: 50}
+		if got := tracker.Total(); got != want {
+			t.Errorf("Total() = %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestOpenAIRestructurer_Azure(t *testing.T) {
+	t.Parallel()
+
+	var gotPath, gotQuery, gotAPIKey, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery = r.URL.Path, r.URL.RawQuery
+		gotAPIKey, gotAuth = r.Header.Get("api-key"), r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(openAIResponse("# Notes"))
+	}))
+	t.Cleanup(server.Close)
+
+	r := restructure.NewOpenAIRestructurer("azure-key",
+		restructure.WithBaseURL(server.URL+"/"),
+		restructure.WithAzure("notes-gpt4o", ""),
+	)
+	got, err := r.Restructure(context.Background(), "transcript", template.MustParseName("notes"), lang.Language{})
+	if err != nil {
+		t.Fatalf("Restructure() unexpected error: %v", err)
+	}
+	if got != "# Notes" {
+		t.Errorf("Restructure() = %q, want %q", got, "# Notes")
+	}
+	if want := "/openai/deployments/notes-gpt4o/chat/completions"; gotPath != want {
+		t.Errorf("path = %q, want %q", gotPath, want)
+	}
+	if want := "api-version=" + restructure.DefaultAzureAPIVersion; gotQuery != want {
+		t.Errorf("query = %q, want %q", gotQuery, want)
+	}
+	if gotAPIKey != "azure-key" || gotAuth != "" {
+		t.Errorf("api-key = %q, Authorization = %q, want the key in api-key only", gotAPIKey, gotAuth)
+	}
+}
@@ -0,0 +1,81 @@
+package restructure
+
+import (
+	"fmt"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// overlapInstruction tells map calls that their part starts with the end of
+// the previous part (see WithMapReduceOverlap).
+const overlapInstruction = `The part starts with the end of the previous part, between "[Context: end of the previous part]" and "[End of context]", so that you know how it continues. It was already processed: do not restructure it again.`
+
+// overlapFormat is the user message of a map call with context: the end of
+// the previous part, then the part.
+const overlapFormat = "[Context: end of the previous part]\n%s\n[End of context]\n\n%s"
+
+// sentenceEnd matches the end of a sentence and the spaces after it:
+// punctuation, then closing quotes or brackets.
+var sentenceEnd = regexp.MustCompile(`[.!?…]+["'”’»)\]]*\s+`)
+
+// WithMapReduceOverlap repeats, at the start of each part of a long
+// transcript, up to tokens of the end of the previous part, so that the map
+// call of a part knows how the previous one ended. The repeated text is
+// marked as context, not to be restructured again. It is cut between
+// sentences, and never more than half a part. Zero (the default) disables it.
+func WithMapReduceOverlap(tokens int) MapReduceOption {
+	return func(mr *MapReduceRestructurer) {
+		mr.overlap = max(tokens, 0)
+	}
+}
+
+// splitSentences cuts text after each sentence end followed by a sentence
+// start (not a lowercase letter, as after "e.g."). Sentences keep their
+// trailing spaces, so that concatenating them gives text back.
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for _, m := range sentenceEnd.FindAllStringIndex(text, -1) {
+		next, _ := utf8.DecodeRuneInString(text[m[1]:])
+		if m[1] == len(text) || unicode.IsLower(next) {
+			continue
+		}
+		sentences = append(sentences, text[start:m[1]])
+		start = m[1]
+	}
+	if start < len(text) {
+		sentences = append(sentences, text[start:])
+	}
+	return sentences
+}
+
+// addOverlap sets the context of each chunk but the first: the last whole
+// sentences of the previous chunk, up to tokens. Chunks are returned as is
+// with tokens <= 0.
+func addOverlap(chunks []TranscriptChunk, tokens int) []TranscriptChunk {
+	if tokens <= 0 {
+		return chunks
+	}
+	for i := 1; i < len(chunks); i++ {
+		sentences := splitSentences(chunks[i-1].Content)
+		first, used := len(sentences), 0
+		for first > 0 && used+estimateTokens(sentences[first-1]) <= tokens {
+			first--
+			used += estimateTokens(sentences[first])
+		}
+		for _, s := range sentences[first:] {
+			chunks[i].Context += s
+		}
+	}
+	return chunks
+}
+
+// input returns the user message of the map call of the chunk: its content,
+// preceded by its context if it has one.
+func (c TranscriptChunk) input() string {
+	if c.Context == "" {
+		return c.Content
+	}
+	return fmt.Sprintf(overlapFormat, c.Context, c.Content)
+}
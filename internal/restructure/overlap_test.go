@@ -0,0 +1,115 @@
+package restructure_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// sentences returns n sentences of about 20 tokens, lettered from A.
+func sentences(n int) []string {
+	s := make([]string, n)
+	for i := range s {
+		s[i] = "Sentence " + strings.Repeat("x", 40) + " number " + string(rune('A'+i)) + "."
+	}
+	return s
+}
+
+func TestSplitTranscript_Sentences(t *testing.T) {
+	t.Parallel()
+
+	t.Run("long paragraph cut between sentences", func(t *testing.T) {
+		t.Parallel()
+
+		paragraph := strings.Join(sentences(10), " ")
+		chunks := restructure.SplitTranscript(paragraph, 50)
+		if len(chunks) < 2 {
+			t.Fatalf("SplitTranscript() = %d chunks, want several", len(chunks))
+		}
+		var parts []string
+		for i, chunk := range chunks {
+			if !strings.HasPrefix(chunk.Content, "Sentence ") || !strings.HasSuffix(chunk.Content, ".") {
+				t.Errorf("chunk %d = %q, want whole sentences", i, chunk.Content)
+			}
+			parts = append(parts, chunk.Content)
+		}
+		if got := strings.Join(parts, " "); got != paragraph {
+			t.Errorf("chunks joined = %q, want %q", got, paragraph)
+		}
+	})
+
+	t.Run("abbreviation is not a sentence end", func(t *testing.T) {
+		t.Parallel()
+
+		first := "We use tools, e.g. " + strings.Repeat("y", 150) + "."
+		paragraph := first + " " + strings.Join(sentences(3), " ")
+		chunks := restructure.SplitTranscript(paragraph, 60)
+		if len(chunks) < 2 || chunks[0].Content != first {
+			t.Errorf("SplitTranscript() first chunk = %q, want %q", chunks[0].Content, first)
+		}
+	})
+
+	t.Run("paragraphs kept whole when they fit", func(t *testing.T) {
+		t.Parallel()
+
+		s := sentences(4)
+		transcript := s[0] + " " + s[1] + "\n\n" + s[2] + " " + s[3]
+		chunks := restructure.SplitTranscript(transcript, 45)
+		if len(chunks) != 2 || chunks[0].Content != s[0]+" "+s[1] {
+			t.Errorf("SplitTranscript() = %+v, want one chunk per paragraph", chunks)
+		}
+	})
+}
+
+func TestMapReduce_Overlap(t *testing.T) {
+	t.Parallel()
+
+	server := newMockOpenAIServer()
+	t.Cleanup(server.Close)
+
+	base := restructure.NewOpenAIRestructurer("test-key",
+		restructure.WithBaseURL(server.URL),
+		restructure.WithRetryDelays(time.Millisecond, time.Millisecond),
+	)
+	mr := restructure.NewMapReduceRestructurer(base,
+		restructure.WithMapReduceMaxTokens(60),
+		restructure.WithMapReduceOverlap(25),
+	)
+
+	s := sentences(6)
+	transcript := strings.Join(s[:3], " ") + "\n\n" + strings.Join(s[3:], " ")
+	if _, mapReduced, err := mr.Restructure(context.Background(), transcript, template.MustParseName("notes"), lang.Language{}); err != nil || !mapReduced {
+		t.Fatalf("Restructure() = mapReduced %v, error %v, want MapReduce", mapReduced, err)
+	}
+	if server.callCount() != 3 {
+		t.Fatalf("expected 3 API calls (2 map + 1 reduce), got %d", server.callCount())
+	}
+
+	var first, second bool
+	for _, call := range server.calls[:2] {
+		user, system := call.Messages[1]["content"], call.Messages[0]["content"]
+		switch {
+		case strings.HasPrefix(user, s[0]):
+			first = true
+			if strings.Contains(system, "already processed") {
+				t.Errorf("first map call has overlap instruction:\n%s", system)
+			}
+		case strings.HasPrefix(user, "[Context: end of the previous part]\n"+s[2]+"\n[End of context]"):
+			second = true
+			if !strings.HasSuffix(user, s[5]) || strings.Contains(user, s[1]) {
+				t.Errorf("second map call content = %q, want the last sentence of part 1 then part 2", user)
+			}
+			if !strings.Contains(system, "already processed") {
+				t.Errorf("second map call has no overlap instruction:\n%s", system)
+			}
+		}
+	}
+	if !first || !second {
+		t.Errorf("map calls = %+v, want part 1, and part 2 with context", server.calls[:2])
+	}
+}
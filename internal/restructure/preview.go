@@ -0,0 +1,57 @@
+package restructure
+
+import (
+	"fmt"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// PromptPreview is a call restructuring would make: the system and user
+// messages sent to the provider.
+type PromptPreview struct {
+	Step   string // "restructure", or "map" and "reduce" with MapReduce
+	Part   int    // Part of a map call (1-based), 0 for other steps
+	System string
+	User   string
+}
+
+// PreviewPrompts returns the calls MapReduceRestructurer makes to restructure
+// transcript with tmpl, in parts of partTokens (see PartTokens) split with
+// split and overlapping by overlap tokens, with glossary in every system
+// prompt (see WithMapReduceGlossary), without calling any API. The reduce
+// call merges the outputs of the map calls, unknown before they are made: its
+// user message holds placeholders, and the condense calls of long outputs
+// (see WithMapReduceLevels) are not known either.
+func PreviewPrompts(transcript string, tmpl template.Name, outputLang lang.Language, partTokens int, split Split, overlap int, glossary []string) []PromptPreview {
+	glossary = glossary[:min(len(glossary), maxGlossaryTerms)]
+	prompt := tmpl.Prompt()
+	if !outputLang.IsZero() && !outputLang.IsEnglish() {
+		prompt = fmt.Sprintf("Respond in %s.\n\n%s", outputLang.DisplayName(), prompt)
+	}
+	prompt = withGlossary(prompt, glossary)
+
+	chunks := splitParts(transcript, partTokens, split, overlap)
+	if chunks == nil {
+		return []PromptPreview{{Step: "restructure", System: prompt, User: transcript}}
+	}
+
+	calls := make([]PromptPreview, 0, len(chunks)+1)
+	outputs := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		calls = append(calls, PromptPreview{
+			Step:   "map",
+			Part:   i + 1,
+			System: buildMapPrompt(prompt, chunk),
+			User:   chunk.input(),
+		})
+		outputs[i] = fmt.Sprintf("<output of part %d>", i+1)
+	}
+
+	reduce := reducePrompt
+	if !outputLang.IsZero() && !outputLang.IsEnglish() {
+		reduce = fmt.Sprintf("Respond in %s.\n\n%s", outputLang.DisplayName(), reduce)
+	}
+	reduce = withGlossary(reduce, glossary)
+	return append(calls, PromptPreview{Step: "reduce", System: reduce, User: reduceInput(outputs)})
+}
@@ -0,0 +1,74 @@
+package restructure_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+func TestPreviewPrompts(t *testing.T) {
+	t.Parallel()
+
+	tmpl := template.MustParseName("meeting")
+
+	t.Run("single call", func(t *testing.T) {
+		t.Parallel()
+
+		calls := restructure.PreviewPrompts("Hello everyone.", tmpl, lang.Language{}, 1000, restructure.SplitParagraphs, 0, nil)
+		if len(calls) != 1 {
+			t.Fatalf("PreviewPrompts() = %d calls, want 1", len(calls))
+		}
+		want := restructure.PromptPreview{Step: "restructure", System: tmpl.Prompt(), User: "Hello everyone."}
+		if calls[0] != want {
+			t.Errorf("PreviewPrompts() = %+v, want %+v", calls[0], want)
+		}
+	})
+
+	t.Run("map and reduce", func(t *testing.T) {
+		t.Parallel()
+
+		paragraph := strings.Repeat("word ", 20)
+		transcript := paragraph + "\n\n" + paragraph + "\n\n" + paragraph
+		calls := restructure.PreviewPrompts(transcript, tmpl, lang.MustParse("fr"), 40, restructure.SplitParagraphs, 0, nil)
+		if len(calls) < 3 {
+			t.Fatalf("PreviewPrompts() = %d calls, want map calls and a reduce call", len(calls))
+		}
+
+		var parts []string
+		for i, call := range calls[:len(calls)-1] {
+			if call.Step != "map" || call.Part != i+1 {
+				t.Errorf("call %d = %s part %d, want map part %d", i, call.Step, call.Part, i+1)
+			}
+			if !strings.Contains(call.System, "Respond in French.\n\n"+tmpl.Prompt()) {
+				t.Errorf("call %d system = %q, want the language and template prompt", i, call.System)
+			}
+			parts = append(parts, strings.TrimSpace(call.User))
+		}
+		if got := strings.Join(parts, " "); strings.Join(strings.Fields(got), " ") != strings.Join(strings.Fields(transcript), " ") {
+			t.Errorf("map calls hold %q, want the whole transcript", got)
+		}
+
+		reduce := calls[len(calls)-1]
+		if reduce.Step != "reduce" || !strings.HasPrefix(reduce.System, "Respond in French.") {
+			t.Errorf("last call = %+v, want the reduce call in French", reduce)
+		}
+		if !strings.Contains(reduce.User, "=== PART 1 ===\n\n<output of part 1>") {
+			t.Errorf("reduce user = %q, want placeholders of the part outputs", reduce.User)
+		}
+	})
+	t.Run("glossary", func(t *testing.T) {
+		t.Parallel()
+
+		paragraph := strings.Repeat("word ", 20)
+		transcript := paragraph + "\n\n" + paragraph + "\n\n" + paragraph
+		calls := restructure.PreviewPrompts(transcript, tmpl, lang.Language{}, 40, restructure.SplitParagraphs, 0, []string{"Kubernetes", "Nguyen"})
+		for i, call := range calls {
+			if !strings.Contains(call.System, "Kubernetes, Nguyen") {
+				t.Errorf("call %d system = %q, want the glossary terms", i, call.System)
+			}
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package restructure
+
+import "github.com/alnah/go-transcript/internal/template"
+
+// Price is the price of a model, in US dollars per million tokens.
+type Price struct {
+	Input  float64 // Prompt tokens
+	Output float64 // Completion tokens (including reasoning tokens)
+}
+
+// Cost returns the price of u, in US dollars.
+func (p Price) Cost(u Usage) float64 {
+	return (float64(u.PromptTokens)*p.Input + float64(u.CompletionTokens)*p.Output) / 1e6
+}
+
+// prices are the published prices of the hosted models of defaultContextWindows,
+// used for estimates only. A name also matches its dated snapshots.
+var prices = map[string]Price{
+	// DeepSeek
+	"deepseek-chat":     {Input: 0.28, Output: 0.42},
+	"deepseek-reasoner": {Input: 0.28, Output: 0.42},
+
+	// OpenAI
+	"o3":           {Input: 2.00, Output: 8.00},
+	"o3-mini":      {Input: 1.10, Output: 4.40},
+	"o4-mini":      {Input: 1.10, Output: 4.40},
+	"gpt-4o":       {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":  {Input: 0.15, Output: 0.60},
+	"gpt-4.1":      {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini": {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano": {Input: 0.10, Output: 0.40},
+	"gpt-5":        {Input: 1.25, Output: 10.00},
+	"gpt-5-mini":   {Input: 0.25, Output: 2.00},
+
+	// Anthropic
+	"claude-3-5-haiku":  {Input: 0.80, Output: 4.00},
+	"claude-sonnet-4-0": {Input: 3.00, Output: 15.00},
+	"claude-sonnet-4-5": {Input: 3.00, Output: 15.00},
+	"claude-opus-4-1":   {Input: 15.00, Output: 75.00},
+	"claude-haiku-4-5":  {Input: 1.00, Output: 5.00},
+}
+
+// LookupPrice returns the price of model. Returns false for unknown models,
+// including local (Ollama) models, which cost nothing per token.
+func LookupPrice(model string) (Price, bool) {
+	return lookupModel(prices, model)
+}
+
+// TokensPerMinute estimates the transcript tokens of a minute of speech:
+// about 150 words of 6 characters (with spaces and punctuation).
+const TokensPerMinute = 150 * 6 / defaultCharsPerToken
+
+// Estimate is the planned usage of restructuring a transcript.
+type Estimate struct {
+	Calls int   // API calls: 1, or one per part plus the reduce call (times Runs, plus the merge call)
+	Parts int   // MapReduce parts, 0 if the transcript is sent in a single call
+	Runs  int   // Self-consistency runs merged by a final call, 0 without self-consistency
+	Usage Usage // Estimated tokens
+}
+
+// EstimateTokens estimates the tokens of text, as restructurers do to size
+// MapReduce parts.
+func EstimateTokens(text string) int {
+	return estimateTokens(text)
+}
+
+// EstimateUsage plans the restructuring of a transcript of transcriptTokens
+// with tmpl, in parts of partTokens (see PartTokens) as MapReduceRestructurer
+// does. Each call is assumed to answer with as many tokens as the transcript
+// it was given: restructuring reorganizes the content rather than summarizing it.
+func EstimateUsage(transcriptTokens int, tmpl template.Name, partTokens int) Estimate {
+	prompt := estimateTokens(tmpl.Prompt())
+	if transcriptTokens <= partTokens {
+		return Estimate{
+			Calls: 1,
+			Usage: Usage{PromptTokens: prompt + transcriptTokens, CompletionTokens: transcriptTokens},
+		}
+	}
+
+	parts := max(minChunksForMapReduce, (transcriptTokens+partTokens-1)/partTokens)
+	mapCalls := Usage{
+		PromptTokens:     parts*(prompt+estimateTokens(mapChunkPromptPrefix)) + transcriptTokens,
+		CompletionTokens: transcriptTokens,
+	}
+	reduceCall := Usage{
+		PromptTokens:     estimateTokens(reducePrompt) + transcriptTokens,
+		CompletionTokens: transcriptTokens,
+	}
+	return Estimate{Calls: parts + 1, Parts: parts, Usage: mapCalls.Add(reduceCall)}
+}
+
+// EstimateSelfConsistency plans restructuring a transcript of transcriptTokens
+// n times (see EstimateUsage) and merging the n outputs in a final call
+// (see WithMapReduceSelfConsistency). n <= 1 plans a regular run.
+func EstimateSelfConsistency(transcriptTokens int, tmpl template.Name, partTokens, n int) Estimate {
+	run := EstimateUsage(transcriptTokens, tmpl, partTokens)
+	n = min(n, MaxSelfConsistency)
+	if n <= 1 {
+		return run
+	}
+
+	var total Usage
+	for range n {
+		total = total.Add(run.Usage)
+	}
+	mergeCall := Usage{
+		PromptTokens:     estimateTokens(selfConsistencyPrompt) + n*transcriptTokens,
+		CompletionTokens: transcriptTokens,
+	}
+	return Estimate{
+		Calls: n*run.Calls + 1,
+		Parts: run.Parts,
+		Runs:  n,
+		Usage: total.Add(mergeCall),
+	}
+}
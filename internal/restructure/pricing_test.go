@@ -0,0 +1,107 @@
+package restructure_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+func TestLookupPrice(t *testing.T) {
+	t.Parallel()
+
+	// Every model with a built-in context window has a price.
+	for model := range restructure.DefaultContextWindows() {
+		if _, ok := restructure.LookupPrice(model); !ok {
+			t.Errorf("LookupPrice(%q) has no price", model)
+		}
+	}
+
+	if p, ok := restructure.LookupPrice("gpt-4o-2024-08-06"); !ok || p.Input != 2.50 {
+		t.Errorf("LookupPrice(snapshot) = %+v, %v, want gpt-4o price", p, ok)
+	}
+	if _, ok := restructure.LookupPrice("llama3.1"); ok {
+		t.Error("LookupPrice() found a price for a local model")
+	}
+}
+
+func TestPrice_Cost(t *testing.T) {
+	t.Parallel()
+
+	p := restructure.Price{Input: 2, Output: 8}
+	got := p.Cost(restructure.Usage{PromptTokens: 500000, CompletionTokens: 250000})
+	if math.Abs(got-3) > 1e-9 {
+		t.Errorf("Cost() = %v, want 3", got)
+	}
+}
+
+func TestEstimateUsage(t *testing.T) {
+	t.Parallel()
+
+	tmpl := template.MustParseName("brainstorm")
+	prompt := restructure.EstimateTokens(tmpl.Prompt())
+
+	t.Run("single call", func(t *testing.T) {
+		t.Parallel()
+
+		got := restructure.EstimateUsage(10000, tmpl, 80000)
+		if got.Calls != 1 || got.Parts != 0 {
+			t.Errorf("EstimateUsage() = %+v, want a single call", got)
+		}
+		want := restructure.Usage{PromptTokens: prompt + 10000, CompletionTokens: 10000}
+		if got.Usage != want {
+			t.Errorf("EstimateUsage() usage = %+v, want %+v", got.Usage, want)
+		}
+	})
+
+	t.Run("map reduce", func(t *testing.T) {
+		t.Parallel()
+
+		got := restructure.EstimateUsage(200000, tmpl, 80000)
+		if got.Parts != 3 || got.Calls != 4 {
+			t.Errorf("EstimateUsage() = %+v, want 3 parts and 4 calls", got)
+		}
+		// Map calls read the transcript, the reduce call reads their output.
+		if got.Usage.PromptTokens < 400000 || got.Usage.CompletionTokens != 400000 {
+			t.Errorf("EstimateUsage() usage = %+v, want the transcript read and written twice", got.Usage)
+		}
+	})
+}
+
+func TestEstimateSelfConsistency(t *testing.T) {
+	t.Parallel()
+
+	tmpl := template.MustParseName("meeting")
+	run := restructure.EstimateUsage(10000, tmpl, 80000)
+
+	t.Run("single run", func(t *testing.T) {
+		t.Parallel()
+
+		if got := restructure.EstimateSelfConsistency(10000, tmpl, 80000, 1); got != run {
+			t.Errorf("EstimateSelfConsistency() = %+v, want %+v", got, run)
+		}
+	})
+
+	t.Run("runs and merge", func(t *testing.T) {
+		t.Parallel()
+
+		got := restructure.EstimateSelfConsistency(10000, tmpl, 80000, 3)
+		if got.Runs != 3 || got.Calls != 4 {
+			t.Errorf("EstimateSelfConsistency() = %+v, want 3 runs and 4 calls", got)
+		}
+		// The merge call reads the three outputs and writes one.
+		if got.Usage.PromptTokens < 3*run.Usage.PromptTokens+30000 || got.Usage.CompletionTokens != 4*run.Usage.CompletionTokens {
+			t.Errorf("EstimateSelfConsistency() usage = %+v, want 3 runs + merge", got.Usage)
+		}
+	})
+
+	t.Run("capped runs", func(t *testing.T) {
+		t.Parallel()
+
+		got := restructure.EstimateSelfConsistency(10000, tmpl, 80000, 10)
+		if got.Runs != restructure.MaxSelfConsistency {
+			t.Errorf("EstimateSelfConsistency() runs = %d, want %d", got.Runs, restructure.MaxSelfConsistency)
+		}
+	})
+}
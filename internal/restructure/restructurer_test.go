@@ -10,15 +10,18 @@ package restructure_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/progress"
 	"github.com/alnah/go-transcript/internal/restructure"
 	"github.com/alnah/go-transcript/internal/template"
 )
@@ -436,3 +439,103 @@ func TestMapReduceRestructurer_Restructure(t *testing.T) {
 		}
 	})
 }
+
+// ---------------------------------------------------------------------------
+// TestRestructurers_RetryAfter - Waits requested by rate-limit responses
+// ---------------------------------------------------------------------------
+
+// newRetryAfterServer creates a test server rejecting the first request with
+// a 429 and the given headers, then answering success.
+func newRetryAfterServer(t *testing.T, header map[string]string, errBody, success any) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if first {
+			for k, v := range header {
+				w.Header().Set(k, v)
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+			_ = json.NewEncoder(w).Encode(errBody)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(success)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRestructurers_RetryAfter(t *testing.T) {
+	t.Parallel()
+
+	// The backoff delays would make the tests time out: only the waits
+	// requested by the servers are short.
+	tests := []struct {
+		name   string
+		header map[string]string
+		new    func(baseURL string) restructure.Restructurer
+		errMsg any
+		ok     any
+	}{
+		{
+			name:   "openai",
+			header: map[string]string{"x-ratelimit-remaining-requests": "0", "x-ratelimit-reset-requests": "1ms"},
+			new: func(baseURL string) restructure.Restructurer {
+				return restructure.NewOpenAIRestructurer("test-key", restructure.WithBaseURL(baseURL),
+					restructure.WithMaxRetries(2), restructure.WithRetryDelays(time.Hour, time.Hour))
+			},
+			errMsg: openAIErrorResponse("rate limit", "rate_limit_error"),
+			ok:     openAIResponse("success"),
+		},
+		{
+			name:   "deepseek",
+			header: map[string]string{"Retry-After": "0.001"},
+			new: func(baseURL string) restructure.Restructurer {
+				r, _ := restructure.NewDeepSeekRestructurer("test-key", restructure.WithDeepSeekBaseURL(baseURL),
+					restructure.WithDeepSeekMaxRetries(2), restructure.WithDeepSeekRetryDelays(time.Hour, time.Hour))
+				return r
+			},
+			errMsg: deepSeekErrorResponse("Rate limit exceeded", "rate_limit", "429"),
+			ok:     deepSeekResponse("success"),
+		},
+		{
+			name:   "anthropic",
+			header: map[string]string{"Retry-After": "0.001"},
+			new: func(baseURL string) restructure.Restructurer {
+				r, _ := restructure.NewAnthropicRestructurer("test-key", restructure.WithAnthropicBaseURL(baseURL),
+					restructure.WithAnthropicMaxRetries(2), restructure.WithAnthropicRetryDelays(time.Hour, time.Hour))
+				return r
+			},
+			errMsg: anthropicErrorResponse("rate_limit_error", "rate limit"),
+			ok:     anthropicResponse("success"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var delays []time.Duration
+			ctx := progress.WithHooks(context.Background(), progress.Hooks{
+				OnRetry: func(attempt int, delay time.Duration, err error) {
+					delays = append(delays, delay)
+				},
+			})
+			server := newRetryAfterServer(t, tt.header, tt.errMsg, tt.ok)
+
+			result, err := tt.new(server.URL).Restructure(ctx, "transcript", template.MustParseName("meeting"), lang.Language{})
+			if err != nil || result != "success" {
+				t.Fatalf("Restructure() = %q, %v; want success", result, err)
+			}
+			if len(delays) != 1 || delays[0] != time.Millisecond {
+				t.Errorf("retry delays = %v, want [1ms]", delays)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package restructure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// Self-consistency configuration.
+const (
+	// MaxSelfConsistency is the maximum number of restructurings merged by
+	// self-consistency: each one costs as much as a regular run.
+	MaxSelfConsistency = 5
+
+	// sampleTemperature is the temperature of self-consistency runs: varied
+	// enough for the runs to notice different details, low enough to stay
+	// faithful to the transcript. The merge call stays deterministic.
+	sampleTemperature = 0.7
+)
+
+// selfConsistencyPrompt merges independent restructurings of a transcript.
+const selfConsistencyPrompt = `You receive %d versions of the same restructured markdown document.
+Each version was produced independently from the same transcript.
+Merge them into a single final document.
+
+Rules:
+- Follow the structure the versions share (title, sections, order)
+- Keep every action item, decision, owner and deadline found in any version, stated once
+- Keep details found in a single version unless another version contradicts them
+- When versions disagree on a fact, keep what most versions say
+- The same point worded differently is one point: do not repeat it
+- Do not mention the versions, do not add commentary
+- Do not alter meaning, do not invent anything`
+
+// WithMapReduceSelfConsistency restructures the transcript n times at a modest
+// temperature, then asks the model to merge the n outputs into the final one.
+// Each run costs as much as a regular restructuring (see EstimateSelfConsistency).
+// n is capped at MaxSelfConsistency; n <= 1 disables self-consistency.
+func WithMapReduceSelfConsistency(n int) MapReduceOption {
+	return func(mr *MapReduceRestructurer) {
+		mr.samples = min(n, MaxSelfConsistency)
+	}
+}
+
+// selfConsistency restructures transcript mr.samples times and merges the outputs.
+// Returns whether the runs used MapReduce.
+func (mr *MapReduceRestructurer) selfConsistency(ctx context.Context, transcript string, tmpl template.Name, outputLang lang.Language) (string, bool, error) {
+	outputs := make([]string, mr.samples)
+	usedMapReduce := false
+	sample := callOptions{temperature: sampleTemperature} // Only the merge is streamed
+	for i := range outputs {
+		if ctx.Err() != nil {
+			return "", usedMapReduce, ctx.Err()
+		}
+		if mr.onProgress != nil {
+			mr.onProgress("sample", i+1, mr.samples)
+		}
+
+		output, mapReduced, err := mr.restructure(ctx, transcript, tmpl, outputLang, sample)
+		usedMapReduce = usedMapReduce || mapReduced
+		if err != nil {
+			return "", usedMapReduce, fmt.Errorf("failed to run restructuring %d/%d: %w", i+1, mr.samples, err)
+		}
+		outputs[i] = output
+	}
+
+	if mr.onProgress != nil {
+		mr.onProgress("consensus", 1, 1)
+	}
+
+	var input strings.Builder
+	for i, output := range outputs {
+		if i > 0 {
+			input.WriteString("\n\n---\n\n")
+		}
+		fmt.Fprintf(&input, "=== VERSION %d ===\n\n%s", i+1, output)
+	}
+
+	prompt := fmt.Sprintf(selfConsistencyPrompt, len(outputs))
+	if !outputLang.IsZero() && !outputLang.IsEnglish() {
+		prompt = fmt.Sprintf("Respond in %s.\n\n%s", outputLang.DisplayName(), prompt)
+	}
+	prompt = withAnchors(prompt, tmpl)
+
+	merged, err := mr.restructurer.restructureCall(ctx, input.String(), prompt, callOptions{stream: mr.stream})
+	if err != nil {
+		return "", usedMapReduce, fmt.Errorf("failed to merge restructurings: %w", err)
+	}
+	return merged, usedMapReduce, nil
+}
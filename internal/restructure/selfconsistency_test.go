@@ -0,0 +1,150 @@
+package restructure_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+func TestMapReduceRestructurer_SelfConsistency(t *testing.T) {
+	t.Parallel()
+
+	t.Run("runs sample and merge is deterministic", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+
+		server.addResponse(http.StatusOK, openAIResponse("# Run 1"))
+		server.addResponse(http.StatusOK, openAIResponse("# Run 2"))
+		server.addResponse(http.StatusOK, openAIResponse("# Run 3"))
+		server.addResponse(http.StatusOK, openAIResponse("# Merged"))
+
+		base := restructure.NewOpenAIRestructurer("test-key",
+			restructure.WithBaseURL(server.URL),
+			restructure.WithRetryDelays(time.Millisecond, time.Millisecond),
+		)
+
+		var mu sync.Mutex
+		var phases []string
+		mr := restructure.NewMapReduceRestructurer(base,
+			restructure.WithMapReduceSelfConsistency(3),
+			restructure.WithMapReduceProgress(func(phase string, current, total int) {
+				mu.Lock()
+				defer mu.Unlock()
+				phases = append(phases, phase)
+			}),
+		)
+
+		result, usedMapReduce, err := mr.Restructure(context.Background(), "Short transcript.", template.MustParseName("meeting"), lang.Language{})
+		if err != nil {
+			t.Fatalf("Restructure() unexpected error: %v", err)
+		}
+		if usedMapReduce {
+			t.Error("should not use MapReduce for short transcript")
+		}
+		if result != "# Merged" {
+			t.Errorf("unexpected result: %s", result)
+		}
+
+		calls := server.calls
+		if len(calls) != 4 {
+			t.Fatalf("expected 4 API calls (3 runs + merge), got %d", len(calls))
+		}
+		for i, call := range calls[:3] {
+			if call.Temperature == 0 {
+				t.Errorf("run %d temperature = 0, want sampling", i+1)
+			}
+		}
+		merge := calls[3]
+		if merge.Temperature != 0 {
+			t.Errorf("merge temperature = %v, want 0", merge.Temperature)
+		}
+		input := merge.Messages[len(merge.Messages)-1]["content"]
+		for _, want := range []string{"=== VERSION 1 ===\n\n# Run 1", "=== VERSION 3 ===\n\n# Run 3"} {
+			if !strings.Contains(input, want) {
+				t.Errorf("merge input missing %q:\n%s", want, input)
+			}
+		}
+
+		if got := strings.Join(phases, ","); got != "sample,sample,sample,consensus" {
+			t.Errorf("progress phases = %s", got)
+		}
+	})
+
+	t.Run("merge prompt asks for the output language", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+
+		base := restructure.NewOpenAIRestructurer("test-key",
+			restructure.WithBaseURL(server.URL),
+			restructure.WithRetryDelays(time.Millisecond, time.Millisecond),
+		)
+		mr := restructure.NewMapReduceRestructurer(base, restructure.WithMapReduceSelfConsistency(2))
+
+		if _, _, err := mr.Restructure(context.Background(), "Transcript.", template.MustParseName("meeting"), lang.MustParse("fr")); err != nil {
+			t.Fatalf("Restructure() unexpected error: %v", err)
+		}
+		if prompt := server.systemPrompt(); !strings.HasPrefix(prompt, "Respond in French.") {
+			t.Errorf("merge prompt does not set the language:\n%s", prompt)
+		}
+	})
+
+	t.Run("failed run stops before merging", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+
+		server.addResponse(http.StatusOK, openAIResponse("# Run 1"))
+		server.addResponse(http.StatusUnauthorized, openAIErrorResponse("invalid key", "invalid_api_key"))
+
+		base := restructure.NewOpenAIRestructurer("test-key",
+			restructure.WithBaseURL(server.URL),
+			restructure.WithRetryDelays(time.Millisecond, time.Millisecond),
+		)
+		mr := restructure.NewMapReduceRestructurer(base, restructure.WithMapReduceSelfConsistency(3))
+
+		_, _, err := mr.Restructure(context.Background(), "Transcript.", template.MustParseName("meeting"), lang.Language{})
+		if !errors.Is(err, apierr.ErrAuthFailed) {
+			t.Errorf("Restructure() error = %v, want ErrAuthFailed", err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "2/3") {
+			t.Errorf("error does not name the failed run: %v", err)
+		}
+		if server.callCount() != 2 {
+			t.Errorf("expected 2 API calls, got %d", server.callCount())
+		}
+	})
+
+	t.Run("single run is a regular restructuring", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+
+		base := restructure.NewOpenAIRestructurer("test-key",
+			restructure.WithBaseURL(server.URL),
+			restructure.WithRetryDelays(time.Millisecond, time.Millisecond),
+		)
+		mr := restructure.NewMapReduceRestructurer(base, restructure.WithMapReduceSelfConsistency(1))
+
+		if _, _, err := mr.Restructure(context.Background(), "Transcript.", template.MustParseName("meeting"), lang.Language{}); err != nil {
+			t.Fatalf("Restructure() unexpected error: %v", err)
+		}
+		if server.callCount() != 1 || server.lastCall().Temperature != 0 {
+			t.Errorf("expected 1 deterministic call, got %d calls", server.callCount())
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package restructure
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Split is how long transcripts are divided into parts for MapReduce.
+type Split string
+
+// Split strategies.
+const (
+	// SplitParagraphs cuts parts at paragraph boundaries (blank lines).
+	SplitParagraphs Split = "paragraphs"
+	// SplitSpeakers cuts parts where the speaker changes, so that each part
+	// holds whole exchanges. Transcripts without speaker labels are split
+	// at paragraphs.
+	SplitSpeakers Split = "speakers"
+)
+
+// ParseSplit validates and parses a split strategy name.
+// Empty means SplitParagraphs.
+// Returns ErrUnknownSplit if the name is not recognized.
+func ParseSplit(s string) (Split, error) {
+	switch Split(s) {
+	case "", SplitParagraphs:
+		return SplitParagraphs, nil
+	case SplitSpeakers:
+		return SplitSpeakers, nil
+	}
+	return "", fmt.Errorf("unknown restructure split %q (use %q or %q): %w", s, SplitParagraphs, SplitSpeakers, ErrUnknownSplit)
+}
+
+// WithMapReduceSplit sets how long transcripts are divided into parts.
+// The default is SplitParagraphs.
+func WithMapReduceSplit(s Split) MapReduceOption {
+	return func(mr *MapReduceRestructurer) {
+		mr.split = s
+	}
+}
+
+var (
+	// timedSpeakerLine matches a line of a timed transcript with a speaker:
+	// "[00:01:30] A: text".
+	timedSpeakerLine = regexp.MustCompile(`^\[\d{2}:\d{2}:\d{2}\] ([^:\[\]]{1,40}): `)
+	// speakerLine matches a line of a diarized transcript: "[A] text".
+	speakerLine = regexp.MustCompile(`^\[([^\[\]]{1,40})\] `)
+	// timestampLine matches a line of a timed transcript.
+	timestampLine = regexp.MustCompile(`^\[\d{2}:\d{2}:\d{2}\] `)
+)
+
+// lineSpeaker returns the speaker label of a transcript line, or "" if the
+// line has none (pauses, continuations, transcripts without diarization).
+func lineSpeaker(line string) string {
+	if m := timedSpeakerLine.FindStringSubmatch(line); m != nil {
+		return m[1]
+	}
+	if timestampLine.MatchString(line) {
+		return ""
+	}
+	if m := speakerLine.FindStringSubmatch(line); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// speakerTurns divides transcript into turns: consecutive lines of the same
+// speaker. Lines without speaker belong to the turn they follow.
+// Returns nil if the transcript has fewer than two speakers.
+func speakerTurns(transcript string) []string {
+	var turns []string
+	var current strings.Builder
+	speakers := make(map[string]bool)
+	speaker := ""
+
+	for _, line := range strings.Split(transcript, "\n") {
+		if s := lineSpeaker(line); s != "" {
+			speakers[s] = true
+			if s != speaker && current.Len() > 0 {
+				turns = append(turns, strings.TrimRight(current.String(), "\n"))
+				current.Reset()
+			}
+			speaker = s
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	if current.Len() > 0 {
+		turns = append(turns, strings.TrimRight(current.String(), "\n"))
+	}
+
+	if len(speakers) < 2 {
+		return nil
+	}
+	return turns
+}
+
+// splitTranscriptByTurns divides a diarized transcript into chunks at speaker
+// turns. Each chunk targets maxTokens size; a turn longer than maxTokens is
+// split at its lines. Transcripts without speakers are split at paragraphs
+// (see splitTranscript). Returns nil if transcript fits in a single chunk.
+func splitTranscriptByTurns(transcript string, maxTokens int) []TranscriptChunk {
+	if estimateTokens(transcript) <= maxTokens {
+		return nil
+	}
+	turns := speakerTurns(transcript)
+	if turns == nil {
+		return splitTranscript(transcript, maxTokens)
+	}
+
+	var segments []string
+	for _, turn := range turns {
+		if estimateTokens(turn) > maxTokens {
+			segments = append(segments, strings.Split(turn, "\n")...)
+			continue
+		}
+		segments = append(segments, turn)
+	}
+	return packChunks(segments, "\n", maxTokens)
+}
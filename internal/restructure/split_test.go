@@ -0,0 +1,125 @@
+package restructure_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/restructure"
+)
+
+func TestParseSplit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		want    restructure.Split
+		wantErr bool
+	}{
+		{input: "", want: restructure.SplitParagraphs},
+		{input: "paragraphs", want: restructure.SplitParagraphs},
+		{input: "speakers", want: restructure.SplitSpeakers},
+		{input: "turns", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := restructure.ParseSplit(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, restructure.ErrUnknownSplit) {
+					t.Errorf("ParseSplit(%q) error = %v, want ErrUnknownSplit", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSplit(%q) unexpected error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSplit(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitTranscriptByTurns(t *testing.T) {
+	t.Parallel()
+
+	// line returns a line of speaker of about 20 tokens.
+	line := func(speaker string) string {
+		return "[" + speaker + "] " + strings.Repeat("a", 56)
+	}
+
+	t.Run("cuts at speaker turns", func(t *testing.T) {
+		t.Parallel()
+
+		// Turns of 2, 3 and 2 lines: paragraphs would not split at all.
+		transcript := strings.Join([]string{
+			line("A"), line("A"),
+			line("B"), line("B"), line("B"),
+			line("A"), line("A"),
+		}, "\n")
+
+		chunks := restructure.SplitTranscriptByTurns(transcript, 70)
+		if len(chunks) != 3 {
+			t.Fatalf("SplitTranscriptByTurns() = %d chunks, want 3", len(chunks))
+		}
+		for i, want := range []string{"[A]", "[B]", "[A]"} {
+			lines := strings.Split(chunks[i].Content, "\n")
+			for _, l := range lines {
+				if !strings.HasPrefix(l, want) {
+					t.Errorf("chunk %d line %q, want only %s lines", i, l, want)
+				}
+			}
+			if chunks[i].Index != i || chunks[i].Total != 3 {
+				t.Errorf("chunk %d Index/Total = %d/%d", i, chunks[i].Index, chunks[i].Total)
+			}
+		}
+	})
+
+	t.Run("timed transcript", func(t *testing.T) {
+		t.Parallel()
+
+		transcript := "[00:00:00] A: " + strings.Repeat("a", 150) + "\n" +
+			"[pause 4s]\n" +
+			"[00:01:00] B: " + strings.Repeat("b", 150) + "\n"
+
+		chunks := restructure.SplitTranscriptByTurns(transcript, 60)
+		if len(chunks) != 2 {
+			t.Fatalf("SplitTranscriptByTurns() = %d chunks, want 2", len(chunks))
+		}
+		if !strings.HasSuffix(chunks[0].Content, "[pause 4s]") || !strings.HasPrefix(chunks[1].Content, "[00:01:00] B:") {
+			t.Errorf("chunks = %q, want the pause kept with the turn it follows", chunks)
+		}
+	})
+
+	t.Run("long turn split at lines", func(t *testing.T) {
+		t.Parallel()
+
+		transcript := strings.Join([]string{line("A"), line("A"), line("A"), line("A"), line("B")}, "\n")
+		chunks := restructure.SplitTranscriptByTurns(transcript, 45)
+		if len(chunks) != 3 {
+			t.Errorf("SplitTranscriptByTurns() = %d chunks, want 3", len(chunks))
+		}
+	})
+
+	t.Run("without speakers falls back to paragraphs", func(t *testing.T) {
+		t.Parallel()
+
+		transcript := strings.Repeat("a", 300) + "\n\n" + strings.Repeat("b", 300)
+		got := restructure.SplitTranscriptByTurns(transcript, 120)
+		want := restructure.SplitTranscript(transcript, 120)
+		if len(got) != 2 || len(got) != len(want) || got[0].Content != want[0].Content {
+			t.Errorf("SplitTranscriptByTurns() = %v, want paragraph split %v", got, want)
+		}
+	})
+
+	t.Run("short transcript", func(t *testing.T) {
+		t.Parallel()
+
+		if got := restructure.SplitTranscriptByTurns(line("A")+"\n"+line("B"), 1000); got != nil {
+			t.Errorf("SplitTranscriptByTurns() = %v, want nil", got)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package restructure
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxStreamLine is the size of the longest line of a streamed response.
+const maxStreamLine = 1024 * 1024
+
+// WithMapReduceStream writes the output of the final call to w as the
+// provider generates it, through streaming responses: the single call of a
+// short transcript, the final reduce call of MapReduce, or the merge call of
+// self-consistency. The calls before it are not streamed. Restructure still
+// returns the whole output once it is done.
+func WithMapReduceStream(w io.Writer) MapReduceOption {
+	return func(mr *MapReduceRestructurer) {
+		mr.stream = w
+	}
+}
+
+// newStreamOutput returns the output streamed responses are written to, or
+// nil if w is nil: the call is not streamed.
+func newStreamOutput(w io.Writer) *streamOutput {
+	if w == nil {
+		return nil
+	}
+	return &streamOutput{w: w}
+}
+
+// streamOutput counts the bytes written to a stream, to know whether a failed
+// call can be retried.
+type streamOutput struct {
+	w       io.Writer
+	written int64
+}
+
+// write writes a piece of a streamed response.
+func (o *streamOutput) write(text string) error {
+	n, err := io.WriteString(o.w, text)
+	o.written += int64(n)
+	return err
+}
+
+// interrupted returns err, marked with ErrStreamInterrupted if part of the
+// response was already written to out (nil when not streaming).
+func (o *streamOutput) interrupted(err error) error {
+	if o == nil || o.written == 0 || err == nil {
+		return err
+	}
+	return fmt.Errorf("%w after %d bytes: %w", ErrStreamInterrupted, o.written, err)
+}
+
+// readStream calls fn with the data of each event of a streamed response:
+// the "data:" lines of server-sent events, or the lines of newline-delimited
+// JSON (Ollama). It stops at the "[DONE]" event or the end of body.
+func readStream(body io.Reader, fn func(data []byte) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || bytes.HasPrefix(line, []byte("event:")) || bytes.HasPrefix(line, []byte(":")) {
+			continue
+		}
+		data := bytes.TrimSpace(bytes.TrimPrefix(line, []byte("data:")))
+		if string(data) == "[DONE]" {
+			return nil
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read streamed response: %w", err)
+	}
+	return nil
+}
+
+// chatStreamChunk is a chunk of a streamed chat completion of the OpenAI API,
+// also used by DeepSeek. The last chunk carries the usage and no choices.
+type chatStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// chatStreamOptions asks for the usage in the last chunk of a streamed chat
+// completion.
+type chatStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// readChatStream writes the content of a streamed chat completion to out as
+// it arrives. Returns the whole content and the usage of the call.
+func readChatStream(body io.Reader, out *streamOutput) (string, Usage, error) {
+	var content strings.Builder
+	var usage Usage
+	err := readStream(body, func(data []byte) error {
+		var chunk chatStreamChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("failed to parse streamed response: %w", err)
+		}
+		if chunk.Usage != nil {
+			usage = Usage{PromptTokens: chunk.Usage.PromptTokens, CompletionTokens: chunk.Usage.CompletionTokens}
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+			if err := out.write(choice.Delta.Content); err != nil {
+				return fmt.Errorf("failed to write streamed output: %w", err)
+			}
+		}
+		return nil
+	})
+	return content.String(), usage, err
+}
@@ -0,0 +1,297 @@
+package restructure_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// streamRecorder records whether each request asked for a streamed response.
+type streamRecorder struct {
+	mu      sync.Mutex
+	streams []bool
+}
+
+func (s *streamRecorder) record(t *testing.T, r *http.Request) bool {
+	t.Helper()
+	var req struct {
+		Stream bool `json:"stream"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		t.Errorf("failed to decode request: %v", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streams = append(s.streams, req.Stream)
+	return req.Stream
+}
+
+func (s *streamRecorder) calls() []bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]bool(nil), s.streams...)
+}
+
+// writeChatStream writes pieces as a streamed chat completion, with the usage
+// in the last chunk.
+func writeChatStream(w http.ResponseWriter, pieces ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, piece := range pieces {
+		chunk, _ := json.Marshal(map[string]any{
+			"choices": []map[string]any{{"delta": map[string]string{"content": piece}}},
+		})
+		fmt.Fprintf(w, "data: %s\n\n", chunk)
+	}
+	fmt.Fprint(w, `data: {"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":5}}`+"\n\n")
+	fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+func TestMapReduce_StreamOpenAI(t *testing.T) {
+	t.Parallel()
+
+	var rec streamRecorder
+	server := newMockOpenAIServerWithHandler(func(w http.ResponseWriter, r *http.Request) {
+		if !rec.record(t, r) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(openAIResponse("# Notes"))
+			return
+		}
+		writeChatStream(w, "# Meeting", "\n\n- Budget", " approved")
+	})
+	t.Cleanup(server.Close)
+
+	var streamed strings.Builder
+	usage := restructure.NewUsageTracker()
+	r := restructure.NewOpenAIRestructurer("test-key", restructure.WithBaseURL(server.URL), restructure.WithMaxRetries(0))
+	mr := restructure.NewMapReduceRestructurer(r,
+		restructure.WithMapReduceStream(&streamed),
+		restructure.WithMapReduceUsageTracker(usage),
+	)
+
+	got, usedMapReduce, err := mr.Restructure(context.Background(), "Short transcript.", template.MustParseName("meeting"), lang.Language{})
+	if err != nil {
+		t.Fatalf("Restructure() error = %v", err)
+	}
+	want := "# Meeting\n\n- Budget approved"
+	if got != want || usedMapReduce {
+		t.Errorf("Restructure() = %q, %v, want %q, false", got, usedMapReduce, want)
+	}
+	if streamed.String() != want {
+		t.Errorf("streamed %q, want %q", streamed.String(), want)
+	}
+	if calls := rec.calls(); len(calls) != 1 || !calls[0] {
+		t.Errorf("requests streamed = %v, want [true]", calls)
+	}
+	if total := usage.Total(); total.PromptTokens != 12 || total.CompletionTokens != 5 {
+		t.Errorf("usage = %+v, want 12 prompt and 5 completion tokens", total)
+	}
+}
+
+func TestMapReduce_StreamOnlyFinalCall(t *testing.T) {
+	t.Parallel()
+
+	var rec streamRecorder
+	server := newMockOpenAIServerWithHandler(func(w http.ResponseWriter, r *http.Request) {
+		if !rec.record(t, r) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(openAIResponse("Part notes"))
+			return
+		}
+		writeChatStream(w, "Merged", " notes")
+	})
+	t.Cleanup(server.Close)
+
+	var streamed strings.Builder
+	r := restructure.NewOpenAIRestructurer("test-key", restructure.WithBaseURL(server.URL), restructure.WithMaxRetries(0))
+	mr := restructure.NewMapReduceRestructurer(r,
+		restructure.WithMapReduceMaxTokens(100),
+		restructure.WithMapReduceStream(&streamed),
+	)
+
+	paragraph := strings.Repeat("word ", 60)
+	transcript := strings.Join([]string{paragraph, paragraph, paragraph}, "\n\n")
+	got, usedMapReduce, err := mr.Restructure(context.Background(), transcript, template.MustParseName("notes"), lang.Language{})
+	if err != nil {
+		t.Fatalf("Restructure() error = %v", err)
+	}
+	if got != "Merged notes" || !usedMapReduce {
+		t.Errorf("Restructure() = %q, %v, want %q, true", got, usedMapReduce, "Merged notes")
+	}
+	if streamed.String() != "Merged notes" {
+		t.Errorf("streamed %q, want only the reduce output", streamed.String())
+	}
+	calls := rec.calls()
+	if len(calls) < 3 {
+		t.Fatalf("got %d requests, want map calls and a reduce call", len(calls))
+	}
+	for i, stream := range calls {
+		if want := i == len(calls)-1; stream != want {
+			t.Errorf("request %d streamed = %v, want %v", i+1, stream, want)
+		}
+	}
+}
+
+func TestMapReduce_StreamInterrupted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		handler func(w http.ResponseWriter)
+		newR    func(url string) restructure.MapReducer
+	}{
+		{
+			name: "openai malformed chunk",
+			handler: func(w http.ResponseWriter) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, `data: {"choices":[{"delta":{"content":"# Partial"}}]}`+"\n\n")
+				fmt.Fprint(w, "data: {not json\n\n")
+			},
+			newR: func(url string) restructure.MapReducer {
+				return restructure.NewMapReduceRestructurer(restructure.NewOpenAIRestructurer("test-key",
+					restructure.WithBaseURL(url),
+					restructure.WithMaxRetries(2),
+					restructure.WithRetryDelays(time.Millisecond, time.Millisecond),
+				), restructure.WithMapReduceStream(&strings.Builder{}))
+			},
+		},
+		{
+			name: "anthropic overloaded event",
+			handler: func(w http.ResponseWriter) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":10}}}\n\n")
+				fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"# Partial\"}}\n\n")
+				fmt.Fprint(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+			},
+			newR: func(url string) restructure.MapReducer {
+				r, err := restructure.NewAnthropicRestructurer("test-key",
+					restructure.WithAnthropicBaseURL(url),
+					restructure.WithAnthropicMaxRetries(2),
+					restructure.WithAnthropicRetryDelays(time.Millisecond, time.Millisecond),
+				)
+				if err != nil {
+					panic(err)
+				}
+				return restructure.NewMapReduceRestructurer(r, restructure.WithMapReduceStream(&strings.Builder{}))
+			},
+		},
+		{
+			name: "ollama error line",
+			handler: func(w http.ResponseWriter) {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				fmt.Fprintln(w, `{"message":{"role":"assistant","content":"# Partial"},"done":false}`)
+				fmt.Fprintln(w, `{"error":"model runner stopped"}`)
+			},
+			newR: func(url string) restructure.MapReducer {
+				return restructure.NewMapReduceRestructurer(restructure.NewOllamaRestructurer(
+					restructure.WithOllamaBaseURL(url),
+					restructure.WithOllamaMaxRetries(2),
+					restructure.WithOllamaRetryDelays(time.Millisecond, time.Millisecond),
+				), restructure.WithMapReduceStream(&strings.Builder{}))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var rec streamRecorder
+			server := newMockOpenAIServerWithHandler(func(w http.ResponseWriter, r *http.Request) {
+				rec.record(t, r)
+				tt.handler(w)
+			})
+			t.Cleanup(server.Close)
+
+			_, _, err := tt.newR(server.URL).Restructure(context.Background(), "Short transcript.", template.MustParseName("meeting"), lang.Language{})
+			if !errors.Is(err, restructure.ErrStreamInterrupted) {
+				t.Fatalf("Restructure() error = %v, want ErrStreamInterrupted", err)
+			}
+			if calls := rec.calls(); len(calls) != 1 {
+				t.Errorf("got %d requests, want 1 (not retried after output was written)", len(calls))
+			}
+		})
+	}
+}
+
+func TestMapReduce_StreamAnthropicAndOllama(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		handler func(w http.ResponseWriter)
+		newR    func(url string, usage *restructure.UsageTracker, w *strings.Builder) restructure.MapReducer
+	}{
+		{
+			name: "anthropic",
+			handler: func(w http.ResponseWriter) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":12}}}\n\n")
+				fmt.Fprint(w, "event: ping\ndata: {\"type\":\"ping\"}\n\n")
+				fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"# Meeting\"}}\n\n")
+				fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"\\n\\n- Budget\"}}\n\n")
+				fmt.Fprint(w, "event: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":5}}\n\n")
+				fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+			},
+			newR: func(url string, usage *restructure.UsageTracker, w *strings.Builder) restructure.MapReducer {
+				r, err := restructure.NewAnthropicRestructurer("test-key", restructure.WithAnthropicBaseURL(url))
+				if err != nil {
+					panic(err)
+				}
+				return restructure.NewMapReduceRestructurer(r, restructure.WithMapReduceStream(w), restructure.WithMapReduceUsageTracker(usage))
+			},
+		},
+		{
+			name: "ollama",
+			handler: func(w http.ResponseWriter) {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				fmt.Fprintln(w, `{"message":{"role":"assistant","content":"# Meeting"},"done":false}`)
+				fmt.Fprintln(w, `{"message":{"role":"assistant","content":"\n\n- Budget"},"done":false}`)
+				fmt.Fprintln(w, `{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":12,"eval_count":5}`)
+			},
+			newR: func(url string, usage *restructure.UsageTracker, w *strings.Builder) restructure.MapReducer {
+				r := restructure.NewOllamaRestructurer(restructure.WithOllamaBaseURL(url))
+				return restructure.NewMapReduceRestructurer(r, restructure.WithMapReduceStream(w), restructure.WithMapReduceUsageTracker(usage))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var rec streamRecorder
+			server := newMockOpenAIServerWithHandler(func(w http.ResponseWriter, r *http.Request) {
+				rec.record(t, r)
+				tt.handler(w)
+			})
+			t.Cleanup(server.Close)
+
+			var streamed strings.Builder
+			usage := restructure.NewUsageTracker()
+			got, _, err := tt.newR(server.URL, usage, &streamed).Restructure(context.Background(), "Short transcript.", template.MustParseName("meeting"), lang.Language{})
+			if err != nil {
+				t.Fatalf("Restructure() error = %v", err)
+			}
+			want := "# Meeting\n\n- Budget"
+			if got != want || streamed.String() != want {
+				t.Errorf("Restructure() = %q, streamed %q, want %q", got, streamed.String(), want)
+			}
+			if calls := rec.calls(); len(calls) != 1 || !calls[0] {
+				t.Errorf("requests streamed = %v, want [true]", calls)
+			}
+			if total := usage.Total(); total.PromptTokens != 12 || total.CompletionTokens != 5 {
+				t.Errorf("usage = %+v, want 12 prompt and 5 completion tokens", total)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package restructure
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// notesPrompt is the map prompt of a transcript restructured with several
+// templates (see RestructureTemplates): notes from which any template can be
+// written, so that the parts are processed once rather than once per template.
+// Placeholders: part number, total parts.
+const notesPrompt = `You receive part %d of %d of a long transcript, that will be restructured afterwards with several templates (such as meeting notes, a summary or a list of actions).
+Write detailed markdown notes of this part, from which any of them can be written.
+
+Rules:
+- Follow the order of the transcript, with a section per topic
+- Keep every decision, action item (with its owner and due date), open question, name, number and date
+- Keep who said what when the speakers are labeled
+- Keep notable statements as short quotes
+- Do not add a main title (H1), an introduction or a conclusion
+- Do not alter meaning, do not invent anything`
+
+// notesReducePrefix introduces the template prompt of the final call of a
+// template, which reads the notes of all parts instead of the transcript.
+const notesReducePrefix = `You receive the notes of consecutive parts of a long transcript, in order, instead of the transcript itself.
+Write a single document from all of them, following the instructions below as if the notes were the transcript.
+
+`
+
+// TemplatesRestructurer restructures a transcript with several templates,
+// sharing the calls the templates have in common.
+type TemplatesRestructurer interface {
+	// RestructureTemplates returns the output of each template, in order.
+	RestructureTemplates(ctx context.Context, transcript string, tmpls []template.Name, outputLang lang.Language) ([]string, error)
+}
+
+// Compile-time interface compliance check.
+var _ TemplatesRestructurer = (*MapReduceRestructurer)(nil)
+
+// RestructureTemplates restructures transcript with each of tmpls, returning
+// their outputs in order. When the transcript needs MapReduce, its parts are
+// mapped once into notes (condensed in groups while they do not fit in one
+// call, see WithMapReduceLevels), and each template makes a single call from
+// them, instead of a map phase each. Templates reading timestamps, which the
+// notes do not keep, and self-consistency runs are restructured on their own
+// (see Restructure), as are transcripts fitting in one call: there is nothing
+// to share.
+func (mr *MapReduceRestructurer) RestructureTemplates(ctx context.Context, transcript string, tmpls []template.Name, outputLang lang.Language) ([]string, error) {
+	chunks := splitParts(transcript, mr.maxTokens, mr.split, mr.overlap)
+	var shared []int // Templates written from the notes
+	if chunks != nil && mr.samples <= 1 {
+		for i, tmpl := range tmpls {
+			if !tmpl.Timed() {
+				shared = append(shared, i)
+			}
+		}
+	}
+	if len(shared) < 2 {
+		shared = nil
+	}
+
+	outputs := make([]string, len(tmpls))
+	for i, tmpl := range tmpls {
+		if slices.Contains(shared, i) {
+			continue
+		}
+		output, _, err := mr.Restructure(ctx, transcript, tmpl, outputLang)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", tmpl, err)
+		}
+		outputs[i] = output
+	}
+	if shared == nil {
+		return outputs, nil
+	}
+
+	notes, err := mr.notes(ctx, chunks, outputLang)
+	if err != nil {
+		return nil, err
+	}
+	for n, i := range shared {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if mr.onProgress != nil {
+			mr.onProgress("reduce", n+1, len(shared))
+		}
+		if outputs[i], err = mr.reduceNotes(ctx, notes, tmpls[i], outputLang); err != nil {
+			return nil, fmt.Errorf("template %s: failed to merge chunks: %w", tmpls[i], err)
+		}
+	}
+	return outputs, nil
+}
+
+// notes maps chunks into notes (see notesPrompt), condensed in groups, up to
+// one level less than the reduce levels, while they do not fit in one call.
+func (mr *MapReduceRestructurer) notes(ctx context.Context, chunks []TranscriptChunk, outputLang lang.Language) ([]string, error) {
+	notes := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if mr.onProgress != nil {
+			mr.onProgress("map", i+1, len(chunks))
+		}
+
+		prompt := fmt.Sprintf(notesPrompt, chunk.Index+1, chunk.Total)
+		if !outputLang.IsZero() && !outputLang.IsEnglish() {
+			prompt = fmt.Sprintf("Respond in %s.\n\n%s", outputLang.DisplayName(), prompt)
+		}
+		prompt = withHighlights(withGlossary(prompt, mr.glossary), mr.highlights)
+		if chunk.Context != "" {
+			prompt += "\n\n" + overlapInstruction
+		}
+		output, err := mr.restructurer.RestructureWithCustomPrompt(ctx, chunk.input(), prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+		notes[i] = output
+	}
+
+	levels := mr.levels
+	if levels <= 0 {
+		levels = DefaultReduceLevels
+	}
+	for level := 1; level < levels; level++ {
+		groups := groupOutputs(notes, mr.maxTokens)
+		if len(groups) == 1 {
+			break
+		}
+		next := make([]string, len(groups))
+		for i, group := range groups {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if len(group) == 1 {
+				next[i] = group[0]
+				continue
+			}
+			if mr.onProgress != nil {
+				mr.onProgress("condense", i+1, len(groups))
+			}
+			condensed, err := mr.condense(ctx, group, outputLang, callOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to condense group %d/%d of reduce level %d: %w", i+1, len(groups), level, err)
+			}
+			next[i] = condensed
+		}
+		notes = next
+	}
+	return notes, nil
+}
+
+// reduceNotes writes the output of tmpl from the notes of all parts.
+func (mr *MapReduceRestructurer) reduceNotes(ctx context.Context, notes []string, tmpl template.Name, outputLang lang.Language) (string, error) {
+	prompt := notesReducePrefix + tmpl.Prompt()
+	if !outputLang.IsZero() && !outputLang.IsEnglish() {
+		prompt = fmt.Sprintf("Respond in %s.\n\n%s", outputLang.DisplayName(), prompt)
+	}
+	prompt = withHighlights(withGlossary(prompt, mr.glossary), mr.highlights)
+
+	return mr.restructurer.RestructureWithCustomPrompt(ctx, reduceInput(notes), prompt)
+}
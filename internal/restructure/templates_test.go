@@ -0,0 +1,124 @@
+package restructure_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/restructure"
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+func TestRestructureTemplates(t *testing.T) {
+	t.Parallel()
+
+	newMapReducer := func(t *testing.T, server *mockOpenAIServer) *restructure.MapReduceRestructurer {
+		t.Helper()
+		base := restructure.NewOpenAIRestructurer("test-key",
+			restructure.WithBaseURL(server.URL),
+			restructure.WithRetryDelays(time.Millisecond, time.Millisecond),
+		)
+		return restructure.NewMapReduceRestructurer(base, restructure.WithMapReduceMaxTokens(200))
+	}
+	long := strings.Repeat("a", 500) + "\n\n" + strings.Repeat("b", 500) + "\n\n" + strings.Repeat("c", 500)
+
+	t.Run("parts mapped once", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+		for range 3 {
+			server.addResponse(http.StatusOK, openAIResponse("Part notes"))
+		}
+		server.addResponse(http.StatusOK, openAIResponse("Meeting notes"))
+		server.addResponse(http.StatusOK, openAIResponse("Brainstorm notes"))
+
+		tmpls := []template.Name{template.MeetingName, template.BrainstormName}
+		got, err := newMapReducer(t, server).RestructureTemplates(context.Background(), long, tmpls, lang.Language{})
+		if err != nil {
+			t.Fatalf("RestructureTemplates() unexpected error: %v", err)
+		}
+		if len(got) != 2 || got[0] != "Meeting notes" || got[1] != "Brainstorm notes" {
+			t.Errorf("RestructureTemplates() = %q, want the output of each template", got)
+		}
+		// 3 map calls shared by the templates, 1 call per template
+		if server.callCount() != 5 {
+			t.Fatalf("expected 5 API calls, got %d", server.callCount())
+		}
+		for i, call := range server.calls[:3] {
+			if system := call.Messages[0]["content"]; !strings.Contains(system, "several templates") {
+				t.Errorf("map call %d system prompt is not the notes prompt:\n%s", i+1, system)
+			}
+		}
+		for i, call := range server.calls[3:] {
+			system := call.Messages[0]["content"]
+			if !strings.Contains(system, "instead of the transcript itself") || !strings.Contains(system, tmpls[i].Prompt()) {
+				t.Errorf("call of template %s system prompt does not read the notes with its prompt:\n%s", tmpls[i], system)
+			}
+			if user := call.Messages[1]["content"]; strings.Count(user, "=== PART") != 3 {
+				t.Errorf("call of template %s reads %d parts, want 3", tmpls[i], strings.Count(user, "=== PART"))
+			}
+		}
+	})
+
+	t.Run("timed template on its own", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+
+		tmpls := []template.Name{template.MeetingName, template.ChaptersName, template.BrainstormName}
+		got, err := newMapReducer(t, server).RestructureTemplates(context.Background(), long, tmpls, lang.Language{})
+		if err != nil {
+			t.Fatalf("RestructureTemplates() unexpected error: %v", err)
+		}
+		if len(got) != 3 {
+			t.Fatalf("RestructureTemplates() = %q, want 3 outputs", got)
+		}
+		// chapters: 3 map calls and a reduce; the others: 3 shared map calls and 1 call each
+		if server.callCount() != 9 {
+			t.Fatalf("expected 9 API calls, got %d", server.callCount())
+		}
+		if system := server.calls[0].Messages[0]["content"]; strings.Contains(system, "several templates") {
+			t.Errorf("chapters mapped with the notes prompt:\n%s", system)
+		}
+	})
+
+	t.Run("short transcript", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+		server.addResponse(http.StatusOK, openAIResponse("Meeting notes"))
+		server.addResponse(http.StatusOK, openAIResponse("Brainstorm notes"))
+
+		tmpls := []template.Name{template.MeetingName, template.BrainstormName}
+		got, err := newMapReducer(t, server).RestructureTemplates(context.Background(), "Short transcript.", tmpls, lang.Language{})
+		if err != nil {
+			t.Fatalf("RestructureTemplates() unexpected error: %v", err)
+		}
+		if len(got) != 2 || got[0] != "Meeting notes" || got[1] != "Brainstorm notes" {
+			t.Errorf("RestructureTemplates() = %q, want the output of each template", got)
+		}
+		if server.callCount() != 2 {
+			t.Errorf("expected 1 API call per template, got %d", server.callCount())
+		}
+	})
+
+	t.Run("failed template", func(t *testing.T) {
+		t.Parallel()
+
+		server := newMockOpenAIServer()
+		t.Cleanup(server.Close)
+		server.addResponse(http.StatusBadRequest, openAIErrorResponse("model not found", "invalid_request_error"))
+
+		tmpls := []template.Name{template.MeetingName, template.BrainstormName}
+		_, err := newMapReducer(t, server).RestructureTemplates(context.Background(), "Short transcript.", tmpls, lang.Language{})
+		if err == nil || !strings.Contains(err.Error(), "template meeting") {
+			t.Errorf("RestructureTemplates() error = %v, want the failed template named", err)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package restructure
+
+import "sync"
+
+// DefaultPromptTokenWarning is the default prompt size (in tokens, as reported
+// by the provider) above which a single call triggers a warning.
+// Map-reduce keeps parts well below this, so exceeding it usually means the
+// token estimate was off for this transcript (e.g., a non-Latin script).
+const DefaultPromptTokenWarning = 100000
+
+// Usage holds token counts reported by a provider.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// TotalTokens returns the sum of prompt and completion tokens.
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Add returns the sum of u and other.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+	}
+}
+
+// UsageTracker aggregates token usage across all API calls of a run,
+// including every map and reduce call of a MapReduceRestructurer.
+// It is safe for concurrent use. A nil *UsageTracker discards all records.
+type UsageTracker struct {
+	mu    sync.Mutex
+	total Usage
+	calls int
+
+	promptWarning int                     // 0 disables the warning.
+	onWarning     func(call int, u Usage) // Called when a prompt exceeds promptWarning.
+}
+
+// UsageTrackerOption configures a UsageTracker.
+type UsageTrackerOption func(*UsageTracker)
+
+// WithPromptTokenWarning calls fn for every call whose prompt exceeds threshold tokens.
+// call is the 1-based index of the call within the run.
+// A threshold <= 0 disables the warning.
+func WithPromptTokenWarning(threshold int, fn func(call int, u Usage)) UsageTrackerOption {
+	return func(t *UsageTracker) {
+		if threshold > 0 {
+			t.promptWarning = threshold
+			t.onWarning = fn
+		}
+	}
+}
+
+// NewUsageTracker creates an empty UsageTracker.
+func NewUsageTracker(opts ...UsageTrackerOption) *UsageTracker {
+	t := &UsageTracker{}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Record adds the usage of a single API call.
+func (t *UsageTracker) Record(u Usage) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	t.total = t.total.Add(u)
+	t.calls++
+	call := t.calls
+	warn := t.onWarning != nil && u.PromptTokens > t.promptWarning
+	t.mu.Unlock()
+
+	// Outside the lock: the callback may write to a shared output.
+	if warn {
+		t.onWarning(call, u)
+	}
+}
+
+// Total returns the aggregated usage.
+func (t *UsageTracker) Total() Usage {
+	if t == nil {
+		return Usage{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.total
+}
+
+// Calls returns the number of recorded API calls.
+func (t *UsageTracker) Calls() int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.calls
+}
+
+// usageReporter is implemented by providers that report token usage.
+// MapReduceRestructurer attaches its tracker to the underlying provider.
+type usageReporter interface {
+	setUsageTracker(t *UsageTracker)
+}
@@ -0,0 +1,121 @@
+package restructure_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/restructure"
+)
+
+// ---------------------------------------------------------------------------
+// TestUsage - Token count arithmetic
+// ---------------------------------------------------------------------------
+
+func TestUsage(t *testing.T) {
+	t.Parallel()
+
+	a := restructure.Usage{PromptTokens: 100, CompletionTokens: 50}
+	b := restructure.Usage{PromptTokens: 20, CompletionTokens: 5}
+
+	if got, want := a.TotalTokens(), 150; got != want {
+		t.Errorf("TotalTokens() = %d, want %d", got, want)
+	}
+
+	sum := a.Add(b)
+	want := restructure.Usage{PromptTokens: 120, CompletionTokens: 55}
+	if sum != want {
+		t.Errorf("Add() = %+v, want %+v", sum, want)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestUsageTracker - Aggregation and prompt size warnings
+// ---------------------------------------------------------------------------
+
+func TestUsageTracker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("aggregates calls", func(t *testing.T) {
+		t.Parallel()
+
+		tracker := restructure.NewUsageTracker()
+		tracker.Record(restructure.Usage{PromptTokens: 100, CompletionTokens: 50})
+		tracker.Record(restructure.Usage{PromptTokens: 200, CompletionTokens: 70})
+
+		if got, want := tracker.Calls(), 2; got != want {
+			t.Errorf("Calls() = %d, want %d", got, want)
+		}
+		want := restructure.Usage{PromptTokens: 300, CompletionTokens: 120}
+		if got := tracker.Total(); got != want {
+			t.Errorf("Total() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("nil tracker is a no-op", func(t *testing.T) {
+		t.Parallel()
+
+		var tracker *restructure.UsageTracker
+		tracker.Record(restructure.Usage{PromptTokens: 100})
+
+		if got := tracker.Calls(); got != 0 {
+			t.Errorf("Calls() = %d, want 0", got)
+		}
+		if got := tracker.Total(); got != (restructure.Usage{}) {
+			t.Errorf("Total() = %+v, want zero", got)
+		}
+	})
+
+	t.Run("warns when prompt exceeds threshold", func(t *testing.T) {
+		t.Parallel()
+
+		var warnedCalls []int
+		tracker := restructure.NewUsageTracker(
+			restructure.WithPromptTokenWarning(1000, func(call int, u restructure.Usage) {
+				warnedCalls = append(warnedCalls, call)
+			}),
+		)
+		tracker.Record(restructure.Usage{PromptTokens: 500})
+		tracker.Record(restructure.Usage{PromptTokens: 1000}) // At threshold: no warning
+		tracker.Record(restructure.Usage{PromptTokens: 1001})
+
+		if len(warnedCalls) != 1 || warnedCalls[0] != 3 {
+			t.Errorf("warned calls = %v, want [3]", warnedCalls)
+		}
+	})
+
+	t.Run("non-positive threshold disables warning", func(t *testing.T) {
+		t.Parallel()
+
+		warned := false
+		tracker := restructure.NewUsageTracker(
+			restructure.WithPromptTokenWarning(0, func(int, restructure.Usage) { warned = true }),
+		)
+		tracker.Record(restructure.Usage{PromptTokens: 1_000_000})
+
+		if warned {
+			t.Error("warning callback called with threshold 0")
+		}
+	})
+
+	t.Run("safe for concurrent use", func(t *testing.T) {
+		t.Parallel()
+
+		tracker := restructure.NewUsageTracker()
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				tracker.Record(restructure.Usage{PromptTokens: 2, CompletionTokens: 1})
+			}()
+		}
+		wg.Wait()
+
+		if got, want := tracker.Calls(), 50; got != want {
+			t.Errorf("Calls() = %d, want %d", got, want)
+		}
+		if got, want := tracker.Total().TotalTokens(), 150; got != want {
+			t.Errorf("Total().TotalTokens() = %d, want %d", got, want)
+		}
+	})
+}
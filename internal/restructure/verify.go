@@ -0,0 +1,64 @@
+package restructure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// VerifyKey checks the API key with the cheapest authenticated call: listing
+// the available models. Returns apierr.ErrAuthFailed if the key is rejected.
+func (r *OpenAIRestructurer) VerifyKey(ctx context.Context) error {
+	header := http.Header{"Authorization": {"Bearer " + r.apiKey}}
+	return verifyKey(ctx, r.httpClient, r.baseURL+"/v1/models", header,
+		func(status int, body []byte) error { return classifyRestructureError(parseOpenAIError(status, body)) })
+}
+
+// VerifyKey checks the API key with the cheapest authenticated call: listing
+// the available models. Returns apierr.ErrAuthFailed if the key is rejected.
+func (r *DeepSeekRestructurer) VerifyKey(ctx context.Context) error {
+	header := http.Header{"Authorization": {"Bearer " + r.apiKey}}
+	return verifyKey(ctx, r.httpClient, r.baseURL+"/models", header,
+		func(status int, body []byte) error { return classifyDeepSeekError(parseDeepSeekError(status, body)) })
+}
+
+// VerifyKey checks the API key with the cheapest authenticated call: listing
+// the available models. Returns apierr.ErrAuthFailed if the key is rejected.
+func (r *AnthropicRestructurer) VerifyKey(ctx context.Context) error {
+	header := http.Header{
+		"X-Api-Key":         {r.apiKey},
+		"Anthropic-Version": {anthropicAPIVersion},
+	}
+	return verifyKey(ctx, r.httpClient, r.baseURL+"/v1/models", header,
+		func(status int, body []byte) error { return classifyAnthropicError(parseAnthropicError(status, body)) })
+}
+
+// verifyKey sends an authenticated GET to url, free of charge with every
+// provider, and returns the error classify makes of a non-2xx response.
+func verifyKey(ctx context.Context, client httpDoer, url string, header http.Header, classify func(status int, body []byte) error) (err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header = header
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	return classify(resp.StatusCode, body)
+}
@@ -0,0 +1,82 @@
+package restructure_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/restructure"
+)
+
+func TestVerifyKey(t *testing.T) {
+	t.Parallel()
+
+	type verifier interface {
+		VerifyKey(ctx context.Context) error
+	}
+
+	providers := []struct {
+		name     string
+		path     string
+		validKey func(r *http.Request) bool
+		newFunc  func(t *testing.T, url, key string) verifier
+	}{
+		{
+			name:     "openai",
+			path:     "/v1/models",
+			validKey: func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer good-key" },
+			newFunc: func(t *testing.T, url, key string) verifier {
+				return restructure.NewOpenAIRestructurer(key, restructure.WithBaseURL(url))
+			},
+		},
+		{
+			name:     "deepseek",
+			path:     "/models",
+			validKey: func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer good-key" },
+			newFunc: func(t *testing.T, url, key string) verifier {
+				return mustNewDeepSeekRestructurer(t, key, restructure.WithDeepSeekBaseURL(url))
+			},
+		},
+		{
+			name: "anthropic",
+			path: "/v1/models",
+			validKey: func(r *http.Request) bool {
+				return r.Header.Get("X-Api-Key") == "good-key" && r.Header.Get("Anthropic-Version") != ""
+			},
+			newFunc: func(t *testing.T, url, key string) verifier {
+				return mustNewAnthropicRestructurer(t, key, restructure.WithAnthropicBaseURL(url))
+			},
+		},
+	}
+
+	for _, p := range providers {
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != p.path {
+					http.NotFound(w, r)
+					return
+				}
+				if !p.validKey(r) {
+					w.WriteHeader(http.StatusUnauthorized)
+					_, _ = w.Write([]byte(`{"error":{"message":"invalid api key"}}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"data":[]}`))
+			}))
+			t.Cleanup(server.Close)
+
+			if err := p.newFunc(t, server.URL, "good-key").VerifyKey(context.Background()); err != nil {
+				t.Errorf("VerifyKey() with a valid key = %v, want nil", err)
+			}
+			err := p.newFunc(t, server.URL, "bad-key").VerifyKey(context.Background())
+			if !errors.Is(err, apierr.ErrAuthFailed) {
+				t.Errorf("VerifyKey() with an invalid key = %v, want ErrAuthFailed", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Package schemas holds the JSON schemas of the machine-readable outputs
+// (session metadata, progress events, errors, task lists, statistics).
+//
+// Every document carries a schema_version field. Within a version, changes
+// are backward compatible: fields are only added, never removed, renamed or
+// given another type, so consumers written against a version keep working.
+// Any other change bumps the version.
+package schemas
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrUnknown indicates an invalid schema name was specified.
+var ErrUnknown = errors.New("unknown schema")
+
+// Schema name constants.
+const (
+	Metadata = "metadata" // session.json of a --session-dir session
+	Progress = "progress" // Progress events
+	Error    = "error"    // Error of a failed run
+	Tasks    = "tasks"    // Action items extracted from a transcript
+	Stats    = "stats"    // Statistics of a transcript and its run
+)
+
+// Current schema versions, written in the schema_version field of each document.
+const (
+	MetadataVersion = 1
+	ProgressVersion = 1
+	ErrorVersion    = 1
+	TasksVersion    = 1
+	StatsVersion    = 1
+)
+
+//go:embed *.json
+var files embed.FS
+
+// versions maps schema names to their current version.
+var versions = map[string]int{
+	Metadata: MetadataVersion,
+	Progress: ProgressVersion,
+	Error:    ErrorVersion,
+	Tasks:    TasksVersion,
+	Stats:    StatsVersion,
+}
+
+// Names returns the schema names, sorted.
+func Names() []string {
+	names := make([]string, 0, len(versions))
+	for name := range versions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Version returns the current version of the named schema.
+// Returns ErrUnknown if the name is not recognized.
+func Version(name string) (int, error) {
+	v, ok := versions[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown schema %q (valid schemas: %v): %w", name, Names(), ErrUnknown)
+	}
+	return v, nil
+}
+
+// Get returns the JSON schema (draft 2020-12) of the named output.
+// Returns ErrUnknown if the name is not recognized.
+func Get(name string) ([]byte, error) {
+	if _, err := Version(name); err != nil {
+		return nil, err
+	}
+	return files.ReadFile(name + ".json")
+}
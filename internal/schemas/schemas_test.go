@@ -0,0 +1,76 @@
+package schemas_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/schemas"
+)
+
+func TestNames(t *testing.T) {
+	t.Parallel()
+
+	want := []string{schemas.Error, schemas.Metadata, schemas.Progress, schemas.Stats, schemas.Tasks}
+	if got := schemas.Names(); !slices.Equal(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}
+
+// TestGet_VersionedDocuments checks that every schema is valid JSON and
+// requires the schema_version field, pinned to the current version.
+func TestGet_VersionedDocuments(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range schemas.Names() {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := schemas.Get(name)
+			if err != nil {
+				t.Fatalf("Get(%q) unexpected error: %v", name, err)
+			}
+			version, err := schemas.Version(name)
+			if err != nil {
+				t.Fatalf("Version(%q) unexpected error: %v", name, err)
+			}
+
+			var doc struct {
+				ID         string   `json:"$id"`
+				Required   []string `json:"required"`
+				Properties map[string]struct {
+					Const *int `json:"const"`
+				} `json:"properties"`
+			}
+			if err := json.Unmarshal(data, &doc); err != nil {
+				t.Fatalf("schema %q is not valid JSON: %v", name, err)
+			}
+
+			if want := fmt.Sprintf("/%s/v%d.json", name, version); !strings.HasSuffix(doc.ID, want) {
+				t.Errorf("$id = %q, want suffix %q", doc.ID, want)
+			}
+			if !slices.Contains(doc.Required, "schema_version") {
+				t.Errorf("required = %v, want schema_version", doc.Required)
+			}
+			if c := doc.Properties["schema_version"].Const; c == nil || *c != version {
+				t.Errorf("schema_version const = %v, want %d", c, version)
+			}
+		})
+	}
+}
+
+func TestGet_Unknown(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"", "Metadata", "schemas"} {
+		if _, err := schemas.Get(name); !errors.Is(err, schemas.ErrUnknown) {
+			t.Errorf("Get(%q) error = %v, want ErrUnknown", name, err)
+		}
+		if _, err := schemas.Version(name); !errors.Is(err, schemas.ErrUnknown) {
+			t.Errorf("Version(%q) error = %v, want ErrUnknown", name, err)
+		}
+	}
+}
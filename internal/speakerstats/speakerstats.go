@@ -0,0 +1,116 @@
+// Package speakerstats measures how each speaker of a diarized transcript
+// talked: talk time, turns, longest monologue, interruptions and pace.
+package speakerstats
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/format"
+)
+
+// Stats are the statistics of a transcript, per speaker.
+type Stats struct {
+	Duration time.Duration // End of the last cue
+	Speakers []Speaker     // By talk time, longest first
+}
+
+// Speaker is the statistics of one speaker of a transcript.
+type Speaker struct {
+	Name             string
+	TalkTime         time.Duration // Sum of the cues of the speaker
+	Share            float64       // Of the talk time of all speakers, from 0 to 1
+	Turns            int           // Runs of consecutive cues of the speaker
+	LongestMonologue time.Duration // Longest turn, from its first cue start to its last cue end
+	Interruptions    int           // Turns started before the previous speaker finished
+	Words            int
+}
+
+// WordsPerMinute returns the pace of s over its talk time, or 0 if it did
+// not talk.
+func (s Speaker) WordsPerMinute() float64 {
+	if s.TalkTime <= 0 {
+		return 0
+	}
+	return float64(s.Words) / s.TalkTime.Minutes()
+}
+
+// Words returns the number of words of all speakers.
+func (s Stats) Words() int {
+	var n int
+	for _, sp := range s.Speakers {
+		n += sp.Words
+	}
+	return n
+}
+
+// Compute returns the statistics of cues, in order of time. Cues without a
+// speaker, such as chunks transcribed without diarization, are left out: they
+// neither count nor break turns.
+func Compute(cues []format.Cue) Stats {
+	var (
+		stats    Stats
+		speakers []*Speaker
+		byName   = map[string]*Speaker{}
+		prev     *format.Cue   // Last cue with a speaker
+		start    time.Duration // Of the current turn
+	)
+	for i := range cues {
+		c := &cues[i]
+		stats.Duration = max(stats.Duration, c.End)
+		if c.Speaker == "" {
+			continue
+		}
+		sp := byName[c.Speaker]
+		if sp == nil {
+			sp = &Speaker{Name: c.Speaker}
+			byName[c.Speaker] = sp
+			speakers = append(speakers, sp)
+		}
+		sp.TalkTime += max(c.End-c.Start, 0)
+		sp.Words += len(strings.Fields(c.Text))
+
+		if prev == nil || prev.Speaker != c.Speaker {
+			sp.Turns++
+			if prev != nil && c.Start < prev.End {
+				sp.Interruptions++
+			}
+			start = c.Start
+		}
+		sp.LongestMonologue = max(sp.LongestMonologue, c.End-start)
+		prev = c
+	}
+
+	var total time.Duration
+	for _, sp := range speakers {
+		total += sp.TalkTime
+	}
+	for _, sp := range speakers {
+		if total > 0 {
+			sp.Share = float64(sp.TalkTime) / float64(total)
+		}
+		stats.Speakers = append(stats.Speakers, *sp)
+	}
+	slices.SortStableFunc(stats.Speakers, func(a, b Speaker) int {
+		return cmp.Compare(b.TalkTime, a.TalkTime)
+	})
+	return stats
+}
+
+// Markdown renders s as a "## Speaker statistics" section: one table row per
+// speaker.
+func (s Stats) Markdown() string {
+	var b strings.Builder
+	b.WriteString("## Speaker statistics\n\n")
+	b.WriteString("| Speaker | Talk time | Share | Turns | Longest monologue | Interruptions | Words/min |\n")
+	b.WriteString("|---|---|---|---|---|---|---|\n")
+	for _, sp := range s.Speakers {
+		fmt.Fprintf(&b, "| %s | %s | %.0f%% | %d | %s | %d | %.0f |\n",
+			sp.Name, format.Duration(sp.TalkTime), sp.Share*100, sp.Turns,
+			format.Duration(sp.LongestMonologue), sp.Interruptions, sp.WordsPerMinute())
+	}
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package speakerstats_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/format"
+	"github.com/alnah/go-transcript/internal/speakerstats"
+)
+
+func TestCompute(t *testing.T) {
+	t.Parallel()
+
+	sec := func(s float64) time.Duration { return time.Duration(s * float64(time.Second)) }
+	cues := []format.Cue{
+		{Start: 0, End: sec(20), Speaker: "Ana", Text: "Welcome everyone to the weekly sync."},
+		{Start: sec(20), End: sec(40), Speaker: "Ana", Text: "First, the release."},
+		{Start: sec(38), End: sec(50), Speaker: "Ben", Text: "Sorry, quick question."},
+		{Start: sec(50), End: sec(55), Text: "Unlabeled chunk."},
+		{Start: sec(55), End: sec(60), Speaker: "Ben", Text: "Is it Friday?"},
+		{Start: sec(61), End: sec(70), Speaker: "Ana", Text: "Yes."},
+	}
+
+	stats := speakerstats.Compute(cues)
+
+	if stats.Duration != sec(70) {
+		t.Errorf("Duration = %v, want 1m10s", stats.Duration)
+	}
+	if len(stats.Speakers) != 2 {
+		t.Fatalf("Speakers = %+v, want 2", stats.Speakers)
+	}
+	ana, ben := stats.Speakers[0], stats.Speakers[1]
+	if ana.Name != "Ana" || ben.Name != "Ben" {
+		t.Fatalf("Speakers = %q, %q, want Ana then Ben (longest talk time first)", ana.Name, ben.Name)
+	}
+
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"Ana talk time", ana.TalkTime, sec(49)},
+		{"Ana turns", ana.Turns, 2},
+		{"Ana longest monologue", ana.LongestMonologue, sec(40)},
+		{"Ana interruptions", ana.Interruptions, 0},
+		{"Ana words", ana.Words, 10},
+		{"Ben talk time", ben.TalkTime, sec(17)},
+		{"Ben turns", ben.Turns, 1},
+		{"Ben longest monologue", ben.LongestMonologue, sec(22)},
+		{"Ben interruptions", ben.Interruptions, 1},
+		{"Ben words", ben.Words, 6},
+		{"words", stats.Words(), 16},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if math.Abs(ana.Share+ben.Share-1) > 1e-9 || math.Abs(ana.Share-49.0/66) > 1e-9 {
+		t.Errorf("shares = %v, %v, want 49/66 and 17/66", ana.Share, ben.Share)
+	}
+	if wpm := ben.WordsPerMinute(); math.Abs(wpm-6/(17.0/60)) > 1e-9 {
+		t.Errorf("Ben WordsPerMinute() = %v, want %v", wpm, 6/(17.0/60))
+	}
+	if wpm := (speakerstats.Speaker{Words: 3}).WordsPerMinute(); wpm != 0 {
+		t.Errorf("WordsPerMinute() without talk time = %v, want 0", wpm)
+	}
+
+	if empty := speakerstats.Compute(nil); len(empty.Speakers) != 0 || empty.Duration != 0 {
+		t.Errorf("Compute(nil) = %+v, want no statistics", empty)
+	}
+}
+
+func TestStats_Markdown(t *testing.T) {
+	t.Parallel()
+
+	stats := speakerstats.Stats{
+		Duration: 3 * time.Minute,
+		Speakers: []speakerstats.Speaker{
+			{Name: "Me", TalkTime: 2 * time.Minute, Share: 0.75, Turns: 4, LongestMonologue: 70 * time.Second, Words: 300},
+			{Name: "Remote", TalkTime: 40 * time.Second, Share: 0.25, Turns: 3, LongestMonologue: 20 * time.Second, Interruptions: 2, Words: 100},
+		},
+	}
+	want := "## Speaker statistics\n\n" +
+		"| Speaker | Talk time | Share | Turns | Longest monologue | Interruptions | Words/min |\n" +
+		"|---|---|---|---|---|---|---|\n" +
+		"| Me | 02:00 | 75% | 4 | 01:10 | 0 | 150 |\n" +
+		"| Remote | 00:40 | 25% | 3 | 00:20 | 2 | 150 |\n"
+	if got := stats.Markdown(); got != want {
+		t.Errorf("Markdown() =\n%s\nwant\n%s", got, want)
+	}
+}
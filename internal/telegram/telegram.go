@@ -0,0 +1,330 @@
+// Package telegram is a minimal client of the Telegram Bot API (direct HTTP):
+// long polling for messages, file downloads, and text and document replies.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+)
+
+// Bot API configuration and limits.
+const (
+	defaultBaseURL     = "https://api.telegram.org"
+	defaultPollTimeout = 50 * time.Second // Long polling wait for new messages
+	defaultHTTPTimeout = 5 * time.Minute  // Downloads and document uploads
+
+	// MaxDownloadSize is the largest file a bot can download (20 MB).
+	MaxDownloadSize = 20 * 1024 * 1024
+	// MaxMessageLength is the longest text message, in UTF-16 code units
+	// (counted as characters here, which is exact for most text).
+	MaxMessageLength = 4096
+
+	// Response size limit to prevent OOM from malformed responses (10MB)
+	maxResponseSize = 10 * 1024 * 1024
+)
+
+// ErrEmptyToken indicates the bot token is empty.
+var ErrEmptyToken = errors.New("telegram bot token is empty")
+
+// Bot receives messages sent to a bot and replies to them.
+type Bot interface {
+	// Updates waits for messages after offset (the last update ID + 1).
+	Updates(ctx context.Context, offset int64) ([]Update, error)
+	// Download writes the file with the given ID to w.
+	Download(ctx context.Context, fileID string, w io.Writer) (filePath string, err error)
+	// SendMessage sends a text message to a chat.
+	SendMessage(ctx context.Context, chatID int64, text string) error
+	// SendDocument sends content as a file named name, with an optional caption.
+	SendDocument(ctx context.Context, chatID int64, name string, content []byte, caption string) error
+}
+
+// Update is an incoming event. Only messages are requested.
+type Update struct {
+	ID      int64    `json:"update_id"`
+	Message *Message `json:"message"`
+}
+
+// Message is a message sent to the bot.
+type Message struct {
+	ID       int64     `json:"message_id"`
+	From     *User     `json:"from"`
+	Chat     Chat      `json:"chat"`
+	Text     string    `json:"text"`
+	Voice    *File     `json:"voice"`
+	Audio    *File     `json:"audio"`
+	Document *Document `json:"document"`
+}
+
+// User is the sender of a message.
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// Chat is the conversation a message belongs to.
+type Chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"` // "private" for direct messages
+}
+
+// File is an audio file or voice note.
+type File struct {
+	FileID   string `json:"file_id"`
+	FileName string `json:"file_name"` // Empty for voice notes
+	MimeType string `json:"mime_type"`
+	FileSize int64  `json:"file_size"`
+	Duration int    `json:"duration"` // Seconds
+}
+
+// Document is a file sent as an attachment.
+type Document struct {
+	FileID   string `json:"file_id"`
+	FileName string `json:"file_name"`
+	MimeType string `json:"mime_type"`
+	FileSize int64  `json:"file_size"`
+}
+
+// Attachment returns the audio of a message: a voice note, an audio file,
+// or an audio document. Returns false if the message has none.
+func (m *Message) Attachment() (File, bool) {
+	switch {
+	case m.Voice != nil:
+		return *m.Voice, true
+	case m.Audio != nil:
+		return *m.Audio, true
+	case m.Document != nil && strings.HasPrefix(m.Document.MimeType, "audio/"):
+		d := m.Document
+		return File{FileID: d.FileID, FileName: d.FileName, MimeType: d.MimeType, FileSize: d.FileSize}, true
+	}
+	return File{}, false
+}
+
+// httpDoer abstracts HTTP client for testing.
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Compile-time interface compliance check.
+var _ Bot = (*Client)(nil)
+
+// Client implements Bot with the Telegram Bot API.
+type Client struct {
+	token       string
+	baseURL     string
+	pollTimeout time.Duration
+	httpClient  httpDoer
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL sets a custom API URL (for testing or a local Bot API server).
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithPollTimeout sets how long Updates waits for new messages.
+func WithPollTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d >= 0 {
+			c.pollTimeout = d
+		}
+	}
+}
+
+// NewClient creates a client for the bot with the given token.
+// Returns ErrEmptyToken if token is empty.
+func NewClient(token string, opts ...Option) (*Client, error) {
+	if token == "" {
+		return nil, ErrEmptyToken
+	}
+	c := &Client{
+		token:       token,
+		baseURL:     defaultBaseURL,
+		pollTimeout: defaultPollTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.httpClient == nil {
+		// Long polls must outlast the poll timeout.
+		c.httpClient = &http.Client{Timeout: max(defaultHTTPTimeout, c.pollTimeout+30*time.Second)}
+	}
+	return c, nil
+}
+
+// Updates waits up to the poll timeout for messages after offset.
+func (c *Client) Updates(ctx context.Context, offset int64) ([]Update, error) {
+	params := url.Values{
+		"offset":          {strconv.FormatInt(offset, 10)},
+		"timeout":         {strconv.Itoa(int(c.pollTimeout.Seconds()))},
+		"allowed_updates": {`["message"]`},
+	}
+	var updates []Update
+	if err := c.call(ctx, "getUpdates", params, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// Download writes the file with the given ID to w and returns its path on
+// the Telegram servers (whose extension tells the format, e.g. voice/file_1.oga).
+func (c *Client) Download(ctx context.Context, fileID string, w io.Writer) (_ string, err error) {
+	var file struct {
+		FilePath string `json:"file_path"`
+		FileSize int64  `json:"file_size"`
+	}
+	if err := c.call(ctx, "getFile", url.Values{"file_id": {fileID}}, &file); err != nil {
+		return "", err
+	}
+	if file.FilePath == "" {
+		return "", fmt.Errorf("file %s is not available (larger than %d MB?)", fileID, MaxDownloadSize/1024/1024)
+	}
+
+	fileURL := fmt.Sprintf("%s/file/bot%s/%s", c.baseURL, c.token, file.FilePath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", redactToken(err, c.token)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download of %s failed with status %d", file.FilePath, resp.StatusCode)
+	}
+	if _, err := io.Copy(w, io.LimitReader(resp.Body, MaxDownloadSize)); err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", file.FilePath, err)
+	}
+	return file.FilePath, nil
+}
+
+// SendMessage sends text to a chat. text must not exceed MaxMessageLength.
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string) error {
+	params := url.Values{
+		"chat_id": {strconv.FormatInt(chatID, 10)},
+		"text":    {text},
+	}
+	return c.call(ctx, "sendMessage", params, nil)
+}
+
+// SendDocument sends content as a file attachment named name.
+func (c *Client) SendDocument(ctx context.Context, chatID int64, name string, content []byte, caption string) error {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("chat_id", strconv.FormatInt(chatID, 10))
+	if caption != "" {
+		_ = mw.WriteField("caption", caption)
+	}
+	part, err := mw.CreateFormFile("document", name)
+	if err != nil {
+		return fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		return fmt.Errorf("failed to write form file: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+	return c.do(ctx, "sendDocument", mw.FormDataContentType(), &body, nil)
+}
+
+// call invokes an API method with form parameters and decodes its result into result.
+func (c *Client) call(ctx context.Context, method string, params url.Values, result any) error {
+	return c.do(ctx, method, "application/x-www-form-urlencoded", strings.NewReader(params.Encode()), result)
+}
+
+// apiResponse is the envelope of every Bot API response.
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// do posts body to an API method and decodes its result into result (if not nil).
+func (c *Client) do(ctx context.Context, method, contentType string, body io.Reader, result any) (err error) {
+	endpoint := fmt.Sprintf("%s/bot%s/%s", c.baseURL, c.token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// The URL holds the token: never let it reach logs.
+		return redactToken(err, c.token)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	var envelope apiResponse
+	if err := json.Unmarshal(respBody, &envelope); err != nil {
+		return fmt.Errorf("failed to parse %s response (status %d): %w", method, resp.StatusCode, err)
+	}
+	if !envelope.OK {
+		return classifyError(method, envelope)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Result, result); err != nil {
+		return fmt.Errorf("failed to parse %s result: %w", method, err)
+	}
+	return nil
+}
+
+// classifyError maps a Bot API error to the apierr sentinels.
+func classifyError(method string, resp apiResponse) error {
+	msg := fmt.Sprintf("Telegram %s error %d: %s", method, resp.ErrorCode, resp.Description)
+	switch resp.ErrorCode {
+	case http.StatusUnauthorized, http.StatusNotFound: // Invalid token
+		return fmt.Errorf("%s: %w", msg, apierr.ErrAuthFailed)
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("%s (retry after %ds): %w", msg, resp.Parameters.RetryAfter, apierr.ErrRateLimit)
+	default:
+		if resp.ErrorCode >= 400 && resp.ErrorCode < 500 {
+			return fmt.Errorf("%s: %w", msg, apierr.ErrBadRequest)
+		}
+		return errors.New(msg)
+	}
+}
+
+// redactToken removes the bot token from err, which may quote the request URL.
+func redactToken(err error, token string) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("telegram request failed: %w", urlErr.Err)
+	}
+	return errors.New(strings.ReplaceAll(err.Error(), token, "<token>"))
+}
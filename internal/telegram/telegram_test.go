@@ -0,0 +1,189 @@
+package telegram_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/telegram"
+)
+
+const testToken = "123:secret"
+
+// newTestClient returns a client of a test server running handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *telegram.Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c, err := telegram.NewClient(testToken, telegram.WithBaseURL(server.URL), telegram.WithPollTimeout(0))
+	if err != nil {
+		t.Fatalf("NewClient() unexpected error: %v", err)
+	}
+	return c
+}
+
+func TestNewClient_EmptyToken(t *testing.T) {
+	t.Parallel()
+
+	if _, err := telegram.NewClient(""); !errors.Is(err, telegram.ErrEmptyToken) {
+		t.Errorf("NewClient(\"\") error = %v, want ErrEmptyToken", err)
+	}
+}
+
+func TestClient_Updates(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bot"+testToken+"/getUpdates" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.FormValue("offset"); got != "42" {
+			t.Errorf("offset = %q, want 42", got)
+		}
+		_, _ = io.WriteString(w, `{"ok":true,"result":[{"update_id":42,"message":{"message_id":7,
+			"from":{"id":1,"username":"ada"},"chat":{"id":99,"type":"private"},
+			"voice":{"file_id":"f1","mime_type":"audio/ogg","file_size":1000,"duration":3}}}]}`)
+	})
+
+	updates, err := c.Updates(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("Updates() unexpected error: %v", err)
+	}
+	if len(updates) != 1 || updates[0].Message == nil {
+		t.Fatalf("Updates() = %+v, want one message", updates)
+	}
+	msg := updates[0].Message
+	if msg.Chat.ID != 99 || msg.From.Username != "ada" {
+		t.Errorf("message = %+v", msg)
+	}
+	file, ok := msg.Attachment()
+	if !ok || file.FileID != "f1" {
+		t.Errorf("Attachment() = %+v, %v, want voice f1", file, ok)
+	}
+}
+
+func TestMessage_Attachment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		msg    telegram.Message
+		wantID string
+		wantOK bool
+	}{
+		{"voice", telegram.Message{Voice: &telegram.File{FileID: "v"}}, "v", true},
+		{"audio", telegram.Message{Audio: &telegram.File{FileID: "a", FileName: "talk.mp3"}}, "a", true},
+		{"audio document", telegram.Message{Document: &telegram.Document{FileID: "d", MimeType: "audio/mp4"}}, "d", true},
+		{"other document", telegram.Message{Document: &telegram.Document{FileID: "d", MimeType: "application/pdf"}}, "", false},
+		{"text", telegram.Message{Text: "hello"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			file, ok := tt.msg.Attachment()
+			if ok != tt.wantOK || file.FileID != tt.wantID {
+				t.Errorf("Attachment() = %q, %v, want %q, %v", file.FileID, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestClient_Download(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bot" + testToken + "/getFile":
+			_, _ = io.WriteString(w, `{"ok":true,"result":{"file_id":"f1","file_path":"voice/file_1.oga"}}`)
+		case "/file/bot" + testToken + "/voice/file_1.oga":
+			_, _ = io.WriteString(w, "audio data")
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	var buf bytes.Buffer
+	path, err := c.Download(context.Background(), "f1", &buf)
+	if err != nil {
+		t.Fatalf("Download() unexpected error: %v", err)
+	}
+	if path != "voice/file_1.oga" || buf.String() != "audio data" {
+		t.Errorf("Download() = %q, %q", path, buf.String())
+	}
+}
+
+func TestClient_SendDocument(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("ParseMultipartForm() error: %v", err)
+		}
+		if r.FormValue("chat_id") != "99" || r.FormValue("caption") != "Notes" {
+			t.Errorf("form = %v", r.MultipartForm.Value)
+		}
+		file, header, err := r.FormFile("document")
+		if err != nil {
+			t.Fatalf("FormFile() error: %v", err)
+		}
+		content, _ := io.ReadAll(file)
+		if header.Filename != "notes.md" || string(content) != "# Notes" {
+			t.Errorf("document = %q: %q", header.Filename, content)
+		}
+		_, _ = io.WriteString(w, `{"ok":true,"result":{}}`)
+	})
+
+	if err := c.SendDocument(context.Background(), 99, "notes.md", []byte("# Notes"), "Notes"); err != nil {
+		t.Errorf("SendDocument() unexpected error: %v", err)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"invalid token", `{"ok":false,"error_code":401,"description":"Unauthorized"}`, apierr.ErrAuthFailed},
+		{"flood control", `{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":5}}`, apierr.ErrRateLimit},
+		{"bad request", `{"ok":false,"error_code":400,"description":"Bad Request: message is too long"}`, apierr.ErrBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.WriteString(w, tt.body)
+			})
+			err := c.SendMessage(context.Background(), 1, "hi")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("SendMessage() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_RedactsToken(t *testing.T) {
+	t.Parallel()
+
+	// Nothing listens on this port: the request fails with the URL in the error.
+	c, err := telegram.NewClient(testToken, telegram.WithBaseURL("http://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("NewClient() unexpected error: %v", err)
+	}
+	err = c.SendMessage(context.Background(), 1, "hi")
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("SendMessage() error = %v, want an error without the token", err)
+	}
+}
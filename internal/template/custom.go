@@ -0,0 +1,214 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrInvalid indicates a user template file could not be parsed.
+var ErrInvalid = errors.New("invalid template")
+
+// FileExt is the extension of user template files.
+const FileExt = ".md"
+
+// frontMatterDelim opens and closes the optional front-matter of a template file.
+const frontMatterDelim = "---"
+
+// validName matches user template names: lowercase letters, digits,
+// hyphens and underscores, starting with a letter or digit.
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ParseFile loads a user template from a markdown file.
+//
+// The file holds the prompt, optionally preceded by a front-matter block:
+//
+//	---
+//	name: standup
+//	description: Daily standup notes
+//	---
+//	You restructure a standup transcript into markdown...
+//
+// The name defaults to the file name without extension. Returns ErrInvalid
+// if the front-matter is malformed, the name is invalid or shadows a built-in
+// template, or the prompt is empty.
+func ParseFile(path string) (Name, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- user-provided template path
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Name{}, fmt.Errorf("template file not found: %s: %w", path, ErrUnknown)
+		}
+		return Name{}, fmt.Errorf("failed to read template: %w", err)
+	}
+
+	n, err := parse(string(data))
+	if err != nil {
+		return Name{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if n.name == "" {
+		n.name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if !validName.MatchString(n.name) {
+		return Name{}, fmt.Errorf("%s: name %q must be lowercase letters, digits, '-' or '_': %w", path, n.name, ErrInvalid)
+	}
+	if _, ok := templates[n.name]; ok {
+		return Name{}, fmt.Errorf("%s: name %q is a built-in template: %w", path, n.name, ErrInvalid)
+	}
+	n.path = path
+	return n, nil
+}
+
+// parse splits a template file into its front-matter and prompt.
+func parse(content string) (Name, error) {
+	content = strings.TrimPrefix(content, "\ufeff") // UTF-8 BOM
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var n Name
+	if rest, ok := strings.CutPrefix(content, frontMatterDelim+"\n"); ok {
+		header, body, found := strings.Cut(rest, "\n"+frontMatterDelim+"\n")
+		if !found {
+			// Closing delimiter at end of file (no prompt after it).
+			header, found = strings.CutSuffix(strings.TrimRight(rest, "\n"), "\n"+frontMatterDelim)
+		}
+		if !found {
+			return Name{}, fmt.Errorf("front-matter is not closed with %q: %w", frontMatterDelim, ErrInvalid)
+		}
+		if err := n.parseFrontMatter(header); err != nil {
+			return Name{}, err
+		}
+		content = body
+	}
+
+	n.prompt = strings.TrimSpace(content)
+	if n.prompt == "" {
+		return Name{}, fmt.Errorf("prompt is empty: %w", ErrInvalid)
+	}
+	return n, nil
+}
+
+// parseFrontMatter reads the "key: value" lines of a front-matter block.
+// Only name and description are supported; other keys are rejected to
+// catch typos early.
+func (n *Name) parseFrontMatter(header string) error {
+	for i, line := range strings.Split(header, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("front-matter line %d: expected key: value: %w", i+1, ErrInvalid)
+		}
+		value = unquote(strings.TrimSpace(value))
+		switch strings.TrimSpace(key) {
+		case "name":
+			n.name = value
+		case "description":
+			n.description = value
+		default:
+			return fmt.Errorf("front-matter line %d: unknown key %q (valid keys: name, description): %w",
+				i+1, strings.TrimSpace(key), ErrInvalid)
+		}
+	}
+	return nil
+}
+
+// unquote removes matching single or double quotes around a value.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// LoadDir loads the user templates stored in dir, sorted by name.
+// A missing directory holds no templates (not an error). Invalid files and
+// duplicate names are reported in the returned error, joined; the valid
+// templates are returned alongside.
+func LoadDir(dir string) ([]Name, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read templates directory: %w", err)
+	}
+
+	var (
+		result []Name
+		errs   []error
+		seen   = make(map[string]string)
+	)
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != FileExt {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		n, err := ParseFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if first, ok := seen[n.name]; ok {
+			errs = append(errs, fmt.Errorf("%s: name %q is already used by %s: %w", path, n.name, first, ErrInvalid))
+			continue
+		}
+		seen[n.name] = path
+		result = append(result, n)
+	}
+
+	slices.SortFunc(result, func(a, b Name) int { return strings.Compare(a.name, b.name) })
+	return result, errors.Join(errs...)
+}
+
+// Resolve parses a --template value: a built-in template name, the path of
+// a template file, or the name of a user template stored in dir.
+// Values containing a path separator or ending in FileExt are read as files.
+// An empty dir disables the lookup of user templates by name.
+// Returns ErrUnknown if the name matches no template.
+func Resolve(s, dir string) (Name, error) {
+	if _, ok := templates[s]; ok || s == "" {
+		return ParseName(s)
+	}
+	if isPath(s) {
+		return ParseFile(s)
+	}
+	if dir == "" {
+		return ParseName(s)
+	}
+
+	user, err := LoadDir(dir)
+	for _, n := range user {
+		if n.name == s {
+			return n, nil
+		}
+	}
+	if err != nil {
+		// The template may be one of the invalid files: report why.
+		return Name{}, fmt.Errorf("unknown template %q: %w", s, errors.Join(ErrUnknown, err))
+	}
+	names := Names()
+	for _, n := range user {
+		names = append(names, n.name)
+	}
+	return Name{}, fmt.Errorf("unknown template %q (available: %s): %w", s, strings.Join(names, ", "), ErrUnknown)
+}
+
+// isPath reports whether a --template value designates a file.
+func isPath(s string) bool {
+	return strings.ContainsRune(s, '/') || strings.ContainsRune(s, filepath.Separator) ||
+		strings.HasSuffix(s, FileExt)
+}
+
+// Builtins returns the built-in templates, in the order of Names.
+func Builtins() []Name {
+	result := make([]Name, len(templateOrder))
+	for i, name := range templateOrder {
+		result[i] = Name{name: name}
+	}
+	return result
+}
@@ -0,0 +1,271 @@
+package template_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+// writeTemplate writes a template file in dir and returns its path.
+func writeTemplate(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	return path
+}
+
+// ---------------------------------------------------------------------------
+// TestParseFile - User template files with optional front-matter
+// ---------------------------------------------------------------------------
+
+func TestParseFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		wantName string
+		wantDesc string
+		wantBody string
+	}{
+		{
+			name:     "front-matter",
+			file:     "x.md",
+			content:  "---\nname: standup\ndescription: Daily standup notes\n---\nYou restructure a standup.\n",
+			wantName: "standup",
+			wantDesc: "Daily standup notes",
+			wantBody: "You restructure a standup.",
+		},
+		{
+			name:     "no front-matter uses file name",
+			file:     "retro.md",
+			content:  "You restructure a retrospective.",
+			wantName: "retro",
+			wantBody: "You restructure a retrospective.",
+		},
+		{
+			name:     "quoted values and comments",
+			file:     "x.md",
+			content:  "---\n# team template\nname: \"one-on-one\"\ndescription: 'Notes: 1:1'\n---\n\nPrompt\n",
+			wantName: "one-on-one",
+			wantDesc: "Notes: 1:1",
+			wantBody: "Prompt",
+		},
+		{
+			name:     "CRLF line endings",
+			file:     "x.md",
+			content:  "---\r\nname: crlf\r\n---\r\nPrompt\r\n",
+			wantName: "crlf",
+			wantBody: "Prompt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := writeTemplate(t, t.TempDir(), tt.file, tt.content)
+			n, err := template.ParseFile(path)
+			if err != nil {
+				t.Fatalf("ParseFile() unexpected error: %v", err)
+			}
+			if n.String() != tt.wantName {
+				t.Errorf("String() = %q, want %q", n.String(), tt.wantName)
+			}
+			if n.Description() != tt.wantDesc {
+				t.Errorf("Description() = %q, want %q", n.Description(), tt.wantDesc)
+			}
+			if n.Prompt() != tt.wantBody {
+				t.Errorf("Prompt() = %q, want %q", n.Prompt(), tt.wantBody)
+			}
+			if n.Path() != path {
+				t.Errorf("Path() = %q, want %q", n.Path(), path)
+			}
+		})
+	}
+}
+
+func TestParseFile_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		file       string
+		content    string
+		errContain string
+	}{
+		{"empty prompt", "x.md", "---\nname: empty\n---\n  \n", "prompt is empty"},
+		{"empty file", "x.md", "", "prompt is empty"},
+		{"unclosed front-matter", "x.md", "---\nname: open\nPrompt\n", "not closed"},
+		{"unknown key", "x.md", "---\ntitle: Standup\n---\nPrompt", "unknown key \"title\""},
+		{"line without colon", "x.md", "---\nname standup\n---\nPrompt", "expected key: value"},
+		{"invalid name", "x.md", "---\nname: Stand Up\n---\nPrompt", "must be lowercase"},
+		{"invalid file name", "My Template.md", "Prompt", "must be lowercase"},
+		{"shadows built-in", "meeting.md", "Prompt", "built-in template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := writeTemplate(t, t.TempDir(), tt.file, tt.content)
+			_, err := template.ParseFile(path)
+			if !errors.Is(err, template.ErrInvalid) {
+				t.Fatalf("ParseFile() error = %v, want ErrInvalid", err)
+			}
+			if !strings.Contains(err.Error(), tt.errContain) {
+				t.Errorf("ParseFile() error = %q, want containing %q", err, tt.errContain)
+			}
+		})
+	}
+}
+
+func TestParseFile_Missing(t *testing.T) {
+	t.Parallel()
+
+	_, err := template.ParseFile(filepath.Join(t.TempDir(), "missing.md"))
+	if !errors.Is(err, template.ErrUnknown) {
+		t.Errorf("ParseFile() error = %v, want ErrUnknown", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestLoadDir - Templates directory scanning
+// ---------------------------------------------------------------------------
+
+func TestLoadDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTemplate(t, dir, "standup.md", "Standup prompt")
+	writeTemplate(t, dir, "a-retro.md", "---\nname: retro\n---\nRetro prompt")
+	writeTemplate(t, dir, "retro.md", "Duplicate retro")
+	writeTemplate(t, dir, "broken.md", "---\nname: broken\n")
+	writeTemplate(t, dir, "readme.txt", "not a template")
+
+	got, err := template.LoadDir(dir)
+
+	var names []string
+	for _, n := range got {
+		names = append(names, n.String())
+	}
+	if strings.Join(names, ",") != "retro,standup" {
+		t.Errorf("LoadDir() names = %v, want [retro standup]", names)
+	}
+	if !errors.Is(err, template.ErrInvalid) {
+		t.Fatalf("LoadDir() error = %v, want ErrInvalid", err)
+	}
+	for _, want := range []string{"broken.md", "already used"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadDir() error = %q, want containing %q", err, want)
+		}
+	}
+}
+
+func TestLoadDir_Missing(t *testing.T) {
+	t.Parallel()
+
+	got, err := template.LoadDir(filepath.Join(t.TempDir(), "none"))
+	if err != nil || got != nil {
+		t.Errorf("LoadDir() = %v, %v, want nil, nil", got, err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestResolve - --template values: built-in name, file path, user name
+// ---------------------------------------------------------------------------
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTemplate(t, dir, "standup.md", "Standup prompt")
+	other := t.TempDir()
+	file := writeTemplate(t, other, "retro.md", "Retro prompt")
+
+	tests := []struct {
+		name       string
+		value      string
+		dir        string
+		wantName   string
+		wantPrompt string
+	}{
+		{"built-in", template.Meeting, dir, template.Meeting, template.MeetingName.Prompt()},
+		{"file path", file, dir, "retro", "Retro prompt"},
+		{"user name", "standup", dir, "standup", "Standup prompt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			n, err := template.Resolve(tt.value, tt.dir)
+			if err != nil {
+				t.Fatalf("Resolve(%q) unexpected error: %v", tt.value, err)
+			}
+			if n.String() != tt.wantName || n.Prompt() != tt.wantPrompt {
+				t.Errorf("Resolve(%q) = %q (%q), want %q (%q)", tt.value, n, n.Prompt(), tt.wantName, tt.wantPrompt)
+			}
+		})
+	}
+}
+
+func TestResolve_Unknown(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTemplate(t, dir, "standup.md", "Standup prompt")
+
+	tests := []struct {
+		name  string
+		value string
+		dir   string
+	}{
+		{"empty", "", dir},
+		{"unknown name", "retro", dir},
+		{"user name without dir", "standup", ""},
+		{"missing file", filepath.Join(dir, "retro.md"), dir},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := template.Resolve(tt.value, tt.dir); !errors.Is(err, template.ErrUnknown) {
+				t.Errorf("Resolve(%q) error = %v, want ErrUnknown", tt.value, err)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestBuiltins - Built-in templates are listed with descriptions
+// ---------------------------------------------------------------------------
+
+func TestBuiltins(t *testing.T) {
+	t.Parallel()
+
+	builtins := template.Builtins()
+	names := template.Names()
+	if len(builtins) != len(names) {
+		t.Fatalf("Builtins() returned %d templates, want %d", len(builtins), len(names))
+	}
+	for i, n := range builtins {
+		if n.String() != names[i] {
+			t.Errorf("Builtins()[%d] = %q, want %q", i, n, names[i])
+		}
+		if n.Description() == "" {
+			t.Errorf("Builtins()[%d].Description() is empty", i)
+		}
+		if n.Path() != "" {
+			t.Errorf("Builtins()[%d].Path() = %q, want empty", i, n.Path())
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidLevel indicates an unknown summary level was specified.
+var ErrInvalidLevel = errors.New("invalid summary level")
+
+// Summary level names (--summary-level).
+const (
+	Short    = "short"
+	Medium   = "medium"
+	Detailed = "detailed"
+)
+
+// Level represents a validated summary level: how much of the transcript a
+// template keeps, from an executive summary to a detailed breakdown.
+// Zero value means "not set": the template as written, like Medium.
+type Level struct {
+	name string
+}
+
+// Pre-parsed summary level constants for use in code.
+var (
+	ShortLevel    = Level{name: Short}
+	MediumLevel   = Level{name: Medium}
+	DetailedLevel = Level{name: Detailed}
+)
+
+// levelRules are appended to the prompt of a template at each summary level.
+// Medium keeps the template as written.
+var levelRules = map[string]string{
+	Short: `Summary level: short.
+Instead of the full structure above, write an executive summary of at most 5 lines under the main title: the outcome, the key decisions and the action items, in order of importance. Keep the output language and the formatting rules above; drop everything else.`,
+	Detailed: `Summary level: detailed.
+Follow the structure above, but write a detailed breakdown: keep every point discussed, with its context, reasoning, examples, figures and open questions, in as many sections and bullet points as needed. Do not condense or merge points to save space.`,
+}
+
+// ParseLevel validates and parses a summary level name.
+// Returns ErrInvalidLevel if the name is not recognized.
+func ParseLevel(s string) (Level, error) {
+	switch s {
+	case Short, Medium, Detailed:
+		return Level{name: s}, nil
+	case "":
+		return Level{}, fmt.Errorf("summary level cannot be empty: %w", ErrInvalidLevel)
+	default:
+		return Level{}, fmt.Errorf("unknown summary level %q (use %s, %s or %s): %w", s, Short, Medium, Detailed, ErrInvalidLevel)
+	}
+}
+
+// String returns the summary level name.
+// Returns empty string for zero value.
+func (l Level) String() string {
+	return l.name
+}
+
+// IsZero returns true if no summary level is set.
+func (l Level) IsZero() bool {
+	return l.name == ""
+}
+
+// WithLevel returns the template at summary level l: its prompt ends with the
+// rules of the level, which the map, reduce and final calls all read.
+// The variant keeps the name of the template.
+func (n Name) WithLevel(l Level) Name {
+	n.level = l
+	return n
+}
+
+// Level returns the summary level of the template (see WithLevel).
+func (n Name) Level() Level {
+	return n.level
+}
@@ -0,0 +1,69 @@
+package template_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+func TestParseLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    template.Level
+		wantErr bool
+	}{
+		{in: "short", want: template.ShortLevel},
+		{in: "medium", want: template.MediumLevel},
+		{in: "detailed", want: template.DetailedLevel},
+		{in: "", wantErr: true},
+		{in: "long", wantErr: true},
+		{in: "Short", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := template.ParseLevel(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, template.ErrInvalidLevel) {
+					t.Errorf("ParseLevel(%q) error = %v, want ErrInvalidLevel", tt.in, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseLevel(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestName_WithLevel(t *testing.T) {
+	t.Parallel()
+
+	base := template.MeetingName.Prompt()
+	if got := template.MeetingName.WithLevel(template.MediumLevel).Prompt(); got != base {
+		t.Errorf("medium Prompt() = %q, want the template as written", got)
+	}
+
+	short := template.MeetingName.WithLevel(template.ShortLevel)
+	if short.String() != template.Meeting || short.Level() != template.ShortLevel {
+		t.Errorf("WithLevel(short) = %s at level %s, want meeting at short", short, short.Level())
+	}
+	if got := short.Prompt(); !strings.HasPrefix(got, base) || !strings.Contains(got, "at most 5 lines") {
+		t.Errorf("short Prompt() = %q, want the template followed by the short rules", got)
+	}
+	if got := template.MeetingName.WithLevel(template.DetailedLevel).Prompt(); !strings.Contains(got, "detailed breakdown") {
+		t.Errorf("detailed Prompt() = %q, want the detailed rules", got)
+	}
+
+	// The level comes last, after the rules of the anchored variant
+	anchored := template.NotesName.Anchored().WithLevel(template.ShortLevel).Prompt()
+	if strings.Index(anchored, "Source anchors:") > strings.Index(anchored, "Summary level: short.") {
+		t.Errorf("anchored short Prompt() = %q, want the level rules last", anchored)
+	}
+}
@@ -15,6 +15,9 @@ const (
 	Meeting    = "meeting"
 	Lecture    = "lecture"
 	Notes      = "notes"
+	Podcast    = "podcast"
+	Chapters   = "chapters"
+	Digest     = "digest"
 )
 
 // ---------------------------------------------------------------------------
@@ -24,8 +27,16 @@ const (
 // Name represents a validated template name.
 // Zero value is invalid and must not be used with Prompt().
 // Use ParseName to create from user input, or the pre-parsed constants.
+// User templates are loaded with ParseFile, LoadDir or Resolve.
 type Name struct {
 	name string
+	// User templates (see ParseFile) carry their own prompt and description.
+	// Both are empty for built-in templates, which are looked up by name.
+	prompt      string
+	description string
+	path        string
+	anchored    bool  // Anchored variant (see Anchored)
+	level       Level // Summary level (see WithLevel)
 }
 
 // Pre-parsed template name constants for use in code.
@@ -35,6 +46,9 @@ var (
 	MeetingName    = Name{name: Meeting}
 	LectureName    = Name{name: Lecture}
 	NotesName      = Name{name: Notes}
+	PodcastName    = Name{name: Podcast}
+	ChaptersName   = Name{name: Chapters}
+	DigestName     = Name{name: Digest}
 )
 
 // ParseName validates and parses a template name string.
@@ -81,7 +95,60 @@ func (n Name) Prompt() string {
 	if n.name == "" {
 		panic("template.Name.Prompt called on zero value")
 	}
-	return templates[n.name]
+	prompt := n.basePrompt()
+	if n.anchored {
+		if !n.builtinTimed() {
+			prompt += "\n\n" + timedInput
+		}
+		prompt += "\n\n" + anchorRules
+	}
+	if rules := levelRules[n.level.name]; rules != "" {
+		prompt += "\n\n" + rules
+	}
+	return prompt
+}
+
+// Description returns a one-line description of the template.
+// Empty for zero value and for user templates without a description.
+func (n Name) Description() string {
+	if n.path != "" {
+		return n.description
+	}
+	return descriptions[n.name]
+}
+
+// Timed reports whether the template reads a timestamped transcript: one
+// "[HH:MM:SS]" line per segment, with "[pause]" marks at long silences.
+// Only the built-in podcast and chapters templates do, to place chapters.
+// Anchored variants of every template do too (see Anchored).
+func (n Name) Timed() bool {
+	return n.anchored || n.builtinTimed()
+}
+
+// builtinTimed reports whether n is a built-in template reading a timestamped
+// transcript, whose prompt describes the input format.
+func (n Name) builtinTimed() bool {
+	return (n.name == Podcast || n.name == Chapters) && n.path == ""
+}
+
+// Anchored returns the anchored variant of the template: it reads a
+// timestamped transcript (see Timed), and ends each bullet point and section
+// of the output with the audio range it comes from, like "(12:30–15:10)".
+// The variant keeps the name of the template.
+func (n Name) Anchored() Name {
+	n.anchored = true
+	return n
+}
+
+// IsAnchored reports whether n is an anchored variant (see Anchored).
+func (n Name) IsAnchored() bool {
+	return n.anchored
+}
+
+// Path returns the file a user template was loaded from.
+// Empty for built-in templates.
+func (n Name) Path() string {
+	return n.path
 }
 
 // ---------------------------------------------------------------------------
@@ -95,6 +162,9 @@ var templateOrder = []string{
 	Meeting,
 	Lecture,
 	Notes,
+	Podcast,
+	Chapters,
+	Digest,
 }
 
 // templates maps template names to their prompt strings.
@@ -104,6 +174,20 @@ var templates = map[string]string{
 	Meeting:    meetingPrompt,
 	Lecture:    lecturePrompt,
 	Notes:      notesPrompt,
+	Podcast:    podcastPrompt,
+	Chapters:   chaptersPrompt,
+	Digest:     digestPrompt,
+}
+
+// descriptions maps built-in template names to their one-line descriptions.
+var descriptions = map[string]string{
+	Brainstorm: "Ideas grouped by theme, with key ideas and actions",
+	Meeting:    "Meeting notes: topics, decisions, action items",
+	Lecture:    "Readable prose with headings, all content preserved",
+	Notes:      "Bullet points grouped by theme, all content preserved",
+	Podcast:    "Show notes: summary, timestamped chapters, quotes, guests",
+	Chapters:   "Chaptered summary: one timestamped heading per topic",
+	Digest:     "Rollup of several sessions: decisions, action items, open questions",
 }
 
 // Get returns the prompt for the given template name.
@@ -119,7 +203,7 @@ func Get(name string) (string, error) {
 }
 
 // Names returns the list of available template names.
-// The order is stable and matches the spec (brainstorm, meeting, lecture).
+// The order is stable and matches the spec (brainstorm, meeting, lecture, notes, podcast, chapters, digest).
 func Names() []string {
 	result := make([]string, len(templateOrder))
 	copy(result, templateOrder)
@@ -192,3 +276,62 @@ Rules:
 - Reorder for logical flow within each theme (not strict transcript order)
 - Do not invent content or alter meaning
 - No table of contents`
+
+const podcastPrompt = `You turn a podcast episode transcript into markdown show notes.
+
+Input format: one line per segment, starting with its timestamp [HH:MM:SS], then the speaker label if speakers were identified. A line [pause Ns] marks a silence of N seconds.
+
+Rules:
+- H1 title: episode subject
+- "Summary" section: one or two paragraphs describing the episode
+- "Guests" section: names of the host and guests, inferred from introductions and how speakers address each other; give a name to a speaker label only when the transcript supports it (if no names are mentioned, omit section)
+- "Chapters" section: one line per chapter, format "- HH:MM:SS Chapter title", first chapter at 00:00:00
+- Start a chapter where the topic changes, preferably at a long pause; use the timestamp of the first line of the chapter, copied exactly from the transcript
+- Aim for one chapter every 5 to 15 minutes
+- "Quotes" section: 3-5 memorable quotes, format "> Quote - Speaker (HH:MM:SS)", kept verbatim except for filler words
+- "Resources" section: books, tools, people and sites mentioned (if none, omit section)
+- If the transcript has no timestamps, list chapters and quotes without them
+- Do not invent names, timestamps or content
+- No table of contents`
+
+const chaptersPrompt = `You segment a recording transcript into chapters, one per topic, in markdown.
+
+Input format: one line per segment, starting with its timestamp [HH:MM:SS], then the speaker label if speakers were identified. A line [pause Ns] marks a silence of N seconds.
+
+Rules:
+- H1 title: subject of the recording
+- "Chapters" section: one line per chapter, format "- MM:SS Chapter title" (HH:MM:SS if the recording lasts an hour or more), first chapter at 00:00
+- Then one H2 per chapter, format "## MM:SS Chapter title" with the same timestamp and title, followed by a short summary of the chapter in 2-5 bullet points
+- Start a chapter where the topic changes, preferably at a long pause; use the timestamp of the first line of the chapter, copied from the transcript
+- Aim for one chapter every 3 to 10 minutes; merge short digressions into the surrounding chapter
+- Chapter titles: a few words naming the topic (e.g. "Intro", "Budget discussion")
+- If the transcript has no timestamps, list chapters without them
+- Correct obvious transcription errors
+- Do not invent topics, timestamps or content
+- No table of contents`
+
+// timedInput describes the input of anchored variants of templates whose
+// prompt does not (see Name.Anchored).
+const timedInput = `Input format: one line per segment, starting with its timestamp [HH:MM:SS], then the speaker label if speakers were identified. A line [pause Ns] marks a silence of N seconds.`
+
+// anchorRules are appended to the prompt of anchored variants (see Name.Anchored).
+const anchorRules = `Source anchors:
+- End each bullet point, and the first paragraph under each heading, with the range of the recording it comes from, format "(MM:SS–MM:SS)" (HH:MM:SS if the recording lasts an hour or more)
+- The range starts at the timestamp of the first line used and ends at the timestamp of the line following the last line used (the last line's own timestamp at the end of the transcript)
+- When a point draws on several passages, give the range covering all of them
+- Copy timestamps from the transcript; never invent or round them
+- Do not put the timestamps anywhere else, and do not list the transcript lines`
+
+const digestPrompt = `You combine the notes of several sessions (meetings, calls, lectures) into a single markdown digest.
+
+Input format: one section per session, starting with a line "=== Session: <name> (<date>) ===", followed by its notes.
+
+Rules:
+- H1 title: "Digest" followed by the period covered (first and last session dates)
+- "Sessions" section: one line per session, format "- <date> <subject>"
+- "Decisions" section: every decision made, format "- Decision (session date)"; merge decisions repeated across sessions, keep the latest when one replaces another
+- "Action Items" section: format "- [ ] Action (Owner, Deadline, session date)"; mark an action done "- [x]" only if a later session says it was completed
+- "Open Questions" section: questions, risks and topics left unresolved; drop the ones answered in a later session
+- If a section has no content, write "None"
+- Do not invent decisions, owners, deadlines or content
+- No table of contents`
@@ -10,6 +10,7 @@ package template_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/alnah/go-transcript/internal/template"
@@ -30,6 +31,8 @@ func TestGet_ValidTemplates(t *testing.T) {
 		{"meeting constant", template.Meeting},
 		{"lecture constant", template.Lecture},
 		{"notes constant", template.Notes},
+		{"chapters constant", template.Chapters},
+		{"digest constant", template.Digest},
 	}
 
 	for _, tt := range tests {
@@ -96,7 +99,7 @@ func TestNames_ReturnsCanonicalOrder(t *testing.T) {
 	t.Parallel()
 
 	got := template.Names()
-	want := []string{template.Brainstorm, template.Meeting, template.Lecture, template.Notes}
+	want := []string{template.Brainstorm, template.Meeting, template.Lecture, template.Notes, template.Podcast, template.Chapters, template.Digest}
 
 	if len(got) != len(want) {
 		t.Fatalf("Names() returned %d elements, want %d", len(got), len(want))
@@ -450,3 +453,41 @@ func TestName_PromptConsistentWithGet(t *testing.T) {
 		})
 	}
 }
+
+func TestName_Timed(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []template.Name{template.PodcastName, template.ChaptersName} {
+		if !name.Timed() {
+			t.Errorf("%q.Timed() = false, want true", name)
+		}
+	}
+	for _, name := range []template.Name{template.BrainstormName, template.MeetingName, template.LectureName, template.NotesName, {}} {
+		if name.Timed() {
+			t.Errorf("%q.Timed() = true, want false", name)
+		}
+	}
+}
+
+func TestName_Anchored(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []template.Name{template.NotesName, template.ChaptersName} {
+		anchored := name.Anchored()
+		if !anchored.IsAnchored() || !anchored.Timed() {
+			t.Errorf("%q.Anchored() = IsAnchored %v, Timed %v, want both true", name, anchored.IsAnchored(), anchored.Timed())
+		}
+		if anchored.String() != name.String() {
+			t.Errorf("%q.Anchored().String() = %q, want the template name", name, anchored.String())
+		}
+		if !strings.HasPrefix(anchored.Prompt(), name.Prompt()) || len(anchored.Prompt()) <= len(name.Prompt()) {
+			t.Errorf("%q.Anchored().Prompt() does not extend the template prompt", name)
+		}
+		if got := strings.Count(anchored.Prompt(), "Input format:"); got != 1 {
+			t.Errorf("%q.Anchored().Prompt() describes the input format %d times, want 1", name, got)
+		}
+	}
+	if template.NotesName.IsAnchored() {
+		t.Error("NotesName.IsAnchored() = true, want false")
+	}
+}
@@ -0,0 +1,114 @@
+package template
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidVar indicates a template variable is malformed, cannot be read,
+// or has no value.
+var ErrInvalidVar = errors.New("invalid template variable")
+
+// varPattern matches the placeholders of a template prompt, like
+// "{{attendees}}", capturing the variable name.
+var varPattern = regexp.MustCompile(`\{\{\s*([A-Za-z][A-Za-z0-9_-]*)\s*\}\}`)
+
+// validVarName matches template variable names.
+var validVarName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Vars returns the variables of the template: the names of the placeholders
+// of its prompt, like "{{attendees}}", in order of first appearance.
+// Built-in templates have none.
+func (n Name) Vars() []string {
+	var names []string
+	for _, m := range varPattern.FindAllStringSubmatch(n.basePrompt(), -1) {
+		if !slices.Contains(names, m[1]) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// WithVars returns the template with the placeholders of its prompt replaced
+// by their value in values. Values of variables the template does not use are
+// ignored. Returns ErrInvalidVar if a variable of the template has no value.
+func (n Name) WithVars(values map[string]string) (Name, error) {
+	vars := n.Vars()
+	if len(vars) == 0 {
+		return n, nil
+	}
+	var missing []string
+	for _, v := range vars {
+		if _, ok := values[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		return Name{}, fmt.Errorf("template %s has no value for %s: %w", n.name, strings.Join(missing, ", "), ErrInvalidVar)
+	}
+
+	n.prompt = varPattern.ReplaceAllStringFunc(n.basePrompt(), func(placeholder string) string {
+		return values[varPattern.FindStringSubmatch(placeholder)[1]]
+	})
+	return n, nil
+}
+
+// basePrompt returns the prompt of the template, without the rules of the
+// anchored variant.
+func (n Name) basePrompt() string {
+	if n.prompt != "" {
+		return n.prompt
+	}
+	return templates[n.name]
+}
+
+// ParseVar parses a "name=value" template variable (--var). The value may be
+// quoted, and empty. Returns ErrInvalidVar if s has no '=' or an invalid name.
+func ParseVar(s string) (name, value string, err error) {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return "", "", fmt.Errorf("%q: expected name=value: %w", s, ErrInvalidVar)
+	}
+	name = strings.TrimSpace(name)
+	if !validVarName.MatchString(name) {
+		return "", "", fmt.Errorf("name %q must start with a letter, followed by letters, digits, '-' or '_': %w", name, ErrInvalidVar)
+	}
+	return name, unquote(strings.TrimSpace(value)), nil
+}
+
+// ReadVars reads a file of template variables (--vars-file): one
+// "name=value" per line, like --var. Blank lines and lines starting with '#'
+// are ignored; a variable given twice keeps its last value.
+// Returns ErrInvalidVar if the file cannot be read or a line is malformed.
+func ReadVars(path string) (map[string]string, error) {
+	f, err := os.Open(path) // #nosec G304 -- vars file given by the user
+	if err != nil {
+		return nil, fmt.Errorf("cannot read template variables: %w: %v", ErrInvalidVar, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, err := ParseVar(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNum, err)
+		}
+		vars[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read template variables: %w: %v", ErrInvalidVar, err)
+	}
+	return vars, nil
+}
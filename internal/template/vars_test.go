@@ -0,0 +1,110 @@
+package template_test
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/template"
+)
+
+func TestName_WithVars(t *testing.T) {
+	t.Parallel()
+
+	path := writeTemplate(t, t.TempDir(), "standup.md",
+		"Notes of the {{project}} standup.\nAttendees: {{ attendees }}.\nProject: {{project}}. Keep {{not a var}}.")
+	tmpl, err := template.ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() unexpected error: %v", err)
+	}
+
+	if got, want := tmpl.Vars(), []string{"project", "attendees"}; !slices.Equal(got, want) {
+		t.Errorf("Vars() = %q, want %q", got, want)
+	}
+	if vars := template.MeetingName.Vars(); len(vars) != 0 {
+		t.Errorf("MeetingName.Vars() = %q, want none", vars)
+	}
+
+	got, err := tmpl.WithVars(map[string]string{"project": "Atlas", "attendees": "Alice, Bob", "unused": "x"})
+	if err != nil {
+		t.Fatalf("WithVars() unexpected error: %v", err)
+	}
+	want := "Notes of the Atlas standup.\nAttendees: Alice, Bob.\nProject: Atlas. Keep {{not a var}}."
+	if got.Prompt() != want {
+		t.Errorf("WithVars().Prompt() = %q, want %q", got.Prompt(), want)
+	}
+	if got.String() != "standup" || got.Path() != path {
+		t.Errorf("WithVars() = %s (%s), want the same template", got, got.Path())
+	}
+	if anchored := got.Anchored().Prompt(); !strings.HasPrefix(anchored, want) {
+		t.Errorf("WithVars().Anchored().Prompt() = %q, want the substituted prompt first", anchored)
+	}
+
+	_, err = tmpl.WithVars(map[string]string{"project": "Atlas"})
+	if !errors.Is(err, template.ErrInvalidVar) || !strings.Contains(err.Error(), "attendees") {
+		t.Errorf("WithVars() without attendees error = %v, want ErrInvalidVar naming attendees", err)
+	}
+
+	if meeting, err := template.MeetingName.WithVars(nil); err != nil || meeting != template.MeetingName {
+		t.Errorf("MeetingName.WithVars(nil) = %v, %v, want it unchanged", meeting, err)
+	}
+}
+
+func TestParseVar(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in        string
+		wantName  string
+		wantValue string
+		wantErr   bool
+	}{
+		{in: "attendees=Alice, Bob", wantName: "attendees", wantValue: "Alice, Bob"},
+		{in: `project = "Atlas = v2"`, wantName: "project", wantValue: "Atlas = v2"},
+		{in: "empty=", wantName: "empty"},
+		{in: "attendees", wantErr: true},
+		{in: "=value", wantErr: true},
+		{in: "1st=value", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			name, value, err := template.ParseVar(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, template.ErrInvalidVar) {
+					t.Errorf("ParseVar(%q) error = %v, want ErrInvalidVar", tt.in, err)
+				}
+				return
+			}
+			if err != nil || name != tt.wantName || value != tt.wantValue {
+				t.Errorf("ParseVar(%q) = %q, %q, %v, want %q, %q", tt.in, name, value, err, tt.wantName, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestReadVars(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeTemplate(t, dir, "vars.txt", "# Team Atlas\nproject=Atlas\n\nattendees = Alice, Bob\nproject=Atlas v2\n")
+	got, err := template.ReadVars(path)
+	if err != nil {
+		t.Fatalf("ReadVars() unexpected error: %v", err)
+	}
+	if len(got) != 2 || got["project"] != "Atlas v2" || got["attendees"] != "Alice, Bob" {
+		t.Errorf("ReadVars() = %v, want project and attendees, last value kept", got)
+	}
+
+	bad := writeTemplate(t, dir, "bad.txt", "project=Atlas\nattendees\n")
+	if _, err := template.ReadVars(bad); !errors.Is(err, template.ErrInvalidVar) || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("ReadVars(malformed) error = %v, want ErrInvalidVar at line 2", err)
+	}
+	if _, err := template.ReadVars(filepath.Join(dir, "missing.txt")); !errors.Is(err, template.ErrInvalidVar) {
+		t.Errorf("ReadVars(missing) error = %v, want ErrInvalidVar", err)
+	}
+}
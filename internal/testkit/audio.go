@@ -0,0 +1,100 @@
+package testkit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// SampleRate is the sample rate of synthetic audio, mono 16-bit PCM.
+const SampleRate = 16000
+
+// toneAmplitude is the peak of tones, half of full scale: well above the
+// silence threshold of chunking, without clipping.
+const toneAmplitude = 0.5
+
+// Segment is a part of synthetic audio: a sine tone, or silence when
+// Frequency is zero.
+type Segment struct {
+	Duration  time.Duration
+	Frequency float64 // Hz
+}
+
+// Tone returns a segment of a sine tone at frequency hz.
+func Tone(d time.Duration, hz float64) Segment {
+	return Segment{Duration: d, Frequency: hz}
+}
+
+// Silence returns a segment of silence.
+func Silence(d time.Duration) Segment {
+	return Segment{Duration: d}
+}
+
+// Speech returns n utterances of length speak separated by pauses of length
+// pause: the pattern silence-based chunking cuts at. Utterances alternate
+// between two pitches, like the voices of a conversation.
+func Speech(n int, speak, pause time.Duration) []Segment {
+	segments := make([]Segment, 0, 2*n)
+	for i := range n {
+		if i > 0 {
+			segments = append(segments, Silence(pause))
+		}
+		hz := 220.0
+		if i%2 == 1 {
+			hz = 330.0
+		}
+		segments = append(segments, Tone(speak, hz))
+	}
+	return segments
+}
+
+// WAV returns the segments played in order, as a WAV file.
+func WAV(segments ...Segment) []byte {
+	var samples []int16
+	for _, s := range segments {
+		n := int(s.Duration.Seconds() * SampleRate)
+		for i := range n {
+			v := 0.0
+			if s.Frequency > 0 {
+				v = toneAmplitude * math.Sin(2*math.Pi*s.Frequency*float64(i)/SampleRate)
+			}
+			samples = append(samples, int16(v*math.MaxInt16))
+		}
+	}
+
+	const (
+		channels      = 1
+		bitsPerSample = 16
+		blockAlign    = channels * bitsPerSample / 8
+	)
+	dataSize := uint32(len(samples) * blockAlign) // #nosec G115 -- test audio lasts minutes at most
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVEfmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, struct {
+		Size          uint32
+		Format        uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+	}{16, 1, channels, SampleRate, SampleRate * blockAlign, blockAlign, bitsPerSample})
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, dataSize)
+	_ = binary.Write(&buf, binary.LittleEndian, samples)
+	return buf.Bytes()
+}
+
+// WriteWAV writes the segments played in order to path, as a WAV file.
+func WriteWAV(path string, segments ...Segment) error {
+	if err := os.WriteFile(path, WAV(segments...), 0600); err != nil {
+		return fmt.Errorf("write synthetic audio: %w", err)
+	}
+	return nil
+}
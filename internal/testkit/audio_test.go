@@ -0,0 +1,82 @@
+package testkit_test
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/testkit"
+)
+
+func TestWAV(t *testing.T) {
+	t.Parallel()
+
+	data := testkit.WAV(testkit.Tone(time.Second, 440), testkit.Silence(500*time.Millisecond))
+
+	if string(data[0:4]) != "RIFF" || string(data[8:16]) != "WAVEfmt " || string(data[36:40]) != "data" {
+		t.Fatalf("WAV() header = %q, want a RIFF WAVE header", data[:44])
+	}
+	if rate := binary.LittleEndian.Uint32(data[24:28]); rate != testkit.SampleRate {
+		t.Errorf("sample rate = %d, want %d", rate, testkit.SampleRate)
+	}
+	wantSamples := testkit.SampleRate * 3 / 2
+	if size := binary.LittleEndian.Uint32(data[40:44]); int(size) != 2*wantSamples || len(data) != 44+2*wantSamples {
+		t.Errorf("data size = %d (file %d bytes), want %d samples", size, len(data), wantSamples)
+	}
+
+	sample := func(i int) int16 { return int16(binary.LittleEndian.Uint16(data[44+2*i:])) }
+	var peak int16
+	for i := range testkit.SampleRate {
+		peak = max(peak, sample(i))
+	}
+	if peak < 16000 {
+		t.Errorf("tone peak = %d, want half of full scale", peak)
+	}
+	for i := testkit.SampleRate; i < wantSamples; i++ {
+		if sample(i) != 0 {
+			t.Fatalf("sample %d = %d, want silence", i, sample(i))
+		}
+	}
+}
+
+func TestSpeech(t *testing.T) {
+	t.Parallel()
+
+	segments := testkit.Speech(3, 4*time.Second, time.Second)
+
+	want := []testkit.Segment{
+		testkit.Tone(4*time.Second, 220), testkit.Silence(time.Second),
+		testkit.Tone(4*time.Second, 330), testkit.Silence(time.Second),
+		testkit.Tone(4*time.Second, 220),
+	}
+	if len(segments) != len(want) {
+		t.Fatalf("Speech() = %+v, want %+v", segments, want)
+	}
+	for i := range want {
+		if segments[i] != want[i] {
+			t.Errorf("segment %d = %+v, want %+v", i, segments[i], want[i])
+		}
+	}
+}
+
+func TestWriteWAV(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "speech.wav")
+	if err := testkit.WriteWAV(path, testkit.Tone(time.Second, 440)); err != nil {
+		t.Fatalf("WriteWAV() unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(data) != 44+2*testkit.SampleRate {
+		t.Errorf("file = %d bytes, want one second of audio", len(data))
+	}
+
+	if err := testkit.WriteWAV(filepath.Join(t.TempDir(), "missing", "x.wav")); err == nil {
+		t.Error("WriteWAV() into a missing directory: expected error, got nil")
+	}
+}
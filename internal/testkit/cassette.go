@@ -0,0 +1,268 @@
+// Package testkit provides helpers for end-to-end tests: HTTP cassettes
+// replaying recorded exchanges with the transcription and restructuring APIs,
+// and synthetic audio.
+//
+// Cassettes let the end-to-end suite run the real pipeline without API keys.
+// With RecordEnv set, the same tests call the real APIs and record the
+// cassettes again.
+package testkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// RecordEnv is the environment variable that makes cassette servers forward
+// requests to the real APIs and record the exchanges, instead of replaying
+// them. The API keys must then be set.
+const RecordEnv = "TRANSCRIPT_RECORD"
+
+// Cassette is a recorded sequence of HTTP exchanges.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request and the response it got.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request identifies a recorded request. Bodies are not compared in full:
+// multipart bodies hold random boundaries and prompts change between versions.
+// A request matches a recording with the same method and path, and the same
+// model and uploaded file name when the recording has them.
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Model  string `json:"model,omitempty"` // "model" field of the JSON or multipart body
+	File   string `json:"file,omitempty"`  // Name of the uploaded file of a multipart body
+}
+
+// Response is a recorded response.
+type Response struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// LoadCassette reads the cassette at path.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is a test fixture
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parse cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path, creating its directory.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	return nil
+}
+
+// Recording reports whether cassettes are recorded rather than replayed
+// (see RecordEnv).
+func Recording() bool {
+	return os.Getenv(RecordEnv) != ""
+}
+
+// Server is an HTTP server standing in for an API: it replays the
+// interactions of a cassette, or records them (see RecordEnv).
+type Server struct {
+	// URL is the base URL of the server, to use instead of the API's.
+	URL string
+
+	t        testing.TB
+	path     string
+	upstream string
+	client   *http.Client
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewServer starts a server replaying the cassette at path for the duration
+// of the test. When recording, it forwards requests to upstream, the base URL
+// of the real API, and writes the cassette at path once the test ends.
+// Credentials are forwarded but never recorded.
+func NewServer(t testing.TB, path, upstream string) *Server {
+	t.Helper()
+
+	s := &Server{t: t, path: path, upstream: strings.TrimSuffix(upstream, "/")}
+	if Recording() {
+		s.cassette = &Cassette{}
+		s.client = &http.Client{}
+		// Cleanups run last-in first-out: the server is closed, waiting for
+		// requests in flight, before the cassette is saved. Skipped and failed
+		// tests keep the cassette they had.
+		t.Cleanup(func() {
+			if t.Skipped() || t.Failed() {
+				return
+			}
+			if err := s.cassette.Save(path); err != nil {
+				t.Errorf("testkit: %v", err)
+			}
+		})
+	} else {
+		c, err := LoadCassette(path)
+		if err != nil {
+			t.Fatalf("testkit: %v (record it with %s=1)", err, RecordEnv)
+		}
+		s.cassette = c
+		s.used = make([]bool, len(c.Interactions))
+		t.Cleanup(s.checkUsed)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(srv.Close)
+	s.URL = srv.URL
+	return s
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := identify(r, body)
+
+	var resp Response
+	if s.client != nil {
+		resp, err = s.forward(r, body)
+		if err != nil {
+			s.t.Errorf("testkit: forward %s %s: %v", req.Method, req.Path, err)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		s.mu.Lock()
+		s.cassette.Interactions = append(s.cassette.Interactions, Interaction{Request: req, Response: resp})
+		s.mu.Unlock()
+	} else {
+		var ok bool
+		resp, ok = s.replay(req)
+		if !ok {
+			// Not retryable, so that the client fails at once.
+			s.t.Errorf("testkit: %s has no interaction left for %+v (record it again with %s=1)", s.path, req, RecordEnv)
+			http.Error(w, "no recorded interaction", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if resp.ContentType != "" {
+		w.Header().Set("Content-Type", resp.ContentType)
+	}
+	w.WriteHeader(resp.Status)
+	_, _ = io.WriteString(w, resp.Body)
+}
+
+// replay returns the response of the first unused interaction matching req.
+func (s *Server) replay(req Request) (Response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, in := range s.cassette.Interactions {
+		if !s.used[i] && matches(in.Request, req) {
+			s.used[i] = true
+			return in.Response, true
+		}
+	}
+	return Response{}, false
+}
+
+// forward sends the request to the real API and returns its response.
+func (s *Server) forward(r *http.Request, body []byte) (Response, error) {
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, s.upstream+r.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		return Response{}, err
+	}
+	req.Header = r.Header.Clone()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Response{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{Status: resp.StatusCode, ContentType: resp.Header.Get("Content-Type"), Body: string(data)}, nil
+}
+
+// checkUsed reports the interactions the test did not replay: the flow no
+// longer makes the requests recorded, and the cassette must be recorded again.
+func (s *Server) checkUsed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, used := range s.used {
+		if !used {
+			s.t.Errorf("testkit: %s: interaction %d (%+v) was not replayed (record it again with %s=1)",
+				s.path, i, s.cassette.Interactions[i].Request, RecordEnv)
+		}
+	}
+}
+
+// matches reports whether got matches the recorded request.
+func matches(recorded, got Request) bool {
+	return recorded.Method == got.Method &&
+		recorded.Path == got.Path &&
+		(recorded.Model == "" || recorded.Model == got.Model) &&
+		(recorded.File == "" || recorded.File == got.File)
+}
+
+// identify returns the fields of r compared to recordings.
+func identify(r *http.Request, body []byte) Request {
+	req := Request{Method: r.Method, Path: r.URL.Path}
+
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/json":
+		var fields struct {
+			Model string `json:"model"`
+		}
+		if json.Unmarshal(body, &fields) == nil {
+			req.Model = fields.Model
+		}
+	case strings.HasPrefix(mediaType, "multipart/"):
+		mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			switch part.FormName() {
+			case "file":
+				req.File = part.FileName()
+			case "model":
+				value, _ := io.ReadAll(part)
+				req.Model = string(value)
+			}
+		}
+	}
+	return req
+}
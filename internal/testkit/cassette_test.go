@@ -0,0 +1,187 @@
+package testkit_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/testkit"
+)
+
+// recordingTB records the errors reported by a cassette server.
+type recordingTB struct {
+	testing.TB
+	mu     sync.Mutex
+	errors []string
+}
+
+func (tb *recordingTB) Errorf(format string, args ...any) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func (tb *recordingTB) Errors() []string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	return tb.errors
+}
+
+func writeCassette(t *testing.T, c testkit.Cassette) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := c.Save(path); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	return path
+}
+
+// postUpload posts a multipart upload of file with model, like a transcription request.
+func postUpload(t *testing.T, url, model, file string) (int, string) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile("file", file)
+	_, _ = part.Write([]byte("audio"))
+	_ = mw.WriteField("model", model)
+	_ = mw.Close()
+	return post(t, url+"/v1/audio/transcriptions", mw.FormDataContentType(), &body)
+}
+
+func post(t *testing.T, url, contentType string, body io.Reader) (int, string) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, url, body)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer secret-key")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+func TestServer_Replay(t *testing.T) {
+	t.Parallel()
+
+	path := writeCassette(t, testkit.Cassette{Interactions: []testkit.Interaction{
+		{
+			Request:  testkit.Request{Method: "POST", Path: "/v1/audio/transcriptions", Model: "whisper-1", File: "chunk_001.ogg"},
+			Response: testkit.Response{Status: 200, ContentType: "application/json", Body: `{"text":"second"}`},
+		},
+		{
+			Request:  testkit.Request{Method: "POST", Path: "/v1/audio/transcriptions", Model: "whisper-1", File: "chunk_000.ogg"},
+			Response: testkit.Response{Status: 200, ContentType: "application/json", Body: `{"text":"first"}`},
+		},
+		{
+			Request:  testkit.Request{Method: "POST", Path: "/chat/completions", Model: "deepseek-chat"},
+			Response: testkit.Response{Status: 429, Body: "slow down"},
+		},
+		{
+			Request:  testkit.Request{Method: "POST", Path: "/chat/completions", Model: "deepseek-chat"},
+			Response: testkit.Response{Status: 200, Body: "done"},
+		},
+	}})
+
+	srv := testkit.NewServer(t, path, "")
+
+	// Uploads match on their file name, whatever their order.
+	if status, body := postUpload(t, srv.URL, "whisper-1", "chunk_000.ogg"); status != 200 || body != `{"text":"first"}` {
+		t.Errorf("chunk_000 = %d %q, want 200 first", status, body)
+	}
+	if status, body := postUpload(t, srv.URL, "whisper-1", "chunk_001.ogg"); status != 200 || body != `{"text":"second"}` {
+		t.Errorf("chunk_001 = %d %q, want 200 second", status, body)
+	}
+
+	// Identical requests replay in the recorded order.
+	for _, want := range []struct {
+		status int
+		body   string
+	}{{429, "slow down"}, {200, "done"}} {
+		status, body := post(t, srv.URL+"/chat/completions", "application/json", strings.NewReader(`{"model":"deepseek-chat"}`))
+		if status != want.status || body != want.body {
+			t.Errorf("chat = %d %q, want %d %q", status, body, want.status, want.body)
+		}
+	}
+}
+
+func TestServer_ReplayMismatch(t *testing.T) {
+	t.Parallel()
+
+	path := writeCassette(t, testkit.Cassette{Interactions: []testkit.Interaction{{
+		Request:  testkit.Request{Method: "POST", Path: "/chat/completions", Model: "deepseek-chat"},
+		Response: testkit.Response{Status: 200, Body: "done"},
+	}}})
+
+	tb := &recordingTB{TB: t}
+	t.Run("server", func(t *testing.T) {
+		tb.TB = t
+		srv := testkit.NewServer(tb, path, "")
+		status, _ := post(t, srv.URL+"/chat/completions", "application/json", strings.NewReader(`{"model":"gpt-4o"}`))
+		if status != http.StatusBadRequest {
+			t.Errorf("unmatched request status = %d, want %d", status, http.StatusBadRequest)
+		}
+	})
+
+	errs := tb.Errors()
+	if len(errs) != 2 {
+		t.Fatalf("errors = %q, want the unmatched request and the unused interaction", errs)
+	}
+	if !strings.Contains(errs[0], "no interaction left") || !strings.Contains(errs[0], "gpt-4o") {
+		t.Errorf("errors[0] = %q, want the unmatched request", errs[0])
+	}
+	if !strings.Contains(errs[1], "was not replayed") {
+		t.Errorf("errors[1] = %q, want the unused interaction", errs[1])
+	}
+}
+
+func TestServer_Record(t *testing.T) {
+	t.Setenv(testkit.RecordEnv, "1")
+
+	var auth string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"text":"hello"}`)
+	}))
+	defer upstream.Close()
+
+	path := filepath.Join(t.TempDir(), "cassettes", "record.json")
+	t.Run("record", func(t *testing.T) {
+		srv := testkit.NewServer(t, path, upstream.URL)
+		if status, body := postUpload(t, srv.URL, "whisper-1", "chunk_000.ogg"); status != 200 || body != `{"text":"hello"}` {
+			t.Errorf("upload = %d %q, want the upstream response", status, body)
+		}
+	})
+
+	if auth != "Bearer secret-key" {
+		t.Errorf("upstream Authorization = %q, want the forwarded key", auth)
+	}
+	c, err := testkit.LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette() unexpected error: %v", err)
+	}
+	want := testkit.Interaction{
+		Request:  testkit.Request{Method: "POST", Path: "/v1/audio/transcriptions", Model: "whisper-1", File: "chunk_000.ogg"},
+		Response: testkit.Response{Status: 200, ContentType: "application/json", Body: `{"text":"hello"}`},
+	}
+	if len(c.Interactions) != 1 || c.Interactions[0] != want {
+		t.Errorf("recorded = %+v, want %+v", c.Interactions, want)
+	}
+}
+
+func TestLoadCassette_Missing(t *testing.T) {
+	t.Parallel()
+
+	if _, err := testkit.LoadCassette(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadCassette() of a missing file: expected error, got nil")
+	}
+}
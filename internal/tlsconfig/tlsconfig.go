@@ -0,0 +1,113 @@
+// Package tlsconfig applies custom TLS settings to outbound HTTPS connections.
+//
+// Networks intercepting TLS re-sign traffic with a corporate certificate
+// authority, which Go does not trust unless it is installed system-wide: a CA
+// bundle adds it to the trusted roots. Proxies requiring mutual TLS get a client
+// certificate. Either way, certificate verification failures are reported with
+// ErrUntrusted instead of an opaque x509 message.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Sentinel errors for error handling with errors.Is().
+var (
+	// ErrInvalid indicates a CA bundle or client certificate that cannot be used.
+	ErrInvalid = errors.New("invalid TLS configuration")
+	// ErrUntrusted indicates a server certificate that failed verification,
+	// typically re-signed by a TLS-intercepting proxy.
+	ErrUntrusted = errors.New("server certificate not trusted")
+)
+
+// Options are the TLS settings of outbound connections. Paths point to PEM files.
+type Options struct {
+	CABundle   string // Extra certificate authorities, trusted with the system ones.
+	ClientCert string // Client certificate, for proxies requiring mutual TLS.
+	ClientKey  string // Private key of ClientCert.
+}
+
+// IsZero reports whether no setting is configured.
+func (o Options) IsZero() bool {
+	return o == Options{}
+}
+
+// Config builds the TLS configuration of o. Returns nil if o is zero, meaning
+// the system defaults. Returns ErrInvalid if a file cannot be used.
+func (o Options) Config() (*tls.Config, error) {
+	if o.IsZero() {
+		return nil, nil
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if o.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool() // No system pool (e.g. some Windows setups).
+		}
+		pem, err := os.ReadFile(o.CABundle) // #nosec G304 -- user-configured CA bundle
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot read CA bundle: %w", ErrInvalid, err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: no PEM certificate in CA bundle %s", ErrInvalid, o.CABundle)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if (o.ClientCert == "") != (o.ClientKey == "") {
+		return nil, fmt.Errorf("%w: client certificate and key must be set together", ErrInvalid)
+	}
+	if o.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot load client certificate: %w", ErrInvalid, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// baseTransport is the default transport of net/http, captured before Install
+// replaces it, so that Install can be called again.
+var baseTransport = http.DefaultTransport.(*http.Transport).Clone()
+
+// Install makes http.DefaultTransport, used by every HTTP client without a
+// transport of its own, connect with cfg (nil: the system defaults).
+// It must be called before any request is sent.
+func Install(cfg *tls.Config) {
+	http.DefaultTransport = Transport(cfg)
+}
+
+// Transport returns a clone of the default net/http transport connecting with
+// cfg (nil: the system defaults), reporting untrusted certificates with ErrUntrusted.
+func Transport(cfg *tls.Config) http.RoundTripper {
+	t := baseTransport.Clone()
+	t.TLSClientConfig = cfg
+	return Wrap(t)
+}
+
+// Wrap returns rt reporting untrusted certificates with ErrUntrusted, for
+// transports that cannot be built by Transport.
+func Wrap(rt http.RoundTripper) http.RoundTripper {
+	return untrustedTransport{next: rt}
+}
+
+// untrustedTransport wraps certificate verification failures with ErrUntrusted.
+type untrustedTransport struct {
+	next http.RoundTripper
+}
+
+func (t untrustedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	var verifyErr *tls.CertificateVerificationError
+	if errors.As(err, &verifyErr) {
+		return nil, fmt.Errorf("%w: %w", ErrUntrusted, err)
+	}
+	return resp, err
+}
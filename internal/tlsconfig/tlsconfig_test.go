@@ -0,0 +1,167 @@
+package tlsconfig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/tlsconfig"
+)
+
+// writePEM writes a PEM block in dir and returns its path.
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+// clientCertFiles generates a self-signed client certificate and returns the
+// paths of its certificate and key.
+func clientCertFiles(t *testing.T, dir string) (certPath, keyPath string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "transcript-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return writePEM(t, dir, "client.pem", "CERTIFICATE", der), writePEM(t, dir, "client-key.pem", "EC PRIVATE KEY", keyDER)
+}
+
+// get sends a GET request to url through the transport of cfg.
+func get(cfg *tls.Config, url string) error {
+	client := &http.Client{Transport: tlsconfig.Transport(cfg), Timeout: 5 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// ---------------------------------------------------------------------------
+// TestConfig - Building the TLS configuration
+// ---------------------------------------------------------------------------
+
+func TestConfig_Zero(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := tlsconfig.Options{}.Config()
+	if cfg != nil || err != nil {
+		t.Errorf("Config() = %v, %v, want nil, nil", cfg, err)
+	}
+}
+
+func TestConfig_Invalid(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certPath, keyPath := clientCertFiles(t, dir)
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts tlsconfig.Options
+	}{
+		{"missing CA bundle", tlsconfig.Options{CABundle: filepath.Join(dir, "missing.pem")}},
+		{"CA bundle without certificate", tlsconfig.Options{CABundle: notPEM}},
+		{"certificate without key", tlsconfig.Options{ClientCert: certPath}},
+		{"key without certificate", tlsconfig.Options{ClientKey: keyPath}},
+		{"mismatched pair", tlsconfig.Options{ClientCert: certPath, ClientKey: notPEM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := tt.opts.Config(); !errors.Is(err, tlsconfig.ErrInvalid) {
+				t.Errorf("Config() error = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestTransport - Connections with custom CAs and client certificates
+// ---------------------------------------------------------------------------
+
+func TestTransport_UntrustedServer(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	if err := get(nil, srv.URL); !errors.Is(err, tlsconfig.ErrUntrusted) {
+		t.Errorf("GET without CA bundle error = %v, want ErrUntrusted", err)
+	}
+}
+
+func TestTransport_CABundle(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	bundle := writePEM(t, t.TempDir(), "ca.pem", "CERTIFICATE", srv.Certificate().Raw)
+	cfg, err := tlsconfig.Options{CABundle: bundle}.Config()
+	if err != nil {
+		t.Fatalf("Config() unexpected error: %v", err)
+	}
+	if err := get(cfg, srv.URL); err != nil {
+		t.Errorf("GET with CA bundle unexpected error: %v", err)
+	}
+}
+
+func TestTransport_ClientCertificate(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	dir := t.TempDir()
+	certPath, keyPath := clientCertFiles(t, dir)
+	cfg, err := tlsconfig.Options{
+		CABundle:   writePEM(t, dir, "ca.pem", "CERTIFICATE", srv.Certificate().Raw),
+		ClientCert: certPath,
+		ClientKey:  keyPath,
+	}.Config()
+	if err != nil {
+		t.Fatalf("Config() unexpected error: %v", err)
+	}
+	if err := get(cfg, srv.URL); err != nil {
+		t.Errorf("GET with client certificate unexpected error: %v", err)
+	}
+}
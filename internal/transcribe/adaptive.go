@@ -0,0 +1,199 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// uploadBudget is the time each upload may take when sharing the connection
+// with the other parallel uploads. It is well below the HTTP timeout (5 min)
+// so that slow connections degrade to fewer parallel requests instead of
+// every parallel request timing out at once.
+const uploadBudget = 60 * time.Second
+
+// UploadStats describes a completed upload.
+type UploadStats struct {
+	Bytes    int64
+	Duration time.Duration
+}
+
+// BytesPerSecond returns the measured upload throughput.
+// Returns 0 if the duration is unknown.
+func (s UploadStats) BytesPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / s.Duration.Seconds()
+}
+
+// uploadMeter is implemented by transcribers that measure their uploads.
+// OpenAITranscriber, DeepgramTranscriber and AssemblyAITranscriber implement it.
+type uploadMeter interface {
+	LastUpload() (UploadStats, bool)
+}
+
+// AutoParallel returns how many chunks of chunkSize bytes can be uploaded
+// concurrently over a connection with the given throughput (bytes/s), so that
+// each upload completes within uploadBudget while sharing the bandwidth.
+// The result is within [1, MaxRecommendedParallel].
+func AutoParallel(bytesPerSecond float64, chunkSize int64) int {
+	if chunkSize <= 0 || bytesPerSecond <= 0 {
+		return MaxRecommendedParallel
+	}
+	n := int(bytesPerSecond * uploadBudget.Seconds() / float64(chunkSize))
+	return max(1, min(n, MaxRecommendedParallel))
+}
+
+// assumedUploadRate is the upload throughput (bytes/s) DefaultParallel sizes
+// parallelism for: a modest connection. --parallel auto measures it instead.
+const assumedUploadRate = 1 << 20
+
+// expectedRequestTime is how long DefaultParallel expects the request of a
+// chunk to take, upload and processing included.
+const expectedRequestTime = 20 * time.Second
+
+// ParallelLimit is what bounds the parallelism chosen by DefaultParallel.
+type ParallelLimit int
+
+const (
+	LimitMaxParallel ParallelLimit = iota // MaxRecommendedParallel
+	LimitChunks                           // One request per chunk
+	LimitRequestRate                      // Requests per minute of the rate limit
+	LimitAudioRate                        // Audio per minute of the rate limit
+	LimitChunkSize                        // Uploads of the largest chunk sharing the connection
+)
+
+// DefaultParallel returns the parallelism to use when none is requested, and
+// what limits it: no more requests than chunks, than the rate limits start
+// while a request runs (zero: unlimited, see NewRateLimiter), or than uploads
+// of the largest chunk a modest connection carries within uploadBudget.
+// Chunks may be nil when unknown yet (streaming): only the rates then apply.
+// The result is within [1, MaxRecommendedParallel].
+func DefaultParallel(chunks []audio.Chunk, requestsPerMinute int, audioPerMinute time.Duration) (int, ParallelLimit) {
+	n, limit := MaxRecommendedParallel, LimitMaxParallel
+	lower := func(m int, l ParallelLimit) {
+		if m = max(1, m); m < n {
+			n, limit = m, l
+		}
+	}
+
+	if len(chunks) > 0 {
+		lower(len(chunks), LimitChunks)
+	}
+	// More requests than the limiter lets start while one runs would only wait.
+	if requestsPerMinute > 0 {
+		lower(int(float64(requestsPerMinute)*expectedRequestTime.Minutes()), LimitRequestRate)
+	}
+	if audioPerMinute > 0 && len(chunks) > 0 {
+		var total time.Duration
+		for _, c := range chunks {
+			total += c.Duration()
+		}
+		if average := total / time.Duration(len(chunks)); average > 0 {
+			lower(int(audioPerMinute.Seconds()*expectedRequestTime.Minutes()/average.Seconds()), LimitAudioRate)
+		}
+	}
+	if size := largestChunkSize(chunks); size > 0 {
+		lower(AutoParallel(assumedUploadRate, size), LimitChunkSize)
+	}
+	return n, limit
+}
+
+// TranscribeRemainingAuto is like TranscribeRemaining, but chooses the parallelism
+// from the measured upload throughput: the first pending chunk is transcribed
+// alone, then the remaining chunks run with AutoParallel concurrency.
+// onParallel, if non-nil, is called with the chosen parallelism and throughput.
+//
+// Throughput is measured by the transcriber when it supports it (OpenAITranscriber).
+// Otherwise it is estimated from the duration of the whole first request, which
+// includes processing time and therefore underestimates the connection: a safe
+// default that only costs some parallelism. If the probe fails in partial
+// failure mode (see WithAllowPartial), chunks are transcribed one at a time.
+// run applies to every chunk, the probe included.
+func TranscribeRemainingAuto(
+	ctx context.Context,
+	chunks []audio.Chunk,
+	t Transcriber,
+	opts Options,
+	done map[int]string,
+	onChunk func(index int, text string),
+	onParallel func(parallel int, bytesPerSecond float64),
+	run ...RunOption,
+) ([]string, error) {
+	// First pending chunk: the probe.
+	probe := -1
+	for i, chunk := range chunks {
+		if _, ok := done[chunk.Index]; !ok {
+			probe = i
+			break
+		}
+	}
+	if probe < 0 {
+		return TranscribeRemaining(ctx, chunks, t, opts, 1, done, onChunk, run...)
+	}
+
+	// Copy done: it must not alias a map onChunk updates (see TranscribeRemaining).
+	known := maps.Clone(done)
+	if known == nil {
+		known = make(map[int]string)
+	}
+
+	start := time.Now()
+	probeResults, err := TranscribeRemaining(ctx, chunks[probe:probe+1], t, opts, 1, nil, onChunk, run...)
+	var partial *PartialError
+	if errors.As(err, &partial) {
+		// A failed probe measures nothing: go on one chunk at a time, and
+		// let the probe be retried with the other chunks.
+		if onParallel != nil {
+			onParallel(1, 0)
+		}
+		return TranscribeRemaining(ctx, chunks, t, opts, 1, known, onChunk, run...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	known[chunks[probe].Index] = probeResults[0]
+	elapsed := time.Since(start)
+
+	var throughput float64
+	if m, ok := t.(uploadMeter); ok {
+		if stats, ok := m.LastUpload(); ok {
+			throughput = stats.BytesPerSecond()
+		}
+	}
+	if throughput == 0 {
+		if size := fileSize(chunks[probe].Path); size > 0 && elapsed > 0 {
+			throughput = float64(size) / elapsed.Seconds()
+		}
+	}
+
+	parallel := AutoParallel(throughput, largestChunkSize(chunks))
+	if onParallel != nil {
+		onParallel(parallel, throughput)
+	}
+
+	return TranscribeRemaining(ctx, chunks, t, opts, parallel, known, onChunk, run...)
+}
+
+// largestChunkSize returns the size of the largest chunk file, or 0 if none can be read.
+func largestChunkSize(chunks []audio.Chunk) int64 {
+	var largest int64
+	for _, c := range chunks {
+		largest = max(largest, fileSize(c.Path))
+	}
+	return largest
+}
+
+// fileSize returns the size of the file at path, or 0 if it cannot be read.
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,482 @@
+package transcribe_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// ---------------------------------------------------------------------------
+// Test helpers
+// ---------------------------------------------------------------------------
+
+// meteredTranscriber reports a fixed upload throughput and tracks the
+// maximum number of concurrent Transcribe calls.
+type meteredTranscriber struct {
+	stats transcribe.UploadStats
+
+	mu            sync.Mutex
+	calls         []string
+	active        int
+	maxConcurrent int
+}
+
+func (m *meteredTranscriber) Transcribe(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, audioPath)
+	m.active++
+	m.maxConcurrent = max(m.maxConcurrent, m.active)
+	m.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	m.mu.Lock()
+	m.active--
+	m.mu.Unlock()
+	return "text " + filepath.Base(audioPath), nil
+}
+
+func (m *meteredTranscriber) LastUpload() (transcribe.UploadStats, bool) {
+	return m.stats, m.stats.Bytes > 0
+}
+
+// createSizedChunks creates n chunk files of size bytes each.
+func createSizedChunks(t *testing.T, n int, size int) []audio.Chunk {
+	t.Helper()
+	dir := t.TempDir()
+	chunks := make([]audio.Chunk, n)
+	for i := range chunks {
+		path := filepath.Join(dir, "chunk"+string(rune('0'+i))+".ogg")
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatalf("failed to create chunk: %v", err)
+		}
+		chunks[i] = audio.Chunk{Path: path, Index: i}
+	}
+	return chunks
+}
+
+// ---------------------------------------------------------------------------
+// Tests for AutoParallel
+// ---------------------------------------------------------------------------
+
+func TestAutoParallel(t *testing.T) {
+	t.Parallel()
+
+	const mb = 1 << 20
+
+	tests := []struct {
+		name           string
+		bytesPerSecond float64
+		chunkSize      int64
+		want           int
+	}{
+		{"fast connection is capped", 100 * mb, 10 * mb, transcribe.MaxRecommendedParallel},
+		{"fits budget exactly", 1 * mb, 20 * mb, 3},
+		{"slow connection", 256 << 10, 10 * mb, 1},
+		{"very slow connection never below one", 1024, 20 * mb, 1},
+		{"unknown throughput", 0, 10 * mb, transcribe.MaxRecommendedParallel},
+		{"unknown chunk size", 1 * mb, 0, transcribe.MaxRecommendedParallel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := transcribe.AutoParallel(tt.bytesPerSecond, tt.chunkSize); got != tt.want {
+				t.Errorf("AutoParallel(%v, %d) = %d, want %d", tt.bytesPerSecond, tt.chunkSize, got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests for DefaultParallel
+// ---------------------------------------------------------------------------
+
+func TestDefaultParallel(t *testing.T) {
+	t.Parallel()
+
+	// withDurations gives each chunk the same duration.
+	withDurations := func(chunks []audio.Chunk, d time.Duration) []audio.Chunk {
+		for i := range chunks {
+			chunks[i].StartTime = time.Duration(i) * d
+			chunks[i].EndTime = time.Duration(i+1) * d
+		}
+		return chunks
+	}
+
+	tests := []struct {
+		name      string
+		chunks    []audio.Chunk
+		requests  int
+		audio     time.Duration
+		want      int
+		wantLimit transcribe.ParallelLimit
+	}{
+		{
+			name:      "many small chunks",
+			chunks:    createSizedChunks(t, 12, 1024),
+			want:      transcribe.MaxRecommendedParallel,
+			wantLimit: transcribe.LimitMaxParallel,
+		},
+		{
+			name:      "one request per chunk",
+			chunks:    createSizedChunks(t, 3, 1024),
+			want:      3,
+			wantLimit: transcribe.LimitChunks,
+		},
+		{
+			name:      "request rate",
+			chunks:    createSizedChunks(t, 12, 1024),
+			requests:  12,
+			want:      4,
+			wantLimit: transcribe.LimitRequestRate,
+		},
+		{
+			name:      "audio rate",
+			chunks:    withDurations(createSizedChunks(t, 12, 1024), 5*time.Minute),
+			audio:     30 * time.Minute,
+			want:      2,
+			wantLimit: transcribe.LimitAudioRate,
+		},
+		{
+			name:      "large chunks",
+			chunks:    createSizedChunks(t, 12, 20<<20),
+			want:      3,
+			wantLimit: transcribe.LimitChunkSize,
+		},
+		{
+			name:      "very low rate never below one",
+			chunks:    createSizedChunks(t, 12, 1024),
+			requests:  1,
+			want:      1,
+			wantLimit: transcribe.LimitRequestRate,
+		},
+		{
+			name:      "chunks unknown",
+			requests:  15,
+			want:      5,
+			wantLimit: transcribe.LimitRequestRate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, limit := transcribe.DefaultParallel(tt.chunks, tt.requests, tt.audio)
+			if got != tt.want || limit != tt.wantLimit {
+				t.Errorf("DefaultParallel() = %d, %d, want %d, %d", got, limit, tt.want, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestUploadStats_BytesPerSecond(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		stats transcribe.UploadStats
+		want  float64
+	}{
+		{"measured", transcribe.UploadStats{Bytes: 1000, Duration: 2 * time.Second}, 500},
+		{"zero duration", transcribe.UploadStats{Bytes: 1000}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.stats.BytesPerSecond(); got != tt.want {
+				t.Errorf("BytesPerSecond() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests for OpenAITranscriber.LastUpload
+// ---------------------------------------------------------------------------
+
+func TestOpenAITranscriber_LastUpload(t *testing.T) {
+	t.Parallel()
+
+	mock := newMockHTTPClient(200, `{"text": "hello"}`)
+	tr := transcribe.NewTestTranscriber(mock, "https://api.test", transcribe.MinimalRetryOpts()...)
+
+	if _, ok := tr.LastUpload(); ok {
+		t.Error("LastUpload() ok = true before any upload")
+	}
+
+	if _, err := tr.Transcribe(context.Background(), createTempAudioFile(t), transcribe.Options{}); err != nil {
+		t.Fatalf("Transcribe() unexpected error: %v", err)
+	}
+
+	stats, ok := tr.LastUpload()
+	if !ok {
+		t.Fatal("LastUpload() ok = false after an upload")
+	}
+	if want := int64(len(mock.requestBodies[0])); stats.Bytes != want {
+		t.Errorf("LastUpload().Bytes = %d, want %d (request body size)", stats.Bytes, want)
+	}
+	if mock.requests[0].ContentLength != stats.Bytes {
+		t.Errorf("request ContentLength = %d, want %d", mock.requests[0].ContentLength, stats.Bytes)
+	}
+}
+
+// processingHTTPClient reads the whole request body, then answers after
+// delay, reporting processing milliseconds of server time when it is not
+// empty. With replay, the first body is dropped after a few bytes and the
+// request replayed from GetBody, like a reused connection that was closed.
+type processingHTTPClient struct {
+	delay      time.Duration
+	processing string
+	replay     bool
+}
+
+func (c *processingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	body := req.Body
+	if c.replay {
+		_, _ = body.Read(make([]byte, 16))
+		var err error
+		if body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return nil, err
+	}
+	time.Sleep(c.delay)
+	header := make(http.Header)
+	if c.processing != "" {
+		header.Set("openai-processing-ms", c.processing)
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"text": "hello"}`)),
+		Header:     header,
+	}, nil
+}
+
+func TestOpenAITranscriber_LastUpload_Duration(t *testing.T) {
+	t.Parallel()
+
+	const delay = 400 * time.Millisecond
+
+	t.Run("timed to the response minus the processing time", func(t *testing.T) {
+		t.Parallel()
+
+		// 100ms between the end of the body and the answer were not
+		// processing: the body was still in the socket buffer.
+		tr := transcribe.NewTestTranscriber(&processingHTTPClient{delay: delay, processing: "300"}, "https://api.test", transcribe.MinimalRetryOpts()...)
+		if _, err := tr.Transcribe(context.Background(), createTempAudioFile(t), transcribe.Options{}); err != nil {
+			t.Fatalf("Transcribe() unexpected error: %v", err)
+		}
+		stats, _ := tr.LastUpload()
+		if stats.Duration < 100*time.Millisecond || stats.Duration >= delay {
+			t.Errorf("LastUpload().Duration = %v, want about 100ms (response time minus processing time)", stats.Duration)
+		}
+	})
+
+	t.Run("timed to the end of the body without processing time", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transcribe.NewTestTranscriber(&processingHTTPClient{delay: delay}, "https://api.test", transcribe.MinimalRetryOpts()...)
+		if _, err := tr.Transcribe(context.Background(), createTempAudioFile(t), transcribe.Options{}); err != nil {
+			t.Fatalf("Transcribe() unexpected error: %v", err)
+		}
+		if stats, _ := tr.LastUpload(); stats.Duration >= delay {
+			t.Errorf("LastUpload().Duration = %v, want under the %v of the response", stats.Duration, delay)
+		}
+	})
+
+	t.Run("processing time longer than the request", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transcribe.NewTestTranscriber(&processingHTTPClient{processing: "60000"}, "https://api.test", transcribe.MinimalRetryOpts()...)
+		if _, err := tr.Transcribe(context.Background(), createTempAudioFile(t), transcribe.Options{}); err != nil {
+			t.Fatalf("Transcribe() unexpected error: %v", err)
+		}
+		if stats, ok := tr.LastUpload(); !ok || stats.Duration < 0 {
+			t.Errorf("LastUpload() = %+v, %v, want the time to send the body", stats, ok)
+		}
+	})
+}
+
+func TestOpenAITranscriber_LastUpload_ReplayedBody(t *testing.T) {
+	t.Parallel()
+
+	mock := newMockHTTPClient(200, `{"text": "hello"}`)
+	audioPath := createTempAudioFile(t)
+	tr := transcribe.NewTestTranscriber(mock, "https://api.test", transcribe.MinimalRetryOpts()...)
+	if _, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{}); err != nil {
+		t.Fatalf("Transcribe() unexpected error: %v", err)
+	}
+	size := int64(len(mock.requestBodies[0]))
+
+	tr = transcribe.NewTestTranscriber(&processingHTTPClient{replay: true}, "https://api.test", transcribe.MinimalRetryOpts()...)
+	if _, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{}); err != nil {
+		t.Fatalf("Transcribe() unexpected error: %v", err)
+	}
+	if stats, ok := tr.LastUpload(); !ok || stats.Bytes != size {
+		t.Errorf("LastUpload() = %+v, %v, want the %d bytes of the replayed body", stats, ok, size)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests for TranscribeRemainingAuto
+// ---------------------------------------------------------------------------
+
+func TestTranscribeRemainingAuto(t *testing.T) {
+	t.Parallel()
+
+	t.Run("limits parallelism on a slow connection", func(t *testing.T) {
+		t.Parallel()
+
+		// 1 MB chunks over 20 KB/s: one upload barely fits the budget.
+		chunks := createSizedChunks(t, 5, 1<<20)
+		tr := &meteredTranscriber{stats: transcribe.UploadStats{Bytes: 20 << 10, Duration: time.Second}}
+
+		var chosen int
+		results, err := transcribe.TranscribeRemainingAuto(context.Background(), chunks, tr,
+			transcribe.Options{}, nil, nil,
+			func(parallel int, _ float64) { chosen = parallel })
+
+		if err != nil {
+			t.Fatalf("TranscribeRemainingAuto() unexpected error: %v", err)
+		}
+		if chosen != 1 {
+			t.Errorf("chosen parallelism = %d, want 1", chosen)
+		}
+		if tr.maxConcurrent != 1 {
+			t.Errorf("max concurrent requests = %d, want 1", tr.maxConcurrent)
+		}
+		if len(results) != 5 || results[0] != "text chunk0.ogg" || results[4] != "text chunk4.ogg" {
+			t.Errorf("results = %v, want one result per chunk in order", results)
+		}
+		if len(tr.calls) != 5 {
+			t.Errorf("Transcribe called %d times, want 5 (probe not repeated)", len(tr.calls))
+		}
+	})
+
+	t.Run("uses full parallelism on a fast connection", func(t *testing.T) {
+		t.Parallel()
+
+		chunks := createSizedChunks(t, 4, 1024)
+		tr := &meteredTranscriber{stats: transcribe.UploadStats{Bytes: 1 << 30, Duration: time.Second}}
+
+		var chosen int
+		var throughput float64
+		_, err := transcribe.TranscribeRemainingAuto(context.Background(), chunks, tr,
+			transcribe.Options{}, nil, nil,
+			func(parallel int, bps float64) { chosen, throughput = parallel, bps })
+
+		if err != nil {
+			t.Fatalf("TranscribeRemainingAuto() unexpected error: %v", err)
+		}
+		if chosen != transcribe.MaxRecommendedParallel {
+			t.Errorf("chosen parallelism = %d, want %d", chosen, transcribe.MaxRecommendedParallel)
+		}
+		if throughput != 1<<30 {
+			t.Errorf("throughput = %v, want measured %v", throughput, float64(1<<30))
+		}
+	})
+
+	t.Run("probes the first pending chunk and keeps done chunks", func(t *testing.T) {
+		t.Parallel()
+
+		chunks := createSizedChunks(t, 3, 1024)
+		tr := &meteredTranscriber{stats: transcribe.UploadStats{Bytes: 1 << 30, Duration: time.Second}}
+		done := map[int]string{0: "cached"}
+
+		var reported []int
+		results, err := transcribe.TranscribeRemainingAuto(context.Background(), chunks, tr,
+			transcribe.Options{}, done,
+			func(index int, _ string) { reported = append(reported, index) }, nil)
+
+		if err != nil {
+			t.Fatalf("TranscribeRemainingAuto() unexpected error: %v", err)
+		}
+		if results[0] != "cached" {
+			t.Errorf("results[0] = %q, want cached result", results[0])
+		}
+		if len(tr.calls) != 2 || tr.calls[0] != chunks[1].Path {
+			t.Errorf("calls = %v, want chunk1 probed first and chunk0 skipped", tr.calls)
+		}
+		if len(reported) != 2 {
+			t.Errorf("reported = %v, want chunks 1 and 2", reported)
+		}
+		if len(done) != 1 {
+			t.Errorf("done map modified: %v", done)
+		}
+	})
+
+	t.Run("estimates throughput without upload meter", func(t *testing.T) {
+		t.Parallel()
+
+		chunks := createSizedChunks(t, 2, 1024)
+		tr := transcriberFunc(func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "ok", nil
+		})
+
+		var throughput float64
+		_, err := transcribe.TranscribeRemainingAuto(context.Background(), chunks, tr,
+			transcribe.Options{}, nil, nil,
+			func(_ int, bps float64) { throughput = bps })
+
+		if err != nil {
+			t.Fatalf("TranscribeRemainingAuto() unexpected error: %v", err)
+		}
+		if throughput <= 0 {
+			t.Errorf("throughput = %v, want estimate from probe duration", throughput)
+		}
+	})
+
+	t.Run("probe failure stops transcription", func(t *testing.T) {
+		t.Parallel()
+
+		chunks := createSizedChunks(t, 3, 1024)
+		var calls int
+		tr := transcriberFunc(func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			calls++
+			return "", errors.New("upload failed")
+		})
+
+		_, err := transcribe.TranscribeRemainingAuto(context.Background(), chunks, tr,
+			transcribe.Options{}, nil, nil, nil)
+
+		if err == nil || !strings.Contains(err.Error(), "upload failed") {
+			t.Errorf("TranscribeRemainingAuto() error = %v, want probe error", err)
+		}
+		if calls != 1 {
+			t.Errorf("Transcribe called %d times, want 1", calls)
+		}
+	})
+
+	t.Run("all chunks done", func(t *testing.T) {
+		t.Parallel()
+
+		chunks := createSizedChunks(t, 2, 1024)
+		tr := transcriberFunc(func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			return "", errors.New("must not be called")
+		})
+
+		results, err := transcribe.TranscribeRemainingAuto(context.Background(), chunks, tr,
+			transcribe.Options{}, map[int]string{0: "a", 1: "b"}, nil, nil)
+
+		if err != nil {
+			t.Fatalf("TranscribeRemainingAuto() unexpected error: %v", err)
+		}
+		if strings.Join(results, "|") != "a|b" {
+			t.Errorf("results = %v, want [a b]", results)
+		}
+	})
+}
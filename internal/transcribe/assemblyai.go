@@ -0,0 +1,413 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// AssemblyAI API identifiers.
+const (
+	// ModelAssemblyAIBest is the AssemblyAI speech model.
+	ModelAssemblyAIBest = "best"
+
+	// AssemblyAIMaxFileSize is the largest audio file the AssemblyAI upload
+	// endpoint accepts (2.2GB).
+	AssemblyAIMaxFileSize = 2200 * 1024 * 1024
+
+	// defaultAssemblyAIBaseURL is the base URL of the AssemblyAI API.
+	defaultAssemblyAIBaseURL = "https://api.assemblyai.com"
+
+	// defaultAssemblyAIPollInterval is the wait between two status requests
+	// of a transcript being processed.
+	defaultAssemblyAIPollInterval = 3 * time.Second
+
+	// defaultAssemblyAIMaxWait bounds the processing of one transcript.
+	defaultAssemblyAIMaxWait = 30 * time.Minute
+)
+
+// AssemblyAI transcript statuses.
+const (
+	assemblyAIStatusCompleted = "completed"
+	assemblyAIStatusError     = "error"
+)
+
+// Compile-time interface compliance checks.
+var (
+	_ Transcriber = (*AssemblyAITranscriber)(nil)
+	_ uploadMeter = (*AssemblyAITranscriber)(nil)
+)
+
+// AssemblyAITranscriber transcribes audio with the AssemblyAI API: the file
+// is uploaded, a transcript is requested for it, and its status is polled
+// until it is processed. AssemblyAI has no free-form prompt: Options.Prompt
+// is ignored. Each request is retried with exponential backoff on transient
+// errors.
+type AssemblyAITranscriber struct {
+	httpClient   httpDoer
+	apiKey       string
+	baseURL      string
+	maxRetries   int
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+	pollInterval time.Duration
+	maxWait      time.Duration
+
+	uploadSender
+}
+
+// AssemblyAIOption configures an AssemblyAITranscriber.
+type AssemblyAIOption func(*AssemblyAITranscriber)
+
+// WithAssemblyAIBaseURL sets a custom base URL (for testing or proxies).
+func WithAssemblyAIBaseURL(url string) AssemblyAIOption {
+	return func(t *AssemblyAITranscriber) {
+		t.baseURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithAssemblyAIHTTPClient sets a custom HTTP client (for testing).
+func WithAssemblyAIHTTPClient(c httpDoer) AssemblyAIOption {
+	return func(t *AssemblyAITranscriber) {
+		t.httpClient = c
+	}
+}
+
+// WithAssemblyAIRetries sets the maximum number of retries and the base and
+// max delays of the exponential backoff of each request.
+func WithAssemblyAIRetries(n int, base, max time.Duration) AssemblyAIOption {
+	return func(t *AssemblyAITranscriber) {
+		if n >= 0 {
+			t.maxRetries = n
+		}
+		if base > 0 {
+			t.baseDelay = base
+		}
+		if max > 0 {
+			t.maxDelay = max
+		}
+	}
+}
+
+// WithAssemblyAIPolling sets the wait between two status requests and how
+// long a transcript may be processed before failing with apierr.ErrTimeout.
+func WithAssemblyAIPolling(interval, maxWait time.Duration) AssemblyAIOption {
+	return func(t *AssemblyAITranscriber) {
+		if interval > 0 {
+			t.pollInterval = interval
+		}
+		if maxWait > 0 {
+			t.maxWait = maxWait
+		}
+	}
+}
+
+// WithAssemblyAIStallTimeout sets how long an upload may send nothing before
+// it is aborted and retried. Zero disables the watchdog.
+func WithAssemblyAIStallTimeout(d time.Duration) AssemblyAIOption {
+	return func(t *AssemblyAITranscriber) {
+		if d >= 0 {
+			t.stall = d
+		}
+	}
+}
+
+// NewAssemblyAITranscriber creates a new AssemblyAITranscriber.
+// apiKey is required for all requests (sent in the authorization header).
+func NewAssemblyAITranscriber(apiKey string, opts ...AssemblyAIOption) *AssemblyAITranscriber {
+	t := &AssemblyAITranscriber{
+		httpClient:   &http.Client{Timeout: 10 * time.Minute},
+		apiKey:       apiKey,
+		baseURL:      defaultAssemblyAIBaseURL,
+		maxRetries:   defaultMaxRetries,
+		baseDelay:    defaultBaseDelay,
+		maxDelay:     defaultMaxDelay,
+		pollInterval: defaultAssemblyAIPollInterval,
+		maxWait:      defaultAssemblyAIMaxWait,
+		uploadSender: uploadSender{stall: defaultStallTimeout},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// assemblyAITranscript represents an AssemblyAI transcript.
+type assemblyAITranscript struct {
+	ID         string `json:"id"`
+	Status     string `json:"status"`
+	Text       string `json:"text"`
+	Error      string `json:"error"`
+	Utterances []struct {
+		Speaker string `json:"speaker"`
+		Text    string `json:"text"`
+	} `json:"utterances"`
+}
+
+// Transcribe transcribes an audio file with the AssemblyAI API.
+// Files over AssemblyAIMaxFileSize fail with audio.ErrChunkTooLarge, without retry.
+// Translation (Options.Translate) fails with ErrTranslateUnsupported.
+func (t *AssemblyAITranscriber) Transcribe(ctx context.Context, audioPath string, opts Options) (string, error) {
+	if opts.Translate {
+		return "", fmt.Errorf("AssemblyAI: %w", ErrTranslateUnsupported)
+	}
+	if err := checkFileSize(audioPath, AssemblyAIMaxFileSize, "AssemblyAI"); err != nil {
+		return "", err
+	}
+
+	var upload struct {
+		URL string `json:"upload_url"`
+	}
+	if err := t.retry(ctx, opts, func() ([]byte, error) {
+		return t.upload(ctx, opts, audioPath)
+	}, &upload); err != nil {
+		return "", fmt.Errorf("upload: %w", err)
+	}
+
+	request := map[string]any{
+		"audio_url":      upload.URL,
+		"speech_model":   ModelAssemblyAIBest,
+		"speaker_labels": opts.Diarize,
+	}
+	if code := opts.Language.BaseCode(); code != "" {
+		request["language_code"] = code
+	} else {
+		request["language_detection"] = true
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	var transcript assemblyAITranscript
+	if err := t.call(ctx, opts, http.MethodPost, "/v2/transcript", payload, &transcript); err != nil {
+		return "", err
+	}
+
+	if transcript, err = t.wait(ctx, opts, transcript); err != nil {
+		return "", err
+	}
+	if opts.Timestamps {
+		return t.sentences(ctx, opts, transcript.ID)
+	}
+	if opts.Diarize && len(transcript.Utterances) > 0 {
+		var b strings.Builder
+		for _, u := range transcript.Utterances {
+			fmt.Fprintf(&b, "[%s] %s\n", u.Speaker, strings.TrimSpace(u.Text))
+		}
+		return strings.TrimSpace(b.String()), nil
+	}
+	return transcript.Text, nil
+}
+
+// wait polls transcript until it is processed, and returns it.
+// A transcript failing to process returns apierr.ErrBadRequest: AssemblyAI
+// reports files it cannot transcribe this way, so they are not retried.
+func (t *AssemblyAITranscriber) wait(ctx context.Context, opts Options, transcript assemblyAITranscript) (assemblyAITranscript, error) {
+	deadline := time.Now().Add(t.maxWait)
+	path := "/v2/transcript/" + url.PathEscape(transcript.ID)
+	for {
+		switch transcript.Status {
+		case assemblyAIStatusCompleted:
+			return transcript, nil
+		case assemblyAIStatusError:
+			return transcript, fmt.Errorf("AssemblyAI transcript %s failed: %s: %w", transcript.ID, transcript.Error, apierr.ErrBadRequest)
+		}
+		if time.Now().After(deadline) {
+			return transcript, fmt.Errorf("AssemblyAI transcript %s still %s after %s: %w", transcript.ID, transcript.Status, t.maxWait, apierr.ErrTimeout)
+		}
+
+		timer := time.NewTimer(t.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return transcript, ctx.Err()
+		case <-timer.C:
+		}
+		if err := t.call(ctx, opts, http.MethodGet, path, nil, &transcript); err != nil {
+			return transcript, err
+		}
+	}
+}
+
+// sentences returns the encoded timed segments of the sentences of the
+// processed transcript id, with their speaker if opts.Diarize.
+func (t *AssemblyAITranscriber) sentences(ctx context.Context, opts Options, id string) (string, error) {
+	var resp struct {
+		Sentences []struct {
+			Text       string  `json:"text"`
+			Start      int64   `json:"start"` // Milliseconds
+			End        int64   `json:"end"`
+			Speaker    string  `json:"speaker"`
+			Confidence float64 `json:"confidence"`
+		} `json:"sentences"`
+	}
+	if err := t.call(ctx, opts, http.MethodGet, "/v2/transcript/"+url.PathEscape(id)+"/sentences", nil, &resp); err != nil {
+		return "", err
+	}
+	segments := make([]TimedSegment, len(resp.Sentences))
+	for i, s := range resp.Sentences {
+		segments[i] = TimedSegment{
+			Start:      time.Duration(s.Start) * time.Millisecond,
+			End:        time.Duration(s.End) * time.Millisecond,
+			Text:       s.Text,
+			Confidence: s.Confidence,
+		}
+		if opts.Diarize {
+			segments[i].Speaker = s.Speaker
+		}
+	}
+	return EncodeSegments(segments)
+}
+
+// call sends a request to path with retries (see retry), and decodes the JSON
+// response into result. body, if non-nil, is the JSON request body.
+func (t *AssemblyAITranscriber) call(ctx context.Context, opts Options, method, path string, body []byte, result any) error {
+	return t.retry(ctx, opts, func() ([]byte, error) {
+		return t.do(ctx, method, path, body)
+	}, result)
+}
+
+// retry calls send with retries (see sendWithRetry), and decodes the JSON
+// response it returns into result.
+func (t *AssemblyAITranscriber) retry(ctx context.Context, opts Options, send func() ([]byte, error), result any) error {
+	cfg := apierr.RetryConfig{
+		MaxRetries: t.maxRetries,
+		BaseDelay:  t.baseDelay,
+		MaxDelay:   t.maxDelay,
+	}
+	respBody, err := sendWithRetry(ctx, cfg, opts, func() ([]byte, error) {
+		respBody, err := send()
+		if err != nil {
+			return nil, keepRetryAfter(err, classifyAssemblyAIError)
+		}
+		return respBody, nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// upload sends the audio file once and returns the body of the successful
+// response. The upload is metered and watched like those of OpenAI (see
+// uploadSender).
+func (t *AssemblyAITranscriber) upload(ctx context.Context, opts Options, audioPath string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/v2/upload", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", t.apiKey)
+
+	resp, err := t.sendFile(t.httpClient, req, opts, audioPath)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, apierr.WithRetryAfter(parseAssemblyAIError(resp.StatusCode, resp.Body), apierr.ParseRetryAfter(resp.Header, time.Now()))
+	}
+	return resp.Body, nil
+}
+
+// do performs one request and returns the body of the successful response.
+func (t *AssemblyAITranscriber) do(ctx context.Context, method, path string, body []byte) (_ []byte, err error) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, t.baseURL+path, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", t.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, apierr.WithRetryAfter(parseAssemblyAIError(resp.StatusCode, respBody), apierr.ParseRetryAfter(resp.Header, time.Now()))
+	}
+	return respBody, nil
+}
+
+// assemblyAIAPIError represents an error response from the AssemblyAI API.
+// Unexported: only used for error classification.
+type assemblyAIAPIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *assemblyAIAPIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("AssemblyAI API error %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("AssemblyAI API error %d", e.StatusCode)
+}
+
+// parseAssemblyAIError parses an HTTP error response from AssemblyAI into a typed error.
+func parseAssemblyAIError(statusCode int, body []byte) *assemblyAIAPIError {
+	var errResp struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Error == "" {
+		return &assemblyAIAPIError{StatusCode: statusCode, Message: string(body)}
+	}
+	return &assemblyAIAPIError{StatusCode: statusCode, Message: errResp.Error}
+}
+
+// classifyAssemblyAIError maps AssemblyAI API errors to apierr sentinel errors.
+func classifyAssemblyAIError(err error) error {
+	var apiErr *assemblyAIAPIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests:
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrRateLimit)
+		case http.StatusPaymentRequired:
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrQuotaExceeded)
+		case http.StatusUnauthorized:
+			// Accounts without credits are refused with 401 too
+			if strings.Contains(strings.ToLower(apiErr.Message), "balance") {
+				return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrQuotaExceeded)
+			}
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrAuthFailed)
+		case http.StatusRequestEntityTooLarge:
+			return fmt.Errorf("%s: %w", apiErr.Message, audio.ErrChunkTooLarge)
+		case http.StatusRequestTimeout, http.StatusGatewayTimeout,
+			http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrTimeout)
+		case http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound:
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrBadRequest)
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("request timed out: %w", apierr.ErrTimeout)
+	}
+	return err
+}
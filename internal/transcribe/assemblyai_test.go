@@ -0,0 +1,225 @@
+package transcribe_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// mockAssemblyAIServer is an AssemblyAI API processing transcripts after
+// two status requests.
+type mockAssemblyAIServer struct {
+	*httptest.Server
+	transcript map[string]any // Completed transcript, without id and status
+
+	mu       sync.Mutex
+	uploaded string         // Body of the upload
+	length   int64          // Content-Length of the upload
+	request  map[string]any // Body of the transcript request
+	polls    int
+	auth     []string // Authorization headers
+}
+
+func newMockAssemblyAIServer(t *testing.T, transcript map[string]any) *mockAssemblyAIServer {
+	t.Helper()
+	m := &mockAssemblyAIServer{transcript: transcript}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v2/upload", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		m.record(r, func() { m.uploaded, m.length = string(data), r.ContentLength })
+		writeJSON(w, map[string]any{"upload_url": "https://cdn.assemblyai.test/upload/1"})
+	})
+	mux.HandleFunc("POST /v2/transcript", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		m.record(r, func() { m.request = req })
+		writeJSON(w, map[string]any{"id": "tr-1", "status": "queued"})
+	})
+	mux.HandleFunc("GET /v2/transcript/tr-1", func(w http.ResponseWriter, r *http.Request) {
+		var polls int
+		m.record(r, func() { m.polls++; polls = m.polls })
+		if polls < 2 {
+			writeJSON(w, map[string]any{"id": "tr-1", "status": "processing"})
+			return
+		}
+		resp := map[string]any{"id": "tr-1", "status": "completed"}
+		for k, v := range m.transcript {
+			resp[k] = v
+		}
+		writeJSON(w, resp)
+	})
+	mux.HandleFunc("GET /v2/transcript/tr-1/sentences", func(w http.ResponseWriter, r *http.Request) {
+		m.record(r, nil)
+		writeJSON(w, map[string]any{"sentences": []map[string]any{
+			{"text": "Bonjour.", "start": 0, "end": 1200, "speaker": "A", "confidence": 0.97},
+			{"text": "Salut.", "start": 1500, "end": 2000, "speaker": "B", "confidence": 0.61},
+		}})
+	})
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+// record stores the authorization of r, then calls fn, holding the lock.
+func (m *mockAssemblyAIServer) record(r *http.Request, fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.auth = append(m.auth, r.Header.Get("Authorization"))
+	if fn != nil {
+		fn()
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newTestAssemblyAITranscriber(baseURL string) *transcribe.AssemblyAITranscriber {
+	return transcribe.NewAssemblyAITranscriber("aai-key",
+		transcribe.WithAssemblyAIBaseURL(baseURL),
+		transcribe.WithAssemblyAIRetries(2, time.Millisecond, time.Millisecond),
+		transcribe.WithAssemblyAIPolling(time.Millisecond, time.Second))
+}
+
+func TestAssemblyAITranscriber(t *testing.T) {
+	t.Parallel()
+
+	transcript := map[string]any{
+		"text": "Bonjour. Salut.",
+		"utterances": []map[string]any{
+			{"speaker": "A", "text": "Bonjour."},
+			{"speaker": "B", "text": "Salut."},
+		},
+	}
+	tests := []struct {
+		name string
+		opts transcribe.Options
+		want string
+	}{
+		{name: "plain text", want: "Bonjour. Salut."},
+		{name: "diarize", opts: transcribe.Options{Diarize: true}, want: "[A] Bonjour.\n[B] Salut."},
+		{
+			name: "timestamps",
+			opts: transcribe.Options{Timestamps: true},
+			want: `[{"start":0,"end":1.2,"text":"Bonjour.","confidence":0.97},{"start":1.5,"end":2,"text":"Salut.","confidence":0.61}]`,
+		},
+		{
+			name: "diarize with timestamps",
+			opts: transcribe.Options{Diarize: true, Timestamps: true},
+			want: `[{"start":0,"end":1.2,"speaker":"A","text":"Bonjour.","confidence":0.97},{"start":1.5,"end":2,"speaker":"B","text":"Salut.","confidence":0.61}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newMockAssemblyAIServer(t, transcript)
+			tr := newTestAssemblyAITranscriber(server.URL)
+
+			got, err := tr.Transcribe(context.Background(), createTempAudioFile(t), tt.opts)
+			if err != nil {
+				t.Fatalf("Transcribe() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Transcribe() = %q, want %q", got, tt.want)
+			}
+
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			if server.uploaded != "fake audio content" || server.length != int64(len(server.uploaded)) {
+				t.Errorf("uploaded %q (Content-Length %d), want the audio file", server.uploaded, server.length)
+			}
+			if stats, ok := tr.LastUpload(); !ok || stats.Bytes != int64(len(server.uploaded)) {
+				t.Errorf("LastUpload() = %+v, %v, want the upload of the audio file", stats, ok)
+			}
+			if server.request["audio_url"] != "https://cdn.assemblyai.test/upload/1" ||
+				server.request["speaker_labels"] != tt.opts.Diarize ||
+				server.request["language_detection"] != true {
+				t.Errorf("transcript request = %v", server.request)
+			}
+			if server.polls != 2 {
+				t.Errorf("polled %d times, want 2", server.polls)
+			}
+			for _, auth := range server.auth {
+				if auth != "aai-key" {
+					t.Errorf("Authorization = %q, want the API key", auth)
+				}
+			}
+		})
+	}
+}
+
+func TestAssemblyAITranscriber_TranscriptError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/upload":
+			writeJSON(w, map[string]any{"upload_url": "https://cdn.assemblyai.test/upload/1"})
+		default:
+			writeJSON(w, map[string]any{"id": "tr-1", "status": "error", "error": "File does not appear to contain audio."})
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := newTestAssemblyAITranscriber(server.URL).Transcribe(context.Background(), createTempAudioFile(t), transcribe.Options{})
+	if !errors.Is(err, apierr.ErrBadRequest) {
+		t.Errorf("Transcribe() error = %v, want ErrBadRequest", err)
+	}
+}
+
+func TestAssemblyAITranscriber_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantErr   error
+		wantCalls int
+	}{
+		{"invalid key", http.StatusUnauthorized, `{"error":"Authentication error, API token missing/invalid"}`, apierr.ErrAuthFailed, 1},
+		{"no balance", http.StatusUnauthorized, `{"error":"Your current account balance is negative"}`, apierr.ErrQuotaExceeded, 1},
+		{"bad request", http.StatusBadRequest, `{"error":"Invalid language_code"}`, apierr.ErrBadRequest, 1},
+		{"rate limited then retried", http.StatusTooManyRequests, `{"error":"Too many requests"}`, apierr.ErrRateLimit, 3},
+		{"server error retried", http.StatusServiceUnavailable, "unavailable", apierr.ErrTimeout, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				calls++
+				mu.Unlock()
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			t.Cleanup(server.Close)
+
+			_, err := newTestAssemblyAITranscriber(server.URL).Transcribe(context.Background(), createTempAudioFile(t), transcribe.Options{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Transcribe() error = %v, want %v", err, tt.wantErr)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if calls != tt.wantCalls {
+				t.Errorf("sent %d requests, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package transcribe
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// checkpointVersion is bumped when the checkpoint format changes incompatibly.
+const checkpointVersion = 1
+
+// Checkpoint records the chunks of an input file that were already transcribed,
+// so an interrupted run can be resumed without paying for them again.
+//
+// A checkpoint is only valid for the same input file (path, size, modification
+// time), the same transcription options and the same chunking (chunk count):
+// chunk indices are meaningless otherwise. Use Matches before reusing one.
+type Checkpoint struct {
+	Version    int            `json:"version"`
+	Input      string         `json:"input"`
+	Size       int64          `json:"size"`
+	ModTime    time.Time      `json:"mod_time"`
+	Diarize    bool           `json:"diarize"`
+	Prompt     string         `json:"prompt,omitempty"`
+	Language   string         `json:"language,omitempty"`
+	Timestamps bool           `json:"timestamps,omitempty"` // Results are encoded timed segments
+	Translate  bool           `json:"translate,omitempty"`  // Results are translated to English
+	Chunks     int            `json:"chunks"`
+	Results    map[int]string `json:"results"`
+}
+
+// NewCheckpoint creates an empty checkpoint for inputPath split into chunkCount chunks.
+func NewCheckpoint(inputPath string, opts Options, chunkCount int) (*Checkpoint, error) {
+	abs, err := filepath.Abs(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve input path: %w", err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access input file: %w", err)
+	}
+
+	return &Checkpoint{
+		Version:    checkpointVersion,
+		Input:      abs,
+		Size:       info.Size(),
+		ModTime:    info.ModTime().UTC(),
+		Diarize:    opts.Diarize,
+		Prompt:     opts.Prompt,
+		Language:   opts.Language.String(),
+		Timestamps: opts.Timestamps,
+		Translate:  opts.Translate,
+		Chunks:     chunkCount,
+		Results:    make(map[int]string),
+	}, nil
+}
+
+// LoadCheckpoint reads a checkpoint file.
+// Returns an error satisfying errors.Is(err, os.ErrNotExist) if there is none.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- checkpoint path derived from output path
+	if err != nil {
+		return nil, err
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+	if cp.Results == nil {
+		cp.Results = make(map[int]string)
+	}
+	return &cp, nil
+}
+
+// Matches reports whether cp was created for the same input, options and
+// chunking as other, i.e. whether its results can be reused.
+func (cp *Checkpoint) Matches(other *Checkpoint) bool {
+	return cp.Version == other.Version &&
+		cp.Input == other.Input &&
+		cp.Size == other.Size &&
+		cp.ModTime.Equal(other.ModTime) &&
+		cp.Diarize == other.Diarize &&
+		cp.Prompt == other.Prompt &&
+		cp.Language == other.Language &&
+		cp.Timestamps == other.Timestamps &&
+		cp.Translate == other.Translate &&
+		cp.Chunks == other.Chunks
+}
+
+// Record stores the transcription of the chunk with the given index.
+func (cp *Checkpoint) Record(index int, text string) {
+	cp.Results[index] = text
+}
+
+// Save writes the checkpoint to path.
+// The file is replaced atomically so an interrupt never leaves a truncated checkpoint.
+func (cp *Checkpoint) Save(path string) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode checkpoint: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("cannot write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("cannot write checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package transcribe_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// ---------------------------------------------------------------------------
+// TestCheckpoint - Persistence and matching
+// ---------------------------------------------------------------------------
+
+func TestCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	t.Run("save and load round trip", func(t *testing.T) {
+		t.Parallel()
+
+		input := createTempAudioFile(t)
+		path := filepath.Join(t.TempDir(), "state.json")
+
+		cp, err := transcribe.NewCheckpoint(input, transcribe.Options{Diarize: true, Language: lang.MustParse("fr")}, 3)
+		if err != nil {
+			t.Fatalf("NewCheckpoint() unexpected error: %v", err)
+		}
+		cp.Record(0, "bonjour")
+		cp.Record(2, "au revoir")
+		if err := cp.Save(path); err != nil {
+			t.Fatalf("Save() unexpected error: %v", err)
+		}
+
+		loaded, err := transcribe.LoadCheckpoint(path)
+		if err != nil {
+			t.Fatalf("LoadCheckpoint() unexpected error: %v", err)
+		}
+		if !loaded.Matches(cp) {
+			t.Errorf("loaded checkpoint %+v does not match saved %+v", loaded, cp)
+		}
+		if len(loaded.Results) != 2 || loaded.Results[0] != "bonjour" || loaded.Results[2] != "au revoir" {
+			t.Errorf("Results = %v, want chunks 0 and 2", loaded.Results)
+		}
+		if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+			t.Errorf("temporary file left behind (stat error = %v)", err)
+		}
+	})
+
+	t.Run("missing file returns ErrNotExist", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := transcribe.LoadCheckpoint(filepath.Join(t.TempDir(), "missing.json"))
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("LoadCheckpoint() error = %v, want os.ErrNotExist", err)
+		}
+	})
+
+	t.Run("invalid JSON returns error", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "state.json")
+		if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+			t.Fatalf("os.WriteFile() unexpected error: %v", err)
+		}
+
+		if _, err := transcribe.LoadCheckpoint(path); err == nil {
+			t.Error("LoadCheckpoint() expected error for invalid JSON, got nil")
+		}
+	})
+
+	t.Run("missing input returns error", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := transcribe.NewCheckpoint(filepath.Join(t.TempDir(), "missing.ogg"), transcribe.Options{}, 1); err == nil {
+			t.Error("NewCheckpoint() expected error for missing input, got nil")
+		}
+	})
+}
+
+func TestCheckpoint_Matches(t *testing.T) {
+	t.Parallel()
+
+	input := createTempAudioFile(t)
+	base, err := transcribe.NewCheckpoint(input, transcribe.Options{}, 4)
+	if err != nil {
+		t.Fatalf("NewCheckpoint() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(cp *transcribe.Checkpoint)
+		want   bool
+	}{
+		{"identical", func(cp *transcribe.Checkpoint) {}, true},
+		{"results differ", func(cp *transcribe.Checkpoint) { cp.Record(1, "text") }, true},
+		{"different input", func(cp *transcribe.Checkpoint) { cp.Input = "/other.ogg" }, false},
+		{"input size changed", func(cp *transcribe.Checkpoint) { cp.Size++ }, false},
+		{"input modified", func(cp *transcribe.Checkpoint) { cp.ModTime = cp.ModTime.Add(time.Second) }, false},
+		{"diarize changed", func(cp *transcribe.Checkpoint) { cp.Diarize = true }, false},
+		{"language changed", func(cp *transcribe.Checkpoint) { cp.Language = "en" }, false},
+		{"timestamps changed", func(cp *transcribe.Checkpoint) { cp.Timestamps = true }, false},
+		{"translate changed", func(cp *transcribe.Checkpoint) { cp.Translate = true }, false},
+		{"chunk count changed", func(cp *transcribe.Checkpoint) { cp.Chunks = 5 }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			other, err := transcribe.NewCheckpoint(input, transcribe.Options{}, 4)
+			if err != nil {
+				t.Fatalf("NewCheckpoint() unexpected error: %v", err)
+			}
+			tt.modify(other)
+
+			if got := other.Matches(base); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package transcribe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// Deepgram API identifiers.
+const (
+	// ModelDeepgramNova3 is the Deepgram transcription model.
+	ModelDeepgramNova3 = "nova-3"
+
+	// DeepgramMaxFileSize is the largest audio file the Deepgram API accepts (2GB).
+	DeepgramMaxFileSize = 2 * 1024 * 1024 * 1024
+
+	// defaultDeepgramBaseURL is the base URL of the Deepgram API.
+	defaultDeepgramBaseURL = "https://api.deepgram.com"
+
+	// deepgramListenPath is the API path of pre-recorded audio transcription.
+	deepgramListenPath = "/v1/listen"
+)
+
+// Compile-time interface compliance checks.
+var (
+	_ Transcriber = (*DeepgramTranscriber)(nil)
+	_ uploadMeter = (*DeepgramTranscriber)(nil)
+)
+
+// DeepgramTranscriber transcribes audio with the Deepgram API, sending the
+// file in the request body. Deepgram has no free-form prompt: Options.Prompt
+// is ignored. Automatic retries with exponential backoff for transient errors.
+type DeepgramTranscriber struct {
+	httpClient httpDoer
+	apiKey     string
+	baseURL    string
+	model      string
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+
+	uploadSender
+}
+
+// DeepgramOption configures a DeepgramTranscriber.
+type DeepgramOption func(*DeepgramTranscriber)
+
+// WithDeepgramBaseURL sets a custom base URL (for testing or proxies).
+func WithDeepgramBaseURL(url string) DeepgramOption {
+	return func(t *DeepgramTranscriber) {
+		t.baseURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithDeepgramHTTPClient sets a custom HTTP client (for testing).
+func WithDeepgramHTTPClient(c httpDoer) DeepgramOption {
+	return func(t *DeepgramTranscriber) {
+		t.httpClient = c
+	}
+}
+
+// WithDeepgramModel sets the model. Default: ModelDeepgramNova3.
+func WithDeepgramModel(model string) DeepgramOption {
+	return func(t *DeepgramTranscriber) {
+		if model != "" {
+			t.model = model
+		}
+	}
+}
+
+// WithDeepgramRetries sets the maximum number of retries and the base and
+// max delays of the exponential backoff.
+func WithDeepgramRetries(n int, base, max time.Duration) DeepgramOption {
+	return func(t *DeepgramTranscriber) {
+		if n >= 0 {
+			t.maxRetries = n
+		}
+		if base > 0 {
+			t.baseDelay = base
+		}
+		if max > 0 {
+			t.maxDelay = max
+		}
+	}
+}
+
+// WithDeepgramStallTimeout sets how long an upload may send nothing before
+// it is aborted and retried. Zero disables the watchdog.
+func WithDeepgramStallTimeout(d time.Duration) DeepgramOption {
+	return func(t *DeepgramTranscriber) {
+		if d >= 0 {
+			t.stall = d
+		}
+	}
+}
+
+// NewDeepgramTranscriber creates a new DeepgramTranscriber.
+// apiKey is required for all requests (sent as a Token authorization).
+func NewDeepgramTranscriber(apiKey string, opts ...DeepgramOption) *DeepgramTranscriber {
+	t := &DeepgramTranscriber{
+		httpClient:   &http.Client{Timeout: 10 * time.Minute},
+		apiKey:       apiKey,
+		baseURL:      defaultDeepgramBaseURL,
+		model:        ModelDeepgramNova3,
+		maxRetries:   defaultMaxRetries,
+		baseDelay:    defaultBaseDelay,
+		maxDelay:     defaultMaxDelay,
+		uploadSender: uploadSender{stall: defaultStallTimeout},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Transcribe transcribes an audio file with the Deepgram API.
+// Speakers are labeled A, B, ... like those of OpenAI.
+// Files over DeepgramMaxFileSize fail with audio.ErrChunkTooLarge, without retry.
+// Translation (Options.Translate) fails with ErrTranslateUnsupported.
+func (t *DeepgramTranscriber) Transcribe(ctx context.Context, audioPath string, opts Options) (string, error) {
+	if opts.Translate {
+		return "", fmt.Errorf("Deepgram: %w", ErrTranslateUnsupported)
+	}
+	if err := checkFileSize(audioPath, DeepgramMaxFileSize, "Deepgram"); err != nil {
+		return "", err
+	}
+	cfg := apierr.RetryConfig{
+		MaxRetries: t.maxRetries,
+		BaseDelay:  t.baseDelay,
+		MaxDelay:   t.maxDelay,
+	}
+	return sendWithRetry(ctx, cfg, opts, func() (string, error) {
+		body, err := t.listen(ctx, audioPath, opts)
+		if err != nil {
+			return "", keepRetryAfter(err, classifyDeepgramError)
+		}
+		return parseDeepgramResponse(body, opts)
+	})
+}
+
+// listenURL returns the URL of the transcription request of opts.
+func (t *DeepgramTranscriber) listenURL(opts Options) string {
+	q := url.Values{}
+	q.Set("model", t.model)
+	q.Set("smart_format", "true")
+	if code := opts.Language.BaseCode(); code != "" {
+		q.Set("language", code)
+	} else {
+		q.Set("detect_language", "true")
+	}
+	if opts.Diarize {
+		q.Set("diarize", "true")
+	}
+	if opts.Diarize || opts.Timestamps {
+		q.Set("utterances", "true")
+	}
+	return t.baseURL + deepgramListenPath + "?" + q.Encode()
+}
+
+// listen sends the audio file and returns the body of the successful response.
+// The upload is metered and watched like those of OpenAI (see uploadSender).
+func (t *DeepgramTranscriber) listen(ctx context.Context, audioPath string, opts Options) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.listenURL(opts), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Token "+t.apiKey)
+	req.Header.Set("Content-Type", audioContentType(audioPath))
+
+	resp, err := t.sendFile(t.httpClient, req, opts, audioPath)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, apierr.WithRetryAfter(parseDeepgramError(resp.StatusCode, resp.Body), apierr.ParseRetryAfter(resp.Header, time.Now()))
+	}
+	return resp.Body, nil
+}
+
+// audioContentType returns the MIME type of an audio file from its extension.
+func audioContentType(audioPath string) string {
+	if t := mime.TypeByExtension(filepath.Ext(audioPath)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// deepgramResponse represents the Deepgram pre-recorded transcription response.
+type deepgramResponse struct {
+	Results struct {
+		Channels []struct {
+			Alternatives []struct {
+				Transcript string `json:"transcript"`
+			} `json:"alternatives"`
+		} `json:"channels"`
+		Utterances []struct {
+			Start      float64 `json:"start"`
+			End        float64 `json:"end"`
+			Speaker    int     `json:"speaker"`
+			Transcript string  `json:"transcript"`
+			Confidence float64 `json:"confidence"`
+		} `json:"utterances"`
+	} `json:"results"`
+}
+
+// parseDeepgramResponse converts a Deepgram response to the result of opts:
+// encoded timed segments with Timestamps, speaker lines with Diarize, plain
+// text otherwise.
+func parseDeepgramResponse(body []byte, opts Options) (string, error) {
+	var resp deepgramResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	utterances := resp.Results.Utterances
+
+	if opts.Timestamps {
+		segments := make([]TimedSegment, len(utterances))
+		for i, u := range utterances {
+			segments[i] = TimedSegment{Start: seconds(u.Start), End: seconds(u.End), Text: u.Transcript, Confidence: u.Confidence}
+			if opts.Diarize {
+				segments[i].Speaker = speakerLabel(u.Speaker)
+			}
+		}
+		return EncodeSegments(segments)
+	}
+
+	if opts.Diarize && len(utterances) > 0 {
+		var b strings.Builder
+		for _, u := range utterances {
+			fmt.Fprintf(&b, "[%s] %s\n", speakerLabel(u.Speaker), strings.TrimSpace(u.Transcript))
+		}
+		return strings.TrimSpace(b.String()), nil
+	}
+
+	if len(resp.Results.Channels) == 0 || len(resp.Results.Channels[0].Alternatives) == 0 {
+		return "", nil
+	}
+	return resp.Results.Channels[0].Alternatives[0].Transcript, nil
+}
+
+// speakerLabel returns the label of the speaker numbered n from 0: A to Z,
+// like OpenAI, then "Speaker N".
+func speakerLabel(n int) string {
+	if n >= 0 && n < 26 {
+		return string(rune('A' + n))
+	}
+	return fmt.Sprintf("Speaker %d", n+1)
+}
+
+// deepgramAPIError represents an error response from the Deepgram API.
+// Unexported: only used for error classification.
+type deepgramAPIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *deepgramAPIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("Deepgram API error %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("Deepgram API error %d", e.StatusCode)
+}
+
+// parseDeepgramError parses an HTTP error response from Deepgram into a typed error.
+func parseDeepgramError(statusCode int, body []byte) *deepgramAPIError {
+	var errResp struct {
+		Code    string `json:"err_code"`
+		Message string `json:"err_msg"`
+	}
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Message == "" {
+		return &deepgramAPIError{StatusCode: statusCode, Message: string(body)}
+	}
+	return &deepgramAPIError{StatusCode: statusCode, Code: errResp.Code, Message: errResp.Message}
+}
+
+// classifyDeepgramError maps Deepgram API errors to apierr sentinel errors.
+func classifyDeepgramError(err error) error {
+	var apiErr *deepgramAPIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests:
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrRateLimit)
+		case http.StatusPaymentRequired:
+			// Deepgram answers 402 when the project runs out of credits
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrQuotaExceeded)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrAuthFailed)
+		case http.StatusRequestEntityTooLarge:
+			return fmt.Errorf("%s: %w", apiErr.Message, audio.ErrChunkTooLarge)
+		case http.StatusRequestTimeout, http.StatusGatewayTimeout,
+			http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrTimeout)
+		case http.StatusBadRequest, http.StatusNotFound, http.StatusUnsupportedMediaType:
+			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrBadRequest)
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("request timed out: %w", apierr.ErrTimeout)
+	}
+	return err
+}
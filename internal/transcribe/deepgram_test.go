@@ -0,0 +1,187 @@
+package transcribe_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// deepgramResponse is a Deepgram response with two utterances of two speakers.
+const deepgramResponse = `{
+	"results": {
+		"channels": [{"alternatives": [{"transcript": "Bonjour. Salut."}]}],
+		"utterances": [
+			{"start": 0.0, "end": 1.2, "speaker": 0, "transcript": "Bonjour.", "confidence": 0.98},
+			{"start": 1.5, "end": 2.0, "speaker": 1, "transcript": "Salut.", "confidence": 0.42}
+		]
+	}
+}`
+
+// deepgramRequest is a request received by a test Deepgram server.
+type deepgramRequest struct {
+	query         url.Values
+	authorization string
+	contentLength int64
+	body          string
+}
+
+// newDeepgramServer starts a server answering status and body, and sending
+// the requests it receives on the returned channel.
+func newDeepgramServer(t *testing.T, status int, body string) (*httptest.Server, <-chan deepgramRequest) {
+	t.Helper()
+	requests := make(chan deepgramRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if r.URL.Path != "/v1/listen" {
+			http.NotFound(w, r)
+			return
+		}
+		requests <- deepgramRequest{query: r.URL.Query(), authorization: r.Header.Get("Authorization"), contentLength: r.ContentLength, body: string(data)}
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestDeepgramTranscriber(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      transcribe.Options
+		wantQuery map[string]string
+		want      string
+	}{
+		{
+			name:      "plain text",
+			wantQuery: map[string]string{"model": "nova-3", "detect_language": "true", "diarize": "", "utterances": ""},
+			want:      "Bonjour. Salut.",
+		},
+		{
+			name:      "language",
+			opts:      transcribe.Options{Language: lang.MustParse("fr-FR")},
+			wantQuery: map[string]string{"language": "fr", "detect_language": ""},
+			want:      "Bonjour. Salut.",
+		},
+		{
+			name:      "diarize",
+			opts:      transcribe.Options{Diarize: true},
+			wantQuery: map[string]string{"diarize": "true", "utterances": "true"},
+			want:      "[A] Bonjour.\n[B] Salut.",
+		},
+		{
+			name:      "timestamps",
+			opts:      transcribe.Options{Timestamps: true},
+			wantQuery: map[string]string{"diarize": "", "utterances": "true"},
+			want:      `[{"start":0,"end":1.2,"text":"Bonjour.","confidence":0.98},{"start":1.5,"end":2,"text":"Salut.","confidence":0.42}]`,
+		},
+		{
+			name:      "diarize with timestamps",
+			opts:      transcribe.Options{Diarize: true, Timestamps: true},
+			wantQuery: map[string]string{"diarize": "true", "utterances": "true"},
+			want:      `[{"start":0,"end":1.2,"speaker":"A","text":"Bonjour.","confidence":0.98},{"start":1.5,"end":2,"speaker":"B","text":"Salut.","confidence":0.42}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server, requests := newDeepgramServer(t, http.StatusOK, deepgramResponse)
+			tr := transcribe.NewDeepgramTranscriber("dg-key", transcribe.WithDeepgramBaseURL(server.URL))
+
+			got, err := tr.Transcribe(context.Background(), createTempAudioFile(t), tt.opts)
+			if err != nil {
+				t.Fatalf("Transcribe() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Transcribe() = %q, want %q", got, tt.want)
+			}
+
+			req := <-requests
+			if req.authorization != "Token dg-key" {
+				t.Errorf("Authorization = %q, want %q", req.authorization, "Token dg-key")
+			}
+			if req.body != "fake audio content" || req.contentLength != int64(len(req.body)) {
+				t.Errorf("request body = %q (Content-Length %d), want the audio file", req.body, req.contentLength)
+			}
+			if stats, ok := tr.LastUpload(); !ok || stats.Bytes != int64(len(req.body)) {
+				t.Errorf("LastUpload() = %+v, %v, want the upload of the audio file", stats, ok)
+			}
+			for key, want := range tt.wantQuery {
+				if got := req.query.Get(key); got != want {
+					t.Errorf("query %s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDeepgramTranscriber_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantErr   error
+		wantCalls int
+	}{
+		{"invalid key", http.StatusUnauthorized, `{"err_code":"INVALID_AUTH","err_msg":"Invalid credentials."}`, apierr.ErrAuthFailed, 1},
+		{"no credits", http.StatusPaymentRequired, `{"err_code":"ASR_PAYMENT_REQUIRED","err_msg":"Insufficient credits."}`, apierr.ErrQuotaExceeded, 1},
+		{"bad audio", http.StatusBadRequest, `{"err_code":"Bad Request","err_msg":"corrupt or unsupported data"}`, apierr.ErrBadRequest, 1},
+		{"too large", http.StatusRequestEntityTooLarge, `{"err_msg":"payload too large"}`, audio.ErrChunkTooLarge, 1},
+		{"rate limited then retried", http.StatusTooManyRequests, `{"err_msg":"Too many requests"}`, apierr.ErrRateLimit, 3},
+		{"server error retried", http.StatusBadGateway, "bad gateway", apierr.ErrTimeout, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server, requests := newDeepgramServer(t, tt.status, tt.body)
+			tr := transcribe.NewDeepgramTranscriber("dg-key",
+				transcribe.WithDeepgramBaseURL(server.URL),
+				transcribe.WithDeepgramRetries(2, time.Millisecond, time.Millisecond))
+
+			_, err := tr.Transcribe(context.Background(), createTempAudioFile(t), transcribe.Options{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Transcribe() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := len(requests); got != tt.wantCalls {
+				t.Errorf("sent %d requests, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDeepgramTranscriber_StallWatchdog(t *testing.T) {
+	t.Parallel()
+
+	client := &stallingHTTPClient{stalls: 1}
+	tr := transcribe.NewDeepgramTranscriber("dg-key",
+		transcribe.WithDeepgramHTTPClient(client),
+		transcribe.WithDeepgramRetries(2, time.Millisecond, time.Millisecond),
+		transcribe.WithDeepgramStallTimeout(20*time.Millisecond))
+
+	if _, err := tr.Transcribe(context.Background(), createTempAudioFile(t), transcribe.Options{}); err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if got := client.calls.Load(); got != 2 {
+		t.Errorf("call count = %d, want 2", got)
+	}
+	if got := client.closed.Load(); got != 1 {
+		t.Errorf("CloseIdleConnections() called %d times, want 1", got)
+	}
+}
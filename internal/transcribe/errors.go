@@ -0,0 +1,25 @@
+package transcribe
+
+import "errors"
+
+// ErrWhisperNotFound indicates the whisper.cpp binary could not be found.
+var ErrWhisperNotFound = errors.New("whisper.cpp binary not found")
+
+// ErrModelNotFound indicates the local whisper model file does not exist.
+var ErrModelNotFound = errors.New("whisper model not found")
+
+// ErrDiarizeUnsupported indicates speaker identification was requested from a
+// transcriber that cannot provide it.
+var ErrDiarizeUnsupported = errors.New("diarization not supported by this transcriber")
+
+// ErrTranslateUnsupported indicates translation to English was requested
+// from a transcriber that cannot provide it (see Options.Translate).
+var ErrTranslateUnsupported = errors.New("audio translation not supported by this transcriber")
+
+// ErrPartial indicates that some chunks could not be transcribed and were left
+// out of the results (see WithAllowPartial).
+var ErrPartial = errors.New("some chunks could not be transcribed")
+
+// ErrUnknownProvider indicates that no transcription provider is registered
+// under a name (see RegisterProvider).
+var ErrUnknownProvider = errors.New("unknown transcription provider")
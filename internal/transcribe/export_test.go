@@ -1,12 +1,23 @@
 package transcribe
 
 import (
+	"encoding/binary"
+	"errors"
+	"math"
 	"time"
 )
 
 // Exports for testing. These allow black-box tests to inject dependencies
 // without modifying the public API.
 
+// UnregisterProvider removes the provider registered under name, so that
+// tests registering one leave the global registry as they found it.
+func UnregisterProvider(name string) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	delete(providers, name)
+}
+
 // NewTestTranscriber creates an OpenAITranscriber with a mock httpDoer and test base URL.
 // This allows testing without a real OpenAI API.
 func NewTestTranscriber(httpClient httpDoer, baseURL string, opts ...TranscriberOption) *OpenAITranscriber {
@@ -43,3 +54,97 @@ var (
 	ParseTranscriptionResponse = parseTranscriptionResponse
 	ParseHTTPError             = parseHTTPError
 )
+
+// WithCommandRunner exports withCommandRunner for testing LocalTranscriber.
+var WithCommandRunner = withCommandRunner
+
+// JoinSegments exports joinSegments for testing.
+var JoinSegments = joinSegments
+
+// SetClock sets the clock of the rate limiter.
+func (l *RateLimiter) SetClock(now func() time.Time) { l.now = now }
+
+// Reserve exports reserve for testing the request schedule.
+func (l *RateLimiter) Reserve(audio time.Duration) time.Time { return l.reserve(audio) }
+
+// Factor returns the share of the configured rates in use.
+func (l *RateLimiter) Factor() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.factor
+}
+
+// GRPCRequest is the decoded stream of RecognizeRequest messages of a call.
+type GRPCRequest struct {
+	AudioFormat string
+	Language    string
+	Prompt      string
+	Diarize     bool
+	Timestamps  bool
+	Audio       []byte
+	Messages    int // Audio messages
+}
+
+// DecodeGRPCRequests decodes the request body of a StreamingRecognize call,
+// for test servers.
+func DecodeGRPCRequests(body []byte) (GRPCRequest, error) {
+	var req GRPCRequest
+	for len(body) > 0 {
+		if len(body) < 5 {
+			return req, errors.New("truncated frame")
+		}
+		n := int(binary.BigEndian.Uint32(body[1:5]))
+		msg := body[5 : 5+n]
+		body = body[5+n:]
+		fields, err := decodeProtoFields(msg)
+		if err != nil {
+			return req, err
+		}
+		for _, f := range fields {
+			switch f.num {
+			case 1:
+				config, err := decodeProtoFields(f.bytes)
+				if err != nil {
+					return req, err
+				}
+				for _, c := range config {
+					switch c.num {
+					case 1:
+						req.AudioFormat = string(c.bytes)
+					case 2:
+						req.Language = string(c.bytes)
+					case 3:
+						req.Prompt = string(c.bytes)
+					case 4:
+						req.Diarize = c.value == 1
+					case 5:
+						req.Timestamps = c.value == 1
+					}
+				}
+			case 2:
+				req.Audio = append(req.Audio, f.bytes...)
+				req.Messages++
+			}
+		}
+	}
+	return req, nil
+}
+
+// EncodeGRPCResponse encodes a RecognizeResponse as a gRPC message, for test servers.
+func EncodeGRPCResponse(text string, segments []TimedSegment) []byte {
+	m := appendStringField(nil, 1, text)
+	for _, s := range segments {
+		var seg []byte
+		seg = binary.AppendUvarint(seg, 1<<3|wireFixed64)
+		seg = binary.LittleEndian.AppendUint64(seg, math.Float64bits(s.Start.Seconds()))
+		seg = binary.AppendUvarint(seg, 2<<3|wireFixed64)
+		seg = binary.LittleEndian.AppendUint64(seg, math.Float64bits(s.End.Seconds()))
+		seg = appendStringField(seg, 3, s.Speaker)
+		seg = appendStringField(seg, 4, s.Text)
+		m = appendBytesField(m, 2, seg)
+	}
+	return grpcFrame(m)
+}
+
+// GRPCTimeout exports grpcTimeout for testing.
+var GRPCTimeout = grpcTimeout
@@ -0,0 +1,56 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// Compile-time interface compliance checks.
+var (
+	_ Transcriber = (*FallbackTranscriber)(nil)
+	_ uploadMeter = (*FallbackTranscriber)(nil)
+)
+
+// FallbackTranscriber sends the chunks too large for its primary transcriber
+// to a fallback transcriber with a larger limit (e.g. the local backend),
+// instead of failing with audio.ErrChunkTooLarge.
+type FallbackTranscriber struct {
+	primary    Transcriber
+	fallback   Transcriber
+	onFallback func(audioPath string, err error)
+}
+
+// NewFallbackTranscriber creates a transcriber using primary, and fallback for
+// the files primary rejects as too large. onFallback, if not nil, is called
+// with the file and the error of primary before each switch to fallback.
+func NewFallbackTranscriber(primary, fallback Transcriber, onFallback func(audioPath string, err error)) *FallbackTranscriber {
+	return &FallbackTranscriber{primary: primary, fallback: fallback, onFallback: onFallback}
+}
+
+// Transcribe transcribes audioPath with the primary transcriber, or with the
+// fallback if the file is too large for the primary one. The fallback
+// transcribes without diarization, which local transcription cannot do:
+// the speakers of that chunk are not labeled.
+func (f *FallbackTranscriber) Transcribe(ctx context.Context, audioPath string, opts Options) (string, error) {
+	text, err := f.primary.Transcribe(ctx, audioPath, opts)
+	if !errors.Is(err, audio.ErrChunkTooLarge) {
+		return text, err
+	}
+	if f.onFallback != nil {
+		f.onFallback(audioPath, err)
+	}
+	opts.Diarize = false
+	return f.fallback.Transcribe(ctx, audioPath, opts)
+}
+
+// LastUpload returns the statistics of the most recent upload of the primary
+// transcriber, if it measures them, so that --parallel auto still sizes
+// parallelism to the connection.
+func (f *FallbackTranscriber) LastUpload() (UploadStats, bool) {
+	if m, ok := f.primary.(uploadMeter); ok {
+		return m.LastUpload()
+	}
+	return UploadStats{}, false
+}
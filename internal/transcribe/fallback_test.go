@@ -0,0 +1,106 @@
+package transcribe_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// funcTranscriber is a Transcriber calling fn.
+type funcTranscriber func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error)
+
+func (f funcTranscriber) Transcribe(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+	return f(ctx, audioPath, opts)
+}
+
+func TestFallbackTranscriber(t *testing.T) {
+	t.Parallel()
+
+	primary := funcTranscriber(func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		switch audioPath {
+		case "large.ogg":
+			return "", fmt.Errorf("large.ogg is 30.0 MB: %w", audio.ErrChunkTooLarge)
+		case "broken.ogg":
+			return "", errors.New("server error")
+		}
+		return "primary", nil
+	})
+	fallback := funcTranscriber(func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "fallback", nil
+	})
+
+	tests := []struct {
+		name         string
+		audioPath    string
+		want         string
+		wantErr      bool
+		wantFallback bool
+	}{
+		{name: "under the limit", audioPath: "small.ogg", want: "primary"},
+		{name: "too large", audioPath: "large.ogg", want: "fallback", wantFallback: true},
+		{name: "other error", audioPath: "broken.ogg", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var switched string
+			tr := transcribe.NewFallbackTranscriber(primary, fallback, func(audioPath string, err error) {
+				if !errors.Is(err, audio.ErrChunkTooLarge) {
+					t.Errorf("onFallback() error = %v, want ErrChunkTooLarge", err)
+				}
+				switched = audioPath
+			})
+
+			got, err := tr.Transcribe(context.Background(), tt.audioPath, transcribe.Options{})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Transcribe(%q) = %q, want error", tt.audioPath, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Transcribe(%q) = %q, %v, want %q", tt.audioPath, got, err, tt.want)
+			}
+			if (switched != "") != tt.wantFallback {
+				t.Errorf("onFallback called with %q, want called: %v", switched, tt.wantFallback)
+			}
+		})
+	}
+
+	t.Run("fallback without diarization", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transcribe.NewFallbackTranscriber(primary, funcTranscriber(func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			if opts.Diarize || !opts.Timestamps {
+				t.Errorf("fallback opts = %+v, want timestamps without diarization", opts)
+			}
+			return "fallback", nil
+		}), nil)
+
+		if _, err := tr.Transcribe(context.Background(), "large.ogg", transcribe.Options{Diarize: true, Timestamps: true}); err != nil {
+			t.Errorf("Transcribe() unexpected error: %v", err)
+		}
+	})
+}
+
+func TestFallbackTranscriber_LastUpload(t *testing.T) {
+	t.Parallel()
+
+	stats := transcribe.UploadStats{Bytes: 1 << 20, Duration: time.Second}
+	tr := transcribe.NewFallbackTranscriber(&meteredTranscriber{stats: stats}, funcTranscriber(nil), nil)
+	if got, ok := tr.LastUpload(); !ok || got != stats {
+		t.Errorf("LastUpload() = %+v, %v, want the stats of the primary transcriber", got, ok)
+	}
+
+	tr = transcribe.NewFallbackTranscriber(funcTranscriber(nil), funcTranscriber(nil), nil)
+	if _, ok := tr.LastUpload(); ok {
+		t.Error("LastUpload() ok = true, want false for a primary transcriber without measurements")
+	}
+}
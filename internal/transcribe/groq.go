@@ -0,0 +1,29 @@
+package transcribe
+
+// Groq API identifiers.
+const (
+	// ModelGroqWhisperLargeV3 is the Groq transcription model. It returns
+	// segment timestamps, but cannot identify speakers.
+	ModelGroqWhisperLargeV3 = "whisper-large-v3"
+
+	// GroqMaxFileSize is the largest audio file the Groq API accepts (25MB).
+	GroqMaxFileSize = 25 * 1024 * 1024
+
+	// defaultGroqBaseURL is the base URL of the OpenAI-compatible Groq API.
+	defaultGroqBaseURL = "https://api.groq.com/openai"
+)
+
+// NewGroqTranscriber creates a transcriber calling the Groq API, which is
+// compatible with the OpenAI transcription API: opts are those of
+// NewOpenAITranscriber. Every request uses ModelGroqWhisperLargeV3; requests
+// with Options.Diarize fail with ErrDiarizeUnsupported.
+func NewGroqTranscriber(apiKey string, opts ...TranscriberOption) *OpenAITranscriber {
+	t := NewOpenAITranscriber(apiKey, WithBaseURL(defaultGroqBaseURL))
+	t.service = "Groq"
+	t.model = ModelGroqWhisperLargeV3
+	t.maxSize = GroqMaxFileSize
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
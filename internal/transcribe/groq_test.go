@@ -0,0 +1,85 @@
+package transcribe_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestGroqTranscriber(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		opts       transcribe.Options
+		response   map[string]any
+		wantFormat string
+		wantText   string
+	}{
+		{
+			name:       "plain text",
+			response:   map[string]any{"text": "Bonjour à tous"},
+			wantFormat: "json",
+			wantText:   "Bonjour à tous",
+		},
+		{
+			name: "timestamps",
+			opts: transcribe.Options{Timestamps: true},
+			response: map[string]any{"text": "Bonjour", "segments": []map[string]any{
+				{"start": 0.0, "end": 1.5, "text": "Bonjour"},
+			}},
+			wantFormat: transcribe.FormatVerboseJSON,
+			wantText:   `[{"start":0,"end":1.5,"text":"Bonjour"}]`,
+		},
+		{
+			name:       "translation",
+			opts:       transcribe.Options{Translate: true},
+			response:   map[string]any{"text": "Hello everyone"},
+			wantFormat: "json",
+			wantText:   "Hello everyone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newMockOpenAIServer()
+			t.Cleanup(server.Close)
+			server.addResponse(http.StatusOK, tt.response)
+
+			tr := transcribe.NewGroqTranscriber("gsk-test", transcribe.WithBaseURL(server.URL))
+			got, err := tr.Transcribe(context.Background(), createTempAudioFile(t), tt.opts)
+			if err != nil {
+				t.Fatalf("Transcribe() error = %v", err)
+			}
+			if got != tt.wantText {
+				t.Errorf("Transcribe() = %q, want %q", got, tt.wantText)
+			}
+			call := server.lastCall()
+			if call.Model != transcribe.ModelGroqWhisperLargeV3 || call.Format != tt.wantFormat {
+				t.Errorf("request model = %q, format = %q, want %q, %q",
+					call.Model, call.Format, transcribe.ModelGroqWhisperLargeV3, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestGroqTranscriber_DiarizeUnsupported(t *testing.T) {
+	t.Parallel()
+
+	server := newMockOpenAIServer()
+	t.Cleanup(server.Close)
+
+	tr := transcribe.NewGroqTranscriber("gsk-test", transcribe.WithBaseURL(server.URL))
+	_, err := tr.Transcribe(context.Background(), createTempAudioFile(t), transcribe.Options{Diarize: true})
+	if !errors.Is(err, transcribe.ErrDiarizeUnsupported) {
+		t.Errorf("Transcribe() error = %v, want ErrDiarizeUnsupported", err)
+	}
+	if server.callCount() != 0 {
+		t.Errorf("sent %d requests, want none", server.callCount())
+	}
+}
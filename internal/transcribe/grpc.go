@@ -0,0 +1,583 @@
+package transcribe
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/tlsconfig"
+)
+
+// grpcRecognizePath is the HTTP/2 path of the StreamingRecognize method of
+// the Recognizer service (docs/asr.proto).
+const grpcRecognizePath = "/transcript.asr.v1.Recognizer/StreamingRecognize"
+
+// DefaultGRPCTimeout is the default deadline of the transcription of a chunk
+// by a gRPC server.
+const DefaultGRPCTimeout = 5 * time.Minute
+
+// grpcAudioMessageSize is the size of the audio sent in each request message.
+const grpcAudioMessageSize = 64 << 10
+
+// grpcMaxTimeoutValue is the largest value of a grpc-timeout header (8 digits).
+const grpcMaxTimeoutValue = 99999999
+
+// gRPC status codes (https://grpc.github.io/grpc/core/md_doc_statuscodes.html).
+const (
+	grpcOK                 = 0
+	grpcUnknown            = 2
+	grpcInvalidArgument    = 3
+	grpcDeadlineExceeded   = 4
+	grpcNotFound           = 5
+	grpcPermissionDenied   = 7
+	grpcResourceExhausted  = 8
+	grpcFailedPrecondition = 9
+	grpcOutOfRange         = 11
+	grpcUnimplemented      = 12
+	grpcInternal           = 13
+	grpcUnavailable        = 14
+	grpcUnauthenticated    = 16
+)
+
+// Compile-time interface compliance check.
+var _ Transcriber = (*GRPCTranscriber)(nil)
+
+// GRPCTranscriber transcribes audio with an external speech recognition
+// server implementing the Recognizer service of docs/asr.proto, for in-house
+// ASR systems. The audio of each chunk is streamed to the server, which
+// answers with its transcript. Calls use TLS unless the transcriber is
+// plaintext, and have a deadline; transient failures are retried.
+//
+// The client speaks gRPC over the HTTP/2 support of net/http, and encodes
+// the few messages of the service itself.
+type GRPCTranscriber struct {
+	httpClient httpDoer
+	baseURL    string
+	tlsConfig  *tls.Config
+	plaintext  bool
+	timeout    time.Duration
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+// GRPCOption configures a GRPCTranscriber.
+type GRPCOption func(*GRPCTranscriber)
+
+// WithGRPCTLSConfig sets the TLS configuration of connections to the server
+// (default: the system trusted roots).
+func WithGRPCTLSConfig(cfg *tls.Config) GRPCOption {
+	return func(t *GRPCTranscriber) {
+		t.tlsConfig = cfg
+	}
+}
+
+// WithGRPCPlaintext connects to the server without TLS, for servers of a
+// trusted network.
+func WithGRPCPlaintext(plaintext bool) GRPCOption {
+	return func(t *GRPCTranscriber) {
+		t.plaintext = plaintext
+	}
+}
+
+// WithGRPCTimeout sets the deadline of the transcription of a chunk.
+// Zero keeps DefaultGRPCTimeout.
+func WithGRPCTimeout(d time.Duration) GRPCOption {
+	return func(t *GRPCTranscriber) {
+		if d > 0 {
+			t.timeout = d
+		}
+	}
+}
+
+// WithGRPCRetries sets the maximum number of retry attempts, and the base
+// and max delays of their exponential backoff.
+func WithGRPCRetries(n int, base, max time.Duration) GRPCOption {
+	return func(t *GRPCTranscriber) {
+		if n >= 0 {
+			t.maxRetries = n
+		}
+		if base > 0 {
+			t.baseDelay = base
+		}
+		if max > 0 {
+			t.maxDelay = max
+		}
+	}
+}
+
+// WithGRPCHTTPClient sets a custom HTTP client (for testing).
+// It must speak HTTP/2.
+func WithGRPCHTTPClient(c httpDoer) GRPCOption {
+	return func(t *GRPCTranscriber) {
+		t.httpClient = c
+	}
+}
+
+// NewGRPCTranscriber creates a GRPCTranscriber calling the server at
+// endpoint, a host:port address.
+func NewGRPCTranscriber(endpoint string, opts ...GRPCOption) (*GRPCTranscriber, error) {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil || host == "" || port == "" {
+		return nil, fmt.Errorf("invalid gRPC endpoint %q (expected host:port)", endpoint)
+	}
+
+	t := &GRPCTranscriber{
+		timeout:    DefaultGRPCTimeout,
+		maxRetries: defaultMaxRetries,
+		baseDelay:  defaultBaseDelay,
+		maxDelay:   defaultMaxDelay,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	scheme := "https"
+	if t.plaintext {
+		scheme = "http"
+	}
+	t.baseURL = scheme + "://" + net.JoinHostPort(host, port)
+	if t.httpClient == nil {
+		t.httpClient = &http.Client{Transport: newHTTP2Transport(t.tlsConfig, t.plaintext)}
+	}
+	return t, nil
+}
+
+// newHTTP2Transport returns a transport speaking HTTP/2 only: over TLS with
+// cfg, or in plaintext (h2c with prior knowledge), as gRPC requires.
+func newHTTP2Transport(cfg *tls.Config, plaintext bool) http.RoundTripper {
+	var protocols http.Protocols
+	if plaintext {
+		protocols.SetUnencryptedHTTP2(true)
+	} else {
+		protocols.SetHTTP2(true)
+	}
+	return tlsconfig.Wrap(&http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		TLSClientConfig:     cfg,
+		Protocols:           &protocols,
+		TLSHandshakeTimeout: 10 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+	})
+}
+
+// Transcribe streams the audio file to the server and returns its transcript.
+// It retries unavailable servers and exceeded deadlines with backoff.
+// The recognition config has no translation: Options.Translate fails with
+// ErrTranslateUnsupported.
+func (t *GRPCTranscriber) Transcribe(ctx context.Context, audioPath string, opts Options) (string, error) {
+	if opts.Translate {
+		return "", fmt.Errorf("gRPC: %w", ErrTranslateUnsupported)
+	}
+	cfg := apierr.RetryConfig{
+		MaxRetries: t.maxRetries,
+		BaseDelay:  t.baseDelay,
+		MaxDelay:   t.maxDelay,
+	}
+
+	// Like OpenAITranscriber: retries wait for the shared rate limiter again.
+	limiter := opts.limiter
+	timer := opts.timer
+	retry := false
+	return apierr.RetryWithBackoff(ctx, cfg, func() (string, error) {
+		if retry {
+			timer.retried()
+			if err := limiter.Wait(ctx, 0); err != nil {
+				return "", err
+			}
+		}
+		retry = true
+		resp, err := t.recognize(ctx, audioPath, opts)
+		if err != nil {
+			if errors.Is(err, apierr.ErrRateLimit) {
+				limiter.RateLimited(0)
+			}
+			return "", err
+		}
+		limiter.Succeeded()
+		return resp.result(opts)
+	}, isRetryableError)
+}
+
+// recognize performs one StreamingRecognize call.
+func (t *GRPCTranscriber) recognize(ctx context.Context, audioPath string, opts Options) (_ recognizeResponse, err error) {
+	file, err := os.Open(audioPath) // #nosec G304 -- audioPath is from internal chunking
+	if err != nil {
+		return recognizeResponse{}, fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	callCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	// The requests are written as the transport sends them.
+	body, w := io.Pipe()
+	defer func() { _ = body.Close() }()
+	config := encodeRecognitionConfig(audioPath, opts)
+	go func() { _ = w.CloseWithError(writeRecognizeRequests(w, config, file)) }()
+
+	req, err := http.NewRequestWithContext(callCtx, http.MethodPost, t.baseURL+grpcRecognizePath, body)
+	if err != nil {
+		return recognizeResponse{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	if deadline, ok := callCtx.Deadline(); ok {
+		req.Header.Set("Grpc-Timeout", grpcTimeout(time.Until(deadline)))
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return recognizeResponse{}, classifyGRPCTransportError(ctx, callCtx, t.timeout, err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close response body: %w", closeErr)
+		}
+	}()
+
+	// The trailers holding the status are only read with the whole body.
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return recognizeResponse{}, classifyGRPCTransportError(ctx, callCtx, t.timeout, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return recognizeResponse{}, classifyGRPCError(&grpcStatusError{
+			Code:    httpStatusToGRPC(resp.StatusCode),
+			Message: fmt.Sprintf("HTTP %d", resp.StatusCode),
+		})
+	}
+	if statusErr := grpcStatus(resp); statusErr != nil {
+		return recognizeResponse{}, classifyGRPCError(statusErr)
+	}
+
+	msg, err := decodeGRPCFrame(data)
+	if err != nil {
+		return recognizeResponse{}, err
+	}
+	return decodeRecognizeResponse(msg)
+}
+
+// writeRecognizeRequests writes the requests of a call to w: the config,
+// then the audio read from r.
+func writeRecognizeRequests(w io.Writer, config []byte, r io.Reader) error {
+	if _, err := w.Write(grpcFrame(appendBytesField(nil, 1, config))); err != nil {
+		return err
+	}
+	buf := make([]byte, grpcAudioMessageSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if _, werr := w.Write(grpcFrame(appendBytesField(nil, 2, buf[:n]))); werr != nil {
+				return werr
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read audio file: %w", err)
+		}
+	}
+}
+
+// grpcTimeout formats d as the value of a grpc-timeout header.
+func grpcTimeout(d time.Duration) string {
+	ms := max(d.Milliseconds(), 1)
+	if ms <= grpcMaxTimeoutValue {
+		return strconv.FormatInt(ms, 10) + "m"
+	}
+	return strconv.FormatInt(min(int64(d.Seconds()), grpcMaxTimeoutValue), 10) + "S"
+}
+
+// grpcStatusError is a call that ended with a gRPC status other than OK.
+type grpcStatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *grpcStatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("gRPC status %d", e.Code)
+	}
+	return fmt.Sprintf("gRPC status %d: %s", e.Code, e.Message)
+}
+
+// grpcStatus returns the status of a call read to the end, from its trailers
+// or, for responses without a body, its headers. Returns nil if the call succeeded.
+func grpcStatus(resp *http.Response) *grpcStatusError {
+	status, message := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, message = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if status == "" {
+		return &grpcStatusError{Code: grpcInternal, Message: "response without grpc-status"}
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return &grpcStatusError{Code: grpcUnknown, Message: fmt.Sprintf("invalid grpc-status %q", status)}
+	}
+	if code == grpcOK {
+		return nil
+	}
+	if decoded, err := url.PathUnescape(message); err == nil {
+		message = decoded // grpc-message is percent-encoded
+	}
+	return &grpcStatusError{Code: code, Message: message}
+}
+
+// httpStatusToGRPC maps the HTTP status of a response that is not a gRPC
+// response, e.g. from a proxy, to a gRPC status code.
+func httpStatusToGRPC(status int) int {
+	switch status {
+	case http.StatusBadRequest:
+		return grpcInternal
+	case http.StatusUnauthorized:
+		return grpcUnauthenticated
+	case http.StatusForbidden:
+		return grpcPermissionDenied
+	case http.StatusNotFound:
+		return grpcUnimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return grpcUnavailable
+	default:
+		return grpcUnknown
+	}
+}
+
+// classifyGRPCError maps gRPC status codes to apierr sentinel errors.
+func classifyGRPCError(err *grpcStatusError) error {
+	switch err.Code {
+	case grpcUnavailable, grpcDeadlineExceeded:
+		return fmt.Errorf("%w: %w", err, apierr.ErrTimeout) // Retryable
+	case grpcResourceExhausted:
+		return fmt.Errorf("%w: %w", err, apierr.ErrRateLimit)
+	case grpcUnauthenticated, grpcPermissionDenied:
+		return fmt.Errorf("%w: %w", err, apierr.ErrAuthFailed)
+	case grpcInvalidArgument, grpcNotFound, grpcFailedPrecondition, grpcOutOfRange, grpcUnimplemented:
+		return fmt.Errorf("%w: %w", err, apierr.ErrBadRequest)
+	}
+	return err
+}
+
+// classifyGRPCTransportError reports a call that failed before a status was
+// received. A call over its own deadline timed out and is retried; a
+// cancelled run is not.
+func classifyGRPCTransportError(ctx, callCtx context.Context, timeout time.Duration, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("no gRPC response within %s: %w", timeout, apierr.ErrTimeout)
+	}
+	return fmt.Errorf("gRPC call failed: %w", err)
+}
+
+// grpcFrame returns msg as a gRPC length-prefixed message, uncompressed.
+func grpcFrame(msg []byte) []byte {
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg))) // #nosec G115 -- messages are at most 64 KiB
+	return append(frame, msg...)
+}
+
+// decodeGRPCFrame returns the message of a response body holding a single
+// gRPC length-prefixed message.
+func decodeGRPCFrame(data []byte) ([]byte, error) {
+	if len(data) < 5 {
+		return nil, errors.New("invalid gRPC response: no message")
+	}
+	if data[0] != 0 {
+		return nil, errors.New("invalid gRPC response: compressed message")
+	}
+	n := binary.BigEndian.Uint32(data[1:5])
+	if uint64(n) != uint64(len(data)-5) {
+		return nil, fmt.Errorf("invalid gRPC response: message of %d bytes in %d", n, len(data)-5)
+	}
+	return data[5:], nil
+}
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// encodeRecognitionConfig encodes the RecognitionConfig of a call.
+func encodeRecognitionConfig(audioPath string, opts Options) []byte {
+	var b []byte
+	b = appendStringField(b, 1, strings.TrimPrefix(strings.ToLower(filepath.Ext(audioPath)), "."))
+	b = appendStringField(b, 2, opts.Language.String())
+	b = appendStringField(b, 3, opts.Prompt)
+	b = appendBoolField(b, 4, opts.Diarize)
+	b = appendBoolField(b, 5, opts.Timestamps)
+	return b
+}
+
+func appendBytesField(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendStringField appends a string field, omitted if empty as in proto3.
+func appendStringField(b []byte, field int, v string) []byte {
+	if v == "" {
+		return b
+	}
+	return appendBytesField(b, field, []byte(v))
+}
+
+// appendBoolField appends a bool field, omitted if false as in proto3.
+func appendBoolField(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(field)<<3|wireVarint)
+	return append(b, 1)
+}
+
+// protoField is a decoded protobuf field: num and, depending on its wire
+// type, the integer value or the bytes.
+type protoField struct {
+	num   int
+	wire  int
+	value uint64
+	bytes []byte
+}
+
+// decodeProtoFields decodes the fields of the protobuf message m.
+func decodeProtoFields(m []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(m) > 0 {
+		tag, n := binary.Uvarint(m)
+		if n <= 0 || tag>>3 == 0 || tag>>3 > math.MaxInt32 {
+			return nil, errors.New("invalid gRPC response: invalid field tag")
+		}
+		m = m[n:]
+		f := protoField{num: int(tag >> 3), wire: int(tag & 7)}
+		switch f.wire {
+		case wireVarint:
+			f.value, n = binary.Uvarint(m)
+			if n <= 0 {
+				return nil, errors.New("invalid gRPC response: truncated varint")
+			}
+			m = m[n:]
+		case wireFixed64:
+			if len(m) < 8 {
+				return nil, errors.New("invalid gRPC response: truncated fixed64")
+			}
+			f.value, m = binary.LittleEndian.Uint64(m), m[8:]
+		case wireFixed32:
+			if len(m) < 4 {
+				return nil, errors.New("invalid gRPC response: truncated fixed32")
+			}
+			f.value, m = uint64(binary.LittleEndian.Uint32(m)), m[4:]
+		case wireBytes:
+			size, n := binary.Uvarint(m)
+			if n <= 0 || size > uint64(len(m)-n) {
+				return nil, errors.New("invalid gRPC response: truncated field")
+			}
+			f.bytes, m = m[n:n+int(size)], m[n+int(size):] // #nosec G115 -- size <= len(m)
+		default:
+			return nil, fmt.Errorf("invalid gRPC response: unsupported wire type %d", f.wire)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// recognizeResponse is a decoded RecognizeResponse.
+type recognizeResponse struct {
+	text     string
+	segments []TimedSegment
+}
+
+// decodeRecognizeResponse decodes a RecognizeResponse. Unknown fields are
+// skipped, so that servers can extend the service.
+func decodeRecognizeResponse(m []byte) (recognizeResponse, error) {
+	fields, err := decodeProtoFields(m)
+	if err != nil {
+		return recognizeResponse{}, err
+	}
+	var resp recognizeResponse
+	for _, f := range fields {
+		switch {
+		case f.num == 1 && f.wire == wireBytes:
+			resp.text = string(f.bytes)
+		case f.num == 2 && f.wire == wireBytes:
+			seg, err := decodeSegment(f.bytes)
+			if err != nil {
+				return recognizeResponse{}, err
+			}
+			resp.segments = append(resp.segments, seg)
+		}
+	}
+	return resp, nil
+}
+
+// decodeSegment decodes a Segment.
+func decodeSegment(m []byte) (TimedSegment, error) {
+	fields, err := decodeProtoFields(m)
+	if err != nil {
+		return TimedSegment{}, err
+	}
+	var seg TimedSegment
+	for _, f := range fields {
+		switch {
+		case f.num == 1 && f.wire == wireFixed64:
+			seg.Start = seconds(math.Float64frombits(f.value))
+		case f.num == 2 && f.wire == wireFixed64:
+			seg.End = seconds(math.Float64frombits(f.value))
+		case f.num == 3 && f.wire == wireBytes:
+			seg.Speaker = string(f.bytes)
+		case f.num == 4 && f.wire == wireBytes:
+			seg.Text = string(f.bytes)
+		}
+	}
+	return seg, nil
+}
+
+// result returns the transcript of r in the format requested by opts, like
+// OpenAITranscriber: encoded segments for timestamps, speaker-labeled lines
+// when diarized, plain text otherwise.
+func (r recognizeResponse) result(opts Options) (string, error) {
+	switch {
+	case opts.Timestamps:
+		if len(r.segments) == 0 && strings.TrimSpace(r.text) != "" {
+			return "", errors.New("invalid gRPC response: timestamps requested but no segments returned")
+		}
+		return EncodeSegments(r.segments)
+	case opts.Diarize && len(r.segments) > 0:
+		var b strings.Builder
+		for _, seg := range r.segments {
+			text := strings.TrimSpace(seg.Text)
+			if seg.Speaker != "" {
+				text = "[" + seg.Speaker + "] " + text
+			}
+			b.WriteString(text + "\n")
+		}
+		return strings.TrimSpace(b.String()), nil
+	case r.text == "" && len(r.segments) > 0:
+		texts := make([]string, 0, len(r.segments))
+		for _, seg := range r.segments {
+			texts = append(texts, strings.TrimSpace(seg.Text))
+		}
+		return strings.Join(texts, " "), nil
+	}
+	return r.text, nil
+}
@@ -0,0 +1,337 @@
+package transcribe_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/tlsconfig"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// grpcHandler answers a StreamingRecognize call of a test server: it returns
+// the response message and the gRPC status to send.
+type grpcHandler func(t *testing.T, r *http.Request, req transcribe.GRPCRequest) (response []byte, status string)
+
+// newGRPCServer starts an HTTP/2 test server running handle, with TLS unless
+// plaintext. Returns the server endpoint and its certificate pool.
+func newGRPCServer(t *testing.T, plaintext bool, handle grpcHandler) (endpoint string, roots *x509.CertPool) {
+	t.Helper()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/transcript.asr.v1.Recognizer/StreamingRecognize" || r.ProtoMajor != 2 {
+			t.Errorf("request = %s %s, want HTTP/2 StreamingRecognize", r.Proto, r.URL.Path)
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read request: %v", err)
+		}
+		req, err := transcribe.DecodeGRPCRequests(body)
+		if err != nil {
+			t.Errorf("invalid request: %v", err)
+		}
+		response, status := handle(t, r, req)
+
+		w.Header().Set("Content-Type", "application/grpc")
+		if response == nil {
+			// Trailers-only response.
+			w.Header().Set("Grpc-Status", status)
+			w.Header().Set("Grpc-Message", "server%20says%20no")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(response)
+		w.Header().Set("Grpc-Status", status)
+	}))
+	if plaintext {
+		srv.Config.Protocols = new(http.Protocols)
+		srv.Config.Protocols.SetUnencryptedHTTP2(true)
+		srv.Start()
+	} else {
+		srv.EnableHTTP2 = true
+		srv.StartTLS()
+		roots = x509.NewCertPool()
+		roots.AddCert(srv.Certificate())
+	}
+	t.Cleanup(srv.Close)
+	return srv.Listener.Addr().String(), roots
+}
+
+// newTestGRPCTranscriber creates a GRPCTranscriber calling endpoint, trusting
+// roots (plaintext if nil), without retry delays.
+func newTestGRPCTranscriber(t *testing.T, endpoint string, roots *x509.CertPool, opts ...transcribe.GRPCOption) *transcribe.GRPCTranscriber {
+	t.Helper()
+
+	opts = append([]transcribe.GRPCOption{
+		transcribe.WithGRPCPlaintext(roots == nil),
+		transcribe.WithGRPCTLSConfig(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}),
+		transcribe.WithGRPCRetries(0, time.Millisecond, time.Millisecond),
+	}, opts...)
+	tr, err := transcribe.NewGRPCTranscriber(endpoint, opts...)
+	if err != nil {
+		t.Fatalf("NewGRPCTranscriber() unexpected error: %v", err)
+	}
+	return tr
+}
+
+// writeAudio writes an audio file of size bytes.
+func writeAudio(t *testing.T, size int) (path string, content []byte) {
+	t.Helper()
+
+	content = make([]byte, size)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	path = filepath.Join(t.TempDir(), "chunk_000.ogg")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path, content
+}
+
+func TestGRPCTranscriber_Transcribe(t *testing.T) {
+	t.Parallel()
+
+	audioPath, content := writeAudio(t, 150_000) // Three audio messages
+	segments := []transcribe.TimedSegment{
+		{Start: 0, End: 1500 * time.Millisecond, Speaker: "Alice", Text: "Hello."},
+		{Start: 1500 * time.Millisecond, End: 3 * time.Second, Speaker: "Bob", Text: "Hi there."},
+	}
+
+	tests := []struct {
+		name      string
+		plaintext bool
+		opts      transcribe.Options
+		response  []byte
+		want      string
+	}{
+		{
+			name:     "text",
+			opts:     transcribe.Options{Language: lang.MustParse("pt-BR"), Prompt: "Kubernetes"},
+			response: transcribe.EncodeGRPCResponse("Olá.", nil),
+			want:     "Olá.",
+		},
+		{
+			name:      "plaintext",
+			plaintext: true,
+			response:  transcribe.EncodeGRPCResponse("Hello.", nil),
+			want:      "Hello.",
+		},
+		{
+			name:     "diarized",
+			opts:     transcribe.Options{Diarize: true},
+			response: transcribe.EncodeGRPCResponse("Hello. Hi there.", segments),
+			want:     "[Alice] Hello.\n[Bob] Hi there.",
+		},
+		{
+			name:     "text of the segments",
+			response: transcribe.EncodeGRPCResponse("", segments),
+			want:     "Hello. Hi there.",
+		},
+		{
+			name:     "timestamps",
+			opts:     transcribe.Options{Timestamps: true},
+			response: transcribe.EncodeGRPCResponse("", segments),
+			want:     `[{"start":0,"end":1.5,"speaker":"Alice","text":"Hello."},{"start":1.5,"end":3,"speaker":"Bob","text":"Hi there."}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			endpoint, roots := newGRPCServer(t, tt.plaintext, func(t *testing.T, r *http.Request, req transcribe.GRPCRequest) ([]byte, string) {
+				if r.Header.Get("Content-Type") != "application/grpc" || r.Header.Get("Te") != "trailers" {
+					t.Errorf("headers = %v, want a gRPC request", r.Header)
+				}
+				if !strings.HasSuffix(r.Header.Get("Grpc-Timeout"), "m") {
+					t.Errorf("grpc-timeout = %q, want the deadline in milliseconds", r.Header.Get("Grpc-Timeout"))
+				}
+				if req.AudioFormat != "ogg" || req.Language != tt.opts.Language.String() || req.Prompt != tt.opts.Prompt ||
+					req.Diarize != tt.opts.Diarize || req.Timestamps != tt.opts.Timestamps {
+					t.Errorf("config = %+v, want the options %+v", req, tt.opts)
+				}
+				if string(req.Audio) != string(content) || req.Messages != 3 {
+					t.Errorf("audio = %d bytes in %d messages, want the %d bytes of the file in 3", len(req.Audio), req.Messages, len(content))
+				}
+				return tt.response, "0"
+			})
+			tr := newTestGRPCTranscriber(t, endpoint, roots)
+
+			got, err := tr.Transcribe(context.Background(), audioPath, tt.opts)
+			if err != nil {
+				t.Fatalf("Transcribe() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Transcribe() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGRPCTranscriber_Status(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		status   string
+		trailers bool // Status in trailers, after an empty response
+		wantErr  error
+	}{
+		{name: "resource exhausted", status: "8", wantErr: apierr.ErrRateLimit},
+		{name: "unauthenticated", status: "16", wantErr: apierr.ErrAuthFailed},
+		{name: "invalid argument", status: "3", wantErr: apierr.ErrBadRequest},
+		{name: "unavailable", status: "14", wantErr: apierr.ErrTimeout},
+		{name: "status in trailers", status: "7", trailers: true, wantErr: apierr.ErrAuthFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			audioPath, _ := writeAudio(t, 10)
+			endpoint, roots := newGRPCServer(t, false, func(*testing.T, *http.Request, transcribe.GRPCRequest) ([]byte, string) {
+				if tt.trailers {
+					return []byte{}, tt.status
+				}
+				return nil, tt.status
+			})
+			tr := newTestGRPCTranscriber(t, endpoint, roots)
+
+			_, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Transcribe() error = %v, want %v", err, tt.wantErr)
+			}
+			if !tt.trailers && !strings.Contains(err.Error(), "server says no") {
+				t.Errorf("Transcribe() error = %v, want the decoded grpc-message", err)
+			}
+		})
+	}
+}
+
+func TestGRPCTranscriber_RetriesUnavailable(t *testing.T) {
+	t.Parallel()
+
+	audioPath, _ := writeAudio(t, 10)
+	var calls atomic.Int32
+	endpoint, roots := newGRPCServer(t, false, func(*testing.T, *http.Request, transcribe.GRPCRequest) ([]byte, string) {
+		if calls.Add(1) == 1 {
+			return nil, "14"
+		}
+		return transcribe.EncodeGRPCResponse("Hello.", nil), "0"
+	})
+	tr := newTestGRPCTranscriber(t, endpoint, roots, transcribe.WithGRPCRetries(2, time.Millisecond, time.Millisecond))
+
+	got, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{})
+	if err != nil || got != "Hello." {
+		t.Errorf("Transcribe() = %q, %v, want the transcript of the retry", got, err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestGRPCTranscriber_Deadline(t *testing.T) {
+	t.Parallel()
+
+	audioPath, _ := writeAudio(t, 10)
+	endpoint, roots := newGRPCServer(t, false, func(t *testing.T, r *http.Request, _ transcribe.GRPCRequest) ([]byte, string) {
+		<-r.Context().Done() // Never answers
+		return nil, "4"
+	})
+	tr := newTestGRPCTranscriber(t, endpoint, roots, transcribe.WithGRPCTimeout(50*time.Millisecond))
+
+	_, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{})
+	if !errors.Is(err, apierr.ErrTimeout) || !strings.Contains(err.Error(), "50ms") {
+		t.Errorf("Transcribe() error = %v, want ErrTimeout after the deadline", err)
+	}
+}
+
+func TestGRPCTranscriber_TimestampsWithoutSegments(t *testing.T) {
+	t.Parallel()
+
+	audioPath, _ := writeAudio(t, 10)
+	endpoint, roots := newGRPCServer(t, false, func(*testing.T, *http.Request, transcribe.GRPCRequest) ([]byte, string) {
+		return transcribe.EncodeGRPCResponse("Hello.", nil), "0"
+	})
+	tr := newTestGRPCTranscriber(t, endpoint, roots)
+
+	_, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{Timestamps: true})
+	if err == nil || !strings.Contains(err.Error(), "no segments") {
+		t.Errorf("Transcribe() error = %v, want an error for the missing segments", err)
+	}
+}
+
+func TestGRPCTranscriber_TranslateUnsupported(t *testing.T) {
+	t.Parallel()
+
+	audioPath, _ := writeAudio(t, 10)
+	endpoint, roots := newGRPCServer(t, false, func(t *testing.T, _ *http.Request, _ transcribe.GRPCRequest) ([]byte, string) {
+		t.Error("request sent, want none")
+		return transcribe.EncodeGRPCResponse("Hello.", nil), "0"
+	})
+	tr := newTestGRPCTranscriber(t, endpoint, roots)
+
+	if _, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{Translate: true}); !errors.Is(err, transcribe.ErrTranslateUnsupported) {
+		t.Errorf("Transcribe() error = %v, want ErrTranslateUnsupported", err)
+	}
+}
+
+func TestGRPCTranscriber_UntrustedServer(t *testing.T) {
+	t.Parallel()
+
+	audioPath, _ := writeAudio(t, 10)
+	endpoint, _ := newGRPCServer(t, false, func(*testing.T, *http.Request, transcribe.GRPCRequest) ([]byte, string) {
+		return transcribe.EncodeGRPCResponse("Hello.", nil), "0"
+	})
+	tr, err := transcribe.NewGRPCTranscriber(endpoint, transcribe.WithGRPCRetries(0, time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewGRPCTranscriber() unexpected error: %v", err)
+	}
+
+	if _, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{}); !errors.Is(err, tlsconfig.ErrUntrusted) {
+		t.Errorf("Transcribe() error = %v, want ErrUntrusted", err)
+	}
+}
+
+func TestNewGRPCTranscriber_InvalidEndpoint(t *testing.T) {
+	t.Parallel()
+
+	for _, endpoint := range []string{"", "asr.internal", ":50051", "asr.internal:"} {
+		if _, err := transcribe.NewGRPCTranscriber(endpoint); err == nil {
+			t.Errorf("NewGRPCTranscriber(%q) error = nil, want error", endpoint)
+		}
+	}
+}
+
+func TestGRPCTimeout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 5 * time.Minute, want: "300000m"},
+		{d: 0, want: "1m"},
+		{d: 48 * time.Hour, want: "172800S"},
+	}
+	for _, tt := range tests {
+		if got := transcribe.GRPCTimeout(tt.d); got != tt.want {
+			t.Errorf("GRPCTimeout(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
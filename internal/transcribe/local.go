@@ -0,0 +1,266 @@
+package transcribe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// whisperBinaries are the names of the whisper.cpp command-line tool, newest first.
+// The tool was renamed from "main" to "whisper-cli"; packages also ship "whisper-cpp".
+var whisperBinaries = []string{"whisper-cli", "whisper-cpp"}
+
+// whisperSampleRate is the only sample rate whisper.cpp accepts.
+const whisperSampleRate = "16000"
+
+// commandRunner executes external commands and returns their combined output.
+type commandRunner interface {
+	CombinedOutput(ctx context.Context, name string, args []string) ([]byte, error)
+}
+
+// osCommandRunner implements commandRunner using exec.CommandContext.
+type osCommandRunner struct{}
+
+func (osCommandRunner) CombinedOutput(ctx context.Context, name string, args []string) ([]byte, error) {
+	// #nosec G204 -- binaries are resolved from configuration, args are built here
+	cmd := exec.CommandContext(ctx, name, args...)
+	return cmd.CombinedOutput()
+}
+
+// Compile-time interface compliance check.
+var _ Transcriber = (*LocalTranscriber)(nil)
+
+// LocalTranscriber transcribes audio offline with whisper.cpp.
+// Each chunk is converted to 16 kHz mono WAV with FFmpeg (the only input
+// format whisper.cpp reads), then transcribed by the whisper.cpp CLI.
+// No API key or network access is needed.
+type LocalTranscriber struct {
+	runner     commandRunner
+	binaryPath string
+	modelPath  string
+	ffmpegPath string
+	threads    int
+}
+
+// LocalTranscriberOption configures a LocalTranscriber.
+type LocalTranscriberOption func(*LocalTranscriber)
+
+// WithWhisperBinary sets the whisper.cpp binary path.
+// By default, whisper-cli (or whisper-cpp) is looked up in PATH.
+func WithWhisperBinary(path string) LocalTranscriberOption {
+	return func(t *LocalTranscriber) {
+		t.binaryPath = path
+	}
+}
+
+// WithWhisperThreads sets the number of CPU threads whisper.cpp uses.
+// Zero keeps the whisper.cpp default.
+func WithWhisperThreads(n int) LocalTranscriberOption {
+	return func(t *LocalTranscriber) {
+		if n >= 0 {
+			t.threads = n
+		}
+	}
+}
+
+// withCommandRunner sets a custom command runner (for testing).
+func withCommandRunner(r commandRunner) LocalTranscriberOption {
+	return func(t *LocalTranscriber) {
+		t.runner = r
+	}
+}
+
+// NewLocalTranscriber creates a LocalTranscriber using the whisper.cpp model
+// at modelPath (a ggml .bin file) and FFmpeg at ffmpegPath.
+// Returns ErrModelNotFound if the model does not exist, and ErrWhisperNotFound
+// if no binary was given and none is found in PATH.
+func NewLocalTranscriber(modelPath, ffmpegPath string, opts ...LocalTranscriberOption) (*LocalTranscriber, error) {
+	t := &LocalTranscriber{
+		runner:     osCommandRunner{},
+		modelPath:  modelPath,
+		ffmpegPath: ffmpegPath,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if modelPath == "" {
+		return nil, fmt.Errorf("%w: no model configured", ErrModelNotFound)
+	}
+	if _, err := os.Stat(modelPath); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, modelPath)
+	}
+
+	if t.binaryPath == "" {
+		for _, name := range whisperBinaries {
+			if path, err := exec.LookPath(name); err == nil {
+				t.binaryPath = path
+				break
+			}
+		}
+		if t.binaryPath == "" {
+			return nil, fmt.Errorf("%w (looked for %s in PATH)", ErrWhisperNotFound, strings.Join(whisperBinaries, ", "))
+		}
+	} else if _, err := os.Stat(t.binaryPath); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrWhisperNotFound, t.binaryPath)
+	}
+
+	return t, nil
+}
+
+// Transcribe transcribes an audio file with whisper.cpp.
+// Diarization is not supported (ErrDiarizeUnsupported).
+// With Options.Timestamps, the segments of whisper.cpp are returned as is.
+// With Options.Translate, whisper.cpp translates the speech to English.
+func (t *LocalTranscriber) Transcribe(ctx context.Context, audioPath string, opts Options) (string, error) {
+	if opts.Diarize {
+		return "", ErrDiarizeUnsupported
+	}
+
+	tempDir, err := os.MkdirTemp("", "go-transcript-whisper-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	// Convert to the input format whisper.cpp expects.
+	wavPath := filepath.Join(tempDir, "audio.wav")
+	convertArgs := []string{
+		"-y", "-hide_banner", "-loglevel", "error",
+		"-i", audioPath,
+		"-ar", whisperSampleRate, "-ac", "1", "-c:a", "pcm_s16le",
+		wavPath,
+	}
+	if out, err := t.runner.CombinedOutput(ctx, t.ffmpegPath, convertArgs); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("failed to convert %s for whisper.cpp: %w\nOutput: %s", audioPath, err, out)
+	}
+
+	// Transcribe; the result is written to <outBase>.txt (or .json with timestamps).
+	outBase := filepath.Join(tempDir, "transcript")
+	if out, err := t.runner.CombinedOutput(ctx, t.binaryPath, t.whisperArgs(wavPath, outBase, opts)); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("whisper.cpp failed: %w\nOutput: %s", err, out)
+	}
+
+	ext := ".txt"
+	if opts.Timestamps {
+		ext = ".json"
+	}
+	data, err := os.ReadFile(outBase + ext) // #nosec G304 -- file created in our temp dir
+	if err != nil {
+		return "", fmt.Errorf("whisper.cpp produced no transcript: %w", err)
+	}
+	if opts.Timestamps {
+		return parseWhisperJSON(data)
+	}
+	return joinSegments(string(data)), nil
+}
+
+// whisperArgs builds the whisper.cpp command line.
+func (t *LocalTranscriber) whisperArgs(wavPath, outBase string, opts Options) []string {
+	language := "auto"
+	if !opts.Language.IsZero() {
+		language = opts.Language.BaseCode() // whisper.cpp has no regional variants
+	}
+
+	args := []string{
+		"-m", t.modelPath,
+		"-f", wavPath,
+		"-l", language,
+		"-of", outBase,
+		"-np", // No progress or system info on the output
+	}
+	if opts.Timestamps {
+		args = append(args, "-oj", "-ojf") // Full JSON: with the probability of each token
+	} else {
+		args = append(args, "-otxt")
+	}
+	if t.threads > 0 {
+		args = append(args, "-t", fmt.Sprint(t.threads))
+	}
+	if opts.Prompt != "" {
+		args = append(args, "--prompt", opts.Prompt)
+	}
+	if opts.Translate {
+		args = append(args, "-tr")
+	}
+	return args
+}
+
+// joinSegments joins the one-segment-per-line text output of whisper.cpp
+// into a single paragraph, like the OpenAI API returns.
+func joinSegments(text string) string {
+	var segments []string
+	for line := range strings.SplitSeq(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			segments = append(segments, line)
+		}
+	}
+	return strings.Join(segments, " ")
+}
+
+// whisperJSON is the JSON output of whisper.cpp (-oj, with tokens with -ojf).
+// Offsets are in milliseconds from the start of the audio.
+type whisperJSON struct {
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"`
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text   string         `json:"text"`
+		Tokens []whisperToken `json:"tokens"`
+	} `json:"transcription"`
+}
+
+// whisperToken is a token of a segment of the full JSON output of whisper.cpp.
+type whisperToken struct {
+	Text string  `json:"text"`
+	P    float64 `json:"p"` // Probability
+}
+
+// tokensConfidence returns the mean probability of the text tokens, leaving
+// out special tokens like [_BEG_], or 0 without tokens.
+func tokensConfidence(tokens []whisperToken) float64 {
+	var sum float64
+	var n int
+	for _, t := range tokens {
+		if strings.HasPrefix(t.Text, "[_") {
+			continue
+		}
+		sum += t.P
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// parseWhisperJSON converts the JSON output of whisper.cpp into encoded timed segments.
+func parseWhisperJSON(data []byte) (string, error) {
+	var out whisperJSON
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("failed to parse whisper.cpp output: %w", err)
+	}
+
+	segments := make([]TimedSegment, len(out.Transcription))
+	for i, seg := range out.Transcription {
+		segments[i] = TimedSegment{
+			Start:      time.Duration(seg.Offsets.From) * time.Millisecond,
+			End:        time.Duration(seg.Offsets.To) * time.Millisecond,
+			Text:       seg.Text,
+			Confidence: tokensConfidence(seg.Tokens),
+		}
+	}
+	return EncodeSegments(segments)
+}
@@ -0,0 +1,293 @@
+package transcribe_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// ---------------------------------------------------------------------------
+// Test helpers
+// ---------------------------------------------------------------------------
+
+// fakeWhisperRunner simulates FFmpeg and whisper.cpp.
+// The whisper.cpp call writes output to the requested -of path.
+type fakeWhisperRunner struct {
+	mu     sync.Mutex
+	calls  [][]string // name followed by args
+	output string     // Text written by whisper.cpp
+	errFor string     // Binary that fails, if any
+}
+
+func (f *fakeWhisperRunner) CombinedOutput(ctx context.Context, name string, args []string) ([]byte, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, append([]string{name}, args...))
+	f.mu.Unlock()
+
+	if name == f.errFor {
+		return []byte("boom"), errors.New("exit status 1")
+	}
+	if i := slices.Index(args, "-of"); i >= 0 {
+		ext := ".txt"
+		if slices.Contains(args, "-oj") {
+			ext = ".json"
+		}
+		if err := os.WriteFile(args[i+1]+ext, []byte(f.output), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// createLocalTestFiles creates a fake model and whisper binary.
+func createLocalTestFiles(t *testing.T) (model, binary string) {
+	t.Helper()
+	dir := t.TempDir()
+	model = filepath.Join(dir, "ggml-base.bin")
+	binary = filepath.Join(dir, "whisper-cli")
+	for _, p := range []string{model, binary} {
+		if err := os.WriteFile(p, []byte("x"), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", p, err)
+		}
+	}
+	return model, binary
+}
+
+// argAfter returns the value following flag in args.
+func argAfter(args []string, flag string) string {
+	if i := slices.Index(args, flag); i >= 0 && i+1 < len(args) {
+		return args[i+1]
+	}
+	return ""
+}
+
+// ---------------------------------------------------------------------------
+// Tests for NewLocalTranscriber
+// ---------------------------------------------------------------------------
+
+func TestNewLocalTranscriber(t *testing.T) {
+	t.Parallel()
+
+	model, binary := createLocalTestFiles(t)
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	tests := []struct {
+		name    string
+		model   string
+		binary  string
+		wantErr error
+	}{
+		{"valid", model, binary, nil},
+		{"no model configured", "", binary, transcribe.ErrModelNotFound},
+		{"missing model", missing, binary, transcribe.ErrModelNotFound},
+		{"missing binary", model, missing, transcribe.ErrWhisperNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := transcribe.NewLocalTranscriber(tt.model, "ffmpeg", transcribe.WithWhisperBinary(tt.binary))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewLocalTranscriber() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests for LocalTranscriber.Transcribe
+// ---------------------------------------------------------------------------
+
+func TestLocalTranscriber_Transcribe(t *testing.T) {
+	t.Parallel()
+
+	t.Run("converts then transcribes", func(t *testing.T) {
+		t.Parallel()
+
+		model, binary := createLocalTestFiles(t)
+		runner := &fakeWhisperRunner{output: " Hello there.\n General Kenobi.\n\n"}
+		tr, err := transcribe.NewLocalTranscriber(model, "/usr/bin/ffmpeg",
+			transcribe.WithWhisperBinary(binary),
+			transcribe.WithWhisperThreads(4),
+			transcribe.WithCommandRunner(runner))
+		if err != nil {
+			t.Fatalf("NewLocalTranscriber() unexpected error: %v", err)
+		}
+
+		text, err := tr.Transcribe(context.Background(), "/audio/chunk.ogg", transcribe.Options{
+			Language: lang.MustParse("pt-BR"),
+			Prompt:   "Kubernetes",
+		})
+		if err != nil {
+			t.Fatalf("Transcribe() unexpected error: %v", err)
+		}
+		if text != "Hello there. General Kenobi." {
+			t.Errorf("Transcribe() = %q, want segments joined", text)
+		}
+
+		if len(runner.calls) != 2 {
+			t.Fatalf("commands run = %d, want 2 (ffmpeg, whisper.cpp)", len(runner.calls))
+		}
+		convert, whisper := runner.calls[0], runner.calls[1]
+		if convert[0] != "/usr/bin/ffmpeg" || argAfter(convert, "-i") != "/audio/chunk.ogg" || argAfter(convert, "-ar") != "16000" {
+			t.Errorf("ffmpeg call = %v, want 16 kHz conversion of the chunk", convert)
+		}
+		if wav := convert[len(convert)-1]; argAfter(whisper, "-f") != wav {
+			t.Errorf("whisper.cpp input = %q, want converted file %q", argAfter(whisper, "-f"), wav)
+		}
+		want := map[string]string{"-m": model, "-l": "pt", "-t": "4", "--prompt": "Kubernetes"}
+		for flag, value := range want {
+			if got := argAfter(whisper, flag); got != value {
+				t.Errorf("whisper.cpp %s = %q, want %q", flag, got, value)
+			}
+		}
+	})
+
+	t.Run("auto-detects language by default", func(t *testing.T) {
+		t.Parallel()
+
+		model, binary := createLocalTestFiles(t)
+		runner := &fakeWhisperRunner{output: "text"}
+		tr, _ := transcribe.NewLocalTranscriber(model, "ffmpeg",
+			transcribe.WithWhisperBinary(binary), transcribe.WithCommandRunner(runner))
+
+		if _, err := tr.Transcribe(context.Background(), "chunk.ogg", transcribe.Options{}); err != nil {
+			t.Fatalf("Transcribe() unexpected error: %v", err)
+		}
+		whisper := runner.calls[1]
+		if got := argAfter(whisper, "-l"); got != "auto" {
+			t.Errorf("whisper.cpp -l = %q, want auto", got)
+		}
+		if slices.Contains(whisper, "--prompt") || slices.Contains(whisper, "-t") {
+			t.Errorf("whisper.cpp args = %v, want no prompt or thread flags", whisper)
+		}
+	})
+
+	t.Run("translates to English", func(t *testing.T) {
+		t.Parallel()
+
+		model, binary := createLocalTestFiles(t)
+		runner := &fakeWhisperRunner{output: "Hello there."}
+		tr, _ := transcribe.NewLocalTranscriber(model, "ffmpeg",
+			transcribe.WithWhisperBinary(binary), transcribe.WithCommandRunner(runner))
+
+		if _, err := tr.Transcribe(context.Background(), "chunk.ogg", transcribe.Options{Translate: true}); err != nil {
+			t.Fatalf("Transcribe() unexpected error: %v", err)
+		}
+		if whisper := runner.calls[1]; !slices.Contains(whisper, "-tr") {
+			t.Errorf("whisper.cpp args = %v, want -tr", whisper)
+		}
+	})
+
+	t.Run("returns timed segments with timestamps", func(t *testing.T) {
+		t.Parallel()
+
+		model, binary := createLocalTestFiles(t)
+		runner := &fakeWhisperRunner{output: `{"transcription":[
+			{"offsets":{"from":0,"to":2500},"text":" Hello there.","tokens":[
+				{"text":"[_BEG_]","p":0.2},{"text":" Hello","p":0.9},{"text":" there.","p":0.7}]},
+			{"offsets":{"from":2500,"to":4000},"text":" General Kenobi."}]}`}
+		tr, _ := transcribe.NewLocalTranscriber(model, "ffmpeg",
+			transcribe.WithWhisperBinary(binary), transcribe.WithCommandRunner(runner))
+
+		got, err := tr.Transcribe(context.Background(), "chunk.ogg", transcribe.Options{Timestamps: true})
+		if err != nil {
+			t.Fatalf("Transcribe() unexpected error: %v", err)
+		}
+		if whisper := runner.calls[1]; !slices.Contains(whisper, "-oj") || !slices.Contains(whisper, "-ojf") || slices.Contains(whisper, "-otxt") {
+			t.Errorf("whisper.cpp args = %v, want full JSON output", whisper)
+		}
+		segments, err := transcribe.ParseSegments(got)
+		if err != nil {
+			t.Fatalf("ParseSegments() unexpected error: %v", err)
+		}
+		want := []transcribe.TimedSegment{
+			{Start: 0, End: 2500 * time.Millisecond, Text: "Hello there.", Confidence: 0.8},
+			{Start: 2500 * time.Millisecond, End: 4 * time.Second, Text: "General Kenobi."},
+		}
+		if !slices.Equal(segments, want) {
+			t.Errorf("segments = %+v, want %+v", segments, want)
+		}
+	})
+
+	t.Run("rejects diarization", func(t *testing.T) {
+		t.Parallel()
+
+		model, binary := createLocalTestFiles(t)
+		runner := &fakeWhisperRunner{}
+		tr, _ := transcribe.NewLocalTranscriber(model, "ffmpeg",
+			transcribe.WithWhisperBinary(binary), transcribe.WithCommandRunner(runner))
+
+		_, err := tr.Transcribe(context.Background(), "chunk.ogg", transcribe.Options{Diarize: true})
+		if !errors.Is(err, transcribe.ErrDiarizeUnsupported) {
+			t.Errorf("Transcribe() error = %v, want ErrDiarizeUnsupported", err)
+		}
+		if len(runner.calls) != 0 {
+			t.Errorf("commands run = %d, want none", len(runner.calls))
+		}
+	})
+
+	t.Run("reports command failures", func(t *testing.T) {
+		t.Parallel()
+
+		model, binary := createLocalTestFiles(t)
+		for _, failing := range []string{"ffmpeg", binary} {
+			runner := &fakeWhisperRunner{errFor: failing}
+			tr, _ := transcribe.NewLocalTranscriber(model, "ffmpeg",
+				transcribe.WithWhisperBinary(binary), transcribe.WithCommandRunner(runner))
+
+			_, err := tr.Transcribe(context.Background(), "chunk.ogg", transcribe.Options{})
+			if err == nil || !strings.Contains(err.Error(), "boom") {
+				t.Errorf("Transcribe() with failing %s error = %v, want command output", failing, err)
+			}
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+
+		model, binary := createLocalTestFiles(t)
+		runner := &fakeWhisperRunner{errFor: "ffmpeg"}
+		tr, _ := transcribe.NewLocalTranscriber(model, "ffmpeg",
+			transcribe.WithWhisperBinary(binary), transcribe.WithCommandRunner(runner))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := tr.Transcribe(ctx, "chunk.ogg", transcribe.Options{}); !errors.Is(err, context.Canceled) {
+			t.Errorf("Transcribe() error = %v, want context.Canceled", err)
+		}
+	})
+}
+
+func TestJoinSegments(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"single line", "Hello.", "Hello."},
+		{"segments", " Hello.\n How are you?\n", "Hello. How are you?"},
+		{"blank lines", "\n\nA\n\n\nB\n", "A B"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := transcribe.JoinSegments(tt.input); got != tt.want {
+				t.Errorf("JoinSegments(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// ChunkError is the failure of one chunk.
+type ChunkError struct {
+	Chunk audio.Chunk
+	Err   error
+}
+
+// Error returns the error of the chunk, prefixed with its index and file name.
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("chunk %d (%s): %v", e.Chunk.Index, filepath.Base(e.Chunk.Path), e.Err)
+}
+
+// Unwrap returns the error of the chunk.
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// PartialError is returned by TranscribeRemaining, with the results of the
+// other chunks, when chunks still failed after being retried (see
+// WithAllowPartial). It wraps ErrPartial.
+type PartialError struct {
+	Failed []*ChunkError // In chunk order
+}
+
+// Error lists the failed chunks.
+func (e *PartialError) Error() string {
+	msgs := make([]string, len(e.Failed))
+	for i, f := range e.Failed {
+		msgs[i] = f.Error()
+	}
+	return fmt.Sprintf("%s: %s", ErrPartial, strings.Join(msgs, "; "))
+}
+
+// Unwrap returns ErrPartial. The errors of the chunks are not wrapped, so the
+// error is classified as partial rather than by the cause of one chunk.
+func (e *PartialError) Unwrap() error {
+	return ErrPartial
+}
+
+// WithAllowPartial makes TranscribeRemaining not abort on the first failed
+// chunk: the other chunks keep going, failed chunks are retried once at the
+// end, one at a time, and those still failing are returned in a PartialError
+// along with the other results.
+//
+// Authentication and quota errors still abort, as they fail every chunk.
+func WithAllowPartial() RunOption {
+	return func(r *runOptions) {
+		r.allowPartial = true
+	}
+}
+
+// isFatalChunkError reports whether err, from a chunk of ctx, must abort the
+// whole transcription even in partial failure mode.
+func isFatalChunkError(ctx context.Context, err error) bool {
+	return ctx.Err() != nil || errors.Is(err, context.Canceled) ||
+		errors.Is(err, apierr.ErrAuthFailed) || errors.Is(err, apierr.ErrQuotaExceeded)
+}
@@ -0,0 +1,139 @@
+package transcribe_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/apierr"
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// flakyTranscriber fails the first failures[path] calls for each path, then
+// returns the path as text.
+type flakyTranscriber struct {
+	mu       sync.Mutex
+	failures map[string]int
+	err      error
+	calls    map[string]int
+}
+
+func (f *flakyTranscriber) Transcribe(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[audioPath]++
+	if f.calls[audioPath] <= f.failures[audioPath] {
+		return "", f.err
+	}
+	return "text of " + audioPath, nil
+}
+
+func partialTestChunks(n int) []audio.Chunk {
+	chunks := make([]audio.Chunk, n)
+	for i := range chunks {
+		chunks[i] = audio.Chunk{Path: fmt.Sprintf("/path/chunk%d.mp3", i), Index: i}
+	}
+	return chunks
+}
+
+func TestTranscribeRemaining_AllowPartial(t *testing.T) {
+	t.Parallel()
+
+	serverErr := errors.New("server error")
+
+	t.Run("failed chunk succeeds when retried", func(t *testing.T) {
+		t.Parallel()
+
+		tr := &flakyTranscriber{failures: map[string]int{"/path/chunk1.mp3": 1}, err: serverErr}
+		results, err := transcribe.TranscribeRemaining(context.Background(),
+			partialTestChunks(3), tr, transcribe.Options{}, 3, nil, nil, transcribe.WithAllowPartial())
+		if err != nil {
+			t.Fatalf("TranscribeRemaining() unexpected error: %v", err)
+		}
+		if results[1] != "text of /path/chunk1.mp3" {
+			t.Errorf("results = %v, want chunk 1 transcribed on retry", results)
+		}
+		if tr.calls["/path/chunk1.mp3"] != 2 {
+			t.Errorf("chunk 1 calls = %d, want 2", tr.calls["/path/chunk1.mp3"])
+		}
+	})
+
+	t.Run("chunks still failing are returned with the others", func(t *testing.T) {
+		t.Parallel()
+
+		tr := &flakyTranscriber{failures: map[string]int{"/path/chunk2.mp3": 2, "/path/chunk0.mp3": 2}, err: serverErr}
+		reported := make(map[int]string)
+		results, err := transcribe.TranscribeRemaining(context.Background(),
+			partialTestChunks(4), tr, transcribe.Options{}, 2, nil,
+			func(index int, text string) { reported[index] = text }, transcribe.WithAllowPartial())
+
+		var partial *transcribe.PartialError
+		if !errors.As(err, &partial) || !errors.Is(err, transcribe.ErrPartial) {
+			t.Fatalf("TranscribeRemaining() error = %v, want PartialError", err)
+		}
+		if len(partial.Failed) != 2 || partial.Failed[0].Chunk.Index != 0 || partial.Failed[1].Chunk.Index != 2 {
+			t.Errorf("Failed = %v, want chunks 0 and 2 in order", partial.Failed)
+		}
+		if !errors.Is(partial.Failed[0], serverErr) {
+			t.Errorf("Failed[0] = %v, want the chunk error", partial.Failed[0])
+		}
+		if errors.Is(err, serverErr) {
+			t.Error("PartialError wraps the chunk errors, want only ErrPartial")
+		}
+		want := []string{"", "text of /path/chunk1.mp3", "", "text of /path/chunk3.mp3"}
+		if strings.Join(results, "|") != strings.Join(want, "|") {
+			t.Errorf("results = %q, want %q", results, want)
+		}
+		if len(reported) != 2 {
+			t.Errorf("reported = %v, want the 2 transcribed chunks only", reported)
+		}
+	})
+
+	t.Run("authentication errors abort", func(t *testing.T) {
+		t.Parallel()
+
+		tr := &flakyTranscriber{failures: map[string]int{"/path/chunk0.mp3": 1}, err: apierr.ErrAuthFailed}
+		results, err := transcribe.TranscribeRemaining(context.Background(),
+			partialTestChunks(2), tr, transcribe.Options{}, 1, nil, nil, transcribe.WithAllowPartial())
+		if !errors.Is(err, apierr.ErrAuthFailed) || results != nil {
+			t.Errorf("TranscribeRemaining() = %v, %v, want ErrAuthFailed", results, err)
+		}
+	})
+
+	t.Run("aborts without partial mode", func(t *testing.T) {
+		t.Parallel()
+
+		tr := &flakyTranscriber{failures: map[string]int{"/path/chunk0.mp3": 1}, err: serverErr}
+		_, err := transcribe.TranscribeRemaining(context.Background(),
+			partialTestChunks(2), tr, transcribe.Options{}, 1, nil, nil)
+		if !errors.Is(err, serverErr) || errors.Is(err, transcribe.ErrPartial) {
+			t.Errorf("TranscribeRemaining() error = %v, want the chunk error", err)
+		}
+	})
+
+	t.Run("failed probe runs chunks one at a time", func(t *testing.T) {
+		t.Parallel()
+
+		tr := &flakyTranscriber{failures: map[string]int{"/path/chunk0.mp3": 3}, err: serverErr}
+		var parallel int
+		results, err := transcribe.TranscribeRemainingAuto(context.Background(),
+			partialTestChunks(3), tr, transcribe.Options{}, nil, nil,
+			func(n int, bps float64) { parallel = n }, transcribe.WithAllowPartial())
+		if err != nil {
+			t.Fatalf("TranscribeRemainingAuto() unexpected error: %v", err)
+		}
+		if parallel != 1 {
+			t.Errorf("parallel = %d, want 1", parallel)
+		}
+		if results[0] != "text of /path/chunk0.mp3" {
+			t.Errorf("results = %v, want the probe retried with the others", results)
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package transcribe
+
+// pricesPerMinute are the prices of the transcription models of the built-in
+// providers, in US dollars per minute of audio (published prices, used for
+// estimates only).
+var pricesPerMinute = map[string]float64{
+	ModelGPT4oMiniTranscribe:    0.003,
+	ModelGPT4oTranscribeDiarize: 0.006,
+	ModelWhisper1:               0.006,
+	ModelGroqWhisperLargeV3:     0.111 / 60,
+	ModelDeepgramNova3:          0.0043,
+	ModelAssemblyAIBest:         0.0025,
+}
+
+// Model returns the OpenAI model OpenAITranscriber uses for opts.
+func Model(opts Options) string {
+	switch {
+	case opts.Diarize:
+		return ModelGPT4oTranscribeDiarize
+	case opts.Timestamps, opts.Translate:
+		return ModelWhisper1
+	default:
+		return ModelGPT4oMiniTranscribe
+	}
+}
+
+// PricePerMinute returns the price of a transcription model, in US dollars
+// per minute of audio. Returns false for unknown models.
+func PricePerMinute(model string) (float64, bool) {
+	price, ok := pricesPerMinute[model]
+	return price, ok
+}
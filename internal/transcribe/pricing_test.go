@@ -0,0 +1,54 @@
+package transcribe_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestModel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts transcribe.Options
+	}{
+		{"standard", transcribe.Options{}},
+		{"diarize", transcribe.Options{Diarize: true}},
+		{"timestamps", transcribe.Options{Timestamps: true}},
+		{"diarize with timestamps", transcribe.Options{Diarize: true, Timestamps: true}},
+		{"translate", transcribe.Options{Translate: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Model must name the model the transcriber actually requests.
+			server := newMockOpenAIServer()
+			t.Cleanup(server.Close)
+			server.addResponse(http.StatusOK, map[string]any{"text": "text"})
+
+			tr := transcribe.NewTestTranscriber(server.Client(), server.URL)
+			_, _ = tr.Transcribe(context.Background(), createTempAudioFile(t), tt.opts)
+
+			want := server.lastCall().Model
+			if got := transcribe.Model(tt.opts); got != want {
+				t.Errorf("Model(%+v) = %q, want %q", tt.opts, got, want)
+			}
+			if _, ok := transcribe.PricePerMinute(want); !ok {
+				t.Errorf("PricePerMinute(%q) has no price", want)
+			}
+		})
+	}
+}
+
+func TestPricePerMinute_Unknown(t *testing.T) {
+	t.Parallel()
+
+	if _, ok := transcribe.PricePerMinute("unknown-model"); ok {
+		t.Error("PricePerMinute() found a price for an unknown model")
+	}
+}
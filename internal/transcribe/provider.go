@@ -0,0 +1,161 @@
+package transcribe
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Built-in transcription providers (see NewProviderTranscriber).
+const (
+	// ProviderOpenAI transcribes with the OpenAI API (OpenAITranscriber).
+	ProviderOpenAI = "openai"
+	// ProviderGroq transcribes with whisper-large-v3 on Groq (NewGroqTranscriber).
+	ProviderGroq = "groq"
+	// ProviderDeepgram transcribes with the Deepgram API (DeepgramTranscriber).
+	ProviderDeepgram = "deepgram"
+	// ProviderAssemblyAI transcribes with the AssemblyAI API (AssemblyAITranscriber).
+	ProviderAssemblyAI = "assemblyai"
+)
+
+// ProviderConfig holds the settings given to provider constructors.
+type ProviderConfig struct {
+	APIKey string
+	// BaseURL overrides the URL of the API, for proxies or tests.
+	// Empty means the provider default.
+	BaseURL string
+}
+
+// Provider describes a speech-to-text API that can be selected by name.
+type Provider struct {
+	// Name selects the provider, e.g. with --transcriber.
+	Name string
+	// APIKeyEnv is the environment variable holding the API key.
+	APIKeyEnv string
+	// MaxFileSize is the largest audio file the API accepts, in bytes.
+	// Chunks must stay under it: larger files fail with audio.ErrChunkTooLarge.
+	MaxFileSize int64
+	// Diarize is true if the API can identify speakers (Options.Diarize).
+	Diarize bool
+	// Translate is true if the API can translate speech to English
+	// (Options.Translate).
+	Translate bool
+	// Model returns the model transcribing with opts, for cost estimates
+	// (see PricePerMinute). Nil means unknown.
+	Model func(opts Options) string
+	// New creates a transcriber calling the API.
+	New func(cfg ProviderConfig) (Transcriber, error)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{
+		ProviderOpenAI: {
+			Name:        ProviderOpenAI,
+			APIKeyEnv:   "OPENAI_API_KEY",
+			MaxFileSize: MaxFileSize,
+			Diarize:     true,
+			Translate:   true,
+			Model:       Model,
+			New: func(cfg ProviderConfig) (Transcriber, error) {
+				return NewOpenAITranscriber(cfg.APIKey, withOptionalBaseURL(cfg.BaseURL)...), nil
+			},
+		},
+		ProviderGroq: {
+			Name:        ProviderGroq,
+			APIKeyEnv:   "GROQ_API_KEY",
+			MaxFileSize: GroqMaxFileSize,
+			Translate:   true,
+			Model:       func(Options) string { return ModelGroqWhisperLargeV3 },
+			New: func(cfg ProviderConfig) (Transcriber, error) {
+				return NewGroqTranscriber(cfg.APIKey, withOptionalBaseURL(cfg.BaseURL)...), nil
+			},
+		},
+		ProviderDeepgram: {
+			Name:        ProviderDeepgram,
+			APIKeyEnv:   "DEEPGRAM_API_KEY",
+			MaxFileSize: DeepgramMaxFileSize,
+			Diarize:     true,
+			Model:       func(Options) string { return ModelDeepgramNova3 },
+			New: func(cfg ProviderConfig) (Transcriber, error) {
+				var opts []DeepgramOption
+				if cfg.BaseURL != "" {
+					opts = append(opts, WithDeepgramBaseURL(cfg.BaseURL))
+				}
+				return NewDeepgramTranscriber(cfg.APIKey, opts...), nil
+			},
+		},
+		ProviderAssemblyAI: {
+			Name:        ProviderAssemblyAI,
+			APIKeyEnv:   "ASSEMBLYAI_API_KEY",
+			MaxFileSize: AssemblyAIMaxFileSize,
+			Diarize:     true,
+			Model:       func(Options) string { return ModelAssemblyAIBest },
+			New: func(cfg ProviderConfig) (Transcriber, error) {
+				var opts []AssemblyAIOption
+				if cfg.BaseURL != "" {
+					opts = append(opts, WithAssemblyAIBaseURL(cfg.BaseURL))
+				}
+				return NewAssemblyAITranscriber(cfg.APIKey, opts...), nil
+			},
+		},
+	}
+)
+
+// withOptionalBaseURL returns the WithBaseURL option of url, or none if empty.
+func withOptionalBaseURL(url string) []TranscriberOption {
+	if url == "" {
+		return nil
+	}
+	return []TranscriberOption{WithBaseURL(url)}
+}
+
+// RegisterProvider makes a speech-to-text API available under p.Name. Like
+// database/sql.Register, it is meant to be called from init functions, and
+// panics if the name is empty or already registered, or if p.New is nil.
+func RegisterProvider(p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if p.Name == "" || p.New == nil {
+		panic("transcribe: RegisterProvider needs a name and a constructor")
+	}
+	if _, dup := providers[p.Name]; dup {
+		panic("transcribe: RegisterProvider called twice for provider " + p.Name)
+	}
+	providers[p.Name] = p
+}
+
+// ProviderNames returns the names of the registered providers, sorted.
+func ProviderNames() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// LookupProvider returns the provider registered under name.
+// Returns ErrUnknownProvider if no provider is registered under name.
+func LookupProvider(name string) (Provider, error) {
+	providersMu.RLock()
+	p, ok := providers[name]
+	providersMu.RUnlock()
+	if !ok {
+		return Provider{}, fmt.Errorf("%w: %q (available: %s)", ErrUnknownProvider, name, strings.Join(ProviderNames(), ", "))
+	}
+	return p, nil
+}
+
+// NewProviderTranscriber creates a transcriber of the provider registered
+// under name. Returns ErrUnknownProvider if no provider is registered under name.
+func NewProviderTranscriber(name string, cfg ProviderConfig) (Transcriber, error) {
+	p, err := LookupProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	return p.New(cfg)
+}
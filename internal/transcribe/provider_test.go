@@ -0,0 +1,216 @@
+package transcribe_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestNewProviderTranscriber(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		provider string
+		wantType string
+		wantErr  error
+	}{
+		{"openai", transcribe.ProviderOpenAI, "openai", nil},
+		{"groq", transcribe.ProviderGroq, "openai", nil},
+		{"deepgram", transcribe.ProviderDeepgram, "deepgram", nil},
+		{"assemblyai", transcribe.ProviderAssemblyAI, "assemblyai", nil},
+		{"unknown", "speechmatics", "", transcribe.ErrUnknownProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tr, err := transcribe.NewProviderTranscriber(tt.provider, transcribe.ProviderConfig{APIKey: "key"})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("NewProviderTranscriber(%q) error = %v, want %v", tt.provider, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewProviderTranscriber(%q) error = %v", tt.provider, err)
+			}
+
+			var got string
+			switch tr.(type) {
+			case *transcribe.OpenAITranscriber:
+				got = "openai"
+			case *transcribe.DeepgramTranscriber:
+				got = "deepgram"
+			case *transcribe.AssemblyAITranscriber:
+				got = "assemblyai"
+			}
+			if got != tt.wantType {
+				t.Errorf("NewProviderTranscriber(%q) = %T, want %s transcriber", tt.provider, tr, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestLookupProvider_BuiltIn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		apiKeyEnv   string
+		maxFileSize int64
+		diarize     bool
+		translate   bool
+	}{
+		{transcribe.ProviderOpenAI, "OPENAI_API_KEY", transcribe.MaxFileSize, true, true},
+		{transcribe.ProviderGroq, "GROQ_API_KEY", transcribe.GroqMaxFileSize, false, true},
+		{transcribe.ProviderDeepgram, "DEEPGRAM_API_KEY", transcribe.DeepgramMaxFileSize, true, false},
+		{transcribe.ProviderAssemblyAI, "ASSEMBLYAI_API_KEY", transcribe.AssemblyAIMaxFileSize, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := transcribe.LookupProvider(tt.name)
+			if err != nil {
+				t.Fatalf("LookupProvider(%q) error = %v", tt.name, err)
+			}
+			if p.Name != tt.name || p.APIKeyEnv != tt.apiKeyEnv || p.MaxFileSize != tt.maxFileSize || p.Diarize != tt.diarize {
+				t.Errorf("LookupProvider(%q) = {%s %s %d %v}, want {%s %s %d %v}", tt.name,
+					p.Name, p.APIKeyEnv, p.MaxFileSize, p.Diarize, tt.name, tt.apiKeyEnv, tt.maxFileSize, tt.diarize)
+			}
+			if p.Translate != tt.translate {
+				t.Errorf("LookupProvider(%q).Translate = %v, want %v", tt.name, p.Translate, tt.translate)
+			}
+			// Cost estimates need the price of every model of the provider
+			for _, opts := range []transcribe.Options{{}, {Timestamps: true}, {Diarize: tt.diarize}, {Translate: tt.translate}} {
+				model := p.Model(opts)
+				if _, ok := transcribe.PricePerMinute(model); !ok {
+					t.Errorf("PricePerMinute(%q) has no price", model)
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterProvider(t *testing.T) {
+	// Not parallel: the registry is global, and parallel tests list its names.
+	t.Cleanup(func() { transcribe.UnregisterProvider("test-register") })
+
+	want := transcribe.NewDeepgramTranscriber("key")
+	var gotCfg transcribe.ProviderConfig
+	transcribe.RegisterProvider(transcribe.Provider{
+		Name:        "test-register",
+		MaxFileSize: 1 << 20,
+		New: func(cfg transcribe.ProviderConfig) (transcribe.Transcriber, error) {
+			gotCfg = cfg
+			return want, nil
+		},
+	})
+
+	if names := transcribe.ProviderNames(); !slices.Contains(names, "test-register") || !slices.IsSorted(names) {
+		t.Errorf("ProviderNames() = %v, want sorted names with test-register", names)
+	}
+	cfg := transcribe.ProviderConfig{APIKey: "key", BaseURL: "http://localhost:8080"}
+	got, err := transcribe.NewProviderTranscriber("test-register", cfg)
+	if err != nil {
+		t.Fatalf("NewProviderTranscriber() error = %v", err)
+	}
+	if got != want || gotCfg != cfg {
+		t.Errorf("NewProviderTranscriber() = %v with %+v, want the registered transcriber with %+v", got, gotCfg, cfg)
+	}
+}
+
+func TestRegisterProvider_Panics(t *testing.T) {
+	t.Parallel()
+
+	newTranscriber := func(transcribe.ProviderConfig) (transcribe.Transcriber, error) { return nil, nil }
+	tests := []struct {
+		name     string
+		provider transcribe.Provider
+	}{
+		{"duplicate", transcribe.Provider{Name: transcribe.ProviderOpenAI, New: newTranscriber}},
+		{"empty name", transcribe.Provider{New: newTranscriber}},
+		{"nil constructor", transcribe.Provider{Name: "test-nil"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			defer func() {
+				if recover() == nil {
+					t.Error("RegisterProvider() did not panic")
+				}
+			}()
+			transcribe.RegisterProvider(tt.provider)
+		})
+	}
+}
+
+func TestProviderTranscriber_FileTooLarge(t *testing.T) {
+	t.Parallel()
+
+	// Each provider checks its own limit before sending anything
+	for _, name := range []string{transcribe.ProviderOpenAI, transcribe.ProviderGroq, transcribe.ProviderDeepgram, transcribe.ProviderAssemblyAI} {
+		p, err := transcribe.LookupProvider(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tr, err := p.New(transcribe.ProviderConfig{APIKey: "key", BaseURL: "http://127.0.0.1:1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = tr.Transcribe(context.Background(), createSparseFile(t, p.MaxFileSize+1), transcribe.Options{})
+			if !errors.Is(err, audio.ErrChunkTooLarge) {
+				t.Errorf("Transcribe() error = %v, want ErrChunkTooLarge", err)
+			}
+		})
+	}
+}
+
+func TestProviderTranscriber_TranslateUnsupported(t *testing.T) {
+	t.Parallel()
+
+	// Providers without translation fail before sending anything
+	for _, name := range []string{transcribe.ProviderDeepgram, transcribe.ProviderAssemblyAI} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tr, err := transcribe.NewProviderTranscriber(name, transcribe.ProviderConfig{APIKey: "key", BaseURL: "http://127.0.0.1:1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = tr.Transcribe(context.Background(), createTempAudioFile(t), transcribe.Options{Translate: true})
+			if !errors.Is(err, transcribe.ErrTranslateUnsupported) {
+				t.Errorf("Transcribe() error = %v, want ErrTranslateUnsupported", err)
+			}
+		})
+	}
+}
+
+// createSparseFile creates an audio file of size bytes, without writing them.
+func createSparseFile(t *testing.T, size int64) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "chunk_000.ogg")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create audio file: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	if err := f.Truncate(size); err != nil {
+		t.Fatalf("failed to size audio file: %v", err)
+	}
+	return path
+}
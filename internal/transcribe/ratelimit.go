@@ -0,0 +1,151 @@
+package transcribe
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Adaptive slowdown parameters of RateLimiter.
+const (
+	// rateLimitPause is how long all requests wait after a rate limit
+	// response that does not say how long to wait.
+	rateLimitPause = 2 * time.Second
+
+	// minRateFactor is the lowest share of the configured rates used after
+	// repeated rate limit responses.
+	minRateFactor = 1.0 / 8
+
+	// rateRecovery is the share of the configured rates recovered by each
+	// successful request, once slowed down.
+	rateRecovery = 1.0 / 16
+
+	// throttleInterval spaces requests without a configured request rate,
+	// once slowed down: (1/factor - 1) intervals apart, from 1s at half rate
+	// to 7s at minRateFactor.
+	throttleInterval = time.Second
+)
+
+// RateLimiter spaces the requests of parallel transcriptions, so that they
+// stay under the rate limits of the API together instead of each retrying
+// on its own. It limits requests and seconds of audio per minute, and slows
+// down when the API answers with a rate limit anyway: all requests wait for
+// the delay requested by the API, then run at half rate (and half again on
+// the next rate limit), recovering gradually as requests succeed.
+//
+// A nil *RateLimiter does not limit anything. It is safe for concurrent use.
+type RateLimiter struct {
+	requestInterval time.Duration // Between request starts at full rate; 0 means unlimited
+	audioPerMinute  time.Duration // Audio sent per minute at full rate; 0 means unlimited
+	now             func() time.Time
+
+	mu          sync.Mutex
+	factor      float64   // Share of the rates in use, in [minRateFactor, 1]
+	nextRequest time.Time // Earliest start of the next request
+	nextAudio   time.Time // Earliest start of the next request, for the audio rate
+	pausedUntil time.Time // No request starts before, after a rate limit
+}
+
+// NewRateLimiter creates a RateLimiter allowing requestsPerMinute requests and
+// audioPerMinute of audio per minute. Zero (or negative) means unlimited:
+// requests are then only slowed down after rate limit responses.
+func NewRateLimiter(requestsPerMinute int, audioPerMinute time.Duration) *RateLimiter {
+	l := &RateLimiter{now: time.Now, factor: 1}
+	if requestsPerMinute > 0 {
+		l.requestInterval = time.Minute / time.Duration(requestsPerMinute)
+	}
+	if audioPerMinute > 0 {
+		l.audioPerMinute = audioPerMinute
+	}
+	return l
+}
+
+// Wait blocks until a request sending audio may start, within the rates.
+// Returns ctx.Err() if ctx is done first.
+func (l *RateLimiter) Wait(ctx context.Context, audio time.Duration) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		start := l.reserve(audio)
+		if d := start.Sub(l.now()); d > 0 {
+			timer := time.NewTimer(d)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		// A rate limit response during the wait pauses this request too.
+		if !l.paused() {
+			return nil
+		}
+	}
+}
+
+// reserve books the next request start within the rates, and returns it.
+func (l *RateLimiter) reserve(audio time.Duration) time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	start := l.now()
+	for _, t := range []time.Time{l.pausedUntil, l.nextRequest, l.nextAudio} {
+		if t.After(start) {
+			start = t
+		}
+	}
+
+	interval := time.Duration(float64(l.requestInterval) / l.factor)
+	if l.requestInterval == 0 && l.factor < 1 {
+		interval = time.Duration((1/l.factor - 1) * float64(throttleInterval))
+	}
+	l.nextRequest = start.Add(interval)
+	if l.audioPerMinute > 0 && audio > 0 {
+		l.nextAudio = start.Add(time.Duration(float64(time.Minute) * float64(audio) / float64(l.audioPerMinute) / l.factor))
+	}
+	return start
+}
+
+// paused reports whether requests are paused after a rate limit response.
+func (l *RateLimiter) paused() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pausedUntil.After(l.now())
+}
+
+// RateLimited reports a rate limit response: requests pause for wait (the
+// delay requested by the API, or rateLimitPause if 0), and the rates halve.
+func (l *RateLimiter) RateLimited(wait time.Duration) {
+	if l == nil {
+		return
+	}
+	if wait <= 0 {
+		wait = rateLimitPause
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := l.now().Add(wait); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.factor = max(l.factor/2, minRateFactor)
+}
+
+// Succeeded reports a successful request: the rates recover a little.
+func (l *RateLimiter) Succeeded() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.factor = min(l.factor+rateRecovery, 1)
+}
+
+// WithRateLimiter makes the chunks of a run share l, whatever their worker:
+// each chunk waits for it before being sent, and the transcriber before
+// retrying it.
+func WithRateLimiter(l *RateLimiter) RunOption {
+	return func(r *runOptions) {
+		r.limiter = l
+	}
+}
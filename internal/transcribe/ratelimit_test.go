@@ -0,0 +1,208 @@
+package transcribe_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+// newClockedLimiter returns a rate limiter whose clock is stopped at t0.
+func newClockedLimiter(requestsPerMinute int, audioPerMinute time.Duration) (*transcribe.RateLimiter, time.Time) {
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	l := transcribe.NewRateLimiter(requestsPerMinute, audioPerMinute)
+	l.SetClock(func() time.Time { return t0 })
+	return l, t0
+}
+
+// schedule reserves n requests of audio each and returns their start offsets from t0.
+func schedule(l *transcribe.RateLimiter, t0 time.Time, n int, audio time.Duration) []time.Duration {
+	offsets := make([]time.Duration, n)
+	for i := range offsets {
+		offsets[i] = l.Reserve(audio).Sub(t0)
+	}
+	return offsets
+}
+
+func TestRateLimiter_Schedule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		requests int
+		audio    time.Duration
+		chunk    time.Duration
+		setup    func(l *transcribe.RateLimiter)
+		want     []time.Duration
+	}{
+		{
+			name: "unlimited",
+			want: []time.Duration{0, 0, 0},
+		},
+		{
+			name:     "requests per minute",
+			requests: 60,
+			want:     []time.Duration{0, time.Second, 2 * time.Second},
+		},
+		{
+			name:  "audio per minute",
+			audio: 30 * time.Minute,
+			chunk: 10 * time.Minute,
+			want:  []time.Duration{0, 20 * time.Second, 40 * time.Second},
+		},
+		{
+			name:     "slowest limit wins",
+			requests: 60,
+			audio:    30 * time.Minute,
+			chunk:    5 * time.Second,
+			want:     []time.Duration{0, time.Second, 2 * time.Second},
+		},
+		{
+			name:     "rate limit pauses and halves the rate",
+			requests: 60,
+			setup:    func(l *transcribe.RateLimiter) { l.RateLimited(10 * time.Second) },
+			want:     []time.Duration{10 * time.Second, 12 * time.Second, 14 * time.Second},
+		},
+		{
+			name:  "rate limit without requested wait",
+			setup: func(l *transcribe.RateLimiter) { l.RateLimited(0) },
+			want:  []time.Duration{2 * time.Second, 3 * time.Second, 4 * time.Second},
+		},
+		{
+			name:  "repeated rate limits slow down further",
+			setup: func(l *transcribe.RateLimiter) { l.RateLimited(time.Second); l.RateLimited(time.Second) },
+			want:  []time.Duration{time.Second, 4 * time.Second, 7 * time.Second},
+		},
+		{
+			name:     "successes recover the rate",
+			requests: 60,
+			setup: func(l *transcribe.RateLimiter) {
+				l.RateLimited(time.Second)
+				for range 8 {
+					l.Succeeded()
+				}
+			},
+			want: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			l, t0 := newClockedLimiter(tt.requests, tt.audio)
+			if tt.setup != nil {
+				tt.setup(l)
+			}
+			got := schedule(l, t0, len(tt.want), tt.chunk)
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("request starts = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiter_MinimumRate(t *testing.T) {
+	t.Parallel()
+
+	l, _ := newClockedLimiter(60, 0)
+	for range 10 {
+		l.RateLimited(time.Second)
+	}
+	if got := l.Factor(); got != 1.0/8 {
+		t.Errorf("Factor() = %v, want 1/8", got)
+	}
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil limiter does not wait", func(t *testing.T) {
+		t.Parallel()
+
+		var l *transcribe.RateLimiter
+		if err := l.Wait(context.Background(), time.Minute); err != nil {
+			t.Errorf("Wait() error = %v", err)
+		}
+		l.RateLimited(time.Second)
+		l.Succeeded()
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		t.Parallel()
+
+		l := transcribe.NewRateLimiter(1, 0)
+		if err := l.Wait(context.Background(), 0); err != nil {
+			t.Fatalf("first Wait() error = %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := l.Wait(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("second Wait() error = %v, want context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("parallel workers share the limiter", func(t *testing.T) {
+		t.Parallel()
+
+		chunks := []audio.Chunk{
+			{Path: "/path/chunk0.mp3", Index: 0},
+			{Path: "/path/chunk1.mp3", Index: 1},
+			{Path: "/path/chunk2.mp3", Index: 2},
+		}
+		// 20ms between requests: 3 parallel chunks take at least 40ms.
+		limiter := transcribe.WithRateLimiter(transcribe.NewRateLimiter(3000, 0))
+
+		start := time.Now()
+		if _, err := transcribe.TranscribeAll(context.Background(), chunks, newMockTranscriber(), transcribe.Options{}, 3, limiter); err != nil {
+			t.Fatalf("TranscribeAll() error = %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+			t.Errorf("TranscribeAll() took %v, want at least 40ms", elapsed)
+		}
+	})
+}
+
+func TestTranscribe_RateLimiter(t *testing.T) {
+	t.Parallel()
+
+	audioPath := createTempAudioFile(t)
+	httpMock := &mockHTTPClient{
+		responses: []*http.Response{
+			{
+				StatusCode: http.StatusTooManyRequests,
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{"error": {"message": "Rate limit exceeded"}}`))),
+				Header:     http.Header{"Retry-After": []string{"0.001"}},
+			},
+			{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{"text": "success"}`))),
+				Header:     make(http.Header),
+			},
+		},
+	}
+
+	l := transcribe.NewRateLimiter(0, 0)
+	tr := transcribe.NewTestTranscriber(httpMock, "http://fake-api.test",
+		transcribe.WithMaxRetries(3),
+		transcribe.WithRetryDelays(time.Millisecond, time.Millisecond),
+	)
+
+	chunks := []audio.Chunk{{Path: audioPath}}
+	if _, err := transcribe.TranscribeAll(context.Background(), chunks, tr, transcribe.Options{}, 1, transcribe.WithRateLimiter(l)); err != nil {
+		t.Fatalf("TranscribeAll() error = %v", err)
+	}
+	// Halved by the rate limit, then recovered by 1/16 with the success.
+	if got, want := l.Factor(), 0.5+1.0/16; got != want {
+		t.Errorf("Factor() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package transcribe
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// TimedSegment is a span of transcribed speech and its position in the audio.
+type TimedSegment struct {
+	Start      time.Duration
+	End        time.Duration
+	Speaker    string // Empty unless diarized
+	Text       string
+	Confidence float64 // Probability the text is right, from 0 to 1; 0 if the transcriber gives none
+}
+
+// segmentJSON is the encoding of a TimedSegment, with times in seconds so
+// checkpoints stay readable.
+type segmentJSON struct {
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Speaker    string  `json:"speaker,omitempty"`
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+// EncodeSegments encodes segments as the text returned by Transcribe when
+// Options.Timestamps is set.
+func EncodeSegments(segments []TimedSegment) (string, error) {
+	encoded := make([]segmentJSON, 0, len(segments))
+	for _, s := range segments {
+		text := strings.TrimSpace(s.Text)
+		if text == "" {
+			continue
+		}
+		encoded = append(encoded, segmentJSON{
+			Start:      s.Start.Seconds(),
+			End:        s.End.Seconds(),
+			Speaker:    s.Speaker,
+			Text:       text,
+			Confidence: math.Round(s.Confidence*1000) / 1000,
+		})
+	}
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode segments: %w", err)
+	}
+	return string(data), nil
+}
+
+// ParseSegments decodes the text returned by Transcribe when Options.Timestamps is set.
+func ParseSegments(text string) ([]TimedSegment, error) {
+	var encoded []segmentJSON
+	if err := json.Unmarshal([]byte(text), &encoded); err != nil {
+		return nil, fmt.Errorf("invalid timed transcript: %w", err)
+	}
+	segments := make([]TimedSegment, len(encoded))
+	for i, s := range encoded {
+		segments[i] = TimedSegment{
+			Start:      seconds(s.Start),
+			End:        seconds(s.End),
+			Speaker:    s.Speaker,
+			Text:       s.Text,
+			Confidence: s.Confidence,
+		}
+	}
+	return segments, nil
+}
+
+// MergeSegments decodes the results of a timestamped transcription of chunks
+// (see TranscribeAll) and shifts the segments of each chunk by its start time,
+// so that all segments are relative to the start of the original audio.
+// Speech transcribed twice where chunks overlap is kept once (see StitchOverlap).
+func MergeSegments(chunks []audio.Chunk, results []string) ([]TimedSegment, error) {
+	if len(chunks) != len(results) {
+		return nil, fmt.Errorf("got %d results for %d chunks", len(results), len(chunks))
+	}
+	var merged []TimedSegment
+	for i, chunk := range chunks {
+		segments, err := ParseSegments(results[i])
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", chunk.Index, err)
+		}
+		for j := range segments {
+			segments[j].Start += chunk.StartTime
+			segments[j].End += chunk.StartTime
+		}
+		merged = append(merged, stitchSegments(merged, segments)...)
+	}
+	return merged, nil
+}
+
+// logprobConfidence converts the average log probability of the tokens of a
+// segment (avg_logprob of verbose_json) to a confidence from 0 to 1, or 0 if
+// it is missing.
+func logprobConfidence(logprob *float64) float64 {
+	if logprob == nil {
+		return 0
+	}
+	return min(math.Exp(*logprob), 1)
+}
+
+// seconds converts API timestamps (seconds) to a duration, rounded to the millisecond.
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Round(s*1000)) * time.Millisecond
+}
@@ -0,0 +1,93 @@
+package transcribe_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestParseSegments(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []transcribe.TimedSegment
+		wantErr bool
+	}{
+		{
+			name:  "seconds are converted to durations",
+			input: `[{"start":1.25,"end":3.5,"speaker":"A","text":"Hi."}]`,
+			want:  []transcribe.TimedSegment{{Start: 1250 * time.Millisecond, End: 3500 * time.Millisecond, Speaker: "A", Text: "Hi."}},
+		},
+		{
+			name:  "confidence",
+			input: `[{"start":0,"end":1,"text":"Hi.","confidence":0.42}]`,
+			want:  []transcribe.TimedSegment{{Start: 0, End: time.Second, Text: "Hi.", Confidence: 0.42}},
+		},
+		{name: "empty list", input: `[]`, want: []transcribe.TimedSegment{}},
+		{name: "plain text is rejected", input: "Hello there.", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := transcribe.ParseSegments(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSegments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("ParseSegments() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeSegments(t *testing.T) {
+	t.Parallel()
+
+	chunks := []audio.Chunk{
+		{Index: 0, StartTime: 0, EndTime: 5 * time.Minute},
+		{Index: 1, StartTime: 5 * time.Minute, EndTime: 10 * time.Minute},
+	}
+
+	t.Run("shifts segments by chunk start", func(t *testing.T) {
+		t.Parallel()
+
+		results := []string{
+			`[{"start":0,"end":2,"text":"First."}]`,
+			`[{"start":1,"end":3,"text":"Second."}]`,
+		}
+		got, err := transcribe.MergeSegments(chunks, results)
+		if err != nil {
+			t.Fatalf("MergeSegments() unexpected error: %v", err)
+		}
+		want := []transcribe.TimedSegment{
+			{Start: 0, End: 2 * time.Second, Text: "First."},
+			{Start: 5*time.Minute + time.Second, End: 5*time.Minute + 3*time.Second, Text: "Second."},
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("MergeSegments() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("rejects plain text results", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := transcribe.MergeSegments(chunks, []string{"[]", "plain"}); err == nil {
+			t.Error("MergeSegments() expected error for plain text result")
+		}
+	})
+
+	t.Run("rejects mismatched lengths", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := transcribe.MergeSegments(chunks, []string{"[]"}); err == nil {
+			t.Error("MergeSegments() expected error for missing result")
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package transcribe
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Overlap stitching parameters. Chunks overlap so that words at a boundary
+// are heard whole in at least one chunk, but the overlap is then transcribed
+// twice: stitching removes the repeat from the start of the later chunk.
+const (
+	// stitchWindow is how many words at the end of a chunk and at the start of
+	// the next one are compared: enough for the 30s overlap of time-based chunks.
+	stitchWindow = 150
+
+	// stitchMinWords is the shortest run of repeated words removed as overlap,
+	// so that a short phrase said twice around a boundary is kept.
+	stitchMinWords = 3
+
+	// stitchSlack is how many words may precede the repeat at the start of a
+	// chunk, or follow it at the end of the previous one: words cut by the
+	// chunk boundary are often transcribed differently on each side.
+	stitchSlack = 3
+)
+
+// stitchTrim is what is left out of the start of a chunk after its repeat.
+const stitchTrim = " \t\r\n,.;:!?"
+
+// word is a word of a transcript and its position in it.
+type word struct {
+	norm string // Lowercase letters and digits, compared across chunks
+	end  int    // Byte offset of the end of the word in the text
+}
+
+// splitWords returns the words of text. Tokens without letters or digits,
+// such as dashes, are left out.
+func splitWords(text string) []word {
+	var words []word
+	for i := 0; i < len(text); {
+		start := strings.IndexFunc(text[i:], func(r rune) bool { return !unicode.IsSpace(r) })
+		if start < 0 {
+			break
+		}
+		start += i
+		end := strings.IndexFunc(text[start:], unicode.IsSpace)
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += start
+		}
+		if norm := normalizeWord(text[start:end]); norm != "" {
+			words = append(words, word{norm: norm, end: end})
+		}
+		i = end
+	}
+	return words
+}
+
+// normalizeWord returns the letters and digits of s, in lowercase.
+func normalizeWord(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
+// overlapWords returns how many words at the start of next repeat the end of
+// prev, including the few words before the repeat (see stitchSlack).
+// Returns 0 if no run of at least stitchMinWords words repeats.
+func overlapWords(prev, next []word) int {
+	prev = prev[max(0, len(prev)-stitchWindow):]
+	next = next[:min(len(next), stitchWindow)]
+
+	drop, longest := 0, 0
+	for s := 0; s <= stitchSlack && s < len(next); s++ {
+		for p := range prev {
+			n := 0
+			for p+n < len(prev) && s+n < len(next) && prev[p+n].norm == next[s+n].norm {
+				n++
+			}
+			// The repeat must reach the end of prev, give or take the slack.
+			if n >= stitchMinWords && n > longest && p+n >= len(prev)-stitchSlack {
+				drop, longest = s+n, n
+			}
+		}
+	}
+	return drop
+}
+
+// StitchOverlap removes from the start of each result the words repeating
+// the end of the previous one, transcribed twice where chunks overlap.
+// Results are plain text (MergeSegments stitches timestamped results).
+func StitchOverlap(results []string) []string {
+	stitched := make([]string, len(results))
+	for i, text := range results {
+		if i > 0 {
+			next := splitWords(text)
+			if n := overlapWords(splitWords(stitched[i-1]), next); n > 0 {
+				text = strings.TrimLeft(text[next[n-1].end:], stitchTrim)
+			}
+		}
+		stitched[i] = text
+	}
+	return stitched
+}
+
+// stitchSegments removes from the start of next the words repeating the end
+// of prev, like StitchOverlap: segments entirely repeated are dropped, and
+// the text of a segment repeated in part is trimmed.
+func stitchSegments(prev, next []TimedSegment) []TimedSegment {
+	var prevWords []word
+	for i := len(prev) - 1; i >= 0 && len(prevWords) < stitchWindow; i-- {
+		prevWords = append(splitWords(prev[i].Text), prevWords...)
+	}
+
+	var nextWords []word
+	var owners []int // Segment of each word of nextWords
+	for i, s := range next {
+		if len(nextWords) >= stitchWindow {
+			break
+		}
+		for _, w := range splitWords(s.Text) {
+			nextWords = append(nextWords, w)
+			owners = append(owners, i)
+		}
+	}
+
+	n := overlapWords(prevWords, nextWords)
+	if n == 0 {
+		return next
+	}
+	last := owners[n-1]
+	rest := strings.TrimLeft(next[last].Text[nextWords[n-1].end:], stitchTrim)
+	if rest == "" {
+		return next[last+1:]
+	}
+	stitched := append([]TimedSegment(nil), next[last:]...)
+	stitched[0].Text = rest
+	return stitched
+}
@@ -0,0 +1,149 @@
+package transcribe_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestStitchOverlap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		results []string
+		want    []string
+	}{
+		{
+			name: "exact repeat",
+			results: []string{
+				"We reviewed the budget. Next we talk about hiring for the new team.",
+				"Next we talk about hiring for the new team. Two roles are open.",
+			},
+			want: []string{
+				"We reviewed the budget. Next we talk about hiring for the new team.",
+				"Two roles are open.",
+			},
+		},
+		{
+			name: "punctuation and case differ",
+			results: []string{
+				"so the release is planned for March, right after",
+				"The release is planned for March right after the audit. Any questions?",
+			},
+			want: []string{
+				"so the release is planned for March, right after",
+				"the audit. Any questions?",
+			},
+		},
+		{
+			name: "words cut at the boundary",
+			results: []string{
+				"the deadline moves to Friday because of the holi",
+				"ay because of the holiday. Please update the board.",
+			},
+			want: []string{
+				"the deadline moves to Friday because of the holi",
+				"holiday. Please update the board.",
+			},
+		},
+		{
+			name: "no overlap",
+			results: []string{
+				"First part of the meeting.",
+				"Second part, with other words.",
+			},
+			want: []string{
+				"First part of the meeting.",
+				"Second part, with other words.",
+			},
+		},
+		{
+			name: "short repeat kept",
+			results: []string{
+				"It was a good idea. Thank you.",
+				"Thank you. Let's move on.",
+			},
+			want: []string{
+				"It was a good idea. Thank you.",
+				"Thank you. Let's move on.",
+			},
+		},
+		{
+			name: "repeat far from the end kept",
+			results: []string{
+				"we need more tests before the release and then we ship it next week once the review is done",
+				"we need more tests before the release, said the lead.",
+			},
+			want: []string{
+				"we need more tests before the release and then we ship it next week once the review is done",
+				"we need more tests before the release, said the lead.",
+			},
+		},
+		{
+			name: "whole chunk repeated",
+			results: []string{
+				"one two three four",
+				"two three four",
+				"five six",
+			},
+			want: []string{"one two three four", "", "five six"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := transcribe.StitchOverlap(tt.results)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("StitchOverlap() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeSegments_StitchesOverlap(t *testing.T) {
+	t.Parallel()
+
+	// Two 10-minute chunks overlapping by 30s, as cut by TimeChunker.
+	chunks := []audio.Chunk{
+		{Index: 0, StartTime: 0, EndTime: 10 * time.Minute},
+		{Index: 1, StartTime: 9*time.Minute + 30*time.Second, EndTime: 19*time.Minute + 30*time.Second},
+	}
+	first, err := transcribe.EncodeSegments([]transcribe.TimedSegment{
+		{Start: 9 * time.Minute, End: 9*time.Minute + 40*time.Second, Text: "Let's look at the numbers."},
+		{Start: 9*time.Minute + 40*time.Second, End: 10 * time.Minute, Text: "Revenue grew by ten percent"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := transcribe.EncodeSegments([]transcribe.TimedSegment{
+		{Start: 5 * time.Second, End: 10 * time.Second, Text: "at the numbers."},
+		{Start: 10 * time.Second, End: 35 * time.Second, Text: "Revenue grew by ten percent this quarter."},
+		{Start: 35 * time.Second, End: 40 * time.Second, Text: "Costs were flat."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := transcribe.MergeSegments(chunks, []string{first, second})
+	if err != nil {
+		t.Fatalf("MergeSegments() unexpected error: %v", err)
+	}
+
+	var texts []string
+	for _, s := range merged {
+		texts = append(texts, s.Text)
+	}
+	want := []string{"Let's look at the numbers.", "Revenue grew by ten percent", "this quarter.", "Costs were flat."}
+	if strings.Join(texts, "|") != strings.Join(want, "|") {
+		t.Errorf("merged texts = %q, want %q", texts, want)
+	}
+	if got := merged[2].Start; got != 9*time.Minute+40*time.Second {
+		t.Errorf("trimmed segment starts at %v, want its original start", got)
+	}
+}
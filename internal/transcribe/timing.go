@@ -0,0 +1,123 @@
+package transcribe
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+)
+
+// ChunkTiming is where the time of one chunk went.
+type ChunkTiming struct {
+	Chunk   audio.Chunk   // Chunk.Extraction is the time taken to cut it
+	Wait    time.Duration // Waiting for the rate limiter before the first attempt
+	API     time.Duration // Transcription call, including retries and their delays
+	Upload  time.Duration // Sending the audio, summed over attempts (OpenAI only)
+	Retries int           // Attempts after the first one
+	Failed  bool          // The chunk was not transcribed
+}
+
+// Timings collects the ChunkTiming of every chunk of a run (see WithTimings).
+// TranscribeRemaining times every chunk sent, and OpenAITranscriber records
+// its uploads and retries.
+// It is safe for concurrent use. A nil *Timings discards all records.
+type Timings struct {
+	mu     sync.Mutex
+	chunks map[int]ChunkTiming // By chunk index
+}
+
+// NewTimings creates an empty Timings.
+func NewTimings() *Timings {
+	return &Timings{chunks: make(map[int]ChunkTiming)}
+}
+
+// record adds c to the timing of its chunk. A chunk sent again (see
+// WithAllowPartial) adds its time to the first attempt and counts as a retry.
+func (t *Timings) record(c ChunkTiming) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if prev, ok := t.chunks[c.Chunk.Index]; ok {
+		c.Wait += prev.Wait
+		c.API += prev.API
+		c.Upload += prev.Upload
+		c.Retries += prev.Retries + 1
+	}
+	t.chunks[c.Chunk.Index] = c
+}
+
+// Chunks returns the recorded timings, in chunk order.
+func (t *Timings) Chunks() []ChunkTiming {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	chunks := make([]ChunkTiming, 0, len(t.chunks))
+	for _, c := range t.chunks {
+		chunks = append(chunks, c)
+	}
+	slices.SortFunc(chunks, func(a, b ChunkTiming) int { return a.Chunk.Index - b.Chunk.Index })
+	return chunks
+}
+
+// WithTimings makes the chunk functions record the timing of the chunks of
+// a run in t.
+func WithTimings(t *Timings) RunOption {
+	return func(r *runOptions) {
+		r.timings = t
+	}
+}
+
+// chunkTimer times the chunk being transcribed, so that the transcriber can
+// add its uploads and retries (see Options). A nil *chunkTimer records
+// nothing.
+type chunkTimer struct {
+	timings *Timings // Where the timing is recorded when finished
+
+	mu     sync.Mutex // The transport may report uploads from another goroutine
+	timing ChunkTiming
+}
+
+// startChunkTimer returns a timer of chunk recording in t, or nil if t is nil.
+func startChunkTimer(t *Timings, chunk audio.Chunk) *chunkTimer {
+	if t == nil {
+		return nil
+	}
+	return &chunkTimer{timings: t, timing: ChunkTiming{Chunk: chunk}}
+}
+
+// update applies fn to the timing, under the lock.
+func (ct *chunkTimer) update(fn func(*ChunkTiming)) {
+	if ct == nil {
+		return
+	}
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	fn(&ct.timing)
+}
+
+// uploaded adds an upload of d.
+func (ct *chunkTimer) uploaded(d time.Duration) {
+	ct.update(func(c *ChunkTiming) { c.Upload += d })
+}
+
+// retried counts an attempt after the first one.
+func (ct *chunkTimer) retried() {
+	ct.update(func(c *ChunkTiming) { c.Retries++ })
+}
+
+// finish records the timing in its Timings.
+func (ct *chunkTimer) finish(wait, api time.Duration, err error) {
+	if ct == nil {
+		return
+	}
+	ct.mu.Lock()
+	c := ct.timing
+	ct.mu.Unlock()
+	c.Wait, c.API, c.Failed = wait, api, err != nil
+	ct.timings.record(c)
+}
@@ -0,0 +1,106 @@
+package transcribe_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestTimings(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records the retries of a chunk", func(t *testing.T) {
+		t.Parallel()
+		audioPath := createTempAudioFile(t)
+
+		httpMock := &mockHTTPClient{
+			responses: []*http.Response{
+				{
+					StatusCode: http.StatusInternalServerError,
+					Body:       io.NopCloser(bytes.NewReader([]byte(`{"error": {"message": "server error"}}`))),
+					Header:     make(http.Header),
+				},
+				{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader([]byte(`{"text": "first"}`))),
+					Header:     make(http.Header),
+				},
+			},
+		}
+		tr := transcribe.NewTestTranscriber(httpMock, "http://fake-api.test",
+			transcribe.WithMaxRetries(3),
+			transcribe.WithRetryDelays(time.Millisecond, time.Millisecond),
+		)
+		chunks := []audio.Chunk{
+			{Path: audioPath, Index: 0, EndTime: time.Minute, Extraction: time.Second},
+		}
+
+		timings := transcribe.NewTimings()
+		if _, err := transcribe.TranscribeRemaining(context.Background(), chunks, tr, transcribe.Options{}, 1, nil, nil, transcribe.WithTimings(timings)); err != nil {
+			t.Fatalf("TranscribeRemaining() unexpected error: %v", err)
+		}
+
+		got := timings.Chunks()
+		if len(got) != 1 {
+			t.Fatalf("Chunks() = %+v, want 1 chunk", got)
+		}
+		if got[0].Chunk.Index != 0 || got[0].Retries != 1 || got[0].Failed {
+			t.Errorf("Chunks()[0] = %+v, want chunk 0 with 1 retry", got[0])
+		}
+		if got[0].Chunk.Extraction != time.Second {
+			t.Errorf("Chunks()[0].Chunk.Extraction = %v, want the extraction time of the chunk", got[0].Chunk.Extraction)
+		}
+		if got[0].API <= 0 {
+			t.Errorf("Chunks()[0].API = %v, want the duration of the call", got[0].API)
+		}
+	})
+
+	t.Run("chunk sent again counts as a retry", func(t *testing.T) {
+		t.Parallel()
+
+		tr := &flakyTranscriber{failures: map[string]int{"/path/chunk1.mp3": 1}, err: errors.New("server error")}
+		timings := transcribe.NewTimings()
+		if _, err := transcribe.TranscribeRemaining(context.Background(), partialTestChunks(2), tr, transcribe.Options{}, 2, nil, nil,
+			transcribe.WithAllowPartial(), transcribe.WithTimings(timings)); err != nil {
+			t.Fatalf("TranscribeRemaining() unexpected error: %v", err)
+		}
+
+		got := timings.Chunks()
+		if len(got) != 2 || got[0].Chunk.Index != 0 || got[0].Retries != 0 {
+			t.Errorf("Chunks() = %+v, want chunk 0 first, without retry", got)
+		}
+		if len(got) == 2 && (got[1].Retries != 1 || got[1].Failed) {
+			t.Errorf("Chunks()[1] = %+v, want chunk 1 transcribed after 1 retry", got[1])
+		}
+	})
+
+	t.Run("failed chunk", func(t *testing.T) {
+		t.Parallel()
+
+		tr := &flakyTranscriber{failures: map[string]int{"/path/chunk0.mp3": 1}, err: errors.New("server error")}
+		timings := transcribe.NewTimings()
+		if _, err := transcribe.TranscribeRemaining(context.Background(), partialTestChunks(1), tr, transcribe.Options{}, 1, nil, nil, transcribe.WithTimings(timings)); err == nil {
+			t.Fatal("TranscribeRemaining() expected error")
+		}
+
+		if got := timings.Chunks(); len(got) != 1 || !got[0].Failed {
+			t.Errorf("Chunks() = %+v, want chunk 0 failed", got)
+		}
+	})
+
+	t.Run("nil Timings", func(t *testing.T) {
+		t.Parallel()
+
+		var timings *transcribe.Timings
+		if got := timings.Chunks(); got != nil {
+			t.Errorf("Chunks() = %v, want nil", got)
+		}
+	})
+}
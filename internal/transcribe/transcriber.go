@@ -9,9 +9,13 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/sync/errgroup"
@@ -19,6 +23,7 @@ import (
 	"github.com/alnah/go-transcript/internal/apierr"
 	"github.com/alnah/go-transcript/internal/audio"
 	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/progress"
 )
 
 // OpenAI transcription model and format identifiers.
@@ -29,9 +34,15 @@ const (
 	// ModelGPT4oTranscribeDiarize is the transcription model with speaker identification.
 	ModelGPT4oTranscribeDiarize = "gpt-4o-transcribe-diarize"
 
+	// ModelWhisper1 is the transcription model returning segment timestamps.
+	ModelWhisper1 = "whisper-1"
+
 	// FormatDiarizedJSON is the response format for diarized transcription.
 	FormatDiarizedJSON = "diarized_json"
 
+	// FormatVerboseJSON is the response format with segment timestamps (whisper-1 only).
+	FormatVerboseJSON = "verbose_json"
+
 	// ChunkingStrategyAuto lets OpenAI automatically determine chunking boundaries.
 	// Required for diarization model when input is longer than 30 seconds.
 	ChunkingStrategyAuto = "auto"
@@ -41,6 +52,13 @@ const (
 
 	// transcriptionPath is the API path for audio transcription.
 	transcriptionPath = "/v1/audio/transcriptions"
+
+	// translationPath is the API path for audio translation to English.
+	translationPath = "/v1/audio/translations"
+
+	// DefaultAzureAPIVersion is the Azure OpenAI API version used when none
+	// is configured (see WithAzure).
+	DefaultAzureAPIVersion = "2024-10-21"
 )
 
 // Parallelism configuration.
@@ -57,9 +75,22 @@ const (
 	defaultMaxDelay   = 30 * time.Second
 )
 
+// defaultStallTimeout is how long an upload may send nothing before it is
+// aborted and retried on a fresh connection. Without it, a dead connection
+// is only detected by the OS TCP timeout, which can exceed 15 minutes.
+const defaultStallTimeout = 60 * time.Second
+
+// errUploadStalled is the cancellation cause of a request aborted by the
+// upload watchdog.
+var errUploadStalled = errors.New("upload stalled")
+
 // Response size limit to prevent OOM from malformed responses (10MB).
 const maxResponseSize = 10 * 1024 * 1024
 
+// MaxFileSize is the largest audio file the OpenAI API accepts (25MB).
+// Larger files fail with audio.ErrChunkTooLarge before being uploaded.
+const MaxFileSize = 25 * 1024 * 1024
+
 // Options configures transcription behavior.
 type Options struct {
 	// Diarize enables speaker identification in the transcript.
@@ -75,6 +106,21 @@ type Options struct {
 	// Language specifies the audio language.
 	// Zero value means auto-detect (recommended for most use cases).
 	Language lang.Language
+
+	// Timestamps makes Transcribe return the timed segments of the audio
+	// instead of plain text, for subtitles. Decode them with ParseSegments.
+	// Without diarization, OpenAI uses whisper-1 (the only model with timestamps).
+	Timestamps bool
+
+	// Translate makes Transcribe return the speech translated to English
+	// instead of transcribed in its language. OpenAI uses whisper-1, the only
+	// model of its translations endpoint. Cannot be combined with Diarize.
+	Translate bool
+
+	// Set by the chunk functions for the transcriber (see RunOption).
+	limiter *RateLimiter  // Waited for again before each retry
+	usage   *UsageTracker // Receives the tokens reported by the API
+	timer   *chunkTimer   // Of the chunk being transcribed, adding its uploads and retries
 }
 
 // Transcriber transcribes audio files to text.
@@ -103,6 +149,12 @@ type OpenAITranscriber struct {
 	maxRetries int
 	baseDelay  time.Duration
 	maxDelay   time.Duration
+	azure      *azureDeployment // Azure OpenAI deployment, nil for OpenAI
+	service    string           // Name of the API in errors
+	model      string           // Only model of OpenAI-compatible APIs, empty for OpenAI
+	maxSize    int64            // Largest file accepted, 0 for MaxFileSize
+
+	uploadSender
 }
 
 // TranscriberOption configures an OpenAITranscriber.
@@ -129,6 +181,16 @@ func WithRetryDelays(base, max time.Duration) TranscriberOption {
 	}
 }
 
+// WithStallTimeout sets how long an upload may send nothing before it is
+// aborted and retried. Zero disables the watchdog.
+func WithStallTimeout(d time.Duration) TranscriberOption {
+	return func(t *OpenAITranscriber) {
+		if d >= 0 {
+			t.stall = d
+		}
+	}
+}
+
 // WithHTTPClient sets a custom HTTP client (for testing).
 func WithHTTPClient(c httpDoer) TranscriberOption {
 	return func(t *OpenAITranscriber) {
@@ -143,16 +205,37 @@ func WithBaseURL(url string) TranscriberOption {
 	}
 }
 
+// azureDeployment is the Azure OpenAI deployment requests are sent to.
+type azureDeployment struct {
+	name       string
+	apiVersion string
+}
+
+// WithAzure sends the requests to the deployment of an Azure OpenAI resource,
+// whose endpoint (https://NAME.openai.azure.com) is set with WithBaseURL.
+// The API key is sent in the api-key header. An empty apiVersion selects
+// DefaultAzureAPIVersion. The deployment sets the model: diarization and
+// timestamps need a deployment of a model supporting them.
+func WithAzure(deployment, apiVersion string) TranscriberOption {
+	return func(t *OpenAITranscriber) {
+		if apiVersion == "" {
+			apiVersion = DefaultAzureAPIVersion
+		}
+		t.azure = &azureDeployment{name: deployment, apiVersion: apiVersion}
+	}
+}
+
 // NewOpenAITranscriber creates a new OpenAITranscriber.
 // apiKey is required for all requests (used as Bearer token).
 func NewOpenAITranscriber(apiKey string, opts ...TranscriberOption) *OpenAITranscriber {
 	t := &OpenAITranscriber{
-		httpClient: &http.Client{Timeout: 5 * time.Minute},
-		apiKey:     apiKey,
-		baseURL:    defaultOpenAIBaseURL,
-		maxRetries: defaultMaxRetries,
-		baseDelay:  defaultBaseDelay,
-		maxDelay:   defaultMaxDelay,
+		httpClient:   &http.Client{Timeout: 5 * time.Minute},
+		apiKey:       apiKey,
+		baseURL:      defaultOpenAIBaseURL,
+		maxRetries:   defaultMaxRetries,
+		baseDelay:    defaultBaseDelay,
+		maxDelay:     defaultMaxDelay,
+		uploadSender: uploadSender{stall: defaultStallTimeout},
 	}
 	for _, opt := range opts {
 		opt(t)
@@ -162,36 +245,153 @@ func NewOpenAITranscriber(apiKey string, opts ...TranscriberOption) *OpenAITrans
 
 // Transcribe transcribes an audio file using OpenAI's API.
 // It automatically retries on transient errors (rate limits, timeouts, server errors).
+// Files over MaxFileSize fail with audio.ErrChunkTooLarge, without retry.
 func (t *OpenAITranscriber) Transcribe(ctx context.Context, audioPath string, opts Options) (string, error) {
+	service, maxSize := t.service, t.maxSize
+	if service == "" {
+		service = "OpenAI"
+	}
+	if maxSize == 0 {
+		maxSize = MaxFileSize
+	}
+	if err := checkFileSize(audioPath, maxSize, service); err != nil {
+		return "", err
+	}
+	if opts.Translate {
+		return t.translate(ctx, audioPath, opts, service)
+	}
+	if t.model != "" {
+		// OpenAI-compatible APIs serve a single whisper model
+		switch {
+		case opts.Diarize:
+			return "", fmt.Errorf("%s: %w", service, ErrDiarizeUnsupported)
+		case opts.Timestamps:
+			return t.transcribeWithRetry(ctx, audioPath, opts, t.model, FormatVerboseJSON, parseVerboseResponse)
+		}
+		return t.transcribeWithRetry(ctx, audioPath, opts, t.model, "json", parseTranscriptionResponse)
+	}
+	switch {
+	case opts.Diarize && opts.Timestamps:
+		return t.transcribeWithRetry(ctx, audioPath, opts, ModelGPT4oTranscribeDiarize, FormatDiarizedJSON, parseDiarizeSegments)
+	case opts.Diarize:
+		return t.transcribeWithRetry(ctx, audioPath, opts, ModelGPT4oTranscribeDiarize, FormatDiarizedJSON, parseDiarizeResponse)
+	case opts.Timestamps:
+		return t.transcribeWithRetry(ctx, audioPath, opts, ModelWhisper1, FormatVerboseJSON, parseVerboseResponse)
+	}
+	return t.transcribeWithRetry(ctx, audioPath, opts, ModelGPT4oMiniTranscribe, "json", parseTranscriptionResponse)
+}
+
+// translate translates an audio file to English with the translations
+// endpoint, which only serves whisper models and cannot identify speakers.
+func (t *OpenAITranscriber) translate(ctx context.Context, audioPath string, opts Options, service string) (string, error) {
 	if opts.Diarize {
-		return t.transcribeWithRetry(ctx, audioPath, opts, ModelGPT4oTranscribeDiarize, FormatDiarizedJSON, true)
+		return "", fmt.Errorf("%s translation: %w", service, ErrDiarizeUnsupported)
+	}
+	model := t.model
+	if model == "" {
+		model = ModelWhisper1
 	}
-	return t.transcribeWithRetry(ctx, audioPath, opts, ModelGPT4oMiniTranscribe, "json", false)
+	if opts.Timestamps {
+		return t.transcribeWithRetry(ctx, audioPath, opts, model, FormatVerboseJSON, parseVerboseResponse)
+	}
+	return t.transcribeWithRetry(ctx, audioPath, opts, model, "json", parseTranscriptionResponse)
+}
+
+// checkFileSize returns audio.ErrChunkTooLarge if audioPath is larger than
+// maxSize, the limit of the service API.
+func checkFileSize(audioPath string, maxSize int64, service string) error {
+	info, err := os.Stat(audioPath)
+	if err != nil || info.Size() <= maxSize {
+		return nil // Missing files fail when opened
+	}
+	return fmt.Errorf("%s is %.1f MB, over the %d MB limit of the %s API: %w",
+		filepath.Base(audioPath), float64(info.Size())/(1<<20), maxSize>>20, service, audio.ErrChunkTooLarge)
 }
 
 // transcribeWithRetry executes the transcription with exponential backoff retry.
-func (t *OpenAITranscriber) transcribeWithRetry(ctx context.Context, audioPath string, opts Options, model, format string, diarize bool) (string, error) {
+// parse converts the response body of the requested format to the result.
+func (t *OpenAITranscriber) transcribeWithRetry(ctx context.Context, audioPath string, opts Options, model, format string, parse func([]byte) (string, error)) (string, error) {
 	cfg := apierr.RetryConfig{
 		MaxRetries: t.maxRetries,
 		BaseDelay:  t.baseDelay,
 		MaxDelay:   t.maxDelay,
 	}
+	return sendWithRetry(ctx, cfg, opts, func() (string, error) {
+		body, err := t.transcribeHTTP(ctx, audioPath, opts, model, format)
+		if err != nil {
+			return "", keepRetryAfter(err, classifyError)
+		}
+		text, err := parse(body)
+		if err != nil {
+			return "", err
+		}
+		opts.usage.Record(parseUsage(body))
+		return text, nil
+	})
+}
 
-	return apierr.RetryWithBackoff(ctx, cfg, func() (string, error) {
-		result, err := t.transcribeHTTP(ctx, audioPath, opts, model, format, diarize)
+// sendWithRetry calls send with exponential backoff until it succeeds or
+// fails with an error that is not retryable. send returns classified errors
+// (apierr sentinels), with the wait requested by the API if any.
+//
+// The first attempt waited for the shared rate limiter of opts before the
+// call (see WithRateLimiter); retries wait for it again, so that parallel
+// workers do not all retry at once after a rate limit.
+func sendWithRetry[T any](ctx context.Context, cfg apierr.RetryConfig, opts Options, send func() (T, error)) (T, error) {
+	limiter := opts.limiter
+	timer := opts.timer
+	retry := false
+	return apierr.RetryWithBackoff(ctx, cfg, func() (T, error) {
+		var zero T
+		if retry {
+			timer.retried()
+			if err := limiter.Wait(ctx, 0); err != nil {
+				return zero, err
+			}
+		}
+		retry = true
+		result, err := send()
 		if err != nil {
-			return "", classifyError(err)
+			if errors.Is(err, apierr.ErrRateLimit) {
+				delay, _ := apierr.RetryAfter(err)
+				limiter.RateLimited(delay)
+			}
+			return zero, err
 		}
+		limiter.Succeeded()
 		return result, nil
 	}, isRetryableError)
 }
 
+// keepRetryAfter classifies err with classify, keeping the wait requested by
+// the API through classification.
+func keepRetryAfter(err error, classify func(error) error) error {
+	delay, _ := apierr.RetryAfter(err)
+	return apierr.WithRetryAfter(classify(err), delay)
+}
+
+// requestURL returns the URL of transcription requests, or of translation
+// requests with opts.Translate: the OpenAI path, or the path of the Azure
+// OpenAI deployment with its API version.
+func (t *OpenAITranscriber) requestURL(opts Options) string {
+	path, operation := transcriptionPath, "transcriptions"
+	if opts.Translate {
+		path, operation = translationPath, "translations"
+	}
+	if t.azure == nil {
+		return t.baseURL + path
+	}
+	return t.baseURL + "/openai/deployments/" + url.PathEscape(t.azure.name) +
+		"/audio/" + operation + "?api-version=" + url.QueryEscape(t.azure.apiVersion)
+}
+
 // transcribeHTTP performs a transcription via direct HTTP to OpenAI's REST API.
-func (t *OpenAITranscriber) transcribeHTTP(ctx context.Context, audioPath string, opts Options, model, format string, diarize bool) (_ string, err error) {
+// Returns the body of the successful response.
+func (t *OpenAITranscriber) transcribeHTTP(ctx context.Context, audioPath string, opts Options, model, format string) (_ []byte, err error) {
 	// Open audio file
 	file, err := os.Open(audioPath) // #nosec G304 -- audioPath is from internal chunking
 	if err != nil {
-		return "", fmt.Errorf("failed to open audio file: %w", err)
+		return nil, fmt.Errorf("failed to open audio file: %w", err)
 	}
 	defer func() { _ = file.Close() }()
 
@@ -202,56 +402,140 @@ func (t *OpenAITranscriber) transcribeHTTP(ctx context.Context, audioPath string
 	// Add file field
 	part, err := writer.CreateFormFile("file", filepath.Base(audioPath))
 	if err != nil {
-		return "", fmt.Errorf("failed to create form file: %w", err)
+		return nil, fmt.Errorf("failed to create form file: %w", err)
 	}
 	if _, err := io.Copy(part, file); err != nil {
-		return "", fmt.Errorf("failed to copy file to form: %w", err)
+		return nil, fmt.Errorf("failed to copy file to form: %w", err)
 	}
 
 	// Add required fields
 	if err := writer.WriteField("model", model); err != nil {
-		return "", fmt.Errorf("failed to write model field: %w", err)
+		return nil, fmt.Errorf("failed to write model field: %w", err)
 	}
 	if err := writer.WriteField("response_format", format); err != nil {
-		return "", fmt.Errorf("failed to write response_format field: %w", err)
+		return nil, fmt.Errorf("failed to write response_format field: %w", err)
 	}
 
 	// Diarization requires chunking_strategy
-	if diarize {
+	if opts.Diarize {
 		if err := writer.WriteField("chunking_strategy", ChunkingStrategyAuto); err != nil {
-			return "", fmt.Errorf("failed to write chunking_strategy field: %w", err)
+			return nil, fmt.Errorf("failed to write chunking_strategy field: %w", err)
+		}
+	}
+
+	// verbose_json only includes segments when asked to (translations
+	// always include them)
+	if format == FormatVerboseJSON && !opts.Translate {
+		if err := writer.WriteField("timestamp_granularities[]", "segment"); err != nil {
+			return nil, fmt.Errorf("failed to write timestamp_granularities field: %w", err)
 		}
 	}
 
 	// Add optional fields
 	if opts.Prompt != "" {
 		if err := writer.WriteField("prompt", opts.Prompt); err != nil {
-			return "", fmt.Errorf("failed to write prompt field: %w", err)
+			return nil, fmt.Errorf("failed to write prompt field: %w", err)
 		}
 	}
-	if langCode := opts.Language.BaseCode(); langCode != "" {
+	// Translations are always to English: the endpoint takes no language
+	if langCode := opts.Language.BaseCode(); langCode != "" && !opts.Translate {
 		if err := writer.WriteField("language", langCode); err != nil {
-			return "", fmt.Errorf("failed to write language field: %w", err)
+			return nil, fmt.Errorf("failed to write language field: %w", err)
 		}
 	}
 
 	if err := writer.Close(); err != nil {
-		return "", fmt.Errorf("failed to close multipart writer: %w", err)
+		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
 	}
 
 	// Create HTTP request
-	url := t.baseURL + transcriptionPath
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	// The body is metered to measure upload throughput (see LastUpload)
+	// and to detect stalled uploads.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.requestURL(opts), nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	if t.azure != nil {
+		req.Header.Set("api-key", t.apiKey)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
 
 	// Execute request
-	resp, err := t.httpClient.Do(req)
+	data := body.Bytes()
+	resp, err := t.send(t.httpClient, req, opts, bytes.NewReader(data), int64(len(data)))
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	// Handle errors
+	if resp.StatusCode != http.StatusOK {
+		return nil, apierr.WithRetryAfter(parseHTTPError(resp.StatusCode, resp.Body), apierr.ParseRetryAfter(resp.Header, time.Now()))
+	}
+
+	return resp.Body, nil
+}
+
+// uploadSender sends request bodies through an uploadReader, and keeps the
+// statistics of the last completed upload. Transcribers uploading audio embed
+// it, which makes them an uploadMeter.
+type uploadSender struct {
+	stall time.Duration // Upload watchdog timeout, 0 to disable
+
+	mu         sync.Mutex
+	lastUpload UploadStats // Most recent completed upload (see LastUpload)
+}
+
+// uploadResponse is the response to a request sent by uploadSender.
+type uploadResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte // Read up to maxResponseSize
+}
+
+// send sends req with a body of size bytes read from body. The upload is
+// metered (see LastUpload and Options.timer), and aborted with an
+// errUploadStalled wrapping apierr.ErrTimeout when nothing is sent for the
+// stall timeout. The transport may replay the body, which is then read again
+// from its start.
+func (s *uploadSender) send(client httpDoer, req *http.Request, opts Options, body io.ReadSeeker, size int64) (_ uploadResponse, err error) {
+	ctx, cancel := context.WithCancelCause(req.Context())
+	defer cancel(nil)
+	upload := &uploadReader{r: body, stall: s.stall}
+	req = req.WithContext(ctx)
+	req.Body = io.NopCloser(upload)
+	req.ContentLength = size
+	// The transport replays the body when a reused connection was closed
+	// before the request was sent: the replay is metered too.
+	req.GetBody = func() (io.ReadCloser, error) {
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		upload.rewind(body)
+		return io.NopCloser(upload), nil
+	}
+
+	upload.start = time.Now()
+	if s.stall > 0 {
+		upload.watchdog = time.AfterFunc(s.stall, func() { cancel(errUploadStalled) })
+	}
+	resp, err := client.Do(req)
+	upload.stopWatchdog()
+	if err != nil {
+		if errors.Is(context.Cause(ctx), errUploadStalled) {
+			// The stalled connection was closed with the request: make sure
+			// the retry does not reuse another connection from the same pool.
+			if c, ok := client.(interface{ CloseIdleConnections() }); ok {
+				c.CloseIdleConnections()
+			}
+			return uploadResponse{}, fmt.Errorf("%w: nothing sent for %s: %w", errUploadStalled, s.stall, apierr.ErrTimeout)
+		}
+		return uploadResponse{}, err
+	}
+	if stats, ok := upload.stats(resp.Header, time.Now()); ok {
+		s.recordUpload(stats)
+		opts.timer.uploaded(stats.Duration)
 	}
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
@@ -262,19 +546,119 @@ func (t *OpenAITranscriber) transcribeHTTP(ctx context.Context, audioPath string
 	// Read response body with size limit
 	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return uploadResponse{}, fmt.Errorf("failed to read response: %w", err)
 	}
+	return uploadResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody}, nil
+}
 
-	// Handle errors
-	if resp.StatusCode != http.StatusOK {
-		return "", parseHTTPError(resp.StatusCode, respBody)
+// sendFile sends req with the audio file at path as its body (see send).
+func (s *uploadSender) sendFile(client httpDoer, req *http.Request, opts Options, path string) (uploadResponse, error) {
+	file, err := os.Open(path) // #nosec G304 -- path is from internal chunking
+	if err != nil {
+		return uploadResponse{}, fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	info, err := file.Stat()
+	if err != nil {
+		return uploadResponse{}, fmt.Errorf("failed to stat audio file: %w", err)
+	}
+	return s.send(client, req, opts, file, info.Size())
+}
+
+// LastUpload returns the statistics of the most recent completed upload.
+// ok is false if no upload has completed yet.
+func (s *uploadSender) LastUpload() (stats UploadStats, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastUpload, s.lastUpload.Bytes > 0
+}
+
+// recordUpload stores the statistics of a completed upload.
+func (s *uploadSender) recordUpload(stats UploadStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastUpload = stats
+}
+
+// processingTimeHeader is the response header in which OpenAI reports how
+// long the request was processed, in milliseconds.
+const processingTimeHeader = "openai-processing-ms"
+
+// uploadReader wraps a request body and measures how long it took to upload.
+//
+// The HTTP transport reads the body as it sends it, but the end of the body
+// is only handed to the kernel, whose socket buffer may still hold it: timed
+// to the end of the body, a small upload looks faster than the connection.
+// When the server reports its processing time (processingTimeHeader), the
+// upload is timed until the response headers instead, minus that time.
+//
+// It also feeds the upload watchdog: the transport stops reading while the
+// connection accepts no data, so a watchdog not reset for its timeout means
+// the upload stalled. The watchdog is stopped once the body is sent: waiting
+// for the response is bounded by the HTTP client timeout.
+type uploadReader struct {
+	stall    time.Duration
+	watchdog *time.Timer
+
+	mu    sync.Mutex // Guards the fields below: the transport may read from another goroutine
+	r     io.Reader
+	start time.Time
+	sent  time.Time // When the end of the body was read, zero until then
+	n     int64
+}
+
+func (u *uploadReader) Read(p []byte) (int, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	n, err := u.r.Read(p)
+	u.n += int64(n)
+	if err == io.EOF {
+		if u.sent.IsZero() {
+			u.sent = time.Now()
+		}
+		if u.watchdog != nil {
+			u.watchdog.Stop()
+			u.watchdog = nil
+		}
+	} else if n > 0 && u.watchdog != nil {
+		u.watchdog.Reset(u.stall)
+	}
+	return n, err
+}
+
+// rewind starts reading the body again from r, when the transport replays
+// the request: the upload is timed from now.
+func (u *uploadReader) rewind(r io.Reader) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.r, u.n = r, 0
+	u.start, u.sent = time.Now(), time.Time{}
+}
+
+// stats returns the upload measured for a response with header, received
+// at now. ok is false if the body was not sent entirely.
+func (u *uploadReader) stats(header http.Header, now time.Time) (stats UploadStats, ok bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.sent.IsZero() {
+		return UploadStats{}, false
+	}
+	// The upload lasts at least until the transport read the whole body.
+	d := u.sent.Sub(u.start)
+	if ms, err := strconv.ParseInt(header.Get(processingTimeHeader), 10, 64); err == nil && ms >= 0 {
+		d = max(d, now.Sub(u.start)-time.Duration(ms)*time.Millisecond)
 	}
+	return UploadStats{Bytes: u.n, Duration: d}, true
+}
 
-	// Parse response based on format
-	if diarize {
-		return parseDiarizeResponse(respBody)
+// stopWatchdog stops the upload watchdog, if any.
+func (u *uploadReader) stopWatchdog() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.watchdog != nil {
+		u.watchdog.Stop()
+		u.watchdog = nil
 	}
-	return parseTranscriptionResponse(respBody)
 }
 
 // transcriptionResponse represents a standard OpenAI transcription JSON response.
@@ -291,6 +675,36 @@ func parseTranscriptionResponse(body []byte) (string, error) {
 	return resp.Text, nil
 }
 
+// verboseResponse represents the OpenAI verbose_json transcription response.
+type verboseResponse struct {
+	Text     string `json:"text"`
+	Segments []struct {
+		Start      float64  `json:"start"`
+		End        float64  `json:"end"`
+		Text       string   `json:"text"`
+		AvgLogprob *float64 `json:"avg_logprob"` // Absent from some compatible APIs
+	} `json:"segments"`
+}
+
+// parseVerboseResponse parses a verbose_json response into encoded timed segments.
+func parseVerboseResponse(body []byte) (string, error) {
+	var resp verboseResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	segments := make([]TimedSegment, len(resp.Segments))
+	for i, seg := range resp.Segments {
+		segments[i] = TimedSegment{
+			Start:      seconds(seg.Start),
+			End:        seconds(seg.End),
+			Text:       seg.Text,
+			Confidence: logprobConfidence(seg.AvgLogprob),
+		}
+	}
+	return EncodeSegments(segments)
+}
+
 // diarizeResponse represents the OpenAI diarized transcription response.
 type diarizeResponse struct {
 	Text     string `json:"text"`
@@ -327,6 +741,25 @@ func parseDiarizeResponse(body []byte) (string, error) {
 	return strings.TrimSpace(b.String()), nil
 }
 
+// parseDiarizeSegments parses the diarized JSON response into encoded timed segments.
+func parseDiarizeSegments(body []byte) (string, error) {
+	var resp diarizeResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	segments := make([]TimedSegment, len(resp.Segments))
+	for i, seg := range resp.Segments {
+		segments[i] = TimedSegment{
+			Start:   seconds(seg.Start),
+			End:     seconds(seg.End),
+			Speaker: seg.Speaker,
+			Text:    seg.Text,
+		}
+	}
+	return EncodeSegments(segments)
+}
+
 // openAIAPIError represents an error response from OpenAI's REST API.
 // Unexported: only used for error classification.
 type openAIAPIError struct {
@@ -386,6 +819,8 @@ func classifyError(err error) error {
 				return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrQuotaExceeded)
 			}
 			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrRateLimit)
+		case http.StatusRequestEntityTooLarge:
+			return fmt.Errorf("%s: %w", apiErr.Message, audio.ErrChunkTooLarge)
 		case http.StatusPaymentRequired:
 			return fmt.Errorf("%s: %w", apiErr.Message, apierr.ErrQuotaExceeded)
 		case http.StatusUnauthorized:
@@ -432,9 +867,39 @@ func isRetryableError(err error) bool {
 	return false
 }
 
+// RunOption configures a run of the chunk functions (TranscribeAll,
+// TranscribeRemaining, TranscribeRemainingAuto, TranscribeStream).
+type RunOption func(*runOptions)
+
+// runOptions holds the settings of a run of the chunk functions.
+type runOptions struct {
+	allowPartial bool          // See WithAllowPartial
+	limiter      *RateLimiter  // See WithRateLimiter
+	usage        *UsageTracker // See WithUsageTracker
+	timings      *Timings      // See WithTimings
+}
+
+// newRunOptions applies opts.
+func newRunOptions(opts []RunOption) runOptions {
+	var r runOptions
+	for _, opt := range opts {
+		opt(&r)
+	}
+	return r
+}
+
+// transcriberOptions returns opts with the settings of r the transcriber
+// uses.
+func (r runOptions) transcriberOptions(opts Options) Options {
+	opts.limiter = r.limiter
+	opts.usage = r.usage
+	return opts
+}
+
 // TranscribeAll transcribes multiple audio chunks in parallel.
 // Results are returned in the same order as the input chunks.
-// If any chunk fails, the entire operation is aborted and the error is returned.
+// If any chunk fails, the entire operation is aborted and the error is returned,
+// unless run has WithAllowPartial (see TranscribeRemaining).
 // maxParallel limits the number of concurrent API requests (1-MaxRecommendedParallel recommended).
 func TranscribeAll(
 	ctx context.Context,
@@ -442,6 +907,32 @@ func TranscribeAll(
 	t Transcriber,
 	opts Options,
 	maxParallel int,
+	run ...RunOption,
+) ([]string, error) {
+	return TranscribeRemaining(ctx, chunks, t, opts, maxParallel, nil, nil, run...)
+}
+
+// TranscribeRemaining is like TranscribeAll, but reuses the text of chunks found
+// in done (keyed by chunk index) instead of transcribing them again.
+// done is only read before transcription starts, so onChunk may update it.
+// onChunk, if non-nil, is called after each newly transcribed chunk, one call at a
+// time, so it can persist progress (see Checkpoint). Chunks completed before an
+// error or cancellation have already been reported through onChunk.
+// Chunk progress is also reported to the progress hooks of ctx, the audio of
+// transcribed chunks to the UsageTracker of WithUsageTracker, and their timing
+// to the Timings of WithTimings; reused chunks are not reported.
+// Chunks wait for the RateLimiter of WithRateLimiter, if any, before being
+// sent. With WithAllowPartial, failed chunks do not abort the others: the results
+// are returned with a PartialError if some still fail.
+func TranscribeRemaining(
+	ctx context.Context,
+	chunks []audio.Chunk,
+	t Transcriber,
+	opts Options,
+	maxParallel int,
+	done map[int]string,
+	onChunk func(index int, text string),
+	run ...RunOption,
 ) ([]string, error) {
 	if len(chunks) == 0 {
 		return nil, nil
@@ -455,24 +946,210 @@ func TranscribeAll(
 	// Semaphore channel for concurrency control.
 	// Not closed explicitly: it's local to this function and will be GC'd.
 	sem := make(chan struct{}, maxParallel)
+	var mu sync.Mutex // Serializes onChunk calls and guards failed.
+	var failed []int  // Chunks to retry at the end, in partial failure mode.
+	runOpts := newRunOptions(run)
+	partial := runOpts.allowPartial
+	opts = runOpts.transcriberOptions(opts)
+
+	// Collect remaining chunks before any goroutine can call onChunk.
+	var remaining []int
+	for i, chunk := range chunks {
+		if text, ok := done[chunk.Index]; ok {
+			results[i] = text
+			continue
+		}
+		remaining = append(remaining, i)
+	}
 
-	g, ctx := errgroup.WithContext(ctx)
+	// complete records the text of chunk i.
+	complete := func(i int, text string) {
+		results[i] = text
+		if onChunk != nil {
+			mu.Lock()
+			onChunk(chunks[i].Index, text)
+			mu.Unlock()
+		}
+	}
 
-	for i, chunk := range chunks {
+	g, gctx := errgroup.WithContext(ctx)
+
+	for _, i := range remaining {
 		g.Go(func() error {
 			// Acquire semaphore slot.
 			select {
 			case sem <- struct{}{}:
-			case <-ctx.Done():
-				return ctx.Err()
+			case <-gctx.Done():
+				return gctx.Err()
 			}
 			defer func() { <-sem }()
 
-			text, err := t.Transcribe(ctx, chunk.Path, opts)
+			text, err := transcribeChunk(gctx, t, chunks[i], len(chunks), opts, runOpts.timings)
+			if err != nil {
+				if !partial || isFatalChunkError(gctx, err) {
+					return err
+				}
+				mu.Lock()
+				failed = append(failed, i)
+				mu.Unlock()
+				return nil
+			}
+			complete(i, text)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	if len(failed) == 0 {
+		return results, nil
+	}
+
+	// Retry failed chunks once the others are done, one at a time: transient
+	// failures (rate limits, network) may have cleared by then.
+	slices.Sort(failed)
+	var partialErr PartialError
+	for _, i := range failed {
+		text, err := transcribeChunk(ctx, t, chunks[i], len(chunks), opts, runOpts.timings)
+		if err != nil {
+			if isFatalChunkError(ctx, err) {
+				return nil, err
+			}
+			// Every failed chunk is reported, so that it gets a marker
+			var chunkErr *ChunkError
+			if !errors.As(err, &chunkErr) {
+				chunkErr = &ChunkError{Chunk: chunks[i], Err: err}
+			}
+			partialErr.Failed = append(partialErr.Failed, chunkErr)
+			continue
+		}
+		complete(i, text)
+	}
+	if len(partialErr.Failed) > 0 {
+		return results, &partialErr
+	}
+	return results, nil
+}
+
+// transcribeChunk transcribes chunk, the progress of which is reported out of
+// total chunks and the timing recorded in timings if not nil. Returns a
+// *ChunkError on failure.
+func transcribeChunk(ctx context.Context, t Transcriber, chunk audio.Chunk, total int, opts Options, timings *Timings) (string, error) {
+	timer := startChunkTimer(timings, chunk)
+	opts.timer = timer
+	start := time.Now()
+	if err := opts.limiter.Wait(ctx, chunk.Duration()); err != nil {
+		return "", err
+	}
+	wait := time.Since(start)
+	progress.ChunkStart(ctx, chunk.Index, total)
+	start = time.Now()
+	text, err := t.Transcribe(ctx, chunk.Path, opts)
+	timer.finish(wait, time.Since(start), err)
+	progress.ChunkDone(ctx, chunk.Index, total, err)
+	if err != nil {
+		return "", &ChunkError{Chunk: chunk, Err: err}
+	}
+	opts.usage.Record(Usage{Audio: chunk.Duration()})
+	return text, nil
+}
+
+// Segment is a transcribed chunk delivered by TranscribeStream.
+type Segment struct {
+	Chunk audio.Chunk
+	Text  string
+}
+
+// TranscribeStream transcribes chunks as they arrive on the channel, until it is closed.
+// Up to maxParallel chunks are transcribed concurrently, but onSegment is called
+// sequentially and in arrival order, so partial results can be printed or appended
+// as soon as every earlier chunk is done. onSegment may be nil.
+// Returns all results in arrival order. If any chunk fails, the operation is aborted
+// and the error is returned; the caller should then stop producing chunks.
+// Chunk progress is reported to the progress hooks of ctx, with a total of 0,
+// and the audio of transcribed chunks to the UsageTracker of WithUsageTracker.
+// Chunks wait for the RateLimiter of WithRateLimiter, if any, before being sent.
+func TranscribeStream(
+	ctx context.Context,
+	chunks <-chan audio.Chunk,
+	t Transcriber,
+	opts Options,
+	maxParallel int,
+	onSegment func(Segment),
+	run ...RunOption,
+) ([]string, error) {
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+	opts = newRunOptions(run).transcriberOptions(opts)
+
+	var (
+		mu      sync.Mutex
+		results []string
+		pending []*Segment // Completed segments by position, nil while in flight.
+		next    int        // Position of the next segment to deliver.
+	)
+	sem := make(chan struct{}, maxParallel)
+
+	g, gctx := errgroup.WithContext(ctx)
+
+	// deliver hands completed segments to onSegment in order. Must hold mu.
+	deliver := func() {
+		for next < len(pending) && pending[next] != nil {
+			if onSegment != nil {
+				onSegment(*pending[next])
+			}
+			pending[next] = nil
+			next++
+		}
+	}
+
+dispatch:
+	for {
+		var chunk audio.Chunk
+		select {
+		case c, ok := <-chunks:
+			if !ok {
+				break dispatch
+			}
+			chunk = c
+		case <-gctx.Done():
+			break dispatch
+		}
+
+		// Acquire the slot before spawning, so a fast producer cannot pile up goroutines.
+		select {
+		case sem <- struct{}{}:
+		case <-gctx.Done():
+			break dispatch
+		}
+
+		mu.Lock()
+		pos := len(results)
+		results = append(results, "")
+		pending = append(pending, nil)
+		mu.Unlock()
+
+		g.Go(func() error {
+			defer func() { <-sem }()
+
+			if err := opts.limiter.Wait(gctx, chunk.Duration()); err != nil {
+				return err
+			}
+			progress.ChunkStart(gctx, chunk.Index, 0)
+			text, err := t.Transcribe(gctx, chunk.Path, opts)
+			progress.ChunkDone(gctx, chunk.Index, 0, err)
 			if err != nil {
 				return fmt.Errorf("chunk %d (%s): %w", chunk.Index, filepath.Base(chunk.Path), err)
 			}
-			results[i] = text
+			opts.usage.Record(Usage{Audio: chunk.Duration()})
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[pos] = text
+			pending[pos] = &Segment{Chunk: chunk, Text: text}
+			deliver()
 			return nil
 		})
 	}
@@ -480,6 +1157,9 @@ func TranscribeAll(
 	if err := g.Wait(); err != nil {
 		return nil, err
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	return results, nil
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/alnah/go-transcript/internal/apierr"
 	"github.com/alnah/go-transcript/internal/audio"
 	"github.com/alnah/go-transcript/internal/lang"
+	"github.com/alnah/go-transcript/internal/progress"
 	"github.com/alnah/go-transcript/internal/transcribe"
 )
 
@@ -209,6 +210,12 @@ func (m *mockOpenAIServer) lastCall() openAITranscribeCall {
 	return m.calls[len(m.calls)-1]
 }
 
+func (m *mockOpenAIServer) callCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls)
+}
+
 // mockTranscriber implements transcribe.Transcriber for TranscribeAll tests.
 type mockTranscriber struct {
 	mu         sync.Mutex
@@ -841,6 +848,12 @@ func TestTranscribe_ErrorClassification(t *testing.T) {
 			responseBody: `{"error": {"message": "Model not found"}}`,
 			wantSentinel: apierr.ErrBadRequest,
 		},
+		{
+			name:         "413 request too large returns ErrChunkTooLarge",
+			statusCode:   http.StatusRequestEntityTooLarge,
+			responseBody: `{"error": {"message": "Maximum content size limit exceeded"}}`,
+			wantSentinel: audio.ErrChunkTooLarge,
+		},
 	}
 
 	for _, tt := range tests {
@@ -865,6 +878,32 @@ func TestTranscribe_ErrorClassification(t *testing.T) {
 	}
 }
 
+func TestTranscribe_FileTooLarge(t *testing.T) {
+	t.Parallel()
+
+	// Sparse file: over the limit without writing 25MB
+	audioPath := filepath.Join(t.TempDir(), "chunk_000.ogg")
+	f, err := os.Create(audioPath)
+	if err != nil {
+		t.Fatalf("failed to create audio file: %v", err)
+	}
+	if err := f.Truncate(transcribe.MaxFileSize + 1); err != nil {
+		t.Fatalf("failed to size audio file: %v", err)
+	}
+	_ = f.Close()
+
+	httpMock := newMockHTTPClient(http.StatusOK, `{"text": "never sent"}`)
+	tr := transcribe.NewTestTranscriber(httpMock, "http://fake-api.test")
+
+	_, err = tr.Transcribe(context.Background(), audioPath, transcribe.Options{})
+	if !errors.Is(err, audio.ErrChunkTooLarge) {
+		t.Errorf("Transcribe() error = %v, want ErrChunkTooLarge", err)
+	}
+	if len(httpMock.requestBodies) != 0 {
+		t.Errorf("sent %d requests, want none for a file over the limit", len(httpMock.requestBodies))
+	}
+}
+
 // ---------------------------------------------------------------------------
 // TestTranscribe_Retry - Retry behavior with backoff
 // ---------------------------------------------------------------------------
@@ -913,6 +952,47 @@ func TestTranscribe_Retry(t *testing.T) {
 		}
 	})
 
+	t.Run("waits as long as Retry-After requests", func(t *testing.T) {
+		t.Parallel()
+		audioPath := createTempAudioFile(t)
+
+		httpMock := &mockHTTPClient{
+			responses: []*http.Response{
+				{
+					StatusCode: http.StatusTooManyRequests,
+					Body:       io.NopCloser(bytes.NewReader([]byte(`{"error": {"message": "Rate limit exceeded"}}`))),
+					Header:     http.Header{"Retry-After": []string{"0.001"}},
+				},
+				{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader([]byte(`{"text": "success"}`))),
+					Header:     make(http.Header),
+				},
+			},
+		}
+
+		var delays []time.Duration
+		ctx := progress.WithHooks(context.Background(), progress.Hooks{
+			OnRetry: func(attempt int, delay time.Duration, err error) {
+				delays = append(delays, delay)
+			},
+		})
+
+		// The backoff delay would make the test time out.
+		tr := transcribe.NewTestTranscriber(httpMock, "http://fake-api.test",
+			transcribe.WithMaxRetries(3),
+			transcribe.WithRetryDelays(time.Hour, time.Hour),
+		)
+
+		result, err := tr.Transcribe(ctx, audioPath, transcribe.Options{})
+		if err != nil || result != "success" {
+			t.Fatalf("Transcribe() = %q, %v; want success", result, err)
+		}
+		if len(delays) != 1 || delays[0] != time.Millisecond {
+			t.Errorf("retry delays = %v, want [1ms]", delays)
+		}
+	})
+
 	t.Run("retries on server error 500", func(t *testing.T) {
 		t.Parallel()
 		audioPath := createTempAudioFile(t)
@@ -1036,10 +1116,209 @@ func TestTranscribe_Retry(t *testing.T) {
 	})
 }
 
+// stallingHTTPClient reads the request body at a given pace. The first
+// stalls calls stop reading after a few bytes and hang until the request is
+// canceled, like a dead connection; later calls succeed.
+type stallingHTTPClient struct {
+	stalls int
+	pace   time.Duration // Delay between reads of 16 bytes
+	calls  atomic.Int32
+	closed atomic.Int32
+}
+
+func (c *stallingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	call := int(c.calls.Add(1))
+	buf := make([]byte, 16)
+	if call <= c.stalls {
+		_, _ = req.Body.Read(buf)
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}
+	for {
+		time.Sleep(c.pace)
+		if _, err := req.Body.Read(buf); err != nil {
+			break
+		}
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"text": "uploaded"}`)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func (c *stallingHTTPClient) CloseIdleConnections() {
+	c.closed.Add(1)
+}
+
+func TestTranscribe_StallWatchdog(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stalled upload is retried on a fresh connection", func(t *testing.T) {
+		t.Parallel()
+		audioPath := createTempAudioFile(t)
+
+		client := &stallingHTTPClient{stalls: 1}
+		tr := transcribe.NewTestTranscriber(client, "http://fake-api.test",
+			transcribe.WithMaxRetries(2),
+			transcribe.WithRetryDelays(time.Millisecond, time.Millisecond),
+			transcribe.WithStallTimeout(20*time.Millisecond),
+		)
+
+		result, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{})
+		if err != nil {
+			t.Fatalf("Transcribe() unexpected error: %v", err)
+		}
+		if result != "uploaded" {
+			t.Errorf("got %q, want %q", result, "uploaded")
+		}
+		if got := client.calls.Load(); got != 2 {
+			t.Errorf("call count = %d, want 2", got)
+		}
+		if got := client.closed.Load(); got != 1 {
+			t.Errorf("CloseIdleConnections() called %d times, want 1", got)
+		}
+	})
+
+	t.Run("stalled upload fails with ErrTimeout after retries", func(t *testing.T) {
+		t.Parallel()
+		audioPath := createTempAudioFile(t)
+
+		client := &stallingHTTPClient{stalls: 10}
+		tr := transcribe.NewTestTranscriber(client, "http://fake-api.test",
+			transcribe.WithMaxRetries(1),
+			transcribe.WithRetryDelays(time.Millisecond, time.Millisecond),
+			transcribe.WithStallTimeout(20*time.Millisecond),
+		)
+
+		_, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{})
+		if !errors.Is(err, apierr.ErrTimeout) {
+			t.Errorf("error = %v, want ErrTimeout", err)
+		}
+		if err == nil || !strings.Contains(err.Error(), "upload stalled") {
+			t.Errorf("error = %v, want mention of the stalled upload", err)
+		}
+		if got := client.calls.Load(); got != 2 {
+			t.Errorf("call count = %d, want 2", got)
+		}
+	})
+
+	t.Run("slow upload making progress is not aborted", func(t *testing.T) {
+		t.Parallel()
+		audioPath := createTempAudioFile(t)
+
+		// Each read comes before the watchdog fires, but the whole upload
+		// takes longer than its timeout.
+		client := &stallingHTTPClient{pace: 10 * time.Millisecond}
+		tr := transcribe.NewTestTranscriber(client, "http://fake-api.test",
+			transcribe.WithMaxRetries(0),
+			transcribe.WithStallTimeout(50*time.Millisecond),
+		)
+
+		if _, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{}); err != nil {
+			t.Fatalf("Transcribe() unexpected error: %v", err)
+		}
+		if got := client.calls.Load(); got != 1 {
+			t.Errorf("call count = %d, want 1", got)
+		}
+	})
+}
+
 // ---------------------------------------------------------------------------
 // TestTranscribe_Options - Option functions
 // ---------------------------------------------------------------------------
 
+func TestTranscribe_Timestamps(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		diarize     bool
+		response    string
+		wantFields  []string
+		wantSegment []transcribe.TimedSegment
+	}{
+		{
+			name:       "verbose_json with whisper-1",
+			response:   `{"text": "Hello there. General Kenobi.", "segments": [{"start": 0.0, "end": 1.52, "text": " Hello there."}, {"start": 1.52, "end": 3.0, "text": " General Kenobi."}]}`,
+			wantFields: []string{transcribe.ModelWhisper1, transcribe.FormatVerboseJSON, "timestamp_granularities[]"},
+			wantSegment: []transcribe.TimedSegment{
+				{Start: 0, End: 1520 * time.Millisecond, Text: "Hello there."},
+				{Start: 1520 * time.Millisecond, End: 3 * time.Second, Text: "General Kenobi."},
+			},
+		},
+		{
+			name:       "avg_logprob is a confidence",
+			response:   `{"text": "Hello there.", "segments": [{"start": 0.0, "end": 1.52, "text": " Hello there.", "avg_logprob": -0.6931}]}`,
+			wantFields: []string{transcribe.ModelWhisper1, transcribe.FormatVerboseJSON},
+			wantSegment: []transcribe.TimedSegment{
+				{Start: 0, End: 1520 * time.Millisecond, Text: "Hello there.", Confidence: 0.5},
+			},
+		},
+		{
+			name:       "diarized segments keep speakers",
+			diarize:    true,
+			response:   `{"text": "Hi. Hello.", "segments": [{"id": "0", "start": 0.5, "end": 1.0, "text": "Hi.", "speaker": "A"}, {"id": "1", "start": 1.0, "end": 2.0, "text": " ", "speaker": "B"}, {"id": "2", "start": 2.0, "end": 2.5, "text": "Hello.", "speaker": "B"}]}`,
+			wantFields: []string{transcribe.ModelGPT4oTranscribeDiarize, transcribe.FormatDiarizedJSON},
+			wantSegment: []transcribe.TimedSegment{
+				{Start: 500 * time.Millisecond, End: time.Second, Speaker: "A", Text: "Hi."},
+				{Start: 2 * time.Second, End: 2500 * time.Millisecond, Speaker: "B", Text: "Hello."},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			audioPath := createTempAudioFile(t)
+
+			httpMock := newMockHTTPClient(http.StatusOK, tt.response)
+			tr := transcribe.NewTestTranscriber(httpMock, "http://fake-api.test", transcribe.MinimalRetryOpts()...)
+
+			got, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{Diarize: tt.diarize, Timestamps: true})
+			if err != nil {
+				t.Fatalf("Transcribe() unexpected error: %v", err)
+			}
+
+			body := string(httpMock.requestBodies[0])
+			for _, field := range tt.wantFields {
+				if !strings.Contains(body, field) {
+					t.Errorf("request body missing %q", field)
+				}
+			}
+
+			segments, err := transcribe.ParseSegments(got)
+			if err != nil {
+				t.Fatalf("ParseSegments() unexpected error: %v", err)
+			}
+			if len(segments) != len(tt.wantSegment) {
+				t.Fatalf("got %d segments, want %d: %+v", len(segments), len(tt.wantSegment), segments)
+			}
+			for i := range segments {
+				if segments[i] != tt.wantSegment[i] {
+					t.Errorf("segment %d = %+v, want %+v", i, segments[i], tt.wantSegment[i])
+				}
+			}
+		})
+	}
+
+	t.Run("plain transcription does not request timestamps", func(t *testing.T) {
+		t.Parallel()
+		audioPath := createTempAudioFile(t)
+
+		httpMock := newMockHTTPClient(http.StatusOK, `{"text": "ok"}`)
+		tr := transcribe.NewTestTranscriber(httpMock, "http://fake-api.test", transcribe.MinimalRetryOpts()...)
+
+		if _, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{}); err != nil {
+			t.Fatalf("Transcribe() unexpected error: %v", err)
+		}
+		body := string(httpMock.requestBodies[0])
+		if strings.Contains(body, "timestamp_granularities") || strings.Contains(body, transcribe.ModelWhisper1) {
+			t.Errorf("request body = %q, want no timestamp request", body)
+		}
+	})
+}
+
 func TestTranscribe_Options(t *testing.T) {
 	t.Parallel()
 
@@ -1098,6 +1377,152 @@ func TestTranscribe_Options(t *testing.T) {
 	})
 }
 
+func TestTranscribe_Azure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		deployment string
+		apiVersion string
+		wantURL    string
+	}{
+		{
+			name:       "deployment and API version",
+			deployment: "whisper",
+			apiVersion: "2025-03-01-preview",
+			wantURL:    "https://contoso.openai.azure.com/openai/deployments/whisper/audio/transcriptions?api-version=2025-03-01-preview",
+		},
+		{
+			name:       "default API version",
+			deployment: "gpt-4o mini",
+			wantURL:    "https://contoso.openai.azure.com/openai/deployments/gpt-4o%20mini/audio/transcriptions?api-version=" + transcribe.DefaultAzureAPIVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			audioPath := createTempAudioFile(t)
+
+			httpMock := newMockHTTPClient(http.StatusOK, `{"text": "hello"}`)
+			tr := transcribe.NewTestTranscriber(httpMock, "https://contoso.openai.azure.com",
+				transcribe.WithAzure(tt.deployment, tt.apiVersion),
+			)
+
+			result, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{})
+			if err != nil {
+				t.Fatalf("Transcribe() unexpected error: %v", err)
+			}
+			if result != "hello" {
+				t.Errorf("got %q, want %q", result, "hello")
+			}
+			req := httpMock.requests[0]
+			if got := req.URL.String(); got != tt.wantURL {
+				t.Errorf("URL = %q, want %q", got, tt.wantURL)
+			}
+			if got := req.Header.Get("api-key"); got != "test-api-key" {
+				t.Errorf("api-key header = %q, want %q", got, "test-api-key")
+			}
+			if got := req.Header.Get("Authorization"); got != "" {
+				t.Errorf("Authorization header = %q, want none", got)
+			}
+		})
+	}
+}
+
+func TestTranscribe_Translate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      []transcribe.TranscriberOption
+		baseURL   string
+		wantURL   string
+		wantModel string
+	}{
+		{
+			name:      "openai",
+			baseURL:   "http://fake-api.test",
+			wantURL:   "http://fake-api.test/v1/audio/translations",
+			wantModel: transcribe.ModelWhisper1,
+		},
+		{
+			name:      "azure deployment",
+			opts:      []transcribe.TranscriberOption{transcribe.WithAzure("whisper", "2025-03-01-preview")},
+			baseURL:   "https://contoso.openai.azure.com",
+			wantURL:   "https://contoso.openai.azure.com/openai/deployments/whisper/audio/translations?api-version=2025-03-01-preview",
+			wantModel: transcribe.ModelWhisper1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			audioPath := createTempAudioFile(t)
+
+			httpMock := newMockHTTPClient(http.StatusOK, `{"text": "hello"}`)
+			tr := transcribe.NewTestTranscriber(httpMock, tt.baseURL, tt.opts...)
+
+			result, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{
+				Translate: true,
+				Language:  lang.MustParse("fr"),
+			})
+			if err != nil {
+				t.Fatalf("Transcribe() unexpected error: %v", err)
+			}
+			if result != "hello" {
+				t.Errorf("got %q, want %q", result, "hello")
+			}
+			if got := httpMock.requests[0].URL.String(); got != tt.wantURL {
+				t.Errorf("URL = %q, want %q", got, tt.wantURL)
+			}
+			body := string(httpMock.requestBodies[0])
+			if !strings.Contains(body, tt.wantModel) {
+				t.Errorf("request body has no model %q", tt.wantModel)
+			}
+			if strings.Contains(body, `name="language"`) {
+				t.Error("request body has a language field, the translations endpoint takes none")
+			}
+		})
+	}
+
+	t.Run("timestamps without granularities", func(t *testing.T) {
+		t.Parallel()
+		audioPath := createTempAudioFile(t)
+
+		httpMock := newMockHTTPClient(http.StatusOK, `{"text": "hello", "segments": [{"start": 0, "end": 1.5, "text": "hello"}]}`)
+		tr := transcribe.NewTestTranscriber(httpMock, "http://fake-api.test")
+
+		result, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{Translate: true, Timestamps: true})
+		if err != nil {
+			t.Fatalf("Transcribe() unexpected error: %v", err)
+		}
+		segments, err := transcribe.ParseSegments(result)
+		if err != nil || len(segments) != 1 || segments[0].Text != "hello" {
+			t.Errorf("ParseSegments() = %v, %v, want the hello segment", segments, err)
+		}
+		if strings.Contains(string(httpMock.requestBodies[0]), "timestamp_granularities") {
+			t.Error("request body has timestamp_granularities, the translations endpoint takes none")
+		}
+	})
+
+	t.Run("diarize unsupported", func(t *testing.T) {
+		t.Parallel()
+		audioPath := createTempAudioFile(t)
+
+		httpMock := newMockHTTPClient(http.StatusOK, `{"text": "hello"}`)
+		tr := transcribe.NewTestTranscriber(httpMock, "http://fake-api.test")
+
+		_, err := tr.Transcribe(context.Background(), audioPath, transcribe.Options{Translate: true, Diarize: true})
+		if !errors.Is(err, transcribe.ErrDiarizeUnsupported) {
+			t.Errorf("Transcribe() error = %v, want ErrDiarizeUnsupported", err)
+		}
+		if httpMock.CallCount() != 0 {
+			t.Errorf("HTTP call count = %d, want 0", httpMock.CallCount())
+		}
+	})
+}
+
 // ---------------------------------------------------------------------------
 // TestClassifyError - Exported internal function
 // ---------------------------------------------------------------------------
@@ -1476,3 +1901,300 @@ func TestTranscribeAll(t *testing.T) {
 		}
 	})
 }
+
+// ---------------------------------------------------------------------------
+// TestTranscribeRemaining - Resuming from cached results
+// ---------------------------------------------------------------------------
+
+func TestTranscribeRemaining(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reuses done chunks and reports new ones", func(t *testing.T) {
+		t.Parallel()
+
+		mock := newMockTranscriber()
+		mock.results["/path/chunk1.mp3"] = "second"
+		mock.results["/path/chunk2.mp3"] = "third"
+		mock.errors["/path/chunk0.mp3"] = errors.New("chunk 0 must not be transcribed again")
+
+		chunks := []audio.Chunk{
+			{Path: "/path/chunk0.mp3", Index: 0},
+			{Path: "/path/chunk1.mp3", Index: 1},
+			{Path: "/path/chunk2.mp3", Index: 2},
+		}
+
+		reported := make(map[int]string)
+		results, err := transcribe.TranscribeRemaining(
+			context.Background(),
+			chunks,
+			mock,
+			transcribe.Options{},
+			4,
+			map[int]string{0: "first"},
+			func(index int, text string) { reported[index] = text },
+		)
+
+		if err != nil {
+			t.Fatalf("TranscribeRemaining() unexpected error: %v", err)
+		}
+		want := []string{"first", "second", "third"}
+		if strings.Join(results, "|") != strings.Join(want, "|") {
+			t.Errorf("results = %v, want %v", results, want)
+		}
+		if len(reported) != 2 || reported[1] != "second" || reported[2] != "third" {
+			t.Errorf("reported = %v, want chunks 1 and 2 only", reported)
+		}
+	})
+
+	t.Run("reports completed chunks before failing", func(t *testing.T) {
+		t.Parallel()
+
+		// Chunk 1 fails only once chunk 0 has been reported.
+		reportedFirst := make(chan struct{})
+		tr := transcriberFunc(func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+			if audioPath == "/path/chunk1.mp3" {
+				<-reportedFirst
+				return "", errors.New("network down")
+			}
+			return "first", nil
+		})
+
+		chunks := []audio.Chunk{
+			{Path: "/path/chunk0.mp3", Index: 0},
+			{Path: "/path/chunk1.mp3", Index: 1},
+		}
+
+		reported := make(map[int]string)
+		_, err := transcribe.TranscribeRemaining(
+			context.Background(),
+			chunks,
+			tr,
+			transcribe.Options{},
+			2,
+			nil,
+			func(index int, text string) {
+				reported[index] = text
+				close(reportedFirst)
+			},
+		)
+
+		if err == nil {
+			t.Fatal("TranscribeRemaining() expected error, got nil")
+		}
+		if reported[0] != "first" {
+			t.Errorf("reported = %v, want chunk 0 reported", reported)
+		}
+	})
+
+	t.Run("reports chunk progress to hooks", func(t *testing.T) {
+		t.Parallel()
+
+		mock := newMockTranscriber()
+		mock.errors["/path/chunk2.mp3"] = errors.New("network down")
+
+		var (
+			mu      sync.Mutex
+			started = make(map[int]int)
+			done    = make(map[int]error)
+		)
+		ctx := progress.WithHooks(context.Background(), progress.Hooks{
+			OnChunkStart: func(index, total int) {
+				mu.Lock()
+				defer mu.Unlock()
+				started[index] = total
+			},
+			OnChunkDone: func(index, total int, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				done[index] = err
+			},
+		})
+
+		// Chunk 0 is reused, chunk 1 succeeds.
+		chunks := []audio.Chunk{
+			{Path: "/path/chunk0.mp3", Index: 0},
+			{Path: "/path/chunk1.mp3", Index: 1},
+		}
+		if _, err := transcribe.TranscribeRemaining(ctx, chunks, mock, transcribe.Options{}, 2, map[int]string{0: "first"}, nil); err != nil {
+			t.Fatalf("TranscribeRemaining() unexpected error: %v", err)
+		}
+
+		// Chunk 2 fails.
+		failing := []audio.Chunk{{Path: "/path/chunk2.mp3", Index: 2}}
+		if _, err := transcribe.TranscribeRemaining(ctx, failing, mock, transcribe.Options{}, 1, nil, nil); err == nil {
+			t.Fatal("TranscribeRemaining() expected error, got nil")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := started[0]; ok {
+			t.Error("reused chunk 0 was reported as started")
+		}
+		if started[1] != 2 || started[2] != 1 {
+			t.Errorf("started = %v, want chunk 1 of 2 and chunk 2 of 1", started)
+		}
+		if err, ok := done[1]; !ok || err != nil {
+			t.Errorf("chunk 1 done = %v (reported %v), want success", err, ok)
+		}
+		if done[2] == nil {
+			t.Error("chunk 2 done without its error")
+		}
+	})
+}
+
+// transcriberFunc adapts a function to transcribe.Transcriber.
+type transcriberFunc func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error)
+
+func (f transcriberFunc) Transcribe(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+	return f(ctx, audioPath, opts)
+}
+
+// ---------------------------------------------------------------------------
+// TestTranscribeStream - Streaming transcription
+// ---------------------------------------------------------------------------
+
+// chunkChannel returns a closed channel pre-filled with the given chunks.
+func chunkChannel(chunks ...audio.Chunk) <-chan audio.Chunk {
+	ch := make(chan audio.Chunk, len(chunks))
+	for _, c := range chunks {
+		ch <- c
+	}
+	close(ch)
+	return ch
+}
+
+func TestTranscribeStream(t *testing.T) {
+	t.Parallel()
+
+	t.Run("closed channel returns no results", func(t *testing.T) {
+		t.Parallel()
+
+		results, err := transcribe.TranscribeStream(context.Background(), chunkChannel(), newMockTranscriber(), transcribe.Options{}, 2, nil)
+		if err != nil {
+			t.Errorf("TranscribeStream() unexpected error: %v", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("got %v, want no results", results)
+		}
+	})
+
+	t.Run("segments delivered in order despite completion order", func(t *testing.T) {
+		t.Parallel()
+
+		mock := newMockTranscriber()
+		mock.results["/seg0.ogg"] = "zero"
+		mock.results["/seg1.ogg"] = "one"
+		mock.results["/seg2.ogg"] = "two"
+
+		// Block the first segment until the others have been transcribed.
+		slow := &slowFirstTranscriber{mockTranscriber: mock, slowPath: "/seg0.ogg", release: make(chan struct{})}
+
+		var delivered []string
+		results, err := transcribe.TranscribeStream(
+			context.Background(),
+			chunkChannel(
+				audio.Chunk{Path: "/seg0.ogg", Index: 0},
+				audio.Chunk{Path: "/seg1.ogg", Index: 1},
+				audio.Chunk{Path: "/seg2.ogg", Index: 2},
+			),
+			slow,
+			transcribe.Options{},
+			3,
+			func(seg transcribe.Segment) {
+				delivered = append(delivered, seg.Text)
+				if seg.Chunk.Index == 0 {
+					// Segments 1 and 2 must not have been delivered before 0.
+					if len(delivered) != 1 {
+						t.Errorf("segment 0 delivered after %v", delivered[:len(delivered)-1])
+					}
+				}
+			},
+		)
+		if err != nil {
+			t.Fatalf("TranscribeStream() unexpected error: %v", err)
+		}
+
+		want := []string{"zero", "one", "two"}
+		if strings.Join(results, ",") != strings.Join(want, ",") {
+			t.Errorf("results = %v, want %v", results, want)
+		}
+		if strings.Join(delivered, ",") != strings.Join(want, ",") {
+			t.Errorf("delivered = %v, want %v", delivered, want)
+		}
+	})
+
+	t.Run("error aborts with chunk context", func(t *testing.T) {
+		t.Parallel()
+
+		mock := newMockTranscriber()
+		mock.errors["/seg1.ogg"] = apierr.ErrRateLimit
+
+		_, err := transcribe.TranscribeStream(
+			context.Background(),
+			chunkChannel(
+				audio.Chunk{Path: "/seg0.ogg", Index: 0},
+				audio.Chunk{Path: "/seg1.ogg", Index: 1},
+			),
+			mock,
+			transcribe.Options{},
+			2,
+			nil,
+		)
+		if !errors.Is(err, apierr.ErrRateLimit) {
+			t.Errorf("TranscribeStream() error = %v, want ErrRateLimit", err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "chunk 1") {
+			t.Errorf("TranscribeStream() error = %q, want chunk index", err.Error())
+		}
+	})
+
+	t.Run("respects maxParallel", func(t *testing.T) {
+		t.Parallel()
+
+		mock := newMockTranscriber()
+		chunks := make([]audio.Chunk, 6)
+		for i := range chunks {
+			chunks[i] = audio.Chunk{Path: filepath.Join("/seg", strings.Repeat("x", i+1)), Index: i}
+		}
+
+		if _, err := transcribe.TranscribeStream(context.Background(), chunkChannel(chunks...), mock, transcribe.Options{}, 2, nil); err != nil {
+			t.Fatalf("TranscribeStream() unexpected error: %v", err)
+		}
+		if atomic.LoadInt32(&mock.maxConc) > 2 {
+			t.Errorf("maxConcurrent = %d, want <= 2", mock.maxConc)
+		}
+	})
+
+	t.Run("context cancellation stops waiting for chunks", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		chunks := make(chan audio.Chunk) // Never closed.
+		cancel()
+
+		_, err := transcribe.TranscribeStream(ctx, chunks, newMockTranscriber(), transcribe.Options{}, 2, nil)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("TranscribeStream() error = %v, want context.Canceled", err)
+		}
+	})
+}
+
+// slowFirstTranscriber delays one path until every other call has completed.
+type slowFirstTranscriber struct {
+	*mockTranscriber
+	slowPath string
+	release  chan struct{}
+	done     int32
+}
+
+func (s *slowFirstTranscriber) Transcribe(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+	if audioPath == s.slowPath {
+		<-s.release
+		return s.mockTranscriber.Transcribe(ctx, audioPath, opts)
+	}
+	text, err := s.mockTranscriber.Transcribe(ctx, audioPath, opts)
+	if atomic.AddInt32(&s.done, 1) == 2 {
+		close(s.release)
+	}
+	return text, err
+}
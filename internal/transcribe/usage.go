@@ -0,0 +1,87 @@
+package transcribe
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Usage is the transcription work of a run.
+type Usage struct {
+	Audio        time.Duration // Audio transcribed (sum of the chunks sent)
+	InputTokens  int           // Tokens reported by token-billed models (0 otherwise)
+	OutputTokens int
+}
+
+// Add returns the sum of u and other.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		Audio:        u.Audio + other.Audio,
+		InputTokens:  u.InputTokens + other.InputTokens,
+		OutputTokens: u.OutputTokens + other.OutputTokens,
+	}
+}
+
+// UsageTracker aggregates the transcription usage of a run (see
+// WithUsageTracker).
+// The chunk functions (TranscribeRemaining, TranscribeStream) record the audio
+// of every transcribed chunk, and OpenAITranscriber the tokens reported by the API.
+// It is safe for concurrent use. A nil *UsageTracker discards all records.
+type UsageTracker struct {
+	mu    sync.Mutex
+	total Usage
+}
+
+// NewUsageTracker creates an empty UsageTracker.
+func NewUsageTracker() *UsageTracker {
+	return &UsageTracker{}
+}
+
+// Record adds u to the total.
+func (t *UsageTracker) Record(u Usage) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total = t.total.Add(u)
+}
+
+// Total returns the aggregated usage.
+func (t *UsageTracker) Total() Usage {
+	if t == nil {
+		return Usage{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.total
+}
+
+// WithUsageTracker makes the chunk functions, and the transcriber, record
+// the usage of a run in t.
+func WithUsageTracker(t *UsageTracker) RunOption {
+	return func(r *runOptions) {
+		r.usage = t
+	}
+}
+
+// usageResponse is the usage field of an OpenAI transcription response.
+// Token-billed models report tokens; whisper-1 reports the audio duration,
+// which the chunk functions already count.
+type usageResponse struct {
+	Usage struct {
+		Type         string `json:"type"`
+		InputTokens  int    `json:"input_tokens"`
+		OutputTokens int    `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// parseUsage returns the token usage reported in a transcription response body.
+// Returns zero usage if the response has none.
+func parseUsage(body []byte) Usage {
+	var resp usageResponse
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Usage.Type != "tokens" {
+		return Usage{}
+	}
+	return Usage{InputTokens: resp.Usage.InputTokens, OutputTokens: resp.Usage.OutputTokens}
+}
@@ -0,0 +1,137 @@
+package transcribe_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/audio"
+	"github.com/alnah/go-transcript/internal/transcribe"
+)
+
+func TestUsageTracker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("aggregates concurrent records", func(t *testing.T) {
+		t.Parallel()
+
+		tracker := transcribe.NewUsageTracker()
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				tracker.Record(transcribe.Usage{Audio: time.Second, InputTokens: 2, OutputTokens: 3})
+			})
+		}
+		wg.Wait()
+
+		want := transcribe.Usage{Audio: 10 * time.Second, InputTokens: 20, OutputTokens: 30}
+		if got := tracker.Total(); got != want {
+			t.Errorf("Total() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("nil tracker discards records", func(t *testing.T) {
+		t.Parallel()
+
+		var tracker *transcribe.UsageTracker
+		tracker.Record(transcribe.Usage{Audio: time.Second})
+		if got := tracker.Total(); got != (transcribe.Usage{}) {
+			t.Errorf("Total() = %+v, want zero", got)
+		}
+	})
+}
+
+func TestTranscribe_RecordsTokenUsage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want transcribe.Usage
+	}{
+		{
+			name: "token usage",
+			body: `{"text":"hello","usage":{"type":"tokens","input_tokens":120,"output_tokens":8,"total_tokens":128}}`,
+			want: transcribe.Usage{InputTokens: 120, OutputTokens: 8},
+		},
+		{
+			name: "duration usage is counted from chunks",
+			body: `{"text":"hello","usage":{"type":"duration","seconds":12}}`,
+			want: transcribe.Usage{},
+		},
+		{
+			name: "no usage",
+			body: `{"text":"hello"}`,
+			want: transcribe.Usage{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := newMockHTTPClient(http.StatusOK, tt.body)
+			tr := transcribe.NewTestTranscriber(client, "https://api.test", transcribe.MinimalRetryOpts()...)
+			tracker := transcribe.NewUsageTracker()
+
+			chunks := []audio.Chunk{{Path: createTempAudioFile(t)}}
+			texts, err := transcribe.TranscribeAll(context.Background(), chunks, tr, transcribe.Options{}, 1, transcribe.WithUsageTracker(tracker))
+			if err != nil {
+				t.Fatalf("TranscribeAll() error = %v", err)
+			}
+			if texts[0] != "hello" {
+				t.Errorf("TranscribeAll() = %q, want %q", texts[0], "hello")
+			}
+			if got := tracker.Total(); got != tt.want {
+				t.Errorf("usage = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscribeRemaining_RecordsAudio(t *testing.T) {
+	t.Parallel()
+
+	chunks := []audio.Chunk{
+		{Path: "/tmp/0.ogg", Index: 0, StartTime: 0, EndTime: 30 * time.Second},
+		{Path: "/tmp/1.ogg", Index: 1, StartTime: 30 * time.Second, EndTime: 50 * time.Second},
+		{Path: "/tmp/2.ogg", Index: 2, StartTime: 50 * time.Second, EndTime: 60 * time.Second},
+	}
+	tr := transcriberFunc(func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "text", nil
+	})
+	tracker := transcribe.NewUsageTracker()
+
+	// Chunk 1 was transcribed by an earlier run: its audio is not sent again.
+	done := map[int]string{1: "earlier"}
+	if _, err := transcribe.TranscribeRemaining(context.Background(), chunks, tr, transcribe.Options{}, 2, done, nil, transcribe.WithUsageTracker(tracker)); err != nil {
+		t.Fatalf("TranscribeRemaining() error = %v", err)
+	}
+
+	if got := tracker.Total().Audio; got != 40*time.Second {
+		t.Errorf("audio = %v, want 40s", got)
+	}
+}
+
+func TestTranscribeStream_RecordsAudio(t *testing.T) {
+	t.Parallel()
+
+	tr := transcriberFunc(func(ctx context.Context, audioPath string, opts transcribe.Options) (string, error) {
+		return "text", nil
+	})
+	tracker := transcribe.NewUsageTracker()
+
+	segments := chunkChannel(
+		audio.Chunk{Path: "/tmp/0.ogg", Index: 0, StartTime: 0, EndTime: 30 * time.Second},
+		audio.Chunk{Path: "/tmp/1.ogg", Index: 1, StartTime: 30 * time.Second, EndTime: 45 * time.Second},
+	)
+	if _, err := transcribe.TranscribeStream(context.Background(), segments, tr, transcribe.Options{}, 2, nil, transcribe.WithUsageTracker(tracker)); err != nil {
+		t.Fatalf("TranscribeStream() error = %v", err)
+	}
+
+	if got := tracker.Total().Audio; got != 45*time.Second {
+		t.Errorf("audio = %v, want 45s", got)
+	}
+}
@@ -0,0 +1,261 @@
+// Package trash keeps the previous versions of replaced output files, so that
+// an overwrite can be undone.
+//
+// A replaced file is moved into a .trash directory next to it, and recorded in
+// an index with its original path. The trash is bounded in size: the oldest
+// versions are removed once it grows past its limit. Restore puts the most
+// recent version back and moves the file that replaced it into the trash, so
+// undoing twice returns to where you started.
+package trash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DirName is the name of the trash directory, created in the output directory.
+const DirName = ".trash"
+
+// DefaultMaxBytes bounds the total size of the files kept in a trash.
+const DefaultMaxBytes int64 = 100 << 20 // 100 MB
+
+// indexVersion is bumped when the index format changes incompatibly.
+const indexVersion = 1
+
+// indexName is the file recording the trashed files, inside the trash directory.
+const indexName = "index.json"
+
+// ErrEmpty indicates there is no replaced file to restore.
+var ErrEmpty = errors.New("nothing to undo")
+
+// mu serializes the read-modify-write of trash indexes.
+var mu sync.Mutex
+
+// Entry is a file moved to the trash.
+type Entry struct {
+	Name       string    `json:"name"`        // File name inside the trash directory
+	Original   string    `json:"original"`    // Absolute path the file was replaced at
+	ReplacedAt time.Time `json:"replaced_at"` // When the file was moved to the trash
+	Size       int64     `json:"size"`        // File size in bytes
+}
+
+// index is the content of the index file. Entries are oldest first.
+type index struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// Trash is the .trash directory of an output directory.
+type Trash struct {
+	dir      string
+	maxBytes int64
+	now      func() time.Time
+}
+
+// Option configures a Trash.
+type Option func(*Trash)
+
+// WithMaxBytes sets the size above which the oldest files are pruned.
+// Zero or negative values are ignored.
+func WithMaxBytes(n int64) Option {
+	return func(t *Trash) {
+		if n > 0 {
+			t.maxBytes = n
+		}
+	}
+}
+
+// WithNow sets the clock used to timestamp trashed files.
+func WithNow(now func() time.Time) Option {
+	return func(t *Trash) {
+		if now != nil {
+			t.now = now
+		}
+	}
+}
+
+// New returns the trash of outputDir. The directory is created on the first Move.
+func New(outputDir string, opts ...Option) *Trash {
+	t := &Trash{
+		dir:      filepath.Join(outputDir, DirName),
+		maxBytes: DefaultMaxBytes,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Dir returns the trash directory.
+func (t *Trash) Dir() string {
+	return t.dir
+}
+
+// Move moves the file at path into the trash, before it is replaced.
+// A missing file is not an error: there is nothing to keep.
+// The oldest trashed files are pruned if the trash exceeds its size limit;
+// the file just moved is always kept.
+func (t *Trash) Move(path string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot access %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("cannot move directory %s to the trash", path)
+	}
+
+	idx, err := t.load()
+	if err != nil {
+		return err
+	}
+	entry, err := t.moveIn(path, info.Size())
+	if err != nil {
+		return err
+	}
+	idx.Entries = append(idx.Entries, entry)
+	t.prune(&idx)
+	return t.save(idx)
+}
+
+// Restore moves the most recently trashed file back to its original path.
+// The file currently at that path, if any, is moved to the trash in its place.
+// Returns the restored entry, or ErrEmpty if the trash holds no file.
+func (t *Trash) Restore() (Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	idx, err := t.load()
+	if err != nil {
+		return Entry{}, err
+	}
+	if len(idx.Entries) == 0 {
+		return Entry{}, ErrEmpty
+	}
+	last := idx.Entries[len(idx.Entries)-1]
+	idx.Entries = idx.Entries[:len(idx.Entries)-1]
+
+	if err := os.MkdirAll(filepath.Dir(last.Original), 0750); err != nil {
+		return Entry{}, fmt.Errorf("cannot restore %s: %w", last.Original, err)
+	}
+
+	// Keep the replacing version: undoing again swaps back.
+	var current *Entry
+	if info, err := os.Stat(last.Original); err == nil && !info.IsDir() {
+		e, err := t.moveIn(last.Original, info.Size())
+		if err != nil {
+			return Entry{}, err
+		}
+		current = &e
+	}
+
+	if err := os.Rename(filepath.Join(t.dir, last.Name), last.Original); err != nil {
+		if current != nil {
+			_ = os.Rename(filepath.Join(t.dir, current.Name), last.Original)
+		}
+		return Entry{}, fmt.Errorf("cannot restore %s: %w", last.Original, err)
+	}
+	if current != nil {
+		idx.Entries = append(idx.Entries, *current)
+	}
+	return last, t.save(idx)
+}
+
+// List returns the trashed files, oldest first.
+func (t *Trash) List() ([]Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	idx, err := t.load()
+	return idx.Entries, err
+}
+
+// moveIn renames the file at path into the trash directory under a unique,
+// timestamped name.
+func (t *Trash) moveIn(path string, size int64) (Entry, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Entry{}, fmt.Errorf("cannot resolve %s: %w", path, err)
+	}
+	if err := os.MkdirAll(t.dir, 0750); err != nil {
+		return Entry{}, fmt.Errorf("cannot create trash directory: %w", err)
+	}
+
+	now := t.now()
+	base := now.UTC().Format("20060102T150405.000000000") + "-" + filepath.Base(path)
+	name := base
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(filepath.Join(t.dir, name)); os.IsNotExist(err) {
+			break
+		}
+		name = fmt.Sprintf("%s.%d", base, i)
+	}
+
+	if err := os.Rename(path, filepath.Join(t.dir, name)); err != nil {
+		return Entry{}, fmt.Errorf("cannot move %s to the trash: %w", path, err)
+	}
+	return Entry{Name: name, Original: abs, ReplacedAt: now, Size: size}, nil
+}
+
+// prune removes the oldest entries until the trash fits its size limit,
+// keeping at least the most recent one.
+func (t *Trash) prune(idx *index) {
+	var total int64
+	for _, e := range idx.Entries {
+		total += e.Size
+	}
+	for total > t.maxBytes && len(idx.Entries) > 1 {
+		oldest := idx.Entries[0]
+		_ = os.Remove(filepath.Join(t.dir, oldest.Name))
+		total -= oldest.Size
+		idx.Entries = idx.Entries[1:]
+	}
+}
+
+// load reads the index, or returns an empty one if the trash does not exist.
+func (t *Trash) load() (index, error) {
+	data, err := os.ReadFile(filepath.Join(t.dir, indexName)) // #nosec G304 -- trash of the output dir
+	if os.IsNotExist(err) {
+		return index{Version: indexVersion}, nil
+	}
+	if err != nil {
+		return index{}, fmt.Errorf("cannot read trash index: %w", err)
+	}
+
+	var idx index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return index{}, fmt.Errorf("invalid trash index %s: %w", filepath.Join(t.dir, indexName), err)
+	}
+	return idx, nil
+}
+
+// save writes the index atomically.
+func (t *Trash) save(idx index) error {
+	idx.Version = indexVersion
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode trash index: %w", err)
+	}
+
+	path := filepath.Join(t.dir, indexName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("cannot write trash index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("cannot write trash index: %w", err)
+	}
+	return nil
+}
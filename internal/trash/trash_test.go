@@ -0,0 +1,189 @@
+package trash_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/trash"
+)
+
+// writeFile writes content at path, failing the test on error.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+// readFile returns the content at path, failing the test on error.
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// steppingClock returns a clock advancing one second per call.
+func steppingClock() func() time.Time {
+	now := time.Date(2026, 1, 26, 14, 30, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+}
+
+func TestMoveAndRestore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	tr := trash.New(dir, trash.WithNow(steppingClock()))
+
+	writeFile(t, path, "v1")
+	if err := tr.Move(path); err != nil {
+		t.Fatalf("Move() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Move() left the file in place (stat error = %v)", err)
+	}
+	writeFile(t, path, "v2")
+
+	entry, err := tr.Restore()
+	if err != nil {
+		t.Fatalf("Restore() unexpected error: %v", err)
+	}
+	if entry.Original != path {
+		t.Errorf("Restore() original = %q, want %q", entry.Original, path)
+	}
+	if got := readFile(t, path); got != "v1" {
+		t.Errorf("after Restore() content = %q, want %q", got, "v1")
+	}
+
+	// The replacing version was kept: undoing again swaps back.
+	if _, err := tr.Restore(); err != nil {
+		t.Fatalf("second Restore() unexpected error: %v", err)
+	}
+	if got := readFile(t, path); got != "v2" {
+		t.Errorf("after second Restore() content = %q, want %q", got, "v2")
+	}
+}
+
+func TestMove_MissingFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tr := trash.New(dir)
+
+	if err := tr.Move(filepath.Join(dir, "missing.md")); err != nil {
+		t.Fatalf("Move() on missing file error = %v, want nil", err)
+	}
+	if _, err := os.Stat(tr.Dir()); !os.IsNotExist(err) {
+		t.Errorf("Move() on missing file created the trash (stat error = %v)", err)
+	}
+}
+
+func TestMove_SameNameTwice(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	fixed := func() time.Time { return time.Date(2026, 1, 26, 14, 30, 0, 0, time.UTC) }
+	tr := trash.New(dir, trash.WithNow(fixed))
+
+	for _, content := range []string{"v1", "v2"} {
+		writeFile(t, path, content)
+		if err := tr.Move(path); err != nil {
+			t.Fatalf("Move() unexpected error: %v", err)
+		}
+	}
+
+	entries, err := tr.List()
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Name == entries[1].Name {
+		t.Fatalf("List() = %+v, want 2 entries with distinct names", entries)
+	}
+	if got := readFile(t, filepath.Join(tr.Dir(), entries[1].Name)); got != "v2" {
+		t.Errorf("latest trashed content = %q, want %q", got, "v2")
+	}
+}
+
+func TestMove_PrunesOldest(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tr := trash.New(dir, trash.WithMaxBytes(10), trash.WithNow(steppingClock()))
+
+	for _, name := range []string{"a.md", "b.md", "c.md"} {
+		path := filepath.Join(dir, name)
+		writeFile(t, path, strings.Repeat("x", 4))
+		if err := tr.Move(path); err != nil {
+			t.Fatalf("Move(%s) unexpected error: %v", name, err)
+		}
+	}
+
+	entries, err := tr.List()
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if len(entries) != 2 || filepath.Base(entries[0].Original) != "b.md" {
+		t.Fatalf("List() = %+v, want b.md and c.md", entries)
+	}
+	files, _ := filepath.Glob(filepath.Join(tr.Dir(), "*-a.md"))
+	if len(files) != 0 {
+		t.Errorf("pruned file still in trash: %v", files)
+	}
+}
+
+func TestMove_KeepsLatestAboveLimit(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "big.md")
+	tr := trash.New(dir, trash.WithMaxBytes(1))
+
+	writeFile(t, path, "larger than the limit")
+	if err := tr.Move(path); err != nil {
+		t.Fatalf("Move() unexpected error: %v", err)
+	}
+	if entries, _ := tr.List(); len(entries) != 1 {
+		t.Errorf("List() = %+v, want the file just moved", entries)
+	}
+}
+
+func TestRestore_Empty(t *testing.T) {
+	t.Parallel()
+
+	if _, err := trash.New(t.TempDir()).Restore(); !errors.Is(err, trash.ErrEmpty) {
+		t.Errorf("Restore() error = %v, want ErrEmpty", err)
+	}
+}
+
+func TestRestore_OriginalRemoved(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	tr := trash.New(dir)
+
+	writeFile(t, path, "v1")
+	if err := tr.Move(path); err != nil {
+		t.Fatalf("Move() unexpected error: %v", err)
+	}
+	if _, err := tr.Restore(); err != nil {
+		t.Fatalf("Restore() unexpected error: %v", err)
+	}
+	if got := readFile(t, path); got != "v1" {
+		t.Errorf("content = %q, want %q", got, "v1")
+	}
+	if _, err := tr.Restore(); !errors.Is(err, trash.ErrEmpty) {
+		t.Errorf("second Restore() error = %v, want ErrEmpty", err)
+	}
+}
@@ -0,0 +1,9 @@
+package vocab
+
+import "errors"
+
+// ErrInvalidTag indicates a session tag that cannot be used as a history name.
+var ErrInvalidTag = errors.New("invalid tag")
+
+// ErrInvalidGlossary indicates a glossary file that cannot be read or has no terms.
+var ErrInvalidGlossary = errors.New("invalid glossary")
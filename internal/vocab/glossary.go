@@ -0,0 +1,50 @@
+package vocab
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// maxGlossaryTermLength bounds a glossary term: longer lines are sentences,
+// not names.
+const maxGlossaryTermLength = 100
+
+// ReadGlossary reads the glossary file at path (--glossary): one term per
+// line, in order of priority, as the prompts keep the first terms when they
+// cannot hold them all. Blank lines and lines starting with '#' are ignored,
+// and repeated terms are kept once.
+// Returns ErrInvalidGlossary if the file cannot be read or has no terms.
+func ReadGlossary(path string) ([]string, error) {
+	f, err := os.Open(path) // #nosec G304 -- glossary file given by the user
+	if err != nil {
+		return nil, fmt.Errorf("cannot read glossary: %w: %v", ErrInvalidGlossary, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var terms []string
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		term := strings.TrimSpace(scanner.Text())
+		if term == "" || strings.HasPrefix(term, "#") || seen[term] {
+			continue
+		}
+		if len(term) > maxGlossaryTermLength {
+			return nil, fmt.Errorf("%w: %s:%d: term longer than %d characters",
+				ErrInvalidGlossary, path, lineNum, maxGlossaryTermLength)
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read glossary: %w: %v", ErrInvalidGlossary, err)
+	}
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("%w: %s has no terms", ErrInvalidGlossary, path)
+	}
+	return terms, nil
+}
@@ -0,0 +1,66 @@
+package vocab_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/vocab"
+)
+
+func TestReadGlossary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "one term per line",
+			content: "Kubernetes\nOKR\nMarie-Hélène Dupont\n",
+			want:    []string{"Kubernetes", "OKR", "Marie-Hélène Dupont"},
+		},
+		{
+			name:    "comments, blank lines and duplicates skipped",
+			content: "# Products\nAcme Cloud\n\n  OKR  \nAcme Cloud\n",
+			want:    []string{"Acme Cloud", "OKR"},
+		},
+		{name: "no terms", content: "# Nothing yet\n\n", wantErr: true},
+		{name: "term too long", content: strings.Repeat("x", 101), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "glossary.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := vocab.ReadGlossary(path)
+			if tt.wantErr {
+				if !errors.Is(err, vocab.ErrInvalidGlossary) {
+					t.Errorf("ReadGlossary() error = %v, want ErrInvalidGlossary", err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadGlossary() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadGlossary_Missing(t *testing.T) {
+	t.Parallel()
+
+	_, err := vocab.ReadGlossary(filepath.Join(t.TempDir(), "missing.txt"))
+	if !errors.Is(err, vocab.ErrInvalidGlossary) {
+		t.Errorf("ReadGlossary() error = %v, want ErrInvalidGlossary", err)
+	}
+}
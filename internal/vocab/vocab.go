@@ -0,0 +1,261 @@
+// Package vocab builds transcription prompts from the vocabulary of previous
+// sessions sharing a tag.
+//
+// Whisper recognizes names it was told about much more reliably. Instead of
+// maintaining vocabulary files by hand, each tagged session records the proper
+// nouns found in its transcript, and the next session with the same tag passes
+// the most frequent ones as the prompt.
+package vocab
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// historyVersion is bumped when the history format changes incompatibly.
+const historyVersion = 1
+
+// DefaultPromptTerms is the number of terms put in the prompt.
+// Whisper only reads the last 224 tokens of the prompt.
+const DefaultPromptTerms = 40
+
+// MaxPromptLength bounds the length of the prompt, in bytes: at about three
+// characters per token, Whisper would drop the terms beyond 224 tokens, and
+// it drops those at the start, the ones that matter most.
+const MaxPromptLength = 600
+
+// minTermCount is how often a term must have been seen to be put in the prompt.
+// A name mentioned once may be a misrecognition itself.
+const minTermCount = 2
+
+// maxTagLength bounds the tag, which is used as a file name.
+const maxTagLength = 64
+
+// tagPattern restricts tags to characters that are safe in file names.
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// recordMu serializes Record, whose read-modify-write would otherwise lose
+// updates when several files of a batch share a tag.
+var recordMu sync.Mutex
+
+// speakerLabel matches the "[Speaker]" prefix of diarized transcript lines.
+var speakerLabel = regexp.MustCompile(`(?m)^\[[^\]\n]*\]`)
+
+// commonCapitalized are words often capitalized mid-sentence that are not names.
+var commonCapitalized = map[string]bool{
+	"I": true, "I'm": true, "I've": true, "I'll": true, "I'd": true,
+	"OK": true, "Okay": true, "Speaker": true,
+}
+
+// ParseTag validates a session tag (--tag).
+// Tags are lowercase letters, digits, '.', '_' and '-', starting with a letter or digit.
+// Returns ErrInvalidTag otherwise.
+func ParseTag(s string) (string, error) {
+	if len(s) > maxTagLength || !tagPattern.MatchString(s) {
+		return "", fmt.Errorf("tag %q must be lowercase letters, digits, '.', '_' or '-' (max %d characters): %w",
+			s, maxTagLength, ErrInvalidTag)
+	}
+	return s, nil
+}
+
+// History is the vocabulary recorded for a tag.
+type History struct {
+	Version  int            `json:"version"`
+	Sessions int            `json:"sessions"` // Number of transcripts recorded
+	Terms    map[string]int `json:"terms"`    // Proper noun -> occurrences
+}
+
+// Store keeps the history of each tag as <dir>/<tag>.json.
+type Store struct {
+	dir string
+}
+
+// NewStore returns a store of tag histories in dir.
+// The directory is created on the first Record.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// path returns the history file of tag.
+func (s *Store) path(tag string) string {
+	return filepath.Join(s.dir, tag+".json")
+}
+
+// Load returns the history of tag, or an empty history if none was recorded.
+func (s *Store) Load(tag string) (History, error) {
+	if _, err := ParseTag(tag); err != nil {
+		return History{}, err
+	}
+
+	data, err := os.ReadFile(s.path(tag)) // #nosec G304 -- tag validated, dir from config
+	if os.IsNotExist(err) {
+		return History{Version: historyVersion, Terms: map[string]int{}}, nil
+	}
+	if err != nil {
+		return History{}, fmt.Errorf("cannot read vocabulary of tag %q: %w", tag, err)
+	}
+
+	var h History
+	if err := json.Unmarshal(data, &h); err != nil {
+		return History{}, fmt.Errorf("invalid vocabulary of tag %q: %w", tag, err)
+	}
+	if h.Terms == nil {
+		h.Terms = map[string]int{}
+	}
+	return h, nil
+}
+
+// Record adds the proper nouns of transcript to the history of tag.
+func (s *Store) Record(tag, transcript string) error {
+	recordMu.Lock()
+	defer recordMu.Unlock()
+
+	h, err := s.Load(tag)
+	if err != nil {
+		return err
+	}
+	h.Version = historyVersion
+	h.Sessions++
+	for term, n := range ProperNouns(transcript) {
+		h.Terms[term] += n
+	}
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode vocabulary: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0750); err != nil {
+		return fmt.Errorf("cannot create vocabulary directory: %w", err)
+	}
+
+	path := s.path(tag)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("cannot write vocabulary: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("cannot write vocabulary: %w", err)
+	}
+	return nil
+}
+
+// TopTerms returns up to limit terms seen at least twice, most frequent first
+// (ties in alphabetical order).
+func (h History) TopTerms(limit int) []string {
+	terms := make([]string, 0, len(h.Terms))
+	for term, n := range h.Terms {
+		if n >= minTermCount {
+			terms = append(terms, term)
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if h.Terms[terms[i]] != h.Terms[terms[j]] {
+			return h.Terms[terms[i]] > h.Terms[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+	if len(terms) > limit {
+		terms = terms[:limit]
+	}
+	return terms
+}
+
+// Prompt returns the transcription prompt listing the top terms of h,
+// or an empty string if no term was seen often enough.
+func (h History) Prompt(limit int) string {
+	return h.PromptWith(nil, limit)
+}
+
+// PromptWith returns the transcription prompt listing the fixed terms first
+// (the glossary, then the vocab of a project file), then the top terms of h
+// not already listed, up to limit terms in all. Terms that would make the
+// prompt longer than MaxPromptLength are left out, so the first ones are
+// kept. Returns an empty string if there are none.
+func (h History) PromptWith(fixed []string, limit int) string {
+	terms := make([]string, 0, limit)
+	length := 0
+	for _, term := range slices.Concat(fixed, h.TopTerms(limit)) {
+		if len(terms) == limit {
+			break
+		}
+		if slices.Contains(terms, term) || length+len(term)+len(", ") > MaxPromptLength {
+			continue
+		}
+		terms = append(terms, term)
+		length += len(term) + len(", ")
+	}
+	if len(terms) == 0 {
+		return ""
+	}
+	return strings.Join(terms, ", ") + "."
+}
+
+// ProperNouns counts the proper nouns of text.
+//
+// A word is a proper noun if it is capitalized in the middle of a sentence
+// at least once; its capitalized occurrences at the start of sentences then
+// count too. Acronyms (two or more capitals) always count. Speaker labels of
+// diarized transcripts are ignored.
+func ProperNouns(text string) map[string]int {
+	text = speakerLabel.ReplaceAllString(text, "")
+
+	var words []string // Capitalized words, in order
+	names := map[string]bool{}
+
+	initial := true
+	for _, field := range strings.Fields(text) {
+		word := strings.TrimFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if word != "" {
+			if isCapitalized(word) && !commonCapitalized[word] {
+				words = append(words, word)
+				if !initial || isAcronym(word) {
+					names[word] = true
+				}
+			}
+			initial = false
+		}
+		if strings.ContainsAny(field[len(field)-1:], ".!?:") || strings.HasPrefix(field, "#") {
+			initial = true
+		}
+	}
+
+	counts := map[string]int{}
+	for _, w := range words {
+		if names[w] {
+			counts[w]++
+		}
+	}
+	return counts
+}
+
+// isCapitalized reports whether word starts with an uppercase letter.
+func isCapitalized(word string) bool {
+	r, _ := utf8.DecodeRuneInString(word)
+	return unicode.IsUpper(r)
+}
+
+// isAcronym reports whether word has at least two letters, all uppercase.
+func isAcronym(word string) bool {
+	letters := 0
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			if !unicode.IsUpper(r) {
+				return false
+			}
+			letters++
+		}
+	}
+	return letters >= 2
+}
@@ -0,0 +1,227 @@
+package vocab_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alnah/go-transcript/internal/vocab"
+)
+
+func TestParseTag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "simple", input: "apollo"},
+		{name: "with separators", input: "project-apollo_2.0"},
+		{name: "empty", input: "", wantErr: true},
+		{name: "uppercase", input: "Apollo", wantErr: true},
+		{name: "path separator", input: "../apollo", wantErr: true},
+		{name: "leading dot", input: ".apollo", wantErr: true},
+		{name: "too long", input: string(make([]byte, 65)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := vocab.ParseTag(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, vocab.ErrInvalidTag) {
+					t.Errorf("ParseTag(%q) error = %v, want ErrInvalidTag", tt.input, err)
+				}
+				return
+			}
+			if err != nil || got != tt.input {
+				t.Errorf("ParseTag(%q) = %q, %v", tt.input, got, err)
+			}
+		})
+	}
+}
+
+func TestProperNouns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]int
+	}{
+		{
+			name:  "mid-sentence names",
+			input: "We met Alice and Bob at Kubernetes Conf.",
+			want:  map[string]int{"Alice": 1, "Bob": 1, "Kubernetes": 1, "Conf": 1},
+		},
+		{
+			name:  "sentence start counts once the word is known as a name",
+			input: "Alice joined late. Then Alice left. We said goodbye to Alice.",
+			want:  map[string]int{"Alice": 3},
+		},
+		{
+			name:  "sentence start alone is not a name",
+			input: "Then we left. Then we came back.",
+			want:  map[string]int{},
+		},
+		{
+			name:  "acronyms count anywhere",
+			input: "NASA approved it. We called NASA twice.",
+			want:  map[string]int{"NASA": 2},
+		},
+		{
+			name:  "pronoun and speaker labels ignored",
+			input: "[Speaker A] I think I'm right.\n[Speaker B] OK, ask Marie.",
+			want:  map[string]int{"Marie": 1},
+		},
+		{
+			name:  "punctuation stripped",
+			input: "ask (Houston), then \"Houston\"?",
+			want:  map[string]int{"Houston": 2},
+		},
+		{
+			name:  "empty",
+			input: "",
+			want:  map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := vocab.ProperNouns(tt.input)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ProperNouns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistory_Prompt(t *testing.T) {
+	t.Parallel()
+
+	h := vocab.History{Terms: map[string]int{
+		"Apollo":  5,
+		"Houston": 3,
+		"Aldrin":  3,
+		"Once":    1,
+	}}
+
+	if got, want := h.Prompt(10), "Apollo, Aldrin, Houston."; got != want {
+		t.Errorf("Prompt(10) = %q, want %q", got, want)
+	}
+	if got, want := h.Prompt(1), "Apollo."; got != want {
+		t.Errorf("Prompt(1) = %q, want %q", got, want)
+	}
+	if got := (vocab.History{}).Prompt(10); got != "" {
+		t.Errorf("empty Prompt() = %q, want empty", got)
+	}
+}
+
+func TestHistory_PromptWith(t *testing.T) {
+	t.Parallel()
+
+	h := vocab.History{Terms: map[string]int{"Apollo": 5, "Houston": 3}}
+	fixed := []string{"Kubernetes", "Houston"}
+
+	tests := []struct {
+		name    string
+		history vocab.History
+		limit   int
+		want    string
+	}{
+		{"fixed terms first, without duplicates", h, 10, "Kubernetes, Houston, Apollo."},
+		{"limit counts fixed terms", h, 3, "Kubernetes, Houston, Apollo."},
+		{"fixed terms are kept first", h, 1, "Kubernetes."},
+		{"no history", vocab.History{}, 10, "Kubernetes, Houston."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.history.PromptWith(fixed, tt.limit); got != tt.want {
+				t.Errorf("PromptWith(%d) = %q, want %q", tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistory_PromptWith_MaxLength(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("x", vocab.MaxPromptLength-len("Apollo, "))
+	got := vocab.History{}.PromptWith([]string{"Apollo", long, "Houston"}, 10)
+	if len(got) > vocab.MaxPromptLength {
+		t.Errorf("len(PromptWith()) = %d, want at most %d", len(got), vocab.MaxPromptLength)
+	}
+	if got != "Apollo, Houston." {
+		t.Errorf("PromptWith() = %q, want the terms that fit", got)
+	}
+}
+
+func TestStore_RecordAndLoad(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "tags")
+	store := vocab.NewStore(dir)
+
+	h, err := store.Load("apollo")
+	if err != nil {
+		t.Fatalf("Load() on empty store: %v", err)
+	}
+	if h.Sessions != 0 || len(h.Terms) != 0 {
+		t.Errorf("Load() on empty store = %+v, want empty history", h)
+	}
+
+	if err := store.Record("apollo", "We met Armstrong in Houston."); err != nil {
+		t.Fatalf("Record() error: %v", err)
+	}
+	if err := store.Record("apollo", "Then Armstrong called Houston again."); err != nil {
+		t.Fatalf("Record() error: %v", err)
+	}
+
+	h, err = store.Load("apollo")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if h.Sessions != 2 {
+		t.Errorf("Sessions = %d, want 2", h.Sessions)
+	}
+	if got, want := h.Prompt(vocab.DefaultPromptTerms), "Armstrong, Houston."; got != want {
+		t.Errorf("Prompt() = %q, want %q", got, want)
+	}
+
+	// Other tags are independent
+	other, err := store.Load("zeus")
+	if err != nil || other.Sessions != 0 {
+		t.Errorf("Load(other tag) = %+v, %v; want empty history", other, err)
+	}
+}
+
+func TestStore_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store := vocab.NewStore(dir)
+
+	if _, err := store.Load("../escape"); !errors.Is(err, vocab.ErrInvalidTag) {
+		t.Errorf("Load(invalid tag) error = %v, want ErrInvalidTag", err)
+	}
+	if err := store.Record("../escape", "text"); !errors.Is(err, vocab.ErrInvalidTag) {
+		t.Errorf("Record(invalid tag) error = %v, want ErrInvalidTag", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load("broken"); err == nil {
+		t.Error("Load(corrupt history) expected error")
+	}
+}
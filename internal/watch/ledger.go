@@ -0,0 +1,99 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ledgerVersion is the format version of ledger files.
+const ledgerVersion = 1
+
+// Entry records a processed file, as it was when processed.
+type Entry struct {
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"mod_time"`
+	Output    string    `json:"output"`
+	Processed time.Time `json:"processed"`
+}
+
+// ledgerFile is the content of a ledger file.
+type ledgerFile struct {
+	Version int              `json:"version"`
+	Dir     string           `json:"dir"`   // Watched directory, for people reading the file
+	Files   map[string]Entry `json:"files"` // File name -> entry
+}
+
+// Ledger remembers the files of a watched directory that were processed, so
+// that they are not processed again after a restart. A file modified since
+// it was processed is processed again. It is safe for concurrent use.
+type Ledger struct {
+	path string
+
+	mu   sync.Mutex
+	data ledgerFile
+}
+
+// OpenLedger returns the ledger of dir stored at path, empty if the file
+// does not exist yet. The file is written on the first Record.
+func OpenLedger(path, dir string) (*Ledger, error) {
+	l := &Ledger{path: path, data: ledgerFile{Version: ledgerVersion, Dir: dir, Files: map[string]Entry{}}}
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path in the state directory
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read watch ledger: %w", err)
+	}
+	if err := json.Unmarshal(data, &l.data); err != nil {
+		return nil, fmt.Errorf("invalid watch ledger %s: %w", path, err)
+	}
+	if l.data.Files == nil {
+		l.data.Files = map[string]Entry{}
+	}
+	return l, nil
+}
+
+// Processed reports whether the file at path was processed as it is now.
+func (l *Ledger) Processed(path string, info os.FileInfo) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.data.Files[filepath.Base(path)]
+	return ok && e.Size == info.Size() && e.ModTime.Equal(info.ModTime())
+}
+
+// Record marks the file at path, as described by info, as processed into
+// output at time at, and saves the ledger.
+func (l *Ledger) Record(path string, info os.FileInfo, output string, at time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.data.Version = ledgerVersion
+	l.data.Files[filepath.Base(path)] = Entry{
+		Size:      info.Size(),
+		ModTime:   info.ModTime(),
+		Output:    output,
+		Processed: at,
+	}
+
+	data, err := json.MarshalIndent(l.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode watch ledger: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0750); err != nil {
+		return fmt.Errorf("cannot create watch ledger directory: %w", err)
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("cannot write watch ledger: %w", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("cannot write watch ledger: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package watch_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/watch"
+)
+
+func TestLedger(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(t.TempDir(), "state", "ledger.json")
+	audio := filepath.Join(dir, "talk.ogg")
+	writeFile(t, audio, "audio")
+	info, err := os.Stat(audio)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := watch.OpenLedger(path, dir)
+	if err != nil {
+		t.Fatalf("OpenLedger() unexpected error: %v", err)
+	}
+	if l.Processed(audio, info) {
+		t.Fatal("Processed() = true before Record")
+	}
+	if err := l.Record(audio, info, filepath.Join(dir, "talk.md"), time.Now()); err != nil {
+		t.Fatalf("Record() unexpected error: %v", err)
+	}
+	if !l.Processed(audio, info) {
+		t.Error("Processed() = false after Record")
+	}
+
+	// Remembered across runs
+	reopened, err := watch.OpenLedger(path, dir)
+	if err != nil {
+		t.Fatalf("OpenLedger() unexpected error: %v", err)
+	}
+	if !reopened.Processed(audio, info) {
+		t.Error("Processed() = false after reopening the ledger")
+	}
+
+	// Processed again once modified
+	writeFile(t, audio, "longer audio")
+	modified, err := os.Stat(audio)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Processed(audio, modified) {
+		t.Error("Processed() = true for a modified file")
+	}
+}
+
+func TestOpenLedger_Invalid(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	writeFile(t, path, "{not json")
+	if _, err := watch.OpenLedger(path, t.TempDir()); err == nil {
+		t.Error("OpenLedger() error = nil, want an error for an invalid file")
+	}
+}
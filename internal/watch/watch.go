@@ -0,0 +1,162 @@
+// Package watch reports the files added to a directory once they are
+// complete, and remembers which files were processed across runs.
+//
+// A file being copied or recorded into the directory triggers many write
+// notifications: it is reported only after its size and modification time
+// stopped changing for a settle delay.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Compile-time interface implementation check.
+var _ Watcher = (*FSWatcher)(nil)
+
+// DefaultSettle is how long a file must stay unchanged before it is reported.
+const DefaultSettle = 3 * time.Second
+
+// minCheckInterval bounds how often pending files are checked.
+const minCheckInterval = 10 * time.Millisecond
+
+// Watcher watches directories for new files.
+type Watcher interface {
+	// Watch calls ready with the path of each regular file of dir whose path
+	// satisfies match, once complete: the files already there, then each file
+	// added or modified, until ctx is canceled. ready is called from the
+	// watching goroutine and must not block. Hidden files are ignored.
+	Watch(ctx context.Context, dir string, match func(path string) bool, ready func(path string)) error
+}
+
+// FSWatcher implements Watcher with file system notifications.
+type FSWatcher struct {
+	settle time.Duration
+}
+
+// Option configures an FSWatcher.
+type Option func(*FSWatcher)
+
+// WithSettle sets how long a file must stay unchanged before it is reported.
+// Values <= 0 keep DefaultSettle.
+func WithSettle(d time.Duration) Option {
+	return func(w *FSWatcher) {
+		if d > 0 {
+			w.settle = d
+		}
+	}
+}
+
+// NewFSWatcher creates a file system watcher.
+func NewFSWatcher(opts ...Option) *FSWatcher {
+	w := &FSWatcher{settle: DefaultSettle}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// pendingFile is a file waiting to settle.
+type pendingFile struct {
+	size    int64
+	modTime time.Time
+	since   time.Time // Last change seen
+}
+
+// Watch implements Watcher. Returns nil once ctx is canceled.
+func (w *FSWatcher) Watch(ctx context.Context, dir string, match func(path string) bool, ready func(path string)) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("cannot watch %s: %w", dir, err)
+	}
+	defer fw.Close() //nolint:errcheck // nothing to flush
+	if err := fw.Add(dir); err != nil {
+		return fmt.Errorf("cannot watch %s: %w", dir, err)
+	}
+
+	// Scanned once watching, so that no file added in between is missed.
+	pending := make(map[string]pendingFile)
+	if err := w.scan(dir, match, pending); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(max(w.settle/4, minCheckInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-fw.Events:
+			if !ok {
+				return nil
+			}
+			switch {
+			case event.Has(fsnotify.Create), event.Has(fsnotify.Write):
+				w.track(event.Name, match, pending)
+			case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+				delete(pending, event.Name) // Renamed files are created under their new name
+			}
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return nil
+			}
+			if !errors.Is(err, fsnotify.ErrEventOverflow) {
+				return fmt.Errorf("watching %s: %w", dir, err)
+			}
+			// Notifications were lost: look for the files they were about.
+			if err := w.scan(dir, match, pending); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			w.settleFiles(pending, ready)
+		}
+	}
+}
+
+// scan tracks the files of dir.
+func (w *FSWatcher) scan(dir string, match func(path string) bool, pending map[string]pendingFile) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("cannot read directory %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		w.track(filepath.Join(dir, e.Name()), match, pending)
+	}
+	return nil
+}
+
+// track adds path to the pending files, or restarts its settle delay.
+func (w *FSWatcher) track(path string, match func(path string) bool, pending map[string]pendingFile) {
+	if strings.HasPrefix(filepath.Base(path), ".") || !match(path) {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return
+	}
+	pending[path] = pendingFile{size: info.Size(), modTime: info.ModTime(), since: time.Now()}
+}
+
+// settleFiles reports the pending files unchanged for the settle delay.
+func (w *FSWatcher) settleFiles(pending map[string]pendingFile, ready func(path string)) {
+	now := time.Now()
+	for path, p := range pending {
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			delete(pending, path)
+		case info.Size() != p.size || !info.ModTime().Equal(p.modTime):
+			pending[path] = pendingFile{size: info.Size(), modTime: info.ModTime(), since: now}
+		case now.Sub(p.since) >= w.settle:
+			delete(pending, path)
+			ready(path)
+		}
+	}
+}
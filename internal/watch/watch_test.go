@@ -0,0 +1,189 @@
+package watch_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alnah/go-transcript/internal/watch"
+)
+
+// testSettle is the settle delay of watchers under test.
+const testSettle = 100 * time.Millisecond
+
+// isAudio matches the files watched in tests.
+func isAudio(path string) bool {
+	return strings.HasSuffix(path, ".ogg")
+}
+
+// startWatch watches dir until the test ends. Returns the channel of the
+// files reported.
+func startWatch(t *testing.T, dir string) <-chan string {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ready := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- watch.NewFSWatcher(watch.WithSettle(testSettle)).Watch(ctx, dir, isAudio, func(path string) {
+			ready <- path
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Watch() unexpected error: %v", err)
+		}
+	})
+	return ready
+}
+
+// nextReady returns the next file reported, failing t after a timeout.
+func nextReady(t *testing.T, ready <-chan string) string {
+	t.Helper()
+	select {
+	case path := <-ready:
+		return path
+	case <-time.After(5 * time.Second):
+		t.Fatal("no file reported")
+		return ""
+	}
+}
+
+// assertNoneReady fails t if a file is reported within a few settle delays.
+func assertNoneReady(t *testing.T, ready <-chan string) {
+	t.Helper()
+	select {
+	case path := <-ready:
+		t.Errorf("unexpected file reported: %s", path)
+	case <-time.After(4 * testSettle):
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFSWatcher_Watch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("existing then new files", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		existing := filepath.Join(dir, "existing.ogg")
+		writeFile(t, existing, "audio")
+		ready := startWatch(t, dir)
+
+		if got := nextReady(t, ready); got != existing {
+			t.Errorf("first file = %q, want %q", got, existing)
+		}
+		added := filepath.Join(dir, "added.ogg")
+		writeFile(t, added, "audio")
+		if got := nextReady(t, ready); got != added {
+			t.Errorf("second file = %q, want %q", got, added)
+		}
+		assertNoneReady(t, ready)
+	})
+
+	t.Run("ignores hidden and unmatched files", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		ready := startWatch(t, dir)
+		writeFile(t, filepath.Join(dir, ".partial.ogg"), "audio")
+		writeFile(t, filepath.Join(dir, "notes.md"), "text")
+		if err := os.Mkdir(filepath.Join(dir, "folder.ogg"), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		assertNoneReady(t, ready)
+	})
+
+	t.Run("file being written reported once complete", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		ready := startWatch(t, dir)
+		path := filepath.Join(dir, "recording.ogg")
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		// Writes for twice the settle delay
+		start := time.Now()
+		for time.Since(start) < 2*testSettle {
+			if _, err := f.WriteString("audio"); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(testSettle / 5)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+		written := time.Now()
+
+		if got := nextReady(t, ready); got != path {
+			t.Errorf("file = %q, want %q", got, path)
+		}
+		if elapsed := time.Since(written); elapsed < testSettle/2 {
+			t.Errorf("reported %s after the last write, want after the settle delay", elapsed)
+		}
+		assertNoneReady(t, ready)
+	})
+
+	t.Run("modified file reported again", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "talk.ogg")
+		writeFile(t, path, "audio")
+		ready := startWatch(t, dir)
+		nextReady(t, ready)
+
+		writeFile(t, path, "new audio")
+		if got := nextReady(t, ready); got != path {
+			t.Errorf("file = %q, want %q", got, path)
+		}
+	})
+}
+
+func TestFSWatcher_MissingDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "missing")
+	err := watch.NewFSWatcher().Watch(context.Background(), dir, isAudio, func(string) {})
+	if err == nil || !strings.Contains(err.Error(), dir) {
+		t.Errorf("Watch() error = %v, want an error naming %s", err, dir)
+	}
+}
+
+func TestFSWatcher_ExistingFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	var want []string
+	for _, name := range []string{"a.ogg", "b.ogg", "c.ogg"} {
+		path := filepath.Join(dir, name)
+		writeFile(t, path, "audio")
+		want = append(want, path)
+	}
+	ready := startWatch(t, dir)
+
+	var got []string
+	for range want {
+		got = append(got, nextReady(t, ready))
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("files = %q, want %q", got, want)
+	}
+}